@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +32,7 @@ import (
 
 	"github.com/getgort/gort/auth"
 	"github.com/getgort/gort/bundles"
+	"github.com/getgort/gort/cluster"
 	"github.com/getgort/gort/command"
 	"github.com/getgort/gort/config"
 	"github.com/getgort/gort/data"
@@ -40,7 +43,7 @@ import (
 	"github.com/getgort/gort/rules"
 	"github.com/getgort/gort/telemetry"
 	"github.com/getgort/gort/templates"
-	"github.com/getgort/gort/version"
+	"github.com/getgort/gort/types"
 )
 
 var (
@@ -72,10 +75,23 @@ var (
 	// to false.
 	ErrSelfRegistrationOff = errors.New("user doesn't exist and self-registration is off")
 
+	// ErrInputFileTooLarge is returned when a file attached to a command
+	// message is larger than global.input_files.max_size.
+	ErrInputFileTooLarge = errors.New("attached file is too large")
+
 	// ErrMultipleCommands is returned by GetCommandEntry when the same command
 	// shortcut matches commands in two or more bundles.
 	ErrMultipleCommands = errors.New("multiple commands match that pattern")
 
+	// ErrNoPreviousCommand is returned when a user asks to repeat their
+	// last command in a channel, but they haven't run any there.
+	ErrNoPreviousCommand = errors.New("no previous command")
+
+	// ErrRepeatRedacted is returned when a user asks to repeat their last
+	// command, but some of its parameters were redacted when it was
+	// recorded, so it can't be repeated faithfully.
+	ErrRepeatRedacted = errors.New("previous command has redacted parameters")
+
 	// ErrNoSuchAdapter is returned by GetAdapter if a requested adapter name
 	// can't be found.
 	ErrNoSuchAdapter = errors.New("no such adapter")
@@ -132,6 +148,13 @@ type RequestorIdentity struct {
 	ChatUser    *UserInfo
 	ChatChannel *ChannelInfo
 	GortUser    *rest.User
+
+	// MessageID is the provider ID of the message being handled, if known.
+	MessageID string
+
+	// Received is the time the message being handled was received, if
+	// known. Request deadlines are measured from it.
+	Received time.Time
 }
 
 // AddAdapter adds an adapter.
@@ -145,6 +168,7 @@ func AddAdapter(a Adapter) {
 
 	log.WithField("adapter", name).Debug("Adapter added")
 	adapterLookup[name] = a
+	cluster.AddAdapter(name)
 }
 
 // GetAdapter returns the requested adapter instance, if one exists.
@@ -196,8 +220,10 @@ func GetCommandEntry(ctx context.Context, bundleName, commandName string) (data.
 }
 
 // GetCommandEntryByTrigger accepts a tokenized parameter slice and returns any
-// associated data.CommandEntry instances. If the number of matching
-// commands is > 1, an error is returned.
+// associated data.CommandEntry instances. Triggers that are restricted to
+// channels other than the one the message was posted in are ignored. If more
+// than one command matches, the precedence strategies in global.triggers are
+// used to choose between them; if they can't, an error is returned.
 func GetCommandEntryByTrigger(ctx context.Context, tokens []string) (data.CommandEntry, error) {
 	finders, err := allCommandEntryFinders()
 	if err != nil {
@@ -209,6 +235,18 @@ func GetCommandEntryByTrigger(ctx context.Context, tokens []string) (data.Comman
 		return data.CommandEntry{}, err
 	}
 
+	message := strings.Join(tokens, " ")
+
+	entries, err = triggerEntriesForChannel(ctx, entries, message)
+	if err != nil {
+		return data.CommandEntry{}, err
+	}
+
+	entries, err = bundles.ResolveTriggerPrecedence(entries, message, config.GetGlobalConfigs().Triggers)
+	if err != nil {
+		return data.CommandEntry{}, err
+	}
+
 	if len(entries) == 0 {
 		return data.CommandEntry{}, ErrNoSuchCommand
 	}
@@ -247,15 +285,8 @@ func OnConnected(ctx context.Context, event *ProviderEvent, data *ConnectedEvent
 		return
 	}
 
-	for _, c := range channels {
-		message := fmt.Sprintf("Gort version %s is online. Hello, %s!", version.Version, c.Name)
-		err := SendMessage(ctx, event.Adapter, c.ID, message)
-		if err != nil {
-			telemetry.Errors().WithError(err).Commit(ctx)
-			addSpanAttributes(ctx, sp, err)
-			le.WithError(err).Error("Failed to send greeting")
-		}
-	}
+	greetChannels(ctx, event.Adapter, channels)
+	leaveInactiveChannels(ctx, event.Adapter)
 }
 
 // OnChannelMessage handles ChannelMessageEvent events.
@@ -267,6 +298,8 @@ func OnChannelMessage(ctx context.Context, event *ProviderEvent, data *ChannelMe
 	ctx, sp := tr.Start(ctx, "adapter.OnChannelMessage")
 	defer sp.End()
 
+	recordChannelActivity(event.Adapter.GetName(), data.ChannelID)
+
 	rawCommandText := data.Text
 
 	// Ignore empty messages
@@ -285,20 +318,39 @@ func OnChannelMessage(ctx context.Context, event *ProviderEvent, data *ChannelMe
 		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Error", unexpectedError)
 		return nil, err
 	}
+	id.MessageID = data.MessageID
+	id.Received = event.Received
 
 	adapterLogEntry(ctx, nil, event, id).
 		WithField("command.raw", rawCommandText).
 		Debug("Got message")
 	addSpanAttributes(ctx, sp, event, attribute.String("command.raw", rawCommandText))
 
-	// Find command by Name if the message starts with '!'
+	// Find command by Name if the message starts with '!'. Otherwise attempt
+	// to find command by trigger.
+	lookup := commandFromTokensByTrigger
+	getRequest := GetCommandRequest
 	if rawCommandText[0] == '!' {
 		rawCommandText = rawCommandText[1:]
-		return GetCommandRequest(ctx, rawCommandText, id, commandFromTokensByName)
+		if request, ok, err := recallHistory(ctx, rawCommandText, id); ok {
+			return request, err
+		}
+		lookup = commandFromTokensByName
+		if pipelinesEnabled() {
+			getRequest = GetPipelineRequest
+		}
+	}
+
+	request, err := getRequest(ctx, rawCommandText, id, lookup)
+	if request == nil || err != nil {
+		return request, err
+	}
+
+	if err := attachInputFile(ctx, request, id, data.Attachments); err != nil {
+		return nil, err
 	}
 
-	// Otherwise attempt to find command by trigger
-	return GetCommandRequest(ctx, rawCommandText, id, commandFromTokensByTrigger)
+	return request, nil
 }
 
 // OnDirectMessage handles DirectMessageEvent events.
@@ -315,17 +367,67 @@ func OnDirectMessage(ctx context.Context, event *ProviderEvent, data *DirectMess
 		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Error", unexpectedError)
 		return nil, err
 	}
+	id.MessageID = data.MessageID
+	id.Received = event.Received
 
 	adapterLogEntry(ctx, nil, event, id).
 		WithField("command.raw", rawCommandText).
 		Debug("Got direct message")
 	addSpanAttributes(ctx, sp, event, attribute.String("command.raw", rawCommandText))
 
+	lookup := commandFromTokensByNameOrTrigger
+	getRequest := GetCommandRequest
 	if rawCommandText[0] == '!' {
 		rawCommandText = rawCommandText[1:]
-		return GetCommandRequest(ctx, rawCommandText, id, commandFromTokensByName)
+		if request, ok, err := recallHistory(ctx, rawCommandText, id); ok {
+			return request, err
+		}
+		lookup = commandFromTokensByName
+		if pipelinesEnabled() {
+			getRequest = GetPipelineRequest
+		}
 	}
-	return GetCommandRequest(ctx, rawCommandText, id, commandFromTokensByNameOrTrigger)
+
+	request, err := getRequest(ctx, rawCommandText, id, lookup)
+	if request == nil || err != nil {
+		return request, err
+	}
+
+	if err := attachInputFile(ctx, request, id, data.Attachments); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// OnInteraction handles InteractionEvent events. The element's command, plus
+// the selected option if there is one, is executed exactly as though the
+// user had typed it, so the usual permission checks apply.
+func OnInteraction(ctx context.Context, event *ProviderEvent, data *InteractionEvent) (*data.CommandRequest, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.OnInteraction")
+	defer sp.End()
+
+	rawCommandText := strings.TrimPrefix(data.Command, "!")
+	if data.Selection != "" {
+		rawCommandText += " " + strconv.Quote(data.Selection)
+	}
+
+	id, err := buildRequestorIdentity(ctx, event.Adapter, data.ChannelID, data.UserID)
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Error", unexpectedError)
+		return nil, err
+	}
+	id.MessageID = data.MessageID
+	id.Received = event.Received
+
+	adapterLogEntry(ctx, nil, event, id).
+		WithField("command.raw", rawCommandText).
+		Debug("Got interaction")
+	addSpanAttributes(ctx, sp, event, attribute.String("command.raw", rawCommandText))
+
+	return GetCommandRequest(ctx, rawCommandText, id, commandFromTokensByName)
 }
 
 // SendErrorMessage sends an error message to a specified channel.
@@ -343,19 +445,28 @@ func SendMessage(ctx context.Context, a Adapter, channelID string, message strin
 // Send the contents of a response envelope to a specified channel. If
 // channelID is empty the value of envelope.Request.ChannelID will be used.
 func SendEnvelope(ctx context.Context, a Adapter, channelID string, envelope data.CommandResponseEnvelope, tt data.TemplateType) error {
+	_, err := sendEnvelope(ctx, a, channelID, envelope, tt)
+	return err
+}
+
+// sendEnvelope does the work of SendEnvelope. It also returns the text form
+// of the rendered message, even if sending it failed.
+func sendEnvelope(ctx context.Context, a Adapter, channelID string, envelope data.CommandResponseEnvelope, tt data.TemplateType) (string, error) {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "adapter.SendEnvelope")
 	defer sp.End()
 
 	e := adapterLogEntry(ctx, log.WithContext(ctx), a).WithField("message.type", tt)
 
+	renderStart := time.Now()
+
 	template, err := templates.Get(envelope.Request.Command, envelope.Request.Bundle, tt)
 	if err != nil {
 		e.WithError(err).Error("failed to get template")
 		if err := a.SendError(ctx, channelID, "Failed to Get Template", err); err != nil {
 			e.WithError(err).Error("break-glass send error failure!")
 		}
-		return err
+		return "", err
 	}
 
 	tf, err := templates.Transform(template, envelope)
@@ -364,7 +475,7 @@ func SendEnvelope(ctx context.Context, a Adapter, channelID string, envelope dat
 		if err := a.SendError(ctx, channelID, "Failed to Transform Template", err); err != nil {
 			e.WithError(err).Error("break-glass send error failure!")
 		}
-		return err
+		return "", err
 	}
 
 	elements, err := templates.EncodeElements(tf)
@@ -373,25 +484,49 @@ func SendEnvelope(ctx context.Context, a Adapter, channelID string, envelope dat
 		if err := a.SendError(ctx, channelID, "Failed to Transform Template", err); err != nil {
 			e.WithError(err).Error("break-glass send error failure!")
 		}
-		return err
+		return "", err
 	}
 
-	err = a.Send(ctx, channelID, elements)
+	rendered := elements.Alt()
+	envelope.Request.Timings.Record(data.StageRender, time.Since(renderStart))
+
+	sendStart := time.Now()
+	defer func() {
+		envelope.Request.Timings.Record(data.StageSend, time.Since(sendStart))
+	}()
+
+	ephemeral, err := sendOutput(ctx, a, channelID, envelope.Request, elements)
 	if err == nil {
-		return nil
+		return rendered, nil
+	}
+
+	// Falling back to alt text would leave the ephemeral output behind.
+	if ephemeral {
+		e.WithError(err).Error("failed to send ephemeral message to adapter")
+		if err := a.SendError(ctx, channelID, "Failed to Send Message", err); err != nil {
+			e.WithError(err).Error("break-glass send error failure!")
+		}
+		return rendered, err
+	}
+
+	// Falling back to alt text won't help if we're still being rate limited.
+	var rle *RateLimitedError
+	if errors.As(err, &rle) {
+		e.WithError(err).Error("failed to send message to adapter")
+		return rendered, err
 	}
 
 	e.WithError(err).Warn("failed to send rich message to adapter, falling back to alt text")
-	err = a.SendText(ctx, channelID, elements.Alt())
+	err = queueSendText(ctx, a, channelID, rendered)
 	if err != nil {
 		e.WithError(err).Error("failed to send message to adapter")
 		if err := a.SendError(ctx, channelID, "Failed to Send Message", err); err != nil {
 			e.WithError(err).Error("break-glass send error failure!")
 		}
-		return err
+		return rendered, err
 	}
 
-	return nil
+	return rendered, nil
 }
 
 // StartListening instructs all relays to establish connections, receives all
@@ -403,6 +538,7 @@ func StartListening(ctx context.Context) (<-chan data.CommandRequest, chan<- dat
 	commandResponses := make(chan data.CommandResponseEnvelope)
 
 	allEvents, adapterErrors := startAdapters(ctx)
+	pipelineRequests = commandRequests
 
 	// Start listening for events coming from the chat provider
 	go startProviderEventListening(commandRequests, allEvents, adapterErrors)
@@ -410,6 +546,21 @@ func StartListening(ctx context.Context) (<-chan data.CommandRequest, chan<- dat
 	// Start listening for responses coming back from the relay
 	go startRelayResponseListening(commandResponses, allEvents, adapterErrors)
 
+	// Periodically purge expired request payloads
+	if config.GetGlobalConfigs().RequestArchive.Retention > 0 {
+		go startArchivePurge(ctx)
+	}
+
+	// Resend responses that were stored but never delivered
+	if config.GetGlobalConfigs().Outbox.Enabled {
+		go startOutboxDispatch(ctx, commandResponses)
+	}
+
+	// Periodically leave channels that haven't been used in a while
+	if config.GetGortServerConfigs().ChannelInactivityTimeout > 0 {
+		go startInactivitySweep(ctx)
+	}
+
 	return commandRequests, commandResponses, adapterErrors
 }
 
@@ -423,11 +574,16 @@ type requestLog struct {
 
 // logAction defines a function that allows additional logging actions to be
 // passed to requestLog.Error.
-type logAction func(ctx context.Context, r *requestLog)
+type logAction func(ctx context.Context, r *requestLog, err error)
 
 // logUserMessage allows an error to be sent to the user via a chat message.
+// If the error has an associated error code, it's appended to the message.
 func logUserMessage(title, msg string) logAction {
-	return func(ctx context.Context, r *requestLog) {
+	return func(ctx context.Context, r *requestLog, err error) {
+		if c, ok := gerrs.CodeOf(err); ok {
+			msg = fmt.Sprintf("%s\n\nError code: %s", msg, c.Code)
+		}
+
 		SendErrorMessage(ctx, r.id.Adapter, r.id.ChatChannel.ID, title, msg)
 	}
 }
@@ -444,7 +600,7 @@ func (r *requestLog) Error(
 	telemetry.Errors().WithError(err).Commit(ctx)
 	r.le.WithError(err).Error(logMessage)
 	for _, action := range actions {
-		action(ctx, r)
+		action(ctx, r, err)
 	}
 	return fmt.Errorf("%v: %w", logMessage, err)
 }
@@ -466,7 +622,7 @@ func commandFromTokensByName(ctx context.Context, tokens []string) (*data.Comman
 		return nil, command.Command{}, err
 	}
 
-	cmdEntry, err := GetCommandEntry(ctx, cmdInput.Bundle, cmdInput.Command)
+	cmdEntry, err := getCommandEntryForChannel(ctx, cmdInput.Bundle, cmdInput.Command)
 	if err != nil {
 		return nil, command.Command{}, err
 	}
@@ -475,7 +631,14 @@ func commandFromTokensByName(ctx context.Context, tokens []string) (*data.Comman
 	tokens[0] = cmdEntry.Bundle.Name + ":" + cmdEntry.Command.Name
 
 	// TODO Set parse options based on the CommandEntry settings.
-	cmdInput, err = command.Parse(tokens)
+	parseOptions, err := optionDefaultParseOptions(ctx, cmdEntry)
+	if err != nil {
+		return nil, command.Command{}, err
+	}
+	parseOptions = append(parseOptions, profileParseOptions(cmdEntry)...)
+	parseOptions = append(parseOptions, optionPermissionParseOptions(cmdEntry)...)
+
+	cmdInput, err = command.Parse(tokens, parseOptions...)
 	if err != nil {
 		return nil, command.Command{}, err
 	}
@@ -495,11 +658,19 @@ func commandFromTokensByTrigger(ctx context.Context, tokens []string) (*data.Com
 	}
 
 	// TODO Set parse options based on the CommandEntry settings.
+	parseOptions, err := optionDefaultParseOptions(ctx, cmdEntry)
+	if err != nil {
+		return nil, command.Command{}, err
+	}
+	parseOptions = append(parseOptions, profileParseOptions(cmdEntry)...)
+	parseOptions = append(parseOptions, optionPermissionParseOptions(cmdEntry)...)
+
 	cmdInput, err := command.Parse(
 		append(
 			[]string{cmdEntry.Bundle.Name + ":" + cmdEntry.Command.Name},
 			tokens...,
 		),
+		parseOptions...,
 	)
 	if err != nil {
 		return nil, command.Command{}, err
@@ -522,10 +693,31 @@ func commandFromTokensByNameOrTrigger(ctx context.Context, tokens []string) (*da
 	return commandFromTokensByTrigger(ctx, tokens)
 }
 
-// parametersFromCommand converts parameters from a command.Command into
-// a string slice.
+// parametersFromCommand converts the options and parameters from a
+// command.Command into a string slice. Options come first, sorted by name,
+// followed by the parameters in their original order.
 func parametersFromCommand(cmd command.Command) []string {
 	var out []string
+
+	names := make([]string, 0, len(cmd.Options))
+	for name := range cmd.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		dashes := "--"
+		if len(name) == 1 {
+			dashes = "-"
+		}
+		out = append(out, dashes+name)
+
+		// Flags are represented as a true boolean; anything else is a value.
+		if b, ok := cmd.Options[name].Value.(types.BoolValue); !ok || !b.V {
+			out = append(out, cmd.Options[name].Value.String())
+		}
+	}
+
 	for _, p := range cmd.Parameters {
 		out = append(out, p.String())
 	}
@@ -547,17 +739,44 @@ func GetCommandRequest(
 	ctx, sp := tr.Start(ctx, "adapter.CommandByName")
 	defer sp.End()
 
+	start := time.Now()
+
 	da, err := dataaccess.Get()
 	if err != nil {
 		return nil, err
 	}
 
+	// Expand any aliases before the command is tokenized.
+	commandText, aliases, err := expandAliases(ctx, rawCommand, id)
+	if err != nil {
+		return nil, err
+	}
+
 	// Tokenize the raw command.
-	tokens, err := command.Tokenize(rawCommand)
+	tokens, err := command.Tokenize(commandText)
 	if err != nil {
 		return nil, fmt.Errorf("command tokenziation error")
 	}
 
+	// Unqualified commands are looked up in the channel's default bundle
+	// first, if it has one.
+	ctx = withRequestChannel(ctx, id)
+
+	// Everything from the message's receipt on counts against the request
+	// deadline, starting with the command lookup. The request itself keeps
+	// the context without it, since the relay applies the final deadline,
+	// which the command's own timeout may extend.
+	if id.Received.IsZero() {
+		id.Received = start
+	}
+
+	requestCtx := ctx
+	if deadline := requestDeadline(id.Received); !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
 	cmdEntry, cmdInput, commandLookupErr := fCommandFromTokens(ctx, tokens)
 	if commandLookupErr == nil && cmdEntry == nil {
 		return nil, nil
@@ -567,12 +786,39 @@ func GetCommandRequest(
 	if err != nil {
 		return nil, err
 	}
+	request.Context = requestCtx
+
+	request.InvocationText = rawCommand
+
+	if len(aliases) > 0 {
+		rl.le = rl.le.WithField("alias.names", aliases)
+	}
 
 	if len(tokens) == 0 {
 		msg := "Empty command received. Did you forget something?"
 		return nil, rl.Error(ctx, err, "command had no tokens", logUserMessage("Empty Command", msg))
 	}
 
+	// If there's no such command, the tokens may instead invoke a saved
+	// macro. Its expansion is looked up by name, and the rules evaluated
+	// against it, just as if the user had typed it.
+	if gerrs.Is(commandLookupErr, ErrNoSuchCommand) {
+		macro, expanded, err := expandMacro(ctx, tokens, id)
+		if macro != nil {
+			rl.le = rl.le.WithField("macro.name", macro.Name)
+		}
+
+		switch {
+		case errors.Is(err, data.ErrBadMacro):
+			return nil, rl.Error(ctx, err, "macro expansion error", logUserMessage("Macro Error", err.Error()))
+		case err != nil:
+			return nil, rl.Error(ctx, err, "macro lookup error", logUserMessage("Error", unexpectedError))
+		case macro != nil:
+			tokens = expanded
+			cmdEntry, cmdInput, commandLookupErr = commandFromTokensByName(ctx, tokens)
+		}
+	}
+
 	if commandLookupErr != nil {
 		err := commandLookupErr
 		switch {
@@ -591,18 +837,39 @@ func GetCommandRequest(
 		}
 	}
 
+	cmdInput, err = applyOptionDefaults(ctx, cmdInput, *cmdEntry, id)
+	if err != nil {
+		return nil, rl.Error(ctx, err, "option default lookup error", logUserMessage("Error", unexpectedError))
+	}
+
+	profileName, profile, cmdParams, err := selectProfile(cmdInput, *cmdEntry)
+	if err != nil {
+		msg := fmt.Sprintf("The command %s:%s has no such profile. Available profiles are: %s.",
+			cmdEntry.Bundle.Name, cmdEntry.Command.Name, strings.Join(profileNames(*cmdEntry), ", "))
+		return nil, rl.Error(ctx, err, "profile lookup error", logUserMessage("No Such Profile", msg))
+	}
+
+	request.Profile = profileName
+	request.Parameters = parametersFromCommand(cmdParams)
+	request.Timings.Record(data.StageLookup, time.Since(start))
 	rl.le = rl.le.WithField("command.name", cmdEntry.Command.Name).
-		WithField("command.params", cmdInput.Parameters.String())
-	request.Parameters = parametersFromCommand(cmdInput)
+		WithField("command.params", cmdEntry.Command.RedactParameters(request.Parameters, config.GetRedactPatterns()...).String())
 	da.RequestUpdate(ctx, request)
 
 	cmdFoundMessage := fmt.Sprintf("Executing command: %s", cmdEntry.Command.Name)
-	err = SendMessage(ctx, id.Adapter, id.ChatChannel.ID, cmdFoundMessage)
+	err = SendNonCriticalMessage(ctx, id.Adapter, id.ChatChannel.ID, cmdFoundMessage)
 	if err != nil {
 		rl.Error(ctx, err, "failed to send command acknowledgement")
 	}
 
 	request.CommandEntry = *cmdEntry
+
+	// A command's own timeout replaces the global one, measured from the
+	// same moment. The relay enforces it by stopping the worker.
+	if timeout, _ := cmdEntry.Command.TimeoutDuration(); timeout > 0 {
+		request.Deadline = request.Timestamp.Add(timeout)
+	}
+
 	da.RequestUpdate(ctx, request)
 
 	// Update log entry with cmd info
@@ -610,7 +877,9 @@ func GetCommandRequest(
 	rl.le.Debug("Found matching command+bundle")
 	addSpanAttributes(ctx, sp, *cmdEntry)
 
-	err = checkPermissions(ctx, id, cmdInput, *cmdEntry)
+	authStart := time.Now()
+	err = checkPermissions(ctx, id, cmdInput, *cmdEntry, profile)
+	request.Timings.Record(data.StageAuth, time.Since(authStart))
 	if err != nil {
 		switch {
 		case gerrs.Is(err, auth.ErrRuleLoadError):
@@ -620,6 +889,9 @@ func GetCommandRequest(
 				"For a command to be executable, it must have at least one rule.",
 				cmdEntry.Bundle.Name, cmdEntry.Command.Name)
 			return nil, rl.Error(ctx, err, "no rules defined", logUserMessage("No Rules Defined", msg))
+		case gerrs.Is(err, ErrNotAllowed) && profileName != "":
+			msg := fmt.Sprintf("You do not have the permissions to execute %s:%s with the %s profile.", cmdEntry.Bundle.Name, cmdEntry.Command.Name, profileName)
+			return nil, rl.Error(ctx, err, "permission denied", logUserMessage("Permission Denied", msg))
 		case gerrs.Is(err, ErrNotAllowed):
 			msg := fmt.Sprintf("You do not have the permissions to execute %s:%s.", cmdEntry.Bundle.Name, cmdEntry.Command.Name)
 			return nil, rl.Error(ctx, err, "permission denied", logUserMessage("Permission Denied", msg))
@@ -634,7 +906,10 @@ func GetCommandRequest(
 	return &request, nil
 }
 
-func checkPermissions(ctx context.Context, id RequestorIdentity, cmdInput command.Command, cmdEntry data.CommandEntry) error {
+// checkPermissions evaluates the command's rules against the user's
+// permissions, and checks that the user may use the selected profile, if any.
+// The profile option remains visible to rules as option["profile"].
+func checkPermissions(ctx context.Context, id RequestorIdentity, cmdInput command.Command, cmdEntry data.CommandEntry, profile *data.BundleCommandProfile) error {
 	da, err := dataaccess.Get()
 	if err != nil {
 		return err
@@ -645,8 +920,16 @@ func checkPermissions(ctx context.Context, id RequestorIdentity, cmdInput comman
 		return err
 	}
 
+	// Bundle-scoped permissions can't be resolved against a gort command's
+	// arguments, so they're treated as unscoped here. The REST API that
+	// the gort command calls enforces their scope.
+	ps := perms.Strings()
+	if cmdEntry.Bundle.Name == "gort" {
+		ps = auth.UnscopePermissions(ps)
+	}
+
 	allowed, err := auth.EvaluateCommandEntry(
-		perms.Strings(),
+		ps,
 		cmdEntry,
 		rules.EvaluationEnvironment{
 			"option": cmdInput.OptionsValues(),
@@ -656,7 +939,7 @@ func checkPermissions(ctx context.Context, id RequestorIdentity, cmdInput comman
 	if err != nil {
 		return err
 	}
-	if !allowed {
+	if !allowed || !hasProfilePermission(ps, cmdEntry.Bundle.Name, profile) {
 		return ErrNotAllowed
 	}
 	return nil
@@ -733,7 +1016,7 @@ func adapterLogEntry(ctx context.Context, e *log.Entry, obs ...interface{}) *log
 
 		case data.CommandRequest:
 			e = adapterLogEntry(ctx, e, o.CommandEntry, o.Bundle).
-				WithField("command.params", strings.Join(o.Parameters, " ")).
+				WithField("command.params", o.RedactedParameters(config.GetRedactPatterns()...).String()).
 				WithField("request.id", o.RequestID)
 
 		case trace.Span:
@@ -826,7 +1109,7 @@ func addSpanAttributes(ctx context.Context, sp trace.Span, obs ...interface{}) {
 		case data.CommandRequest:
 			addSpanAttributes(ctx, sp, o.CommandEntry, o.Bundle)
 			attr = append(attr,
-				attribute.String("command.params", strings.Join(o.Parameters, " ")),
+				attribute.String("command.params", o.RedactedParameters(config.GetRedactPatterns()...).String()),
 				attribute.Int64("request.id", o.RequestID),
 			)
 
@@ -874,7 +1157,7 @@ func buildRequestorIdentity(ctx context.Context, adapter Adapter, channelId, use
 		WithField("userId", userId)
 
 	if channelId != "" {
-		id.ChatChannel, err = adapter.GetChannelInfo(channelId)
+		id.ChatChannel, err = getChannelInfo(ctx, adapter, channelId)
 		switch {
 		case err == nil:
 			le = adapterLogEntry(ctx, le, *id.ChatChannel)
@@ -887,7 +1170,7 @@ func buildRequestorIdentity(ctx context.Context, adapter Adapter, channelId, use
 	}
 
 	if userId != "" {
-		id.ChatUser, err = adapter.GetUserInfo(userId)
+		id.ChatUser, err = getUserInfo(ctx, adapter, userId)
 		switch {
 		case err == nil:
 			le = adapterLogEntry(ctx, le, *id.ChatUser)
@@ -923,17 +1206,35 @@ func buildRequestorIdentity(ctx context.Context, adapter Adapter, channelId, use
 	return id, nil
 }
 
+// requestDeadline returns the deadline of a request for a message received
+// at the given time, or the zero time if there's no command timeout.
+func requestDeadline(received time.Time) time.Time {
+	if timeout := config.GetGlobalConfigs().CommandTimeout; timeout > 0 {
+		return received.Add(timeout)
+	}
+	return time.Time{}
+}
+
 // buildAndBeginRequest sets up a data.CommandRequest.
 // User information is verified and populated as needed.
 // The user is only required to exist, permission checks take place later.
 func buildAndBeginRequest(ctx context.Context, id RequestorIdentity) (data.CommandRequest, RequestorIdentity, requestLog, error) {
 	request := data.CommandRequest{
-		Adapter:   id.Adapter.GetName(),
-		ChannelID: id.ChatChannel.ID,
-		Context:   ctx,
-		Timestamp: time.Now(),
-		UserEmail: id.ChatUser.Email,
-		UserID:    id.ChatUser.ID,
+		Adapter:         id.Adapter.GetName(),
+		ChannelID:       id.ChatChannel.ID,
+		ChannelName:     id.ChatChannel.Name,
+		Context:         ctx,
+		MessageID:       id.MessageID,
+		Deadline:        requestDeadline(id.Received),
+		Timestamp:       id.Received,
+		Timings:         data.StageTimings{},
+		UserDisplayName: id.ChatUser.DisplayName,
+		UserEmail:       id.ChatUser.Email,
+		UserID:          id.ChatUser.ID,
+	}
+
+	if request.UserDisplayName == "" {
+		request.UserDisplayName = id.ChatUser.Name
 	}
 
 	if id.GortUser != nil {
@@ -941,6 +1242,11 @@ func buildAndBeginRequest(ctx context.Context, id RequestorIdentity) (data.Comma
 		request.UserName = id.GortUser.Username
 	}
 
+	request.Locale = id.ChatUser.Locale
+	if request.Locale == "" {
+		request.Locale = config.GetGlobalConfigs().Locale
+	}
+
 	r := requestLog{
 		request: &request,
 		id:      &id,
@@ -1109,6 +1415,7 @@ func handleIncomingEvent(event *ProviderEvent, commandRequests chan<- data.Comma
 	switch ev := event.Data.(type) {
 	case *ConnectedEvent:
 		OnConnected(ctx, event, ev)
+		catchUp(ctx, event, commandRequests, adapterErrors)
 
 	case *DisconnectedEvent:
 		// Do nothing.
@@ -1119,6 +1426,7 @@ func handleIncomingEvent(event *ProviderEvent, commandRequests chan<- data.Comma
 	case *ChannelMessageEvent:
 		request, err := OnChannelMessage(ctx, event, ev)
 		if request != nil {
+			markChannelActive(ctx, event.Adapter.GetName(), ev.ChannelID)
 			commandRequests <- *request
 		}
 		if err != nil {
@@ -1134,6 +1442,21 @@ func handleIncomingEvent(event *ProviderEvent, commandRequests chan<- data.Comma
 			adapterErrors <- err
 		}
 
+	case *InteractionEvent:
+		request, err := OnInteraction(ctx, event, ev)
+		if request != nil {
+			commandRequests <- *request
+		}
+		if err != nil {
+			adapterErrors <- err
+		}
+
+	case *ChannelChangedEvent:
+		OnChannelChanged(ctx, event, ev)
+
+	case *UserChangedEvent:
+		OnUserChanged(ctx, event, ev)
+
 	case *ErrorEvent:
 		adapterErrors <- ev
 
@@ -1152,6 +1475,9 @@ func startAdapters(ctx context.Context) (<-chan *ProviderEvent, chan error) {
 
 		go func(adapter Adapter) {
 			for event := range adapter.Listen(ctx) {
+				if event.Received.IsZero() {
+					event.Received = time.Now()
+				}
 				allEvents <- event
 			}
 		}(a)
@@ -1172,21 +1498,76 @@ func startRelayResponseListening(responses <-chan data.CommandResponseEnvelope,
 	allEvents <-chan *ProviderEvent, adapterErrors chan<- error) {
 
 	for envelope := range responses {
-		adapter, err := GetAdapter(envelope.Request.Adapter)
-		if err != nil {
-			adapterErrors <- err
-			continue
+		ctx := context.Background()
+		if handleResponse(ctx, envelope, adapterErrors) {
+			markDelivered(ctx, envelope)
 		}
+	}
+}
 
-		tt := data.Command
-		if envelope.Data.ExitCode != 0 {
-			tt = data.CommandError
-		}
+// handleResponse sends a command response to the channel it belongs to. It
+// returns true if the response should be marked as delivered: it was sent,
+// or its delivery failed and it was dead-lettered. It returns false if it
+// wasn't sent, either because it already had been or because its adapter
+// isn't available.
+func handleResponse(ctx context.Context, envelope data.CommandResponseEnvelope, adapterErrors chan<- error) bool {
+	if returnSelfTestResponse(envelope) {
+		return true
+	}
 
-		ctx := context.Background()
-		channelID := envelope.Request.ChannelID
-		if err := SendEnvelope(ctx, adapter, channelID, envelope, tt); err != nil {
+	if alreadyDelivered(ctx, envelope) {
+		log.WithField("request.id", envelope.Request.RequestID).
+			Debug("Response already delivered; not sending again")
+		return false
+	}
+
+	adapter, err := GetAdapter(envelope.Request.Adapter)
+	if err != nil {
+		adapterErrors <- err
+		return false
+	}
+
+	tt := data.Command
+	switch {
+	case envelope.Data.TimedOut:
+		tt = data.CommandTimeout
+	case envelope.Data.ExitCode != 0:
+		tt = data.CommandError
+	}
+
+	filterOutput(ctx, &envelope)
+	trackFailure(envelope)
+
+	if envelope.Request.Command.SecretOutput {
+		if err := deliverSecretOutput(ctx, adapter, envelope); err != nil {
+			log.WithError(err).
+				WithField("request.id", envelope.Request.RequestID).
+				Error("Failed to deliver secret output")
+			SendErrorMessage(ctx, adapter, envelope.Request.ChannelID,
+				"Failed to Deliver Output", "The output of this command is secret, and couldn't be delivered privately.")
 			adapterErrors <- err
 		}
+
+		finishRequestTimings(ctx, envelope.Request)
+		archiveRequest(ctx, envelope, "")
+		return true
+	}
+
+	formatANSI(adapter, &envelope)
+	reactToResponse(ctx, adapter, envelope)
+
+	channelID := envelope.Request.ChannelID
+	rendered, err := sendEnvelope(ctx, adapter, channelID, envelope, tt)
+	if err != nil {
+		// A rendered message means that only the send itself failed,
+		// so the message can be kept for redelivery.
+		if rendered != "" {
+			deadLetter(ctx, adapter, channelID, envelope, rendered, err)
+		}
+		adapterErrors <- err
 	}
+
+	finishRequestTimings(ctx, envelope.Request)
+	archiveRequest(ctx, envelope, rendered)
+	return true
 }
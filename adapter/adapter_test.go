@@ -11,6 +11,7 @@ import (
 	"github.com/getgort/gort/dataaccess"
 	"github.com/getgort/gort/service"
 	"github.com/getgort/gort/templates"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMain(m *testing.M) {
@@ -106,6 +107,43 @@ func TestChannelMessage(t *testing.T) {
 
 }
 
+func TestChannelMessageInvocationContext(t *testing.T) {
+	result, err := OnChannelMessage(
+		context.Background(),
+		&ProviderEvent{
+			EventType: EventChannelMessage,
+			Info: &Info{
+				Provider: &ProviderInfo{
+					Type: "test",
+					Name: "provider",
+				},
+			},
+			Adapter: &testAdapter{},
+		},
+		&ChannelMessageEvent{
+			ChannelID: "mychannel",
+			Text:      `!test:cmd "arg 1"  arg2`,
+			UserID:    "user",
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result == nil {
+		t.Fatal("expected a request")
+	}
+
+	if expected := `test:cmd "arg 1"  arg2`; result.InvocationText != expected {
+		t.Errorf("expected invocation text %q, got %q", expected, result.InvocationText)
+	}
+	if result.ChannelName != "mychannel" {
+		t.Errorf("expected channel name %q, got %q", "mychannel", result.ChannelName)
+	}
+	if result.UserDisplayName != "user" {
+		t.Errorf("expected user display name %q, got %q", "user", result.UserDisplayName)
+	}
+}
+
 func TestDirectMessage(t *testing.T) {
 	var tests = []struct {
 		name            string
@@ -190,6 +228,384 @@ func TestDirectMessage(t *testing.T) {
 
 }
 
+func TestChannelMessageOptionDefaults(t *testing.T) {
+	ctx := context.Background()
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := []data.OptionDefault{
+		{Bundle: "test", Command: "cmd", Layer: data.LayerBundle, Option: "v"},
+		{Bundle: "test", Command: "cmd", Layer: data.LayerBundle, Option: "region", Value: "us-west-2"},
+		{Bundle: "test", Command: "cmd", Layer: data.LayerRoom, Owner: "mychannel", Option: "region", Value: "us-east-1"},
+	}
+	for _, d := range defaults {
+		if err := da.OptionDefaultSet(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+		defer da.OptionDefaultDelete(ctx, d.Layer, d.Owner, d.Bundle, d.Command, d.Option)
+	}
+
+	var tests = []struct {
+		message  string
+		expected string
+	}{
+		{
+			message:  "!test:cmd arg1",
+			expected: "test:cmd --region us-east-1 -v arg1",
+		},
+		{
+			message:  "!test:cmd --region eu-west-1 arg1",
+			expected: "test:cmd --region eu-west-1 -v arg1",
+		},
+	}
+
+	for _, test := range tests {
+		result, err := OnChannelMessage(
+			ctx,
+			&ProviderEvent{
+				EventType: EventChannelMessage,
+				Info:      &Info{Provider: &ProviderInfo{Type: "test", Name: "provider"}},
+				Adapter:   &testAdapter{},
+			},
+			&ChannelMessageEvent{ChannelID: "mychannel", Text: test.message, UserID: "user"},
+		)
+		if err != nil {
+			t.Errorf("%v", err)
+			continue
+		}
+		if result == nil || result.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, result)
+		}
+	}
+}
+
+func TestChannelMessageDefaultBundle(t *testing.T) {
+	ctx := context.Background()
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundles := []data.Bundle{
+		{
+			GortBundleVersion: 1,
+			Name:              "other",
+			Version:           "1.0.0",
+			Description:       "a bundle with a conflicting command",
+			Enabled:           true,
+			Commands:          map[string]*data.BundleCommand{"cmd": {Name: "cmd", Rules: []string{"allow"}}},
+		},
+		{
+			GortBundleVersion: 1,
+			Name:              "extra",
+			Version:           "1.0.0",
+			Description:       "a bundle with a unique command",
+			Enabled:           true,
+			Commands:          map[string]*data.BundleCommand{"solo": {Name: "solo", Rules: []string{"allow"}}},
+		},
+	}
+	for _, b := range bundles {
+		if err := da.BundleCreate(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		defer da.BundleDelete(ctx, b.Name, b.Version)
+	}
+	defer da.ChannelSettingsDelete(ctx, "testAdapter", "mychannel")
+
+	var tests = []struct {
+		defaultBundle string
+		message       string
+		expected      string
+		err           bool
+	}{
+		{message: "!cmd arg1", err: true},
+		{defaultBundle: "other", message: "!cmd arg1", expected: "other:cmd arg1"},
+		{defaultBundle: "test", message: "!cmd arg1", expected: "test:cmd arg1"},
+		{defaultBundle: "other", message: "!test:cmd arg1", expected: "test:cmd arg1"},
+		{defaultBundle: "other", message: "!solo arg1", expected: "extra:solo arg1"},
+	}
+
+	for _, test := range tests {
+		err := da.ChannelSettingsSet(ctx, data.ChannelSettings{Adapter: "testAdapter", ChannelID: "mychannel", DefaultBundle: test.defaultBundle})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := OnChannelMessage(
+			ctx,
+			&ProviderEvent{
+				EventType: EventChannelMessage,
+				Info:      &Info{Provider: &ProviderInfo{Type: "test", Name: "provider"}},
+				Adapter:   &testAdapter{},
+			},
+			&ChannelMessageEvent{ChannelID: "mychannel", Text: test.message, UserID: "user"},
+		)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q with default %q: expected an error, got %q", test.message, test.defaultBundle, result)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q with default %q: %v", test.message, test.defaultBundle, err)
+			continue
+		}
+		if result == nil || result.String() != test.expected {
+			t.Errorf("%q with default %q: expected %q, got %q", test.message, test.defaultBundle, test.expected, result)
+		}
+	}
+}
+
+func TestChannelMessageMacros(t *testing.T) {
+	ctx := context.Background()
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	macros := []data.Macro{
+		{Name: "greet", Layer: data.LayerUser, Owner: "user", Command: "test:cmd hello {{.who}}"},
+		{Name: "greet", Layer: data.LayerGroup, Owner: "admin", Command: "test:cmd hi {{.who}}"},
+		{Name: "loop", Layer: data.LayerUser, Owner: "user", Command: "greet who=loop"},
+	}
+	for _, m := range macros {
+		if err := da.MacroSave(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+		defer da.MacroDelete(ctx, m.Layer, m.Owner, m.Name)
+	}
+
+	var tests = []struct {
+		message  string
+		expected string
+		err      bool
+	}{
+		{
+			message:  "!greet who=world",
+			expected: "test:cmd hello world",
+		},
+		{
+			message: "!greet",
+			err:     true,
+		},
+		{
+			message: "!greet world",
+			err:     true,
+		},
+		{
+			// Macros are only expanded once.
+			message: "!loop",
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		result, err := OnChannelMessage(
+			ctx,
+			&ProviderEvent{
+				EventType: EventChannelMessage,
+				Info:      &Info{Provider: &ProviderInfo{Type: "test", Name: "provider"}},
+				Adapter:   &testAdapter{},
+			},
+			&ChannelMessageEvent{ChannelID: "mychannel", Text: test.message, UserID: "user"},
+		)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", test.message, result)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.message, err)
+			continue
+		}
+		if result == nil || result.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, result)
+		}
+	}
+}
+
+func TestChannelMessageAliases(t *testing.T) {
+	ctx := context.Background()
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aliases := []data.Alias{
+		{Name: "dep", Command: "test:cmd prod"},
+		{Name: "mine", Command: "test:cmd global"},
+		{Name: "mine", Owner: "user", Command: "test:cmd mine"},
+		{Name: "chain", Owner: "user", Command: "dep chained"},
+		{Name: "ping", Owner: "user", Command: "pong"},
+		{Name: "pong", Owner: "user", Command: "ping"},
+	}
+	for _, a := range aliases {
+		if err := da.AliasSave(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+		defer da.AliasDelete(ctx, a.Owner, a.Name)
+	}
+
+	var tests = []struct {
+		message  string
+		expected string
+		err      bool
+	}{
+		{
+			message:  "!dep",
+			expected: "test:cmd prod",
+		},
+		{
+			message:  "!dep extra",
+			expected: "test:cmd prod extra",
+		},
+		{
+			// A user's own aliases take precedence over global ones.
+			message:  "!mine",
+			expected: "test:cmd mine",
+		},
+		{
+			message:  "!chain",
+			expected: "test:cmd prod chained",
+		},
+		{
+			// Each alias is only expanded once.
+			message: "!ping",
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		result, err := OnChannelMessage(
+			ctx,
+			&ProviderEvent{
+				EventType: EventChannelMessage,
+				Info:      &Info{Provider: &ProviderInfo{Type: "test", Name: "provider"}},
+				Adapter:   &testAdapter{},
+			},
+			&ChannelMessageEvent{ChannelID: "mychannel", Text: test.message, UserID: "user"},
+		)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", test.message, result)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.message, err)
+			continue
+		}
+		if result == nil || result.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, result)
+		}
+	}
+}
+
+func TestChannelMessagePipeline(t *testing.T) {
+	ctx := context.Background()
+
+	var tests = []struct {
+		message  string
+		expected []string
+		err      bool
+	}{
+		{
+			message:  "!test:cmd foo",
+			expected: []string{"test:cmd foo"},
+		},
+		{
+			message:  "!test:cmd foo | test:cmd bar | test:cmd",
+			expected: []string{"test:cmd foo", "test:cmd bar", "test:cmd "},
+		},
+		{
+			// Quoted pipes are parameters.
+			message:  `!test:cmd "foo | bar"`,
+			expected: []string{`test:cmd "foo | bar"`},
+		},
+		{
+			message: "!test:cmd foo |",
+			err:     true,
+		},
+		{
+			message: "!test:cmd foo | nope:nope",
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		result, err := OnChannelMessage(
+			ctx,
+			&ProviderEvent{
+				EventType: EventChannelMessage,
+				Info:      &Info{Provider: &ProviderInfo{Type: "test", Name: "provider"}},
+				Adapter:   &testAdapter{},
+			},
+			&ChannelMessageEvent{ChannelID: "mychannel", Text: test.message, UserID: "user"},
+		)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", test.message, result)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.message, err)
+			continue
+		}
+
+		var actual []string
+		for r := result; r != nil; r = r.Next {
+			actual = append(actual, r.String())
+
+			if r.InvocationText != test.message[1:] {
+				t.Errorf("%s: expected invocation text %q, got %q", test.message, test.message[1:], r.InvocationText)
+			}
+		}
+		assert.Equal(t, test.expected, actual, test.message)
+	}
+}
+
+func TestChannelMessageTriggerChannels(t *testing.T) {
+	var tests = []struct {
+		channel  string
+		message  string
+		expected string
+	}{
+		{channel: "ops", message: "JIRA-1234", expected: "test:cmd JIRA-1234"},
+		{channel: "mychannel", message: "JIRA-1234"},
+		{channel: "mychannel", message: "run this command", expected: "test:cmd run this command"},
+		{channel: "ops", message: "run this command", expected: "test:cmd run this command"},
+	}
+
+	for _, test := range tests {
+		result, err := OnChannelMessage(
+			context.Background(),
+			&ProviderEvent{
+				EventType: EventChannelMessage,
+				Info:      &Info{Provider: &ProviderInfo{Type: "test", Name: "provider"}},
+				Adapter:   &testAdapter{},
+			},
+			&ChannelMessageEvent{ChannelID: test.channel, Text: test.message, UserID: "user"},
+		)
+		if !assert.NoError(t, err, "%s in %s", test.message, test.channel) {
+			continue
+		}
+
+		if test.expected == "" {
+			assert.Nil(t, result, "%s in %s", test.message, test.channel)
+		} else if assert.NotNil(t, result, "%s in %s", test.message, test.channel) {
+			assert.Equal(t, test.expected, result.String())
+		}
+	}
+}
+
 func setupGort() error {
 	// Init Gort
 	err := config.Initialize("../testing/config/no-database.yml")
@@ -229,6 +645,10 @@ var testBundle = data.Bundle{
 				{
 					Match: "com+and",
 				},
+				{
+					Match:    `^JIRA-\d+$`,
+					Channels: []string{"#ops"},
+				},
 			},
 			Rules: []string{"allow"},
 		},
@@ -293,3 +713,60 @@ func (t *testAdapter) SendText(ctx context.Context, channelID string, message st
 func (t *testAdapter) SendError(ctx context.Context, channelID string, title string, err error) error {
 	panic("not implemented") // TODO: Implement
 }
+
+func TestFilterOutput(t *testing.T) {
+	req := data.CommandRequest{}
+	req.Bundle.OutputFilters = data.OutputFilters{Redact: []string{`secret-\w+`}, MaxLines: 1}
+
+	envelope := data.NewCommandResponseEnvelope(req, data.WithResponseLines([]string{"key: secret-abc", "more"}))
+	filterOutput(context.Background(), &envelope)
+
+	assert.Equal(t, []string{"key: [REDACTED]", "[1 more lines truncated]"}, envelope.Response.Lines)
+	assert.Equal(t, "key: [REDACTED]\n[1 more lines truncated]", envelope.Response.Out)
+	assert.Equal(t, envelope.Response.Out, envelope.Payload)
+}
+
+func BenchmarkCommandFromTokensByName(b *testing.B) {
+	ctx := context.Background()
+
+	for i := 0; i < b.N; i++ {
+		// The lookup rewrites the first token, so each iteration gets a
+		// fresh slice.
+		tokens := []string{"test:cmd", "arg1", "arg2"}
+
+		if _, _, err := commandFromTokensByName(ctx, tokens); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkChannelMessage measures the request hot path: tokenization,
+// command lookup, and authorization.
+func BenchmarkChannelMessage(b *testing.B) {
+	ctx := context.Background()
+	event := &ProviderEvent{
+		EventType: EventChannelMessage,
+		Info: &Info{
+			Provider: &ProviderInfo{
+				Type: "test",
+				Name: "provider",
+			},
+		},
+		Adapter: &testAdapter{},
+	}
+	message := &ChannelMessageEvent{
+		ChannelID: "mychannel",
+		Text:      "!test:cmd arg1 arg2",
+		UserID:    "user",
+	}
+
+	for i := 0; i < b.N; i++ {
+		result, err := OnChannelMessage(ctx, event, message)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if result == nil {
+			b.Fatal("expected a request")
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+// expandAliases replaces an alias at the start of rawCommand with its
+// command, repeatedly, for as long as the result begins with another alias.
+// Only aliases visible to the requesting user (their own, and global ones)
+// are expanded. To guard against aliases that refer to one another, each
+// alias is expanded at most once, and no more than data.MaxAliasDepth are
+// expanded in all. It returns the expanded command and the names of the
+// aliases that were expanded, in order.
+func expandAliases(ctx context.Context, rawCommand string, id RequestorIdentity) (string, []string, error) {
+	var username string
+	if id.GortUser != nil {
+		username = id.GortUser.Username
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return rawCommand, nil, err
+	}
+
+	seen := map[string]bool{}
+	var expanded []string
+
+	for len(expanded) < data.MaxAliasDepth {
+		name := data.AliasName(rawCommand)
+		if name == "" || seen[name] {
+			break
+		}
+
+		aliases, err := da.AliasList(ctx, name)
+		if err != nil {
+			return rawCommand, expanded, err
+		}
+
+		alias, ok := data.ResolveAlias(aliases, name, username)
+		if !ok {
+			break
+		}
+
+		seen[name] = true
+		expanded = append(expanded, name)
+		rawCommand = alias.Expand(rawCommand)
+	}
+
+	return rawCommand, expanded, nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/telemetry"
+)
+
+// announceInterval is the minimum time between announcement messages sent
+// through the same adapter, to stay clear of chat provider rate limits.
+const announceInterval = 250 * time.Millisecond
+
+// ErrNoAnnouncementTargets is returned by Announce if an announcement
+// specifies neither AllChannels nor any Channels.
+var ErrNoAnnouncementTargets = errors.New("announcement has no target channels")
+
+// Announce sends an announcement message through the selected adapters (or
+// all adapters, if none are selected). If AllChannels is set, it's sent to
+// every channel the adapter is present in; otherwise only to those whose ID
+// or name is in Channels. Failures to send to an individual adapter or channel
+// don't stop the announcement; they're reported in the result.
+func Announce(ctx context.Context, a rest.Announcement) (rest.AnnouncementResult, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.Announce")
+	defer sp.End()
+
+	result := rest.AnnouncementResult{Sent: []rest.AnnouncementTarget{}}
+
+	if !a.AllChannels && len(a.Channels) == 0 {
+		return result, ErrNoAnnouncementTargets
+	}
+
+	names := a.Adapters
+	if len(names) == 0 {
+		for name := range adapterLookup {
+			names = append(names, name)
+		}
+	} else {
+		names = append([]string{}, names...)
+	}
+	sort.Strings(names)
+
+	channels := map[string]bool{}
+	for _, c := range a.Channels {
+		channels[strings.TrimPrefix(c, "#")] = true
+	}
+
+	for _, name := range names {
+		ad, err := GetAdapter(name)
+		if err != nil {
+			result.Failed = append(result.Failed, rest.AnnouncementTarget{
+				Adapter: name,
+				Error:   err.Error(),
+			})
+			continue
+		}
+
+		present, err := ad.GetPresentChannels()
+		if err != nil {
+			result.Failed = append(result.Failed, rest.AnnouncementTarget{
+				Adapter: name,
+				Error:   err.Error(),
+			})
+			continue
+		}
+
+		var last time.Time
+
+		for _, c := range present {
+			if !a.AllChannels && !channels[c.ID] && !channels[c.Name] {
+				continue
+			}
+
+			if wait := announceInterval - time.Since(last); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return result, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+			last = time.Now()
+
+			target := rest.AnnouncementTarget{
+				Adapter:     name,
+				ChannelID:   c.ID,
+				ChannelName: c.Name,
+			}
+
+			if err := SendMessage(ctx, ad, c.ID, a.Message); err != nil {
+				log.WithError(err).
+					WithField("adapter.name", name).
+					WithField("channel.id", c.ID).
+					Warn("Failed to send announcement")
+
+				target.Error = err.Error()
+				result.Failed = append(result.Failed, target)
+				continue
+			}
+
+			result.Sent = append(result.Sent, target)
+		}
+	}
+
+	sp.SetAttributes(
+		attribute.Int("announce.sent", len(result.Sent)),
+		attribute.Int("announce.failed", len(result.Failed)),
+	)
+
+	return result, nil
+}
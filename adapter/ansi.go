@@ -0,0 +1,54 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"github.com/getgort/gort/data"
+)
+
+// MarkupProvider may be implemented by an Adapter whose chat provider doesn't
+// use data.StandardMarkup. It's used when converting ANSI-formatted output.
+type MarkupProvider interface {
+	Markup() data.Markup
+}
+
+// formatANSI strips or converts ANSI escape sequences in the envelope's
+// response lines, according to the command's "ansi" setting.
+func formatANSI(a Adapter, envelope *data.CommandResponseEnvelope) {
+	var lines []string
+
+	switch envelope.Request.Command.ANSI {
+	case data.ANSIStrip:
+		lines = make([]string, len(envelope.Response.Lines))
+		for i, line := range envelope.Response.Lines {
+			lines[i] = data.StripANSI(line)
+		}
+
+	case data.ANSIConvert:
+		markup := data.StandardMarkup
+		if mp, ok := a.(MarkupProvider); ok {
+			markup = mp.Markup()
+		}
+		lines = data.ConvertANSI(envelope.Response.Lines, markup)
+
+	default:
+		return
+	}
+
+	data.WithResponseLines(lines)(envelope)
+	envelope.Response.Markdown = envelope.Request.Command.ANSI == data.ANSIConvert
+}
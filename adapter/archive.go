@@ -0,0 +1,114 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// archivePurgeInterval is how often expired request payloads are purged.
+const archivePurgeInterval = 10 * time.Minute
+
+// archiveRequest stores a request's response payload, if
+// global.request_archive.retention is set. Failures are logged but
+// otherwise ignored.
+func archiveRequest(ctx context.Context, envelope data.CommandResponseEnvelope, rendered string) {
+	c := config.GetGlobalConfigs().RequestArchive
+	if c.Retention <= 0 || envelope.Request.RequestID == 0 {
+		return
+	}
+
+	payload := data.RequestPayload{
+		ArchivedAt: time.Now().UTC(),
+		Title:      envelope.Response.Title,
+		ErrorCode:  envelope.Data.ErrorCode,
+		Partial:    envelope.Data.Partial,
+	}
+
+	// Secret output is only ever available through its one-time link.
+	if !c.OmitOutput && !envelope.Request.Command.SecretOutput {
+		payload.Output = envelope.Response.Lines
+		payload.Rendered = rendered
+
+		max := c.MaxSize
+		if max <= 0 {
+			max = data.DefaultRequestArchiveMaxSize
+		}
+		payload.Truncate(max)
+	}
+
+	le := log.WithField("request.id", envelope.Request.RequestID)
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Warn("Failed to get data access; request payload not archived")
+		return
+	}
+
+	if err := da.RequestArchive(ctx, envelope.Request.RequestID, payload); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to archive request payload")
+	}
+}
+
+// startArchivePurge periodically deletes request payloads older than
+// global.request_archive.retention until the context is cancelled.
+func startArchivePurge(ctx context.Context) {
+	ticker := time.NewTicker(archivePurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purgeArchive(ctx)
+		}
+	}
+}
+
+func purgeArchive(ctx context.Context) {
+	retention := config.GetGlobalConfigs().RequestArchive.Retention
+	if retention <= 0 {
+		return
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get data access; request payloads not purged")
+		return
+	}
+
+	n, err := da.RequestArchivePurge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).Error("Failed to purge request payloads")
+		return
+	}
+
+	if n > 0 {
+		log.WithField("count", n).Debug("Purged expired request payloads")
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// AttachmentDownloader is implemented by adapters that can download the
+// files attached to messages.
+type AttachmentDownloader interface {
+	// DownloadAttachment writes the content of an attached file to w.
+	DownloadAttachment(ctx context.Context, attachment Attachment, w io.Writer) error
+}
+
+// attachInputFile downloads the file attached to the message that invoked
+// the request, and sets it as the request's input file. Only the first
+// attachment is used. Attachments are ignored if input files are disabled
+// or the adapter can't download them. If the download fails the user is
+// told why, the request is closed, and an error is returned.
+func attachInputFile(ctx context.Context, request *data.CommandRequest, id RequestorIdentity, attachments []Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	c := config.GetGlobalConfigs().InputFiles
+	if c.Disabled {
+		return nil
+	}
+
+	le := adapterLogEntry(ctx, nil, id, *request).
+		WithField("attachment.name", attachments[0].Name).
+		WithField("attachments", len(attachments))
+
+	downloader, ok := id.Adapter.(AttachmentDownloader)
+	if !ok {
+		le.Debug("Adapter can't download attachments; ignoring them")
+		return nil
+	}
+
+	f, err := downloadAttachment(ctx, downloader, attachments[0], c.MaxSizeOrDefault())
+	if err != nil {
+		var msg string
+		if gerrs.Is(err, ErrInputFileTooLarge) {
+			msg = fmt.Sprintf("The attached file is too large. The largest file that Gort accepts is %d bytes.", c.MaxSizeOrDefault())
+		} else {
+			telemetry.Errors().WithError(err).Commit(ctx)
+			msg = "Gort couldn't download the attached file. Please try again."
+		}
+
+		le.WithError(err).Warn("Failed to download attachment")
+		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Attachment Error", msg)
+
+		if da, derr := dataaccess.Get(); derr == nil {
+			da.RequestError(ctx, *request, err)
+		}
+
+		return err
+	}
+
+	le.WithField("attachment.size", len(f.Data)).Debug("Downloaded attachment")
+	request.InputFile = f
+
+	return nil
+}
+
+// downloadAttachment downloads an attachment, failing with
+// ErrInputFileTooLarge if it's larger than max bytes.
+func downloadAttachment(ctx context.Context, d AttachmentDownloader, a Attachment, max int) (*data.InputFile, error) {
+	if a.Size > int64(max) {
+		return nil, gerrs.Wrap(ErrInputFileTooLarge, fmt.Errorf("%s is %d bytes", a.Name, a.Size))
+	}
+
+	buf := &bytes.Buffer{}
+	w := &limitedWriter{w: buf, remaining: max}
+	if err := d.DownloadAttachment(ctx, a, w); err != nil {
+		if w.exceeded {
+			return nil, gerrs.Wrap(ErrInputFileTooLarge, fmt.Errorf("%s is over %d bytes", a.Name, max))
+		}
+		return nil, err
+	}
+
+	return &data.InputFile{
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// limitedWriter is a writer that fails once more than a set number of bytes
+// have been written to it.
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+	exceeded  bool
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.remaining {
+		l.exceeded = true
+		return 0, ErrInputFileTooLarge
+	}
+
+	l.remaining -= len(p)
+	return l.w.Write(p)
+}
+
+// DownloadURL is a helper for adapters that implement AttachmentDownloader:
+// it writes the body of an HTTP GET of the url to w. If token is non-empty
+// it's sent as a bearer token.
+func DownloadURL(ctx context.Context, url, token string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,356 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/command"
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+var (
+	// canaryMutex guards canaryStatuses, which are updated by the canary
+	// loop and read by the REST service, and canaryDigest.
+	canaryMutex sync.Mutex
+
+	canaryStatuses = map[string]*rest.CanaryStatus{}
+
+	// canaryDigest accumulates each canary's results for the next digest,
+	// if digests are enabled.
+	canaryDigest = map[string]*data.DigestEntry{}
+)
+
+// CanaryStatuses returns the recent results of each configured canary,
+// sorted by name. Canaries that haven't run yet are included.
+func CanaryStatuses(ctx context.Context) ([]rest.CanaryStatus, error) {
+	canaryMutex.Lock()
+	defer canaryMutex.Unlock()
+
+	list := []rest.CanaryStatus{}
+	for _, c := range config.GetGlobalConfigs().Canaries.Commands {
+		if s, ok := canaryStatuses[c.Name]; ok {
+			list = append(list, *s)
+		} else {
+			list = append(list, rest.CanaryStatus{Name: c.Name, Command: c.Command})
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+// StartCanaries runs each canary defined in global.canaries through the
+// command pipeline on the configured schedule, until the context is
+// cancelled. It returns immediately if no canaries are defined.
+func StartCanaries(ctx context.Context) {
+	cc := config.GetGlobalConfigs().Canaries
+	if len(cc.Commands) == 0 {
+		return
+	}
+
+	interval := cc.Interval
+	if interval <= 0 {
+		interval = data.DefaultCanaryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// A nil channel is never ready, so without digests the case is ignored.
+	var digests <-chan time.Time
+	if cc.Digest {
+		digestTicker := time.NewTicker(cc.DigestIntervalOrDefault())
+		defer digestTicker.Stop()
+		digests = digestTicker.C
+	}
+
+	RunCanaries(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			RunCanaries(ctx)
+		case <-digests:
+			sendCanaryDigest(ctx, cc)
+		}
+	}
+}
+
+// RunCanaries runs every configured canary once, recording the results and
+// raising or clearing alerts as needed.
+func RunCanaries(ctx context.Context) {
+	cc := config.GetGlobalConfigs().Canaries
+
+	for _, c := range cc.Commands {
+		start := time.Now()
+		out, err := runCanary(ctx, cc, c)
+		recordCanaryResult(ctx, cc, c, time.Since(start), err)
+
+		if cc.Digest {
+			recordCanaryDigest(c, start, time.Since(start), out, err)
+		}
+	}
+}
+
+// runCanary runs a single canary, returning its output, and an error if it
+// couldn't be run or didn't exit successfully. Like a self-test, its output
+// is returned to us rather than delivered.
+func runCanary(ctx context.Context, cc data.CanaryConfigs, c data.CanaryCommand) (string, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.runCanary")
+	defer sp.End()
+
+	sp.SetAttributes(attribute.String("canary.name", c.Name))
+
+	if pipelineRequests == nil {
+		return "", ErrNotListening
+	}
+
+	timeout := config.GetGlobalConfigs().CommandTimeout
+	if timeout <= 0 {
+		timeout = selfTestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := newCanaryRequest(ctx, cc, c)
+	if err != nil {
+		return "", err
+	}
+
+	envelope, err := runSelfTestRequest(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	if envelope.Data.ExitCode != 0 {
+		return envelope.Response.Out, fmt.Errorf("command exited with code %d: %v", envelope.Data.ExitCode, envelope.Data.Error)
+	}
+
+	return envelope.Response.Out, nil
+}
+
+// newCanaryRequest builds a request for a canary's command, and registers it
+// with the data access layer like any other request.
+func newCanaryRequest(ctx context.Context, cc data.CanaryConfigs, c data.CanaryCommand) (data.CommandRequest, error) {
+	tokens := TokenizeParameters(c.Command)
+	if len(tokens) == 0 {
+		return data.CommandRequest{}, fmt.Errorf("canary %q has no command", c.Name)
+	}
+
+	cmdInput, err := command.Parse(tokens)
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	entry, err := GetCommandEntry(ctx, cmdInput.Bundle, cmdInput.Command)
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	username := cc.User
+	if username == "" {
+		username = data.DefaultCanaryUser
+	}
+
+	user, err := da.UserGet(ctx, username)
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	request := data.CommandRequest{
+		CommandEntry:   entry,
+		InvocationText: c.Command,
+		Parameters:     tokens[1:],
+		Timestamp:      time.Now(),
+		UserEmail:      user.Email,
+		UserName:       user.Username,
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		request.Deadline = deadline
+	}
+
+	if err := da.RequestBegin(ctx, &request); err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	return request, nil
+}
+
+// recordCanaryResult updates a canary's status and metrics with the outcome
+// of a run. An alert is sent when the canary's consecutive failures reach
+// the alert threshold, and again when it next succeeds, unless digests are
+// enabled, in which case the recovery is left to the next digest.
+func recordCanaryResult(ctx context.Context, cc data.CanaryConfigs, c data.CanaryCommand, d time.Duration, err error) {
+	threshold := cc.AlertThreshold
+	if threshold <= 0 {
+		threshold = data.DefaultCanaryAlertThreshold
+	}
+
+	telemetry.CanaryRuns().
+		WithAttribute("canary", c.Name).
+		WithAttribute("success", err == nil).
+		Commit(ctx)
+	telemetry.CanaryDuration(ctx, c.Name, d)
+
+	canaryMutex.Lock()
+
+	s, ok := canaryStatuses[c.Name]
+	if !ok {
+		s = &rest.CanaryStatus{Name: c.Name}
+		canaryStatuses[c.Name] = s
+	}
+
+	s.Command = c.Command
+	s.LastRun = time.Now().UTC()
+	s.LastDuration = d
+
+	var alert, recovered bool
+
+	if err == nil {
+		s.LastError = ""
+		s.Successes++
+		s.ConsecutiveFailures = 0
+		recovered = s.Alerting
+		s.Alerting = false
+	} else {
+		s.LastError = err.Error()
+		s.Failures++
+		s.ConsecutiveFailures++
+		alert = s.ConsecutiveFailures == threshold
+		s.Alerting = s.ConsecutiveFailures >= threshold
+	}
+
+	failures := s.ConsecutiveFailures
+	canaryMutex.Unlock()
+
+	e := log.WithField("canary", c.Name).WithField("duration", d)
+
+	switch {
+	case alert:
+		e.WithError(err).WithField("failures", failures).Error("Canary is failing")
+		sendCanaryAlert(ctx, cc, fmt.Sprintf("Canary %q has failed %d times in a row: %v", c.Name, failures, err), true)
+	case recovered:
+		e.Info("Canary has recovered")
+		if !cc.Digest {
+			sendCanaryAlert(ctx, cc, fmt.Sprintf("Canary %q has recovered.", c.Name), false)
+		}
+	case err != nil:
+		e.WithError(err).WithField("failures", failures).Warn("Canary failed")
+	default:
+		e.Debug("Canary succeeded")
+	}
+}
+
+// recordCanaryDigest adds the outcome of a canary's run to the next digest.
+func recordCanaryDigest(c data.CanaryCommand, at time.Time, d time.Duration, out string, err error) {
+	canaryMutex.Lock()
+	defer canaryMutex.Unlock()
+
+	entry, ok := canaryDigest[c.Name]
+	if !ok {
+		entry = &data.DigestEntry{Name: c.Name, Command: c.Command}
+		canaryDigest[c.Name] = entry
+	}
+
+	entry.Record(at.UTC(), d, out, err)
+}
+
+// sendCanaryDigest sends the accumulated results of every canary to the
+// canary alert channel as a single digest message, and starts a new digest.
+// Nothing is sent if no canaries have run since the last digest.
+func sendCanaryDigest(ctx context.Context, cc data.CanaryConfigs) {
+	canaryMutex.Lock()
+	entries := make([]data.DigestEntry, 0, len(canaryDigest))
+	for _, e := range canaryDigest {
+		entries = append(entries, *e)
+	}
+	canaryDigest = map[string]*data.DigestEntry{}
+	canaryMutex.Unlock()
+
+	if len(entries) == 0 || cc.AlertAdapter == "" || cc.AlertChannel == "" {
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	envelope := data.NewCommandResponseEnvelope(data.CommandRequest{}, data.WithDigest("Canary Digest", entries))
+
+	a, err := GetAdapter(cc.AlertAdapter)
+	if err == nil {
+		err = SendEnvelope(ctx, a, cc.AlertChannel, envelope, data.Digest)
+	}
+
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).
+			WithField("adapter", cc.AlertAdapter).
+			WithField("channel", cc.AlertChannel).
+			Error("Failed to send canary digest")
+	}
+}
+
+// sendCanaryAlert sends a message to the canary alert channel, if one is
+// configured.
+func sendCanaryAlert(ctx context.Context, cc data.CanaryConfigs, message string, failing bool) {
+	if cc.AlertAdapter == "" || cc.AlertChannel == "" {
+		return
+	}
+
+	a, err := GetAdapter(cc.AlertAdapter)
+	if err == nil {
+		if failing {
+			err = SendErrorMessage(ctx, a, cc.AlertChannel, "Canary Failing", message)
+		} else {
+			err = SendNonCriticalMessage(ctx, a, cc.AlertChannel, message)
+		}
+	}
+
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).
+			WithField("adapter", cc.AlertAdapter).
+			WithField("channel", cc.AlertChannel).
+			Error("Failed to send canary alert")
+	}
+}
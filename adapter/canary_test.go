@@ -0,0 +1,94 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestRecordCanaryResult(t *testing.T) {
+	ctx := context.Background()
+	cc := data.CanaryConfigs{AlertThreshold: 2}
+	c := data.CanaryCommand{Name: "test-canary", Command: "test:cmd"}
+
+	defer func() {
+		canaryMutex.Lock()
+		delete(canaryStatuses, c.Name)
+		canaryMutex.Unlock()
+	}()
+
+	recordCanaryResult(ctx, cc, c, time.Millisecond, errors.New("boom"))
+	s := canaryStatuses[c.Name]
+	require.NotNil(t, s)
+	assert.Equal(t, 1, s.Failures)
+	assert.Equal(t, 1, s.ConsecutiveFailures)
+	assert.Equal(t, "boom", s.LastError)
+	assert.False(t, s.Alerting)
+
+	recordCanaryResult(ctx, cc, c, time.Millisecond, errors.New("boom"))
+	assert.Equal(t, 2, s.ConsecutiveFailures)
+	assert.True(t, s.Alerting)
+
+	recordCanaryResult(ctx, cc, c, 2*time.Millisecond, nil)
+	assert.Equal(t, 1, s.Successes)
+	assert.Equal(t, 2, s.Failures)
+	assert.Zero(t, s.ConsecutiveFailures)
+	assert.Empty(t, s.LastError)
+	assert.False(t, s.Alerting)
+	assert.Equal(t, 2*time.Millisecond, s.LastDuration)
+}
+
+func TestSendCanaryDigest(t *testing.T) {
+	ctx := context.Background()
+
+	defer func(d time.Duration) { sendInterval = d }(sendInterval)
+	sendInterval = time.Millisecond
+
+	a := &historyTestAdapter{}
+	adapterLookup["testCanaryDigest"] = a
+	defer delete(adapterLookup, "testCanaryDigest")
+
+	cc := data.CanaryConfigs{AlertAdapter: "testCanaryDigest", AlertChannel: "alerts", Digest: true}
+	c := data.CanaryCommand{Name: "test-canary", Command: "test:cmd"}
+
+	// Nothing is sent if no canaries have run.
+	sendCanaryDigest(ctx, cc)
+	assert.Empty(t, a.sent)
+
+	now := time.Now()
+	recordCanaryDigest(c, now, time.Millisecond, "ok", nil)
+	recordCanaryDigest(c, now, time.Millisecond, "not ok", errors.New("boom"))
+	recordCanaryDigest(c, now, time.Millisecond, "ok", nil)
+
+	sendCanaryDigest(ctx, cc)
+	require.Len(t, a.sent, 1)
+	assert.Contains(t, a.last(), "Canary Digest")
+	assert.Contains(t, a.last(), "test-canary (test:cmd): 3 runs, 1 failed")
+	assert.Contains(t, a.last(), "Most recent error: boom")
+
+	// Each digest starts afresh.
+	sendCanaryDigest(ctx, cc)
+	assert.Len(t, a.sent, 1)
+}
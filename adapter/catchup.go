@@ -0,0 +1,159 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// HistoryReader is an optional interface implemented by adapters whose chat
+// provider can list the messages previously posted to a channel.
+type HistoryReader interface {
+	// ChannelHistory returns the messages posted by users to a channel
+	// after since, oldest first.
+	ChannelHistory(ctx context.Context, channelID string, since time.Time) ([]HistoryMessage, error)
+}
+
+// HistoryMessage is a channel message retrieved from a provider's history,
+// along with the time it was posted.
+type HistoryMessage struct {
+	ChannelMessageEvent
+
+	Timestamp time.Time
+}
+
+// catchUp processes any commands that were posted to the adapter's channels
+// while Gort was unable to receive them, so that a restart doesn't silently
+// drop requests. Only messages posted in the last gort.catch_up_window, and
+// after the last command seen in each channel, are considered. It does
+// nothing if the window is unset or if the adapter's chat provider can't
+// read channel history.
+func catchUp(ctx context.Context, event *ProviderEvent, commandRequests chan<- data.CommandRequest, adapterErrors chan<- error) {
+	window := config.GetGortServerConfigs().CatchUpWindow
+	if window <= 0 {
+		return
+	}
+
+	h, ok := event.Adapter.(HistoryReader)
+	if !ok {
+		return
+	}
+
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.catchUp")
+	defer sp.End()
+
+	le := log.WithField("adapter.name", event.Adapter.GetName())
+
+	channels, err := event.Adapter.GetPresentChannels()
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to get channels list; skipping catch-up")
+		return
+	}
+
+	lastCommands := map[string]time.Time{}
+	if da, err := dataaccess.Get(); err != nil {
+		le.WithError(err).Warn("Failed to get data access; catching up on the full window")
+	} else if presences, err := da.ChannelPresenceList(ctx, event.Adapter.GetName()); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to list channel presence; catching up on the full window")
+	} else {
+		for _, p := range presences {
+			lastCommands[p.ChannelID] = p.LastCommandAt
+		}
+	}
+
+	until := time.Now().UTC()
+
+	for _, c := range channels {
+		since := catchUpSince(event.Adapter.GetName(), c.ID, lastCommands[c.ID], until.Add(-window))
+		catchUpChannel(ctx, event, h, c.ID, since, until, commandRequests, adapterErrors)
+	}
+}
+
+// catchUpSince returns the time after which messages in a channel may have
+// been missed: the latest of the start of the catch-up window, the last time
+// a command was recorded for the channel, and the last time a message was
+// seen in it by this process.
+func catchUpSince(adapterName, channelID string, lastCommand, windowStart time.Time) time.Time {
+	since := windowStart
+
+	if lastCommand.After(since) {
+		since = lastCommand
+	}
+
+	if t := lastChannelActivity(adapterName, channelID); t != nil && t.After(since) {
+		since = *t
+	}
+
+	return since
+}
+
+// catchUpChannel processes the commands posted to a channel after since and
+// no later than until. Each command is acknowledged in the channel before
+// it's run, so it's clear why a response arrives late.
+func catchUpChannel(ctx context.Context, event *ProviderEvent, h HistoryReader, channelID string, since, until time.Time,
+	commandRequests chan<- data.CommandRequest, adapterErrors chan<- error) {
+
+	le := log.WithField("adapter.name", event.Adapter.GetName()).
+		WithField("channel.id", channelID)
+
+	messages, err := h.ChannelHistory(ctx, channelID, since)
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to read channel history")
+		return
+	}
+
+	for _, m := range messages {
+		if !m.Timestamp.After(since) || m.Timestamp.After(until) {
+			continue
+		}
+
+		message := m.ChannelMessageEvent
+		message.ChannelID = channelID
+
+		request, err := OnChannelMessage(ctx, event, &message)
+		if request != nil {
+			ack := fmt.Sprintf("Catching up on a command sent while Gort was unavailable: `%s`", request.String())
+			if err := SendNonCriticalMessage(ctx, event.Adapter, channelID, ack); err != nil {
+				le.WithError(err).Warn("Failed to send catch-up acknowledgement")
+			}
+
+			le.WithField("command.raw", message.Text).
+				WithField("message.timestamp", m.Timestamp).
+				Info("Catching up on missed command")
+
+			markChannelActive(ctx, event.Adapter.GetName(), channelID)
+			commandRequests <- *request
+		}
+		if err != nil {
+			adapterErrors <- err
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+// historyReaderTestAdapter serves a fixed channel history.
+type historyReaderTestAdapter struct {
+	historyTestAdapter
+
+	history []HistoryMessage
+}
+
+func (t *historyReaderTestAdapter) ChannelHistory(ctx context.Context, channelID string, since time.Time) ([]HistoryMessage, error) {
+	return t.history, nil
+}
+
+func TestCatchUpChannel(t *testing.T) {
+	ctx := context.Background()
+	since := time.Now().Add(-time.Hour)
+	until := time.Now()
+
+	message := func(text string, at time.Time) HistoryMessage {
+		return HistoryMessage{
+			ChannelMessageEvent: ChannelMessageEvent{Text: text, UserID: "user"},
+			Timestamp:           at,
+		}
+	}
+
+	a := &historyReaderTestAdapter{
+		history: []HistoryMessage{
+			message("!test:cmd too-old", since.Add(-time.Minute)),
+			message("just chatting", since.Add(time.Minute)),
+			message("!test:cmd missed", since.Add(2*time.Minute)),
+			message("!test:cmd already-live", until.Add(time.Minute)),
+		},
+	}
+
+	event := &ProviderEvent{
+		EventType: EventConnected,
+		Info:      &Info{Provider: &ProviderInfo{Type: "test", Name: "provider"}},
+		Adapter:   a,
+	}
+
+	requests := make(chan data.CommandRequest, 10)
+	errs := make(chan error, 10)
+
+	catchUpChannel(ctx, event, a, "catchupchannel", since, until, requests, errs)
+	close(requests)
+	close(errs)
+
+	var got []string
+	for r := range requests {
+		assert.Equal(t, "catchupchannel", r.ChannelID)
+		got = append(got, r.String())
+	}
+	assert.Equal(t, []string{"test:cmd missed"}, got)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	require.NotEmpty(t, a.sent)
+	assert.Contains(t, a.last(), "Catching up")
+	assert.Contains(t, a.last(), "test:cmd missed")
+}
+
+func TestCatchUpSince(t *testing.T) {
+	windowStart := time.Now().Add(-time.Hour)
+
+	// Nothing recorded: the whole window.
+	assert.Equal(t, windowStart, catchUpSince("testAdapter", "catchupsince", time.Time{}, windowStart))
+
+	// A command recorded within the window.
+	lastCommand := windowStart.Add(10 * time.Minute)
+	assert.Equal(t, lastCommand, catchUpSince("testAdapter", "catchupsince", lastCommand, windowStart))
+
+	// A message seen more recently still, by this process.
+	recordChannelActivity("testAdapter", "catchupsince")
+	seen := lastChannelActivity("testAdapter", "catchupsince")
+	require.NotNil(t, seen)
+	assert.Equal(t, *seen, catchUpSince("testAdapter", "catchupsince", lastCommand, windowStart))
+}
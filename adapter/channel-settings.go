@@ -0,0 +1,110 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// requestChannelKey is the context key of the channel that a command is
+// being looked up for.
+type requestChannelKey struct{}
+
+type requestChannel struct {
+	adapter     string
+	channelID   string
+	channelName string
+}
+
+// withRequestChannel returns a copy of ctx that records the channel that a
+// command is being looked up for, so that the channel's default bundle can
+// be used by getCommandEntryForChannel, and so that triggers restricted to
+// other channels can be ignored by triggerEntriesForChannel.
+func withRequestChannel(ctx context.Context, id RequestorIdentity) context.Context {
+	if id.Adapter == nil || id.ChatChannel == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, requestChannelKey{}, requestChannel{
+		adapter:     id.Adapter.GetName(),
+		channelID:   id.ChatChannel.ID,
+		channelName: id.ChatChannel.Name,
+	})
+}
+
+// getCommandEntryForChannel is equivalent to GetCommandEntry, except that if
+// bundleName is empty and the channel recorded in ctx has a default bundle,
+// the command is looked up in that bundle first. If the default bundle
+// isn't enabled or has no such command, every enabled bundle is searched.
+func getCommandEntryForChannel(ctx context.Context, bundleName, commandName string) (data.CommandEntry, error) {
+	if bundleName != "" {
+		return GetCommandEntry(ctx, bundleName, commandName)
+	}
+
+	rc, ok := ctx.Value(requestChannelKey{}).(requestChannel)
+	if !ok {
+		return GetCommandEntry(ctx, bundleName, commandName)
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return data.CommandEntry{}, err
+	}
+
+	settings, err := da.ChannelSettingsGet(ctx, rc.adapter, rc.channelID)
+	if err != nil {
+		return data.CommandEntry{}, err
+	}
+
+	if settings.DefaultBundle != "" {
+		entry, err := GetCommandEntry(ctx, settings.DefaultBundle, commandName)
+		if err == nil || !gerrs.Is(err, ErrNoSuchCommand) {
+			return entry, err
+		}
+	}
+
+	return GetCommandEntry(ctx, bundleName, commandName)
+}
+
+// triggerEntriesForChannel narrows entries, the commands with a trigger that
+// matches message, to those with a matching trigger that's allowed in the
+// channel recorded in ctx. Each remaining entry's triggers are limited to
+// the ones allowed in the channel, so that trigger precedence only considers
+// those. If ctx records no channel, only unrestricted triggers are allowed.
+func triggerEntriesForChannel(ctx context.Context, entries []data.CommandEntry, message string) ([]data.CommandEntry, error) {
+	rc, _ := ctx.Value(requestChannelKey{}).(requestChannel)
+
+	narrowed := make([]data.CommandEntry, 0, len(entries))
+
+	for _, e := range entries {
+		e.Command.Triggers = e.Command.TriggersForChannel(rc.channelID, rc.channelName)
+
+		matched, err := e.Command.MatchTrigger(ctx, message)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			narrowed = append(narrowed, e)
+		}
+	}
+
+	return narrowed, nil
+}
@@ -22,3 +22,14 @@ type ChannelInfo struct {
 	Members []string
 	Name    string
 }
+
+// ChannelJoiner is an optional interface implemented by adapters whose chat
+// provider allows the bot to join and leave channels on request. Channels may
+// be specified either by ID or by name.
+type ChannelJoiner interface {
+	// JoinChannel causes the bot to join the specified channel.
+	JoinChannel(channel string) (*ChannelInfo, error)
+
+	// LeaveChannel causes the bot to leave the specified channel.
+	LeaveChannel(channel string) error
+}
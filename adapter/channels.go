@@ -0,0 +1,168 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+var (
+	// channelActivity records the time of the last message seen in each
+	// channel, keyed by adapter name and then by channel ID.
+	channelActivity   = map[string]map[string]time.Time{}
+	channelActivityMx sync.RWMutex
+)
+
+// recordChannelActivity notes that a message was just seen in a channel.
+func recordChannelActivity(adapterName, channelID string) {
+	channelActivityMx.Lock()
+	defer channelActivityMx.Unlock()
+
+	m, ok := channelActivity[adapterName]
+	if !ok {
+		m = map[string]time.Time{}
+		channelActivity[adapterName] = m
+	}
+
+	m[channelID] = time.Now().UTC()
+}
+
+// lastChannelActivity returns the time the last message was seen in a
+// channel, or nil if none has been.
+func lastChannelActivity(adapterName, channelID string) *time.Time {
+	channelActivityMx.RLock()
+	defer channelActivityMx.RUnlock()
+
+	if t, ok := channelActivity[adapterName][channelID]; ok {
+		return &t
+	}
+
+	return nil
+}
+
+// ChannelManager provides channel inventory and membership operations
+// across all adapters. It's used by the REST service.
+type ChannelManager struct{}
+
+// ChannelList returns every channel that each adapter is present in,
+// ordered by adapter name and channel name.
+func (ChannelManager) ChannelList(ctx context.Context) ([]rest.Channel, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "adapter.ChannelList")
+	defer sp.End()
+
+	channels := []rest.Channel{}
+
+	for name, a := range adapterLookup {
+		present, err := a.GetPresentChannels()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range present {
+			channels = append(channels, newRestChannel(name, c))
+		}
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].Adapter != channels[j].Adapter {
+			return channels[i].Adapter < channels[j].Adapter
+		}
+		return channels[i].Name < channels[j].Name
+	})
+
+	return channels, nil
+}
+
+// ChannelJoin causes the named adapter to join a channel. If the adapter's
+// chat provider doesn't support this, an ErrUnsupported error is returned.
+func (ChannelManager) ChannelJoin(ctx context.Context, adapterName, channel string) (rest.Channel, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "adapter.ChannelJoin")
+	defer sp.End()
+
+	j, err := getChannelJoiner(adapterName)
+	if err != nil {
+		return rest.Channel{}, err
+	}
+
+	info, err := j.JoinChannel(channel)
+	if err != nil {
+		return rest.Channel{}, err
+	}
+
+	return newRestChannel(adapterName, info), nil
+}
+
+// ChannelLeave causes the named adapter to leave a channel. If the adapter's
+// chat provider doesn't support this, an ErrUnsupported error is returned.
+func (ChannelManager) ChannelLeave(ctx context.Context, adapterName, channel string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.ChannelLeave")
+	defer sp.End()
+
+	j, err := getChannelJoiner(adapterName)
+	if err != nil {
+		return err
+	}
+
+	if err := j.LeaveChannel(channel); err != nil {
+		return err
+	}
+
+	// Forget the channel so that it's greeted again if Gort is re-invited.
+	da, err := dataaccess.Get()
+	if err != nil {
+		return err
+	}
+
+	return da.ChannelPresenceDelete(ctx, adapterName, channel)
+}
+
+func getChannelJoiner(adapterName string) (ChannelJoiner, error) {
+	a, err := GetAdapter(adapterName)
+	if err != nil {
+		return nil, err
+	}
+
+	j, ok := a.(ChannelJoiner)
+	if !ok {
+		return nil, gerrs.ErrUnsupported
+	}
+
+	return j, nil
+}
+
+func newRestChannel(adapterName string, c *ChannelInfo) rest.Channel {
+	return rest.Channel{
+		Adapter:      adapterName,
+		ID:           c.ID,
+		Name:         c.Name,
+		Members:      len(c.Members),
+		LastActivity: lastChannelActivity(adapterName, c.ID),
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// codeFence opens and closes a block of preformatted text.
+const codeFence = "```"
+
+// MessageLimiter is an optional interface implemented by adapters whose chat
+// provider limits the length of a single text message. Text messages longer
+// than the limit are split into numbered chunks before they're sent.
+type MessageLimiter interface {
+	// MaxMessageLength returns the maximum number of characters in a single
+	// text message.
+	MaxMessageLength() int
+}
+
+// maxMessageLength returns the message length limit declared by an adapter,
+// or zero if it doesn't declare one.
+func maxMessageLength(a Adapter) int {
+	if l, ok := a.(MessageLimiter); ok {
+		return l.MaxMessageLength()
+	}
+
+	return 0
+}
+
+// chunkText splits text into chunks no longer than max characters, each
+// prefixed with its sequence number, like "(1/3)". Chunks are split between
+// lines where possible, and code blocks split across chunks are closed at
+// the end of one and reopened at the start of the next. Text that already
+// fits, or any text if max isn't positive, is returned as a single chunk.
+func chunkText(text string, max int) []string {
+	if max <= 0 || utf8.RuneCountInString(text) <= max {
+		return []string{text}
+	}
+
+	// The header's length depends on the number of chunks, which depends on
+	// the space left by the header; retry with more digits until it fits.
+	for digits := 1; ; digits++ {
+		limit := max - len("(/)\n") - 2*digits
+		if limit < 1 {
+			return []string{text}
+		}
+
+		chunks := splitText(text, limit)
+		if len(strconv.Itoa(len(chunks))) > digits {
+			continue
+		}
+
+		for i, c := range chunks {
+			chunks[i] = fmt.Sprintf("(%d/%d)\n%s", i+1, len(chunks), c)
+		}
+
+		return chunks
+	}
+}
+
+// splitText splits text into chunks of no more than limit characters,
+// preserving code blocks.
+func splitText(text string, limit int) []string {
+	var chunks, lines []string
+	var size int
+
+	// fence is the line that opened the current code block, if any.
+	var fence string
+
+	add := func(line string) {
+		if len(lines) > 0 {
+			size++
+		}
+		lines = append(lines, line)
+		size += utf8.RuneCountInString(line)
+	}
+
+	flush := func() {
+		if fence != "" {
+			add(codeFence)
+		}
+
+		chunks = append(chunks, strings.Join(lines, "\n"))
+		lines, size = nil, 0
+
+		if fence != "" {
+			add(fence)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		// Leave room to reopen and close a code block around each piece.
+		pieceLimit := limit
+		if fence != "" {
+			pieceLimit -= utf8.RuneCountInString(fence) + len("\n\n") + len(codeFence)
+		}
+
+		for _, piece := range splitLine(line, pieceLimit) {
+			next := fence
+			if piece == line && strings.HasPrefix(strings.TrimSpace(line), codeFence) {
+				if fence == "" {
+					next = line
+				} else {
+					next = ""
+				}
+			}
+
+			cost := size + utf8.RuneCountInString(piece)
+			if len(lines) > 0 {
+				cost++
+			}
+			if next != "" {
+				cost += len("\n") + len(codeFence)
+			}
+
+			if cost > limit && len(lines) > 0 {
+				flush()
+			}
+
+			add(piece)
+			fence = next
+		}
+	}
+
+	if len(lines) > 0 {
+		chunks = append(chunks, strings.Join(lines, "\n"))
+	}
+
+	return chunks
+}
+
+// splitLine splits a line into pieces of no more than limit characters.
+func splitLine(line string, limit int) []string {
+	if limit < 1 {
+		limit = 1
+	}
+
+	runes := []rune(line)
+	if len(runes) <= limit {
+		return []string{line}
+	}
+
+	var pieces []string
+	for len(runes) > limit {
+		pieces = append(pieces, string(runes[:limit]))
+		runes = runes[limit:]
+	}
+
+	return append(pieces, string(runes))
+}
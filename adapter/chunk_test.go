@@ -0,0 +1,119 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// limitedTestAdapter is a queueTestAdapter with a message length limit.
+type limitedTestAdapter struct {
+	queueTestAdapter
+	max int
+}
+
+func (t *limitedTestAdapter) MaxMessageLength() int {
+	return t.max
+}
+
+// chunkBodies checks that each chunk fits and is numbered, and returns the
+// chunks with their sequence numbers removed.
+func chunkBodies(t *testing.T, chunks []string, max int) []string {
+	var bodies []string
+
+	for i, c := range chunks {
+		assert.LessOrEqual(t, utf8.RuneCountInString(c), max, c)
+
+		header := fmt.Sprintf("(%d/%d)\n", i+1, len(chunks))
+		require.True(t, strings.HasPrefix(c, header), c)
+		bodies = append(bodies, strings.TrimPrefix(c, header))
+	}
+
+	return bodies
+}
+
+func TestChunkTextFits(t *testing.T) {
+	assert.Equal(t, []string{"short"}, chunkText("short", 100))
+
+	long := strings.Repeat("long ", 100)
+	assert.Equal(t, []string{long}, chunkText(long, 0))
+}
+
+func TestChunkTextLines(t *testing.T) {
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	text := strings.Join(lines, "\n")
+
+	chunks := chunkText(text, 30)
+	assert.Len(t, chunks, 4)
+	assert.Equal(t, text, strings.Join(chunkBodies(t, chunks, 30), "\n"))
+}
+
+func TestChunkTextLongLine(t *testing.T) {
+	text := strings.Repeat("x", 25)
+
+	chunks := chunkText(text, 10)
+	assert.Len(t, chunks, 7)
+	assert.Equal(t, text, strings.Join(chunkBodies(t, chunks, 10), ""))
+}
+
+func TestChunkTextCodeFences(t *testing.T) {
+	text := "before\n```\ncode 0\ncode 1\ncode 2\ncode 3\ncode 4\ncode 5\n```\nafter"
+
+	chunks := chunkText(text, 40)
+	bodies := chunkBodies(t, chunks, 40)
+	require.Len(t, bodies, 3)
+
+	for _, b := range bodies {
+		assert.Equal(t, 0, strings.Count(b, codeFence)%2, "unbalanced code fence: %q", b)
+	}
+
+	assert.Equal(t, "before\n```\ncode 0\ncode 1\n```", bodies[0])
+	assert.Equal(t, "```\ncode 2\ncode 3\ncode 4\n```", bodies[1])
+	assert.Equal(t, "```\ncode 5\n```\nafter", bodies[2])
+}
+
+func TestSendQueueChunksText(t *testing.T) {
+	defer func(d time.Duration) { sendInterval = d }(sendInterval)
+	sendInterval = time.Millisecond
+
+	a := &limitedTestAdapter{queueTestAdapter: queueTestAdapter{name: "queue-chunks"}, max: 30}
+
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+
+	require.NoError(t, queueSendText(context.Background(), a, "C1", strings.Join(lines, "\n")))
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	require.Len(t, a.sent, 4)
+	assert.Equal(t, "C1: (1/4)\nline 0\nline 1\nline 2", a.sent[0])
+	assert.Equal(t, "C1: (4/4)\nline 9", a.sent[3])
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// Chat error codes are in the GORT-2xxx range. Note that ErrNoSuchCommand
+// isn't registered here: it shares its message with errs.ErrNoSuchBundle, so
+// the two share a code as well.
+func init() {
+	gerrs.RegisterCode(ErrNotAllowed, gerrs.Code{
+		Code:        "GORT-2001",
+		Title:       "Permission denied",
+		Description: "You don't have the permissions required by the command's rules.",
+		Remediation: "Ask a Gort administrator to grant one of your groups a role with the required permission.",
+	})
+	gerrs.RegisterCode(ErrMultipleCommands, gerrs.Code{
+		Code:        "GORT-2002",
+		Title:       "Ambiguous command",
+		Description: "The command name, or the message for a trigger, matches commands in more than one bundle.",
+		Remediation: "Namespace the command with its bundle name, as in `bundle:command`. For triggers, ask a Gort administrator to configure global.triggers.precedence.",
+	})
+	gerrs.RegisterCode(ErrSelfRegistrationOff, gerrs.Code{
+		Code:        "GORT-2003",
+		Title:       "Unknown user",
+		Description: "You don't have a Gort account, and self-registration is disabled.",
+		Remediation: "Ask a Gort administrator to create an account for you or to map your chat identity to an existing one.",
+	})
+	gerrs.RegisterCode(ErrGortNotBootstrapped, gerrs.Code{
+		Code:        "GORT-2004",
+		Title:       "Gort not bootstrapped",
+		Description: "Gort hasn't been bootstrapped yet, so no commands can be executed.",
+		Remediation: "Run `gort bootstrap` against the controller.",
+	})
+	gerrs.RegisterCode(ErrUserNotFound, gerrs.Code{
+		Code:        "GORT-2005",
+		Title:       "Chat user not found",
+		Description: "The chat provider didn't return information about the requesting user.",
+		Remediation: "Check that the adapter's bot has permission to read user profiles.",
+	})
+	gerrs.RegisterCode(ErrNoPreviousCommand, gerrs.Code{
+		Code:        "GORT-2006",
+		Title:       "No previous command",
+		Description: "You asked to repeat your last command with `!!`, but you haven't run any commands in this channel.",
+		Remediation: "Run the command in full.",
+	})
+	gerrs.RegisterCode(ErrRepeatRedacted, gerrs.Code{
+		Code:        "GORT-2007",
+		Title:       "Command can't be repeated",
+		Description: "Some of your last command's parameters were redacted when it was recorded, so `!!` can't repeat it exactly.",
+		Remediation: "Run the command in full.",
+	})
+	gerrs.RegisterCode(ErrInputFileTooLarge, gerrs.Code{
+		Code:        "GORT-2008",
+		Title:       "Attached file too large",
+		Description: "The file attached to the command message is larger than Gort accepts.",
+		Remediation: "Attach a smaller file, or ask a Gort administrator to increase global.input_files.max_size.",
+	})
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// deadLetter stores a response that couldn't be delivered so that it can be
+// redelivered later, and notifies the channel configured in
+// global.dead_letters, if any. Failures are logged but otherwise ignored.
+func deadLetter(ctx context.Context, a Adapter, channelID string, envelope data.CommandResponseEnvelope, rendered string, sendErr error) {
+	now := time.Now().UTC()
+
+	letter := data.DeadLetter{
+		RequestID:   envelope.Request.RequestID,
+		Adapter:     a.GetName(),
+		ChannelID:   channelID,
+		Message:     rendered,
+		Error:       sendErr.Error(),
+		Attempts:    1,
+		Created:     now,
+		LastAttempt: now,
+	}
+
+	le := log.WithField("request.id", letter.RequestID).
+		WithField("adapter.name", letter.Adapter).
+		WithField("channel.id", letter.ChannelID)
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Error("Failed to get data access; undeliverable response lost")
+		return
+	}
+
+	if err := da.DeadLetterCreate(ctx, &letter); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to store undeliverable response")
+		return
+	}
+
+	le.WithField("deadletter.id", letter.ID).Warn("Undeliverable response stored as a dead letter")
+
+	notifyDeadLetter(ctx, letter)
+}
+
+// notifyDeadLetter tells administrators about a new dead letter by sending a
+// message to global.dead_letters.notify_channel.
+func notifyDeadLetter(ctx context.Context, letter data.DeadLetter) {
+	c := config.GetGlobalConfigs().DeadLetters
+	if c.NotifyAdapter == "" || c.NotifyChannel == "" {
+		return
+	}
+
+	a, err := GetAdapter(c.NotifyAdapter)
+	if err != nil {
+		log.WithError(err).WithField("adapter.name", c.NotifyAdapter).
+			Warn("Failed to get dead letter notification adapter")
+		return
+	}
+
+	msg := fmt.Sprintf("A response to request %d couldn't be delivered to channel %s (%s): %s\n"+
+		"Use `gort deadletter redeliver %d` to retry once the problem is fixed.",
+		letter.RequestID, letter.ChannelID, letter.Adapter, letter.Error, letter.ID)
+
+	if err := SendMessage(ctx, a, c.NotifyChannel, msg); err != nil {
+		log.WithError(err).WithField("adapter.name", c.NotifyAdapter).
+			WithField("channel.id", c.NotifyChannel).
+			Warn("Failed to send dead letter notification")
+	}
+}
+
+// Redeliver attempts to send a dead letter's message, as plain text, to the
+// channel it was originally meant for.
+func Redeliver(ctx context.Context, letter data.DeadLetter) error {
+	a, err := GetAdapter(letter.Adapter)
+	if err != nil {
+		return err
+	}
+
+	return queueSendText(ctx, a, letter.ChannelID, letter.Message)
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+
+	"github.com/getgort/gort/command"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/types"
+)
+
+// optionDefaultParseOptions returns parse options that mark every option
+// with a non-empty default value, at any layer, as taking an argument. This
+// ensures that an explicit value for such an option is bound to the option
+// rather than treated as a positional parameter.
+func optionDefaultParseOptions(ctx context.Context, cmdEntry data.CommandEntry) ([]command.ParseOption, error) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := da.OptionDefaultList(ctx, cmdEntry.Bundle.Name, cmdEntry.Command.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []command.ParseOption
+	for _, d := range defaults {
+		if d.Value != "" {
+			options = append(options, command.ParseOptionHasArgument(d.Option, true))
+		}
+	}
+
+	return options, nil
+}
+
+// optionPermissionParseOptions returns parse options that mark every option
+// whose values require permissions as taking an argument, so that the value
+// is bound to the option and the option's rules can match it.
+func optionPermissionParseOptions(cmdEntry data.CommandEntry) []command.ParseOption {
+	var options []command.ParseOption
+	for name, o := range cmdEntry.Command.Options {
+		if o != nil && len(o.Permissions) > 0 {
+			options = append(options, command.ParseOptionHasArgument(name, true))
+		}
+	}
+
+	return options
+}
+
+// applyOptionDefaults adds any administrator-defined option defaults to
+// cmdInput that apply to the requesting user and channel. Options that were
+// provided explicitly are never overridden.
+func applyOptionDefaults(ctx context.Context, cmdInput command.Command, cmdEntry data.CommandEntry, id RequestorIdentity) (command.Command, error) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		return cmdInput, err
+	}
+
+	defaults, err := da.OptionDefaultList(ctx, cmdEntry.Bundle.Name, cmdEntry.Command.Name)
+	if err != nil || len(defaults) == 0 {
+		return cmdInput, err
+	}
+
+	var groupNames []string
+	if id.GortUser != nil {
+		groups, err := da.UserGroupList(ctx, id.GortUser.Username)
+		if err != nil {
+			return cmdInput, err
+		}
+
+		for _, g := range groups {
+			groupNames = append(groupNames, g.Name)
+		}
+	}
+
+	var channel string
+	if id.ChatChannel != nil {
+		channel = id.ChatChannel.ID
+	}
+
+	values := data.ResolveOptionDefaults(defaults, channel, groupNames)
+
+	options := make(map[string]command.CommandOption, len(cmdInput.Options)+len(values))
+	for name, value := range values {
+		var v types.Value = types.StringValue{V: value}
+		if value == "" {
+			v = types.BoolValue{V: true}
+		}
+
+		options[name] = command.CommandOption{Name: name, Value: v}
+	}
+
+	for name, o := range cmdInput.Options {
+		options[name] = o
+	}
+
+	cmdInput.Options = options
+
+	return cmdInput, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/telemetry"
+)
+
+// SendDirectMessage sends a message directly to a Gort user through each
+// adapter that the user is mapped to (or only m.Adapter, if it's set). A
+// direct message channel is opened with the user's chat account on each
+// adapter that requires one.
+func SendDirectMessage(ctx context.Context, user rest.User, m rest.DirectMessage) (rest.DirectMessageResult, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.SendDirectMessage")
+	defer sp.End()
+
+	result := rest.DirectMessageResult{Sent: []rest.DirectMessageTarget{}}
+
+	var names []string
+	for name := range user.Mappings {
+		if m.Adapter == "" || m.Adapter == name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		target := rest.DirectMessageTarget{Adapter: name, UserID: user.Mappings[name]}
+
+		if err := sendDirectMessage(ctx, name, &target, m.Message); err != nil {
+			log.WithError(err).
+				WithField("adapter.name", name).
+				WithField("user.name", user.Username).
+				Warn("Failed to send direct message")
+
+			target.Error = err.Error()
+			result.Failed = append(result.Failed, target)
+			continue
+		}
+
+		result.Sent = append(result.Sent, target)
+	}
+
+	sp.SetAttributes(
+		attribute.Int("dm.sent", len(result.Sent)),
+		attribute.Int("dm.failed", len(result.Failed)),
+	)
+
+	return result, nil
+}
+
+// sendDirectMessage sends a message to target.UserID through the named
+// adapter, filling in the target's channel ID and presence.
+func sendDirectMessage(ctx context.Context, adapterName string, target *rest.DirectMessageTarget, message string) error {
+	a, err := GetAdapter(adapterName)
+	if err != nil {
+		return err
+	}
+
+	target.ChannelID = target.UserID
+	if dm, ok := a.(DirectMessenger); ok {
+		if target.ChannelID, err = dm.OpenDirectChannel(target.UserID); err != nil {
+			return err
+		}
+	}
+
+	// Presence is informational only, so failing to get it isn't an error.
+	if pr, ok := a.(PresenceReporter); ok {
+		if presence, err := pr.GetUserPresence(target.UserID); err == nil {
+			target.Presence = presence
+		}
+	}
+
+	return SendMessage(ctx, a, target.ChannelID, message)
+}
@@ -19,6 +19,7 @@ package discord
 import (
 	"context"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -28,10 +29,15 @@ import (
 	"github.com/getgort/gort/templates"
 
 	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
 )
 
 const ZeroWidthSpace = "\u200b"
 
+// maxMessageLength is the longest text message, in characters, that Discord
+// will accept.
+const maxMessageLength = 2000
+
 // NewAdapter will construct a DiscordAdapter instance for a given provider configuration.
 func NewAdapter(provider data.DiscordProvider) (adapter.Adapter, error) {
 	// Create a new Discord session using the provided bot token.
@@ -46,7 +52,14 @@ func NewAdapter(provider data.DiscordProvider) (adapter.Adapter, error) {
 	}, nil
 }
 
-var _ adapter.Adapter = &Adapter{}
+var (
+	_ adapter.Adapter              = &Adapter{}
+	_ adapter.AttachmentDownloader = &Adapter{}
+	_ adapter.DirectMessenger      = &Adapter{}
+	_ adapter.MessageDeleter       = &Adapter{}
+	_ adapter.PresenceReporter     = &Adapter{}
+	_ adapter.Reactor              = &Adapter{}
+)
 
 // Adapter is the Discord provider implementation of a relay, which knows how
 // to receive events from the Discord API, translate them into Gort events, and
@@ -72,16 +85,22 @@ func (s *Adapter) GetName() string {
 	return s.provider.Name
 }
 
-// GetPresentChannels returns a slice of channels that a user is present in.
+// GetPresentChannels returns a slice of the text channels in every guild
+// that the bot is a member of.
 func (s *Adapter) GetPresentChannels() ([]*adapter.ChannelInfo, error) {
-	allChannels, err := s.session.UserChannels()
-	if err != nil {
-		return nil, err
-	}
-
 	channels := make([]*adapter.ChannelInfo, 0)
-	for _, ch := range allChannels {
-		channels = append(channels, newChannelInfoFromDiscordChannel(ch))
+
+	for _, guild := range s.session.State.Guilds {
+		guildChannels, err := s.session.GuildChannels(guild.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ch := range guildChannels {
+			if ch.Type == discordgo.ChannelTypeGuildText {
+				channels = append(channels, newChannelInfoFromDiscordChannel(ch))
+			}
+		}
 	}
 
 	return channels, nil
@@ -98,6 +117,35 @@ func (s *Adapter) GetUserInfo(userID string) (*adapter.UserInfo, error) {
 	return newUserInfoFromDiscordUser(u), nil
 }
 
+// GetUserPresence returns PresenceActive if the user is online in any guild
+// that the bot is a member of, and PresenceAway otherwise. Presence is only
+// reported if the bot has been granted the guild presences intent.
+func (s *Adapter) GetUserPresence(userID string) (string, error) {
+	for _, guild := range s.session.State.Guilds {
+		p, err := s.session.State.Presence(guild.ID, userID)
+		if err != nil {
+			continue
+		}
+
+		if p.Status == discordgo.StatusOnline {
+			return adapter.PresenceActive, nil
+		}
+	}
+
+	return adapter.PresenceAway, nil
+}
+
+// OpenDirectChannel opens (or finds the existing) direct message channel
+// with the specified user, and returns its ID.
+func (s *Adapter) OpenDirectChannel(userID string) (string, error) {
+	ch, err := s.session.UserChannelCreate(userID)
+	if err != nil {
+		return "", err
+	}
+
+	return ch.ID, nil
+}
+
 // Listen causes the Adapter to initiate a connection to its provider and
 // begin relaying back events (including errors) via the returned channel.
 func (s *Adapter) Listen(ctx context.Context) <-chan *adapter.ProviderEvent {
@@ -105,8 +153,10 @@ func (s *Adapter) Listen(ctx context.Context) <-chan *adapter.ProviderEvent {
 
 	// Register the messageCreate func as a callback for MessageCreate events.
 	s.session.AddHandler(s.messageCreate)
-	s.session.AddHandler(s.onConnected)
+	s.session.AddHandler(s.onReady)
 	s.session.AddHandler(s.onDisconnected)
+	s.session.AddHandler(s.onChannelUpdate)
+	s.session.AddHandler(s.onGuildMemberUpdate)
 
 	go func() {
 		// Open a websocket connection to Discord and begin listening.
@@ -126,6 +176,13 @@ func (s *Adapter) Listen(ctx context.Context) <-chan *adapter.ProviderEvent {
 // Send the contents of a response envelope to a specified channel. If
 // channelID is empty the value of envelope.Request.ChannelID will be used.
 func (s *Adapter) Send(ctx context.Context, channelID string, elements templates.OutputElements) error {
+	_, err := s.SendDeletable(ctx, channelID, elements)
+	return err
+}
+
+// SendDeletable sends the contents of a response envelope to the specified
+// channel, and returns the ID of the message so that it can be deleted.
+func (s *Adapter) SendDeletable(ctx context.Context, channelID string, elements templates.OutputElements) (string, error) {
 	var flattened []templates.OutputElement
 
 	for _, e := range elements.Elements {
@@ -137,6 +194,7 @@ func (s *Adapter) Send(ctx context.Context, channelID string, elements templates
 	}
 
 	var err error
+	var msg *discordgo.Message
 	var fields []*discordgo.MessageEmbedField
 	var textOnly = true
 
@@ -183,6 +241,21 @@ func (s *Adapter) Send(ctx context.Context, channelID string, elements templates
 		case *templates.Alt:
 			// Ignore Alt, only rendered as fallback
 
+		case *templates.Button, *templates.Select:
+			// Interactive elements aren't supported yet; show the command
+			// that the user can type instead.
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:  ZeroWidthSpace,
+				Value: e.(templates.WithAlt).Alt(),
+			})
+
+		case *templates.Table:
+			// Discord has no table support, so use aligned monospace text.
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:  ZeroWidthSpace,
+				Value: fmt.Sprintf("```%s```", t.Alt()),
+			})
+
 		case *templates.Text:
 			var title = t.Title
 			var text = t.Text
@@ -204,7 +277,7 @@ func (s *Adapter) Send(ctx context.Context, channelID string, elements templates
 			})
 
 		default:
-			return fmt.Errorf("%T fields are not yet supported by Gort for Discord", e)
+			return "", fmt.Errorf("%T fields are not yet supported by Gort for Discord", e)
 		}
 	}
 
@@ -219,14 +292,14 @@ func (s *Adapter) Send(ctx context.Context, channelID string, elements templates
 			text += "\n" + fields[i].Value
 		}
 
-		_, err = s.session.ChannelMessageSend(channelID, text)
+		msg, err = s.session.ChannelMessageSend(channelID, text)
 	} else {
 		var color uint64
 
 		if elements.Color != "" {
 			color, err = strconv.ParseUint(strings.Replace(elements.Color, "#", "", 1), 16, 64)
 			if err != nil {
-				return fmt.Errorf("badly-formatted color code: %q", elements.Color)
+				return "", fmt.Errorf("badly-formatted color code: %q", elements.Color)
 			}
 		}
 
@@ -234,10 +307,45 @@ func (s *Adapter) Send(ctx context.Context, channelID string, elements templates
 		embed.Title = elements.Title
 		embed.Fields = fields
 
-		_, err = s.session.ChannelMessageSendEmbed(channelID, embed)
+		msg, err = s.session.ChannelMessageSendEmbed(channelID, embed)
 	}
 
-	return err
+	if err != nil {
+		return "", err
+	}
+
+	return msg.ID, nil
+}
+
+// MaxMessageLength returns the longest text message that Discord accepts.
+func (s *Adapter) MaxMessageLength() int {
+	return maxMessageLength
+}
+
+// DeleteMessage deletes a message previously sent by the adapter.
+func (s *Adapter) DeleteMessage(ctx context.Context, channelID string, messageID string) error {
+	return s.session.ChannelMessageDelete(channelID, messageID)
+}
+
+// DownloadAttachment downloads a file attached to a message. Discord
+// attachment URLs don't require authentication.
+func (s *Adapter) DownloadAttachment(ctx context.Context, attachment adapter.Attachment, w io.Writer) error {
+	return adapter.DownloadURL(ctx, attachment.URL, "", w)
+}
+
+// React adds an emoji reaction to a message. Discord identifies a custom
+// emoji by "name:id", so custom emoji must be given in that form.
+func (s *Adapter) React(ctx context.Context, channelID string, messageID string, emoji data.Emoji) error {
+	id := emoji.Unicode
+
+	if emoji.Custom != "" {
+		if !strings.Contains(emoji.Custom, ":") {
+			return fmt.Errorf("%w: Discord custom emoji must be given as \"name:id\"", data.ErrUnsupportedEmoji)
+		}
+		id = emoji.Custom
+	}
+
+	return s.session.MessageReactionAdd(channelID, messageID, id)
 }
 
 // SendText sends a simple text message to the specified channel.
@@ -269,44 +377,82 @@ func (s *Adapter) SendError(ctx context.Context, channelID string, title string,
 // This function will be called (due to AddHandler above) every time a new
 // message is created on any channel that the authenticated bot has access to.
 func (s *Adapter) messageCreate(sess *discordgo.Session, m *discordgo.MessageCreate) {
-	// Ignore all messages created by the bot itself
-	if m.Author.ID == sess.State.User.ID {
+	// Ignore all messages created by the bot itself, or by other bots
+	if m.Author.ID == sess.State.User.ID || m.Author.Bot {
 		return
 	}
-	channel, err := sess.Channel(m.ChannelID)
+
+	channel, err := sess.State.Channel(m.ChannelID)
 	if err != nil {
-		panic(err)
+		if channel, err = sess.Channel(m.ChannelID); err != nil {
+			log.WithError(err).
+				WithField("adapter.name", s.provider.Name).
+				WithField("channel.id", m.ChannelID).
+				Error("Failed to look up Discord channel; message ignored")
+			return
+		}
 	}
-	if len(channel.Recipients) > 0 {
+
+	if channel.Type == discordgo.ChannelTypeDM || channel.Type == discordgo.ChannelTypeGroupDM {
 		s.events <- s.wrapEvent(
-			adapter.EventChannelMessage,
+			adapter.EventDirectMessage,
 			&adapter.DirectMessageEvent{
-				ChannelID: m.ChannelID,
-				Text:      m.Content,
-				UserID:    m.Author.ID,
+				Attachments: attachments(m.Attachments),
+				ChannelID:   m.ChannelID,
+				MessageID:   m.ID,
+				Text:        m.Content,
+				UserID:      m.Author.ID,
 			},
 		)
 	} else {
 		s.events <- s.wrapEvent(
 			adapter.EventChannelMessage,
 			&adapter.ChannelMessageEvent{
-				ChannelID: m.ChannelID,
-				Text:      m.Content,
-				UserID:    m.Author.ID,
+				Attachments: attachments(m.Attachments),
+				ChannelID:   m.ChannelID,
+				MessageID:   m.ID,
+				Text:        m.Content,
+				UserID:      m.Author.ID,
 			},
 		)
 	}
 }
 
-// onConnected is called when the Slack API emits a ConnectedEvent.
-func (s *Adapter) onConnected(sess *discordgo.Session, m *discordgo.Connect) *adapter.ProviderEvent {
-	return s.wrapEvent(
+// attachments describes the files attached to a message as attachments.
+func attachments(files []*discordgo.MessageAttachment) []adapter.Attachment {
+	var out []adapter.Attachment
+
+	for _, f := range files {
+		out = append(out, adapter.Attachment{
+			ID:   f.ID,
+			Name: f.Filename,
+			Size: int64(f.Size),
+			URL:  f.URL,
+		})
+	}
+
+	return out
+}
+
+// onReady is called when the Discord API emits a Ready event, once the
+// connection is established and the session state (including the guilds
+// that the bot is a member of) has been populated.
+func (s *Adapter) onReady(sess *discordgo.Session, m *discordgo.Ready) {
+	s.events <- s.wrapEvent(
 		adapter.EventConnected,
 		&adapter.ConnectedEvent{},
 	)
 }
 
-// onConnectionError is called when the Slack API emits an ConnectionErrorEvent.
+// onChannelUpdate is called when the Discord API emits a ChannelUpdate event.
+func (s *Adapter) onChannelUpdate(sess *discordgo.Session, m *discordgo.ChannelUpdate) {
+	s.events <- s.wrapEvent(
+		adapter.EventChannelChanged,
+		&adapter.ChannelChangedEvent{ChannelID: m.ID},
+	)
+}
+
+// onConnectionError is called when the Discord session fails to open.
 func (s *Adapter) onConnectionError(message string) *adapter.ProviderEvent {
 	return s.wrapEvent(
 		adapter.EventConnectionError,
@@ -322,7 +468,20 @@ func (s *Adapter) onDisconnected(sess *discordgo.Session, m *discordgo.Disconnec
 	)
 }
 
-// onInvalidAuth is called when the Slack API emits an InvalidAuthEvent.
+// onGuildMemberUpdate is called when the Discord API emits a
+// GuildMemberUpdate event.
+func (s *Adapter) onGuildMemberUpdate(sess *discordgo.Session, m *discordgo.GuildMemberUpdate) {
+	if m.User == nil {
+		return
+	}
+
+	s.events <- s.wrapEvent(
+		adapter.EventUserChanged,
+		&adapter.UserChangedEvent{UserID: m.User.ID},
+	)
+}
+
+// onInvalidAuth is called when the Discord session fails to authenticate.
 func (s *Adapter) onInvalidAuth() *adapter.ProviderEvent {
 	return s.wrapEvent(
 		adapter.EventAuthenticationError,
@@ -360,8 +519,9 @@ func newUserInfoFromDiscordUser(user *discordgo.User) *adapter.UserInfo {
 
 	u.ID = user.ID
 	u.Name = user.Username
-	u.DisplayName = user.Avatar
-	u.DisplayNameNormalized = user.Avatar
+	u.DisplayName = user.Username
+	u.DisplayNameNormalized = strings.ToLower(user.Username)
 	u.Email = user.Email
+	u.Locale = user.Locale
 	return u
 }
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUserInfoFromDiscordUser(t *testing.T) {
+	u := newUserInfoFromDiscordUser(&discordgo.User{
+		ID:       "1234",
+		Username: "Gort",
+		Avatar:   "a1b2c3",
+		Email:    "gort@example.com",
+		Locale:   "en-US",
+	})
+
+	assert.Equal(t, "1234", u.ID)
+	assert.Equal(t, "Gort", u.Name)
+	assert.Equal(t, "Gort", u.DisplayName)
+	assert.Equal(t, "gort", u.DisplayNameNormalized)
+	assert.Equal(t, "gort@example.com", u.Email)
+	assert.Equal(t, "en-US", u.Locale)
+}
+
+func TestNewChannelInfoFromDiscordChannel(t *testing.T) {
+	c := newChannelInfoFromDiscordChannel(&discordgo.Channel{
+		ID:         "5678",
+		Name:       "general",
+		Recipients: []*discordgo.User{{Username: "alice"}, {Username: "bob"}},
+	})
+
+	assert.Equal(t, "5678", c.ID)
+	assert.Equal(t, "general", c.Name)
+	assert.Equal(t, []string{"alice", "bob"}, c.Members)
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/templates"
+)
+
+// MessageDeleter is an optional interface implemented by adapters whose chat
+// provider allows the bot to delete messages that it has sent. It's used to
+// remove the output of commands marked with ephemeral_output.
+type MessageDeleter interface {
+	// SendDeletable sends a message to the specified channel, and returns
+	// an ID that can later be passed to DeleteMessage.
+	SendDeletable(ctx context.Context, channelID string, elements templates.OutputElements) (string, error)
+
+	// DeleteMessage deletes a message previously sent by SendDeletable.
+	DeleteMessage(ctx context.Context, channelID string, messageID string) error
+}
+
+// sendOutput sends a command's rendered output to a channel. Output from
+// commands marked ephemeral_output is sent directly (bypassing the send
+// queue, which may coalesce it with other messages) and scheduled for
+// deletion once its TTL expires. Scheduled deletions are held in memory, so
+// any still pending when the controller stops are lost.
+func sendOutput(ctx context.Context, a Adapter, channelID string, request data.CommandRequest, elements templates.OutputElements) (ephemeral bool, err error) {
+	ttl, err := request.Command.EphemeralDuration()
+	if err != nil || ttl == 0 {
+		return false, queueSend(ctx, a, channelID, elements)
+	}
+
+	d, ok := a.(MessageDeleter)
+	if !ok {
+		adapterLogEntry(ctx, log.WithContext(ctx), a).
+			WithField("command.name", request.Command.Name).
+			Warn("Adapter doesn't support message deletion; ephemeral output will not be deleted")
+		return false, queueSend(ctx, a, channelID, elements)
+	}
+
+	id, err := d.SendDeletable(ctx, channelID, elements)
+	if err != nil {
+		return true, err
+	}
+
+	scheduleDeletion(d, a, channelID, id, ttl)
+
+	return true, nil
+}
+
+// scheduleDeletion deletes the message with the given ID after ttl elapses.
+func scheduleDeletion(d MessageDeleter, a Adapter, channelID, messageID string, ttl time.Duration) {
+	time.AfterFunc(ttl, func() {
+		ctx := context.Background()
+
+		if err := d.DeleteMessage(ctx, channelID, messageID); err != nil {
+			adapterLogEntry(ctx, log.WithContext(ctx), a).
+				WithError(err).
+				WithField("channel.id", channelID).
+				WithField("message.id", messageID).
+				Error("Failed to delete ephemeral output")
+			return
+		}
+
+		adapterLogEntry(ctx, log.WithContext(ctx), a).
+			WithField("channel.id", channelID).
+			WithField("message.id", messageID).
+			Debug("Deleted ephemeral output")
+	})
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/templates"
+)
+
+// deleterTestAdapter records deletable sends and deletions.
+type deleterTestAdapter struct {
+	testAdapter
+
+	mx      sync.Mutex
+	sent    []string
+	deleted chan string
+}
+
+func (t *deleterTestAdapter) GetName() string {
+	return "deleter"
+}
+
+func (t *deleterTestAdapter) SendDeletable(ctx context.Context, channelID string, elements templates.OutputElements) (string, error) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	t.sent = append(t.sent, channelID)
+	return "M1", nil
+}
+
+func (t *deleterTestAdapter) DeleteMessage(ctx context.Context, channelID string, messageID string) error {
+	t.deleted <- channelID + "/" + messageID
+	return nil
+}
+
+func TestSendOutputEphemeral(t *testing.T) {
+	a := &deleterTestAdapter{deleted: make(chan string, 1)}
+	ctx := context.Background()
+
+	request := data.CommandRequest{}
+	request.Command.EphemeralOutput = true
+	request.Command.EphemeralTTL = "10ms"
+
+	ephemeral, err := sendOutput(ctx, a, "C1", request, templates.OutputElements{})
+	require.NoError(t, err)
+	assert.True(t, ephemeral)
+	assert.Equal(t, []string{"C1"}, a.sent)
+
+	select {
+	case id := <-a.deleted:
+		assert.Equal(t, "C1/M1", id)
+	case <-time.After(time.Second):
+		t.Fatal("ephemeral output was not deleted")
+	}
+}
@@ -18,6 +18,7 @@ package adapter
 
 import (
 	"fmt"
+	"time"
 )
 
 // Info is used by events to wrap user and provider info.
@@ -37,6 +38,9 @@ const (
 	EventDisconnected        EventType = "disconnected"
 	EventAuthenticationError EventType = "authentication_error"
 	EventError               EventType = "error"
+	EventInteraction         EventType = "interaction"
+	EventChannelChanged      EventType = "channel_changed"
+	EventUserChanged         EventType = "user_changed"
 )
 
 // ProviderEvent is the main wrapper. You will find all the other messages
@@ -53,6 +57,9 @@ type ProviderEvent struct {
 
 	// The adapter that generated the event
 	Adapter Adapter
+
+	// The time the event was received from the provider
+	Received time.Time
 }
 
 // AuthenticationErrorEvent indicates failure to authenticate
@@ -60,12 +67,23 @@ type AuthenticationErrorEvent struct {
 	Msg string
 }
 
+// Attachment describes a file attached to a message.
+type Attachment struct {
+	ID          string // The provider ID of the file, if it has one
+	Name        string // The file's name
+	ContentType string // The file's MIME type, if known
+	Size        int64  // The file's size in bytes, or zero if unknown
+	URL         string // The URL that the file can be downloaded from
+}
+
 // ChannelMessageEvent indicates received a message via a public or private
 // channel (message.channels)
 type ChannelMessageEvent struct {
-	ChannelID string
-	Text      string
-	UserID    string
+	Attachments []Attachment
+	ChannelID   string
+	MessageID   string
+	Text        string
+	UserID      string
 }
 
 // ConnectedEvent indicates the client has successfully connected to
@@ -87,9 +105,33 @@ type ChannelJoinedEvent struct {
 // DirectMessageEvent indicates the bot has received a direct message from a
 // user (message.im)
 type DirectMessageEvent struct {
+	Attachments []Attachment
+	ChannelID   string
+	MessageID   string
+	Text        string
+	UserID      string
+}
+
+// InteractionEvent indicates that a user has clicked a button or chosen an
+// option from a menu produced by a template's interactive elements.
+type InteractionEvent struct {
 	ChannelID string
-	Text      string
+	MessageID string // The ID of the message containing the element
 	UserID    string
+	Command   string // The element's command line, as "bundle:command args"
+	Selection string // The selected option, if any
+}
+
+// ChannelChangedEvent indicates that a channel's info, such as its name, has
+// changed.
+type ChannelChangedEvent struct {
+	ChannelID string
+}
+
+// UserChangedEvent indicates that a user's info, such as their name or
+// email address, has changed.
+type UserChangedEvent struct {
+	UserID string
 }
 
 // ErrorEvent indicates an error reported by the provider. The occurs before a
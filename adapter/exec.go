@@ -0,0 +1,158 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/auth"
+	"github.com/getgort/gort/command"
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// Exec executes a command on behalf of a Gort user, exactly as if they'd typed
+// it in chat, and returns its output rather than sending it to a channel. It's
+// intended for automation like schedulers, webhooks, and CI, which usually
+// authenticate as service accounts. The command's rules are evaluated against
+// the user's permissions, and the request is recorded under the user's name,
+// as in chat. Macros and triggers aren't expanded.
+func Exec(ctx context.Context, user rest.User, r rest.ExecRequest) (rest.ExecResult, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.Exec")
+	defer sp.End()
+
+	sp.SetAttributes(attribute.String("user.username", user.Username))
+
+	if pipelineRequests == nil {
+		return rest.ExecResult{}, ErrNotListening
+	}
+
+	tokens, err := command.Tokenize(r.Command)
+	if err != nil {
+		return rest.ExecResult{}, err
+	}
+	if len(tokens) == 0 {
+		return rest.ExecResult{}, errs.ErrFieldRequired
+	}
+
+	cmdEntry, cmdInput, err := commandFromTokensByName(ctx, tokens)
+	switch {
+	case gerrs.Is(err, ErrNoSuchCommand):
+		return rest.ExecResult{}, gerrs.Wrap(errs.ErrNoSuchBundle, fmt.Errorf("no such command: %s", tokens[0]))
+	case gerrs.Is(err, ErrMultipleCommands):
+		return rest.ExecResult{}, gerrs.Wrap(errs.ErrNoSuchBundle,
+			fmt.Errorf("the command %s matches multiple bundles; use bundle:command", tokens[0]))
+	case err != nil:
+		return rest.ExecResult{}, err
+	}
+
+	request, err := newExecRequest(ctx, user, r, *cmdEntry, cmdInput)
+	if err != nil {
+		return rest.ExecResult{}, err
+	}
+
+	timeout, _ := cmdEntry.Command.TimeoutDuration()
+	if timeout <= 0 {
+		timeout = config.GetGlobalConfigs().CommandTimeout
+	}
+	if timeout <= 0 {
+		timeout = selfTestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	request.Deadline = request.Timestamp.Add(timeout)
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return rest.ExecResult{}, err
+	}
+
+	if err := da.RequestBegin(ctx, &request); err != nil {
+		return rest.ExecResult{}, err
+	}
+
+	// Exec shares the self-test's plumbing, so that the response comes back
+	// to us instead of going to a chat provider.
+	envelope, err := runSelfTestRequest(ctx, request)
+	if err != nil {
+		return rest.ExecResult{}, err
+	}
+
+	result := rest.ExecResult{
+		RequestID:     request.RequestID,
+		Bundle:        request.Bundle.Name,
+		BundleVersion: request.Bundle.Version,
+		Command:       request.Command.Name,
+		ExitCode:      envelope.Data.ExitCode,
+		Output:        envelope.Response.Lines,
+		Duration:      envelope.Data.Duration,
+	}
+
+	if envelope.Data.Error != nil {
+		result.Error = envelope.Data.Error.Error()
+	}
+
+	return result, nil
+}
+
+// newExecRequest builds a request for an executed command, after checking
+// that the user is permitted to execute it.
+func newExecRequest(ctx context.Context, user rest.User, r rest.ExecRequest, cmdEntry data.CommandEntry, cmdInput command.Command) (data.CommandRequest, error) {
+	id := RequestorIdentity{GortUser: &user}
+
+	cmdInput, err := applyOptionDefaults(ctx, cmdInput, cmdEntry, id)
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	profileName, profile, cmdParams, err := selectProfile(cmdInput, cmdEntry)
+	if err != nil {
+		return data.CommandRequest{}, gerrs.Wrap(errs.ErrNoSuchBundle,
+			fmt.Errorf("the command %s:%s has no such profile", cmdEntry.Bundle.Name, cmdEntry.Command.Name))
+	}
+
+	err = checkPermissions(ctx, id, cmdInput, cmdEntry, profile)
+	switch {
+	case gerrs.Is(err, auth.ErrNoRulesDefined), gerrs.Is(err, ErrNotAllowed):
+		return data.CommandRequest{}, gerrs.Wrap(gerrs.ErrNotPermitted,
+			fmt.Errorf("%s may not execute %s:%s: %w", user.Username, cmdEntry.Bundle.Name, cmdEntry.Command.Name, err))
+	case err != nil:
+		return data.CommandRequest{}, err
+	}
+
+	return data.CommandRequest{
+		CommandEntry:   cmdEntry,
+		InvocationText: r.Command,
+		Parameters:     parametersFromCommand(cmdParams),
+		Profile:        profileName,
+		Timestamp:      time.Now(),
+		Timings:        data.StageTimings{},
+		UserEmail:      user.Email,
+		UserName:       user.Username,
+	}, nil
+}
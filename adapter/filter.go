@@ -0,0 +1,49 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/telemetry"
+)
+
+// filterOutput applies the global and bundle output filters to the
+// envelope's response lines. Since the filters exist to keep secrets out of
+// chat, the output is withheld entirely if they can't be applied.
+func filterOutput(ctx context.Context, envelope *data.CommandResponseEnvelope) {
+	filters := config.GetGlobalConfigs().OutputFilters.Merge(envelope.Request.Bundle.OutputFilters)
+	if filters.IsZero() || len(envelope.Response.Lines) == 0 {
+		return
+	}
+
+	lines, err := filters.Apply(envelope.Response.Lines)
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).
+			WithField("request.id", envelope.Request.RequestID).
+			WithField("bundle.name", envelope.Request.Bundle.Name).
+			Error("Failed to apply output filters; output withheld")
+		lines = []string{"[output withheld: invalid output filter]"}
+	}
+
+	data.WithResponseLines(lines)(envelope)
+}
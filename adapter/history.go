@@ -0,0 +1,153 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getgort/gort/command"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+const (
+	// repeatShortcut, typed after the leading "!" (so "!!" in chat),
+	// repeats the user's last command in the channel.
+	repeatShortcut = "!"
+
+	// historyShortcut lists the user's recent commands in the channel,
+	// unless an installed command has the same name.
+	historyShortcut = "history"
+
+	defaultHistoryLength = 10
+	maxHistoryLength     = 50
+)
+
+// recallHistory handles the "!!" and "!history" chat shortcuts, which are
+// scoped to the requesting user and the channel the message was sent in.
+// The text is the message less its leading "!". If it isn't a shortcut,
+// the returned bool is false and the message should be handled as usual.
+func recallHistory(ctx context.Context, text string, id RequestorIdentity) (*data.CommandRequest, bool, error) {
+	text = strings.TrimSpace(text)
+
+	if text == repeatShortcut {
+		request, err := repeatLastCommand(ctx, id)
+		return request, true, err
+	}
+
+	tokens, err := command.Tokenize(text)
+	if err != nil || len(tokens) == 0 || tokens[0] != historyShortcut {
+		return nil, false, nil
+	}
+
+	// An installed command named "history" takes precedence.
+	lookup := append([]string{}, tokens...)
+	if _, _, err := commandFromTokensByName(ctx, lookup); !gerrs.Is(err, ErrNoSuchCommand) {
+		return nil, false, nil
+	}
+
+	return nil, true, showHistory(ctx, id, tokens[1:])
+}
+
+// repeatLastCommand builds a request that re-executes the user's most
+// recent command in the channel. The command is looked up and its rules
+// evaluated again, exactly as if the user had typed it.
+func repeatLastCommand(ctx context.Context, id RequestorIdentity) (*data.CommandRequest, error) {
+	records, err := requestHistory(ctx, id, 1)
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Error", unexpectedError)
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "No Previous Command",
+			"You haven't run any commands in this channel yet.")
+		return nil, ErrNoPreviousCommand
+	}
+
+	last := records[0]
+	if strings.Contains(last.Parameters, data.RedactedValue) {
+		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Command Can't Be Repeated",
+			"Some of your last command's parameters were redacted, so it can't be repeated. Please run it in full.")
+		return nil, ErrRepeatRedacted
+	}
+
+	return GetCommandRequest(ctx, historyEntryText(last), id, commandFromTokensByName)
+}
+
+// showHistory sends the user a list of their recent commands in the
+// channel, newest first. The only argument is an optional count.
+func showHistory(ctx context.Context, id RequestorIdentity, args []string) error {
+	limit := defaultHistoryLength
+
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || len(args) > 1 {
+			msg := fmt.Sprintf("Usage: `!%s [count]`, where count is between 1 and %d.", historyShortcut, maxHistoryLength)
+			return SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Usage", msg)
+		}
+		if n > maxHistoryLength {
+			n = maxHistoryLength
+		}
+		limit = n
+	}
+
+	records, err := requestHistory(ctx, id, limit)
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Error", unexpectedError)
+		return err
+	}
+
+	if len(records) == 0 {
+		return SendMessage(ctx, id.Adapter, id.ChatChannel.ID, "You haven't run any commands in this channel yet.")
+	}
+
+	lines := []string{"Your recent commands in this channel, newest first. Use `!!` to repeat the first."}
+	for i, r := range records {
+		line := fmt.Sprintf("%d. `%s`", i+1, historyEntryText(r))
+		if r.Closed && r.ExitCode != 0 {
+			line += " (failed)"
+		}
+		lines = append(lines, line)
+	}
+
+	return SendMessage(ctx, id.Adapter, id.ChatChannel.ID, strings.Join(lines, "\n"))
+}
+
+// requestHistory returns up to limit of the requestor's most recent
+// commands in the channel, newest first.
+func requestHistory(ctx context.Context, id RequestorIdentity, limit int) ([]data.RequestRecord, error) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return da.RequestHistory(ctx, id.Adapter.GetName(), id.ChatChannel.ID, id.ChatUser.ID, limit)
+}
+
+// historyEntryText returns a recorded request as the command text that
+// would reproduce it.
+func historyEntryText(r data.RequestRecord) string {
+	return strings.TrimSpace(fmt.Sprintf("%s:%s %s", r.BundleName, r.CommandName, r.Parameters))
+}
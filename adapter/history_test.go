@@ -0,0 +1,110 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/templates"
+)
+
+// historyTestAdapter records the text of everything it sends.
+type historyTestAdapter struct {
+	testAdapter
+
+	mx   sync.Mutex
+	sent []string
+}
+
+func (t *historyTestAdapter) Send(ctx context.Context, channelID string, elements templates.OutputElements) error {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	t.sent = append(t.sent, elements.Alt())
+	return nil
+}
+
+func (t *historyTestAdapter) last() string {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if len(t.sent) == 0 {
+		return ""
+	}
+	return t.sent[len(t.sent)-1]
+}
+
+func TestChannelMessageHistory(t *testing.T) {
+	a := &historyTestAdapter{}
+	ctx := context.Background()
+
+	send := func(channel, user, text string) (string, error) {
+		request, err := OnChannelMessage(ctx,
+			&ProviderEvent{
+				EventType: EventChannelMessage,
+				Info:      &Info{Provider: &ProviderInfo{Type: "test", Name: "provider"}},
+				Adapter:   a,
+			},
+			&ChannelMessageEvent{ChannelID: channel, Text: text, UserID: user},
+		)
+		if request == nil {
+			return "", err
+		}
+		return request.String(), err
+	}
+
+	_, err := send("historychannel", "user", "!!")
+	assert.True(t, gerrs.Is(err, ErrNoPreviousCommand), err)
+
+	for _, m := range []struct{ channel, text string }{
+		{"historychannel", "!test:cmd one two"},
+		{"historychannel", "!test:cmd three"},
+		{"otherchannel", "!test:cmd four"}, // Other channels have their own history
+	} {
+		_, err := send(m.channel, "user", m.text)
+		require.NoError(t, err)
+	}
+
+	repeated, err := send("historychannel", "user", "!!")
+	require.NoError(t, err)
+	assert.Equal(t, "test:cmd three", repeated)
+
+	_, err = send("historychannel", "user", "!history")
+	require.NoError(t, err)
+	out := a.last()
+	assert.Contains(t, out, "1. `test:cmd three`")
+	assert.Contains(t, out, "2. `test:cmd three`")
+	assert.Contains(t, out, "3. `test:cmd one two`")
+	assert.NotContains(t, out, "four")
+
+	_, err = send("historychannel", "user", "!history 1")
+	require.NoError(t, err)
+	out = a.last()
+	assert.Contains(t, out, "1. `test:cmd three`")
+	assert.False(t, strings.Contains(out, "2. "))
+
+	_, err = send("historychannel", "user", "!history lots")
+	require.NoError(t, err)
+	assert.Contains(t, a.last(), "Usage")
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/telemetry"
+)
+
+var (
+	// infoCacheNow returns the current time. It's a variable so that tests
+	// can change it.
+	infoCacheNow = time.Now
+
+	channelInfoCache = map[string]cachedChannelInfo{}
+	userInfoCache    = map[string]cachedUserInfo{}
+	infoCacheMx      sync.Mutex
+)
+
+type cachedChannelInfo struct {
+	info    *ChannelInfo
+	expires time.Time
+}
+
+type cachedUserInfo struct {
+	info    *UserInfo
+	expires time.Time
+}
+
+// getChannelInfo returns a's GetChannelInfo for channelID, which is cached
+// for global.provider_info_ttl to spare the provider's rate limits. Errors
+// aren't cached.
+func getChannelInfo(ctx context.Context, a Adapter, channelID string) (*ChannelInfo, error) {
+	key := infoCacheKey(a.GetName(), channelID)
+	ttl := providerInfoTTL()
+
+	if ttl > 0 {
+		infoCacheMx.Lock()
+		c, ok := channelInfoCache[key]
+		infoCacheMx.Unlock()
+
+		if ok && infoCacheNow().Before(c.expires) {
+			countInfoLookup(ctx, a, "channel", true)
+			return c.info, nil
+		}
+	}
+
+	countInfoLookup(ctx, a, "channel", false)
+
+	info, err := a.GetChannelInfo(channelID)
+	if err != nil || ttl <= 0 {
+		return info, err
+	}
+
+	infoCacheMx.Lock()
+	channelInfoCache[key] = cachedChannelInfo{info, infoCacheNow().Add(ttl)}
+	infoCacheMx.Unlock()
+
+	return info, nil
+}
+
+// getUserInfo returns a's GetUserInfo for userID, which is cached for
+// global.provider_info_ttl to spare the provider's rate limits. Errors
+// aren't cached.
+func getUserInfo(ctx context.Context, a Adapter, userID string) (*UserInfo, error) {
+	key := infoCacheKey(a.GetName(), userID)
+	ttl := providerInfoTTL()
+
+	if ttl > 0 {
+		infoCacheMx.Lock()
+		c, ok := userInfoCache[key]
+		infoCacheMx.Unlock()
+
+		if ok && infoCacheNow().Before(c.expires) {
+			countInfoLookup(ctx, a, "user", true)
+			return c.info, nil
+		}
+	}
+
+	countInfoLookup(ctx, a, "user", false)
+
+	info, err := a.GetUserInfo(userID)
+	if err != nil || ttl <= 0 {
+		return info, err
+	}
+
+	infoCacheMx.Lock()
+	userInfoCache[key] = cachedUserInfo{info, infoCacheNow().Add(ttl)}
+	infoCacheMx.Unlock()
+
+	return info, nil
+}
+
+// OnChannelChanged handles ChannelChangedEvent events by discarding the
+// cached info for the channel.
+func OnChannelChanged(ctx context.Context, event *ProviderEvent, data *ChannelChangedEvent) {
+	infoCacheMx.Lock()
+	defer infoCacheMx.Unlock()
+
+	delete(channelInfoCache, infoCacheKey(event.Adapter.GetName(), data.ChannelID))
+}
+
+// OnUserChanged handles UserChangedEvent events by discarding the cached
+// info for the user.
+func OnUserChanged(ctx context.Context, event *ProviderEvent, data *UserChangedEvent) {
+	infoCacheMx.Lock()
+	defer infoCacheMx.Unlock()
+
+	delete(userInfoCache, infoCacheKey(event.Adapter.GetName(), data.UserID))
+}
+
+func countInfoLookup(ctx context.Context, a Adapter, kind string, hit bool) {
+	telemetry.ProviderInfoLookups().
+		WithAttribute("adapter", a.GetName()).
+		WithAttribute("kind", kind).
+		WithAttribute("hit", hit).
+		Commit(ctx)
+}
+
+func infoCacheKey(adapterName, id string) string {
+	return adapterName + "/" + id
+}
+
+func providerInfoTTL() time.Duration {
+	ttl := config.GetGlobalConfigs().ProviderInfoTTL
+	if ttl == 0 {
+		ttl = data.DefaultProviderInfoTTL
+	}
+	return ttl
+}
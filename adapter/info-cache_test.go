@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// infoCountingTestAdapter counts calls to GetChannelInfo and GetUserInfo.
+type infoCountingTestAdapter struct {
+	testAdapter
+
+	channelLookups int
+	userLookups    int
+}
+
+func (t *infoCountingTestAdapter) GetName() string {
+	return "infoCountingTestAdapter"
+}
+
+func (t *infoCountingTestAdapter) GetChannelInfo(channelID string) (*ChannelInfo, error) {
+	t.channelLookups++
+	return t.testAdapter.GetChannelInfo(channelID)
+}
+
+func (t *infoCountingTestAdapter) GetUserInfo(userID string) (*UserInfo, error) {
+	t.userLookups++
+	return t.testAdapter.GetUserInfo(userID)
+}
+
+func TestProviderInfoCache(t *testing.T) {
+	ctx := context.Background()
+	a := &infoCountingTestAdapter{}
+	event := &ProviderEvent{Adapter: a}
+
+	now := time.Now()
+	infoCacheNow = func() time.Time { return now }
+	defer func() { infoCacheNow = time.Now }()
+
+	for i := 0; i < 3; i++ {
+		c, err := getChannelInfo(ctx, a, "C1")
+		require.NoError(t, err)
+		assert.Equal(t, "C1", c.ID)
+
+		u, err := getUserInfo(ctx, a, "U1")
+		require.NoError(t, err)
+		assert.Equal(t, "U1", u.ID)
+	}
+
+	assert.Equal(t, 1, a.channelLookups)
+	assert.Equal(t, 1, a.userLookups)
+
+	// Change events discard the cached info.
+	OnChannelChanged(ctx, event, &ChannelChangedEvent{ChannelID: "C1"})
+	OnUserChanged(ctx, event, &UserChangedEvent{UserID: "U1"})
+
+	getChannelInfo(ctx, a, "C1")
+	getUserInfo(ctx, a, "U1")
+	assert.Equal(t, 2, a.channelLookups)
+	assert.Equal(t, 2, a.userLookups)
+
+	// So does time.
+	now = now.Add(providerInfoTTL())
+
+	getChannelInfo(ctx, a, "C1")
+	getUserInfo(ctx, a, "U1")
+	assert.Equal(t, 3, a.channelLookups)
+	assert.Equal(t, 3, a.userLookups)
+}
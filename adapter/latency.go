@@ -0,0 +1,66 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// finishRequestTimings is called once a request's response has been sent.
+// It warns about any stage that exceeded its configured latency budget, and
+// stores the request's complete stage timings.
+func finishRequestTimings(ctx context.Context, request data.CommandRequest) {
+	if request.RequestID == 0 || request.Timings == nil {
+		return
+	}
+
+	le := log.WithField("request.id", request.RequestID).
+		WithField("bundle.name", request.Bundle.Name).
+		WithField("command.name", request.Command.Name)
+
+	budgets := config.GetGlobalConfigs().LatencyBudgets
+	for _, stage := range request.Timings.OverBudget(budgets) {
+		le.WithField("stage", stage).
+			WithField("stage.duration", request.Timings[stage]).
+			WithField("stage.budget", budgets[stage]).
+			Warn("Request stage exceeded its latency budget")
+
+		telemetry.SlowStages().
+			WithAttribute("stage", string(stage)).
+			WithAttribute("bundle.name", request.Bundle.Name).
+			WithAttribute("command.name", request.Command.Name).
+			Commit(ctx)
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Warn("Failed to get data access; request timings not stored")
+		return
+	}
+
+	if err := da.RequestUpdateTimings(ctx, request.RequestID, request.Timings); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to store request timings")
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+
+	"github.com/getgort/gort/command"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+// expandMacro checks whether tokens invoke a macro that's visible to the
+// requesting user and, if so, returns the macro and the tokens of its
+// expanded command. The remaining tokens are the macro's name=value
+// arguments. If no such macro exists, a nil macro is returned. Macros are
+// expanded exactly once: a macro whose command names another macro won't
+// be expanded again.
+func expandMacro(ctx context.Context, tokens []string, id RequestorIdentity) (*data.Macro, []string, error) {
+	if len(tokens) == 0 || id.GortUser == nil {
+		return nil, nil, nil
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	macros, err := da.MacroList(ctx, tokens[0])
+	if err != nil || len(macros) == 0 {
+		return nil, nil, err
+	}
+
+	groups, err := da.UserGroupList(ctx, id.GortUser.Username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var groupNames []string
+	for _, g := range groups {
+		groupNames = append(groupNames, g.Name)
+	}
+
+	macro, ok := data.ResolveMacro(macros, tokens[0], id.GortUser.Username, groupNames)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	args, err := data.ParseMacroArguments(tokens[1:])
+	if err != nil {
+		return &macro, nil, err
+	}
+
+	expanded, err := macro.Expand(args)
+	if err != nil {
+		return &macro, nil, err
+	}
+
+	expandedTokens, err := command.Tokenize(expanded)
+	if err != nil {
+		return &macro, nil, err
+	}
+
+	return &macro, expandedTokens, nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// outboxEnabled returns true if responses are persisted in the outbox
+// before they're sent.
+func outboxEnabled() bool {
+	return config.GetGlobalConfigs().Outbox.Enabled
+}
+
+// alreadyDelivered returns true if the envelope's response is recorded in
+// the outbox as already delivered, in which case it isn't sent again. This
+// guards against a response being sent by both the relay and the outbox
+// dispatcher. Failures are logged, and treated as not delivered.
+func alreadyDelivered(ctx context.Context, envelope data.CommandResponseEnvelope) bool {
+	if !outboxEnabled() || envelope.Request.RequestID == 0 {
+		return false
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return false
+	}
+
+	entry, err := da.OutboxGet(ctx, envelope.Request.RequestID)
+	if err != nil {
+		return false
+	}
+
+	return entry.IsDelivered()
+}
+
+// markDelivered records in the outbox that the envelope's response has been
+// delivered, so that it isn't resent. Failures are logged but otherwise
+// ignored; at worst, the response is delivered again.
+func markDelivered(ctx context.Context, envelope data.CommandResponseEnvelope) {
+	if !outboxEnabled() || envelope.Request.RequestID == 0 {
+		return
+	}
+
+	le := log.WithField("request.id", envelope.Request.RequestID)
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Warn("Failed to get data access; response not marked as delivered")
+		return
+	}
+
+	if err := da.OutboxMarkDelivered(ctx, envelope.Request.RequestID, time.Now().UTC()); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to mark response as delivered")
+	}
+}
+
+// startOutboxDispatch resends undelivered responses from the outbox by
+// passing them back through the responses channel, first when it's started
+// (to pick up responses left over from before a restart) and then every
+// global.outbox.retry_interval, until the context is cancelled.
+func startOutboxDispatch(ctx context.Context, responses chan<- data.CommandResponseEnvelope) {
+	interval := config.GetGlobalConfigs().Outbox.RetryIntervalOrDefault()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		dispatchOutbox(ctx, responses)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchOutbox resends each response that hasn't been delivered or
+// attempted within the retry interval. Responses that have been attempted
+// global.outbox.max_attempts times are given up on.
+func dispatchOutbox(ctx context.Context, responses chan<- data.CommandResponseEnvelope) {
+	c := config.GetGlobalConfigs().Outbox
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get data access; outbox not dispatched")
+		return
+	}
+
+	now := time.Now().UTC()
+
+	pending, err := da.OutboxPending(ctx, now.Add(-c.RetryIntervalOrDefault()))
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).Error("Failed to list undelivered responses")
+		return
+	}
+
+	for _, entry := range pending {
+		le := log.WithField("request.id", entry.RequestID).
+			WithField("adapter.name", entry.Adapter).
+			WithField("channel.id", entry.ChannelID).
+			WithField("attempts", entry.Attempts)
+
+		if entry.Attempts >= c.MaxAttemptsOrDefault() {
+			le.Error("Giving up on undeliverable response")
+			if err := da.OutboxMarkDelivered(ctx, entry.RequestID, now); err != nil {
+				le.WithError(err).Error("Failed to mark response as given up on")
+			}
+			continue
+		}
+
+		envelope, err := entry.CommandResponseEnvelope()
+		if err != nil {
+			le.WithError(err).Error("Failed to decode undelivered response; giving up")
+			da.OutboxMarkDelivered(ctx, entry.RequestID, now)
+			continue
+		}
+
+		if err := da.OutboxMarkAttempted(ctx, entry.RequestID, now); err != nil {
+			telemetry.Errors().WithError(err).Commit(ctx)
+			le.WithError(err).Error("Failed to record delivery attempt")
+			continue
+		}
+
+		le.Info("Resending undelivered response")
+
+		select {
+		case <-ctx.Done():
+			return
+		case responses <- envelope:
+		}
+	}
+}
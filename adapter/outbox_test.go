@@ -0,0 +1,81 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestDispatchOutbox(t *testing.T) {
+	ctx := context.Background()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	newEntry := func(id int64, attempts int) data.OutboxEntry {
+		request := data.CommandRequest{RequestID: id, Adapter: "test", ChannelID: "C-OUTBOX"}
+		entry, err := data.NewOutboxEntry(data.NewCommandResponseEnvelope(request,
+			data.WithResponseLines([]string{"hello"})))
+		require.NoError(t, err)
+
+		entry.Attempts = attempts
+		entry.LastAttempt = entry.LastAttempt.Add(-time.Hour)
+		return entry
+	}
+
+	pending := newEntry(7001, 0)
+	exhausted := newEntry(7002, data.DefaultOutboxMaxAttempts)
+	require.NoError(t, da.OutboxCreate(ctx, pending))
+	require.NoError(t, da.OutboxCreate(ctx, exhausted))
+
+	// A response that was attempted recently isn't resent yet.
+	recent := newEntry(7003, 1)
+	recent.LastAttempt = time.Now().UTC()
+	require.NoError(t, da.OutboxCreate(ctx, recent))
+
+	responses := make(chan data.CommandResponseEnvelope, 3)
+	dispatchOutbox(ctx, responses)
+	close(responses)
+
+	var resent []int64
+	for envelope := range responses {
+		resent = append(resent, envelope.Request.RequestID)
+		assert.Equal(t, []string{"hello"}, envelope.Response.Lines)
+	}
+	assert.Equal(t, []int64{pending.RequestID}, resent)
+
+	got, err := da.OutboxGet(ctx, pending.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Attempts)
+	assert.False(t, got.IsDelivered())
+
+	got, err = da.OutboxGet(ctx, exhausted.RequestID)
+	require.NoError(t, err)
+	assert.True(t, got.IsDelivered())
+
+	got, err = da.OutboxGet(ctx, recent.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Attempts)
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+// maxFailureSummaryLength is the length to which the error summary of each
+// failed request is truncated in owner notices.
+const maxFailureSummaryLength = 120
+
+// commandFailure is a single failed execution of a command.
+type commandFailure struct {
+	RequestID int64
+	Time      time.Time
+	Summary   string
+}
+
+// failureTracker counts the recent failures of each command, keyed by
+// "bundle:command". Counts are kept in memory, so each controller counts
+// only the failures of the requests that it handled.
+type failureTracker struct {
+	sync.Mutex
+	failures map[string][]commandFailure
+}
+
+var failures = &failureTracker{failures: map[string][]commandFailure{}}
+
+// record adds a failure of the keyed command, and forgets those older than
+// window. If that brings the command's count to threshold, the counted
+// failures are returned and the count is reset; otherwise it returns nil.
+func (t *failureTracker) record(key string, f commandFailure, window time.Duration, threshold int) []commandFailure {
+	t.Lock()
+	defer t.Unlock()
+
+	var recent []commandFailure
+	for _, g := range t.failures[key] {
+		if f.Time.Sub(g.Time) <= window {
+			recent = append(recent, g)
+		}
+	}
+	recent = append(recent, f)
+
+	if len(recent) < threshold {
+		t.failures[key] = recent
+		return nil
+	}
+
+	delete(t.failures, key)
+	return recent
+}
+
+// trackFailure records the failure of a command, if the response is one,
+// and notifies the bundle's owners if the command has now failed too many
+// times. The notice is sent asynchronously.
+func trackFailure(envelope data.CommandResponseEnvelope) {
+	c := config.GetGlobalConfigs().FailureNotices
+	bundle := envelope.Request.Bundle
+
+	if c.Disabled || envelope.Data.ExitCode == 0 || len(bundle.Owners) == 0 {
+		return
+	}
+
+	f := commandFailure{
+		RequestID: envelope.Request.RequestID,
+		Time:      time.Now(),
+		Summary:   failureSummary(envelope),
+	}
+
+	key := bundle.Name + ":" + envelope.Request.Command.Name
+	recent := failures.record(key, f, c.WindowOrDefault(), c.ThresholdOrDefault())
+	if recent == nil {
+		return
+	}
+
+	go notifyOwners(context.Background(), bundle, key, recent, c)
+}
+
+// failureSummary returns a single line describing why a command failed.
+func failureSummary(envelope data.CommandResponseEnvelope) string {
+	var summary string
+
+	switch {
+	case envelope.Data.ErrorDetail != nil:
+		summary = envelope.Data.ErrorDetail.Message
+	case envelope.Data.Error != nil:
+		summary = envelope.Data.Error.Error()
+	default:
+		for i := len(envelope.Response.Lines) - 1; i >= 0; i-- {
+			if line := strings.TrimSpace(envelope.Response.Lines[i]); line != "" {
+				summary = line
+				break
+			}
+		}
+	}
+
+	if summary == "" {
+		summary = fmt.Sprintf("exited with status %d", envelope.Data.ExitCode)
+	}
+
+	if len(summary) > maxFailureSummaryLength {
+		summary = summary[:maxFailureSummaryLength-3] + "..."
+	}
+
+	return summary
+}
+
+// failureNotice builds the text of a notice about the repeated failures of
+// the command.
+func failureNotice(command string, owners []string, recent []commandFailure, window time.Duration) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "The command %s (owned by %s) failed %d times in the last %s:\n",
+		command, strings.Join(owners, ", "), len(recent), window)
+	for _, f := range recent {
+		fmt.Fprintf(&b, "- request %d: %s\n", f.RequestID, f.Summary)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// notifyOwners tells the owners of a bundle that one of its commands has
+// failed repeatedly, either through the configured notification channel or
+// by direct message to each member of the owning groups. Failures are logged
+// but otherwise ignored.
+func notifyOwners(ctx context.Context, bundle data.Bundle, command string, recent []commandFailure, c data.FailureNotificationConfigs) {
+	msg := failureNotice(command, bundle.Owners, recent, c.WindowOrDefault())
+
+	le := log.WithField("bundle.name", bundle.Name).WithField("command", command)
+
+	if c.NotifyAdapter != "" {
+		a, err := GetAdapter(c.NotifyAdapter)
+		if err != nil {
+			le.WithError(err).WithField("adapter.name", c.NotifyAdapter).
+				Warn("Failed to get failure notification adapter")
+			return
+		}
+
+		if err := SendMessage(ctx, a, c.NotifyChannel, msg); err != nil {
+			le.WithError(err).WithField("adapter.name", c.NotifyAdapter).
+				WithField("channel.id", c.NotifyChannel).
+				Warn("Failed to send failure notification")
+		}
+		return
+	}
+
+	notifyGroups(ctx, bundle.Owners, msg, le)
+}
+
+// notifyGroups sends a direct message to each member of the named groups.
+// Failures are logged to le but otherwise ignored.
+func notifyGroups(ctx context.Context, groups []string, msg string, le *log.Entry) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Warn("Failed to get data access; notification not sent")
+		return
+	}
+
+	// A user in more than one group is only notified once.
+	notified := map[string]bool{}
+
+	for _, group := range groups {
+		members, err := da.GroupUserList(ctx, group)
+		if err != nil {
+			le.WithError(err).WithField("group.name", group).
+				Warn("Failed to list group members")
+			continue
+		}
+
+		for _, m := range members {
+			if notified[m.Username] {
+				continue
+			}
+			notified[m.Username] = true
+
+			user, err := da.UserGet(ctx, m.Username)
+			if err != nil {
+				le.WithError(err).WithField("user.name", m.Username).
+					Warn("Failed to get group member")
+				continue
+			}
+
+			SendDirectMessage(ctx, user, rest.DirectMessage{Message: msg})
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestFailureTrackerRecord(t *testing.T) {
+	tracker := &failureTracker{failures: map[string][]commandFailure{}}
+	start := time.Now()
+
+	record := func(id int64, offset time.Duration) []commandFailure {
+		f := commandFailure{RequestID: id, Time: start.Add(offset)}
+		return tracker.record("test:echox", f, time.Minute, 3)
+	}
+
+	assert.Nil(t, record(1, 0))
+	assert.Nil(t, record(2, 10*time.Second))
+
+	// The first failure has aged out of the window by now.
+	assert.Nil(t, record(3, 65*time.Second))
+
+	// The second is exactly a window old, which still counts.
+	recent := record(4, 70*time.Second)
+	require.Len(t, recent, 3)
+	assert.Equal(t, int64(2), recent[0].RequestID)
+	assert.Equal(t, int64(4), recent[2].RequestID)
+
+	// The count is reset after a notice.
+	assert.Nil(t, record(5, 75*time.Second))
+}
+
+func TestFailureSummary(t *testing.T) {
+	envelope := data.CommandResponseEnvelope{
+		Response: data.CommandResponse{Lines: []string{"first", "last", ""}},
+		Data:     data.CommandResponseData{ExitCode: 1},
+	}
+	assert.Equal(t, "last", failureSummary(envelope))
+
+	envelope.Data.Error = errors.New("failed to spawn worker")
+	assert.Equal(t, "failed to spawn worker", failureSummary(envelope))
+
+	envelope.Data.ErrorDetail = &data.ErrorDetail{Message: "quota exceeded"}
+	assert.Equal(t, "quota exceeded", failureSummary(envelope))
+
+	envelope = data.CommandResponseEnvelope{Data: data.CommandResponseData{ExitCode: 2}}
+	assert.Equal(t, "exited with status 2", failureSummary(envelope))
+}
+
+func TestFailureNotice(t *testing.T) {
+	recent := []commandFailure{
+		{RequestID: 12, Summary: "quota exceeded"},
+		{RequestID: 15, Summary: "exited with status 1"},
+	}
+
+	expected := "The command test:echox (owned by ops, payments) failed 2 times in the last 15m0s:\n" +
+		"- request 12: quota exceeded\n" +
+		"- request 15: exited with status 1"
+
+	assert.Equal(t, expected, failureNotice("test:echox", []string{"ops", "payments"}, recent, 15*time.Minute))
+}
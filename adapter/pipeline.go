@@ -0,0 +1,122 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/getgort/gort/command"
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+var (
+	// ErrPipelineAborted is recorded for the requests of a pipeline that
+	// was abandoned because one of its commands couldn't be looked up or
+	// authorized.
+	ErrPipelineAborted = errors.New("pipeline aborted")
+
+	// ErrSecretOutputPiped is returned when the output of a command with
+	// secret output would be piped to another command.
+	ErrSecretOutputPiped = errors.New("secret output can't be piped")
+)
+
+// pipelinesEnabled returns whether the pipelines feature flag is enabled. If
+// it isn't, commands are built with GetCommandRequest, so a "|" is passed to
+// the command like any other argument.
+func pipelinesEnabled() bool {
+	return config.FeatureEnabled(data.FeaturePipelines)
+}
+
+// GetPipelineRequest is like GetCommandRequest, except that rawCommand may
+// be a pipeline: a sequence of commands separated by pipes ("|"), each of
+// which receives the output of the one before as its input file. A request
+// is built for each command, linked in order by their Next fields, and the
+// first is returned. Each command is looked up and authorized as if it had
+// been typed alone, so any of them may fail with the usual messages to the
+// user, in which case the requests already begun for the pipeline are
+// closed.
+func GetPipelineRequest(
+	ctx context.Context,
+	rawCommand string,
+	id RequestorIdentity,
+	fCommandFromTokens commandFromTokens,
+) (*data.CommandRequest, error) {
+	stages, err := command.SplitPipeline(rawCommand)
+	switch {
+	case errors.Is(err, command.ErrEmptyPipelineCommand):
+		msg := "Every pipe (`|`) in a pipeline must be between two commands."
+		SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Pipeline Error", msg)
+		return nil, err
+	case err != nil || len(stages) == 1:
+		return GetCommandRequest(ctx, rawCommand, id, fCommandFromTokens)
+	}
+
+	var head, tail *data.CommandRequest
+
+	for i, stage := range stages {
+		request, err := GetCommandRequest(ctx, stage, id, fCommandFromTokens)
+		if request == nil || err != nil {
+			abortPipeline(ctx, head, err)
+			return nil, err
+		}
+
+		if head == nil {
+			head = request
+		} else {
+			tail.Next = request
+		}
+		tail = request
+
+		// Secret output is only ever delivered privately, so it can't be
+		// passed along to a command whose output might not be.
+		if request.Command.SecretOutput && i < len(stages)-1 {
+			msg := fmt.Sprintf("The output of %s:%s is secret, so it can't be piped to another command.",
+				request.Bundle.Name, request.Command.Name)
+			SendErrorMessage(ctx, id.Adapter, id.ChatChannel.ID, "Pipeline Error", msg)
+			abortPipeline(ctx, head, ErrSecretOutputPiped)
+			return nil, ErrSecretOutputPiped
+		}
+	}
+
+	// Every command of the pipeline was invoked by the whole message.
+	for r := head; r != nil; r = r.Next {
+		r.InvocationText = rawCommand
+	}
+
+	return head, nil
+}
+
+// abortPipeline closes the requests of an abandoned pipeline.
+func abortPipeline(ctx context.Context, head *data.CommandRequest, cause error) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		return
+	}
+
+	err = ErrPipelineAborted
+	if cause != nil {
+		err = fmt.Errorf("%w: %v", ErrPipelineAborted, cause)
+	}
+
+	for r := head; r != nil; r = r.Next {
+		da.RequestError(ctx, *r, err)
+	}
+}
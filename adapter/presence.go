@@ -0,0 +1,181 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+	"github.com/getgort/gort/version"
+)
+
+// inactivitySweepInterval is how often channels are checked for inactivity
+// when gort.channel_inactivity_timeout is set.
+const inactivitySweepInterval = time.Hour
+
+// greetChannels sends a greeting to each channel that Gort hasn't already
+// greeted, and records that it did so.
+func greetChannels(ctx context.Context, a Adapter, channels []*ChannelInfo) {
+	le := log.WithField("adapter.name", a.GetName())
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Warn("Failed to get data access; greetings can't be tracked")
+	}
+
+	greeted := map[string]bool{}
+	if da != nil {
+		presences, err := da.ChannelPresenceList(ctx, a.GetName())
+		if err != nil {
+			telemetry.Errors().WithError(err).Commit(ctx)
+			le.WithError(err).Error("Failed to list channel presence")
+		}
+
+		for _, p := range presences {
+			greeted[p.ChannelID] = !p.GreetedAt.IsZero()
+		}
+	}
+
+	for _, c := range channels {
+		if greeted[c.ID] {
+			continue
+		}
+
+		message := fmt.Sprintf("Gort version %s is online. Hello, %s!", version.Version, c.Name)
+		if err := SendNonCriticalMessage(ctx, a, c.ID, message); err != nil {
+			telemetry.Errors().WithError(err).Commit(ctx)
+			le.WithError(err).Error("Failed to send greeting")
+			continue
+		}
+
+		if da == nil {
+			continue
+		}
+
+		if err := da.ChannelPresenceMarkGreeted(ctx, a.GetName(), c.ID); err != nil {
+			telemetry.Errors().WithError(err).Commit(ctx)
+			le.WithError(err).Error("Failed to record channel greeting")
+		}
+	}
+}
+
+// markChannelActive records that a command was just requested from a
+// channel. Failures are logged but otherwise ignored.
+func markChannelActive(ctx context.Context, adapterName, channelID string) {
+	le := log.WithField("adapter.name", adapterName).
+		WithField("channel.id", channelID)
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Warn("Failed to get data access; channel activity can't be tracked")
+		return
+	}
+
+	if err := da.ChannelPresenceMarkActive(ctx, adapterName, channelID); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to record channel activity")
+	}
+}
+
+// leaveInactiveChannels causes the adapter to leave any channel that hasn't
+// had a command requested from it in the last
+// gort.channel_inactivity_timeout. It does nothing if the timeout is unset or
+// if the adapter's chat provider can't leave channels.
+func leaveInactiveChannels(ctx context.Context, a Adapter) {
+	timeout := config.GetGortServerConfigs().ChannelInactivityTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	j, ok := a.(ChannelJoiner)
+	if !ok {
+		return
+	}
+
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.leaveInactiveChannels")
+	defer sp.End()
+
+	le := log.WithField("adapter.name", a.GetName())
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Warn("Failed to get data access; skipping inactivity check")
+		return
+	}
+
+	presences, err := da.ChannelPresenceList(ctx, a.GetName())
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to list channel presence")
+		return
+	}
+
+	cutoff := time.Now().Add(-timeout)
+
+	for _, p := range presences {
+		if !p.LastActivity().Before(cutoff) {
+			continue
+		}
+
+		if err := leaveInactiveChannel(ctx, j, da, p); err != nil {
+			telemetry.Errors().WithError(err).Commit(ctx)
+			le.WithError(err).
+				WithField("channel.id", p.ChannelID).
+				Error("Failed to leave inactive channel")
+			continue
+		}
+
+		le.WithField("channel.id", p.ChannelID).
+			WithField("channel.last_activity", p.LastActivity()).
+			Info("Left inactive channel")
+	}
+}
+
+func leaveInactiveChannel(ctx context.Context, j ChannelJoiner, da dataaccess.DataAccess, p data.ChannelPresence) error {
+	if err := j.LeaveChannel(p.ChannelID); err != nil {
+		return err
+	}
+
+	return da.ChannelPresenceDelete(ctx, p.Adapter, p.ChannelID)
+}
+
+// startInactivitySweep periodically leaves inactive channels on every
+// adapter until the context is cancelled.
+func startInactivitySweep(ctx context.Context) {
+	ticker := time.NewTicker(inactivitySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, a := range adapterLookup {
+				leaveInactiveChannels(ctx, a)
+			}
+		}
+	}
+}
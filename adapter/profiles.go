@@ -0,0 +1,98 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"sort"
+
+	"github.com/getgort/gort/command"
+	"github.com/getgort/gort/data"
+)
+
+// ProfileOption is the reserved option used to select a command's execution
+// profile, as in "deploy --profile prod". It's only reserved for commands
+// that define profiles; for any other command it's an ordinary option.
+const ProfileOption = "profile"
+
+// profileParseOptions returns parse options that mark the profile option as
+// taking an argument, if the command defines any profiles.
+func profileParseOptions(cmdEntry data.CommandEntry) []command.ParseOption {
+	if len(cmdEntry.Command.Profiles) == 0 {
+		return nil
+	}
+
+	return []command.ParseOption{command.ParseOptionHasArgument(ProfileOption, true)}
+}
+
+// profileNames returns the sorted names of the command's profiles.
+func profileNames(cmdEntry data.CommandEntry) []string {
+	names := make([]string, 0, len(cmdEntry.Command.Profiles))
+	for name := range cmdEntry.Command.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// selectProfile resolves the execution profile requested by cmdInput, or
+// the command's default profile if none was requested. It returns a copy of
+// cmdInput with the profile option removed, suitable for passing to the
+// command as its parameters.
+func selectProfile(cmdInput command.Command, cmdEntry data.CommandEntry) (string, *data.BundleCommandProfile, command.Command, error) {
+	if len(cmdEntry.Command.Profiles) == 0 {
+		return "", nil, cmdInput, nil
+	}
+
+	var requested string
+	if o, ok := cmdInput.Options[ProfileOption]; ok && o.Value != nil {
+		requested = o.Value.String()
+
+		options := make(map[string]command.CommandOption, len(cmdInput.Options))
+		for name, o := range cmdInput.Options {
+			if name != ProfileOption {
+				options[name] = o
+			}
+		}
+		cmdInput.Options = options
+	}
+
+	name, profile, err := cmdEntry.Command.Profile(requested)
+	if err != nil {
+		return "", nil, cmdInput, err
+	}
+
+	return name, profile, cmdInput, nil
+}
+
+// hasProfilePermission returns true if perms grants the permission required
+// by profile. A nil profile, or one that requires no permission, is always
+// permitted.
+func hasProfilePermission(perms []string, bundleName string, profile *data.BundleCommandProfile) bool {
+	if profile == nil || profile.Permission == "" {
+		return true
+	}
+
+	want := bundleName + ":" + profile.Permission
+	for _, p := range perms {
+		if p == want {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/getgort/gort/command"
+	"github.com/getgort/gort/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectProfile(t *testing.T) {
+	prod := &data.BundleCommandProfile{Permission: "deploy_prod"}
+	entry := data.CommandEntry{
+		Bundle: data.Bundle{Name: "deploy"},
+		Command: data.BundleCommand{
+			Name:           "deploy",
+			DefaultProfile: "staging",
+			Profiles: map[string]*data.BundleCommandProfile{
+				"staging": {},
+				"prod":    prod,
+			},
+		},
+	}
+
+	tests := []struct {
+		input    string
+		profile  string
+		expected string
+		err      error
+	}{
+		{"deploy:deploy app", "staging", "app", nil},
+		{"deploy:deploy --profile prod app", "prod", "app", nil},
+		{"deploy:deploy --profile prod -v app", "prod", "-v app", nil},
+		{"deploy:deploy --profile qa app", "", "", data.ErrNoSuchProfile},
+	}
+
+	for _, test := range tests {
+		cmdInput, err := command.TokenizeAndParse(test.input, profileParseOptions(entry)...)
+		if !assert.NoError(t, err, test.input) {
+			continue
+		}
+
+		name, _, cmdParams, err := selectProfile(cmdInput, entry)
+		if test.err != nil {
+			assert.True(t, errors.Is(err, test.err), test.input)
+			continue
+		}
+
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.profile, name, test.input)
+		assert.Equal(t, test.expected, data.CommandParameters(parametersFromCommand(cmdParams)).String(), test.input)
+
+		// The original input retains the option so that rules can see it.
+		if _, ok := cmdInput.Options[ProfileOption]; name == "prod" && !ok {
+			t.Errorf("%s: profile option removed from original input", test.input)
+		}
+	}
+
+	// Commands without profiles treat --profile as an ordinary option.
+	plain := data.CommandEntry{Command: data.BundleCommand{Name: "cmd"}}
+	cmdInput, err := command.TokenizeAndParse("test:cmd --profile prod", profileParseOptions(plain)...)
+	assert.NoError(t, err)
+	name, profile, cmdParams, err := selectProfile(cmdInput, plain)
+	assert.NoError(t, err)
+	assert.Empty(t, name)
+	assert.Nil(t, profile)
+	assert.Equal(t, "--profile prod", data.CommandParameters(parametersFromCommand(cmdParams)).String())
+}
+
+func TestHasProfilePermission(t *testing.T) {
+	prod := &data.BundleCommandProfile{Permission: "deploy_prod"}
+
+	assert.True(t, hasProfilePermission(nil, "deploy", nil))
+	assert.True(t, hasProfilePermission(nil, "deploy", &data.BundleCommandProfile{}))
+	assert.True(t, hasProfilePermission([]string{"deploy:deploy_prod"}, "deploy", prod))
+	assert.False(t, hasProfilePermission([]string{"deploy:deploy"}, "deploy", prod))
+	assert.False(t, hasProfilePermission([]string{"other:deploy_prod"}, "deploy", prod))
+}
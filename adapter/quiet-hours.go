@@ -0,0 +1,164 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+var (
+	// quietHoursNow returns the current time. It's a variable so that tests
+	// can change it.
+	quietHoursNow = time.Now
+
+	quietDigests   = map[string]*quietDigest{}
+	quietDigestsMx sync.Mutex
+)
+
+// quietDigest is the non-critical output held for a single channel during
+// its quiet hours, which is posted when they end.
+type quietDigest struct {
+	adapter   Adapter
+	channelID string
+	timezone  *time.Location
+	messages  []heldMessage
+	timer     *time.Timer
+}
+
+// heldMessage is a single message held for a quiet hours digest.
+type heldMessage struct {
+	at   time.Time
+	text string
+}
+
+// SendNonCriticalMessage sends a message to a channel unless the channel is
+// in its quiet hours, in which case the message is either discarded or held
+// for the digest that's posted when the quiet hours end, depending on the
+// channel's settings. It should be used for output that the channel can do
+// without, such as acknowledgements; critical and error output should be
+// sent with SendMessage or SendErrorMessage.
+func SendNonCriticalMessage(ctx context.Context, a Adapter, channelID string, message string) error {
+	quiet := channelQuietHours(ctx, a, channelID)
+	now := quietHoursNow()
+
+	if quiet == nil || !quiet.Contains(now) {
+		return SendMessage(ctx, a, channelID, message)
+	}
+
+	le := log.WithField("adapter.name", a.GetName()).WithField("channel.id", channelID)
+
+	if !quiet.Digest {
+		le.Debug("Channel is in quiet hours; discarding message")
+		return nil
+	}
+
+	holdForDigest(a, channelID, *quiet, now, message)
+	le.Debug("Channel is in quiet hours; holding message for digest")
+
+	return nil
+}
+
+// channelQuietHours returns the quiet hours of a channel, or nil if it has
+// none. Failures are logged, and treated as no quiet hours, so that output
+// is delivered rather than lost.
+func channelQuietHours(ctx context.Context, a Adapter, channelID string) *data.QuietHours {
+	da, err := dataaccess.Get()
+	if err != nil {
+		return nil
+	}
+
+	settings, err := da.ChannelSettingsGet(ctx, a.GetName(), channelID)
+	if err != nil {
+		log.WithError(err).
+			WithField("adapter.name", a.GetName()).
+			WithField("channel.id", channelID).
+			Warn("Failed to get channel settings; ignoring quiet hours")
+		return nil
+	}
+
+	return settings.QuietHours
+}
+
+// holdForDigest adds a message to a channel's quiet hours digest, scheduling
+// the digest to be posted when the quiet hours end if it's the first.
+func holdForDigest(a Adapter, channelID string, quiet data.QuietHours, now time.Time, message string) {
+	quietDigestsMx.Lock()
+	defer quietDigestsMx.Unlock()
+
+	key := a.GetName() + "/" + channelID
+
+	d, ok := quietDigests[key]
+	if !ok {
+		end := quiet.EndAfter(now)
+
+		d = &quietDigest{adapter: a, channelID: channelID, timezone: end.Location()}
+		d.timer = time.AfterFunc(end.Sub(now), func() { postQuietDigest(key) })
+		quietDigests[key] = d
+	}
+
+	d.messages = append(d.messages, heldMessage{at: now, text: message})
+}
+
+// postQuietDigest posts, and then forgets, the quiet hours digest of the
+// channel with the given key.
+func postQuietDigest(key string) {
+	quietDigestsMx.Lock()
+	d, ok := quietDigests[key]
+	delete(quietDigests, key)
+	quietDigestsMx.Unlock()
+
+	if !ok || len(d.messages) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+
+	if err := SendMessage(ctx, d.adapter, d.channelID, d.String()); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).
+			WithField("adapter.name", d.adapter.GetName()).
+			WithField("channel.id", d.channelID).
+			Error("Failed to post quiet hours digest")
+	}
+}
+
+// String renders the digest as a single message.
+func (d *quietDigest) String() string {
+	var b strings.Builder
+
+	noun := "messages"
+	if len(d.messages) == 1 {
+		noun = "message"
+	}
+
+	fmt.Fprintf(&b, "Quiet hours are over. Gort held back %d %s:", len(d.messages), noun)
+	for _, m := range d.messages {
+		fmt.Fprintf(&b, "\n- %s %s", m.at.In(d.timezone).Format("15:04"), m.text)
+	}
+
+	return b.String()
+}
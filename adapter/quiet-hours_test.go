@@ -0,0 +1,81 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestSendNonCriticalMessage(t *testing.T) {
+	ctx := context.Background()
+
+	defer func(d time.Duration) { sendInterval = d }(sendInterval)
+	sendInterval = time.Millisecond
+
+	defer func(f func() time.Time) { quietHoursNow = f }(quietHoursNow)
+	quietHoursNow = func() time.Time { return time.Date(2021, 6, 1, 23, 30, 0, 0, time.UTC) }
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	setQuietHours := func(q *data.QuietHours) {
+		settings := data.ChannelSettings{Adapter: "testAdapter", ChannelID: "quietchannel", QuietHours: q}
+		require.NoError(t, da.ChannelSettingsSet(ctx, settings))
+	}
+	defer da.ChannelSettingsDelete(ctx, "testAdapter", "quietchannel")
+
+	a := &historyTestAdapter{}
+
+	// Outside of quiet hours, messages are sent as usual.
+	setQuietHours(&data.QuietHours{Start: "08:00", End: "17:00"})
+	require.NoError(t, SendNonCriticalMessage(ctx, a, "quietchannel", "one"))
+	assert.Contains(t, a.last(), "one")
+	assert.Len(t, a.sent, 1)
+
+	// During quiet hours, messages are discarded...
+	setQuietHours(&data.QuietHours{Start: "22:00", End: "07:00"})
+	require.NoError(t, SendNonCriticalMessage(ctx, a, "quietchannel", "two"))
+	assert.Len(t, a.sent, 1)
+
+	// ...or held for a digest.
+	setQuietHours(&data.QuietHours{Start: "22:00", End: "07:00", Digest: true})
+	require.NoError(t, SendNonCriticalMessage(ctx, a, "quietchannel", "three"))
+	require.NoError(t, SendNonCriticalMessage(ctx, a, "quietchannel", "four"))
+	assert.Len(t, a.sent, 1)
+
+	// Critical messages are never held.
+	require.NoError(t, SendMessage(ctx, a, "quietchannel", "five"))
+	assert.Contains(t, a.last(), "five")
+	assert.Len(t, a.sent, 2)
+
+	quietDigestsMx.Lock()
+	d := quietDigests["testAdapter/quietchannel"]
+	quietDigestsMx.Unlock()
+	require.NotNil(t, d)
+	d.timer.Stop()
+
+	postQuietDigest("testAdapter/quietchannel")
+	assert.Contains(t, a.last(), "Gort held back 2 messages:\n- 23:30 three\n- 23:30 four")
+}
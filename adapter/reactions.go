@@ -0,0 +1,77 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/data"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// Reactor is an optional interface implemented by adapters whose chat
+// provider supports adding emoji reactions to messages.
+type Reactor interface {
+	// React adds an emoji reaction to a message. If the emoji can't be
+	// represented by the chat provider an error wrapping
+	// data.ErrUnsupportedEmoji is returned.
+	React(ctx context.Context, channelID string, messageID string, emoji data.Emoji) error
+}
+
+// React adds an emoji reaction, in any form accepted by data.ParseEmoji, to
+// a message. An error is returned if the emoji is invalid or if the adapter
+// doesn't support reactions.
+func React(ctx context.Context, a Adapter, channelID, messageID, emoji string) error {
+	r, ok := a.(Reactor)
+	if !ok {
+		return gerrs.ErrUnsupported
+	}
+
+	e, err := data.ParseEmoji(emoji)
+	if err != nil {
+		return err
+	}
+
+	return r.React(ctx, channelID, messageID, e)
+}
+
+// reactToResponse adds the command's configured success or failure
+// reaction, if any, to the message that invoked it. Failures are logged, but
+// otherwise ignored: a missing reaction shouldn't prevent the command's
+// output from being delivered.
+func reactToResponse(ctx context.Context, a Adapter, envelope data.CommandResponseEnvelope) {
+	request := envelope.Request
+
+	emoji := request.Command.Reactions.For(envelope.Data.ExitCode)
+	if emoji == "" || request.MessageID == "" {
+		return
+	}
+
+	if _, ok := a.(Reactor); !ok {
+		return
+	}
+
+	if err := React(ctx, a, request.ChannelID, request.MessageID, emoji); err != nil {
+		adapterLogEntry(ctx, log.WithContext(ctx), a).
+			WithError(err).
+			WithField("command.name", request.Command.Name).
+			WithField("emoji", emoji).
+			Warn("Failed to react to command message")
+	}
+}
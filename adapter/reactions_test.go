@@ -0,0 +1,68 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/getgort/gort/data"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// reactorTestAdapter records the reactions added to messages.
+type reactorTestAdapter struct {
+	testAdapter
+
+	reactions []string
+}
+
+func (t *reactorTestAdapter) React(ctx context.Context, channelID string, messageID string, emoji data.Emoji) error {
+	t.reactions = append(t.reactions, channelID+"/"+messageID+"/"+emoji.String())
+	return nil
+}
+
+func TestReact(t *testing.T) {
+	ctx := context.Background()
+
+	a := &reactorTestAdapter{}
+	assert.NoError(t, React(ctx, a, "C1", "M1", ":thumbsup:"))
+	assert.NoError(t, React(ctx, a, "C1", "M2", "custom:partyparrot"))
+	assert.ErrorIs(t, React(ctx, a, "C1", "M3", "no_such_emoji"), data.ErrUnknownEmoji)
+	assert.Equal(t, []string{"C1/M1/\U0001F44D", "C1/M2/custom:partyparrot"}, a.reactions)
+
+	assert.ErrorIs(t, React(ctx, &testAdapter{}, "C1", "M1", ":thumbsup:"), gerrs.ErrUnsupported)
+}
+
+func TestReactToResponse(t *testing.T) {
+	ctx := context.Background()
+	a := &reactorTestAdapter{}
+
+	request := data.CommandRequest{ChannelID: "C1", MessageID: "M1"}
+	request.Command.Reactions = data.CommandReactions{Success: "white_check_mark", Failure: "x"}
+
+	reactToResponse(ctx, a, data.NewCommandResponseEnvelope(request, data.WithExitCode(0)))
+	reactToResponse(ctx, a, data.NewCommandResponseEnvelope(request, data.WithExitCode(1)))
+
+	// Without a message ID there's nothing to react to.
+	request.MessageID = ""
+	reactToResponse(ctx, a, data.NewCommandResponseEnvelope(request, data.WithExitCode(0)))
+
+	assert.Equal(t, []string{"C1/M1/\u2705", "C1/M1/\u274C"}, a.reactions)
+}
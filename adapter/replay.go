@@ -0,0 +1,132 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// Replay re-executes a historical request with the same command, parameters,
+// user, and channel as the original. If r.BundleVersion is set, the command
+// is taken from that version of the bundle rather than the one that
+// originally handled the request. The replay is recorded as a new request
+// that links back to the original, and its output is returned rather than
+// delivered to the original channel.
+func Replay(ctx context.Context, original data.RequestRecord, r rest.ReplayRequest) (rest.ReplayResult, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.Replay")
+	defer sp.End()
+
+	sp.SetAttributes(attribute.Int64("request.replay_of", original.RequestID))
+
+	if pipelineRequests == nil {
+		return rest.ReplayResult{}, ErrNotListening
+	}
+
+	timeout := config.GetGlobalConfigs().CommandTimeout
+	if timeout <= 0 {
+		timeout = selfTestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := newReplayRequest(ctx, original, r.BundleVersion)
+	if err != nil {
+		return rest.ReplayResult{}, err
+	}
+
+	// Replays share the self-test's plumbing, so that the response comes
+	// back to us instead of going to the chat provider.
+	envelope, err := runSelfTestRequest(ctx, request)
+	if err != nil {
+		return rest.ReplayResult{}, err
+	}
+
+	result := rest.ReplayResult{
+		RequestID:     request.RequestID,
+		ReplayOf:      original.RequestID,
+		BundleVersion: request.Bundle.Version,
+		ExitCode:      envelope.Data.ExitCode,
+		Output:        envelope.Response.Lines,
+		Duration:      envelope.Data.Duration,
+	}
+
+	if envelope.Data.Error != nil {
+		result.Error = envelope.Data.Error.Error()
+	}
+
+	return result, nil
+}
+
+// newReplayRequest builds a request that replays the original, and registers
+// it with the data access layer like any other request.
+func newReplayRequest(ctx context.Context, original data.RequestRecord, version string) (data.CommandRequest, error) {
+	if version == "" {
+		version = original.BundleVersion
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	bundle, err := da.BundleGet(ctx, original.BundleName, version)
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	cmd, ok := bundle.Commands[original.CommandName]
+	if !ok {
+		return data.CommandRequest{}, gerrs.Wrap(errs.ErrNoSuchBundle,
+			fmt.Errorf("bundle %s:%s has no command %q", bundle.Name, bundle.Version, original.CommandName))
+	}
+
+	request := data.CommandRequest{
+		CommandEntry: data.CommandEntry{Bundle: bundle, Command: *cmd},
+		Adapter:      original.Adapter,
+		ChannelID:    original.ChannelID,
+		Parameters:   TokenizeParameters(original.Parameters),
+		ReplayOf:     original.RequestID,
+		Timestamp:    time.Now(),
+		UserID:       original.UserID,
+		UserEmail:    original.UserEmail,
+		UserName:     original.UserName,
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		request.Deadline = deadline
+	}
+
+	if err := da.RequestBegin(ctx, &request); err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	return request, nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// Respond sends an interim message from a running command to the channel
+// that the command was invoked from. The message is subject to the same
+// output filters as the command's eventual output.
+func Respond(ctx context.Context, record data.RequestRecord, message string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.Respond")
+	defer sp.End()
+
+	sp.SetAttributes(
+		attribute.Int64("request.id", record.RequestID),
+		attribute.String("adapter.name", record.Adapter),
+	)
+
+	a, err := GetAdapter(record.Adapter)
+	if err != nil {
+		return err
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return err
+	}
+
+	bundle, err := da.BundleGet(ctx, record.BundleName, record.BundleVersion)
+	if err != nil {
+		return err
+	}
+
+	request := data.CommandRequest{
+		CommandEntry: data.CommandEntry{Bundle: bundle},
+		Adapter:      record.Adapter,
+		ChannelID:    record.ChannelID,
+		RequestID:    record.RequestID,
+	}
+
+	envelope := data.NewCommandResponseEnvelope(request, data.WithResponseLines([]string{message}))
+	filterOutput(ctx, &envelope)
+
+	return SendEnvelope(ctx, a, record.ChannelID, envelope, data.Message)
+}
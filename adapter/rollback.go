@@ -0,0 +1,287 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+const (
+	// adminGroup is the group whose members are notified of rollbacks when
+	// no notification channel is configured.
+	adminGroup = "admin"
+
+	// autoRollbackRequestLimit is the number of recent requests examined to
+	// calculate failure rates.
+	autoRollbackRequestLimit = 1000
+)
+
+// bundleEnablement is the enabling of a bundle version, as recorded in the
+// audit log.
+type bundleEnablement struct {
+	Name    string
+	Version string
+	Time    time.Time
+	User    string
+}
+
+// StartAutoRollback periodically checks recently enabled bundle versions,
+// and rolls back any whose failure rate is too high, until the context is
+// cancelled. It returns immediately if global.auto_rollback isn't enabled.
+func StartAutoRollback(ctx context.Context) {
+	c := config.GetGlobalConfigs().AutoRollback
+	if !c.Enabled {
+		return
+	}
+
+	ticker := time.NewTicker(c.IntervalOrDefault())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			CheckAutoRollback(ctx)
+		}
+	}
+}
+
+// CheckAutoRollback examines each bundle version that was enabled within
+// the auto rollback window, and re-enables the previously enabled version
+// of any whose failure rate exceeds the threshold. Enablements are found in
+// the audit log, and failure rates are calculated from the request log.
+func CheckAutoRollback(ctx context.Context) {
+	c := config.GetGlobalConfigs().AutoRollback
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get data access; auto rollback not checked")
+		return
+	}
+
+	records, err := da.AuditRecordList(ctx, data.AuditFilter{
+		Kind:  "bundle",
+		Since: time.Now().Add(-c.WindowOrDefault()),
+	})
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).Error("Failed to list audit records; auto rollback not checked")
+		return
+	}
+
+	// Records are newest first, so only the latest enablement of each
+	// bundle is kept.
+	latest := map[string]bundleEnablement{}
+	for _, r := range records {
+		if e, ok := enablementFromAudit(r); ok {
+			if _, seen := latest[e.Name]; !seen {
+				latest[e.Name] = e
+			}
+		}
+	}
+	if len(latest) == 0 {
+		return
+	}
+
+	requests, err := da.RequestList(ctx, autoRollbackRequestLimit)
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).Error("Failed to list requests; auto rollback not checked")
+		return
+	}
+
+	for _, e := range latest {
+		rollbackIfFailing(ctx, da, c, e, requests)
+	}
+}
+
+// enablementFromAudit returns the enablement recorded by an audit record, if
+// it records one: a successful change after which the target bundle version
+// was enabled, and before which it wasn't.
+func enablementFromAudit(r data.AuditRecord) (bundleEnablement, bool) {
+	if r.Kind != "bundle" || r.Status < 200 || r.Status >= 300 || len(r.After) == 0 {
+		return bundleEnablement{}, false
+	}
+
+	var before, after data.Bundle
+
+	if err := json.Unmarshal(r.After, &after); err != nil || !after.Enabled {
+		return bundleEnablement{}, false
+	}
+	if len(r.Before) > 0 {
+		if err := json.Unmarshal(r.Before, &before); err == nil && before.Enabled {
+			return bundleEnablement{}, false
+		}
+	}
+
+	name := after.Name
+	if name == "" {
+		name = r.Target
+	}
+
+	return bundleEnablement{Name: name, Version: after.Version, Time: r.Timestamp, User: r.User}, true
+}
+
+// versionFailures counts the completed requests, and the failed ones, that
+// were handled by the enabled bundle version since it was enabled.
+func versionFailures(requests []data.RequestRecord, e bundleEnablement) (total, failed int) {
+	for _, r := range requests {
+		if r.BundleName != e.Name || r.BundleVersion != e.Version {
+			continue
+		}
+		if !r.Closed || r.Timestamp.Before(e.Time) {
+			continue
+		}
+
+		total++
+		if r.ExitCode != 0 {
+			failed++
+		}
+	}
+
+	return total, failed
+}
+
+// previousEnabledVersion returns the version of the bundle that was enabled
+// before e, according to the audit log, or an empty string if there isn't
+// one or it's no longer installed.
+func previousEnabledVersion(ctx context.Context, da dataaccess.DataAccess, e bundleEnablement) (string, error) {
+	records, err := da.AuditRecordList(ctx, data.AuditFilter{Kind: "bundle", Target: e.Name, Until: e.Time})
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range records {
+		p, ok := enablementFromAudit(r)
+		if !ok || p.Version == e.Version {
+			continue
+		}
+
+		return p.Version, nil
+	}
+
+	return "", nil
+}
+
+// rollbackIfFailing re-enables the version of the bundle that was enabled
+// before e if e's version is still enabled and its failure rate exceeds the
+// threshold. Rollbacks are themselves never rolled back.
+func rollbackIfFailing(ctx context.Context, da dataaccess.DataAccess, c data.AutoRollbackConfigs, e bundleEnablement, requests []data.RequestRecord) {
+	if e.User == data.AutoRollbackUser {
+		return
+	}
+
+	le := log.WithField("bundle.name", e.Name).WithField("bundle.version", e.Version)
+
+	enabled, err := da.BundleEnabledVersion(ctx, e.Name)
+	if err != nil {
+		le.WithError(err).Warn("Failed to get enabled bundle version")
+		return
+	}
+	if enabled != e.Version {
+		return
+	}
+
+	total, failed := versionFailures(requests, e)
+	if total < c.MinRequestsOrDefault() || float64(failed)/float64(total) <= c.ThresholdOrDefault() {
+		return
+	}
+
+	le = le.WithField("requests", total).WithField("failures", failed)
+
+	previous, err := previousEnabledVersion(ctx, da, e)
+	if err != nil {
+		le.WithError(err).Warn("Failed to find previously enabled bundle version")
+		return
+	}
+	if previous == "" {
+		le.Warn("Bundle version is failing, but no previous version is available to roll back to")
+		return
+	}
+	if exists, err := da.BundleVersionExists(ctx, e.Name, previous); err != nil || !exists {
+		le.WithError(err).WithField("bundle.previous", previous).
+			Warn("Bundle version is failing, but the previous version is no longer installed")
+		return
+	}
+
+	before, _ := da.BundleGet(ctx, e.Name, previous)
+
+	if err := da.BundleEnable(ctx, e.Name, previous); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).WithField("bundle.previous", previous).Error("Failed to roll back bundle")
+		return
+	}
+
+	after, _ := da.BundleGet(ctx, e.Name, previous)
+
+	record := data.AuditRecord{
+		Timestamp: time.Now().UTC(),
+		User:      data.AutoRollbackUser,
+		Method:    http.MethodPatch,
+		Path:      fmt.Sprintf("/v2/bundles/%s/versions/%s", e.Name, previous),
+		Kind:      "bundle",
+		Target:    e.Name,
+		Status:    http.StatusOK,
+	}
+	record.Before, _ = json.Marshal(before)
+	record.After, _ = json.Marshal(after)
+
+	if err := da.AuditRecordCreate(ctx, &record); err != nil {
+		le.WithError(err).Warn("Failed to record auto rollback in the audit log")
+	}
+
+	le.WithField("bundle.previous", previous).Warn("Rolled back failing bundle version")
+
+	notifyRollback(ctx, c, rollbackNotice(e, previous, total, failed), le)
+}
+
+// rollbackNotice builds the text of a notice about a rollback.
+func rollbackNotice(e bundleEnablement, previous string, total, failed int) string {
+	return fmt.Sprintf("Bundle %s version %s failed %d of %d requests after it was enabled, "+
+		"so it has been rolled back to version %s.", e.Name, e.Version, failed, total, previous)
+}
+
+// notifyRollback sends a rollback notice to the configured notification
+// channel or, if there isn't one, to each member of the admin group.
+func notifyRollback(ctx context.Context, c data.AutoRollbackConfigs, msg string, le *log.Entry) {
+	if c.NotifyAdapter == "" {
+		notifyGroups(ctx, []string{adminGroup}, msg, le)
+		return
+	}
+
+	a, err := GetAdapter(c.NotifyAdapter)
+	if err == nil {
+		err = SendMessage(ctx, a, c.NotifyChannel, msg)
+	}
+	if err != nil {
+		le.WithError(err).WithField("adapter.name", c.NotifyAdapter).
+			WithField("channel.id", c.NotifyChannel).
+			Warn("Failed to send rollback notification")
+	}
+}
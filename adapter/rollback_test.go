@@ -0,0 +1,141 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestEnablementFromAudit(t *testing.T) {
+	disabled, _ := json.Marshal(data.Bundle{Name: "test", Version: "1.1.0"})
+	enabled, _ := json.Marshal(data.Bundle{Name: "test", Version: "1.1.0", Enabled: true})
+	now := time.Now()
+
+	r := data.AuditRecord{Timestamp: now, Kind: "bundle", Target: "test", Status: 200, Before: disabled, After: enabled}
+	e, ok := enablementFromAudit(r)
+	assert.True(t, ok)
+	assert.Equal(t, bundleEnablement{Name: "test", Version: "1.1.0", Time: now}, e)
+
+	// Installed and enabled at once
+	r.Before = nil
+	_, ok = enablementFromAudit(r)
+	assert.True(t, ok)
+
+	// Already enabled
+	r.Before = enabled
+	_, ok = enablementFromAudit(r)
+	assert.False(t, ok)
+
+	// Disabled
+	r.Before, r.After = enabled, disabled
+	_, ok = enablementFromAudit(r)
+	assert.False(t, ok)
+
+	// Failed
+	r.Before, r.After, r.Status = disabled, enabled, 403
+	_, ok = enablementFromAudit(r)
+	assert.False(t, ok)
+}
+
+func TestVersionFailures(t *testing.T) {
+	now := time.Now()
+	e := bundleEnablement{Name: "test", Version: "1.1.0", Time: now}
+
+	requests := []data.RequestRecord{
+		{BundleName: "test", BundleVersion: "1.1.0", Timestamp: now.Add(time.Second), Closed: true, ExitCode: 1},
+		{BundleName: "test", BundleVersion: "1.1.0", Timestamp: now.Add(time.Second), Closed: true},
+		{BundleName: "test", BundleVersion: "1.1.0", Timestamp: now.Add(time.Second)},
+		{BundleName: "test", BundleVersion: "1.1.0", Timestamp: now.Add(-time.Second), Closed: true, ExitCode: 1},
+		{BundleName: "test", BundleVersion: "1.0.0", Timestamp: now.Add(time.Second), Closed: true, ExitCode: 1},
+		{BundleName: "other", BundleVersion: "1.1.0", Timestamp: now.Add(time.Second), Closed: true, ExitCode: 1},
+	}
+
+	total, failed := versionFailures(requests, e)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, failed)
+}
+
+func TestCheckAutoRollback(t *testing.T) {
+	ctx := context.Background()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	v1 := data.Bundle{GortBundleVersion: 1, Name: "rollme", Version: "1.0.0", Description: "a rollback test bundle"}
+	v2 := v1
+	v2.Version = "1.1.0"
+
+	for _, b := range []data.Bundle{v1, v2} {
+		require.NoError(t, da.BundleCreate(ctx, b))
+		defer da.BundleDelete(ctx, b.Name, b.Version)
+	}
+
+	enable := func(b data.Bundle, at time.Time) {
+		require.NoError(t, da.BundleEnable(ctx, b.Name, b.Version))
+		after, err := da.BundleGet(ctx, b.Name, b.Version)
+		require.NoError(t, err)
+
+		r := data.AuditRecord{Timestamp: at, Kind: "bundle", Target: b.Name, Status: 200}
+		r.After, _ = json.Marshal(after)
+		require.NoError(t, da.AuditRecordCreate(ctx, &r))
+	}
+
+	enable(v1, time.Now().Add(-2*time.Hour))
+	enable(v2, time.Now().Add(-5*time.Minute))
+
+	for i := 0; i < data.DefaultAutoRollbackMinRequests; i++ {
+		request := data.CommandRequest{
+			CommandEntry: data.CommandEntry{Bundle: v2, Command: data.BundleCommand{Name: "cmd"}},
+			Timestamp:    time.Now(),
+		}
+		require.NoError(t, da.RequestBegin(ctx, &request))
+
+		envelope := data.NewCommandResponseEnvelope(request)
+		if i > 1 {
+			envelope = data.NewCommandResponseEnvelope(request, data.WithError("", errors.New("boom"), 1))
+		}
+		require.NoError(t, da.RequestClose(ctx, envelope))
+	}
+
+	CheckAutoRollback(ctx)
+
+	version, err := da.BundleEnabledVersion(ctx, "rollme")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", version)
+
+	records, err := da.AuditRecordList(ctx, data.AuditFilter{Kind: "bundle", Target: "rollme", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, data.AutoRollbackUser, records[0].User)
+
+	// A rollback is never itself rolled back.
+	CheckAutoRollback(ctx)
+
+	version, err = da.BundleEnabledVersion(ctx, "rollme")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", version)
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scripted
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/getgort/gort/adapter"
+	"github.com/getgort/gort/templates"
+)
+
+// ProviderType is the provider type reported by scripted adapters.
+const ProviderType = "scripted"
+
+// Message is a message sent by Gort through the adapter.
+type Message struct {
+	ChannelID string
+
+	// Text is the plain-text (alt) rendering of the message.
+	Text string
+
+	// Error is true if the message was sent via SendError.
+	Error bool
+}
+
+// NewAdapter returns a new scripted adapter with the given name. It must be
+// registered with adapter.AddAdapter before adapter.StartListening is
+// called.
+func NewAdapter(name string) *Adapter {
+	return &Adapter{
+		name:     name,
+		channels: map[string]*adapter.ChannelInfo{},
+		users:    map[string]*adapter.UserInfo{},
+		events:   make(chan *adapter.ProviderEvent, 100),
+		sent:     map[string][]Message{},
+		read:     map[string]int{},
+		signal:   make(chan struct{}),
+	}
+}
+
+var _ adapter.Adapter = &Adapter{}
+
+// Adapter is an in-process adapter that doesn't connect to any chat
+// provider. Messages are injected with Say, and the messages that Gort
+// sends are recorded so that they can be asserted on with Expect. Play
+// drives an entire scripted conversation.
+type Adapter struct {
+	name   string
+	events chan *adapter.ProviderEvent
+
+	mu       sync.Mutex
+	channels map[string]*adapter.ChannelInfo
+	users    map[string]*adapter.UserInfo
+	sent     map[string][]Message // Keyed by channel ID
+	read     map[string]int       // Number of messages consumed by Expect, by channel ID
+	signal   chan struct{}        // Closed and replaced whenever a message is sent
+}
+
+// AddChannel describes a channel that the adapter is present in.
+func (a *Adapter) AddChannel(c adapter.ChannelInfo) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.channels[c.ID] = &c
+}
+
+// AddUser describes a chat user.
+func (a *Adapter) AddUser(u adapter.UserInfo) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.users[u.ID] = &u
+}
+
+// GetChannelInfo provides info on a specific provider channel accessible
+// to the adapter.
+func (a *Adapter) GetChannelInfo(channelID string) (*adapter.ChannelInfo, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if c, ok := a.channels[channelID]; ok {
+		info := *c
+		return &info, nil
+	}
+
+	return &adapter.ChannelInfo{ID: channelID, Name: channelID}, nil
+}
+
+// GetName provides the name of this adapter as per the configuration.
+func (a *Adapter) GetName() string {
+	return a.name
+}
+
+// GetPresentChannels returns a slice of channels that the adapter is present in.
+func (a *Adapter) GetPresentChannels() ([]*adapter.ChannelInfo, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	channels := []*adapter.ChannelInfo{}
+	for _, c := range a.channels {
+		info := *c
+		channels = append(channels, &info)
+	}
+
+	sort.Slice(channels, func(i, j int) bool { return channels[i].ID < channels[j].ID })
+
+	return channels, nil
+}
+
+// GetUserInfo provides info on a specific provider user accessible
+// to the adapter.
+func (a *Adapter) GetUserInfo(userID string) (*adapter.UserInfo, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if u, ok := a.users[userID]; ok {
+		info := *u
+		return &info, nil
+	}
+
+	return &adapter.UserInfo{ID: userID, Name: userID, DisplayName: userID}, nil
+}
+
+// Listen returns the channel that injected messages are relayed through.
+// A ConnectedEvent is sent immediately.
+func (a *Adapter) Listen(ctx context.Context) <-chan *adapter.ProviderEvent {
+	a.events <- a.wrapEvent(adapter.EventConnected, &adapter.ConnectedEvent{})
+	return a.events
+}
+
+// Messages returns every message sent to a channel so far, including those
+// already consumed by Expect.
+func (a *Adapter) Messages(channelID string) []Message {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]Message{}, a.sent[channelID]...)
+}
+
+// Say injects a message from a user, as if it had been typed in the given
+// channel. If direct is true it's sent as a direct message.
+func (a *Adapter) Say(userID, channelID, text string, direct bool) {
+	if direct {
+		a.events <- a.wrapEvent(adapter.EventDirectMessage,
+			&adapter.DirectMessageEvent{ChannelID: channelID, Text: text, UserID: userID})
+		return
+	}
+
+	a.events <- a.wrapEvent(adapter.EventChannelMessage,
+		&adapter.ChannelMessageEvent{ChannelID: channelID, Text: text, UserID: userID})
+}
+
+// Expect waits for a message matching e to be sent to the given channel.
+// Messages are consumed in the order they were sent; any that don't match
+// are skipped. An error describing the skipped messages is returned if no
+// matching message is sent before the context is done.
+func (a *Adapter) Expect(ctx context.Context, channelID string, e Expectation) (Message, error) {
+	var skipped []Message
+
+	for {
+		a.mu.Lock()
+		signal := a.signal
+		for a.read[channelID] < len(a.sent[channelID]) {
+			m := a.sent[channelID][a.read[channelID]]
+			a.read[channelID]++
+
+			if e.Match(m) {
+				a.mu.Unlock()
+				return m, nil
+			}
+			skipped = append(skipped, m)
+		}
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Message{}, &ExpectationError{Expectation: e, ChannelID: channelID, Received: skipped}
+		case <-signal:
+		}
+	}
+}
+
+// Send records the plain-text rendering of the elements.
+func (a *Adapter) Send(ctx context.Context, channelID string, elements templates.OutputElements) error {
+	a.record(Message{ChannelID: channelID, Text: elements.Alt()})
+	return nil
+}
+
+// SendError records a break-glass error message.
+func (a *Adapter) SendError(ctx context.Context, channelID string, title string, err error) error {
+	a.record(Message{ChannelID: channelID, Text: fmt.Sprintf("%s: %v", title, err), Error: true})
+	return nil
+}
+
+// SendText records a simple text message.
+func (a *Adapter) SendText(ctx context.Context, channelID string, message string) error {
+	a.record(Message{ChannelID: channelID, Text: message})
+	return nil
+}
+
+func (a *Adapter) record(m Message) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sent[m.ChannelID] = append(a.sent[m.ChannelID], m)
+
+	close(a.signal)
+	a.signal = make(chan struct{})
+}
+
+func (a *Adapter) wrapEvent(eventType adapter.EventType, data interface{}) *adapter.ProviderEvent {
+	return &adapter.ProviderEvent{
+		EventType: eventType,
+		Data:      data,
+		Info: &adapter.Info{
+			Provider: &adapter.ProviderInfo{Type: ProviderType, Name: a.name},
+		},
+		Adapter: a,
+	}
+}
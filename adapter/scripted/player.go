@@ -0,0 +1,98 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scripted
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getgort/gort/adapter"
+)
+
+// ExpectationError is returned by Expect and Play when no message matching
+// an expectation is sent in time.
+type ExpectationError struct {
+	Expectation Expectation
+	ChannelID   string
+
+	// Received are the messages sent to the channel while waiting, none of
+	// which matched.
+	Received []Message
+}
+
+func (e *ExpectationError) Error() string {
+	msg := fmt.Sprintf("expected %s in channel %s", e.Expectation, e.ChannelID)
+	if len(e.Received) == 0 {
+		return msg + ", but none was sent"
+	}
+
+	texts := make([]string, len(e.Received))
+	for i, m := range e.Received {
+		texts[i] = fmt.Sprintf("%q", m.Text)
+	}
+
+	return msg + ", but got: " + strings.Join(texts, ", ")
+}
+
+// Play plays a scripted conversation: the script's users and channels are
+// added to the adapter, and then each step is executed in order. It returns
+// an error describing the first step whose expectation isn't met.
+func (a *Adapter) Play(ctx context.Context, s Script) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	for _, u := range s.Users {
+		a.AddUser(adapter.UserInfo{ID: u.ID, Name: u.Name, DisplayName: u.Name, Email: u.Email})
+	}
+	for _, c := range s.Channels {
+		a.AddChannel(adapter.ChannelInfo{ID: c.ID, Name: c.Name})
+	}
+
+	for i, step := range s.Steps {
+		channel := s.channelOf(step)
+
+		if step.Say != "" {
+			a.Say(s.userOf(step), channel, step.Say, step.Direct)
+			continue
+		}
+
+		sctx, cancel := context.WithTimeout(ctx, s.timeoutOf(step))
+		_, err := a.Expect(sctx, channel, *step.Expect)
+		cancel()
+
+		if err != nil {
+			if s.Name != "" {
+				return fmt.Errorf("%s: step %d: %w", s.Name, i+1, err)
+			}
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// PlayFile loads a script from a YAML file and plays it.
+func (a *Adapter) PlayFile(ctx context.Context, path string) error {
+	s, err := LoadScript(path)
+	if err != nil {
+		return err
+	}
+
+	return a.Play(ctx, s)
+}
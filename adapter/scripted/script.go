@@ -0,0 +1,242 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scripted
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// DefaultTimeout is how long an expectation waits for a matching message
+// when neither the step nor the script specifies a timeout.
+const DefaultTimeout = 10 * time.Second
+
+// Script is a scripted conversation: a sequence of messages sent to Gort,
+// interleaved with expectations about the messages Gort sends back.
+type Script struct {
+	// Name describes the conversation. It's used in error messages.
+	Name string `yaml:"name,omitempty"`
+
+	// User and Channel are the IDs of the user and channel used by steps
+	// that don't specify their own.
+	User    string `yaml:"user,omitempty"`
+	Channel string `yaml:"channel,omitempty"`
+
+	// Timeout is the default expectation timeout. If zero, DefaultTimeout
+	// is used.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// Users and Channels describe the chat users and channels that the
+	// adapter reports to Gort. Users and channels that aren't described are
+	// reported with their ID as their name.
+	Users    []User    `yaml:"users,omitempty"`
+	Channels []Channel `yaml:"channels,omitempty"`
+
+	// Steps are executed in order.
+	Steps []Step `yaml:"steps"`
+}
+
+// User describes a chat user.
+type User struct {
+	ID    string `yaml:"id"`
+	Name  string `yaml:"name,omitempty"`
+	Email string `yaml:"email,omitempty"`
+}
+
+// Channel describes a chat channel.
+type Channel struct {
+	ID   string `yaml:"id"`
+	Name string `yaml:"name,omitempty"`
+}
+
+// Step is a single step of a conversation. Exactly one of Say or Expect must
+// be set.
+type Step struct {
+	// Say is a message sent to Gort, as if typed by User in Channel.
+	Say string `yaml:"say,omitempty"`
+
+	// Direct sends Say as a direct message rather than a channel message.
+	Direct bool `yaml:"direct,omitempty"`
+
+	// Expect describes a message that Gort is expected to send to Channel.
+	Expect *Expectation `yaml:"expect,omitempty"`
+
+	// User and Channel override the script's defaults for this step.
+	User    string `yaml:"user,omitempty"`
+	Channel string `yaml:"channel,omitempty"`
+
+	// Timeout overrides the script's default expectation timeout.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// Expectation describes a message that Gort is expected to send. Every
+// non-empty condition must hold for a message to match.
+type Expectation struct {
+	// Equals must be equal to the message text, ignoring leading and
+	// trailing whitespace.
+	Equals string `yaml:"equals,omitempty"`
+
+	// Contains must be contained in the message text.
+	Contains string `yaml:"contains,omitempty"`
+
+	// Matches is a regular expression that must match the message text.
+	Matches string `yaml:"matches,omitempty"`
+
+	// Error, if true, requires that the message was sent as a break-glass
+	// error (via SendError).
+	Error bool `yaml:"error,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// LoadScript reads and parses a script from a YAML file.
+func LoadScript(path string) (Script, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Script{}, err
+	}
+
+	s, err := ParseScript(b)
+	if err != nil {
+		return Script{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if s.Name == "" {
+		s.Name = path
+	}
+
+	return s, nil
+}
+
+// ParseScript parses a script from YAML, and validates it.
+func ParseScript(b []byte) (Script, error) {
+	var s Script
+
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return Script{}, err
+	}
+
+	if err := s.Validate(); err != nil {
+		return Script{}, err
+	}
+
+	return s, nil
+}
+
+// Validate returns an error if any step is malformed.
+func (s *Script) Validate() error {
+	for i := range s.Steps {
+		step := &s.Steps[i]
+
+		switch {
+		case step.Say == "" && step.Expect == nil:
+			return fmt.Errorf("step %d: one of say or expect is required", i+1)
+		case step.Say != "" && step.Expect != nil:
+			return fmt.Errorf("step %d: only one of say or expect may be set", i+1)
+		case step.Say != "" && s.userOf(*step) == "":
+			return fmt.Errorf("step %d: no user specified", i+1)
+		case s.channelOf(*step) == "":
+			return fmt.Errorf("step %d: no channel specified", i+1)
+		}
+
+		if step.Expect != nil && step.Expect.Matches != "" {
+			re, err := regexp.Compile(step.Expect.Matches)
+			if err != nil {
+				return fmt.Errorf("step %d: %w", i+1, err)
+			}
+			step.Expect.re = re
+		}
+	}
+
+	return nil
+}
+
+// Match returns true if m satisfies the expectation.
+func (e Expectation) Match(m Message) bool {
+	switch {
+	case e.Error && !m.Error:
+		return false
+	case e.Equals != "" && strings.TrimSpace(m.Text) != strings.TrimSpace(e.Equals):
+		return false
+	case e.Contains != "" && !strings.Contains(m.Text, e.Contains):
+		return false
+	case e.Matches != "" && !e.regexp().MatchString(m.Text):
+		return false
+	}
+
+	return true
+}
+
+// String returns a human-readable description of the expectation.
+func (e Expectation) String() string {
+	var conds []string
+
+	if e.Equals != "" {
+		conds = append(conds, fmt.Sprintf("equal to %q", e.Equals))
+	}
+	if e.Contains != "" {
+		conds = append(conds, fmt.Sprintf("containing %q", e.Contains))
+	}
+	if e.Matches != "" {
+		conds = append(conds, fmt.Sprintf("matching /%s/", e.Matches))
+	}
+	if e.Error {
+		conds = append(conds, "sent as an error")
+	}
+	if len(conds) == 0 {
+		return "any message"
+	}
+
+	return "a message " + strings.Join(conds, " and ")
+}
+
+func (e Expectation) regexp() *regexp.Regexp {
+	if e.re != nil {
+		return e.re
+	}
+	return regexp.MustCompile(e.Matches)
+}
+
+func (s Script) channelOf(step Step) string {
+	if step.Channel != "" {
+		return step.Channel
+	}
+	return s.Channel
+}
+
+func (s Script) timeoutOf(step Step) time.Duration {
+	switch {
+	case step.Timeout > 0:
+		return step.Timeout
+	case s.Timeout > 0:
+		return s.Timeout
+	default:
+		return DefaultTimeout
+	}
+}
+
+func (s Script) userOf(step Step) string {
+	if step.User != "" {
+		return step.User
+	}
+	return s.User
+}
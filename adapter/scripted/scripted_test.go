@@ -0,0 +1,143 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scripted
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/adapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoBot stands in for Gort: it echoes any message starting with "!echo",
+// and responds to "!fail" with an error.
+func echoBot(ctx context.Context, a *Adapter) {
+	events := a.Listen(ctx)
+
+	for {
+		var e *adapter.ProviderEvent
+
+		select {
+		case <-ctx.Done():
+			return
+		case e = <-events:
+		}
+
+		var channel, text string
+
+		switch ev := e.Data.(type) {
+		case *adapter.ChannelMessageEvent:
+			channel, text = ev.ChannelID, ev.Text
+		case *adapter.DirectMessageEvent:
+			channel, text = ev.ChannelID, ev.Text
+		default:
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "!echo "):
+			e.Adapter.SendText(ctx, channel, "Executing command")
+			e.Adapter.SendText(ctx, channel, strings.TrimPrefix(text, "!echo "))
+		case text == "!fail":
+			e.Adapter.SendError(ctx, channel, "Error", errors.New("command failed"))
+		}
+	}
+}
+
+func TestPlayFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := NewAdapter("scripted")
+	go echoBot(ctx, a)
+
+	err := a.PlayFile(ctx, "testdata/echo.yml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Message{
+		{ChannelID: "C001", Text: "Executing command"},
+		{ChannelID: "C001", Text: "hello world"},
+		{ChannelID: "C001", Text: "Error: command failed", Error: true},
+	}, a.Messages("C001"))
+
+	u, err := a.GetUserInfo("U001")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", u.Email)
+
+	c, err := a.GetChannelInfo("C001")
+	require.NoError(t, err)
+	assert.Equal(t, "general", c.Name)
+}
+
+func TestPlayUnmetExpectation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := NewAdapter("scripted")
+	go echoBot(ctx, a)
+
+	s := Script{
+		Name:    "unmet",
+		User:    "U001",
+		Channel: "C001",
+		Timeout: 100 * time.Millisecond,
+		Steps: []Step{
+			{Say: "!echo foo"},
+			{Expect: &Expectation{Equals: "bar"}},
+		},
+	}
+
+	err := a.Play(ctx, s)
+	require.Error(t, err)
+
+	var ee *ExpectationError
+	require.True(t, errors.As(err, &ee))
+	assert.Len(t, ee.Received, 2)
+	assert.Contains(t, err.Error(), `unmet: step 2: expected a message equal to "bar" in channel C001`)
+}
+
+func TestParseScript(t *testing.T) {
+	tests := []struct {
+		Name string
+		YAML string
+		Err  string
+	}{
+		{"Valid", "channel: C\nuser: U\nsteps:\n  - say: hi\n  - expect: {contains: hi}\n", ""},
+		{"Empty step", "channel: C\nsteps:\n  - user: U\n", "step 1: one of say or expect is required"},
+		{"Both", "channel: C\nuser: U\nsteps:\n  - say: hi\n    expect: {}\n", "step 1: only one of say or expect may be set"},
+		{"No user", "channel: C\nsteps:\n  - say: hi\n", "step 1: no user specified"},
+		{"No channel", "user: U\nsteps:\n  - say: hi\n", "step 1: no channel specified"},
+		{"Bad regex", "channel: C\nsteps:\n  - expect: {matches: '('}\n", "step 1: error parsing regexp"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := ParseScript([]byte(test.YAML))
+			if test.Err == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+// secretTokenBytes is the number of random bytes in a secret output token.
+const secretTokenBytes = 32
+
+// deliverSecretOutput delivers the output of a command marked secret_output.
+// The output is never posted to the channel: it's stored behind a one-time
+// token, and the requester is sent a direct message containing a link
+// through which they can view it once. A short notice is posted to the
+// originating channel in place of the output.
+func deliverSecretOutput(ctx context.Context, a Adapter, envelope data.CommandResponseEnvelope) error {
+	token, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := config.GetGlobalConfigs().SecretOutputTTL
+	if ttl <= 0 {
+		ttl = data.DefaultSecretOutputTTL
+	}
+
+	now := time.Now().UTC()
+	secret := data.SecretOutput{
+		TokenHash: data.HashSecretToken(token),
+		RequestID: envelope.Request.RequestID,
+		Username:  envelope.Request.UserName,
+		Title:     envelope.Response.Title,
+		Output:    envelope.Response.Lines,
+		Created:   now,
+		Expires:   now.Add(ttl),
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return err
+	}
+
+	if err := da.SecretOutputCreate(ctx, secret); err != nil {
+		return err
+	}
+
+	link := strings.TrimSuffix(config.GetGortServerConfigs().APIURLBase, "/") + "/v2/secrets/" + token
+	message := fmt.Sprintf("The output of `%s` can be viewed once, until %s, at %s",
+		envelope.Request.Command.Name, secret.Expires.Format(time.RFC1123), link)
+
+	target := rest.DirectMessageTarget{Adapter: a.GetName(), UserID: envelope.Request.UserID}
+	if err := sendDirectMessage(ctx, a.GetName(), &target, message); err != nil {
+		return err
+	}
+
+	notice := fmt.Sprintf("The output of `%s` was sent to you by direct message.", envelope.Request.Command.Name)
+	return SendMessage(ctx, a, envelope.Request.ChannelID, notice)
+}
+
+// newSecretToken returns a random, URL-safe token.
+func newSecretToken() (string, error) {
+	b := make([]byte, secretTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,288 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/bundles"
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+	"github.com/getgort/gort/templates"
+)
+
+var (
+	// ErrNotListening is returned by SelfTest if the adapters haven't been
+	// started with StartListening.
+	ErrNotListening = errors.New("adapters aren't listening")
+
+	// pipelineRequests carries command requests from the adapters to the
+	// relay. It's set by StartListening.
+	pipelineRequests chan<- data.CommandRequest
+
+	// selfTestTimeout bounds a self-test when no command timeout is
+	// configured.
+	selfTestTimeout = time.Minute
+)
+
+// selfTestKey is the context key under which a self-test request carries the
+// channel that its response envelope should be returned on.
+type selfTestKey struct{}
+
+// selfTestCommand is the command run by a self-test. It's the default
+// bundle's version command, which needs nothing but the Gort image.
+const selfTestCommand = "version"
+
+// SelfTest runs a trivial built-in command through the entire command
+// pipeline on behalf of the named user, and reports how long each stage took.
+// If t.Adapter is set, the command's output is sent to the channel with the
+// ID t.Channel; otherwise the chat provider isn't contacted. A failed stage
+// is reported in the result rather than as an error.
+func SelfTest(ctx context.Context, username string, t rest.SelfTestRequest) (rest.SelfTestResult, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "adapter.SelfTest")
+	defer sp.End()
+
+	if pipelineRequests == nil {
+		return rest.SelfTestResult{}, ErrNotListening
+	}
+
+	timeout := config.GetGlobalConfigs().CommandTimeout
+	if timeout <= 0 {
+		timeout = selfTestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	st := selfTest{start: time.Now()}
+	defer func() {
+		sp.SetAttributes(attribute.Bool("selftest.success", st.result.Success))
+	}()
+
+	// Stage 1: Look up the target channel, which proves that the adapter
+	// can talk to its chat provider.
+	var a Adapter
+	if t.Adapter != "" {
+		err := st.stage("adapter", func() error {
+			var err error
+			if a, err = GetAdapter(t.Adapter); err != nil {
+				return err
+			}
+			_, err = a.GetChannelInfo(t.Channel)
+			return err
+		})
+		if err != nil {
+			return st.finish(), nil
+		}
+	}
+
+	// Stage 2: Build the request and hand it to the relay, which runs the
+	// command in a worker.
+	var envelope data.CommandResponseEnvelope
+	err := st.stage("request", func() error {
+		request, err := newSelfTestRequest(ctx, username, t)
+		if err != nil {
+			return err
+		}
+
+		envelope, err = runSelfTestRequest(ctx, request)
+		return err
+	})
+	if err != nil {
+		return st.finish(), nil
+	}
+
+	// The relay records how long the worker itself ran, so the request stage
+	// can be split in two.
+	st.split("relay", "worker", envelope.Data.WorkerDuration)
+
+	if envelope.Data.ExitCode != 0 {
+		st.fail(fmt.Errorf("command exited with code %d: %v", envelope.Data.ExitCode, envelope.Data.Error))
+		return st.finish(), nil
+	}
+	st.result.Output = envelope.Response.Lines
+
+	// Stage 3: Render the command output.
+	var elements templates.OutputElements
+	err = st.stage("template", func() error {
+		tmpl, err := templates.Get(envelope.Request.Command, envelope.Request.Bundle, data.Command)
+		if err != nil {
+			return err
+		}
+
+		tf, err := templates.Transform(tmpl, envelope)
+		if err != nil {
+			return err
+		}
+
+		elements, err = templates.EncodeElements(tf)
+		return err
+	})
+	if err != nil {
+		return st.finish(), nil
+	}
+
+	// Stage 4: Deliver the output, if there's somewhere to deliver it.
+	if a != nil {
+		err = st.stage("send", func() error {
+			return queueSend(ctx, a, t.Channel, elements)
+		})
+		if err != nil {
+			return st.finish(), nil
+		}
+	}
+
+	st.result.Success = true
+	return st.finish(), nil
+}
+
+// newSelfTestRequest builds a request for the self-test command, and
+// registers it with the data access layer like any other request.
+func newSelfTestRequest(ctx context.Context, username string, t rest.SelfTestRequest) (data.CommandRequest, error) {
+	bundle, err := bundles.Default()
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	cmd, ok := bundle.Commands[selfTestCommand]
+	if !ok {
+		return data.CommandRequest{}, fmt.Errorf("default bundle has no %q command", selfTestCommand)
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	user, err := da.UserGet(ctx, username)
+	if err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	request := data.CommandRequest{
+		CommandEntry: data.CommandEntry{Bundle: bundle, Command: *cmd},
+		Adapter:      t.Adapter,
+		ChannelID:    t.Channel,
+		Parameters:   data.CommandParameters{"--short"},
+		Timestamp:    time.Now(),
+		UserEmail:    user.Email,
+		UserName:     user.Username,
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		request.Deadline = deadline
+	}
+
+	if err := da.RequestBegin(ctx, &request); err != nil {
+		return data.CommandRequest{}, err
+	}
+
+	return request, nil
+}
+
+// runSelfTestRequest sends a self-test request to the relay and waits for
+// its response, which startRelayResponseListening hands back to us rather
+// than delivering.
+func runSelfTestRequest(ctx context.Context, request data.CommandRequest) (data.CommandResponseEnvelope, error) {
+	responses := make(chan data.CommandResponseEnvelope, 1)
+	request.Context = context.WithValue(ctx, selfTestKey{}, responses)
+
+	select {
+	case pipelineRequests <- request:
+	case <-ctx.Done():
+		return data.CommandResponseEnvelope{}, ctx.Err()
+	}
+
+	select {
+	case envelope := <-responses:
+		return envelope, nil
+	case <-ctx.Done():
+		return data.CommandResponseEnvelope{}, ctx.Err()
+	}
+}
+
+// returnSelfTestResponse returns true if the envelope is the response to a
+// self-test request, in which case it's passed to the waiting self-test.
+func returnSelfTestResponse(envelope data.CommandResponseEnvelope) bool {
+	if envelope.Request.Context == nil {
+		return false
+	}
+
+	responses, ok := envelope.Request.Context.Value(selfTestKey{}).(chan data.CommandResponseEnvelope)
+	if !ok {
+		return false
+	}
+
+	responses <- envelope
+	return true
+}
+
+// selfTest accumulates the stages of a self-test as they're run.
+type selfTest struct {
+	start  time.Time
+	result rest.SelfTestResult
+}
+
+// stage runs and times f, recording the outcome as a stage with the given
+// name. The error returned by f is returned.
+func (s *selfTest) stage(name string, f func() error) error {
+	start := time.Now()
+	err := f()
+
+	stage := rest.SelfTestStage{Name: name, Duration: time.Since(start)}
+	if err != nil {
+		stage.Error = err.Error()
+	}
+
+	s.result.Stages = append(s.result.Stages, stage)
+	return err
+}
+
+// split replaces the most recent stage with two stages, the second of which
+// took d.
+func (s *selfTest) split(first, second string, d time.Duration) {
+	n := len(s.result.Stages)
+	last := s.result.Stages[n-1]
+
+	if d > last.Duration {
+		d = last.Duration
+	}
+
+	s.result.Stages = append(s.result.Stages[:n-1],
+		rest.SelfTestStage{Name: first, Duration: last.Duration - d},
+		rest.SelfTestStage{Name: second, Duration: d},
+	)
+}
+
+// fail records an error against the most recent stage.
+func (s *selfTest) fail(err error) {
+	s.result.Stages[len(s.result.Stages)-1].Error = err.Error()
+}
+
+func (s *selfTest) finish() rest.SelfTestResult {
+	s.result.Total = time.Since(s.start)
+	return s.result
+}
@@ -0,0 +1,256 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/templates"
+)
+
+var (
+	// sendInterval is the minimum time between consecutive messages sent by
+	// any single adapter. Slack, for example, allows roughly one message per
+	// second per channel, with short bursts tolerated.
+	sendInterval = time.Second
+
+	// sendMaxAttempts is the number of times a rate limited send will be
+	// attempted before its error is returned to the caller.
+	sendMaxAttempts = 4
+
+	sendQueues   = map[string]*sendQueue{}
+	sendQueuesMx sync.Mutex
+)
+
+// RateLimitedError may be returned by an Adapter's send methods to indicate
+// that the chat provider has rate limited the adapter. Sends that fail with
+// this error are retried by the send queue after RetryAfter has elapsed.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited: retry after %s", e.RetryAfter)
+}
+
+// sendJob is a single queued message. A job may represent several rapid
+// sequential messages to the same channel that have been coalesced together,
+// in which case every caller waits on the same result.
+type sendJob struct {
+	ctx       context.Context
+	adapter   Adapter
+	channelID string
+	elements  *templates.OutputElements
+	text      *string
+	done      []chan error
+}
+
+// coalesce attempts to merge j2 into j, returning true if it succeeded.
+// Only messages of the same kind to the same channel can be merged; rich
+// messages must also share a title and color.
+func (j *sendJob) coalesce(j2 *sendJob) bool {
+	if j.channelID != j2.channelID {
+		return false
+	}
+
+	switch {
+	case j.text != nil && j2.text != nil:
+		merged := *j.text + "\n" + *j2.text
+		j.text = &merged
+
+	case j.elements != nil && j2.elements != nil:
+		if j.elements.Title != j2.elements.Title || j.elements.Color != j2.elements.Color {
+			return false
+		}
+		// Copy before appending so the caller's slice is never modified.
+		elements := make([]templates.OutputElement, 0, len(j.elements.Elements)+len(j2.elements.Elements))
+		elements = append(elements, j.elements.Elements...)
+		j.elements.Elements = append(elements, j2.elements.Elements...)
+
+	default:
+		return false
+	}
+
+	j.done = append(j.done, j2.done...)
+	return true
+}
+
+// send performs the job's send. Text longer than the adapter's message
+// length limit is sent as a series of chunks, spaced sendInterval apart.
+func (j *sendJob) send() error {
+	if j.text == nil {
+		return j.attempt(func() error {
+			return j.adapter.Send(j.ctx, j.channelID, *j.elements)
+		})
+	}
+
+	for i, chunk := range chunkText(*j.text, maxMessageLength(j.adapter)) {
+		if i > 0 {
+			select {
+			case <-j.ctx.Done():
+				return j.ctx.Err()
+			case <-time.After(sendInterval):
+			}
+		}
+
+		err := j.attempt(func() error {
+			return j.adapter.SendText(j.ctx, j.channelID, chunk)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// attempt calls send, retrying for as long as the provider reports a rate
+// limit and attempts remain.
+func (j *sendJob) attempt(send func() error) error {
+	var err error
+
+	for attempt := 1; attempt <= sendMaxAttempts; attempt++ {
+		err = send()
+
+		var rle *RateLimitedError
+		if !errors.As(err, &rle) || attempt == sendMaxAttempts {
+			return err
+		}
+
+		log.WithField("adapter.name", j.adapter.GetName()).
+			WithField("channel.id", j.channelID).
+			WithField("retry_after", rle.RetryAfter).
+			Warn("Adapter rate limited; retrying send")
+
+		select {
+		case <-j.ctx.Done():
+			return j.ctx.Err()
+		case <-time.After(rle.RetryAfter):
+		}
+	}
+
+	return err
+}
+
+// sendQueue serializes the messages sent by a single adapter, spacing them
+// at least sendInterval apart.
+type sendQueue struct {
+	mx      sync.Mutex
+	pending []*sendJob
+	wake    chan struct{}
+}
+
+// getSendQueue returns the send queue for the named adapter, creating and
+// starting it if necessary.
+func getSendQueue(name string) *sendQueue {
+	sendQueuesMx.Lock()
+	defer sendQueuesMx.Unlock()
+
+	q, ok := sendQueues[name]
+	if !ok {
+		q = &sendQueue{wake: make(chan struct{}, 1)}
+		sendQueues[name] = q
+		go q.run()
+	}
+
+	return q
+}
+
+// enqueue adds a job to the queue, coalescing it into the last pending job
+// if possible, and waits for it to be sent.
+func (q *sendQueue) enqueue(j *sendJob) error {
+	done := make(chan error, 1)
+	j.done = []chan error{done}
+
+	q.mx.Lock()
+	if n := len(q.pending); n == 0 || !q.pending[n-1].coalesce(j) {
+		q.pending = append(q.pending, j)
+	}
+	q.mx.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-j.ctx.Done():
+		return j.ctx.Err()
+	}
+}
+
+// next removes and returns the first pending job, or nil if there is none.
+func (q *sendQueue) next() *sendJob {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	if len(q.pending) == 0 {
+		return nil
+	}
+
+	j := q.pending[0]
+	q.pending = q.pending[1:]
+	return j
+}
+
+func (q *sendQueue) run() {
+	var last time.Time
+
+	for range q.wake {
+		for j := q.next(); j != nil; j = q.next() {
+			if wait := sendInterval - time.Since(last); wait > 0 {
+				time.Sleep(wait)
+			}
+
+			err := j.send()
+			last = time.Now()
+
+			for _, done := range j.done {
+				done <- err
+			}
+		}
+	}
+}
+
+// queueSend sends rich output to a channel via the adapter's send queue.
+func queueSend(ctx context.Context, a Adapter, channelID string, elements templates.OutputElements) error {
+	return getSendQueue(a.GetName()).enqueue(&sendJob{
+		ctx:       ctx,
+		adapter:   a,
+		channelID: channelID,
+		elements:  &elements,
+	})
+}
+
+// queueSendText sends a text message to a channel via the adapter's send
+// queue.
+func queueSendText(ctx context.Context, a Adapter, channelID string, message string) error {
+	return getSendQueue(a.GetName()).enqueue(&sendJob{
+		ctx:       ctx,
+		adapter:   a,
+		channelID: channelID,
+		text:      &message,
+	})
+}
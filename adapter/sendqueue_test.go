@@ -0,0 +1,109 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueTestAdapter records text messages. Its first send blocks until gate
+// is closed, and it reports a rate limit for the first limited sends.
+type queueTestAdapter struct {
+	testAdapter
+	name    string
+	gate    chan struct{}
+	limited int
+
+	mx   sync.Mutex
+	sent []string
+}
+
+func (t *queueTestAdapter) GetName() string {
+	return t.name
+}
+
+func (t *queueTestAdapter) SendText(ctx context.Context, channelID string, message string) error {
+	if t.gate != nil {
+		<-t.gate
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if t.limited > 0 {
+		t.limited--
+		return &RateLimitedError{RetryAfter: time.Millisecond}
+	}
+
+	t.sent = append(t.sent, channelID+": "+message)
+	return nil
+}
+
+func TestSendQueueCoalesce(t *testing.T) {
+	defer func(d time.Duration) { sendInterval = d }(sendInterval)
+	sendInterval = time.Millisecond
+
+	a := &queueTestAdapter{name: "queue-coalesce", gate: make(chan struct{})}
+	ctx := context.Background()
+
+	// The first message is picked up immediately and blocks at the gate; the
+	// rest pile up behind it.
+	var wg sync.WaitGroup
+	send := func(channel, message string) {
+		defer wg.Done()
+		assert.NoError(t, queueSendText(ctx, a, channel, message))
+	}
+
+	wg.Add(1)
+	go send("C1", "one")
+	time.Sleep(50 * time.Millisecond)
+
+	for _, m := range []struct{ channel, message string }{
+		{"C1", "two"}, {"C1", "three"}, {"C2", "four"},
+	} {
+		wg.Add(1)
+		go send(m.channel, m.message)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(a.gate)
+	wg.Wait()
+
+	assert.Equal(t, []string{"C1: one", "C1: two\nthree", "C2: four"}, a.sent)
+}
+
+func TestSendQueueRetryAfter(t *testing.T) {
+	defer func(d time.Duration) { sendInterval = d }(sendInterval)
+	sendInterval = time.Millisecond
+
+	ctx := context.Background()
+
+	a := &queueTestAdapter{name: "queue-retry", limited: sendMaxAttempts - 1}
+	require.NoError(t, queueSendText(ctx, a, "C1", "hello"))
+	assert.Equal(t, []string{"C1: hello"}, a.sent)
+
+	a = &queueTestAdapter{name: "queue-retry-exhausted", limited: sendMaxAttempts}
+	err := queueSendText(ctx, a, "C1", "hello")
+	assert.IsType(t, &RateLimitedError{}, err)
+	assert.Empty(t, a.sent)
+}
@@ -19,7 +19,12 @@ package slack
 import (
 	"context"
 	"fmt"
+	"io"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/getgort/gort/adapter"
 	"github.com/getgort/gort/data"
@@ -35,6 +40,23 @@ var (
 	linkMarkdownRegexLong  = regexp.MustCompile(`\<[^|:]*:[^|]*\|([^|]*)\>`)
 )
 
+const (
+	// buttonActionPrefix and selectBlockPrefix identify the action and block
+	// IDs of interactive elements built by Gort.
+	buttonActionPrefix = "gort_button_"
+	selectBlockPrefix  = "gort_select_"
+
+	// maxBlockIDLength is the longest block_id that Slack will accept.
+	maxBlockIDLength = 255
+
+	// maxMessageLength is the longest text message, in characters, that
+	// Slack recommends posting. Much longer messages are truncated.
+	maxMessageLength = 4000
+)
+
+// mrkdwn is Slack's own flavor of inline formatting.
+var mrkdwn = data.Markup{Bold: "*", Italic: "_", Strike: "~", CodeFence: "```"}
+
 // NewAdapter will construct a SlackAdapter instance for a given provider configuration.
 func NewAdapter(provider data.SlackProvider) adapter.Adapter {
 	if provider.APIToken != "" {
@@ -86,6 +108,14 @@ func ScrubMarkdown(text string) string {
 // Send the contents of a response envelope to a specified channel. If
 // channelID is empty the value of envelope.Request.ChannelID will be used.
 func Send(ctx context.Context, client *slack.Client, a adapter.Adapter, channelID string, elements templates.OutputElements) error {
+	_, err := SendDeletable(ctx, client, a, channelID, elements)
+	return err
+}
+
+// SendDeletable is like Send, but also returns the timestamp of the posted
+// message, which Slack uses as its ID, so that it can later be deleted with
+// DeleteMessage.
+func SendDeletable(ctx context.Context, client *slack.Client, a adapter.Adapter, channelID string, elements templates.OutputElements) (string, error) {
 	e := log.WithContext(ctx)
 
 	options, err := buildSlackOptions(&elements)
@@ -94,19 +124,160 @@ func Send(ctx context.Context, client *slack.Client, a adapter.Adapter, channelI
 		if err := a.SendError(ctx, channelID, "Slack Option Build Failure", err); err != nil {
 			e.WithError(err).Error("break-glass send error failure!")
 		}
-		return err
+		return "", err
 	}
 
-	_, _, err = client.PostMessage(channelID, options...)
+	_, timestamp, err := client.PostMessage(channelID, options...)
 	if err != nil {
+		if rle, ok := err.(*slack.RateLimitedError); ok {
+			// Let the adapter's send queue retry, rather than sending an error
+			// message that's certain to be rate limited too.
+			return "", &adapter.RateLimitedError{RetryAfter: rle.RetryAfter}
+		}
+
 		e.WithError(err).Error("failed to post Slack message")
 		if err := a.SendError(ctx, channelID, "Slack Message Failure", err); err != nil {
 			e.WithError(err).Error("break-glass send error failure!")
 		}
+		return "", err
+	}
+
+	return timestamp, nil
+}
+
+// DeleteMessage deletes a message previously posted by the bot, identified
+// by its timestamp.
+func DeleteMessage(ctx context.Context, client *slack.Client, channelID string, messageID string) error {
+	_, _, err := client.DeleteMessageContext(ctx, channelID, messageID)
+	return err
+}
+
+// ChannelHistory returns the messages posted by users to a channel after
+// since, oldest first. Messages from bots, and those with a subtype (joins,
+// topic changes, and the like) aren't included.
+func ChannelHistory(ctx context.Context, client *slack.Client, channelID string, since time.Time) ([]adapter.HistoryMessage, error) {
+	params := &slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Limit:     200,
+		Oldest:    formatTimestamp(since),
+	}
+
+	var messages []adapter.HistoryMessage
+
+	for {
+		response, err := client.GetConversationHistoryContext(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range response.Messages {
+			if m.Text == "" || m.BotID != "" || m.SubType != "" {
+				continue
+			}
+
+			ts, err := parseTimestamp(m.Timestamp)
+			if err != nil {
+				return nil, err
+			}
+
+			messages = append(messages, adapter.HistoryMessage{
+				ChannelMessageEvent: adapter.ChannelMessageEvent{
+					Attachments: fileAttachments(m.Files),
+					ChannelID:   channelID,
+					MessageID:   m.Timestamp,
+					Text:        ScrubMarkdown(m.Text),
+					UserID:      m.User,
+				},
+				Timestamp: ts,
+			})
+		}
+
+		if !response.HasMore || response.ResponseMetaData.NextCursor == "" {
+			break
+		}
+
+		params.Cursor = response.ResponseMetaData.NextCursor
+	}
+
+	// Slack returns the newest messages first.
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+
+	return messages, nil
+}
+
+// formatTimestamp renders a time as a Slack message timestamp, which is
+// a Unix time with microsecond precision.
+func formatTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/int(time.Microsecond))
+}
+
+// parseTimestamp parses a Slack message timestamp.
+func parseTimestamp(ts string) (time.Time, error) {
+	parts := strings.SplitN(ts, ".", 2)
+
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid message timestamp %q: %w", ts, err)
+	}
+
+	var usec int64
+	if len(parts) == 2 {
+		if usec, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("invalid message timestamp %q: %w", ts, err)
+		}
+	}
+
+	return time.Unix(sec, usec*int64(time.Microsecond)).UTC(), nil
+}
+
+// DownloadAttachment downloads a file shared in a message. The download is
+// authenticated with the given bot token.
+func DownloadAttachment(ctx context.Context, token string, attachment adapter.Attachment, w io.Writer) error {
+	return adapter.DownloadURL(ctx, attachment.URL, token, w)
+}
+
+// fileAttachments describes the files shared in a message as attachments.
+func fileAttachments(files []slack.File) []adapter.Attachment {
+	var attachments []adapter.Attachment
+
+	for _, f := range files {
+		attachments = append(attachments, adapter.Attachment{
+			ID:          f.ID,
+			Name:        f.Name,
+			ContentType: f.Mimetype,
+			Size:        int64(f.Size),
+			URL:         f.URLPrivateDownload,
+		})
+	}
+
+	return attachments
+}
+
+// React adds an emoji reaction to a message, identified by its timestamp.
+func React(ctx context.Context, client *slack.Client, channelID string, messageID string, emoji data.Emoji) error {
+	name, err := emojiName(emoji)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return client.AddReactionContext(ctx, name, slack.NewRefToMessage(channelID, messageID))
+}
+
+// emojiName returns the name by which Slack knows an emoji. Slack reactions
+// can only be specified by name, so Unicode emoji without a known alias
+// aren't supported.
+func emojiName(emoji data.Emoji) (string, error) {
+	if emoji.Custom != "" {
+		return emoji.Custom, nil
+	}
+
+	if alias, ok := emoji.Alias(); ok {
+		return alias, nil
+	}
+
+	return "", fmt.Errorf("%w: %q has no Slack name", data.ErrUnsupportedEmoji, emoji.Unicode)
 }
 
 // SendText sends a text message to a specified channel.
@@ -116,6 +287,10 @@ func SendText(ctx context.Context, client *slack.Client, a adapter.Adapter, chan
 
 	_, _, err := client.PostMessage(channelID, slack.MsgOptionText(message, false))
 	if err != nil {
+		if rle, ok := err.(*slack.RateLimitedError); ok {
+			return &adapter.RateLimitedError{RetryAfter: rle.RetryAfter}
+		}
+
 		e.WithError(err).Error("failed to post Slack message")
 		if err := a.SendError(ctx, channelID, "Slack Message Failure", err); err != nil {
 			e.WithError(err).Error("break-glass send error failure!")
@@ -161,9 +336,33 @@ func buildSlackOptions(elements *templates.OutputElements) ([]slack.MsgOption, e
 	var blocks []slack.Block
 	var headerBlock *slack.SectionBlock
 	var currentSection *slack.SectionBlock
+	var currentActions *slack.ActionBlock
+
+	for i, e := range elements.Elements {
+		if _, ok := e.(*templates.Button); !ok {
+			currentActions = nil
+		}
 
-	for _, e := range elements.Elements {
 		switch t := e.(type) {
+		case *templates.Button:
+			// Consecutive buttons are rendered side by side in one block.
+			if currentActions == nil {
+				currentActions = slack.NewActionBlock("")
+				blocks = append(blocks, currentActions)
+			}
+
+			currentSection = nil
+			currentActions.Elements.ElementSet = append(currentActions.Elements.ElementSet, buildButtonElement(t, i))
+
+		case *templates.Select:
+			block, err := buildSelectBlock(t, i)
+			if err != nil {
+				return nil, err
+			}
+
+			currentSection = nil
+			blocks = append(blocks, block)
+
 		case *templates.Divider:
 			blocks = append(blocks, slack.NewDividerBlock())
 
@@ -274,6 +473,15 @@ func buildSlackOptions(elements *templates.OutputElements) ([]slack.MsgOption, e
 				currentSection.Text.Text += "\n" + t.Text
 			}
 
+		case *templates.Table:
+			block, err := buildTableBlock(t)
+			if err != nil {
+				return nil, err
+			}
+
+			currentSection = nil
+			blocks = append(blocks, block)
+
 		case *templates.Alt:
 			// Ignore Alt, only rendered as fallback
 
@@ -312,6 +520,126 @@ func buildSlackOptions(elements *templates.OutputElements) ([]slack.MsgOption, e
 	return options, nil
 }
 
+// maxTableFields is the most fields Slack allows in a section block.
+const maxTableFields = 10
+
+// buildTableBlock renders a templates.Table. Slack lays out section fields in
+// two columns, so small two-column tables use those; anything else becomes a
+// monospaced block of aligned text.
+// buildButtonElement builds a Block Kit button. The command is carried in the
+// button's value and returned in the block_actions payload when it's clicked.
+func buildButtonElement(t *templates.Button, index int) *slack.ButtonBlockElement {
+	label := slack.NewTextBlockObject("plain_text", t.Label, false, false)
+	button := slack.NewButtonBlockElement(fmt.Sprintf("%s%d", buttonActionPrefix, index), t.Command, label)
+
+	if t.Style != "" {
+		button = button.WithStyle(slack.Style(t.Style))
+	}
+	if t.Confirm != "" {
+		button.Confirm = buildConfirmationBlockObject(t.Confirm)
+	}
+
+	return button
+}
+
+// buildSelectBlock builds an actions block containing a single static select
+// menu. Slack only returns the selected option's value, so the command is
+// encoded into the block ID.
+func buildSelectBlock(t *templates.Select, index int) (*slack.ActionBlock, error) {
+	blockID := fmt.Sprintf("%s%d:%s", selectBlockPrefix, index, t.Command)
+	if len(blockID) > maxBlockIDLength {
+		return nil, fmt.Errorf("select command is too long for Slack: %q", t.Command)
+	}
+
+	var options []*slack.OptionBlockObject
+	for _, o := range t.Options {
+		text := slack.NewTextBlockObject("plain_text", o, false, false)
+		options = append(options, slack.NewOptionBlockObject(o, text, nil))
+	}
+
+	var placeholder *slack.TextBlockObject
+	if t.Placeholder != "" {
+		placeholder = slack.NewTextBlockObject("plain_text", t.Placeholder, false, false)
+	}
+
+	sel := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, fmt.Sprintf("%s%d", selectBlockPrefix, index), options...)
+	if t.Confirm != "" {
+		sel.Confirm = buildConfirmationBlockObject(t.Confirm)
+	}
+
+	return slack.NewActionBlock(blockID, sel), nil
+}
+
+func buildConfirmationBlockObject(text string) *slack.ConfirmationBlockObject {
+	return slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject("plain_text", "Are you sure?", false, false),
+		slack.NewTextBlockObject("plain_text", text, false, false),
+		slack.NewTextBlockObject("plain_text", "Confirm", false, false),
+		slack.NewTextBlockObject("plain_text", "Cancel", false, false),
+	)
+}
+
+// interactionFromBlockAction translates a Block Kit action produced by
+// buildButtonElement or buildSelectBlock back into an InteractionEvent. It
+// returns nil if the action didn't come from a Gort element.
+func interactionFromBlockAction(callback *slack.InteractionCallback, action *slack.BlockAction) *adapter.InteractionEvent {
+	event := &adapter.InteractionEvent{
+		ChannelID: callback.Channel.ID,
+		MessageID: callback.Container.MessageTs,
+		UserID:    callback.User.ID,
+	}
+
+	switch {
+	case strings.HasPrefix(action.ActionID, buttonActionPrefix):
+		event.Command = action.Value
+
+	case strings.HasPrefix(action.BlockID, selectBlockPrefix):
+		parts := strings.SplitN(action.BlockID, ":", 2)
+		if len(parts) != 2 {
+			return nil
+		}
+		event.Command = parts[1]
+		event.Selection = action.SelectedOption.Value
+
+	default:
+		return nil
+	}
+
+	if event.ChannelID == "" {
+		event.ChannelID = callback.Container.ChannelID
+	}
+
+	return event
+}
+
+func buildTableBlock(t *templates.Table) (*slack.SectionBlock, error) {
+	if len(t.Columns) == 2 && 2*(len(t.Rows)+1) <= maxTableFields {
+		fields := []*slack.TextBlockObject{
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s*", t.Columns[0]), false, false),
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s*", t.Columns[1]), false, false),
+		}
+
+		for _, row := range t.Rows {
+			for _, cell := range row {
+				// Slack rejects empty text objects.
+				if cell == "" {
+					cell = "-"
+				}
+				fields = append(fields, slack.NewTextBlockObject("plain_text", cell, false, false))
+			}
+		}
+
+		return slack.NewSectionBlock(nil, fields, nil), nil
+	}
+
+	tbo, err := buildTextBlockObject(&templates.Text{Markdown: true, Monospace: true, Text: t.Alt()})
+	if err != nil {
+		return nil, err
+	}
+
+	return slack.NewSectionBlock(tbo, nil, nil), nil
+}
+
 // buildTextBlockObject accepts a templates.Text value, does some basic error
 // correction to satisty the very tempermental Slack API, and returns an
 // equivalent slack.TextBlockObject. It produces an error if the resulting
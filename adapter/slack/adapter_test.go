@@ -17,8 +17,13 @@
 package slack
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/templates"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,3 +41,87 @@ func TestScrubMarkdown(t *testing.T) {
 		assert.Equal(t, expected, ScrubMarkdown(test))
 	}
 }
+
+func TestBuildTableBlock(t *testing.T) {
+	small := &templates.Table{Columns: []string{"Name", "Stars"}, Rows: [][]string{{"foo", "4"}, {"bar", ""}}}
+
+	block, err := buildTableBlock(small)
+	assert.NoError(t, err)
+	assert.Nil(t, block.Text)
+	assert.Len(t, block.Fields, 6)
+	assert.Equal(t, "-", block.Fields[5].Text)
+
+	wide := &templates.Table{Columns: []string{"A", "B", "C"}, Rows: [][]string{{"1", "2", "3"}}}
+
+	block, err = buildTableBlock(wide)
+	assert.NoError(t, err)
+	assert.Empty(t, block.Fields)
+	assert.Equal(t, "```"+wide.Alt()+"```", block.Text.Text)
+}
+
+func TestEmojiName(t *testing.T) {
+	name, err := emojiName(data.Emoji{Unicode: "\U0001F44D"})
+	assert.NoError(t, err)
+	assert.Equal(t, "+1", name)
+
+	name, err = emojiName(data.Emoji{Custom: "partyparrot"})
+	assert.NoError(t, err)
+	assert.Equal(t, "partyparrot", name)
+
+	_, err = emojiName(data.Emoji{Unicode: "\U0001F9A9"})
+	assert.ErrorIs(t, err, data.ErrUnsupportedEmoji)
+}
+
+func TestInteractiveElementsRoundTrip(t *testing.T) {
+	button := &templates.Button{Label: "Approve", Command: "deploy:approve 42", Style: "primary", Confirm: "Sure?"}
+
+	be := buildButtonElement(button, 3)
+	assert.Equal(t, "gort_button_3", be.ActionID)
+	assert.Equal(t, "deploy:approve 42", be.Value)
+	assert.NotNil(t, be.Confirm)
+
+	sel := &templates.Select{Placeholder: "Env", Command: "deploy:to 42", Options: []string{"staging", "production"}}
+
+	block, err := buildSelectBlock(sel, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "gort_select_4:deploy:to 42", block.BlockID)
+
+	callback := &slack.InteractionCallback{}
+	callback.Channel.ID = "C123"
+	callback.User.ID = "U123"
+	callback.Container.MessageTs = "1234.5678"
+
+	ie := interactionFromBlockAction(callback, &slack.BlockAction{ActionID: be.ActionID, Value: be.Value})
+	if assert.NotNil(t, ie) {
+		assert.Equal(t, "C123", ie.ChannelID)
+		assert.Equal(t, "U123", ie.UserID)
+		assert.Equal(t, "1234.5678", ie.MessageID)
+		assert.Equal(t, "deploy:approve 42", ie.Command)
+		assert.Empty(t, ie.Selection)
+	}
+
+	action := &slack.BlockAction{ActionID: "gort_select_4", BlockID: block.BlockID}
+	action.SelectedOption.Value = "production"
+
+	ie = interactionFromBlockAction(callback, action)
+	if assert.NotNil(t, ie) {
+		assert.Equal(t, "deploy:to 42", ie.Command)
+		assert.Equal(t, "production", ie.Selection)
+	}
+
+	assert.Nil(t, interactionFromBlockAction(callback, &slack.BlockAction{ActionID: "something_else"}))
+
+	long := &templates.Select{Command: strings.Repeat("x", 300), Options: []string{"a"}}
+	_, err = buildSelectBlock(long, 0)
+	assert.Error(t, err)
+}
+
+func TestTimestamps(t *testing.T) {
+	ts, err := parseTimestamp("1634567890.000200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1634567890, 200*int64(time.Microsecond)).UTC(), ts)
+	assert.Equal(t, "1634567890.000200", formatTimestamp(ts))
+
+	_, err = parseTimestamp("not-a-timestamp")
+	assert.Error(t, err)
+}
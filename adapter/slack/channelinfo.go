@@ -17,10 +17,17 @@
 package slack
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/getgort/gort/adapter"
 	"github.com/slack-go/slack"
 )
 
+// channelIDPattern matches Slack public and private channel IDs.
+var channelIDPattern = regexp.MustCompile(`^[CG][A-Z0-9]{8,}$`)
+
 func newChannelInfoFromSlackChannel(slackChannel *slack.Channel) *adapter.ChannelInfo {
 	ch := &adapter.ChannelInfo{}
 
@@ -30,3 +37,60 @@ func newChannelInfoFromSlackChannel(slackChannel *slack.Channel) *adapter.Channe
 
 	return ch
 }
+
+// findChannelID returns the ID of the named channel. If channel already
+// looks like a channel ID, it's returned as-is.
+func findChannelID(client *slack.Client, channel string) (string, error) {
+	channel = strings.TrimPrefix(channel, "#")
+
+	if channelIDPattern.MatchString(channel) {
+		return channel, nil
+	}
+
+	params := &slack.GetConversationsParameters{ExcludeArchived: true, Limit: 1000}
+
+	for {
+		channels, cursor, err := client.GetConversations(params)
+		if err != nil {
+			return "", err
+		}
+
+		for _, ch := range channels {
+			if ch.Name == channel {
+				return ch.ID, nil
+			}
+		}
+
+		if cursor == "" {
+			return "", fmt.Errorf("channel not found: %s", channel)
+		}
+
+		params.Cursor = cursor
+	}
+}
+
+// joinChannel causes the bot to join a Slack channel by ID or name.
+func joinChannel(client *slack.Client, channel string) (*adapter.ChannelInfo, error) {
+	id, err := findChannelID(client, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, _, _, err := client.JoinConversation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newChannelInfoFromSlackChannel(ch), nil
+}
+
+// leaveChannel causes the bot to leave a Slack channel by ID or name.
+func leaveChannel(client *slack.Client, channel string) error {
+	id, err := findChannelID(client, channel)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.LeaveConversation(id)
+	return err
+}
@@ -19,8 +19,10 @@ package slack
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/getgort/gort/adapter"
 	"github.com/getgort/gort/data"
@@ -31,6 +33,10 @@ import (
 )
 
 var _ adapter.Adapter = &ClassicAdapter{}
+var _ adapter.AttachmentDownloader = &ClassicAdapter{}
+var _ adapter.ChannelJoiner = &ClassicAdapter{}
+var _ adapter.HistoryReader = &ClassicAdapter{}
+var _ adapter.MessageLimiter = &ClassicAdapter{}
 
 // ClassicAdapter is the Slack provider implementation of a relay, which knows how
 // to receive events from the Slack API, translate them into Gort events, and
@@ -84,6 +90,29 @@ func (s ClassicAdapter) GetUserInfo(userID string) (*adapter.UserInfo, error) {
 	return newUserInfoFromSlackUser(u), nil
 }
 
+// GetUserPresence returns the presence of the specified user.
+func (s ClassicAdapter) GetUserPresence(userID string) (string, error) {
+	return getUserPresence(s.client, userID)
+}
+
+// JoinChannel causes the bot to join the specified channel, which may be
+// given by ID or by name.
+func (s ClassicAdapter) JoinChannel(channel string) (*adapter.ChannelInfo, error) {
+	return joinChannel(s.client, channel)
+}
+
+// LeaveChannel causes the bot to leave the specified channel, which may be
+// given by ID or by name.
+func (s ClassicAdapter) LeaveChannel(channel string) error {
+	return leaveChannel(s.client, channel)
+}
+
+// OpenDirectChannel opens (or finds the existing) direct message channel
+// with the specified user, and returns its ID.
+func (s ClassicAdapter) OpenDirectChannel(userID string) (string, error) {
+	return openDirectChannel(s.client, userID)
+}
+
 // Listen instructs the relay to begin listening to the provider that it's attached to.
 // It exits immediately, returning a channel that emits ProviderEvents.
 func (s ClassicAdapter) Listen(ctx context.Context) <-chan *adapter.ProviderEvent {
@@ -220,6 +249,15 @@ func (s ClassicAdapter) Listen(ctx context.Context) <-chan *adapter.ProviderEven
 			case *slack.HelloEvent:
 				// Do nothing (for now).
 
+			case *slack.ChannelRenameEvent:
+				events <- s.onChannelChanged(ev.Channel.ID, info)
+
+			case *slack.GroupRenameEvent:
+				events <- s.onChannelChanged(ev.Group.ID, info)
+
+			case *slack.UserChangeEvent:
+				events <- s.onUserChanged(ev.User.ID, info)
+
 			default:
 				// Report and ignore other events..
 				e.WithField("message.data", msg.Data).
@@ -234,12 +272,50 @@ func (s ClassicAdapter) Listen(ctx context.Context) <-chan *adapter.ProviderEven
 	return events
 }
 
+// Markup returns Slack's mrkdwn formatting delimiters.
+func (s *ClassicAdapter) Markup() data.Markup {
+	return mrkdwn
+}
+
+// MaxMessageLength returns the longest text message that Slack recommends
+// posting.
+func (s *ClassicAdapter) MaxMessageLength() int {
+	return maxMessageLength
+}
+
 // Send the contents of a response envelope to a specified channel. If
 // channelID is empty the value of envelope.Request.ChannelID will be used.
 func (s *ClassicAdapter) Send(ctx context.Context, channelID string, elements templates.OutputElements) error {
 	return Send(ctx, s.client, s, channelID, elements)
 }
 
+// SendDeletable sends the contents of a response envelope to the specified
+// channel, and returns the ID of the message so that it can be deleted.
+func (s *ClassicAdapter) SendDeletable(ctx context.Context, channelID string, elements templates.OutputElements) (string, error) {
+	return SendDeletable(ctx, s.client, s, channelID, elements)
+}
+
+// DeleteMessage deletes a message previously sent by the adapter.
+func (s *ClassicAdapter) DeleteMessage(ctx context.Context, channelID string, messageID string) error {
+	return DeleteMessage(ctx, s.client, channelID, messageID)
+}
+
+// ChannelHistory returns the messages posted by users to a channel after
+// since, oldest first.
+func (s *ClassicAdapter) ChannelHistory(ctx context.Context, channelID string, since time.Time) ([]adapter.HistoryMessage, error) {
+	return ChannelHistory(ctx, s.client, channelID, since)
+}
+
+// DownloadAttachment downloads a file shared in a message.
+func (s *ClassicAdapter) DownloadAttachment(ctx context.Context, attachment adapter.Attachment, w io.Writer) error {
+	return DownloadAttachment(ctx, s.provider.APIToken, attachment, w)
+}
+
+// React adds an emoji reaction to a message.
+func (s *ClassicAdapter) React(ctx context.Context, channelID string, messageID string, emoji data.Emoji) error {
+	return React(ctx, s.client, channelID, messageID, emoji)
+}
+
 // SendText sends a simple text message to the specified channel.
 func (s *ClassicAdapter) SendText(ctx context.Context, channelID string, message string) error {
 	return SendText(ctx, s.client, s, channelID, message)
@@ -258,13 +334,25 @@ func (s *ClassicAdapter) onChannelMessage(event *slack.MessageEvent, info *adapt
 		adapter.EventChannelMessage,
 		info,
 		&adapter.ChannelMessageEvent{
-			ChannelID: event.Channel,
-			Text:      ScrubMarkdown(event.Msg.Text),
-			UserID:    event.Msg.User,
+			Attachments: fileAttachments(event.Msg.Files),
+			ChannelID:   event.Channel,
+			MessageID:   event.Msg.Timestamp,
+			Text:        ScrubMarkdown(event.Msg.Text),
+			UserID:      event.Msg.User,
 		},
 	)
 }
 
+// onChannelChanged is called when the Slack API emits a ChannelRenameEvent
+// or GroupRenameEvent.
+func (s *ClassicAdapter) onChannelChanged(channelID string, info *adapter.Info) *adapter.ProviderEvent {
+	return s.wrapEvent(
+		adapter.EventChannelChanged,
+		info,
+		&adapter.ChannelChangedEvent{ChannelID: channelID},
+	)
+}
+
 // onConnected is called when the Slack API emits a ConnectedEvent.
 func (s *ClassicAdapter) onConnected(event *slack.ConnectedEvent, info *adapter.Info) *adapter.ProviderEvent {
 	return s.wrapEvent(
@@ -289,9 +377,11 @@ func (s *ClassicAdapter) onDirectMessage(event *slack.MessageEvent, info *adapte
 		adapter.EventDirectMessage,
 		info,
 		&adapter.DirectMessageEvent{
-			ChannelID: event.Channel,
-			Text:      ScrubMarkdown(event.Msg.Text),
-			UserID:    event.Msg.User,
+			Attachments: fileAttachments(event.Msg.Files),
+			ChannelID:   event.Channel,
+			MessageID:   event.Msg.Timestamp,
+			Text:        ScrubMarkdown(event.Msg.Text),
+			UserID:      event.Msg.User,
 		},
 	)
 }
@@ -319,7 +409,7 @@ func (s *ClassicAdapter) onInvalidAuth(event *slack.InvalidAuthEvent, info *adap
 // onMessage is called when the Slack API emits a MessageEvent.
 func (s *ClassicAdapter) onMessage(event *slack.MessageEvent, info *adapter.Info) *adapter.ProviderEvent {
 	switch event.Msg.SubType {
-	case "": // Just a plain message. Handle accordingly.
+	case "", "file_share": // Just a plain message, possibly with files. Handle accordingly.
 		if event.Channel[0] == 'D' {
 			return s.onDirectMessage(event, info)
 		}
@@ -353,6 +443,15 @@ func (s *ClassicAdapter) onRTMError(event *slack.RTMError, info *adapter.Info) *
 	)
 }
 
+// onUserChanged is called when the Slack API emits a UserChangeEvent.
+func (s *ClassicAdapter) onUserChanged(userID string, info *adapter.Info) *adapter.ProviderEvent {
+	return s.wrapEvent(
+		adapter.EventUserChanged,
+		info,
+		&adapter.UserChangedEvent{UserID: userID},
+	)
+}
+
 // wrapEvent creates a new ProviderEvent instance with metadata and the Event data attached.
 func (s *ClassicAdapter) wrapEvent(eventType adapter.EventType, info *adapter.Info, data interface{}) *adapter.ProviderEvent {
 	return &adapter.ProviderEvent{
@@ -19,6 +19,8 @@ package slack
 import (
 	"context"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/getgort/gort/adapter"
 	"github.com/getgort/gort/data"
@@ -32,6 +34,10 @@ import (
 )
 
 var _ adapter.Adapter = &SocketModeAdapter{}
+var _ adapter.AttachmentDownloader = &SocketModeAdapter{}
+var _ adapter.ChannelJoiner = &SocketModeAdapter{}
+var _ adapter.HistoryReader = &SocketModeAdapter{}
+var _ adapter.MessageLimiter = &SocketModeAdapter{}
 
 // SocketModeAdapter is the Slack provider implementation of a relay, which knows how
 // to receive events from the Slack API, translate them into Gort events, and
@@ -86,6 +92,29 @@ func (s *SocketModeAdapter) GetUserInfo(userID string) (*adapter.UserInfo, error
 	return newUserInfoFromSlackUser(u), nil
 }
 
+// GetUserPresence returns the presence of the specified user.
+func (s *SocketModeAdapter) GetUserPresence(userID string) (string, error) {
+	return getUserPresence(s.client, userID)
+}
+
+// JoinChannel causes the bot to join the specified channel, which may be
+// given by ID or by name.
+func (s *SocketModeAdapter) JoinChannel(channel string) (*adapter.ChannelInfo, error) {
+	return joinChannel(s.client, channel)
+}
+
+// LeaveChannel causes the bot to leave the specified channel, which may be
+// given by ID or by name.
+func (s *SocketModeAdapter) LeaveChannel(channel string) error {
+	return leaveChannel(s.client, channel)
+}
+
+// OpenDirectChannel opens (or finds the existing) direct message channel
+// with the specified user, and returns its ID.
+func (s *SocketModeAdapter) OpenDirectChannel(userID string) (string, error) {
+	return openDirectChannel(s.client, userID)
+}
+
 // Listen causes the Adapter to initiate a connection to its provider and
 // begin relaying back events (including errors) via the returned channel.
 func (s *SocketModeAdapter) Listen(ctx context.Context) <-chan *adapter.ProviderEvent {
@@ -175,12 +204,38 @@ func (s *SocketModeAdapter) Listen(ctx context.Context) <-chan *adapter.Provider
 								WithField("channel_type", ev.ChannelType).
 								Debug("Slack event: unhandled channel type")
 						}
+					case *slackevents.ChannelRenameEvent:
+						events <- s.onChannelChanged(ev.Channel.ID, info)
+					case *slackevents.GroupRenameEvent:
+						events <- s.onChannelChanged(ev.Channel.ID, info)
+					case *slack.UserChangeEvent:
+						events <- s.onUserChanged(ev.User.ID, info)
 					default:
 						e.WithField("message.data", fmt.Sprintf("%+v", evt.Data)).
 							WithField("type", eventsAPIEvent.Type).
 							Debug("Slack event: unhandled Events API event type")
 					}
 				}
+			case socketmode.EventTypeInteractive:
+				callback, ok := evt.Data.(slack.InteractionCallback)
+				if !ok {
+					e.WithField("message.data", fmt.Sprintf("%+v", evt.Data)).
+						Debug("Slack event: ignored interaction")
+					continue
+				}
+				s.socketClient.Ack(*evt.Request)
+
+				if callback.Type != slack.InteractionTypeBlockActions {
+					e.WithField("type", callback.Type).
+						Debug("Slack event: unhandled interaction type")
+					continue
+				}
+
+				for _, action := range callback.ActionCallback.BlockActions {
+					if ie := interactionFromBlockAction(&callback, action); ie != nil {
+						events <- s.wrapEvent(adapter.EventInteraction, info, ie)
+					}
+				}
 			case socketmode.EventTypeHello:
 				// Do nothing for now
 			default:
@@ -207,12 +262,50 @@ func (s *SocketModeAdapter) Listen(ctx context.Context) <-chan *adapter.Provider
 	return events
 }
 
+// Markup returns Slack's mrkdwn formatting delimiters.
+func (s *SocketModeAdapter) Markup() data.Markup {
+	return mrkdwn
+}
+
+// MaxMessageLength returns the longest text message that Slack recommends
+// posting.
+func (s *SocketModeAdapter) MaxMessageLength() int {
+	return maxMessageLength
+}
+
 // Send the contents of a response envelope to a specified channel. If
 // channelID is empty the value of envelope.Request.ChannelID will be used.
 func (s *SocketModeAdapter) Send(ctx context.Context, channelID string, elements templates.OutputElements) error {
 	return Send(ctx, s.client, s, channelID, elements)
 }
 
+// SendDeletable sends the contents of a response envelope to the specified
+// channel, and returns the ID of the message so that it can be deleted.
+func (s *SocketModeAdapter) SendDeletable(ctx context.Context, channelID string, elements templates.OutputElements) (string, error) {
+	return SendDeletable(ctx, s.client, s, channelID, elements)
+}
+
+// DeleteMessage deletes a message previously sent by the adapter.
+func (s *SocketModeAdapter) DeleteMessage(ctx context.Context, channelID string, messageID string) error {
+	return DeleteMessage(ctx, s.client, channelID, messageID)
+}
+
+// ChannelHistory returns the messages posted by users to a channel after
+// since, oldest first.
+func (s *SocketModeAdapter) ChannelHistory(ctx context.Context, channelID string, since time.Time) ([]adapter.HistoryMessage, error) {
+	return ChannelHistory(ctx, s.client, channelID, since)
+}
+
+// DownloadAttachment downloads a file shared in a message.
+func (s *SocketModeAdapter) DownloadAttachment(ctx context.Context, attachment adapter.Attachment, w io.Writer) error {
+	return DownloadAttachment(ctx, s.provider.BotToken, attachment, w)
+}
+
+// React adds an emoji reaction to a message.
+func (s *SocketModeAdapter) React(ctx context.Context, channelID string, messageID string, emoji data.Emoji) error {
+	return React(ctx, s.client, channelID, messageID, emoji)
+}
+
 // SendText sends a simple text message to the specified channel.
 func (s *SocketModeAdapter) SendText(ctx context.Context, channelID string, message string) error {
 	return SendText(ctx, s.client, s, channelID, message)
@@ -231,13 +324,25 @@ func (s *SocketModeAdapter) onChannelMessage(event *slackevents.MessageEvent, in
 		adapter.EventChannelMessage,
 		info,
 		&adapter.ChannelMessageEvent{
-			ChannelID: event.Channel,
-			Text:      ScrubMarkdown(event.Text),
-			UserID:    event.User,
+			Attachments: eventFileAttachments(event.Files),
+			ChannelID:   event.Channel,
+			MessageID:   event.TimeStamp,
+			Text:        ScrubMarkdown(event.Text),
+			UserID:      event.User,
 		},
 	)
 }
 
+// onChannelChanged is called when the Slack API emits a channel_rename or
+// group_rename event.
+func (s *SocketModeAdapter) onChannelChanged(channelID string, info *adapter.Info) *adapter.ProviderEvent {
+	return s.wrapEvent(
+		adapter.EventChannelChanged,
+		info,
+		&adapter.ChannelChangedEvent{ChannelID: channelID},
+	)
+}
+
 // onConnected is called when the Slack API emits a ConnectedEvent.
 func (s *SocketModeAdapter) onConnected(info *adapter.Info) *adapter.ProviderEvent {
 	return s.wrapEvent(
@@ -262,9 +367,11 @@ func (s *SocketModeAdapter) onDirectMessage(event *slackevents.MessageEvent, inf
 		adapter.EventDirectMessage,
 		info,
 		&adapter.DirectMessageEvent{
-			ChannelID: event.Channel,
-			Text:      ScrubMarkdown(event.Text),
-			UserID:    event.User,
+			Attachments: eventFileAttachments(event.Files),
+			ChannelID:   event.Channel,
+			MessageID:   event.TimeStamp,
+			Text:        ScrubMarkdown(event.Text),
+			UserID:      event.User,
 		},
 	)
 }
@@ -289,6 +396,15 @@ func (s *SocketModeAdapter) onInvalidAuth(info *adapter.Info) *adapter.ProviderE
 	)
 }
 
+// onUserChanged is called when the Slack API emits a user_change event.
+func (s *SocketModeAdapter) onUserChanged(userID string, info *adapter.Info) *adapter.ProviderEvent {
+	return s.wrapEvent(
+		adapter.EventUserChanged,
+		info,
+		&adapter.UserChangedEvent{UserID: userID},
+	)
+}
+
 // wrapEvent creates a new ProviderEvent instance with metadata and the Event data attached.
 func (s *SocketModeAdapter) wrapEvent(eventType adapter.EventType, info *adapter.Info, data interface{}) *adapter.ProviderEvent {
 	return &adapter.ProviderEvent{
@@ -298,3 +414,21 @@ func (s *SocketModeAdapter) wrapEvent(eventType adapter.EventType, info *adapter
 		Adapter:   s,
 	}
 }
+
+// eventFileAttachments describes the files shared in a message event as
+// attachments.
+func eventFileAttachments(files []slackevents.File) []adapter.Attachment {
+	var attachments []adapter.Attachment
+
+	for _, f := range files {
+		attachments = append(attachments, adapter.Attachment{
+			ID:          f.ID,
+			Name:        f.Name,
+			ContentType: f.Mimetype,
+			Size:        int64(f.Size),
+			URL:         f.URLPrivateDownload,
+		})
+	}
+
+	return attachments
+}
@@ -31,8 +31,35 @@ func newUserInfoFromSlackUser(slackUser *slack.User) *adapter.UserInfo {
 	u.Email = slackUser.Profile.Email
 	u.FirstName = slackUser.Profile.FirstName
 	u.LastName = slackUser.Profile.LastName
+	u.Locale = slackUser.Locale
 	u.RealName = slackUser.RealName
 	u.RealNameNormalized = slackUser.Profile.RealNameNormalized
 
 	return u
 }
+
+// openDirectChannel opens (or finds the existing) direct message
+// conversation with a Slack user, and returns its ID.
+func openDirectChannel(client *slack.Client, userID string) (string, error) {
+	ch, _, _, err := client.OpenConversation(&slack.OpenConversationParameters{Users: []string{userID}})
+	if err != nil {
+		return "", err
+	}
+
+	return ch.ID, nil
+}
+
+// getUserPresence returns adapter.PresenceActive if the Slack user is
+// active, or adapter.PresenceAway otherwise.
+func getUserPresence(client *slack.Client, userID string) (string, error) {
+	p, err := client.GetUserPresence(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if p.Presence == "active" {
+		return adapter.PresenceActive, nil
+	}
+
+	return adapter.PresenceAway, nil
+}
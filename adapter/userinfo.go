@@ -25,6 +25,30 @@ type UserInfo struct {
 	Email                 string
 	FirstName             string
 	LastName              string
+	Locale                string
 	RealName              string
 	RealNameNormalized    string
 }
+
+// User presence values reported by PresenceReporter.
+const (
+	PresenceActive = "active"
+	PresenceAway   = "away"
+)
+
+// DirectMessenger is an optional interface implemented by adapters whose chat
+// provider requires a direct message channel to be opened before a user can
+// be messaged. Adapters that don't implement it are sent direct messages
+// using the user's ID as the channel ID.
+type DirectMessenger interface {
+	// OpenDirectChannel opens (or finds the existing) direct message channel
+	// with the specified user, and returns its ID.
+	OpenDirectChannel(userID string) (string, error)
+}
+
+// PresenceReporter is an optional interface implemented by adapters whose
+// chat provider reports whether users are online.
+type PresenceReporter interface {
+	// GetUserPresence returns PresenceActive or PresenceAway.
+	GetUserPresence(userID string) (string, error)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+)
+
+const (
+	// APITokenAudience is the audience of API tokens. Tokens with any other
+	// audience aren't accepted by the REST API.
+	APITokenAudience = "gort-api"
+
+	// APITokenIssuer is the issuer of API tokens.
+	APITokenIssuer = "gort"
+)
+
+var (
+	generatedAPIKey     []byte
+	generatedAPIKeyErr  error
+	generatedAPIKeyOnce sync.Once
+)
+
+// APIClaims are the claims of an API token issued in the "jwt" mode of
+// global.api_tokens. Each token has a unique ID, so that it can be revoked
+// before it expires. SessionStart is when the user last authenticated with
+// their password: refreshing a token carries it forward, so that a session
+// can't be refreshed forever.
+type APIClaims struct {
+	Audience     string `json:"aud"`
+	ExpiresAt    int64  `json:"exp"`
+	ID           string `json:"jti"`
+	IssuedAt     int64  `json:"iat"`
+	Issuer       string `json:"iss"`
+	SessionStart int64  `json:"gort_session_start"`
+	Subject      string `json:"sub"`
+}
+
+// NewAPIClaims returns the claims of an API token for the user, with a new
+// random ID, issued at the given time and valid for lifetime.
+func NewAPIClaims(username string, sessionStart, now time.Time, lifetime time.Duration) (APIClaims, error) {
+	id, err := data.GenerateRandomToken(32)
+	if err != nil {
+		return APIClaims{}, err
+	}
+
+	return APIClaims{
+		Audience:     APITokenAudience,
+		ExpiresAt:    now.Add(lifetime).Unix(),
+		ID:           id,
+		IssuedAt:     now.Unix(),
+		Issuer:       APITokenIssuer,
+		SessionStart: sessionStart.Unix(),
+		Subject:      username,
+	}, nil
+}
+
+// Validate returns an error if the claims have expired as of now, or weren't
+// issued by Gort for the REST API.
+func (c APIClaims) Validate(now time.Time) error {
+	if c.Audience != APITokenAudience || c.Issuer != APITokenIssuer {
+		return ErrInvalidAudience
+	}
+
+	if c.ID == "" || c.Subject == "" {
+		return ErrMalformedToken
+	}
+
+	if now.Unix() >= c.ExpiresAt {
+		return ErrTokenExpired
+	}
+
+	return nil
+}
+
+// SignAPIToken returns the claims as an API token, signed with the
+// configured API token key.
+func SignAPIToken(claims APIClaims) (string, error) {
+	key, err := APITokenKey()
+	if err != nil {
+		return "", err
+	}
+
+	return signJWT(key, claims)
+}
+
+// VerifyAPIToken verifies the API token's signature with the configured API
+// token key, and returns its claims if they're valid. It doesn't consult the
+// revocation list.
+func VerifyAPIToken(token string) (APIClaims, error) {
+	key, err := APITokenKey()
+	if err != nil {
+		return APIClaims{}, err
+	}
+
+	return ParseAPIToken(key, token, time.Now())
+}
+
+// ParseAPIToken verifies the API token's signature with key, and returns its
+// claims if they're valid as of now.
+func ParseAPIToken(key []byte, token string, now time.Time) (APIClaims, error) {
+	var claims APIClaims
+
+	if err := verifyJWT(key, token, &claims); err != nil {
+		return APIClaims{}, err
+	}
+
+	if err := claims.Validate(now); err != nil {
+		return APIClaims{}, err
+	}
+
+	return claims, nil
+}
+
+// IsJWT returns true if the token has the form of a JWT rather than of an
+// opaque API token, which never contains a ".".
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// APITokenKey returns the key that signs API tokens: the one set by
+// global.api_tokens.signing_key or signing_key_file if either is set,
+// otherwise a random key generated the first time it's requested.
+func APITokenKey() ([]byte, error) {
+	tc := config.GetGlobalConfigs().APITokens
+
+	key, err := readTokenKey(tc.SigningKey, tc.SigningKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API token key: %w", err)
+	}
+	if key != nil {
+		return key, nil
+	}
+
+	generatedAPIKeyOnce.Do(func() {
+		log.Warn("No API token signing key is configured; generating one. " +
+			"API tokens won't be accepted by any other controller.")
+
+		generatedAPIKey = make([]byte, 32)
+		_, generatedAPIKeyErr = rand.Read(generatedAPIKey)
+	})
+
+	return generatedAPIKey, generatedAPIKeyErr
+}
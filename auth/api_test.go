@@ -0,0 +1,102 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestParseAPIToken(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Unix(1600000000, 0)
+
+	claims, err := NewAPIClaims("alice", now.Add(-time.Hour), now, time.Minute)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+
+	token, err := signJWT(key, claims)
+	require.NoError(t, err)
+	assert.True(t, IsJWT(token))
+
+	parsed, err := ParseAPIToken(key, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, claims, parsed)
+	assert.Equal(t, "alice", parsed.Subject)
+	assert.Equal(t, now.Add(-time.Hour).Unix(), parsed.SessionStart)
+
+	// Every token has its own ID.
+	other, err := NewAPIClaims("alice", now, now, time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, claims.ID, other.ID)
+
+	// Expired after its lifetime
+	_, err = ParseAPIToken(key, token, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	// Signed with a different key
+	_, err = ParseAPIToken([]byte("fedcba9876543210fedcba9876543210"), token, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// A worker token isn't an API token
+	request := data.CommandRequest{RequestID: 42, UserName: "alice", Deadline: now.Add(time.Minute)}
+	token, err = signJWT(key, NewWorkerClaims(request, now))
+	require.NoError(t, err)
+	_, err = ParseAPIToken(key, token, now)
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+
+	// Opaque tokens aren't JWTs
+	opaque, err := data.GenerateRandomToken(64)
+	require.NoError(t, err)
+	assert.False(t, IsJWT(opaque))
+}
+
+func TestParseOIDCState(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Unix(1600000000, 0)
+
+	state, err := NewOIDCState(now, time.Minute)
+	require.NoError(t, err)
+	assert.NotEmpty(t, state.Nonce)
+
+	token, err := signJWT(key, state)
+	require.NoError(t, err)
+
+	parsed, err := ParseOIDCState(key, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, state, parsed)
+
+	_, err = ParseOIDCState(key, token, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	// An API token isn't a login state, and vice versa
+	claims, err := NewAPIClaims("alice", now, now, time.Minute)
+	require.NoError(t, err)
+	apiToken, err := signJWT(key, claims)
+	require.NoError(t, err)
+
+	_, err = ParseOIDCState(key, apiToken, now)
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+
+	_, err = ParseAPIToken(key, token, now)
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+}
@@ -18,12 +18,15 @@ package auth
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 
 	// "fmt"
 
 	"github.com/getgort/gort/data"
 	gerrs "github.com/getgort/gort/errors"
 	"github.com/getgort/gort/rules"
+	"github.com/getgort/gort/types"
 )
 
 const (
@@ -79,11 +82,15 @@ func EvaluateCommandEntry(perms []string, ce data.CommandEntry, env rules.Evalua
 
 // ParseCommandEntry is a helper function that accepts a fully-constructed
 // data.CommandEntry, tokenizes and parses all of the command's rule strings,
-// and returns a []Rules value.
+// including those generated from its option permissions, and returns a
+// []Rules value.
 func ParseCommandEntry(ce data.CommandEntry) ([]rules.Rule, error) {
 	rr := []rules.Rule{}
 
-	for i, r := range ce.Command.Rules {
+	all := append([]string{}, ce.Command.Rules...)
+	all = append(all, OptionPermissionRules(ce)...)
+
+	for i, r := range all {
 		s := fmt.Sprintf("%s:%s %s", ce.Bundle.Name, ce.Command.Name, r)
 
 		rule, err := rules.TokenizeAndParse(s)
@@ -96,3 +103,53 @@ func ParseCommandEntry(ce data.CommandEntry) ([]rules.Rule, error) {
 
 	return rr, nil
 }
+
+// OptionPermissionRules returns the rules, in the form used in a bundle's
+// "rules" section, that are generated from the permissions declared by the
+// command's options. Each option value that requires a permission generates
+// a rule like `with option["env"] == "prod" must have deploy:deploy-prod`.
+// Rules are ordered by option name and then by value.
+func OptionPermissionRules(ce data.CommandEntry) []string {
+	var rr []string
+
+	names := make([]string, 0, len(ce.Command.Options))
+	for name := range ce.Command.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		o := ce.Command.Options[name]
+		if o == nil {
+			continue
+		}
+
+		values := make([]string, 0, len(o.Permissions))
+		for v := range o.Permissions {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+
+		for _, v := range values {
+			rr = append(rr, fmt.Sprintf("with option[%s] == %s must have %s:%s",
+				strconv.Quote(name), optionValueLiteral(v), ce.Bundle.Name, o.Permissions[v]))
+		}
+	}
+
+	return rr
+}
+
+// optionValueLiteral returns v as it should appear in a rule condition so
+// that it's compared to option values of the same type: values that are
+// recognizably booleans or numbers are left as they are, and anything else
+// is quoted as a string.
+func optionValueLiteral(v string) string {
+	if val, err := (types.Inferrer{}).Infer(v); err == nil {
+		switch val.(type) {
+		case types.BoolValue, types.FloatValue, types.IntValue:
+			return v
+		}
+	}
+
+	return strconv.Quote(v)
+}
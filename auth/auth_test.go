@@ -138,3 +138,78 @@ func TestParseCommandEntry(t *testing.T) {
 		assert.Equal(t, expected[i].Permissions, rule.Permissions)
 	}
 }
+
+func BenchmarkEvaluateCommandEntry(b *testing.B) {
+	bundle, err := bundles.LoadBundleFromFile("../testing/test-default.yml")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	cmd, env, err := parse("gort:user --help")
+	if err != nil {
+		b.Fatal(err)
+	}
+	entry := data.CommandEntry{Bundle: bundle, Command: *bundle.Commands[cmd.Command]}
+	perms := []string{"test:foo", "gort:manage_users"}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := EvaluateCommandEntry(perms, entry, env); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestOptionPermissionRules(t *testing.T) {
+	ce := data.CommandEntry{
+		Bundle: data.Bundle{Name: "deploy"},
+		Command: data.BundleCommand{
+			Name:  "app",
+			Rules: []string{"must have deploy:app"},
+			Options: map[string]*data.BundleCommandOption{
+				"env": {Permissions: map[string]string{
+					"staging": "deploy-staging",
+					"prod":    "deploy-prod",
+				}},
+				"replicas": {Permissions: map[string]string{"10": "scale-large"}},
+				"verbose":  nil,
+			},
+		},
+	}
+
+	assert.Equal(t, []string{
+		`with option["env"] == "prod" must have deploy:deploy-prod`,
+		`with option["env"] == "staging" must have deploy:deploy-staging`,
+		`with option["replicas"] == 10 must have deploy:scale-large`,
+	}, OptionPermissionRules(ce))
+
+	tests := []struct {
+		command  string
+		perms    []string
+		expected bool
+	}{
+		{"deploy:app", []string{"deploy:app"}, true},
+		{"deploy:app --env dev", []string{"deploy:app"}, true},
+		{"deploy:app --env prod", []string{"deploy:app"}, false},
+		{"deploy:app --env prod", []string{"deploy:app", "deploy:deploy-staging"}, false},
+		{"deploy:app --env prod", []string{"deploy:app", "deploy:deploy-prod"}, true},
+		{"deploy:app --env staging", []string{"deploy:app", "deploy:deploy-staging"}, true},
+		{"deploy:app --env staging", []string{"deploy:deploy-staging"}, false},
+		{"deploy:app --replicas 10", []string{"deploy:app"}, false},
+		{"deploy:app --replicas 10", []string{"deploy:app", "deploy:scale-large"}, true},
+	}
+
+	for _, test := range tests {
+		cmd, err := command.TokenizeAndParse(test.command,
+			command.ParseOptionHasArgument("env", true),
+			command.ParseOptionHasArgument("replicas", true))
+		assert.NoError(t, err)
+
+		env := rules.EvaluationEnvironment{"option": cmd.OptionsValues(), "arg": cmd.Parameters}
+
+		result, err := EvaluateCommandEntry(test.perms, ce, env)
+		assert.NoError(t, err, test.command)
+		assert.Equal(t, test.expected, result, "%s with %v", test.command, test.perms)
+	}
+}
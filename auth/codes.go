@@ -0,0 +1,42 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	gerrs "github.com/getgort/gort/errors"
+)
+
+func init() {
+	gerrs.RegisterCode(ErrRuleLoadError, gerrs.Code{
+		Code:        "GORT-2101",
+		Title:       "Rule load failure",
+		Description: "One or more of the command's rules couldn't be parsed.",
+		Remediation: "Ask the bundle author or a Gort administrator to correct the command's rules.",
+	})
+	gerrs.RegisterCode(ErrNoRulesDefined, gerrs.Code{
+		Code:        "GORT-2102",
+		Title:       "No rules defined",
+		Description: "The command has no rules. For a command to be executable, it must have at least one rule.",
+		Remediation: "Ask the bundle author to add at least one rule (such as \"allow\") to the command.",
+	})
+	gerrs.RegisterCode(ErrBadPermissionScope, gerrs.Code{
+		Code:        "GORT-2103",
+		Title:       "Invalid permission scope",
+		Description: "A bundle-scoped permission (like \"bundle_enable@team-*\") has an empty or malformed bundle name pattern.",
+		Remediation: "Correct the pattern following the pattern syntax of Go's path.Match function.",
+	})
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"time"
+
+	"github.com/getgort/gort/data"
+)
+
+// OIDCStateAudience is the audience of the state of an OpenID Connect
+// browser login, which distinguishes it from an API token signed with the
+// same key.
+const OIDCStateAudience = "gort-oidc-state"
+
+// OIDCState is the state of an OpenID Connect browser login. It's passed
+// through the identity provider, and back to Gort's callback endpoint, as a
+// signed JWT, so that a login can be completed by any controller. Nonce is
+// also sent to the provider, which includes it in the ID token it issues.
+type OIDCState struct {
+	Audience  string `json:"aud"`
+	ExpiresAt int64  `json:"exp"`
+	Nonce     string `json:"nonce"`
+}
+
+// NewOIDCState returns a login state with a new random nonce, valid for
+// lifetime.
+func NewOIDCState(now time.Time, lifetime time.Duration) (OIDCState, error) {
+	nonce, err := data.GenerateRandomToken(32)
+	if err != nil {
+		return OIDCState{}, err
+	}
+
+	return OIDCState{
+		Audience:  OIDCStateAudience,
+		ExpiresAt: now.Add(lifetime).Unix(),
+		Nonce:     nonce,
+	}, nil
+}
+
+// SignOIDCState returns the state as a JWT signed with the configured API
+// token key.
+func SignOIDCState(state OIDCState) (string, error) {
+	key, err := APITokenKey()
+	if err != nil {
+		return "", err
+	}
+
+	return signJWT(key, state)
+}
+
+// VerifyOIDCState verifies a login state's signature with the configured
+// API token key, and returns it if it hasn't expired.
+func VerifyOIDCState(token string) (OIDCState, error) {
+	key, err := APITokenKey()
+	if err != nil {
+		return OIDCState{}, err
+	}
+
+	return ParseOIDCState(key, token, time.Now())
+}
+
+// ParseOIDCState verifies a login state's signature with key, and returns it
+// if it's valid as of now.
+func ParseOIDCState(key []byte, token string, now time.Time) (OIDCState, error) {
+	var state OIDCState
+
+	if err := verifyJWT(key, token, &state); err != nil {
+		return OIDCState{}, err
+	}
+
+	if state.Audience != OIDCStateAudience {
+		return OIDCState{}, ErrInvalidAudience
+	}
+
+	if state.Nonce == "" {
+		return OIDCState{}, ErrMalformedToken
+	}
+
+	if now.Unix() >= state.ExpiresAt {
+		return OIDCState{}, ErrTokenExpired
+	}
+
+	return state, nil
+}
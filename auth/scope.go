@@ -0,0 +1,106 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ScopeSeparator separates a permission from the bundle name pattern that it
+// is scoped to. For example, "gort:bundle_enable@team-*" grants
+// "gort:bundle_enable", but only for bundles whose names match "team-*".
+const ScopeSeparator = "@"
+
+var (
+	ErrBadPermissionScope = fmt.Errorf("invalid permission scope")
+)
+
+// SplitPermissionScope splits a (possibly scoped) permission into its
+// unscoped permission and its bundle name pattern. The pattern is empty if
+// the permission isn't scoped.
+func SplitPermissionScope(perm string) (string, string) {
+	i := strings.LastIndex(perm, ScopeSeparator)
+	if i < 0 {
+		return perm, ""
+	}
+
+	return perm[:i], perm[i+len(ScopeSeparator):]
+}
+
+// ValidatePermissionScope returns an error if the permission is scoped with
+// an empty or malformed bundle name pattern.
+func ValidatePermissionScope(perm string) error {
+	if !strings.Contains(perm, ScopeSeparator) {
+		return nil
+	}
+
+	_, pattern := SplitPermissionScope(perm)
+	if pattern == "" {
+		return fmt.Errorf("%w: empty bundle pattern in %q", ErrBadPermissionScope, perm)
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("%w: bad bundle pattern in %q", ErrBadPermissionScope, perm)
+	}
+
+	return nil
+}
+
+// ScopePermissions resolves any scoped permissions against the named
+// bundle. Scoped permissions whose pattern matches the bundle name are
+// returned in their unscoped form; those that don't match (or that are
+// malformed) are dropped. Unscoped permissions are returned as-is. If bundle
+// is empty, all scoped permissions are dropped.
+func ScopePermissions(perms []string, bundle string) []string {
+	scoped := make([]string, 0, len(perms))
+
+	for _, p := range perms {
+		perm, pattern := SplitPermissionScope(p)
+
+		if perm == p {
+			scoped = append(scoped, p)
+			continue
+		}
+
+		if bundle == "" {
+			continue
+		}
+
+		if ok, err := path.Match(pattern, bundle); err == nil && ok {
+			scoped = append(scoped, perm)
+		}
+	}
+
+	return scoped
+}
+
+// UnscopePermissions returns all permissions in their unscoped form,
+// effectively granting scoped permissions for every bundle. It's meant for
+// coarse checks that are followed by a finer, scope-aware check, such as a
+// gort command executed from chat that in turn calls the REST API.
+func UnscopePermissions(perms []string) []string {
+	unscoped := make([]string, 0, len(perms))
+
+	for _, p := range perms {
+		perm, _ := SplitPermissionScope(p)
+		unscoped = append(unscoped, perm)
+	}
+
+	return unscoped
+}
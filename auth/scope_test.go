@@ -0,0 +1,66 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopePermissions(t *testing.T) {
+	perms := []string{
+		"gort:manage_users",
+		"gort:bundle_enable@team-*",
+		"gort:bundle_delete@team-a",
+		"gort:bundle_install@[",
+	}
+
+	tests := map[string][]string{
+		"":       {"gort:manage_users"},
+		"team-a": {"gort:manage_users", "gort:bundle_enable", "gort:bundle_delete"},
+		"team-b": {"gort:manage_users", "gort:bundle_enable"},
+		"other":  {"gort:manage_users"},
+	}
+
+	for bundle, expected := range tests {
+		assert.Equal(t, expected, ScopePermissions(perms, bundle), bundle)
+	}
+
+	assert.Equal(t,
+		[]string{"gort:manage_users", "gort:bundle_enable", "gort:bundle_delete", "gort:bundle_install"},
+		UnscopePermissions(perms))
+}
+
+func TestValidatePermissionScope(t *testing.T) {
+	tests := map[string]bool{
+		"bundle_enable":           true,
+		"bundle_enable@team-*":    true,
+		"bundle_enable@team-[ab]": true,
+		"bundle_enable@":          false,
+		"bundle_enable@[":         false,
+	}
+
+	for perm, valid := range tests {
+		err := ValidatePermissionScope(perm)
+		if valid {
+			assert.NoError(t, err, perm)
+		} else {
+			assert.ErrorIs(t, err, ErrBadPermissionScope, perm)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+)
+
+const (
+	// WorkerTokenAudience is the audience of worker tokens. Tokens with any
+	// other audience aren't accepted by the worker API.
+	WorkerTokenAudience = "gort-worker"
+
+	// WorkerTokenIssuer is the issuer of worker tokens.
+	WorkerTokenIssuer = "gort"
+
+	// DefaultWorkerTokenLifetime is how long a worker token is valid for if
+	// its request has no deadline.
+	DefaultWorkerTokenLifetime = 15 * time.Minute
+)
+
+var (
+	generatedKey     []byte
+	generatedKeyErr  error
+	generatedKeyOnce sync.Once
+)
+
+// WorkerClaims are the claims of a worker token: a signed token that's given
+// to a single command execution, and that's valid only for the request that
+// it was issued for, and only until that request's deadline. Unlike a session
+// token it doesn't grant any of the requesting user's permissions, so a
+// leaked worker environment can't be used to act as that user.
+type WorkerClaims struct {
+	Audience  string `json:"aud"`
+	Bundle    string `json:"gort_bundle"`
+	Command   string `json:"gort_command"`
+	ExpiresAt int64  `json:"exp"`
+	IssuedAt  int64  `json:"iat"`
+	Issuer    string `json:"iss"`
+	RequestID int64  `json:"gort_request_id"`
+	Subject   string `json:"sub"`
+}
+
+// NewWorkerClaims returns the claims of a worker token for the request,
+// issued at the given time. The token expires at the request's deadline, or
+// after DefaultWorkerTokenLifetime if it has none.
+func NewWorkerClaims(request data.CommandRequest, now time.Time) WorkerClaims {
+	expires := request.Deadline
+	if expires.IsZero() {
+		expires = now.Add(DefaultWorkerTokenLifetime)
+	}
+
+	return WorkerClaims{
+		Audience:  WorkerTokenAudience,
+		Bundle:    request.Bundle.Name,
+		Command:   request.Command.Name,
+		ExpiresAt: expires.Unix(),
+		IssuedAt:  now.Unix(),
+		Issuer:    WorkerTokenIssuer,
+		RequestID: request.RequestID,
+		Subject:   request.UserName,
+	}
+}
+
+// Validate returns an error if the claims have expired as of now, or weren't
+// issued by Gort for a worker.
+func (c WorkerClaims) Validate(now time.Time) error {
+	if c.Audience != WorkerTokenAudience || c.Issuer != WorkerTokenIssuer {
+		return ErrInvalidAudience
+	}
+
+	if c.RequestID == 0 || c.Subject == "" {
+		return ErrMalformedToken
+	}
+
+	if now.Unix() >= c.ExpiresAt {
+		return ErrTokenExpired
+	}
+
+	return nil
+}
+
+// SignWorkerToken returns a worker token for the request, signed with the
+// configured worker token key.
+func SignWorkerToken(request data.CommandRequest) (string, error) {
+	key, err := WorkerTokenKey()
+	if err != nil {
+		return "", err
+	}
+
+	return signJWT(key, NewWorkerClaims(request, time.Now()))
+}
+
+// VerifyWorkerToken verifies the worker token's signature with the
+// configured worker token key, and returns its claims if they're valid.
+func VerifyWorkerToken(token string) (WorkerClaims, error) {
+	key, err := WorkerTokenKey()
+	if err != nil {
+		return WorkerClaims{}, err
+	}
+
+	return ParseWorkerToken(key, token, time.Now())
+}
+
+// ParseWorkerToken verifies the worker token's signature with key, and
+// returns its claims if they're valid as of now.
+func ParseWorkerToken(key []byte, token string, now time.Time) (WorkerClaims, error) {
+	var claims WorkerClaims
+
+	if err := verifyJWT(key, token, &claims); err != nil {
+		return WorkerClaims{}, err
+	}
+
+	if err := claims.Validate(now); err != nil {
+		return WorkerClaims{}, err
+	}
+
+	return claims, nil
+}
+
+// WorkerTokenKey returns the key that signs worker tokens: the one set by
+// worker.token_key or worker.token_key_file if either is set, otherwise a
+// random key generated the first time it's requested.
+func WorkerTokenKey() ([]byte, error) {
+	wc := config.GetWorkerConfigs()
+
+	key, err := readTokenKey(wc.TokenKey, wc.TokenKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worker token key: %w", err)
+	}
+	if key != nil {
+		return key, nil
+	}
+
+	generatedKeyOnce.Do(func() {
+		log.Warn("No worker token key is configured; generating one. " +
+			"Worker tokens won't be accepted by any other controller.")
+
+		generatedKey = make([]byte, 32)
+		_, generatedKeyErr = rand.Read(generatedKey)
+	})
+
+	return generatedKey, generatedKeyErr
+}
+
+// readTokenKey decodes encoded, or if it's empty the content of file, as a
+// token signing key. If neither is set it returns a nil key.
+func readTokenKey(encoded, file string) ([]byte, error) {
+	if encoded == "" && file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		encoded = strings.TrimSpace(string(b))
+	}
+
+	if encoded == "" {
+		return nil, nil
+	}
+
+	return data.DecodeWorkerTokenKey(encoded)
+}
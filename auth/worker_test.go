@@ -0,0 +1,89 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestParseWorkerToken(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Unix(1600000000, 0)
+
+	request := data.CommandRequest{
+		CommandEntry: data.CommandEntry{
+			Bundle:  data.Bundle{Name: "test"},
+			Command: data.BundleCommand{Name: "echo"},
+		},
+		Deadline:  now.Add(time.Minute),
+		RequestID: 42,
+		UserName:  "alice",
+	}
+
+	claims := NewWorkerClaims(request, now)
+	token, err := signJWT(key, claims)
+	require.NoError(t, err)
+
+	parsed, err := ParseWorkerToken(key, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, claims, parsed)
+	assert.Equal(t, int64(42), parsed.RequestID)
+	assert.Equal(t, "alice", parsed.Subject)
+	assert.Equal(t, now.Add(time.Minute).Unix(), parsed.ExpiresAt)
+
+	// Expired at the request's deadline
+	_, err = ParseWorkerToken(key, token, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	// Signed with a different key
+	_, err = ParseWorkerToken([]byte("fedcba9876543210fedcba9876543210"), token, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Payload altered after signing
+	parts := strings.Split(token, ".")
+	other, err := signJWT(key, NewWorkerClaims(data.CommandRequest{RequestID: 43, UserName: "alice"}, now))
+	require.NoError(t, err)
+	forged := parts[0] + "." + strings.Split(other, ".")[1] + "." + parts[2]
+	_, err = ParseWorkerToken(key, forged, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Some other audience
+	claims.Audience = "gort-api"
+	token, err = signJWT(key, claims)
+	require.NoError(t, err)
+	_, err = ParseWorkerToken(key, token, now)
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+
+	// Not a JWT
+	_, err = ParseWorkerToken(key, "not-a-token", now)
+	assert.ErrorIs(t, err, ErrMalformedToken)
+}
+
+func TestNewWorkerClaimsNoDeadline(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	claims := NewWorkerClaims(data.CommandRequest{RequestID: 1, UserName: "alice"}, now)
+	assert.Equal(t, now.Add(DefaultWorkerTokenLifetime).Unix(), claims.ExpiresAt)
+	assert.NoError(t, claims.Validate(now))
+}
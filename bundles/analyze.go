@@ -0,0 +1,167 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundles
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/rules"
+)
+
+// Codes of the warnings returned by Analyze.
+const (
+	WarnAllowAllRule     = "allow-all-rule"
+	WarnAllowRule        = "allow-rule"
+	WarnInvalidRule      = "invalid-rule"
+	WarnNoRules          = "no-rules"
+	WarnLatestImage      = "latest-image"
+	WarnAdminAccount     = "admin-service-account"
+	WarnMissingTemplates = "missing-templates"
+	WarnMatchAllTrigger  = "match-all-trigger"
+	WarnBroadTrigger     = "broad-trigger"
+	WarnInvalidTrigger   = "invalid-trigger"
+)
+
+// broadTriggerSamples are ordinary chat messages that no trigger should
+// reasonably match. A trigger that matches any of them is likely to fire on
+// unrelated conversation.
+var broadTriggerSamples = []string{"hi", "ok", "thanks!", "lunch?", "see you tomorrow"}
+
+// Analyze inspects a bundle for risky characteristics and returns a warning
+// for each one that it finds. Bundle-wide warnings come first, followed by
+// warnings for each command in name order.
+func Analyze(bundle data.Bundle) []rest.BundleWarning {
+	var warnings []rest.BundleWarning
+
+	warn := func(severity, code, command, format string, a ...interface{}) {
+		warnings = append(warnings, rest.BundleWarning{
+			Severity: severity,
+			Code:     code,
+			Command:  command,
+			Message:  fmt.Sprintf(format, a...),
+		})
+	}
+
+	if bundle.Image != "" {
+		if _, tag := data.NormalizeImage(bundle.Image); tag == "latest" {
+			warn(data.SeverityWarning, WarnLatestImage, "",
+				"image %q uses the \"latest\" tag, so the code that runs can change without the bundle changing", bundle.Image)
+		}
+	}
+
+	k := bundle.Kubernetes
+	if isAdminAccount(k.ServiceAccountName) {
+		warn(data.SeverityCritical, WarnAdminAccount, "",
+			"commands run as the privileged Kubernetes service account %q", k.ServiceAccountName)
+	}
+	for _, group := range sortedKeys(k.GroupServiceAccounts) {
+		if sa := k.GroupServiceAccounts[group]; isAdminAccount(sa) {
+			warn(data.SeverityCritical, WarnAdminAccount, "",
+				"commands invoked by group %q run as the privileged Kubernetes service account %q", group, sa)
+		}
+	}
+
+	names := make([]string, 0, len(bundle.Commands))
+	for name := range bundle.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cmd := bundle.Commands[name]
+		if cmd == nil {
+			continue
+		}
+
+		if len(cmd.Rules) == 0 {
+			warn(data.SeverityInfo, WarnNoRules, name, "command has no rules, so no user can run it")
+		}
+
+		for _, r := range cmd.Rules {
+			rule, err := rules.TokenizeAndParse(fmt.Sprintf("%s:%s %s", bundle.Name, name, r))
+			switch {
+			case err != nil:
+				warn(data.SeverityWarning, WarnInvalidRule, name, "rule %q can't be parsed: %v", r, err)
+			case len(rule.Permissions) > 0:
+			case len(rule.Conditions) == 0:
+				warn(data.SeverityCritical, WarnAllowAllRule, name, "rule %q allows every user to run the command", r)
+			default:
+				warn(data.SeverityWarning, WarnAllowRule, name, "rule %q allows any user to run the command when its conditions match", r)
+			}
+		}
+
+		profiles := map[string]string{}
+		for p, profile := range cmd.Profiles {
+			if profile != nil {
+				profiles[p] = profile.ServiceAccountName
+			}
+		}
+		for _, p := range sortedKeys(profiles) {
+			if sa := profiles[p]; isAdminAccount(sa) {
+				warn(data.SeverityCritical, WarnAdminAccount, name,
+					"profile %q runs as the privileged Kubernetes service account %q", p, sa)
+			}
+		}
+
+		if cmd.Templates.Command == "" && bundle.Templates.Command == "" &&
+			cmd.Templates.CommandError == "" && bundle.Templates.CommandError == "" {
+			warn(data.SeverityInfo, WarnMissingTemplates, name, "command defines no templates, so its output uses the server defaults")
+		}
+
+		for _, t := range cmd.Triggers {
+			re, err := regexp.Compile(t.Match)
+			if err != nil {
+				warn(data.SeverityWarning, WarnInvalidTrigger, name, "trigger %q isn't a valid regular expression: %v", t.Match, err)
+				continue
+			}
+
+			if re.MatchString("") {
+				warn(data.SeverityCritical, WarnMatchAllTrigger, name, "trigger %q matches every message", t.Match)
+				continue
+			}
+
+			for _, s := range broadTriggerSamples {
+				if re.MatchString(s) {
+					warn(data.SeverityWarning, WarnBroadTrigger, name, "trigger %q is overly broad: it matches %q", t.Match, s)
+					break
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+// isAdminAccount returns true if a Kubernetes service account name suggests
+// that it's bound to an administrative role.
+func isAdminAccount(name string) bool {
+	return strings.Contains(strings.ToLower(name), "admin")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
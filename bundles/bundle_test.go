@@ -17,9 +17,12 @@
 package bundles
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/getgort/gort/data"
 )
 
 func TestLoadBundleFromFile(t *testing.T) {
@@ -34,8 +37,13 @@ func TestLoadBundleFromFile(t *testing.T) {
 	assert.Equal(t, "https://guide.getgort.io", b.Homepage)
 	assert.Equal(t, "A test bundle.", b.Description)
 	assert.Equal(t, "This is test bundle.\nThere are many like it, but this one is mine.", b.LongDescription)
+	assert.Equal(t, []string{"ops"}, b.Owners)
 	assert.Len(t, b.Permissions, 1)
 	assert.Equal(t, "ubuntu:20.04", b.Image)
+	assert.Equal(t, data.BundlePlatform{OS: "linux", Arch: "amd64"}, b.Platform)
+	assert.Equal(t, "gort-jobs", b.Kubernetes.Namespace)
+	assert.Equal(t, map[string]string{"pool": "ops"}, b.Kubernetes.NodeSelector)
+	assert.Equal(t, map[string]string{"ops": "ops-service-account"}, b.Kubernetes.GroupServiceAccounts)
 	assert.Len(t, b.Commands, 4)
 
 	// Bundle templates
@@ -51,9 +59,24 @@ func TestLoadBundleFromFile(t *testing.T) {
 
 Usage:
   test:echox [string ...]`, cmd.LongDescription)
+	assert.Equal(t, "[--token TOKEN] [string ...]", cmd.Usage)
+	assert.Equal(t, []string{"test:echox hello world"}, cmd.Examples)
 	assert.Equal(t, []string{"/bin/echo"}, cmd.Executable)
+	assert.Equal(t, data.ANSIConvert, cmd.ANSI)
 	assert.Len(t, cmd.Rules, 1)
 	assert.Equal(t, "must have test:echox", cmd.Rules[0])
+	assert.Equal(t, map[string]*data.BundleCommandOption{
+		"token": {Description: "An access token.", Sensitive: true},
+	}, cmd.Options)
+	assert.Equal(t, map[string]*data.BundleCommandProfile{
+		"prod": {
+			Description:        "Production environment.",
+			Env:                map[string]string{"STAGE": "prod"},
+			EnvSecret:          "prod-secrets",
+			ServiceAccountName: "prod-service-account",
+			Permission:         "echox",
+		},
+	}, cmd.Profiles)
 
 	// Command templates
 	assert.Equal(t, "Template:Command:CommandError", cmd.Templates.CommandError)
@@ -61,3 +84,422 @@ Usage:
 	assert.Equal(t, "Template:Command:MessageError", cmd.Templates.MessageError)
 	assert.Equal(t, "Template:Command:Message", cmd.Templates.Message)
 }
+
+func TestDefault(t *testing.T) {
+	b, err := Default()
+	assert.NoError(t, err)
+	assert.Equal(t, "gort", b.Name)
+	assert.Contains(t, b.Commands["macro"].LongDescription, "{{.name}} placeholders")
+}
+
+func TestNewerVersionInstalled(t *testing.T) {
+	installed := []data.Bundle{{Version: "0.1.0"}, {Version: "0.10.0"}, {Version: "0.2.0"}}
+
+	v, ok := NewerVersionInstalled(installed, "0.3.0")
+	assert.True(t, ok)
+	assert.Equal(t, "0.10.0", v)
+
+	_, ok = NewerVersionInstalled(installed, "0.10.0")
+	assert.False(t, ok)
+
+	_, ok = NewerVersionInstalled(installed, "1.0.0")
+	assert.False(t, ok)
+}
+
+func TestPruneCandidates(t *testing.T) {
+	installed := []data.Bundle{{Version: "0.1.0"}, {Version: "0.10.0"}, {Version: "0.2.0"}, {Version: "0.3.0"}}
+
+	assert.Empty(t, PruneCandidates(installed, "", 0))
+	assert.Empty(t, PruneCandidates(installed, "", 4))
+	assert.Equal(t, []string{"0.2.0", "0.1.0"}, PruneCandidates(installed, "", 2))
+
+	// The enabled version is never pruned.
+	assert.Equal(t, []string{"0.2.0"}, PruneCandidates(installed, "0.1.0", 2))
+}
+
+func TestDiffLines(t *testing.T) {
+	assert.Equal(t, []string{" a", "-b", "+x", " c", "+d"}, diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"}))
+	assert.Equal(t, []string{"+a", "+b"}, diffLines(nil, []string{"a", "b"}))
+	assert.Equal(t, []string{"-a"}, diffLines([]string{"a"}, nil))
+}
+
+func TestDiff(t *testing.T) {
+	base := data.Bundle{Name: "test", Version: "0.1.0", Image: "ubuntu:20.04", Enabled: true}
+	target := data.Bundle{Name: "test", Version: "0.2.0", Image: "ubuntu:20.04", InstalledBy: "someone"}
+
+	diff, err := Diff(base, target)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{" name: test", "-version: 0.1.0", "+version: 0.2.0", " image: ubuntu:20.04"}, diff)
+
+	// Without a base, everything is added.
+	diff, err = Diff(data.Bundle{}, target)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"+name: test", "+version: 0.2.0", "+image: ubuntu:20.04"}, diff)
+}
+
+func TestLoadBundleInvalidPlatform(t *testing.T) {
+	_, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+platform:
+  os: plan9
+`))
+
+	assert.Error(t, err)
+}
+
+func TestLoadBundleCommandPlatform(t *testing.T) {
+	b, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+platform:
+  os: linux
+  arch: amd64
+commands:
+  native:
+    executable: [ "/bin/true" ]
+  arm:
+    executable: [ "/bin/true" ]
+    platform: linux/arm64
+`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, data.BundlePlatform{}, b.Commands["native"].Platform)
+	assert.Equal(t, data.BundlePlatform{OS: "linux", Arch: "arm64"}, b.Commands["arm"].Platform)
+
+	entry := data.CommandEntry{Bundle: b, Command: *b.Commands["arm"]}
+	assert.Equal(t, "linux/arm64", entry.Platform().String())
+}
+
+func TestLoadBundleInvalidCommandPlatform(t *testing.T) {
+	_, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+commands:
+  foo:
+    executable: [ "/bin/true" ]
+    platform:
+      arch: sparc
+`))
+
+	assert.Error(t, err)
+}
+
+func TestLoadBundleInvalidCooldown(t *testing.T) {
+	_, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+commands:
+  foo:
+    executable: [ "/bin/true" ]
+    cooldown: soon
+`))
+
+	assert.Error(t, err)
+}
+
+func TestLoadBundleOutputFilters(t *testing.T) {
+	b, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+output_filters:
+  redact: [ 'AKIA[0-9A-Z]{16}' ]
+  max_lines: 50
+  strip_ansi: true
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, data.OutputFilters{
+		Redact:    []string{`AKIA[0-9A-Z]{16}`},
+		MaxLines:  50,
+		StripANSI: true,
+	}, b.OutputFilters)
+
+	_, err = LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+output_filters:
+  redact: [ 'AKIA[0-9A-Z' ]
+`))
+	assert.Error(t, err)
+}
+
+func TestLoadBundleTelemetryAttributes(t *testing.T) {
+	b, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+telemetry_attributes:
+  team: payments
+  cost.center: "1234"
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, data.TelemetryAttributes{"team": "payments", "cost.center": "1234"}, b.Telemetry)
+
+	_, err = LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+telemetry_attributes:
+  bundle.owner: payments
+`))
+	assert.Error(t, err)
+}
+
+func TestLoadBundleReactions(t *testing.T) {
+	b, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+commands:
+  foo:
+    executable: [ "/bin/true" ]
+    reactions:
+      success: ":white_check_mark:"
+      failure: custom:sadparrot
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, data.CommandReactions{Success: ":white_check_mark:", Failure: "custom:sadparrot"}, b.Commands["foo"].Reactions)
+
+	_, err = LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+commands:
+  foo:
+    executable: [ "/bin/true" ]
+    reactions:
+      success: ":no_such_emoji:"
+`))
+	assert.Error(t, err)
+}
+
+func TestLoadBundleInvalidANSIMode(t *testing.T) {
+	_, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+commands:
+  foo:
+    executable: [ "/bin/true" ]
+    ansi: colour
+`))
+
+	assert.Error(t, err)
+}
+
+func TestLoadBundleCommandProfiles(t *testing.T) {
+	b, err := LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+permissions:
+  - deploy_prod
+commands:
+  deploy:
+    executable: [ "/bin/deploy" ]
+    default_profile: staging
+    profiles:
+      staging:
+        env:
+          STAGE: staging
+      prod:
+        env:
+          STAGE: prod
+        env_secret: prod-secrets
+        serviceAccountName: deployer-prod
+        permission: deploy_prod
+`))
+	assert.NoError(t, err)
+
+	cmd := b.Commands["deploy"]
+	assert.Equal(t, "staging", cmd.DefaultProfile)
+	assert.Equal(t, &data.BundleCommandProfile{
+		Env:                map[string]string{"STAGE": "prod"},
+		EnvSecret:          "prod-secrets",
+		ServiceAccountName: "deployer-prod",
+		Permission:         "deploy_prod",
+	}, cmd.Profiles["prod"])
+
+	_, err = LoadBundle(strings.NewReader(`---
+gort_bundle_version: 1
+name: test
+version: 0.0.1
+commands:
+  deploy:
+    executable: [ "/bin/deploy" ]
+    profiles:
+      prod:
+        permission: deploy_prod
+`))
+	assert.Error(t, err)
+}
+
+func TestAnalyze(t *testing.T) {
+	codes := func(b data.Bundle) []string {
+		var cs []string
+		for _, w := range Analyze(b) {
+			cs = append(cs, w.Command+":"+w.Code)
+		}
+		return cs
+	}
+
+	safe := data.Bundle{
+		Name:      "test",
+		Image:     "ubuntu:20.04",
+		Templates: data.Templates{Command: "{{ text }}{{ .Response.Out }}{{ endtext }}"},
+		Commands: map[string]*data.BundleCommand{
+			"echo": {
+				Rules:    []string{"must have test:echo"},
+				Triggers: []data.Trigger{{Match: `^deploy \w+ to prod$`}},
+			},
+		},
+	}
+	assert.Empty(t, Analyze(safe))
+
+	risky := data.Bundle{
+		Name:  "test",
+		Image: "ubuntu",
+		Kubernetes: data.BundleKubernetes{
+			ServiceAccountName:   "gort-cluster-admin",
+			GroupServiceAccounts: map[string]string{"ops": "ops", "sre": "admin"},
+		},
+		Commands: map[string]*data.BundleCommand{
+			"b": {
+				Rules:    []string{"allow", `with arg[0] == "x" allow`, "allow test:echo"},
+				Triggers: []data.Trigger{{Match: ".*"}, {Match: "(?i)o"}, {Match: "("}},
+			},
+			"a": {
+				Templates: data.Templates{CommandError: "oops"},
+				Profiles:  map[string]*data.BundleCommandProfile{"root": {ServiceAccountName: "Admin"}},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		":" + WarnLatestImage,
+		":" + WarnAdminAccount,
+		":" + WarnAdminAccount,
+		"a:" + WarnNoRules,
+		"a:" + WarnAdminAccount,
+		"b:" + WarnAllowAllRule,
+		"b:" + WarnAllowRule,
+		"b:" + WarnInvalidRule,
+		"b:" + WarnMissingTemplates,
+		"b:" + WarnMatchAllTrigger,
+		"b:" + WarnBroadTrigger,
+		"b:" + WarnInvalidTrigger,
+	}, codes(risky))
+
+	for _, w := range Analyze(risky) {
+		if w.Code == WarnAllowAllRule {
+			assert.Equal(t, data.SeverityCritical, w.Severity)
+		}
+	}
+}
+
+func TestTriggersOverlap(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		overlap bool
+	}{
+		{`^deploy`, `^deploy`, true},
+		{`^deploy \w+`, `deploy (web|db)$`, true},
+		{`(?i)^hello`, `^HELLO there`, true},
+		{`^deploy`, `^rollback`, false},
+		{`^status$`, `^status .+$`, false},
+		{`foo`, `(`, false},
+		{``, `foo`, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.overlap, TriggersOverlap(test.a, test.b), "%q %q", test.a, test.b)
+		assert.Equal(t, test.overlap, TriggersOverlap(test.b, test.a), "%q %q", test.b, test.a)
+	}
+}
+
+func TestTriggerCollisions(t *testing.T) {
+	bundle := data.Bundle{Name: "a", Commands: map[string]*data.BundleCommand{
+		"deploy": {Triggers: []data.Trigger{{Match: `^deploy \w+`}}},
+	}}
+	others := []data.Bundle{
+		{Name: "a", Commands: map[string]*data.BundleCommand{
+			"deploy": {Triggers: []data.Trigger{{Match: `^deploy \w+`}}},
+		}},
+		{Name: "b", Commands: map[string]*data.BundleCommand{
+			"ship": {Triggers: []data.Trigger{{Match: `^deploy web`}}},
+			"stop": {Triggers: []data.Trigger{{Match: `^stop`}}},
+		}},
+	}
+
+	warnings := TriggerCollisions(bundle, others, data.TriggerConfigs{})
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, WarnTriggerCollision, warnings[0].Code)
+		assert.Equal(t, data.SeverityWarning, warnings[0].Severity)
+		assert.Equal(t, "deploy", warnings[0].Command)
+		assert.Contains(t, warnings[0].Message, "b:ship")
+	}
+
+	warnings = TriggerCollisions(bundle, others, data.TriggerConfigs{Precedence: []string{data.TriggerPrecedenceLongestMatch}})
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, data.SeverityInfo, warnings[0].Severity)
+	}
+
+	// Triggers restricted to different channels never collide.
+	bundle.Commands["deploy"].Triggers[0].Channels = []string{"#ops"}
+	others[1].Commands["ship"].Triggers[0].Channels = []string{"release"}
+	assert.Empty(t, TriggerCollisions(bundle, others, data.TriggerConfigs{}))
+
+	others[1].Commands["ship"].Triggers[0].Channels = []string{"release", "ops"}
+	assert.Len(t, TriggerCollisions(bundle, others, data.TriggerConfigs{}), 1)
+}
+
+func TestResolveTriggerPrecedence(t *testing.T) {
+	entry := func(bundle, trigger string) data.CommandEntry {
+		return data.CommandEntry{
+			Bundle:  data.Bundle{Name: bundle},
+			Command: data.BundleCommand{Triggers: []data.Trigger{{Match: trigger}}},
+		}
+	}
+	names := func(entries []data.CommandEntry) []string {
+		var ns []string
+		for _, e := range entries {
+			ns = append(ns, e.Bundle.Name)
+		}
+		return ns
+	}
+
+	entries := []data.CommandEntry{entry("a", `deploy`), entry("b", `deploy \w+`), entry("c", `deploy web`)}
+	message := "deploy web"
+
+	// No strategies leaves the collision in place.
+	resolved, err := ResolveTriggerPrecedence(entries, message, data.TriggerConfigs{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names(resolved))
+
+	tc := data.TriggerConfigs{
+		Precedence:     []string{data.TriggerPrecedencePriority},
+		BundlePriority: map[string]int{"a": 5},
+	}
+	resolved, err = ResolveTriggerPrecedence(entries, message, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, names(resolved))
+
+	// Longest match leaves b and c tied; priority breaks the tie.
+	tc = data.TriggerConfigs{Precedence: []string{data.TriggerPrecedenceLongestMatch}}
+	resolved, err = ResolveTriggerPrecedence(entries, message, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, names(resolved))
+
+	tc = data.TriggerConfigs{
+		Precedence:     []string{data.TriggerPrecedenceLongestMatch, data.TriggerPrecedencePriority},
+		BundlePriority: map[string]int{"c": 1},
+	}
+	resolved, err = ResolveTriggerPrecedence(entries, message, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c"}, names(resolved))
+}
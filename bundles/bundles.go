@@ -18,6 +18,7 @@ package bundles
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -61,9 +62,69 @@ func unmarshal(dat []byte) (data.Bundle, error) {
 		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, err)
 	}
 
+	if err := bun.ValidatePlatforms(); err != nil {
+		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, err)
+	}
+
+	if err := bun.Kubernetes.ValidateGroupServiceAccounts(); err != nil {
+		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("kubernetes: %w", err))
+	}
+
+	if err := bun.Kubernetes.ValidateNamespace(); err != nil {
+		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("kubernetes: %w", err))
+	}
+
+	if err := bun.Serverless.Validate(); err != nil {
+		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("serverless: %w", err))
+	}
+
+	if err := bun.OutputFilters.Validate(); err != nil {
+		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("output_filters: %w", err))
+	}
+
+	if err := bun.Resources.Validate(); err != nil {
+		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("resources: %w", err))
+	}
+
+	if err := bun.ValidateOwners(); err != nil {
+		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("owners: %w", err))
+	}
+
+	if err := bun.Telemetry.Validate(); err != nil {
+		return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("telemetry_attributes: %w", err))
+	}
+
 	// Ensure that the command name is propagated from the map key.
 	for n := range bun.Commands {
 		(bun.Commands[n]).Name = n
+
+		if _, err := bun.Commands[n].CooldownDuration(); err != nil {
+			return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("command %s: %w", n, err))
+		}
+
+		if _, err := bun.Commands[n].TimeoutDuration(); err != nil {
+			return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("command %s: %w", n, err))
+		}
+
+		if _, err := bun.Commands[n].EphemeralDuration(); err != nil {
+			return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("command %s: %w", n, err))
+		}
+
+		if err := bun.Commands[n].Reactions.Validate(); err != nil {
+			return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("command %s: %w", n, err))
+		}
+
+		if err := bun.Commands[n].ANSI.Validate(); err != nil {
+			return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("command %s: %w", n, err))
+		}
+
+		if err := bun.Commands[n].ValidateProfiles(bun.Permissions); err != nil {
+			return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("command %s: %w", n, err))
+		}
+
+		if err := bun.Commands[n].ValidateOptionPermissions(bun.Permissions); err != nil {
+			return data.Bundle{}, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("command %s: %w", n, err))
+		}
 	}
 
 	return bun, nil
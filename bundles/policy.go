@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundles
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// ErrImageNotAllowed is returned by CheckImage when an image isn't permitted
+// by global.allowed_images.
+var ErrImageNotAllowed = errors.New("image not allowed")
+
+// ErrBundleAnalysisBlocked is returned by CheckAnalysis when a bundle has a
+// warning at or above global.bundle_analysis.block_severity.
+var ErrBundleAnalysisBlocked = errors.New("bundle blocked by analysis")
+
+func init() {
+	gerrs.RegisterCode(ErrImageNotAllowed, gerrs.Code{
+		Code:        "GORT-3101",
+		Title:       "Image not allowed",
+		Description: "The bundle's image isn't permitted by the Gort server's image allowlist.",
+		Remediation: "Use an image from an allowed registry, or ask a Gort administrator to add it to global.allowed_images.",
+	})
+	gerrs.RegisterCode(ErrBundleAnalysisBlocked, gerrs.Code{
+		Code:        "GORT-3102",
+		Title:       "Bundle blocked by analysis",
+		Description: "Analysis of the bundle found a risk at or above the severity that the Gort server's policy blocks.",
+		Remediation: "Address the reported warnings and install the bundle again, or ask a Gort administrator to change global.bundle_analysis.block_severity.",
+	})
+
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusForbidden, Level: log.WarnLevel},
+		ErrImageNotAllowed,
+		ErrBundleAnalysisBlocked,
+	)
+}
+
+// CheckImage returns an error wrapping ErrImageNotAllowed unless image is
+// allowed by global.allowed_images. Bundles without an image, and the
+// default bundle's image (in any version), are always allowed.
+func CheckImage(image string) error {
+	if image == "" {
+		return nil
+	}
+
+	if def, err := Default(); err == nil {
+		repository, _ := data.NormalizeImage(def.Image)
+		if r, _ := data.NormalizeImage(image); r == repository {
+			return nil
+		}
+	}
+
+	allowed, err := config.GetGlobalConfigs().AllowedImages.Allows(image)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return gerrs.Wrap(ErrImageNotAllowed, fmt.Errorf("image %q isn't in global.allowed_images", image))
+	}
+
+	return nil
+}
+
+// CheckAnalysis returns an error wrapping ErrBundleAnalysisBlocked if any of
+// warnings is severe enough to be blocked by global.bundle_analysis.
+func CheckAnalysis(warnings []rest.BundleWarning) error {
+	policy := config.GetGlobalConfigs().BundleAnalysis
+
+	for _, w := range warnings {
+		if policy.Blocks(w.Severity) {
+			return gerrs.Wrap(ErrBundleAnalysisBlocked,
+				fmt.Errorf("%s warning %s: %s", w.Severity, w.Code, w.Message))
+		}
+	}
+
+	return nil
+}
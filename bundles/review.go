@@ -0,0 +1,113 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundles
+
+import (
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/getgort/gort/data"
+)
+
+// Diff returns a line diff of the YAML definitions of two versions of a
+// bundle, as presented for review. Each line is prefixed with "-" if it's
+// only in base, "+" if it's only in target, or " " if it's in both. If base
+// is the zero value, every line of target is added.
+func Diff(base, target data.Bundle) ([]string, error) {
+	var a []string
+
+	if base.Name != "" {
+		b, err := definitionLines(base)
+		if err != nil {
+			return nil, err
+		}
+		a = b
+	}
+
+	b, err := definitionLines(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffLines(a, b), nil
+}
+
+// definitionLines returns the lines of the bundle's YAML, omitting the fields
+// that describe its installation rather than its definition.
+func definitionLines(b data.Bundle) ([]string, error) {
+	b.Enabled = false
+	b.InstalledBy = ""
+
+	out, err := yaml.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(strings.TrimRight(string(out), "\n"), "\n"), nil
+}
+
+// diffLines computes a minimal line diff of a and b from their longest
+// common subsequence.
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diff := make([]string, 0, len(a)+len(b))
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, "-"+a[i])
+			i++
+		default:
+			diff = append(diff, "+"+b[j])
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		diff = append(diff, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, "+"+b[j])
+	}
+
+	return diff
+}
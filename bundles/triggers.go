@@ -0,0 +1,263 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundles
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+// WarnTriggerCollision is the code of the warnings returned by
+// TriggerCollisions.
+const WarnTriggerCollision = "trigger-collision"
+
+// maxTriggerExamples caps the number of example messages generated for a
+// single trigger, since alternations multiply them.
+const maxTriggerExamples = 32
+
+// TriggerCollisions compares the triggers of bundle with those of the
+// commands in others, and returns a warning for each pair that can match the
+// same message in the same channel. Other versions of bundle in others are
+// ignored, since only one version of a bundle can be enabled. The warning's
+// severity is "info" if precedence strategies are configured, since they may
+// settle the collision, and "warning" otherwise.
+func TriggerCollisions(bundle data.Bundle, others []data.Bundle, tc data.TriggerConfigs) []rest.BundleWarning {
+	severity := data.SeverityWarning
+	if len(tc.Precedence) > 0 {
+		severity = data.SeverityInfo
+	}
+
+	var warnings []rest.BundleWarning
+
+	for _, name := range commandNames(bundle) {
+		for _, t := range bundle.Commands[name].Triggers {
+			for _, other := range others {
+				if other.Name == bundle.Name {
+					continue
+				}
+
+				for _, oname := range commandNames(other) {
+					for _, ot := range other.Commands[oname].Triggers {
+						if !channelsOverlap(t, ot) || !TriggersOverlap(t.Match, ot.Match) {
+							continue
+						}
+
+						warnings = append(warnings, rest.BundleWarning{
+							Severity: severity,
+							Code:     WarnTriggerCollision,
+							Command:  name,
+							Message: fmt.Sprintf("trigger %q overlaps trigger %q of %s:%s",
+								t.Match, ot.Match, other.Name, oname),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+// TriggersOverlap returns true if there's a message that's matched by both
+// triggers. It works by generating example messages that each trigger
+// matches and testing them against the other, so it never reports a false
+// overlap but may miss some. Invalid patterns never overlap.
+func TriggersOverlap(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+
+	ra, err := regexp.Compile(a)
+	if err != nil {
+		return false
+	}
+	rb, err := regexp.Compile(b)
+	if err != nil {
+		return false
+	}
+
+	for _, ex := range triggerExamples(a) {
+		if ra.MatchString(ex) && rb.MatchString(ex) {
+			return true
+		}
+	}
+	for _, ex := range triggerExamples(b) {
+		if ra.MatchString(ex) && rb.MatchString(ex) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// channelsOverlap returns true if there may be a channel that both triggers
+// are allowed in. Channels can be given by ID or by name, so triggers that
+// are both restricted are only known to be disjoint if they're given the
+// same way.
+func channelsOverlap(a, b data.Trigger) bool {
+	if len(a.Channels) == 0 || len(b.Channels) == 0 {
+		return true
+	}
+
+	for _, c := range a.Channels {
+		if b.AllowsChannel(c, c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveTriggerPrecedence narrows entries, the commands whose triggers
+// matched message, by applying the configured precedence strategies in
+// order. It returns the remaining candidates, which are ambiguous if there's
+// more than one.
+func ResolveTriggerPrecedence(entries []data.CommandEntry, message string, tc data.TriggerConfigs) ([]data.CommandEntry, error) {
+	for _, strategy := range tc.Precedence {
+		if len(entries) <= 1 {
+			break
+		}
+
+		scores := make([]int, len(entries))
+		for i, e := range entries {
+			switch strategy {
+			case data.TriggerPrecedencePriority:
+				scores[i] = tc.BundlePriority[e.Bundle.Name]
+			case data.TriggerPrecedenceLongestMatch:
+				n, err := e.Command.TriggerMatchLength(message)
+				if err != nil {
+					return nil, err
+				}
+				scores[i] = n
+			default:
+				return nil, fmt.Errorf("unknown precedence strategy: %q", strategy)
+			}
+		}
+
+		best := scores[0]
+		for _, s := range scores[1:] {
+			if s > best {
+				best = s
+			}
+		}
+
+		var kept []data.CommandEntry
+		for i, e := range entries {
+			if scores[i] == best {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+
+	return entries, nil
+}
+
+func commandNames(bundle data.Bundle) []string {
+	names := make([]string, 0, len(bundle.Commands))
+	for name, cmd := range bundle.Commands {
+		if cmd != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// triggerExamples returns a sample of the strings matched by pattern.
+func triggerExamples(pattern string) []string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return examples(re.Simplify())
+}
+
+func examples(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return nil
+
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return nil
+		}
+		return []string{string(re.Rune[0])}
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return []string{"x"}
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return examples(re.Sub[0])
+
+	case syntax.OpStar, syntax.OpQuest:
+		return limitExamples(append([]string{""}, examples(re.Sub[0])...))
+
+	case syntax.OpRepeat:
+		var out []string
+		if re.Min == 0 {
+			out = append(out, "")
+		}
+		for _, ex := range examples(re.Sub[0]) {
+			out = append(out, strings.Repeat(ex, re.Min))
+		}
+		return limitExamples(out)
+
+	case syntax.OpConcat:
+		out := []string{""}
+		for _, sub := range re.Sub {
+			var next []string
+			for _, prefix := range out {
+				for _, ex := range examples(sub) {
+					next = append(next, prefix+ex)
+				}
+			}
+			out = limitExamples(next)
+		}
+		return out
+
+	case syntax.OpAlternate:
+		var out []string
+		for _, sub := range re.Sub {
+			out = append(out, examples(sub)...)
+		}
+		return limitExamples(out)
+
+	default:
+		// Empty matches, anchors, and word boundaries consume nothing.
+		return []string{""}
+	}
+}
+
+func limitExamples(ss []string) []string {
+	if len(ss) > maxTriggerExamples {
+		return ss[:maxTriggerExamples]
+	}
+	return ss
+}
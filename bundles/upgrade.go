@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundles
+
+import (
+	"sort"
+
+	"github.com/getgort/gort/data"
+)
+
+// SortVersions sorts a slice of versions of a bundle in place, newest
+// first, according to their semantic versions.
+func SortVersions(versions []data.Bundle) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[j].Semver().LessThan(versions[i].Semver())
+	})
+}
+
+// NewerVersionInstalled returns the newest of the installed versions that
+// is strictly newer than version, if there is one.
+func NewerVersionInstalled(installed []data.Bundle, version string) (string, bool) {
+	target := data.Bundle{Version: version}.Semver()
+
+	var newest *data.Bundle
+	for i, b := range installed {
+		if !target.LessThan(b.Semver()) {
+			continue
+		}
+		if newest == nil || newest.Semver().LessThan(b.Semver()) {
+			newest = &installed[i]
+		}
+	}
+
+	if newest == nil {
+		return "", false
+	}
+
+	return newest.Version, true
+}
+
+// PruneCandidates returns the versions from installed that fall outside
+// the retain newest versions and may therefore be uninstalled. The enabled
+// version is never a candidate. If retain is less than 1 nothing is pruned.
+func PruneCandidates(installed []data.Bundle, enabled string, retain int) []string {
+	if retain < 1 || len(installed) <= retain {
+		return nil
+	}
+
+	sorted := make([]data.Bundle, len(installed))
+	copy(sorted, installed)
+	SortVersions(sorted)
+
+	var prune []string
+	for _, b := range sorted[retain:] {
+		if b.Version != enabled {
+			prune = append(prune, b.Version)
+		}
+	}
+
+	return prune
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	aliasDeleteUse   = "delete"
+	aliasDeleteShort = "Delete an alias"
+	aliasDeleteLong  = "Delete an alias."
+	aliasDeleteUsage = `Usage:
+  gort alias delete [-G | -u user] [flags] alias_name
+
+Flags:
+  -G, --global        Delete the global alias
+  -h, --help          Show this message and exit
+  -u, --user string   Delete the alias of this user (default: yourself)
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortAliasDeleteGlobal bool
+	flagGortAliasDeleteUser   string
+)
+
+// GetAliasDeleteCmd is a command
+func GetAliasDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   aliasDeleteUse,
+		Short: aliasDeleteShort,
+		Long:  aliasDeleteLong,
+		RunE:  aliasDeleteCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.SetUsageTemplate(aliasDeleteUsage)
+
+	cmd.Flags().BoolVarP(&flagGortAliasDeleteGlobal, "global", "G", false, "Delete the global alias")
+	cmd.Flags().StringVarP(&flagGortAliasDeleteUser, "user", "u", "", "Delete the alias of this user")
+
+	return cmd
+}
+
+func aliasDeleteCmd(cmd *cobra.Command, args []string) error {
+	alias, err := newAlias(args[0], flagGortAliasDeleteGlobal, flagGortAliasDeleteUser)
+	if err != nil {
+		return err
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	err = gortClient.AliasDelete(alias)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Alias deleted: %s\n", describeAlias(alias))
+
+	return nil
+}
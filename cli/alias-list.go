@@ -0,0 +1,86 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	aliasListUse   = "list"
+	aliasListShort = "List the aliases available to you"
+	aliasListLong  = `List the aliases available to you: your own, and global ones. Users with
+the manage_aliases permission see every alias.
+`
+	aliasListUsage = `Usage:
+  gort alias list [flags]
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetAliasListCmd is a command
+func GetAliasListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   aliasListUse,
+		Short: aliasListShort,
+		Long:  aliasListLong,
+		RunE:  aliasListCmd,
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.SetUsageTemplate(aliasListUsage)
+
+	return cmd
+}
+
+func aliasListCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	as, err := gortClient.AliasList()
+	if err != nil {
+		return err
+	}
+
+	out := make([]aliasOutput, len(as))
+	for i, a := range as {
+		out[i] = newAliasOutput(a)
+	}
+
+	return printOutput(out, func() {
+		c := &Columnizer{}
+		c.StringColumn("NAME", func(i int) string { return as[i].Name })
+		c.StringColumn("OWNER", func(i int) string {
+			if as[i].Owner == "" {
+				return "(global)"
+			}
+			return as[i].Owner
+		})
+		c.StringColumn("COMMAND", func(i int) string { return as[i].Command })
+
+		c.Print(as)
+	})
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	aliasSaveUse   = "save"
+	aliasSaveShort = "Create or replace an alias"
+	aliasSaveLong  = `Create or replace an alias.
+
+When a command begins with the alias's name, the name is replaced by the
+alias's command; any remaining arguments are appended to it.
+`
+	aliasSaveUsage = `Usage:
+  gort alias save [-G | -u user] [flags] alias_name command
+
+Flags:
+  -G, --global        Make the alias available to everybody
+  -h, --help          Show this message and exit
+  -u, --user string   Save the alias for this user (default: yourself)
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortAliasSaveGlobal bool
+	flagGortAliasSaveUser   string
+)
+
+// GetAliasSaveCmd is a command
+func GetAliasSaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   aliasSaveUse,
+		Short: aliasSaveShort,
+		Long:  aliasSaveLong,
+		RunE:  aliasSaveCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.SetUsageTemplate(aliasSaveUsage)
+
+	cmd.Flags().BoolVarP(&flagGortAliasSaveGlobal, "global", "G", false, "Make the alias available to everybody")
+	cmd.Flags().StringVarP(&flagGortAliasSaveUser, "user", "u", "", "Save the alias for this user")
+
+	return cmd
+}
+
+func aliasSaveCmd(cmd *cobra.Command, args []string) error {
+	alias, err := newAlias(args[0], flagGortAliasSaveGlobal, flagGortAliasSaveUser)
+	if err != nil {
+		return err
+	}
+	alias.Command = args[1]
+
+	if err := alias.Validate(); err != nil {
+		return err
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	err = gortClient.AliasSave(alias)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Alias saved: %s\n", describeAlias(alias))
+
+	return nil
+}
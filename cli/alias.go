@@ -0,0 +1,84 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/data"
+	"github.com/spf13/cobra"
+)
+
+const (
+	aliasUse   = "alias"
+	aliasShort = "Save, list, or delete command aliases"
+	aliasLong  = `Save, list, or delete command aliases.
+
+An alias is a short name for a longer command. When a command begins with an
+alias, the alias is replaced by its command and any remaining arguments are
+appended to it. For example, an alias saved as
+
+  gort alias save dep "deploy:status prod"
+
+can be invoked in chat as "!dep", or as "!dep --verbose". The expanded
+command is subject to the same rules as if it had been typed directly.
+
+Aliases belong to a user or, with --global, are available to everybody. A
+user's own aliases take precedence over global ones.
+`
+)
+
+// GetAliasCmd alias
+func GetAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   aliasUse,
+		Short: aliasShort,
+		Long:  aliasLong,
+	}
+
+	cmd.AddCommand(GetAliasDeleteCmd())
+	cmd.AddCommand(GetAliasListCmd())
+	cmd.AddCommand(GetAliasSaveCmd())
+
+	return cmd
+}
+
+// newAlias builds an alias value from a name and the --global and --user
+// flags. If neither flag is set the alias belongs to the requesting user.
+func newAlias(name string, global bool, user string) (data.Alias, error) {
+	switch {
+	case global && user != "":
+		return data.Alias{}, fmt.Errorf("only one of --global or --user may be set")
+	case global:
+		return data.Alias{Name: name}, nil
+	case user == "":
+		return data.Alias{Name: name, Owner: "-"}, nil
+	default:
+		return data.Alias{Name: name, Owner: user}, nil
+	}
+}
+
+func describeAlias(a data.Alias) string {
+	switch a.Owner {
+	case "":
+		return fmt.Sprintf("name=%q global", a.Name)
+	case "-":
+		return fmt.Sprintf("name=%q", a.Name)
+	default:
+		return fmt.Sprintf("name=%q owner=%q", a.Name, a.Owner)
+	}
+}
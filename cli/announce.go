@@ -0,0 +1,119 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data/rest"
+	"github.com/spf13/cobra"
+)
+
+const (
+	announceUse   = "announce"
+	announceShort = "Broadcast a message to chat channels"
+	announceLong  = `Broadcast a message to chat channels.
+
+Sends a message to every channel Gort is present in (with --all-channels), or
+to only the listed channels. Either may be limited to specific adapters.
+Messages are sent at a limited rate to stay within chat provider limits, so
+announcing to many channels can take some time.`
+	announceUsage = `Usage:
+  gort announce [flags] message
+
+Flags:
+  -a, --adapter strings   Only announce through these adapters (can be repeated)
+      --all-channels      Announce to every channel Gort is present in
+  -c, --channel strings   Announce to these channel names or IDs (can be repeated)
+  -h, --help              Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortAnnounceAdapters    []string
+	flagGortAnnounceAllChannels bool
+	flagGortAnnounceChannels    []string
+)
+
+// GetAnnounceCmd is a command
+func GetAnnounceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   announceUse,
+		Short: announceShort,
+		Long:  announceLong,
+		RunE:  announceCmd,
+		Args:  cobra.MinimumNArgs(1),
+	}
+
+	cmd.Flags().StringSliceVarP(&flagGortAnnounceAdapters, "adapter", "a", nil, "Only announce through these adapters")
+	cmd.Flags().BoolVar(&flagGortAnnounceAllChannels, "all-channels", false, "Announce to every channel Gort is present in")
+	cmd.Flags().StringSliceVarP(&flagGortAnnounceChannels, "channel", "c", nil, "Announce to these channel names or IDs")
+
+	cmd.SetUsageTemplate(announceUsage)
+
+	return cmd
+}
+
+func announceCmd(cmd *cobra.Command, args []string) error {
+	switch {
+	case flagGortAnnounceAllChannels && len(flagGortAnnounceChannels) > 0:
+		return fmt.Errorf("--all-channels and --channel flags are mutually exclusive")
+	case !flagGortAnnounceAllChannels && len(flagGortAnnounceChannels) == 0:
+		return fmt.Errorf("one of --all-channels or --channel is required")
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	result, err := gortClient.Announce(rest.Announcement{
+		Message:     strings.Join(args, " "),
+		AllChannels: flagGortAnnounceAllChannels,
+		Adapters:    flagGortAnnounceAdapters,
+		Channels:    flagGortAnnounceChannels,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printOutput(result, func() {
+		fmt.Printf("Announcement sent to %d channel(s).\n", len(result.Sent))
+
+		if len(result.Failed) > 0 {
+			fmt.Printf("Failed to send to %d target(s):\n\n", len(result.Failed))
+
+			failed := result.Failed
+			c := &Columnizer{}
+			c.StringColumn("ADAPTER", func(i int) string { return failed[i].Adapter })
+			c.StringColumn("CHANNEL", func(i int) string {
+				if failed[i].ChannelName != "" {
+					return failed[i].ChannelName
+				}
+				return failed[i].ChannelID
+			})
+			c.StringColumn("ERROR", func(i int) string { return failed[i].Error })
+			c.Print(failed)
+		}
+	})
+}
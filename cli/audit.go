@@ -0,0 +1,131 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data"
+	"github.com/spf13/cobra"
+)
+
+const (
+	auditUse   = "audit"
+	auditShort = "List changes made through the REST API"
+	auditLong  = `List changes made through the REST API to users, groups, roles, bundles,
+and other settings, newest first.
+
+Each change records the user that made it, the address it came from, and
+the response status. Use "--output json" to also see snapshots of the
+changed entity from immediately before and after the change.`
+	auditUsage = `Usage:
+  gort audit [flags]
+
+Flags:
+  -h, --help            Show this message and exit
+  -k, --kind string     Only list changes to this kind of entity (user, group, role, bundle, ...)
+  -n, --limit int       The maximum number of changes to list (default 50)
+      --since string    Only list changes made at or after this RFC 3339 time
+  -t, --target string   Only list changes to the entity with this name
+      --until string    Only list changes made before this RFC 3339 time
+  -u, --user string     Only list changes made by this user
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortAuditKind   string
+	flagGortAuditLimit  int
+	flagGortAuditSince  string
+	flagGortAuditTarget string
+	flagGortAuditUntil  string
+	flagGortAuditUser   string
+)
+
+// GetAuditCmd is a command
+func GetAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   auditUse,
+		Short: auditShort,
+		Long:  auditLong,
+		RunE:  auditCmd,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&flagGortAuditKind, "kind", "k", "", "Only list changes to this kind of entity")
+	cmd.Flags().IntVarP(&flagGortAuditLimit, "limit", "n", 50, "The maximum number of changes to list")
+	cmd.Flags().StringVar(&flagGortAuditSince, "since", "", "Only list changes made at or after this RFC 3339 time")
+	cmd.Flags().StringVarP(&flagGortAuditTarget, "target", "t", "", "Only list changes to the entity with this name")
+	cmd.Flags().StringVar(&flagGortAuditUntil, "until", "", "Only list changes made before this RFC 3339 time")
+	cmd.Flags().StringVarP(&flagGortAuditUser, "user", "u", "", "Only list changes made by this user")
+
+	cmd.SetUsageTemplate(auditUsage)
+
+	return cmd
+}
+
+func auditCmd(cmd *cobra.Command, args []string) error {
+	filter := data.AuditFilter{
+		User:   flagGortAuditUser,
+		Kind:   flagGortAuditKind,
+		Target: flagGortAuditTarget,
+		Limit:  flagGortAuditLimit,
+	}
+
+	var err error
+
+	if flagGortAuditSince != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, flagGortAuditSince); err != nil {
+			return fmt.Errorf("invalid --since time: %w", err)
+		}
+	}
+
+	if flagGortAuditUntil != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, flagGortAuditUntil); err != nil {
+			return fmt.Errorf("invalid --until time: %w", err)
+		}
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	records, err := gortClient.AuditList(filter)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(records, func() {
+		c := &Columnizer{}
+		c.StringColumn("ID", func(i int) string { return strconv.FormatInt(records[i].ID, 10) })
+		c.StringColumn("TIME", func(i int) string { return records[i].Timestamp.Local().Format(time.Stamp) })
+		c.StringColumn("USER", func(i int) string { return records[i].User })
+		c.StringColumn("SOURCE", func(i int) string { return records[i].SourceIP })
+		c.StringColumn("KIND", func(i int) string { return records[i].Kind })
+		c.StringColumn("TARGET", func(i int) string { return records[i].Target })
+		c.StringColumn("REQUEST", func(i int) string { return records[i].Method + " " + records[i].Path })
+		c.StringColumn("STATUS", func(i int) string { return strconv.Itoa(records[i].Status) })
+		c.Print(records)
+	})
+}
@@ -48,6 +48,7 @@ Flags:
   -h, --help              help for bootstrap
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getgort/gort/client"
+)
+
+const (
+	bundleApproveUse   = "approve"
+	bundleApproveShort = "Approve a bundle version that's pending review"
+	bundleApproveLong  = `Approve a bundle version that's pending review, allowing it to be enabled.
+
+A bundle version can't be approved by the user that installed it. Use
+"gort bundle review" to see its changes first.`
+	bundleApproveUsage = `Usage:
+  gort bundle approve [flags] bundle_name version
+
+Flags:
+  -h, --help             Show this message and exit
+  -m, --message string   A comment to record with the approval
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagBundleReviewMessage string
+)
+
+// GetBundleApproveCmd is a command
+func GetBundleApproveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   bundleApproveUse,
+		Short: bundleApproveShort,
+		Long:  bundleApproveLong,
+		RunE:  bundleApproveCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.Flags().StringVarP(&flagBundleReviewMessage, "message", "m", "", "A comment to record with the approval")
+
+	cmd.SetUsageTemplate(bundleApproveUsage)
+
+	return cmd
+}
+
+func bundleApproveCmd(cmd *cobra.Command, args []string) error {
+	return bundleReviewDecide(args[0], args[1], true)
+}
+
+func bundleReviewDecide(bundleName, bundleVersion string, approve bool) error {
+	c, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	err = c.BundleReviewDecide(bundleName, bundleVersion, approve, flagBundleReviewMessage)
+	if err != nil {
+		return err
+	}
+
+	decision := "rejected"
+	if approve {
+		decision = "approved"
+	}
+
+	fmt.Printf("Bundle \"%s\" version %s %s.\n", bundleName, bundleVersion, decision)
+
+	return nil
+}
@@ -17,9 +17,12 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data"
 
 	"github.com/spf13/cobra"
 )
@@ -30,7 +33,8 @@ const (
 	bundleEnableLong  = `Enable the specified version of the bundle.
 
 If no version is given, the latest installed version (by standard semantic
-version ordering) will be enabled.
+version ordering) will be enabled. In chat, if more than one version is
+installed, a version picker is shown instead.
 
 If any version of this bundle is currently enabled, it will be disabled in
 the process.`
@@ -41,6 +45,7 @@ Flags:
   --help  Show this message and exit.
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -72,31 +77,69 @@ func bundleEnableCmd(cmd *cobra.Command, args []string) error {
 	if len(args) > 1 {
 		bundleVersion = args[1]
 	} else {
-		bundleVersion, err = findLatestVersion(c, bundleName)
+		bb, err := c.BundleListVersions(bundleName)
 		if err != nil {
 			return err
 		}
+
+		// In chat, let the user choose rather than guessing.
+		if len(bb) > 1 && runningInChat() {
+			return printBundlePicker(bundleName, bb)
+		}
+
+		bundleVersion = latestVersion(bb)
 	}
 
-	err = c.BundleEnable(bundleName, bundleVersion)
+	warnings, err := c.BundleEnable(bundleName, bundleVersion)
 	if err != nil {
 		return err
 	}
 
+	printBundleWarnings(warnings)
+
 	fmt.Printf("Bundle \"%s\" version %s enabled.\n", bundleName, bundleVersion)
 
 	return nil
 }
 
-func findLatestVersion(c *client.GortClient, bundleName string) (string, error) {
-	bb, err := c.BundleListVersions(bundleName)
-	if err != nil {
-		return "", err
+func latestVersion(bb []data.Bundle) string {
+	if len(bb) == 0 {
+		return ""
 	}
 
-	if len(bb) == 0 {
-		return "", nil
+	return bb[len(bb)-1].Version
+}
+
+// bundlePickerOutput is printed instead of enabling a bundle when "bundle
+// enable" is run from chat without a version and more than one version is
+// installed. The gort:bundle command template renders it as a button for
+// each version, which runs "bundle enable" with that version when clicked.
+type bundlePickerOutput struct {
+	Picker bundleOutput `json:"picker"`
+}
+
+func printBundlePicker(bundleName string, bb []data.Bundle) error {
+	picker := bundleOutput{Name: bundleName, Versions: make([]string, len(bb))}
+	for i, b := range bb {
+		picker.Versions[i] = b.Version
+		if b.Enabled {
+			picker.Enabled = true
+			picker.EnabledVersion = b.Version
+		}
+	}
+
+	b, err := json.Marshal(bundlePickerOutput{Picker: picker})
+	if err != nil {
+		return err
 	}
 
-	return bb[len(bb)-1].Version, nil
+	fmt.Println(string(b))
+
+	return nil
+}
+
+// runningInChat returns true if this command was run from chat, in which case
+// the worker sets GORT_ADAPTER to the name of the originating chat adapter.
+func runningInChat() bool {
+	return os.Getenv("GORT_ADAPTER") != ""
 }
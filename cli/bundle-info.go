@@ -45,6 +45,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -97,28 +98,30 @@ func doBundleInfoAll(name string) error {
 		}
 	}
 
-	fmt.Printf("Name: %s\n", name)
-	fmt.Printf("Versions: %s\n", strings.Join(versions, ", "))
+	out := bundleOutput{Name: name, Versions: versions}
 
 	if enabled.Version != "" {
-		fmt.Println("Status: Enabled")
-		fmt.Printf("Enabled Version: %s\n", enabled.Version)
-
-		var commands []string
-		for name := range enabled.Commands {
-			commands = append(commands, name)
-		}
-
-		sort.Strings(commands)
-		fmt.Printf("Commands: %s\n", strings.Join(commands, ", "))
+		out.Enabled = true
+		out.EnabledVersion = enabled.Version
+		out.Commands = bundleCommandNames(enabled)
 
 		sort.Strings(enabled.Permissions)
-		fmt.Printf("Permissions: %s\n", strings.Join(enabled.Permissions, ", "))
-	} else {
-		fmt.Println("Status: Disabled")
+		out.Permissions = enabled.Permissions
 	}
 
-	return nil
+	return printOutput(out, func() {
+		fmt.Printf("Name: %s\n", out.Name)
+		fmt.Printf("Versions: %s\n", strings.Join(out.Versions, ", "))
+
+		if out.Enabled {
+			fmt.Println("Status: Enabled")
+			fmt.Printf("Enabled Version: %s\n", out.EnabledVersion)
+			fmt.Printf("Commands: %s\n", strings.Join(out.Commands, ", "))
+			fmt.Printf("Permissions: %s\n", strings.Join(out.Permissions, ", "))
+		} else {
+			fmt.Println("Status: Disabled")
+		}
+	})
 }
 
 func doBundleInfoVersion(name, version string) error {
@@ -132,22 +135,42 @@ func doBundleInfoVersion(name, version string) error {
 		return err
 	}
 
-	fmt.Printf("Name: %s\n", bundle.Name)
-	fmt.Printf("Version: %s\n", bundle.Version)
-
-	if bundle.Enabled {
-		fmt.Println("Status: Enabled")
-	} else {
-		fmt.Println("Status: Not Enabled")
+	out := bundleOutput{
+		Name:        bundle.Name,
+		Version:     bundle.Version,
+		Enabled:     bundle.Enabled,
+		Platform:    bundle.Platform.String(),
+		Commands:    bundleCommandNames(bundle),
+		Permissions: bundle.Permissions,
 	}
 
+	return printOutput(out, func() {
+		fmt.Printf("Name: %s\n", out.Name)
+		fmt.Printf("Version: %s\n", out.Version)
+
+		if out.Platform != "" {
+			fmt.Printf("Platform: %s\n", out.Platform)
+		}
+
+		if out.Enabled {
+			fmt.Println("Status: Enabled")
+		} else {
+			fmt.Println("Status: Not Enabled")
+		}
+
+		fmt.Printf("Commands: %s\n", strings.Join(out.Commands, ", "))
+		fmt.Printf("Permissions: %s\n", strings.Join(out.Permissions, ", "))
+	})
+}
+
+// bundleCommandNames returns the sorted names of the bundle's commands.
+func bundleCommandNames(bundle data.Bundle) []string {
 	commands := make([]string, 0)
 	for name := range bundle.Commands {
 		commands = append(commands, name)
 	}
 
-	fmt.Printf("Commands: %s\n", strings.Join(commands, ", "))
-	fmt.Printf("Permissions: %s\n", strings.Join(bundle.Permissions, ", "))
+	sort.Strings(commands)
 
-	return nil
+	return commands
 }
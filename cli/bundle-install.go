@@ -24,6 +24,7 @@ import (
 	"github.com/getgort/gort/bundles"
 	"github.com/getgort/gort/client"
 	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
 	"github.com/go-git/go-git/v5"
 	"github.com/spf13/cobra"
 )
@@ -98,6 +99,20 @@ the subdirectory where the bundle can be found:
   gort bundle install git@example.com/repo.git//bundles/example
 
 If a bundle file is not specified, it will default to "bundle.yml".
+
+Use --upgrade to install a newer version of an already-installed bundle.
+Upgrading is idempotent: if the version is already installed nothing is
+reinstalled, and installing a version older than one that's already installed
+is an error. When combined with --keep, all but the given number of newest
+versions are uninstalled as part of the same operation; the enabled version
+is never uninstalled.
+
+  gort bundle install --upgrade --enable --keep 3 /path/to/bundle.yml
+
+The server analyzes each bundle that it installs and reports any risky
+characteristics, such as rules that allow every user or images that use the
+"latest" tag, as warnings. Depending on the server's policy, a sufficiently
+severe warning can prevent the bundle from being installed.
 `
 	bundleInstallUsage = `Usage:
   gort bundle install [flags] config_path
@@ -111,15 +126,24 @@ Flags:
 						installed from a file, and not from the Warehouse
 						bundle registry. Use this to shorten iteration
 						cycles in bundle development.  [default: False]
+  -k, --keep int		With --upgrade, uninstall all but this many of the
+						newest versions. Zero keeps all versions.
+						[default: 0]
+  -u, --upgrade			Install a newer version of the bundle, leaving the
+						installation unchanged if the version is already
+						installed.  [default: False]
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
 
 var (
-	flagBundleInstallEnable bool
-	flagBundleInstallForce  bool
+	flagBundleInstallEnable  bool
+	flagBundleInstallForce   bool
+	flagBundleInstallKeep    int
+	flagBundleInstallUpgrade bool
 )
 
 // GetBundleInstallCmd is a command
@@ -135,6 +159,8 @@ func GetBundleInstallCmd() *cobra.Command {
 	cmd.SetUsageTemplate(bundleInstallUsage)
 	cmd.Flags().BoolVarP(&flagBundleInstallEnable, "enable", "e", false, "Automatically enable a bundle after installing")
 	cmd.Flags().BoolVarP(&flagBundleInstallForce, "force", "f", false, "Install even if a bundle with the same version is already installed.")
+	cmd.Flags().IntVarP(&flagBundleInstallKeep, "keep", "k", 0, "With --upgrade, the number of newest versions to keep installed")
+	cmd.Flags().BoolVarP(&flagBundleInstallUpgrade, "upgrade", "u", false, "Install a newer version of an installed bundle")
 
 	return cmd
 }
@@ -142,6 +168,16 @@ func GetBundleInstallCmd() *cobra.Command {
 func bundleInstallCmd(cmd *cobra.Command, args []string) error {
 	bundlefile := args[0]
 
+	if flagBundleInstallUpgrade && flagBundleInstallForce {
+		return fmt.Errorf("--upgrade and --force can't be used together")
+	}
+	if flagBundleInstallKeep < 0 {
+		return fmt.Errorf("--keep must not be negative")
+	}
+	if flagBundleInstallKeep > 0 && !flagBundleInstallUpgrade {
+		return fmt.Errorf("--keep can only be used with --upgrade")
+	}
+
 	c, err := client.Connect(FlagGortProfile)
 	if err != nil {
 		return err
@@ -182,6 +218,10 @@ func bundleInstallCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if flagBundleInstallUpgrade {
+		return bundleUpgrade(c, bundle)
+	}
+
 	// Check for existing instances of this bundle, allowing forced replacement.
 	exists, err := c.BundleVersionExists(bundle.Name, bundle.Version)
 	if err != nil {
@@ -197,16 +237,20 @@ func bundleInstallCmd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	err = c.BundleInstall(bundle)
+	result, err := c.BundleInstall(bundle)
 	if err != nil {
 		return err
 	}
 
+	printBundleWarnings(result.Warnings)
+
 	if flagBundleInstallEnable {
-		err = c.BundleEnable(bundle.Name, bundle.Version)
+		warnings, err := c.BundleEnable(bundle.Name, bundle.Version)
 		if err != nil {
 			return err
 		}
+
+		printBundleWarnings(warnings)
 	}
 
 	fmt.Printf("Bundle %q installed.\n", bundle.Name)
@@ -214,6 +258,49 @@ func bundleInstallCmd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// bundleUpgrade performs an upgrade install of bundle and reports what was
+// changed.
+func bundleUpgrade(c *client.GortClient, bundle data.Bundle) error {
+	result, err := c.BundleUpgrade(bundle, flagBundleInstallEnable, flagBundleInstallKeep)
+	if err != nil {
+		return err
+	}
+
+	printBundleWarnings(result.Warnings)
+
+	if result.Installed {
+		fmt.Printf("Bundle %q upgraded to version %s.\n", result.Name, result.Version)
+	} else {
+		fmt.Printf("Bundle %q version %s is already installed.\n", result.Name, result.Version)
+	}
+
+	if result.Enabled {
+		if result.PreviousEnabled != "" && result.PreviousEnabled != result.Version {
+			fmt.Printf("Version %s is enabled (was %s).\n", result.Version, result.PreviousEnabled)
+		} else {
+			fmt.Printf("Version %s is enabled.\n", result.Version)
+		}
+	}
+
+	if len(result.Pruned) > 0 {
+		fmt.Printf("Uninstalled older versions: %s\n", strings.Join(result.Pruned, ", "))
+	}
+
+	return nil
+}
+
+// printBundleWarnings prints the warnings found by the server's analysis of
+// an installed bundle, if there are any.
+func printBundleWarnings(warnings []rest.BundleWarning) {
+	for _, w := range warnings {
+		if w.Command != "" {
+			fmt.Printf("%s: %s: %s (%s)\n", strings.ToUpper(w.Severity), w.Command, w.Message, w.Code)
+		} else {
+			fmt.Printf("%s: %s (%s)\n", strings.ToUpper(w.Severity), w.Message, w.Code)
+		}
+	}
+}
+
 func loadBundleFile(bundlefile string) (data.Bundle, error) {
 	file, err := os.Open(bundlefile)
 	if err != nil {
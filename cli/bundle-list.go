@@ -54,6 +54,7 @@ Flags:
   -v, --verbose    Display additional bundle details
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -117,25 +118,35 @@ func bundleListCmd(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	c := &Columnizer{}
-	c.StringColumn("BUNDLE", func(i int) string { return metadata[i].name })
-	c.StringColumn("ENABLED", func(i int) string {
-		version := metadata[i].enabledVersion
-		if version == "" {
-			version = "-"
+	out := make([]bundleOutput, len(metadata))
+	for i, m := range metadata {
+		out[i] = bundleOutput{
+			Name:           m.name,
+			Versions:       m.versions,
+			Enabled:        m.enabled,
+			EnabledVersion: m.enabledVersion,
 		}
-		return version
-	})
+	}
 
-	if flagBundleListVerbose {
-		c.StringColumn("INSTALLED VERSIONS", func(i int) string {
-			return strings.Join(metadata[i].versions, ", ")
+	return printOutput(out, func() {
+		c := &Columnizer{}
+		c.StringColumn("BUNDLE", func(i int) string { return metadata[i].name })
+		c.StringColumn("ENABLED", func(i int) string {
+			version := metadata[i].enabledVersion
+			if version == "" {
+				version = "-"
+			}
+			return version
 		})
-	}
 
-	c.Print(metadata)
+		if flagBundleListVerbose {
+			c.StringColumn("INSTALLED VERSIONS", func(i int) string {
+				return strings.Join(metadata[i].versions, ", ")
+			})
+		}
 
-	return nil
+		c.Print(metadata)
+	})
 }
 
 func getBundleData(bundles []data.Bundle) []bundleData {
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	bundleRejectUse   = "reject"
+	bundleRejectShort = "Reject a bundle version that's pending review"
+	bundleRejectLong  = `Reject a bundle version that's pending review. A rejected version can't be
+enabled.
+
+A bundle version can't be rejected by the user that installed it.`
+	bundleRejectUsage = `Usage:
+  gort bundle reject [flags] bundle_name version
+
+Flags:
+  -h, --help             Show this message and exit
+  -m, --message string   A comment to record with the rejection
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetBundleRejectCmd is a command
+func GetBundleRejectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   bundleRejectUse,
+		Short: bundleRejectShort,
+		Long:  bundleRejectLong,
+		RunE:  bundleRejectCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.Flags().StringVarP(&flagBundleReviewMessage, "message", "m", "", "A comment to record with the rejection")
+
+	cmd.SetUsageTemplate(bundleRejectUsage)
+
+	return cmd
+}
+
+func bundleRejectCmd(cmd *cobra.Command, args []string) error {
+	return bundleReviewDecide(args[0], args[1], false)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getgort/gort/client"
+)
+
+const (
+	bundleReviewUse   = "review"
+	bundleReviewShort = "Show a bundle version's review status and changes"
+	bundleReviewLong  = `Show the review status of a bundle version, and how it differs from the
+currently enabled version of the bundle.
+
+Lines found only in the enabled version are prefixed with "-", and those
+found only in the reviewed version with "+". If no version is enabled,
+every line is new.`
+	bundleReviewUsage = `Usage:
+  gort bundle review [flags] bundle_name version
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetBundleReviewCmd is a command
+func GetBundleReviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   bundleReviewUse,
+		Short: bundleReviewShort,
+		Long:  bundleReviewLong,
+		RunE:  bundleReviewCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.SetUsageTemplate(bundleReviewUsage)
+
+	return cmd
+}
+
+func bundleReviewCmd(cmd *cobra.Command, args []string) error {
+	c, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	diff, err := c.BundleReview(args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	status := diff.Status
+	if status == "" {
+		status = "not subject to review"
+	}
+
+	fmt.Printf("Bundle:       %s %s\n", diff.Name, diff.Version)
+	fmt.Printf("Status:       %s\n", status)
+	fmt.Printf("Installed by: %s\n", diff.InstalledBy)
+	if diff.Reviewer != "" {
+		fmt.Printf("Reviewed by:  %s\n", diff.Reviewer)
+	}
+	if diff.Comment != "" {
+		fmt.Printf("Comment:      %s\n", diff.Comment)
+	}
+	fmt.Println()
+
+	base := "(none enabled)"
+	if diff.BaseVersion != "" {
+		base = fmt.Sprintf("%s %s", diff.Name, diff.BaseVersion)
+	}
+
+	fmt.Printf("--- %s\n+++ %s %s\n", base, diff.Name, diff.Version)
+	for _, line := range diff.Diff {
+		fmt.Println(line)
+	}
+
+	return nil
+}
@@ -38,23 +38,33 @@ import (
 const (
 	bundleUninstallUse   = "uninstall"
 	bundleUninstallShort = "Uninstall bundles"
-	bundleUninstallLong  = `Uninstall bundles.`
+	bundleUninstallLong  = `Uninstall bundles.
+
+Uninstalling a bundle doesn't revoke the permissions that roles were granted
+for it. Use --cascade to also revoke any role permissions that are no longer
+declared by an installed version of the bundle, and --dry-run to see what
+would be uninstalled and revoked without changing anything.`
 	bundleUninstallUsage = `Usage:
   gort bundle uninstall [flags] bundle_name [version]
 
 Flags:
-  -a, --all     Uninstall all versions of the bundle
-  -c, --clean   Uninstall all disabled bundle versions
-  -h, --help    help for uninstall
+  -a, --all       Uninstall all versions of the bundle
+      --cascade   Revoke role permissions orphaned by the uninstall
+  -c, --clean     Uninstall all disabled bundle versions
+  -n, --dry-run   Report what would be done without making changes
+  -h, --help      help for uninstall
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
 
 var (
-	flagBundleUninstallAll   bool
-	flagBundleUninstallClean bool
+	flagBundleUninstallAll     bool
+	flagBundleUninstallCascade bool
+	flagBundleUninstallClean   bool
+	flagBundleUninstallDryRun  bool
 )
 
 // GetBundleUninstallCmd is a command
@@ -68,7 +78,9 @@ func GetBundleUninstallCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&flagBundleUninstallAll, "all", "a", false, "Uninstall all versions of the bundle")
+	cmd.Flags().BoolVar(&flagBundleUninstallCascade, "cascade", false, "Revoke role permissions orphaned by the uninstall")
 	cmd.Flags().BoolVarP(&flagBundleUninstallClean, "clean", "c", false, "Uninstall all disabled bundle versions")
+	cmd.Flags().BoolVarP(&flagBundleUninstallDryRun, "dry-run", "n", false, "Report what would be done without making changes")
 
 	cmd.SetUsageTemplate(bundleUninstallUsage)
 
@@ -118,6 +130,10 @@ func bundleUninstallCmd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if flagBundleUninstallDryRun {
+		return bundleUninstallDryRun(c, bundleName, uninstall)
+	}
+
 	for _, b := range uninstall {
 		if err = c.BundleUninstall(b.Name, b.Version); err != nil {
 			return err
@@ -126,5 +142,54 @@ func bundleUninstallCmd(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Bundle %s %s uninstalled.\n", b.Name, b.Version)
 	}
 
+	if flagBundleUninstallCascade {
+		revoked, err := c.BundleOrphanDelete(bundleName)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range revoked {
+			fmt.Printf("Permission %s:%s revoked from role %s.\n", o.BundleName, o.Permission, o.Role)
+		}
+
+		return nil
+	}
+
+	orphans, err := c.BundleOrphanList(bundleName)
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) > 0 {
+		fmt.Printf("%d role permission(s) for bundle %s are no longer declared by any installed version.\n", len(orphans), bundleName)
+		fmt.Println("Use --cascade to revoke them.")
+	}
+
+	return nil
+}
+
+// bundleUninstallDryRun reports the bundle versions that would be uninstalled
+// and the role permissions that would be orphaned as a result.
+func bundleUninstallDryRun(c *client.GortClient, bundleName string, uninstall []data.Bundle) error {
+	versions := make([]string, len(uninstall))
+	for i, b := range uninstall {
+		versions[i] = b.Version
+		fmt.Printf("Bundle %s %s would be uninstalled.\n", b.Name, b.Version)
+	}
+
+	orphans, err := c.BundleOrphanList(bundleName, versions...)
+	if err != nil {
+		return err
+	}
+
+	verb := "would be orphaned"
+	if flagBundleUninstallCascade {
+		verb = "would be revoked"
+	}
+
+	for _, o := range orphans {
+		fmt.Printf("Permission %s:%s on role %s %s.\n", o.BundleName, o.Permission, o.Role, verb)
+	}
+
 	return nil
 }
@@ -70,22 +70,27 @@ func bundleVersionsCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Printf(format, "BUNDLE", "VERSION", "STATUS")
+	out := make([]bundleOutput, len(bundles))
+	for i, b := range bundles {
+		out[i] = bundleOutput{Name: b.Name, Version: b.Version, Enabled: b.Enabled}
+	}
 
-	for _, b := range bundles {
-		if b.Version == "" {
-			b.Version = "-"
-		}
+	return printOutput(out, func() {
+		fmt.Printf(format, "BUNDLE", "VERSION", "STATUS")
 
-		status := "Disabled"
-		if b.Enabled {
-			status = "Enabled"
-		}
-		// TODO: Determine whether bundles are incompatible
+		for _, b := range bundles {
+			if b.Version == "" {
+				b.Version = "-"
+			}
 
-		fmt.Printf(format, b.Name, b.Version, status)
+			status := "Disabled"
+			if b.Enabled {
+				status = "Enabled"
+			}
+			// TODO: Determine whether bundles are incompatible
 
-	}
+			fmt.Printf(format, b.Name, b.Version, status)
 
-	return nil
+		}
+	})
 }
@@ -57,11 +57,14 @@ func GetBundleCmd() *cobra.Command {
 		Long:  bundleLong,
 	}
 
+	cmd.AddCommand(GetBundleApproveCmd())
 	cmd.AddCommand(GetBundleDisableCmd())
 	cmd.AddCommand(GetBundleEnableCmd())
 	cmd.AddCommand(GetBundleInfoCmd())
 	cmd.AddCommand(GetBundleInstallCmd())
 	cmd.AddCommand(GetBundleListCmd())
+	cmd.AddCommand(GetBundleRejectCmd())
+	cmd.AddCommand(GetBundleReviewCmd())
 	cmd.AddCommand(GetBundleUninstallCmd())
 	cmd.AddCommand(GetBundleYamlCmd())
 	cmd.AddCommand(GetBundleVersionsCmd())
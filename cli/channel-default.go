@@ -0,0 +1,133 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data"
+	"github.com/spf13/cobra"
+)
+
+const (
+	channelDefaultUse   = "default"
+	channelDefaultShort = "Get or set a chat channel's default bundle"
+	channelDefaultLong  = `Get or set a chat channel's default bundle.
+
+Commands invoked from a channel without a bundle name (for example, "ec2"
+rather than "aws:ec2") are looked up in the channel's default bundle first.
+If the default bundle isn't enabled or has no such command, all enabled
+bundles are searched as usual.
+
+The channel must be specified by ID. With no bundle, the channel's current
+default bundle is shown.
+
+  gort channel default MySlack C0123456789 aws
+  gort channel default MySlack C0123456789
+  gort channel default --clear MySlack C0123456789`
+	channelDefaultUsage = `Usage:
+  gort channel default [flags] adapter channel_id [bundle]
+
+Flags:
+  -c, --clear  Remove the channel's default bundle
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagChannelDefaultClear bool
+)
+
+// GetChannelDefaultCmd is a command
+func GetChannelDefaultCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   channelDefaultUse,
+		Short: channelDefaultShort,
+		Long:  channelDefaultLong,
+		RunE:  channelDefaultCmd,
+		Args:  cobra.RangeArgs(2, 3),
+	}
+
+	cmd.SetUsageTemplate(channelDefaultUsage)
+	cmd.Flags().BoolVarP(&flagChannelDefaultClear, "clear", "c", false, "Remove the channel's default bundle")
+
+	return cmd
+}
+
+func channelDefaultCmd(cmd *cobra.Command, args []string) error {
+	adapter, channel := args[0], args[1]
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	settings, err := gortClient.ChannelSettingsGet(adapter, channel)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case flagChannelDefaultClear:
+		if len(args) > 2 {
+			return fmt.Errorf("a bundle can't be given with --clear")
+		}
+
+		settings.DefaultBundle = ""
+		err = setOrDeleteChannelSettings(gortClient, settings)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Cleared the default bundle of channel %s on %s\n", channel, adapter)
+
+	case len(args) > 2:
+		settings.DefaultBundle = args[2]
+		err = gortClient.ChannelSettingsSet(settings)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Set the default bundle of channel %s on %s to %s\n", channel, adapter, args[2])
+
+	default:
+		return printOutput(settings, func() {
+			if settings.DefaultBundle == "" {
+				fmt.Printf("Channel %s on %s has no default bundle\n", channel, adapter)
+			} else {
+				fmt.Println(settings.DefaultBundle)
+			}
+		})
+	}
+
+	return nil
+}
+
+// setOrDeleteChannelSettings saves a channel's settings or, if nothing is
+// left in them, deletes them.
+func setOrDeleteChannelSettings(gortClient *client.GortClient, settings data.ChannelSettings) error {
+	if settings.DefaultBundle == "" && settings.QuietHours == nil {
+		return gortClient.ChannelSettingsDelete(settings.Adapter, settings.ChannelID)
+	}
+
+	return gortClient.ChannelSettingsSet(settings)
+}
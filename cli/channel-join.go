@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	channelJoinUse   = "join"
+	channelJoinShort = "Make Gort join a chat channel"
+	channelJoinLong  = `Make Gort join a chat channel.
+
+The channel may be specified by ID or by name. Not all chat providers allow
+bots to join channels on request; for those that don't, the bot must be
+invited to the channel from the chat client.`
+	channelJoinUsage = `Usage:
+  gort channel join [flags] adapter channel
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetChannelJoinCmd is a command
+func GetChannelJoinCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   channelJoinUse,
+		Short: channelJoinShort,
+		Long:  channelJoinLong,
+		RunE:  channelJoinCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.SetUsageTemplate(channelJoinUsage)
+
+	return cmd
+}
+
+func channelJoinCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	channel, err := gortClient.ChannelJoin(args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	return printOutput(channel, func() {
+		fmt.Printf("Joined channel %s (%s) on %s\n", channel.Name, channel.ID, channel.Adapter)
+	})
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	channelLeaveUse   = "leave"
+	channelLeaveShort = "Make Gort leave a chat channel"
+	channelLeaveLong  = `Make Gort leave a chat channel.
+
+The channel may be specified by ID or by name. Not all chat providers allow
+bots to leave channels on request.`
+	channelLeaveUsage = `Usage:
+  gort channel leave [flags] adapter channel
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetChannelLeaveCmd is a command
+func GetChannelLeaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   channelLeaveUse,
+		Short: channelLeaveShort,
+		Long:  channelLeaveLong,
+		RunE:  channelLeaveCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.SetUsageTemplate(channelLeaveUsage)
+
+	return cmd
+}
+
+func channelLeaveCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	err = gortClient.ChannelLeave(args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Left channel %s on %s\n", args[1], args[0])
+
+	return nil
+}
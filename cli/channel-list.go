@@ -0,0 +1,83 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	channelListUse   = "list"
+	channelListShort = "List the chat channels Gort is present in"
+	channelListLong  = `List the chat channels Gort is present in.
+
+For each channel, the time of the most recent message seen by the Gort
+controller since it started is shown, if there has been one.`
+	channelListUsage = `Usage:
+  gort channel list [flags]
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetChannelListCmd is a command
+func GetChannelListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   channelListUse,
+		Short: channelListShort,
+		Long:  channelListLong,
+		RunE:  channelListCmd,
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.SetUsageTemplate(channelListUsage)
+
+	return cmd
+}
+
+func channelListCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	channels, err := gortClient.ChannelList()
+	if err != nil {
+		return err
+	}
+
+	return printOutput(channels, func() {
+		c := &Columnizer{}
+		c.StringColumn("ADAPTER", func(i int) string { return channels[i].Adapter })
+		c.StringColumn("ID", func(i int) string { return channels[i].ID })
+		c.StringColumn("NAME", func(i int) string { return channels[i].Name })
+		c.IntColumn("MEMBERS", func(i int) int { return channels[i].Members })
+		c.StringColumn("LAST ACTIVITY", func(i int) string {
+			if channels[i].LastActivity == nil {
+				return "-"
+			}
+			return channels[i].LastActivity.Local().Format("2006-01-02 15:04:05")
+		})
+		c.Print(channels)
+	})
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data"
+	"github.com/spf13/cobra"
+)
+
+const (
+	channelQuietUse   = "quiet"
+	channelQuietShort = "Get or set a chat channel's quiet hours"
+	channelQuietLong  = `Get or set a chat channel's quiet hours.
+
+During a channel's quiet hours, non-critical Gort output to the channel --
+command acknowledgements, greetings, and the results of scheduled jobs such
+as canaries -- is discarded or, with --digest, held and posted as a single
+digest when the quiet hours end. Critical and error output, including the
+output of commands, is always delivered immediately.
+
+Times are given as HH:MM. If the end time is earlier than the start time,
+quiet hours span midnight. The channel must be specified by ID. With no
+times, the channel's current quiet hours are shown.
+
+  gort channel quiet --timezone America/New_York --digest MySlack C0123456789 22:00 07:00
+  gort channel quiet MySlack C0123456789
+  gort channel quiet --clear MySlack C0123456789`
+	channelQuietUsage = `Usage:
+  gort channel quiet [flags] adapter channel_id [start end]
+
+Flags:
+  -c, --clear             Remove the channel's quiet hours
+  -d, --digest            Post output held during quiet hours when they end
+  -h, --help              Show this message and exit
+  -t, --timezone string   The time zone of the start and end times (default "UTC")
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagChannelQuietClear    bool
+	flagChannelQuietDigest   bool
+	flagChannelQuietTimezone string
+)
+
+// GetChannelQuietCmd is a command
+func GetChannelQuietCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   channelQuietUse,
+		Short: channelQuietShort,
+		Long:  channelQuietLong,
+		RunE:  channelQuietCmd,
+		Args:  cobra.RangeArgs(2, 4),
+	}
+
+	cmd.SetUsageTemplate(channelQuietUsage)
+	cmd.Flags().BoolVarP(&flagChannelQuietClear, "clear", "c", false, "Remove the channel's quiet hours")
+	cmd.Flags().BoolVarP(&flagChannelQuietDigest, "digest", "d", false, "Post output held during quiet hours when they end")
+	cmd.Flags().StringVarP(&flagChannelQuietTimezone, "timezone", "t", "", "The time zone of the start and end times")
+
+	return cmd
+}
+
+func channelQuietCmd(cmd *cobra.Command, args []string) error {
+	adapter, channel := args[0], args[1]
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	settings, err := gortClient.ChannelSettingsGet(adapter, channel)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case flagChannelQuietClear:
+		if len(args) > 2 {
+			return fmt.Errorf("times can't be given with --clear")
+		}
+
+		settings.QuietHours = nil
+		err = setOrDeleteChannelSettings(gortClient, settings)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Cleared the quiet hours of channel %s on %s\n", channel, adapter)
+
+	case len(args) > 2:
+		if len(args) != 4 {
+			return fmt.Errorf("both a start and an end time are required")
+		}
+
+		quiet := data.QuietHours{
+			Start:    args[2],
+			End:      args[3],
+			Timezone: flagChannelQuietTimezone,
+			Digest:   flagChannelQuietDigest,
+		}
+		if err := quiet.Validate(); err != nil {
+			return err
+		}
+
+		settings.QuietHours = &quiet
+		err = gortClient.ChannelSettingsSet(settings)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Set the quiet hours of channel %s on %s to %s\n", channel, adapter, formatQuietHours(quiet))
+
+	default:
+		return printOutput(settings.QuietHours, func() {
+			if settings.QuietHours == nil {
+				fmt.Printf("Channel %s on %s has no quiet hours\n", channel, adapter)
+			} else {
+				fmt.Println(formatQuietHours(*settings.QuietHours))
+			}
+		})
+	}
+
+	return nil
+}
+
+func formatQuietHours(q data.QuietHours) string {
+	tz := q.Timezone
+	if tz == "" {
+		tz = "UTC"
+	}
+
+	s := fmt.Sprintf("%s to %s %s", q.Start, q.End, tz)
+	if q.Digest {
+		s += ", with a digest"
+	}
+
+	return s
+}
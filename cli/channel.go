@@ -0,0 +1,44 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	channelUse   = "channel"
+	channelShort = "Manage the chat channels Gort is present in"
+	channelLong  = "Manage the chat channels Gort is present in."
+)
+
+// GetChannelCmd channel
+func GetChannelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   channelUse,
+		Short: channelShort,
+		Long:  channelLong,
+	}
+
+	cmd.AddCommand(GetChannelDefaultCmd())
+	cmd.AddCommand(GetChannelJoinCmd())
+	cmd.AddCommand(GetChannelLeaveCmd())
+	cmd.AddCommand(GetChannelListCmd())
+	cmd.AddCommand(GetChannelQuietCmd())
+
+	return cmd
+}
@@ -21,6 +21,9 @@ import (
 )
 
 var (
+	// FlagGortOutput is a persistent flag
+	FlagGortOutput string
+
 	// FlagGortProfile is a persistent flag
 	FlagGortProfile string
 )
@@ -39,6 +39,7 @@ Flags:
   -o, --owner string    The owning room, group, or user
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
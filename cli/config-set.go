@@ -44,6 +44,7 @@ Flags:
   -s, --secret          Makes a configuration value secret
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	costsUse   = "costs"
+	costsShort = "Report the compute used by command executions"
+	costsLong  = `Report the compute used by command executions, totalled by month, bundle,
+and group, so that it can be charged back to the teams that used it.
+
+Each execution is charged for the CPU and memory declared in its bundle's
+"resources" section (or the defaults in global.costs) for as long as its
+worker ran, and is attributed to the invoking user's group that sorts first
+by name. Costs are only reported if prices are set in global.costs.
+
+Cost accounting must be enabled in the global.costs section of the Gort
+configuration.`
+	costsUsage = `Usage:
+  gort costs [flags]
+
+Flags:
+  -h, --help           Show this message and exit
+  -m, --month string   Report only the given month, like 2021-06
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortCostsMonth string
+)
+
+// GetCostsCmd is a command
+func GetCostsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   costsUse,
+		Short: costsShort,
+		Long:  costsLong,
+		RunE:  costsCmd,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&flagGortCostsMonth, "month", "m", "", "Report only the given month, like 2021-06")
+
+	cmd.SetUsageTemplate(costsUsage)
+
+	return cmd
+}
+
+func costsCmd(cmd *cobra.Command, args []string) error {
+	if flagGortCostsMonth != "" {
+		if _, err := time.Parse("2006-01", flagGortCostsMonth); err != nil {
+			return fmt.Errorf("invalid --month: must be of the form YYYY-MM")
+		}
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	records, err := gortClient.CostList(flagGortCostsMonth)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(records, func() {
+		c := &Columnizer{}
+		c.StringColumn("MONTH", func(i int) string { return records[i].Month })
+		c.StringColumn("BUNDLE", func(i int) string { return records[i].Bundle })
+		c.StringColumn("GROUP", func(i int) string { return records[i].Group })
+		c.StringColumn("EXECUTIONS", func(i int) string { return strconv.FormatInt(records[i].Executions, 10) })
+		c.StringColumn("CPU HOURS", func(i int) string { return fmt.Sprintf("%.3f", records[i].CPUSeconds/3600) })
+		c.StringColumn("MEMORY GIB HOURS", func(i int) string { return fmt.Sprintf("%.3f", records[i].MemoryGiBSeconds/3600) })
+		c.StringColumn("COST", func(i int) string { return fmt.Sprintf("%.2f", records[i].Cost) })
+		c.Print(records)
+	})
+}
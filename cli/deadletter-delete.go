@@ -0,0 +1,77 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	deadletterDeleteUse   = "delete"
+	deadletterDeleteShort = "Delete an undeliverable response"
+	deadletterDeleteLong  = "Delete an undeliverable response without redelivering it."
+	deadletterDeleteUsage = `Usage:
+  gort deadletter delete [flags] id
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetDeadLetterDeleteCmd is a command
+func GetDeadLetterDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   deadletterDeleteUse,
+		Short: deadletterDeleteShort,
+		Long:  deadletterDeleteLong,
+		RunE:  deadletterDeleteCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.SetUsageTemplate(deadletterDeleteUsage)
+
+	return cmd
+}
+
+func deadletterDeleteCmd(cmd *cobra.Command, args []string) error {
+	id, err := parseDeadLetterID(args[0])
+	if err != nil {
+		return err
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleting dead letter %d... ", id)
+
+	err = gortClient.DeadLetterDelete(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Successful.")
+
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	deadletterListUse   = "list"
+	deadletterListShort = "List undeliverable responses"
+	deadletterListLong  = "List undeliverable responses, oldest first."
+	deadletterListUsage = `Usage:
+  gort deadletter list [flags]
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetDeadLetterListCmd is a command
+func GetDeadLetterListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   deadletterListUse,
+		Short: deadletterListShort,
+		Long:  deadletterListLong,
+		RunE:  deadletterListCmd,
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.SetUsageTemplate(deadletterListUsage)
+
+	return cmd
+}
+
+func deadletterListCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	letters, err := gortClient.DeadLetterList()
+	if err != nil {
+		return err
+	}
+
+	return printOutput(letters, func() {
+		c := &Columnizer{}
+		c.StringColumn("ID", func(i int) string { return strconv.FormatInt(letters[i].ID, 10) })
+		c.StringColumn("REQUEST", func(i int) string { return strconv.FormatInt(letters[i].RequestID, 10) })
+		c.StringColumn("CREATED", func(i int) string { return letters[i].Created.Local().Format(time.Stamp) })
+		c.StringColumn("ADAPTER", func(i int) string { return letters[i].Adapter })
+		c.StringColumn("CHANNEL", func(i int) string { return letters[i].ChannelID })
+		c.StringColumn("ATTEMPTS", func(i int) string { return strconv.Itoa(letters[i].Attempts) })
+		c.StringColumn("ERROR", func(i int) string { return letters[i].Error })
+		c.Print(letters)
+	})
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	deadletterRedeliverUse   = "redeliver"
+	deadletterRedeliverShort = "Retry delivery of an undeliverable response"
+	deadletterRedeliverLong  = `Retry delivery of an undeliverable response to its original channel, as
+plain text. If delivery succeeds the dead letter is deleted; otherwise it's
+kept, and the new error is recorded.
+`
+	deadletterRedeliverUsage = `Usage:
+  gort deadletter redeliver [flags] id
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetDeadLetterRedeliverCmd is a command
+func GetDeadLetterRedeliverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   deadletterRedeliverUse,
+		Short: deadletterRedeliverShort,
+		Long:  deadletterRedeliverLong,
+		RunE:  deadletterRedeliverCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.SetUsageTemplate(deadletterRedeliverUsage)
+
+	return cmd
+}
+
+func deadletterRedeliverCmd(cmd *cobra.Command, args []string) error {
+	id, err := parseDeadLetterID(args[0])
+	if err != nil {
+		return err
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Redelivering dead letter %d... ", id)
+
+	err = gortClient.DeadLetterRedeliver(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Successful.")
+
+	return nil
+}
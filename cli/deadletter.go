@@ -0,0 +1,60 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	deadletterUse   = "deadletter"
+	deadletterShort = "Manage responses that couldn't be delivered"
+	deadletterLong  = `Manage command responses that couldn't be delivered.
+
+When a response can't be delivered to its chat channel, even as plain text
+(for example, because the channel was archived or Gort's permissions were
+revoked), it's kept as a "dead letter". Once the problem is fixed it can be
+redelivered, or it can be deleted.
+`
+)
+
+// GetDeadLetterCmd deadletter
+func GetDeadLetterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   deadletterUse,
+		Short: deadletterShort,
+		Long:  deadletterLong,
+	}
+
+	cmd.AddCommand(GetDeadLetterDeleteCmd())
+	cmd.AddCommand(GetDeadLetterListCmd())
+	cmd.AddCommand(GetDeadLetterRedeliverCmd())
+
+	return cmd
+}
+
+func parseDeadLetterID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid dead letter ID: %s", s)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultsDeleteUse   = "delete"
+	defaultsDeleteShort = "Delete a command option default"
+	defaultsDeleteLong  = "Delete a command option default."
+	defaultsDeleteUsage = `Usage:
+  gort defaults delete [-b bundle] [-c command] [-l layer] [-o owner] [flags] option
+
+Flags:
+  -b, --bundle string    The bundle that contains the command (required)
+  -c, --command string   The command (required)
+  -h, --help             Show this message and exit
+  -l, --layer string     One of: [bundle room group] (default "bundle")
+  -o, --owner string     The owning room or group
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortDefaultsDeleteBundle  string
+	flagGortDefaultsDeleteCommand string
+	flagGortDefaultsDeleteLayer   string
+	flagGortDefaultsDeleteOwner   string
+)
+
+// GetDefaultsDeleteCmd is a command
+func GetDefaultsDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   defaultsDeleteUse,
+		Short: defaultsDeleteShort,
+		Long:  defaultsDeleteLong,
+		RunE:  defaultsDeleteCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.SetUsageTemplate(defaultsDeleteUsage)
+
+	cmd.Flags().StringVarP(&flagGortDefaultsDeleteBundle, "bundle", "b", "", "The bundle that contains the command")
+	cmd.Flags().StringVarP(&flagGortDefaultsDeleteCommand, "command", "c", "", "The command")
+	cmd.Flags().StringVarP(&flagGortDefaultsDeleteLayer, "layer", "l", "bundle", "One of: [bundle room group]")
+	cmd.Flags().StringVarP(&flagGortDefaultsDeleteOwner, "owner", "o", "", "The owning room or group")
+
+	return cmd
+}
+
+func defaultsDeleteCmd(cmd *cobra.Command, args []string) error {
+	def := data.OptionDefault{
+		Bundle:  flagGortDefaultsDeleteBundle,
+		Command: flagGortDefaultsDeleteCommand,
+		Layer:   data.ConfigurationLayer(flagGortDefaultsDeleteLayer),
+		Owner:   flagGortDefaultsDeleteOwner,
+		Option:  args[0],
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	err = gortClient.OptionDefaultDelete(def)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Option default deleted: %s\n", describeOptionDefault(def))
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultsListUse   = "list"
+	defaultsListShort = "List a command's option defaults"
+	defaultsListLong  = "List a command's option defaults at all layers."
+	defaultsListUsage = `Usage:
+  gort defaults list [-b bundle] [-c command] [flags]
+
+Flags:
+  -b, --bundle string    The bundle that contains the command (required)
+  -c, --command string   The command (required)
+  -h, --help             Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortDefaultsListBundle  string
+	flagGortDefaultsListCommand string
+)
+
+// GetDefaultsListCmd is a command
+func GetDefaultsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   defaultsListUse,
+		Short: defaultsListShort,
+		Long:  defaultsListLong,
+		RunE:  defaultsListCmd,
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.SetUsageTemplate(defaultsListUsage)
+
+	cmd.Flags().StringVarP(&flagGortDefaultsListBundle, "bundle", "b", "", "The bundle that contains the command")
+	cmd.Flags().StringVarP(&flagGortDefaultsListCommand, "command", "c", "", "The command")
+
+	return cmd
+}
+
+func defaultsListCmd(cmd *cobra.Command, args []string) error {
+	switch {
+	case flagGortDefaultsListBundle == "":
+		return fmt.Errorf("option default bundle (--bundle) is required")
+	case flagGortDefaultsListCommand == "":
+		return fmt.Errorf("option default command (--command) is required")
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	ds, err := gortClient.OptionDefaultList(flagGortDefaultsListBundle, flagGortDefaultsListCommand)
+	if err != nil {
+		return err
+	}
+
+	out := make([]optionDefaultOutput, len(ds))
+	for i, d := range ds {
+		out[i] = newOptionDefaultOutput(d)
+	}
+
+	return printOutput(out, func() {
+		c := &Columnizer{}
+		c.StringColumn("LAYER", func(i int) string { return string(ds[i].Layer) })
+		c.StringColumn("OWNER", func(i int) string {
+			if ds[i].Owner == "" {
+				return "-"
+			} else {
+				return ds[i].Owner
+			}
+		})
+		c.StringColumn("OPTION", func(i int) string { return ds[i].Option })
+		c.StringColumn("VALUE", func(i int) string {
+			if ds[i].Value == "" {
+				return "<flag>"
+			} else {
+				return ds[i].Value
+			}
+		})
+
+		c.Print(ds)
+	})
+}
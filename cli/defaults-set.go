@@ -0,0 +1,120 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultsSetUse   = "set"
+	defaultsSetShort = "Set or update a command option default"
+	defaultsSetLong  = `Set or update the default value of a command option.
+
+If no value is provided the option is treated as a flag: it's set to true
+whenever the user doesn't provide it.
+`
+	defaultsSetUsage = `Usage:
+  gort defaults set [-b bundle] [-c command] [-l layer] [-o owner] [flags] option [value]
+
+Flags:
+  -b, --bundle string    The bundle that contains the command (required)
+  -c, --command string   The command (required)
+  -h, --help             Show this message and exit
+  -l, --layer string     One of: [bundle room group] (default "bundle")
+  -o, --owner string     The owning room or group
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortDefaultsSetBundle  string
+	flagGortDefaultsSetCommand string
+	flagGortDefaultsSetLayer   string
+	flagGortDefaultsSetOwner   string
+)
+
+// GetDefaultsSetCmd is a command
+func GetDefaultsSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   defaultsSetUse,
+		Short: defaultsSetShort,
+		Long:  defaultsSetLong,
+		RunE:  defaultsSetCmd,
+		Args:  cobra.RangeArgs(1, 2),
+	}
+
+	cmd.SetUsageTemplate(defaultsSetUsage)
+
+	cmd.Flags().StringVarP(&flagGortDefaultsSetBundle, "bundle", "b", "", "The bundle that contains the command")
+	cmd.Flags().StringVarP(&flagGortDefaultsSetCommand, "command", "c", "", "The command")
+	cmd.Flags().StringVarP(&flagGortDefaultsSetLayer, "layer", "l", "bundle", "One of: [bundle room group]")
+	cmd.Flags().StringVarP(&flagGortDefaultsSetOwner, "owner", "o", "", "The owning room or group")
+
+	return cmd
+}
+
+func defaultsSetCmd(cmd *cobra.Command, args []string) error {
+	def := data.OptionDefault{
+		Bundle:  flagGortDefaultsSetBundle,
+		Command: flagGortDefaultsSetCommand,
+		Layer:   data.ConfigurationLayer(flagGortDefaultsSetLayer),
+		Owner:   flagGortDefaultsSetOwner,
+		Option:  args[0],
+	}
+
+	if len(args) > 1 {
+		def.Value = args[1]
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	if exists, err := gortClient.BundleExists(def.Bundle); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("no such bundle installed: %s", def.Bundle)
+	}
+
+	err = gortClient.OptionDefaultSave(def)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Option default set: %s\n", describeOptionDefault(def))
+
+	return nil
+}
+
+func describeOptionDefault(def data.OptionDefault) string {
+	if def.Layer == data.LayerBundle {
+		return fmt.Sprintf("bundle=%q command=%q layer=%q option=%q",
+			def.Bundle, def.Command, def.Layer, def.Option)
+	}
+
+	return fmt.Sprintf("bundle=%q command=%q layer=%q owner=%q option=%q",
+		def.Bundle, def.Command, def.Layer, def.Owner, def.Option)
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultsUse   = "defaults"
+	defaultsShort = "Read, write, or delete command option defaults"
+	defaultsLong  = `Read, write, or delete command option defaults.
+
+Option defaults are applied to a command's options when a user doesn't
+provide them explicitly. They can be set for a whole bundle, for a group, or
+for a chat channel. If more than one default applies, a channel default takes
+precedence over a group default, which takes precedence over a bundle default.
+`
+)
+
+// GetDefaultsCmd defaults
+func GetDefaultsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   defaultsUse,
+		Short: defaultsShort,
+		Long:  defaultsLong,
+	}
+
+	cmd.AddCommand(GetDefaultsDeleteCmd())
+	cmd.AddCommand(GetDefaultsListCmd())
+	cmd.AddCommand(GetDefaultsSetCmd())
+
+	return cmd
+}
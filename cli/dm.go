@@ -0,0 +1,98 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data/rest"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dmUse   = "dm"
+	dmShort = "Send a direct message to a Gort user"
+	dmLong  = `Send a direct message to a Gort user.
+
+The message is sent through each chat adapter that the user is mapped to, or
+only the adapter specified with --adapter. This is useful for notifying a
+user when a long-running job they started finishes.`
+	dmUsage = `Usage:
+  gort dm [flags] username message
+
+Flags:
+  -a, --adapter string   Only send through this adapter
+  -h, --help             Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortDmAdapter string
+)
+
+// GetDmCmd is a command
+func GetDmCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   dmUse,
+		Short: dmShort,
+		Long:  dmLong,
+		RunE:  dmCmd,
+		Args:  cobra.MinimumNArgs(2),
+	}
+
+	cmd.Flags().StringVarP(&flagGortDmAdapter, "adapter", "a", "", "Only send through this adapter")
+
+	cmd.SetUsageTemplate(dmUsage)
+
+	return cmd
+}
+
+func dmCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	result, err := gortClient.UserMessage(args[0], rest.DirectMessage{
+		Message: strings.Join(args[1:], " "),
+		Adapter: flagGortDmAdapter,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printOutput(result, func() {
+		fmt.Printf("Message sent to %d chat user(s).\n", len(result.Sent))
+
+		if len(result.Failed) > 0 {
+			fmt.Printf("Failed to send to %d chat user(s):\n\n", len(result.Failed))
+
+			failed := result.Failed
+			c := &Columnizer{}
+			c.StringColumn("ADAPTER", func(i int) string { return failed[i].Adapter })
+			c.StringColumn("USER ID", func(i int) string { return failed[i].UserID })
+			c.StringColumn("ERROR", func(i int) string { return failed[i].Error })
+			c.Print(failed)
+		}
+	})
+}
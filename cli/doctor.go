@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	doctorUse   = "doctor"
+	doctorShort = "Check the data store for dangling references"
+	doctorLong  = `Check the data store for dangling references: role permissions for bundles
+that aren't installed, user mappings to adapters that aren't configured, and
+enabled bundle versions that don't exist.
+
+By default problems are only reported. With --fix, each problem found is
+also repaired: the permission is revoked, the mapping is removed, or the
+bundle is disabled.
+
+A read-only check is also run each time Gort starts, logging a warning for
+each problem found.`
+	doctorUsage = `Usage:
+  gort doctor [flags]
+
+Flags:
+  -f, --fix    Repair the problems found
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortDoctorFix bool
+)
+
+// GetDoctorCmd is a command
+func GetDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   doctorUse,
+		Short: doctorShort,
+		Long:  doctorLong,
+		RunE:  doctorCmd,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().BoolVarP(&flagGortDoctorFix, "fix", "f", false, "Repair the problems found")
+
+	cmd.SetUsageTemplate(doctorUsage)
+
+	return cmd
+}
+
+func doctorCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	report, err := gortClient.Doctor(flagGortDoctorFix)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(report, func() {
+		problems := report.Problems
+
+		if len(problems) == 0 {
+			fmt.Println("No problems found.")
+			return
+		}
+
+		c := &Columnizer{}
+		c.StringColumn("KIND", func(i int) string { return problems[i].Kind })
+		c.StringColumn("SUBJECT", func(i int) string { return problems[i].Subject })
+		c.StringColumn("PROBLEM", func(i int) string { return problems[i].Description })
+		c.StringColumn("FIXED", func(i int) string { return fmt.Sprint(problems[i].Fixed) })
+		c.Print(problems)
+	})
+}
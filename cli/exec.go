@@ -0,0 +1,97 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	execUse   = "exec"
+	execShort = "Execute a command"
+	execLong  = `Execute a command.
+
+Executes a command exactly as it would be typed in chat (less any leading "!"),
+on behalf of the authenticated user, and prints its output here rather than
+sending it to a chat channel. The command's rules are evaluated against the
+user's permissions, and the request is recorded under the user's name.
+
+It's intended for automation like schedulers, webhooks, and CI, which should
+authenticate as a service account: create one with 'gort user create
+--service-account', issue it a token with 'gort user token', and provide the
+token in the GORT_SERVICE_TOKEN environment variable.
+
+If the command exits with a non-zero status, so does this one.`
+	execUsage = `Usage:
+  gort exec [flags] "bundle:command [args]"
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetExecCmd is a command
+func GetExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   execUse,
+		Short: execShort,
+		Long:  execLong,
+		RunE:  execCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.SetUsageTemplate(execUsage)
+
+	return cmd
+}
+
+func execCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	result, err := gortClient.Exec(args[0])
+	if err != nil {
+		return err
+	}
+
+	err = printOutput(result, func() {
+		for _, line := range result.Output {
+			fmt.Println(line)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.ExitCode != 0 {
+		if result.Error != "" {
+			return fmt.Errorf("request %d exited with status %d: %s", result.RequestID, result.ExitCode, result.Error)
+		}
+		return fmt.Errorf("request %d exited with status %d", result.RequestID, result.ExitCode)
+	}
+
+	return nil
+}
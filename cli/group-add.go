@@ -42,6 +42,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -44,6 +44,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -85,17 +86,23 @@ func groupInfoCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	const format = `Name   %s
+	out := groupOutput{
+		Name:  groupname,
+		Users: userNames(users),
+		Roles: roleNames(roles),
+	}
+
+	return printOutput(out, func() {
+		const format = `Name   %s
 Users  %s
 Roles  %s
 `
 
-	fmt.Printf(
-		format,
-		groupname,
-		strings.Join(userNames(users), ", "),
-		strings.Join(roleNames(roles), ", "),
-	)
-
-	return nil
+		fmt.Printf(
+			format,
+			out.Name,
+			strings.Join(out.Users, ", "),
+			strings.Join(out.Roles, ", "),
+		)
+	})
 }
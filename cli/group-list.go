@@ -54,6 +54,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -86,9 +87,14 @@ func groupListCmd(cmd *cobra.Command, args []string) error {
 	// Sort by name, for presentation purposes.
 	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
 
-	c := &Columnizer{}
-	c.StringColumn("GROUP NAME", func(i int) string { return groups[i].Name })
-	c.Print(groups)
+	out := make([]groupOutput, len(groups))
+	for i, g := range groups {
+		out[i] = groupOutput{Name: g.Name}
+	}
 
-	return nil
+	return printOutput(out, func() {
+		c := &Columnizer{}
+		c.StringColumn("GROUP NAME", func(i int) string { return groups[i].Name })
+		c.Print(groups)
+	})
 }
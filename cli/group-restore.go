@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	groupRestoreUse   = "restore"
+	groupRestoreShort = "Restore a recently deleted group"
+	groupRestoreLong  = `Restore a recently deleted group, along with its members and roles.
+
+Deleted groups are retained for a limited time (7 days by default) before
+being removed permanently.`
+	groupRestoreUsage = `Usage:
+  gort group restore [flags] group_name
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetGroupRestoreCmd is a command
+func GetGroupRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   groupRestoreUse,
+		Short: groupRestoreShort,
+		Long:  groupRestoreLong,
+		RunE:  groupRestoreCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.SetUsageTemplate(groupRestoreUsage)
+
+	return cmd
+}
+
+func groupRestoreCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	groupname := args[0]
+
+	fmt.Printf("Restoring group %s... ", groupname)
+
+	err = gortClient.GroupRestore(groupname)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Successful.")
+
+	return nil
+}
@@ -61,6 +61,7 @@ func GetGroupCmd() *cobra.Command {
 	cmd.AddCommand(GetGroupInfoCmd())
 	cmd.AddCommand(GetGroupListCmd())
 	cmd.AddCommand(GetGroupRemoveCmd())
+	cmd.AddCommand(GetGroupRestoreCmd())
 	cmd.AddCommand(GetGroupRevokeCmd())
 
 	return cmd
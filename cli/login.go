@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data/rest"
+)
+
+const (
+	loginUse   = "login"
+	loginShort = "Log in with the server's identity provider"
+	loginLong  = `Log in with the Gort server's OpenID Connect identity provider, rather than
+with the profile's password.
+
+A URL and a code are displayed: visit the URL in any browser, enter the code,
+and log in. The resulting token is saved, just like one obtained with a
+password. Profiles created with "gort profile create --sso" can only log in
+this way.`
+	loginUsage = `Usage:
+  gort login [flags]
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetLoginCmd is a command
+func GetLoginCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   loginUse,
+		Short: loginShort,
+		Long:  loginLong,
+		RunE:  loginCmd,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.SetUsageTemplate(loginUsage)
+
+	return cmd
+}
+
+func loginCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	token, err := gortClient.AuthenticateOIDC(func(da rest.DeviceAuthorization) {
+		if da.VerificationURIComplete != "" {
+			fmt.Printf("To log in, visit %s\n", da.VerificationURIComplete)
+			fmt.Printf("and confirm that it shows the code %s.\n", da.UserCode)
+		} else {
+			fmt.Printf("To log in, visit %s\n", da.VerificationURI)
+			fmt.Printf("and enter the code %s.\n", da.UserCode)
+		}
+		fmt.Println("Waiting for the login to complete...")
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged in as %s.\n", token.User)
+
+	return nil
+}
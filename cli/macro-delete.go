@@ -0,0 +1,86 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	macroDeleteUse   = "delete"
+	macroDeleteShort = "Delete a macro"
+	macroDeleteLong  = "Delete a macro."
+	macroDeleteUsage = `Usage:
+  gort macro delete [-g group | -u user] [flags] macro_name
+
+Flags:
+  -g, --group string   Delete the macro shared with this group
+  -h, --help           Show this message and exit
+  -u, --user string    Delete the macro of this user (default: yourself)
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortMacroDeleteGroup string
+	flagGortMacroDeleteUser  string
+)
+
+// GetMacroDeleteCmd is a command
+func GetMacroDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   macroDeleteUse,
+		Short: macroDeleteShort,
+		Long:  macroDeleteLong,
+		RunE:  macroDeleteCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.SetUsageTemplate(macroDeleteUsage)
+
+	cmd.Flags().StringVarP(&flagGortMacroDeleteGroup, "group", "g", "", "Delete the macro shared with this group")
+	cmd.Flags().StringVarP(&flagGortMacroDeleteUser, "user", "u", "", "Delete the macro of this user")
+
+	return cmd
+}
+
+func macroDeleteCmd(cmd *cobra.Command, args []string) error {
+	macro, err := newMacro(args[0], flagGortMacroDeleteGroup, flagGortMacroDeleteUser)
+	if err != nil {
+		return err
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	err = gortClient.MacroDelete(macro)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Macro deleted: %s\n", describeMacro(macro))
+
+	return nil
+}
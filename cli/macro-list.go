@@ -0,0 +1,83 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	macroListUse   = "list"
+	macroListShort = "List the macros available to you"
+	macroListLong  = `List the macros available to you: your own, and those shared with the
+groups you belong to. Users with the manage_macros permission see every
+macro.
+`
+	macroListUsage = `Usage:
+  gort macro list [flags]
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetMacroListCmd is a command
+func GetMacroListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   macroListUse,
+		Short: macroListShort,
+		Long:  macroListLong,
+		RunE:  macroListCmd,
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.SetUsageTemplate(macroListUsage)
+
+	return cmd
+}
+
+func macroListCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	ms, err := gortClient.MacroList()
+	if err != nil {
+		return err
+	}
+
+	out := make([]macroOutput, len(ms))
+	for i, m := range ms {
+		out[i] = newMacroOutput(m)
+	}
+
+	return printOutput(out, func() {
+		c := &Columnizer{}
+		c.StringColumn("NAME", func(i int) string { return ms[i].Name })
+		c.StringColumn("LAYER", func(i int) string { return string(ms[i].Layer) })
+		c.StringColumn("OWNER", func(i int) string { return ms[i].Owner })
+		c.StringColumn("COMMAND", func(i int) string { return ms[i].Command })
+
+		c.Print(ms)
+	})
+}
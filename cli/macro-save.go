@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	macroSaveUse   = "save"
+	macroSaveShort = "Create or replace a macro"
+	macroSaveLong  = `Create or replace a macro.
+
+The command is a template: any {{.name}} placeholders are filled in from the
+name=value arguments the macro is invoked with, all of which are required.
+`
+	macroSaveUsage = `Usage:
+  gort macro save [-g group | -u user] [flags] macro_name command
+
+Flags:
+  -g, --group string   Share the macro with the members of this group
+  -h, --help           Show this message and exit
+  -u, --user string    Save the macro for this user (default: yourself)
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortMacroSaveGroup string
+	flagGortMacroSaveUser  string
+)
+
+// GetMacroSaveCmd is a command
+func GetMacroSaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   macroSaveUse,
+		Short: macroSaveShort,
+		Long:  macroSaveLong,
+		RunE:  macroSaveCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.SetUsageTemplate(macroSaveUsage)
+
+	cmd.Flags().StringVarP(&flagGortMacroSaveGroup, "group", "g", "", "Share the macro with the members of this group")
+	cmd.Flags().StringVarP(&flagGortMacroSaveUser, "user", "u", "", "Save the macro for this user")
+
+	return cmd
+}
+
+func macroSaveCmd(cmd *cobra.Command, args []string) error {
+	macro, err := newMacro(args[0], flagGortMacroSaveGroup, flagGortMacroSaveUser)
+	if err != nil {
+		return err
+	}
+	macro.Command = args[1]
+
+	if err := macro.Validate(); err != nil {
+		return err
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	err = gortClient.MacroSave(macro)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Macro saved: %s\n", describeMacro(macro))
+
+	return nil
+}
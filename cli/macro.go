@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/data"
+	"github.com/spf13/cobra"
+)
+
+const (
+	macroUse   = "macro"
+	macroShort = "Save, list, or delete command macros"
+	macroLong  = `Save, list, or delete command macros.
+
+A macro is a named, parameterized command. Its command is a template whose
+{{.name}} placeholders are filled in from the name=value arguments the macro
+is invoked with. For example, a macro saved as
+
+  gort macro save restart-api "kubectl:rollout restart deploy/{{.svc}}"
+
+can be invoked in chat as "!restart-api svc=billing". The expanded command
+is subject to the same rules as if it had been typed directly.
+
+Macros belong to a user or, with --group, to a group, in which case they're
+available to all of its members. A user's own macros take precedence over
+their groups'.
+`
+)
+
+// GetMacroCmd macro
+func GetMacroCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   macroUse,
+		Short: macroShort,
+		Long:  macroLong,
+	}
+
+	cmd.AddCommand(GetMacroDeleteCmd())
+	cmd.AddCommand(GetMacroListCmd())
+	cmd.AddCommand(GetMacroSaveCmd())
+
+	return cmd
+}
+
+// newMacro builds a macro value from a name and the --group and --user
+// flags. If neither flag is set the macro belongs to the requesting user.
+func newMacro(name, group, user string) (data.Macro, error) {
+	switch {
+	case group != "" && user != "":
+		return data.Macro{}, fmt.Errorf("only one of --group or --user may be set")
+	case group != "":
+		return data.Macro{Name: name, Layer: data.LayerGroup, Owner: group}, nil
+	default:
+		return data.Macro{Name: name, Layer: data.LayerUser, Owner: user}, nil
+	}
+}
+
+func describeMacro(m data.Macro) string {
+	if m.Owner == "" {
+		return fmt.Sprintf("name=%q layer=%q", m.Name, m.Layer)
+	}
+
+	return fmt.Sprintf("name=%q layer=%q owner=%q", m.Name, m.Layer, m.Owner)
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+// Supported values for the --output flag.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// printOutput writes v to stdout in the format selected by the --output flag.
+// For the (default) table format, the table function is called to print the
+// human-readable form instead.
+//
+// The YAML form is produced from the JSON form, so both use the same field
+// names: the json tags of v.
+func printOutput(v interface{}, table func()) error {
+	switch strings.ToLower(FlagGortOutput) {
+	case "", outputTable:
+		table()
+		return nil
+
+	case outputJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+
+	case outputYAML:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		var i interface{}
+		if err := json.Unmarshal(b, &i); err != nil {
+			return err
+		}
+
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(i); err != nil {
+			return err
+		}
+		return enc.Close()
+
+	default:
+		return fmt.Errorf("unsupported output format %q: must be one of %v",
+			FlagGortOutput, []string{outputTable, outputJSON, outputYAML})
+	}
+}
+
+// The following types describe the machine-readable output of the various
+// list and info commands. Their field names are part of the CLI's interface:
+// don't rename or remove them.
+
+type bundleOutput struct {
+	Name           string   `json:"name"`
+	Version        string   `json:"version,omitempty"`
+	Versions       []string `json:"versions,omitempty"`
+	Enabled        bool     `json:"enabled"`
+	EnabledVersion string   `json:"enabled_version,omitempty"`
+	Platform       string   `json:"platform,omitempty"`
+	Commands       []string `json:"commands,omitempty"`
+	Permissions    []string `json:"permissions,omitempty"`
+}
+
+type groupOutput struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+type permissionOutput struct {
+	Name       string `json:"name"`
+	Bundle     string `json:"bundle"`
+	Permission string `json:"permission"`
+	Version    string `json:"version,omitempty"`
+}
+
+type roleOutput struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	Groups      []string `json:"groups"`
+}
+
+func newRoleOutput(r rest.Role) roleOutput {
+	return roleOutput{
+		Name:        r.Name,
+		Permissions: r.Permissions.Strings(),
+		Groups:      groupNames(r.Groups),
+	}
+}
+
+type userOutput struct {
+	Username       string            `json:"username"`
+	FullName       string            `json:"fullname"`
+	Email          string            `json:"email"`
+	ServiceAccount bool              `json:"service_account,omitempty"`
+	Groups         []string          `json:"groups,omitempty"`
+	Mappings       map[string]string `json:"mappings,omitempty"`
+}
+
+func newUserOutput(u rest.User) userOutput {
+	return userOutput{
+		Username:       u.Username,
+		FullName:       u.FullName,
+		Email:          u.Email,
+		ServiceAccount: u.ServiceAccount,
+		Mappings:       u.Mappings,
+	}
+}
+
+type aliasOutput struct {
+	Name    string `json:"name"`
+	Owner   string `json:"owner,omitempty"`
+	Command string `json:"command"`
+}
+
+func newAliasOutput(a data.Alias) aliasOutput {
+	return aliasOutput{
+		Name:    a.Name,
+		Owner:   a.Owner,
+		Command: a.Command,
+	}
+}
+
+type macroOutput struct {
+	Name    string `json:"name"`
+	Layer   string `json:"layer"`
+	Owner   string `json:"owner"`
+	Command string `json:"command"`
+}
+
+func newMacroOutput(m data.Macro) macroOutput {
+	return macroOutput{
+		Name:    m.Name,
+		Layer:   string(m.Layer),
+		Owner:   m.Owner,
+		Command: m.Command,
+	}
+}
+
+type optionDefaultOutput struct {
+	Bundle  string `json:"bundle"`
+	Command string `json:"command"`
+	Layer   string `json:"layer"`
+	Owner   string `json:"owner,omitempty"`
+	Option  string `json:"option"`
+	Value   string `json:"value,omitempty"`
+}
+
+func newOptionDefaultOutput(d data.OptionDefault) optionDefaultOutput {
+	return optionDefaultOutput{
+		Bundle:  d.Bundle,
+		Command: d.Command,
+		Layer:   string(d.Layer),
+		Owner:   d.Owner,
+		Option:  d.Option,
+		Value:   d.Value,
+	}
+}
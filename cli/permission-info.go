@@ -34,6 +34,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -66,16 +67,27 @@ func permissionInfoCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Printf(format, "BUNDLE", "PERMISSION", "VERSION")
+	perms := make([]permissionOutput, 0)
 
 	for _, b := range bundles {
 		for _, p := range b.Permissions {
 			combinedName := fmt.Sprintf("%v:%v", b.Name, p)
 			if p == args[0] || combinedName == args[0] {
-				fmt.Printf(format, b.Name, p, b.Version)
+				perms = append(perms, permissionOutput{
+					Name:       combinedName,
+					Bundle:     b.Name,
+					Permission: p,
+					Version:    b.Version,
+				})
 			}
 		}
 	}
 
-	return nil
+	return printOutput(perms, func() {
+		fmt.Printf(format, "BUNDLE", "PERMISSION", "VERSION")
+
+		for _, p := range perms {
+			fmt.Printf(format, p.Bundle, p.Permission, p.Version)
+		}
+	})
 }
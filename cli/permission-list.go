@@ -35,6 +35,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -64,20 +65,24 @@ func permissionListCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	names := make([]string, 0)
+	perms := make([]permissionOutput, 0)
 
 	for _, b := range bundles {
 		for _, p := range b.Permissions {
-			names = append(names, fmt.Sprintf("%v:%v", b.Name, p))
+			perms = append(perms, permissionOutput{
+				Name:       fmt.Sprintf("%v:%v", b.Name, p),
+				Bundle:     b.Name,
+				Permission: p,
+			})
 		}
 	}
 
 	// Sort by name, for presentation purposes.
-	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	sort.Slice(perms, func(i, j int) bool { return perms[i].Name < perms[j].Name })
 
-	c := &Columnizer{}
-	c.StringColumn("NAME", func(i int) string { return names[i] })
-	c.Print(names)
-
-	return nil
+	return printOutput(perms, func() {
+		c := &Columnizer{}
+		c.StringColumn("NAME", func(i int) string { return perms[i].Name })
+		c.Print(perms)
+	})
 }
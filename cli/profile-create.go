@@ -35,15 +35,24 @@ import (
 const (
 	profileCreateUse   = "create"
 	profileCreateShort = "Create a new Gort user profile"
-	profileCreateLong  = "Adds a new profile with the given name for the specified Gort server."
+	profileCreateLong  = `Adds a new profile with the given name for the specified Gort server.
+
+With --sso, the profile's user logs in with the server's OpenID Connect
+identity provider using "gort login", so no user or password is given.`
 	profileCreateUsage = `Usage:
   gort profile create [flags] profile_name url user password
+  gort profile create --sso [flags] profile_name url
 
 Flags:
   -h, --help   Show this message and exit
+      --sso    Log in with the server's identity provider instead of a password
 `
 )
 
+var (
+	flagProfileCreateSSO bool
+)
+
 // GetProfileCreateCmd is a command
 func GetProfileCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -51,9 +60,16 @@ func GetProfileCreateCmd() *cobra.Command {
 		Short: profileCreateShort,
 		Long:  profileCreateLong,
 		RunE:  profileCreateCmd,
-		Args:  cobra.ExactArgs(4),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if flagProfileCreateSSO {
+				return cobra.ExactArgs(2)(cmd, args)
+			}
+			return cobra.ExactArgs(4)(cmd, args)
+		},
 	}
 
+	cmd.Flags().BoolVar(&flagProfileCreateSSO, "sso", false, "Log in with the server's identity provider instead of a password")
+
 	cmd.SetUsageTemplate(profileCreateUsage)
 
 	return cmd
@@ -72,8 +88,12 @@ func profileCreateCmd(cmd *cobra.Command, args []string) error {
 
 	name := args[0]
 	urlstring := args[1]
-	user := args[2]
-	password := args[3]
+
+	var user, password string
+	if !flagProfileCreateSSO {
+		user = args[2]
+		password = args[3]
+	}
 
 	if _, exists := profile.Profiles[name]; exists {
 		fmt.Printf("Profile '%s' already exists.\n", name)
@@ -91,6 +111,7 @@ func profileCreateCmd(cmd *cobra.Command, args []string) error {
 		URLString: furl.String(),
 		Password:  password,
 		Username:  user,
+		SSO:       flagProfileCreateSSO,
 	}
 
 	profile.Profiles[name] = pe
@@ -105,7 +126,11 @@ func profileCreateCmd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("Profile '%s' (%s@%s) created.\n", pe.Name, pe.Username, pe.URLString)
+	if pe.SSO {
+		fmt.Printf("Profile '%s' (%s) created. Use 'gort login' to log in.\n", pe.Name, pe.URLString)
+	} else {
+		fmt.Printf("Profile '%s' (%s@%s) created.\n", pe.Name, pe.Username, pe.URLString)
+	}
 
 	return nil
 }
@@ -0,0 +1,248 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data"
+	"github.com/spf13/cobra"
+)
+
+const (
+	psUse   = "ps"
+	psShort = "List recent command requests"
+	psLong  = `List recent command requests.
+
+With no arguments, lists the most recent command requests, newest first,
+along with each request's status, duration, and slowest stage.
+
+If a request ID is provided, details on that request are presented instead,
+including how long each stage of the request took: command lookup,
+authorization, scheduling, worker start, execution, rendering, and sending,
+and any log entries that the command attached to the request.`
+	psUsage = `Usage:
+  gort ps [flags] [request_id]
+
+Flags:
+  -h, --help        Show this message and exit
+  -n, --limit int   The maximum number of requests to list (default 20)
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortPsLimit int
+)
+
+// GetPsCmd is a command
+func GetPsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   psUse,
+		Short: psShort,
+		Long:  psLong,
+		RunE:  psCmd,
+		Args:  cobra.MaximumNArgs(1),
+	}
+
+	cmd.Flags().IntVarP(&flagGortPsLimit, "limit", "n", 20, "The maximum number of requests to list")
+
+	cmd.SetUsageTemplate(psUsage)
+
+	return cmd
+}
+
+func psCmd(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid request ID: %s", args[0])
+		}
+		return doPsInfo(id)
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	records, err := gortClient.RequestList(flagGortPsLimit)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(records, func() {
+		c := &Columnizer{}
+		c.StringColumn("ID", func(i int) string { return strconv.FormatInt(records[i].RequestID, 10) })
+		c.StringColumn("STARTED", func(i int) string { return records[i].Timestamp.Local().Format(time.Stamp) })
+		c.StringColumn("COMMAND", func(i int) string { return requestCommandName(records[i]) })
+		c.StringColumn("USER", func(i int) string { return records[i].UserName })
+		c.StringColumn("STATUS", func(i int) string { return requestStatus(records[i]) })
+		c.StringColumn("DURATION", func(i int) string { return formatRequestDuration(records[i].Duration) })
+		c.StringColumn("SLOWEST STAGE", func(i int) string {
+			stage, d := slowestStage(records[i].Timings)
+			if stage == "" {
+				return ""
+			}
+			return fmt.Sprintf("%s (%s)", stage, formatRequestDuration(d))
+		})
+		c.Print(records)
+	})
+}
+
+func doPsInfo(id int64) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	record, err := gortClient.RequestGet(id)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(record, func() {
+		fmt.Printf("Request ID: %d\n", record.RequestID)
+		fmt.Printf("Command: %s %s\n", requestCommandName(record), record.Parameters)
+		fmt.Printf("Bundle Version: %s\n", record.BundleVersion)
+		if record.ReplayOf != 0 {
+			fmt.Printf("Replay Of: %d\n", record.ReplayOf)
+		}
+		fmt.Printf("User: %s\n", record.UserName)
+		fmt.Printf("Adapter: %s\n", record.Adapter)
+		fmt.Printf("Channel: %s\n", record.ChannelID)
+		fmt.Printf("Started: %s\n", record.Timestamp.Local().Format(time.RFC3339))
+		fmt.Printf("Status: %s\n", requestStatus(record))
+		fmt.Printf("Duration: %s\n", formatRequestDuration(record.Duration))
+		if record.Error != "" {
+			fmt.Printf("Error: %s\n", record.Error)
+		}
+
+		if len(record.Timings) > 0 {
+			fmt.Println()
+			printRequestTimings(record)
+		}
+
+		if len(record.Logs) > 0 {
+			fmt.Println()
+			printRequestLogs(record.Logs)
+		}
+
+		if record.Payload != nil {
+			fmt.Println()
+			printRequestPayload(record.Payload)
+		}
+	})
+}
+
+func printRequestLogs(logs []data.RequestLogEntry) {
+	c := &Columnizer{}
+	c.StringColumn("TIME", func(i int) string { return logs[i].Timestamp.Local().Format(time.StampMilli) })
+	c.StringColumn("LEVEL", func(i int) string { return logs[i].Level })
+	c.StringColumn("MESSAGE", func(i int) string { return logs[i].Message })
+	c.StringColumn("FIELDS", func(i int) string {
+		keys := make([]string, 0, len(logs[i].Fields))
+		for k := range logs[i].Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fields := make([]string, len(keys))
+		for j, k := range keys {
+			fields[j] = k + "=" + logs[i].Fields[k]
+		}
+		return strings.Join(fields, " ")
+	})
+	c.Print(logs)
+}
+
+func printRequestTimings(record data.RequestRecord) {
+	var stages []data.RequestStage
+	for _, s := range data.RequestStages {
+		if _, ok := record.Timings[s]; ok {
+			stages = append(stages, s)
+		}
+	}
+
+	c := &Columnizer{}
+	c.StringColumn("STAGE", func(i int) string { return string(stages[i]) })
+	c.StringColumn("DURATION", func(i int) string { return formatRequestDuration(record.Timings[stages[i]]) })
+	c.Print(stages)
+}
+
+func printRequestPayload(p *data.RequestPayload) {
+	fmt.Printf("Archived: %s\n", p.ArchivedAt.Local().Format(time.RFC3339))
+	if p.Title != "" {
+		fmt.Printf("Title: %s\n", p.Title)
+	}
+	if p.Truncated {
+		fmt.Println("(payload truncated)")
+	}
+
+	if len(p.Output) > 0 {
+		fmt.Println("\nOutput:")
+		for _, line := range p.Output {
+			fmt.Println(line)
+		}
+	}
+
+	if p.Rendered != "" {
+		fmt.Println("\nRendered:")
+		fmt.Println(p.Rendered)
+	}
+}
+
+func formatRequestDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
+
+func requestCommandName(r data.RequestRecord) string {
+	return fmt.Sprintf("%s:%s", r.BundleName, r.CommandName)
+}
+
+func requestStatus(r data.RequestRecord) string {
+	switch {
+	case !r.Closed:
+		return "running"
+	case r.ExitCode == 0:
+		return "ok"
+	default:
+		return fmt.Sprintf("exit %d", r.ExitCode)
+	}
+}
+
+// slowestStage returns the stage that took the longest, and its duration.
+func slowestStage(t data.StageTimings) (data.RequestStage, time.Duration) {
+	var slowest data.RequestStage
+	var max time.Duration
+
+	for _, s := range data.RequestStages {
+		if d, ok := t[s]; ok && d > max {
+			slowest, max = s, d
+		}
+	}
+
+	return slowest, max
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data/rest"
+	"github.com/spf13/cobra"
+)
+
+const (
+	replayUse   = "replay"
+	replayShort = "Re-execute a historical command request"
+	replayLong  = `Re-execute a historical command request.
+
+Runs the command from the request with the given ID again, with identical
+parameters and on behalf of the same user and channel. By default the same
+bundle version that originally handled the request is used; use --version to
+replay it against a different version instead, for example to verify a fix
+after a bundle upgrade.
+
+The replay is recorded as a new request that links back to the original, and
+its output is returned here rather than sent to the original channel.
+
+Requests whose parameters were redacted when they were recorded can't be
+replayed.`
+	replayUsage = `Usage:
+  gort replay [flags] request_id
+
+Flags:
+  -h, --help             Show this message and exit
+  -v, --version string   The bundle version to replay against (default: the original version)
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortReplayVersion string
+)
+
+// GetReplayCmd is a command
+func GetReplayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   replayUse,
+		Short: replayShort,
+		Long:  replayLong,
+		RunE:  replayCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().StringVarP(&flagGortReplayVersion, "version", "v", "", "The bundle version to replay against")
+
+	cmd.SetUsageTemplate(replayUsage)
+
+	return cmd
+}
+
+func replayCmd(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid request ID: %s", args[0])
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	result, err := gortClient.RequestReplay(id, rest.ReplayRequest{BundleVersion: flagGortReplayVersion})
+	if err != nil {
+		return err
+	}
+
+	return printOutput(result, func() {
+		fmt.Printf("Request ID: %d (replay of %d)\n", result.RequestID, result.ReplayOf)
+		fmt.Printf("Bundle Version: %s\n", result.BundleVersion)
+		fmt.Printf("Duration: %s\n", formatRequestDuration(result.Duration))
+		if result.ExitCode == 0 {
+			fmt.Println("Status: ok")
+		} else {
+			fmt.Printf("Status: exit %d\n", result.ExitCode)
+		}
+		if result.Error != "" {
+			fmt.Printf("Error: %s\n", result.Error)
+		}
+
+		if len(result.Output) > 0 {
+			fmt.Println("\nOutput:")
+			for _, line := range result.Output {
+				fmt.Println(line)
+			}
+		}
+	})
+}
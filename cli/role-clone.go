@@ -0,0 +1,82 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getgort/gort/client"
+)
+
+const (
+	roleCloneUse   = "clone"
+	roleCloneShort = "Copy a role's permissions to a new role"
+	roleCloneLong  = `Create a new role with the same permissions as an existing role.
+
+Group memberships aren't copied: grant the new role to groups as needed with
+"gort group grant".`
+	roleCloneUsage = `Usage:
+  gort role clone [flags] source_role new_role
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetRoleCloneCmd is a command
+func GetRoleCloneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   roleCloneUse,
+		Short: roleCloneShort,
+		Long:  roleCloneLong,
+		RunE:  roleCloneCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.SetUsageTemplate(roleCloneUsage)
+
+	return cmd
+}
+
+func roleCloneCmd(cmd *cobra.Command, args []string) error {
+	rolename, clonename := args[0], args[1]
+
+	c, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	err = c.RoleClone(rolename, clonename)
+	if err != nil {
+		return err
+	}
+
+	perms, err := c.RolePermissionList(clonename)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Role %q cloned to %q with %d permission(s).\n", rolename, clonename, len(perms))
+
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getgort/gort/client"
+)
+
+const (
+	roleDiffUse   = "diff"
+	roleDiffShort = "Compare the permissions of two roles"
+	roleDiffLong  = `Compare the permissions granted to two roles.
+
+Permissions granted only to the first role are prefixed with "-", those
+granted only to the second role with "+", and those granted to both with a
+space.`
+	roleDiffUsage = `Usage:
+  gort role diff [flags] role_a role_b
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetRoleDiffCmd is a command
+func GetRoleDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   roleDiffUse,
+		Short: roleDiffShort,
+		Long:  roleDiffLong,
+		RunE:  roleDiffCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.SetUsageTemplate(roleDiffUsage)
+
+	return cmd
+}
+
+func roleDiffCmd(cmd *cobra.Command, args []string) error {
+	c, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	diff, err := c.RoleDiff(args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", diff.A, diff.B)
+
+	for _, p := range diff.OnlyA {
+		fmt.Printf("-%s\n", p)
+	}
+	for _, p := range diff.OnlyB {
+		fmt.Printf("+%s\n", p)
+	}
+	for _, p := range diff.Common {
+		fmt.Printf(" %s\n", p)
+	}
+
+	if len(diff.OnlyA) == 0 && len(diff.OnlyB) == 0 {
+		fmt.Println("Roles have identical permissions.")
+	}
+
+	return nil
+}
@@ -35,6 +35,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -67,15 +68,17 @@ func roleInfoCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	const format = `Name         %s
+	out := newRoleOutput(role)
+
+	return printOutput(out, func() {
+		const format = `Name         %s
 Permissions  %s
 Groups       %s
 `
 
-	fmt.Printf(format,
-		role.Name,
-		strings.Join(role.Permissions.Strings(), ", "),
-		strings.Join(groupNames(role.Groups), ", "))
-
-	return nil
+		fmt.Printf(format,
+			out.Name,
+			strings.Join(out.Permissions, ", "),
+			strings.Join(out.Groups, ", "))
+	})
 }
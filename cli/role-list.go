@@ -34,6 +34,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -66,9 +67,14 @@ func roleListCmd(cmd *cobra.Command, args []string) error {
 	// Sort by name, for presentation purposes.
 	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
 
-	c := &Columnizer{}
-	c.StringColumn("ROLE NAME", func(i int) string { return roles[i].Name })
-	c.Print(roles)
+	out := make([]roleOutput, len(roles))
+	for i, r := range roles {
+		out[i] = newRoleOutput(r)
+	}
 
-	return nil
+	return printOutput(out, func() {
+		c := &Columnizer{}
+		c.StringColumn("ROLE NAME", func(i int) string { return roles[i].Name })
+		c.Print(roles)
+	})
 }
@@ -51,8 +51,10 @@ func GetRoleCmd() *cobra.Command {
 		Long:  roleLong,
 	}
 
+	cmd.AddCommand(GetRoleCloneCmd())
 	cmd.AddCommand(GetRoleCreateCmd())
 	cmd.AddCommand(GetRoleDeleteCmd())
+	cmd.AddCommand(GetRoleDiffCmd())
 	cmd.AddCommand(GetRoleGrantCmd())
 	cmd.AddCommand(GetRoleInfoCmd())
 	cmd.AddCommand(GetRoleListCmd())
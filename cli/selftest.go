@@ -0,0 +1,120 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data/rest"
+	"github.com/spf13/cobra"
+)
+
+const (
+	selftestUse   = "selftest"
+	selftestShort = "Run a command through the entire Gort pipeline"
+	selftestLong  = `Run a command through the entire Gort pipeline.
+
+Runs a trivial built-in command (the default bundle's version command) through
+the relay and a worker, renders its output with the template engine, and
+reports how long each stage took. If an adapter and channel are given, the
+channel is looked up through the adapter first and the output is delivered
+there; otherwise the chat provider isn't contacted.
+
+Use this to validate that a deployment works end to end, and to measure its
+baseline latency.`
+	selftestUsage = `Usage:
+  gort selftest [flags]
+
+Flags:
+  -a, --adapter string   The adapter to deliver output through
+  -c, --channel string   The ID of the channel to deliver output to
+  -h, --help             Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortSelftestAdapter string
+	flagGortSelftestChannel string
+)
+
+// GetSelftestCmd is a command
+func GetSelftestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   selftestUse,
+		Short: selftestShort,
+		Long:  selftestLong,
+		RunE:  selftestCmd,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&flagGortSelftestAdapter, "adapter", "a", "", "The adapter to deliver output through")
+	cmd.Flags().StringVarP(&flagGortSelftestChannel, "channel", "c", "", "The ID of the channel to deliver output to")
+
+	cmd.SetUsageTemplate(selftestUsage)
+
+	return cmd
+}
+
+func selftestCmd(cmd *cobra.Command, args []string) error {
+	if (flagGortSelftestAdapter == "") != (flagGortSelftestChannel == "") {
+		return fmt.Errorf("--adapter and --channel must be used together")
+	}
+
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	result, err := gortClient.SelfTest(rest.SelfTestRequest{
+		Adapter: flagGortSelftestAdapter,
+		Channel: flagGortSelftestChannel,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = printOutput(result, func() {
+		stages := result.Stages
+		c := &Columnizer{}
+		c.StringColumn("STAGE", func(i int) string { return stages[i].Name })
+		c.StringColumn("DURATION", func(i int) string { return stages[i].Duration.Round(time.Millisecond).String() })
+		c.StringColumn("ERROR", func(i int) string { return stages[i].Error })
+		c.Print(stages)
+
+		fmt.Println()
+		fmt.Printf("Total: %s\n", result.Total.Round(time.Millisecond))
+		if len(result.Output) > 0 {
+			fmt.Printf("Output: %s\n", strings.Join(result.Output, " "))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if !result.Success {
+		return fmt.Errorf("self-test failed")
+	}
+
+	return nil
+}
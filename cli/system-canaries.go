@@ -0,0 +1,107 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	systemCanariesUse   = "canaries"
+	systemCanariesShort = "Show the recent results of the configured canaries"
+	systemCanariesLong  = `Show the recent results of the configured canaries.
+
+Canaries are commands, defined in the global.canaries section of the Gort
+configuration, that are run through the entire command pipeline on a schedule
+to continuously verify that it's working. For each canary this lists when it
+last ran, how long it took, how many times it has succeeded and failed, and
+whether it's currently alerting.
+
+Results are kept in memory by the controller, so they're reset when it
+restarts.`
+	systemCanariesUsage = `Usage:
+  gort system canaries [flags]
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetSystemCanariesCmd is a command
+func GetSystemCanariesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   systemCanariesUse,
+		Short: systemCanariesShort,
+		Long:  systemCanariesLong,
+		RunE:  systemCanariesCmd,
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.SetUsageTemplate(systemCanariesUsage)
+
+	return cmd
+}
+
+func systemCanariesCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := gortClient.CanaryStatuses()
+	if err != nil {
+		return err
+	}
+
+	return printOutput(statuses, func() {
+		c := &Columnizer{}
+		c.StringColumn("NAME", func(i int) string { return statuses[i].Name })
+		c.StringColumn("COMMAND", func(i int) string { return statuses[i].Command })
+		c.StringColumn("LAST RUN", func(i int) string {
+			if statuses[i].LastRun.IsZero() {
+				return "never"
+			}
+			return statuses[i].LastRun.Local().Format(time.Stamp)
+		})
+		c.StringColumn("DURATION", func(i int) string { return formatRequestDuration(statuses[i].LastDuration) })
+		c.StringColumn("OK/FAILED", func(i int) string {
+			return strconv.Itoa(statuses[i].Successes) + "/" + strconv.Itoa(statuses[i].Failures)
+		})
+		c.StringColumn("STATUS", func(i int) string {
+			switch {
+			case statuses[i].Alerting:
+				return "alerting"
+			case statuses[i].ConsecutiveFailures > 0:
+				return "failing"
+			case statuses[i].LastRun.IsZero():
+				return "pending"
+			default:
+				return "ok"
+			}
+		})
+		c.StringColumn("LAST ERROR", func(i int) string { return statuses[i].LastError })
+		c.Print(statuses)
+	})
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"strconv"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	systemFeaturesUse   = "features"
+	systemFeaturesShort = "Show the feature flags and whether each is enabled"
+	systemFeaturesLong  = `Show the feature flags and whether each is enabled.
+
+Feature flags gate experimental capabilities that aren't yet enabled by
+default. They're set in the features section of the Gort configuration; any
+flag that isn't set there takes its default value.`
+	systemFeaturesUsage = `Usage:
+  gort system features [flags]
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetSystemFeaturesCmd is a command
+func GetSystemFeaturesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   systemFeaturesUse,
+		Short: systemFeaturesShort,
+		Long:  systemFeaturesLong,
+		RunE:  systemFeaturesCmd,
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.SetUsageTemplate(systemFeaturesUsage)
+
+	return cmd
+}
+
+func systemFeaturesCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	flags, err := gortClient.FeatureFlags()
+	if err != nil {
+		return err
+	}
+
+	return printOutput(flags, func() {
+		c := &Columnizer{}
+		c.StringColumn("NAME", func(i int) string { return flags[i].Name })
+		c.StringColumn("ENABLED", func(i int) string { return strconv.FormatBool(flags[i].Enabled) })
+		c.StringColumn("DEFAULT", func(i int) string { return strconv.FormatBool(flags[i].Default) })
+		c.StringColumn("DESCRIPTION", func(i int) string { return flags[i].Description })
+		c.Print(flags)
+	})
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	systemStatusUse   = "status"
+	systemStatusShort = "Show the status of the Gort controller cluster"
+	systemStatusLong  = `Show the status of the Gort controller cluster.
+
+Lists each controller instance in the cluster, along with the adapters it
+owns and the number of command requests it's currently executing. The
+current leader is marked with an asterisk.`
+	systemStatusUsage = `Usage:
+  gort system status [flags]
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetSystemStatusCmd is a command
+func GetSystemStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   systemStatusUse,
+		Short: systemStatusShort,
+		Long:  systemStatusLong,
+		RunE:  systemStatusCmd,
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.SetUsageTemplate(systemStatusUsage)
+
+	return cmd
+}
+
+func systemStatusCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	status, err := gortClient.ClusterStatus()
+	if err != nil {
+		return err
+	}
+
+	members := status.Members
+
+	c := &Columnizer{}
+	c.StringColumn("NODE", func(i int) string {
+		if members[i].Leader {
+			return members[i].Name + " *"
+		}
+		return members[i].Name
+	})
+	c.StringColumn("VERSION", func(i int) string { return members[i].Version })
+	c.StringColumn("STARTED", func(i int) string { return members[i].StartedAt.Local().Format("2006-01-02 15:04:05") })
+	c.IntColumn("IN-FLIGHT", func(i int) int { return int(members[i].InFlight) })
+	c.StringColumn("ADAPTERS", func(i int) string { return strings.Join(members[i].Adapters, ", ") })
+	c.Print(members)
+
+	fmt.Printf("\nLeader: %s\n", status.Leader)
+
+	return nil
+}
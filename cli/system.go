@@ -0,0 +1,42 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	systemUse   = "system"
+	systemShort = "Report on the state of the Gort system"
+	systemLong  = "Report on the state of the Gort system."
+)
+
+// GetSystemCmd system
+func GetSystemCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   systemUse,
+		Short: systemShort,
+		Long:  systemLong,
+	}
+
+	cmd.AddCommand(GetSystemCanariesCmd())
+	cmd.AddCommand(GetSystemFeaturesCmd())
+	cmd.AddCommand(GetSystemStatusCmd())
+
+	return cmd
+}
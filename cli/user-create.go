@@ -27,14 +27,23 @@ import (
 const (
 	userCreateUse   = "create"
 	userCreateShort = "Create a new user"
-	userCreateLong  = "Create a new user."
+	userCreateLong  = `Create a new user.
+
+Use --service-account to create a user for automation like schedulers,
+webhooks, and CI. Service accounts have no password and can't be mapped to
+chat users; use 'gort user token' to issue them a token instead.`
 	userCreateUsage = `Usage:
   gort user create [flags] user_name
 
 Flags:
-  -h, --help   Show this message and exit
+  -e, --email string      Email for the user (required)
+  -h, --help              Show this message and exit
+  -n, --name string       Full name of the user (required)
+  -p, --password string   Password for user (required, unless --service-account)
+  -s, --service-account   Create a service account, which has no password and authenticates only with tokens
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -43,6 +52,7 @@ var (
 	flagUserCreateEmail    string
 	flagUserCreateName     string
 	flagUserCreatePassword string
+	flagUserCreateService  bool
 )
 
 // GetUserCreateCmd is a command
@@ -57,11 +67,11 @@ func GetUserCreateCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&flagUserCreateEmail, "email", "e", "", "Email for the user (required)")
 	cmd.Flags().StringVarP(&flagUserCreateName, "name", "n", "", "Full name of the user (required)")
-	cmd.Flags().StringVarP(&flagUserCreatePassword, "password", "p", "", "Password for user (required)")
+	cmd.Flags().StringVarP(&flagUserCreatePassword, "password", "p", "", "Password for user (required, unless --service-account)")
+	cmd.Flags().BoolVarP(&flagUserCreateService, "service-account", "s", false, "Create a service account")
 
 	cmd.MarkFlagRequired("email")
 	cmd.MarkFlagRequired("name")
-	cmd.MarkFlagRequired("password")
 
 	cmd.SetUsageTemplate(userCreateUsage)
 
@@ -71,6 +81,13 @@ func GetUserCreateCmd() *cobra.Command {
 func userCreateCmd(cmd *cobra.Command, args []string) error {
 	username := args[0]
 
+	switch {
+	case flagUserCreateService && flagUserCreatePassword != "":
+		return fmt.Errorf("service accounts can't have passwords")
+	case !flagUserCreateService && flagUserCreatePassword == "":
+		return fmt.Errorf(`required flag(s) "password" not set`)
+	}
+
 	c, err := client.Connect(FlagGortProfile)
 	if err != nil {
 		return err
@@ -86,10 +103,11 @@ func userCreateCmd(cmd *cobra.Command, args []string) error {
 	}
 
 	user := rest.User{
-		Email:    flagUserCreateEmail,
-		FullName: flagUserCreateName,
-		Password: flagUserCreatePassword,
-		Username: username,
+		Email:          flagUserCreateEmail,
+		FullName:       flagUserCreateName,
+		Password:       flagUserCreatePassword,
+		Username:       username,
+		ServiceAccount: flagUserCreateService,
 	}
 
 	err = c.UserSave(user)
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data/rest"
 	"github.com/spf13/cobra"
 )
 
@@ -36,6 +37,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -77,6 +79,13 @@ func userInfoCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	out := newUserOutput(user)
+	out.Groups = groupNames(groups)
+
+	return printOutput(out, func() { printUserInfo(user, groups) })
+}
+
+func printUserInfo(user rest.User, groups []rest.Group) {
 	const format = `Name       %s
 Full Name  %s
 Email      %s
@@ -91,7 +100,10 @@ Groups     %s
 		process(groupNames(groups)),
 	)
 
-	if len(user.Mappings) == 0 {
+	if user.ServiceAccount {
+		fmt.Println("This user is a service account. It can't be mapped to chat provider users, and\n" +
+			"authenticates with tokens issued by 'gort user token'.")
+	} else if len(user.Mappings) == 0 {
 		fmt.Println("This user has no chat provider mappings. Use 'gort user map' to map a Gort\n" +
 			"user to one or more chat provider IDs.")
 	} else {
@@ -106,8 +118,6 @@ Groups     %s
 		c.StringColumn("ID MAPPING", func(i int) string { return user.Mappings[keys[i]] })
 		c.Print(keys)
 	}
-
-	return nil
 }
 
 func process(i interface{}) string {
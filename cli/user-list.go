@@ -34,6 +34,7 @@ Flags:
   -h, --help   Show this message and exit
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
@@ -67,11 +68,16 @@ func userListCmd(cmd *cobra.Command, args []string) error {
 	// Sort by name, for presentation purposes.
 	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
 
-	c := &Columnizer{}
-	c.StringColumn("USER NAME", func(i int) string { return users[i].Username })
-	c.StringColumn("FULL NAME", func(i int) string { return users[i].FullName })
-	c.StringColumn("EMAIL", func(i int) string { return users[i].Email })
-	c.Print(users)
+	out := make([]userOutput, len(users))
+	for i, u := range users {
+		out[i] = newUserOutput(u)
+	}
 
-	return nil
+	return printOutput(out, func() {
+		c := &Columnizer{}
+		c.StringColumn("USER NAME", func(i int) string { return users[i].Username })
+		c.StringColumn("FULL NAME", func(i int) string { return users[i].FullName })
+		c.StringColumn("EMAIL", func(i int) string { return users[i].Email })
+		c.Print(users)
+	})
 }
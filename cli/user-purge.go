@@ -0,0 +1,140 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	userPurgeUse   = "purge"
+	userPurgeShort = "Export and purge all personal data for a user"
+	userPurgeLong  = `Permanently delete a user and all of their personal data.
+
+The user's account, chat adapter mappings, group memberships, and tokens are
+deleted. Their command request records are kept, so that aggregate usage
+statistics are preserved, but are stripped of personal data and attributed
+to a random pseudonym instead of the username.
+
+Before anything is deleted, all of the user's data is exported as JSON to
+the file specified by --export (by default, <user_name>-export.json), and
+you're asked to confirm the purge by typing the username.
+
+This operation cannot be undone.
+`
+	userPurgeUsage = `Usage:
+  gort user purge [flags] user_name
+
+Flags:
+  -e, --export string   The file to write the exported data to (default "<user_name>-export.json")
+  -h, --help            Show this message and exit
+  -y, --yes             Don't prompt for confirmation
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagGortUserPurgeExport string
+	flagGortUserPurgeYes    bool
+)
+
+// GetUserPurgeCmd is a command
+func GetUserPurgeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   userPurgeUse,
+		Short: userPurgeShort,
+		Long:  userPurgeLong,
+		RunE:  userPurgeCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().StringVarP(&flagGortUserPurgeExport, "export", "e", "", "The file to write the exported data to")
+	cmd.Flags().BoolVarP(&flagGortUserPurgeYes, "yes", "y", false, "Don't prompt for confirmation")
+
+	cmd.SetUsageTemplate(userPurgeUsage)
+
+	return cmd
+}
+
+func userPurgeCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	username := args[0]
+
+	export, err := gortClient.UserExport(username)
+	if err != nil {
+		return err
+	}
+
+	filename := flagGortUserPurgeExport
+	if filename == "" {
+		filename = username + "-export.json"
+	}
+
+	// Never overwrite an existing file: it may be an earlier export.
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(export)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported data for user %s to %s:\n", username, filename)
+	fmt.Printf("  Adapter mappings: %d\n", len(export.User.Mappings))
+	fmt.Printf("  Groups:           %d\n", len(export.Groups))
+	fmt.Printf("  Request records:  %d\n", len(export.Requests))
+
+	if !flagGortUserPurgeYes {
+		fmt.Printf("\nThis will permanently purge user %s. Type the username to confirm: ", username)
+
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != username {
+			return fmt.Errorf("purge not confirmed; no data was deleted")
+		}
+	}
+
+	result, err := gortClient.UserPurge(username)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("User %s purged; %d request records were anonymized as %s.\n",
+		result.Username, result.RequestsAnonymized, result.Pseudonym)
+
+	return nil
+}
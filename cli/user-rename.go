@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	userRenameUse   = "rename"
+	userRenameShort = "Change an existing user's username"
+	userRenameLong  = `Change an existing user's username.
+
+The user keeps their group memberships, adapter mappings, token, user-layer
+configurations and option defaults, and request history.`
+	userRenameUsage = `Usage:
+  gort user rename [flags] user_name new_user_name
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetUserRenameCmd is a command
+func GetUserRenameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   userRenameUse,
+		Short: userRenameShort,
+		Long:  userRenameLong,
+		RunE:  userRenameCmd,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.SetUsageTemplate(userRenameUsage)
+
+	return cmd
+}
+
+func userRenameCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	username, newname := args[0], args[1]
+
+	fmt.Printf("Renaming user %s to %s... ", username, newname)
+
+	err = gortClient.UserRename(username, newname)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Successful.")
+
+	return nil
+}
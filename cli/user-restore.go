@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	userRestoreUse   = "restore"
+	userRestoreShort = "Restore a recently deleted user"
+	userRestoreLong  = `Restore a recently deleted user, along with their group memberships.
+
+Deleted users are retained for a limited time (7 days by default) before
+being removed permanently. The restored user will need a new token.`
+	userRestoreUsage = `Usage:
+  gort user restore [flags] user_name
+
+Flags:
+  -h, --help   Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+// GetUserRestoreCmd is a command
+func GetUserRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   userRestoreUse,
+		Short: userRestoreShort,
+		Long:  userRestoreLong,
+		RunE:  userRestoreCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.SetUsageTemplate(userRestoreUsage)
+
+	return cmd
+}
+
+func userRestoreCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	username := args[0]
+
+	fmt.Printf("Restoring user %s... ", username)
+
+	err = gortClient.UserRestore(username)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Successful.")
+
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/getgort/gort/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	userTokenUse   = "token"
+	userTokenShort = "Issue a token to a service account"
+	userTokenLong  = `Issue a token to a service account.
+
+Service accounts have no password, so automation like schedulers, webhooks,
+and CI authenticates as a service account with a token issued by this command,
+for example by setting the GORT_SERVICE_TOKEN environment variable. Any token
+the service account already has is revoked.`
+	userTokenUsage = `Usage:
+  gort user token [flags] user_name
+
+Flags:
+  -d, --duration duration   How long the token is valid (default: the server's default, 90 days)
+  -h, --help                Show this message and exit
+
+Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
+  -P, --profile string   The Gort profile within the config file to use
+`
+)
+
+var (
+	flagUserTokenDuration time.Duration
+)
+
+// GetUserTokenCmd is a command
+func GetUserTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   userTokenUse,
+		Short: userTokenShort,
+		Long:  userTokenLong,
+		RunE:  userTokenCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().DurationVarP(&flagUserTokenDuration, "duration", "d", 0, "How long the token is valid")
+
+	cmd.SetUsageTemplate(userTokenUsage)
+
+	return cmd
+}
+
+func userTokenCmd(cmd *cobra.Command, args []string) error {
+	gortClient, err := client.Connect(FlagGortProfile)
+	if err != nil {
+		return err
+	}
+
+	token, err := gortClient.UserToken(args[0], flagUserTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(token, func() {
+		fmt.Printf("Token:        %s\n", token.Token)
+		fmt.Printf("Valid Until:  %s\n", token.ValidUntil.Format(time.RFC3339))
+	})
+}
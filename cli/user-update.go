@@ -51,6 +51,7 @@ Flags:
   -p, --password string   Password for user
 
 Global Flags:
+      --output string    Output format: table, json, or yaml (default "table")
   -P, --profile string   The Gort profile within the config file to use
 `
 )
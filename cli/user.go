@@ -36,6 +36,7 @@ import (
 //   info                    Get info about a specific user by username.
 //   password-reset          Reset user password with a token.
 //   password-reset-request  Request a password reset.
+//   purge                   Export and purge all personal data for a user.
 //   update                  Updates an existing user.
 
 const (
@@ -57,6 +58,10 @@ func GetUserCmd() *cobra.Command {
 	cmd.AddCommand(GetUserInfoCmd())
 	cmd.AddCommand(GetUserListCmd())
 	cmd.AddCommand(GetUserMapCmd())
+	cmd.AddCommand(GetUserPurgeCmd())
+	cmd.AddCommand(GetUserRenameCmd())
+	cmd.AddCommand(GetUserRestoreCmd())
+	cmd.AddCommand(GetUserTokenCmd())
 	cmd.AddCommand(GetUserUpdateCmd())
 
 	return cmd
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getgort/gort/data"
+)
+
+// AliasDelete deletes an alias. An alias with an empty owner is global; an
+// owner of "-" refers to the requesting user.
+func (c *GortClient) AliasDelete(alias data.Alias) error {
+	url, err := c.aliasURL(alias)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest("DELETE", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// AliasList returns the aliases available to the user: their own, and
+// global ones. Users with the manage_aliases permission get every alias.
+func (c *GortClient) AliasList() ([]data.Alias, error) {
+	url := fmt.Sprintf("%s/v2/aliases", c.profile.URL.String())
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return []data.Alias{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return []data.Alias{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return []data.Alias{}, err
+	}
+
+	aliases := []data.Alias{}
+	err = json.Unmarshal(body, &aliases)
+	if err != nil {
+		return []data.Alias{}, err
+	}
+
+	return aliases, nil
+}
+
+// AliasSave creates an alias, replacing any existing alias with the same
+// name and owner. An alias with an empty owner is global; an owner of "-"
+// refers to the requesting user.
+func (c *GortClient) AliasSave(alias data.Alias) error {
+	url, err := c.aliasURL(alias)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(alias)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest("PUT", url, bytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+func (c *GortClient) aliasURL(alias data.Alias) (string, error) {
+	if alias.Name == "" {
+		return "", fmt.Errorf("alias name is required")
+	}
+
+	if alias.Owner == "" {
+		return fmt.Sprintf("%s/v2/aliases/%s", c.profile.URL.String(), alias.Name), nil
+	}
+
+	return fmt.Sprintf("%s/v2/users/%s/aliases/%s", c.profile.URL.String(),
+		alias.Owner, alias.Name), nil
+}
@@ -45,6 +45,11 @@ func (c *GortClient) Authenticate() (rest.Token, error) {
 		return token, nil
 	}
 
+	// SSO profiles have no password to authenticate with.
+	if c.profile.SSO {
+		return rest.Token{}, ErrLoginRequired
+	}
+
 	endpointURL := fmt.Sprintf("%s/v2/authenticate", c.profile.URL)
 
 	postBytes, err := json.Marshal(c.profile.User())
@@ -52,20 +57,101 @@ func (c *GortClient) Authenticate() (rest.Token, error) {
 		return rest.Token{}, gerrs.Wrap(gerrs.ErrMarshal, err)
 	}
 
-	resp, err := c.client.Post(endpointURL, "application/json", bytes.NewBuffer(postBytes))
+	resp, err := c.postUnauthenticated(endpointURL, postBytes)
+	if err != nil {
+		return rest.Token{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.Token{}, getResponseError(resp)
+	}
+
+	return c.saveHostToken(resp)
+}
+
+// AuthenticateOIDC logs in with the server's OpenID Connect identity
+// provider, using the device authorization flow: prompt is called with the
+// URL that the user must visit, and the code they must enter there, and the
+// server is then polled until the user has done so. As with Authenticate,
+// the resulting token is saved.
+func (c *GortClient) AuthenticateOIDC(prompt func(rest.DeviceAuthorization)) (rest.Token, error) {
+	endpointURL := fmt.Sprintf("%s/v2/auth/oidc/device", c.profile.URL)
+
+	resp, err := c.postUnauthenticated(endpointURL, []byte{})
+	if err != nil {
+		return rest.Token{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.Token{}, getResponseError(resp)
+	}
+
+	var da rest.DeviceAuthorization
+	if err := json.NewDecoder(resp.Body).Decode(&da); err != nil {
+		return rest.Token{}, gerrs.Wrap(gerrs.ErrUnmarshal, err)
+	}
+
+	prompt(da)
+
+	postBytes, err := json.Marshal(rest.DeviceAuthorization{DeviceCode: da.DeviceCode})
+	if err != nil {
+		return rest.Token{}, gerrs.Wrap(gerrs.ErrMarshal, err)
+	}
+
+	endpointURL = fmt.Sprintf("%s/v2/auth/oidc/device/token", c.profile.URL)
+	interval := time.Duration(da.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(da.ExpiresIn) * time.Second)
+
+	for {
+		time.Sleep(interval)
+
+		resp, err := c.postUnauthenticated(endpointURL, postBytes)
+		if err != nil {
+			return rest.Token{}, err
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			defer resp.Body.Close()
+			return c.saveHostToken(resp)
+
+		// The user hasn't finished logging in yet.
+		case http.StatusPreconditionRequired:
+		case http.StatusTooManyRequests:
+			interval += 5 * time.Second
+
+		default:
+			defer resp.Body.Close()
+			return rest.Token{}, getResponseError(resp)
+		}
+
+		resp.Body.Close()
+
+		if da.ExpiresIn > 0 && time.Now().After(deadline) {
+			return rest.Token{}, ErrLoginExpired
+		}
+	}
+}
+
+// postUnauthenticated posts a JSON body to an endpoint that doesn't require
+// a token.
+func (c *GortClient) postUnauthenticated(endpointURL string, body []byte) (*http.Response, error) {
+	resp, err := c.client.Post(endpointURL, "application/json", bytes.NewBuffer(body))
 	switch {
 	case err == nil:
+		return resp, nil
 	case strings.Contains(err.Error(), "certificate"):
-		return rest.Token{}, fmt.Errorf("self-signed certificate detected: use --allow-insecure to proceed (not recommended)")
+		return nil, fmt.Errorf("self-signed certificate detected: use --allow-insecure to proceed (not recommended)")
 	default:
-		return rest.Token{}, gerrs.Wrap(ErrConnectionFailed, err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		bytes, _ := ioutil.ReadAll(resp.Body)
-		return rest.Token{}, fmt.Errorf(string(bytes))
+		return nil, gerrs.Wrap(ErrConnectionFailed, err)
 	}
+}
 
+// saveHostToken reads a token from a successful response, and saves it to
+// disk for the current server.
+func (c *GortClient) saveHostToken(resp *http.Response) (rest.Token, error) {
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return rest.Token{}, gerrs.Wrap(ErrResponseReadFailure, err)
@@ -77,6 +163,8 @@ func (c *GortClient) Authenticate() (rest.Token, error) {
 		return rest.Token{}, gerrs.Wrap(gerrs.ErrUnmarshal, err)
 	}
 
+	c.token = &token
+
 	// Save the token to disk
 	file, err := c.getGortTokenFilename()
 	if err != nil {
@@ -158,8 +246,7 @@ func (c *GortClient) Bootstrap(overwrite bool) (rest.User, error) {
 		err := fmt.Errorf("internal server error; check the server logs for details")
 		return rest.User{}, err
 	default:
-		bytes, _ := ioutil.ReadAll(resp.Body)
-		return rest.User{}, fmt.Errorf(string(bytes))
+		return rest.User{}, getResponseError(resp)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
@@ -21,19 +21,25 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"sort"
 
 	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
 )
 
 // BundleDisable comments to be written...
 func (c *GortClient) BundleDisable(bundlename string) error {
-	return c.doBundleEnable(bundlename, "-", false)
+	_, err := c.doBundleEnable(bundlename, "-", false)
+	return err
 }
 
-// BundleEnable comments to be written...
-func (c *GortClient) BundleEnable(bundlename string, version string) error {
-	return c.doBundleEnable(bundlename, version, true)
+// BundleEnable enables a bundle version, and returns any warnings about it
+// reported by the server, such as triggers that collide with those of other
+// enabled bundles.
+func (c *GortClient) BundleEnable(bundlename string, version string) ([]rest.BundleWarning, error) {
+	result, err := c.doBundleEnable(bundlename, version, true)
+	return result.Warnings, err
 }
 
 // BundleExists simply returns true if a bundle exists with the
@@ -168,12 +174,78 @@ func (c *GortClient) BundleListVersions(bundlename string) ([]data.Bundle, error
 	return bundles, nil
 }
 
-// BundleInstall comments to be written...
-func (c *GortClient) BundleInstall(bundle data.Bundle) error {
+// BundleInstall installs a bundle version, and returns any warnings found
+// by the server's analysis of the bundle.
+func (c *GortClient) BundleInstall(bundle data.Bundle) (rest.BundleInstallResult, error) {
 	url := fmt.Sprintf("%s/v2/bundles/%s/versions/%s",
 		c.profile.URL.String(), bundle.Name, bundle.Version)
 
 	bytes, err := json.Marshal(bundle)
+	if err != nil {
+		return rest.BundleInstallResult{}, err
+	}
+
+	resp, err := c.doRequest("PUT", url, bytes)
+	if err != nil {
+		return rest.BundleInstallResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.BundleInstallResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.BundleInstallResult{}, err
+	}
+
+	result := rest.BundleInstallResult{Name: bundle.Name, Version: bundle.Version}
+
+	// Older servers don't return a result.
+	if len(body) == 0 {
+		return result, nil
+	}
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return rest.BundleInstallResult{}, err
+	}
+
+	return result, nil
+}
+
+// BundleReview describes a bundle version's review state and its changes
+// from the currently enabled version of the bundle.
+func (c *GortClient) BundleReview(bundlename string, version string) (rest.BundleReviewDiff, error) {
+	url := fmt.Sprintf("%s/v2/bundles/%s/versions/%s/review",
+		c.profile.URL.String(), bundlename, version)
+
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return rest.BundleReviewDiff{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.BundleReviewDiff{}, getResponseError(resp)
+	}
+
+	diff := rest.BundleReviewDiff{}
+	if err := json.NewDecoder(resp.Body).Decode(&diff); err != nil {
+		return rest.BundleReviewDiff{}, err
+	}
+
+	return diff, nil
+}
+
+// BundleReviewDecide approves or rejects a bundle version that's pending
+// review. It can't be used by the user that installed the version.
+func (c *GortClient) BundleReviewDecide(bundlename string, version string, approve bool, comment string) error {
+	url := fmt.Sprintf("%s/v2/bundles/%s/versions/%s/review",
+		c.profile.URL.String(), bundlename, version)
+
+	bytes, err := json.Marshal(rest.BundleReviewDecision{Approve: approve, Comment: comment})
 	if err != nil {
 		return err
 	}
@@ -191,6 +263,91 @@ func (c *GortClient) BundleInstall(bundle data.Bundle) error {
 	return nil
 }
 
+// BundleUpgrade installs bundle if it isn't already installed. If enable is
+// true the new version is enabled, and if retain is greater than zero all but
+// the retain newest versions of the bundle are uninstalled. The enabled
+// version is never uninstalled.
+func (c *GortClient) BundleUpgrade(bundle data.Bundle, enable bool, retain int) (rest.BundleUpgradeResult, error) {
+	url := fmt.Sprintf("%s/v2/bundles/%s/versions/%s/upgrade?enable=%v&retain=%d",
+		c.profile.URL.String(), bundle.Name, bundle.Version, enable, retain)
+
+	bytes, err := json.Marshal(bundle)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	resp, err := c.doRequest("PUT", url, bytes)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.BundleUpgradeResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	result := rest.BundleUpgradeResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	return result, nil
+}
+
+// BundleOrphanList returns the role permissions for the named bundle that
+// aren't declared by any of its installed versions. Any versions in exclude
+// are treated as already uninstalled, which allows the effect of an uninstall
+// to be previewed.
+func (c *GortClient) BundleOrphanList(bundlename string, exclude ...string) ([]rest.OrphanedPermission, error) {
+	query := url.Values{"exclude": exclude}
+
+	url := fmt.Sprintf("%s/v2/bundles/%s/orphans?%s",
+		c.profile.URL.String(), bundlename, query.Encode())
+
+	return c.doBundleOrphans("GET", url)
+}
+
+// BundleOrphanDelete revokes all role permissions for the named bundle that
+// aren't declared by any of its installed versions. It returns the
+// permissions that were revoked.
+func (c *GortClient) BundleOrphanDelete(bundlename string) ([]rest.OrphanedPermission, error) {
+	url := fmt.Sprintf("%s/v2/bundles/%s/orphans",
+		c.profile.URL.String(), bundlename)
+
+	return c.doBundleOrphans("DELETE", url)
+}
+
+func (c *GortClient) doBundleOrphans(method, url string) ([]rest.OrphanedPermission, error) {
+	resp, err := c.doRequest(method, url, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []rest.OrphanedPermission{}
+	err = json.Unmarshal(body, &orphans)
+	if err != nil {
+		return nil, err
+	}
+
+	return orphans, nil
+}
+
 // BundleUninstall comments to be written...
 func (c *GortClient) BundleUninstall(bundlename string, version string) error {
 	url := fmt.Sprintf("%s/v2/bundles/%s/versions/%s",
@@ -211,7 +368,7 @@ func (c *GortClient) BundleUninstall(bundlename string, version string) error {
 
 // doBundleEnable allows a bundle to be enabled or disabled. The value of
 // version is ignored when disabling a bundle.
-func (c *GortClient) doBundleEnable(bundlename string, version string, enabled bool) error {
+func (c *GortClient) doBundleEnable(bundlename string, version string, enabled bool) (rest.BundleEnableResult, error) {
 	url := fmt.Sprintf("%s/v2/bundles/%s/versions/%s?enabled=%v",
 		c.profile.URL.String(), bundlename, version, enabled)
 
@@ -219,14 +376,26 @@ func (c *GortClient) doBundleEnable(bundlename string, version string, enabled b
 
 	resp, err := c.doRequest("PATCH", url, []byte{})
 	if err != nil {
-		return err
+		return rest.BundleEnableResult{}, err
 	}
 	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return nil
-	default:
-		return getResponseError(resp)
+	if resp.StatusCode != http.StatusOK {
+		return rest.BundleEnableResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.BundleEnableResult{}, err
+	}
+
+	result := rest.BundleEnableResult{}
+
+	// Disabling, and older servers, don't return a result.
+	if len(body) == 0 {
+		return result, nil
 	}
+
+	err = json.Unmarshal(body, &result)
+	return result, err
 }
@@ -26,6 +26,18 @@ import (
 	"github.com/getgort/gort/data"
 )
 
+// configURL returns the URL of a bundle's dynamic configuration endpoint
+// for the given layer, owner, and key. If bundle is the "*" wildcard, the
+// cross-bundle endpoint is used instead.
+func (c *GortClient) configURL(bundle string, elems ...string) string {
+	base := fmt.Sprintf("%s/v2/bundles/%s/config", c.profile.URL.String(), bundle)
+	if bundle == "*" {
+		base = fmt.Sprintf("%s/v2/configs/%s", c.profile.URL.String(), bundle)
+	}
+
+	return strings.TrimRight(base+"/"+strings.Join(elems, "/"), "/")
+}
+
 // DynamicConfigurationDelete
 func (c *GortClient) DynamicConfigurationDelete(bundle string, layer data.ConfigurationLayer, owner, key string) error {
 	switch {
@@ -45,7 +57,7 @@ func (c *GortClient) DynamicConfigurationDelete(bundle string, layer data.Config
 		owner = "-"
 	}
 
-	url := fmt.Sprintf("%s/v2/configs/%s/%s/%s/%s", c.profile.URL.String(), bundle, layer, owner, key)
+	url := c.configURL(bundle, string(layer), owner, key)
 	resp, err := c.doRequest("DELETE", url, []byte{})
 	if err != nil {
 		return err
@@ -61,7 +73,7 @@ func (c *GortClient) DynamicConfigurationDelete(bundle string, layer data.Config
 
 // DynamicConfigurationExists
 func (c *GortClient) DynamicConfigurationExists(bundle string, layer data.ConfigurationLayer, owner, key string) (bool, error) {
-	url := fmt.Sprintf("%s/v2/configs/%s/%s/%s/%s", c.profile.URL.String(), bundle, layer, owner, key)
+	url := c.configURL(bundle, string(layer), owner, key)
 	resp, err := c.doRequest("GET", url, []byte{})
 	if err != nil {
 		return false, err
@@ -88,8 +100,7 @@ func (c *GortClient) DynamicConfigurationList(bundle string, layer data.Configur
 		}
 	}
 
-	url := fmt.Sprintf("%s/v2/configs/%s/%s/%s/%s", c.profile.URL.String(), p(bundle), p(string(layer)), p(owner), p(key))
-	url = strings.TrimRight(url, "/")
+	url := c.configURL(p(bundle), p(string(layer)), p(owner), p(key))
 	resp, err := c.doRequest("GET", url, []byte{})
 	if err != nil {
 		return []data.DynamicConfiguration{}, err
@@ -133,7 +144,7 @@ func (c *GortClient) DynamicConfigurationSave(config data.DynamicConfiguration)
 		config.Owner = "-"
 	}
 
-	url := fmt.Sprintf("%s/v2/configs/%s/%s/%s/%s", c.profile.URL.String(), config.Bundle, config.Layer, config.Owner, config.Key)
+	url := c.configURL(config.Bundle, string(config.Layer), config.Owner, config.Key)
 
 	bytes, err := json.Marshal(config)
 	if err != nil {
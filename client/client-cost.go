@@ -0,0 +1,61 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/getgort/gort/data"
+)
+
+// CostList retrieves the compute used by command executions, totalled by
+// month, bundle, and group. If month (like "2021-06") is empty, every month
+// is returned.
+func (c *GortClient) CostList(month string) ([]data.CostRecord, error) {
+	query := url.Values{}
+	if month != "" {
+		query.Set("month", month)
+	}
+
+	url := fmt.Sprintf("%s/v2/costs?%s", c.profile.URL.String(), query.Encode())
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []data.CostRecord{}
+	err = json.Unmarshal(body, &records)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getgort/gort/data"
+)
+
+// DeadLetterDelete deletes an undeliverable response without redelivering
+// it.
+func (c *GortClient) DeadLetterDelete(id int64) error {
+	url := fmt.Sprintf("%s/v2/deadletters/%d", c.profile.URL.String(), id)
+	resp, err := c.doRequest("DELETE", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// DeadLetterList retrieves all undeliverable responses, oldest first.
+func (c *GortClient) DeadLetterList() ([]data.DeadLetter, error) {
+	url := fmt.Sprintf("%s/v2/deadletters", c.profile.URL.String())
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := []data.DeadLetter{}
+	err = json.Unmarshal(body, &letters)
+	if err != nil {
+		return nil, err
+	}
+
+	return letters, nil
+}
+
+// DeadLetterRedeliver retries delivery of an undeliverable response. If it
+// succeeds the dead letter is deleted; otherwise an error is returned and
+// the dead letter is kept.
+func (c *GortClient) DeadLetterRedeliver(id int64) error {
+	url := fmt.Sprintf("%s/v2/deadletters/%d/redeliver", c.profile.URL.String(), id)
+	resp, err := c.doRequest("POST", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getgort/gort/data"
+)
+
+// OptionDefaultDelete deletes the default value of a command option at the
+// given layer.
+func (c *GortClient) OptionDefaultDelete(def data.OptionDefault) error {
+	url, err := c.optionDefaultURL(def)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest("DELETE", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// OptionDefaultList returns all option defaults, at all layers, for a
+// bundle command.
+func (c *GortClient) OptionDefaultList(bundle, command string) ([]data.OptionDefault, error) {
+	url := fmt.Sprintf("%s/v2/defaults/%s/%s", c.profile.URL.String(), bundle, command)
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return []data.OptionDefault{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return []data.OptionDefault{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return []data.OptionDefault{}, err
+	}
+
+	defaults := []data.OptionDefault{}
+	err = json.Unmarshal(body, &defaults)
+	if err != nil {
+		return []data.OptionDefault{}, err
+	}
+
+	return defaults, nil
+}
+
+// OptionDefaultSave sets the default value of a command option at the
+// given layer, replacing any existing value.
+func (c *GortClient) OptionDefaultSave(def data.OptionDefault) error {
+	url, err := c.optionDefaultURL(def)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest("PUT", url, bytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+func (c *GortClient) optionDefaultURL(def data.OptionDefault) (string, error) {
+	switch {
+	case def.Bundle == "":
+		return "", fmt.Errorf("option default bundle is required")
+	case def.Command == "":
+		return "", fmt.Errorf("option default command is required")
+	case def.Layer == data.ConfigurationLayer(""):
+		return "", fmt.Errorf("option default layer is required")
+	case data.ValidateOptionDefaultLayer(def.Layer) != nil:
+		return "", data.ValidateOptionDefaultLayer(def.Layer)
+	case def.Owner == "" && def.Layer != data.LayerBundle:
+		return "", fmt.Errorf("option default owner is required for layer %s", def.Layer)
+	case def.Option == "":
+		return "", fmt.Errorf("option default option is required")
+	}
+
+	owner := def.Owner
+	if owner == "" {
+		owner = "-"
+	}
+
+	return fmt.Sprintf("%s/v2/defaults/%s/%s/%s/%s/%s", c.profile.URL.String(),
+		def.Bundle, def.Command, def.Layer, owner, def.Option), nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getgort/gort/data/rest"
+)
+
+// Doctor checks the data store for dangling references. If fix is true,
+// each problem found is also repaired.
+func (c *GortClient) Doctor(fix bool) (rest.DoctorReport, error) {
+	method := "GET"
+	if fix {
+		method = "POST"
+	}
+
+	url := fmt.Sprintf("%s/v2/doctor", c.profile.URL.String())
+	resp, err := c.doRequest(method, url, []byte{})
+	if err != nil {
+		return rest.DoctorReport{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.DoctorReport{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.DoctorReport{}, err
+	}
+
+	report := rest.DoctorReport{}
+	err = json.Unmarshal(body, &report)
+	if err != nil {
+		return rest.DoctorReport{}, err
+	}
+
+	return report, nil
+}
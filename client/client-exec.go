@@ -0,0 +1,61 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getgort/gort/data/rest"
+)
+
+// Exec executes a command, exactly as it would be typed in chat, on behalf of
+// the authenticated user, and returns its output. It's intended for
+// automation authenticating as a service account.
+func (c *GortClient) Exec(command string) (rest.ExecResult, error) {
+	url := fmt.Sprintf("%s/v2/exec", c.profile.URL.String())
+
+	bytes, err := json.Marshal(rest.ExecRequest{Command: command})
+	if err != nil {
+		return rest.ExecResult{}, err
+	}
+
+	resp, err := c.doRequest("POST", url, bytes)
+	if err != nil {
+		return rest.ExecResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.ExecResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.ExecResult{}, err
+	}
+
+	result := rest.ExecResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return rest.ExecResult{}, err
+	}
+
+	return result, nil
+}
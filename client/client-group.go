@@ -175,6 +175,25 @@ func (c *GortClient) GroupMemberList(groupname string) ([]rest.User, error) {
 	return users, nil
 }
 
+// GroupRestore restores a recently deleted group, along with its members and
+// roles. Only groups deleted within the server's retention period can be
+// restored.
+func (c *GortClient) GroupRestore(groupname string) error {
+	url := fmt.Sprintf("%s/v2/groups/%s/restore", c.profile.URL.String(), groupname)
+
+	resp, err := c.doRequest("PUT", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
 // GroupSave comments to be written...
 func (c *GortClient) GroupSave(group rest.Group) error {
 	url := fmt.Sprintf("%s/v2/groups/%s", c.profile.URL.String(), group.Name)
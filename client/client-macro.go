@@ -0,0 +1,123 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getgort/gort/data"
+)
+
+// MacroDelete deletes a macro.
+func (c *GortClient) MacroDelete(macro data.Macro) error {
+	url, err := c.macroURL(macro)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest("DELETE", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// MacroList returns the macros available to the user: their own, and those
+// of the groups they belong to. Users with the manage_macros permission get
+// every macro.
+func (c *GortClient) MacroList() ([]data.Macro, error) {
+	url := fmt.Sprintf("%s/v2/macros", c.profile.URL.String())
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return []data.Macro{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return []data.Macro{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return []data.Macro{}, err
+	}
+
+	macros := []data.Macro{}
+	err = json.Unmarshal(body, &macros)
+	if err != nil {
+		return []data.Macro{}, err
+	}
+
+	return macros, nil
+}
+
+// MacroSave creates a macro, replacing any existing macro with the same
+// name, layer, and owner.
+func (c *GortClient) MacroSave(macro data.Macro) error {
+	url, err := c.macroURL(macro)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(macro)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest("PUT", url, bytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+func (c *GortClient) macroURL(macro data.Macro) (string, error) {
+	switch {
+	case macro.Name == "":
+		return "", fmt.Errorf("macro name is required")
+	case macro.Layer == data.ConfigurationLayer(""):
+		return "", fmt.Errorf("macro layer is required")
+	case data.ValidateMacroLayer(macro.Layer) != nil:
+		return "", data.ValidateMacroLayer(macro.Layer)
+	case macro.Owner == "" && macro.Layer != data.LayerUser:
+		return "", fmt.Errorf("macro owner is required for layer %s", macro.Layer)
+	}
+
+	// An empty user-layer owner refers to the requesting user.
+	owner := macro.Owner
+	if owner == "" {
+		owner = "-"
+	}
+
+	return fmt.Sprintf("%s/v2/macros/%s/%s/%s", c.profile.URL.String(),
+		macro.Layer, owner, macro.Name), nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+// RequestGet retrieves the record of a single command request, including
+// its stage timings.
+func (c *GortClient) RequestGet(id int64) (data.RequestRecord, error) {
+	url := fmt.Sprintf("%s/v2/requests/%d", c.profile.URL.String(), id)
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return data.RequestRecord{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+
+	record := data.RequestRecord{}
+	err = json.Unmarshal(body, &record)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+
+	return record, nil
+}
+
+// RequestList retrieves the records of up to limit of the most recent
+// command requests, newest first. If limit is zero, all are returned.
+func (c *GortClient) RequestList(limit int) ([]data.RequestRecord, error) {
+	url := fmt.Sprintf("%s/v2/requests?limit=%d", c.profile.URL.String(), limit)
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []data.RequestRecord{}
+	err = json.Unmarshal(body, &records)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// RequestReplay re-executes a historical command request with identical
+// parameters. If r.BundleVersion is set, the request is replayed against that
+// version of its bundle.
+func (c *GortClient) RequestReplay(id int64, r rest.ReplayRequest) (rest.ReplayResult, error) {
+	url := fmt.Sprintf("%s/v2/requests/%d/replay", c.profile.URL.String(), id)
+
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return rest.ReplayResult{}, err
+	}
+
+	resp, err := c.doRequest("POST", url, bytes)
+	if err != nil {
+		return rest.ReplayResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.ReplayResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.ReplayResult{}, err
+	}
+
+	result := rest.ReplayResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return rest.ReplayResult{}, err
+	}
+
+	return result, nil
+}
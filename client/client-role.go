@@ -60,27 +60,27 @@ func (c *GortClient) RoleCreate(rolename string) error {
 }
 
 // RoleList comments to be written...
-func (c *GortClient) RoleList() ([]rest.Group, error) {
+func (c *GortClient) RoleList() ([]rest.Role, error) {
 	url := fmt.Sprintf("%s/v2/roles", c.profile.URL.String())
 	resp, err := c.doRequest("GET", url, []byte{})
 	if err != nil {
-		return []rest.Group{}, err
+		return []rest.Role{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return []rest.Group{}, getResponseError(resp)
+		return []rest.Role{}, getResponseError(resp)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return []rest.Group{}, err
+		return []rest.Role{}, err
 	}
 
-	roles := []rest.Group{}
+	roles := []rest.Role{}
 	err = json.Unmarshal(body, &roles)
 	if err != nil {
-		return []rest.Group{}, err
+		return []rest.Role{}, err
 	}
 
 	return roles, nil
@@ -193,3 +193,48 @@ func (c *GortClient) RolePermissionGrant(rolename string, bundlename string, per
 
 	return nil
 }
+
+// RoleClone creates a new role named clonename with the same permissions as
+// an existing role. Group memberships aren't copied.
+func (c *GortClient) RoleClone(rolename, clonename string) error {
+	url := fmt.Sprintf("%s/v2/roles/%s/clones/%s", c.profile.URL.String(), rolename, clonename)
+
+	resp, err := c.doRequest("PUT", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// RoleDiff compares the permissions granted to two roles.
+func (c *GortClient) RoleDiff(rolename, othername string) (rest.RoleDiff, error) {
+	url := fmt.Sprintf("%s/v2/roles/%s/diff/%s", c.profile.URL.String(), rolename, othername)
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return rest.RoleDiff{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.RoleDiff{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.RoleDiff{}, err
+	}
+
+	diff := rest.RoleDiff{}
+	err = json.Unmarshal(body, &diff)
+	if err != nil {
+		return rest.RoleDiff{}, err
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,316 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+// ClusterStatus retrieves the status of the Gort controller cluster,
+// including its members, the current leader, and each member's adapters
+// and in-flight request count.
+func (c *GortClient) ClusterStatus() (rest.ClusterStatus, error) {
+	url := fmt.Sprintf("%s/v2/system/cluster", c.profile.URL.String())
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return rest.ClusterStatus{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.ClusterStatus{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.ClusterStatus{}, err
+	}
+
+	status := rest.ClusterStatus{}
+	err = json.Unmarshal(body, &status)
+	if err != nil {
+		return rest.ClusterStatus{}, err
+	}
+
+	return status, nil
+}
+
+// CanaryStatuses retrieves the recent results of each configured canary.
+func (c *GortClient) CanaryStatuses() ([]rest.CanaryStatus, error) {
+	url := fmt.Sprintf("%s/v2/system/canaries", c.profile.URL.String())
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []rest.CanaryStatus{}
+	err = json.Unmarshal(body, &statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// FeatureFlags retrieves every known feature flag, and whether each is
+// enabled on the controller.
+func (c *GortClient) FeatureFlags() ([]rest.FeatureFlag, error) {
+	url := fmt.Sprintf("%s/v2/system/features", c.profile.URL.String())
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := []rest.FeatureFlag{}
+	err = json.Unmarshal(body, &flags)
+	if err != nil {
+		return nil, err
+	}
+
+	return flags, nil
+}
+
+// Announce broadcasts a message to the chat channels targeted by the
+// announcement, and reports which channels it was sent to.
+func (c *GortClient) Announce(a rest.Announcement) (rest.AnnouncementResult, error) {
+	url := fmt.Sprintf("%s/v2/system/announce", c.profile.URL.String())
+
+	bytes, err := json.Marshal(a)
+	if err != nil {
+		return rest.AnnouncementResult{}, err
+	}
+
+	resp, err := c.doRequest("POST", url, bytes)
+	if err != nil {
+		return rest.AnnouncementResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.AnnouncementResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.AnnouncementResult{}, err
+	}
+
+	result := rest.AnnouncementResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return rest.AnnouncementResult{}, err
+	}
+
+	return result, nil
+}
+
+// ChannelList returns every chat channel that Gort's adapters are present in.
+func (c *GortClient) ChannelList() ([]rest.Channel, error) {
+	url := fmt.Sprintf("%s/v2/system/channels", c.profile.URL.String())
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := []rest.Channel{}
+	err = json.Unmarshal(body, &channels)
+	if err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// ChannelJoin causes the named adapter to join a channel, specified by ID or
+// name. Not all chat providers support this.
+func (c *GortClient) ChannelJoin(adapter, channel string) (rest.Channel, error) {
+	url := fmt.Sprintf("%s/v2/system/channels/%s/%s", c.profile.URL.String(), adapter, channel)
+	resp, err := c.doRequest("PUT", url, []byte{})
+	if err != nil {
+		return rest.Channel{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.Channel{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.Channel{}, err
+	}
+
+	ch := rest.Channel{}
+	err = json.Unmarshal(body, &ch)
+	if err != nil {
+		return rest.Channel{}, err
+	}
+
+	return ch, nil
+}
+
+// ChannelLeave causes the named adapter to leave a channel, specified by ID
+// or name. Not all chat providers support this.
+func (c *GortClient) ChannelLeave(adapter, channel string) error {
+	url := fmt.Sprintf("%s/v2/system/channels/%s/%s", c.profile.URL.String(), adapter, channel)
+	resp, err := c.doRequest("DELETE", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// ChannelSettingsGet returns the settings of a channel, specified by ID.
+func (c *GortClient) ChannelSettingsGet(adapter, channel string) (data.ChannelSettings, error) {
+	url := fmt.Sprintf("%s/v2/system/channels/%s/%s/settings", c.profile.URL.String(), adapter, channel)
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return data.ChannelSettings{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return data.ChannelSettings{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return data.ChannelSettings{}, err
+	}
+
+	settings := data.ChannelSettings{}
+	err = json.Unmarshal(body, &settings)
+	if err != nil {
+		return data.ChannelSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// ChannelSettingsSet replaces the settings of a channel, specified by ID.
+func (c *GortClient) ChannelSettingsSet(settings data.ChannelSettings) error {
+	url := fmt.Sprintf("%s/v2/system/channels/%s/%s/settings", c.profile.URL.String(), settings.Adapter, settings.ChannelID)
+
+	bytes, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest("PUT", url, bytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// ChannelSettingsDelete removes the settings of a channel, specified by ID.
+func (c *GortClient) ChannelSettingsDelete(adapter, channel string) error {
+	url := fmt.Sprintf("%s/v2/system/channels/%s/%s/settings", c.profile.URL.String(), adapter, channel)
+	resp, err := c.doRequest("DELETE", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// SelfTest runs a trivial built-in command through the entire command
+// pipeline and returns the timing of each stage.
+func (c *GortClient) SelfTest(t rest.SelfTestRequest) (rest.SelfTestResult, error) {
+	url := fmt.Sprintf("%s/v2/system/selftest", c.profile.URL.String())
+
+	bytes, err := json.Marshal(t)
+	if err != nil {
+		return rest.SelfTestResult{}, err
+	}
+
+	resp, err := c.doRequest("POST", url, bytes)
+	if err != nil {
+		return rest.SelfTestResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.SelfTestResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.SelfTestResult{}, err
+	}
+
+	result := rest.SelfTestResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return rest.SelfTestResult{}, err
+	}
+
+	return result, nil
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/getgort/gort/data/rest"
 )
@@ -62,6 +64,34 @@ func (c *GortClient) UserExists(username string) (bool, error) {
 	}
 }
 
+// UserExport retrieves all of the personal data that Gort stores about a
+// user, including their command request records.
+func (c *GortClient) UserExport(username string) (rest.UserDataExport, error) {
+	url := fmt.Sprintf("%s/v2/users/%s/export", c.profile.URL.String(), username)
+	resp, err := c.doRequest("GET", url, []byte{})
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.UserDataExport{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	export := rest.UserDataExport{}
+	err = json.Unmarshal(body, &export)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	return export, nil
+}
+
 // UserGet comments to be written...
 func (c *GortClient) UserGet(username string) (rest.User, error) {
 	url := fmt.Sprintf("%s/v2/users/%s", c.profile.URL.String(), username)
@@ -143,6 +173,73 @@ func (c *GortClient) UserList() ([]rest.User, error) {
 	return users, nil
 }
 
+// UserMessage sends a direct message to a Gort user through the chat
+// adapters they're mapped to, and reports which chat users it was sent to.
+func (c *GortClient) UserMessage(username string, m rest.DirectMessage) (rest.DirectMessageResult, error) {
+	url := fmt.Sprintf("%s/v2/users/%s/message", c.profile.URL.String(), username)
+
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return rest.DirectMessageResult{}, err
+	}
+
+	resp, err := c.doRequest("POST", url, bytes)
+	if err != nil {
+		return rest.DirectMessageResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.DirectMessageResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.DirectMessageResult{}, err
+	}
+
+	result := rest.DirectMessageResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return rest.DirectMessageResult{}, err
+	}
+
+	return result, nil
+}
+
+// UserToken issues a new token, valid for the given duration, to a service
+// account. Any token the service account already has is revoked. If duration
+// is zero, the server's default is used.
+func (c *GortClient) UserToken(username string, duration time.Duration) (rest.Token, error) {
+	url := fmt.Sprintf("%s/v2/users/%s/token", c.profile.URL.String(), username)
+	if duration > 0 {
+		url += "?duration=" + duration.String()
+	}
+
+	resp, err := c.doRequest("POST", url, []byte{})
+	if err != nil {
+		return rest.Token{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.Token{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	token := rest.Token{}
+	err = json.Unmarshal(body, &token)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	return token, nil
+}
+
 // UserPermissionList comments to be written...
 func (c *GortClient) UserPermissionList(username string) (rest.RolePermissionList, error) {
 	url := fmt.Sprintf("%s/v2/users/%s/permissions", c.profile.URL.String(), username)
@@ -170,6 +267,74 @@ func (c *GortClient) UserPermissionList(username string) (rest.RolePermissionLis
 	return rpl, nil
 }
 
+// UserPurge permanently removes a user and anonymizes their request records.
+// This can't be undone, so callers will usually want to use UserExport first.
+func (c *GortClient) UserPurge(username string) (rest.UserPurgeResult, error) {
+	query := url.Values{"confirm": []string{username}}
+
+	url := fmt.Sprintf("%s/v2/users/%s/purge?%s",
+		c.profile.URL.String(), username, query.Encode())
+	resp, err := c.doRequest("DELETE", url, []byte{})
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rest.UserPurgeResult{}, getResponseError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+
+	result := rest.UserPurgeResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+
+	return result, nil
+}
+
+// UserRename changes a user's username. The user keeps their group
+// memberships, adapter mappings, token, and request history.
+func (c *GortClient) UserRename(username, newname string) error {
+	url := fmt.Sprintf("%s/v2/users/%s/rename/%s", c.profile.URL.String(), username, newname)
+
+	resp, err := c.doRequest("PUT", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
+// UserRestore restores a recently deleted user, along with their group
+// memberships. Only users deleted within the server's retention period can be
+// restored.
+func (c *GortClient) UserRestore(username string) error {
+	url := fmt.Sprintf("%s/v2/users/%s/restore", c.profile.URL.String(), username)
+
+	resp, err := c.doRequest("PUT", url, []byte{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getResponseError(resp)
+	}
+
+	return nil
+}
+
 // UserSave will create or update a user. Note the the key is the username: if
 // this is called with a user whose username exists that user is updated
 // (empty fields will not be overwritten); otherwise a new user is created.
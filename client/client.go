@@ -61,6 +61,13 @@ var (
 	ErrURLFormat = errors.New("invalid URL format")
 
 	ErrInsecureURL = errors.New("insecure URL provided, please use https or set the allow insecure flag in the config or clients")
+
+	// ErrLoginRequired is returned when an SSO profile has no valid token.
+	ErrLoginRequired = errors.New("not logged in: use 'gort login' to log in with your identity provider")
+
+	// ErrLoginExpired is returned by AuthenticateOIDC if the user didn't
+	// complete the login in time.
+	ErrLoginExpired = errors.New("login expired before it was completed")
 )
 
 // GortClient comments to be written...
@@ -77,6 +84,7 @@ type Error struct {
 	error
 	profile ProfileEntry
 	status  uint
+	code    string
 }
 
 // Error returns the error message for this error.
@@ -96,6 +104,12 @@ func (c Error) Status() uint {
 	return c.status
 }
 
+// Code returns the error code (like "GORT-4001") provided by the server, if
+// any. Codes are described by the "GET /v2/errors/{code}" endpoint.
+func (c Error) Code() string {
+	return c.code
+}
+
 // Connect creates and returns a configured instance of the client for the
 // specified host. An empty string will use the default profile. If the
 // requested profile doesn't exist, an empty ProfileEntry is returned.
@@ -329,12 +343,19 @@ func getGortTokenDir() (string, error) {
 }
 
 // getResponseError receives an http.Response pointer and returns an Error
-// from its status message and code.
+// from its status message and code. The server describes errors with a JSON
+// rest.Error body, though older servers respond with plain text.
 func getResponseError(resp *http.Response) Error {
 	bytes, _ := ioutil.ReadAll(resp.Body)
 	status := strings.TrimSpace(string(bytes))
 	code := uint(resp.StatusCode)
 
+	var body rest.Error
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") &&
+		json.Unmarshal(bytes, &body) == nil && body.Message != "" {
+		return Error{error: errors.New(body.String()), status: code, code: body.Code}
+	}
+
 	if status == "" {
 		status = resp.Status
 	}
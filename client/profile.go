@@ -59,6 +59,11 @@ type ProfileEntry struct {
 	Username      string   `yaml:"user,omitempty"`
 	AllowInsecure bool     `yaml:"allow_insecure,omitempty"`
 	TLSCertFile   string   `yaml:"tls_cert_file,omitempty"`
+
+	// SSO is true if the profile's user logs in with the server's OpenID
+	// Connect identity provider, using "gort login", rather than with a
+	// password.
+	SSO bool `yaml:"sso,omitempty"`
 }
 
 // User is a convenience method that returns a rest.User pre-set with the
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/version"
+)
+
+var (
+	// adapters contains the names of the adapters owned by this node.
+	adapters   = map[string]bool{}
+	adaptersMx sync.RWMutex
+
+	// inFlight is the number of command requests currently being executed
+	// by this node.
+	inFlight int64
+
+	startedAt = time.Now().UTC()
+)
+
+// AddAdapter records that the named adapter is owned by this node.
+func AddAdapter(name string) {
+	adaptersMx.Lock()
+	defer adaptersMx.Unlock()
+
+	adapters[name] = true
+}
+
+// RemoveAdapter records that the named adapter is no longer owned by this
+// node.
+func RemoveAdapter(name string) {
+	adaptersMx.Lock()
+	defer adaptersMx.Unlock()
+
+	delete(adapters, name)
+}
+
+// RequestStarted increments this node's in-flight request count. It should
+// be paired with a call to RequestFinished.
+func RequestStarted() {
+	atomic.AddInt64(&inFlight, 1)
+}
+
+// RequestFinished decrements this node's in-flight request count.
+func RequestFinished() {
+	atomic.AddInt64(&inFlight, -1)
+}
+
+// InFlight returns the number of command requests currently being executed
+// by this node.
+func InFlight() int64 {
+	return atomic.LoadInt64(&inFlight)
+}
+
+// NodeName returns the name of this node. This is the host name, if it can
+// be determined, or "localhost" otherwise.
+func NodeName() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+
+	return "localhost"
+}
+
+// LocalMember returns a ClusterMember describing this node.
+func LocalMember() rest.ClusterMember {
+	adaptersMx.RLock()
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	adaptersMx.RUnlock()
+
+	sort.Strings(names)
+
+	return rest.ClusterMember{
+		Name:      NodeName(),
+		Adapters:  names,
+		InFlight:  InFlight(),
+		StartedAt: startedAt,
+		Version:   version.Version,
+	}
+}
+
+// Status returns the current status of the cluster. Gort doesn't yet support
+// running multiple controllers, so the cluster always consists of exactly one
+// member, which is also the leader.
+func Status() rest.ClusterStatus {
+	member := LocalMember()
+	member.Leader = true
+
+	return rest.ClusterStatus{
+		Leader:  member.Name,
+		Members: []rest.ClusterMember{member},
+	}
+}
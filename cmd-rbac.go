@@ -0,0 +1,89 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/worker/kubernetes"
+)
+
+const (
+	rbacUse   = "rbac"
+	rbacShort = "Generate Kubernetes RBAC manifests for Gort"
+	rbacLong  = `Generate the Kubernetes Roles and RoleBindings that Gort's service account
+needs to run commands on a cluster, as configured in the kubernetes section of
+the Gort config file. The output can be applied with "kubectl apply -f -".
+
+Jobs are granted access to the cluster's job_namespace, or to Gort's own
+namespace if it has none. Use --job-namespace to also grant access to the
+namespaces that bundles run their jobs in.`
+)
+
+var (
+	flagRBACConfigfile     string
+	flagRBACCluster        string
+	flagRBACServiceAccount string
+	flagRBACNamespace      string
+	flagRBACJobNamespaces  []string
+)
+
+// GetRBACCmd rbac
+func GetRBACCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   rbacUse,
+		Short: rbacShort,
+		Long:  rbacLong,
+		RunE:  rbacCmd,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&flagRBACConfigfile, "config", "c", "config.yml", "The location of the config file to use")
+	cmd.Flags().StringVar(&flagRBACCluster, "cluster", "", "The cluster to generate manifests for, from kubernetes.clusters (default: the default cluster)")
+	cmd.Flags().StringVar(&flagRBACServiceAccount, "service-account", "gort", "The name of Gort's service account")
+	cmd.Flags().StringVarP(&flagRBACNamespace, "namespace", "n", "", "Gort's namespace (default: kubernetes.namespace)")
+	cmd.Flags().StringSliceVar(&flagRBACJobNamespaces, "job-namespace", nil, "An additional namespace that jobs run in (may be repeated)")
+
+	return cmd
+}
+
+func rbacCmd(cmd *cobra.Command, args []string) error {
+	if err := initializeConfig(flagRBACConfigfile); err != nil {
+		return err
+	}
+
+	cc, ok := config.GetKubernetesConfigs().Cluster(flagRBACCluster)
+	if !ok {
+		return fmt.Errorf("%w: %q", kubernetes.ErrNoSuchCluster, flagRBACCluster)
+	}
+
+	manifests, err := kubernetes.RBAC(cc, kubernetes.RBACOptions{
+		ServiceAccount: flagRBACServiceAccount,
+		Namespace:      flagRBACNamespace,
+		JobNamespaces:  flagRBACJobNamespaces,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(manifests)
+
+	return nil
+}
@@ -38,17 +38,35 @@ func GetRootCmd() *cobra.Command {
 	}
 
 	root.AddCommand(GetStartCmd())
+	root.AddCommand(GetRBACCmd())
+	root.AddCommand(cli.GetAliasCmd())
+	root.AddCommand(cli.GetAnnounceCmd())
+	root.AddCommand(cli.GetAuditCmd())
 	root.AddCommand(cli.GetBootstrapCmd())
 	root.AddCommand(cli.GetBundleCmd())
+	root.AddCommand(cli.GetChannelCmd())
 	root.AddCommand(cli.GetConfigCmd())
+	root.AddCommand(cli.GetCostsCmd())
+	root.AddCommand(cli.GetDeadLetterCmd())
+	root.AddCommand(cli.GetDefaultsCmd())
+	root.AddCommand(cli.GetDmCmd())
+	root.AddCommand(cli.GetDoctorCmd())
+	root.AddCommand(cli.GetExecCmd())
 	root.AddCommand(cli.GetGroupCmd())
 	root.AddCommand(cli.GetHiddenCmd())
+	root.AddCommand(cli.GetLoginCmd())
+	root.AddCommand(cli.GetMacroCmd())
 	root.AddCommand(cli.GetPermissionCmd())
 	root.AddCommand(cli.GetProfileCmd())
+	root.AddCommand(cli.GetPsCmd())
+	root.AddCommand(cli.GetReplayCmd())
 	root.AddCommand(cli.GetRoleCmd())
+	root.AddCommand(cli.GetSelftestCmd())
+	root.AddCommand(cli.GetSystemCmd())
 	root.AddCommand(cli.GetUserCmd())
 	root.AddCommand(cli.GetVersionCmd())
 
+	root.PersistentFlags().StringVar(&cli.FlagGortOutput, "output", "table", "Output format: table, json, or yaml")
 	root.PersistentFlags().StringVarP(&cli.FlagGortProfile, "profile", "P", "", "The Gort profile within the config file to use")
 
 	return root
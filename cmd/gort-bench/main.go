@@ -0,0 +1,269 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command gort-bench is a load generator for the relay. It injects synthetic
+// command requests at a fixed rate, runs them against a fake worker engine,
+// and reports throughput, latency, and database usage.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/dataaccess/mysql"
+	"github.com/getgort/gort/dataaccess/postgres"
+	"github.com/getgort/gort/relay"
+	"github.com/getgort/gort/worker"
+)
+
+const (
+	benchUse   = "gort-bench"
+	benchShort = "Load test the Gort relay"
+	benchLong  = `Load test the Gort relay.
+
+Synthetic command requests are injected into the relay at a fixed rate and
+executed by a fake worker engine that returns canned output after a fixed
+delay, so no container engine is required. When the run completes, request
+throughput, queue latency (from submission until the worker starts), total
+latency, and database round-trips are reported.
+
+The data access layer is selected by the config file: if it doesn't define a
+database section, the in-memory data access layer is used.`
+
+	benchUser    = "bench"
+	benchBundle  = "bench"
+	benchCommand = "echo"
+)
+
+var (
+	flagBenchConfigfile  string
+	flagBenchDrain       time.Duration
+	flagBenchDuration    time.Duration
+	flagBenchExitCode    int64
+	flagBenchRate        float64
+	flagBenchWorkerDelay time.Duration
+	flagBenchWorkerLines int
+)
+
+// GetBenchCmd bench
+func GetBenchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          benchUse,
+		Short:        benchShort,
+		Long:         benchLong,
+		RunE:         benchCmd,
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVarP(&flagBenchConfigfile, "config", "c", "testing/config/no-database.yml", "The location of the config file to use")
+	cmd.Flags().DurationVar(&flagBenchDrain, "drain", 30*time.Second, "How long to wait for outstanding requests after the run")
+	cmd.Flags().DurationVarP(&flagBenchDuration, "duration", "d", 10*time.Second, "How long to inject requests for")
+	cmd.Flags().Int64Var(&flagBenchExitCode, "exit-code", 0, "The exit code returned by the fake worker")
+	cmd.Flags().Float64VarP(&flagBenchRate, "rate", "r", 100, "Requests to inject per second")
+	cmd.Flags().DurationVar(&flagBenchWorkerDelay, "worker-delay", 0, "How long the fake worker takes to execute each command")
+	cmd.Flags().IntVar(&flagBenchWorkerLines, "worker-lines", 1, "Lines of output produced by the fake worker")
+
+	return cmd
+}
+
+func benchCmd(cmd *cobra.Command, args []string) error {
+	if flagBenchRate <= 0 {
+		return fmt.Errorf("rate must be greater than zero")
+	}
+
+	log.SetLevel(log.WarnLevel)
+
+	ctx := context.Background()
+
+	entry, err := setup(ctx, flagBenchConfigfile)
+	if err != nil {
+		return err
+	}
+
+	stats := &Stats{}
+
+	worker.SetFactory(func(command data.CommandRequest, token rest.Token) (worker.Worker, error) {
+		return NewFakeWorker(command, flagBenchWorkerDelay, flagBenchWorkerLines, flagBenchExitCode, stats), nil
+	})
+
+	report, err := run(ctx, entry, stats)
+	if err != nil {
+		return err
+	}
+
+	report.Print(os.Stdout)
+
+	return nil
+}
+
+// run injects requests into the relay until the configured duration has
+// elapsed, and then waits for outstanding responses.
+func run(ctx context.Context, entry data.CommandEntry, stats *Stats) (Report, error) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		return Report{}, err
+	}
+
+	requests, responses := relay.StartListening()
+
+	go func() {
+		for envelope := range responses {
+			stats.RecordResponse(envelope)
+		}
+	}()
+
+	var sent int64
+	connections := connectionCount()
+	start := time.Now()
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / flagBenchRate))
+	defer ticker.Stop()
+
+	deadline := time.After(flagBenchDuration)
+
+inject:
+	for {
+		select {
+		case <-deadline:
+			break inject
+		case <-ticker.C:
+		}
+
+		request := data.CommandRequest{
+			CommandEntry: entry,
+			Adapter:      "bench",
+			ChannelID:    "bench",
+			Context:      ctx,
+			Parameters:   data.CommandParameters{"hello", "world"},
+			Timestamp:    time.Now(),
+			Timings:      data.StageTimings{},
+			UserID:       benchUser,
+			UserName:     benchUser,
+		}
+
+		if err := da.RequestBegin(ctx, &request); err != nil {
+			return Report{}, err
+		}
+
+		requests <- request
+		sent++
+	}
+
+	injected := time.Since(start)
+
+	// Wait for every sent request to be answered, or the drain period to
+	// expire, whichever comes first.
+	drain := time.After(flagBenchDrain)
+
+wait:
+	for stats.Completed() < sent {
+		select {
+		case <-drain:
+			log.Warnf("%d requests still outstanding after %s", sent-stats.Completed(), flagBenchDrain)
+			break wait
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// The connection count is only meaningful if a database-backed data
+	// access layer is in use.
+	roundTrips := int64(-1)
+	if !config.Undefined(config.GetDatabaseConfigs()) {
+		roundTrips = int64(connectionCount() - connections)
+	}
+
+	return stats.Report(sent, injected, time.Since(start), roundTrips), nil
+}
+
+// connectionCount returns the number of connections acquired by the
+// configured database's data access layer.
+func connectionCount() uint64 {
+	if config.GetDatabaseConfigs().DriverOrDefault() == data.DatabaseDriverMySQL {
+		return mysql.ConnectionCount()
+	}
+	return postgres.ConnectionCount()
+}
+
+// setup loads the configuration, initializes the data access layer, and
+// ensures that the benchmark user and bundle exist.
+func setup(ctx context.Context, configfile string) (data.CommandEntry, error) {
+	if err := config.Initialize(configfile); err != nil {
+		return data.CommandEntry{}, err
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return data.CommandEntry{}, err
+	}
+
+	if err := da.Initialize(ctx); err != nil {
+		return data.CommandEntry{}, err
+	}
+
+	if exists, err := da.UserExists(ctx, benchUser); err != nil {
+		return data.CommandEntry{}, err
+	} else if !exists {
+		user := rest.User{Email: benchUser + "@getgort.io", Username: benchUser}
+		if err := da.UserCreate(ctx, user); err != nil {
+			return data.CommandEntry{}, err
+		}
+	}
+
+	if exists, err := da.BundleVersionExists(ctx, benchBundle, benchBundleDefinition.Version); err != nil {
+		return data.CommandEntry{}, err
+	} else if !exists {
+		if err := da.BundleCreate(ctx, benchBundleDefinition); err != nil {
+			return data.CommandEntry{}, err
+		}
+	}
+
+	return data.CommandEntry{
+		Bundle:  benchBundleDefinition,
+		Command: *benchBundleDefinition.Commands[benchCommand],
+	}, nil
+}
+
+var benchBundleDefinition = data.Bundle{
+	GortBundleVersion: 1,
+	Name:              benchBundle,
+	Version:           "1.0.0",
+	Description:       "A bundle used by gort-bench",
+	Enabled:           true,
+	Commands: map[string]*data.BundleCommand{
+		benchCommand: {
+			Name:        benchCommand,
+			Description: "Echoes its arguments",
+			Executable:  []string{"/bin/echo"},
+			Rules:       []string{"allow"},
+		},
+	},
+}
+
+func main() {
+	err := GetBenchCmd().Execute()
+	if err != nil {
+		os.Exit(1)
+	}
+}
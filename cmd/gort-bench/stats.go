@@ -0,0 +1,182 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/getgort/gort/data"
+)
+
+// Stats collects measurements from a benchmark run. It's safe for
+// concurrent use.
+type Stats struct {
+	mu        sync.Mutex
+	completed int64
+	failed    int64
+	queue     []time.Duration
+	total     []time.Duration
+}
+
+// Completed returns the number of responses received so far.
+func (s *Stats) Completed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.completed
+}
+
+// RecordQueueLatency records the time a request waited between being
+// submitted and its worker being started.
+func (s *Stats) RecordQueueLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queue = append(s.queue, d)
+}
+
+// RecordResponse records the receipt of a response from the relay.
+func (s *Stats) RecordResponse(envelope data.CommandResponseEnvelope) {
+	d := time.Since(envelope.Request.Timestamp)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.completed++
+	if envelope.Data.ExitCode != 0 {
+		s.failed++
+	}
+	s.total = append(s.total, d)
+}
+
+// Report summarizes the measurements. Sent is the number of requests
+// injected over the injected duration; elapsed includes the time spent
+// waiting for outstanding responses. A negative roundTrips indicates that
+// database round-trips weren't measured.
+func (s *Stats) Report(sent int64, injected, elapsed time.Duration, roundTrips int64) Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := Report{
+		Sent:         sent,
+		Completed:    s.completed,
+		Failed:       s.failed,
+		Injected:     injected,
+		Elapsed:      elapsed,
+		QueueLatency: NewLatencies(s.queue),
+		TotalLatency: NewLatencies(s.total),
+		RoundTrips:   roundTrips,
+	}
+
+	if elapsed > 0 {
+		r.Throughput = float64(s.completed) / elapsed.Seconds()
+	}
+
+	return r
+}
+
+// Report is the outcome of a benchmark run.
+type Report struct {
+	Sent         int64
+	Completed    int64
+	Failed       int64
+	Injected     time.Duration
+	Elapsed      time.Duration
+	Throughput   float64 // Completed requests per second
+	QueueLatency Latencies
+	TotalLatency Latencies
+	RoundTrips   int64 // Negative if not measured
+}
+
+// Print writes the report in a human-readable form.
+func (r Report) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "Requests sent:\t%d (over %s, %.1f/s)\n", r.Sent, r.Injected.Round(time.Millisecond), float64(r.Sent)/r.Injected.Seconds())
+	fmt.Fprintf(tw, "Requests completed:\t%d (%d failed)\n", r.Completed, r.Failed)
+	fmt.Fprintf(tw, "Elapsed:\t%s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "Throughput:\t%.1f/s\n", r.Throughput)
+	fmt.Fprintf(tw, "Queue latency:\t%s\n", r.QueueLatency)
+	fmt.Fprintf(tw, "Total latency:\t%s\n", r.TotalLatency)
+
+	switch {
+	case r.RoundTrips < 0:
+		fmt.Fprintf(tw, "DB round-trips:\tnot measured (in-memory data access)\n")
+	case r.Sent > 0:
+		fmt.Fprintf(tw, "DB round-trips:\t%d (%.1f/request)\n", r.RoundTrips, float64(r.RoundTrips)/float64(r.Sent))
+	default:
+		fmt.Fprintf(tw, "DB round-trips:\t%d\n", r.RoundTrips)
+	}
+}
+
+// Latencies summarizes a set of latency measurements.
+type Latencies struct {
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// NewLatencies summarizes ds, which isn't modified.
+func NewLatencies(ds []time.Duration) Latencies {
+	if len(ds) == 0 {
+		return Latencies{}
+	}
+
+	sorted := append([]time.Duration{}, ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return Latencies{
+		Count: len(sorted),
+		P50:   percentile(sorted, 50),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+func (l Latencies) String() string {
+	if l.Count == 0 {
+		return "no measurements"
+	}
+
+	return fmt.Sprintf("p50=%s p95=%s p99=%s max=%s",
+		round(l.P50), round(l.P95), round(l.P99), round(l.Max))
+}
+
+// percentile returns the nearest-rank pth percentile of sorted, which must
+// not be empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	return sorted[i]
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
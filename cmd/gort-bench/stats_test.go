@@ -0,0 +1,67 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLatencies(t *testing.T) {
+	var ds []time.Duration
+	for i := 100; i > 0; i-- {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+
+	l := NewLatencies(ds)
+	assert.Equal(t, Latencies{
+		Count: 100,
+		P50:   50 * time.Millisecond,
+		P95:   95 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}, l)
+
+	// The input isn't modified.
+	assert.Equal(t, 100*time.Millisecond, ds[0])
+
+	assert.Equal(t, Latencies{}, NewLatencies(nil))
+	assert.Equal(t, "no measurements", NewLatencies(nil).String())
+}
+
+func TestStatsReport(t *testing.T) {
+	s := &Stats{}
+
+	s.RecordQueueLatency(time.Millisecond)
+	s.RecordResponse(data.CommandResponseEnvelope{Request: data.CommandRequest{Timestamp: time.Now()}})
+	s.RecordResponse(data.CommandResponseEnvelope{
+		Request: data.CommandRequest{Timestamp: time.Now()},
+		Data:    data.CommandResponseData{ExitCode: 1},
+	})
+
+	r := s.Report(2, time.Second, 2*time.Second, -1)
+	assert.EqualValues(t, 2, r.Sent)
+	assert.EqualValues(t, 2, r.Completed)
+	assert.EqualValues(t, 1, r.Failed)
+	assert.Equal(t, 1.0, r.Throughput)
+	assert.Equal(t, 1, r.QueueLatency.Count)
+	assert.Equal(t, 2, r.TotalLatency.Count)
+	assert.EqualValues(t, -1, r.RoundTrips)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/worker"
+)
+
+// NewFakeWorker returns a worker that doesn't run a container. Instead, once
+// started it waits for delay, emits lines of output, and exits with exitCode.
+// The time between the request being submitted and the worker being started
+// is recorded in stats as queue latency.
+func NewFakeWorker(command data.CommandRequest, delay time.Duration, lines int, exitCode int64, stats *Stats) *FakeWorker {
+	return &FakeWorker{
+		command:  command,
+		delay:    delay,
+		lines:    lines,
+		exitCode: exitCode,
+		stats:    stats,
+		stopped:  make(chan int64, 1),
+	}
+}
+
+var _ worker.Worker = &FakeWorker{}
+
+// FakeWorker is a worker.Worker that produces canned output.
+type FakeWorker struct {
+	command  data.CommandRequest
+	delay    time.Duration
+	lines    int
+	exitCode int64
+	stats    *Stats
+	stopped  chan int64
+}
+
+// Initialize does nothing: the fake worker has no use for configuration.
+func (w *FakeWorker) Initialize([]data.DynamicConfiguration) {}
+
+// Start records the request's queue latency and begins "executing" it.
+func (w *FakeWorker) Start(ctx context.Context) (<-chan string, error) {
+	w.stats.RecordQueueLatency(time.Since(w.command.Timestamp))
+
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+
+		select {
+		case <-time.After(w.delay):
+		case <-ctx.Done():
+			return
+		}
+
+		for i := 0; i < w.lines; i++ {
+			select {
+			case out <- fmt.Sprintf("%s (line %d)", w.command.Parameters, i+1):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		w.stopped <- w.exitCode
+	}()
+
+	return out, nil
+}
+
+// Stop does nothing: the fake worker always stops by itself.
+func (w *FakeWorker) Stop(ctx context.Context, timeout *time.Duration) {}
+
+// Stopped returns a channel that receives the worker's exit code when it
+// finishes.
+func (w *FakeWorker) Stopped() <-chan int64 {
+	return w.stopped
+}
@@ -265,3 +265,13 @@ func TestSplitCommand(t *testing.T) {
 func stringValue(s string) Value {
 	return StringValue{V: s, Quote: '\u0000'}
 }
+
+func BenchmarkParse(b *testing.B) {
+	tokens := []string{`foo:curl`, `-Ik`, `--ssl`, `"foo bar"`, `localhost`}
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(tokens); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import "testing"
+
+var fuzzSeeds = []string{
+	``,
+	`echo -n foo bar`,
+	`echo -n "foo bar"`,
+	`echo "What's" "\"this\"?"`,
+	`curl -Ik --header "Accept: application/json" 'https://example.com/a b'`,
+	`bundle:command --option value -- -x 1 2.5 true`,
+	`a:b:c`,
+	`echo 123456789012345678901234567890`,
+	`echo “smart quotes”`,
+	`\`,
+	`'"`,
+}
+
+// FuzzTokenize checks that Tokenize doesn't panic, that errors are always
+// TokenizeErrors, and that it never produces empty tokens.
+func FuzzTokenize(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		tokens, err := Tokenize(in)
+		if err != nil {
+			if _, ok := err.(TokenizeError); !ok {
+				t.Fatalf("Tokenize(%q): unexpected error type %T", in, err)
+			}
+			return
+		}
+
+		for _, token := range tokens {
+			if token == "" {
+				t.Fatalf("Tokenize(%q): empty token in %q", in, tokens)
+			}
+		}
+	})
+}
+
+// FuzzTokenizeAndParse checks that any successfully tokenized input can be
+// parsed unless its command name is malformed.
+func FuzzTokenizeAndParse(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s, false)
+		f.Add(s, true)
+	}
+
+	f.Fuzz(func(t *testing.T, in string, assumeArgs bool) {
+		tokens, err := Tokenize(in)
+		if err != nil || len(tokens) == 0 {
+			return
+		}
+
+		cmd, err := Parse(tokens, ParseAssumeOptionArguments(assumeArgs))
+
+		if _, _, serr := SplitCommand(tokens[0]); serr != nil {
+			if err == nil {
+				t.Fatalf("Parse(%q): expected an error", tokens)
+			}
+			return
+		}
+
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tokens, err)
+		}
+
+		_ = cmd.Parameters.String()
+	})
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"strings"
+)
+
+// PipeToken is the token that separates the commands of a pipeline.
+const PipeToken = "|"
+
+// ErrEmptyPipelineCommand is returned by TokenizePipeline when a pipe
+// isn't both preceded and followed by a command.
+var ErrEmptyPipelineCommand = errors.New("pipeline has an empty command")
+
+// TokenizePipeline tokenizes input like Tokenize, and splits the tokens into
+// the commands of a pipeline, which are separated by "|" tokens. A pipe that
+// is quoted, escaped, or part of a longer token doesn't separate commands.
+// Input without any pipes produces a single command.
+// Examples:
+//
+//	echo foo | wc -l -> {{"echo", "foo"}, {"wc", "-l"}}
+//	echo "foo | bar" -> {{"echo", "\"foo | bar\""}}
+//	echo foo|bar     -> {{"echo", "foo|bar"}}
+func TokenizePipeline(input string) ([][]string, error) {
+	tokens, err := Tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := [][]string{}
+	current := []string{}
+
+	for _, t := range tokens {
+		if t != PipeToken {
+			current = append(current, t)
+			continue
+		}
+
+		if len(current) == 0 {
+			return nil, ErrEmptyPipelineCommand
+		}
+
+		commands = append(commands, current)
+		current = []string{}
+	}
+
+	if len(current) == 0 && len(commands) > 0 {
+		return nil, ErrEmptyPipelineCommand
+	}
+
+	return append(commands, current), nil
+}
+
+// SplitPipeline splits input into the text of each of the commands of a
+// pipeline, as separated by TokenizePipeline. Each command's text is its
+// tokens joined with spaces, which Tokenize splits into the same tokens.
+// Input without any pipes is returned unchanged.
+func SplitPipeline(input string) ([]string, error) {
+	commands, err := TokenizePipeline(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commands) < 2 {
+		return []string{input}, nil
+	}
+
+	texts := make([]string, len(commands))
+	for i, c := range commands {
+		texts[i] = strings.Join(c, " ")
+	}
+
+	return texts, nil
+}
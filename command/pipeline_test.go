@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizePipeline(t *testing.T) {
+	inputs := map[string][][]string{
+		``:                         {{}},
+		`echo foo`:                 {{`echo`, `foo`}},
+		`echo foo | wc -l`:         {{`echo`, `foo`}, {`wc`, `-l`}},
+		`a | b | c`:                {{`a`}, {`b`}, {`c`}},
+		`echo "foo | bar"`:         {{`echo`, `"foo | bar"`}},
+		`echo foo \| bar`:          {{`echo`, `foo`, `\|`, `bar`}},
+		`echo foo|bar`:             {{`echo`, `foo|bar`}},
+		`echo '|' | grep -F '|'`:   {{`echo`, `'|'`}, {`grep`, `-F`, `'|'`}},
+		"list-hosts\n|\ngrep prod": {{`list-hosts`}, {`grep`, `prod`}},
+	}
+
+	for in, expected := range inputs {
+		commands, err := TokenizePipeline(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, expected, commands, in)
+	}
+}
+
+func TestTokenizePipelineErrors(t *testing.T) {
+	inputs := []string{`| foo`, `foo |`, `foo | | bar`, `|`}
+
+	for _, in := range inputs {
+		_, err := TokenizePipeline(in)
+		assert.ErrorIs(t, err, ErrEmptyPipelineCommand, in)
+	}
+
+	_, err := TokenizePipeline(`echo "foo | bar`)
+	assert.IsType(t, TokenizeError{}, err)
+}
+
+func TestSplitPipeline(t *testing.T) {
+	texts, err := SplitPipeline(`echo  "foo  bar"`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`echo  "foo  bar"`}, texts)
+
+	texts, err = SplitPipeline(`list-hosts --env "prod east" | grep   web\ 1 | deploy:restart`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`list-hosts --env "prod east"`, `grep web\ 1`, `deploy:restart`}, texts)
+
+	// Each command's text tokenizes to that command's tokens.
+	commands, err := TokenizePipeline(`echo "a | b" 'c' | wc -l`)
+	require.NoError(t, err)
+	texts, err = SplitPipeline(`echo "a | b" 'c' | wc -l`)
+	require.NoError(t, err)
+
+	for i, text := range texts {
+		tokens, err := Tokenize(text)
+		require.NoError(t, err)
+		assert.Equal(t, commands[i], tokens)
+	}
+}
@@ -56,3 +56,13 @@ func TestTokenizeErrors(t *testing.T) {
 		assert.IsType(t, TokenizeError{}, err, in)
 	}
 }
+
+func BenchmarkTokenize(b *testing.B) {
+	const in = `curl -Ik --header "Accept: application/json" 'https://example.com/a b'`
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Tokenize(in); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -19,10 +19,12 @@ package config
 import (
 	"crypto/md5"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"reflect"
+	"regexp"
 	"sync"
 	"time"
 
@@ -35,6 +37,8 @@ import (
 
 const (
 	EnvDatabasePassword = "GORT_DB_PASSWORD"
+	EnvEncryptionKey    = "GORT_ENCRYPTION_KEY"
+	EnvWorkerTokenKey   = "GORT_WORKER_TOKEN_KEY"
 )
 
 const (
@@ -59,6 +63,8 @@ var (
 	configMutex = sync.RWMutex{}
 	md5sum      = []byte{}
 
+	redactPatterns = []*regexp.Regexp{}
+
 	stateChangeListeners      = make([]chan State, 0)
 	stateChangeListenersMutex = sync.Mutex{}
 
@@ -136,6 +142,29 @@ func GetDynamicConfigs() data.DynamicConfigs {
 	return config.DynamicConfigs
 }
 
+// GetEncryptionConfigs returns the data wrapper for the "encryption" config
+// section.
+func GetEncryptionConfigs() data.EncryptionConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.EncryptionConfigs
+}
+
+// GetFeatureConfigs returns the data wrapper for the "features" config
+// section.
+func GetFeatureConfigs() data.FeatureConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.Features
+}
+
+// FeatureEnabled returns whether the named feature flag is enabled.
+func FeatureEnabled(name string) bool {
+	return GetFeatureConfigs().Enabled(name)
+}
+
 // GetGlobalConfigs returns the data wrapper for the "global" config section.
 func GetGlobalConfigs() data.GlobalConfigs {
 	configMutex.RLock()
@@ -144,6 +173,16 @@ func GetGlobalConfigs() data.GlobalConfigs {
 	return config.GlobalConfigs
 }
 
+// GetRedactPatterns returns the compiled form of the global redaction
+// patterns (global.redact_patterns). Any parameter text matching one of these
+// is redacted before it's logged or stored.
+func GetRedactPatterns() []*regexp.Regexp {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return redactPatterns
+}
+
 // GetGortServerConfigs returns the data wrapper for the "gort" config section.
 func GetGortServerConfigs() data.GortServerConfigs {
 	configMutex.RLock()
@@ -152,6 +191,22 @@ func GetGortServerConfigs() data.GortServerConfigs {
 	return config.GortServerConfigs
 }
 
+// GetLDAPConfigs returns the data wrapper for the "ldap" config section.
+func GetLDAPConfigs() data.LDAPConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.LDAPConfigs
+}
+
+// GetOIDCConfigs returns the data wrapper for the "oidc" config section.
+func GetOIDCConfigs() data.OIDCConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.OIDCConfigs
+}
+
 // GetJaegerConfigs returns the data wrapper for the "jaeger" config section.
 func GetJaegerConfigs() data.JaegerConfigs {
 	configMutex.RLock()
@@ -168,6 +223,47 @@ func GetKubernetesConfigs() data.KubernetesConfigs {
 	return config.KubernetesConfigs
 }
 
+// GetMemoryStoreConfigs returns the data wrapper for the "memory_store"
+// config section.
+func GetMemoryStoreConfigs() data.MemoryStoreConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.MemoryStore
+}
+
+// GetMockConfigs returns the data wrapper for the "mock" config section.
+func GetMockConfigs() data.MockConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.MockConfigs
+}
+
+// GetNativeConfigs returns the data wrapper for the "native" config section.
+func GetNativeConfigs() data.NativeConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.NativeConfigs
+}
+
+// GetSSHConfigs returns the data wrapper for the "ssh" config section.
+func GetSSHConfigs() data.SSHConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.SSHConfigs
+}
+
+// GetServerlessConfigs returns the data wrapper for the "serverless" config section.
+func GetServerlessConfigs() data.ServerlessConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.ServerlessConfigs
+}
+
 // GetSlackProviders returns the data wrapper for the "slack" config section.
 func GetSlackProviders() []data.SlackProvider {
 	configMutex.RLock()
@@ -184,6 +280,14 @@ func GetTemplates() data.Templates {
 	return config.Templates
 }
 
+// GetWorkerConfigs returns the data wrapper for the "worker" config section.
+func GetWorkerConfigs() data.WorkerConfigs {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return config.WorkerConfigs
+}
+
 // Initialize is called by main() to trigger creation of the config singleton.
 // It can be called multiple times, if you're into that kind of thing. If
 // successful, this will emit a StateConfigInitialized to any update listeners.
@@ -238,10 +342,15 @@ func Reload() error {
 		md5sum = sum
 		config = cp
 
+		// The patterns were already validated by load().
+		redactPatterns, _ = compileRedactPatterns(cp.GlobalConfigs.RedactPatterns)
+
 		setLogFormatter()
 
 		// Properly load the database configs.
 		standardizeDatabaseConfig(&cp.DatabaseConfigs)
+		standardizeEncryptionConfig(&cp.EncryptionConfigs)
+		standardizeWorkerConfig(&cp.WorkerConfigs)
 
 		updateConfigState(StateConfigInitialized)
 
@@ -265,6 +374,22 @@ func Updates() <-chan State {
 	return ch
 }
 
+// compileRedactPatterns compiles the global redaction patterns, returning
+// an error if any of them isn't a valid regular expression.
+func compileRedactPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
 // getMd5Sum is used to determine when the underlying config file is modified.
 func getMd5Sum(file string) ([]byte, error) {
 	f, err := os.Open(file)
@@ -300,6 +425,105 @@ func load(file string) (*data.GortConfig, error) {
 		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, err)
 	}
 
+	_, err = compileRedactPatterns(config.GlobalConfigs.RedactPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.GlobalConfigs.AllowedImages.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.allowed_images: %w", err))
+	}
+
+	if err := config.GlobalConfigs.APITokens.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.api_tokens: %w", err))
+	}
+
+	if err := config.GlobalConfigs.BundleAnalysis.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.bundle_analysis: %w", err))
+	}
+
+	if err := config.GlobalConfigs.Triggers.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.triggers: %w", err))
+	}
+
+	if err := config.GlobalConfigs.OutputFilters.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.output_filters: %w", err))
+	}
+
+	if err := config.GlobalConfigs.Canaries.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.canaries: %w", err))
+	}
+
+	if err := config.GlobalConfigs.Costs.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.costs: %w", err))
+	}
+
+	if err := config.GlobalConfigs.AutoRollback.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.auto_rollback: %w", err))
+	}
+
+	if err := config.GlobalConfigs.FailureNotices.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.failure_notifications: %w", err))
+	}
+
+	if err := config.GlobalConfigs.InputFiles.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.input_files: %w", err))
+	}
+
+	if err := config.GlobalConfigs.Janitor.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.janitor: %w", err))
+	}
+
+	if err := config.GlobalConfigs.Outbox.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("global.outbox: %w", err))
+	}
+
+	if !Undefined(config.LDAPConfigs) {
+		if err := config.LDAPConfigs.Validate(); err != nil {
+			return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("ldap: %w", err))
+		}
+	}
+
+	if !Undefined(config.OIDCConfigs) {
+		if err := config.OIDCConfigs.Validate(); err != nil {
+			return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("oidc: %w", err))
+		}
+	}
+
+	if err := config.NativeConfigs.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("native: %w", err))
+	}
+
+	if err := config.EncryptionConfigs.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("encryption: %w", err))
+	}
+
+	if err := config.DatabaseConfigs.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("database: %w", err))
+	}
+
+	if err := config.Features.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("features: %w", err))
+	}
+
+	if err := config.MemoryStore.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("memory_store: %w", err))
+	}
+
+	if err := config.KubernetesConfigs.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("kubernetes: %w", err))
+	}
+
+	if err := config.WorkerConfigs.Validate(); err != nil {
+		return nil, gerrs.Wrap(gerrs.ErrUnmarshal, fmt.Errorf("worker: %w", err))
+	}
+
+	for stage := range config.GlobalConfigs.LatencyBudgets {
+		if _, err := data.ParseRequestStage(string(stage)); err != nil {
+			return nil, gerrs.Wrap(gerrs.ErrUnmarshal, err)
+		}
+	}
+
 	return &config, nil
 }
 
@@ -346,6 +570,20 @@ func standardizeDatabaseConfig(dbc *data.DatabaseConfigs) {
 	}
 }
 
+func standardizeEncryptionConfig(ec *data.EncryptionConfigs) {
+	if ec.Provider != "" && ec.Key == "" && ec.KeyFile == "" {
+		log.Debug("Config encryption key empty; using envvar ", EnvEncryptionKey)
+		ec.Key = os.Getenv(EnvEncryptionKey)
+	}
+}
+
+func standardizeWorkerConfig(wc *data.WorkerConfigs) {
+	if wc.TokenKey == "" && wc.TokenKeyFile == "" {
+		log.Debug("Config worker token key empty; using envvar ", EnvWorkerTokenKey)
+		wc.TokenKey = os.Getenv(EnvWorkerTokenKey)
+	}
+}
+
 // updateConfigState updates the state and emits the new state to any listeners.
 func updateConfigState(newState State) {
 	stateMutex.Lock()
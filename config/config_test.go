@@ -61,6 +61,8 @@ func TestLoad(t *testing.T) {
 	assert.NotNil(t, cd)
 	assert.Equal(t, "unix:///var/run/docker.sock", cd.DockerHost)
 
+	assert.Equal(t, data.WorkerEngineDocker, config.WorkerConfigs.Engine)
+
 	cs := config.SlackProviders
 	assert.NotNil(t, cs)
 	assert.NotEmpty(t, cs)
@@ -77,6 +79,17 @@ func TestLoad(t *testing.T) {
 	assert.Equal(t, cj.Password, "veryKleverPassw0rd!")
 }
 
+func TestLoadFeatures(t *testing.T) {
+	c, err := load("../testing/config/no-database.yml")
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+
+	assert.True(t, c.Features.Enabled(data.FeaturePipelines))
+	assert.False(t, c.Features.Enabled(data.FeatureStreamingOutput))
+}
+
 func TestUndefinedNil(t *testing.T) {
 	id := Undefined(nil)
 	assert.True(t, id)
@@ -153,3 +166,12 @@ func TestStandardizeDatabaseConfigDefined(t *testing.T) {
 	assert.NotEmpty(t, dbp)
 	assert.Equal(t, dbp, expected)
 }
+
+func TestCompileRedactPatterns(t *testing.T) {
+	compiled, err := compileRedactPatterns([]string{`AKIA[0-9A-Z]{16}`, `(?i)password=\S+`})
+	assert.NoError(t, err)
+	assert.Len(t, compiled, 2)
+
+	_, err = compileRedactPatterns([]string{`AKIA[0-9A-Z`})
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrBadAlias is returned when an alias's name or command is malformed.
+var ErrBadAlias = errors.New("bad alias")
+
+// MaxAliasDepth is the most aliases that will be expanded in a single
+// command, to guard against aliases that refer to one another.
+const MaxAliasDepth = 8
+
+// Alias is a short name for a longer command, like "dep" for
+// "deploy:status prod". When a command begins with an alias's name, that
+// name is replaced by its command; any remaining text is appended to it.
+type Alias struct {
+	// Name is the name the alias is invoked by.
+	Name string
+
+	// Owner is the name of the user the alias belongs to. If empty, the
+	// alias is global: it was defined by an administrator and is available
+	// to everybody.
+	Owner string
+
+	// Command is the text the alias's name is replaced with.
+	Command string
+}
+
+// Validate returns an error if the alias's name isn't a single word, or if
+// its command is empty.
+func (a Alias) Validate() error {
+	switch {
+	case strings.ContainsAny(a.Name, ": \t\n"):
+		return fmt.Errorf("%w: alias names may not contain colons or whitespace", ErrBadAlias)
+	case strings.TrimSpace(a.Command) == "":
+		return fmt.Errorf("%w: alias command may not be empty", ErrBadAlias)
+	}
+
+	return nil
+}
+
+// Expand replaces the first word of text, which is assumed to be the
+// alias's name, with the alias's command.
+func (a Alias) Expand(text string) string {
+	_, rest := splitFirstWord(text)
+	if rest == "" {
+		return a.Command
+	}
+
+	return a.Command + " " + rest
+}
+
+// AliasName returns the first word of text, which is the name of the alias
+// it invokes, if any.
+func AliasName(text string) string {
+	name, _ := splitFirstWord(text)
+	return name
+}
+
+// splitFirstWord splits text into its first whitespace-delimited word and
+// everything after it, with the surrounding whitespace removed.
+func splitFirstWord(text string) (string, string) {
+	text = strings.TrimSpace(text)
+
+	i := strings.IndexAny(text, " \t\n")
+	if i < 0 {
+		return text, ""
+	}
+
+	return text[:i], strings.TrimSpace(text[i:])
+}
+
+// ResolveAlias finds the alias with the given name that applies to a user.
+// The user's own aliases take precedence over global ones.
+func ResolveAlias(aliases []Alias, name, username string) (Alias, bool) {
+	var global *Alias
+
+	for i, a := range aliases {
+		if a.Name != name {
+			continue
+		}
+
+		switch {
+		case a.Owner != "" && a.Owner == username:
+			return a, true
+		case a.Owner == "" && global == nil:
+			global = &aliases[i]
+		}
+	}
+
+	if global == nil {
+		return Alias{}, false
+	}
+
+	return *global, true
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasValidate(t *testing.T) {
+	assert.NoError(t, Alias{Name: "dep", Command: "deploy:status prod"}.Validate())
+	assert.ErrorIs(t, Alias{Name: "gort:dep", Command: "deploy:status"}.Validate(), ErrBadAlias)
+	assert.ErrorIs(t, Alias{Name: "my dep", Command: "deploy:status"}.Validate(), ErrBadAlias)
+	assert.ErrorIs(t, Alias{Name: "dep", Command: "  "}.Validate(), ErrBadAlias)
+}
+
+func TestAliasExpand(t *testing.T) {
+	a := Alias{Name: "dep", Command: "deploy:status prod"}
+
+	assert.Equal(t, "deploy:status prod", a.Expand("dep"))
+	assert.Equal(t, "deploy:status prod --verbose \"a  b\"", a.Expand("dep --verbose \"a  b\""))
+	assert.Equal(t, "dep", AliasName("  dep --verbose"))
+	assert.Equal(t, "", AliasName(""))
+}
+
+func TestResolveAlias(t *testing.T) {
+	aliases := []Alias{
+		{Name: "dep", Command: "deploy:status prod"},
+		{Name: "dep", Owner: "alice", Command: "deploy:status staging"},
+		{Name: "other", Owner: "bob", Command: "echo"},
+	}
+
+	a, ok := ResolveAlias(aliases, "dep", "alice")
+	assert.True(t, ok)
+	assert.Equal(t, "deploy:status staging", a.Command)
+
+	a, ok = ResolveAlias(aliases, "dep", "bob")
+	assert.True(t, ok)
+	assert.Equal(t, "deploy:status prod", a.Command)
+
+	_, ok = ResolveAlias(aliases, "other", "alice")
+	assert.False(t, ok)
+
+	_, ok = ResolveAlias(aliases, "missing", "alice")
+	assert.False(t, ok)
+}
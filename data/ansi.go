@@ -0,0 +1,224 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ANSIMode describes how ANSI escape sequences in a command's output are
+// handled before it's rendered.
+type ANSIMode string
+
+const (
+	// ANSIKeep leaves the output untouched. This is the default.
+	ANSIKeep ANSIMode = "keep"
+
+	// ANSIStrip removes all ANSI escape sequences.
+	ANSIStrip ANSIMode = "strip"
+
+	// ANSIConvert converts bold, italic, and strikethrough SGR codes into
+	// the chat provider's equivalent markup, and removes everything else.
+	ANSIConvert ANSIMode = "convert"
+)
+
+// Validate returns an error if m isn't empty or one of the defined modes.
+func (m ANSIMode) Validate() error {
+	switch m {
+	case "", ANSIKeep, ANSIStrip, ANSIConvert:
+		return nil
+	default:
+		return fmt.Errorf("invalid ansi mode %q: must be one of %q, %q, or %q",
+			m, ANSIKeep, ANSIStrip, ANSIConvert)
+	}
+}
+
+var (
+	// ansiEscape matches ANSI CSI sequences (colors, cursor movement), OSC
+	// sequences (terminal titles, hyperlinks), and the remaining two-byte
+	// escapes.
+	ansiEscape = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+	// sgrSequence matches ANSI SGR ("Select Graphic Rendition") sequences,
+	// capturing their parameters.
+	sgrSequence = regexp.MustCompile(`\x1b\[([0-9;]*)m`)
+)
+
+// StripANSI returns s with all ANSI escape sequences removed.
+func StripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// Markup describes the inline formatting delimiters used by a chat provider.
+type Markup struct {
+	Bold      string
+	Italic    string
+	Strike    string
+	CodeFence string
+}
+
+// StandardMarkup is the Markdown formatting understood by most providers.
+var StandardMarkup = Markup{Bold: "**", Italic: "*", Strike: "~~", CodeFence: "```"}
+
+// ConvertANSI converts ANSI-formatted lines into lines formatted with m.
+// Lines containing bold, italic, or strikethrough text are converted to
+// markup; runs of other lines are wrapped in code blocks so they keep their
+// alignment. Styles carry across lines, as they do in a terminal.
+func ConvertANSI(lines []string, m Markup) []string {
+	var out, block []string
+	var style ansiStyle
+
+	flush := func() {
+		if len(block) > 0 {
+			out = append(out, m.CodeFence)
+			out = append(out, block...)
+			out = append(out, m.CodeFence)
+			block = nil
+		}
+	}
+
+	for _, line := range lines {
+		var converted string
+		var formatted bool
+
+		converted, style, formatted = convertANSILine(line, style, m)
+		if formatted {
+			flush()
+			out = append(out, converted)
+		} else {
+			block = append(block, converted)
+		}
+	}
+
+	flush()
+
+	return out
+}
+
+// convertANSILine converts a single line, starting with the given style. It
+// returns the converted line, the style in effect at its end, and whether
+// any markup was added.
+func convertANSILine(line string, style ansiStyle, m Markup) (string, ansiStyle, bool) {
+	type segment struct {
+		style ansiStyle
+		text  string
+	}
+
+	var segments []segment
+
+	add := func(text string) {
+		if text = StripANSI(text); text == "" {
+			return
+		}
+		if n := len(segments); n > 0 && segments[n-1].style == style {
+			segments[n-1].text += text
+			return
+		}
+		segments = append(segments, segment{style, text})
+	}
+
+	pos := 0
+	for _, loc := range sgrSequence.FindAllStringSubmatchIndex(line, -1) {
+		add(line[pos:loc[0]])
+		style = style.apply(line[loc[2]:loc[3]])
+		pos = loc[1]
+	}
+	add(line[pos:])
+
+	var b strings.Builder
+	formatted := false
+
+	for _, s := range segments {
+		open, close := s.style.markup(m)
+		core := strings.TrimSpace(s.text)
+
+		// Markup can't start or end with whitespace, so it goes outside.
+		if open == "" || core == "" {
+			b.WriteString(s.text)
+			continue
+		}
+
+		i := strings.Index(s.text, core)
+		b.WriteString(s.text[:i])
+		b.WriteString(open)
+		b.WriteString(core)
+		b.WriteString(close)
+		b.WriteString(s.text[i+len(core):])
+		formatted = true
+	}
+
+	return b.String(), style, formatted
+}
+
+// ansiStyle is the subset of SGR state that has a chat equivalent.
+type ansiStyle struct {
+	bold, italic, strike bool
+}
+
+// apply returns s updated with the semicolon-delimited SGR parameters.
+// Underlining has no chat equivalent, so it's treated as italic.
+func (s ansiStyle) apply(params string) ansiStyle {
+	codes := strings.Split(params, ";")
+
+	for i := 0; i < len(codes); i++ {
+		switch codes[i] {
+		case "", "0":
+			s = ansiStyle{}
+		case "1":
+			s.bold = true
+		case "3", "4":
+			s.italic = true
+		case "9":
+			s.strike = true
+		case "22":
+			s.bold = false
+		case "23", "24":
+			s.italic = false
+		case "29":
+			s.strike = false
+		case "38", "48", "58":
+			// Extended colors are followed by "5;n" or "2;r;g;b".
+			if i+1 < len(codes) {
+				switch codes[i+1] {
+				case "5":
+					i += 2
+				case "2":
+					i += 4
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+// markup returns the opening and closing delimiters for s.
+func (s ansiStyle) markup(m Markup) (open, close string) {
+	if s.bold {
+		open, close = open+m.Bold, m.Bold+close
+	}
+	if s.italic {
+		open, close = open+m.Italic, m.Italic+close
+	}
+	if s.strike {
+		open, close = open+m.Strike, m.Strike+close
+	}
+
+	return open, close
+}
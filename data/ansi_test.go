@@ -0,0 +1,58 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripANSI(t *testing.T) {
+	assert.Equal(t, "red plain", StripANSI("\x1b[31mred\x1b[0m \x1b]0;title\x07plain"))
+}
+
+func TestConvertANSI(t *testing.T) {
+	lines := []string{
+		"\x1b[1mBuild\x1b[0m \x1b[32mok\x1b[0m",
+		"  plain   aligned",
+		"\x1b[38;5;196mred only\x1b[0m",
+		"\x1b[1;3m both \x1b[22mitalic\x1b[0m end",
+		"\x1b[9mstart",
+		"continues\x1b[29m done",
+	}
+
+	assert.Equal(t, []string{
+		"**Build** ok",
+		"```",
+		"  plain   aligned",
+		"red only",
+		"```",
+		" ***both*** *italic* end",
+		"~~start~~",
+		"~~continues~~ done",
+	}, ConvertANSI(lines, StandardMarkup))
+
+	slack := Markup{Bold: "*", Italic: "_", Strike: "~", CodeFence: "```"}
+	assert.Equal(t, []string{"*_x_*"}, ConvertANSI([]string{"\x1b[1;4mx"}, slack))
+}
+
+func TestANSIModeValidate(t *testing.T) {
+	assert.NoError(t, ANSIMode("").Validate())
+	assert.NoError(t, ANSIConvert.Validate())
+	assert.Error(t, ANSIMode("colour").Validate())
+}
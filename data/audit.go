@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditRecord describes a single mutating operation (creating a user,
+// granting a permission, enabling a bundle, and so on) performed through the
+// REST API.
+type AuditRecord struct {
+	// ID is the unique, increasing identifier of the record. It's assigned
+	// when the record is created.
+	ID int64 `json:"id"`
+
+	// Timestamp is the time the operation was performed.
+	Timestamp time.Time `json:"timestamp"`
+
+	// User is the name of the Gort user that performed the operation. It's
+	// empty if the request wasn't authenticated.
+	User string `json:"user"`
+
+	// SourceIP is the IP address the request came from.
+	SourceIP string `json:"source_ip"`
+
+	// Method and Path are the HTTP method and URL path of the request.
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Kind is the kind of entity that was changed ("user", "group", "role",
+	// "bundle", etc), and Target is its name.
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+
+	// Status is the HTTP status of the response.
+	Status int `json:"status"`
+
+	// Before and After are JSON snapshots of the target taken immediately
+	// before and after the operation. Either is empty if the target didn't
+	// exist at the time, or if snapshots aren't supported for its kind.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditFilter describes which audit records to retrieve. Zero-value fields
+// are ignored.
+type AuditFilter struct {
+	// User, Kind, and Target, if set, must match the record exactly.
+	User   string
+	Kind   string
+	Target string
+
+	// Since and Until bound the record's timestamp (inclusive and exclusive,
+	// respectively).
+	Since time.Time
+	Until time.Time
+
+	// Limit is the maximum number of records to retrieve, newest first.
+	Limit int
+}
+
+// Matches returns true if the record satisfies the filter's conditions.
+// Limit isn't considered.
+func (f AuditFilter) Matches(r AuditRecord) bool {
+	switch {
+	case f.User != "" && f.User != r.User:
+		return false
+	case f.Kind != "" && f.Kind != r.Kind:
+		return false
+	case f.Target != "" && f.Target != r.Target:
+		return false
+	case !f.Since.IsZero() && r.Timestamp.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Timestamp.Before(f.Until):
+		return false
+	}
+
+	return true
+}
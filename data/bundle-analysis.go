@@ -0,0 +1,68 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import "fmt"
+
+// Severity levels of the warnings reported by bundle analysis, from least
+// to most severe.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// SeverityRank returns the relative rank of a severity level: higher is
+// more severe. Unknown levels rank 0.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityCritical:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// BundleAnalysisConfigs is the data wrapper for the
+// "global.bundle_analysis" section, which controls how the warnings found
+// by analyzing a bundle at install time are acted upon.
+type BundleAnalysisConfigs struct {
+	// BlockSeverity is the lowest severity at which a warning blocks the
+	// install. If empty, warnings are reported but never block.
+	BlockSeverity string `yaml:"block_severity,omitempty"`
+}
+
+// Validate returns an error if BlockSeverity isn't a known severity level.
+func (c BundleAnalysisConfigs) Validate() error {
+	if c.BlockSeverity != "" && SeverityRank(c.BlockSeverity) == 0 {
+		return fmt.Errorf("invalid block_severity: %q", c.BlockSeverity)
+	}
+	return nil
+}
+
+// Blocks returns true if a warning of the given severity should block the
+// install.
+func (c BundleAnalysisConfigs) Blocks(severity string) bool {
+	if c.BlockSeverity == "" {
+		return false
+	}
+	return SeverityRank(severity) >= SeverityRank(c.BlockSeverity)
+}
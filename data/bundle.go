@@ -18,13 +18,21 @@ package data
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/coreos/go-semver/semver"
+	"gopkg.in/yaml.v3"
 )
 
+// ErrNoSuchProfile is returned when a command is invoked with, or defaults
+// to, an execution profile that it doesn't define.
+var ErrNoSuchProfile = errors.New("no such profile")
+
 // BundleInfo wraps a minimal amount of data about a bundle.
 type BundleInfo struct {
 	Name           string
@@ -46,8 +54,16 @@ type Bundle struct {
 	Image             string                    `yaml:",omitempty" json:",omitempty"`
 	InstalledOn       time.Time                 `yaml:"-" json:",omitempty"`
 	InstalledBy       string                    `yaml:",omitempty" json:",omitempty"`
+	Owners            []string                  `yaml:",omitempty" json:",omitempty"`
+	Review            BundleReview              `yaml:"-" json:",omitempty"`
 	LongDescription   string                    `yaml:"long_description,omitempty" json:",omitempty"`
+	OutputFilters     OutputFilters             `yaml:"output_filters,omitempty" json:",omitempty"`
 	Kubernetes        BundleKubernetes          `yaml:",omitempty" json:",omitempty"`
+	Serverless        BundleServerless          `yaml:",omitempty" json:",omitempty"`
+	SSH               BundleSSH                 `yaml:"ssh,omitempty" json:",omitempty"`
+	Telemetry         TelemetryAttributes       `yaml:"telemetry_attributes,omitempty" json:",omitempty"`
+	Platform          BundlePlatform            `yaml:",omitempty" json:",omitempty"`
+	Resources         BundleResources           `yaml:"resources,omitempty" json:",omitempty"`
 	Permissions       []string                  `yaml:",omitempty" json:",omitempty"`
 	Commands          map[string]*BundleCommand `yaml:",omitempty" json:",omitempty"`
 	Default           bool                      `yaml:"-" json:",omitempty"`
@@ -100,25 +116,279 @@ func (b Bundle) Semver() semver.Version {
 // BundleCommand represents a bundle command, as defined in the "bundles/commands"
 // section of the config.
 type BundleCommand struct {
-	Description     string    `yaml:",omitempty" json:"description,omitempty"`
-	Executable      []string  `yaml:",omitempty,flow" json:"executable,omitempty"`
-	LongDescription string    `yaml:"long_description,omitempty" json:"long_description,omitempty"`
-	Name            string    `yaml:"-" json:"-"`
-	Triggers        []Trigger `yaml:"triggers,omitempty" json:"trigger,omitempty"`
-	Rules           []string  `yaml:",omitempty" json:"rules,omitempty"`
-	Templates       Templates `yaml:",omitempty" json:"templates,omitempty"`
+	ANSI            ANSIMode                         `yaml:"ansi,omitempty" json:"ansi,omitempty"`
+	Cooldown        string                           `yaml:",omitempty" json:"cooldown,omitempty"`
+	DefaultProfile  string                           `yaml:"default_profile,omitempty" json:"default_profile,omitempty"`
+	Description     string                           `yaml:",omitempty" json:"description,omitempty"`
+	EphemeralOutput bool                             `yaml:"ephemeral_output,omitempty" json:"ephemeral_output,omitempty"`
+	EphemeralTTL    string                           `yaml:"ephemeral_ttl,omitempty" json:"ephemeral_ttl,omitempty"`
+	Examples        []string                         `yaml:",omitempty" json:"examples,omitempty"`
+	Exclusive       string                           `yaml:",omitempty" json:"exclusive,omitempty"`
+	Executable      []string                         `yaml:",omitempty,flow" json:"executable,omitempty"`
+	LongDescription string                           `yaml:"long_description,omitempty" json:"long_description,omitempty"`
+	Name            string                           `yaml:"-" json:"-"`
+	NativeHelp      bool                             `yaml:"native_help,omitempty" json:"native_help,omitempty"`
+	Options         map[string]*BundleCommandOption  `yaml:",omitempty" json:"options,omitempty"`
+	Platform        BundlePlatform                   `yaml:",omitempty" json:"platform,omitempty"`
+	Profiles        map[string]*BundleCommandProfile `yaml:",omitempty" json:"profiles,omitempty"`
+	Reactions       CommandReactions                 `yaml:"reactions,omitempty" json:"reactions,omitempty"`
+	Triggers        []Trigger                        `yaml:"triggers,omitempty" json:"trigger,omitempty"`
+	Rules           []string                         `yaml:",omitempty" json:"rules,omitempty"`
+	SecretOutput    bool                             `yaml:"secret_output,omitempty" json:"secret_output,omitempty"`
+	Templates       Templates                        `yaml:",omitempty" json:"templates,omitempty"`
+	Timeout         string                           `yaml:",omitempty" json:"timeout,omitempty"`
+	Usage           string                           `yaml:",omitempty" json:"usage,omitempty"`
+}
+
+// CooldownDuration parses the command's Cooldown value, which is a Go
+// duration string like "90s" or "5m". A command with no cooldown returns 0.
+func (c BundleCommand) CooldownDuration() (time.Duration, error) {
+	if c.Cooldown == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(c.Cooldown)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cooldown %q: %w", c.Cooldown, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid cooldown %q: must not be negative", c.Cooldown)
+	}
+
+	return d, nil
+}
+
+// TimeoutDuration parses the command's Timeout value, which is a Go duration
+// string like "30s" or "10m". A command with no timeout returns 0, in which
+// case global.command_timeout applies.
+func (c BundleCommand) TimeoutDuration() (time.Duration, error) {
+	if c.Timeout == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", c.Timeout, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be positive", c.Timeout)
+	}
+
+	return d, nil
+}
+
+// DefaultEphemeralTTL is how long the output of an ephemeral_output command
+// remains visible when the command doesn't specify an ephemeral_ttl.
+const DefaultEphemeralTTL = 5 * time.Minute
+
+// EphemeralDuration returns how long the command's output should remain
+// visible before Gort deletes it. A command without ephemeral_output
+// returns 0.
+func (c BundleCommand) EphemeralDuration() (time.Duration, error) {
+	if !c.EphemeralOutput {
+		return 0, nil
+	}
+
+	if c.EphemeralTTL == "" {
+		return DefaultEphemeralTTL, nil
+	}
+
+	d, err := time.ParseDuration(c.EphemeralTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ephemeral_ttl %q: %w", c.EphemeralTTL, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid ephemeral_ttl %q: must be positive", c.EphemeralTTL)
+	}
+
+	return d, nil
+}
+
+// BundleCommandOption describes a single command option, as defined in the
+// bundles/commands/options section of the config. Options don't need to be
+// declared to be used; this only exists to attach metadata to them.
+type BundleCommandOption struct {
+	Description string `yaml:",omitempty" json:"description,omitempty"`
+
+	// Sensitive options have their values redacted from logs, trace spans,
+	// and stored command records.
+	Sensitive bool `yaml:",omitempty" json:"sensitive,omitempty"`
+
+	// Permissions maps option values to the bundle permissions (without the
+	// bundle name prefix) that a user must hold to use them. For example,
+	// {"prod": "deploy-prod"} requires "deploy-prod" whenever the option's
+	// value is "prod". Values that aren't listed require no additional
+	// permission.
+	Permissions map[string]string `yaml:",omitempty" json:"permissions,omitempty"`
+}
+
+// BundleCommandProfile describes a named execution profile for a command, as
+// defined in the bundles/commands/profiles section of the config. A profile
+// is selected at invocation with the reserved "--profile" option, and
+// changes the environment the command executes in.
+type BundleCommandProfile struct {
+	Description string `yaml:",omitempty" json:"description,omitempty"`
+
+	// Env is a set of environment variables added to the command's
+	// environment. These take precedence over dynamic configuration values.
+	Env map[string]string `yaml:",omitempty" json:"env,omitempty"`
+
+	// Cluster, EnvSecret, and ServiceAccountName override the bundle's
+	// Kubernetes settings of the same names when this profile is selected.
+	Cluster            string `yaml:"cluster,omitempty" json:"cluster,omitempty"`
+	EnvSecret          string `yaml:"env_secret,omitempty" json:"env_secret,omitempty"`
+	ServiceAccountName string `yaml:"serviceAccountName,omitempty" json:"serviceAccountName,omitempty"`
+
+	// Permission, if set, is a bundle permission (without the bundle name
+	// prefix) that a user must hold to select this profile, in addition to
+	// satisfying the command's rules.
+	Permission string `yaml:",omitempty" json:"permission,omitempty"`
+}
+
+// Profile returns the named execution profile. If name is empty, the
+// command's DefaultProfile is used. A command with no profiles, invoked
+// with no profile name, returns a nil profile and no error.
+func (c BundleCommand) Profile(name string) (string, *BundleCommandProfile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return "", nil, nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrNoSuchProfile, name)
+	}
+	if p == nil {
+		p = &BundleCommandProfile{}
+	}
+
+	return name, p, nil
+}
+
+// ValidateProfiles checks that the command's default profile exists and
+// that every profile permission is declared by the bundle.
+func (c BundleCommand) ValidateProfiles(permissions []string) error {
+	if c.DefaultProfile != "" {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			return fmt.Errorf("default_profile: %w: %q", ErrNoSuchProfile, c.DefaultProfile)
+		}
+	}
+
+	for name, p := range c.Profiles {
+		if name == "" {
+			return fmt.Errorf("profiles: profile name must not be empty")
+		}
+		if p == nil || p.Permission == "" {
+			continue
+		}
+
+		found := false
+		for _, perm := range permissions {
+			if perm == p.Permission {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("profile %s: permission %q is not declared by the bundle", name, p.Permission)
+		}
+	}
+
+	return nil
+}
+
+// ValidateOptionPermissions checks that every permission required by an
+// option value is declared by the bundle.
+func (c BundleCommand) ValidateOptionPermissions(permissions []string) error {
+	for name, o := range c.Options {
+		if o == nil {
+			continue
+		}
+
+		for value, p := range o.Permissions {
+			found := false
+			for _, perm := range permissions {
+				if perm == p {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("option %s: value %q: permission %q is not declared by the bundle", name, value, p)
+			}
+		}
+	}
+
+	return nil
 }
 
 // Trigger represents the configuration for a command trigger as defined
 // in the bundles/commands/triggers section of the config.
 type Trigger struct {
 	Match string `yaml:"match" json:"match"`
+
+	// Channels, if set, restricts the trigger to messages posted in the
+	// listed channels, each given by ID or by name. If empty, the trigger
+	// matches messages in any channel.
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty"`
 }
 
+// UnmarshalYAML allows a trigger to be given either as a mapping with
+// "match" and "channels" keys or as a single regular expression string.
+func (t *Trigger) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*t = Trigger{Match: value.Value}
+		return nil
+	}
+
+	type plain Trigger
+	return value.Decode((*plain)(t))
+}
+
+// AllowsChannel returns true if the trigger isn't restricted to specific
+// channels, or if the channel with the given ID or name is one of them.
+// Channel names may be written with or without a leading "#".
+func (t Trigger) AllowsChannel(id, name string) bool {
+	if len(t.Channels) == 0 {
+		return true
+	}
+
+	for _, c := range t.Channels {
+		if id != "" && c == id {
+			return true
+		}
+		if name != "" && strings.EqualFold(strings.TrimPrefix(c, "#"), strings.TrimPrefix(name, "#")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TriggersForChannel returns the command's triggers that are allowed in the
+// channel with the given ID or name.
+func (c *BundleCommand) TriggersForChannel(id, name string) []Trigger {
+	var triggers []Trigger
+	for _, t := range c.Triggers {
+		if t.AllowsChannel(id, name) {
+			triggers = append(triggers, t)
+		}
+	}
+	return triggers
+}
+
+// MatchTrigger returns true if any of the command's triggers matches
+// message, regardless of any channel restrictions.
 func (c *BundleCommand) MatchTrigger(ctx context.Context, message string) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+
 	for _, trigger := range c.Triggers {
-		if c == nil || len(trigger.Match) == 0 {
-			return false, nil
+		if len(trigger.Match) == 0 {
+			continue
 		}
 		// TODO: Compile regexes up-front for improved performance
 		re, err := regexp.Compile(trigger.Match)
@@ -132,10 +402,274 @@ func (c *BundleCommand) MatchTrigger(ctx context.Context, message string) (bool,
 	return false, nil
 }
 
+// TriggerMatchLength returns the length of the longest match of any of the
+// command's triggers in message, or -1 if none of them match.
+func (c *BundleCommand) TriggerMatchLength(message string) (int, error) {
+	longest := -1
+	for _, trigger := range c.Triggers {
+		if len(trigger.Match) == 0 {
+			continue
+		}
+		re, err := regexp.Compile(trigger.Match)
+		if err != nil {
+			return -1, err
+		}
+		if loc := re.FindStringIndex(message); loc != nil && loc[1]-loc[0] > longest {
+			longest = loc[1] - loc[0]
+		}
+	}
+	return longest, nil
+}
+
 // BundleKubernetes represents the "bundles/kubernetes" subsection of the config doc
 type BundleKubernetes struct {
-	ServiceAccountName string `yaml:"serviceAccountName,omitempty" json:"serviceAccountName,omitempty"`
-	EnvSecret          string `yaml:"env_secret,omitempty" json:"env_secret,omitempty"`
+	ServiceAccountName string            `yaml:"serviceAccountName,omitempty" json:"serviceAccountName,omitempty"`
+	EnvSecret          string            `yaml:"env_secret,omitempty" json:"env_secret,omitempty"`
+	NodeSelector       map[string]string `yaml:"node_selector,omitempty" json:"node_selector,omitempty"`
+
+	// Cluster is the name of the cluster, from the kubernetes.clusters
+	// section of the config, that the bundle's commands run on. If empty,
+	// the default cluster is used.
+	Cluster string `yaml:"cluster,omitempty" json:"cluster,omitempty"`
+
+	// Namespace is the namespace that the bundle's commands run in. If
+	// empty, the cluster's job namespace is used.
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// GroupServiceAccounts maps Gort group names to the Kubernetes service
+	// account that commands invoked by members of that group run as. Users
+	// in no mapped group fall back to ServiceAccountName.
+	GroupServiceAccounts map[string]string `yaml:"group_service_accounts,omitempty" json:"group_service_accounts,omitempty"`
+}
+
+// ServiceAccountFor returns the service account that a command should run
+// as when invoked by a member of the given groups. If more than one group
+// is mapped, the mapping for the group that sorts first by name is used.
+func (k BundleKubernetes) ServiceAccountFor(groups []string) string {
+	sorted := make([]string, len(groups))
+	copy(sorted, groups)
+	sort.Strings(sorted)
+
+	for _, g := range sorted {
+		if sa, ok := k.GroupServiceAccounts[g]; ok {
+			return sa
+		}
+	}
+
+	return k.ServiceAccountName
+}
+
+// ValidateGroupServiceAccounts checks that every group service account
+// mapping has a group name and a service account name.
+func (k BundleKubernetes) ValidateGroupServiceAccounts() error {
+	for g, sa := range k.GroupServiceAccounts {
+		if g == "" {
+			return fmt.Errorf("group_service_accounts: group name must not be empty")
+		}
+		if sa == "" {
+			return fmt.Errorf("group_service_accounts: group %s: service account name must not be empty", g)
+		}
+	}
+
+	return nil
+}
+
+// ValidateNamespace checks that the bundle's namespace, if it has one, is a
+// valid Kubernetes namespace name.
+func (k BundleKubernetes) ValidateNamespace() error {
+	if k.Namespace == "" {
+		return nil
+	}
+	return ValidateKubernetesNamespace(k.Namespace)
+}
+
+// Bundle review statuses. A bundle version that was installed while review
+// was required has a pending status until it's approved or rejected; a
+// version installed otherwise has an empty status.
+const (
+	BundleReviewPending  = "pending"
+	BundleReviewApproved = "approved"
+	BundleReviewRejected = "rejected"
+)
+
+// BundleReview is the review state of an installed bundle version.
+type BundleReview struct {
+	Status     string    `json:",omitempty"`
+	Reviewer   string    `json:",omitempty"`
+	ReviewedOn time.Time `json:",omitempty"`
+	Comment    string    `json:",omitempty"`
+}
+
+// Enableable returns true if the bundle version may be enabled: either it
+// was never subject to review, or it was approved.
+func (r BundleReview) Enableable() bool {
+	return r.Status == "" || r.Status == BundleReviewApproved
+}
+
+// BundleServerless represents the "bundles/serverless" subsection of the
+// config doc. A serverless bundle's commands are executed by invoking a
+// function or job rather than by running its image. At most one of
+// Function and Job may be set.
+type BundleServerless struct {
+	// Function is the name or ARN of an AWS Lambda function.
+	Function string `yaml:"function,omitempty" json:"function,omitempty"`
+
+	// Job is the name of a Google Cloud Run job.
+	Job string `yaml:"job,omitempty" json:"job,omitempty"`
+}
+
+// IsZero returns true if the bundle isn't serverless.
+func (s BundleServerless) IsZero() bool {
+	return s.Function == "" && s.Job == ""
+}
+
+// Validate checks that at most one of Function and Job is set.
+func (s BundleServerless) Validate() error {
+	if s.Function != "" && s.Job != "" {
+		return fmt.Errorf("only one of function and job may be set")
+	}
+	return nil
+}
+
+// BundleSSH represents the "bundles/ssh" subsection of the config doc. An
+// SSH bundle's commands are executed on each of the selected hosts from the
+// "ssh" config section, rather than in a container.
+type BundleSSH struct {
+	// Hosts are the names of the hosts to execute commands on.
+	Hosts []string `yaml:"hosts,omitempty,flow" json:"hosts,omitempty"`
+
+	// Tags select every host that has at least one of them.
+	Tags []string `yaml:"tags,omitempty,flow" json:"tags,omitempty"`
+}
+
+// IsZero returns true if the bundle doesn't select any SSH hosts.
+func (s BundleSSH) IsZero() bool {
+	return len(s.Hosts) == 0 && len(s.Tags) == 0
+}
+
+// Supported values for BundlePlatform.OS.
+const (
+	PlatformLinux   = "linux"
+	PlatformWindows = "windows"
+)
+
+// BundlePlatform represents the "bundles/platform" subsection of the config
+// doc. It describes the operating system and architecture that a bundle's
+// image is built for, which is used to select the image variant to pull and
+// the nodes the command can be scheduled on. If OS is empty, linux is assumed.
+type BundlePlatform struct {
+	OS   string `yaml:"os,omitempty" json:"os,omitempty"`
+	Arch string `yaml:"arch,omitempty" json:"arch,omitempty"`
+}
+
+// IsWindows returns true if the bundle's image is a Windows container image.
+func (p BundlePlatform) IsWindows() bool {
+	return strings.EqualFold(p.OS, PlatformWindows)
+}
+
+// String returns the platform in "os/arch" form, as understood by Docker.
+// If neither OS nor Arch is set an empty string is returned.
+func (p BundlePlatform) String() string {
+	switch {
+	case p.OS == "" && p.Arch == "":
+		return ""
+	case p.OS == "":
+		return PlatformLinux + "/" + p.Arch
+	case p.Arch == "":
+		return strings.ToLower(p.OS)
+	default:
+		return strings.ToLower(p.OS) + "/" + p.Arch
+	}
+}
+
+// Supported values for BundlePlatform.Arch. These are the GOARCH names used
+// by Docker and by the "kubernetes.io/arch" node label.
+var PlatformArchitectures = []string{"386", "amd64", "arm", "arm64", "ppc64le", "s390x"}
+
+// ParsePlatform parses a platform string in "os", "os/arch", or "os/arch/variant"
+// form (for example, "linux/arm64"). Any variant is ignored.
+func ParsePlatform(s string) (BundlePlatform, error) {
+	ss := strings.Split(s, "/")
+	if len(ss) > 3 || ss[0] == "" {
+		return BundlePlatform{}, fmt.Errorf("invalid platform %q: expected os/arch", s)
+	}
+
+	p := BundlePlatform{OS: strings.ToLower(ss[0])}
+	if len(ss) > 1 {
+		p.Arch = strings.ToLower(ss[1])
+	}
+
+	return p, p.Validate()
+}
+
+// UnmarshalYAML allows a platform to be given either as a mapping with "os"
+// and "arch" keys or as a single "os/arch" string.
+func (p *BundlePlatform) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		parsed, err := ParsePlatform(value.Value)
+		if err != nil {
+			return err
+		}
+		*p = parsed
+		return nil
+	}
+
+	type plain BundlePlatform
+	return value.Decode((*plain)(p))
+}
+
+// Override returns a copy of p with any fields set in o replacing those in p.
+func (p BundlePlatform) Override(o BundlePlatform) BundlePlatform {
+	if o.OS != "" {
+		p.OS = o.OS
+	}
+	if o.Arch != "" {
+		p.Arch = o.Arch
+	}
+	return p
+}
+
+// Validate returns an error if the platform's OS or architecture isn't
+// supported.
+func (p BundlePlatform) Validate() error {
+	switch strings.ToLower(p.OS) {
+	case "", PlatformLinux, PlatformWindows:
+	default:
+		return fmt.Errorf("unsupported bundle platform os %q: must be one of %v",
+			p.OS, []string{PlatformLinux, PlatformWindows})
+	}
+
+	if p.Arch == "" {
+		return nil
+	}
+
+	for _, a := range PlatformArchitectures {
+		if strings.EqualFold(p.Arch, a) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported bundle platform arch %q: must be one of %v",
+		p.Arch, PlatformArchitectures)
+}
+
+// ValidatePlatforms returns an error if the bundle's platform, or the
+// platform of any of its commands, isn't supported.
+func (b Bundle) ValidatePlatforms() error {
+	if err := b.Platform.Validate(); err != nil {
+		return err
+	}
+
+	for name, cmd := range b.Commands {
+		if cmd == nil {
+			continue
+		}
+		if err := cmd.Platform.Validate(); err != nil {
+			return fmt.Errorf("command %s: %w", name, err)
+		}
+	}
+
+	return nil
 }
 
 // CoerceVersionToSemver takes a version number and attempts to coerce it
@@ -18,9 +18,11 @@ package data
 
 import (
 	"testing"
+	"time"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func TestBundleImageFullParts(t *testing.T) {
@@ -44,6 +46,252 @@ func TestBundleImageFullParts(t *testing.T) {
 	}
 }
 
+func TestBundlePlatform(t *testing.T) {
+	tests := []struct {
+		Platform        BundlePlatform
+		ExpectedString  string
+		ExpectedWindows bool
+		ExpectedValid   bool
+	}{
+		{BundlePlatform{}, "", false, true},
+		{BundlePlatform{OS: "linux"}, "linux", false, true},
+		{BundlePlatform{Arch: "arm64"}, "linux/arm64", false, true},
+		{BundlePlatform{OS: "windows", Arch: "amd64"}, "windows/amd64", true, true},
+		{BundlePlatform{OS: "Windows"}, "windows", true, true},
+		{BundlePlatform{OS: "plan9"}, "plan9", false, false},
+		{BundlePlatform{OS: "linux", Arch: "mips"}, "linux/mips", false, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.ExpectedString, test.Platform.String())
+		assert.Equal(t, test.ExpectedWindows, test.Platform.IsWindows())
+		assert.Equal(t, test.ExpectedValid, test.Platform.Validate() == nil)
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		Value    string
+		Expected BundlePlatform
+		Valid    bool
+	}{
+		{"linux", BundlePlatform{OS: "linux"}, true},
+		{"linux/arm64", BundlePlatform{OS: "linux", Arch: "arm64"}, true},
+		{"linux/arm/v7", BundlePlatform{OS: "linux", Arch: "arm"}, true},
+		{"Windows/AMD64", BundlePlatform{OS: "windows", Arch: "amd64"}, true},
+		{"", BundlePlatform{}, false},
+		{"/amd64", BundlePlatform{}, false},
+		{"linux/sparc", BundlePlatform{OS: "linux", Arch: "sparc"}, false},
+		{"linux/arm/v7/x", BundlePlatform{}, false},
+	}
+
+	for _, test := range tests {
+		p, err := ParsePlatform(test.Value)
+		assert.Equal(t, test.Valid, err == nil, test.Value)
+		if test.Valid {
+			assert.Equal(t, test.Expected, p, test.Value)
+		}
+	}
+}
+
+func TestBundlePlatformOverride(t *testing.T) {
+	bundle := BundlePlatform{OS: "linux", Arch: "amd64"}
+
+	assert.Equal(t, bundle, bundle.Override(BundlePlatform{}))
+	assert.Equal(t, BundlePlatform{OS: "linux", Arch: "arm64"}, bundle.Override(BundlePlatform{Arch: "arm64"}))
+	assert.Equal(t, BundlePlatform{OS: "windows", Arch: "amd64"}, bundle.Override(BundlePlatform{OS: "windows"}))
+}
+
+func TestBundleCommandCooldownDuration(t *testing.T) {
+	tests := []struct {
+		Cooldown string
+		Expected time.Duration
+		Valid    bool
+	}{
+		{"", 0, true},
+		{"90s", 90 * time.Second, true},
+		{"5m", 5 * time.Minute, true},
+		{"5", 0, false},
+		{"-1m", 0, false},
+	}
+
+	for _, test := range tests {
+		d, err := BundleCommand{Cooldown: test.Cooldown}.CooldownDuration()
+		assert.Equal(t, test.Valid, err == nil, test.Cooldown)
+		assert.Equal(t, test.Expected, d, test.Cooldown)
+	}
+}
+
+func TestBundleCommandEphemeralDuration(t *testing.T) {
+	tests := []struct {
+		Ephemeral bool
+		TTL       string
+		Expected  time.Duration
+		Valid     bool
+	}{
+		{false, "", 0, true},
+		{false, "10m", 0, true},
+		{true, "", DefaultEphemeralTTL, true},
+		{true, "90s", 90 * time.Second, true},
+		{true, "0s", 0, false},
+		{true, "-1m", 0, false},
+		{true, "5", 0, false},
+	}
+
+	for _, test := range tests {
+		d, err := BundleCommand{EphemeralOutput: test.Ephemeral, EphemeralTTL: test.TTL}.EphemeralDuration()
+		assert.Equal(t, test.Valid, err == nil, test.TTL)
+		assert.Equal(t, test.Expected, d, test.TTL)
+	}
+}
+
+func TestBundleCommandTimeoutDuration(t *testing.T) {
+	tests := []struct {
+		Timeout  string
+		Expected time.Duration
+		Valid    bool
+	}{
+		{"", 0, true},
+		{"30s", 30 * time.Second, true},
+		{"10m", 10 * time.Minute, true},
+		{"0s", 0, false},
+		{"-1m", 0, false},
+		{"30", 0, false},
+	}
+
+	for _, test := range tests {
+		d, err := BundleCommand{Timeout: test.Timeout}.TimeoutDuration()
+		assert.Equal(t, test.Valid, err == nil, test.Timeout)
+		assert.Equal(t, test.Expected, d, test.Timeout)
+	}
+}
+
+func TestBundleCommandProfile(t *testing.T) {
+	staging := &BundleCommandProfile{Env: map[string]string{"STAGE": "staging"}}
+	prod := &BundleCommandProfile{Permission: "deploy_prod"}
+
+	cmd := BundleCommand{
+		DefaultProfile: "staging",
+		Profiles:       map[string]*BundleCommandProfile{"staging": staging, "prod": prod},
+	}
+
+	name, p, err := cmd.Profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", name)
+	assert.Equal(t, staging, p)
+
+	name, p, err = cmd.Profile("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", name)
+	assert.Equal(t, prod, p)
+
+	_, _, err = cmd.Profile("qa")
+	assert.ErrorIs(t, err, ErrNoSuchProfile)
+
+	name, p, err = BundleCommand{}.Profile("")
+	assert.NoError(t, err)
+	assert.Empty(t, name)
+	assert.Nil(t, p)
+
+	assert.NoError(t, cmd.ValidateProfiles([]string{"deploy_prod"}))
+	assert.Error(t, cmd.ValidateProfiles(nil))
+
+	cmd.DefaultProfile = "qa"
+	assert.ErrorIs(t, cmd.ValidateProfiles([]string{"deploy_prod"}), ErrNoSuchProfile)
+}
+
+func TestBundleCommandValidateOptionPermissions(t *testing.T) {
+	cmd := BundleCommand{
+		Options: map[string]*BundleCommandOption{
+			"env":     {Permissions: map[string]string{"prod": "deploy_prod", "staging": "deploy_staging"}},
+			"verbose": nil,
+		},
+	}
+
+	assert.NoError(t, cmd.ValidateOptionPermissions([]string{"deploy_prod", "deploy_staging"}))
+	assert.Error(t, cmd.ValidateOptionPermissions([]string{"deploy_prod"}))
+	assert.NoError(t, BundleCommand{}.ValidateOptionPermissions(nil))
+}
+
+func TestBundleKubernetesServiceAccountFor(t *testing.T) {
+	k := BundleKubernetes{
+		ServiceAccountName: "default-sa",
+		GroupServiceAccounts: map[string]string{
+			"ops": "ops-sa",
+			"dev": "dev-sa",
+		},
+	}
+
+	assert.Equal(t, "default-sa", k.ServiceAccountFor(nil))
+	assert.Equal(t, "default-sa", k.ServiceAccountFor([]string{"sales"}))
+	assert.Equal(t, "ops-sa", k.ServiceAccountFor([]string{"sales", "ops"}))
+	assert.Equal(t, "dev-sa", k.ServiceAccountFor([]string{"ops", "dev"}))
+	assert.Equal(t, "", BundleKubernetes{}.ServiceAccountFor([]string{"ops"}))
+
+	assert.NoError(t, k.ValidateGroupServiceAccounts())
+	assert.Error(t, BundleKubernetes{GroupServiceAccounts: map[string]string{"ops": ""}}.ValidateGroupServiceAccounts())
+
+	assert.NoError(t, k.ValidateNamespace())
+	assert.NoError(t, BundleKubernetes{Namespace: "team-jobs"}.ValidateNamespace())
+	assert.Error(t, BundleKubernetes{Namespace: "team.jobs"}.ValidateNamespace())
+}
+
+func TestBundleServerless(t *testing.T) {
+	assert.True(t, BundleServerless{}.IsZero())
+	assert.False(t, BundleServerless{Function: "echo"}.IsZero())
+
+	assert.NoError(t, BundleServerless{Function: "echo"}.Validate())
+	assert.NoError(t, BundleServerless{Job: "echo"}.Validate())
+	assert.Error(t, BundleServerless{Function: "echo", Job: "echo"}.Validate())
+}
+
+func TestBundleCommandTriggerMatchLength(t *testing.T) {
+	c := &BundleCommand{Triggers: []Trigger{{Match: `deploy`}, {Match: `deploy \w+`}}}
+
+	n, err := c.TriggerMatchLength("please deploy web now")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+
+	n, err = c.TriggerMatchLength("rollback")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, n)
+
+	_, err = (&BundleCommand{Triggers: []Trigger{{Match: `(`}}}).TriggerMatchLength("x")
+	assert.Error(t, err)
+}
+
+func TestTriggerUnmarshalYAML(t *testing.T) {
+	var c BundleCommand
+
+	err := yaml.Unmarshal([]byte(`
+triggers:
+  - JIRA-\d+
+  - match: deploy
+    channels: [ops, "#release"]
+`), &c)
+	assert.NoError(t, err)
+	assert.Equal(t, []Trigger{
+		{Match: `JIRA-\d+`},
+		{Match: `deploy`, Channels: []string{"ops", "#release"}},
+	}, c.Triggers)
+}
+
+func TestTriggerAllowsChannel(t *testing.T) {
+	assert.True(t, Trigger{}.AllowsChannel("C123", "general"))
+	assert.True(t, Trigger{}.AllowsChannel("", ""))
+
+	tr := Trigger{Channels: []string{"C123", "#Ops"}}
+	assert.True(t, tr.AllowsChannel("C123", "general"))
+	assert.True(t, tr.AllowsChannel("C456", "ops"))
+	assert.True(t, tr.AllowsChannel("C456", "#ops"))
+	assert.False(t, tr.AllowsChannel("C456", "general"))
+	assert.False(t, tr.AllowsChannel("", ""))
+
+	c := &BundleCommand{Triggers: []Trigger{{Match: `a`}, tr}}
+	assert.Equal(t, []Trigger{{Match: `a`}}, c.TriggersForChannel("C456", "general"))
+	assert.Equal(t, c.Triggers, c.TriggersForChannel("C123", ""))
+}
+
 func TestCoerceVersionToSemver(t *testing.T) {
 	tests := []struct {
 		Version  string
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultCanaryInterval is how often canaries are run when
+	// global.canaries.interval isn't set.
+	DefaultCanaryInterval = 5 * time.Minute
+
+	// DefaultCanaryAlertThreshold is the number of consecutive failures
+	// after which a canary raises an alert, when
+	// global.canaries.alert_threshold isn't set.
+	DefaultCanaryAlertThreshold = 3
+
+	// DefaultCanaryUser is the Gort user that canaries are run as when
+	// global.canaries.user isn't set.
+	DefaultCanaryUser = "admin"
+
+	// DefaultCanaryDigestInterval is how often a canary digest is posted
+	// when global.canaries.digest_interval isn't set.
+	DefaultCanaryDigestInterval = 24 * time.Hour
+)
+
+// CanaryConfigs is the data wrapper for the "global.canaries" section, which
+// defines synthetic commands that are run through the command pipeline on a
+// schedule to continuously verify that it's working.
+type CanaryConfigs struct {
+	// Interval is how often each canary is run. Zero uses
+	// DefaultCanaryInterval.
+	Interval time.Duration `yaml:"interval,omitempty"`
+
+	// User is the Gort user that canaries are run as. Empty uses
+	// DefaultCanaryUser.
+	User string `yaml:"user,omitempty"`
+
+	// AlertAdapter and AlertChannel identify the channel that's alerted when
+	// a canary fails AlertThreshold times in a row, and again when it
+	// recovers. If either is empty, no alerts are sent.
+	AlertAdapter string `yaml:"alert_adapter,omitempty"`
+	AlertChannel string `yaml:"alert_channel,omitempty"`
+
+	// AlertThreshold is the number of consecutive failures that raises an
+	// alert. Zero uses DefaultCanaryAlertThreshold.
+	AlertThreshold int `yaml:"alert_threshold,omitempty"`
+
+	// Digest, if true, accumulates the results of every canary run and posts
+	// them to the alert channel as a single digest message every
+	// DigestInterval, rendered with the "digest" template. Recovery notices
+	// are left to the digest rather than being sent individually; failure
+	// alerts are still sent immediately.
+	Digest bool `yaml:"digest,omitempty"`
+
+	// DigestInterval is how often the digest is posted. Zero uses
+	// DefaultCanaryDigestInterval.
+	DigestInterval time.Duration `yaml:"digest_interval,omitempty"`
+
+	// Commands are the canaries to run.
+	Commands []CanaryCommand `yaml:"commands,omitempty"`
+}
+
+// Validate returns an error if any duration or count is negative, if only
+// one of AlertAdapter and AlertChannel is set, or if a digest is requested
+// without an alert channel to post it to.
+func (c CanaryConfigs) Validate() error {
+	switch {
+	case c.Interval < 0:
+		return fmt.Errorf("interval must not be negative")
+	case c.AlertThreshold < 0:
+		return fmt.Errorf("alert_threshold must not be negative")
+	case c.DigestInterval < 0:
+		return fmt.Errorf("digest_interval must not be negative")
+	case (c.AlertAdapter == "") != (c.AlertChannel == ""):
+		return fmt.Errorf("alert_adapter and alert_channel must be set together")
+	case c.Digest && c.AlertChannel == "":
+		return fmt.Errorf("digest requires alert_adapter and alert_channel")
+	}
+
+	return nil
+}
+
+// DigestIntervalOrDefault returns DigestInterval, or
+// DefaultCanaryDigestInterval if it's unset.
+func (c CanaryConfigs) DigestIntervalOrDefault() time.Duration {
+	if c.DigestInterval == 0 {
+		return DefaultCanaryDigestInterval
+	}
+	return c.DigestInterval
+}
+
+// CanaryCommand is a single canary: a command that's expected to succeed.
+type CanaryCommand struct {
+	// Name identifies the canary in metrics, status reports, and alerts.
+	Name string `yaml:"name,omitempty"`
+
+	// Command is the command to run, exactly as it would be typed in chat
+	// (without the leading "!"), like "gort:version --short".
+	Command string `yaml:"command,omitempty"`
+}
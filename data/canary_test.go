@@ -0,0 +1,39 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanaryConfigsValidate(t *testing.T) {
+	var c CanaryConfigs
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, DefaultCanaryDigestInterval, c.DigestIntervalOrDefault())
+
+	c = CanaryConfigs{AlertAdapter: "slack", AlertChannel: "C01", Digest: true, DigestInterval: time.Hour}
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, time.Hour, c.DigestIntervalOrDefault())
+
+	assert.Error(t, CanaryConfigs{Interval: -time.Minute}.Validate())
+	assert.Error(t, CanaryConfigs{DigestInterval: -time.Minute}.Validate())
+	assert.Error(t, CanaryConfigs{AlertAdapter: "slack"}.Validate())
+	assert.Error(t, CanaryConfigs{Digest: true}.Validate())
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import "time"
+
+// ChannelPresence records Gort's interactions with a single chat channel. It's
+// used to avoid greeting a channel more than once, and to find channels that
+// have gone unused long enough that Gort should leave them.
+type ChannelPresence struct {
+	// Adapter is the name of the adapter the channel belongs to.
+	Adapter string `json:"adapter"`
+
+	// ChannelID is the provider ID of the channel.
+	ChannelID string `json:"channel_id"`
+
+	// FirstSeen is the time the channel was first recorded.
+	FirstSeen time.Time `json:"first_seen"`
+
+	// GreetedAt is the time Gort greeted the channel. It's the zero value if
+	// the channel hasn't been greeted.
+	GreetedAt time.Time `json:"greeted_at,omitempty"`
+
+	// LastCommandAt is the time a command was last run from the channel.
+	// It's the zero value if none has been.
+	LastCommandAt time.Time `json:"last_command_at,omitempty"`
+}
+
+// LastActivity returns the time a command was last run from the channel or,
+// if one never has been, the time the channel was first seen.
+func (p ChannelPresence) LastActivity() time.Time {
+	if p.LastCommandAt.After(p.FirstSeen) {
+		return p.LastCommandAt
+	}
+
+	return p.FirstSeen
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChannelSettings holds the administrator-defined settings for a single chat
+// channel.
+type ChannelSettings struct {
+	// Adapter is the name of the adapter the channel belongs to.
+	Adapter string `json:"adapter"`
+
+	// ChannelID is the provider ID of the channel.
+	ChannelID string `json:"channel_id"`
+
+	// DefaultBundle is the bundle that unqualified commands invoked from the
+	// channel are looked up in first. If it's empty, or the bundle isn't
+	// enabled or has no such command, every enabled bundle is searched.
+	DefaultBundle string `json:"default_bundle,omitempty"`
+
+	// QuietHours, if set, is a daily window during which non-critical
+	// output to the channel is held back.
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+// QuietHours is a daily window during which a channel's non-critical Gort
+// output -- command acknowledgements and the results of scheduled jobs -- is
+// suppressed, or held and posted as a single digest when the window ends.
+// Critical and error output is always delivered immediately.
+type QuietHours struct {
+	// Start and End are the wall clock times, formatted as "15:04", at which
+	// quiet hours begin and end. If End is earlier than Start, quiet hours
+	// span midnight.
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	// Timezone is the IANA name of the time zone that Start and End are in.
+	// If it's empty, UTC is used.
+	Timezone string `json:"timezone,omitempty"`
+
+	// Digest, if true, holds output sent during quiet hours and posts it as
+	// a digest when they end. Otherwise, the output is discarded.
+	Digest bool `json:"digest,omitempty"`
+}
+
+// Validate returns an error if the quiet hours' times or time zone can't be
+// parsed, or if they start and end at the same time.
+func (q QuietHours) Validate() error {
+	start, end, _, err := q.parse()
+	if err != nil {
+		return err
+	}
+
+	if start == end {
+		return fmt.Errorf("quiet hours can't start and end at the same time")
+	}
+
+	return nil
+}
+
+// Contains returns true if t falls within quiet hours. It returns false if
+// the quiet hours are invalid.
+func (q QuietHours) Contains(t time.Time) bool {
+	start, end, loc, err := q.parse()
+	if err != nil || start == end {
+		return false
+	}
+
+	t = t.In(loc)
+	m := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+
+	if start < end {
+		return m >= start && m < end
+	}
+
+	return m >= start || m < end
+}
+
+// EndAfter returns the first time after t at which quiet hours end.
+func (q QuietHours) EndAfter(t time.Time) time.Time {
+	_, end, loc, err := q.parse()
+	if err != nil {
+		return t
+	}
+
+	t = t.In(loc)
+	h, m := int(end/time.Hour), int(end%time.Hour/time.Minute)
+
+	e := time.Date(t.Year(), t.Month(), t.Day(), h, m, 0, 0, loc)
+	if !e.After(t) {
+		e = time.Date(t.Year(), t.Month(), t.Day()+1, h, m, 0, 0, loc)
+	}
+
+	return e
+}
+
+// parse returns the start and end times as offsets from midnight, and the
+// time zone they're in.
+func (q QuietHours) parse() (time.Duration, time.Duration, *time.Location, error) {
+	start, err := parseClockTime(q.Start)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+
+	end, err := parseClockTime(q.End)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+
+	loc := time.UTC
+	if q.Timezone != "" {
+		if loc, err = time.LoadLocation(q.Timezone); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid quiet hours timezone: %w", err)
+		}
+	}
+
+	return start, end, loc, nil
+}
+
+// parseClockTime parses a "15:04" wall clock time into an offset from
+// midnight.
+func parseClockTime(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q isn't a time of the form HH:MM", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
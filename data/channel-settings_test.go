@@ -0,0 +1,69 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHoursValidate(t *testing.T) {
+	assert.NoError(t, QuietHours{Start: "22:00", End: "07:00"}.Validate())
+	assert.NoError(t, QuietHours{Start: "12:00", End: "13:30", Timezone: "America/New_York"}.Validate())
+
+	assert.Error(t, QuietHours{}.Validate())
+	assert.Error(t, QuietHours{Start: "22:00"}.Validate())
+	assert.Error(t, QuietHours{Start: "10pm", End: "07:00"}.Validate())
+	assert.Error(t, QuietHours{Start: "22:00", End: "22:00"}.Validate())
+	assert.Error(t, QuietHours{Start: "22:00", End: "07:00", Timezone: "Nowhere/Special"}.Validate())
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2021, 6, 1, hour, min, 0, 0, time.UTC)
+	}
+
+	overnight := QuietHours{Start: "22:00", End: "07:00"}
+	assert.True(t, overnight.Contains(at(22, 0)))
+	assert.True(t, overnight.Contains(at(3, 0)))
+	assert.False(t, overnight.Contains(at(7, 0)))
+	assert.False(t, overnight.Contains(at(12, 0)))
+
+	lunch := QuietHours{Start: "12:00", End: "13:00"}
+	assert.True(t, lunch.Contains(at(12, 30)))
+	assert.False(t, lunch.Contains(at(13, 0)))
+	assert.False(t, lunch.Contains(at(11, 59)))
+
+	// 22:00 in UTC is 18:00 in New York, during daylight saving time.
+	zoned := QuietHours{Start: "17:00", End: "19:00", Timezone: "America/New_York"}
+	assert.True(t, zoned.Contains(at(22, 0)))
+	assert.False(t, zoned.Contains(at(17, 30)))
+
+	assert.False(t, QuietHours{}.Contains(at(0, 0)))
+}
+
+func TestQuietHoursEndAfter(t *testing.T) {
+	overnight := QuietHours{Start: "22:00", End: "07:00"}
+
+	late := time.Date(2021, 6, 1, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2021, 6, 2, 7, 0, 0, 0, time.UTC), overnight.EndAfter(late))
+
+	early := time.Date(2021, 6, 2, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2021, 6, 2, 7, 0, 0, 0, time.UTC), overnight.EndAfter(early))
+}
@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	gerrs "github.com/getgort/gort/errors"
 )
 
 // CommandEntry conveniently wraps a bundle and one command within that bundle.
@@ -30,6 +32,34 @@ type CommandEntry struct {
 	Command BundleCommand
 }
 
+// Platform returns the platform the command's image should run on: the
+// bundle's platform, with any fields pinned by the command itself taking
+// precedence.
+func (e CommandEntry) Platform() BundlePlatform {
+	return e.Bundle.Platform.Override(e.Command.Platform)
+}
+
+// EntryPoint returns the container entrypoint for the command. Commands whose
+// executable is a PowerShell script (a "*.ps1" file) are run with the
+// PowerShell host for the command's platform: powershell.exe for Windows
+// images and pwsh for everything else.
+func (e CommandEntry) EntryPoint() []string {
+	exec := e.Command.Executable
+
+	if len(exec) == 0 || !strings.HasSuffix(strings.ToLower(exec[0]), ".ps1") {
+		return exec
+	}
+
+	var host []string
+	if e.Platform().IsWindows() {
+		host = []string{"powershell.exe", "-NoLogo", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File"}
+	} else {
+		host = []string{"pwsh", "-NoLogo", "-NoProfile", "-NonInteractive", "-File"}
+	}
+
+	return append(host, exec...)
+}
+
 type CommandParameters []string
 
 func (c CommandParameters) String() string {
@@ -40,15 +70,27 @@ func (c CommandParameters) String() string {
 // a chat provider.
 type CommandRequest struct {
 	CommandEntry
-	Adapter    string            // The name of the adapter this request originated from
-	ChannelID  string            // The provider ID of the channel that the request originated in
-	Context    context.Context   // The request context
-	Parameters CommandParameters // Tokenized command parameters
-	RequestID  int64             // A unique requestID
-	Timestamp  time.Time         // The time this request was triggered
-	UserID     string            // The provider ID of user making this request
-	UserEmail  string            // The email address associated with the user making the request
-	UserName   string            // The gort username of the user making the request
+	Adapter         string            // The name of the adapter this request originated from
+	ChannelID       string            // The provider ID of the channel that the request originated in
+	ChannelName     string            // The provider name of the channel that the request originated in
+	Locale          string            // The requesting user's locale, like "en-US"; used to format output
+	Context         context.Context   // The request context
+	Deadline        time.Time         // The time by which the request must complete; zero means no deadline
+	InputFile       *InputFile        // The file attached to the message that invoked the command, if any
+	InvocationText  string            // The message text that invoked the command, before tokenization (less any leading "!")
+	MessageID       string            // The provider ID of the message that invoked the command, if known
+	Next            *CommandRequest   // The next command of a pipeline, which receives this command's output; nil if there's none
+	Parameters      CommandParameters // Tokenized command parameters
+	Profile         string            // The name of the selected execution profile, if any
+	ReplayOf        int64             // The ID of the request that this request replays; zero if it's not a replay
+	RequestID       int64             // A unique requestID
+	Timestamp       time.Time         // The time this request was triggered
+	Timings         StageTimings      // How long each stage took; shared by copies of the request
+	UserDisplayName string            // The provider display name of the user making this request
+	UserID          string            // The provider ID of user making this request
+	UserEmail       string            // The email address associated with the user making the request
+	UserName        string            // The gort username of the user making the request
+	WorkerToken     string            // The signed, request-scoped token given to the worker; set when it's spawned, and never stored
 }
 
 // String is a convenience method that outputs the normalized command
@@ -57,6 +99,43 @@ func (r CommandRequest) String() string {
 	return fmt.Sprintf("%s:%s %s", r.Bundle.Name, r.Command.Name, r.Parameters)
 }
 
+// ExecutionProfile returns the request's selected execution profile, or nil
+// if no profile was selected.
+func (r CommandRequest) ExecutionProfile() *BundleCommandProfile {
+	if r.Profile == "" {
+		return nil
+	}
+
+	_, p, err := r.Command.Profile(r.Profile)
+	if err != nil {
+		return nil
+	}
+
+	return p
+}
+
+// KubernetesCluster returns the name of the cluster that the request should
+// be executed on: the selected profile's cluster if it names one, otherwise
+// the bundle's. The empty string refers to the default cluster.
+func (r CommandRequest) KubernetesCluster() string {
+	if p := r.ExecutionProfile(); p != nil && p.Cluster != "" {
+		return p.Cluster
+	}
+
+	return r.Bundle.Kubernetes.Cluster
+}
+
+// DeadlineContext returns a copy of ctx that's canceled at the request's
+// Deadline. If the request has no deadline, ctx is returned unchanged (with
+// a no-op cancel function).
+func (r CommandRequest) DeadlineContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.Deadline.IsZero() {
+		return ctx, func() {}
+	}
+
+	return context.WithDeadline(ctx, r.Deadline)
+}
+
 // CommandResponse wraps the response text emitted by an executed command.
 type CommandResponse struct {
 	// Lines contains the command output (from both stdout and stderr) as
@@ -72,6 +151,11 @@ type CommandResponse struct {
 	// to Out).
 	Structured bool
 
+	// Markdown is true if Out has already been formatted with the chat
+	// provider's markup, in which case templates shouldn't render it as
+	// monospace.
+	Markdown bool
+
 	// Title includes a title. Usually only set by the relay for certain
 	// internally-detected errors. It can be used to build a user output
 	// message, and generally contains a short description of the result.
@@ -88,11 +172,32 @@ type CommandResponseData struct {
 	// else?
 	Duration time.Duration
 
+	// WorkerDuration is how long the worker ran, from the time it was
+	// started until it exited. It's zero if a worker was never started.
+	WorkerDuration time.Duration
+
 	// ExitCode is the exit code reported by the command.
 	ExitCode int16
 
+	// Partial is true if the command was interrupted before it completed
+	// (for example, because its deadline was reached) and the response
+	// contains only the output captured up to that point.
+	Partial bool
+
+	// TimedOut is true if the command was stopped because it didn't
+	// complete within its timeout.
+	TimedOut bool
+
 	// Error is set by the relay under certain internal error conditions.
 	Error error
+
+	// ErrorCode is the user-facing error code (e.g., "GORT-3001") associated
+	// with Error, if there is one.
+	ErrorCode string
+
+	// ErrorDetail is the structured error reported by a failed command, if
+	// it reported one. See ErrorDetailKey.
+	ErrorDetail *ErrorDetail
 }
 
 // CommandResponseEnvelope encapsulates the data and metadata around a command
@@ -115,6 +220,35 @@ type CommandResponseEnvelope struct {
 	// it will be unmarshalled and placed here where it can be accessible to
 	// Go templates. If it's not, this will be a string equal to Out.
 	Payload interface{}
+
+	// Pipeline describes the commands that were executed before this one,
+	// in order, if it was part of a pipeline. The envelope itself describes
+	// the last command executed: the end of the pipeline, or the command
+	// that stopped it by failing.
+	Pipeline []PipelineStage
+
+	// Digest lists the scheduled commands summarized by a digest message.
+	// It's only set for envelopes rendered with the Digest template.
+	Digest []DigestEntry
+}
+
+// PipelineStage summarizes the execution of one command of a pipeline.
+type PipelineStage struct {
+	RequestID int64
+	Command   string // The command as "bundle:command"
+	ExitCode  int16
+	Duration  time.Duration
+}
+
+// NewPipelineStage summarizes the command execution described by an
+// envelope.
+func NewPipelineStage(e CommandResponseEnvelope) PipelineStage {
+	return PipelineStage{
+		RequestID: e.Request.RequestID,
+		Command:   e.Request.Bundle.Name + ":" + e.Request.Command.Name,
+		ExitCode:  e.Data.ExitCode,
+		Duration:  e.Data.Duration,
+	}
 }
 
 // NewCommandResponseEnvelope can be used to generate a new
@@ -136,6 +270,14 @@ func NewCommandResponseEnvelope(request CommandRequest, opts ...CommandResponseE
 // and accepted by NewCommandResponseEnvelope.
 type CommandResponseEnvelopeOption func(e *CommandResponseEnvelope)
 
+// WithDigest sets Digest and Response.Title.
+func WithDigest(title string, entries []DigestEntry) CommandResponseEnvelopeOption {
+	return func(e *CommandResponseEnvelope) {
+		e.Digest = entries
+		e.Response.Title = title
+	}
+}
+
 // WithExitCode sets Data.ExitCode. It does NOT set
 func WithExitCode(code int16) CommandResponseEnvelopeOption {
 	return func(e *CommandResponseEnvelope) {
@@ -143,12 +285,16 @@ func WithExitCode(code int16) CommandResponseEnvelopeOption {
 	}
 }
 
-// WithError sets Data.Error, Data.ExitCode, Response.Lines, Response.Out,
-// Response.Structured, Response.Title, and Payload (as err.Error).
+// WithError sets Data.Error, Data.ErrorCode, Data.ExitCode, Response.Lines,
+// Response.Out, Response.Structured, Response.Title, and Payload (as
+// err.Error).
 func WithError(title string, err error, code int16) CommandResponseEnvelopeOption {
 	return func(e *CommandResponseEnvelope) {
 		e.Data.Error = err
 		e.Data.ExitCode = code
+		if c, ok := gerrs.CodeOf(err); ok {
+			e.Data.ErrorCode = c.Code
+		}
 		e.Response.Lines = []string{err.Error()}
 		e.Response.Out = err.Error()
 		e.Response.Title = title
@@ -156,6 +302,33 @@ func WithError(title string, err error, code int16) CommandResponseEnvelopeOptio
 	}
 }
 
+// WithErrorDetail sets Data.ErrorDetail and, if the detail includes a code,
+// Data.ErrorCode. It should follow any WithError option.
+func WithErrorDetail(d *ErrorDetail) CommandResponseEnvelopeOption {
+	return func(e *CommandResponseEnvelope) {
+		e.Data.ErrorDetail = d
+		if d != nil && d.Code != "" {
+			e.Data.ErrorCode = d.Code
+		}
+	}
+}
+
+// WithPartial sets Data.Partial, indicating that the response contains
+// only part of the command's output.
+func WithPartial(partial bool) CommandResponseEnvelopeOption {
+	return func(e *CommandResponseEnvelope) {
+		e.Data.Partial = partial
+	}
+}
+
+// WithTimedOut sets Data.TimedOut, indicating that the command was stopped
+// because it didn't complete within its timeout.
+func WithTimedOut(timedOut bool) CommandResponseEnvelopeOption {
+	return func(e *CommandResponseEnvelope) {
+		e.Data.TimedOut = timedOut
+	}
+}
+
 // WithResponseLines sets Response.Lines, Response.Out, Response.Structured,
 // and Payload.
 func WithResponseLines(r []string) CommandResponseEnvelopeOption {
@@ -17,8 +17,10 @@
 package data
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -89,3 +91,94 @@ func TestNewCommandResponseEnvelope_WithStructuredResponseLines(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "Matt", p["Name"])
 }
+
+func TestNewCommandResponseEnvelope_WithPartial(t *testing.T) {
+	e := NewCommandResponseEnvelope(request, WithPartial(true))
+
+	assert.True(t, e.Data.Partial)
+}
+
+func TestCommandRequestDeadlineContext(t *testing.T) {
+	ctx, cancel := request.DeadlineContext(context.Background())
+	defer cancel()
+
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+
+	r := request
+	r.Deadline = time.Now().Add(time.Minute)
+
+	ctx, cancel = r.DeadlineContext(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, r.Deadline, deadline)
+}
+
+func TestCommandRequestKubernetesCluster(t *testing.T) {
+	r := CommandRequest{}
+	assert.Equal(t, "", r.KubernetesCluster())
+
+	r.Bundle.Kubernetes.Cluster = "us-east"
+	assert.Equal(t, "us-east", r.KubernetesCluster())
+
+	r.Command.Profiles = map[string]*BundleCommandProfile{
+		"eu":      {Cluster: "eu-west"},
+		"staging": {},
+	}
+
+	r.Profile = "eu"
+	assert.Equal(t, "eu-west", r.KubernetesCluster())
+
+	r.Profile = "staging"
+	assert.Equal(t, "us-east", r.KubernetesCluster())
+
+	kc := KubernetesConfigs{
+		Namespace:    "gort",
+		JobNamespace: "gort-jobs",
+		Clusters:     []KubernetesCluster{{Name: "eu-west", Context: "eu"}},
+	}
+
+	c, ok := kc.Cluster("")
+	assert.True(t, ok)
+	assert.Equal(t, KubernetesCluster{Namespace: "gort", JobNamespace: "gort-jobs"}, c)
+
+	c, ok = kc.Cluster("eu-west")
+	assert.True(t, ok)
+	assert.True(t, c.OutOfCluster())
+
+	_, ok = kc.Cluster("us-east")
+	assert.False(t, ok)
+}
+
+func TestCommandEntryEntryPoint(t *testing.T) {
+	tests := []struct {
+		Platform   BundlePlatform
+		Executable []string
+		Expected   []string
+	}{
+		{BundlePlatform{}, nil, nil},
+		{BundlePlatform{}, []string{"/bin/echo"}, []string{"/bin/echo"}},
+		{
+			BundlePlatform{},
+			[]string{"/scripts/deploy.ps1", "-Verbose"},
+			[]string{"pwsh", "-NoLogo", "-NoProfile", "-NonInteractive", "-File", "/scripts/deploy.ps1", "-Verbose"},
+		},
+		{
+			BundlePlatform{OS: "windows"},
+			[]string{`C:\scripts\Deploy.PS1`},
+			[]string{"powershell.exe", "-NoLogo", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", `C:\scripts\Deploy.PS1`},
+		},
+		{BundlePlatform{OS: "windows"}, []string{"cmd.exe", "/c", "dir"}, []string{"cmd.exe", "/c", "dir"}},
+	}
+
+	for _, test := range tests {
+		e := CommandEntry{
+			Bundle:  Bundle{Platform: test.Platform},
+			Command: BundleCommand{Executable: test.Executable},
+		}
+
+		assert.Equal(t, test.Expected, e.EntryPoint())
+	}
+}
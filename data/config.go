@@ -16,40 +16,237 @@
 
 package data
 
-import "time"
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
 
 // GortConfig is the top-level configuration object
 type GortConfig struct {
-	GortServerConfigs GortServerConfigs `yaml:"gort,omitempty"`
-	GlobalConfigs     GlobalConfigs     `yaml:"global,omitempty"`
-	DatabaseConfigs   DatabaseConfigs   `yaml:"database,omitempty"`
-	DockerConfigs     DockerConfigs     `yaml:"docker,omitempty"`
-	DynamicConfigs    DynamicConfigs    `yaml:"dynamic_configuration,omitempty"`
-	JaegerConfigs     JaegerConfigs     `yaml:"jaeger,omitempty"`
-	KubernetesConfigs KubernetesConfigs `yaml:"kubernetes,omitempty"`
-	SlackProviders    []SlackProvider   `yaml:"slack,omitempty"`
-	DiscordProviders  []DiscordProvider `yaml:"discord,omitempty"`
-	Templates         Templates         `yaml:"templates,omitempty"`
+	GortServerConfigs GortServerConfigs  `yaml:"gort,omitempty"`
+	GlobalConfigs     GlobalConfigs      `yaml:"global,omitempty"`
+	DatabaseConfigs   DatabaseConfigs    `yaml:"database,omitempty"`
+	DockerConfigs     DockerConfigs      `yaml:"docker,omitempty"`
+	DynamicConfigs    DynamicConfigs     `yaml:"dynamic_configuration,omitempty"`
+	EncryptionConfigs EncryptionConfigs  `yaml:"encryption,omitempty"`
+	Features          FeatureConfigs     `yaml:"features,omitempty"`
+	JaegerConfigs     JaegerConfigs      `yaml:"jaeger,omitempty"`
+	KubernetesConfigs KubernetesConfigs  `yaml:"kubernetes,omitempty"`
+	LDAPConfigs       LDAPConfigs        `yaml:"ldap,omitempty"`
+	MemoryStore       MemoryStoreConfigs `yaml:"memory_store,omitempty"`
+	MockConfigs       MockConfigs        `yaml:"mock,omitempty"`
+	NativeConfigs     NativeConfigs      `yaml:"native,omitempty"`
+	OIDCConfigs       OIDCConfigs        `yaml:"oidc,omitempty"`
+	ServerlessConfigs ServerlessConfigs  `yaml:"serverless,omitempty"`
+	SlackProviders    []SlackProvider    `yaml:"slack,omitempty"`
+	SSHConfigs        SSHConfigs         `yaml:"ssh,omitempty"`
+	DiscordProviders  []DiscordProvider  `yaml:"discord,omitempty"`
+	Templates         Templates          `yaml:"templates,omitempty"`
+	WorkerConfigs     WorkerConfigs      `yaml:"worker,omitempty"`
 }
 
 // GortServerConfigs is the data wrapper for the "gort" section.
 type GortServerConfigs struct {
-	AllowSelfRegistration bool   `yaml:"allow_self_registration,omitempty"`
-	APIAddress            string `yaml:"api_address,omitempty"`
-	APIURLBase            string `yaml:"api_url_base,omitempty"`
-	DevelopmentMode       bool   `yaml:"development_mode,omitempty"`
-	EnableSpokenCommands  bool   `yaml:"enable_spoken_commands,omitempty"`
-	TLSCertFile           string `yaml:"tls_cert_file,omitempty"`
-	TLSKeyFile            string `yaml:"tls_key_file,omitempty"`
+	AllowSelfRegistration    bool          `yaml:"allow_self_registration,omitempty"`
+	APIAddress               string        `yaml:"api_address,omitempty"`
+	APIURLBase               string        `yaml:"api_url_base,omitempty"`
+	CatchUpWindow            time.Duration `yaml:"catch_up_window,omitempty"`
+	ChannelInactivityTimeout time.Duration `yaml:"channel_inactivity_timeout,omitempty"`
+	DevelopmentMode          bool          `yaml:"development_mode,omitempty"`
+	EnableSpokenCommands     bool          `yaml:"enable_spoken_commands,omitempty"`
+	RequireBundleReview      bool          `yaml:"require_bundle_review,omitempty"`
+	TLSCertFile              string        `yaml:"tls_cert_file,omitempty"`
+	TLSKeyFile               string        `yaml:"tls_key_file,omitempty"`
 }
 
 // GlobalConfigs is the data wrapper for the "global" section
 type GlobalConfigs struct {
-	CommandTimeout time.Duration `yaml:"command_timeout,omitempty"`
+	AllowedImages    ImageAllowlist                 `yaml:"allowed_images,omitempty"`
+	APITokens        APITokenConfigs                `yaml:"api_tokens,omitempty"`
+	AutoRollback     AutoRollbackConfigs            `yaml:"auto_rollback,omitempty"`
+	BundleAnalysis   BundleAnalysisConfigs          `yaml:"bundle_analysis,omitempty"`
+	Canaries         CanaryConfigs                  `yaml:"canaries,omitempty"`
+	CommandTimeout   time.Duration                  `yaml:"command_timeout,omitempty"`
+	Costs            CostConfigs                    `yaml:"costs,omitempty"`
+	DeadLetters      DeadLetterConfigs              `yaml:"dead_letters,omitempty"`
+	DeletedRetention time.Duration                  `yaml:"deleted_retention,omitempty"`
+	FailureNotices   FailureNotificationConfigs     `yaml:"failure_notifications,omitempty"`
+	InputFiles       InputFileConfigs               `yaml:"input_files,omitempty"`
+	Janitor          JanitorConfigs                 `yaml:"janitor,omitempty"`
+	LatencyBudgets   map[RequestStage]time.Duration `yaml:"latency_budgets,omitempty"`
+	Locale           string                         `yaml:"locale,omitempty"`
+	Outbox           OutboxConfigs                  `yaml:"outbox,omitempty"`
+	OutputFilters    OutputFilters                  `yaml:"output_filters,omitempty"`
+	ProviderInfoTTL  time.Duration                  `yaml:"provider_info_ttl,omitempty"`
+	RedactPatterns   []string                       `yaml:"redact_patterns,omitempty"`
+	RequestArchive   RequestArchiveConfigs          `yaml:"request_archive,omitempty"`
+	SecretOutputTTL  time.Duration                  `yaml:"secret_output_ttl,omitempty"`
+	Triggers         TriggerConfigs                 `yaml:"triggers,omitempty"`
 }
 
+// DefaultDeletedRetention is how long deleted users and groups can be
+// restored when global.deleted_retention isn't set.
+const DefaultDeletedRetention = 7 * 24 * time.Hour
+
+// DefaultProviderInfoTTL is how long the user and channel info retrieved
+// from chat providers is cached when global.provider_info_ttl isn't set.
+const DefaultProviderInfoTTL = 5 * time.Minute
+
+// The supported values of APITokenConfigs.Mode.
+const (
+	APITokenModeOpaque = "opaque"
+	APITokenModeJWT    = "jwt"
+)
+
+// APITokenConfigs is the data wrapper for the "global.api_tokens" section,
+// which controls the tokens issued to REST API clients.
+type APITokenConfigs struct {
+	// Mode selects the kind of token issued: APITokenModeOpaque (the
+	// default), a random string stored in the database, or APITokenModeJWT,
+	// a signed JWT that's validated without a database lookup.
+	Mode string `yaml:"mode,omitempty"`
+
+	// Lifetime is how long a token is valid for. Zero uses
+	// DefaultAPITokenLifetime.
+	Lifetime time.Duration `yaml:"lifetime,omitempty"`
+
+	// MaxSessionLifetime is how long after a JWT was first issued by
+	// authenticating that it can still be refreshed. Zero uses
+	// DefaultAPITokenMaxSessionLifetime.
+	MaxSessionLifetime time.Duration `yaml:"max_session_lifetime,omitempty"`
+
+	// SigningKey is the base64-encoded key, at least 32 bytes long, that
+	// signs JWTs. If it's empty it's read from SigningKeyFile. If neither is
+	// set, each controller generates a random key when it starts.
+	SigningKey     string `yaml:"signing_key,omitempty"`
+	SigningKeyFile string `yaml:"signing_key_file,omitempty"`
+}
+
+// DefaultAPITokenLifetime is how long API tokens are valid for when
+// global.api_tokens.lifetime isn't set.
+const DefaultAPITokenLifetime = 10 * time.Second
+
+// DefaultAPITokenMaxSessionLifetime is how long a JWT session can be
+// refreshed for when global.api_tokens.max_session_lifetime isn't set.
+const DefaultAPITokenMaxSessionLifetime = 24 * time.Hour
+
+// LifetimeOrDefault returns Lifetime, or DefaultAPITokenLifetime if it's
+// not set.
+func (c APITokenConfigs) LifetimeOrDefault() time.Duration {
+	if c.Lifetime == 0 {
+		return DefaultAPITokenLifetime
+	}
+	return c.Lifetime
+}
+
+// MaxSessionLifetimeOrDefault returns MaxSessionLifetime, or
+// DefaultAPITokenMaxSessionLifetime if it's not set.
+func (c APITokenConfigs) MaxSessionLifetimeOrDefault() time.Duration {
+	if c.MaxSessionLifetime == 0 {
+		return DefaultAPITokenMaxSessionLifetime
+	}
+	return c.MaxSessionLifetime
+}
+
+// Validate returns an error if Mode isn't a supported mode, if either
+// duration is negative, or if a signing key is set that's too short.
+func (c APITokenConfigs) Validate() error {
+	switch c.Mode {
+	case "", APITokenModeOpaque, APITokenModeJWT:
+	default:
+		return fmt.Errorf("mode must be one of: %s, %s", APITokenModeOpaque, APITokenModeJWT)
+	}
+
+	if c.Lifetime < 0 {
+		return fmt.Errorf("lifetime must not be negative")
+	}
+
+	if c.MaxSessionLifetime < 0 {
+		return fmt.Errorf("max_session_lifetime must not be negative")
+	}
+
+	if c.SigningKey != "" && c.SigningKeyFile != "" {
+		return fmt.Errorf("only one of signing_key and signing_key_file may be set")
+	}
+
+	if c.SigningKey != "" {
+		if _, err := DecodeWorkerTokenKey(c.SigningKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// JanitorConfigs is the data wrapper for the "global.janitor" section, which
+// controls the periodic removal of expired tokens, locks, and secret outputs,
+// and of old dead letters.
+type JanitorConfigs struct {
+	// Interval is how often the janitor runs. Zero uses
+	// DefaultJanitorInterval.
+	Interval time.Duration `yaml:"interval,omitempty"`
+
+	// DeadLetterRetention is how long a dead letter is kept before it's
+	// purged. Zero uses DefaultDeadLetterRetention.
+	DeadLetterRetention time.Duration `yaml:"dead_letter_retention,omitempty"`
+}
+
+// DefaultJanitorInterval is how often the janitor runs when
+// global.janitor.interval isn't set.
+const DefaultJanitorInterval = time.Hour
+
+// DefaultDeadLetterRetention is how long dead letters are kept when
+// global.janitor.dead_letter_retention isn't set.
+const DefaultDeadLetterRetention = 30 * 24 * time.Hour
+
+// Validate returns an error if either duration is negative.
+func (c JanitorConfigs) Validate() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+
+	if c.DeadLetterRetention < 0 {
+		return fmt.Errorf("dead_letter_retention must not be negative")
+	}
+
+	return nil
+}
+
+// RequestArchiveConfigs is the data wrapper for the "global.request_archive"
+// section, which controls how long request payloads are kept.
+type RequestArchiveConfigs struct {
+	// Retention is how long a payload is kept; zero disables archival.
+	Retention time.Duration `yaml:"retention,omitempty"`
+
+	// MaxSize caps the size in bytes of each of the stored output and
+	// rendered message. Zero uses DefaultRequestArchiveMaxSize.
+	MaxSize int `yaml:"max_size,omitempty"`
+
+	// OmitOutput prevents the command output and rendered message from being
+	// stored at all.
+	OmitOutput bool `yaml:"omit_output,omitempty"`
+}
+
+// DefaultRequestArchiveMaxSize is the archived payload size cap used when
+// global.request_archive.max_size isn't set.
+const DefaultRequestArchiveMaxSize = 64 * 1024
+
+// The supported values of DatabaseConfigs.Driver.
+const (
+	DatabaseDriverPostgres = "postgres"
+	DatabaseDriverMySQL    = "mysql"
+)
+
 // DatabaseConfigs is the data wrapper for the "database" section.
 type DatabaseConfigs struct {
+	// Driver selects the database implementation: DatabaseDriverPostgres
+	// (the default) or DatabaseDriverMySQL, which also supports MariaDB.
+	Driver                string        `yaml:"driver,omitempty"`
 	Host                  string        `yaml:"host,omitempty"`
 	Port                  int           `yaml:"port,omitempty"`
 	User                  string        `yaml:"user,omitempty"`
@@ -60,6 +257,59 @@ type DatabaseConfigs struct {
 	MaxIdleConnections    int           `yaml:"max_idle_connections,omitempty"`
 	MaxOpenConnections    int           `yaml:"max_open_connections,omitempty"`
 	QueryTimeout          time.Duration `yaml:"query_timeout,omitempty"`
+
+	// CommandCacheTTL is how long the enabled bundles' commands are cached
+	// before being reloaded from the database. The cache is also emptied
+	// whenever a bundle is changed. If < 0, commands aren't cached.
+	CommandCacheTTL time.Duration `yaml:"command_cache_ttl,omitempty"`
+
+	// TokenCacheTTL is how long a REST API token is cached after it's
+	// retrieved from the database. Tokens are evicted from the cache when
+	// they're revoked. If < 0, tokens aren't cached.
+	TokenCacheTTL time.Duration `yaml:"token_cache_ttl,omitempty"`
+}
+
+// DefaultCommandCacheTTL is the value of CommandCacheTTL if it isn't set.
+const DefaultCommandCacheTTL = time.Minute
+
+// CommandCacheTTLOrDefault returns CommandCacheTTL, or
+// DefaultCommandCacheTTL if it's not set.
+func (c DatabaseConfigs) CommandCacheTTLOrDefault() time.Duration {
+	if c.CommandCacheTTL == 0 {
+		return DefaultCommandCacheTTL
+	}
+	return c.CommandCacheTTL
+}
+
+// DefaultTokenCacheTTL is the value of TokenCacheTTL if it isn't set.
+const DefaultTokenCacheTTL = 5 * time.Second
+
+// TokenCacheTTLOrDefault returns TokenCacheTTL, or DefaultTokenCacheTTL if
+// it's not set.
+func (c DatabaseConfigs) TokenCacheTTLOrDefault() time.Duration {
+	if c.TokenCacheTTL == 0 {
+		return DefaultTokenCacheTTL
+	}
+	return c.TokenCacheTTL
+}
+
+// DriverOrDefault returns the database driver, or DatabaseDriverPostgres if
+// it's not set.
+func (c DatabaseConfigs) DriverOrDefault() string {
+	if c.Driver == "" {
+		return DatabaseDriverPostgres
+	}
+	return c.Driver
+}
+
+// Validate returns an error if the driver isn't supported.
+func (c DatabaseConfigs) Validate() error {
+	switch c.DriverOrDefault() {
+	case DatabaseDriverPostgres, DatabaseDriverMySQL:
+		return nil
+	default:
+		return fmt.Errorf("unsupported driver %q", c.Driver)
+	}
 }
 
 // DockerConfigs is the data wrapper for the "docker" section.
@@ -82,9 +332,647 @@ type JaegerConfigs struct {
 
 // KubernetesConfigs is the data wrapper for the "kubernetes" section.
 type KubernetesConfigs struct {
-	Namespace             string `yaml:"namespace,omitempty"`
+	// Namespace is the namespace that Gort itself runs in, where its pod
+	// and endpoint are found. If empty, it's discovered from Gort's pod, or
+	// when running out of cluster, taken from the kubeconfig context.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// JobNamespace is the namespace that command jobs and their input file
+	// secrets are created in. If empty, jobs run in Namespace. A bundle may
+	// override it with its own kubernetes.namespace.
+	JobNamespace string `yaml:"job_namespace,omitempty"`
+
 	EndpointFieldSelector string `yaml:"endpoint_field_selector,omitempty"`
 	EndpointLabelSelector string `yaml:"endpoint_label_selector,omitempty"`
 	PodFieldSelector      string `yaml:"pod_field_selector,omitempty"`
 	PodLabelSelector      string `yaml:"pod_label_selector,omitempty"`
+
+	// Kubeconfig and Context select a cluster from a kubeconfig file, for
+	// when Gort runs outside of the cluster that it dispatches jobs to. If
+	// both are empty, the in-cluster configuration is used.
+	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+	Context    string `yaml:"context,omitempty"`
+
+	// Clusters are additional, named clusters that bundles and execution
+	// profiles may route commands to. Commands that don't name a cluster
+	// run on the default cluster described by the fields above.
+	Clusters []KubernetesCluster `yaml:"clusters,omitempty"`
+
+	// HealthCheckInterval is how often each cluster's API server is checked.
+	// Commands aren't dispatched to a cluster that failed its last check.
+	HealthCheckInterval time.Duration `yaml:"health_check_interval,omitempty"`
+}
+
+// Cluster returns the named cluster. The empty name refers to the default
+// cluster, which is always present.
+func (c KubernetesConfigs) Cluster(name string) (KubernetesCluster, bool) {
+	if name == "" {
+		return KubernetesCluster{
+			Kubeconfig:   c.Kubeconfig,
+			Context:      c.Context,
+			Namespace:    c.Namespace,
+			JobNamespace: c.JobNamespace,
+		}, true
+	}
+
+	for _, cl := range c.Clusters {
+		if cl.Name == name {
+			return cl, true
+		}
+	}
+
+	return KubernetesCluster{}, false
+}
+
+// Validate returns an error if any namespace, of the default cluster or of
+// a named cluster, isn't a valid Kubernetes namespace name.
+func (c KubernetesConfigs) Validate() error {
+	def, _ := c.Cluster("")
+	if err := def.validateNamespaces(); err != nil {
+		return err
+	}
+
+	for _, cl := range c.Clusters {
+		if err := cl.validateNamespaces(); err != nil {
+			return fmt.Errorf("clusters: %s: %w", cl.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// KubernetesCluster describes a single cluster that commands may be
+// executed on, and the credentials used to reach it.
+type KubernetesCluster struct {
+	Name       string `yaml:"name,omitempty"`
+	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+	Context    string `yaml:"context,omitempty"`
+	Namespace  string `yaml:"namespace,omitempty"`
+
+	// JobNamespace is the namespace that jobs on this cluster run in. If
+	// empty, jobs run in Namespace.
+	JobNamespace string `yaml:"job_namespace,omitempty"`
+
+	// APIURLBase is the address that jobs on this cluster use to reach Gort.
+	// If empty, gort.api_url_base is used.
+	APIURLBase string `yaml:"api_url_base,omitempty"`
+}
+
+// OutOfCluster returns true if Gort should connect to the cluster using a
+// kubeconfig file rather than its in-cluster service account.
+func (c KubernetesCluster) OutOfCluster() bool {
+	return c.Kubeconfig != "" || c.Context != ""
+}
+
+func (c KubernetesCluster) validateNamespaces() error {
+	if c.Namespace != "" {
+		if err := ValidateKubernetesNamespace(c.Namespace); err != nil {
+			return fmt.Errorf("namespace: %w", err)
+		}
+	}
+
+	if c.JobNamespace != "" {
+		if err := ValidateKubernetesNamespace(c.JobNamespace); err != nil {
+			return fmt.Errorf("job_namespace: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// kubernetesNamespacePattern matches an RFC 1123 DNS label, which is what
+// Kubernetes requires of namespace names.
+var kubernetesNamespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ValidateKubernetesNamespace returns an error if name isn't a valid
+// Kubernetes namespace name.
+func ValidateKubernetesNamespace(name string) error {
+	if len(name) > 63 || !kubernetesNamespacePattern.MatchString(name) {
+		return fmt.Errorf("invalid namespace %q: must be at most 63 lowercase alphanumeric characters or '-', and start and end with an alphanumeric character", name)
+	}
+	return nil
+}
+
+// LDAPConfigs is the data wrapper for the "ldap" section. If it's present,
+// users that authenticate to the REST API with a password are checked against
+// the LDAP or Active Directory server before Gort's own user database.
+type LDAPConfigs struct {
+	// URL is the server's URL, like "ldaps://ldap.example.com" or
+	// "ldap://dc1.example.com:389".
+	URL string `yaml:"url,omitempty"`
+
+	// StartTLS upgrades an "ldap" URL's connection to TLS before binding.
+	StartTLS bool `yaml:"start_tls,omitempty"`
+
+	// InsecureSkipVerify disables verification of the server's certificate.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty"`
+
+	// BindDN and BindPassword are the credentials of the account that
+	// searches for users' entries.
+	BindDN       string `yaml:"bind_dn,omitempty"`
+	BindPassword string `yaml:"bind_password,omitempty"`
+
+	// SearchBase is the DN of the subtree that users' entries are in.
+	SearchBase string `yaml:"search_base,omitempty"`
+
+	// UserFilter is the search filter that finds a user's entry, in which
+	// each "%s" is replaced by the escaped username. Empty uses
+	// DefaultLDAPUserFilter.
+	UserFilter string `yaml:"user_filter,omitempty"`
+
+	// GroupMappings maps the DNs of LDAP groups to the names of Gort
+	// groups. Each time a user authenticates they're added to the Gort
+	// groups of the LDAP groups they're a member of, and removed from the
+	// other mapped Gort groups.
+	GroupMappings map[string]string `yaml:"group_mappings,omitempty"`
+
+	// Timeout limits how long an authentication can take. Zero uses
+	// DefaultLDAPTimeout.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// DefaultLDAPUserFilter is the user search filter used when ldap.user_filter
+// isn't set. Active Directory users will want "(sAMAccountName=%s)".
+const DefaultLDAPUserFilter = "(uid=%s)"
+
+// DefaultLDAPTimeout is how long an LDAP authentication can take when
+// ldap.timeout isn't set.
+const DefaultLDAPTimeout = 10 * time.Second
+
+// UserFilterOrDefault returns UserFilter, or DefaultLDAPUserFilter if it's
+// not set.
+func (c LDAPConfigs) UserFilterOrDefault() string {
+	if c.UserFilter == "" {
+		return DefaultLDAPUserFilter
+	}
+	return c.UserFilter
+}
+
+// TimeoutOrDefault returns Timeout, or DefaultLDAPTimeout if it's not set.
+func (c LDAPConfigs) TimeoutOrDefault() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultLDAPTimeout
+	}
+	return c.Timeout
+}
+
+// Validate returns an error if the URL or search base is missing or
+// malformed, or if the user filter has no placeholder. It's only called if
+// the section is present.
+func (c LDAPConfigs) Validate() error {
+	switch {
+	case strings.HasPrefix(c.URL, "ldap://"):
+	case strings.HasPrefix(c.URL, "ldaps://"):
+		if c.StartTLS {
+			return fmt.Errorf("start_tls can't be used with an ldaps url")
+		}
+	default:
+		return fmt.Errorf("url must begin with ldap:// or ldaps://")
+	}
+
+	if c.SearchBase == "" {
+		return fmt.Errorf("search_base is required")
+	}
+
+	if !strings.Contains(c.UserFilterOrDefault(), "%s") {
+		return fmt.Errorf("user_filter must contain a %%s placeholder for the username")
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
+	return nil
+}
+
+// OIDCConfigs is the data wrapper for the "oidc" section. If it's present,
+// users can log in with an OpenID Connect identity provider, either in a
+// browser, or with the device authorization flow from the gort CLI.
+type OIDCConfigs struct {
+	// Issuer is the identity provider's issuer URL, like
+	// "https://accounts.example.com". Its configuration is discovered from
+	// "/.well-known/openid-configuration" under it.
+	Issuer string `yaml:"issuer,omitempty"`
+
+	// ClientID and ClientSecret are the credentials of Gort's client at the
+	// identity provider.
+	ClientID     string `yaml:"client_id,omitempty"`
+	ClientSecret string `yaml:"client_secret,omitempty"`
+
+	// RedirectURL is the URL of Gort's "/v2/auth/oidc/callback" endpoint,
+	// as registered with the identity provider. Browser logins aren't
+	// possible without it.
+	RedirectURL string `yaml:"redirect_url,omitempty"`
+
+	// Scopes are the scopes requested from the identity provider. The
+	// "openid" scope is always requested. Empty uses DefaultOIDCScopes.
+	Scopes []string `yaml:"scopes,omitempty"`
+
+	// UsernameClaim is the ID token claim that holds a user's Gort
+	// username. Empty uses DefaultOIDCUsernameClaim.
+	UsernameClaim string `yaml:"username_claim,omitempty"`
+
+	// GroupsClaim is the ID token claim that lists a user's groups at the
+	// identity provider. Empty uses DefaultOIDCGroupsClaim.
+	GroupsClaim string `yaml:"groups_claim,omitempty"`
+
+	// GroupMappings maps the names of identity provider groups to the
+	// names of Gort groups. Each time a user logs in they're added to the
+	// Gort groups of the groups they're a member of, and removed from the
+	// other mapped Gort groups.
+	GroupMappings map[string]string `yaml:"group_mappings,omitempty"`
+
+	// Timeout limits how long each request to the identity provider can
+	// take. Zero uses DefaultOIDCTimeout.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+const (
+	// DefaultOIDCUsernameClaim is the claim that holds a user's username
+	// when oidc.username_claim isn't set.
+	DefaultOIDCUsernameClaim = "preferred_username"
+
+	// DefaultOIDCGroupsClaim is the claim that lists a user's groups when
+	// oidc.groups_claim isn't set.
+	DefaultOIDCGroupsClaim = "groups"
+
+	// DefaultOIDCTimeout is how long each request to the identity provider
+	// can take when oidc.timeout isn't set.
+	DefaultOIDCTimeout = 10 * time.Second
+)
+
+// DefaultOIDCScopes are the scopes requested when oidc.scopes isn't set.
+var DefaultOIDCScopes = []string{"openid", "profile", "email"}
+
+// ScopesOrDefault returns Scopes, or DefaultOIDCScopes if it's not set. The
+// result always includes "openid".
+func (c OIDCConfigs) ScopesOrDefault() []string {
+	if len(c.Scopes) == 0 {
+		return append([]string{}, DefaultOIDCScopes...)
+	}
+
+	for _, s := range c.Scopes {
+		if s == "openid" {
+			return append([]string{}, c.Scopes...)
+		}
+	}
+
+	return append([]string{"openid"}, c.Scopes...)
+}
+
+// UsernameClaimOrDefault returns UsernameClaim, or DefaultOIDCUsernameClaim
+// if it's not set.
+func (c OIDCConfigs) UsernameClaimOrDefault() string {
+	if c.UsernameClaim == "" {
+		return DefaultOIDCUsernameClaim
+	}
+	return c.UsernameClaim
+}
+
+// GroupsClaimOrDefault returns GroupsClaim, or DefaultOIDCGroupsClaim if
+// it's not set.
+func (c OIDCConfigs) GroupsClaimOrDefault() string {
+	if c.GroupsClaim == "" {
+		return DefaultOIDCGroupsClaim
+	}
+	return c.GroupsClaim
+}
+
+// TimeoutOrDefault returns Timeout, or DefaultOIDCTimeout if it's not set.
+func (c OIDCConfigs) TimeoutOrDefault() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultOIDCTimeout
+	}
+	return c.Timeout
+}
+
+// Validate returns an error if the issuer or client ID is missing, or if
+// the issuer or redirect URL is malformed. It's only called if the section
+// is present.
+func (c OIDCConfigs) Validate() error {
+	if u, err := url.Parse(c.Issuer); err != nil || u.Host == "" ||
+		(u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("issuer must be an http:// or https:// URL")
+	}
+
+	if c.ClientID == "" {
+		return fmt.Errorf("client_id is required")
+	}
+
+	if c.RedirectURL != "" {
+		if u, err := url.Parse(c.RedirectURL); err != nil || u.Host == "" {
+			return fmt.Errorf("redirect_url must be an absolute URL")
+		}
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
+	return nil
+}
+
+// ServerlessConfigs is the data wrapper for the "serverless" section. If it's
+// present, commands are executed by invoking the function or job named by
+// their bundle's serverless section.
+type ServerlessConfigs struct {
+	Lambda   LambdaConfigs   `yaml:"lambda,omitempty"`
+	CloudRun CloudRunConfigs `yaml:"cloud_run,omitempty"`
+}
+
+// LambdaConfigs is the data wrapper for the "serverless/lambda" section.
+// Credentials are found by the AWS SDK's default chain: the standard
+// environment variables, the shared configuration files, or the role of the
+// instance or task that Gort runs on.
+type LambdaConfigs struct {
+	Region string `yaml:"region,omitempty"`
+
+	// Endpoint overrides the Lambda API endpoint for Region.
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+// CloudRunConfigs is the data wrapper for the "serverless/cloud_run"
+// section. An access token is read from the GOOGLE_OAUTH_ACCESS_TOKEN
+// environment variable, or else from the GCE metadata server.
+type CloudRunConfigs struct {
+	Project  string `yaml:"project,omitempty"`
+	Location string `yaml:"location,omitempty"`
+
+	// Endpoint and LoggingEndpoint override the Cloud Run and Cloud Logging
+	// API endpoints.
+	Endpoint        string `yaml:"endpoint,omitempty"`
+	LoggingEndpoint string `yaml:"logging_endpoint,omitempty"`
+
+	// PollInterval is how often a running job execution is checked for
+	// completion.
+	PollInterval time.Duration `yaml:"poll_interval,omitempty"`
+}
+
+// SSHConfigs is the data wrapper for the "ssh" section. If it's present,
+// commands are executed over SSH on the hosts selected by each bundle.
+type SSHConfigs struct {
+	// User and PrivateKeyFile are used for hosts that don't set their own.
+	// If no private key file is set, the agent at SSH_AUTH_SOCK is used.
+	User           string `yaml:"user,omitempty"`
+	PrivateKeyFile string `yaml:"private_key_file,omitempty"`
+
+	// KnownHostsFile is used to verify host keys. Defaults to
+	// ~/.ssh/known_hosts.
+	KnownHostsFile string `yaml:"known_hosts_file,omitempty"`
+
+	// ConnectTimeout bounds how long connecting to a host may take.
+	ConnectTimeout time.Duration `yaml:"connect_timeout,omitempty"`
+
+	// Hosts is the inventory of hosts that bundles may select.
+	Hosts []SSHHost `yaml:"hosts,omitempty"`
+}
+
+// SSHHost is a single host in the "ssh" section's inventory.
+type SSHHost struct {
+	Name string `yaml:"name,omitempty"`
+
+	// Address is the host's "host[:port]". The port defaults to 22.
+	Address string `yaml:"address,omitempty"`
+
+	User           string   `yaml:"user,omitempty"`
+	PrivateKeyFile string   `yaml:"private_key_file,omitempty"`
+	Tags           []string `yaml:"tags,omitempty"`
+}
+
+// HostsFor returns the hosts selected by a bundle: those that it names, and
+// those that have any of its tags, in inventory order. An error is returned
+// if the bundle names an unknown host or selects no hosts at all.
+func (c SSHConfigs) HostsFor(b BundleSSH) ([]SSHHost, error) {
+	names := map[string]bool{}
+	for _, name := range b.Hosts {
+		names[name] = false
+	}
+
+	tags := map[string]bool{}
+	for _, tag := range b.Tags {
+		tags[tag] = true
+	}
+
+	var hosts []SSHHost
+
+	for _, h := range c.Hosts {
+		selected := false
+
+		if _, ok := names[h.Name]; ok {
+			names[h.Name] = true
+			selected = true
+		}
+
+		for _, tag := range h.Tags {
+			if tags[tag] {
+				selected = true
+			}
+		}
+
+		if selected {
+			hosts = append(hosts, h)
+		}
+	}
+
+	for _, name := range b.Hosts {
+		if !names[name] {
+			return nil, fmt.Errorf("no such ssh host: %s", name)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no ssh hosts selected")
+	}
+
+	return hosts, nil
+}
+
+// NativeConfigs is the data wrapper for the "native" section. If it's
+// present, commands are executed as subprocesses of Gort itself, on the Gort
+// host, rather than in containers.
+type NativeConfigs struct {
+	// AllowedExecutables lists the executables that commands may run, as
+	// absolute paths or filepath.Match patterns. A command whose executable
+	// isn't listed is refused.
+	AllowedExecutables []string `yaml:"allowed_executables,omitempty"`
+
+	// WorkingDirectory is the directory that commands are run in. If it's
+	// empty, each command is run in a new temporary directory that's removed
+	// when it exits.
+	WorkingDirectory string `yaml:"working_directory,omitempty"`
+
+	// PassEnv lists the variables in Gort's own environment that are passed
+	// to commands. No others are; if PATH isn't listed, it's set to
+	// DefaultNativePath.
+	PassEnv []string `yaml:"pass_env,omitempty"`
+
+	// Limits are resource limits applied to each command.
+	Limits NativeLimits `yaml:"limits,omitempty"`
+}
+
+// DefaultNativePath is the PATH of natively executed commands, unless PATH
+// is listed in native.pass_env.
+const DefaultNativePath = "/usr/local/bin:/usr/bin:/bin"
+
+// NativeLimits are the resource limits (rlimits) of a natively executed
+// command. Zero values are unlimited.
+type NativeLimits struct {
+	// CPUTime is the maximum CPU time, which is rounded up to a second.
+	CPUTime time.Duration `yaml:"cpu_time,omitempty"`
+
+	// Memory is the maximum size of the process's virtual memory, in bytes.
+	Memory int64 `yaml:"memory,omitempty"`
+
+	// FileSize is the maximum size of a file that the process can write,
+	// in bytes.
+	FileSize int64 `yaml:"file_size,omitempty"`
+
+	// OpenFiles is the maximum number of open file descriptors.
+	OpenFiles int64 `yaml:"open_files,omitempty"`
+}
+
+// Validate returns an error if an allowed executable pattern is malformed,
+// the working directory isn't absolute, or a limit is negative.
+func (c NativeConfigs) Validate() error {
+	for _, p := range c.AllowedExecutables {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("allowed_executables: invalid pattern %q: %w", p, err)
+		}
+	}
+
+	if c.WorkingDirectory != "" && !filepath.IsAbs(c.WorkingDirectory) {
+		return fmt.Errorf("working_directory must be an absolute path")
+	}
+
+	l := c.Limits
+	if l.CPUTime < 0 || l.Memory < 0 || l.FileSize < 0 || l.OpenFiles < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+
+	return nil
+}
+
+// Allows returns true if the executable matches one of the allowed
+// executable patterns.
+func (c NativeConfigs) Allows(executable string) bool {
+	for _, p := range c.AllowedExecutables {
+		if ok, _ := filepath.Match(p, executable); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MockConfigs is the data wrapper for the "mock" section. If it's present,
+// commands are executed by a mock worker that returns canned responses
+// rather than running a container.
+type MockConfigs struct {
+	// Responses are checked in order; the first to match a command is used.
+	Responses []MockResponse `yaml:"responses,omitempty"`
+
+	// Default is used for commands that don't match any of Responses.
+	Default MockResponse `yaml:"default,omitempty"`
+}
+
+// MockResponse describes a canned command response returned by the mock
+// worker.
+type MockResponse struct {
+	// Command is the "bundle:command" that this response is for. If empty,
+	// it matches every command.
+	Command string `yaml:"command,omitempty"`
+
+	// Matches is a regular expression that the command's parameters, joined
+	// with spaces, must match. If empty, any parameters match.
+	Matches string `yaml:"matches,omitempty"`
+
+	// Output is the lines of output that the command produces.
+	Output []string `yaml:"output,omitempty"`
+
+	// Echo, if true, appends the command's parameters to Output as a
+	// single line.
+	Echo bool `yaml:"echo,omitempty"`
+
+	// ExitCode is the command's exit code.
+	ExitCode int64 `yaml:"exit_code,omitempty"`
+
+	// Delay is how long the command takes to execute.
+	Delay time.Duration `yaml:"delay,omitempty"`
+}
+
+// The worker engines that may be selected with the "worker" section's engine.
+// Each is configured by the config section of the same name.
+const (
+	WorkerEngineDocker     = "docker"
+	WorkerEngineKubernetes = "kubernetes"
+	WorkerEngineMock       = "mock"
+	WorkerEngineNative     = "native"
+	WorkerEngineServerless = "serverless"
+	WorkerEngineSSH        = "ssh"
+)
+
+// WorkerEngines lists the valid values of WorkerConfigs.Engine.
+var WorkerEngines = []string{
+	WorkerEngineDocker,
+	WorkerEngineKubernetes,
+	WorkerEngineMock,
+	WorkerEngineNative,
+	WorkerEngineServerless,
+	WorkerEngineSSH,
+}
+
+// WorkerConfigs is the data wrapper for the "worker" section.
+type WorkerConfigs struct {
+	// Engine selects the worker engine that executes commands. If it's
+	// empty, the engine is inferred from whichever one of the engines' config
+	// sections is present, and it's an error for more than one to be.
+	Engine string `yaml:"engine,omitempty"`
+
+	// TokenKey is the base64-encoded key, at least 32 bytes long, that signs
+	// the request-scoped token given to each command execution. If it's
+	// empty it's read from TokenKeyFile, or else from the
+	// GORT_WORKER_TOKEN_KEY envvar. If none is set, each controller
+	// generates a random key when it starts.
+	TokenKey     string `yaml:"token_key,omitempty"`
+	TokenKeyFile string `yaml:"token_key_file,omitempty"`
+}
+
+// Validate returns an error if Engine isn't empty or one of WorkerEngines,
+// or if a token key is set that's too short.
+func (c WorkerConfigs) Validate() error {
+	if c.TokenKey != "" && c.TokenKeyFile != "" {
+		return fmt.Errorf("only one of token_key and token_key_file may be set")
+	}
+
+	if c.TokenKey != "" {
+		if _, err := DecodeWorkerTokenKey(c.TokenKey); err != nil {
+			return err
+		}
+	}
+
+	if c.Engine == "" {
+		return nil
+	}
+
+	for _, e := range WorkerEngines {
+		if c.Engine == e {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("engine must be one of: %s", strings.Join(WorkerEngines, ", "))
+}
+
+// DecodeWorkerTokenKey decodes a base64-encoded worker token signing key,
+// returning an error if it's shorter than 32 bytes.
+func DecodeWorkerTokenKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("token key must be base64 encoded: %w", err)
+	}
+
+	if len(key) < 32 {
+		return nil, fmt.Errorf("token key must be at least 32 bytes long, not %d", len(key))
+	}
+
+	return key, nil
 }
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSHConfigsHostsFor(t *testing.T) {
+	c := SSHConfigs{
+		Hosts: []SSHHost{
+			{Name: "web-1", Tags: []string{"web"}},
+			{Name: "web-2", Tags: []string{"web"}},
+			{Name: "db-1", Tags: []string{"db"}},
+		},
+	}
+
+	names := func(hosts []SSHHost) []string {
+		var ns []string
+		for _, h := range hosts {
+			ns = append(ns, h.Name)
+		}
+		return ns
+	}
+
+	hosts, err := c.HostsFor(BundleSSH{Hosts: []string{"db-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db-1"}, names(hosts))
+
+	hosts, err = c.HostsFor(BundleSSH{Tags: []string{"web"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-1", "web-2"}, names(hosts))
+
+	hosts, err = c.HostsFor(BundleSSH{Hosts: []string{"db-1", "web-1"}, Tags: []string{"web"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-1", "web-2", "db-1"}, names(hosts))
+
+	_, err = c.HostsFor(BundleSSH{Hosts: []string{"web-3"}})
+	assert.EqualError(t, err, "no such ssh host: web-3")
+
+	_, err = c.HostsFor(BundleSSH{Tags: []string{"cache"}})
+	assert.EqualError(t, err, "no ssh hosts selected")
+}
+
+func TestBundleAnalysisConfigs(t *testing.T) {
+	assert.NoError(t, BundleAnalysisConfigs{}.Validate())
+	assert.NoError(t, BundleAnalysisConfigs{BlockSeverity: SeverityWarning}.Validate())
+	assert.Error(t, BundleAnalysisConfigs{BlockSeverity: "severe"}.Validate())
+
+	assert.False(t, BundleAnalysisConfigs{}.Blocks(SeverityCritical))
+
+	c := BundleAnalysisConfigs{BlockSeverity: SeverityWarning}
+	assert.False(t, c.Blocks(SeverityInfo))
+	assert.True(t, c.Blocks(SeverityWarning))
+	assert.True(t, c.Blocks(SeverityCritical))
+}
+
+func TestTriggerConfigsValidate(t *testing.T) {
+	assert.NoError(t, TriggerConfigs{}.Validate())
+	assert.NoError(t, TriggerConfigs{Precedence: []string{TriggerPrecedencePriority, TriggerPrecedenceLongestMatch}}.Validate())
+	assert.EqualError(t, TriggerConfigs{Precedence: []string{"newest"}}.Validate(), `unknown precedence strategy: "newest"`)
+}
+
+func TestWorkerConfigsValidate(t *testing.T) {
+	assert.NoError(t, WorkerConfigs{}.Validate())
+	assert.NoError(t, WorkerConfigs{Engine: WorkerEngineDocker}.Validate())
+	assert.Error(t, WorkerConfigs{Engine: "podman"}.Validate())
+}
+
+func TestKubernetesConfigsValidate(t *testing.T) {
+	assert.NoError(t, KubernetesConfigs{}.Validate())
+	assert.NoError(t, KubernetesConfigs{Namespace: "gort", JobNamespace: "gort-jobs"}.Validate())
+	assert.Error(t, KubernetesConfigs{JobNamespace: "Gort_Jobs"}.Validate())
+	assert.Error(t, KubernetesConfigs{Clusters: []KubernetesCluster{{Name: "eu-west", JobNamespace: "-jobs"}}}.Validate())
+	assert.Error(t, KubernetesConfigs{Namespace: strings.Repeat("a", 64)}.Validate())
+}
+
+func TestNativeConfigsValidate(t *testing.T) {
+	assert.NoError(t, NativeConfigs{AllowedExecutables: []string{"/usr/bin/*"}}.Validate())
+	assert.Error(t, NativeConfigs{AllowedExecutables: []string{"/usr/bin/["}}.Validate())
+	assert.Error(t, NativeConfigs{WorkingDirectory: "work"}.Validate())
+	assert.Error(t, NativeConfigs{Limits: NativeLimits{Memory: -1}}.Validate())
+}
+
+func TestNativeConfigsAllows(t *testing.T) {
+	c := NativeConfigs{AllowedExecutables: []string{"/bin/echo", "/opt/bundles/*"}}
+
+	assert.True(t, c.Allows("/bin/echo"))
+	assert.True(t, c.Allows("/opt/bundles/deploy"))
+	assert.False(t, c.Allows("/opt/bundles/sub/deploy"))
+	assert.False(t, c.Allows("/bin/sh"))
+	assert.False(t, NativeConfigs{}.Allows("/bin/echo"))
+}
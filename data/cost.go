@@ -0,0 +1,168 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// CostConfigs is the data wrapper for the "global.costs" section, which
+// controls the accounting of the compute used by command executions.
+type CostConfigs struct {
+	// Enabled turns on cost accounting.
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// DefaultCPU and DefaultMemory are the resources charged for commands
+	// whose bundles don't declare resources, like "250m" and "256Mi".
+	DefaultCPU    string `yaml:"default_cpu,omitempty"`
+	DefaultMemory string `yaml:"default_memory,omitempty"`
+
+	// CPUCoreHourPrice and MemoryGiBHourPrice convert resource usage into a
+	// cost, in whatever currency is convenient. If both are zero, usage is
+	// recorded without a cost.
+	CPUCoreHourPrice   float64 `yaml:"cpu_core_hour_price,omitempty"`
+	MemoryGiBHourPrice float64 `yaml:"memory_gib_hour_price,omitempty"`
+}
+
+// Validate returns an error if either default resource is malformed, or if
+// either price is negative.
+func (c CostConfigs) Validate() error {
+	if c.CPUCoreHourPrice < 0 || c.MemoryGiBHourPrice < 0 {
+		return fmt.Errorf("prices must not be negative")
+	}
+
+	return BundleResources{CPU: c.DefaultCPU, Memory: c.DefaultMemory}.Validate()
+}
+
+// BundleResources describes the compute resources requested by each
+// execution of a bundle's commands. Quantities use the Kubernetes format,
+// like "500m" CPU or "256Mi" memory. Kubernetes workers request these
+// resources for their jobs, and cost accounting charges for them.
+type BundleResources struct {
+	CPU    string `yaml:"cpu,omitempty" json:"cpu,omitempty"`
+	Memory string `yaml:"memory,omitempty" json:"memory,omitempty"`
+}
+
+// IsZero returns true if no resources are declared.
+func (r BundleResources) IsZero() bool {
+	return r.CPU == "" && r.Memory == ""
+}
+
+// Validate returns an error if either quantity is malformed or negative.
+func (r BundleResources) Validate() error {
+	if _, err := r.Cores(); err != nil {
+		return err
+	}
+
+	_, err := r.GiB()
+	return err
+}
+
+// Cores returns the CPU request in cores. An empty request is zero cores.
+func (r BundleResources) Cores() (float64, error) {
+	return parseQuantity("cpu", r.CPU, 1)
+}
+
+// GiB returns the memory request in gibibytes. An empty request is zero.
+func (r BundleResources) GiB() (float64, error) {
+	return parseQuantity("memory", r.Memory, 1<<30)
+}
+
+// Or returns r, with any empty value replaced by the corresponding value
+// from o.
+func (r BundleResources) Or(o BundleResources) BundleResources {
+	if r.CPU == "" {
+		r.CPU = o.CPU
+	}
+	if r.Memory == "" {
+		r.Memory = o.Memory
+	}
+
+	return r
+}
+
+func parseQuantity(name, s string, unit float64) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, s, err)
+	}
+	if q.Sign() < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must not be negative", name, s)
+	}
+
+	return q.AsApproximateFloat64() / unit, nil
+}
+
+// CostRecord is the compute used by the executions of one bundle's commands
+// by the members of one group over one month. Each execution is charged to
+// the invoking user's group that sorts first by name, or to no group if the
+// user isn't in any.
+type CostRecord struct {
+	// Month is the UTC month, like "2021-06".
+	Month  string `json:"month"`
+	Bundle string `json:"bundle"`
+	Group  string `json:"group,omitempty"`
+
+	Executions       int64   `json:"executions"`
+	Seconds          float64 `json:"seconds"`
+	CPUSeconds       float64 `json:"cpu_seconds"`
+	MemoryGiBSeconds float64 `json:"memory_gib_seconds"`
+	Cost             float64 `json:"cost"`
+}
+
+// CostMonth returns the month, in CostRecord.Month form, that t falls in.
+func CostMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// NewCostRecord returns the cost of a single execution of a bundle command
+// that ran for d at time t, charged to the given group.
+func NewCostRecord(c CostConfigs, bundle Bundle, group string, t time.Time, d time.Duration) (CostRecord, error) {
+	resources := bundle.Resources.Or(BundleResources{CPU: c.DefaultCPU, Memory: c.DefaultMemory})
+
+	cores, err := resources.Cores()
+	if err != nil {
+		return CostRecord{}, err
+	}
+
+	gib, err := resources.GiB()
+	if err != nil {
+		return CostRecord{}, err
+	}
+
+	seconds := d.Seconds()
+	r := CostRecord{
+		Month:            CostMonth(t),
+		Bundle:           bundle.Name,
+		Group:            group,
+		Executions:       1,
+		Seconds:          seconds,
+		CPUSeconds:       cores * seconds,
+		MemoryGiBSeconds: gib * seconds,
+	}
+
+	r.Cost = (r.CPUSeconds*c.CPUCoreHourPrice + r.MemoryGiBSeconds*c.MemoryGiBHourPrice) / 3600
+
+	return r, nil
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleResourcesValidate(t *testing.T) {
+	assert.NoError(t, BundleResources{}.Validate())
+	assert.NoError(t, BundleResources{CPU: "500m", Memory: "256Mi"}.Validate())
+	assert.Error(t, BundleResources{CPU: "lots"}.Validate())
+	assert.Error(t, BundleResources{Memory: "-1Gi"}.Validate())
+}
+
+func TestNewCostRecord(t *testing.T) {
+	c := CostConfigs{
+		DefaultCPU:         "1",
+		DefaultMemory:      "1Gi",
+		CPUCoreHourPrice:   3.6,
+		MemoryGiBHourPrice: 0.36,
+	}
+
+	bundle := Bundle{Name: "test", Resources: BundleResources{CPU: "500m"}}
+	ts := time.Date(2021, 6, 30, 23, 0, 0, 0, time.UTC)
+
+	r, err := NewCostRecord(c, bundle, "ops", ts, 10*time.Second)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2021-06", r.Month)
+	assert.Equal(t, "test", r.Bundle)
+	assert.Equal(t, "ops", r.Group)
+	assert.Equal(t, int64(1), r.Executions)
+	assert.InDelta(t, 10.0, r.Seconds, 0.0001)
+
+	// The bundle's CPU is used, and the default memory fills in.
+	assert.InDelta(t, 5.0, r.CPUSeconds, 0.0001)
+	assert.InDelta(t, 10.0, r.MemoryGiBSeconds, 0.0001)
+	assert.InDelta(t, 0.006, r.Cost, 0.000001)
+}
@@ -19,6 +19,7 @@ package data
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 
 	gerrs "github.com/getgort/gort/errors"
@@ -56,6 +57,20 @@ func GenerateRandomToken(length int) (string, error) {
 	return sEnc, nil
 }
 
+// GeneratePseudonym generates a random name that can stand in for a username
+// in records that outlive the user, such as the request records of a purged
+// user.
+func GeneratePseudonym() (string, error) {
+	bytes := make([]byte, 6)
+
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", gerrs.Wrap(ErrCryptoIO, err)
+	}
+
+	return "purged-" + hex.EncodeToString(bytes), nil
+}
+
 // HashPassword receives a plaintext password and returns its hashed equivalent.
 func HashPassword(pwd string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.MinCost)
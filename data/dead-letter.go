@@ -0,0 +1,63 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"time"
+)
+
+// DeadLetter is a command response that couldn't be delivered to its chat
+// channel, even as plain text. It's kept so that it can be redelivered once
+// the underlying problem (an archived channel, revoked permissions, and so
+// on) has been fixed.
+type DeadLetter struct {
+	// ID is the unique, increasing identifier of the dead letter. It's
+	// assigned when the dead letter is created.
+	ID int64 `json:"id"`
+
+	// RequestID is the ID of the command request the response belongs to.
+	RequestID int64 `json:"request_id,omitempty"`
+
+	// Adapter and ChannelID identify where the response was sent.
+	Adapter   string `json:"adapter"`
+	ChannelID string `json:"channel_id"`
+
+	// Message is the rendered, plain text form of the response.
+	Message string `json:"message"`
+
+	// Error is the error returned by the most recent delivery attempt.
+	Error string `json:"error"`
+
+	// Attempts is the number of times delivery has been attempted.
+	Attempts int `json:"attempts"`
+
+	// Created is when the dead letter was created, and LastAttempt is when
+	// delivery was most recently attempted.
+	Created     time.Time `json:"created"`
+	LastAttempt time.Time `json:"last_attempt"`
+}
+
+// DeadLetterConfigs is the data wrapper for the "global.dead_letters"
+// section, which controls how administrators are told about undeliverable
+// responses.
+type DeadLetterConfigs struct {
+	// NotifyAdapter and NotifyChannel identify the channel to which a notice
+	// is sent whenever a response is dead-lettered. If either is empty, no
+	// notice is sent.
+	NotifyAdapter string `yaml:"notify_adapter,omitempty"`
+	NotifyChannel string `yaml:"notify_channel,omitempty"`
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"time"
+)
+
+// DigestEntry accumulates the results of the runs of a single scheduled
+// command, so that they can be reported in one digest message rather than
+// a message per run. Digest entries are rendered with the Digest template.
+type DigestEntry struct {
+	// Name identifies the scheduled command.
+	Name string
+
+	// Command is the command that was run, like "gort:version --short".
+	Command string
+
+	// Runs and Failures count the runs since the last digest, and the
+	// runs among them that failed.
+	Runs     int
+	Failures int
+
+	// LastRun and LastDuration describe the most recent run.
+	LastRun      time.Time
+	LastDuration time.Duration
+
+	// LastError is the error of the most recent failed run, if any run
+	// failed.
+	LastError string
+
+	// Out is the output of the most recent run.
+	Out string
+}
+
+// Record adds the result of a run to the entry.
+func (e *DigestEntry) Record(at time.Time, d time.Duration, out string, err error) {
+	e.Runs++
+	e.LastRun = at
+	e.LastDuration = d
+	e.Out = out
+
+	if err != nil {
+		e.Failures++
+		e.LastError = err.Error()
+	}
+}
@@ -18,6 +18,7 @@ package data
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -30,6 +31,26 @@ const (
 
 type ConfigurationLayer string
 
+// layerPrecedence orders the layers from least to most specific.
+var layerPrecedence = map[ConfigurationLayer]int{
+	LayerBundle: 0,
+	LayerGroup:  1,
+	LayerRoom:   2,
+	LayerUser:   3,
+}
+
+// SortDynamicConfigurations sorts configurations from the least specific
+// layer (bundle) to the most specific (user). Workers apply configurations
+// in order, so when the same key is set in more than one layer the value
+// from the most specific one is used.
+func SortDynamicConfigurations(dcs []DynamicConfiguration) {
+	sort.SliceStable(dcs, func(i, j int) bool {
+		li := ConfigurationLayer(strings.ToLower(string(dcs[i].Layer)))
+		lj := ConfigurationLayer(strings.ToLower(string(dcs[j].Layer)))
+		return layerPrecedence[li] < layerPrecedence[lj]
+	})
+}
+
 func (c ConfigurationLayer) Validate() error {
 	s := ConfigurationLayer(strings.ToLower(string(c)))
 	if s != LayerBundle && s != LayerRoom && s != LayerGroup && s != LayerUser {
@@ -64,3 +85,53 @@ type DynamicConfiguration struct {
 	// value is unrelated to that.
 	Secret bool
 }
+
+// String returns a description of the configuration that's safe to log: if
+// it's a secret, its value is redacted.
+func (c DynamicConfiguration) String() string {
+	value := c.Value
+	if c.Secret {
+		value = RedactedValue
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s=%s", c.Bundle, c.Layer, c.Owner, c.Key, value)
+}
+
+// minRedactedSecretLength is the length below which secret values aren't
+// redacted from command output, since they'd match too much ordinary text.
+const minRedactedSecretLength = 4
+
+// RedactSecrets returns a copy of lines in which every occurrence of the
+// value of a secret configuration is replaced with RedactedValue, and true
+// if anything was redacted.
+func RedactSecrets(lines []string, dcs []DynamicConfiguration) ([]string, bool) {
+	var secrets []string
+	for _, dc := range dcs {
+		if dc.Secret && len(dc.Value) >= minRedactedSecretLength {
+			secrets = append(secrets, dc.Value)
+		}
+	}
+	if len(secrets) == 0 {
+		return lines, false
+	}
+
+	// Replace longer secrets first, in case one contains another.
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+
+	redacted := false
+	out := make([]string, len(lines))
+
+	for i, line := range lines {
+		for _, s := range secrets {
+			if strings.Contains(line, s) {
+				line = strings.ReplaceAll(line, s, RedactedValue)
+				redacted = true
+			}
+		}
+		out[i] = line
+	}
+
+	return out, redacted
+}
@@ -48,3 +48,47 @@ func TestDynamicConfigValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestSortDynamicConfigurations(t *testing.T) {
+	dcs := []DynamicConfiguration{
+		{Layer: LayerUser, Key: "K", Value: "user"},
+		{Layer: LayerRoom, Key: "K", Value: "room"},
+		{Layer: LayerBundle, Key: "K", Value: "bundle"},
+		{Layer: LayerGroup, Key: "K", Value: "group-a"},
+		{Layer: LayerGroup, Key: "K", Value: "group-b"},
+	}
+
+	SortDynamicConfigurations(dcs)
+
+	var values []string
+	for _, dc := range dcs {
+		values = append(values, dc.Value)
+	}
+
+	assert.Equal(t, []string{"bundle", "group-a", "group-b", "room", "user"}, values)
+}
+
+func TestDynamicConfigurationString(t *testing.T) {
+	dc := DynamicConfiguration{Bundle: "b", Layer: LayerRoom, Owner: "ops", Key: "TOKEN", Value: "hunter2"}
+	assert.Equal(t, "b/room/ops/TOKEN=hunter2", dc.String())
+
+	dc.Secret = true
+	assert.Equal(t, "b/room/ops/TOKEN=[REDACTED]", dc.String())
+}
+
+func TestRedactSecrets(t *testing.T) {
+	dcs := []DynamicConfiguration{
+		{Key: "TOKEN", Value: "hunter2", Secret: true},
+		{Key: "LONG", Value: "hunter2hunter2", Secret: true},
+		{Key: "SHORT", Value: "ab", Secret: true},
+		{Key: "REGION", Value: "us-east-1"},
+	}
+
+	lines, redacted := RedactSecrets([]string{"token=hunter2", "long=hunter2hunter2", "ab us-east-1"}, dcs)
+	assert.True(t, redacted)
+	assert.Equal(t, []string{"token=[REDACTED]", "long=[REDACTED]", "ab us-east-1"}, lines)
+
+	lines, redacted = RedactSecrets([]string{"nothing here"}, dcs)
+	assert.False(t, redacted)
+	assert.Equal(t, []string{"nothing here"}, lines)
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrUnknownEmoji is returned by ParseEmoji if an emoji alias isn't
+	// recognized.
+	ErrUnknownEmoji = errors.New("unknown emoji alias")
+
+	// ErrInvalidEmoji is returned by ParseEmoji if a value is neither an
+	// alias, a custom emoji, nor a Unicode emoji.
+	ErrInvalidEmoji = errors.New("invalid emoji")
+
+	// ErrUnsupportedEmoji is returned by an adapter if an emoji can't be
+	// represented by its chat provider.
+	ErrUnsupportedEmoji = errors.New("emoji not supported by chat provider")
+)
+
+// CustomEmojiPrefix marks an emoji that's specific to a chat provider, like
+// a Slack workspace's custom emoji or a Discord server's "name:id" emoji.
+// Custom emoji are passed to the provider as-is.
+const CustomEmojiPrefix = "custom:"
+
+// Emoji is Gort's provider-independent representation of an emoji. Exactly
+// one of Unicode or Custom is set. Adapters map it to whatever their chat
+// provider expects.
+type Emoji struct {
+	// Unicode is the emoji's Unicode representation, like "\U0001F44D".
+	Unicode string
+
+	// Custom is the provider-specific name of a custom emoji.
+	Custom string
+}
+
+// String returns the canonical form of the emoji: its Unicode
+// representation or, for a custom emoji, its name prefixed by "custom:".
+func (e Emoji) String() string {
+	if e.Custom != "" {
+		return CustomEmojiPrefix + e.Custom
+	}
+
+	return e.Unicode
+}
+
+// Alias returns the emoji's alias, like "thumbsup", if it has one. Custom
+// emoji never do.
+func (e Emoji) Alias() (string, bool) {
+	if e.Custom != "" {
+		return "", false
+	}
+
+	alias, ok := emojiAliasesByUnicode[stripVariationSelectors(e.Unicode)]
+	return alias, ok
+}
+
+// stripVariationSelectors removes the emoji presentation selector (U+FE0F),
+// which is often omitted, so that emoji can be compared with or without it.
+func stripVariationSelectors(s string) string {
+	return strings.ReplaceAll(s, "\uFE0F", "")
+}
+
+// ParseEmoji parses an emoji, which may be given as a Unicode emoji (like
+// "\U0001F44D"), an alias with or without surrounding colons (like
+// ":thumbsup:" or "thumbsup"), or a custom emoji prefixed by "custom:"
+// (like "custom:partyparrot").
+func ParseEmoji(s string) (Emoji, error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case s == "":
+		return Emoji{}, fmt.Errorf("%w: empty value", ErrInvalidEmoji)
+
+	case strings.HasPrefix(s, CustomEmojiPrefix):
+		name := strings.TrimPrefix(s, CustomEmojiPrefix)
+		if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+			return Emoji{}, fmt.Errorf("%w: %q", ErrInvalidEmoji, s)
+		}
+		return Emoji{Custom: name}, nil
+
+	case isEmojiAlias(s):
+		alias := strings.ToLower(strings.Trim(s, ":"))
+		if u, ok := emojiUnicodeByAlias[alias]; ok {
+			return Emoji{Unicode: u}, nil
+		}
+		return Emoji{}, fmt.Errorf("%w: %q", ErrUnknownEmoji, s)
+	}
+
+	for _, r := range s {
+		if r <= unicode.MaxASCII || unicode.IsSpace(r) {
+			return Emoji{}, fmt.Errorf("%w: %q", ErrInvalidEmoji, s)
+		}
+	}
+
+	return Emoji{Unicode: s}, nil
+}
+
+// isEmojiAlias returns true if s looks like an emoji alias: ASCII letters,
+// digits, and "_", "+", or "-", optionally surrounded by colons.
+func isEmojiAlias(s string) bool {
+	if strings.HasPrefix(s, ":") != strings.HasSuffix(s, ":") {
+		return false
+	}
+
+	s = strings.Trim(s, ":")
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		switch {
+		case r > unicode.MaxASCII:
+			return false
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_', r == '+', r == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// emojiAliases lists the recognized emoji aliases. Where an emoji has more
+// than one alias, the first listed is the one returned by Emoji.Alias. The
+// names match Slack's, so that an alias can be passed to Slack as-is.
+var emojiAliases = []struct{ alias, unicode string }{
+	{"+1", "\U0001F44D"},
+	{"thumbsup", "\U0001F44D"},
+	{"-1", "\U0001F44E"},
+	{"thumbsdown", "\U0001F44E"},
+	{"white_check_mark", "\u2705"},
+	{"heavy_check_mark", "\u2714\uFE0F"},
+	{"x", "\u274C"},
+	{"negative_squared_cross_mark", "\u274E"},
+	{"warning", "\u26A0\uFE0F"},
+	{"no_entry", "\u26D4"},
+	{"no_entry_sign", "\U0001F6AB"},
+	{"question", "\u2753"},
+	{"exclamation", "\u2757"},
+	{"hourglass", "\u231B"},
+	{"hourglass_flowing_sand", "\u23F3"},
+	{"stopwatch", "\u23F1\uFE0F"},
+	{"eyes", "\U0001F440"},
+	{"rocket", "\U0001F680"},
+	{"tada", "\U0001F389"},
+	{"fire", "\U0001F525"},
+	{"bug", "\U0001F41B"},
+	{"lock", "\U0001F512"},
+	{"unlock", "\U0001F513"},
+	{"key", "\U0001F511"},
+	{"construction", "\U0001F6A7"},
+	{"robot_face", "\U0001F916"},
+	{"wave", "\U0001F44B"},
+	{"clap", "\U0001F44F"},
+	{"pray", "\U0001F64F"},
+	{"ok_hand", "\U0001F44C"},
+	{"heart", "\u2764\uFE0F"},
+	{"star", "\u2B50"},
+	{"sparkles", "\u2728"},
+	{"zap", "\u26A1"},
+	{"boom", "\U0001F4A5"},
+	{"bell", "\U0001F514"},
+	{"gear", "\u2699\uFE0F"},
+	{"wrench", "\U0001F527"},
+	{"hammer", "\U0001F528"},
+	{"mag", "\U0001F50D"},
+	{"memo", "\U0001F4DD"},
+	{"pushpin", "\U0001F4CC"},
+	{"package", "\U0001F4E6"},
+	{"inbox_tray", "\U0001F4E5"},
+	{"outbox_tray", "\U0001F4E4"},
+	{"repeat", "\U0001F501"},
+	{"arrows_counterclockwise", "\U0001F504"},
+	{"thinking_face", "\U0001F914"},
+	{"smile", "\U0001F604"},
+	{"slightly_smiling_face", "\U0001F642"},
+	{"disappointed", "\U0001F61E"},
+	{"100", "\U0001F4AF"},
+	{"large_green_circle", "\U0001F7E2"},
+	{"large_yellow_circle", "\U0001F7E1"},
+	{"red_circle", "\U0001F534"},
+}
+
+var (
+	emojiUnicodeByAlias   = map[string]string{}
+	emojiAliasesByUnicode = map[string]string{}
+)
+
+func init() {
+	for _, a := range emojiAliases {
+		emojiUnicodeByAlias[a.alias] = a.unicode
+
+		u := stripVariationSelectors(a.unicode)
+		if _, ok := emojiAliasesByUnicode[u]; !ok {
+			emojiAliasesByUnicode[u] = a.alias
+		}
+	}
+}
+
+// CommandReactions describes the emoji with which Gort reacts to the
+// message that invoked a command, as defined in the
+// bundles/commands/reactions section of the config. Each is parsed by
+// ParseEmoji; an empty value means no reaction.
+type CommandReactions struct {
+	// Success is added when the command exits with status 0.
+	Success string `yaml:"success,omitempty" json:"success,omitempty"`
+
+	// Failure is added when the command exits with any other status.
+	Failure string `yaml:"failure,omitempty" json:"failure,omitempty"`
+}
+
+// Validate returns an error if either reaction isn't a valid emoji.
+func (r CommandReactions) Validate() error {
+	if r.Success != "" {
+		if _, err := ParseEmoji(r.Success); err != nil {
+			return fmt.Errorf("reactions.success: %w", err)
+		}
+	}
+
+	if r.Failure != "" {
+		if _, err := ParseEmoji(r.Failure); err != nil {
+			return fmt.Errorf("reactions.failure: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// For returns the reaction for a command that exited with the given code,
+// or the empty string if there's none.
+func (r CommandReactions) For(exitCode int16) string {
+	if exitCode == 0 {
+		return r.Success
+	}
+
+	return r.Failure
+}
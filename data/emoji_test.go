@@ -0,0 +1,79 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEmoji(t *testing.T) {
+	tests := []struct {
+		Value    string
+		Expected Emoji
+		Err      error
+	}{
+		{"\U0001F44D", Emoji{Unicode: "\U0001F44D"}, nil},
+		{"thumbsup", Emoji{Unicode: "\U0001F44D"}, nil},
+		{":thumbsup:", Emoji{Unicode: "\U0001F44D"}, nil},
+		{":+1:", Emoji{Unicode: "\U0001F44D"}, nil},
+		{" :White_Check_Mark: ", Emoji{Unicode: "\u2705"}, nil},
+		{"custom:partyparrot", Emoji{Custom: "partyparrot"}, nil},
+		{"custom:gort:123456", Emoji{Custom: "gort:123456"}, nil},
+		{"", Emoji{}, ErrInvalidEmoji},
+		{"custom:", Emoji{}, ErrInvalidEmoji},
+		{":thumbsup", Emoji{}, ErrInvalidEmoji},
+		{"thumbs up", Emoji{}, ErrInvalidEmoji},
+		{"\U0001F44Dx", Emoji{}, ErrInvalidEmoji},
+		{"no_such_emoji", Emoji{}, ErrUnknownEmoji},
+	}
+
+	for _, test := range tests {
+		e, err := ParseEmoji(test.Value)
+		if test.Err != nil {
+			assert.ErrorIs(t, err, test.Err, test.Value)
+			continue
+		}
+
+		assert.NoError(t, err, test.Value)
+		assert.Equal(t, test.Expected, e, test.Value)
+	}
+}
+
+func TestEmojiAlias(t *testing.T) {
+	alias, ok := Emoji{Unicode: "\U0001F44D"}.Alias()
+	assert.True(t, ok)
+	assert.Equal(t, "+1", alias)
+
+	// The presentation selector is optional.
+	alias, ok = Emoji{Unicode: "\u26A0"}.Alias()
+	assert.True(t, ok)
+	assert.Equal(t, "warning", alias)
+
+	_, ok = Emoji{Unicode: "\U0001F9A9"}.Alias()
+	assert.False(t, ok)
+
+	_, ok = Emoji{Custom: "partyparrot"}.Alias()
+	assert.False(t, ok)
+}
+
+func TestCommandReactionsValidate(t *testing.T) {
+	assert.NoError(t, CommandReactions{}.Validate())
+	assert.NoError(t, CommandReactions{Success: ":white_check_mark:", Failure: "x"}.Validate())
+	assert.ErrorIs(t, CommandReactions{Failure: "no_such_emoji"}.Validate(), ErrUnknownEmoji)
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// EncryptionProviderAESGCM encrypts values with AES-GCM, using a key from
+// the configuration.
+const EncryptionProviderAESGCM = "aes-gcm"
+
+// EncryptionConfigs is the data wrapper for the "encryption" section, which
+// configures how secret values (such as secret dynamic configurations) are
+// encrypted before they're stored.
+type EncryptionConfigs struct {
+	// Provider is the encryption provider. Only "aes-gcm" is currently
+	// supported. If it's empty, secrets are stored unencrypted.
+	Provider string `yaml:"provider,omitempty"`
+
+	// Key is the base64-encoded 16, 24, or 32 byte AES key. If it's empty
+	// it's read from KeyFile, or else from the GORT_ENCRYPTION_KEY envvar.
+	Key     string `yaml:"key,omitempty"`
+	KeyFile string `yaml:"key_file,omitempty"`
+}
+
+// Validate returns an error if the provider is unsupported, or if a key is
+// set that isn't a valid AES key.
+func (c EncryptionConfigs) Validate() error {
+	switch c.Provider {
+	case "":
+		return nil
+	case EncryptionProviderAESGCM:
+	default:
+		return fmt.Errorf("unsupported provider %q", c.Provider)
+	}
+
+	if c.Key != "" && c.KeyFile != "" {
+		return fmt.Errorf("only one of key and key_file may be set")
+	}
+
+	if c.Key != "" {
+		if _, err := DecodeEncryptionKey(c.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DecodeEncryptionKey decodes a base64-encoded AES key, returning an error
+// if it isn't 16, 24, or 32 bytes long.
+func DecodeEncryptionKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64 encoded: %w", err)
+	}
+
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("key must be 16, 24, or 32 bytes long, not %d", len(key))
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ErrorDetailKey is the key under which a command reports an ErrorDetail.
+// A command that wants to describe its failure in a structured way writes,
+// as the last line of its output before exiting with a non-zero status, a
+// JSON object with this single key:
+//
+//	{"gort_error": {"code": "EX-1", "message": "...", "hint": "...", "docs_url": "..."}}
+const ErrorDetailKey = "gort_error"
+
+// ErrorDetail is a structured description of a command failure, emitted by
+// the command itself. It lets CommandError templates render an actionable
+// message rather than the command's raw output.
+type ErrorDetail struct {
+	// Code is a command-defined error code, like "MYBUNDLE-42".
+	Code string `json:"code,omitempty"`
+
+	// Message is a short, human-readable description of the error. It's
+	// the only required field.
+	Message string `json:"message"`
+
+	// Hint suggests what the user can do to resolve the error.
+	Hint string `json:"hint,omitempty"`
+
+	// DocsURL links to documentation that describes the error.
+	DocsURL string `json:"docs_url,omitempty"`
+}
+
+// String returns the error detail as the single line that a command should
+// write to report it.
+func (d ErrorDetail) String() string {
+	b, _ := json.Marshal(map[string]ErrorDetail{ErrorDetailKey: d})
+	return string(b)
+}
+
+// ParseErrorDetail examines the last non-blank line of a command's output.
+// If it's an error detail object (see ErrorDetailKey) with a non-empty
+// message, it returns the detail and the lines that precede it. Otherwise
+// it returns nil and the original lines.
+func ParseErrorDetail(lines []string) (*ErrorDetail, []string) {
+	last := len(lines) - 1
+	for last >= 0 && strings.TrimSpace(lines[last]) == "" {
+		last--
+	}
+	if last < 0 {
+		return nil, lines
+	}
+
+	line := strings.TrimSpace(lines[last])
+	if !strings.HasPrefix(line, "{") {
+		return nil, lines
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &obj); err != nil || len(obj) != 1 {
+		return nil, lines
+	}
+
+	raw, ok := obj[ErrorDetailKey]
+	if !ok {
+		return nil, lines
+	}
+
+	var d ErrorDetail
+	if err := json.Unmarshal(raw, &d); err != nil || strings.TrimSpace(d.Message) == "" {
+		return nil, lines
+	}
+
+	return &d, lines[:last]
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorDetail(t *testing.T) {
+	tests := []struct {
+		Lines  []string
+		Detail *ErrorDetail
+		Rest   []string
+	}{
+		{
+			Lines: nil,
+			Rest:  nil,
+		},
+		{
+			Lines: []string{"something broke"},
+			Rest:  []string{"something broke"},
+		},
+		{
+			Lines:  []string{`{"gort_error":{"message":"no such host"}}`},
+			Detail: &ErrorDetail{Message: "no such host"},
+			Rest:   []string{},
+		},
+		{
+			Lines:  []string{"resolving...", `{"gort_error":{"code":"NET-1","message":"no such host","hint":"Check the spelling.","docs_url":"https://example.com/net-1"}}`, ""},
+			Detail: &ErrorDetail{Code: "NET-1", Message: "no such host", Hint: "Check the spelling.", DocsURL: "https://example.com/net-1"},
+			Rest:   []string{"resolving..."},
+		},
+		{
+			// A detail that isn't the last line is just output.
+			Lines: []string{`{"gort_error":{"message":"no such host"}}`, "done"},
+			Rest:  []string{`{"gort_error":{"message":"no such host"}}`, "done"},
+		},
+		{
+			// The message is required.
+			Lines: []string{`{"gort_error":{"code":"NET-1"}}`},
+			Rest:  []string{`{"gort_error":{"code":"NET-1"}}`},
+		},
+		{
+			// Ordinary JSON output isn't a detail.
+			Lines: []string{`{"gort_error":{"message":"x"},"other":1}`},
+			Rest:  []string{`{"gort_error":{"message":"x"},"other":1}`},
+		},
+		{
+			Lines: []string{`{"gort_error":"no such host"}`},
+			Rest:  []string{`{"gort_error":"no such host"}`},
+		},
+	}
+
+	for i, test := range tests {
+		d, rest := ParseErrorDetail(test.Lines)
+		assert.Equal(t, test.Detail, d, "test %d", i)
+		assert.Equal(t, test.Rest, rest, "test %d", i)
+	}
+}
+
+func TestErrorDetailString(t *testing.T) {
+	d := ErrorDetail{Code: "NET-1", Message: "no such host"}
+
+	p, rest := ParseErrorDetail([]string{d.String()})
+	assert.Equal(t, &d, p)
+	assert.Empty(t, rest)
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The names of Gort's feature flags.
+const (
+	FeatureHAMode          = "ha_mode"
+	FeaturePipelines       = "pipelines"
+	FeatureStreamingOutput = "streaming_output"
+)
+
+// FeatureFlag describes a feature flag, which gates an experimental
+// capability so that it can ship disabled and be enabled per deployment.
+type FeatureFlag struct {
+	Name        string
+	Description string
+
+	// Default is whether the feature is enabled when the "features" config
+	// section doesn't mention it.
+	Default bool
+}
+
+// FeatureFlags is the registry of every feature flag, in name order.
+var FeatureFlags = []FeatureFlag{
+	{
+		Name:        FeatureHAMode,
+		Description: "Run multiple controllers against a shared database",
+	},
+	{
+		Name:        FeaturePipelines,
+		Description: "Pipe the output of one command into the next with '|'",
+	},
+	{
+		Name:        FeatureStreamingOutput,
+		Description: "Send command output to chat as it's produced",
+	},
+}
+
+// LookupFeatureFlag returns the named feature flag, if it exists.
+func LookupFeatureFlag(name string) (FeatureFlag, bool) {
+	for _, f := range FeatureFlags {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return FeatureFlag{}, false
+}
+
+// FeatureConfigs is the data wrapper for the "features" section, which maps
+// feature flag names to whether they're enabled.
+type FeatureConfigs map[string]bool
+
+// Enabled returns whether the named feature is enabled: its configured
+// value if it has one, or its default otherwise. Unknown features are never
+// enabled.
+func (c FeatureConfigs) Enabled(name string) bool {
+	f, ok := LookupFeatureFlag(name)
+	if !ok {
+		return false
+	}
+
+	if enabled, ok := c[name]; ok {
+		return enabled
+	}
+
+	return f.Default
+}
+
+// Validate returns an error if any unknown feature flags are configured.
+func (c FeatureConfigs) Validate() error {
+	var unknown []string
+	for name := range c {
+		if _, ok := LookupFeatureFlag(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown feature flags: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
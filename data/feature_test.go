@@ -0,0 +1,50 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureConfigsEnabled(t *testing.T) {
+	c := FeatureConfigs{FeaturePipelines: true, FeatureHAMode: false}
+
+	assert.True(t, c.Enabled(FeaturePipelines))
+	assert.False(t, c.Enabled(FeatureHAMode))
+	assert.False(t, c.Enabled(FeatureStreamingOutput))
+	assert.False(t, c.Enabled("no_such_feature"))
+
+	assert.False(t, FeatureConfigs(nil).Enabled(FeaturePipelines))
+}
+
+func TestFeatureConfigsValidate(t *testing.T) {
+	assert.NoError(t, FeatureConfigs(nil).Validate())
+	assert.NoError(t, FeatureConfigs{FeaturePipelines: true}.Validate())
+
+	err := FeatureConfigs{"zebra": true, FeaturePipelines: true, "aardvark": false}.Validate()
+	if assert.Error(t, err) {
+		assert.Equal(t, "unknown feature flags: aardvark, zebra", err.Error())
+	}
+}
+
+func TestFeatureFlagsSorted(t *testing.T) {
+	for i := 1; i < len(FeatureFlags); i++ {
+		assert.Less(t, FeatureFlags[i-1].Name, FeatureFlags[i].Name)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ImageAllowlist is a list of patterns describing the worker images that
+// bundles may use. Patterns wrapped in slashes, like "/^ghcr\.io\/acme\//",
+// are regular expressions; all others are globs, in which "*" matches any
+// characters except "/", "**" matches any characters at all, and "?" matches
+// a single character. Patterns are matched against the normalized image
+// reference both with and without its tag, so "docker.io/getgort/*" allows
+// every tag of every getgort image on Docker Hub.
+//
+// An empty allowlist allows all images.
+type ImageAllowlist []string
+
+// Validate returns an error if any of the patterns can't be compiled.
+func (l ImageAllowlist) Validate() error {
+	_, err := l.compile()
+	return err
+}
+
+// Allows returns true if image matches at least one of the patterns, or if
+// the allowlist is empty.
+func (l ImageAllowlist) Allows(image string) (bool, error) {
+	if len(l) == 0 {
+		return true, nil
+	}
+
+	patterns, err := l.compile()
+	if err != nil {
+		return false, err
+	}
+
+	repository, tag := NormalizeImage(image)
+	full := repository + ":" + tag
+
+	for _, re := range patterns {
+		if re.MatchString(full) || re.MatchString(repository) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (l ImageAllowlist) compile() ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(l))
+
+	for _, p := range l {
+		expr := p
+		if len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			expr = p[1 : len(p)-1]
+		} else {
+			expr = globToRegexp(p)
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+	return b.String()
+}
+
+// NormalizeImage expands an image name into a fully-qualified repository and
+// a tag (or digest), the way Docker does: images without a registry are on
+// docker.io, single-component Docker Hub images are in "library", and images
+// without a tag are "latest". For example, "ubuntu" becomes
+// "docker.io/library/ubuntu" and "latest".
+func NormalizeImage(image string) (repository, tag string) {
+	repository = image
+
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository, tag = repository[:i], repository[i+1:]
+	} else if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+
+	if tag == "" {
+		tag = "latest"
+	}
+
+	parts := strings.SplitN(repository, "/", 2)
+	switch {
+	case len(parts) == 1:
+		repository = "docker.io/library/" + repository
+	case !strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost":
+		repository = "docker.io/" + repository
+	}
+
+	return repository, tag
+}
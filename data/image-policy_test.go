@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeImage(t *testing.T) {
+	tests := map[string][2]string{
+		"ubuntu":                 {"docker.io/library/ubuntu", "latest"},
+		"ubuntu:20.04":           {"docker.io/library/ubuntu", "20.04"},
+		"getgort/gort:0.9":       {"docker.io/getgort/gort", "0.9"},
+		"ghcr.io/acme/tool:1":    {"ghcr.io/acme/tool", "1"},
+		"localhost:5000/x/y":     {"localhost:5000/x/y", "latest"},
+		"quay.io/a/b@sha256:abc": {"quay.io/a/b", "sha256:abc"},
+	}
+
+	for image, expected := range tests {
+		repository, tag := NormalizeImage(image)
+		assert.Equal(t, expected[0], repository, image)
+		assert.Equal(t, expected[1], tag, image)
+	}
+}
+
+func TestImageAllowlistAllows(t *testing.T) {
+	l := ImageAllowlist{
+		"docker.io/getgort/*",
+		"docker.io/library/ubuntu:20.04",
+		`/^ghcr\.io\/acme\//`,
+		"quay.io/**",
+	}
+
+	tests := map[string]bool{
+		"getgort/gort:0.9":    true,
+		"getgort/sub/x":       false,
+		"ubuntu:20.04":        true,
+		"ubuntu":              false,
+		"ghcr.io/acme/tool:1": true,
+		"ghcr.io/evil/tool":   false,
+		"quay.io/a/b/c:1":     true,
+	}
+
+	for image, expected := range tests {
+		allowed, err := l.Allows(image)
+		require.NoError(t, err)
+		assert.Equal(t, expected, allowed, image)
+	}
+
+	allowed, err := ImageAllowlist{}.Allows("anything")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestImageAllowlistValidate(t *testing.T) {
+	assert.NoError(t, ImageAllowlist{"docker.io/*", "/^x$/"}.Validate())
+	assert.Error(t, ImageAllowlist{"/[/"}.Validate())
+}
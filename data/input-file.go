@@ -0,0 +1,162 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// InputFileDir is the directory in a worker's container or host that an
+// input file is placed in.
+const InputFileDir = "/gort/input"
+
+// Environment variables that describe an input file to a command.
+const (
+	// EnvInputFile is the path of the input file, if it could be placed in
+	// the command's filesystem.
+	EnvInputFile = "GORT_INPUT_FILE"
+
+	// EnvInputFileBase64 is the base64-encoded content of the input file.
+	// It's only set for workers that can't place the file in the command's
+	// filesystem.
+	EnvInputFileBase64 = "GORT_INPUT_FILE_BASE64"
+
+	// EnvInputFileName is the file's name as it was uploaded.
+	EnvInputFileName = "GORT_INPUT_FILE_NAME"
+
+	// EnvInputFileType is the file's MIME type, as reported by the chat
+	// provider, if known.
+	EnvInputFileType = "GORT_INPUT_FILE_TYPE"
+)
+
+// DefaultInputFileMaxSize is the largest input file accepted when
+// global.input_files.max_size isn't set.
+const DefaultInputFileMaxSize = 1024 * 1024
+
+// DefaultInputFileMaxEnvSize is the largest input file that's passed in the
+// environment when global.input_files.max_env_size isn't set.
+const DefaultInputFileMaxEnvSize = 32 * 1024
+
+// InputFileConfigs is the data wrapper for the "global.input_files" section,
+// which controls the files that users attach to command messages.
+type InputFileConfigs struct {
+	// Disabled prevents attached files from being downloaded.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// MaxSize is the largest file, in bytes, that's accepted. Zero uses
+	// DefaultInputFileMaxSize.
+	MaxSize int `yaml:"max_size,omitempty"`
+
+	// MaxEnvSize is the largest file, in bytes, that's passed to workers
+	// that can only receive it in the environment (as with serverless
+	// workers). Zero uses DefaultInputFileMaxEnvSize.
+	MaxEnvSize int `yaml:"max_env_size,omitempty"`
+}
+
+// Validate returns an error if either size is negative.
+func (c InputFileConfigs) Validate() error {
+	if c.MaxSize < 0 {
+		return fmt.Errorf("max_size must not be negative")
+	}
+
+	if c.MaxEnvSize < 0 {
+		return fmt.Errorf("max_env_size must not be negative")
+	}
+
+	return nil
+}
+
+// MaxSizeOrDefault returns MaxSize, or DefaultInputFileMaxSize if it's unset.
+func (c InputFileConfigs) MaxSizeOrDefault() int {
+	if c.MaxSize == 0 {
+		return DefaultInputFileMaxSize
+	}
+	return c.MaxSize
+}
+
+// MaxEnvSizeOrDefault returns MaxEnvSize, or DefaultInputFileMaxEnvSize if
+// it's unset.
+func (c InputFileConfigs) MaxEnvSizeOrDefault() int {
+	if c.MaxEnvSize == 0 {
+		return DefaultInputFileMaxEnvSize
+	}
+	return c.MaxEnvSize
+}
+
+// InputFile is a file that the user attached to the message that invoked a
+// command. Workers make it available to the command.
+type InputFile struct {
+	// Name is the file's name, as uploaded.
+	Name string
+
+	// ContentType is the file's MIME type, if the provider reported one.
+	ContentType string
+
+	// Data is the file's content.
+	Data []byte
+}
+
+// FileName returns a version of the file's name that's safe to use as a
+// single path element.
+func (f InputFile) FileName() string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r == '/' || r == '\\' || r == 0:
+			return '_'
+		case unicode.IsControl(r):
+			return -1
+		default:
+			return r
+		}
+	}, path.Base(f.Name))
+
+	if name == "" || name == "." || name == ".." || name == "/" || name == "_" {
+		return "input"
+	}
+
+	return name
+}
+
+// Path returns the path at which a worker places the file.
+func (f InputFile) Path() string {
+	return path.Join(InputFileDir, f.FileName())
+}
+
+// EnvVars returns the environment variables that describe the file to the
+// command. If placed is true the file is in the command's filesystem at
+// Path; otherwise its content is passed in EnvInputFileBase64.
+func (f InputFile) EnvVars(placed bool) map[string]string {
+	env := map[string]string{
+		EnvInputFileName: f.Name,
+	}
+
+	if f.ContentType != "" {
+		env[EnvInputFileType] = f.ContentType
+	}
+
+	if placed {
+		env[EnvInputFile] = f.Path()
+	} else {
+		env[EnvInputFileBase64] = base64.StdEncoding.EncodeToString(f.Data)
+	}
+
+	return env
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputFileName(t *testing.T) {
+	tests := map[string]string{
+		"config.yaml":       "config.yaml",
+		"../../etc/passwd":  "passwd",
+		"dir/file.txt":      "file.txt",
+		`C:\Users\me\a.txt`: "C:_Users_me_a.txt",
+		"bad\x00\nname.txt": "bad_name.txt",
+		"..":                "input",
+		"":                  "input",
+		"/":                 "input",
+	}
+
+	for name, expected := range tests {
+		assert.Equal(t, expected, InputFile{Name: name}.FileName(), name)
+	}
+}
+
+func TestInputFileEnvVars(t *testing.T) {
+	f := InputFile{Name: "config.yaml", ContentType: "text/yaml", Data: []byte("a: 1\n")}
+
+	assert.Equal(t, map[string]string{
+		EnvInputFile:     "/gort/input/config.yaml",
+		EnvInputFileName: "config.yaml",
+		EnvInputFileType: "text/yaml",
+	}, f.EnvVars(true))
+
+	assert.Equal(t, map[string]string{
+		EnvInputFileBase64: "YTogMQo=",
+		EnvInputFileName:   "config.yaml",
+		EnvInputFileType:   "text/yaml",
+	}, f.EnvVars(false))
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import "time"
+
+// Lock is a named, time-boxed lock. Locks are used to ensure that only one
+// instance of an exclusive command (one with a non-empty
+// BundleCommand.Exclusive value) can run at a time. A lock that isn't renewed
+// before ExpiresAt is considered abandoned and may be taken by another owner.
+type Lock struct {
+	// Name is the name of the lock, as defined by BundleCommand.Exclusive.
+	Name string `json:"name"`
+
+	// Owner is an opaque identifier for the lock holder. The relay uses the
+	// ID of the command request that holds the lock.
+	Owner string `json:"owner"`
+
+	// UserName is the name of the Gort user on whose behalf the lock is held.
+	UserName string `json:"username,omitempty"`
+
+	// AcquiredAt is the time the lock was acquired.
+	AcquiredAt time.Time `json:"acquired_at"`
+
+	// ExpiresAt is the time the lock will expire if it isn't renewed.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IsExpired returns true if the lock has expired.
+func (l Lock) IsExpired() bool {
+	return time.Now().After(l.ExpiresAt)
+}
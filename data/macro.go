@@ -0,0 +1,145 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ErrBadMacro is returned when a macro's name or command is malformed, or
+// when it's invoked with malformed arguments.
+var ErrBadMacro = errors.New("bad macro")
+
+// Macro is a saved, parameterized command. Its Command is a Go template that
+// is expanded with the arguments it's invoked with; the result is then
+// executed (and its rules evaluated) exactly as if the user had typed it.
+type Macro struct {
+	// Name is the name the macro is invoked by.
+	Name string
+
+	// Layer is the scope of the macro. Must be one of LayerUser or
+	// LayerGroup.
+	Layer ConfigurationLayer
+
+	// Owner is the name of the user if Layer is LayerUser, or of the group
+	// if Layer is LayerGroup.
+	Owner string
+
+	// Command is the command template, like
+	// "kubectl:rollout restart deploy/{{.svc}}".
+	Command string
+}
+
+// ValidateMacroLayer returns an error if layer isn't a layer that supports
+// macros.
+func ValidateMacroLayer(layer ConfigurationLayer) error {
+	switch ConfigurationLayer(strings.ToLower(string(layer))) {
+	case LayerUser, LayerGroup:
+		return nil
+	default:
+		return fmt.Errorf("macro layers must be one of: %v",
+			[]ConfigurationLayer{LayerUser, LayerGroup})
+	}
+}
+
+// Validate returns an error if the macro's name isn't a single word, or if
+// its command isn't a valid template.
+func (m Macro) Validate() error {
+	if strings.ContainsAny(m.Name, ": \t\n") {
+		return fmt.Errorf("%w: macro names may not contain colons or whitespace", ErrBadMacro)
+	}
+
+	if _, err := m.template(); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadMacro, err)
+	}
+
+	return nil
+}
+
+// Expand renders the macro's command using the given arguments. Every
+// argument referenced by the template must be provided.
+func (m Macro) Expand(args map[string]string) (string, error) {
+	t, err := m.template()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBadMacro, err)
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, args); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBadMacro, err)
+	}
+
+	return sb.String(), nil
+}
+
+func (m Macro) template() (*template.Template, error) {
+	return template.New(m.Name).Option("missingkey=error").Parse(m.Command)
+}
+
+// ParseMacroArguments parses macro invocation arguments of the form
+// "name=value" into a map.
+func ParseMacroArguments(tokens []string) (map[string]string, error) {
+	args := map[string]string{}
+
+	for _, t := range tokens {
+		i := strings.Index(t, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("%w: arguments must be of the form name=value: %q", ErrBadMacro, t)
+		}
+
+		args[t[:i]] = t[i+1:]
+	}
+
+	return args, nil
+}
+
+// ResolveMacro finds the macro with the given name that applies to a user
+// who is a member of the given groups. The user's own macros take
+// precedence over their groups'. If more than one of the user's groups has
+// a macro with the name, the group whose name sorts first wins.
+func ResolveMacro(macros []Macro, name, username string, groups []string) (Macro, bool) {
+	member := map[string]bool{}
+	for _, g := range groups {
+		member[g] = true
+	}
+
+	var candidates []Macro
+	for _, m := range macros {
+		if m.Name != name {
+			continue
+		}
+
+		switch {
+		case m.Layer == LayerUser && m.Owner == username:
+			return m, true
+		case m.Layer == LayerGroup && member[m.Owner]:
+			candidates = append(candidates, m)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return Macro{}, false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Owner < candidates[j].Owner })
+
+	return candidates[0], true
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMacroLayer(t *testing.T) {
+	assert.NoError(t, ValidateMacroLayer(LayerUser))
+	assert.NoError(t, ValidateMacroLayer(LayerGroup))
+	assert.Error(t, ValidateMacroLayer(LayerBundle))
+	assert.Error(t, ValidateMacroLayer(LayerRoom))
+	assert.Error(t, ValidateMacroLayer(""))
+}
+
+func TestMacroValidate(t *testing.T) {
+	assert.NoError(t, Macro{Name: "restart-api", Command: "kubectl:rollout restart deploy/{{.svc}}"}.Validate())
+	assert.ErrorIs(t, Macro{Name: "gort:restart", Command: "echo"}.Validate(), ErrBadMacro)
+	assert.ErrorIs(t, Macro{Name: "restart api", Command: "echo"}.Validate(), ErrBadMacro)
+	assert.ErrorIs(t, Macro{Name: "restart", Command: "echo {{.svc"}.Validate(), ErrBadMacro)
+}
+
+func TestMacroExpand(t *testing.T) {
+	m := Macro{Name: "restart-api", Command: "kubectl:rollout restart deploy/{{.svc}} -n {{.ns}}"}
+
+	cmd, err := m.Expand(map[string]string{"svc": "api", "ns": "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, "kubectl:rollout restart deploy/api -n prod", cmd)
+
+	_, err = m.Expand(map[string]string{"svc": "api"})
+	assert.ErrorIs(t, err, ErrBadMacro)
+}
+
+func TestParseMacroArguments(t *testing.T) {
+	args, err := ParseMacroArguments([]string{"svc=api", "msg=a=b", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"svc": "api", "msg": "a=b", "empty": ""}, args)
+
+	_, err = ParseMacroArguments([]string{"api"})
+	assert.ErrorIs(t, err, ErrBadMacro)
+
+	_, err = ParseMacroArguments([]string{"=api"})
+	assert.ErrorIs(t, err, ErrBadMacro)
+}
+
+func TestResolveMacro(t *testing.T) {
+	macros := []Macro{
+		{Name: "deploy", Layer: LayerGroup, Owner: "ops", Command: "ops"},
+		{Name: "deploy", Layer: LayerGroup, Owner: "dev", Command: "dev"},
+		{Name: "deploy", Layer: LayerUser, Owner: "alice", Command: "alice"},
+		{Name: "deploy", Layer: LayerUser, Owner: "bob", Command: "bob"},
+		{Name: "other", Layer: LayerGroup, Owner: "ops", Command: "other"},
+	}
+
+	tests := []struct {
+		username string
+		groups   []string
+		expected string
+		found    bool
+	}{
+		{"alice", []string{"ops"}, "alice", true},
+		{"carol", []string{"ops", "dev"}, "dev", true},
+		{"carol", []string{"ops"}, "ops", true},
+		{"carol", []string{"qa"}, "", false},
+	}
+
+	for _, test := range tests {
+		m, found := ResolveMacro(macros, "deploy", test.username, test.groups)
+		assert.Equal(t, test.found, found, "user=%q groups=%v", test.username, test.groups)
+		assert.Equal(t, test.expected, m.Command, "user=%q groups=%v", test.username, test.groups)
+	}
+}
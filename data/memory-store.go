@@ -0,0 +1,59 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultMemorySnapshotInterval is how often the in-memory data store is
+// snapshotted when memory_store.snapshot_interval isn't set.
+const DefaultMemorySnapshotInterval = time.Minute
+
+// MemoryStoreConfigs is the data wrapper for the "memory_store" section,
+// which configures the in-memory data store that's used when no database is
+// configured. It's intended for demo and development environments.
+type MemoryStoreConfigs struct {
+	// SnapshotFile is the path of a JSON file that the store's users,
+	// groups, roles, tokens, bundles, and other settings are periodically
+	// written to, and restored from at startup. If it's empty, nothing is
+	// persisted.
+	SnapshotFile string `yaml:"snapshot_file,omitempty"`
+
+	// SnapshotInterval is how often a snapshot is written. Zero uses
+	// DefaultMemorySnapshotInterval.
+	SnapshotInterval time.Duration `yaml:"snapshot_interval,omitempty"`
+}
+
+// SnapshotIntervalOrDefault returns the snapshot interval, or
+// DefaultMemorySnapshotInterval if it's not set.
+func (c MemoryStoreConfigs) SnapshotIntervalOrDefault() time.Duration {
+	if c.SnapshotInterval <= 0 {
+		return DefaultMemorySnapshotInterval
+	}
+	return c.SnapshotInterval
+}
+
+// Validate returns an error if the snapshot interval is negative.
+func (c MemoryStoreConfigs) Validate() error {
+	if c.SnapshotInterval < 0 {
+		return fmt.Errorf("snapshot_interval must not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OptionDefault is an administrator-defined default value for a command
+// option. Defaults are applied when a command is bound to its parameters,
+// and only to options that the user didn't provide explicitly.
+type OptionDefault struct {
+	// Bundle and Command identify the command the default applies to.
+	Bundle  string
+	Command string
+
+	// Layer is the scope of the default. Must be one of LayerBundle,
+	// LayerGroup, or LayerRoom.
+	Layer ConfigurationLayer
+
+	// Owner is the name of the group if Layer is LayerGroup, or the channel
+	// ID if Layer is LayerRoom. It's ignored for LayerBundle.
+	Owner string
+
+	// Option is the option name, without leading dashes.
+	Option string
+
+	// Value is the default value. If empty, the option is set as a flag.
+	Value string
+}
+
+// ValidateOptionDefaultLayer returns an error if layer isn't a layer that
+// supports option defaults.
+func ValidateOptionDefaultLayer(layer ConfigurationLayer) error {
+	switch ConfigurationLayer(strings.ToLower(string(layer))) {
+	case LayerBundle, LayerGroup, LayerRoom:
+		return nil
+	default:
+		return fmt.Errorf("option default layers must be one of: %v",
+			[]ConfigurationLayer{LayerBundle, LayerGroup, LayerRoom})
+	}
+}
+
+// ResolveOptionDefaults determines the effective default value for each
+// option in defaults for a user in the given channel and groups. Channel
+// defaults take precedence over group defaults, which take precedence over
+// bundle defaults. If the user is in more than one group with a default for
+// the same option, the group whose name sorts first wins. Defaults for other
+// channels and groups are ignored.
+func ResolveOptionDefaults(defaults []OptionDefault, channel string, groups []string) map[string]string {
+	member := map[string]bool{}
+	for _, g := range groups {
+		member[g] = true
+	}
+
+	precedence := map[ConfigurationLayer]int{LayerBundle: 1, LayerGroup: 2, LayerRoom: 3}
+
+	applicable := []OptionDefault{}
+	for _, d := range defaults {
+		switch d.Layer {
+		case LayerBundle:
+		case LayerGroup:
+			if !member[d.Owner] {
+				continue
+			}
+		case LayerRoom:
+			if d.Owner != channel {
+				continue
+			}
+		default:
+			continue
+		}
+
+		applicable = append(applicable, d)
+	}
+
+	// Order from lowest to highest precedence so that later entries win.
+	sort.SliceStable(applicable, func(i, j int) bool {
+		a, b := applicable[i], applicable[j]
+		if precedence[a.Layer] != precedence[b.Layer] {
+			return precedence[a.Layer] < precedence[b.Layer]
+		}
+		return a.Owner > b.Owner
+	})
+
+	values := map[string]string{}
+	for _, d := range applicable {
+		values[d.Option] = d.Value
+	}
+
+	return values
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOptionDefaultLayer(t *testing.T) {
+	assert.NoError(t, ValidateOptionDefaultLayer(LayerBundle))
+	assert.NoError(t, ValidateOptionDefaultLayer(LayerGroup))
+	assert.NoError(t, ValidateOptionDefaultLayer(LayerRoom))
+	assert.Error(t, ValidateOptionDefaultLayer(LayerUser))
+	assert.Error(t, ValidateOptionDefaultLayer(""))
+}
+
+func TestResolveOptionDefaults(t *testing.T) {
+	defaults := []OptionDefault{
+		{Layer: LayerRoom, Owner: "C123", Option: "region", Value: "us-east-1"},
+		{Layer: LayerRoom, Owner: "C999", Option: "profile", Value: "other-channel"},
+		{Layer: LayerGroup, Owner: "eu-ops", Option: "region", Value: "eu-west-1"},
+		{Layer: LayerGroup, Owner: "eu-ops", Option: "profile", Value: "eu"},
+		{Layer: LayerGroup, Owner: "ap-ops", Option: "profile", Value: "ap"},
+		{Layer: LayerGroup, Owner: "us-ops", Option: "verbose", Value: ""},
+		{Layer: LayerBundle, Option: "region", Value: "us-west-2"},
+		{Layer: LayerBundle, Option: "output", Value: "json"},
+	}
+
+	tests := []struct {
+		channel  string
+		groups   []string
+		expected map[string]string
+	}{
+		{
+			channel:  "",
+			groups:   nil,
+			expected: map[string]string{"region": "us-west-2", "output": "json"},
+		},
+		{
+			channel:  "",
+			groups:   []string{"eu-ops"},
+			expected: map[string]string{"region": "eu-west-1", "profile": "eu", "output": "json"},
+		},
+		{
+			channel:  "C123",
+			groups:   []string{"eu-ops", "ap-ops"},
+			expected: map[string]string{"region": "us-east-1", "profile": "ap", "output": "json"},
+		},
+		{
+			channel:  "C123",
+			groups:   []string{"us-ops"},
+			expected: map[string]string{"region": "us-east-1", "verbose": "", "output": "json"},
+		},
+	}
+
+	for _, test := range tests {
+		actual := ResolveOptionDefaults(defaults, test.channel, test.groups)
+		assert.Equal(t, test.expected, actual, "channel=%q groups=%v", test.channel, test.groups)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultOutboxRetryInterval is how long an undelivered response waits
+	// before it's resent when global.outbox.retry_interval isn't set.
+	DefaultOutboxRetryInterval = time.Minute
+
+	// DefaultOutboxMaxAttempts is the number of times a response is resent
+	// before it's given up on when global.outbox.max_attempts isn't set.
+	DefaultOutboxMaxAttempts = 5
+
+	// DefaultOutboxRetention is how long delivered responses are kept when
+	// global.outbox.retention isn't set.
+	DefaultOutboxRetention = 24 * time.Hour
+)
+
+// OutboxEntry is a command response that's been persisted before being sent
+// to its chat channel. The entry's request ID doubles as its deduplication
+// marker: a response is stored at most once per request, and is resent
+// until it's marked as delivered.
+type OutboxEntry struct {
+	// RequestID is the ID of the command request the response belongs to.
+	RequestID int64 `json:"request_id"`
+
+	// Adapter and ChannelID identify where the response is to be sent.
+	Adapter   string `json:"adapter"`
+	ChannelID string `json:"channel_id"`
+
+	// Envelope is the JSON-encoded response envelope. See NewOutboxEntry.
+	Envelope string `json:"envelope"`
+
+	// Attempts is the number of times delivery has been attempted.
+	Attempts int `json:"attempts"`
+
+	// Created is when the entry was created, LastAttempt is when delivery
+	// was most recently attempted, and Delivered is when delivery succeeded
+	// or was given up on. Delivered is zero while the entry is pending.
+	Created     time.Time `json:"created"`
+	LastAttempt time.Time `json:"last_attempt"`
+	Delivered   time.Time `json:"delivered,omitempty"`
+}
+
+// outboxEnvelope is the stored form of a CommandResponseEnvelope. The
+// envelope's error is stored as a string, since error values can't be
+// decoded from JSON.
+type outboxEnvelope struct {
+	CommandResponseEnvelope
+	Error string `json:",omitempty"`
+}
+
+// NewOutboxEntry returns a pending outbox entry for a response envelope. The
+// request's context and input file aren't stored.
+func NewOutboxEntry(envelope CommandResponseEnvelope) (OutboxEntry, error) {
+	stored := outboxEnvelope{CommandResponseEnvelope: envelope}
+	stored.Request.Context = nil
+	stored.Request.InputFile = nil
+
+	if err := envelope.Data.Error; err != nil {
+		stored.Data.Error = nil
+		stored.Error = err.Error()
+	}
+
+	b, err := json.Marshal(stored)
+	if err != nil {
+		return OutboxEntry{}, fmt.Errorf("failed to encode response envelope: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	return OutboxEntry{
+		RequestID:   envelope.Request.RequestID,
+		Adapter:     envelope.Request.Adapter,
+		ChannelID:   envelope.Request.ChannelID,
+		Envelope:    string(b),
+		Created:     now,
+		LastAttempt: now,
+	}, nil
+}
+
+// CommandResponseEnvelope decodes the entry's stored response envelope.
+func (e OutboxEntry) CommandResponseEnvelope() (CommandResponseEnvelope, error) {
+	var stored outboxEnvelope
+	if err := json.Unmarshal([]byte(e.Envelope), &stored); err != nil {
+		return CommandResponseEnvelope{}, fmt.Errorf("failed to decode response envelope: %w", err)
+	}
+
+	envelope := stored.CommandResponseEnvelope
+	if stored.Error != "" {
+		envelope.Data.Error = errors.New(stored.Error)
+	}
+
+	return envelope, nil
+}
+
+// IsDelivered returns true if the entry has been marked as delivered.
+func (e OutboxEntry) IsDelivered() bool {
+	return !e.Delivered.IsZero()
+}
+
+// OutboxConfigs is the data wrapper for the "global.outbox" section. If it's
+// enabled, every command response is persisted before it's sent, and
+// responses that weren't delivered (because Gort was restarted before they
+// could be, for example) are resent, so that each is delivered at least
+// once.
+type OutboxConfigs struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// RetryInterval is how long an undelivered response waits after its most
+	// recent attempt before it's resent.
+	RetryInterval time.Duration `yaml:"retry_interval,omitempty"`
+
+	// MaxAttempts is the number of times a response is resent before it's
+	// given up on.
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
+
+	// Retention is how long delivered responses are kept before they're
+	// purged.
+	Retention time.Duration `yaml:"retention,omitempty"`
+}
+
+// Validate returns an error if any duration or count is negative.
+func (c OutboxConfigs) Validate() error {
+	switch {
+	case c.RetryInterval < 0:
+		return fmt.Errorf("retry_interval must not be negative")
+	case c.MaxAttempts < 0:
+		return fmt.Errorf("max_attempts must not be negative")
+	case c.Retention < 0:
+		return fmt.Errorf("retention must not be negative")
+	}
+
+	return nil
+}
+
+// RetryIntervalOrDefault returns RetryInterval, or
+// DefaultOutboxRetryInterval if it's unset.
+func (c OutboxConfigs) RetryIntervalOrDefault() time.Duration {
+	if c.RetryInterval == 0 {
+		return DefaultOutboxRetryInterval
+	}
+	return c.RetryInterval
+}
+
+// MaxAttemptsOrDefault returns MaxAttempts, or DefaultOutboxMaxAttempts if
+// it's unset.
+func (c OutboxConfigs) MaxAttemptsOrDefault() int {
+	if c.MaxAttempts == 0 {
+		return DefaultOutboxMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+// RetentionOrDefault returns Retention, or DefaultOutboxRetention if it's
+// unset.
+func (c OutboxConfigs) RetentionOrDefault() time.Duration {
+	if c.Retention == 0 {
+		return DefaultOutboxRetention
+	}
+	return c.Retention
+}
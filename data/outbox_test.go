@@ -0,0 +1,64 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxEntryEnvelope(t *testing.T) {
+	request := CommandRequest{
+		Adapter:   "slack",
+		ChannelID: "C123",
+		Context:   context.Background(),
+		InputFile: &InputFile{Name: "data.csv", Data: []byte("a,b")},
+		RequestID: 42,
+		UserName:  "alice",
+	}
+
+	envelope := NewCommandResponseEnvelope(request,
+		WithError("Oops", errors.New("something broke"), 3))
+
+	entry, err := NewOutboxEntry(envelope)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), entry.RequestID)
+	assert.Equal(t, "slack", entry.Adapter)
+	assert.Equal(t, "C123", entry.ChannelID)
+	assert.False(t, entry.IsDelivered())
+
+	got, err := entry.CommandResponseEnvelope()
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Request.UserName)
+	assert.Nil(t, got.Request.Context)
+	assert.Nil(t, got.Request.InputFile)
+	assert.Equal(t, envelope.Response.Lines, got.Response.Lines)
+	assert.Equal(t, envelope.Data.ExitCode, got.Data.ExitCode)
+	require.Error(t, got.Data.Error)
+	assert.Equal(t, "something broke", got.Data.Error.Error())
+
+	// The original envelope isn't modified.
+	assert.NotNil(t, envelope.Request.Context)
+	assert.NotNil(t, envelope.Data.Error)
+
+	_, err = OutboxEntry{Envelope: "{"}.CommandResponseEnvelope()
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// OutputFilters describes the post-processing applied to command output
+// before it's rendered. It's used by both the "global.output_filters" config
+// section and a bundle's "output_filters" section.
+type OutputFilters struct {
+	// Redact is a list of regular expressions; matching output text is
+	// replaced with RedactedValue.
+	Redact []string `yaml:"redact,omitempty" json:"redact,omitempty"`
+
+	// MaxLines is the maximum number of output lines kept. Zero is no limit.
+	MaxLines int `yaml:"max_lines,omitempty" json:"max_lines,omitempty"`
+
+	// StripANSI removes ANSI escape sequences from the output.
+	StripANSI bool `yaml:"strip_ansi,omitempty" json:"strip_ansi,omitempty"`
+}
+
+// IsZero returns true if f doesn't filter anything.
+func (f OutputFilters) IsZero() bool {
+	return len(f.Redact) == 0 && f.MaxLines == 0 && !f.StripANSI
+}
+
+// Validate returns an error if any of the redaction patterns isn't a valid
+// regular expression, or if MaxLines is negative.
+func (f OutputFilters) Validate() error {
+	if f.MaxLines < 0 {
+		return fmt.Errorf("invalid max_lines: %d", f.MaxLines)
+	}
+
+	_, err := f.compile()
+	return err
+}
+
+// Merge combines f with o. The result uses the patterns of both, the smaller
+// non-zero line limit, and strips ANSI sequences if either does, so merging
+// can only make filtering stricter.
+func (f OutputFilters) Merge(o OutputFilters) OutputFilters {
+	m := OutputFilters{
+		Redact:    append(append([]string{}, f.Redact...), o.Redact...),
+		MaxLines:  f.MaxLines,
+		StripANSI: f.StripANSI || o.StripANSI,
+	}
+
+	if o.MaxLines > 0 && (m.MaxLines == 0 || o.MaxLines < m.MaxLines) {
+		m.MaxLines = o.MaxLines
+	}
+
+	return m
+}
+
+// Apply returns a filtered copy of lines. ANSI sequences are stripped first so
+// that redaction patterns match the visible text, and truncation happens last.
+// If any lines are dropped a final line noting how many is appended.
+func (f OutputFilters) Apply(lines []string) ([]string, error) {
+	patterns, err := f.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(lines))
+
+	for i, line := range lines {
+		if f.MaxLines > 0 && i >= f.MaxLines {
+			out = append(out, fmt.Sprintf("[%d more lines truncated]", len(lines)-i))
+			break
+		}
+
+		if f.StripANSI {
+			line = StripANSI(line)
+		}
+
+		for _, re := range patterns {
+			line = re.ReplaceAllString(line, RedactedValue)
+		}
+
+		out = append(out, line)
+	}
+
+	return out, nil
+}
+
+func (f OutputFilters) compile() ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(f.Redact))
+
+	for _, p := range f.Redact {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
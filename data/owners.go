@@ -0,0 +1,105 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultFailureNotificationThreshold is the number of failures that
+	// trigger a notice when global.failure_notifications.threshold isn't set.
+	DefaultFailureNotificationThreshold = 3
+
+	// DefaultFailureNotificationWindow is the window within which failures
+	// are counted when global.failure_notifications.window isn't set.
+	DefaultFailureNotificationWindow = 15 * time.Minute
+)
+
+// FailureNotificationConfigs is the data wrapper for the
+// "global.failure_notifications" section, which controls how the owners of
+// a bundle are told that its commands are failing repeatedly.
+type FailureNotificationConfigs struct {
+	// Disabled turns failure notifications off entirely.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// Threshold is the number of times a command must fail within Window
+	// for its bundle's owners to be notified. Each notice resets the count.
+	Threshold int           `yaml:"threshold,omitempty"`
+	Window    time.Duration `yaml:"window,omitempty"`
+
+	// NotifyAdapter and NotifyChannel identify a channel to which notices
+	// are sent. If they're empty, each member of the owning groups is sent
+	// a direct message instead.
+	NotifyAdapter string `yaml:"notify_adapter,omitempty"`
+	NotifyChannel string `yaml:"notify_channel,omitempty"`
+}
+
+// Validate returns an error if the threshold or window is negative, or if
+// only one of NotifyAdapter and NotifyChannel is set.
+func (c FailureNotificationConfigs) Validate() error {
+	if c.Threshold < 0 {
+		return fmt.Errorf("threshold must not be negative")
+	}
+
+	if c.Window < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+
+	if (c.NotifyAdapter == "") != (c.NotifyChannel == "") {
+		return fmt.Errorf("notify_adapter and notify_channel must be set together")
+	}
+
+	return nil
+}
+
+// ThresholdOrDefault returns Threshold, or DefaultFailureNotificationThreshold
+// if it's unset.
+func (c FailureNotificationConfigs) ThresholdOrDefault() int {
+	if c.Threshold == 0 {
+		return DefaultFailureNotificationThreshold
+	}
+	return c.Threshold
+}
+
+// WindowOrDefault returns Window, or DefaultFailureNotificationWindow if it's
+// unset.
+func (c FailureNotificationConfigs) WindowOrDefault() time.Duration {
+	if c.Window == 0 {
+		return DefaultFailureNotificationWindow
+	}
+	return c.Window
+}
+
+// ValidateOwners returns an error if any of the bundle's owners is empty or
+// listed more than once. Owners are the names of Gort groups.
+func (b Bundle) ValidateOwners() error {
+	seen := map[string]bool{}
+
+	for _, o := range b.Owners {
+		if o == "" {
+			return fmt.Errorf("owner group names must not be empty")
+		}
+		if seen[o] {
+			return fmt.Errorf("owner %q is listed more than once", o)
+		}
+		seen[o] = true
+	}
+
+	return nil
+}
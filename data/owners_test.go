@@ -0,0 +1,46 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureNotificationConfigs(t *testing.T) {
+	var c FailureNotificationConfigs
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, DefaultFailureNotificationThreshold, c.ThresholdOrDefault())
+	assert.Equal(t, DefaultFailureNotificationWindow, c.WindowOrDefault())
+
+	c = FailureNotificationConfigs{Threshold: 5, Window: time.Hour}
+	assert.Equal(t, 5, c.ThresholdOrDefault())
+	assert.Equal(t, time.Hour, c.WindowOrDefault())
+
+	assert.Error(t, FailureNotificationConfigs{Threshold: -1}.Validate())
+	assert.Error(t, FailureNotificationConfigs{NotifyAdapter: "slack"}.Validate())
+	assert.NoError(t, FailureNotificationConfigs{NotifyAdapter: "slack", NotifyChannel: "C01"}.Validate())
+}
+
+func TestValidateOwners(t *testing.T) {
+	assert.NoError(t, Bundle{}.ValidateOwners())
+	assert.NoError(t, Bundle{Owners: []string{"ops", "payments"}}.ValidateOwners())
+	assert.Error(t, Bundle{Owners: []string{"ops", ""}}.ValidateOwners())
+	assert.Error(t, Bundle{Owners: []string{"ops", "ops"}}.ValidateOwners())
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedValue is the placeholder that replaces redacted parameter values.
+const RedactedValue = "[REDACTED]"
+
+// RedactParameters returns a copy of params in which the values of the
+// command's sensitive options, as well as any text matching one of the
+// patterns, are replaced with RedactedValue. Option values may be provided
+// either inline ("--token=abc") or as the following parameter ("--token abc").
+// Options after a "--" terminator aren't treated as options.
+func (c BundleCommand) RedactParameters(params []string, patterns ...*regexp.Regexp) CommandParameters {
+	out := make(CommandParameters, len(params))
+
+	redactNext := false
+	optionsDone := false
+
+	for i, p := range params {
+		switch {
+		case redactNext:
+			p = RedactedValue
+			redactNext = false
+
+		case optionsDone || !strings.HasPrefix(p, "-") || p == "-":
+
+		case p == "--":
+			optionsDone = true
+
+		default:
+			name := strings.TrimLeft(p, "-")
+
+			if j := strings.Index(name, "="); j >= 0 {
+				if c.isSensitiveOption(name[:j]) {
+					p = p[:len(p)-len(name)+j+1] + RedactedValue
+				}
+			} else if c.isSensitiveOption(name) {
+				redactNext = true
+			}
+		}
+
+		for _, re := range patterns {
+			p = re.ReplaceAllString(p, RedactedValue)
+		}
+
+		out[i] = p
+	}
+
+	return out
+}
+
+func (c BundleCommand) isSensitiveOption(name string) bool {
+	o, ok := c.Options[name]
+	return ok && o != nil && o.Sensitive
+}
+
+// RedactedParameters returns the request's parameters with the values of
+// any sensitive options, and any text matching one of the patterns, redacted.
+// It should be used instead of Parameters for anything that's logged or
+// stored.
+func (r CommandRequest) RedactedParameters(patterns ...*regexp.Regexp) CommandParameters {
+	return r.Command.RedactParameters(r.Parameters, patterns...)
+}
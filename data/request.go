@@ -0,0 +1,218 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// RequestStage names one stage in the lifecycle of a command request.
+type RequestStage string
+
+const (
+	// StageLookup is finding the command that matches the user's input and
+	// applying its option defaults.
+	StageLookup RequestStage = "lookup"
+
+	// StageAuth is evaluating the command's rules against the user's
+	// permissions.
+	StageAuth RequestStage = "auth"
+
+	// StageSchedule is the relay's preparation for running the command:
+	// user lookup, locks, cooldowns, worker creation, and configuration.
+	StageSchedule RequestStage = "schedule"
+
+	// StageStart is starting the worker (pulling and starting the container,
+	// for example).
+	StageStart RequestStage = "start"
+
+	// StageExecution is the time from the worker starting until it exits.
+	StageExecution RequestStage = "execution"
+
+	// StageRender is transforming the command output with its template.
+	StageRender RequestStage = "render"
+
+	// StageSend is delivering the rendered output to the chat provider.
+	StageSend RequestStage = "send"
+)
+
+// RequestStages lists every request stage, in the order they occur.
+var RequestStages = []RequestStage{
+	StageLookup,
+	StageAuth,
+	StageSchedule,
+	StageStart,
+	StageExecution,
+	StageRender,
+	StageSend,
+}
+
+// ParseRequestStage returns the RequestStage named by s, or an error if
+// there's no such stage.
+func ParseRequestStage(s string) (RequestStage, error) {
+	for _, stage := range RequestStages {
+		if string(stage) == s {
+			return stage, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown request stage %q", s)
+}
+
+// StageTimings records how long each stage of a request took.
+type StageTimings map[RequestStage]time.Duration
+
+// Record sets the duration of a stage. It does nothing if t is nil.
+func (t StageTimings) Record(stage RequestStage, d time.Duration) {
+	if t != nil {
+		t[stage] = d
+	}
+}
+
+// OverBudget returns the stages, in order, whose duration exceeded their
+// budget. Stages without a budget are never over budget.
+func (t StageTimings) OverBudget(budgets map[RequestStage]time.Duration) []RequestStage {
+	var over []RequestStage
+
+	for _, stage := range RequestStages {
+		budget, ok := budgets[stage]
+		if !ok || budget <= 0 {
+			continue
+		}
+
+		if d, ok := t[stage]; ok && d > budget {
+			over = append(over, stage)
+		}
+	}
+
+	return over
+}
+
+// RequestRecord is the stored record of a single command request, as
+// reported by the data access layer.
+type RequestRecord struct {
+	RequestID     int64         `json:"request_id"`
+	Timestamp     time.Time     `json:"timestamp"`
+	Duration      time.Duration `json:"duration"`
+	Adapter       string        `json:"adapter"`
+	ChannelID     string        `json:"channel_id"`
+	UserID        string        `json:"user_id"`
+	UserEmail     string        `json:"user_email"`
+	UserName      string        `json:"user_name"`
+	BundleName    string        `json:"bundle_name"`
+	BundleVersion string        `json:"bundle_version"`
+	CommandName   string        `json:"command_name"`
+	Parameters    string        `json:"parameters"`
+
+	// ReplayOf is the ID of the request that this one replays, or zero if
+	// it isn't a replay.
+	ReplayOf int64 `json:"replay_of,omitempty"`
+
+	// Closed is true once the request has completed, successfully or not.
+	// ExitCode and Error are meaningful only if it's set.
+	Closed   bool   `json:"closed"`
+	ExitCode int16  `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+
+	Timings StageTimings `json:"timings,omitempty"`
+
+	// Payload is the archived response, if there is one. It's only included
+	// when a single request is retrieved.
+	Payload *RequestPayload `json:"payload,omitempty"`
+
+	// Logs are the log entries that the command attached to the request, in
+	// the order they were received. They're only included when a single
+	// request is retrieved.
+	Logs []RequestLogEntry `json:"logs,omitempty"`
+}
+
+// The levels of a RequestLogEntry.
+const (
+	RequestLogDebug = "debug"
+	RequestLogInfo  = "info"
+	RequestLogWarn  = "warn"
+	RequestLogError = "error"
+)
+
+// MaxRequestLogMessageSize is the largest message, in bytes, that a
+// RequestLogEntry may have.
+const MaxRequestLogMessageSize = 4096
+
+// RequestLogEntry is a structured log line that a running command attached
+// to its request through the worker API.
+type RequestLogEntry struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Level     string            `json:"level"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// Validate returns an error if the entry has no message or one that's
+// larger than MaxRequestLogMessageSize, or if its level isn't one of debug,
+// info, warn, or error.
+func (e RequestLogEntry) Validate() error {
+	switch e.Level {
+	case RequestLogDebug, RequestLogInfo, RequestLogWarn, RequestLogError:
+	default:
+		return fmt.Errorf("log level must be one of: debug, info, warn, error")
+	}
+
+	switch {
+	case e.Message == "":
+		return fmt.Errorf("log message is empty")
+	case len(e.Message) > MaxRequestLogMessageSize:
+		return fmt.Errorf("log message is larger than %d bytes", MaxRequestLogMessageSize)
+	}
+
+	return nil
+}
+
+// RequestPayload is the archived response to a request: what the command
+// produced, and what Gort actually sent to the chat provider.
+type RequestPayload struct {
+	ArchivedAt time.Time `json:"archived_at"`
+	Title      string    `json:"title,omitempty"`
+	ErrorCode  string    `json:"error_code,omitempty"`
+	Partial    bool      `json:"partial,omitempty"`
+	Output     []string  `json:"output,omitempty"`
+	Rendered   string    `json:"rendered,omitempty"`
+
+	// Truncated is true if Output or Rendered was cut short to fit the
+	// archive's size cap.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Truncate shortens Output and Rendered so that each is no larger than max
+// bytes, setting Truncated if anything was removed. Output is truncated on
+// line boundaries.
+func (p *RequestPayload) Truncate(max int) {
+	size := 0
+	for i, line := range p.Output {
+		size += len(line) + 1
+		if size > max {
+			p.Output = p.Output[:i]
+			p.Truncated = true
+			break
+		}
+	}
+
+	if len(p.Rendered) > max {
+		p.Rendered = p.Rendered[:max]
+		p.Truncated = true
+	}
+}
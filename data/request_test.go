@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequestStage(t *testing.T) {
+	stage, err := ParseRequestStage("execution")
+	assert.NoError(t, err)
+	assert.Equal(t, StageExecution, stage)
+
+	_, err = ParseRequestStage("nope")
+	assert.Error(t, err)
+}
+
+func TestStageTimingsOverBudget(t *testing.T) {
+	timings := StageTimings{
+		StageLookup:    10 * time.Millisecond,
+		StageStart:     5 * time.Second,
+		StageExecution: 2 * time.Second,
+		StageSend:      3 * time.Second,
+	}
+
+	budgets := map[RequestStage]time.Duration{
+		StageLookup:    100 * time.Millisecond,
+		StageSend:      time.Second,
+		StageStart:     time.Second,
+		StageExecution: 0, // no budget
+		StageRender:    time.Millisecond,
+	}
+
+	assert.Equal(t, []RequestStage{StageStart, StageSend}, timings.OverBudget(budgets))
+	assert.Empty(t, timings.OverBudget(nil))
+}
+
+func TestStageTimingsRecordNil(t *testing.T) {
+	var timings StageTimings
+	timings.Record(StageLookup, time.Second)
+	assert.Nil(t, timings)
+}
+
+func TestRequestPayloadTruncate(t *testing.T) {
+	p := RequestPayload{
+		Output:   []string{"aaaa", "bbbb", "cccc"},
+		Rendered: "aaaa\nbbbb\ncccc",
+	}
+
+	p.Truncate(100)
+	assert.False(t, p.Truncated)
+	assert.Len(t, p.Output, 3)
+
+	p.Truncate(10)
+	assert.True(t, p.Truncated)
+	assert.Equal(t, []string{"aaaa", "bbbb"}, p.Output)
+	assert.Equal(t, "aaaa\nbbbb\n", p.Rendered)
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+// Announcement is a message to be broadcast by Gort to one or more chat
+// channels.
+type Announcement struct {
+	// Message is the text to send.
+	Message string `json:"message"`
+
+	// AllChannels sends the message to every channel that Gort is present in.
+	// If it's false, Channels must be non-empty.
+	AllChannels bool `json:"all_channels,omitempty"`
+
+	// Adapters limits the announcement to the named adapters. If empty, all
+	// adapters are used.
+	Adapters []string `json:"adapters,omitempty"`
+
+	// Channels limits the announcement to the channels with these IDs or
+	// names.
+	Channels []string `json:"channels,omitempty"`
+}
+
+// AnnouncementTarget describes a single channel that an announcement was
+// (or failed to be) sent to.
+type AnnouncementTarget struct {
+	Adapter     string `json:"adapter"`
+	ChannelID   string `json:"channel_id"`
+	ChannelName string `json:"channel_name,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// AnnouncementResult is returned after an announcement is sent.
+type AnnouncementResult struct {
+	Sent   []AnnouncementTarget `json:"sent"`
+	Failed []AnnouncementTarget `json:"failed,omitempty"`
+}
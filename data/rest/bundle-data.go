@@ -0,0 +1,112 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+// BundleUpgradeResult reports the outcome of a bundle upgrade.
+type BundleUpgradeResult struct {
+	// Name and Version identify the bundle version that was upgraded to.
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// Installed is false if the requested version was already installed,
+	// in which case the upgrade didn't install anything.
+	Installed bool `json:"installed"`
+
+	// Enabled is true if the upgraded version is enabled after the upgrade.
+	Enabled bool `json:"enabled"`
+
+	// PreviousEnabled is the version that was enabled before the upgrade,
+	// if any.
+	PreviousEnabled string `json:"previous_enabled,omitempty"`
+
+	// Pruned lists the older versions that were uninstalled because they
+	// exceeded the retention count.
+	Pruned []string `json:"pruned,omitempty"`
+
+	// Warnings lists the risks found by analyzing the upgraded version.
+	Warnings []BundleWarning `json:"warnings,omitempty"`
+}
+
+// BundleInstallResult reports the outcome of a bundle install.
+type BundleInstallResult struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// Warnings lists the risks found by analyzing the installed bundle.
+	Warnings []BundleWarning `json:"warnings,omitempty"`
+}
+
+// BundleEnableResult reports the outcome of enabling a bundle version.
+type BundleEnableResult struct {
+	// Warnings lists the problems found with the enabled version, such as
+	// triggers that overlap those of other enabled bundles.
+	Warnings []BundleWarning `json:"warnings,omitempty"`
+}
+
+// BundleWarning describes a risky characteristic of a bundle that was found
+// when it was installed or enabled.
+type BundleWarning struct {
+	// Severity is one of "info", "warning", or "critical".
+	Severity string `json:"severity"`
+
+	// Code is a short, stable identifier for the kind of risk, such as
+	// "allow-all-rule".
+	Code string `json:"code"`
+
+	// Command is the name of the command that the warning applies to. It's
+	// empty for warnings about the bundle as a whole.
+	Command string `json:"command,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// BundleReviewDecision is the body of a request to approve or reject a
+// bundle version that's pending review.
+type BundleReviewDecision struct {
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// BundleReviewDiff describes a bundle version for review: its review state,
+// and how it differs from the currently enabled version of the bundle.
+type BundleReviewDiff struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// BaseVersion is the enabled version that the diff is against. It's
+	// empty if no version is enabled, in which case every line is added.
+	BaseVersion string `json:"base_version,omitempty"`
+
+	Status      string `json:"status"`
+	InstalledBy string `json:"installed_by,omitempty"`
+	Reviewer    string `json:"reviewer,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+
+	// Diff is a line diff of the two versions' YAML. Each line is prefixed
+	// with "-" if it's only in the base version, "+" if it's only in the
+	// reviewed version, or " " if it's in both.
+	Diff []string `json:"diff"`
+}
+
+// OrphanedPermission is a permission granted to a role that isn't declared
+// by any installed version of its bundle, typically because the bundle (or
+// the version that declared it) was uninstalled.
+type OrphanedPermission struct {
+	Role       string `json:"role"`
+	BundleName string `json:"bundle_name"`
+	Permission string `json:"permission"`
+}
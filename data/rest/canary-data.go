@@ -0,0 +1,38 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"time"
+)
+
+// CanaryStatus reports the recent results of a single canary: a command
+// that's run through the command pipeline on a schedule.
+type CanaryStatus struct {
+	Name                string        `json:"name"`
+	Command             string        `json:"command"`
+	LastRun             time.Time     `json:"last_run,omitempty"`
+	LastDuration        time.Duration `json:"last_duration"`
+	LastError           string        `json:"last_error,omitempty"`
+	Successes           int           `json:"successes"`
+	Failures            int           `json:"failures"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+
+	// Alerting is true if the canary has failed enough times in a row to
+	// raise an alert, and hasn't succeeded since.
+	Alerting bool `json:"alerting"`
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import "time"
+
+// Channel describes a chat channel that one of Gort's adapters is present in.
+type Channel struct {
+	Adapter string `json:"adapter"`
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Members int    `json:"members"`
+
+	// LastActivity is the time the last message was seen in the channel
+	// since this controller started. It's nil if none has been seen.
+	LastActivity *time.Time `json:"last_activity,omitempty"`
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import "time"
+
+// ClusterStatus describes the state of the Gort controller cluster.
+type ClusterStatus struct {
+	// Leader is the name of the current leader node.
+	Leader string `json:"leader,omitempty"`
+
+	// Members contains one entry for each controller in the cluster.
+	Members []ClusterMember `json:"members,omitempty"`
+}
+
+// ClusterMember describes a single Gort controller instance.
+type ClusterMember struct {
+	Name      string    `json:"name,omitempty"`
+	Leader    bool      `json:"leader"`
+	Adapters  []string  `json:"adapters,omitempty"`
+	InFlight  int64     `json:"in_flight"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	Version   string    `json:"version,omitempty"`
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+// The kinds of problem that can be reported by the consistency checker.
+const (
+	DoctorProblemPermission     = "permission"
+	DoctorProblemMapping        = "mapping"
+	DoctorProblemEnabledVersion = "enabled_version"
+)
+
+// DoctorProblem describes a dangling reference found by the consistency
+// checker. Fixed is true if it was repaired.
+type DoctorProblem struct {
+	Kind        string `json:"kind"`
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
+	Fixed       bool   `json:"fixed,omitempty"`
+}
+
+// DoctorReport is returned by the consistency checker.
+type DoctorReport struct {
+	Problems []DoctorProblem `json:"problems"`
+}
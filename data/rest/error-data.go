@@ -0,0 +1,37 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+// Error is the body of every REST API error response. Code and Title are
+// set if the error has a registered error code (like "GORT-4001"), which can
+// be described in detail with "GET /v2/errors/{code}".
+type Error struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+// String returns the error's message, prefixed by its code if it has one
+// (e.g., "[GORT-4001] unauthorized").
+func (e Error) String() string {
+	if e.Code == "" {
+		return e.Message
+	}
+
+	return "[" + e.Code + "] " + e.Message
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"time"
+)
+
+// ExecRequest describes a command to execute on behalf of the requesting
+// user, exactly as it would be typed in chat (less any leading "!"), like
+// "bundle:command --option arg".
+type ExecRequest struct {
+	Command string `json:"command"`
+}
+
+// ExecResult is returned after an executed command completes. Its output is
+// returned to the caller rather than sent to a chat channel.
+type ExecResult struct {
+	RequestID     int64         `json:"request_id"`
+	Bundle        string        `json:"bundle"`
+	BundleVersion string        `json:"bundle_version"`
+	Command       string        `json:"command"`
+	ExitCode      int16         `json:"exit_code"`
+	Error         string        `json:"error,omitempty"`
+	Output        []string      `json:"output,omitempty"`
+	Duration      time.Duration `json:"duration"`
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+// DirectMessage is a message to be sent directly to a Gort user, through
+// each of the chat adapters that the user is mapped to.
+type DirectMessage struct {
+	// Message is the text to send.
+	Message string `json:"message"`
+
+	// Adapter limits the message to the named adapter. If empty, it's sent
+	// through every adapter that the user is mapped to.
+	Adapter string `json:"adapter,omitempty"`
+}
+
+// DirectMessageTarget describes a single chat user that a direct message was
+// (or failed to be) sent to.
+type DirectMessageTarget struct {
+	Adapter   string `json:"adapter"`
+	UserID    string `json:"user_id"`
+	ChannelID string `json:"channel_id,omitempty"`
+
+	// Presence is the user's presence ("active" or "away") when the message
+	// was sent, if the chat provider reports it.
+	Presence string `json:"presence,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// DirectMessageResult is returned after a direct message is sent.
+type DirectMessageResult struct {
+	Sent   []DirectMessageTarget `json:"sent"`
+	Failed []DirectMessageTarget `json:"failed,omitempty"`
+}
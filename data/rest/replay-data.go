@@ -0,0 +1,40 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"time"
+)
+
+// ReplayRequest describes how a historical request should be replayed. If
+// BundleVersion is empty, the request is replayed against the same bundle
+// version that originally handled it.
+type ReplayRequest struct {
+	BundleVersion string `json:"bundle_version,omitempty"`
+}
+
+// ReplayResult is returned after a replayed request completes. Its output
+// is returned to the caller rather than sent to the original channel.
+type ReplayResult struct {
+	RequestID     int64         `json:"request_id"`
+	ReplayOf      int64         `json:"replay_of"`
+	BundleVersion string        `json:"bundle_version"`
+	ExitCode      int16         `json:"exit_code"`
+	Error         string        `json:"error,omitempty"`
+	Output        []string      `json:"output,omitempty"`
+	Duration      time.Duration `json:"duration"`
+}
@@ -44,3 +44,51 @@ func (l RolePermissionList) Strings() []string {
 
 	return s
 }
+
+// RoleDiff describes the differences between the permissions granted to two
+// roles.
+type RoleDiff struct {
+	// A and B are the names of the roles being compared.
+	A string
+	B string
+
+	// OnlyA and OnlyB contain the permissions granted to only one role.
+	OnlyA RolePermissionList
+	OnlyB RolePermissionList
+
+	// Common contains the permissions granted to both roles.
+	Common RolePermissionList
+}
+
+// DiffRolePermissions compares two permission lists, returning the
+// permissions found only in a, only in b, and in both. Each result is in the
+// same order as its source list.
+func DiffRolePermissions(a, b RolePermissionList) (onlyA, onlyB, common RolePermissionList) {
+	inA := map[RolePermission]bool{}
+	for _, p := range a {
+		inA[p] = true
+	}
+
+	inB := map[RolePermission]bool{}
+	for _, p := range b {
+		inB[p] = true
+	}
+
+	onlyA, onlyB, common = RolePermissionList{}, RolePermissionList{}, RolePermissionList{}
+
+	for _, p := range a {
+		if inB[p] {
+			common = append(common, p)
+		} else {
+			onlyA = append(onlyA, p)
+		}
+	}
+
+	for _, p := range b {
+		if !inA[p] {
+			onlyB = append(onlyB, p)
+		}
+	}
+
+	return onlyA, onlyB, common
+}
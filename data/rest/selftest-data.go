@@ -0,0 +1,43 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import "time"
+
+// SelfTestRequest describes a self-test to run. If Adapter and Channel are
+// set, the test's output is delivered to that channel; otherwise the chat
+// provider isn't contacted.
+type SelfTestRequest struct {
+	Adapter string `json:"adapter,omitempty"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// SelfTestStage describes the outcome of a single stage of a self-test.
+type SelfTestStage struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// SelfTestResult is returned after a self-test completes. Success is true
+// only if every stage succeeded.
+type SelfTestResult struct {
+	Success bool            `json:"success"`
+	Output  []string        `json:"output,omitempty"`
+	Stages  []SelfTestStage `json:"stages"`
+	Total   time.Duration   `json:"total"`
+}
@@ -16,6 +16,8 @@
 
 package rest
 
+import "time"
+
 // User is a data struct used to exchange data between a Gort client and a
 // Gort controller REST service.
 type User struct {
@@ -28,4 +30,47 @@ type User struct {
 	// The key is the adapter name as defined in the config; the value is the
 	// associated ID in the service the adapter connects to.
 	Mappings map[string]string `json:"mappings,omitempty"`
+
+	// ServiceAccount is true if the user is a service account, used by
+	// automation like schedulers, webhooks, and CI rather than by a person.
+	// Service accounts have no password and no chat mappings; they
+	// authenticate only with tokens issued by "POST /v2/users/{username}/token".
+	// It's set when the user is created, and can't be changed.
+	ServiceAccount bool `json:"service_account,omitempty"`
+}
+
+// UserDataExport contains all of the personal data that Gort stores about a
+// user. It's intended to be retrieved (and kept, if necessary) before the
+// user is purged.
+type UserDataExport struct {
+	User     User                `json:"user"`
+	Groups   []string            `json:"groups,omitempty"`
+	Requests []UserRequestRecord `json:"requests,omitempty"`
+	Exported time.Time           `json:"exported"`
+}
+
+// UserRequestRecord is the stored record of a single command request made
+// by a user.
+type UserRequestRecord struct {
+	RequestID  int64     `json:"request_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Adapter    string    `json:"adapter"`
+	ChannelID  string    `json:"channel_id"`
+	UserID     string    `json:"user_id"`
+	UserEmail  string    `json:"user_email,omitempty"`
+	Command    string    `json:"command"`
+	Parameters string    `json:"parameters,omitempty"`
+}
+
+// UserPurgeResult reports the outcome of a user purge.
+type UserPurgeResult struct {
+	Username string `json:"username"`
+
+	// Pseudonym is the name that replaces the username in the purged user's
+	// request records, so that aggregate statistics remain meaningful.
+	Pseudonym string `json:"pseudonym"`
+
+	// RequestsAnonymized is the number of request records that were
+	// stripped of the user's personal data.
+	RequestsAnonymized int64 `json:"requests_anonymized"`
 }
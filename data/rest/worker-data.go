@@ -0,0 +1,53 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import "time"
+
+// WorkerToken describes the worker token that a request to the worker API
+// was made with.
+type WorkerToken struct {
+	RequestID int64     `json:"request_id"`
+	User      string    `json:"user"`
+	Bundle    string    `json:"bundle"`
+	Command   string    `json:"command"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// WorkerRequest describes the request that a command is running for, as
+// reported to the command by the worker API.
+type WorkerRequest struct {
+	RequestID     int64     `json:"request_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Adapter       string    `json:"adapter"`
+	ChannelID     string    `json:"channel_id"`
+	User          string    `json:"user"`
+	UserID        string    `json:"user_id"`
+	UserEmail     string    `json:"user_email,omitempty"`
+	Bundle        string    `json:"bundle"`
+	BundleVersion string    `json:"bundle_version"`
+	Command       string    `json:"command"`
+	Parameters    string    `json:"parameters"`
+}
+
+// WorkerResponse is an interim message that a running command sends to the
+// channel that it was invoked from.
+type WorkerResponse struct {
+	Message string `json:"message"`
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// AutoRollbackUser is recorded as the user in the audit records of
+	// automatic rollbacks.
+	AutoRollbackUser = "gort:auto-rollback"
+
+	// DefaultAutoRollbackInterval is how often recently enabled bundle
+	// versions are checked when global.auto_rollback.interval isn't set.
+	DefaultAutoRollbackInterval = time.Minute
+
+	// DefaultAutoRollbackMinRequests is the number of requests a version must
+	// have handled before it can be rolled back when
+	// global.auto_rollback.min_requests isn't set.
+	DefaultAutoRollbackMinRequests = 5
+
+	// DefaultAutoRollbackThreshold is the failure rate above which a version
+	// is rolled back when global.auto_rollback.threshold isn't set.
+	DefaultAutoRollbackThreshold = 0.5
+
+	// DefaultAutoRollbackWindow is how long after being enabled a version is
+	// watched when global.auto_rollback.window isn't set.
+	DefaultAutoRollbackWindow = 30 * time.Minute
+)
+
+// AutoRollbackConfigs is the data wrapper for the "global.auto_rollback"
+// section. If it's enabled, a newly enabled bundle version whose failure
+// rate exceeds Threshold within Window of being enabled is replaced by the
+// version that was enabled before it.
+type AutoRollbackConfigs struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Interval is how often recently enabled versions are checked.
+	Interval time.Duration `yaml:"interval,omitempty"`
+
+	// Window is how long after being enabled a version is watched.
+	Window time.Duration `yaml:"window,omitempty"`
+
+	// Threshold is the fraction of requests, between 0 and 1, that must fail
+	// for a version to be rolled back.
+	Threshold float64 `yaml:"threshold,omitempty"`
+
+	// MinRequests is the number of requests that a version must have
+	// handled before its failure rate is considered.
+	MinRequests int `yaml:"min_requests,omitempty"`
+
+	// NotifyAdapter and NotifyChannel identify a channel to which rollback
+	// notices are sent. If they're empty, each member of the admin group is
+	// sent a direct message instead.
+	NotifyAdapter string `yaml:"notify_adapter,omitempty"`
+	NotifyChannel string `yaml:"notify_channel,omitempty"`
+}
+
+// Validate returns an error if any duration or count is negative, if the
+// threshold isn't between 0 and 1, or if only one of NotifyAdapter and
+// NotifyChannel is set.
+func (c AutoRollbackConfigs) Validate() error {
+	switch {
+	case c.Interval < 0:
+		return fmt.Errorf("interval must not be negative")
+	case c.Window < 0:
+		return fmt.Errorf("window must not be negative")
+	case c.MinRequests < 0:
+		return fmt.Errorf("min_requests must not be negative")
+	case c.Threshold < 0 || c.Threshold > 1:
+		return fmt.Errorf("threshold must be between 0 and 1")
+	case (c.NotifyAdapter == "") != (c.NotifyChannel == ""):
+		return fmt.Errorf("notify_adapter and notify_channel must be set together")
+	}
+
+	return nil
+}
+
+// IntervalOrDefault returns Interval, or DefaultAutoRollbackInterval if it's
+// unset.
+func (c AutoRollbackConfigs) IntervalOrDefault() time.Duration {
+	if c.Interval == 0 {
+		return DefaultAutoRollbackInterval
+	}
+	return c.Interval
+}
+
+// MinRequestsOrDefault returns MinRequests, or
+// DefaultAutoRollbackMinRequests if it's unset.
+func (c AutoRollbackConfigs) MinRequestsOrDefault() int {
+	if c.MinRequests == 0 {
+		return DefaultAutoRollbackMinRequests
+	}
+	return c.MinRequests
+}
+
+// ThresholdOrDefault returns Threshold, or DefaultAutoRollbackThreshold if
+// it's unset.
+func (c AutoRollbackConfigs) ThresholdOrDefault() float64 {
+	if c.Threshold == 0 {
+		return DefaultAutoRollbackThreshold
+	}
+	return c.Threshold
+}
+
+// WindowOrDefault returns Window, or DefaultAutoRollbackWindow if it's unset.
+func (c AutoRollbackConfigs) WindowOrDefault() time.Duration {
+	if c.Window == 0 {
+		return DefaultAutoRollbackWindow
+	}
+	return c.Window
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoRollbackConfigs(t *testing.T) {
+	var c AutoRollbackConfigs
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, DefaultAutoRollbackInterval, c.IntervalOrDefault())
+	assert.Equal(t, DefaultAutoRollbackMinRequests, c.MinRequestsOrDefault())
+	assert.Equal(t, DefaultAutoRollbackThreshold, c.ThresholdOrDefault())
+	assert.Equal(t, DefaultAutoRollbackWindow, c.WindowOrDefault())
+
+	c = AutoRollbackConfigs{Threshold: 0.2, Window: time.Hour}
+	assert.Equal(t, 0.2, c.ThresholdOrDefault())
+	assert.Equal(t, time.Hour, c.WindowOrDefault())
+
+	assert.Error(t, AutoRollbackConfigs{Threshold: 1.5}.Validate())
+	assert.Error(t, AutoRollbackConfigs{MinRequests: -1}.Validate())
+	assert.Error(t, AutoRollbackConfigs{NotifyChannel: "C01"}.Validate())
+}
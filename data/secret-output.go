@@ -0,0 +1,63 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// DefaultSecretOutputTTL is how long a secret output link remains valid
+// when global.secret_output_ttl isn't set.
+const DefaultSecretOutputTTL = 15 * time.Minute
+
+// SecretOutput is the output of a command marked secret_output. Rather than
+// being posted to the channel, it's held until the requester views it once
+// through the link that Gort sends them by direct message.
+type SecretOutput struct {
+	// TokenHash is the SHA-256 hash of the token that protects the output.
+	// The token itself is only ever sent to the requester.
+	TokenHash string `json:"-"`
+
+	// RequestID is the ID of the command request that produced the output.
+	RequestID int64 `json:"request_id,omitempty"`
+
+	// Username is the Gort user who made the request.
+	Username string `json:"username,omitempty"`
+
+	// Title and Output are the command's response.
+	Title  string   `json:"title,omitempty"`
+	Output []string `json:"output"`
+
+	// Created is when the output was stored, and Expires is when it can no
+	// longer be viewed.
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+// Expired returns true if the output can no longer be viewed.
+func (s SecretOutput) Expired() bool {
+	return !time.Now().Before(s.Expires)
+}
+
+// HashSecretToken returns the hash by which a secret output's token is
+// stored and looked up.
+func HashSecretToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
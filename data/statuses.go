@@ -0,0 +1,42 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// The HTTP statuses that the data package's errors are reported with by
+// the REST API.
+func init() {
+	// Malformed alias or macro name, command, or arguments
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusBadRequest, Level: log.InfoLevel},
+		ErrBadAlias,
+		ErrBadMacro,
+	)
+
+	// Unsupported configuration layers, which are described by
+	// ValidateMacroLayer and friends.
+	invalidLayer := gerrs.Status{HTTPStatus: http.StatusExpectationFailed, Level: log.InfoLevel}
+	gerrs.RegisterStatusPrefix("dynamic configuration layers must be one of:", invalidLayer)
+	gerrs.RegisterStatusPrefix("macro layers must be one of:", invalidLayer)
+	gerrs.RegisterStatusPrefix("option default layers must be one of:", invalidLayer)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxTelemetryAttributes is the largest number of telemetry attributes that a
+// bundle may declare. Every attribute becomes a metric label, so the limit
+// keeps metric cardinality in check.
+const MaxTelemetryAttributes = 8
+
+// telemetryAttributeKey matches valid telemetry attribute keys: lowercase,
+// dot-separated words, like "team" or "cost.center".
+var telemetryAttributeKey = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z0-9_]+)*$`)
+
+// reservedTelemetryPrefixes are the attribute namespaces used by Gort itself,
+// which bundles may not declare attributes in.
+var reservedTelemetryPrefixes = []string{"adapter.", "bundle.", "command.", "gort.", "request.", "user."}
+
+// TelemetryAttributes are static attributes, like "team: payments", that a
+// bundle adds to the spans and metrics of its command executions. They
+// allow usage to be attributed to the team that owns the bundle.
+type TelemetryAttributes map[string]string
+
+// Keys returns the attribute keys in sorted order.
+func (t TelemetryAttributes) Keys() []string {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Validate returns an error if there are too many attributes, or if any key
+// is malformed or in a namespace reserved by Gort.
+func (t TelemetryAttributes) Validate() error {
+	if len(t) > MaxTelemetryAttributes {
+		return fmt.Errorf("too many attributes: %d (maximum %d)", len(t), MaxTelemetryAttributes)
+	}
+
+	for _, k := range t.Keys() {
+		if !telemetryAttributeKey.MatchString(k) {
+			return fmt.Errorf("invalid attribute key %q", k)
+		}
+
+		for _, p := range reservedTelemetryPrefixes {
+			if strings.HasPrefix(k, p) {
+				return fmt.Errorf("invalid attribute key %q: the %q namespace is reserved", k, strings.TrimSuffix(p, "."))
+			}
+		}
+	}
+
+	return nil
+}
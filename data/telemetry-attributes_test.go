@@ -0,0 +1,50 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelemetryAttributesValidate(t *testing.T) {
+	tests := []struct {
+		Attributes TelemetryAttributes
+		Valid      bool
+	}{
+		{nil, true},
+		{TelemetryAttributes{"team": "payments", "tier": "1"}, true},
+		{TelemetryAttributes{"cost.center": "1234"}, true},
+		{TelemetryAttributes{"Team": "payments"}, false},
+		{TelemetryAttributes{"team-name": "payments"}, false},
+		{TelemetryAttributes{"cost.": "1234"}, false},
+		{TelemetryAttributes{"request.owner": "payments"}, false},
+		{TelemetryAttributes{"gort.team": "payments"}, false},
+		{TelemetryAttributes{"a": "", "b": "", "c": "", "d": "", "e": "", "f": "", "g": "", "h": "", "i": ""}, false},
+	}
+
+	for _, test := range tests {
+		err := test.Attributes.Validate()
+		assert.Equal(t, test.Valid, err == nil, test.Attributes)
+	}
+}
+
+func TestTelemetryAttributesKeys(t *testing.T) {
+	a := TelemetryAttributes{"tier": "1", "team": "payments", "cost.center": "1234"}
+	assert.Equal(t, []string{"cost.center", "team", "tier"}, a.Keys())
+}
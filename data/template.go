@@ -31,6 +31,14 @@ const (
 	// by commands that return with a non-zero status.
 	CommandError TemplateType = "command_error"
 
+	// CommandTimeout templates are used to format the error messages
+	// produced when commands don't complete within their timeout.
+	CommandTimeout TemplateType = "command_timeout"
+
+	// Digest templates are used to format digests: single messages that
+	// summarize many runs of scheduled commands.
+	Digest TemplateType = "digest"
+
 	// Message templates are used to format standard informative (non-error)
 	// messages from the Gort system (not commands).
 	Message TemplateType = "message"
@@ -52,6 +60,14 @@ type Templates struct {
 	// by commands that return with a non-zero status.
 	CommandError string `yaml:"command_error,omitempty" json:"command_error,omitempty"`
 
+	// CommandTimeout templates are used to format the error messages
+	// produced when commands don't complete within their timeout.
+	CommandTimeout string `yaml:"command_timeout,omitempty" json:"command_timeout,omitempty"`
+
+	// Digest templates are used to format digests: single messages that
+	// summarize many runs of scheduled commands.
+	Digest string `yaml:"digest,omitempty" json:"digest,omitempty"`
+
 	// Message templates are used to format standard informative (non-error)
 	// messages from the Gort system (not commands).
 	Message string `yaml:"message,omitempty" json:"message,omitempty"`
@@ -69,6 +85,10 @@ func (t Templates) Get(tt TemplateType) (string, error) {
 		return t.Command, nil
 	case CommandError:
 		return t.CommandError, nil
+	case CommandTimeout:
+		return t.CommandTimeout, nil
+	case Digest:
+		return t.Digest, nil
 	case Message:
 		return t.Message, nil
 	case MessageError:
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import "fmt"
+
+// Trigger precedence strategies, which decide between commands in different
+// bundles whose triggers match the same message.
+const (
+	// TriggerPrecedencePriority prefers the command from the bundle with the
+	// highest priority in global.triggers.bundle_priority.
+	TriggerPrecedencePriority = "priority"
+
+	// TriggerPrecedenceLongestMatch prefers the command whose trigger
+	// matches the longest part of the message.
+	TriggerPrecedenceLongestMatch = "longest_match"
+)
+
+// TriggerConfigs is the data wrapper for the "global.triggers" section.
+type TriggerConfigs struct {
+	// Precedence lists the strategies used, in order, to choose between
+	// commands whose triggers match the same message. If the candidates
+	// remain tied after every strategy is applied, or if no strategies are
+	// set, the message is ambiguous and no command is run.
+	Precedence []string `yaml:"precedence,omitempty"`
+
+	// BundlePriority maps bundle names to their priority for the "priority"
+	// strategy. Higher values take precedence; unlisted bundles are 0.
+	BundlePriority map[string]int `yaml:"bundle_priority,omitempty"`
+}
+
+// Validate returns an error if any of the precedence strategies is unknown.
+func (c TriggerConfigs) Validate() error {
+	for _, s := range c.Precedence {
+		switch s {
+		case TriggerPrecedencePriority, TriggerPrecedenceLongestMatch:
+		default:
+			return fmt.Errorf("unknown precedence strategy: %q", s)
+		}
+	}
+	return nil
+}
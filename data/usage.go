@@ -0,0 +1,203 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The reserved options. Unless a command opts out with native_help, or
+// declares an option of the same name, Gort handles these itself rather than
+// running the command.
+const (
+	// HelpOption and HelpShortOption ask for a description of the command.
+	HelpOption      = "help"
+	HelpShortOption = "h"
+
+	// VersionOption asks for the version of the command's bundle.
+	VersionOption = "version"
+)
+
+// ErrUnknownOption is returned by CheckParameters when a command is passed
+// an option that it doesn't declare.
+var ErrUnknownOption = errors.New("unknown option")
+
+// HasUsage returns true if the command declares a usage string. Only such
+// commands have their parameters checked by Gort.
+func (c BundleCommand) HasUsage() bool {
+	return c.Usage != ""
+}
+
+// HelpRequested returns true if params includes --help or -h, and Gort
+// should handle it on the command's behalf.
+func (c BundleCommand) HelpRequested(params []string) bool {
+	return c.reservedOptionRequested(params, HelpOption) ||
+		c.reservedOptionRequested(params, HelpShortOption)
+}
+
+// VersionRequested returns true if params includes --version, and Gort
+// should handle it on the command's behalf.
+func (c BundleCommand) VersionRequested(params []string) bool {
+	return c.reservedOptionRequested(params, VersionOption)
+}
+
+func (c BundleCommand) reservedOptionRequested(params []string, option string) bool {
+	if c.NativeHelp {
+		return false
+	}
+
+	if _, ok := c.Options[option]; ok {
+		return false
+	}
+
+	for _, name := range optionNames(params) {
+		if name == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckParameters validates params against the command's declared options.
+// Commands without a usage string, or that don't declare any options, accept
+// anything. Otherwise, an option that isn't declared results in an error
+// wrapping ErrUnknownOption.
+func (c BundleCommand) CheckParameters(params []string) error {
+	if !c.HasUsage() || len(c.Options) == 0 {
+		return nil
+	}
+
+	for _, name := range optionNames(params) {
+		if _, ok := c.Options[name]; !ok && !c.isReservedOption(name) {
+			return fmt.Errorf("%w: %s", ErrUnknownOption, name)
+		}
+	}
+
+	return nil
+}
+
+func (c BundleCommand) isReservedOption(name string) bool {
+	switch name {
+	case HelpOption, HelpShortOption, VersionOption:
+		return !c.NativeHelp
+	default:
+		return false
+	}
+}
+
+// UsageLines returns a plain-text description of how to use the command,
+// built from its usage string, description, options, and examples. The long
+// description is preferred to the short one; since long descriptions
+// conventionally include a usage section of their own, the synopsis line is
+// omitted for commands that have one but no usage string.
+func (e CommandEntry) UsageLines() []string {
+	c := e.Command
+
+	var lines []string
+
+	if c.Usage != "" || c.LongDescription == "" {
+		synopsis := e.Bundle.Name + ":" + c.Name
+		if c.Usage != "" {
+			synopsis += " " + c.Usage
+		}
+		lines = append(lines, "Usage: "+synopsis)
+	}
+
+	description := c.LongDescription
+	if description == "" {
+		description = c.Description
+	}
+	if description != "" {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, strings.Split(description, "\n")...)
+	}
+
+	if len(c.Options) > 0 {
+		names := make([]string, 0, len(c.Options))
+		width := 0
+		for name := range c.Options {
+			names = append(names, name)
+			if len(optionFlag(name)) > width {
+				width = len(optionFlag(name))
+			}
+		}
+		sort.Strings(names)
+
+		lines = append(lines, "", "Options:")
+		for _, name := range names {
+			var description string
+			if o := c.Options[name]; o != nil {
+				description = o.Description
+			}
+			line := fmt.Sprintf("  %-*s  %s", width, optionFlag(name), description)
+			lines = append(lines, strings.TrimRight(line, " "))
+		}
+	}
+
+	if len(c.Examples) > 0 {
+		lines = append(lines, "", "Examples:")
+		for _, ex := range c.Examples {
+			lines = append(lines, "  "+ex)
+		}
+	}
+
+	return lines
+}
+
+// VersionLines returns a plain-text description of the version of the
+// command's bundle.
+func (e CommandEntry) VersionLines() []string {
+	return []string{fmt.Sprintf("%s:%s (bundle %s, version %s)",
+		e.Bundle.Name, e.Command.Name, e.Bundle.Name, e.Bundle.Version)}
+}
+
+// optionNames returns the names of the options in params, in the same way
+// that RedactParameters identifies them: anything with a leading dash that
+// comes before a "--" terminator, less any inline "=value".
+func optionNames(params []string) []string {
+	var names []string
+
+	for _, p := range params {
+		if p == "--" {
+			break
+		}
+		if !strings.HasPrefix(p, "-") || p == "-" {
+			continue
+		}
+
+		name := strings.TrimLeft(p, "-")
+		if i := strings.Index(name, "="); i >= 0 {
+			name = name[:i]
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func optionFlag(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
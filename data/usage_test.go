@@ -0,0 +1,127 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckParameters(t *testing.T) {
+	cmd := BundleCommand{
+		Usage:   "[--token TOKEN] [string ...]",
+		Options: map[string]*BundleCommandOption{"token": {}, "v": {}},
+	}
+
+	assert.NoError(t, cmd.CheckParameters([]string{"--token", "abc", "-v", "foo"}))
+	assert.NoError(t, cmd.CheckParameters([]string{"--token=abc", "--help"}))
+	assert.NoError(t, cmd.CheckParameters([]string{"-h", "--version"}))
+	assert.NoError(t, cmd.CheckParameters([]string{"--", "--nope"}))
+
+	err := cmd.CheckParameters([]string{"--token", "abc", "--nope"})
+	assert.True(t, errors.Is(err, ErrUnknownOption))
+	assert.Contains(t, err.Error(), "nope")
+
+	// Commands that handle their own help don't reserve its options.
+	cmd.NativeHelp = true
+	assert.Error(t, cmd.CheckParameters([]string{"--help"}))
+
+	// Commands without a usage string check nothing.
+	cmd.Usage = ""
+	assert.NoError(t, cmd.CheckParameters([]string{"--nope"}))
+}
+
+func TestHelpRequested(t *testing.T) {
+	cmd := BundleCommand{}
+
+	assert.True(t, cmd.HelpRequested([]string{"foo", "--help"}))
+	assert.True(t, cmd.HelpRequested([]string{"-h"}))
+	assert.False(t, cmd.HelpRequested([]string{"foo"}))
+	assert.False(t, cmd.HelpRequested([]string{"--", "--help"}))
+
+	// A declared option of the same name belongs to the command.
+	cmd.Options = map[string]*BundleCommandOption{"h": {Description: "Host name."}}
+	assert.False(t, cmd.HelpRequested([]string{"-h", "example.com"}))
+	assert.True(t, cmd.HelpRequested([]string{"--help"}))
+
+	cmd.NativeHelp = true
+	assert.False(t, cmd.HelpRequested([]string{"--help"}))
+}
+
+func TestVersionRequested(t *testing.T) {
+	cmd := BundleCommand{}
+
+	assert.True(t, cmd.VersionRequested([]string{"--version"}))
+	assert.False(t, cmd.VersionRequested([]string{"version"}))
+
+	cmd.NativeHelp = true
+	assert.False(t, cmd.VersionRequested([]string{"--version"}))
+}
+
+func TestUsageLines(t *testing.T) {
+	entry := CommandEntry{
+		Bundle: Bundle{Name: "test"},
+		Command: BundleCommand{
+			Name:        "echox",
+			Description: "Write arguments to the standard output.",
+			Usage:       "[--token TOKEN] [string ...]",
+			Examples:    []string{"test:echox hello"},
+			Options: map[string]*BundleCommandOption{
+				"token": {Description: "An access token."},
+				"n":     {Description: "No trailing newline."},
+			},
+		},
+	}
+
+	expected := []string{
+		"Usage: test:echox [--token TOKEN] [string ...]",
+		"",
+		"Write arguments to the standard output.",
+		"",
+		"Options:",
+		"  -n       No trailing newline.",
+		"  --token  An access token.",
+		"",
+		"Examples:",
+		"  test:echox hello",
+	}
+
+	assert.Equal(t, expected, entry.UsageLines())
+}
+
+func TestUsageLinesLongDescription(t *testing.T) {
+	entry := CommandEntry{
+		Bundle: Bundle{Name: "test", Version: "0.0.1"},
+		Command: BundleCommand{
+			Name:            "echox",
+			Description:     "Write arguments to the standard output.",
+			LongDescription: "Write arguments.\n\nUsage:\n  test:echox [string ...]",
+		},
+	}
+
+	expected := []string{
+		"Write arguments.",
+		"",
+		"Usage:",
+		"  test:echox [string ...]",
+	}
+
+	assert.Equal(t, expected, entry.UsageLines())
+	assert.Equal(t, []string{"test:echox (bundle test, version 0.0.1)"}, entry.VersionLines())
+}
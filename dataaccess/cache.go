@@ -0,0 +1,191 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataaccess
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/memory"
+)
+
+// commandCache holds the command entries of every enabled bundle. It's
+// shared by all of the cachingDataAccess values returned by Get, and is
+// invalidated whenever a bundle is changed through one of them. Changes made
+// by other Gort instances are picked up when its TTL expires.
+var commandCache = &entryCache{}
+
+// entryCache is a snapshot of the command entries of every enabled bundle.
+type entryCache struct {
+	sync.Mutex
+	entries []data.CommandEntry
+	expires time.Time
+}
+
+// get returns the cached entries, loading them with da if the cache is empty
+// or expired.
+func (c *entryCache) get(ctx context.Context, da DataAccess) ([]data.CommandEntry, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.entries != nil && time.Now().Before(c.expires) {
+		return c.entries, nil
+	}
+
+	bundles, err := da.BundleList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]data.CommandEntry, 0)
+	for _, b := range bundles {
+		if !b.Enabled {
+			continue
+		}
+
+		for _, cmd := range b.Commands {
+			entries = append(entries, data.CommandEntry{Bundle: b, Command: *cmd})
+		}
+	}
+
+	c.entries = entries
+	c.expires = time.Now().Add(config.GetDatabaseConfigs().CommandCacheTTLOrDefault())
+
+	return entries, nil
+}
+
+// invalidate empties the cache, so that it's reloaded on its next use.
+func (c *entryCache) invalidate() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.entries = nil
+}
+
+// withCommandCache wraps da so that command lookups are served from
+// commandCache. The in-memory DAL is returned as-is: it has no database to
+// spare, and its data can be reset without going through the wrapper.
+func withCommandCache(da DataAccess) DataAccess {
+	if _, ok := da.(*memory.InMemoryDataAccess); ok {
+		return da
+	}
+
+	if config.GetDatabaseConfigs().CommandCacheTTLOrDefault() <= 0 {
+		return da
+	}
+
+	return cachingDataAccess{da}
+}
+
+// cachingDataAccess is a DataAccess that serves FindCommandEntry and
+// FindCommandEntryByTrigger from commandCache, and invalidates it whenever a
+// bundle is changed.
+type cachingDataAccess struct {
+	DataAccess
+}
+
+func (da cachingDataAccess) Initialize(ctx context.Context) error {
+	defer commandCache.invalidate()
+	return da.DataAccess.Initialize(ctx)
+}
+
+// FindCommandEntry is used to find the enabled commands with the provided
+// bundle and command names. If either is empty, it is treated as a wildcard.
+func (da cachingDataAccess) FindCommandEntry(ctx context.Context, bundleName, commandName string) ([]data.CommandEntry, error) {
+	cached, err := commandCache.get(ctx, da.DataAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]data.CommandEntry, 0)
+	for _, e := range cached {
+		if bundleName != "" && bundleName != e.Bundle.Name {
+			continue
+		}
+		if commandName != "" && commandName != e.Command.Name {
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// FindCommandEntryByTrigger is used to find the enabled commands with a
+// trigger that matches the provided tokens.
+func (da cachingDataAccess) FindCommandEntryByTrigger(ctx context.Context, tokens []string) ([]data.CommandEntry, error) {
+	cached, err := commandCache.get(ctx, da.DataAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.Join(tokens, " ")
+
+	entries := make([]data.CommandEntry, 0)
+	for _, e := range cached {
+		matched, err := e.Command.MatchTrigger(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+func (da cachingDataAccess) BundleCreate(ctx context.Context, bundle data.Bundle) error {
+	defer commandCache.invalidate()
+	return da.DataAccess.BundleCreate(ctx, bundle)
+}
+
+func (da cachingDataAccess) BundleDelete(ctx context.Context, name, version string) error {
+	defer commandCache.invalidate()
+	return da.DataAccess.BundleDelete(ctx, name, version)
+}
+
+func (da cachingDataAccess) BundleDisable(ctx context.Context, name, version string) error {
+	defer commandCache.invalidate()
+	return da.DataAccess.BundleDisable(ctx, name, version)
+}
+
+func (da cachingDataAccess) BundleEnable(ctx context.Context, name, version string) error {
+	defer commandCache.invalidate()
+	return da.DataAccess.BundleEnable(ctx, name, version)
+}
+
+func (da cachingDataAccess) BundleReviewUpdate(ctx context.Context, name, version string, review data.BundleReview) error {
+	defer commandCache.invalidate()
+	return da.DataAccess.BundleReviewUpdate(ctx, name, version, review)
+}
+
+func (da cachingDataAccess) BundleUpdate(ctx context.Context, bundle data.Bundle) error {
+	defer commandCache.invalidate()
+	return da.DataAccess.BundleUpdate(ctx, bundle)
+}
+
+func (da cachingDataAccess) BundleUpgrade(ctx context.Context, bundle data.Bundle, enable bool, retain int) (rest.BundleUpgradeResult, error) {
+	defer commandCache.invalidate()
+	return da.DataAccess.BundleUpgrade(ctx, bundle, enable, retain)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataaccess
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+// countingDataAccess is a DataAccess whose BundleList returns a fixed set of
+// bundles and counts its calls. Any other method panics.
+type countingDataAccess struct {
+	DataAccess
+	bundles []data.Bundle
+	lists   *int
+}
+
+func (da countingDataAccess) BundleList(ctx context.Context) ([]data.Bundle, error) {
+	*da.lists++
+	return da.bundles, nil
+}
+
+func (da countingDataAccess) BundleEnable(ctx context.Context, name, version string) error {
+	return nil
+}
+
+func TestCommandCache(t *testing.T) {
+	ctx := context.Background()
+	commandCache.invalidate()
+	defer commandCache.invalidate()
+
+	lists := 0
+	da := cachingDataAccess{countingDataAccess{
+		lists: &lists,
+		bundles: []data.Bundle{
+			{
+				Name:    "test",
+				Version: "0.0.1",
+				Enabled: true,
+				Commands: map[string]*data.BundleCommand{
+					"echo":  {Name: "echo"},
+					"greet": {Name: "greet", Triggers: []data.Trigger{{Match: "^hello"}}},
+				},
+			},
+			{
+				Name:     "test",
+				Version:  "0.0.0",
+				Commands: map[string]*data.BundleCommand{"old": {Name: "old"}},
+			},
+		},
+	}}
+
+	entries, err := da.FindCommandEntry(ctx, "test", "echo")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "0.0.1", entries[0].Bundle.Version)
+
+	// Commands of disabled bundle versions aren't found.
+	entries, err = da.FindCommandEntry(ctx, "test", "old")
+	require.NoError(t, err)
+	assert.Len(t, entries, 0)
+
+	// Empty names are wildcards.
+	entries, err = da.FindCommandEntry(ctx, "", "")
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	entries, err = da.FindCommandEntryByTrigger(ctx, []string{"hello", "there"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "greet", entries[0].Command.Name)
+
+	// All of those were served by a single load.
+	assert.Equal(t, 1, lists)
+
+	// Changing a bundle empties the cache.
+	require.NoError(t, da.BundleEnable(ctx, "test", "0.0.0"))
+
+	_, err = da.FindCommandEntry(ctx, "test", "echo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, lists)
+}
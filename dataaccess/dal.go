@@ -23,8 +23,10 @@ import (
 	"time"
 
 	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/dataaccess/errs"
 	"github.com/getgort/gort/dataaccess/memory"
+	"github.com/getgort/gort/dataaccess/mysql"
 	"github.com/getgort/gort/dataaccess/postgres"
 	"github.com/getgort/gort/errors"
 	"github.com/getgort/gort/telemetry"
@@ -55,7 +57,7 @@ func Get() (DataAccess, error) {
 		return nil, initializationError
 	}
 
-	return getCorrectDataAccess(), nil
+	return withTokenCache(withCommandCache(getCorrectDataAccess())), nil
 }
 
 func getCorrectDataAccess() DataAccess {
@@ -65,6 +67,10 @@ func getCorrectDataAccess() DataAccess {
 		return memory.NewInMemoryDataAccess()
 	}
 
+	if dbConfigs.DriverOrDefault() == data.DatabaseDriverMySQL {
+		return mysql.NewMySQLDataAccess(dbConfigs)
+	}
+
 	return postgres.NewPostgresDataAccess(dbConfigs)
 }
 
@@ -107,6 +113,8 @@ func initializeDataAccess(ctx context.Context, cancel chan interface{}) {
 		}
 
 		initializationError = nil
+		commandCache.invalidate()
+		tokenCache.invalidate()
 		log.WithField("type", fmt.Sprintf("%T", dataAccess)).
 			Info("Connection to data source established")
 	}()
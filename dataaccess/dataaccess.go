@@ -32,10 +32,31 @@ type DataAccess interface {
 
 	Initialize(context.Context) error
 
+	AuditRecordCreate(ctx context.Context, record *data.AuditRecord) error
+	AuditRecordList(ctx context.Context, filter data.AuditFilter) ([]data.AuditRecord, error)
+
+	AliasDelete(ctx context.Context, owner, name string) error
+	AliasList(ctx context.Context, name string) ([]data.Alias, error)
+	AliasSave(ctx context.Context, alias data.Alias) error
+
+	DeadLetterCreate(ctx context.Context, letter *data.DeadLetter) error
+	DeadLetterDelete(ctx context.Context, id int64) error
+	DeadLetterGet(ctx context.Context, id int64) (data.DeadLetter, error)
+	DeadLetterList(ctx context.Context) ([]data.DeadLetter, error)
+	DeadLetterPurge(ctx context.Context, before time.Time) (int, error)
+	DeadLetterUpdate(ctx context.Context, letter data.DeadLetter) error
+
 	RequestBegin(ctx context.Context, request *data.CommandRequest) error
 	RequestUpdate(ctx context.Context, request data.CommandRequest) error
 	RequestError(ctx context.Context, request data.CommandRequest, err error) error
 	RequestClose(ctx context.Context, result data.CommandResponseEnvelope) error
+	RequestArchive(ctx context.Context, requestID int64, payload data.RequestPayload) error
+	RequestArchivePurge(ctx context.Context, before time.Time) (int, error)
+	RequestGet(ctx context.Context, requestID int64) (data.RequestRecord, error)
+	RequestHistory(ctx context.Context, adapter, channelID, userID string, limit int) ([]data.RequestRecord, error)
+	RequestList(ctx context.Context, limit int) ([]data.RequestRecord, error)
+	RequestLogAppend(ctx context.Context, requestID int64, entry data.RequestLogEntry) error
+	RequestUpdateTimings(ctx context.Context, requestID int64, timings data.StageTimings) error
 
 	BundleCreate(ctx context.Context, bundle data.Bundle) error
 	BundleDelete(ctx context.Context, name string, version string) error
@@ -46,8 +67,23 @@ type DataAccess interface {
 	BundleVersionExists(ctx context.Context, name string, version string) (bool, error)
 	BundleGet(ctx context.Context, name string, version string) (data.Bundle, error)
 	BundleList(ctx context.Context) ([]data.Bundle, error)
+	BundleReviewUpdate(ctx context.Context, name string, version string, review data.BundleReview) error
 	BundleVersionList(ctx context.Context, name string) ([]data.Bundle, error)
 	BundleUpdate(ctx context.Context, bundle data.Bundle) error
+	BundleUpgrade(ctx context.Context, bundle data.Bundle, enable bool, retain int) (rest.BundleUpgradeResult, error)
+
+	ChannelPresenceDelete(ctx context.Context, adapter, channelID string) error
+	ChannelPresenceList(ctx context.Context, adapter string) ([]data.ChannelPresence, error)
+	ChannelPresenceMarkActive(ctx context.Context, adapter, channelID string) error
+	ChannelPresenceMarkGreeted(ctx context.Context, adapter, channelID string) error
+	ChannelSettingsDelete(ctx context.Context, adapter, channelID string) error
+	ChannelSettingsGet(ctx context.Context, adapter, channelID string) (data.ChannelSettings, error)
+	ChannelSettingsSet(ctx context.Context, settings data.ChannelSettings) error
+
+	CostAdd(ctx context.Context, record data.CostRecord) error
+	CostList(ctx context.Context, month string) ([]data.CostRecord, error)
+
+	DeletedPurge(ctx context.Context, before time.Time) (int, error)
 
 	DynamicConfigurationCreate(ctx context.Context, config data.DynamicConfiguration) error
 	DynamicConfigurationDelete(ctx context.Context, layer data.ConfigurationLayer, bundle, owner, key string) error
@@ -61,6 +97,7 @@ type DataAccess interface {
 	GroupGet(ctx context.Context, groupname string) (rest.Group, error)
 	GroupList(ctx context.Context) ([]rest.Group, error)
 	GroupPermissionList(ctx context.Context, groupname string) (rest.RolePermissionList, error)
+	GroupRestore(ctx context.Context, groupname string, since time.Time) error
 	GroupRoleAdd(ctx context.Context, groupname, rolename string) error
 	GroupRoleDelete(ctx context.Context, groupname, rolename string) error
 	GroupRoleList(ctx context.Context, groupname string) ([]rest.Role, error)
@@ -69,6 +106,28 @@ type DataAccess interface {
 	GroupUserDelete(ctx context.Context, groupname string, username string) error
 	GroupUserList(ctx context.Context, groupname string) ([]rest.User, error)
 
+	LockAcquire(ctx context.Context, name, owner, username string, ttl time.Duration) (data.Lock, error)
+	LockGet(ctx context.Context, name string) (data.Lock, error)
+	LockPurge(ctx context.Context, before time.Time) (int, error)
+	LockRelease(ctx context.Context, name, owner string) error
+	LockRenew(ctx context.Context, name, owner string, ttl time.Duration) (data.Lock, error)
+
+	MacroDelete(ctx context.Context, layer data.ConfigurationLayer, owner, name string) error
+	MacroList(ctx context.Context, name string) ([]data.Macro, error)
+	MacroSave(ctx context.Context, macro data.Macro) error
+
+	OptionDefaultDelete(ctx context.Context, layer data.ConfigurationLayer, owner, bundle, command, option string) error
+	OptionDefaultList(ctx context.Context, bundle, command string) ([]data.OptionDefault, error)
+	OptionDefaultSet(ctx context.Context, def data.OptionDefault) error
+
+	OutboxCreate(ctx context.Context, entry data.OutboxEntry) error
+	OutboxGet(ctx context.Context, requestID int64) (data.OutboxEntry, error)
+	OutboxMarkAttempted(ctx context.Context, requestID int64, at time.Time) error
+	OutboxMarkDelivered(ctx context.Context, requestID int64, at time.Time) error
+	OutboxPending(ctx context.Context, before time.Time) ([]data.OutboxEntry, error)
+	OutboxPurge(ctx context.Context, before time.Time) (int, error)
+
+	RoleClone(ctx context.Context, rolename, clonename string) error
 	RoleCreate(ctx context.Context, rolename string) error
 	RoleDelete(ctx context.Context, rolename string) error
 	RoleGet(ctx context.Context, rolename string) (rest.Role, error)
@@ -79,20 +138,29 @@ type DataAccess interface {
 	RoleList(ctx context.Context) ([]rest.Role, error)
 	RoleExists(ctx context.Context, rolename string) (bool, error)
 	RolePermissionAdd(ctx context.Context, rolename, bundlename, permission string) error
+	RolePermissionAddBatch(ctx context.Context, rolename string, permissions rest.RolePermissionList) error
 	RolePermissionDelete(ctx context.Context, rolename, bundlename, permission string) error
 	RolePermissionExists(ctx context.Context, rolename, bundlename, permission string) (bool, error)
 	RolePermissionList(ctx context.Context, rolename string) (rest.RolePermissionList, error)
 
+	SecretOutputCreate(ctx context.Context, output data.SecretOutput) error
+	SecretOutputPurge(ctx context.Context, before time.Time) (int, error)
+	SecretOutputRedeem(ctx context.Context, tokenHash string) (data.SecretOutput, error)
+
 	TokenEvaluate(ctx context.Context, token string) bool
 	TokenGenerate(ctx context.Context, username string, duration time.Duration) (rest.Token, error)
 	TokenInvalidate(ctx context.Context, token string) error
+	TokenPurge(ctx context.Context, before time.Time) (int, error)
 	TokenRetrieveByUser(ctx context.Context, username string) (rest.Token, error)
 	TokenRetrieveByToken(ctx context.Context, token string) (rest.Token, error)
+	TokenRevoke(ctx context.Context, id string, until time.Time) error
+	TokenRevoked(ctx context.Context, id string) (bool, error)
 
 	UserAuthenticate(ctx context.Context, username string, password string) (bool, error)
 	UserCreate(ctx context.Context, user rest.User) error
 	UserDelete(ctx context.Context, username string) error
 	UserExists(ctx context.Context, username string) (bool, error)
+	UserExport(ctx context.Context, username string) (rest.UserDataExport, error)
 	UserGet(ctx context.Context, username string) (rest.User, error)
 	UserGetByEmail(ctx context.Context, email string) (rest.User, error)
 	UserGetByID(ctx context.Context, adapter, id string) (rest.User, error)
@@ -101,6 +169,9 @@ type DataAccess interface {
 	UserGroupDelete(ctx context.Context, username string, groupname string) error
 	UserList(ctx context.Context) ([]rest.User, error)
 	UserPermissionList(ctx context.Context, username string) (rest.RolePermissionList, error)
+	UserPurge(ctx context.Context, username string) (rest.UserPurgeResult, error)
+	UserRename(ctx context.Context, username, newname string) error
+	UserRestore(ctx context.Context, username string, since time.Time) error
 	UserRoleList(ctx context.Context, username string) ([]rest.Role, error)
 	UserUpdate(ctx context.Context, user rest.User) error
 }
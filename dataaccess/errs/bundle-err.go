@@ -29,5 +29,9 @@ var ErrEmptyBundleName = errors.New("bundle name is empty")
 // ErrEmptyBundleVersion indicates...
 var ErrEmptyBundleVersion = errors.New("bundle version is empty")
 
+// ErrBundleVersionOlder is returned by an upgrade when the requested
+// version is older than a version that's already installed.
+var ErrBundleVersionOlder = errors.New("bundle version is older than an installed version")
+
 // ErrNoSuchBundle indicates...
 var ErrNoSuchBundle = errors.New("no such bundle")
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errs
+
+import (
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// Data access error codes are in the GORT-1xxx range.
+func init() {
+	gerrs.RegisterCode(ErrDataAccess, gerrs.Code{
+		Code:        "GORT-1001",
+		Title:       "Data store error",
+		Description: "The data store reported an error while handling the request.",
+		Remediation: "Check the Gort controller logs for the underlying error, and verify that the database is healthy.",
+	})
+	gerrs.RegisterCode(ErrDataAccessCantConnect, gerrs.Code{
+		Code:        "GORT-1002",
+		Title:       "Can't connect to the data store",
+		Description: "The Gort controller was unable to connect to its data store.",
+		Remediation: "Verify the database section of the Gort configuration and that the database is reachable from the controller.",
+	})
+	gerrs.RegisterCode(ErrDataAccessCantInitialize, gerrs.Code{
+		Code:        "GORT-1003",
+		Title:       "Can't initialize the data store",
+		Description: "The Gort controller was unable to initialize its data store schema.",
+		Remediation: "Check that the configured database user has permission to create tables.",
+	})
+	gerrs.RegisterCode(ErrDataAccessNotInitialized, gerrs.Code{
+		Code:        "GORT-1004",
+		Title:       "Data store not initialized",
+		Description: "The data store hasn't finished initializing.",
+		Remediation: "Wait a few moments and try again. If the problem persists, check the controller logs.",
+	})
+	gerrs.RegisterCode(ErrNotImplemented, gerrs.Code{
+		Code:        "GORT-1005",
+		Title:       "Not implemented",
+		Description: "The configured data store doesn't support this operation.",
+	})
+	gerrs.RegisterCode(ErrNoSuchBundle, gerrs.Code{
+		Code:        "GORT-1101",
+		Title:       "No such bundle or command",
+		Description: "The requested bundle or command isn't installed.",
+		Remediation: "Use `gort bundle list` to see installed bundles, or ask a Gort administrator to install the bundle.",
+	})
+	gerrs.RegisterCode(ErrNoSuchConfig, gerrs.Code{
+		Code:        "GORT-1102",
+		Title:       "No such dynamic configuration",
+		Description: "The requested dynamic configuration doesn't exist.",
+		Remediation: "Use `gort config get` to list the configurations for a bundle.",
+	})
+	gerrs.RegisterCode(ErrNoSuchGroup, gerrs.Code{
+		Code:        "GORT-1103",
+		Title:       "No such group",
+		Description: "The requested group doesn't exist.",
+		Remediation: "Use `gort group list` to see existing groups.",
+	})
+	gerrs.RegisterCode(ErrNoSuchRole, gerrs.Code{
+		Code:        "GORT-1104",
+		Title:       "No such role",
+		Description: "The requested role doesn't exist.",
+		Remediation: "Use `gort role list` to see existing roles.",
+	})
+	gerrs.RegisterCode(ErrNoSuchToken, gerrs.Code{
+		Code:        "GORT-1105",
+		Title:       "No such token",
+		Description: "The session token doesn't exist or has expired.",
+		Remediation: "Re-authenticate to obtain a new token.",
+	})
+	gerrs.RegisterCode(ErrNoSuchUser, gerrs.Code{
+		Code:        "GORT-1106",
+		Title:       "No such user",
+		Description: "The requested user doesn't exist.",
+		Remediation: "Use `gort user list` to see existing users, or ask a Gort administrator to create or map your account.",
+	})
+	gerrs.RegisterCode(ErrNoSuchOptionDefault, gerrs.Code{
+		Code:        "GORT-1107",
+		Title:       "No such option default",
+		Description: "The requested command option default doesn't exist.",
+		Remediation: "Use `gort defaults list` to see the defaults for a command.",
+	})
+	gerrs.RegisterCode(ErrNoSuchRequest, gerrs.Code{
+		Code:        "GORT-1108",
+		Title:       "No such request",
+		Description: "The requested command request doesn't exist, or its record has been purged.",
+		Remediation: "Use `gort ps` to see recent requests.",
+	})
+	gerrs.RegisterCode(ErrNoSuchMacro, gerrs.Code{
+		Code:        "GORT-1109",
+		Title:       "No such macro",
+		Description: "The requested macro doesn't exist at the given layer and owner.",
+		Remediation: "Use `gort macro list` to see the macros available to you.",
+	})
+	gerrs.RegisterCode(ErrNoSuchDeadLetter, gerrs.Code{
+		Code:        "GORT-1110",
+		Title:       "No such dead letter",
+		Description: "The requested undeliverable response doesn't exist, or has already been redelivered or deleted.",
+		Remediation: "Use `gort deadletter list` to see undeliverable responses.",
+	})
+	gerrs.RegisterCode(ErrNoSuchSecretOutput, gerrs.Code{
+		Code:        "GORT-1111",
+		Title:       "No such secret output",
+		Description: "The requested secret output doesn't exist, has already been viewed, or has expired.",
+		Remediation: "Run the command again to receive a new link.",
+	})
+	gerrs.RegisterCode(ErrNoSuchOutboxEntry, gerrs.Code{
+		Code:        "GORT-1112",
+		Title:       "No such outbox entry",
+		Description: "The requested stored response doesn't exist, or has already been delivered and purged.",
+	})
+	gerrs.RegisterCode(ErrNoSuchAlias, gerrs.Code{
+		Code:        "GORT-1113",
+		Title:       "No such alias",
+		Description: "The requested alias doesn't exist for the given user, or globally.",
+		Remediation: "Use `gort alias list` to see the aliases available to you.",
+	})
+	gerrs.RegisterCode(ErrAdminUndeletable, gerrs.Code{
+		Code:        "GORT-1201",
+		Title:       "Admin can't be deleted",
+		Description: "The built-in admin user, group, or role can't be deleted or renamed.",
+	})
+	gerrs.RegisterCode(ErrConfigIllegal, gerrs.Code{
+		Code:        "GORT-1202",
+		Title:       "Illegal dynamic configuration",
+		Description: "Dynamic configuration keys may not begin with GORT_, which is reserved for values set by Gort itself.",
+		Remediation: "Choose a key without the GORT_ prefix.",
+	})
+	gerrs.RegisterCode(ErrLockHeld, gerrs.Code{
+		Code:        "GORT-1203",
+		Title:       "Command locked",
+		Description: "The command is exclusive, and another invocation of it (or of a command sharing the same lock) is already running.",
+		Remediation: "Wait for the other invocation to complete, then try again.",
+	})
+	gerrs.RegisterCode(ErrBundleVersionOlder, gerrs.Code{
+		Code:        "GORT-1204",
+		Title:       "Bundle version is older",
+		Description: "An upgrade was requested to a bundle version that's older than one that's already installed.",
+		Remediation: "Use `gort bundle info` to see the installed versions, or install the older version without --upgrade.",
+	})
+	gerrs.RegisterCode(ErrServiceAccountCredentials, gerrs.Code{
+		Code:        "GORT-1205",
+		Title:       "Service account credentials",
+		Description: "Service accounts authenticate only with tokens, so they can't have a password or be mapped to chat users.",
+		Remediation: "Remove the password and mappings, and issue a token with `gort user token <username>`.",
+	})
+}
@@ -21,8 +21,8 @@ import (
 )
 
 var (
-	// ErrAdminUndeletable is returned when an attempt is made to delete an
-	// admin user/account/etc.
+	// ErrAdminUndeletable is returned when an attempt is made to delete (or
+	// rename) an admin user/account/etc.
 	ErrAdminUndeletable = errors.New("admin can't be deleted")
 
 	// ErrDataAccess that an error has been reported by the data store.
@@ -0,0 +1,29 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errs
+
+import (
+	"errors"
+)
+
+// ErrLockHeld indicates that a lock couldn't be acquired because it's
+// already held by another owner.
+var ErrLockHeld = errors.New("lock is held by another owner")
+
+// ErrNoSuchLock indicates that the lock doesn't exist, or isn't held by the
+// specified owner.
+var ErrNoSuchLock = errors.New("no such lock")
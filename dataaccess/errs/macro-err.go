@@ -0,0 +1,31 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errs
+
+import (
+	"errors"
+)
+
+// ErrEmptyMacroName indicates that a macro is missing its name.
+var ErrEmptyMacroName = errors.New("macro name is empty")
+
+// ErrEmptyMacroOwner indicates that a macro is missing its owning user or
+// group name.
+var ErrEmptyMacroOwner = errors.New("macro owner name is empty")
+
+// ErrNoSuchMacro indicates that the requested macro doesn't exist.
+var ErrNoSuchMacro = errors.New("no such macro")
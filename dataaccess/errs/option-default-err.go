@@ -0,0 +1,37 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errs
+
+import (
+	"errors"
+)
+
+// ErrEmptyOptionDefaultCommand indicates that an option default is missing
+// its bundle or command name.
+var ErrEmptyOptionDefaultCommand = errors.New("option default bundle or command name is empty")
+
+// ErrEmptyOptionDefaultOption indicates that an option default is missing
+// its option name.
+var ErrEmptyOptionDefaultOption = errors.New("option default option name is empty")
+
+// ErrEmptyOptionDefaultOwner indicates that a group or room option default
+// is missing its owner.
+var ErrEmptyOptionDefaultOwner = errors.New("option default owner name is empty")
+
+// ErrNoSuchOptionDefault indicates that the requested option default
+// doesn't exist.
+var ErrNoSuchOptionDefault = errors.New("no such option default")
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errs
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// The HTTP statuses that data access errors are reported with by the REST
+// API.
+func init() {
+	// A required field is empty or missing
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusExpectationFailed, Level: log.InfoLevel},
+		ErrEmptyAliasName,
+		ErrEmptyBundleName,
+		ErrEmptyBundleVersion,
+		ErrEmptyChannel,
+		ErrEmptyConfigBundle,
+		ErrEmptyConfigKey,
+		ErrEmptyConfigLayer,
+		ErrEmptyConfigOwner,
+		ErrEmptyGroupName,
+		ErrEmptyMacroName,
+		ErrEmptyMacroOwner,
+		ErrEmptyOptionDefaultCommand,
+		ErrEmptyOptionDefaultOption,
+		ErrEmptyOptionDefaultOwner,
+		ErrEmptyPermission,
+		ErrEmptyRoleName,
+		ErrEmptyUserAdapter,
+		ErrEmptyUserEmail,
+		ErrEmptyUserID,
+		ErrEmptyUserName,
+		ErrFieldRequired,
+	)
+
+	// Requested resource doesn't exist
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusNotFound, Level: log.InfoLevel},
+		ErrNoSuchAlias,
+		ErrNoSuchBundle,
+		ErrNoSuchConfig,
+		ErrNoSuchDeadLetter,
+		ErrNoSuchGroup,
+		ErrNoSuchLock,
+		ErrNoSuchMacro,
+		ErrNoSuchOptionDefault,
+		ErrNoSuchOutboxEntry,
+		ErrNoSuchRequest,
+		ErrNoSuchRole,
+		ErrNoSuchSecretOutput,
+		ErrNoSuchToken,
+		ErrNoSuchUser,
+	)
+
+	// Nope
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusForbidden, Level: log.WarnLevel},
+		ErrAdminUndeletable,
+		ErrConfigIllegal,
+		ErrServiceAccountCredentials,
+	)
+
+	// Can't insert over something that already exists
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusConflict, Level: log.InfoLevel},
+		ErrBundleExists,
+		ErrBundleVersionOlder,
+		ErrConfigExists,
+		ErrGroupExists,
+		ErrLockHeld,
+		ErrRoleExists,
+		ErrUserExists,
+	)
+
+	// Not done yet, or not possible
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusNotImplemented, Level: log.InfoLevel},
+		ErrNotImplemented,
+	)
+
+	// The data store itself is unhappy
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusInternalServerError, Level: log.ErrorLevel},
+		ErrDataAccess,
+		ErrDataAccessCantConnect,
+		ErrDataAccessCantInitialize,
+		ErrDataAccessNotInitialized,
+	)
+}
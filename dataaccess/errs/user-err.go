@@ -38,3 +38,7 @@ var ErrEmptyUserName = errors.New("user name is empty")
 
 // ErrUserExists TBD
 var ErrUserExists = errors.New("user already exists")
+
+// ErrServiceAccountCredentials indicates that a service account was given a
+// password or chat adapter mappings, neither of which it may have.
+var ErrServiceAccountCredentials = errors.New("service accounts can't have passwords or chat mappings")
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+// AliasDelete removes an alias. An empty owner refers to a global alias.
+func (da *InMemoryDataAccess) AliasDelete(ctx context.Context, owner, name string) error {
+	if name == "" {
+		return errs.ErrEmptyAliasName
+	}
+
+	key := aliasKey(data.Alias{Owner: owner, Name: name})
+	if da.aliases[key] == nil {
+		return errs.ErrNoSuchAlias
+	}
+
+	delete(da.aliases, key)
+
+	return nil
+}
+
+// AliasList returns all aliases, global and user-owned, with the given name.
+// If name is empty, all aliases are returned. The results are sorted by name
+// and owner, with global aliases first.
+func (da *InMemoryDataAccess) AliasList(ctx context.Context, name string) ([]data.Alias, error) {
+	list := []data.Alias{}
+	for _, a := range da.aliases {
+		if name == "" || a.Name == name {
+			list = append(list, *a)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Owner < b.Owner
+	})
+
+	return list, nil
+}
+
+// AliasSave creates or replaces an alias.
+func (da *InMemoryDataAccess) AliasSave(ctx context.Context, alias data.Alias) error {
+	if alias.Name == "" {
+		return errs.ErrEmptyAliasName
+	}
+
+	if err := alias.Validate(); err != nil {
+		return err
+	}
+
+	da.aliases[aliasKey(alias)] = &alias
+
+	return nil
+}
+
+// aliasKey returns a's map key.
+func aliasKey(a data.Alias) string {
+	return strings.Join([]string{a.Owner, a.Name}, "|")
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// auditMutex guards da.audit, which is appended to by concurrent REST
+// requests.
+var auditMutex sync.Mutex
+
+// AuditRecordCreate stores an audit record, setting its ID.
+func (da *InMemoryDataAccess) AuditRecordCreate(ctx context.Context, record *data.AuditRecord) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.AuditRecordCreate")
+	defer sp.End()
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	record.ID = int64(len(da.audit) + 1)
+
+	r := *record
+	da.audit = append(da.audit, &r)
+
+	return nil
+}
+
+// AuditRecordList returns the audit records that match the filter, newest
+// first.
+func (da *InMemoryDataAccess) AuditRecordList(ctx context.Context, filter data.AuditFilter) ([]data.AuditRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.AuditRecordList")
+	defer sp.End()
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	list := []data.AuditRecord{}
+
+	for i := len(da.audit) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(list) >= filter.Limit {
+			break
+		}
+
+		if filter.Matches(*da.audit[i]) {
+			list = append(list, *da.audit[i])
+		}
+	}
+
+	return list, nil
+}
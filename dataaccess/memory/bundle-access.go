@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/getgort/gort/bundles"
 	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/dataaccess/errs"
 )
 
@@ -217,6 +219,26 @@ func (da *InMemoryDataAccess) BundleList(ctx context.Context) ([]data.Bundle, er
 	return list, nil
 }
 
+// BundleReviewUpdate sets the review state of a bundle version.
+func (da *InMemoryDataAccess) BundleReviewUpdate(ctx context.Context, name, version string, review data.BundleReview) error {
+	if name == "" {
+		return errs.ErrEmptyBundleName
+	}
+
+	if version == "" {
+		return errs.ErrEmptyBundleVersion
+	}
+
+	bundle, ok := da.bundles[bundleKey(name, version)]
+	if !ok {
+		return errs.ErrNoSuchBundle
+	}
+
+	bundle.Review = review
+
+	return nil
+}
+
 // BundleListVersions TBD
 func (da *InMemoryDataAccess) BundleVersionList(ctx context.Context, name string) ([]data.Bundle, error) {
 	list := make([]data.Bundle, 0)
@@ -253,6 +275,62 @@ func (da *InMemoryDataAccess) BundleUpdate(ctx context.Context, bundle data.Bund
 	return nil
 }
 
+// BundleUpgrade installs bundle if it isn't already installed, optionally
+// enables it, and then uninstalls all but the retain newest versions. It
+// returns ErrBundleVersionOlder if a newer version is already installed.
+func (da *InMemoryDataAccess) BundleUpgrade(ctx context.Context, bundle data.Bundle, enable bool, retain int) (rest.BundleUpgradeResult, error) {
+	if bundle.Name == "" {
+		return rest.BundleUpgradeResult{}, errs.ErrEmptyBundleName
+	}
+
+	if bundle.Version == "" {
+		return rest.BundleUpgradeResult{}, errs.ErrEmptyBundleVersion
+	}
+
+	installed, err := da.BundleVersionList(ctx, bundle.Name)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	if _, newer := bundles.NewerVersionInstalled(installed, bundle.Version); newer {
+		return rest.BundleUpgradeResult{}, errs.ErrBundleVersionOlder
+	}
+
+	result := rest.BundleUpgradeResult{Name: bundle.Name, Version: bundle.Version}
+
+	for _, b := range installed {
+		if b.Enabled {
+			result.PreviousEnabled = b.Version
+		}
+	}
+
+	if _, exists := da.bundles[bundleKey(bundle.Name, bundle.Version)]; !exists {
+		if err := da.BundleCreate(ctx, bundle); err != nil {
+			return rest.BundleUpgradeResult{}, err
+		}
+
+		result.Installed = true
+		installed = append(installed, bundle)
+	}
+
+	enabled := result.PreviousEnabled
+	if enable {
+		if err := da.BundleEnable(ctx, bundle.Name, bundle.Version); err != nil {
+			return rest.BundleUpgradeResult{}, err
+		}
+
+		enabled = bundle.Version
+	}
+	result.Enabled = (enabled == bundle.Version)
+
+	for _, v := range bundles.PruneCandidates(installed, enabled, retain) {
+		delete(da.bundles, bundleKey(bundle.Name, v))
+		result.Pruned = append(result.Pruned, v)
+	}
+
+	return result, nil
+}
+
 // FindCommandEntry is used to find the enabled commands with the provided
 // bundle and command names. If either is empty, it is treated as a wildcard.
 // Importantly, this must only return ENABLED commands!
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+// channelsMutex guards da.channels, which is updated by adapter event
+// handlers that may run concurrently.
+var channelsMutex sync.Mutex
+
+// ChannelPresenceDelete removes the record of a channel. It's not an error
+// if no record exists.
+func (da *InMemoryDataAccess) ChannelPresenceDelete(ctx context.Context, adapter, channelID string) error {
+	channelsMutex.Lock()
+	defer channelsMutex.Unlock()
+
+	delete(da.channels, channelPresenceKey(adapter, channelID))
+
+	return nil
+}
+
+// ChannelPresenceList returns the records of every channel belonging to the
+// named adapter, ordered by channel ID.
+func (da *InMemoryDataAccess) ChannelPresenceList(ctx context.Context, adapter string) ([]data.ChannelPresence, error) {
+	channelsMutex.Lock()
+	defer channelsMutex.Unlock()
+
+	list := []data.ChannelPresence{}
+	for _, p := range da.channels {
+		if p.Adapter == adapter {
+			list = append(list, *p)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ChannelID < list[j].ChannelID })
+
+	return list, nil
+}
+
+// ChannelPresenceMarkActive records that a command was just run from a
+// channel, creating the channel's record if necessary.
+func (da *InMemoryDataAccess) ChannelPresenceMarkActive(ctx context.Context, adapter, channelID string) error {
+	channelsMutex.Lock()
+	defer channelsMutex.Unlock()
+
+	p := da.channelPresence(adapter, channelID)
+	p.LastCommandAt = time.Now().UTC()
+
+	return nil
+}
+
+// ChannelPresenceMarkGreeted records that a channel was just greeted,
+// creating the channel's record if necessary.
+func (da *InMemoryDataAccess) ChannelPresenceMarkGreeted(ctx context.Context, adapter, channelID string) error {
+	channelsMutex.Lock()
+	defer channelsMutex.Unlock()
+
+	p := da.channelPresence(adapter, channelID)
+	p.GreetedAt = time.Now().UTC()
+
+	return nil
+}
+
+// ChannelSettingsDelete removes a channel's settings. It's not an error if
+// the channel has none.
+func (da *InMemoryDataAccess) ChannelSettingsDelete(ctx context.Context, adapter, channelID string) error {
+	channelsMutex.Lock()
+	defer channelsMutex.Unlock()
+
+	delete(da.chansets, channelPresenceKey(adapter, channelID))
+
+	return nil
+}
+
+// ChannelSettingsGet returns a channel's settings. If the channel has none,
+// zero-value settings are returned.
+func (da *InMemoryDataAccess) ChannelSettingsGet(ctx context.Context, adapter, channelID string) (data.ChannelSettings, error) {
+	channelsMutex.Lock()
+	defer channelsMutex.Unlock()
+
+	if s, ok := da.chansets[channelPresenceKey(adapter, channelID)]; ok {
+		return copyChannelSettings(*s), nil
+	}
+
+	return data.ChannelSettings{Adapter: adapter, ChannelID: channelID}, nil
+}
+
+// ChannelSettingsSet creates or replaces a channel's settings.
+func (da *InMemoryDataAccess) ChannelSettingsSet(ctx context.Context, settings data.ChannelSettings) error {
+	if settings.Adapter == "" || settings.ChannelID == "" {
+		return errs.ErrEmptyChannel
+	}
+
+	channelsMutex.Lock()
+	defer channelsMutex.Unlock()
+
+	settings = copyChannelSettings(settings)
+	da.chansets[channelPresenceKey(settings.Adapter, settings.ChannelID)] = &settings
+
+	return nil
+}
+
+// copyChannelSettings returns a copy of settings that shares no memory with
+// it, so that stored settings can't be changed by their caller.
+func copyChannelSettings(settings data.ChannelSettings) data.ChannelSettings {
+	if settings.QuietHours != nil {
+		q := *settings.QuietHours
+		settings.QuietHours = &q
+	}
+
+	return settings
+}
+
+// channelPresence returns the record for a channel, creating it if it doesn't
+// exist. The caller must hold channelsMutex.
+func (da *InMemoryDataAccess) channelPresence(adapter, channelID string) *data.ChannelPresence {
+	key := channelPresenceKey(adapter, channelID)
+
+	p, ok := da.channels[key]
+	if !ok {
+		p = &data.ChannelPresence{
+			Adapter:   adapter,
+			ChannelID: channelID,
+			FirstSeen: time.Now().UTC(),
+		}
+		da.channels[key] = p
+	}
+
+	return p
+}
+
+func channelPresenceKey(adapter, channelID string) string {
+	return adapter + "/" + channelID
+}
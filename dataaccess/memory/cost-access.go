@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// costsMutex guards da.costs, which are added to by concurrent command
+// executions.
+var costsMutex sync.Mutex
+
+// CostAdd adds a record's usage to the total for its month, bundle, and
+// group.
+func (da *InMemoryDataAccess) CostAdd(ctx context.Context, record data.CostRecord) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.CostAdd")
+	defer sp.End()
+
+	costsMutex.Lock()
+	defer costsMutex.Unlock()
+
+	key := record.Month + "|" + record.Bundle + "|" + record.Group
+
+	r, ok := da.costs[key]
+	if !ok {
+		r = &data.CostRecord{Month: record.Month, Bundle: record.Bundle, Group: record.Group}
+		da.costs[key] = r
+	}
+
+	r.Executions += record.Executions
+	r.Seconds += record.Seconds
+	r.CPUSeconds += record.CPUSeconds
+	r.MemoryGiBSeconds += record.MemoryGiBSeconds
+	r.Cost += record.Cost
+
+	return nil
+}
+
+// CostList returns the cost totals for the given month, or for every month
+// if month is empty, ordered by month, bundle, and group.
+func (da *InMemoryDataAccess) CostList(ctx context.Context, month string) ([]data.CostRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.CostList")
+	defer sp.End()
+
+	costsMutex.Lock()
+	defer costsMutex.Unlock()
+
+	list := []data.CostRecord{}
+	for _, r := range da.costs {
+		if month == "" || r.Month == month {
+			list = append(list, *r)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Month != list[j].Month {
+			return list[i].Month < list[j].Month
+		}
+		if list[i].Bundle != list[j].Bundle {
+			return list[i].Bundle < list[j].Bundle
+		}
+		return list[i].Group < list[j].Group
+	})
+
+	return list, nil
+}
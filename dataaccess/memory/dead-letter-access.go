@@ -0,0 +1,153 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+var (
+	// deadLettersMutex guards da.deadletters and lastDeadLetterID. Dead
+	// letters are created by the adapters and redelivered through the REST
+	// API concurrently.
+	deadLettersMutex sync.Mutex
+
+	lastDeadLetterID int64
+)
+
+// DeadLetterCreate stores an undeliverable response, setting its ID.
+func (da *InMemoryDataAccess) DeadLetterCreate(ctx context.Context, letter *data.DeadLetter) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.DeadLetterCreate")
+	defer sp.End()
+
+	deadLettersMutex.Lock()
+	defer deadLettersMutex.Unlock()
+
+	lastDeadLetterID++
+	letter.ID = lastDeadLetterID
+
+	l := *letter
+	da.deadletters[l.ID] = &l
+
+	return nil
+}
+
+// DeadLetterDelete deletes a dead letter.
+func (da *InMemoryDataAccess) DeadLetterDelete(ctx context.Context, id int64) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.DeadLetterDelete")
+	defer sp.End()
+
+	deadLettersMutex.Lock()
+	defer deadLettersMutex.Unlock()
+
+	if _, ok := da.deadletters[id]; !ok {
+		return errs.ErrNoSuchDeadLetter
+	}
+
+	delete(da.deadletters, id)
+
+	return nil
+}
+
+// DeadLetterGet returns a dead letter.
+func (da *InMemoryDataAccess) DeadLetterGet(ctx context.Context, id int64) (data.DeadLetter, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.DeadLetterGet")
+	defer sp.End()
+
+	deadLettersMutex.Lock()
+	defer deadLettersMutex.Unlock()
+
+	l, ok := da.deadletters[id]
+	if !ok {
+		return data.DeadLetter{}, errs.ErrNoSuchDeadLetter
+	}
+
+	return *l, nil
+}
+
+// DeadLetterList returns all dead letters, oldest first.
+func (da *InMemoryDataAccess) DeadLetterList(ctx context.Context) ([]data.DeadLetter, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.DeadLetterList")
+	defer sp.End()
+
+	deadLettersMutex.Lock()
+	defer deadLettersMutex.Unlock()
+
+	list := []data.DeadLetter{}
+	for _, l := range da.deadletters {
+		list = append(list, *l)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	return list, nil
+}
+
+// DeadLetterPurge deletes every dead letter created before the given time,
+// returning the number deleted.
+func (da *InMemoryDataAccess) DeadLetterPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.DeadLetterPurge")
+	defer sp.End()
+
+	deadLettersMutex.Lock()
+	defer deadLettersMutex.Unlock()
+
+	n := 0
+	for id, l := range da.deadletters {
+		if l.Created.Before(before) {
+			delete(da.deadletters, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// DeadLetterUpdate records the outcome of a failed redelivery attempt:
+// the error, attempt count, and attempt time are updated.
+func (da *InMemoryDataAccess) DeadLetterUpdate(ctx context.Context, letter data.DeadLetter) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.DeadLetterUpdate")
+	defer sp.End()
+
+	deadLettersMutex.Lock()
+	defer deadLettersMutex.Unlock()
+
+	l, ok := da.deadletters[letter.ID]
+	if !ok {
+		return errs.ErrNoSuchDeadLetter
+	}
+
+	l.Error = letter.Error
+	l.Attempts = letter.Attempts
+	l.LastAttempt = letter.LastAttempt
+
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/getgort/gort/data/rest"
+)
+
+// deletedEntities holds soft-deleted users and groups until they're restored
+// or purged.
+type deletedEntities struct {
+	groups map[string]deletedGroup
+	users  map[string]deletedUser
+}
+
+type deletedGroup struct {
+	group     *rest.Group
+	deletedAt time.Time
+}
+
+type deletedUser struct {
+	user      *rest.User
+	deletedAt time.Time
+}
+
+func newDeletedEntities() deletedEntities {
+	return deletedEntities{
+		groups: make(map[string]deletedGroup),
+		users:  make(map[string]deletedUser),
+	}
+}
+
+// DeletedPurge permanently removes every user and group that was deleted
+// before the given time, returning the number removed.
+func (da *InMemoryDataAccess) DeletedPurge(ctx context.Context, before time.Time) (int, error) {
+	count := 0
+
+	for name, d := range da.deleted.groups {
+		if d.deletedAt.Before(before) {
+			delete(da.deleted.groups, name)
+			count++
+		}
+	}
+
+	for name, d := range da.deleted.users {
+		if d.deletedAt.Before(before) {
+			da.purgeDeletedUser(name)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// purgeDeletedUser permanently removes a soft-deleted user, including their
+// lingering group memberships. It's a no-op if the user isn't deleted.
+func (da *InMemoryDataAccess) purgeDeletedUser(username string) {
+	if _, ok := da.deleted.users[username]; !ok {
+		return
+	}
+
+	groups := make([]*rest.Group, 0, len(da.groups)+len(da.deleted.groups))
+	for _, g := range da.groups {
+		groups = append(groups, g)
+	}
+	for _, d := range da.deleted.groups {
+		groups = append(groups, d.group)
+	}
+
+	for _, g := range groups {
+		users := make([]rest.User, 0, len(g.Users))
+		for _, u := range g.Users {
+			if u.Username != username {
+				users = append(users, u)
+			}
+		}
+		g.Users = users
+	}
+
+	delete(da.deleted.users, username)
+}
@@ -24,6 +24,8 @@ import (
 
 	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/getgort/gort/encryption"
+	gerr "github.com/getgort/gort/errors"
 )
 
 func (da *InMemoryDataAccess) DynamicConfigurationCreate(_ context.Context, config data.DynamicConfiguration) error {
@@ -40,6 +42,11 @@ func (da *InMemoryDataAccess) DynamicConfigurationCreate(_ context.Context, conf
 		return errs.ErrConfigExists
 	}
 
+	config, err = encryption.SealConfiguration(config)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
 	da.configs[lookupKey] = &config
 
 	return nil
@@ -92,13 +99,18 @@ func (da *InMemoryDataAccess) DynamicConfigurationGet(_ context.Context, layer d
 		return data.DynamicConfiguration{}, errs.ErrNoSuchConfig
 	}
 
-	return *dc, nil
+	c, err := encryption.OpenConfiguration(*dc)
+	if err != nil {
+		return data.DynamicConfiguration{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return c, nil
 }
 
 // DynamicConfigurationList will list matching configurations. Empty values
 // are treated as wildcards. Bundle (at a minimum) must be not empty.
 func (da *InMemoryDataAccess) DynamicConfigurationList(_ context.Context, layer data.ConfigurationLayer, bundle, owner, key string) ([]data.DynamicConfiguration, error) {
-	const wildcard = `([^\|]+)`
+	const wildcard = `([^\|]*)`
 
 	if bundle == "" {
 		return nil, errs.ErrEmptyConfigBundle
@@ -120,7 +132,11 @@ func (da *InMemoryDataAccess) DynamicConfigurationList(_ context.Context, layer
 
 	for k, v := range da.configs {
 		if p.Match([]byte(k)) {
-			cc = append(cc, *v)
+			c, err := encryption.OpenConfiguration(*v)
+			if err != nil {
+				return nil, gerr.Wrap(errs.ErrDataAccess, err)
+			}
+			cc = append(cc, c)
 		}
 	}
 
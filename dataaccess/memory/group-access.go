@@ -19,6 +19,7 @@ package memory
 import (
 	"context"
 	"sort"
+	"time"
 
 	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/dataaccess/errs"
@@ -38,12 +39,17 @@ func (da *InMemoryDataAccess) GroupCreate(ctx context.Context, group rest.Group)
 		return errs.ErrGroupExists
 	}
 
+	// Creating a group permanently discards any deleted group of the same
+	// name.
+	delete(da.deleted.groups, group.Name)
+
 	da.groups[group.Name] = &group
 
 	return nil
 }
 
-// GroupDelete delete a group.
+// GroupDelete soft-deletes a group: the group is hidden, but it may be
+// restored (along with its members and roles) using GroupRestore.
 func (da *InMemoryDataAccess) GroupDelete(ctx context.Context, groupname string) error {
 	if groupname == "" {
 		return errs.ErrEmptyGroupName
@@ -62,6 +68,7 @@ func (da *InMemoryDataAccess) GroupDelete(ctx context.Context, groupname string)
 		return errs.ErrNoSuchGroup
 	}
 
+	da.deleted.groups[groupname] = deletedGroup{da.groups[groupname], time.Now().UTC()}
 	delete(da.groups, groupname)
 
 	return nil
@@ -88,9 +95,15 @@ func (da *InMemoryDataAccess) GroupGet(ctx context.Context, groupname string) (r
 		return rest.Group{}, errs.ErrNoSuchGroup
 	}
 
-	group := da.groups[groupname]
+	group := *da.groups[groupname]
+
+	// Omit deleted members, as GroupUserList does.
+	group.Users, err = da.GroupUserList(ctx, groupname)
+	if err != nil {
+		return rest.Group{}, err
+	}
 
-	return *group, nil
+	return group, nil
 }
 
 // GroupList returns a list of all known groups in the datastore.
@@ -135,6 +148,25 @@ func (da *InMemoryDataAccess) GroupPermissionList(ctx context.Context, groupname
 	return pp, nil
 }
 
+// GroupRestore restores a group that was deleted at or after since. An
+// error is returned if the groupname parameter is empty, or if no such group
+// was deleted in that time.
+func (da *InMemoryDataAccess) GroupRestore(ctx context.Context, groupname string, since time.Time) error {
+	if groupname == "" {
+		return errs.ErrEmptyGroupName
+	}
+
+	d, ok := da.deleted.groups[groupname]
+	if !ok || d.deletedAt.Before(since) {
+		return errs.ErrNoSuchGroup
+	}
+
+	da.groups[groupname] = d.group
+	delete(da.deleted.groups, groupname)
+
+	return nil
+}
+
 func (da *InMemoryDataAccess) GroupRoleList(ctx context.Context, groupname string) ([]rest.Role, error) {
 	gr := da.groups[groupname]
 	if gr == nil {
@@ -279,5 +311,14 @@ func (da *InMemoryDataAccess) GroupUserList(ctx context.Context, groupname strin
 		return []rest.User{}, errs.ErrNoSuchGroup
 	}
 
-	return group.Users, nil
+	// Deleted users remain members (so that they can be restored), but
+	// aren't listed.
+	users := []rest.User{}
+	for _, u := range group.Users {
+		if _, ok := da.users[u.Username]; ok {
+			users = append(users, u)
+		}
+	}
+
+	return users, nil
 }
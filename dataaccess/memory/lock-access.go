@@ -0,0 +1,118 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+// locksMutex guards da.locks, since unlike most data, locks are expected to
+// be contended.
+var locksMutex sync.Mutex
+
+// LockAcquire attempts to acquire the named lock on behalf of owner for the
+// duration of ttl. If the lock is held by a different owner and hasn't yet
+// expired, the current lock is returned along with errs.ErrLockHeld.
+func (da *InMemoryDataAccess) LockAcquire(ctx context.Context, name, owner, username string, ttl time.Duration) (data.Lock, error) {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+
+	if l, ok := da.locks[name]; ok && l.Owner != owner && !l.IsExpired() {
+		return *l, errs.ErrLockHeld
+	}
+
+	now := time.Now().UTC()
+	l := &data.Lock{
+		Name:       name,
+		Owner:      owner,
+		UserName:   username,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+
+	da.locks[name] = l
+
+	return *l, nil
+}
+
+// LockGet returns the named lock. An error is returned if the lock doesn't
+// exist or has expired.
+func (da *InMemoryDataAccess) LockGet(ctx context.Context, name string) (data.Lock, error) {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+
+	l, ok := da.locks[name]
+	if !ok || l.IsExpired() {
+		return data.Lock{}, errs.ErrNoSuchLock
+	}
+
+	return *l, nil
+}
+
+// LockPurge deletes every lock that expired before the given time, returning
+// the number deleted.
+func (da *InMemoryDataAccess) LockPurge(ctx context.Context, before time.Time) (int, error) {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+
+	n := 0
+	for name, l := range da.locks {
+		if l.ExpiresAt.Before(before) {
+			delete(da.locks, name)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// LockRelease releases the named lock. An error is returned if the lock
+// isn't held by owner.
+func (da *InMemoryDataAccess) LockRelease(ctx context.Context, name, owner string) error {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+
+	l, ok := da.locks[name]
+	if !ok || l.Owner != owner {
+		return errs.ErrNoSuchLock
+	}
+
+	delete(da.locks, name)
+
+	return nil
+}
+
+// LockRenew extends the expiry of the named lock to ttl from now. An error
+// is returned if the lock isn't held by owner.
+func (da *InMemoryDataAccess) LockRenew(ctx context.Context, name, owner string, ttl time.Duration) (data.Lock, error) {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+
+	l, ok := da.locks[name]
+	if !ok || l.Owner != owner {
+		return data.Lock{}, errs.ErrNoSuchLock
+	}
+
+	l.ExpiresAt = time.Now().UTC().Add(ttl)
+
+	return *l, nil
+}
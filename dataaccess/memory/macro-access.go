@@ -0,0 +1,103 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+// MacroDelete removes a macro.
+func (da *InMemoryDataAccess) MacroDelete(ctx context.Context, layer data.ConfigurationLayer, owner, name string) error {
+	m := data.Macro{Layer: layer, Owner: owner, Name: name}
+
+	key, err := macroKey(&m)
+	if err != nil {
+		return err
+	}
+
+	if da.macros[key] == nil {
+		return errs.ErrNoSuchMacro
+	}
+
+	delete(da.macros, key)
+
+	return nil
+}
+
+// MacroList returns all macros, at every layer, with the given name. If name
+// is empty, all macros are returned. The results are sorted by name, layer,
+// and owner.
+func (da *InMemoryDataAccess) MacroList(ctx context.Context, name string) ([]data.Macro, error) {
+	list := []data.Macro{}
+	for _, m := range da.macros {
+		if name == "" || m.Name == name {
+			list = append(list, *m)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Layer != b.Layer {
+			return a.Layer < b.Layer
+		}
+		return a.Owner < b.Owner
+	})
+
+	return list, nil
+}
+
+// MacroSave creates or replaces a macro.
+func (da *InMemoryDataAccess) MacroSave(ctx context.Context, macro data.Macro) error {
+	key, err := macroKey(&macro)
+	if err != nil {
+		return err
+	}
+
+	if err := macro.Validate(); err != nil {
+		return err
+	}
+
+	da.macros[key] = &macro
+
+	return nil
+}
+
+// macroKey validates and normalizes m, and returns its map key.
+func macroKey(m *data.Macro) (string, error) {
+	if err := data.ValidateMacroLayer(m.Layer); err != nil {
+		return "", err
+	}
+
+	m.Layer = data.ConfigurationLayer(strings.ToLower(string(m.Layer)))
+
+	switch {
+	case m.Name == "":
+		return "", errs.ErrEmptyMacroName
+	case m.Owner == "":
+		return "", errs.ErrEmptyMacroOwner
+	}
+
+	return strings.Join([]string{string(m.Layer), m.Owner, m.Name}, "|"), nil
+}
@@ -24,21 +24,47 @@ import (
 )
 
 var dataAccess = &InMemoryDataAccess{
-	bundles: make(map[string]*data.Bundle),
-	configs: make(map[string]*data.DynamicConfiguration),
-	groups:  make(map[string]*rest.Group),
-	roles:   make(map[string]*rest.Role),
-	users:   make(map[string]*rest.User),
+	aliases:     make(map[string]*data.Alias),
+	audit:       []*data.AuditRecord{},
+	bundles:     make(map[string]*data.Bundle),
+	channels:    make(map[string]*data.ChannelPresence),
+	chansets:    make(map[string]*data.ChannelSettings),
+	configs:     make(map[string]*data.DynamicConfiguration),
+	costs:       make(map[string]*data.CostRecord),
+	deadletters: make(map[int64]*data.DeadLetter),
+	defaults:    make(map[string]*data.OptionDefault),
+	deleted:     newDeletedEntities(),
+	groups:      make(map[string]*rest.Group),
+	locks:       make(map[string]*data.Lock),
+	macros:      make(map[string]*data.Macro),
+	outbox:      make(map[int64]*data.OutboxEntry),
+	requests:    make(map[int64]*data.RequestRecord),
+	roles:       make(map[string]*rest.Role),
+	secrets:     make(map[string]*data.SecretOutput),
+	users:       make(map[string]*rest.User),
 }
 
 // InMemoryDataAccess is an entirely in-memory representation of a data access layer.
 // Great for testing and development. Terrible for production.
 type InMemoryDataAccess struct {
-	bundles map[string]*data.Bundle
-	configs map[string]*data.DynamicConfiguration
-	groups  map[string]*rest.Group
-	roles   map[string]*rest.Role
-	users   map[string]*rest.User
+	aliases     map[string]*data.Alias
+	audit       []*data.AuditRecord
+	bundles     map[string]*data.Bundle
+	channels    map[string]*data.ChannelPresence
+	chansets    map[string]*data.ChannelSettings
+	configs     map[string]*data.DynamicConfiguration
+	costs       map[string]*data.CostRecord
+	deadletters map[int64]*data.DeadLetter
+	defaults    map[string]*data.OptionDefault
+	deleted     deletedEntities
+	groups      map[string]*rest.Group
+	locks       map[string]*data.Lock
+	macros      map[string]*data.Macro
+	outbox      map[int64]*data.OutboxEntry
+	requests    map[int64]*data.RequestRecord
+	roles       map[string]*rest.Role
+	secrets     map[string]*data.SecretOutput
+	users       map[string]*rest.User
 }
 
 // NewInMemoryDataAccess returns a new InMemoryDataAccess instance.
@@ -46,15 +72,30 @@ func NewInMemoryDataAccess() *InMemoryDataAccess {
 	return dataAccess
 }
 
-// Initialize initializes an InMemoryDataAccess instance.
+// Initialize initializes an InMemoryDataAccess instance. If a snapshot file
+// is configured in the memory_store section, the data is restored from it
+// and periodically written back to it.
 func (da *InMemoryDataAccess) Initialize(ctx context.Context) error {
-	return nil
+	return da.startSnapshots(ctx)
 }
 
 func Reset() {
+	dataAccess.aliases = make(map[string]*data.Alias)
+	dataAccess.audit = []*data.AuditRecord{}
 	dataAccess.bundles = make(map[string]*data.Bundle)
+	dataAccess.channels = make(map[string]*data.ChannelPresence)
+	dataAccess.chansets = make(map[string]*data.ChannelSettings)
 	dataAccess.configs = make(map[string]*data.DynamicConfiguration)
+	dataAccess.costs = make(map[string]*data.CostRecord)
+	dataAccess.deadletters = make(map[int64]*data.DeadLetter)
+	dataAccess.defaults = make(map[string]*data.OptionDefault)
+	dataAccess.deleted = newDeletedEntities()
 	dataAccess.groups = make(map[string]*rest.Group)
+	dataAccess.locks = make(map[string]*data.Lock)
+	dataAccess.macros = make(map[string]*data.Macro)
+	dataAccess.outbox = make(map[int64]*data.OutboxEntry)
+	dataAccess.requests = make(map[int64]*data.RequestRecord)
 	dataAccess.roles = make(map[string]*rest.Role)
+	dataAccess.secrets = make(map[string]*data.SecretOutput)
 	dataAccess.users = make(map[string]*rest.User)
 }
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+// OptionDefaultDelete removes an option default.
+func (da *InMemoryDataAccess) OptionDefaultDelete(ctx context.Context, layer data.ConfigurationLayer, owner, bundle, command, option string) error {
+	def := data.OptionDefault{Layer: layer, Owner: owner, Bundle: bundle, Command: command, Option: option}
+
+	key, err := optionDefaultKey(&def)
+	if err != nil {
+		return err
+	}
+
+	if da.defaults[key] == nil {
+		return errs.ErrNoSuchOptionDefault
+	}
+
+	delete(da.defaults, key)
+
+	return nil
+}
+
+// OptionDefaultList returns all option defaults, at every layer, for a
+// command. The results are sorted by layer, owner, and option.
+func (da *InMemoryDataAccess) OptionDefaultList(ctx context.Context, bundle, command string) ([]data.OptionDefault, error) {
+	if bundle == "" || command == "" {
+		return nil, errs.ErrEmptyOptionDefaultCommand
+	}
+
+	list := []data.OptionDefault{}
+	for _, d := range da.defaults {
+		if d.Bundle == bundle && d.Command == command {
+			list = append(list, *d)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Layer != b.Layer {
+			return a.Layer < b.Layer
+		}
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		return a.Option < b.Option
+	})
+
+	return list, nil
+}
+
+// OptionDefaultSet creates or replaces an option default.
+func (da *InMemoryDataAccess) OptionDefaultSet(ctx context.Context, def data.OptionDefault) error {
+	key, err := optionDefaultKey(&def)
+	if err != nil {
+		return err
+	}
+
+	da.defaults[key] = &def
+
+	return nil
+}
+
+// optionDefaultKey validates and normalizes def, and returns its map key.
+func optionDefaultKey(def *data.OptionDefault) (string, error) {
+	if err := data.ValidateOptionDefaultLayer(def.Layer); err != nil {
+		return "", err
+	}
+
+	def.Layer = data.ConfigurationLayer(strings.ToLower(string(def.Layer)))
+	if def.Layer == data.LayerBundle {
+		def.Owner = ""
+	}
+
+	switch {
+	case def.Bundle == "" || def.Command == "":
+		return "", errs.ErrEmptyOptionDefaultCommand
+	case def.Option == "":
+		return "", errs.ErrEmptyOptionDefaultOption
+	case def.Owner == "" && def.Layer != data.LayerBundle:
+		return "", errs.ErrEmptyOptionDefaultOwner
+	}
+
+	return strings.Join([]string{string(def.Layer), def.Owner, def.Bundle, def.Command, def.Option}, "|"), nil
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// outboxMutex guards da.outbox. Entries are created by the relay and
+// delivered by the adapters concurrently.
+var outboxMutex sync.Mutex
+
+// OutboxCreate stores a response before it's sent. If an entry already
+// exists for the request, it's left unchanged.
+func (da *InMemoryDataAccess) OutboxCreate(ctx context.Context, entry data.OutboxEntry) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.OutboxCreate")
+	defer sp.End()
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+
+	if _, ok := da.outbox[entry.RequestID]; ok {
+		return nil
+	}
+
+	da.outbox[entry.RequestID] = &entry
+
+	return nil
+}
+
+// OutboxGet returns the outbox entry for a request.
+func (da *InMemoryDataAccess) OutboxGet(ctx context.Context, requestID int64) (data.OutboxEntry, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.OutboxGet")
+	defer sp.End()
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+
+	e, ok := da.outbox[requestID]
+	if !ok {
+		return data.OutboxEntry{}, errs.ErrNoSuchOutboxEntry
+	}
+
+	return *e, nil
+}
+
+// OutboxMarkAttempted records a delivery attempt, incrementing the entry's
+// attempt count and setting its last attempt time.
+func (da *InMemoryDataAccess) OutboxMarkAttempted(ctx context.Context, requestID int64, at time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.OutboxMarkAttempted")
+	defer sp.End()
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+
+	e, ok := da.outbox[requestID]
+	if !ok {
+		return errs.ErrNoSuchOutboxEntry
+	}
+
+	e.Attempts++
+	e.LastAttempt = at
+
+	return nil
+}
+
+// OutboxMarkDelivered marks an entry as delivered, so that it's no longer
+// resent. Marking an entry that's already delivered has no effect.
+func (da *InMemoryDataAccess) OutboxMarkDelivered(ctx context.Context, requestID int64, at time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.OutboxMarkDelivered")
+	defer sp.End()
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+
+	e, ok := da.outbox[requestID]
+	if !ok {
+		return errs.ErrNoSuchOutboxEntry
+	}
+
+	if e.Delivered.IsZero() {
+		e.Delivered = at
+	}
+
+	return nil
+}
+
+// OutboxPending returns the undelivered entries whose most recent attempt
+// was before the given time, oldest first.
+func (da *InMemoryDataAccess) OutboxPending(ctx context.Context, before time.Time) ([]data.OutboxEntry, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.OutboxPending")
+	defer sp.End()
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+
+	list := []data.OutboxEntry{}
+	for _, e := range da.outbox {
+		if e.Delivered.IsZero() && e.LastAttempt.Before(before) {
+			list = append(list, *e)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Created.Before(list[j].Created) })
+
+	return list, nil
+}
+
+// OutboxPurge deletes every entry delivered before the given time,
+// returning the number deleted.
+func (da *InMemoryDataAccess) OutboxPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.OutboxPurge")
+	defer sp.End()
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+
+	n := 0
+	for id, e := range da.outbox {
+		if !e.Delivered.IsZero() && e.Delivered.Before(before) {
+			delete(da.outbox, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -19,13 +19,65 @@ package memory
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
+	"github.com/getgort/gort/config"
 	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
 	"github.com/getgort/gort/telemetry"
 	"go.opentelemetry.io/otel"
 )
 
-// Will not implement
+var (
+	// requestsMutex guards da.requests and lastRequestID. Requests are
+	// begun and closed concurrently by the adapters and the relay.
+	requestsMutex sync.Mutex
+
+	lastRequestID int64
+)
+
+// RequestArchive stores the response payload of a request.
+func (da *InMemoryDataAccess) RequestArchive(ctx context.Context, requestID int64, payload data.RequestPayload) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.RequestArchive")
+	defer sp.End()
+
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	r, ok := da.requests[requestID]
+	if !ok {
+		return errs.ErrNoSuchRequest
+	}
+
+	r.Payload = copyRequestPayload(&payload)
+
+	return nil
+}
+
+// RequestArchivePurge deletes every payload archived before the given time,
+// returning the number deleted.
+func (da *InMemoryDataAccess) RequestArchivePurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.RequestArchivePurge")
+	defer sp.End()
+
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	count := 0
+	for _, r := range da.requests {
+		if r.Payload != nil && r.Payload.ArchivedAt.Before(before) {
+			r.Payload = nil
+			count++
+		}
+	}
+
+	return count, nil
+}
+
 func (da *InMemoryDataAccess) RequestBegin(ctx context.Context, req *data.CommandRequest) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	_, sp := tr.Start(ctx, "memory.RequestBegin")
@@ -35,25 +87,130 @@ func (da *InMemoryDataAccess) RequestBegin(ctx context.Context, req *data.Comman
 		return fmt.Errorf("command request ID already set")
 	}
 
-	req.RequestID++
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	lastRequestID++
+	req.RequestID = lastRequestID
+
+	r := &data.RequestRecord{}
+	setRequestRecord(r, *req)
+	da.requests[req.RequestID] = r
 
 	return nil
 }
 
-// Will not implement
 func (da *InMemoryDataAccess) RequestError(ctx context.Context, result data.CommandRequest, err error) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	_, sp := tr.Start(ctx, "memory.RequestUpdate")
 	defer sp.End()
 
-	if result.RequestID == 0 {
-		return fmt.Errorf("command request ID unset")
+	return da.RequestClose(ctx, data.NewCommandResponseEnvelope(result, data.WithError("", err, 1)))
+}
+
+// RequestGet returns the record of a single request.
+func (da *InMemoryDataAccess) RequestGet(ctx context.Context, requestID int64) (data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.RequestGet")
+	defer sp.End()
+
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	r, ok := da.requests[requestID]
+	if !ok {
+		return data.RequestRecord{}, errs.ErrNoSuchRequest
+	}
+
+	return copyRequestRecord(r), nil
+}
+
+// RequestHistory returns the records of up to limit of the most recent
+// commands that the user made in the channel, newest first. Requests that
+// never matched a command, and replays, are excluded. If limit is zero or
+// less all matching records are returned.
+func (da *InMemoryDataAccess) RequestHistory(ctx context.Context, adapter, channelID, userID string, limit int) ([]data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.RequestHistory")
+	defer sp.End()
+
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	list := []data.RequestRecord{}
+	for _, r := range da.requests {
+		if r.Adapter != adapter || r.ChannelID != channelID || r.UserID != userID {
+			continue
+		}
+		if r.CommandName == "" || r.ReplayOf != 0 {
+			continue
+		}
+
+		c := copyRequestRecord(r)
+		c.Payload = nil
+		c.Logs = nil
+		list = append(list, c)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].RequestID > list[j].RequestID
+	})
+
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
 	}
 
+	return list, nil
+}
+
+// RequestList returns the records of up to limit of the most recent
+// requests, newest first. If limit is zero or less all records are returned.
+func (da *InMemoryDataAccess) RequestList(ctx context.Context, limit int) ([]data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.RequestList")
+	defer sp.End()
+
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	list := make([]data.RequestRecord, 0, len(da.requests))
+	for _, r := range da.requests {
+		c := copyRequestRecord(r)
+		c.Payload = nil
+		c.Logs = nil
+		list = append(list, c)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].RequestID > list[j].RequestID
+	})
+
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+
+	return list, nil
+}
+
+// RequestLogAppend attaches a log entry to a request.
+func (da *InMemoryDataAccess) RequestLogAppend(ctx context.Context, requestID int64, entry data.RequestLogEntry) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.RequestLogAppend")
+	defer sp.End()
+
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	r, ok := da.requests[requestID]
+	if !ok {
+		return errs.ErrNoSuchRequest
+	}
+
+	r.Logs = append(r.Logs, copyRequestLogEntry(entry))
+
 	return nil
 }
 
-// Will not implement
 func (da *InMemoryDataAccess) RequestUpdate(ctx context.Context, result data.CommandRequest) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	_, sp := tr.Start(ctx, "memory.RequestUpdate")
@@ -63,10 +220,36 @@ func (da *InMemoryDataAccess) RequestUpdate(ctx context.Context, result data.Com
 		return fmt.Errorf("command request ID unset")
 	}
 
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	if r, ok := da.requests[result.RequestID]; ok {
+		setRequestRecord(r, result)
+	}
+
+	return nil
+}
+
+// RequestUpdateTimings replaces the stage timings of a request.
+func (da *InMemoryDataAccess) RequestUpdateTimings(ctx context.Context, requestID int64, timings data.StageTimings) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.RequestUpdateTimings")
+	defer sp.End()
+
+	if requestID == 0 {
+		return fmt.Errorf("command request ID unset")
+	}
+
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	if r, ok := da.requests[requestID]; ok {
+		r.Timings = copyStageTimings(timings)
+	}
+
 	return nil
 }
 
-// Will not implement
 func (da *InMemoryDataAccess) RequestClose(ctx context.Context, envelope data.CommandResponseEnvelope) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	_, sp := tr.Start(ctx, "memory.RequestClose")
@@ -76,5 +259,86 @@ func (da *InMemoryDataAccess) RequestClose(ctx context.Context, envelope data.Co
 		return fmt.Errorf("command request ID unset")
 	}
 
+	requestsMutex.Lock()
+	defer requestsMutex.Unlock()
+
+	r, ok := da.requests[envelope.Request.RequestID]
+	if !ok {
+		return nil
+	}
+
+	setRequestRecord(r, envelope.Request)
+	r.Duration = envelope.Data.Duration
+	r.Closed = true
+	r.ExitCode = envelope.Data.ExitCode
+	r.Error = ""
+	if envelope.Data.Error != nil {
+		r.Error = envelope.Data.Error.Error()
+	}
+
 	return nil
 }
+
+// setRequestRecord copies the request's fields into the record.
+func setRequestRecord(r *data.RequestRecord, req data.CommandRequest) {
+	r.RequestID = req.RequestID
+	r.Timestamp = req.Timestamp
+	r.Adapter = req.Adapter
+	r.ChannelID = req.ChannelID
+	r.UserID = req.UserID
+	r.UserEmail = req.UserEmail
+	r.UserName = req.UserName
+	r.BundleName = req.Bundle.Name
+	r.BundleVersion = req.Bundle.Version
+	r.CommandName = req.Command.Name
+	r.Parameters = req.RedactedParameters(config.GetRedactPatterns()...).String()
+	r.ReplayOf = req.ReplayOf
+	r.Timings = copyStageTimings(req.Timings)
+}
+
+func copyRequestRecord(r *data.RequestRecord) data.RequestRecord {
+	c := *r
+	c.Timings = copyStageTimings(r.Timings)
+	c.Payload = copyRequestPayload(r.Payload)
+
+	c.Logs = nil
+	for _, e := range r.Logs {
+		c.Logs = append(c.Logs, copyRequestLogEntry(e))
+	}
+
+	return c
+}
+
+func copyRequestLogEntry(e data.RequestLogEntry) data.RequestLogEntry {
+	if e.Fields != nil {
+		fields := make(map[string]string, len(e.Fields))
+		for k, v := range e.Fields {
+			fields[k] = v
+		}
+		e.Fields = fields
+	}
+
+	return e
+}
+
+func copyRequestPayload(p *data.RequestPayload) *data.RequestPayload {
+	if p == nil {
+		return nil
+	}
+
+	c := *p
+	c.Output = append([]string(nil), p.Output...)
+	return &c
+}
+
+func copyStageTimings(t data.StageTimings) data.StageTimings {
+	if t == nil {
+		return nil
+	}
+
+	c := data.StageTimings{}
+	for k, v := range t {
+		c[k] = v
+	}
+	return c
+}
@@ -24,6 +24,25 @@ import (
 	"github.com/getgort/gort/dataaccess/errs"
 )
 
+// RoleClone creates a new role named clonename that has been granted the same
+// permissions as rolename. Group memberships aren't copied.
+func (da *InMemoryDataAccess) RoleClone(ctx context.Context, rolename, clonename string) error {
+	if rolename == "" || clonename == "" {
+		return errs.ErrEmptyRoleName
+	}
+
+	role, ok := da.roles[rolename]
+	if !ok {
+		return errs.ErrNoSuchRole
+	}
+
+	if err := da.RoleCreate(ctx, clonename); err != nil {
+		return err
+	}
+
+	return da.RolePermissionAddBatch(ctx, clonename, role.Permissions)
+}
+
 // RoleCreate creates a new role.
 func (da *InMemoryDataAccess) RoleCreate(ctx context.Context, rolename string) error {
 	if rolename == "" {
@@ -139,7 +158,15 @@ func (da *InMemoryDataAccess) RoleGroupList(ctx context.Context, rolename string
 	if !ok {
 		return nil, errs.ErrNoSuchRole
 	}
-	return role.Groups, nil
+
+	groups := make([]rest.Group, 0, len(role.Groups))
+	for _, g := range role.Groups {
+		if _, ok := da.groups[g.Name]; ok {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups, nil
 }
 
 func (da *InMemoryDataAccess) RolePermissionAdd(ctx context.Context, rolename, bundlename, permission string) error {
@@ -153,6 +180,38 @@ func (da *InMemoryDataAccess) RolePermissionAdd(ctx context.Context, rolename, b
 	return nil
 }
 
+// RolePermissionAddBatch grants all of the given permissions to a role.
+// Permissions that the role has already been granted are ignored.
+func (da *InMemoryDataAccess) RolePermissionAddBatch(ctx context.Context, rolename string, permissions rest.RolePermissionList) error {
+	role, ok := da.roles[rolename]
+	if !ok {
+		return errs.ErrNoSuchRole
+	}
+
+	for _, p := range permissions {
+		if p.BundleName == "" {
+			return errs.ErrEmptyBundleName
+		}
+		if p.Permission == "" {
+			return errs.ErrEmptyPermission
+		}
+	}
+
+	granted := map[rest.RolePermission]bool{}
+	for _, p := range role.Permissions {
+		granted[p] = true
+	}
+
+	for _, p := range permissions {
+		if !granted[p] {
+			role.Permissions = append(role.Permissions, p)
+			granted[p] = true
+		}
+	}
+
+	return nil
+}
+
 func (da *InMemoryDataAccess) RolePermissionDelete(ctx context.Context, rolename, bundlename, permission string) error {
 	role, ok := da.roles[rolename]
 
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// secretsMutex guards da.secrets, so that each secret output can only be
+// redeemed once.
+var secretsMutex sync.Mutex
+
+// SecretOutputCreate stores a secret output.
+func (da *InMemoryDataAccess) SecretOutputCreate(ctx context.Context, output data.SecretOutput) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.SecretOutputCreate")
+	defer sp.End()
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	output.Output = append([]string{}, output.Output...)
+	da.secrets[output.TokenHash] = &output
+
+	return nil
+}
+
+// SecretOutputPurge deletes every secret output that expired before the
+// given time, returning the number deleted.
+func (da *InMemoryDataAccess) SecretOutputPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.SecretOutputPurge")
+	defer sp.End()
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	n := 0
+	for h, s := range da.secrets {
+		if s.Expires.Before(before) {
+			delete(da.secrets, h)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// SecretOutputRedeem deletes and returns the secret output with the given
+// token hash. An output that has expired can't be redeemed.
+func (da *InMemoryDataAccess) SecretOutputRedeem(ctx context.Context, tokenHash string) (data.SecretOutput, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "memory.SecretOutputRedeem")
+	defer sp.End()
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	s, ok := da.secrets[tokenHash]
+	if !ok {
+		return data.SecretOutput{}, errs.ErrNoSuchSecretOutput
+	}
+
+	delete(da.secrets, tokenHash)
+
+	if s.Expired() {
+		return data.SecretOutput{}, errs.ErrNoSuchSecretOutput
+	}
+
+	return *s, nil
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// snapshotVersion is the version of the snapshot file format.
+const snapshotVersion = 1
+
+var (
+	// snapshotMutex guards the snapshotter state below.
+	snapshotMutex sync.Mutex
+
+	// snapshotCancel stops the running snapshotter, if there is one.
+	snapshotCancel context.CancelFunc
+
+	// snapshotRestored is the path of the last snapshot restored, so that a
+	// configuration reload doesn't overwrite newer data with it.
+	snapshotRestored string
+
+	// lastSnapshot is the content of the last snapshot written, so that
+	// unchanged data isn't rewritten.
+	lastSnapshot []byte
+)
+
+// snapshot is the persisted form of the durable parts of the in-memory
+// data store. Requests, locks, and other transient state aren't included.
+type snapshot struct {
+	Version  int                                   `json:"version"`
+	Taken    time.Time                             `json:"taken"`
+	Aliases  map[string]*data.Alias                `json:"aliases,omitempty"`
+	Bundles  map[string]*data.Bundle               `json:"bundles,omitempty"`
+	Chansets map[string]*data.ChannelSettings      `json:"channel_settings,omitempty"`
+	Configs  map[string]*data.DynamicConfiguration `json:"configs,omitempty"`
+	Defaults map[string]*data.OptionDefault        `json:"option_defaults,omitempty"`
+	Groups   map[string]*rest.Group                `json:"groups,omitempty"`
+	Macros   map[string]*data.Macro                `json:"macros,omitempty"`
+	Revoked  map[string]time.Time                  `json:"revoked_tokens,omitempty"`
+	Roles    map[string]*rest.Role                 `json:"roles,omitempty"`
+	Tokens   map[string]rest.Token                 `json:"tokens,omitempty"`
+	Users    map[string]*rest.User                 `json:"users,omitempty"`
+}
+
+// WriteSnapshot atomically writes the durable parts of the data store to
+// path as JSON: the snapshot is written to a temporary file in the same
+// directory, which then replaces path. The file is only readable by its
+// owner, since it contains passwords and tokens.
+func (da *InMemoryDataAccess) WriteSnapshot(path string) error {
+	s := da.snapshot()
+	s.Taken = time.Now().UTC()
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, b)
+}
+
+// snapshot returns the durable parts of the data store, without a
+// timestamp.
+func (da *InMemoryDataAccess) snapshot() snapshot {
+	return snapshot{
+		Version:  snapshotVersion,
+		Aliases:  da.aliases,
+		Bundles:  da.bundles,
+		Chansets: da.chansets,
+		Configs:  da.configs,
+		Defaults: da.defaults,
+		Groups:   da.groups,
+		Macros:   da.macros,
+		Revoked:  revokedTokens,
+		Roles:    da.roles,
+		Tokens:   tokensByUser,
+		Users:    da.users,
+	}
+}
+
+// ReadSnapshot replaces the durable parts of the data store with those in
+// the snapshot at path. If there's no file at path the store is unchanged
+// and the returned error satisfies errors.Is(err, os.ErrNotExist).
+func (da *InMemoryDataAccess) ReadSnapshot(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Sections missing from the file are restored as empty.
+	s := snapshot{
+		Aliases:  make(map[string]*data.Alias),
+		Bundles:  make(map[string]*data.Bundle),
+		Chansets: make(map[string]*data.ChannelSettings),
+		Configs:  make(map[string]*data.DynamicConfiguration),
+		Defaults: make(map[string]*data.OptionDefault),
+		Groups:   make(map[string]*rest.Group),
+		Macros:   make(map[string]*data.Macro),
+		Revoked:  make(map[string]time.Time),
+		Roles:    make(map[string]*rest.Role),
+		Tokens:   make(map[string]rest.Token),
+		Users:    make(map[string]*rest.User),
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("malformed snapshot %s: %w", path, err)
+	}
+	if s.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+
+	// Command names aren't serialized; they're the keys of the commands map.
+	for _, bundle := range s.Bundles {
+		for name, cmd := range bundle.Commands {
+			cmd.Name = name
+		}
+	}
+
+	da.aliases = s.Aliases
+	da.bundles = s.Bundles
+	da.chansets = s.Chansets
+	da.configs = s.Configs
+	da.defaults = s.Defaults
+	da.groups = s.Groups
+	da.macros = s.Macros
+	da.roles = s.Roles
+	da.users = s.Users
+
+	tokensByUser = make(map[string]rest.Token)
+	tokensByValue = make(map[string]rest.Token)
+	for _, token := range s.Tokens {
+		tokensByUser[token.User] = token
+		tokensByValue[token.Token] = token
+	}
+	revokedTokens = s.Revoked
+
+	return nil
+}
+
+// startSnapshots restores the configured snapshot, if it hasn't already
+// been restored, and starts writing snapshots at the configured interval
+// until ctx is done, when a final snapshot is written. Any snapshotter
+// started by an earlier call is stopped first.
+func (da *InMemoryDataAccess) startSnapshots(ctx context.Context) error {
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+
+	if snapshotCancel != nil {
+		snapshotCancel()
+		snapshotCancel = nil
+	}
+
+	mc := config.GetMemoryStoreConfigs()
+	if mc.SnapshotFile == "" {
+		return nil
+	}
+
+	if snapshotRestored != mc.SnapshotFile {
+		err := da.ReadSnapshot(mc.SnapshotFile)
+		switch {
+		case err == nil:
+			log.WithField("file", mc.SnapshotFile).Info("Restored in-memory data from snapshot")
+		case errors.Is(err, os.ErrNotExist):
+			log.WithField("file", mc.SnapshotFile).Info("No snapshot found: starting with empty in-memory data")
+		default:
+			return err
+		}
+
+		snapshotRestored = mc.SnapshotFile
+	}
+
+	ctx, snapshotCancel = context.WithCancel(ctx)
+	go da.snapshotLoop(ctx, mc.SnapshotFile, mc.SnapshotIntervalOrDefault())
+
+	return nil
+}
+
+// snapshotLoop writes a snapshot to path every interval until ctx is done,
+// and once more when it is.
+func (da *InMemoryDataAccess) snapshotLoop(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			da.snapshotIfChanged(path)
+		case <-ctx.Done():
+			da.snapshotIfChanged(path)
+			return
+		}
+	}
+}
+
+// snapshotIfChanged writes a snapshot to path unless the data is unchanged
+// since the last one was written. Failures are logged.
+func (da *InMemoryDataAccess) snapshotIfChanged(path string) {
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+
+	// JSON objects are written with sorted keys, so unchanged data always
+	// encodes the same way.
+	b, err := json.Marshal(da.snapshot())
+	if err != nil {
+		log.WithError(err).Error("Failed to encode in-memory data snapshot")
+		return
+	}
+	if bytes.Equal(b, lastSnapshot) {
+		return
+	}
+
+	if err := da.WriteSnapshot(path); err != nil {
+		log.WithError(err).WithField("file", path).Error("Failed to write in-memory data snapshot")
+		return
+	}
+
+	lastSnapshot = b
+}
+
+// writeFileAtomic writes b to a temporary file in the same directory as
+// path, and then renames it to path, so that readers of path never see a
+// partially written file.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	ctx := context.Background()
+	da := NewInMemoryDataAccess()
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	require.NoError(t, da.UserCreate(ctx, rest.User{Username: "alice", Password: "secret"}))
+	require.NoError(t, da.BundleCreate(ctx, data.Bundle{
+		GortBundleVersion: 1,
+		Name:              "test",
+		Version:           "0.0.1",
+		Description:       "A test bundle",
+		Commands: map[string]*data.BundleCommand{
+			"echo": {Name: "echo", Executable: []string{"/bin/echo"}, Rules: []string{"allow"}},
+		},
+	}))
+	token, err := da.TokenGenerate(ctx, "alice", time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, da.WriteSnapshot(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Only the snapshot itself is left behind.
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	Reset()
+	tokensByUser = make(map[string]rest.Token)
+	tokensByValue = make(map[string]rest.Token)
+
+	require.NoError(t, da.ReadSnapshot(path))
+
+	user, err := da.UserGet(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", user.Username)
+
+	authenticated, err := da.UserAuthenticate(ctx, "alice", "secret")
+	assert.NoError(t, err)
+	assert.True(t, authenticated)
+
+	bundle, err := da.BundleGet(ctx, "test", "0.0.1")
+	assert.NoError(t, err)
+	if assert.Contains(t, bundle.Commands, "echo") {
+		assert.Equal(t, "echo", bundle.Commands["echo"].Name)
+	}
+
+	assert.True(t, da.TokenEvaluate(ctx, token.Token))
+}
+
+func TestSnapshotMissing(t *testing.T) {
+	da := NewInMemoryDataAccess()
+
+	err := da.ReadSnapshot(filepath.Join(t.TempDir(), "missing.json"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
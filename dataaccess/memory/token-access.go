@@ -28,11 +28,13 @@ import (
 var (
 	tokensByUser  map[string]rest.Token // key=username
 	tokensByValue map[string]rest.Token // key=token
+	revokedTokens map[string]time.Time  // key=token ID
 )
 
 func init() {
 	tokensByUser = make(map[string]rest.Token)
 	tokensByValue = make(map[string]rest.Token)
+	revokedTokens = make(map[string]time.Time)
 }
 
 // TokenEvaluate will test a token for validity. It returns true if the token
@@ -100,6 +102,28 @@ func (da *InMemoryDataAccess) TokenInvalidate(ctx context.Context, tokenString s
 	return nil
 }
 
+// TokenPurge deletes every token and token revocation that expired before
+// the given time, returning the number deleted.
+func (da *InMemoryDataAccess) TokenPurge(ctx context.Context, before time.Time) (int, error) {
+	n := 0
+	for value, token := range tokensByValue {
+		if token.ValidUntil.Before(before) {
+			delete(tokensByUser, token.User)
+			delete(tokensByValue, value)
+			n++
+		}
+	}
+
+	for id, until := range revokedTokens {
+		if until.Before(before) {
+			delete(revokedTokens, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
 // TokenRetrieveByUser retrieves the token associated with a username. An
 // error is returned if no such token (or user) exists.
 func (da *InMemoryDataAccess) TokenRetrieveByUser(ctx context.Context, username string) (rest.Token, error) {
@@ -119,3 +143,23 @@ func (da *InMemoryDataAccess) TokenRetrieveByToken(ctx context.Context, tokenStr
 
 	return rest.Token{}, errs.ErrNoSuchToken
 }
+
+// TokenRevoke adds the ID of a self-contained token, like a JWT, to the
+// revocation list. It's kept there until the given time, after which the
+// token will have expired anyway. Revoking a token more than once isn't an
+// error.
+func (da *InMemoryDataAccess) TokenRevoke(ctx context.Context, id string, until time.Time) error {
+	if id == "" {
+		return errs.ErrNoSuchToken
+	}
+
+	revokedTokens[id] = until
+
+	return nil
+}
+
+// TokenRevoked returns true if the token ID is on the revocation list.
+func (da *InMemoryDataAccess) TokenRevoked(ctx context.Context, id string) (bool, error) {
+	_, ok := revokedTokens[id]
+	return ok, nil
+}
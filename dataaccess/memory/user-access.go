@@ -19,12 +19,15 @@ package memory
 import (
 	"context"
 	"sort"
+	"time"
 
+	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/dataaccess/errs"
 )
 
-// UserAuthenticate authenticates a username/password combination.
+// UserAuthenticate authenticates a username/password combination. Service
+// accounts have no password, so they never authenticate.
 func (da *InMemoryDataAccess) UserAuthenticate(ctx context.Context, username string, password string) (bool, error) {
 	exists, err := da.UserExists(ctx, username)
 	if err != nil {
@@ -39,11 +42,12 @@ func (da *InMemoryDataAccess) UserAuthenticate(ctx context.Context, username str
 		return false, err
 	}
 
-	return password == user.Password, nil
+	return !user.ServiceAccount && password == user.Password, nil
 }
 
 // UserCreate is used to create a new Gort user in the data store. An error is
-// returned if the username is empty or if a user already exists.
+// returned if the username is empty, if a user already exists, or if a service
+// account has a password or mappings.
 func (da *InMemoryDataAccess) UserCreate(ctx context.Context, user rest.User) error {
 	if user.Username == "" {
 		return errs.ErrEmptyUserName
@@ -57,17 +61,25 @@ func (da *InMemoryDataAccess) UserCreate(ctx context.Context, user rest.User) er
 		return errs.ErrUserExists
 	}
 
+	if user.ServiceAccount && (user.Password != "" || len(user.Mappings) > 0) {
+		return errs.ErrServiceAccountCredentials
+	}
+
 	if user.Mappings == nil {
 		user.Mappings = map[string]string{}
 	}
 
+	// Creating a user permanently discards any deleted user of the same name.
+	da.purgeDeletedUser(user.Username)
+
 	da.users[user.Username] = &user
 
 	return nil
 }
 
-// UserDelete deletes an existing user from the data store. An error is
-// returned if the username parameter is empty of if the user doesn't
+// UserDelete soft-deletes an existing user: the user is hidden and their
+// token is invalidated, but they may be restored using UserRestore. An error
+// is returned if the username parameter is empty of if the user doesn't
 // exist.
 func (da *InMemoryDataAccess) UserDelete(ctx context.Context, username string) error {
 	if username == "" {
@@ -87,6 +99,11 @@ func (da *InMemoryDataAccess) UserDelete(ctx context.Context, username string) e
 		return errs.ErrNoSuchUser
 	}
 
+	if token, err := da.TokenRetrieveByUser(ctx, username); err == nil {
+		da.TokenInvalidate(ctx, token.Token)
+	}
+
+	da.deleted.users[username] = deletedUser{da.users[username], time.Now().UTC()}
 	delete(da.users, username)
 
 	return nil
@@ -100,6 +117,29 @@ func (da *InMemoryDataAccess) UserExists(ctx context.Context, username string) (
 	return exists, nil
 }
 
+// UserExport returns all of the personal data stored about a user. The
+// in-memory data store doesn't keep request records, so none are included.
+func (da *InMemoryDataAccess) UserExport(ctx context.Context, username string) (rest.UserDataExport, error) {
+	user, err := da.UserGet(ctx, username)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+	user.Password = ""
+
+	groups, err := da.UserGroupList(ctx, username)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	export := rest.UserDataExport{User: user, Exported: time.Now().UTC()}
+	for _, g := range groups {
+		export.Groups = append(export.Groups, g.Name)
+	}
+	sort.Strings(export.Groups)
+
+	return export, nil
+}
+
 // UserGet returns a user from the data store. An error is returned if the
 // username parameter is empty or if the user doesn't exist.
 func (da *InMemoryDataAccess) UserGet(ctx context.Context, username string) (rest.User, error) {
@@ -235,6 +275,177 @@ func (da *InMemoryDataAccess) UserPermissionList(ctx context.Context, username s
 	return pp, nil
 }
 
+// UserPurge removes a user, along with their group memberships, adapter
+// mappings, and tokens. An error is returned if the username parameter is
+// empty, if the user doesn't exist, or if the user is "admin".
+func (da *InMemoryDataAccess) UserPurge(ctx context.Context, username string) (rest.UserPurgeResult, error) {
+	if username == "" {
+		return rest.UserPurgeResult{}, errs.ErrEmptyUserName
+	}
+
+	// Thou Shalt Not Purge Admin
+	if username == "admin" {
+		return rest.UserPurgeResult{}, errs.ErrAdminUndeletable
+	}
+
+	// Deleted users may also be purged.
+	if _, deleted := da.deleted.users[username]; deleted {
+		da.users[username] = da.deleted.users[username].user
+		delete(da.deleted.users, username)
+	}
+
+	if exists, _ := da.UserExists(ctx, username); !exists {
+		return rest.UserPurgeResult{}, errs.ErrNoSuchUser
+	}
+
+	pseudonym, err := data.GeneratePseudonym()
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+
+	groups, err := da.UserGroupList(ctx, username)
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+	for _, g := range groups {
+		if err := da.GroupUserDelete(ctx, g.Name, username); err != nil {
+			return rest.UserPurgeResult{}, err
+		}
+	}
+
+	if token, err := da.TokenRetrieveByUser(ctx, username); err == nil {
+		da.TokenInvalidate(ctx, token.Token)
+	}
+
+	delete(da.users, username)
+
+	return rest.UserPurgeResult{Username: username, Pseudonym: pseudonym}, nil
+}
+
+// UserRename changes a user's username, updating everything that refers to
+// it: group memberships, tokens, user-layer configurations, option
+// defaults, macros, aliases, locks, and request records. An error is
+// returned if either name is empty, if the user doesn't exist, if the new
+// name is already taken, or if the user is "admin".
+func (da *InMemoryDataAccess) UserRename(ctx context.Context, username, newname string) error {
+	if username == "" || newname == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	// Thou Shalt Not Rename Admin
+	if username == "admin" {
+		return errs.ErrAdminUndeletable
+	}
+
+	user, exists := da.users[username]
+	if !exists {
+		return errs.ErrNoSuchUser
+	}
+	if _, exists := da.users[newname]; exists {
+		return errs.ErrUserExists
+	}
+
+	// As with UserCreate, the new name displaces any deleted user.
+	da.purgeDeletedUser(newname)
+
+	user.Username = newname
+	da.users[newname] = user
+	delete(da.users, username)
+
+	groups := make([]*rest.Group, 0, len(da.groups)+len(da.deleted.groups))
+	for _, g := range da.groups {
+		groups = append(groups, g)
+	}
+	for _, d := range da.deleted.groups {
+		groups = append(groups, d.group)
+	}
+	for _, g := range groups {
+		for i, u := range g.Users {
+			if u.Username == username {
+				g.Users[i].Username = newname
+			}
+		}
+	}
+
+	if token, ok := tokensByUser[username]; ok {
+		token.User = newname
+		delete(tokensByUser, username)
+		tokensByUser[newname] = token
+		tokensByValue[token.Token] = token
+	}
+
+	for key, c := range da.configs {
+		if c.Layer == data.LayerUser && c.Owner == username {
+			c.Owner = newname
+			delete(da.configs, key)
+			if key, err := generateLookupKey(c.Layer, c.Bundle, c.Owner, c.Key); err == nil {
+				da.configs[key] = c
+			}
+		}
+	}
+
+	for key, d := range da.defaults {
+		if d.Layer == data.LayerUser && d.Owner == username {
+			d.Owner = newname
+			delete(da.defaults, key)
+			if key, err := optionDefaultKey(d); err == nil {
+				da.defaults[key] = d
+			}
+		}
+	}
+
+	for key, m := range da.macros {
+		if m.Layer == data.LayerUser && m.Owner == username {
+			m.Owner = newname
+			delete(da.macros, key)
+			if key, err := macroKey(m); err == nil {
+				da.macros[key] = m
+			}
+		}
+	}
+
+	for key, a := range da.aliases {
+		if a.Owner == username {
+			a.Owner = newname
+			delete(da.aliases, key)
+			da.aliases[aliasKey(*a)] = a
+		}
+	}
+
+	for _, l := range da.locks {
+		if l.UserName == username {
+			l.UserName = newname
+		}
+	}
+
+	for _, r := range da.requests {
+		if r.UserName == username {
+			r.UserName = newname
+		}
+	}
+
+	return nil
+}
+
+// UserRestore restores a user that was deleted at or after since. An error is
+// returned if the username parameter is empty, or if no such user was
+// deleted in that time.
+func (da *InMemoryDataAccess) UserRestore(ctx context.Context, username string, since time.Time) error {
+	if username == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	d, ok := da.deleted.users[username]
+	if !ok || d.deletedAt.Before(since) {
+		return errs.ErrNoSuchUser
+	}
+
+	da.users[username] = d.user
+	delete(da.deleted.users, username)
+
+	return nil
+}
+
 // UserRoleList returns a slice of Role values representing the specified
 // user's indirect roles (indirect because users are members of groups,
 // and groups have roles).
@@ -269,7 +480,9 @@ func (da *InMemoryDataAccess) UserRoleList(ctx context.Context, username string)
 }
 
 // UserUpdate is used to update an existing user. An error is returned if the
-// username is empty or if the user doesn't exist.
+// username is empty, if the user doesn't exist, or if a service account is
+// given a password or mappings. Whether a user is a service account can't be
+// changed.
 // TODO Should we let this create users that don't exist?
 func (da *InMemoryDataAccess) UserUpdate(ctx context.Context, user rest.User) error {
 	if user.Username == "" {
@@ -284,6 +497,12 @@ func (da *InMemoryDataAccess) UserUpdate(ctx context.Context, user rest.User) er
 		return errs.ErrNoSuchUser
 	}
 
+	// Whether a user is a service account is fixed when it's created.
+	user.ServiceAccount = da.users[user.Username].ServiceAccount
+	if user.ServiceAccount && (user.Password != "" || len(user.Mappings) > 0) {
+		return errs.ErrServiceAccountCredentials
+	}
+
 	if user.Mappings == nil {
 		user.Mappings = map[string]string{}
 	}
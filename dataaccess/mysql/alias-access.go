@@ -0,0 +1,135 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// AliasDelete removes an alias. An empty owner refers to a global alias.
+func (da MySQLDataAccess) AliasDelete(ctx context.Context, owner, name string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.AliasDelete")
+	defer sp.End()
+
+	if name == "" {
+		return errs.ErrEmptyAliasName
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM aliases WHERE owner=? AND name=?;`
+
+	result, err := db.ExecContext(ctx, query, owner, name)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchAlias
+	}
+
+	return nil
+}
+
+// AliasList returns all aliases, global and user-owned, with the given name.
+// If name is empty, all aliases are returned. The results are sorted by name
+// and owner, with global aliases first.
+func (da MySQLDataAccess) AliasList(ctx context.Context, name string) ([]data.Alias, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.AliasList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT name, owner, command
+		FROM aliases
+		WHERE ?='' OR name=?
+		ORDER BY name, owner;`
+
+	rows, err := db.QueryContext(ctx, query, name, name)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.Alias{}
+	for rows.Next() {
+		var a data.Alias
+
+		err = rows.Scan(&a.Name, &a.Owner, &a.Command)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		list = append(list, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// AliasSave creates or replaces an alias.
+func (da MySQLDataAccess) AliasSave(ctx context.Context, alias data.Alias) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.AliasSave")
+	defer sp.End()
+
+	if alias.Name == "" {
+		return errs.ErrEmptyAliasName
+	}
+
+	if err := alias.Validate(); err != nil {
+		return err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO aliases (owner, name, command)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE command=VALUES(command);`
+
+	_, err = db.ExecContext(ctx, query, alias.Owner, alias.Name, alias.Command)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// AuditRecordCreate stores an audit record, setting its ID.
+func (da MySQLDataAccess) AuditRecordCreate(ctx context.Context, record *data.AuditRecord) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.AuditRecordCreate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO rest_audit
+		(timestamp, username, source_ip, method, path, kind, target, status, before_state, after_state)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	result, err := db.ExecContext(ctx, query,
+		record.Timestamp, record.User, record.SourceIP, record.Method,
+		record.Path, record.Kind, record.Target, record.Status,
+		string(record.Before), string(record.After))
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	record.ID, err = result.LastInsertId()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// AuditRecordList returns the audit records that match the filter, newest
+// first.
+func (da MySQLDataAccess) AuditRecordList(ctx context.Context, filter data.AuditFilter) ([]data.AuditRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.AuditRecordList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, cond)
+	}
+
+	if filter.User != "" {
+		addCondition("username=?", filter.User)
+	}
+	if filter.Kind != "" {
+		addCondition("kind=?", filter.Kind)
+	}
+	if filter.Target != "" {
+		addCondition("target=?", filter.Target)
+	}
+	if !filter.Since.IsZero() {
+		addCondition("timestamp>=?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		addCondition("timestamp<?", filter.Until)
+	}
+
+	query := `SELECT audit_id, timestamp, username, source_ip, method, path,
+			kind, target, status, before_state, after_state
+		FROM rest_audit`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY audit_id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += ` LIMIT ?`
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.AuditRecord{}
+
+	for rows.Next() {
+		var r data.AuditRecord
+		var before, after string
+
+		err = rows.Scan(&r.ID, &r.Timestamp, &r.User, &r.SourceIP, &r.Method,
+			&r.Path, &r.Kind, &r.Target, &r.Status, &before, &after)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if before != "" {
+			r.Before = []byte(before)
+		}
+		if after != "" {
+			r.After = []byte(after)
+		}
+
+		list = append(list, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+func (da MySQLDataAccess) createRestAuditTable(ctx context.Context, conn *sql.Conn) error {
+	createRestAuditQuery := `CREATE TABLE rest_audit (
+		audit_id		BIGINT NOT NULL AUTO_INCREMENT,
+		timestamp		DATETIME(6) NOT NULL,
+		username		VARCHAR(255) NOT NULL,
+		source_ip		VARCHAR(255) NOT NULL,
+		method			VARCHAR(16) NOT NULL,
+		path			TEXT NOT NULL,
+		kind			VARCHAR(255) NOT NULL,
+		target			VARCHAR(255) NOT NULL,
+		status			INT NOT NULL,
+		before_state	MEDIUMTEXT NOT NULL,
+		after_state		MEDIUMTEXT NOT NULL,
+		PRIMARY KEY		(audit_id)
+	);
+
+	CREATE INDEX rest_audit_kind_target ON rest_audit (kind, target);
+	CREATE INDEX rest_audit_timestamp ON rest_audit (timestamp);`
+
+	_, err := conn.ExecContext(ctx, createRestAuditQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,1778 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/bundles"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+type bundleData struct {
+	BundleName    string
+	BundleVersion string
+}
+
+type bundleCommandData struct {
+	data.BundleCommand
+	bundleData
+}
+
+// BundleCreate TBD
+func (da MySQLDataAccess) BundleCreate(ctx context.Context, bundle data.Bundle) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleCreate")
+	defer sp.End()
+
+	if bundle.Name == "" {
+		return errs.ErrEmptyBundleName
+	}
+
+	if bundle.Version == "" {
+		return errs.ErrEmptyBundleVersion
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	exists, err := da.doBundleVersionExists(ctx, tx, bundle.Name, bundle.Version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	} else if exists {
+		tx.Rollback()
+		return errs.ErrBundleExists
+	}
+
+	err = da.doBundleCreate(ctx, tx, bundle)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return err
+}
+
+// BundleDelete TBD
+func (da MySQLDataAccess) BundleDelete(ctx context.Context, name, version string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleDelete")
+	defer sp.End()
+
+	if name == "" {
+		return errs.ErrEmptyBundleName
+	}
+
+	if version == "" {
+		return errs.ErrEmptyBundleVersion
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	exists, err := da.doBundleVersionExists(ctx, tx, name, version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	} else if !exists {
+		tx.Rollback()
+		return errs.ErrNoSuchBundle
+	}
+
+	err = da.doBundleDisable(ctx, tx, name, version)
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	err = da.doBundleDelete(ctx, tx, name, version)
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// BundleDisable TBD
+func (da MySQLDataAccess) BundleDisable(ctx context.Context, name, version string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleDisable")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	err = da.doBundleDisable(ctx, tx, name, version)
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// BundleEnable TBD
+func (da MySQLDataAccess) BundleEnable(ctx context.Context, name, version string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleEnable")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	err = da.doBundleEnable(ctx, tx, name, version)
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// BundleEnabledVersion returns the currently enabled version of the specified bundle.
+// If no version is enabled an empty string will be returned.
+func (da MySQLDataAccess) BundleEnabledVersion(ctx context.Context, bundlename string) (string, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleEnabledVersion")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return "", gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer tx.Commit()
+
+	enabled, err := da.doBundleEnabledVersion(ctx, tx, bundlename)
+	if err != nil {
+		return "", gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return enabled, nil
+}
+
+// BundleExists TBD
+func (da MySQLDataAccess) BundleExists(ctx context.Context, name string) (bool, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleExists")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return false, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer tx.Commit()
+
+	return da.doBundleExists(ctx, tx, name)
+}
+
+// BundleVersionExists TBD
+func (da MySQLDataAccess) BundleVersionExists(ctx context.Context, name, version string) (bool, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleVersionExists")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return false, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer tx.Commit()
+
+	return da.doBundleVersionExists(ctx, tx, name, version)
+}
+
+// BundleGet TBD
+func (da MySQLDataAccess) BundleGet(ctx context.Context, name, version string) (data.Bundle, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleGet")
+	defer sp.End()
+
+	if name == "" {
+		return data.Bundle{}, errs.ErrEmptyBundleName
+	}
+
+	if version == "" {
+		return data.Bundle{}, errs.ErrEmptyBundleVersion
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.Bundle{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer tx.Commit()
+
+	b, err := da.doBundleGet(ctx, tx, name, version)
+	if err != nil {
+		return data.Bundle{}, err
+	}
+
+	return b, err
+}
+
+// BundleList TBD
+func (da MySQLDataAccess) BundleList(ctx context.Context) ([]data.Bundle, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return []data.Bundle{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer tx.Commit()
+
+	return da.doBundleLoad(ctx, tx, "", "")
+}
+
+// BundleReviewUpdate sets the review state of a bundle version.
+func (da MySQLDataAccess) BundleReviewUpdate(ctx context.Context, name, version string, review data.BundleReview) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleReviewUpdate")
+	defer sp.End()
+
+	if name == "" {
+		return errs.ErrEmptyBundleName
+	}
+
+	if version == "" {
+		return errs.ErrEmptyBundleVersion
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE bundles
+		SET review_status=?, review_user=?, review_timestamp=?, review_comment=?
+		WHERE name=? AND version=?;`
+
+	reviewedOn := sql.NullTime{Time: review.ReviewedOn, Valid: !review.ReviewedOn.IsZero()}
+
+	result, err := db.ExecContext(ctx, query,
+		review.Status, review.Reviewer, reviewedOn, review.Comment, name, version)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchBundle
+	}
+
+	return nil
+}
+
+// BundleUpdate TBD
+func (da MySQLDataAccess) BundleUpdate(ctx context.Context, bundle data.Bundle) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleUpdate")
+	defer sp.End()
+
+	if bundle.Name == "" {
+		return errs.ErrEmptyBundleName
+	}
+
+	if bundle.Version == "" {
+		return errs.ErrEmptyBundleVersion
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	exists, err := da.doBundleVersionExists(ctx, tx, bundle.Name, bundle.Version)
+	if err != nil {
+		return err
+	} else if !exists {
+		return errs.ErrNoSuchBundle
+	}
+
+	err = da.doBundleDelete(ctx, tx, bundle.Name, bundle.Version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = da.doBundleInsert(ctx, tx, bundle)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// BundleUpgrade installs bundle if it isn't already installed, optionally
+// enables it, and then uninstalls all but the retain newest versions. All
+// changes are made in a single transaction, so a failure at any step leaves
+// the installed versions untouched. It returns ErrBundleVersionOlder if a
+// newer version is already installed.
+func (da MySQLDataAccess) BundleUpgrade(ctx context.Context, bundle data.Bundle, enable bool, retain int) (rest.BundleUpgradeResult, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleUpgrade")
+	defer sp.End()
+
+	if bundle.Name == "" {
+		return rest.BundleUpgradeResult{}, errs.ErrEmptyBundleName
+	}
+
+	if bundle.Version == "" {
+		return rest.BundleUpgradeResult{}, errs.ErrEmptyBundleVersion
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return rest.BundleUpgradeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	result, err := da.doBundleUpgrade(ctx, tx, bundle, enable, retain)
+	if err != nil {
+		tx.Rollback()
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return rest.BundleUpgradeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return result, nil
+}
+
+// BundleVersionList TBD
+func (da MySQLDataAccess) BundleVersionList(ctx context.Context, name string) ([]data.Bundle, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.BundleVersionList")
+	defer sp.End()
+
+	if name == "" {
+		return []data.Bundle{}, nil
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return []data.Bundle{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer tx.Commit()
+
+	return da.doBundleLoad(ctx, tx, name, "")
+}
+
+// FindCommandEntry is used to find the enabled commands with the provided
+// bundle and command names. If either is empty, it is treated as a wildcard.
+// Importantly, this must only return ENABLED commands!
+func (da MySQLDataAccess) FindCommandEntry(ctx context.Context, bundleName, commandName string) ([]data.CommandEntry, error) {
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer tx.Commit()
+
+	return da.doFindCommandEntry(ctx, tx, bundleName, commandName)
+}
+
+func (da MySQLDataAccess) FindCommandEntryByTrigger(ctx context.Context, tokens []string) ([]data.CommandEntry, error) {
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer tx.Commit()
+
+	return da.doFindCommandEntryByTrigger(ctx, tx, tokens)
+}
+
+// doBundleUpgrade performs the work of BundleUpgrade inside tx. The caller
+// is responsible for committing or rolling back the transaction.
+func (da MySQLDataAccess) doBundleUpgrade(ctx context.Context, tx *sql.Tx, bundle data.Bundle, enable bool, retain int) (rest.BundleUpgradeResult, error) {
+	installed, err := da.doBundleInstalledVersions(ctx, tx, bundle.Name)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	if _, newer := bundles.NewerVersionInstalled(installed, bundle.Version); newer {
+		return rest.BundleUpgradeResult{}, errs.ErrBundleVersionOlder
+	}
+
+	result := rest.BundleUpgradeResult{Name: bundle.Name, Version: bundle.Version}
+
+	result.PreviousEnabled, err = da.doBundleEnabledVersion(ctx, tx, bundle.Name)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	exists, err := da.doBundleVersionExists(ctx, tx, bundle.Name, bundle.Version)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	if !exists {
+		if err = da.doBundleCreate(ctx, tx, bundle); err != nil {
+			return rest.BundleUpgradeResult{}, err
+		}
+
+		result.Installed = true
+		installed = append(installed, bundle)
+	}
+
+	enabled := result.PreviousEnabled
+	if enable && enabled != bundle.Version {
+		if err = da.doBundleEnable(ctx, tx, bundle.Name, bundle.Version); err != nil {
+			return rest.BundleUpgradeResult{}, err
+		}
+
+		enabled = bundle.Version
+	}
+	result.Enabled = (enabled == bundle.Version)
+
+	for _, v := range bundles.PruneCandidates(installed, enabled, retain) {
+		if err = da.doBundleDelete(ctx, tx, bundle.Name, v); err != nil {
+			return rest.BundleUpgradeResult{}, err
+		}
+
+		result.Pruned = append(result.Pruned, v)
+	}
+
+	return result, nil
+}
+
+// doBundleCreate inserts a bundle and all of its associated data.
+func (da MySQLDataAccess) doBundleCreate(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
+	// Save bundle
+	err := da.doBundleInsert(ctx, tx, bundle)
+	if err != nil {
+		return err
+	}
+
+	// Save permissions
+	err = da.doBundleInsertPermissions(ctx, tx, bundle)
+	if err != nil {
+		return err
+	}
+
+	// Save commands
+	err = da.doBundleInsertCommands(ctx, tx, bundle)
+	if err != nil {
+		return err
+	}
+
+	// Save templates
+	err = da.doBundleInsertTemplates(ctx, tx, bundle)
+	if err != nil {
+		return err
+	}
+
+	// Save kubernetes config
+	return da.doBundleInsertKubernetes(ctx, tx, bundle)
+}
+
+func (da MySQLDataAccess) doBundleDelete(ctx context.Context, tx *sql.Tx, name string, version string) error {
+	query := "DELETE FROM bundle_kubernetes WHERE bundle_name=? AND bundle_version=?;"
+	_, err := tx.ExecContext(ctx, query, name, version)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query = "DELETE FROM bundle_command_rules WHERE bundle_name=? AND bundle_version=?;"
+	_, err = tx.ExecContext(ctx, query, name, version)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query = "DELETE FROM bundle_permissions WHERE bundle_name=? AND bundle_version=?;"
+	_, err = tx.ExecContext(ctx, query, name, version)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query = "DELETE FROM bundle_commands WHERE bundle_name=? AND bundle_version=?;"
+	_, err = tx.ExecContext(ctx, query, name, version)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query = "DELETE FROM bundles WHERE name=? AND version=?;"
+	_, err = tx.ExecContext(ctx, query, name, version)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// doBundleDisable TBD
+func (da MySQLDataAccess) doBundleDisable(ctx context.Context, tx *sql.Tx, name string, version string) error {
+	query := `DELETE FROM bundle_enabled WHERE bundle_name=? AND bundle_version=?`
+
+	_, err := tx.ExecContext(ctx, query, name, version)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// BundleEnable TBD
+func (da MySQLDataAccess) doBundleEnable(ctx context.Context, tx *sql.Tx, name string, version string) error {
+	enabled, err := da.doBundleEnabledVersion(ctx, tx, name)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query := ""
+
+	if enabled == "" {
+		query = `INSERT INTO bundle_enabled (bundle_name, bundle_version)
+			VALUES (?, ?);`
+	} else {
+		query = `UPDATE bundle_enabled
+			SET bundle_version=?
+			WHERE bundle_name=?;`
+	}
+
+	args := []interface{}{name, version}
+	if enabled != "" {
+		args = []interface{}{version, name}
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// BundleExists TBD
+func (da MySQLDataAccess) doBundleEnabledVersion(ctx context.Context, tx *sql.Tx, name string) (string, error) {
+	query := `SELECT
+		COALESCE(
+		(SELECT bundle_version FROM bundle_enabled WHERE bundle_name=?),
+		''
+		) AS bundle_version;`
+
+	enabled := ""
+
+	err := tx.QueryRowContext(ctx, query, name).Scan(&enabled)
+	if err != nil {
+		return "", gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return enabled, nil
+}
+
+// doBundleInstalledVersions returns the installed versions of the named
+// bundle. Only the Name and Version fields are populated.
+func (da MySQLDataAccess) doBundleInstalledVersions(ctx context.Context, tx *sql.Tx, name string) ([]data.Bundle, error) {
+	query := `SELECT version FROM bundles WHERE name=?`
+
+	rows, err := tx.QueryContext(ctx, query, name)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	installed := make([]data.Bundle, 0)
+	for rows.Next() {
+		b := data.Bundle{Name: name}
+
+		if err = rows.Scan(&b.Version); err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		installed = append(installed, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return installed, nil
+}
+
+// BundleExists TBD
+func (da MySQLDataAccess) doBundleExists(ctx context.Context, tx *sql.Tx, name string) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM bundles WHERE name=?)"
+	exists := false
+
+	err := tx.QueryRowContext(ctx, query, name).Scan(&exists)
+	if err != nil {
+		return false, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return exists, nil
+}
+
+// BundleVersionExists TBD
+func (da MySQLDataAccess) doBundleVersionExists(ctx context.Context, tx *sql.Tx, name string, version string) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM bundles WHERE name=? AND version=?)"
+	exists := false
+
+	err := tx.QueryRowContext(ctx, query, name, version).Scan(&exists)
+	if err != nil {
+		return false, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return exists, nil
+}
+
+// doFindCommandEntry returns all command entries for any enabled bundle
+// matching the specified bundle and command names. The bundle parameter may be
+// empty, in which case it will match all bundles.
+func (da MySQLDataAccess) doFindCommandEntry(ctx context.Context, tx *sql.Tx, bundle, command string) ([]data.CommandEntry, error) {
+	bcd, err := da.doBundleGetCommandsData(ctx, tx, bundle, "", command, true)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	entries := make([]data.CommandEntry, 0)
+
+	// Load each matching bundle only once, however many of its commands match.
+	loaded := map[bundleData]data.Bundle{}
+
+	for _, cd := range bcd {
+		b, ok := loaded[cd.bundleData]
+		if !ok {
+			b, err = da.doBundleGet(ctx, tx, cd.BundleName, cd.BundleVersion)
+			if err != nil {
+				return nil, gerr.Wrap(errs.ErrDataAccess, err)
+			}
+			loaded[cd.bundleData] = b
+		}
+
+		cmd, ok := b.Commands[cd.Name]
+		if !ok {
+			return nil, gerr.Wrap(errs.ErrDataAccess, fmt.Errorf("no such command: %s:%s", cd.BundleName, cd.Name))
+		}
+
+		entries = append(entries, data.CommandEntry{Bundle: b, Command: *cmd})
+	}
+
+	return entries, nil
+}
+
+func (da MySQLDataAccess) doFindCommandEntryByTrigger(ctx context.Context, tx *sql.Tx, tokens []string) ([]data.CommandEntry, error) {
+	bundles, err := da.doBundleLoad(ctx, tx, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]data.CommandEntry, 0)
+
+	for _, bundle := range bundles {
+		// Only enabled bundles' triggers are live.
+		if !bundle.Enabled {
+			continue
+		}
+
+		for _, cmd := range bundle.Commands {
+			matched, err := cmd.MatchTrigger(ctx, strings.Join(tokens, " "))
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				entries = append(entries, data.CommandEntry{
+					Bundle:  bundle,
+					Command: *cmd,
+				})
+			}
+		}
+
+	}
+
+	return entries, nil
+}
+
+func (da MySQLDataAccess) doBundleGet(ctx context.Context, tx *sql.Tx, name string, version string) (data.Bundle, error) {
+	if name == "" || version == "" {
+		return data.Bundle{}, errs.ErrNoSuchBundle
+	}
+
+	bundles, err := da.doBundleLoad(ctx, tx, name, version)
+	if err != nil {
+		return data.Bundle{}, err
+	}
+
+	if len(bundles) == 0 {
+		return data.Bundle{}, errs.ErrNoSuchBundle
+	}
+
+	return bundles[0], nil
+}
+
+// bundleLoadFilter restricts a query against one of the bundle_* tables to
+// the name and version being loaded by doBundleLoad. Either may be empty, in
+// which case it matches all.
+const bundleLoadFilter = `(? = '' OR bundle_name = ?) AND (? = '' OR bundle_version = ?)`
+
+// bundleCommandKey identifies a single command of a single bundle version.
+type bundleCommandKey struct {
+	bundleData
+	CommandName string
+}
+
+// bundleLoader accumulates the bundles loaded by doBundleLoad.
+type bundleLoader struct {
+	tx            *sql.Tx
+	name, version string
+	args          []interface{}
+
+	keys     []bundleData
+	bundles  map[bundleData]*data.Bundle
+	commands map[bundleCommandKey]*data.BundleCommand
+}
+
+// query runs a query using the loader's name and version filter arguments,
+// calling scan for each row.
+func (l *bundleLoader) query(ctx context.Context, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := l.tx.QueryContext(ctx, query, l.args...)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// command returns the loaded command with the given key, or nil if its
+// bundle wasn't loaded.
+func (l *bundleLoader) command(bundleName, bundleVersion, commandName string) *data.BundleCommand {
+	return l.commands[bundleCommandKey{bundleData{bundleName, bundleVersion}, commandName}]
+}
+
+// doBundleLoad loads every version of every bundle matching name and
+// version, either of which may be empty to match all, ordered by name and
+// version. Rather than querying each table once per bundle and command, it
+// queries each table once, so the number of queries is fixed no matter how
+// many bundles are loaded.
+func (da MySQLDataAccess) doBundleLoad(ctx context.Context, tx *sql.Tx, name, version string) ([]data.Bundle, error) {
+	l := &bundleLoader{
+		tx:       tx,
+		name:     name,
+		version:  version,
+		args:     []interface{}{name, name, version, version},
+		bundles:  map[bundleData]*data.Bundle{},
+		commands: map[bundleCommandKey]*data.BundleCommand{},
+	}
+
+	steps := []struct {
+		what string
+		load func(ctx context.Context, l *bundleLoader) error
+	}{
+		{"bundles", da.doBundleLoadBundles},
+		{"bundle enabled versions", da.doBundleLoadEnabled},
+		{"bundle permissions", da.doBundleLoadPermissions},
+		{"bundle templates", da.doBundleLoadTemplates},
+		{"bundle kubernetes config", da.doBundleLoadKubernetes},
+		{"bundle commands", da.doBundleLoadCommands},
+		{"bundle command triggers", da.doBundleLoadCommandTriggers},
+		{"bundle command options", da.doBundleLoadCommandOptions},
+		{"bundle command profiles", da.doBundleLoadCommandProfiles},
+		{"bundle command rules", da.doBundleLoadCommandRules},
+		{"bundle command templates", da.doBundleLoadCommandTemplates},
+	}
+
+	for _, s := range steps {
+		if err := s.load(ctx, l); err != nil {
+			return nil, gerr.Wrap(fmt.Errorf("failed to get %s", s.what), err)
+		}
+
+		// There's nothing more to load if no bundles matched.
+		if len(l.keys) == 0 {
+			return []data.Bundle{}, nil
+		}
+	}
+
+	bundles := make([]data.Bundle, 0, len(l.keys))
+	for _, key := range l.keys {
+		bundles = append(bundles, *l.bundles[key])
+	}
+
+	return bundles, nil
+}
+
+func (da MySQLDataAccess) doBundleLoadBundles(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT gort_bundle_version, name, version, author, homepage,
+			description, long_description, image_repository, image_tag,
+			install_timestamp, install_user, platform_os, platform_arch,
+			output_filters, serverless_function, serverless_job, ssh,
+			review_status, review_user, review_timestamp, review_comment,
+			telemetry_attributes, resource_cpu, resource_memory, owners
+		FROM bundles
+		WHERE (? = '' OR name = ?) AND (? = '' OR version = ?)
+		ORDER BY name, version`
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var repository, tag, filters, ssh, attributes, owners string
+		var reviewedOn sql.NullTime
+
+		bundle := data.Bundle{}
+		err := rows.Scan(&bundle.GortBundleVersion, &bundle.Name, &bundle.Version,
+			&bundle.Author, &bundle.Homepage, &bundle.Description,
+			&bundle.LongDescription, &repository, &tag,
+			&bundle.InstalledOn, &bundle.InstalledBy,
+			&bundle.Platform.OS, &bundle.Platform.Arch, &filters,
+			&bundle.Serverless.Function, &bundle.Serverless.Job, &ssh,
+			&bundle.Review.Status, &bundle.Review.Reviewer, &reviewedOn, &bundle.Review.Comment,
+			&attributes, &bundle.Resources.CPU, &bundle.Resources.Memory, &owners)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if owners != "" {
+			bundle.Owners = decodeStringSlice(owners)
+		}
+
+		if filters != "" {
+			if err := json.Unmarshal([]byte(filters), &bundle.OutputFilters); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if ssh != "" {
+			if err := json.Unmarshal([]byte(ssh), &bundle.SSH); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if attributes != "" {
+			if err := json.Unmarshal([]byte(attributes), &bundle.Telemetry); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if reviewedOn.Valid {
+			bundle.Review.ReviewedOn = reviewedOn.Time
+		}
+
+		if repository != "" {
+			if tag == "" {
+				tag = "latest"
+			}
+
+			bundle.Image = repository + ":" + tag
+		}
+
+		bundle.Permissions = make([]string, 0)
+		bundle.Commands = make(map[string]*data.BundleCommand)
+
+		key := bundleData{bundle.Name, bundle.Version}
+		l.keys = append(l.keys, key)
+		l.bundles[key] = &bundle
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadEnabled(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version
+		FROM bundle_enabled
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+
+		if err := rows.Scan(&key.BundleName, &key.BundleVersion); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			b.Enabled = true
+		}
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadPermissions(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, permission
+		FROM bundle_permissions
+		WHERE ` + bundleLoadFilter + `
+		ORDER BY bundle_name, bundle_version, perm_index`
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var perm string
+
+		if err := rows.Scan(&key.BundleName, &key.BundleVersion, &perm); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			b.Permissions = append(b.Permissions, perm)
+		}
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadTemplates(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version,
+			command, command_error, command_timeout, digest, message, message_error
+		FROM bundle_templates
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var templates data.Templates
+
+		err := rows.Scan(&key.BundleName, &key.BundleVersion,
+			&templates.Command, &templates.CommandError, &templates.CommandTimeout,
+			&templates.Digest, &templates.Message, &templates.MessageError)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			b.Templates = templates
+		}
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadKubernetes(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, service_account_name, env_secret, cluster, namespace
+		FROM bundle_kubernetes
+		WHERE ` + bundleLoadFilter
+
+	err := l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var kubernetes data.BundleKubernetes
+
+		err := rows.Scan(&key.BundleName, &key.BundleVersion,
+			&kubernetes.ServiceAccountName, &kubernetes.EnvSecret, &kubernetes.Cluster, &kubernetes.Namespace)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			b.Kubernetes = kubernetes
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	query = `SELECT bundle_name, bundle_version, selector_key, selector_value
+		FROM bundle_kubernetes_node_selectors
+		WHERE ` + bundleLoadFilter
+
+	err = l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var selectorKey, selectorValue string
+
+		if err := rows.Scan(&key.BundleName, &key.BundleVersion, &selectorKey, &selectorValue); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			if b.Kubernetes.NodeSelector == nil {
+				b.Kubernetes.NodeSelector = map[string]string{}
+			}
+			b.Kubernetes.NodeSelector[selectorKey] = selectorValue
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	query = `SELECT bundle_name, bundle_version, group_name, service_account_name
+		FROM bundle_kubernetes_service_accounts
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var group, sa string
+
+		if err := rows.Scan(&key.BundleName, &key.BundleVersion, &group, &sa); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			if b.Kubernetes.GroupServiceAccounts == nil {
+				b.Kubernetes.GroupServiceAccounts = map[string]string{}
+			}
+			b.Kubernetes.GroupServiceAccounts[group] = sa
+		}
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadCommands(ctx context.Context, l *bundleLoader) error {
+	bcd, err := da.doBundleGetCommandsData(ctx, l.tx, l.name, l.version, "", false)
+	if err != nil {
+		return err
+	}
+
+	for _, cd := range bcd {
+		b, ok := l.bundles[cd.bundleData]
+		if !ok {
+			continue
+		}
+
+		command := cd.BundleCommand
+		command.Rules = make([]string, 0)
+
+		b.Commands[command.Name] = &command
+		l.commands[bundleCommandKey{cd.bundleData, command.Name}] = &command
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleLoadCommandTriggers(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name, pattern, channels
+		FROM bundle_command_triggers
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName, channels string
+		var trigger data.Trigger
+
+		err := rows.Scan(&bundleName, &bundleVersion, &commandName, &trigger.Match, &channels)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if channels != "" {
+			trigger.Channels = decodeStringSlice(channels)
+		}
+
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			c.Triggers = append(c.Triggers, trigger)
+		}
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadCommandOptions(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name, name, description, is_sensitive, permissions
+		FROM bundle_command_options
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName, name, permissions string
+		var option data.BundleCommandOption
+
+		err := rows.Scan(&bundleName, &bundleVersion, &commandName,
+			&name, &option.Description, &option.Sensitive, &permissions)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if permissions != "" {
+			if err := json.Unmarshal([]byte(permissions), &option.Permissions); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			if c.Options == nil {
+				c.Options = map[string]*data.BundleCommandOption{}
+			}
+			c.Options[name] = &option
+		}
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadCommandProfiles(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name,
+			name, description, env, cluster, env_secret, service_account_name, permission
+		FROM bundle_command_profiles
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName, name, env string
+		var profile data.BundleCommandProfile
+
+		err := rows.Scan(&bundleName, &bundleVersion, &commandName,
+			&name, &profile.Description, &env, &profile.Cluster, &profile.EnvSecret,
+			&profile.ServiceAccountName, &profile.Permission)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if env != "" {
+			if err := json.Unmarshal([]byte(env), &profile.Env); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			if c.Profiles == nil {
+				c.Profiles = map[string]*data.BundleCommandProfile{}
+			}
+			c.Profiles[name] = &profile
+		}
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadCommandRules(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name, rule
+		FROM bundle_command_rules
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName, rule string
+
+		if err := rows.Scan(&bundleName, &bundleVersion, &commandName, &rule); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			c.Rules = append(c.Rules, rule)
+		}
+
+		return nil
+	})
+}
+
+func (da MySQLDataAccess) doBundleLoadCommandTemplates(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name,
+			command, command_error, command_timeout, digest, message, message_error
+		FROM bundle_command_templates
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName string
+		var templates data.Templates
+
+		err := rows.Scan(&bundleName, &bundleVersion, &commandName,
+			&templates.Command, &templates.CommandError, &templates.CommandTimeout,
+			&templates.Digest, &templates.Message, &templates.MessageError)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			c.Templates = templates
+		}
+
+		return nil
+	})
+}
+
+// doBundleGetCommandsData is a helper method that retrieves zero or more
+// commands for the specified bundle name+version, along with the owning
+// bundle's name and version. Empty string parameters are treated as wildcards.
+func (da MySQLDataAccess) doBundleGetCommandsData(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion, commandName string, enabledOnly bool) ([]bundleCommandData, error) {
+	var query string
+
+	if bundleName == "" {
+		bundleName = "%"
+	}
+	if bundleVersion == "" {
+		bundleVersion = "%"
+	}
+	if commandName == "" {
+		commandName = "%"
+	}
+
+	if enabledOnly {
+		query = `SELECT bundle_commands.bundle_name, bundle_commands.bundle_version, name, description, exclusive, executable, long_description,
+			bundle_commands.platform_os, bundle_commands.platform_arch, bundle_commands.cooldown,
+			bundle_commands.ansi, bundle_commands.default_profile,
+			bundle_commands.ephemeral_output, bundle_commands.ephemeral_ttl,
+			bundle_commands.secret_output,
+			bundle_commands.reaction_success, bundle_commands.reaction_failure,
+			bundle_commands.usage_text, bundle_commands.examples, bundle_commands.native_help,
+			bundle_commands.timeout
+			FROM bundle_commands
+			INNER JOIN bundle_enabled ON bundle_commands.bundle_name=bundle_enabled.bundle_name
+			WHERE bundle_commands.bundle_name LIKE ? AND bundle_commands.bundle_version LIKE ? AND name LIKE ?`
+	} else {
+		query = `SELECT bundle_commands.bundle_name, bundle_commands.bundle_version, name, description, exclusive, executable, long_description,
+			bundle_commands.platform_os, bundle_commands.platform_arch, bundle_commands.cooldown,
+			bundle_commands.ansi, bundle_commands.default_profile,
+			bundle_commands.ephemeral_output, bundle_commands.ephemeral_ttl,
+			bundle_commands.secret_output,
+			bundle_commands.reaction_success, bundle_commands.reaction_failure,
+			bundle_commands.usage_text, bundle_commands.examples, bundle_commands.native_help,
+			bundle_commands.timeout
+			FROM bundle_commands
+			WHERE bundle_commands.bundle_name LIKE ? AND bundle_commands.bundle_version LIKE ? AND name LIKE ?`
+	}
+
+	rows, err := tx.QueryContext(ctx, query, bundleName, bundleVersion, commandName)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	commands := make([]bundleCommandData, 0)
+
+	for rows.Next() {
+		var enc, examples string
+		cd := bundleCommandData{}
+
+		err = rows.Scan(&cd.BundleName, &cd.BundleVersion, &cd.Name, &cd.Description, &cd.Exclusive, &enc, &cd.LongDescription,
+			&cd.Platform.OS, &cd.Platform.Arch, &cd.Cooldown, &cd.ANSI, &cd.DefaultProfile,
+			&cd.EphemeralOutput, &cd.EphemeralTTL, &cd.SecretOutput,
+			&cd.Reactions.Success, &cd.Reactions.Failure,
+			&cd.Usage, &examples, &cd.NativeHelp, &cd.Timeout)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		cd.Executable = decodeStringSlice(enc)
+		if examples != "" {
+			cd.Examples = decodeStringSlice(examples)
+		}
+		commands = append(commands, cd)
+	}
+
+	return commands, nil
+}
+
+func (da MySQLDataAccess) doBundleInsert(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
+	query := `INSERT INTO bundles (gort_bundle_version, name, version, author,
+		homepage, description, long_description, image_repository, image_tag,
+		install_user, platform_os, platform_arch, output_filters,
+		serverless_function, serverless_job, ssh, review_status, review_user,
+		review_timestamp, review_comment, telemetry_attributes, resource_cpu,
+		resource_memory, owners)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?, ?, ?, ?);`
+
+	repository, tag := bundle.ImageFullParts()
+
+	var filters string
+	if !bundle.OutputFilters.IsZero() {
+		b, err := json.Marshal(bundle.OutputFilters)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		filters = string(b)
+	}
+
+	var ssh string
+	if !bundle.SSH.IsZero() {
+		b, err := json.Marshal(bundle.SSH)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		ssh = string(b)
+	}
+
+	var attributes string
+	if len(bundle.Telemetry) > 0 {
+		b, err := json.Marshal(bundle.Telemetry)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		attributes = string(b)
+	}
+
+	_, err := tx.ExecContext(ctx, query, bundle.GortBundleVersion, bundle.Name, bundle.Version,
+		bundle.Author, bundle.Homepage, bundle.Description, bundle.LongDescription,
+		repository, tag, bundle.InstalledBy, bundle.Platform.OS, bundle.Platform.Arch, filters,
+		bundle.Serverless.Function, bundle.Serverless.Job, ssh,
+		bundle.Review.Status, bundle.Review.Reviewer,
+		sql.NullTime{Time: bundle.Review.ReviewedOn, Valid: !bundle.Review.ReviewedOn.IsZero()},
+		bundle.Review.Comment, attributes, bundle.Resources.CPU, bundle.Resources.Memory,
+		encodeStringSlice(bundle.Owners))
+
+	if err != nil {
+		if violatesConstraint(err) {
+			err = gerr.Wrap(errs.ErrFieldRequired, err)
+		} else {
+			err = gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertCommandTriggers(ctx context.Context,
+	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
+
+	query := `INSERT INTO bundle_command_triggers
+		(bundle_name, bundle_version, command_name, pattern, channels)
+		VALUES (?, ?, ?, ?, ?);`
+
+	for _, trigger := range command.Triggers {
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name,
+			trigger.Match, encodeStringSlice(trigger.Channels))
+		if err != nil {
+			if violatesConstraint(err) {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertCommandOptions(ctx context.Context,
+	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
+
+	query := `INSERT INTO bundle_command_options
+		(bundle_name, bundle_version, command_name, name, description, is_sensitive, permissions)
+		VALUES (?, ?, ?, ?, ?, ?, ?);`
+
+	for name, option := range command.Options {
+		if option == nil {
+			option = &data.BundleCommandOption{}
+		}
+
+		var permissions string
+		if len(option.Permissions) > 0 {
+			b, err := json.Marshal(option.Permissions)
+			if err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+			permissions = string(b)
+		}
+
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name, name,
+			option.Description, option.Sensitive, permissions)
+		if err != nil {
+			if violatesConstraint(err) {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertCommandProfiles(ctx context.Context,
+	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
+
+	query := `INSERT INTO bundle_command_profiles
+		(bundle_name, bundle_version, command_name, name, description, env,
+			cluster, env_secret, service_account_name, permission)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	for name, profile := range command.Profiles {
+		if profile == nil {
+			profile = &data.BundleCommandProfile{}
+		}
+
+		var env string
+		if len(profile.Env) > 0 {
+			b, err := json.Marshal(profile.Env)
+			if err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+			env = string(b)
+		}
+
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name, name,
+			profile.Description, env, profile.Cluster, profile.EnvSecret, profile.ServiceAccountName, profile.Permission)
+		if err != nil {
+			if violatesConstraint(err) {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertCommandRules(ctx context.Context,
+	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
+
+	query := `INSERT INTO bundle_command_rules
+		(bundle_name, bundle_version, command_name, rule)
+		VALUES (?, ?, ?, ?);`
+
+	for _, rule := range command.Rules {
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name, rule)
+		if err != nil {
+			if violatesConstraint(err) {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertCommandTemplates(ctx context.Context,
+	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
+
+	query := `INSERT INTO bundle_command_templates
+		(bundle_name, bundle_version, command_name, command, command_error, command_timeout, digest, message, message_error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name,
+		command.Templates.Command, command.Templates.CommandError, command.Templates.CommandTimeout,
+		command.Templates.Digest, command.Templates.Message, command.Templates.MessageError)
+
+	if err != nil {
+		if violatesConstraint(err) {
+			err = gerr.Wrap(errs.ErrFieldRequired, err)
+		} else {
+			err = gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertCommands(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
+	query := `INSERT INTO bundle_commands
+		(bundle_name, bundle_version, name, description, exclusive, executable, long_description,
+			platform_os, platform_arch, cooldown, ansi, default_profile,
+			ephemeral_output, ephemeral_ttl, secret_output,
+			reaction_success, reaction_failure, usage_text, examples, native_help, timeout)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	for name, cmd := range bundle.Commands {
+		cmd.Name = name
+
+		enc := encodeStringSlice(cmd.Executable)
+
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version,
+			cmd.Name, cmd.Description, cmd.Exclusive, enc, cmd.LongDescription,
+			cmd.Platform.OS, cmd.Platform.Arch, cmd.Cooldown, cmd.ANSI, cmd.DefaultProfile,
+			cmd.EphemeralOutput, cmd.EphemeralTTL, cmd.SecretOutput,
+			cmd.Reactions.Success, cmd.Reactions.Failure,
+			cmd.Usage, encodeStringSlice(cmd.Examples), cmd.NativeHelp, cmd.Timeout)
+
+		if err != nil {
+			if violatesConstraint(err) {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+
+		err = da.doBundleInsertCommandTriggers(ctx, tx, bundle, cmd)
+		if err != nil {
+			return err
+		}
+
+		err = da.doBundleInsertCommandOptions(ctx, tx, bundle, cmd)
+		if err != nil {
+			return err
+		}
+
+		err = da.doBundleInsertCommandProfiles(ctx, tx, bundle, cmd)
+		if err != nil {
+			return err
+		}
+
+		err = da.doBundleInsertCommandRules(ctx, tx, bundle, cmd)
+		if err != nil {
+			return err
+		}
+
+		err = da.doBundleInsertCommandTemplates(ctx, tx, bundle, cmd)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertPermissions(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
+	query := `INSERT INTO bundle_permissions
+		(bundle_name, bundle_version, perm_index, permission)
+		VALUES (?, ?, ?, ?);`
+
+	for i, perm := range bundle.Permissions {
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, i, perm)
+		if err != nil {
+			if violatesConstraint(err) {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertTemplates(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
+	query := `INSERT INTO bundle_templates
+		(bundle_name, bundle_version, command, command_error, command_timeout, digest, message, message_error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
+
+	_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version,
+		bundle.Templates.Command, bundle.Templates.CommandError, bundle.Templates.CommandTimeout,
+		bundle.Templates.Digest, bundle.Templates.Message, bundle.Templates.MessageError)
+
+	if err != nil {
+		if violatesConstraint(err) {
+			err = gerr.Wrap(errs.ErrFieldRequired, err)
+		} else {
+			err = gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) doBundleInsertKubernetes(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
+	query := `INSERT INTO bundle_kubernetes
+		(bundle_name, bundle_version, service_account_name, env_secret, cluster, namespace)
+		VALUES (?, ?, ?, ?, ?, ?);`
+
+	_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version,
+		bundle.Kubernetes.ServiceAccountName, bundle.Kubernetes.EnvSecret, bundle.Kubernetes.Cluster,
+		bundle.Kubernetes.Namespace)
+
+	if err != nil {
+		if violatesConstraint(err) {
+			err = gerr.Wrap(errs.ErrFieldRequired, err)
+		} else {
+			err = gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		return err
+	}
+
+	query = `INSERT INTO bundle_kubernetes_node_selectors
+		(bundle_name, bundle_version, selector_key, selector_value)
+		VALUES (?, ?, ?, ?);`
+
+	for key, value := range bundle.Kubernetes.NodeSelector {
+		_, err = tx.ExecContext(ctx, query, bundle.Name, bundle.Version, key, value)
+		if err != nil {
+			if violatesConstraint(err) {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	query = `INSERT INTO bundle_kubernetes_service_accounts
+		(bundle_name, bundle_version, group_name, service_account_name)
+		VALUES (?, ?, ?, ?);`
+
+	for group, sa := range bundle.Kubernetes.GroupServiceAccounts {
+		_, err = tx.ExecContext(ctx, query, bundle.Name, bundle.Version, group, sa)
+		if err != nil {
+			if violatesConstraint(err) {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeStringSlice(str string) []string {
+	if str == "" {
+		return []string{}
+	}
+
+	enc := strings.Split(str, ",")
+
+	for i, s := range enc {
+		enc[i], _ = url.QueryUnescape(s)
+	}
+
+	return enc
+}
+
+func encodeStringSlice(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+
+	enc := make([]string, len(strs))
+
+	for i, s := range strs {
+		enc[i] = url.QueryEscape(s)
+	}
+
+	return strings.Join(enc, ",")
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// CostAdd adds a record's usage to the total for its month, bundle, and
+// group.
+func (da MySQLDataAccess) CostAdd(ctx context.Context, record data.CostRecord) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.CostAdd")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO costs
+		(month, bundle_name, group_name, executions, seconds, cpu_seconds,
+			memory_gib_seconds, cost)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			executions = executions + VALUES(executions),
+			seconds = seconds + VALUES(seconds),
+			cpu_seconds = cpu_seconds + VALUES(cpu_seconds),
+			memory_gib_seconds = memory_gib_seconds + VALUES(memory_gib_seconds),
+			cost = cost + VALUES(cost);`
+
+	_, err = db.ExecContext(ctx, query, record.Month, record.Bundle,
+		record.Group, record.Executions, record.Seconds, record.CPUSeconds,
+		record.MemoryGiBSeconds, record.Cost)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// CostList returns the cost totals for the given month, or for every month
+// if month is empty, ordered by month, bundle, and group.
+func (da MySQLDataAccess) CostList(ctx context.Context, month string) ([]data.CostRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.CostList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `SELECT month, bundle_name, group_name, executions, seconds,
+			cpu_seconds, memory_gib_seconds, cost
+		FROM costs
+		WHERE ? = '' OR month = ?
+		ORDER BY month, bundle_name, group_name;`
+
+	rows, err := db.QueryContext(ctx, query, month, month)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.CostRecord{}
+	for rows.Next() {
+		var r data.CostRecord
+
+		err := rows.Scan(&r.Month, &r.Bundle, &r.Group, &r.Executions,
+			&r.Seconds, &r.CPUSeconds, &r.MemoryGiBSeconds, &r.Cost)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		list = append(list, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+func (da MySQLDataAccess) createCostsTable(ctx context.Context, conn *sql.Conn) error {
+	createCostsQuery := `CREATE TABLE costs (
+		month				VARCHAR(16) NOT NULL,
+		bundle_name			VARCHAR(128) NOT NULL,
+		group_name			VARCHAR(128) NOT NULL,
+		executions			BIGINT NOT NULL,
+		seconds				DOUBLE PRECISION NOT NULL,
+		cpu_seconds			DOUBLE PRECISION NOT NULL,
+		memory_gib_seconds	DOUBLE PRECISION NOT NULL,
+		cost				DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY			(month, bundle_name, group_name)
+	);`
+
+	_, err := conn.ExecContext(ctx, createCostsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
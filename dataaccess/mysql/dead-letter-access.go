@@ -0,0 +1,239 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// DeadLetterCreate stores an undeliverable response, setting its ID.
+func (da MySQLDataAccess) DeadLetterCreate(ctx context.Context, letter *data.DeadLetter) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.DeadLetterCreate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO dead_letters
+		(request_id, adapter, channel_id, message, error, attempts, created, last_attempt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
+
+	result, err := db.ExecContext(ctx, query,
+		letter.RequestID, letter.Adapter, letter.ChannelID, letter.Message,
+		letter.Error, letter.Attempts, letter.Created, letter.LastAttempt)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	letter.ID, err = result.LastInsertId()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// DeadLetterDelete deletes a dead letter.
+func (da MySQLDataAccess) DeadLetterDelete(ctx context.Context, id int64) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.DeadLetterDelete")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM dead_letters WHERE dead_letter_id=?;`, id)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchDeadLetter
+	}
+
+	return nil
+}
+
+// DeadLetterGet returns a dead letter.
+func (da MySQLDataAccess) DeadLetterGet(ctx context.Context, id int64) (data.DeadLetter, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.DeadLetterGet")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.DeadLetter{}, err
+	}
+
+	const query = `SELECT dead_letter_id, request_id, adapter, channel_id,
+			message, error, attempts, created, last_attempt
+		FROM dead_letters
+		WHERE dead_letter_id=?;`
+
+	var l data.DeadLetter
+
+	err = db.QueryRowContext(ctx, query, id).Scan(&l.ID, &l.RequestID,
+		&l.Adapter, &l.ChannelID, &l.Message, &l.Error, &l.Attempts,
+		&l.Created, &l.LastAttempt)
+	switch {
+	case err == sql.ErrNoRows:
+		return data.DeadLetter{}, errs.ErrNoSuchDeadLetter
+	case err != nil:
+		return data.DeadLetter{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return l, nil
+}
+
+// DeadLetterList returns all dead letters, oldest first.
+func (da MySQLDataAccess) DeadLetterList(ctx context.Context) ([]data.DeadLetter, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.DeadLetterList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `SELECT dead_letter_id, request_id, adapter, channel_id,
+			message, error, attempts, created, last_attempt
+		FROM dead_letters
+		ORDER BY dead_letter_id;`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.DeadLetter{}
+
+	for rows.Next() {
+		var l data.DeadLetter
+
+		err = rows.Scan(&l.ID, &l.RequestID, &l.Adapter, &l.ChannelID,
+			&l.Message, &l.Error, &l.Attempts, &l.Created, &l.LastAttempt)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		list = append(list, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// DeadLetterPurge deletes every dead letter created before the given time,
+// returning the number deleted.
+func (da MySQLDataAccess) DeadLetterPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.DeadLetterPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM dead_letters WHERE created < ?;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
+// DeadLetterUpdate records the outcome of a failed redelivery attempt:
+// the error, attempt count, and attempt time are updated.
+func (da MySQLDataAccess) DeadLetterUpdate(ctx context.Context, letter data.DeadLetter) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.DeadLetterUpdate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE dead_letters
+		SET error=?, attempts=?, last_attempt=?
+		WHERE dead_letter_id=?;`
+
+	result, err := db.ExecContext(ctx, query, letter.Error, letter.Attempts, letter.LastAttempt, letter.ID)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchDeadLetter
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createDeadLettersTable(ctx context.Context, conn *sql.Conn) error {
+	createDeadLettersQuery := `CREATE TABLE dead_letters (
+		dead_letter_id	BIGINT NOT NULL AUTO_INCREMENT,
+		request_id		BIGINT NOT NULL,
+		adapter			VARCHAR(64) NOT NULL,
+		channel_id		VARCHAR(255) NOT NULL,
+		message			MEDIUMTEXT NOT NULL,
+		error			TEXT NOT NULL,
+		attempts		INT NOT NULL,
+		created			DATETIME(6) NOT NULL,
+		last_attempt	DATETIME(6) NOT NULL,
+		PRIMARY KEY		(dead_letter_id)
+	);`
+
+	_, err := conn.ExecContext(ctx, createDeadLettersQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
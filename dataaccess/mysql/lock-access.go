@@ -0,0 +1,228 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// LockAcquire attempts to acquire the named lock on behalf of owner for the
+// duration of ttl. If the lock is held by a different owner and hasn't yet
+// expired, the current lock is returned along with errs.ErrLockHeld.
+func (da MySQLDataAccess) LockAcquire(ctx context.Context, name, owner, username string, ttl time.Duration) (data.Lock, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.LockAcquire")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.Lock{}, err
+	}
+
+	now := time.Now().UTC()
+	lock := data.Lock{
+		Name:       name,
+		Owner:      owner,
+		UserName:   username,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// MySQL's upsert can't be made conditional, so any existing lock is
+	// read with a row lock first. It's only overwritten if it's expired or
+	// already belongs to this owner, so the whole thing is atomic.
+	query := `SELECT name, owner, username, acquired_at, expires_at
+		FROM locks
+		WHERE name=?
+		FOR UPDATE;`
+
+	held := data.Lock{}
+	err = tx.
+		QueryRowContext(ctx, query, name).
+		Scan(&held.Name, &held.Owner, &held.UserName, &held.AcquiredAt, &held.ExpiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		tx.Rollback()
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	case held.Owner != owner && !held.ExpiresAt.Before(now):
+		tx.Rollback()
+		return held, errs.ErrLockHeld
+	}
+
+	query = `INSERT INTO locks (name, owner, username, acquired_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE owner=VALUES(owner), username=VALUES(username),
+			acquired_at=VALUES(acquired_at), expires_at=VALUES(expires_at);`
+
+	_, err = tx.ExecContext(ctx, query, lock.Name, lock.Owner, lock.UserName, lock.AcquiredAt, lock.ExpiresAt)
+	if err != nil {
+		tx.Rollback()
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return lock, nil
+}
+
+// LockGet returns the named lock. An error is returned if the lock doesn't
+// exist or has expired.
+func (da MySQLDataAccess) LockGet(ctx context.Context, name string) (data.Lock, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.LockGet")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.Lock{}, err
+	}
+
+	query := `SELECT name, owner, username, acquired_at, expires_at
+		FROM locks
+		WHERE name=? AND expires_at >= ?`
+
+	lock := data.Lock{}
+	err = db.
+		QueryRowContext(ctx, query, name, time.Now().UTC()).
+		Scan(&lock.Name, &lock.Owner, &lock.UserName, &lock.AcquiredAt, &lock.ExpiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return data.Lock{}, errs.ErrNoSuchLock
+	case err != nil:
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return lock, nil
+}
+
+// LockPurge deletes every lock that expired before the given time,
+// returning the number deleted.
+func (da MySQLDataAccess) LockPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.LockPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM locks WHERE expires_at < ?;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
+// LockRelease releases the named lock. An error is returned if the lock
+// isn't held by owner.
+func (da MySQLDataAccess) LockRelease(ctx context.Context, name, owner string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.LockRelease")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM locks WHERE name=? AND owner=?;`
+	result, err := db.ExecContext(ctx, query, name, owner)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	} else if rows == 0 {
+		return errs.ErrNoSuchLock
+	}
+
+	return nil
+}
+
+// LockRenew extends the expiry of the named lock to ttl from now. An error
+// is returned if the lock isn't held by owner.
+func (da MySQLDataAccess) LockRenew(ctx context.Context, name, owner string, ttl time.Duration) (data.Lock, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.LockRenew")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.Lock{}, err
+	}
+
+	query := `UPDATE locks SET expires_at=?
+		WHERE name=? AND owner=?;`
+
+	result, err := db.ExecContext(ctx, query, time.Now().UTC().Add(ttl), name, owner)
+	if err != nil {
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return data.Lock{}, errs.ErrNoSuchLock
+	}
+
+	query = `SELECT name, owner, username, acquired_at, expires_at
+		FROM locks
+		WHERE name=? AND owner=?`
+
+	lock := data.Lock{}
+	err = db.
+		QueryRowContext(ctx, query, name, owner).
+		Scan(&lock.Name, &lock.Owner, &lock.UserName, &lock.AcquiredAt, &lock.ExpiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return data.Lock{}, errs.ErrNoSuchLock
+	case err != nil:
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return lock, nil
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+)
+
+// A migration is a versioned schema change. Migrations are applied in order,
+// each in its own transaction, after the base tables have been created, and
+// are recorded in the schema_migrations table so that each is applied
+// exactly once.
+//
+// Migrations must never be edited or reordered once released: to change the
+// schema further, append a new one. Note that MySQL implicitly commits most
+// DDL statements, so a migration that fails part way may need to be repaired
+// by hand.
+type migration struct {
+	Version     int
+	Description string
+	Migrate     func(ctx context.Context, tx *sql.Tx) error
+}
+
+// The base schema already includes every change made by the first six
+// Postgres migrations, so later changes start at version 1.
+var migrations = []migration{
+	{1, "channel quiet hours", migrateChannelQuietHours},
+	{2, "digest templates", migrateDigestTemplates},
+	{3, "revoked tokens", migrateRevokedTokens},
+	{4, "bundle job namespaces", migrateBundleNamespaces},
+}
+
+// migrationLock is the name of the advisory lock that serializes
+// controllers applying migrations.
+const migrationLock = "gort_schema_migrations"
+
+// runMigrations applies any migrations that haven't yet been applied to the
+// Gort database.
+func (da MySQLDataAccess) runMigrations(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version			INT NOT NULL,
+		description		TEXT NOT NULL,
+		applied_at		DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		PRIMARY KEY		(version)
+	);`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// Serialize controllers starting at the same time, so that only one of
+	// them applies each migration. Tables can't be locked inside of a
+	// transaction, so a named lock is held on the connection instead.
+	var locked sql.NullInt64
+	err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 60);`, migrationLock).Scan(&locked)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if locked.Int64 != 1 {
+		return gerr.Wrap(errs.ErrDataAccess, fmt.Errorf("timed out waiting for lock %q", migrationLock))
+	}
+	defer conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?);`, migrationLock)
+
+	for _, m := range migrations {
+		if err := da.runMigration(ctx, conn, m); err != nil {
+			return gerr.Wrap(fmt.Errorf("migration %d (%s) failed", m.Version, m.Description), err)
+		}
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) runMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	applied := false
+	query := `SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version=?)`
+	if err := tx.QueryRowContext(ctx, query, m.Version).Scan(&applied); err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if applied {
+		return tx.Rollback()
+	}
+
+	if err := m.Migrate(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query = `INSERT INTO schema_migrations (version, description) VALUES (?, ?);`
+	if _, err := tx.ExecContext(ctx, query, m.Version, m.Description); err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	log.WithField("version", m.Version).
+		WithField("description", m.Description).
+		Info("Applied database migration")
+
+	return nil
+}
+
+// migrateChannelQuietHours adds a column to the channel_settings table that
+// holds a channel's quiet hours, encoded as JSON.
+func migrateChannelQuietHours(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	ALTER TABLE channel_settings ADD COLUMN quiet_hours TEXT NOT NULL DEFAULT ('');
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateDigestTemplates adds a column for the digest template to the
+// bundle and bundle command template tables.
+func migrateDigestTemplates(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	ALTER TABLE bundle_templates ADD COLUMN digest TEXT NOT NULL DEFAULT ('');
+	ALTER TABLE bundle_command_templates ADD COLUMN digest TEXT NOT NULL DEFAULT ('');
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateRevokedTokens adds the revocation list of self-contained API tokens.
+// Each entry is kept until the token it revokes would have expired.
+func migrateRevokedTokens(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS revoked_tokens (
+		token_id		VARCHAR(255) NOT NULL,
+		valid_until		DATETIME(6) NOT NULL,
+		PRIMARY KEY		(token_id)
+	);
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateBundleNamespaces adds a column for the namespace that a bundle's
+// Kubernetes jobs run in to the bundle_kubernetes table.
+func migrateBundleNamespaces(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	ALTER TABLE bundle_kubernetes ADD COLUMN namespace TEXT NOT NULL DEFAULT ('');
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,819 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+
+	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	DatabaseGort = "gort"
+	DriverName   = "mysql"
+)
+
+// connections is the number of times that pool has been called.
+var connections uint64
+
+// ConnectionCount returns the number of times that the Gort database's
+// connection pool has been used by all MySQLDataAccess values since the
+// process started. Every data access call uses it once, so this
+// approximates the number of database round-trips.
+func ConnectionCount() uint64 {
+	return atomic.LoadUint64(&connections)
+}
+
+// MySQLDataAccess is a data access implementation backed by a MySQL or
+// MariaDB database.
+type MySQLDataAccess struct {
+	configs data.DatabaseConfigs
+	dbs     map[string]*sql.DB
+	mutex   *sync.Mutex
+}
+
+// NewMySQLDataAccess returns a new MySQLDataAccess based on the
+// supplied config.
+func NewMySQLDataAccess(configs data.DatabaseConfigs) MySQLDataAccess {
+	return MySQLDataAccess{
+		configs: configs,
+		dbs:     map[string]*sql.DB{},
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Initialize sets up the database.
+func (da MySQLDataAccess) Initialize(ctx context.Context) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.Initialize")
+	defer sp.End()
+
+	if err := da.initializeGortData(ctx); err != nil {
+		return gerr.Wrap(fmt.Errorf("failed to initialize gort data"), err)
+	}
+	if err := da.initializeAuditData(ctx); err != nil {
+		return gerr.Wrap(fmt.Errorf("failed to initialize audit data"), err)
+	}
+
+	return nil
+}
+
+// tableCreator creates a table (or a set of related tables) if the table
+// with the given name doesn't yet exist.
+type tableCreator struct {
+	Table  string
+	Create func(ctx context.Context, conn *sql.Conn) error
+}
+
+func (da MySQLDataAccess) initializeAuditData(ctx context.Context) error {
+	// Does the database exist? If not, create it.
+	err := da.ensureDatabaseExists(ctx, DatabaseGort)
+	if err != nil {
+		return gerr.Wrap(fmt.Errorf("cannot ensure gort database exists"), err)
+	}
+
+	// Establish a connection to the "gort" database
+	conn, err := da.connect(ctx)
+	if err != nil {
+		return gerr.Wrap(fmt.Errorf("cannot connect to gort database"), err)
+	}
+	defer conn.Close()
+
+	return da.createTables(ctx, conn, []tableCreator{
+		{"commands", da.createCommandsTable},
+		{"request_payloads", da.createRequestPayloadsTable},
+		{"request_logs", da.createRequestLogsTable},
+		{"rest_audit", da.createRestAuditTable},
+		{"dead_letters", da.createDeadLettersTable},
+		{"outbox", da.createOutboxTable},
+	})
+}
+
+func (da MySQLDataAccess) initializeGortData(ctx context.Context) error {
+	// Does the database exist? If not, create it.
+	err := da.ensureDatabaseExists(ctx, DatabaseGort)
+	if err != nil {
+		return err
+	}
+
+	// Establish a connection to the "gort" database
+	conn, err := da.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Tables are created in dependency order, so that each table's foreign
+	// keys refer to tables that already exist.
+	err = da.createTables(ctx, conn, []tableCreator{
+		{"users", da.createUsersTable},
+		{"user_adapter_ids", da.createUsersAdapterIDsTable},
+		{"gort_groups", da.createGroupsTable},
+		{"groupusers", da.createGroupUsersTable},
+		{"tokens", da.createTokensTable},
+		{"bundles", da.createBundlesTables},
+		{"bundle_kubernetes", da.createBundleKubernetesTables},
+		{"roles", da.createRolesTables},
+		{"configs", da.createConfigsTable},
+		{"channel_presence", da.createChannelPresenceTable},
+		{"channel_settings", da.createChannelSettingsTable},
+		{"locks", da.createLocksTable},
+		{"macros", da.createMacrosTable},
+		{"aliases", da.createAliasesTable},
+		{"option_defaults", da.createOptionDefaultsTable},
+		{"secret_outputs", da.createSecretOutputsTable},
+		{"costs", da.createCostsTable},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Apply any schema changes made since the tables were created
+	return da.runMigrations(ctx, conn)
+}
+
+// createTables calls each creator whose table doesn't yet exist.
+func (da MySQLDataAccess) createTables(ctx context.Context, conn *sql.Conn, creators []tableCreator) error {
+	for _, c := range creators {
+		exists, err := da.tableExists(ctx, c.Table, conn)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		if err := c.Create(ctx, conn); err != nil {
+			return gerr.Wrap(fmt.Errorf("failed to create %s table", c.Table), err)
+		}
+	}
+
+	return nil
+}
+
+// pool returns the connection pool of the Gort database, which is opened
+// the first time it's needed and held for the life of the data access
+// layer. Data access calls run their statements directly against the pool,
+// so a connection is only held for as long as each statement or transaction
+// needs it, and is then returned to the pool for reuse.
+func (da MySQLDataAccess) pool(ctx context.Context) (*sql.DB, error) {
+	db, err := da.open(ctx, DatabaseGort)
+	if err != nil {
+		return nil, gerr.WrapStr("failed to open Gort database", err)
+	}
+
+	atomic.AddUint64(&connections, 1)
+
+	return db, nil
+}
+
+// connect acquires a single connection from the Gort database's pool, for
+// work that has to happen in one session, like schema setup and migrations.
+// It must be closed to return it to the pool.
+func (da MySQLDataAccess) connect(ctx context.Context) (*sql.Conn, error) {
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Conn(ctx)
+}
+
+// The schema below is the MySQL equivalent of the Postgres schema after all
+// of its migrations. A few columns are renamed to avoid MySQL reserved
+// words, and columns that are part of a key are VARCHARs, since MySQL can't
+// index TEXT columns. Foreign keys cascade on update so that renames are a
+// single UPDATE.
+
+func (da MySQLDataAccess) createBundlesTables(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createBundlesQuery := `CREATE TABLE bundles (
+		gort_bundle_version INT NOT NULL CHECK(gort_bundle_version > 0),
+		name				VARCHAR(128) NOT NULL CHECK(name <> ''),
+		version				VARCHAR(128) NOT NULL CHECK(version <> ''),
+		author				TEXT,
+		homepage			TEXT,
+		description			TEXT NOT NULL CHECK(description <> ''),
+		long_description	TEXT,
+		image_repository	TEXT,
+		image_tag			TEXT,
+		install_timestamp	DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+		install_user		TEXT,
+		platform_os			TEXT NOT NULL DEFAULT (''),
+		platform_arch		TEXT NOT NULL DEFAULT (''),
+		output_filters		TEXT NOT NULL DEFAULT (''),
+		serverless_function	TEXT NOT NULL DEFAULT (''),
+		serverless_job		TEXT NOT NULL DEFAULT (''),
+		ssh					TEXT NOT NULL DEFAULT (''),
+		telemetry_attributes TEXT NOT NULL DEFAULT (''),
+		resource_cpu		TEXT NOT NULL DEFAULT (''),
+		resource_memory		TEXT NOT NULL DEFAULT (''),
+		review_status		TEXT NOT NULL DEFAULT (''),
+		review_user			TEXT NOT NULL DEFAULT (''),
+		review_timestamp	DATETIME(6),
+		review_comment		TEXT NOT NULL DEFAULT (''),
+		owners				TEXT NOT NULL DEFAULT (''),
+		PRIMARY KEY 		(name, version)
+	);
+
+	CREATE TABLE bundle_enabled (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		PRIMARY KEY			(bundle_name),
+		FOREIGN KEY 		(bundle_name, bundle_version) REFERENCES bundles(name, version)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_kubernetes_node_selectors (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		selector_key		VARCHAR(255) NOT NULL CHECK(selector_key <> ''),
+		selector_value		TEXT NOT NULL,
+		PRIMARY KEY			(bundle_name, bundle_version, selector_key),
+		FOREIGN KEY 		(bundle_name, bundle_version) REFERENCES bundles(name, version)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_kubernetes_service_accounts (
+		bundle_name				VARCHAR(128) NOT NULL,
+		bundle_version			VARCHAR(128) NOT NULL,
+		group_name				VARCHAR(128) NOT NULL CHECK(group_name <> ''),
+		service_account_name	TEXT NOT NULL CHECK(service_account_name <> ''),
+		PRIMARY KEY				(bundle_name, bundle_version, group_name),
+		FOREIGN KEY 			(bundle_name, bundle_version) REFERENCES bundles(name, version)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_permissions (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		perm_index			INT NOT NULL CHECK(perm_index >= 0),
+		permission			TEXT,
+		PRIMARY KEY			(bundle_name, bundle_version, perm_index),
+		FOREIGN KEY 		(bundle_name, bundle_version) REFERENCES bundles(name, version)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_templates (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		command				TEXT,
+		command_error		TEXT,
+		command_timeout		TEXT NOT NULL DEFAULT (''),
+		message				TEXT,
+		message_error		TEXT,
+		PRIMARY KEY			(bundle_name, bundle_version),
+		FOREIGN KEY 		(bundle_name, bundle_version) REFERENCES bundles(name, version)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_commands (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		name				VARCHAR(128) NOT NULL CHECK(name <> ''),
+		description			TEXT NOT NULL,
+		executable			TEXT NOT NULL,
+		long_description	TEXT,
+		exclusive			TEXT NOT NULL DEFAULT (''),
+		platform_os			TEXT NOT NULL DEFAULT (''),
+		platform_arch		TEXT NOT NULL DEFAULT (''),
+		cooldown			TEXT NOT NULL DEFAULT (''),
+		ansi				TEXT NOT NULL DEFAULT (''),
+		default_profile		TEXT NOT NULL DEFAULT (''),
+		ephemeral_output	BOOLEAN NOT NULL DEFAULT false,
+		ephemeral_ttl		TEXT NOT NULL DEFAULT (''),
+		secret_output		BOOLEAN NOT NULL DEFAULT false,
+		reaction_success	TEXT NOT NULL DEFAULT (''),
+		reaction_failure	TEXT NOT NULL DEFAULT (''),
+		usage_text			TEXT NOT NULL DEFAULT (''),
+		examples			TEXT NOT NULL DEFAULT (''),
+		native_help			BOOLEAN NOT NULL DEFAULT false,
+		timeout				TEXT NOT NULL DEFAULT (''),
+		PRIMARY KEY			(bundle_name, bundle_version, name),
+		FOREIGN KEY 		(bundle_name, bundle_version) REFERENCES bundles(name, version)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_command_triggers (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		command_name		VARCHAR(128) NOT NULL,
+		pattern				VARCHAR(255) NOT NULL,
+		channels			TEXT NOT NULL DEFAULT (''),
+		PRIMARY KEY			(bundle_name, bundle_version, command_name, pattern),
+		FOREIGN KEY 		(bundle_name, bundle_version, command_name)
+		REFERENCES 			bundle_commands(bundle_name, bundle_version, name)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_command_rules (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		command_name		VARCHAR(128) NOT NULL,
+		rule				VARCHAR(384) NOT NULL CHECK(rule <> ''),
+		PRIMARY KEY			(bundle_name, bundle_version, command_name, rule),
+		FOREIGN KEY 		(bundle_name, bundle_version, command_name)
+		REFERENCES 			bundle_commands(bundle_name, bundle_version, name)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_command_templates (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		command_name		VARCHAR(128) NOT NULL,
+		command				TEXT,
+		command_error		TEXT,
+		command_timeout		TEXT NOT NULL DEFAULT (''),
+		message				TEXT,
+		message_error		TEXT,
+		PRIMARY KEY			(bundle_name, bundle_version, command_name),
+		FOREIGN KEY 		(bundle_name, bundle_version, command_name)
+		REFERENCES 			bundle_commands(bundle_name, bundle_version, name)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_command_options (
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		command_name		VARCHAR(128) NOT NULL,
+		name				VARCHAR(128) NOT NULL CHECK(name <> ''),
+		description			TEXT NOT NULL,
+		is_sensitive		BOOLEAN NOT NULL DEFAULT false,
+		permissions			TEXT NOT NULL DEFAULT (''),
+		PRIMARY KEY			(bundle_name, bundle_version, command_name, name),
+		FOREIGN KEY 		(bundle_name, bundle_version, command_name)
+		REFERENCES 			bundle_commands(bundle_name, bundle_version, name)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE bundle_command_profiles (
+		bundle_name				VARCHAR(128) NOT NULL,
+		bundle_version			VARCHAR(128) NOT NULL,
+		command_name			VARCHAR(128) NOT NULL,
+		name					VARCHAR(128) NOT NULL CHECK(name <> ''),
+		description				TEXT NOT NULL DEFAULT (''),
+		env						TEXT NOT NULL DEFAULT (''),
+		env_secret				TEXT NOT NULL DEFAULT (''),
+		service_account_name	TEXT NOT NULL DEFAULT (''),
+		permission				TEXT NOT NULL DEFAULT (''),
+		cluster					TEXT NOT NULL DEFAULT (''),
+		PRIMARY KEY				(bundle_name, bundle_version, command_name, name),
+		FOREIGN KEY 			(bundle_name, bundle_version, command_name)
+		REFERENCES 				bundle_commands(bundle_name, bundle_version, name)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+	`
+
+	_, err = conn.ExecContext(ctx, createBundlesQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createBundleKubernetesTables(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createBundlesQuery := `CREATE TABLE bundle_kubernetes (
+		bundle_version 			VARCHAR(128) NOT NULL,
+		bundle_name				VARCHAR(128) NOT NULL,
+		service_account_name 	TEXT NOT NULL,
+		env_secret				TEXT NOT NULL,
+		cluster					TEXT NOT NULL DEFAULT (''),
+		INDEX					(bundle_name, bundle_version)
+	);
+	`
+
+	_, err = conn.ExecContext(ctx, createBundlesQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createConfigsTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createConfigsQuery := `CREATE TABLE configs (
+		bundle_name		VARCHAR(128) NOT NULL CHECK(bundle_name <> ''),
+		layer			VARCHAR(32) NOT NULL,
+		owner			VARCHAR(128) NOT NULL,
+		config_key		VARCHAR(255) NOT NULL,
+		value			TEXT NOT NULL,
+		secret			BOOLEAN NOT NULL,
+		INDEX			(bundle_name, layer, owner, config_key)
+	);`
+
+	_, err = conn.ExecContext(ctx, createConfigsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createGroupsTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createGroupQuery := `CREATE TABLE gort_groups (
+		groupname	VARCHAR(128) PRIMARY KEY,
+		deleted_at	DATETIME(6)
+	);`
+
+	_, err = conn.ExecContext(ctx, createGroupQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createGroupUsersTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createGroupUsersQuery := `CREATE TABLE groupusers (
+		groupname	VARCHAR(128) NOT NULL,
+		username	VARCHAR(128) NOT NULL,
+		PRIMARY KEY	(groupname, username),
+		FOREIGN KEY	(groupname) REFERENCES gort_groups(groupname)
+		ON UPDATE CASCADE,
+		FOREIGN KEY	(username) REFERENCES users(username)
+		ON UPDATE CASCADE
+	);`
+
+	_, err = conn.ExecContext(ctx, createGroupUsersQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createChannelPresenceTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createChannelPresenceQuery := `CREATE TABLE channel_presence (
+		adapter			VARCHAR(64) NOT NULL,
+		channel_id		VARCHAR(255) NOT NULL,
+		first_seen		DATETIME(6) NOT NULL,
+		greeted_at		DATETIME(6),
+		last_command_at	DATETIME(6),
+		PRIMARY KEY		(adapter, channel_id)
+	);`
+
+	_, err = conn.ExecContext(ctx, createChannelPresenceQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createChannelSettingsTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createChannelSettingsQuery := `CREATE TABLE channel_settings (
+		adapter			VARCHAR(64) NOT NULL,
+		channel_id		VARCHAR(255) NOT NULL,
+		default_bundle	VARCHAR(128) NOT NULL DEFAULT '',
+		PRIMARY KEY		(adapter, channel_id)
+	);`
+
+	_, err = conn.ExecContext(ctx, createChannelSettingsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createLocksTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createLocksQuery := `CREATE TABLE locks (
+		name			VARCHAR(255) NOT NULL CHECK(name <> ''),
+		owner			VARCHAR(255) NOT NULL,
+		username		VARCHAR(128) NOT NULL,
+		acquired_at		DATETIME(6) NOT NULL,
+		expires_at		DATETIME(6) NOT NULL,
+		PRIMARY KEY		(name)
+	);`
+
+	_, err = conn.ExecContext(ctx, createLocksQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createMacrosTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createMacrosQuery := `CREATE TABLE macros (
+		layer			VARCHAR(32) NOT NULL,
+		owner			VARCHAR(128) NOT NULL CHECK(owner <> ''),
+		name			VARCHAR(128) NOT NULL CHECK(name <> ''),
+		command			TEXT NOT NULL,
+		PRIMARY KEY		(layer, owner, name)
+	);`
+
+	_, err = conn.ExecContext(ctx, createMacrosQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createAliasesTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createAliasesQuery := `CREATE TABLE aliases (
+		owner			VARCHAR(128) NOT NULL,
+		name			VARCHAR(128) NOT NULL CHECK(name <> ''),
+		command			TEXT NOT NULL CHECK(command <> ''),
+		PRIMARY KEY		(owner, name)
+	);`
+
+	_, err = conn.ExecContext(ctx, createAliasesQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createOptionDefaultsTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createOptionDefaultsQuery := `CREATE TABLE option_defaults (
+		bundle_name		VARCHAR(128) NOT NULL CHECK(bundle_name <> ''),
+		command_name	VARCHAR(128) NOT NULL CHECK(command_name <> ''),
+		layer			VARCHAR(32) NOT NULL,
+		owner			VARCHAR(128) NOT NULL,
+		option_name		VARCHAR(128) NOT NULL CHECK(option_name <> ''),
+		value			TEXT NOT NULL,
+		PRIMARY KEY		(layer, owner, bundle_name, command_name, option_name)
+	);`
+
+	_, err = conn.ExecContext(ctx, createOptionDefaultsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createRolesTables(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createRolesQuery := `CREATE TABLE roles (
+		role_name		VARCHAR(128) NOT NULL,
+		PRIMARY KEY 	(role_name)
+	);
+
+	CREATE TABLE group_roles (
+		group_name		VARCHAR(128) NOT NULL,
+		role_name		VARCHAR(128) NOT NULL,
+		PRIMARY KEY		(group_name, role_name),
+		FOREIGN KEY 	(group_name) REFERENCES gort_groups(groupname)
+		ON DELETE CASCADE ON UPDATE CASCADE,
+		FOREIGN KEY 	(role_name) REFERENCES roles(role_name)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+
+	CREATE TABLE role_permissions (
+		role_name			VARCHAR(128) NOT NULL,
+		bundle_name			VARCHAR(128) NOT NULL,
+		permission			VARCHAR(255) NOT NULL,
+		PRIMARY KEY			(role_name, bundle_name, permission),
+		FOREIGN KEY 		(role_name) REFERENCES roles(role_name)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+	`
+
+	_, err = conn.ExecContext(ctx, createRolesQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createTokensTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createTokensQuery := `CREATE TABLE tokens (
+		token       VARCHAR(255),
+		username    VARCHAR(128) NOT NULL,
+		valid_from  DATETIME(6),
+		valid_until DATETIME(6),
+		PRIMARY KEY (username),
+		FOREIGN KEY (username) REFERENCES users(username)
+		ON UPDATE CASCADE
+	);
+
+	CREATE UNIQUE INDEX tokens_token ON tokens (token);
+	`
+
+	_, err = conn.ExecContext(ctx, createTokensQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createUsersTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createUserQuery := `CREATE TABLE users (
+		email         	TEXT,
+		full_name     	TEXT,
+		password_hash 	TEXT,
+		username 		VARCHAR(128) PRIMARY KEY,
+		deleted_at		DATETIME(6),
+		service_account	BOOLEAN NOT NULL DEFAULT false
+	);`
+
+	_, err = conn.ExecContext(ctx, createUserQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createUsersAdapterIDsTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createTableQuery := `CREATE TABLE user_adapter_ids (
+		username            VARCHAR(128) NOT NULL,
+		adapter             VARCHAR(64) NOT NULL,
+		id                  VARCHAR(255) NOT NULL,
+		CONSTRAINT          unq_adapter_id UNIQUE(username, adapter),
+		PRIMARY KEY         (adapter, id),
+		FOREIGN KEY         (username) REFERENCES users(username)
+		ON DELETE CASCADE ON UPDATE CASCADE
+	);
+	`
+
+	_, err = conn.ExecContext(ctx, createTableQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// ensureDatabaseExists creates the dbname database if it doesn't already
+// exist. Comparisons in the database are case-sensitive, as they are in
+// Postgres.
+func (da MySQLDataAccess) ensureDatabaseExists(ctx context.Context, dbName string) error {
+	db, err := da.open(ctx, "")
+	if err != nil {
+		return gerr.WrapStr("failed to open mysql server", err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Identifiers can't be parameterized, but dbName is always a constant.
+	query := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;", dbName)
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess,
+			gerr.Wrap(fmt.Errorf("failed to create database"),
+				err))
+	}
+
+	return nil
+}
+
+// dsn returns the data source name for the named database. An empty
+// databaseName connects to the server without selecting a database.
+func (da MySQLDataAccess) dsn(databaseName string) string {
+	cfg := mysql.NewConfig()
+	cfg.User = da.configs.User
+	cfg.Passwd = da.configs.Password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s:%d", da.configs.Host, da.configs.Port)
+	cfg.DBName = databaseName
+	cfg.Collation = "utf8mb4_bin"
+	cfg.Loc = time.UTC
+	cfg.ParseTime = true
+	cfg.MultiStatements = true
+
+	// Report matched rather than changed rows, as Postgres does, so that an
+	// UPDATE that changes nothing still reports that its row exists.
+	cfg.ClientFoundRows = true
+
+	if da.configs.SSLEnabled {
+		cfg.TLSConfig = "true"
+	}
+
+	return cfg.FormatDSN()
+}
+
+func (da MySQLDataAccess) open(ctx context.Context, databaseName string) (*sql.DB, error) {
+	da.mutex.Lock()
+	defer da.mutex.Unlock()
+
+	if db, exists := da.dbs[databaseName]; exists {
+		return db, nil
+	}
+
+	db, err := sql.Open(DriverName, da.dsn(databaseName))
+	if err != nil {
+		return nil, gerr.WrapStr(fmt.Sprintf("failed to open database %q", databaseName), err)
+	}
+
+	if da.configs.MaxIdleConnections != 0 {
+		db.SetMaxIdleConns(da.configs.MaxIdleConnections)
+	}
+	if da.configs.MaxOpenConnections != 0 {
+		db.SetMaxOpenConns(da.configs.MaxOpenConnections)
+	}
+	if da.configs.ConnectionMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(da.configs.ConnectionMaxIdleTime)
+	}
+	if da.configs.ConnectionMaxLifetime != 0 {
+		db.SetConnMaxLifetime(da.configs.ConnectionMaxLifetime)
+	}
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	da.dbs[databaseName] = db
+
+	return db, nil
+}
+
+func (da MySQLDataAccess) tableExists(ctx context.Context, table string, conn *sql.Conn) (bool, error) {
+	const query = `SELECT EXISTS(SELECT 1 FROM information_schema.tables
+		WHERE table_schema=DATABASE() AND table_name=?)`
+
+	var exists bool
+	if err := conn.QueryRowContext(ctx, query, table).Scan(&exists); err != nil {
+		return false, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return exists, nil
+}
+
+// violatesConstraint returns true if err reports that a statement violated
+// a NOT NULL, unique, foreign key, or check constraint. It's the MySQL
+// equivalent of checking a Postgres error for "violates".
+func violatesConstraint(err error) bool {
+	var merr *mysql.MySQLError
+	if !errors.As(err, &merr) {
+		return false
+	}
+
+	switch merr.Number {
+	case 1048, // ER_BAD_NULL_ERROR
+		1062, // ER_DUP_ENTRY
+		1364, // ER_NO_DEFAULT_FOR_FIELD
+		1451, // ER_ROW_IS_REFERENCED_2
+		1452, // ER_NO_REFERENCED_ROW_2
+		3819, // ER_CHECK_CONSTRAINT_VIOLATED (MySQL)
+		4025: // ER_CONSTRAINT_FAILED (MariaDB)
+		return true
+	default:
+		return false
+	}
+}
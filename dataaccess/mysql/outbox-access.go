@@ -0,0 +1,247 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+const outboxColumns = `request_id, adapter, channel_id, envelope, attempts,
+			created, last_attempt, delivered`
+
+// OutboxCreate stores a response before it's sent. If an entry already
+// exists for the request, it's left unchanged.
+func (da MySQLDataAccess) OutboxCreate(ctx context.Context, entry data.OutboxEntry) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.OutboxCreate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT IGNORE INTO outbox
+		(request_id, adapter, channel_id, envelope, attempts, created, last_attempt)
+		VALUES (?, ?, ?, ?, ?, ?, ?);`
+
+	_, err = db.ExecContext(ctx, query,
+		entry.RequestID, entry.Adapter, entry.ChannelID, entry.Envelope,
+		entry.Attempts, entry.Created, entry.LastAttempt)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// OutboxGet returns the outbox entry for a request.
+func (da MySQLDataAccess) OutboxGet(ctx context.Context, requestID int64) (data.OutboxEntry, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.OutboxGet")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.OutboxEntry{}, err
+	}
+
+	query := `SELECT ` + outboxColumns + `
+		FROM outbox
+		WHERE request_id=?;`
+
+	e, err := scanOutboxEntry(db.QueryRowContext(ctx, query, requestID))
+	switch {
+	case err == sql.ErrNoRows:
+		return data.OutboxEntry{}, errs.ErrNoSuchOutboxEntry
+	case err != nil:
+		return data.OutboxEntry{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return e, nil
+}
+
+// OutboxMarkAttempted records a delivery attempt, incrementing the entry's
+// attempt count and setting its last attempt time.
+func (da MySQLDataAccess) OutboxMarkAttempted(ctx context.Context, requestID int64, at time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.OutboxMarkAttempted")
+	defer sp.End()
+
+	const query = `UPDATE outbox
+		SET attempts=attempts+1, last_attempt=?
+		WHERE request_id=?;`
+
+	return da.outboxUpdate(ctx, query, requestID, at)
+}
+
+// OutboxMarkDelivered marks an entry as delivered, so that it's no longer
+// resent. Marking an entry that's already delivered has no effect.
+func (da MySQLDataAccess) OutboxMarkDelivered(ctx context.Context, requestID int64, at time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.OutboxMarkDelivered")
+	defer sp.End()
+
+	const query = `UPDATE outbox
+		SET delivered=COALESCE(delivered, ?)
+		WHERE request_id=?;`
+
+	return da.outboxUpdate(ctx, query, requestID, at)
+}
+
+// OutboxPending returns the undelivered entries whose most recent attempt
+// was before the given time, oldest first.
+func (da MySQLDataAccess) OutboxPending(ctx context.Context, before time.Time) ([]data.OutboxEntry, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.OutboxPending")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + outboxColumns + `
+		FROM outbox
+		WHERE delivered IS NULL AND last_attempt < ?
+		ORDER BY created;`
+
+	rows, err := db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.OutboxEntry{}
+
+	for rows.Next() {
+		e, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		list = append(list, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// OutboxPurge deletes every entry delivered before the given time,
+// returning the number deleted.
+func (da MySQLDataAccess) OutboxPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.OutboxPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM outbox WHERE delivered < ?;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
+// outboxUpdate executes an update of a single outbox entry, returning
+// ErrNoSuchOutboxEntry if there's no entry for the request.
+func (da MySQLDataAccess) outboxUpdate(ctx context.Context, query string, requestID int64, at time.Time) error {
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, query, at, requestID)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchOutboxEntry
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createOutboxTable(ctx context.Context, conn *sql.Conn) error {
+	createOutboxQuery := `CREATE TABLE outbox (
+		request_id		BIGINT NOT NULL,
+		adapter			VARCHAR(64) NOT NULL,
+		channel_id		VARCHAR(255) NOT NULL,
+		envelope		MEDIUMTEXT NOT NULL,
+		attempts		INT NOT NULL,
+		created			DATETIME(6) NOT NULL,
+		last_attempt	DATETIME(6) NOT NULL,
+		delivered		DATETIME(6),
+		PRIMARY KEY		(request_id)
+	);
+
+	CREATE INDEX outbox_pending ON outbox (delivered, last_attempt);`
+
+	_, err := conn.ExecContext(ctx, createOutboxQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOutboxEntry(row rowScanner) (data.OutboxEntry, error) {
+	var e data.OutboxEntry
+	var delivered sql.NullTime
+
+	err := row.Scan(&e.RequestID, &e.Adapter, &e.ChannelID, &e.Envelope,
+		&e.Attempts, &e.Created, &e.LastAttempt, &delivered)
+	if err != nil {
+		return data.OutboxEntry{}, err
+	}
+
+	if delivered.Valid {
+		e.Delivered = delivered.Time
+	}
+
+	return e, nil
+}
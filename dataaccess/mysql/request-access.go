@@ -0,0 +1,664 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// RequestArchive stores the response payload of a request, replacing any
+// that was already stored.
+func (da MySQLDataAccess) RequestArchive(ctx context.Context, requestID int64, payload data.RequestPayload) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestArchive")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO request_payloads (request_id, archived_at,
+			title, error_code, partial, output, rendered, truncated)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?
+		FROM DUAL
+		WHERE EXISTS (SELECT 1 FROM commands WHERE request_id=?)
+		ON DUPLICATE KEY UPDATE
+			archived_at=VALUES(archived_at), title=VALUES(title),
+			error_code=VALUES(error_code), partial=VALUES(partial),
+			output=VALUES(output), rendered=VALUES(rendered),
+			truncated=VALUES(truncated);`
+
+	result, err := db.ExecContext(ctx, query,
+		requestID,
+		payload.ArchivedAt,
+		payload.Title,
+		payload.ErrorCode,
+		payload.Partial,
+		strings.Join(payload.Output, "\n"),
+		payload.Rendered,
+		payload.Truncated,
+		requestID)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	} else if n == 0 {
+		return errs.ErrNoSuchRequest
+	}
+
+	return nil
+}
+
+// RequestArchivePurge deletes every payload archived before the given time,
+// returning the number deleted.
+func (da MySQLDataAccess) RequestArchivePurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestArchivePurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM request_payloads WHERE archived_at < ?;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
+func (da MySQLDataAccess) RequestBegin(ctx context.Context, req *data.CommandRequest) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestBegin")
+	defer sp.End()
+
+	if req.RequestID != 0 {
+		return fmt.Errorf("command request ID already set")
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO commands (bundle_name, bundle_version, command_name,
+		command_executable, command_parameters, adapter, user_id,
+		user_email, channel_id, gort_user_name, timestamp, replay_of)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx,
+		req.Bundle.Name,
+		req.Bundle.Version,
+		req.Command.Name,
+		encodeStringSlice(req.Command.Executable),
+		req.RedactedParameters(config.GetRedactPatterns()...).String(),
+		req.Adapter,
+		req.UserID,
+		req.UserEmail,
+		req.ChannelID,
+		req.UserName,
+		req.Timestamp,
+		req.ReplayOf)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	req.RequestID, err = result.LastInsertId()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) RequestError(ctx context.Context, req data.CommandRequest, err error) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "mysql.RequestUpdate")
+	defer sp.End()
+
+	return da.RequestClose(ctx, data.NewCommandResponseEnvelope(req, data.WithError("", err, 1)))
+}
+
+// RequestGet returns the record of a single request.
+func (da MySQLDataAccess) RequestGet(ctx context.Context, requestID int64) (data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestGet")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+
+	query := requestRecordQuery + ` WHERE request_id=?`
+
+	rows, err := db.QueryContext(ctx, query, requestID)
+	if err != nil {
+		return data.RequestRecord{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return data.RequestRecord{}, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		return data.RequestRecord{}, errs.ErrNoSuchRequest
+	}
+
+	record, err := scanRequestRecord(rows)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+	rows.Close()
+
+	record.Payload, err = da.getRequestPayload(ctx, db, requestID)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+
+	record.Logs, err = da.getRequestLogs(ctx, db, requestID)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+
+	return record, nil
+}
+
+// getRequestPayload returns the archived payload of a request, or nil if
+// there isn't one.
+func (da MySQLDataAccess) getRequestPayload(ctx context.Context, db *sql.DB, requestID int64) (*data.RequestPayload, error) {
+	const query = `SELECT archived_at, title, error_code, partial, output, rendered, truncated
+		FROM request_payloads
+		WHERE request_id=?`
+
+	var p data.RequestPayload
+	var output string
+
+	err := db.QueryRowContext(ctx, query, requestID).Scan(
+		&p.ArchivedAt, &p.Title, &p.ErrorCode, &p.Partial, &output, &p.Rendered, &p.Truncated)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if output != "" {
+		p.Output = strings.Split(output, "\n")
+	}
+
+	return &p, nil
+}
+
+// RequestList returns the records of up to limit of the most recent
+// requests, newest first. If limit is zero or less all records are returned.
+// RequestHistory returns the records of up to limit of the most recent
+// commands that the user made in the channel, newest first. Requests that
+// never matched a command, and replays, are excluded. If limit is zero or
+// less all matching records are returned.
+func (da MySQLDataAccess) RequestHistory(ctx context.Context, adapter, channelID, userID string, limit int) ([]data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestHistory")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := requestRecordQuery + ` WHERE adapter=? AND channel_id=? AND user_id=?
+		AND command_name <> '' AND replay_of=0
+		ORDER BY request_id DESC`
+	args := []interface{}{adapter, channelID, userID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.RequestRecord{}
+
+	for rows.Next() {
+		r, err := scanRequestRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+func (da MySQLDataAccess) RequestList(ctx context.Context, limit int) ([]data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := requestRecordQuery + ` ORDER BY request_id DESC`
+	args := []interface{}{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.RequestRecord{}
+
+	for rows.Next() {
+		r, err := scanRequestRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// RequestLogAppend attaches a log entry to a request.
+func (da MySQLDataAccess) RequestLogAppend(ctx context.Context, requestID int64, entry data.RequestLogEntry) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestLogAppend")
+	defer sp.End()
+
+	fields, err := json.Marshal(entry.Fields)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO request_logs (request_id, timestamp, level, message, fields)
+		SELECT ?, ?, ?, ?, ?
+		FROM DUAL
+		WHERE EXISTS (SELECT 1 FROM commands WHERE request_id=?);`
+
+	result, err := db.ExecContext(ctx, query,
+		requestID, entry.Timestamp, entry.Level, entry.Message, string(fields), requestID)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	} else if n == 0 {
+		return errs.ErrNoSuchRequest
+	}
+
+	return nil
+}
+
+// getRequestLogs returns the log entries attached to a request, in the order
+// they were appended.
+func (da MySQLDataAccess) getRequestLogs(ctx context.Context, db *sql.DB, requestID int64) ([]data.RequestLogEntry, error) {
+	const query = `SELECT timestamp, level, message, fields
+		FROM request_logs
+		WHERE request_id=?
+		ORDER BY id`
+
+	rows, err := db.QueryContext(ctx, query, requestID)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	var logs []data.RequestLogEntry
+
+	for rows.Next() {
+		var e data.RequestLogEntry
+		var fields string
+
+		if err := rows.Scan(&e.Timestamp, &e.Level, &e.Message, &fields); err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		if err := json.Unmarshal([]byte(fields), &e.Fields); err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		logs = append(logs, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return logs, nil
+}
+
+func (da MySQLDataAccess) RequestUpdate(ctx context.Context, req data.CommandRequest) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestUpdate")
+	defer sp.End()
+
+	if req.RequestID == 0 {
+		return fmt.Errorf("command request ID unset")
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE commands
+		SET bundle_name=?, bundle_version=?, command_name=?,
+			command_executable=?, command_parameters=?, adapter=?, user_id=?,
+			user_email=?, channel_id=?, gort_user_name=?
+		WHERE request_id=?;`
+
+	_, err = db.ExecContext(ctx, query,
+		req.Bundle.Name,
+		req.Bundle.Version,
+		req.Command.Name,
+		encodeStringSlice(req.Command.Executable),
+		req.RedactedParameters(config.GetRedactPatterns()...).String(),
+		req.Adapter,
+		req.UserID,
+		req.UserEmail,
+		req.ChannelID,
+		req.UserName,
+		req.RequestID)
+	if err != nil {
+		err = gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return err
+}
+
+// RequestUpdateTimings replaces the stage timings of a request.
+func (da MySQLDataAccess) RequestUpdateTimings(ctx context.Context, requestID int64, timings data.StageTimings) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestUpdateTimings")
+	defer sp.End()
+
+	if requestID == 0 {
+		return fmt.Errorf("command request ID unset")
+	}
+
+	encoded, err := encodeStageTimings(timings)
+	if err != nil {
+		return err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE commands SET timings=? WHERE request_id=?;`
+
+	_, err = db.ExecContext(ctx, query, encoded, requestID)
+	if err != nil {
+		err = gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return err
+}
+
+func (da MySQLDataAccess) RequestClose(ctx context.Context, envelope data.CommandResponseEnvelope) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.RequestClose")
+	defer sp.End()
+
+	if envelope.Request.RequestID == 0 {
+		return fmt.Errorf("command request ID unset")
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE commands
+		SET bundle_name=?, bundle_version=?, command_name=?,
+			command_executable=?, command_parameters=?, adapter=?, user_id=?,
+			user_email=?, channel_id=?, gort_user_name=?, timestamp=?,
+			duration=?, result_status=?, result_error=?, timings=?
+		WHERE request_id=?;`
+
+	errMsg := ""
+	if envelope.Data.Error != nil {
+		errMsg = envelope.Data.Error.Error()
+	}
+
+	timings, err := encodeStageTimings(envelope.Request.Timings)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, query,
+		envelope.Request.Bundle.Name,
+		envelope.Request.Bundle.Version,
+		envelope.Request.Command.Name,
+		encodeStringSlice(envelope.Request.Command.Executable),
+		envelope.Request.RedactedParameters(config.GetRedactPatterns()...).String(),
+		envelope.Request.Adapter,
+		envelope.Request.UserID,
+		envelope.Request.UserEmail,
+		envelope.Request.ChannelID,
+		envelope.Request.UserName,
+		envelope.Request.Timestamp,
+		envelope.Data.Duration.Milliseconds(),
+		envelope.Data.ExitCode,
+		errMsg,
+		timings,
+		envelope.Request.RequestID)
+	if err != nil {
+		err = gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return err
+}
+
+const requestRecordQuery = `SELECT request_id, timestamp, duration,
+		bundle_name, bundle_version, command_name, command_parameters,
+		adapter, user_id, user_email, channel_id, gort_user_name,
+		result_status, result_error, timings, replay_of
+	FROM commands`
+
+// scanRequestRecord scans a row selected by requestRecordQuery.
+func scanRequestRecord(rows *sql.Rows) (data.RequestRecord, error) {
+	var r data.RequestRecord
+	var timestamp sql.NullTime
+	var duration, status sql.NullInt64
+	var errMsg sql.NullString
+	var timings string
+
+	err := rows.Scan(&r.RequestID, &timestamp, &duration,
+		&r.BundleName, &r.BundleVersion, &r.CommandName, &r.Parameters,
+		&r.Adapter, &r.UserID, &r.UserEmail, &r.ChannelID, &r.UserName,
+		&status, &errMsg, &timings, &r.ReplayOf)
+	if err != nil {
+		return data.RequestRecord{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	r.Timestamp = timestamp.Time
+	r.Duration = time.Duration(duration.Int64) * time.Millisecond
+	r.Closed = status.Valid
+	r.ExitCode = int16(status.Int64)
+	r.Error = errMsg.String
+
+	if r.Timings, err = decodeStageTimings(timings); err != nil {
+		return data.RequestRecord{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return r, nil
+}
+
+// encodeStageTimings encodes timings as a JSON object of stage names to
+// durations in milliseconds.
+func encodeStageTimings(t data.StageTimings) (string, error) {
+	if len(t) == 0 {
+		return "", nil
+	}
+
+	ms := map[data.RequestStage]int64{}
+	for stage, d := range t {
+		ms[stage] = d.Milliseconds()
+	}
+
+	b, err := json.Marshal(ms)
+	return string(b), err
+}
+
+func decodeStageTimings(s string) (data.StageTimings, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	ms := map[data.RequestStage]int64{}
+	if err := json.Unmarshal([]byte(s), &ms); err != nil {
+		return nil, err
+	}
+
+	t := data.StageTimings{}
+	for stage, m := range ms {
+		t[stage] = time.Duration(m) * time.Millisecond
+	}
+
+	return t, nil
+}
+
+func (da MySQLDataAccess) createRequestPayloadsTable(ctx context.Context, conn *sql.Conn) error {
+	createRequestPayloadsQuery := `CREATE TABLE request_payloads (
+		request_id		BIGINT NOT NULL,
+		archived_at		DATETIME(6) NOT NULL,
+		title			TEXT NOT NULL,
+		error_code		VARCHAR(64) NOT NULL,
+		partial			BOOLEAN NOT NULL,
+		output			MEDIUMTEXT NOT NULL,
+		rendered		MEDIUMTEXT NOT NULL,
+		truncated		BOOLEAN NOT NULL,
+		PRIMARY KEY		(request_id)
+	);
+
+	CREATE INDEX request_payloads_archived_at ON request_payloads (archived_at);`
+
+	_, err := conn.ExecContext(ctx, createRequestPayloadsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createRequestLogsTable(ctx context.Context, conn *sql.Conn) error {
+	createRequestLogsQuery := `CREATE TABLE request_logs (
+		id				BIGINT NOT NULL AUTO_INCREMENT,
+		request_id		BIGINT NOT NULL,
+		timestamp		DATETIME(6) NOT NULL,
+		level			VARCHAR(16) NOT NULL,
+		message			TEXT NOT NULL,
+		fields			TEXT NOT NULL,
+		PRIMARY KEY		(id)
+	);
+
+	CREATE INDEX request_logs_request_id ON request_logs (request_id);`
+
+	_, err := conn.ExecContext(ctx, createRequestLogsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da MySQLDataAccess) createCommandsTable(ctx context.Context, conn *sql.Conn) error {
+	createCommandsQuery := `CREATE TABLE commands (
+		request_id			BIGINT NOT NULL AUTO_INCREMENT,
+		timestamp			DATETIME(6),
+		duration			INT,
+		bundle_name			VARCHAR(128) NOT NULL,
+		bundle_version		VARCHAR(128) NOT NULL,
+		command_name		VARCHAR(128) NOT NULL,
+		command_executable	TEXT NOT NULL,
+		command_parameters	TEXT NOT NULL,
+		adapter				VARCHAR(64) NOT NULL,
+		user_id				VARCHAR(255) NOT NULL,
+		user_email			VARCHAR(255) NOT NULL,
+		channel_id			VARCHAR(255) NOT NULL,
+		gort_user_name		VARCHAR(128) NOT NULL,
+		result_status		INT,
+		result_error		TEXT,
+		timings				TEXT NOT NULL DEFAULT (''),
+		replay_of			BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY			(request_id)
+	);
+
+	CREATE INDEX commands_requestor_idx
+		ON commands (adapter, channel_id, user_id, request_id DESC);`
+
+	_, err := conn.ExecContext(ctx, createCommandsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
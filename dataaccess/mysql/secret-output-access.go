@@ -0,0 +1,156 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// SecretOutputCreate stores a secret output.
+func (da MySQLDataAccess) SecretOutputCreate(ctx context.Context, output data.SecretOutput) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.SecretOutputCreate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO secret_outputs
+		(token_hash, request_id, username, title, output, created, expires)
+		VALUES (?, ?, ?, ?, ?, ?, ?);`
+
+	_, err = db.ExecContext(ctx, query, output.TokenHash, output.RequestID,
+		output.Username, output.Title, encodeStringSlice(output.Output),
+		output.Created, output.Expires)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// SecretOutputPurge deletes every secret output that expired before the
+// given time, returning the number deleted.
+func (da MySQLDataAccess) SecretOutputPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.SecretOutputPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM secret_outputs WHERE expires < ?;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
+// SecretOutputRedeem deletes and returns the secret output with the given
+// token hash. An output that has expired can't be redeemed.
+func (da MySQLDataAccess) SecretOutputRedeem(ctx context.Context, tokenHash string) (data.SecretOutput, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.SecretOutputRedeem")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.SecretOutput{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return data.SecretOutput{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// MySQL has no DELETE ... RETURNING, so the row is locked while it's
+	// read and deleted. This still guarantees that each output is redeemed
+	// at most once, even by concurrent requests.
+	const query = `SELECT token_hash, request_id, username, title, output, created, expires
+		FROM secret_outputs WHERE token_hash=?
+		FOR UPDATE;`
+
+	var s data.SecretOutput
+	var enc string
+
+	err = tx.QueryRowContext(ctx, query, tokenHash).Scan(&s.TokenHash,
+		&s.RequestID, &s.Username, &s.Title, &enc, &s.Created, &s.Expires)
+	switch {
+	case err == sql.ErrNoRows:
+		tx.Rollback()
+		return data.SecretOutput{}, errs.ErrNoSuchSecretOutput
+	case err != nil:
+		tx.Rollback()
+		return data.SecretOutput{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM secret_outputs WHERE token_hash=?;`, tokenHash)
+	if err != nil {
+		tx.Rollback()
+		return data.SecretOutput{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return data.SecretOutput{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if s.Expired() {
+		return data.SecretOutput{}, errs.ErrNoSuchSecretOutput
+	}
+
+	s.Output = decodeStringSlice(enc)
+
+	return s, nil
+}
+
+func (da MySQLDataAccess) createSecretOutputsTable(ctx context.Context, conn *sql.Conn) error {
+	createSecretOutputsQuery := `CREATE TABLE secret_outputs (
+		token_hash		VARCHAR(128) NOT NULL,
+		request_id		BIGINT NOT NULL,
+		username		VARCHAR(128) NOT NULL,
+		title			TEXT NOT NULL,
+		output			MEDIUMTEXT NOT NULL,
+		created			DATETIME(6) NOT NULL,
+		expires			DATETIME(6) NOT NULL,
+		PRIMARY KEY		(token_hash)
+	);`
+
+	_, err := conn.ExecContext(ctx, createSecretOutputsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
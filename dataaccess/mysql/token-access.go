@@ -0,0 +1,261 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// TokenEvaluate will test a token for validity. It returns true if the token
+// exists and is still within its valid period; false otherwise.
+func (da MySQLDataAccess) TokenEvaluate(ctx context.Context, tokenString string) bool {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.TokenEvaluate")
+	defer sp.End()
+
+	token, err := da.TokenRetrieveByToken(ctx, tokenString)
+	if err != nil {
+		return false
+	}
+
+	return !token.IsExpired()
+}
+
+// TokenGenerate generates a new token for the given user with a specified
+// expiration duration. Any existing token for this user will be automatically
+// invalidated. If the user doesn't exist an error is returned.
+func (da MySQLDataAccess) TokenGenerate(ctx context.Context, username string, duration time.Duration) (rest.Token, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.TokenGenerate")
+	defer sp.End()
+
+	exists, err := da.UserExists(ctx, username)
+	if err != nil {
+		return rest.Token{}, err
+	}
+	if !exists {
+		return rest.Token{}, errs.ErrNoSuchUser
+	}
+
+	// If a token already exists for this user, automatically invalidate it.
+	token, err := da.TokenRetrieveByUser(ctx, username)
+	if err == nil {
+		da.TokenInvalidate(ctx, token.Token)
+	}
+
+	tokenString, err := data.GenerateRandomToken(64)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	validFrom := time.Now().UTC()
+	validUntil := validFrom.Add(duration)
+
+	token = rest.Token{
+		Duration:   duration,
+		Token:      tokenString,
+		User:       username,
+		ValidFrom:  validFrom,
+		ValidUntil: validUntil,
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	query := `INSERT INTO tokens (token, username, valid_from, valid_until)
+	VALUES (?, ?, ?, ?);`
+	_, err = db.ExecContext(ctx, query, token.Token, token.User, token.ValidFrom, token.ValidUntil)
+	if err != nil {
+		return rest.Token{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return token, nil
+}
+
+// TokenInvalidate immediately invalidates the specified token. An error is
+// returned if the token doesn't exist.
+func (da MySQLDataAccess) TokenInvalidate(ctx context.Context, tokenString string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.TokenInvalidate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM tokens WHERE token=?;`
+	_, err = db.ExecContext(ctx, query, tokenString)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// TokenPurge deletes every token and token revocation that expired before
+// the given time, returning the number deleted.
+func (da MySQLDataAccess) TokenPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.TokenPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, query := range []string{
+		`DELETE FROM tokens WHERE valid_until < ?;`,
+		`DELETE FROM revoked_tokens WHERE valid_until < ?;`,
+	} {
+		result, err := db.ExecContext(ctx, query, before)
+		if err != nil {
+			return total, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return total, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		total += int(n)
+	}
+
+	return total, nil
+}
+
+// TokenRetrieveByUser retrieves the token associated with a username. An
+// error is returned if no such token (or user) exists.
+func (da MySQLDataAccess) TokenRetrieveByUser(ctx context.Context, username string) (rest.Token, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.TokenRetrieveByUser")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	// There will be more here eventually
+	query := `SELECT token, username, valid_from, valid_until
+		FROM tokens
+		WHERE username=?`
+
+	token := rest.Token{}
+
+	err = db.
+		QueryRowContext(ctx, query, username).
+		Scan(&token.Token, &token.User, &token.ValidFrom, &token.ValidUntil)
+
+	if err != nil {
+		err = gerr.Wrap(errs.ErrNoSuchToken, err)
+	}
+
+	token.Duration = token.ValidUntil.Sub(token.ValidFrom)
+
+	return token, err
+}
+
+// TokenRetrieveByToken retrieves the token by its value. An error is returned
+// if no such token exists.
+func (da MySQLDataAccess) TokenRetrieveByToken(ctx context.Context, tokenString string) (rest.Token, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.TokenRetrieveByToken")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	// There will be more here eventually
+	query := `SELECT token, username, valid_from, valid_until
+		FROM tokens
+		WHERE token=?`
+
+	token := rest.Token{}
+	err = db.
+		QueryRowContext(ctx, query, tokenString).
+		Scan(&token.Token, &token.User, &token.ValidFrom, &token.ValidUntil)
+
+	if err != nil {
+		err = gerr.Wrap(errs.ErrNoSuchToken, err)
+	}
+
+	token.Duration = token.ValidUntil.Sub(token.ValidFrom)
+
+	return token, err
+}
+
+// TokenRevoke adds the ID of a self-contained token, like a JWT, to the
+// revocation list. It's kept there until the given time, after which the
+// token will have expired anyway. Revoking a token more than once isn't an
+// error.
+func (da MySQLDataAccess) TokenRevoke(ctx context.Context, id string, until time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.TokenRevoke")
+	defer sp.End()
+
+	if id == "" {
+		return errs.ErrNoSuchToken
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT IGNORE INTO revoked_tokens (token_id, valid_until) VALUES (?, ?);`
+	_, err = db.ExecContext(ctx, query, id, until)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// TokenRevoked returns true if the token ID is on the revocation list.
+func (da MySQLDataAccess) TokenRevoked(ctx context.Context, id string) (bool, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.TokenRevoked")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	revoked := false
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_id=?)`
+	if err := db.QueryRowContext(ctx, query, id).Scan(&revoked); err != nil {
+		return false, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return revoked, nil
+}
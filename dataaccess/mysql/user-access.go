@@ -0,0 +1,955 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// UserAuthenticate authenticates a username/password combination. Service
+// accounts have no password, so they never authenticate.
+func (da MySQLDataAccess) UserAuthenticate(ctx context.Context, username string, password string) (bool, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserAuthenticate")
+	defer sp.End()
+
+	exists, err := da.UserExists(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, errs.ErrNoSuchUser
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	query := `SELECT password_hash, service_account
+		FROM users
+		WHERE username=?`
+
+	var hash string
+	var serviceAccount bool
+	err = db.QueryRowContext(ctx, query, username).Scan(&hash, &serviceAccount)
+	if err != nil {
+		err = gerr.Wrap(errs.ErrNoSuchUser, err)
+	}
+
+	return !serviceAccount && data.CompareHashAndPassword(hash, password), err
+}
+
+// UserCreate is used to create a new Gort user in the data store. An error is
+// returned if the username is empty, if a user already exists, or if a service
+// account has a password or mappings.
+func (da MySQLDataAccess) UserCreate(ctx context.Context, user rest.User) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserCreate")
+	defer sp.End()
+
+	if user.Username == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	exists, err := da.UserExists(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errs.ErrUserExists
+	}
+
+	if user.ServiceAccount && (user.Password != "" || len(user.Mappings) > 0) {
+		return errs.ErrServiceAccountCredentials
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating a user permanently discards any deleted user of the same
+	// name.
+	if err := da.doUserPurgeDeleted(ctx, db, user.Username); err != nil {
+		return err
+	}
+
+	var hash string
+	if user.Password != "" {
+		hash, err = data.HashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+	}
+
+	userQuery := `INSERT INTO users (email, full_name, password_hash, username, service_account)
+		VALUES (?, ?, ?, ?, ?);`
+	if _, err := db.ExecContext(ctx, userQuery, user.Email, user.FullName, hash, user.Username, user.ServiceAccount); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if err := da.doUserUpdateAdapterIDs(ctx, user); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UserDelete soft-deletes an existing user from the data store. The user's
+// tokens are revoked immediately, but their group memberships and adapter
+// mappings are retained so that the user can be restored using UserRestore.
+// An error is returned if the username parameter is empty or if the user
+// doesn't exist.
+func (da MySQLDataAccess) UserDelete(ctx context.Context, username string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserDelete")
+	defer sp.End()
+
+	if username == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	// Thou Shalt Not Delete Admin
+	if username == "admin" {
+		return errs.ErrAdminUndeletable
+	}
+
+	exists, err := da.UserExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchUser
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := "DELETE FROM tokens WHERE username=?;"
+	_, err = db.ExecContext(ctx, query, username)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query = "UPDATE users SET deleted_at=CURRENT_TIMESTAMP(6) WHERE username=?;"
+	_, err = db.ExecContext(ctx, query, username)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// UserExists is used to determine whether a Gort user with the given username
+// exists in the data store.
+func (da MySQLDataAccess) UserExists(ctx context.Context, username string) (bool, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserExists")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	query := "SELECT EXISTS(SELECT 1 FROM users WHERE username=? AND deleted_at IS NULL)"
+	exists := false
+
+	err = db.QueryRowContext(ctx, query, username).Scan(&exists)
+	if err != nil {
+		return false, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return exists, nil
+}
+
+// UserExport returns all of the personal data stored about a user, including
+// the records of any command requests that they made.
+func (da MySQLDataAccess) UserExport(ctx context.Context, username string) (rest.UserDataExport, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserExport")
+	defer sp.End()
+
+	user, err := da.UserGet(ctx, username)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	groups, err := da.UserGroupList(ctx, username)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	export := rest.UserDataExport{User: user, Exported: time.Now().UTC()}
+	for _, g := range groups {
+		export.Groups = append(export.Groups, g.Name)
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	query := `SELECT request_id, timestamp, adapter, channel_id, user_id,
+			user_email, bundle_name, command_name, command_parameters
+		FROM commands
+		WHERE gort_user_name=?
+			OR (adapter, user_id) IN (SELECT adapter, id FROM user_adapter_ids WHERE username=?)
+		ORDER BY request_id`
+
+	rows, err := db.QueryContext(ctx, query, username, username)
+	if err != nil {
+		return rest.UserDataExport{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r rest.UserRequestRecord
+		var bundle, command string
+		var timestamp sql.NullTime
+
+		err = rows.Scan(&r.RequestID, &timestamp, &r.Adapter, &r.ChannelID,
+			&r.UserID, &r.UserEmail, &bundle, &command, &r.Parameters)
+		if err != nil {
+			return rest.UserDataExport{}, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		r.Timestamp = timestamp.Time
+		r.Command = bundle + ":" + command
+		export.Requests = append(export.Requests, r)
+	}
+
+	if rows.Err() != nil {
+		return rest.UserDataExport{}, gerr.Wrap(errs.ErrDataAccess, rows.Err())
+	}
+
+	return export, nil
+}
+
+// UserGet returns a user from the data store. An error is returned if the
+// username parameter is empty or if the user doesn't exist.
+func (da MySQLDataAccess) UserGet(ctx context.Context, username string) (rest.User, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserGet")
+	defer sp.End()
+
+	if username == "" {
+		return rest.User{}, errs.ErrEmptyUserName
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.User{}, err
+	}
+
+	query := `SELECT email, full_name, username, service_account
+		FROM users
+		WHERE username=? AND deleted_at IS NULL`
+
+	var user rest.User
+
+	err = db.QueryRowContext(ctx, query, username).Scan(&user.Email, &user.FullName, &user.Username, &user.ServiceAccount)
+	switch {
+	case err == sql.ErrNoRows:
+		return rest.User{}, errs.ErrNoSuchUser
+	case err != nil:
+		return rest.User{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if user.Mappings, err = da.doUserGetAdapterIDs(ctx, user.Username); err != nil {
+		return rest.User{}, err
+	}
+
+	return user, nil
+}
+
+// UserGetByEmail returns a user from the data store. An error is returned if
+// the email parameter is empty or if the user doesn't exist.
+func (da MySQLDataAccess) UserGetByEmail(ctx context.Context, email string) (rest.User, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserGetByEmail")
+	defer sp.End()
+
+	if email == "" {
+		return rest.User{}, errs.ErrEmptyUserEmail
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.User{}, err
+	}
+
+	query := `SELECT email, full_name, username, service_account
+		FROM users
+		WHERE email=? AND deleted_at IS NULL`
+
+	var user rest.User
+	err = db.QueryRowContext(ctx, query, email).Scan(&user.Email, &user.FullName, &user.Username, &user.ServiceAccount)
+	switch {
+	case err == sql.ErrNoRows:
+		return rest.User{}, errs.ErrNoSuchUser
+	case err != nil:
+		return rest.User{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if user.Mappings, err = da.doUserGetAdapterIDs(ctx, user.Username); err != nil {
+		return rest.User{}, err
+	}
+
+	return user, nil
+}
+
+// UserGetByID returns a user from the data store. An error is returned if
+// eitherparameter is empty or if the user doesn't exist.
+func (da MySQLDataAccess) UserGetByID(ctx context.Context, adapter, id string) (rest.User, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserGetByID")
+	defer sp.End()
+
+	if adapter == "" {
+		return rest.User{}, errs.ErrEmptyUserAdapter
+	}
+
+	if id == "" {
+		return rest.User{}, errs.ErrEmptyUserID
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.User{}, err
+	}
+
+	query := `SELECT username
+		FROM user_adapter_ids
+		WHERE adapter=? AND id=?`
+
+	var username string
+	err = db.QueryRowContext(ctx, query, adapter, id).Scan(&username)
+	switch {
+	case err == nil:
+		return da.UserGet(ctx, username)
+	case err == sql.ErrNoRows:
+		return rest.User{}, errs.ErrNoSuchUser
+	default:
+		return rest.User{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+}
+
+// UserGroupList returns a slice of Group values representing the specified user's group memberships.
+// The groups' Users slice is never populated, and is always nil.
+func (da MySQLDataAccess) UserGroupList(ctx context.Context, username string) ([]rest.Group, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserGroupList")
+	defer sp.End()
+
+	groups := make([]rest.Group, 0)
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return groups, err
+	}
+
+	query := `SELECT groupusers.groupname
+		FROM groupusers
+		INNER JOIN gort_groups ON groupusers.groupname=gort_groups.groupname
+		WHERE groupusers.username=? AND gort_groups.deleted_at IS NULL`
+	rows, err := db.QueryContext(ctx, query, username)
+	if err != nil {
+		return groups, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	for rows.Next() {
+		group := rest.Group{}
+
+		err = rows.Scan(&group.Name)
+		if err != nil {
+			err = gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		groups = append(groups, group)
+	}
+
+	if rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return groups, err
+}
+
+// UserGroupAdd comments TBD
+func (da MySQLDataAccess) UserGroupAdd(ctx context.Context, username string, groupname string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserGroupAdd")
+	defer sp.End()
+
+	if username == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	if groupname == "" {
+		return errs.ErrEmptyGroupName
+	}
+
+	exists, err := da.UserExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchUser
+	}
+
+	exists, err = da.GroupExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchGroup
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE groupusers
+		SET groupname=?, username=?
+		WHERE username=?;`
+
+	_, err = db.ExecContext(ctx, query, groupname, username, username)
+	if err != nil {
+		err = gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return err
+}
+
+// UserGroupDelete comments TBD
+func (da MySQLDataAccess) UserGroupDelete(ctx context.Context, username string, groupname string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserGroupDelete")
+	defer sp.End()
+
+	if username == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	if groupname == "" {
+		return errs.ErrEmptyGroupName
+	}
+
+	exists, err := da.UserExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchUser
+	}
+
+	exists, err = da.GroupExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchGroup
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM groupusers WHERE groupname=? AND username=?;`
+
+	_, err = db.ExecContext(ctx, query, groupname, username)
+	if err != nil {
+		err = gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return err
+}
+
+// UserList returns a list of all known users in the datastore.
+// Passwords are not included. Nice try.
+func (da MySQLDataAccess) UserList(ctx context.Context) ([]rest.User, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT email, full_name, username, service_account FROM users WHERE deleted_at IS NULL`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]rest.User, 0)
+
+	for rows.Next() {
+		user := rest.User{}
+		err = rows.Scan(&user.Email, &user.FullName, &user.Username, &user.ServiceAccount)
+		if err != nil {
+			err = gerr.Wrap(errs.ErrNoSuchUser, err)
+		}
+		users = append(users, user)
+	}
+
+	if rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return users, err
+}
+
+// UserPermissionList returns an alphabetically-sorted list of permissions
+// available to the specified user.
+func (da MySQLDataAccess) UserPermissionList(ctx context.Context, username string) (rest.RolePermissionList, error) {
+	// TODO This is HORRIBLY inefficient -- use a real SQL query instead!
+
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserPermissionList")
+	defer sp.End()
+
+	pp := []rest.RolePermission{}
+
+	groups, err := da.UserGroupList(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		roles, err := da.GroupRoleList(ctx, group.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, role := range roles {
+			rp, err := da.RolePermissionList(ctx, role.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, p := range rp {
+				pp = append(pp, p)
+			}
+		}
+	}
+
+	sort.Slice(pp, func(i, j int) bool { return pp[i].String() < pp[j].String() })
+
+	return pp, nil
+}
+
+// UserPurge removes a user, along with their group memberships, adapter
+// mappings, and tokens. The user's request records are kept so that
+// aggregate statistics are preserved, but their personal data is removed and
+// the username replaced with a random pseudonym. An error is returned if the
+// username parameter is empty, if the user doesn't exist, or if the user is
+// "admin".
+func (da MySQLDataAccess) UserPurge(ctx context.Context, username string) (rest.UserPurgeResult, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserPurge")
+	defer sp.End()
+
+	if username == "" {
+		return rest.UserPurgeResult{}, errs.ErrEmptyUserName
+	}
+
+	// Thou Shalt Not Purge Admin
+	if username == "admin" {
+		return rest.UserPurgeResult{}, errs.ErrAdminUndeletable
+	}
+
+	pseudonym, err := data.GeneratePseudonym()
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+
+	// Soft-deleted users can be purged too, so UserExists won't do here.
+	exists := false
+	query := "SELECT EXISTS(SELECT 1 FROM users WHERE username=?)"
+	if err := db.QueryRowContext(ctx, query, username).Scan(&exists); err != nil {
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if !exists {
+		return rest.UserPurgeResult{}, errs.ErrNoSuchUser
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// This has to happen before the user (and therefore their adapter
+	// mappings) is deleted.
+	query = `UPDATE commands
+		SET gort_user_name=?, user_id='', user_email=''
+		WHERE gort_user_name=?
+			OR (adapter, user_id) IN (SELECT adapter, id FROM user_adapter_ids WHERE username=?);`
+	res, err := tx.ExecContext(ctx, query, pseudonym, username, username)
+	if err != nil {
+		tx.Rollback()
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	anonymized, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// Adapter mappings are removed by cascade.
+	for _, query := range []string{
+		`DELETE FROM groupusers WHERE username=?;`,
+		`DELETE FROM tokens WHERE username=?;`,
+		`DELETE FROM users WHERE username=?;`,
+	} {
+		_, err = tx.ExecContext(ctx, query, username)
+		if err != nil {
+			tx.Rollback()
+			return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return rest.UserPurgeResult{
+		Username:           username,
+		Pseudonym:          pseudonym,
+		RequestsAnonymized: anonymized,
+	}, nil
+}
+
+// UserRename changes a user's username. Tokens, group memberships, and
+// adapter mappings follow by foreign key cascade; user-layer configurations,
+// option defaults, macros, aliases, locks, and request records, which
+// aren't keyed to the users table, are updated in the same transaction. An
+// error is returned if either name is empty, if the user doesn't exist, if
+// the new name is already taken, or if the user is "admin".
+func (da MySQLDataAccess) UserRename(ctx context.Context, username, newname string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserRename")
+	defer sp.End()
+
+	if username == "" || newname == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	// Thou Shalt Not Rename Admin
+	if username == "admin" {
+		return errs.ErrAdminUndeletable
+	}
+
+	exists, err := da.UserExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchUser
+	}
+
+	exists, err = da.UserExists(ctx, newname)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errs.ErrUserExists
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	// As with UserCreate, the new name displaces any deleted user.
+	if err := da.doUserPurgeDeleted(ctx, db, newname); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	for _, query := range []string{
+		`UPDATE users SET username=? WHERE username=?;`,
+		`UPDATE configs SET owner=? WHERE lower(layer)='user' AND owner=?;`,
+		`UPDATE option_defaults SET owner=? WHERE lower(layer)='user' AND owner=?;`,
+		`UPDATE macros SET owner=? WHERE layer='user' AND owner=?;`,
+		`UPDATE aliases SET owner=? WHERE owner=?;`,
+		`UPDATE locks SET username=? WHERE username=?;`,
+		`UPDATE commands SET gort_user_name=? WHERE gort_user_name=?;`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, newname, username); err != nil {
+			tx.Rollback()
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// UserRestore restores a user that was deleted at or after since, along
+// with their group memberships and adapter mappings. Tokens revoked by the
+// deletion are not restored. An error is returned if the username parameter
+// is empty, or if no such user was deleted in that time.
+func (da MySQLDataAccess) UserRestore(ctx context.Context, username string, since time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserRestore")
+	defer sp.End()
+
+	if username == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE users SET deleted_at=NULL
+		WHERE username=? AND deleted_at IS NOT NULL AND deleted_at >= ?;`
+	res, err := db.ExecContext(ctx, query, username, since)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if n == 0 {
+		return errs.ErrNoSuchUser
+	}
+
+	return nil
+}
+
+// UserRoleList returns a slice of Role values representing the specified
+// user's indirect roles (indirect because users are members of groups,
+// and groups have roles).
+func (da MySQLDataAccess) UserRoleList(ctx context.Context, username string) ([]rest.Role, error) {
+	rm := map[string]rest.Role{}
+
+	groups, err := da.UserGroupList(ctx, username)
+	if err != nil {
+		return []rest.Role{}, err
+	}
+
+	for _, gr := range groups {
+		rl, err := da.GroupRoleList(ctx, gr.Name)
+		if err != nil {
+			return []rest.Role{}, err
+		}
+
+		for _, r := range rl {
+			rm[r.Name] = r
+		}
+	}
+
+	roles := []rest.Role{}
+
+	for _, r := range rm {
+		roles = append(roles, r)
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	return roles, nil
+}
+
+// UserUpdate is used to update an existing user. An error is returned if the
+// username is empty, if the user doesn't exist, or if a service account is
+// given a password or mappings. Whether a user is a service account can't be
+// changed.
+func (da MySQLDataAccess) UserUpdate(ctx context.Context, user rest.User) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.UserUpdate")
+	defer sp.End()
+
+	if user.Username == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	exists, err := da.UserExists(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchUser
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `SELECT email, full_name, username, password_hash, service_account
+		FROM users
+		WHERE username=?`
+
+	userOld := rest.User{}
+	err = db.
+		QueryRowContext(ctx, query, user.Username).
+		Scan(&userOld.Email, &userOld.FullName, &userOld.Username, &userOld.Password, &userOld.ServiceAccount)
+
+	if err != nil {
+		return gerr.Wrap(errs.ErrNoSuchUser, err)
+	}
+
+	if userOld.ServiceAccount && (user.Password != "" || len(user.Mappings) > 0) {
+		return errs.ErrServiceAccountCredentials
+	}
+
+	if user.Email != "" {
+		userOld.Email = user.Email
+	}
+
+	if user.FullName != "" {
+		userOld.FullName = user.FullName
+	}
+
+	if user.Password != "" {
+		userOld.Password, err = data.HashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `UPDATE users
+	SET email=?, full_name=?, password_hash=?
+	WHERE username=?;`
+
+	if _, err = db.ExecContext(ctx, query, userOld.Email, userOld.FullName, userOld.Password, userOld.Username); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if err := da.doUserUpdateAdapterIDs(ctx, user); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// doUserGetAdapterIDs retrieves any adapter ID mappings associated with the
+// user. If none are found, an emptry map is returned.
+func (da MySQLDataAccess) doUserGetAdapterIDs(ctx context.Context, username string) (map[string]string, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.doUserGetAdapterIDs")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query := `SELECT adapter, id
+		FROM user_adapter_ids
+		WHERE username=?`
+
+	rows, err := db.QueryContext(ctx, query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var m = map[string]string{}
+
+	for rows.Next() {
+		var adapter, id string
+
+		if err := rows.Scan(&adapter, &id); err != nil {
+			return m, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		m[adapter] = id
+	}
+
+	if rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return m, nil
+}
+
+// doUserUpdateAdapterIDs
+func (da MySQLDataAccess) doUserUpdateAdapterIDs(ctx context.Context, user rest.User) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "mysql.doUserUpdateAdapterIDs")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	deleteQuery := `DELETE FROM user_adapter_ids WHERE username=?;`
+	_, err = db.ExecContext(ctx, deleteQuery, user.Username)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// A mapping still held by a deleted user is released to this one.
+	deleteQuery = `DELETE FROM user_adapter_ids
+		WHERE adapter=? AND id=? AND username IN (
+			SELECT username FROM users WHERE deleted_at IS NOT NULL
+		);`
+	for adapter, id := range user.Mappings {
+		if _, err := db.ExecContext(ctx, deleteQuery, adapter, id); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	adapterIDQuery := `INSERT INTO user_adapter_ids (username, adapter, id) VALUES (?, ?, ?);`
+	for adapter, id := range user.Mappings {
+		if _, err := db.ExecContext(ctx, adapterIDQuery, user.Username, adapter, id); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// AuditRecordCreate stores an audit record, setting its ID.
+func (da PostgresDataAccess) AuditRecordCreate(ctx context.Context, record *data.AuditRecord) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.AuditRecordCreate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO rest_audit
+		(timestamp, username, source_ip, method, path, kind, target, status, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING audit_id;`
+
+	err = db.QueryRowContext(ctx, query,
+		record.Timestamp, record.User, record.SourceIP, record.Method,
+		record.Path, record.Kind, record.Target, record.Status,
+		string(record.Before), string(record.After)).
+		Scan(&record.ID)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// AuditRecordList returns the audit records that match the filter, newest
+// first.
+func (da PostgresDataAccess) AuditRecordList(ctx context.Context, filter data.AuditFilter) ([]data.AuditRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.AuditRecordList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.User != "" {
+		addCondition("username=$%d", filter.User)
+	}
+	if filter.Kind != "" {
+		addCondition("kind=$%d", filter.Kind)
+	}
+	if filter.Target != "" {
+		addCondition("target=$%d", filter.Target)
+	}
+	if !filter.Since.IsZero() {
+		addCondition("timestamp>=$%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		addCondition("timestamp<$%d", filter.Until)
+	}
+
+	query := `SELECT audit_id, timestamp, username, source_ip, method, path,
+			kind, target, status, before, after
+		FROM rest_audit`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY audit_id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.AuditRecord{}
+
+	for rows.Next() {
+		var r data.AuditRecord
+		var before, after string
+
+		err = rows.Scan(&r.ID, &r.Timestamp, &r.User, &r.SourceIP, &r.Method,
+			&r.Path, &r.Kind, &r.Target, &r.Status, &before, &after)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if before != "" {
+			r.Before = []byte(before)
+		}
+		if after != "" {
+			r.After = []byte(after)
+		}
+
+		list = append(list, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+func (da PostgresDataAccess) createRestAuditTable(ctx context.Context, conn *sql.Conn) error {
+	createRestAuditQuery := `CREATE TABLE rest_audit (
+		audit_id		BIGSERIAL,
+		timestamp		TIMESTAMP WITH TIME ZONE NOT NULL,
+		username		TEXT NOT NULL,
+		source_ip		TEXT NOT NULL,
+		method			TEXT NOT NULL,
+		path			TEXT NOT NULL,
+		kind			TEXT NOT NULL,
+		target			TEXT NOT NULL,
+		status			INT NOT NULL,
+		before			TEXT NOT NULL,
+		after			TEXT NOT NULL,
+		PRIMARY KEY		(audit_id)
+	);
+
+	CREATE INDEX rest_audit_kind_target ON rest_audit (kind, target);
+	CREATE INDEX rest_audit_timestamp ON rest_audit (timestamp);`
+
+	_, err := conn.ExecContext(ctx, createRestAuditQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
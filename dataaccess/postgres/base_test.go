@@ -177,6 +177,7 @@ loop:
 
 	t.Run("testDatabaseExists", testDatabaseExists)
 	t.Run("testTablesExist", testTablesExist)
+	t.Run("testMigrations", testMigrations)
 }
 
 func testConnectionLeaks(t *testing.T) {
@@ -241,6 +242,104 @@ func testTablesExist(t *testing.T) {
 	assert.False(t, b)
 }
 
+func testMigrations(t *testing.T) {
+	conn, err := da.connect(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	count := 0
+	err = conn.QueryRowContext(ctx, "SELECT count(*) FROM schema_migrations").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), count)
+
+	// Re-initializing doesn't apply anything twice.
+	require.NoError(t, da.Initialize(ctx))
+	err = conn.QueryRowContext(ctx, "SELECT count(*) FROM schema_migrations").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), count)
+
+	// Natural key changes cascade.
+	_, err = conn.ExecContext(ctx, `INSERT INTO users (username) VALUES ('test-migrations');
+		INSERT INTO groups (groupname) VALUES ('test-migrations');
+		INSERT INTO groupusers (groupname, username) VALUES ('test-migrations', 'test-migrations');
+		UPDATE users SET username='test-migrations-renamed' WHERE username='test-migrations';`)
+	require.NoError(t, err)
+
+	member := ""
+	err = conn.QueryRowContext(ctx, "SELECT username FROM groupusers WHERE groupname='test-migrations'").Scan(&member)
+	require.NoError(t, err)
+	assert.Equal(t, "test-migrations-renamed", member)
+
+	_, err = conn.ExecContext(ctx, `DELETE FROM groupusers WHERE groupname='test-migrations';
+		DELETE FROM groups WHERE groupname='test-migrations';
+		DELETE FROM users WHERE username='test-migrations-renamed';`)
+	require.NoError(t, err)
+
+	t.Run("testMigrationsPopulated", testMigrationsPopulated)
+}
+
+// testMigrationsPopulated puts the database back into its state from before
+// the first migration -- with the foreign keys that the tables were created
+// with, and a grant of a role that no longer exists -- and checks that
+// migrating it keeps its data.
+func testMigrationsPopulated(t *testing.T) {
+	conn, err := da.connect(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `INSERT INTO users (username) VALUES ('test-populated');
+		INSERT INTO groups (groupname) VALUES ('test-populated');
+		INSERT INTO groupusers (groupname, username) VALUES ('test-populated', 'test-populated');
+		INSERT INTO roles (role_name) VALUES ('test-populated');
+		INSERT INTO group_roles (group_name, role_name) VALUES ('test-populated', 'test-populated');
+
+		ALTER TABLE groupusers DROP CONSTRAINT fk_groupusers_users;
+		ALTER TABLE groupusers ADD FOREIGN KEY (username) REFERENCES users;
+		ALTER TABLE group_roles DROP CONSTRAINT fk_group_roles_roles;
+		INSERT INTO group_roles (group_name, role_name) VALUES ('test-populated', 'test-populated-deleted');
+
+		DELETE FROM schema_migrations WHERE version=1;`)
+	require.NoError(t, err)
+
+	require.NoError(t, da.runMigrations(ctx, conn))
+
+	count := 0
+	err = conn.QueryRowContext(ctx, `SELECT count(*) FROM pg_constraint
+		WHERE contype = 'f' AND conrelid = 'groupusers'::regclass AND confrelid = 'users'::regclass`).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var roles []string
+	rows, err := conn.QueryContext(ctx, "SELECT role_name FROM group_roles WHERE group_name='test-populated'")
+	require.NoError(t, err)
+	for rows.Next() {
+		var role string
+		require.NoError(t, rows.Scan(&role))
+		roles = append(roles, role)
+	}
+	require.NoError(t, rows.Err())
+	rows.Close()
+	assert.Equal(t, []string{"test-populated"}, roles)
+
+	_, err = conn.ExecContext(ctx, `UPDATE users SET username='test-populated-renamed' WHERE username='test-populated';
+		UPDATE roles SET role_name='test-populated-renamed' WHERE role_name='test-populated';`)
+	require.NoError(t, err)
+
+	member, role := "", ""
+	err = conn.QueryRowContext(ctx, "SELECT username FROM groupusers WHERE groupname='test-populated'").Scan(&member)
+	require.NoError(t, err)
+	assert.Equal(t, "test-populated-renamed", member)
+	err = conn.QueryRowContext(ctx, "SELECT role_name FROM group_roles WHERE group_name='test-populated'").Scan(&role)
+	require.NoError(t, err)
+	assert.Equal(t, "test-populated-renamed", role)
+
+	_, err = conn.ExecContext(ctx, `DELETE FROM groupusers WHERE groupname='test-populated';
+		DELETE FROM groups WHERE groupname='test-populated';
+		DELETE FROM roles WHERE role_name='test-populated-renamed';
+		DELETE FROM users WHERE username='test-populated-renamed';`)
+	require.NoError(t, err)
+}
+
 func getTestBundle() (data.Bundle, error) {
 	return bundles.LoadBundleFromFile("../../testing/test-bundle.yml")
 }
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data/rest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The benchmarks in this file need a running test database, and are skipped
+// if there isn't one. The easiest way to get one is to run the main test
+// with DoNotCleanUpDatabase set to true, or to start one directly:
+//
+//   docker run --rm -p 10864:5432 -e POSTGRES_USER=gort \
+//       -e POSTGRES_PASSWORD=password postgres:14
+//
+// Then run: go test -run XXX -bench . ./dataaccess/postgres/
+
+// newBenchmarkDataAccess returns an initialized PostgresDataAccess for the
+// test database, skipping the benchmark if the database can't be reached.
+func newBenchmarkDataAccess(b *testing.B) (context.Context, PostgresDataAccess) {
+	ctx := context.Background()
+
+	bda := NewPostgresDataAccess(configs)
+	if _, err := bda.open(ctx, "postgres"); err != nil {
+		b.Skipf("test database isn't available: %v", err)
+	}
+
+	require.NoError(b, bda.Initialize(ctx))
+
+	return ctx, bda
+}
+
+func BenchmarkFindCommandEntry(b *testing.B) {
+	ctx, bda := newBenchmarkDataAccess(b)
+
+	bundle, err := getTestBundle()
+	require.NoError(b, err)
+
+	bda.BundleDelete(ctx, bundle.Name, bundle.Version)
+	require.NoError(b, bda.BundleCreate(ctx, bundle))
+	defer bda.BundleDelete(ctx, bundle.Name, bundle.Version)
+	require.NoError(b, bda.BundleEnable(ctx, bundle.Name, bundle.Version))
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := bda.FindCommandEntry(ctx, bundle.Name, "echox"); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkBundleList lists a store containing several versions of the test
+// bundle. Its cost should grow with the number of rows, not the number of
+// queries.
+func BenchmarkBundleList(b *testing.B) {
+	ctx, bda := newBenchmarkDataAccess(b)
+
+	bundle, err := getTestBundle()
+	require.NoError(b, err)
+
+	bundle.Name = "bench-list"
+	for i := 0; i < 10; i++ {
+		bundle.Version = fmt.Sprintf("0.0.%d", i)
+		bda.BundleDelete(ctx, bundle.Name, bundle.Version)
+		require.NoError(b, bda.BundleCreate(ctx, bundle))
+		defer bda.BundleDelete(ctx, bundle.Name, bundle.Version)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := bda.BundleList(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTokenEvaluate(b *testing.B) {
+	ctx, bda := newBenchmarkDataAccess(b)
+	token := createBenchmarkToken(ctx, b, bda)
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if !bda.TokenEvaluate(ctx, token.Token) {
+				b.Error("token evaluated as invalid")
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkTokenEvaluateDedicatedConnection runs the same query as
+// TokenEvaluate, but acquires (and releases) a dedicated connection for every
+// call the way the data access layer used to. It's here as a baseline for
+// BenchmarkTokenEvaluate.
+func BenchmarkTokenEvaluateDedicatedConnection(b *testing.B) {
+	ctx, bda := newBenchmarkDataAccess(b)
+	token := createBenchmarkToken(ctx, b, bda)
+
+	const query = `SELECT valid_until FROM tokens WHERE token=$1`
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			conn, err := bda.connect(ctx)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+
+			var validUntil time.Time
+			err = conn.QueryRowContext(ctx, query, token.Token).Scan(&validUntil)
+			conn.Close()
+
+			if err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func createBenchmarkToken(ctx context.Context, b *testing.B, bda PostgresDataAccess) rest.Token {
+	const username = "test-benchmark-user"
+
+	bda.UserDelete(ctx, username)
+	require.NoError(b, bda.UserCreate(ctx, rest.User{Username: username}))
+	b.Cleanup(func() { bda.UserDelete(ctx, username) })
+
+	token, err := bda.TokenGenerate(ctx, username, time.Hour)
+	require.NoError(b, err)
+
+	return token
+}
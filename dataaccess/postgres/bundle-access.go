@@ -19,13 +19,16 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
 
 	"go.opentelemetry.io/otel"
 
+	"github.com/getgort/gort/bundles"
 	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/dataaccess/errs"
 	gerr "github.com/getgort/gort/errors"
 	"github.com/getgort/gort/telemetry"
@@ -55,13 +58,12 @@ func (da PostgresDataAccess) BundleCreate(ctx context.Context, bundle data.Bundl
 		return errs.ErrEmptyBundleVersion
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -75,36 +77,7 @@ func (da PostgresDataAccess) BundleCreate(ctx context.Context, bundle data.Bundl
 		return errs.ErrBundleExists
 	}
 
-	// Save bundle
-	err = da.doBundleInsert(ctx, tx, bundle)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Save permissions
-	err = da.doBundleInsertPermissions(ctx, tx, bundle)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Save commands
-	err = da.doBundleInsertCommands(ctx, tx, bundle)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Save templates
-	err = da.doBundleInsertTemplates(ctx, tx, bundle)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Save kubernetes config
-	err = da.doBundleInsertKubernetes(ctx, tx, bundle)
+	err = da.doBundleCreate(ctx, tx, bundle)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -133,13 +106,12 @@ func (da PostgresDataAccess) BundleDelete(ctx context.Context, name, version str
 		return errs.ErrEmptyBundleVersion
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -180,13 +152,12 @@ func (da PostgresDataAccess) BundleDisable(ctx context.Context, name, version st
 	ctx, sp := tr.Start(ctx, "postgres.BundleDisable")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -212,13 +183,12 @@ func (da PostgresDataAccess) BundleEnable(ctx context.Context, name, version str
 	ctx, sp := tr.Start(ctx, "postgres.BundleEnable")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -245,13 +215,12 @@ func (da PostgresDataAccess) BundleEnabledVersion(ctx context.Context, bundlenam
 	ctx, sp := tr.Start(ctx, "postgres.BundleEnabledVersion")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return "", err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return "", gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -271,13 +240,12 @@ func (da PostgresDataAccess) BundleExists(ctx context.Context, name string) (boo
 	ctx, sp := tr.Start(ctx, "postgres.BundleExists")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return false, err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return false, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -292,13 +260,12 @@ func (da PostgresDataAccess) BundleVersionExists(ctx context.Context, name, vers
 	ctx, sp := tr.Start(ctx, "postgres.BundleVersionExists")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return false, err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return false, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -321,13 +288,12 @@ func (da PostgresDataAccess) BundleGet(ctx context.Context, name, version string
 		return data.Bundle{}, errs.ErrEmptyBundleVersion
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return data.Bundle{}, err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -347,56 +313,60 @@ func (da PostgresDataAccess) BundleList(ctx context.Context) ([]data.Bundle, err
 	ctx, sp := tr.Start(ctx, "postgres.BundleList")
 	defer sp.End()
 
-	// This is hacky as fuck. I know.
-	// I'll optimize later.
-
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return []data.Bundle{}, err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
 	}
+	defer tx.Commit()
 
-	query := `SELECT name, version FROM bundles`
-	rows, err := tx.QueryContext(ctx, query)
-	if err != nil {
-		tx.Rollback()
-		return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
-	}
+	return da.doBundleLoad(ctx, tx, "", "")
+}
 
-	bds := make([]bundleData, 0)
-	for rows.Next() {
-		var bd bundleData
+// BundleReviewUpdate sets the review state of a bundle version.
+func (da PostgresDataAccess) BundleReviewUpdate(ctx context.Context, name, version string, review data.BundleReview) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.BundleReviewUpdate")
+	defer sp.End()
 
-		err = rows.Scan(&bd.BundleName, &bd.BundleVersion)
-		if err != nil {
-			rows.Close()
-			tx.Rollback()
-			return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
-		}
+	if name == "" {
+		return errs.ErrEmptyBundleName
+	}
 
-		bds = append(bds, bd)
+	if version == "" {
+		return errs.ErrEmptyBundleVersion
 	}
-	rows.Close()
 
-	bundles := make([]data.Bundle, 0)
-	for _, bd := range bds {
-		bundle, err := da.doBundleGet(ctx, tx, bd.BundleName, bd.BundleVersion)
-		if err != nil {
-			tx.Rollback()
-			return []data.Bundle{}, err
-		}
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE bundles
+		SET review_status=$3, review_user=$4, review_timestamp=$5, review_comment=$6
+		WHERE name=$1 AND version=$2;`
 
-		bundles = append(bundles, bundle)
+	reviewedOn := sql.NullTime{Time: review.ReviewedOn, Valid: !review.ReviewedOn.IsZero()}
+
+	result, err := db.ExecContext(ctx, query, name, version,
+		review.Status, review.Reviewer, reviewedOn, review.Comment)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
-	tx.Commit()
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchBundle
+	}
 
-	return bundles, nil
+	return nil
 }
 
 // BundleUpdate TBD
@@ -413,13 +383,12 @@ func (da PostgresDataAccess) BundleUpdate(ctx context.Context, bundle data.Bundl
 		return errs.ErrEmptyBundleVersion
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -452,72 +421,83 @@ func (da PostgresDataAccess) BundleUpdate(ctx context.Context, bundle data.Bundl
 	return nil
 }
 
-// BundleVersionList TBD
-func (da PostgresDataAccess) BundleVersionList(ctx context.Context, name string) ([]data.Bundle, error) {
+// BundleUpgrade installs bundle if it isn't already installed, optionally
+// enables it, and then uninstalls all but the retain newest versions. All
+// changes are made in a single transaction, so a failure at any step leaves
+// the installed versions untouched. It returns ErrBundleVersionOlder if a
+// newer version is already installed.
+func (da PostgresDataAccess) BundleUpgrade(ctx context.Context, bundle data.Bundle, enable bool, retain int) (rest.BundleUpgradeResult, error) {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
-	ctx, sp := tr.Start(ctx, "postgres.BundleVersionList")
+	ctx, sp := tr.Start(ctx, "postgres.BundleUpgrade")
 	defer sp.End()
 
-	// This is hacky as fuck. I know.
-	// I'll optimize later.
+	if bundle.Name == "" {
+		return rest.BundleUpgradeResult{}, errs.ErrEmptyBundleName
+	}
+
+	if bundle.Version == "" {
+		return rest.BundleUpgradeResult{}, errs.ErrEmptyBundleVersion
+	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
-		return []data.Bundle{}, err
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return rest.BundleUpgradeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	result, err := da.doBundleUpgrade(ctx, tx, bundle, enable, retain)
 	if err != nil {
 		tx.Rollback()
-		return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
+		return rest.BundleUpgradeResult{}, err
 	}
-	defer tx.Commit()
 
-	query := `SELECT name, version FROM bundles WHERE name=$1`
-	rows, err := tx.QueryContext(ctx, query, name)
+	err = tx.Commit()
 	if err != nil {
-		return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
+		tx.Rollback()
+		return rest.BundleUpgradeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
-	bds := make([]bundleData, 0)
-	for rows.Next() {
-		var bd bundleData
+	return result, nil
+}
 
-		err = rows.Scan(&bd.BundleName, &bd.BundleVersion)
-		if err != nil {
-			rows.Close()
-			return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
-		}
+// BundleVersionList TBD
+func (da PostgresDataAccess) BundleVersionList(ctx context.Context, name string) ([]data.Bundle, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.BundleVersionList")
+	defer sp.End()
 
-		bds = append(bds, bd)
+	if name == "" {
+		return []data.Bundle{}, nil
 	}
-	rows.Close()
 
-	bundles := make([]data.Bundle, 0)
-	for _, bd := range bds {
-		bundle, err := da.doBundleGet(ctx, tx, bd.BundleName, bd.BundleVersion)
-		if err != nil {
-			return []data.Bundle{}, err
-		}
+	db, err := da.pool(ctx)
+	if err != nil {
+		return []data.Bundle{}, err
+	}
 
-		bundles = append(bundles, bundle)
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return []data.Bundle{}, gerr.Wrap(errs.ErrDataAccess, err)
 	}
+	defer tx.Commit()
 
-	return bundles, nil
+	return da.doBundleLoad(ctx, tx, name, "")
 }
 
 // FindCommandEntry is used to find the enabled commands with the provided
 // bundle and command names. If either is empty, it is treated as a wildcard.
 // Importantly, this must only return ENABLED commands!
 func (da PostgresDataAccess) FindCommandEntry(ctx context.Context, bundleName, commandName string) ([]data.CommandEntry, error) {
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return nil, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -527,13 +507,12 @@ func (da PostgresDataAccess) FindCommandEntry(ctx context.Context, bundleName, c
 }
 
 func (da PostgresDataAccess) FindCommandEntryByTrigger(ctx context.Context, tokens []string) ([]data.CommandEntry, error) {
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
-	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return nil, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -542,6 +521,90 @@ func (da PostgresDataAccess) FindCommandEntryByTrigger(ctx context.Context, toke
 	return da.doFindCommandEntryByTrigger(ctx, tx, tokens)
 }
 
+// doBundleUpgrade performs the work of BundleUpgrade inside tx. The caller
+// is responsible for committing or rolling back the transaction.
+func (da PostgresDataAccess) doBundleUpgrade(ctx context.Context, tx *sql.Tx, bundle data.Bundle, enable bool, retain int) (rest.BundleUpgradeResult, error) {
+	installed, err := da.doBundleInstalledVersions(ctx, tx, bundle.Name)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	if _, newer := bundles.NewerVersionInstalled(installed, bundle.Version); newer {
+		return rest.BundleUpgradeResult{}, errs.ErrBundleVersionOlder
+	}
+
+	result := rest.BundleUpgradeResult{Name: bundle.Name, Version: bundle.Version}
+
+	result.PreviousEnabled, err = da.doBundleEnabledVersion(ctx, tx, bundle.Name)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	exists, err := da.doBundleVersionExists(ctx, tx, bundle.Name, bundle.Version)
+	if err != nil {
+		return rest.BundleUpgradeResult{}, err
+	}
+
+	if !exists {
+		if err = da.doBundleCreate(ctx, tx, bundle); err != nil {
+			return rest.BundleUpgradeResult{}, err
+		}
+
+		result.Installed = true
+		installed = append(installed, bundle)
+	}
+
+	enabled := result.PreviousEnabled
+	if enable && enabled != bundle.Version {
+		if err = da.doBundleEnable(ctx, tx, bundle.Name, bundle.Version); err != nil {
+			return rest.BundleUpgradeResult{}, err
+		}
+
+		enabled = bundle.Version
+	}
+	result.Enabled = (enabled == bundle.Version)
+
+	for _, v := range bundles.PruneCandidates(installed, enabled, retain) {
+		if err = da.doBundleDelete(ctx, tx, bundle.Name, v); err != nil {
+			return rest.BundleUpgradeResult{}, err
+		}
+
+		result.Pruned = append(result.Pruned, v)
+	}
+
+	return result, nil
+}
+
+// doBundleCreate inserts a bundle and all of its associated data.
+func (da PostgresDataAccess) doBundleCreate(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
+	// Save bundle
+	err := da.doBundleInsert(ctx, tx, bundle)
+	if err != nil {
+		return err
+	}
+
+	// Save permissions
+	err = da.doBundleInsertPermissions(ctx, tx, bundle)
+	if err != nil {
+		return err
+	}
+
+	// Save commands
+	err = da.doBundleInsertCommands(ctx, tx, bundle)
+	if err != nil {
+		return err
+	}
+
+	// Save templates
+	err = da.doBundleInsertTemplates(ctx, tx, bundle)
+	if err != nil {
+		return err
+	}
+
+	// Save kubernetes config
+	return da.doBundleInsertKubernetes(ctx, tx, bundle)
+}
+
 func (da PostgresDataAccess) doBundleDelete(ctx context.Context, tx *sql.Tx, name string, version string) error {
 	query := "DELETE FROM bundle_kubernetes WHERE bundle_name=$1 AND bundle_version=$2;"
 	_, err := tx.ExecContext(ctx, query, name, version)
@@ -632,6 +695,35 @@ func (da PostgresDataAccess) doBundleEnabledVersion(ctx context.Context, tx *sql
 	return enabled, nil
 }
 
+// doBundleInstalledVersions returns the installed versions of the named
+// bundle. Only the Name and Version fields are populated.
+func (da PostgresDataAccess) doBundleInstalledVersions(ctx context.Context, tx *sql.Tx, name string) ([]data.Bundle, error) {
+	query := `SELECT version FROM bundles WHERE name=$1`
+
+	rows, err := tx.QueryContext(ctx, query, name)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	installed := make([]data.Bundle, 0)
+	for rows.Next() {
+		b := data.Bundle{Name: name}
+
+		if err = rows.Scan(&b.Version); err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		installed = append(installed, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return installed, nil
+}
+
 // BundleExists TBD
 func (da PostgresDataAccess) doBundleExists(ctx context.Context, tx *sql.Tx, name string) (bool, error) {
 	query := "SELECT EXISTS(SELECT 1 FROM bundles WHERE name=$1)"
@@ -669,33 +761,32 @@ func (da PostgresDataAccess) doFindCommandEntry(ctx context.Context, tx *sql.Tx,
 
 	entries := make([]data.CommandEntry, 0)
 
-	for _, cd := range bcd {
-		entry := data.CommandEntry{}
+	// Load each matching bundle only once, however many of its commands match.
+	loaded := map[bundleData]data.Bundle{}
 
-		// Load the appropriate bundle
-		entry.Bundle, err = da.doBundleGet(ctx, tx, cd.BundleName, cd.BundleVersion)
-		if err != nil {
-			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	for _, cd := range bcd {
+		b, ok := loaded[cd.bundleData]
+		if !ok {
+			b, err = da.doBundleGet(ctx, tx, cd.BundleName, cd.BundleVersion)
+			if err != nil {
+				return nil, gerr.Wrap(errs.ErrDataAccess, err)
+			}
+			loaded[cd.bundleData] = b
 		}
 
-		// Load the relevant bundle command (there should be exactly one)
-		commands, err := da.doBundleGetCommands(ctx, tx, cd.BundleName, cd.BundleVersion, cd.Name)
-		if err != nil {
-			return nil, gerr.Wrap(errs.ErrDataAccess, err)
-		}
-		if len(commands) != 1 {
-			return nil, gerr.Wrap(errs.ErrDataAccess, fmt.Errorf("unexpected commands count: %d", len(commands)))
+		cmd, ok := b.Commands[cd.Name]
+		if !ok {
+			return nil, gerr.Wrap(errs.ErrDataAccess, fmt.Errorf("no such command: %s:%s", cd.BundleName, cd.Name))
 		}
-		entry.Command = *commands[0]
 
-		entries = append(entries, entry)
+		entries = append(entries, data.CommandEntry{Bundle: b, Command: *cmd})
 	}
 
 	return entries, nil
 }
 
 func (da PostgresDataAccess) doFindCommandEntryByTrigger(ctx context.Context, tx *sql.Tx, tokens []string) ([]data.CommandEntry, error) {
-	bundles, err := da.BundleList(ctx)
+	bundles, err := da.doBundleLoad(ctx, tx, "", "")
 	if err != nil {
 		return nil, err
 	}
@@ -703,6 +794,11 @@ func (da PostgresDataAccess) doFindCommandEntryByTrigger(ctx context.Context, tx
 	entries := make([]data.CommandEntry, 0)
 
 	for _, bundle := range bundles {
+		// Only enabled bundles' triggers are live.
+		if !bundle.Enabled {
+			continue
+		}
+
 		for _, cmd := range bundle.Commands {
 			matched, err := cmd.MatchTrigger(ctx, strings.Join(tokens, " "))
 			if err != nil {
@@ -722,303 +818,614 @@ func (da PostgresDataAccess) doFindCommandEntryByTrigger(ctx context.Context, tx
 }
 
 func (da PostgresDataAccess) doBundleGet(ctx context.Context, tx *sql.Tx, name string, version string) (data.Bundle, error) {
-	query := `SELECT gort_bundle_version, name, version, author, homepage,
-			description, long_description, image_repository, image_tag,
-			install_timestamp, install_user
-		FROM bundles
-		WHERE name=$1 AND version=$2`
-
-	var repository, tag string
-
-	bundle := data.Bundle{}
-	row := tx.QueryRowContext(ctx, query, name, version)
-	err := row.Scan(&bundle.GortBundleVersion, &bundle.Name, &bundle.Version,
-		&bundle.Author, &bundle.Homepage, &bundle.Description,
-		&bundle.LongDescription, &repository, &tag,
-		&bundle.InstalledOn, &bundle.InstalledBy)
-	if err != nil {
-		return bundle, gerr.Wrap(errs.ErrNoSuchBundle, err)
-	}
-
-	if repository != "" {
-		if tag == "" {
-			tag = "latest"
-		}
-
-		bundle.Image = repository + ":" + tag
+	if name == "" || version == "" {
+		return data.Bundle{}, errs.ErrNoSuchBundle
 	}
 
-	enabledVersion, err := da.doBundleEnabledVersion(ctx, tx, name)
+	bundles, err := da.doBundleLoad(ctx, tx, name, version)
 	if err != nil {
-		return bundle, gerr.Wrap(fmt.Errorf("failed to get bundle enabled version"), err)
-	}
-	bundle.Enabled = (bundle.Version == enabledVersion)
-
-	// Load bundle permissions
-	bundle.Permissions, err = da.doBundleGetPermissions(ctx, tx, name, version)
-	if err != nil {
-		return bundle, gerr.Wrap(fmt.Errorf("failed to get bundle permissions"), err)
-	}
-
-	// Load all commands (and their rules) for this bundle
-	commandSlice, err := da.doBundleGetCommands(ctx, tx, name, version, "")
-	if err != nil {
-		return bundle, gerr.Wrap(fmt.Errorf("failed to get bundle commands"), err)
+		return data.Bundle{}, err
 	}
 
-	bundle.Commands = make(map[string]*data.BundleCommand)
-	for _, command := range commandSlice {
-		bundle.Commands[command.Name] = command
+	if len(bundles) == 0 {
+		return data.Bundle{}, errs.ErrNoSuchBundle
 	}
 
-	bundle.Templates, err = da.doBundleGetTemplates(ctx, tx, name, version)
-	if err != nil {
-		return bundle, gerr.Wrap(fmt.Errorf("failed to get bundle templates"), err)
-	}
+	return bundles[0], nil
+}
 
-	bundle.Kubernetes, err = da.doBundleGetKubernetes(ctx, tx, name, version)
-	if err != nil {
-		return bundle, gerr.Wrap(fmt.Errorf("failed to get bundle kubernetes config"), err)
-	}
+// bundleLoadFilter restricts a query against one of the bundle_* tables to
+// the name and version being loaded by doBundleLoad. Either may be empty, in
+// which case it matches all.
+const bundleLoadFilter = `($1::TEXT = '' OR bundle_name = $1) AND ($2::TEXT = '' OR bundle_version = $2)`
 
-	return bundle, nil
+// bundleCommandKey identifies a single command of a single bundle version.
+type bundleCommandKey struct {
+	bundleData
+	CommandName string
 }
 
-// doBundleGetCommandsData is a helper method that retrieves zero or more
-// commands for the specified bundle name+version, along with the owning
-// bundle's name and version. Empty string parameters are treated as wildcards.
-func (da PostgresDataAccess) doBundleGetCommandsData(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion, commandName string, enabledOnly bool) ([]bundleCommandData, error) {
-	var query string
-
-	if bundleName == "" {
-		bundleName = "%"
-	}
-	if bundleVersion == "" {
-		bundleVersion = "%"
-	}
-	if commandName == "" {
-		commandName = "%"
-	}
+// bundleLoader accumulates the bundles loaded by doBundleLoad.
+type bundleLoader struct {
+	tx            *sql.Tx
+	name, version string
+	args          []interface{}
 
-	if enabledOnly {
-		query = `SELECT bundle_commands.bundle_name, bundle_commands.bundle_version, name, description, executable, long_description
-			FROM bundle_commands
-			INNER JOIN bundle_enabled ON bundle_commands.bundle_name=bundle_enabled.bundle_name
-			WHERE bundle_commands.bundle_name LIKE $1 AND bundle_commands.bundle_version LIKE $2 AND name LIKE $3`
-	} else {
-		query = `SELECT bundle_commands.bundle_name, bundle_commands.bundle_version, name, description, executable, long_description
-			FROM bundle_commands
-			WHERE bundle_commands.bundle_name LIKE $1 AND bundle_commands.bundle_version LIKE $2 AND name LIKE $3`
-	}
+	keys     []bundleData
+	bundles  map[bundleData]*data.Bundle
+	commands map[bundleCommandKey]*data.BundleCommand
+}
 
-	rows, err := tx.QueryContext(ctx, query, bundleName, bundleVersion, commandName)
+// query runs a query using the loader's name and version filter arguments,
+// calling scan for each row.
+func (l *bundleLoader) query(ctx context.Context, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := l.tx.QueryContext(ctx, query, l.args...)
 	if err != nil {
-		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
 	defer rows.Close()
 
-	commands := make([]bundleCommandData, 0)
-
 	for rows.Next() {
-		var enc string
-		cd := bundleCommandData{}
-
-		err = rows.Scan(&cd.BundleName, &cd.BundleVersion, &cd.Name, &cd.Description, &enc, &cd.LongDescription)
-		if err != nil {
-			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		if err := scan(rows); err != nil {
+			return err
 		}
-
-		cd.Executable = decodeStringSlice(enc)
-		commands = append(commands, cd)
 	}
 
-	return commands, nil
-}
-
-// doBundleGetCommands empty strings become wildcards
-func (da PostgresDataAccess) doBundleGetCommands(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion, commandName string) ([]*data.BundleCommand, error) {
-	bcd, err := da.doBundleGetCommandsData(ctx, tx, bundleName, bundleVersion, commandName, false)
-	if err != nil {
-		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	if err := rows.Err(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
-	commands := make([]*data.BundleCommand, 0)
+	return nil
+}
 
-	for _, bc := range bcd {
-		bc.BundleCommand.Triggers, err = da.doBundleGetCommandTriggers(ctx, tx, bundleName, bundleVersion, bc.Name)
-		if err != nil {
-			return nil, gerr.Wrap(fmt.Errorf("failed to get bundle command templates"), err)
-		}
+// command returns the loaded command with the given key, or nil if its
+// bundle wasn't loaded.
+func (l *bundleLoader) command(bundleName, bundleVersion, commandName string) *data.BundleCommand {
+	return l.commands[bundleCommandKey{bundleData{bundleName, bundleVersion}, commandName}]
+}
 
-		bc.BundleCommand.Rules, err = da.doBundleGetCommandRules(ctx, tx, bundleName, bundleVersion, bc.Name)
-		if err != nil {
-			return nil, gerr.Wrap(fmt.Errorf("failed to get bundle command rules"), err)
+// doBundleLoad loads every version of every bundle matching name and
+// version, either of which may be empty to match all, ordered by name and
+// version. Rather than querying each table once per bundle and command, it
+// queries each table once, so the number of queries is fixed no matter how
+// many bundles are loaded.
+func (da PostgresDataAccess) doBundleLoad(ctx context.Context, tx *sql.Tx, name, version string) ([]data.Bundle, error) {
+	l := &bundleLoader{
+		tx:       tx,
+		name:     name,
+		version:  version,
+		args:     []interface{}{name, version},
+		bundles:  map[bundleData]*data.Bundle{},
+		commands: map[bundleCommandKey]*data.BundleCommand{},
+	}
+
+	steps := []struct {
+		what string
+		load func(ctx context.Context, l *bundleLoader) error
+	}{
+		{"bundles", da.doBundleLoadBundles},
+		{"bundle enabled versions", da.doBundleLoadEnabled},
+		{"bundle permissions", da.doBundleLoadPermissions},
+		{"bundle templates", da.doBundleLoadTemplates},
+		{"bundle kubernetes config", da.doBundleLoadKubernetes},
+		{"bundle commands", da.doBundleLoadCommands},
+		{"bundle command triggers", da.doBundleLoadCommandTriggers},
+		{"bundle command options", da.doBundleLoadCommandOptions},
+		{"bundle command profiles", da.doBundleLoadCommandProfiles},
+		{"bundle command rules", da.doBundleLoadCommandRules},
+		{"bundle command templates", da.doBundleLoadCommandTemplates},
+	}
+
+	for _, s := range steps {
+		if err := s.load(ctx, l); err != nil {
+			return nil, gerr.Wrap(fmt.Errorf("failed to get %s", s.what), err)
 		}
 
-		bc.BundleCommand.Templates, err = da.doBundleGetCommandTemplates(ctx, tx, bundleName, bundleVersion, bc.Name)
-		if err != nil {
-			return nil, gerr.Wrap(fmt.Errorf("failed to get bundle command templates"), err)
+		// There's nothing more to load if no bundles matched.
+		if len(l.keys) == 0 {
+			return []data.Bundle{}, nil
 		}
+	}
 
-		command := bc.BundleCommand
-		commands = append(commands, &command)
+	bundles := make([]data.Bundle, 0, len(l.keys))
+	for _, key := range l.keys {
+		bundles = append(bundles, *l.bundles[key])
 	}
 
-	return commands, nil
+	return bundles, nil
 }
 
-func (da PostgresDataAccess) doBundleGetCommandTriggers(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion, commandName string) ([]data.Trigger, error) {
-	cmdQuery := `SELECT match
-		FROM bundle_command_triggers
-		WHERE bundle_name=$1 AND bundle_version=$2 AND command_name=$3`
+func (da PostgresDataAccess) doBundleLoadBundles(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT gort_bundle_version, name, version, author, homepage,
+			description, long_description, image_repository, image_tag,
+			install_timestamp, install_user, platform_os, platform_arch,
+			output_filters, serverless_function, serverless_job, ssh,
+			review_status, review_user, review_timestamp, review_comment,
+			telemetry_attributes, resource_cpu, resource_memory, owners
+		FROM bundles
+		WHERE ($1::TEXT = '' OR name = $1) AND ($2::TEXT = '' OR version = $2)
+		ORDER BY name, version`
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var repository, tag, filters, ssh, attributes, owners string
+		var reviewedOn sql.NullTime
+
+		bundle := data.Bundle{}
+		err := rows.Scan(&bundle.GortBundleVersion, &bundle.Name, &bundle.Version,
+			&bundle.Author, &bundle.Homepage, &bundle.Description,
+			&bundle.LongDescription, &repository, &tag,
+			&bundle.InstalledOn, &bundle.InstalledBy,
+			&bundle.Platform.OS, &bundle.Platform.Arch, &filters,
+			&bundle.Serverless.Function, &bundle.Serverless.Job, &ssh,
+			&bundle.Review.Status, &bundle.Review.Reviewer, &reviewedOn, &bundle.Review.Comment,
+			&attributes, &bundle.Resources.CPU, &bundle.Resources.Memory, &owners)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
 
-	rows, err := tx.QueryContext(ctx, cmdQuery, bundleName, bundleVersion, commandName)
-	if err != nil {
-		return nil, gerr.Wrap(errs.ErrDataAccess, err)
-	}
-	defer rows.Close()
+		if owners != "" {
+			bundle.Owners = decodeStringSlice(owners)
+		}
 
-	var triggers []data.Trigger
-	for rows.Next() {
-		var trigger data.Trigger
+		if filters != "" {
+			if err := json.Unmarshal([]byte(filters), &bundle.OutputFilters); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if ssh != "" {
+			if err := json.Unmarshal([]byte(ssh), &bundle.SSH); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if attributes != "" {
+			if err := json.Unmarshal([]byte(attributes), &bundle.Telemetry); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if reviewedOn.Valid {
+			bundle.Review.ReviewedOn = reviewedOn.Time
+		}
+
+		if repository != "" {
+			if tag == "" {
+				tag = "latest"
+			}
+
+			bundle.Image = repository + ":" + tag
+		}
+
+		bundle.Permissions = make([]string, 0)
+		bundle.Commands = make(map[string]*data.BundleCommand)
+
+		key := bundleData{bundle.Name, bundle.Version}
+		l.keys = append(l.keys, key)
+		l.bundles[key] = &bundle
+
+		return nil
+	})
+}
+
+func (da PostgresDataAccess) doBundleLoadEnabled(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version
+		FROM bundle_enabled
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+
+		if err := rows.Scan(&key.BundleName, &key.BundleVersion); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			b.Enabled = true
+		}
+
+		return nil
+	})
+}
+
+func (da PostgresDataAccess) doBundleLoadPermissions(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, permission
+		FROM bundle_permissions
+		WHERE ` + bundleLoadFilter + `
+		ORDER BY bundle_name, bundle_version, index`
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var perm string
 
-		err = rows.Scan(&trigger.Match)
+		if err := rows.Scan(&key.BundleName, &key.BundleVersion, &perm); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			b.Permissions = append(b.Permissions, perm)
+		}
+
+		return nil
+	})
+}
+
+func (da PostgresDataAccess) doBundleLoadTemplates(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version,
+			command, command_error, command_timeout, digest, message, message_error
+		FROM bundle_templates
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var templates data.Templates
+
+		err := rows.Scan(&key.BundleName, &key.BundleVersion,
+			&templates.Command, &templates.CommandError, &templates.CommandTimeout,
+			&templates.Digest, &templates.Message, &templates.MessageError)
 		if err != nil {
-			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+			return gerr.Wrap(errs.ErrDataAccess, err)
 		}
 
-		triggers = append(triggers, trigger)
-	}
+		if b, ok := l.bundles[key]; ok {
+			b.Templates = templates
+		}
 
-	return triggers, nil
+		return nil
+	})
 }
 
-func (da PostgresDataAccess) doBundleGetCommandRules(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion, commandName string) ([]string, error) {
-	cmdQuery := `SELECT rule
-		FROM bundle_command_rules
-		WHERE bundle_name=$1 AND bundle_version=$2 AND command_name=$3`
+func (da PostgresDataAccess) doBundleLoadKubernetes(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, service_account_name, env_secret, cluster, namespace
+		FROM bundle_kubernetes
+		WHERE ` + bundleLoadFilter
+
+	err := l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var kubernetes data.BundleKubernetes
+
+		err := rows.Scan(&key.BundleName, &key.BundleVersion,
+			&kubernetes.ServiceAccountName, &kubernetes.EnvSecret, &kubernetes.Cluster, &kubernetes.Namespace)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			b.Kubernetes = kubernetes
+		}
 
-	rows, err := tx.QueryContext(ctx, cmdQuery, bundleName, bundleVersion, commandName)
+		return nil
+	})
 	if err != nil {
-		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		return err
 	}
-	defer rows.Close()
 
-	rules := make([]string, 0)
-	for rows.Next() {
-		var rule string
+	query = `SELECT bundle_name, bundle_version, key, value
+		FROM bundle_kubernetes_node_selectors
+		WHERE ` + bundleLoadFilter
 
-		err = rows.Scan(&rule)
-		if err != nil {
-			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	err = l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var selectorKey, selectorValue string
+
+		if err := rows.Scan(&key.BundleName, &key.BundleVersion, &selectorKey, &selectorValue); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
 		}
 
-		rules = append(rules, rule)
+		if b, ok := l.bundles[key]; ok {
+			if b.Kubernetes.NodeSelector == nil {
+				b.Kubernetes.NodeSelector = map[string]string{}
+			}
+			b.Kubernetes.NodeSelector[selectorKey] = selectorValue
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return rules, nil
+	query = `SELECT bundle_name, bundle_version, group_name, service_account_name
+		FROM bundle_kubernetes_service_accounts
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var key bundleData
+		var group, sa string
+
+		if err := rows.Scan(&key.BundleName, &key.BundleVersion, &group, &sa); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if b, ok := l.bundles[key]; ok {
+			if b.Kubernetes.GroupServiceAccounts == nil {
+				b.Kubernetes.GroupServiceAccounts = map[string]string{}
+			}
+			b.Kubernetes.GroupServiceAccounts[group] = sa
+		}
+
+		return nil
+	})
 }
 
-func (da PostgresDataAccess) doBundleGetCommandTemplates(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion, commandName string) (data.Templates, error) {
-	query := `SELECT command, command_error, message, message_error
-		FROM bundle_command_templates
-		WHERE bundle_name=$1 AND bundle_version=$2 AND command_name=$3`
+func (da PostgresDataAccess) doBundleLoadCommands(ctx context.Context, l *bundleLoader) error {
+	bcd, err := da.doBundleGetCommandsData(ctx, l.tx, l.name, l.version, "", false)
+	if err != nil {
+		return err
+	}
 
-	var templates data.Templates
+	for _, cd := range bcd {
+		b, ok := l.bundles[cd.bundleData]
+		if !ok {
+			continue
+		}
 
-	err := tx.QueryRowContext(ctx, query, bundleName, bundleVersion, commandName).
-		Scan(&templates.Command, &templates.CommandError, &templates.Message, &templates.MessageError)
+		command := cd.BundleCommand
+		command.Rules = make([]string, 0)
 
-	switch {
-	case err == sql.ErrNoRows:
-		return data.Templates{}, errs.ErrNoSuchUser
-	case err != nil:
-		return data.Templates{}, gerr.Wrap(errs.ErrDataAccess, err)
+		b.Commands[command.Name] = &command
+		l.commands[bundleCommandKey{cd.bundleData, command.Name}] = &command
 	}
 
-	return templates, nil
+	return nil
 }
 
-func (da PostgresDataAccess) doBundleGetKubernetes(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion string) (data.BundleKubernetes, error) {
-	query := `SELECT service_account_name, env_secret
-		FROM bundle_kubernetes
-		WHERE bundle_name=$1 AND bundle_version=$2`
+func (da PostgresDataAccess) doBundleLoadCommandTriggers(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name, match, channels
+		FROM bundle_command_triggers
+		WHERE ` + bundleLoadFilter
 
-	var kubernetes data.BundleKubernetes
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName, channels string
+		var trigger data.Trigger
 
-	err := tx.QueryRowContext(ctx, query, bundleName, bundleVersion).
-		Scan(&kubernetes.ServiceAccountName, &kubernetes.EnvSecret)
+		err := rows.Scan(&bundleName, &bundleVersion, &commandName, &trigger.Match, &channels)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
 
-	switch {
-	case err == sql.ErrNoRows:
-		return data.BundleKubernetes{}, errs.ErrNoSuchUser
-	case err != nil:
-		return data.BundleKubernetes{}, gerr.Wrap(errs.ErrDataAccess, err)
-	}
+		if channels != "" {
+			trigger.Channels = decodeStringSlice(channels)
+		}
+
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			c.Triggers = append(c.Triggers, trigger)
+		}
 
-	return kubernetes, nil
+		return nil
+	})
 }
 
-func (da PostgresDataAccess) doBundleGetPermissions(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion string) ([]string, error) {
-	// Load permissions
-	query := `SELECT permission
-		FROM bundle_permissions
-		WHERE bundle_name=$1 AND bundle_version=$2
-		ORDER BY index`
+func (da PostgresDataAccess) doBundleLoadCommandOptions(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name, name, description, sensitive, permissions
+		FROM bundle_command_options
+		WHERE ` + bundleLoadFilter
 
-	rows, err := tx.QueryContext(ctx, query, bundleName, bundleVersion)
-	if err != nil {
-		return nil, gerr.Wrap(errs.ErrDataAccess, err)
-	}
-	defer rows.Close()
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName, name, permissions string
+		var option data.BundleCommandOption
 
-	permissions := make([]string, 0)
+		err := rows.Scan(&bundleName, &bundleVersion, &commandName,
+			&name, &option.Description, &option.Sensitive, &permissions)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
 
-	for rows.Next() {
-		var perm string
+		if permissions != "" {
+			if err := json.Unmarshal([]byte(permissions), &option.Permissions); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
+
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			if c.Options == nil {
+				c.Options = map[string]*data.BundleCommandOption{}
+			}
+			c.Options[name] = &option
+		}
+
+		return nil
+	})
+}
+
+func (da PostgresDataAccess) doBundleLoadCommandProfiles(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name,
+			name, description, env, cluster, env_secret, service_account_name, permission
+		FROM bundle_command_profiles
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName, name, env string
+		var profile data.BundleCommandProfile
 
-		err = rows.Scan(&perm)
+		err := rows.Scan(&bundleName, &bundleVersion, &commandName,
+			&name, &profile.Description, &env, &profile.Cluster, &profile.EnvSecret,
+			&profile.ServiceAccountName, &profile.Permission)
 		if err != nil {
-			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+			return gerr.Wrap(errs.ErrDataAccess, err)
 		}
 
-		permissions = append(permissions, perm)
-	}
-	rows.Close()
+		if env != "" {
+			if err := json.Unmarshal([]byte(env), &profile.Env); err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+		}
 
-	return permissions, nil
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			if c.Profiles == nil {
+				c.Profiles = map[string]*data.BundleCommandProfile{}
+			}
+			c.Profiles[name] = &profile
+		}
+
+		return nil
+	})
 }
 
-func (da PostgresDataAccess) doBundleGetTemplates(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion string) (data.Templates, error) {
-	query := `SELECT command, command_error, message, message_error FROM bundle_templates
-		WHERE bundle_name=$1 AND bundle_version=$2`
+func (da PostgresDataAccess) doBundleLoadCommandRules(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name, rule
+		FROM bundle_command_rules
+		WHERE ` + bundleLoadFilter
 
-	var templates data.Templates
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName, rule string
 
-	err := tx.QueryRowContext(ctx, query, bundleName, bundleVersion).
-		Scan(&templates.Command, &templates.CommandError, &templates.Message, &templates.MessageError)
+		if err := rows.Scan(&bundleName, &bundleVersion, &commandName, &rule); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
 
-	switch {
-	case err == sql.ErrNoRows:
-		return data.Templates{}, errs.ErrNoSuchUser
-	case err != nil:
-		return data.Templates{}, gerr.Wrap(errs.ErrDataAccess, err)
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			c.Rules = append(c.Rules, rule)
+		}
+
+		return nil
+	})
+}
+
+func (da PostgresDataAccess) doBundleLoadCommandTemplates(ctx context.Context, l *bundleLoader) error {
+	query := `SELECT bundle_name, bundle_version, command_name,
+			command, command_error, command_timeout, digest, message, message_error
+		FROM bundle_command_templates
+		WHERE ` + bundleLoadFilter
+
+	return l.query(ctx, query, func(rows *sql.Rows) error {
+		var bundleName, bundleVersion, commandName string
+		var templates data.Templates
+
+		err := rows.Scan(&bundleName, &bundleVersion, &commandName,
+			&templates.Command, &templates.CommandError, &templates.CommandTimeout,
+			&templates.Digest, &templates.Message, &templates.MessageError)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		if c := l.command(bundleName, bundleVersion, commandName); c != nil {
+			c.Templates = templates
+		}
+
+		return nil
+	})
+}
+
+// doBundleGetCommandsData is a helper method that retrieves zero or more
+// commands for the specified bundle name+version, along with the owning
+// bundle's name and version. Empty string parameters are treated as wildcards.
+func (da PostgresDataAccess) doBundleGetCommandsData(ctx context.Context, tx *sql.Tx, bundleName, bundleVersion, commandName string, enabledOnly bool) ([]bundleCommandData, error) {
+	var query string
+
+	if bundleName == "" {
+		bundleName = "%"
+	}
+	if bundleVersion == "" {
+		bundleVersion = "%"
+	}
+	if commandName == "" {
+		commandName = "%"
+	}
+
+	if enabledOnly {
+		query = `SELECT bundle_commands.bundle_name, bundle_commands.bundle_version, name, description, exclusive, executable, long_description,
+			bundle_commands.platform_os, bundle_commands.platform_arch, bundle_commands.cooldown,
+			bundle_commands.ansi, bundle_commands.default_profile,
+			bundle_commands.ephemeral_output, bundle_commands.ephemeral_ttl,
+			bundle_commands.secret_output,
+			bundle_commands.reaction_success, bundle_commands.reaction_failure,
+			bundle_commands.usage, bundle_commands.examples, bundle_commands.native_help,
+			bundle_commands.timeout
+			FROM bundle_commands
+			INNER JOIN bundle_enabled ON bundle_commands.bundle_name=bundle_enabled.bundle_name
+			WHERE bundle_commands.bundle_name LIKE $1 AND bundle_commands.bundle_version LIKE $2 AND name LIKE $3`
+	} else {
+		query = `SELECT bundle_commands.bundle_name, bundle_commands.bundle_version, name, description, exclusive, executable, long_description,
+			bundle_commands.platform_os, bundle_commands.platform_arch, bundle_commands.cooldown,
+			bundle_commands.ansi, bundle_commands.default_profile,
+			bundle_commands.ephemeral_output, bundle_commands.ephemeral_ttl,
+			bundle_commands.secret_output,
+			bundle_commands.reaction_success, bundle_commands.reaction_failure,
+			bundle_commands.usage, bundle_commands.examples, bundle_commands.native_help,
+			bundle_commands.timeout
+			FROM bundle_commands
+			WHERE bundle_commands.bundle_name LIKE $1 AND bundle_commands.bundle_version LIKE $2 AND name LIKE $3`
 	}
 
-	return templates, nil
+	rows, err := tx.QueryContext(ctx, query, bundleName, bundleVersion, commandName)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	commands := make([]bundleCommandData, 0)
+
+	for rows.Next() {
+		var enc, examples string
+		cd := bundleCommandData{}
+
+		err = rows.Scan(&cd.BundleName, &cd.BundleVersion, &cd.Name, &cd.Description, &cd.Exclusive, &enc, &cd.LongDescription,
+			&cd.Platform.OS, &cd.Platform.Arch, &cd.Cooldown, &cd.ANSI, &cd.DefaultProfile,
+			&cd.EphemeralOutput, &cd.EphemeralTTL, &cd.SecretOutput,
+			&cd.Reactions.Success, &cd.Reactions.Failure,
+			&cd.Usage, &examples, &cd.NativeHelp, &cd.Timeout)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		cd.Executable = decodeStringSlice(enc)
+		if examples != "" {
+			cd.Examples = decodeStringSlice(examples)
+		}
+		commands = append(commands, cd)
+	}
+
+	return commands, nil
 }
 
 func (da PostgresDataAccess) doBundleInsert(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
 	query := `INSERT INTO bundles (gort_bundle_version, name, version, author,
 		homepage, description, long_description, image_repository, image_tag,
-		install_user)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
+		install_user, platform_os, platform_arch, output_filters,
+		serverless_function, serverless_job, ssh, review_status, review_user,
+		review_timestamp, review_comment, telemetry_attributes, resource_cpu,
+		resource_memory, owners)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+		$17, $18, $19, $20, $21, $22, $23, $24);`
 
 	repository, tag := bundle.ImageFullParts()
 
+	var filters string
+	if !bundle.OutputFilters.IsZero() {
+		b, err := json.Marshal(bundle.OutputFilters)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		filters = string(b)
+	}
+
+	var ssh string
+	if !bundle.SSH.IsZero() {
+		b, err := json.Marshal(bundle.SSH)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		ssh = string(b)
+	}
+
+	var attributes string
+	if len(bundle.Telemetry) > 0 {
+		b, err := json.Marshal(bundle.Telemetry)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		attributes = string(b)
+	}
+
 	_, err := tx.ExecContext(ctx, query, bundle.GortBundleVersion, bundle.Name, bundle.Version,
 		bundle.Author, bundle.Homepage, bundle.Description, bundle.LongDescription,
-		repository, tag, bundle.InstalledBy)
+		repository, tag, bundle.InstalledBy, bundle.Platform.OS, bundle.Platform.Arch, filters,
+		bundle.Serverless.Function, bundle.Serverless.Job, ssh,
+		bundle.Review.Status, bundle.Review.Reviewer,
+		sql.NullTime{Time: bundle.Review.ReviewedOn, Valid: !bundle.Review.ReviewedOn.IsZero()},
+		bundle.Review.Comment, attributes, bundle.Resources.CPU, bundle.Resources.Memory,
+		encodeStringSlice(bundle.Owners))
 
 	if err != nil {
 		if strings.Contains(err.Error(), "violates") {
@@ -1037,11 +1444,87 @@ func (da PostgresDataAccess) doBundleInsertCommandTriggers(ctx context.Context,
 	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
 
 	query := `INSERT INTO bundle_command_triggers
-		(bundle_name, bundle_version, command_name, match)
-		VALUES ($1, $2, $3, $4);`
+		(bundle_name, bundle_version, command_name, match, channels)
+		VALUES ($1, $2, $3, $4, $5);`
 
 	for _, trigger := range command.Triggers {
-		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name, trigger.Match)
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name,
+			trigger.Match, encodeStringSlice(trigger.Channels))
+		if err != nil {
+			if strings.Contains(err.Error(), "violates") {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) doBundleInsertCommandOptions(ctx context.Context,
+	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
+
+	query := `INSERT INTO bundle_command_options
+		(bundle_name, bundle_version, command_name, name, description, sensitive, permissions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+	for name, option := range command.Options {
+		if option == nil {
+			option = &data.BundleCommandOption{}
+		}
+
+		var permissions string
+		if len(option.Permissions) > 0 {
+			b, err := json.Marshal(option.Permissions)
+			if err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+			permissions = string(b)
+		}
+
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name, name,
+			option.Description, option.Sensitive, permissions)
+		if err != nil {
+			if strings.Contains(err.Error(), "violates") {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) doBundleInsertCommandProfiles(ctx context.Context,
+	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
+
+	query := `INSERT INTO bundle_command_profiles
+		(bundle_name, bundle_version, command_name, name, description, env,
+			cluster, env_secret, service_account_name, permission)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
+
+	for name, profile := range command.Profiles {
+		if profile == nil {
+			profile = &data.BundleCommandProfile{}
+		}
+
+		var env string
+		if len(profile.Env) > 0 {
+			b, err := json.Marshal(profile.Env)
+			if err != nil {
+				return gerr.Wrap(errs.ErrDataAccess, err)
+			}
+			env = string(b)
+		}
+
+		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name, name,
+			profile.Description, env, profile.Cluster, profile.EnvSecret, profile.ServiceAccountName, profile.Permission)
 		if err != nil {
 			if strings.Contains(err.Error(), "violates") {
 				err = gerr.Wrap(errs.ErrFieldRequired, err)
@@ -1083,12 +1566,12 @@ func (da PostgresDataAccess) doBundleInsertCommandTemplates(ctx context.Context,
 	tx *sql.Tx, bundle data.Bundle, command *data.BundleCommand) error {
 
 	query := `INSERT INTO bundle_command_templates
-		(bundle_name, bundle_version, command_name, command, command_error, message, message_error)
-		VALUES ($1, $2, $3, $4, $5, $6, $7);`
+		(bundle_name, bundle_version, command_name, command, command_error, command_timeout, digest, message, message_error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
 
 	_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version, command.Name,
-		command.Templates.Command, command.Templates.CommandError,
-		command.Templates.Message, command.Templates.MessageError)
+		command.Templates.Command, command.Templates.CommandError, command.Templates.CommandTimeout,
+		command.Templates.Digest, command.Templates.Message, command.Templates.MessageError)
 
 	if err != nil {
 		if strings.Contains(err.Error(), "violates") {
@@ -1105,8 +1588,11 @@ func (da PostgresDataAccess) doBundleInsertCommandTemplates(ctx context.Context,
 
 func (da PostgresDataAccess) doBundleInsertCommands(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
 	query := `INSERT INTO bundle_commands
-		(bundle_name, bundle_version, name, description, executable, long_description)
-		VALUES ($1, $2, $3, $4, $5, $6);`
+		(bundle_name, bundle_version, name, description, exclusive, executable, long_description,
+			platform_os, platform_arch, cooldown, ansi, default_profile,
+			ephemeral_output, ephemeral_ttl, secret_output,
+			reaction_success, reaction_failure, usage, examples, native_help, timeout)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21);`
 
 	for name, cmd := range bundle.Commands {
 		cmd.Name = name
@@ -1114,7 +1600,11 @@ func (da PostgresDataAccess) doBundleInsertCommands(ctx context.Context, tx *sql
 		enc := encodeStringSlice(cmd.Executable)
 
 		_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version,
-			cmd.Name, cmd.Description, enc, cmd.LongDescription)
+			cmd.Name, cmd.Description, cmd.Exclusive, enc, cmd.LongDescription,
+			cmd.Platform.OS, cmd.Platform.Arch, cmd.Cooldown, cmd.ANSI, cmd.DefaultProfile,
+			cmd.EphemeralOutput, cmd.EphemeralTTL, cmd.SecretOutput,
+			cmd.Reactions.Success, cmd.Reactions.Failure,
+			cmd.Usage, encodeStringSlice(cmd.Examples), cmd.NativeHelp, cmd.Timeout)
 
 		if err != nil {
 			if strings.Contains(err.Error(), "violates") {
@@ -1131,6 +1621,16 @@ func (da PostgresDataAccess) doBundleInsertCommands(ctx context.Context, tx *sql
 			return err
 		}
 
+		err = da.doBundleInsertCommandOptions(ctx, tx, bundle, cmd)
+		if err != nil {
+			return err
+		}
+
+		err = da.doBundleInsertCommandProfiles(ctx, tx, bundle, cmd)
+		if err != nil {
+			return err
+		}
+
 		err = da.doBundleInsertCommandRules(ctx, tx, bundle, cmd)
 		if err != nil {
 			return err
@@ -1168,12 +1668,12 @@ func (da PostgresDataAccess) doBundleInsertPermissions(ctx context.Context, tx *
 
 func (da PostgresDataAccess) doBundleInsertTemplates(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
 	query := `INSERT INTO bundle_templates
-		(bundle_name, bundle_version, command, command_error, message, message_error)
-		VALUES ($1, $2, $3, $4, $5, $6);`
+		(bundle_name, bundle_version, command, command_error, command_timeout, digest, message, message_error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
 
 	_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version,
-		bundle.Templates.Command, bundle.Templates.CommandError,
-		bundle.Templates.Message, bundle.Templates.MessageError)
+		bundle.Templates.Command, bundle.Templates.CommandError, bundle.Templates.CommandTimeout,
+		bundle.Templates.Digest, bundle.Templates.Message, bundle.Templates.MessageError)
 
 	if err != nil {
 		if strings.Contains(err.Error(), "violates") {
@@ -1190,11 +1690,12 @@ func (da PostgresDataAccess) doBundleInsertTemplates(ctx context.Context, tx *sq
 
 func (da PostgresDataAccess) doBundleInsertKubernetes(ctx context.Context, tx *sql.Tx, bundle data.Bundle) error {
 	query := `INSERT INTO bundle_kubernetes
-		(bundle_name, bundle_version, service_account_name, env_secret)
-		VALUES ($1, $2, $3, $4);`
+		(bundle_name, bundle_version, service_account_name, env_secret, cluster, namespace)
+		VALUES ($1, $2, $3, $4, $5, $6);`
 
 	_, err := tx.ExecContext(ctx, query, bundle.Name, bundle.Version,
-		bundle.Kubernetes.ServiceAccountName, bundle.Kubernetes.EnvSecret)
+		bundle.Kubernetes.ServiceAccountName, bundle.Kubernetes.EnvSecret, bundle.Kubernetes.Cluster,
+		bundle.Kubernetes.Namespace)
 
 	if err != nil {
 		if strings.Contains(err.Error(), "violates") {
@@ -1206,6 +1707,40 @@ func (da PostgresDataAccess) doBundleInsertKubernetes(ctx context.Context, tx *s
 		return err
 	}
 
+	query = `INSERT INTO bundle_kubernetes_node_selectors
+		(bundle_name, bundle_version, key, value)
+		VALUES ($1, $2, $3, $4);`
+
+	for key, value := range bundle.Kubernetes.NodeSelector {
+		_, err = tx.ExecContext(ctx, query, bundle.Name, bundle.Version, key, value)
+		if err != nil {
+			if strings.Contains(err.Error(), "violates") {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
+	query = `INSERT INTO bundle_kubernetes_service_accounts
+		(bundle_name, bundle_version, group_name, service_account_name)
+		VALUES ($1, $2, $3, $4);`
+
+	for group, sa := range bundle.Kubernetes.GroupServiceAccounts {
+		_, err = tx.ExecContext(ctx, query, bundle.Name, bundle.Version, group, sa)
+		if err != nil {
+			if strings.Contains(err.Error(), "violates") {
+				err = gerr.Wrap(errs.ErrFieldRequired, err)
+			} else {
+				err = gerr.Wrap(errs.ErrDataAccess, err)
+			}
+
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,238 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// ChannelPresenceDelete removes the record of a channel. It's not an error
+// if no record exists.
+func (da PostgresDataAccess) ChannelPresenceDelete(ctx context.Context, adapter, channelID string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.ChannelPresenceDelete")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM channel_presence WHERE adapter=$1 AND channel_id=$2;`
+	_, err = db.ExecContext(ctx, query, adapter, channelID)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// ChannelPresenceList returns the records of every channel belonging to the
+// named adapter, ordered by channel ID.
+func (da PostgresDataAccess) ChannelPresenceList(ctx context.Context, adapter string) ([]data.ChannelPresence, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.ChannelPresenceList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT adapter, channel_id, first_seen, greeted_at, last_command_at
+		FROM channel_presence
+		WHERE adapter=$1
+		ORDER BY channel_id`
+
+	rows, err := db.QueryContext(ctx, query, adapter)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.ChannelPresence{}
+
+	for rows.Next() {
+		var p data.ChannelPresence
+		var greeted, command sql.NullTime
+
+		err = rows.Scan(&p.Adapter, &p.ChannelID, &p.FirstSeen, &greeted, &command)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		p.GreetedAt = greeted.Time
+		p.LastCommandAt = command.Time
+		list = append(list, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// ChannelPresenceMarkActive records that a command was just run from a
+// channel, creating the channel's record if necessary.
+func (da PostgresDataAccess) ChannelPresenceMarkActive(ctx context.Context, adapter, channelID string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.ChannelPresenceMarkActive")
+	defer sp.End()
+
+	query := `INSERT INTO channel_presence (adapter, channel_id, first_seen, last_command_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (adapter, channel_id) DO UPDATE
+		SET last_command_at=EXCLUDED.last_command_at;`
+
+	return da.doChannelPresenceUpsert(ctx, query, adapter, channelID)
+}
+
+// ChannelPresenceMarkGreeted records that a channel was just greeted,
+// creating the channel's record if necessary.
+func (da PostgresDataAccess) ChannelPresenceMarkGreeted(ctx context.Context, adapter, channelID string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.ChannelPresenceMarkGreeted")
+	defer sp.End()
+
+	query := `INSERT INTO channel_presence (adapter, channel_id, first_seen, greeted_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (adapter, channel_id) DO UPDATE
+		SET greeted_at=EXCLUDED.greeted_at;`
+
+	return da.doChannelPresenceUpsert(ctx, query, adapter, channelID)
+}
+
+func (da PostgresDataAccess) doChannelPresenceUpsert(ctx context.Context, query, adapter, channelID string) error {
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, query, adapter, channelID, time.Now().UTC())
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// ChannelSettingsDelete removes a channel's settings. It's not an error if
+// the channel has none.
+func (da PostgresDataAccess) ChannelSettingsDelete(ctx context.Context, adapter, channelID string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.ChannelSettingsDelete")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM channel_settings WHERE adapter=$1 AND channel_id=$2;`
+	_, err = db.ExecContext(ctx, query, adapter, channelID)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// ChannelSettingsGet returns a channel's settings. If the channel has none,
+// zero-value settings are returned.
+func (da PostgresDataAccess) ChannelSettingsGet(ctx context.Context, adapter, channelID string) (data.ChannelSettings, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.ChannelSettingsGet")
+	defer sp.End()
+
+	settings := data.ChannelSettings{Adapter: adapter, ChannelID: channelID}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return settings, err
+	}
+
+	query := `SELECT default_bundle, quiet_hours
+		FROM channel_settings
+		WHERE adapter=$1 AND channel_id=$2`
+
+	var quietHours string
+
+	err = db.QueryRowContext(ctx, query, adapter, channelID).Scan(&settings.DefaultBundle, &quietHours)
+	switch {
+	case err == sql.ErrNoRows:
+		return settings, nil
+	case err != nil:
+		return settings, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if quietHours != "" {
+		settings.QuietHours = &data.QuietHours{}
+		if err := json.Unmarshal([]byte(quietHours), settings.QuietHours); err != nil {
+			return settings, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	return settings, nil
+}
+
+// ChannelSettingsSet creates or replaces a channel's settings.
+func (da PostgresDataAccess) ChannelSettingsSet(ctx context.Context, settings data.ChannelSettings) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.ChannelSettingsSet")
+	defer sp.End()
+
+	if settings.Adapter == "" || settings.ChannelID == "" {
+		return errs.ErrEmptyChannel
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	var quietHours string
+	if settings.QuietHours != nil {
+		b, err := json.Marshal(settings.QuietHours)
+		if err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		quietHours = string(b)
+	}
+
+	query := `INSERT INTO channel_settings (adapter, channel_id, default_bundle, quiet_hours)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (adapter, channel_id) DO UPDATE
+		SET default_bundle=EXCLUDED.default_bundle, quiet_hours=EXCLUDED.quiet_hours;`
+
+	_, err = db.ExecContext(ctx, query, settings.Adapter, settings.ChannelID, settings.DefaultBundle, quietHours)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// CostAdd adds a record's usage to the total for its month, bundle, and
+// group.
+func (da PostgresDataAccess) CostAdd(ctx context.Context, record data.CostRecord) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.CostAdd")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO costs
+		(month, bundle_name, group_name, executions, seconds, cpu_seconds,
+			memory_gib_seconds, cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (month, bundle_name, group_name) DO UPDATE SET
+			executions = costs.executions + EXCLUDED.executions,
+			seconds = costs.seconds + EXCLUDED.seconds,
+			cpu_seconds = costs.cpu_seconds + EXCLUDED.cpu_seconds,
+			memory_gib_seconds = costs.memory_gib_seconds + EXCLUDED.memory_gib_seconds,
+			cost = costs.cost + EXCLUDED.cost;`
+
+	_, err = db.ExecContext(ctx, query, record.Month, record.Bundle,
+		record.Group, record.Executions, record.Seconds, record.CPUSeconds,
+		record.MemoryGiBSeconds, record.Cost)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// CostList returns the cost totals for the given month, or for every month
+// if month is empty, ordered by month, bundle, and group.
+func (da PostgresDataAccess) CostList(ctx context.Context, month string) ([]data.CostRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.CostList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `SELECT month, bundle_name, group_name, executions, seconds,
+			cpu_seconds, memory_gib_seconds, cost
+		FROM costs
+		WHERE $1 = '' OR month = $1
+		ORDER BY month, bundle_name, group_name;`
+
+	rows, err := db.QueryContext(ctx, query, month)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.CostRecord{}
+	for rows.Next() {
+		var r data.CostRecord
+
+		err := rows.Scan(&r.Month, &r.Bundle, &r.Group, &r.Executions,
+			&r.Seconds, &r.CPUSeconds, &r.MemoryGiBSeconds, &r.Cost)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		list = append(list, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,236 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// DeadLetterCreate stores an undeliverable response, setting its ID.
+func (da PostgresDataAccess) DeadLetterCreate(ctx context.Context, letter *data.DeadLetter) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.DeadLetterCreate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO dead_letters
+		(request_id, adapter, channel_id, message, error, attempts, created, last_attempt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING dead_letter_id;`
+
+	err = db.QueryRowContext(ctx, query,
+		letter.RequestID, letter.Adapter, letter.ChannelID, letter.Message,
+		letter.Error, letter.Attempts, letter.Created, letter.LastAttempt).
+		Scan(&letter.ID)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// DeadLetterDelete deletes a dead letter.
+func (da PostgresDataAccess) DeadLetterDelete(ctx context.Context, id int64) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.DeadLetterDelete")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM dead_letters WHERE dead_letter_id=$1;`, id)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchDeadLetter
+	}
+
+	return nil
+}
+
+// DeadLetterGet returns a dead letter.
+func (da PostgresDataAccess) DeadLetterGet(ctx context.Context, id int64) (data.DeadLetter, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.DeadLetterGet")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.DeadLetter{}, err
+	}
+
+	const query = `SELECT dead_letter_id, request_id, adapter, channel_id,
+			message, error, attempts, created, last_attempt
+		FROM dead_letters
+		WHERE dead_letter_id=$1;`
+
+	var l data.DeadLetter
+
+	err = db.QueryRowContext(ctx, query, id).Scan(&l.ID, &l.RequestID,
+		&l.Adapter, &l.ChannelID, &l.Message, &l.Error, &l.Attempts,
+		&l.Created, &l.LastAttempt)
+	switch {
+	case err == sql.ErrNoRows:
+		return data.DeadLetter{}, errs.ErrNoSuchDeadLetter
+	case err != nil:
+		return data.DeadLetter{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return l, nil
+}
+
+// DeadLetterList returns all dead letters, oldest first.
+func (da PostgresDataAccess) DeadLetterList(ctx context.Context) ([]data.DeadLetter, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.DeadLetterList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `SELECT dead_letter_id, request_id, adapter, channel_id,
+			message, error, attempts, created, last_attempt
+		FROM dead_letters
+		ORDER BY dead_letter_id;`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.DeadLetter{}
+
+	for rows.Next() {
+		var l data.DeadLetter
+
+		err = rows.Scan(&l.ID, &l.RequestID, &l.Adapter, &l.ChannelID,
+			&l.Message, &l.Error, &l.Attempts, &l.Created, &l.LastAttempt)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		list = append(list, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// DeadLetterPurge deletes every dead letter created before the given time,
+// returning the number deleted.
+func (da PostgresDataAccess) DeadLetterPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.DeadLetterPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM dead_letters WHERE created < $1;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
+// DeadLetterUpdate records the outcome of a failed redelivery attempt:
+// the error, attempt count, and attempt time are updated.
+func (da PostgresDataAccess) DeadLetterUpdate(ctx context.Context, letter data.DeadLetter) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.DeadLetterUpdate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE dead_letters
+		SET error=$2, attempts=$3, last_attempt=$4
+		WHERE dead_letter_id=$1;`
+
+	result, err := db.ExecContext(ctx, query, letter.ID, letter.Error, letter.Attempts, letter.LastAttempt)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchDeadLetter
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) createDeadLettersTable(ctx context.Context, conn *sql.Conn) error {
+	createDeadLettersQuery := `CREATE TABLE dead_letters (
+		dead_letter_id	BIGSERIAL,
+		request_id		BIGINT NOT NULL,
+		adapter			TEXT NOT NULL,
+		channel_id		TEXT NOT NULL,
+		message			TEXT NOT NULL,
+		error			TEXT NOT NULL,
+		attempts		INT NOT NULL,
+		created			TIMESTAMP WITH TIME ZONE NOT NULL,
+		last_attempt	TIMESTAMP WITH TIME ZONE NOT NULL,
+		PRIMARY KEY		(dead_letter_id)
+	);`
+
+	_, err := conn.ExecContext(ctx, createDeadLettersQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
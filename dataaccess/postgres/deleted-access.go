@@ -0,0 +1,121 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// DeletedPurge permanently removes every user and group that was deleted
+// before the given time, returning the number removed.
+func (da PostgresDataAccess) DeletedPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.DeletedPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// Memberships and tokens have no cascade, so they go first. Group roles
+	// and adapter mappings are removed by cascade.
+	for _, query := range []string{
+		`DELETE FROM groupusers WHERE username IN (
+			SELECT username FROM users WHERE deleted_at < $1)
+			OR groupname IN (SELECT groupname FROM groups WHERE deleted_at < $1);`,
+		`DELETE FROM tokens WHERE username IN (
+			SELECT username FROM users WHERE deleted_at < $1);`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, before); err != nil {
+			tx.Rollback()
+			return 0, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	var count int64
+	for _, query := range []string{
+		`DELETE FROM users WHERE deleted_at < $1;`,
+		`DELETE FROM groups WHERE deleted_at < $1;`,
+	} {
+		res, err := tx.ExecContext(ctx, query, before)
+		if err != nil {
+			tx.Rollback()
+			return 0, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		count += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(count), nil
+}
+
+// doGroupPurgeDeleted permanently removes the named group if (and only if)
+// it has been soft-deleted.
+func (da PostgresDataAccess) doGroupPurgeDeleted(ctx context.Context, db *sql.DB, groupname string) error {
+	for _, query := range []string{
+		`DELETE FROM groupusers WHERE groupname IN (
+			SELECT groupname FROM groups WHERE groupname=$1 AND deleted_at IS NOT NULL);`,
+		`DELETE FROM groups WHERE groupname=$1 AND deleted_at IS NOT NULL;`,
+	} {
+		if _, err := db.ExecContext(ctx, query, groupname); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	return nil
+}
+
+// doUserPurgeDeleted permanently removes the named user if (and only if)
+// they have been soft-deleted.
+func (da PostgresDataAccess) doUserPurgeDeleted(ctx context.Context, db *sql.DB, username string) error {
+	for _, query := range []string{
+		`DELETE FROM groupusers WHERE username IN (
+			SELECT username FROM users WHERE username=$1 AND deleted_at IS NOT NULL);`,
+		`DELETE FROM tokens WHERE username IN (
+			SELECT username FROM users WHERE username=$1 AND deleted_at IS NOT NULL);`,
+		`DELETE FROM users WHERE username=$1 AND deleted_at IS NOT NULL;`,
+	} {
+		if _, err := db.ExecContext(ctx, query, username); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	return nil
+}
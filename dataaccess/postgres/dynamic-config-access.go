@@ -24,6 +24,7 @@ import (
 
 	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/getgort/gort/encryption"
 	gerr "github.com/getgort/gort/errors"
 	"github.com/getgort/gort/telemetry"
 )
@@ -43,16 +44,20 @@ func (da PostgresDataAccess) DynamicConfigurationCreate(ctx context.Context, dc
 		return errs.ErrConfigExists
 	}
 
-	conn, err := da.connect(ctx)
+	dc, err := encryption.SealConfiguration(dc)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `INSERT INTO configs
 		(bundle_name, layer, owner, key, secret, value)
 		VALUES ($1, $2, $3, $4, $5, $6);`
-	_, err = conn.ExecContext(ctx, query, dc.Bundle, dc.Layer, dc.Owner, dc.Key, dc.Secret, dc.Value)
+	_, err = db.ExecContext(ctx, query, dc.Bundle, dc.Layer, dc.Owner, dc.Key, dc.Secret, dc.Value)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -75,14 +80,13 @@ func (da PostgresDataAccess) DynamicConfigurationDelete(ctx context.Context, lay
 		return errs.ErrNoSuchConfig
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := "DELETE FROM configs WHERE bundle_name=$1 AND layer=$2 AND owner=$3 AND key=$4;"
-	_, err = conn.ExecContext(ctx, query, bundle, layer, owner, key)
+	_, err = db.ExecContext(ctx, query, bundle, layer, owner, key)
 	if err != nil {
 		err = gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -99,16 +103,15 @@ func (da PostgresDataAccess) DynamicConfigurationExists(ctx context.Context, lay
 		return false, err
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return false, err
 	}
-	defer conn.Close()
 
 	query := "SELECT EXISTS(SELECT 1 FROM configs WHERE bundle_name=$1 AND layer=$2 AND owner=$3 AND key=$4)"
 	exists := false
 
-	err = conn.QueryRowContext(ctx, query, bundle, layer, owner, key).Scan(&exists)
+	err = db.QueryRowContext(ctx, query, bundle, layer, owner, key).Scan(&exists)
 	if err != nil {
 		return false, gerr.Wrap(errs.ErrNoSuchGroup, err)
 	}
@@ -131,18 +134,17 @@ func (da PostgresDataAccess) DynamicConfigurationGet(ctx context.Context, layer
 		return data.DynamicConfiguration{}, errs.ErrNoSuchConfig
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return data.DynamicConfiguration{}, err
 	}
-	defer conn.Close()
 
 	query := `SELECT bundle_name, layer, owner, key, value, secret
 		FROM configs
 		WHERE bundle_name=$1 AND layer=$2 AND owner=$3 AND key=$4`
 	dc := data.DynamicConfiguration{}
 
-	err = conn.QueryRowContext(ctx, query, bundle, layer, owner, key).
+	err = db.QueryRowContext(ctx, query, bundle, layer, owner, key).
 		Scan(&dc.Bundle, &dc.Layer, &dc.Owner, &dc.Key, &dc.Value, &dc.Secret)
 
 	if err == sql.ErrNoRows {
@@ -151,6 +153,11 @@ func (da PostgresDataAccess) DynamicConfigurationGet(ctx context.Context, layer
 		return dc, gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
+	dc, err = encryption.OpenConfiguration(dc)
+	if err != nil {
+		return dc, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
 	return dc, nil
 }
 
@@ -174,17 +181,16 @@ func (da PostgresDataAccess) DynamicConfigurationList(ctx context.Context, layer
 
 	var dcs = make([]data.DynamicConfiguration, 0)
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
 	query := `SELECT bundle_name, layer, owner, key, value, secret
 		FROM configs
 		WHERE bundle_name LIKE $1 AND layer LIKE $2 AND owner LIKE $3 AND key LIKE $4`
 
-	rows, err := conn.QueryContext(ctx, query, bundle, layer, owner, key)
+	rows, err := db.QueryContext(ctx, query, bundle, layer, owner, key)
 	if err != nil {
 		return nil, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -197,6 +203,11 @@ func (da PostgresDataAccess) DynamicConfigurationList(ctx context.Context, layer
 			return nil, gerr.Wrap(errs.ErrNoSuchGroup, err)
 		}
 
+		dc, err = encryption.OpenConfiguration(dc)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
 		dcs = append(dcs, dc)
 	}
 
@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql"
 	"sort"
+	"time"
 
 	"go.opentelemetry.io/otel"
 
@@ -47,14 +48,19 @@ func (da PostgresDataAccess) GroupCreate(ctx context.Context, group rest.Group)
 		return errs.ErrGroupExists
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
+
+	// Creating a group permanently discards any deleted group of the same
+	// name.
+	if err := da.doGroupPurgeDeleted(ctx, db, group.Name); err != nil {
+		return err
+	}
 
 	query := `INSERT INTO groups (groupname) VALUES ($1);`
-	_, err = conn.ExecContext(ctx, query, group.Name)
+	_, err = db.ExecContext(ctx, query, group.Name)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -62,7 +68,8 @@ func (da PostgresDataAccess) GroupCreate(ctx context.Context, group rest.Group)
 	return err
 }
 
-// GroupDelete deletes a group.
+// GroupDelete soft-deletes a group: the group is hidden, but it may be
+// restored (along with its members and roles) using GroupRestore.
 func (da PostgresDataAccess) GroupDelete(ctx context.Context, groupname string) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.GroupDelete")
@@ -85,20 +92,15 @@ func (da PostgresDataAccess) GroupDelete(ctx context.Context, groupname string)
 		return errs.ErrNoSuchGroup
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-
-	query := `DELETE FROM groupusers WHERE groupname=$1;`
-	_, err = conn.ExecContext(ctx, query, groupname)
-	if err != nil {
-		return gerr.Wrap(errs.ErrDataAccess, err)
-	}
 
-	query = `DELETE FROM groups WHERE groupname=$1;`
-	_, err = conn.ExecContext(ctx, query, groupname)
+	// The group's memberships and roles are retained so that it can be
+	// restored.
+	query := `UPDATE groups SET deleted_at=now() WHERE groupname=$1;`
+	_, err = db.ExecContext(ctx, query, groupname)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -112,16 +114,15 @@ func (da PostgresDataAccess) GroupExists(ctx context.Context, groupname string)
 	ctx, sp := tr.Start(ctx, "postgres.GroupExists")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return false, err
 	}
-	defer conn.Close()
 
-	query := "SELECT EXISTS(SELECT 1 FROM groups WHERE groupname=$1)"
+	query := "SELECT EXISTS(SELECT 1 FROM groups WHERE groupname=$1 AND deleted_at IS NULL)"
 	exists := false
 
-	err = conn.QueryRowContext(ctx, query, groupname).Scan(&exists)
+	err = db.QueryRowContext(ctx, query, groupname).Scan(&exists)
 	if err != nil {
 		return false, gerr.Wrap(errs.ErrNoSuchGroup, err)
 	}
@@ -139,19 +140,18 @@ func (da PostgresDataAccess) GroupGet(ctx context.Context, groupname string) (re
 		return rest.Group{}, errs.ErrEmptyGroupName
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return rest.Group{}, err
 	}
-	defer conn.Close()
 
 	// There will be more fields here eventually
 	query := `SELECT groupname
 		FROM groups
-		WHERE groupname=$1`
+		WHERE groupname=$1 AND deleted_at IS NULL`
 
 	group := rest.Group{}
-	err = conn.QueryRowContext(ctx, query, groupname).Scan(&group.Name)
+	err = db.QueryRowContext(ctx, query, groupname).Scan(&group.Name)
 	if err == sql.ErrNoRows {
 		return group, errs.ErrNoSuchGroup
 	} else if err != nil {
@@ -177,14 +177,13 @@ func (da PostgresDataAccess) GroupList(ctx context.Context) ([]rest.Group, error
 
 	groups := make([]rest.Group, 0)
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return groups, err
 	}
-	defer conn.Close()
 
-	query := `SELECT groupname FROM groups`
-	rows, err := conn.QueryContext(ctx, query)
+	query := `SELECT groupname FROM groups WHERE deleted_at IS NULL`
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return groups, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -241,6 +240,41 @@ func (da PostgresDataAccess) GroupPermissionList(ctx context.Context, groupname
 	return pp, nil
 }
 
+// GroupRestore restores a group that was deleted at or after since. An
+// error is returned if the groupname parameter is empty, or if no such group
+// was deleted in that time.
+func (da PostgresDataAccess) GroupRestore(ctx context.Context, groupname string, since time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.GroupRestore")
+	defer sp.End()
+
+	if groupname == "" {
+		return errs.ErrEmptyGroupName
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE groups SET deleted_at=NULL
+		WHERE groupname=$1 AND deleted_at IS NOT NULL AND deleted_at >= $2;`
+	res, err := db.ExecContext(ctx, query, groupname, since)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if n == 0 {
+		return errs.ErrNoSuchGroup
+	}
+
+	return nil
+}
+
 // GroupRoleAdd grants one or more roles to a group.
 func (da PostgresDataAccess) GroupRoleAdd(ctx context.Context, groupname, rolename string) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
@@ -267,15 +301,14 @@ func (da PostgresDataAccess) GroupRoleAdd(ctx context.Context, groupname, rolena
 		return errs.ErrNoSuchRole
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `INSERT INTO group_roles (group_name, role_name)
 		VALUES ($1, $2);`
-	_, err = conn.ExecContext(ctx, query, groupname, rolename)
+	_, err = db.ExecContext(ctx, query, groupname, rolename)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -297,15 +330,14 @@ func (da PostgresDataAccess) GroupRoleDelete(ctx context.Context, groupname, rol
 		return errs.ErrEmptyRoleName
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `DELETE FROM group_roles
 		WHERE group_name=$1 AND role_name=$2;`
-	_, err = conn.ExecContext(ctx, query, groupname, rolename)
+	_, err = db.ExecContext(ctx, query, groupname, rolename)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -330,18 +362,17 @@ func (da PostgresDataAccess) GroupRoleList(ctx context.Context, groupname string
 		return nil, errs.ErrNoSuchGroup
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
 	query := `SELECT role_name
 		FROM group_roles
 		WHERE group_name = $1
 		ORDER BY role_name`
 
-	rows, err := conn.QueryContext(ctx, query, groupname)
+	rows, err := db.QueryContext(ctx, query, groupname)
 	if err != nil {
 		return nil, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -387,18 +418,17 @@ func (da PostgresDataAccess) GroupUpdate(ctx context.Context, group rest.Group)
 		return errs.ErrNoSuchGroup
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	// There will be more eventually
 	query := `UPDATE groupname
 	SET groupname=$1
 	WHERE groupname=$1;`
 
-	_, err = conn.ExecContext(ctx, query, group.Name)
+	_, err = db.ExecContext(ctx, query, group.Name)
 	if err != nil {
 		err = gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -436,14 +466,13 @@ func (da PostgresDataAccess) GroupUserAdd(ctx context.Context, groupname string,
 		return errs.ErrNoSuchUser
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `INSERT INTO groupusers (groupname, username) VALUES ($1, $2);`
-	_, err = conn.ExecContext(ctx, query, groupname, username)
+	_, err = db.ExecContext(ctx, query, groupname, username)
 	if err != nil {
 		err = gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -469,14 +498,13 @@ func (da PostgresDataAccess) GroupUserDelete(ctx context.Context, groupname stri
 		return errs.ErrNoSuchGroup
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := "DELETE FROM groupusers WHERE groupname=$1 AND username=$2;"
-	_, err = conn.ExecContext(ctx, query, groupname, username)
+	_, err = db.ExecContext(ctx, query, groupname, username)
 	if err != nil {
 		err = gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -504,21 +532,20 @@ func (da PostgresDataAccess) GroupUserList(ctx context.Context, groupname string
 		return users, errs.ErrNoSuchGroup
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return users, err
 	}
-	defer conn.Close()
 
-	query := `SELECT email, full_name, username
+	query := `SELECT email, full_name, username, service_account
 	FROM users
-	WHERE username IN (
+	WHERE deleted_at IS NULL AND username IN (
 		SELECT username
 		FROM groupusers
 		WHERE groupname = $1
 	)`
 
-	rows, err := conn.QueryContext(ctx, query, groupname)
+	rows, err := db.QueryContext(ctx, query, groupname)
 	if err != nil {
 		return users, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -526,7 +553,7 @@ func (da PostgresDataAccess) GroupUserList(ctx context.Context, groupname string
 	for rows.Next() {
 		user := rest.User{}
 
-		err = rows.Scan(&user.Email, &user.FullName, &user.Username)
+		err = rows.Scan(&user.Email, &user.FullName, &user.Username, &user.ServiceAccount)
 		if err != nil {
 			return users, gerr.Wrap(errs.ErrNoSuchUser, err)
 		}
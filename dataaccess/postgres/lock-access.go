@@ -0,0 +1,196 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// LockAcquire attempts to acquire the named lock on behalf of owner for the
+// duration of ttl. If the lock is held by a different owner and hasn't yet
+// expired, the current lock is returned along with errs.ErrLockHeld.
+func (da PostgresDataAccess) LockAcquire(ctx context.Context, name, owner, username string, ttl time.Duration) (data.Lock, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.LockAcquire")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.Lock{}, err
+	}
+
+	now := time.Now().UTC()
+	lock := data.Lock{
+		Name:       name,
+		Owner:      owner,
+		UserName:   username,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+
+	// The upsert only overwrites an existing lock if it's expired or already
+	// belongs to this owner, so the whole thing is atomic.
+	query := `INSERT INTO locks (name, owner, username, acquired_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO UPDATE
+		SET owner=EXCLUDED.owner, username=EXCLUDED.username,
+			acquired_at=EXCLUDED.acquired_at, expires_at=EXCLUDED.expires_at
+		WHERE locks.expires_at < EXCLUDED.acquired_at OR locks.owner=EXCLUDED.owner;`
+
+	result, err := db.ExecContext(ctx, query, lock.Name, lock.Owner, lock.UserName, lock.AcquiredAt, lock.ExpiresAt)
+	if err != nil {
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows > 0 {
+		return lock, nil
+	}
+
+	held, err := da.LockGet(ctx, name)
+	if err != nil {
+		return data.Lock{}, err
+	}
+
+	return held, errs.ErrLockHeld
+}
+
+// LockGet returns the named lock. An error is returned if the lock doesn't
+// exist or has expired.
+func (da PostgresDataAccess) LockGet(ctx context.Context, name string) (data.Lock, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.LockGet")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.Lock{}, err
+	}
+
+	query := `SELECT name, owner, username, acquired_at, expires_at
+		FROM locks
+		WHERE name=$1 AND expires_at >= $2`
+
+	lock := data.Lock{}
+	err = db.
+		QueryRowContext(ctx, query, name, time.Now().UTC()).
+		Scan(&lock.Name, &lock.Owner, &lock.UserName, &lock.AcquiredAt, &lock.ExpiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return data.Lock{}, errs.ErrNoSuchLock
+	case err != nil:
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return lock, nil
+}
+
+// LockPurge deletes every lock that expired before the given time,
+// returning the number deleted.
+func (da PostgresDataAccess) LockPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.LockPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM locks WHERE expires_at < $1;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
+// LockRelease releases the named lock. An error is returned if the lock
+// isn't held by owner.
+func (da PostgresDataAccess) LockRelease(ctx context.Context, name, owner string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.LockRelease")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM locks WHERE name=$1 AND owner=$2;`
+	result, err := db.ExecContext(ctx, query, name, owner)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	} else if rows == 0 {
+		return errs.ErrNoSuchLock
+	}
+
+	return nil
+}
+
+// LockRenew extends the expiry of the named lock to ttl from now. An error
+// is returned if the lock isn't held by owner.
+func (da PostgresDataAccess) LockRenew(ctx context.Context, name, owner string, ttl time.Duration) (data.Lock, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.LockRenew")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.Lock{}, err
+	}
+
+	query := `UPDATE locks SET expires_at=$3
+		WHERE name=$1 AND owner=$2
+		RETURNING name, owner, username, acquired_at, expires_at;`
+
+	lock := data.Lock{}
+	err = db.
+		QueryRowContext(ctx, query, name, owner, time.Now().UTC().Add(ttl)).
+		Scan(&lock.Name, &lock.Owner, &lock.UserName, &lock.AcquiredAt, &lock.ExpiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return data.Lock{}, errs.ErrNoSuchLock
+	case err != nil:
+		return data.Lock{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return lock, nil
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// MacroDelete removes a macro.
+func (da PostgresDataAccess) MacroDelete(ctx context.Context, layer data.ConfigurationLayer, owner, name string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.MacroDelete")
+	defer sp.End()
+
+	m := data.Macro{Layer: layer, Owner: owner, Name: name}
+	if err := normalizeMacro(&m); err != nil {
+		return err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM macros WHERE layer=$1 AND owner=$2 AND name=$3;`
+
+	result, err := db.ExecContext(ctx, query, m.Layer, m.Owner, m.Name)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchMacro
+	}
+
+	return nil
+}
+
+// MacroList returns all macros, at every layer, with the given name. If name
+// is empty, all macros are returned. The results are sorted by name, layer,
+// and owner.
+func (da PostgresDataAccess) MacroList(ctx context.Context, name string) ([]data.Macro, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.MacroList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT name, layer, owner, command
+		FROM macros
+		WHERE $1='' OR name=$1
+		ORDER BY name, layer, owner;`
+
+	rows, err := db.QueryContext(ctx, query, name)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.Macro{}
+	for rows.Next() {
+		var m data.Macro
+
+		err = rows.Scan(&m.Name, &m.Layer, &m.Owner, &m.Command)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		list = append(list, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// MacroSave creates or replaces a macro.
+func (da PostgresDataAccess) MacroSave(ctx context.Context, macro data.Macro) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.MacroSave")
+	defer sp.End()
+
+	if err := normalizeMacro(&macro); err != nil {
+		return err
+	}
+
+	if err := macro.Validate(); err != nil {
+		return err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO macros (layer, owner, name, command)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (layer, owner, name) DO UPDATE
+		SET command=EXCLUDED.command;`
+
+	_, err = db.ExecContext(ctx, query, macro.Layer, macro.Owner, macro.Name, macro.Command)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// normalizeMacro validates m's layer, owner, and name, and normalizes its
+// layer.
+func normalizeMacro(m *data.Macro) error {
+	if err := data.ValidateMacroLayer(m.Layer); err != nil {
+		return err
+	}
+
+	m.Layer = data.ConfigurationLayer(strings.ToLower(string(m.Layer)))
+
+	switch {
+	case m.Name == "":
+		return errs.ErrEmptyMacroName
+	case m.Owner == "":
+		return errs.ErrEmptyMacroOwner
+	}
+
+	return nil
+}
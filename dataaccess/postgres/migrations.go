@@ -0,0 +1,369 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+)
+
+// A migration is a versioned schema change. Migrations are applied in order,
+// each in its own transaction, after the base tables have been created, and
+// are recorded in the schema_migrations table so that each is applied
+// exactly once.
+//
+// Migrations must never be edited or reordered once released. Not every
+// schema change is a migration, though: many columns are instead added by the
+// table creation functions with ADD COLUMN IF NOT EXISTS, which is safe to
+// repeat on every start.
+type migration struct {
+	Version     int
+	Description string
+	Migrate     func(ctx context.Context, tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{1, "cascading foreign keys", migrateCascadingKeys},
+	{2, "request replay links", migrateRequestReplays},
+	{3, "secret outputs", migrateSecretOutputs},
+	{4, "cost accounting", migrateCosts},
+	{5, "request history index", migrateRequestHistory},
+	{6, "trigger channel restrictions", migrateTriggerChannels},
+	{7, "channel quiet hours", migrateChannelQuietHours},
+	{8, "digest templates", migrateDigestTemplates},
+	{9, "revoked tokens", migrateRevokedTokens},
+	{10, "bundle job namespaces", migrateBundleNamespaces},
+}
+
+// runMigrations applies any migrations that haven't yet been applied to the
+// Gort database.
+func (da PostgresDataAccess) runMigrations(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version			INT NOT NULL,
+		description		TEXT NOT NULL,
+		applied_at		TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+		PRIMARY KEY		(version)
+	);`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	for _, m := range migrations {
+		if err := da.runMigration(ctx, conn, m); err != nil {
+			return gerr.Wrap(fmt.Errorf("migration %d (%s) failed", m.Version, m.Description), err)
+		}
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) runMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// Serialize controllers starting at the same time, so that only one of
+	// them applies each migration.
+	_, err = tx.ExecContext(ctx, `LOCK TABLE schema_migrations IN EXCLUSIVE MODE;`)
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	applied := false
+	query := `SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version=$1)`
+	if err := tx.QueryRowContext(ctx, query, m.Version).Scan(&applied); err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if applied {
+		return tx.Rollback()
+	}
+
+	if err := m.Migrate(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query = `INSERT INTO schema_migrations (version, description) VALUES ($1, $2);`
+	if _, err := tx.ExecContext(ctx, query, m.Version, m.Description); err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	log.WithField("version", m.Version).
+		WithField("description", m.Description).
+		Info("Applied database migration")
+
+	return nil
+}
+
+// foreignKey describes a foreign key constraint for replaceForeignKey.
+type foreignKey struct {
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string // "CASCADE", or empty for NO ACTION
+}
+
+// replaceForeignKey drops every existing foreign key constraint from the
+// key's table to its referenced table (their names were generated by
+// Postgres, so they're looked up) and adds the key in their place, with
+// updates to the referenced columns cascading.
+func replaceForeignKey(ctx context.Context, tx *sql.Tx, fk foreignKey) error {
+	query := `SELECT conname FROM pg_constraint
+		WHERE contype = 'f' AND conrelid = $1::regclass AND confrelid = $2::regclass`
+
+	rows, err := tx.QueryContext(ctx, query, fk.Table, fk.RefTable)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	for _, name := range names {
+		query := fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT %q;`, fk.Table, name)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	onDelete := ""
+	if fk.OnDelete != "" {
+		onDelete = "ON DELETE " + fk.OnDelete
+	}
+
+	query = fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT fk_%s_%s
+		FOREIGN KEY (%s) REFERENCES %s(%s) %s ON UPDATE CASCADE;`,
+		fk.Table, fk.Table, fk.RefTable,
+		strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "),
+		onDelete)
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateCascadingKeys rebuilds the foreign keys that refer to users, groups,
+// roles, bundles, and bundle commands so that changes to their natural keys
+// cascade. This lets a rename be a single UPDATE rather than a
+// hand-maintained list of them. It also adds the missing key from
+// group_roles to roles, first discarding any grants of roles that no longer
+// exist.
+func migrateCascadingKeys(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	DELETE FROM group_roles WHERE role_name NOT IN (SELECT role_name FROM roles);
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	bundleCols := []string{"bundle_name", "bundle_version"}
+	commandCols := []string{"bundle_name", "bundle_version", "command_name"}
+
+	keys := []foreignKey{
+		{"groupusers", []string{"groupname"}, "groups", []string{"groupname"}, ""},
+		{"groupusers", []string{"username"}, "users", []string{"username"}, ""},
+		{"tokens", []string{"username"}, "users", []string{"username"}, ""},
+		{"user_adapter_ids", []string{"username"}, "users", []string{"username"}, "CASCADE"},
+		{"group_roles", []string{"group_name"}, "groups", []string{"groupname"}, "CASCADE"},
+		{"group_roles", []string{"role_name"}, "roles", []string{"role_name"}, "CASCADE"},
+		{"role_permissions", []string{"role_name"}, "roles", []string{"role_name"}, "CASCADE"},
+		{"bundle_enabled", bundleCols, "bundles", []string{"name", "version"}, "CASCADE"},
+		{"bundle_kubernetes_node_selectors", bundleCols, "bundles", []string{"name", "version"}, "CASCADE"},
+		{"bundle_permissions", bundleCols, "bundles", []string{"name", "version"}, "CASCADE"},
+		{"bundle_templates", bundleCols, "bundles", []string{"name", "version"}, "CASCADE"},
+		{"bundle_commands", bundleCols, "bundles", []string{"name", "version"}, "CASCADE"},
+		{"bundle_command_triggers", commandCols, "bundle_commands", []string{"bundle_name", "bundle_version", "name"}, "CASCADE"},
+		{"bundle_command_rules", commandCols, "bundle_commands", []string{"bundle_name", "bundle_version", "name"}, "CASCADE"},
+		{"bundle_command_templates", commandCols, "bundle_commands", []string{"bundle_name", "bundle_version", "name"}, "CASCADE"},
+		{"bundle_command_options", commandCols, "bundle_commands", []string{"bundle_name", "bundle_version", "name"}, "CASCADE"},
+	}
+
+	for _, fk := range keys {
+		if err := replaceForeignKey(ctx, tx, fk); err != nil {
+			return gerr.Wrap(fmt.Errorf("failed to replace foreign key %s -> %s", fk.Table, fk.RefTable), err)
+		}
+	}
+
+	return nil
+}
+
+// migrateRequestReplays adds a column to the commands table that links a
+// replayed request to the original request that it replays.
+func migrateRequestReplays(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	ALTER TABLE commands ADD COLUMN IF NOT EXISTS replay_of BIGINT NOT NULL DEFAULT 0;
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func migrateSecretOutputs(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS secret_outputs (
+		token_hash		TEXT NOT NULL,
+		request_id		BIGINT NOT NULL,
+		username		TEXT NOT NULL,
+		title			TEXT NOT NULL,
+		output			TEXT NOT NULL,
+		created			TIMESTAMP WITH TIME ZONE NOT NULL,
+		expires			TIMESTAMP WITH TIME ZONE NOT NULL,
+		PRIMARY KEY		(token_hash)
+	);
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func migrateCosts(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS costs (
+		month				TEXT NOT NULL,
+		bundle_name			TEXT NOT NULL,
+		group_name			TEXT NOT NULL,
+		executions			BIGINT NOT NULL,
+		seconds				DOUBLE PRECISION NOT NULL,
+		cpu_seconds			DOUBLE PRECISION NOT NULL,
+		memory_gib_seconds	DOUBLE PRECISION NOT NULL,
+		cost				DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY			(month, bundle_name, group_name)
+	);
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateRequestHistory indexes the commands table by requestor, so that a
+// user's recent commands in a channel can be found quickly.
+func migrateRequestHistory(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE INDEX IF NOT EXISTS commands_requestor_idx
+		ON commands (adapter, channel_id, user_id, request_id DESC);
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateTriggerChannels adds a column to the bundle_command_triggers table
+// that restricts a trigger to a set of channels.
+func migrateTriggerChannels(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	ALTER TABLE bundle_command_triggers ADD COLUMN IF NOT EXISTS channels TEXT NOT NULL DEFAULT '';
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateChannelQuietHours adds a column to the channel_settings table that
+// holds a channel's quiet hours, encoded as JSON.
+func migrateChannelQuietHours(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	ALTER TABLE channel_settings ADD COLUMN IF NOT EXISTS quiet_hours TEXT NOT NULL DEFAULT '';
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateDigestTemplates adds a column for the digest template to the
+// bundle and bundle command template tables.
+func migrateDigestTemplates(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	ALTER TABLE bundle_templates ADD COLUMN IF NOT EXISTS digest TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_command_templates ADD COLUMN IF NOT EXISTS digest TEXT NOT NULL DEFAULT '';
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateRevokedTokens adds the revocation list of self-contained API tokens.
+// Each entry is kept until the token it revokes would have expired.
+func migrateRevokedTokens(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS revoked_tokens (
+		token_id		TEXT NOT NULL,
+		valid_until		TIMESTAMP WITH TIME ZONE NOT NULL,
+		PRIMARY KEY		(token_id)
+	);
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// migrateBundleNamespaces adds a column for the namespace that a bundle's
+// Kubernetes jobs run in to the bundle_kubernetes table.
+func migrateBundleNamespaces(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	ALTER TABLE bundle_kubernetes ADD COLUMN IF NOT EXISTS namespace TEXT NOT NULL DEFAULT '';
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
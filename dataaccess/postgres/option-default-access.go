@@ -0,0 +1,161 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// OptionDefaultDelete removes an option default.
+func (da PostgresDataAccess) OptionDefaultDelete(ctx context.Context, layer data.ConfigurationLayer, owner, bundle, command, option string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.OptionDefaultDelete")
+	defer sp.End()
+
+	def := data.OptionDefault{Layer: layer, Owner: owner, Bundle: bundle, Command: command, Option: option}
+	if err := normalizeOptionDefault(&def); err != nil {
+		return err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM option_defaults
+		WHERE layer=$1 AND owner=$2 AND bundle_name=$3 AND command_name=$4 AND option=$5;`
+
+	result, err := db.ExecContext(ctx, query, def.Layer, def.Owner, def.Bundle, def.Command, def.Option)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if rows == 0 {
+		return errs.ErrNoSuchOptionDefault
+	}
+
+	return nil
+}
+
+// OptionDefaultList returns all option defaults, at every layer, for a
+// command. The results are sorted by layer, owner, and option.
+func (da PostgresDataAccess) OptionDefaultList(ctx context.Context, bundle, command string) ([]data.OptionDefault, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.OptionDefaultList")
+	defer sp.End()
+
+	if bundle == "" || command == "" {
+		return nil, errs.ErrEmptyOptionDefaultCommand
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT bundle_name, command_name, layer, owner, option, value
+		FROM option_defaults
+		WHERE bundle_name=$1 AND command_name=$2
+		ORDER BY layer, owner, option;`
+
+	rows, err := db.QueryContext(ctx, query, bundle, command)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.OptionDefault{}
+	for rows.Next() {
+		var d data.OptionDefault
+
+		err = rows.Scan(&d.Bundle, &d.Command, &d.Layer, &d.Owner, &d.Option, &d.Value)
+		if err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		list = append(list, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// OptionDefaultSet creates or replaces an option default.
+func (da PostgresDataAccess) OptionDefaultSet(ctx context.Context, def data.OptionDefault) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.OptionDefaultSet")
+	defer sp.End()
+
+	if err := normalizeOptionDefault(&def); err != nil {
+		return err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO option_defaults (bundle_name, command_name, layer, owner, option, value)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (layer, owner, bundle_name, command_name, option) DO UPDATE
+		SET value=EXCLUDED.value;`
+
+	_, err = db.ExecContext(ctx, query, def.Bundle, def.Command, def.Layer, def.Owner, def.Option, def.Value)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// normalizeOptionDefault validates def and normalizes its layer and owner.
+func normalizeOptionDefault(def *data.OptionDefault) error {
+	if err := data.ValidateOptionDefaultLayer(def.Layer); err != nil {
+		return err
+	}
+
+	def.Layer = data.ConfigurationLayer(strings.ToLower(string(def.Layer)))
+	if def.Layer == data.LayerBundle {
+		def.Owner = ""
+	}
+
+	switch {
+	case def.Bundle == "" || def.Command == "":
+		return errs.ErrEmptyOptionDefaultCommand
+	case def.Option == "":
+		return errs.ErrEmptyOptionDefaultOption
+	case def.Owner == "" && def.Layer != data.LayerBundle:
+		return errs.ErrEmptyOptionDefaultOwner
+	}
+
+	return nil
+}
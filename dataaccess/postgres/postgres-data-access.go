@@ -21,6 +21,7 @@ import (
 	"database/sql"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/dataaccess/errs"
@@ -36,6 +37,17 @@ const (
 	DriverName   = "pgx"
 )
 
+// connections is the number of times that pool has been called.
+var connections uint64
+
+// ConnectionCount returns the number of times that the Gort database's
+// connection pool has been used by all PostgresDataAccess values since the
+// process started. Every data access call uses it once, so this
+// approximates the number of database round-trips.
+func ConnectionCount() uint64 {
+	return atomic.LoadUint64(&connections)
+}
+
 // PostgresDataAccess is a data access implementation backed by a database.
 type PostgresDataAccess struct {
 	configs data.DatabaseConfigs
@@ -97,6 +109,72 @@ func (da PostgresDataAccess) initializeAuditData(ctx context.Context) error {
 		}
 	}
 
+	// Check whether the request payloads table exists
+	exists, err = da.tableExists(ctx, "request_payloads", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createRequestPayloadsTable(ctx, conn)
+		if err != nil {
+			return gerr.Wrap(fmt.Errorf("failed to create request payloads table"), err)
+		}
+	}
+
+	// Check whether the request logs table exists
+	exists, err = da.tableExists(ctx, "request_logs", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createRequestLogsTable(ctx, conn)
+		if err != nil {
+			return gerr.Wrap(fmt.Errorf("failed to create request logs table"), err)
+		}
+	}
+
+	// Check whether the REST audit table exists
+	exists, err = da.tableExists(ctx, "rest_audit", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createRestAuditTable(ctx, conn)
+		if err != nil {
+			return gerr.Wrap(fmt.Errorf("failed to create rest audit table"), err)
+		}
+	}
+
+	// Check whether the dead letters table exists
+	exists, err = da.tableExists(ctx, "dead_letters", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createDeadLettersTable(ctx, conn)
+		if err != nil {
+			return gerr.Wrap(fmt.Errorf("failed to create dead letters table"), err)
+		}
+	}
+
+	// Check whether the outbox table exists
+	exists, err = da.tableExists(ctx, "outbox", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createOutboxTable(ctx, conn)
+		if err != nil {
+			return gerr.Wrap(fmt.Errorf("failed to create outbox table"), err)
+		}
+	}
+
+	// Columns added after the commands table was first introduced.
+	_, err = conn.ExecContext(ctx, `ALTER TABLE commands ADD COLUMN IF NOT EXISTS timings TEXT NOT NULL DEFAULT '';`)
+	if err != nil {
+		return gerr.Wrap(fmt.Errorf("failed to update commands table"), gerr.Wrap(errs.ErrDataAccess, err))
+	}
+
 	return nil
 }
 
@@ -172,6 +250,16 @@ func (da PostgresDataAccess) initializeGortData(ctx context.Context) error {
 		}
 	}
 
+	// Columns added after the users and groups tables were first introduced.
+	_, err = conn.ExecContext(ctx, `
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS service_account BOOLEAN NOT NULL DEFAULT false;
+	ALTER TABLE groups ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+	`)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
 	// Upsert bundles tables to make sure it and related tables exist with appropriate columns
 	err = da.createBundlesTables(ctx, conn)
 	if err != nil {
@@ -190,6 +278,12 @@ func (da PostgresDataAccess) initializeGortData(ctx context.Context) error {
 		}
 	}
 
+	// Columns added after the bundle_kubernetes table was first introduced.
+	_, err = conn.ExecContext(ctx, `ALTER TABLE bundle_kubernetes ADD COLUMN IF NOT EXISTS cluster TEXT NOT NULL DEFAULT '';`)
+	if err != nil {
+		return gerr.Wrap(fmt.Errorf("failed to update bundle_kubernetes table"), gerr.Wrap(errs.ErrDataAccess, err))
+	}
+
 	// Check whether the roles table exists
 	exists, err = da.tableExists(ctx, "roles", conn)
 	if err != nil {
@@ -214,21 +308,108 @@ func (da PostgresDataAccess) initializeGortData(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	// Check whether the channel presence table exists
+	exists, err = da.tableExists(ctx, "channel_presence", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createChannelPresenceTable(ctx, conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check whether the channel settings table exists
+	exists, err = da.tableExists(ctx, "channel_settings", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createChannelSettingsTable(ctx, conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check whether the locks table exists
+	exists, err = da.tableExists(ctx, "locks", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createLocksTable(ctx, conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check whether the macros table exists
+	exists, err = da.tableExists(ctx, "macros", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createMacrosTable(ctx, conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check whether the aliases table exists
+	exists, err = da.tableExists(ctx, "aliases", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createAliasesTable(ctx, conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check whether the option defaults table exists
+	exists, err = da.tableExists(ctx, "option_defaults", conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = da.createOptionDefaultsTable(ctx, conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Apply any schema changes made since the tables were created
+	return da.runMigrations(ctx, conn)
 }
 
-func (da PostgresDataAccess) connect(ctx context.Context) (*sql.Conn, error) {
+// pool returns the connection pool of the Gort database, which is opened
+// the first time it's needed and held for the life of the data access
+// layer. Data access calls run their statements directly against the pool,
+// so a connection is only held for as long as each statement or transaction
+// needs it, and is then returned to the pool for reuse.
+func (da PostgresDataAccess) pool(ctx context.Context) (*sql.DB, error) {
 	db, err := da.open(ctx, DatabaseGort)
 	if err != nil {
 		return nil, gerr.WrapStr("failed to open Gort database", err)
 	}
 
-	conn, err := db.Conn(ctx)
+	atomic.AddUint64(&connections, 1)
+
+	return db, nil
+}
+
+// connect acquires a single connection from the Gort database's pool, for
+// work that has to happen in one session, like schema setup and migrations.
+// It must be closed to return it to the pool.
+func (da PostgresDataAccess) connect(ctx context.Context) (*sql.Conn, error) {
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return conn, nil
+	return db.Conn(ctx)
 }
 
 func (da PostgresDataAccess) createBundlesTables(ctx context.Context, conn *sql.Conn) error {
@@ -251,6 +432,20 @@ func (da PostgresDataAccess) createBundlesTables(ctx context.Context, conn *sql.
 	);
 
 	ALTER TABLE bundles ALTER COLUMN install_timestamp SET DEFAULT now();
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS platform_os TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS platform_arch TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS output_filters TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS serverless_function TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS serverless_job TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS ssh TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS telemetry_attributes TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS resource_cpu TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS resource_memory TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS review_status TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS review_user TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS review_timestamp TIMESTAMP WITH TIME ZONE;
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS review_comment TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundles ADD COLUMN IF NOT EXISTS owners TEXT NOT NULL DEFAULT '';
 
 	CREATE TABLE IF NOT EXISTS bundle_enabled (
 		bundle_name			TEXT NOT NULL,
@@ -261,6 +456,26 @@ func (da PostgresDataAccess) createBundlesTables(ctx context.Context, conn *sql.
 		ON DELETE CASCADE
 	);
 
+	CREATE TABLE IF NOT EXISTS bundle_kubernetes_node_selectors (
+		bundle_name			TEXT NOT NULL,
+		bundle_version		TEXT NOT NULL,
+		key					TEXT NOT NULL CHECK(key <> ''),
+		value				TEXT NOT NULL,
+		PRIMARY KEY			(bundle_name, bundle_version, key),
+		FOREIGN KEY 		(bundle_name, bundle_version) REFERENCES bundles(name, version)
+		ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS bundle_kubernetes_service_accounts (
+		bundle_name				TEXT NOT NULL,
+		bundle_version			TEXT NOT NULL,
+		group_name				TEXT NOT NULL CHECK(group_name <> ''),
+		service_account_name	TEXT NOT NULL CHECK(service_account_name <> ''),
+		PRIMARY KEY				(bundle_name, bundle_version, group_name),
+		FOREIGN KEY 			(bundle_name, bundle_version) REFERENCES bundles(name, version)
+		ON DELETE CASCADE
+	);
+
 	CREATE TABLE IF NOT EXISTS bundle_permissions (
 		bundle_name			TEXT NOT NULL,
 		bundle_version		TEXT NOT NULL,
@@ -285,6 +500,8 @@ func (da PostgresDataAccess) createBundlesTables(ctx context.Context, conn *sql.
 		ON DELETE CASCADE
 	);
 
+	ALTER TABLE bundle_templates ADD COLUMN IF NOT EXISTS command_timeout TEXT NOT NULL DEFAULT '';
+
 	CREATE TABLE IF NOT EXISTS bundle_commands (
 		bundle_name			TEXT NOT NULL,
 		bundle_version		TEXT NOT NULL,
@@ -298,6 +515,22 @@ func (da PostgresDataAccess) createBundlesTables(ctx context.Context, conn *sql.
 		ON DELETE CASCADE
 	);
 
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS exclusive TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS platform_os TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS platform_arch TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS cooldown TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS ansi TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS default_profile TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS ephemeral_output BOOLEAN NOT NULL DEFAULT false;
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS ephemeral_ttl TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS secret_output BOOLEAN NOT NULL DEFAULT false;
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS reaction_success TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS reaction_failure TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS usage TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS examples TEXT NOT NULL DEFAULT '';
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS native_help BOOLEAN NOT NULL DEFAULT false;
+	ALTER TABLE bundle_commands ADD COLUMN IF NOT EXISTS timeout TEXT NOT NULL DEFAULT '';
+
 	CREATE TABLE IF NOT EXISTS bundle_command_triggers (
 		bundle_name			TEXT NOT NULL,
 		bundle_version		TEXT NOT NULL,
@@ -334,6 +567,41 @@ func (da PostgresDataAccess) createBundlesTables(ctx context.Context, conn *sql.
 		REFERENCES 			bundle_commands(bundle_name, bundle_version, name)
 		ON DELETE CASCADE
 	);
+
+	ALTER TABLE bundle_command_templates ADD COLUMN IF NOT EXISTS command_timeout TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE IF NOT EXISTS bundle_command_options (
+		bundle_name			TEXT NOT NULL,
+		bundle_version		TEXT NOT NULL,
+		command_name		TEXT NOT NULL,
+		name				TEXT NOT NULL CHECK(name <> ''),
+		description			TEXT NOT NULL,
+		sensitive			BOOLEAN NOT NULL DEFAULT false,
+		PRIMARY KEY			(bundle_name, bundle_version, command_name, name),
+		FOREIGN KEY 		(bundle_name, bundle_version, command_name)
+		REFERENCES 			bundle_commands(bundle_name, bundle_version, name)
+		ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS bundle_command_profiles (
+		bundle_name				TEXT NOT NULL,
+		bundle_version			TEXT NOT NULL,
+		command_name			TEXT NOT NULL,
+		name					TEXT NOT NULL CHECK(name <> ''),
+		description				TEXT NOT NULL DEFAULT '',
+		env						TEXT NOT NULL DEFAULT '',
+		env_secret				TEXT NOT NULL DEFAULT '',
+		service_account_name	TEXT NOT NULL DEFAULT '',
+		permission				TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY				(bundle_name, bundle_version, command_name, name),
+		FOREIGN KEY 			(bundle_name, bundle_version, command_name)
+		REFERENCES 				bundle_commands(bundle_name, bundle_version, name)
+		ON DELETE CASCADE
+	);
+
+	ALTER TABLE bundle_command_options ADD COLUMN IF NOT EXISTS permissions TEXT NOT NULL DEFAULT '';
+
+	ALTER TABLE bundle_command_profiles ADD COLUMN IF NOT EXISTS cluster TEXT NOT NULL DEFAULT '';
 	`
 
 	_, err = conn.ExecContext(ctx, createBundlesQuery)
@@ -387,7 +655,8 @@ func (da PostgresDataAccess) createGroupsTable(ctx context.Context, conn *sql.Co
 	var err error
 
 	createGroupQuery := `CREATE TABLE groups (
-		groupname TEXT PRIMARY KEY
+		groupname TEXT PRIMARY KEY,
+		deleted_at TIMESTAMP WITH TIME ZONE
 	  );`
 
 	_, err = conn.ExecContext(ctx, createGroupQuery)
@@ -415,6 +684,122 @@ func (da PostgresDataAccess) createGroupUsersTable(ctx context.Context, conn *sq
 	return nil
 }
 
+func (da PostgresDataAccess) createChannelPresenceTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createChannelPresenceQuery := `CREATE TABLE channel_presence (
+		adapter			TEXT NOT NULL,
+		channel_id		TEXT NOT NULL,
+		first_seen		TIMESTAMP WITH TIME ZONE NOT NULL,
+		greeted_at		TIMESTAMP WITH TIME ZONE,
+		last_command_at	TIMESTAMP WITH TIME ZONE,
+		PRIMARY KEY		(adapter, channel_id)
+	);`
+
+	_, err = conn.ExecContext(ctx, createChannelPresenceQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) createChannelSettingsTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createChannelSettingsQuery := `CREATE TABLE channel_settings (
+		adapter			TEXT NOT NULL,
+		channel_id		TEXT NOT NULL,
+		default_bundle	TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY		(adapter, channel_id)
+	);`
+
+	_, err = conn.ExecContext(ctx, createChannelSettingsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) createLocksTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createLocksQuery := `CREATE TABLE locks (
+		name			TEXT NOT NULL CHECK(name <> ''),
+		owner			TEXT NOT NULL,
+		username		TEXT NOT NULL,
+		acquired_at		TIMESTAMP WITH TIME ZONE NOT NULL,
+		expires_at		TIMESTAMP WITH TIME ZONE NOT NULL,
+		PRIMARY KEY		(name)
+	);`
+
+	_, err = conn.ExecContext(ctx, createLocksQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) createMacrosTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createMacrosQuery := `CREATE TABLE macros (
+		layer			TEXT NOT NULL,
+		owner			TEXT NOT NULL CHECK(owner <> ''),
+		name			TEXT NOT NULL CHECK(name <> ''),
+		command			TEXT NOT NULL,
+		PRIMARY KEY		(layer, owner, name)
+	);`
+
+	_, err = conn.ExecContext(ctx, createMacrosQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) createAliasesTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createAliasesQuery := `CREATE TABLE aliases (
+		owner			TEXT NOT NULL,
+		name			TEXT NOT NULL CHECK(name <> ''),
+		command			TEXT NOT NULL CHECK(command <> ''),
+		PRIMARY KEY		(owner, name)
+	);`
+
+	_, err = conn.ExecContext(ctx, createAliasesQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) createOptionDefaultsTable(ctx context.Context, conn *sql.Conn) error {
+	var err error
+
+	createOptionDefaultsQuery := `CREATE TABLE option_defaults (
+		bundle_name		TEXT NOT NULL CHECK(bundle_name <> ''),
+		command_name	TEXT NOT NULL CHECK(command_name <> ''),
+		layer			TEXT NOT NULL,
+		owner			TEXT NOT NULL,
+		option			TEXT NOT NULL CHECK(option <> ''),
+		value			TEXT NOT NULL,
+		PRIMARY KEY		(layer, owner, bundle_name, command_name, option)
+	);`
+
+	_, err = conn.ExecContext(ctx, createOptionDefaultsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
 func (da PostgresDataAccess) createRolesTables(ctx context.Context, conn *sql.Conn) error {
 	var err error
 
@@ -480,7 +865,8 @@ func (da PostgresDataAccess) createUsersTable(ctx context.Context, conn *sql.Con
 		email         	TEXT,
 		full_name     	TEXT,
 		password_hash 	TEXT,
-		username 		TEXT PRIMARY KEY
+		username 		TEXT PRIMARY KEY,
+		deleted_at		TIMESTAMP WITH TIME ZONE
 	  );`
 
 	_, err = conn.ExecContext(ctx, createUserQuery)
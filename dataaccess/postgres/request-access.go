@@ -19,9 +19,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/getgort/gort/config"
 	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/dataaccess/errs"
 	gerr "github.com/getgort/gort/errors"
@@ -29,6 +32,75 @@ import (
 	"go.opentelemetry.io/otel"
 )
 
+// RequestArchive stores the response payload of a request, replacing any
+// that was already stored.
+func (da PostgresDataAccess) RequestArchive(ctx context.Context, requestID int64, payload data.RequestPayload) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RequestArchive")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO request_payloads (request_id, archived_at,
+			title, error_code, partial, output, rendered, truncated)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8
+		WHERE EXISTS (SELECT 1 FROM commands WHERE request_id=$1)
+		ON CONFLICT (request_id) DO UPDATE
+		SET archived_at=EXCLUDED.archived_at, title=EXCLUDED.title,
+			error_code=EXCLUDED.error_code, partial=EXCLUDED.partial,
+			output=EXCLUDED.output, rendered=EXCLUDED.rendered,
+			truncated=EXCLUDED.truncated;`
+
+	result, err := db.ExecContext(ctx, query,
+		requestID,
+		payload.ArchivedAt,
+		payload.Title,
+		payload.ErrorCode,
+		payload.Partial,
+		strings.Join(payload.Output, "\n"),
+		payload.Rendered,
+		payload.Truncated)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	} else if n == 0 {
+		return errs.ErrNoSuchRequest
+	}
+
+	return nil
+}
+
+// RequestArchivePurge deletes every payload archived before the given time,
+// returning the number deleted.
+func (da PostgresDataAccess) RequestArchivePurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RequestArchivePurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM request_payloads WHERE archived_at < $1;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
 func (da PostgresDataAccess) RequestBegin(ctx context.Context, req *data.CommandRequest) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.RequestBegin")
@@ -38,19 +110,18 @@ func (da PostgresDataAccess) RequestBegin(ctx context.Context, req *data.Command
 		return fmt.Errorf("command request ID already set")
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	const query = `INSERT INTO commands (bundle_name, bundle_version, command_name,
 		command_executable, command_parameters, adapter, user_id,
-		user_email, channel_id, gort_user_name, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		user_email, channel_id, gort_user_name, timestamp, replay_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING request_id;`
 
-	stmt, err := conn.PrepareContext(ctx, query)
+	stmt, err := db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -61,13 +132,14 @@ func (da PostgresDataAccess) RequestBegin(ctx context.Context, req *data.Command
 		req.Bundle.Version,
 		req.Command.Name,
 		encodeStringSlice(req.Command.Executable),
-		strings.Join(req.Parameters, " "),
+		req.RedactedParameters(config.GetRedactPatterns()...).String(),
 		req.Adapter,
 		req.UserID,
 		req.UserEmail,
 		req.ChannelID,
 		req.UserName,
-		req.Timestamp).Scan(&req.RequestID)
+		req.Timestamp,
+		req.ReplayOf).Scan(&req.RequestID)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -83,6 +155,237 @@ func (da PostgresDataAccess) RequestError(ctx context.Context, req data.CommandR
 	return da.RequestClose(ctx, data.NewCommandResponseEnvelope(req, data.WithError("", err, 1)))
 }
 
+// RequestGet returns the record of a single request.
+func (da PostgresDataAccess) RequestGet(ctx context.Context, requestID int64) (data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RequestGet")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+
+	query := requestRecordQuery + ` WHERE request_id=$1`
+
+	rows, err := db.QueryContext(ctx, query, requestID)
+	if err != nil {
+		return data.RequestRecord{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return data.RequestRecord{}, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		return data.RequestRecord{}, errs.ErrNoSuchRequest
+	}
+
+	record, err := scanRequestRecord(rows)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+	rows.Close()
+
+	record.Payload, err = da.getRequestPayload(ctx, db, requestID)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+
+	record.Logs, err = da.getRequestLogs(ctx, db, requestID)
+	if err != nil {
+		return data.RequestRecord{}, err
+	}
+
+	return record, nil
+}
+
+// getRequestPayload returns the archived payload of a request, or nil if
+// there isn't one.
+func (da PostgresDataAccess) getRequestPayload(ctx context.Context, db *sql.DB, requestID int64) (*data.RequestPayload, error) {
+	const query = `SELECT archived_at, title, error_code, partial, output, rendered, truncated
+		FROM request_payloads
+		WHERE request_id=$1`
+
+	var p data.RequestPayload
+	var output string
+
+	err := db.QueryRowContext(ctx, query, requestID).Scan(
+		&p.ArchivedAt, &p.Title, &p.ErrorCode, &p.Partial, &output, &p.Rendered, &p.Truncated)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if output != "" {
+		p.Output = strings.Split(output, "\n")
+	}
+
+	return &p, nil
+}
+
+// RequestList returns the records of up to limit of the most recent
+// requests, newest first. If limit is zero or less all records are returned.
+// RequestHistory returns the records of up to limit of the most recent
+// commands that the user made in the channel, newest first. Requests that
+// never matched a command, and replays, are excluded. If limit is zero or
+// less all matching records are returned.
+func (da PostgresDataAccess) RequestHistory(ctx context.Context, adapter, channelID, userID string, limit int) ([]data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RequestHistory")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := requestRecordQuery + ` WHERE adapter=$1 AND channel_id=$2 AND user_id=$3
+		AND command_name <> '' AND replay_of=0
+		ORDER BY request_id DESC`
+	args := []interface{}{adapter, channelID, userID}
+	if limit > 0 {
+		query += ` LIMIT $4`
+		args = append(args, limit)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.RequestRecord{}
+
+	for rows.Next() {
+		r, err := scanRequestRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+func (da PostgresDataAccess) RequestList(ctx context.Context, limit int) ([]data.RequestRecord, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RequestList")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := requestRecordQuery + ` ORDER BY request_id DESC`
+	args := []interface{}{}
+	if limit > 0 {
+		query += ` LIMIT $1`
+		args = append(args, limit)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	list := []data.RequestRecord{}
+
+	for rows.Next() {
+		r, err := scanRequestRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return list, nil
+}
+
+// RequestLogAppend attaches a log entry to a request.
+func (da PostgresDataAccess) RequestLogAppend(ctx context.Context, requestID int64, entry data.RequestLogEntry) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RequestLogAppend")
+	defer sp.End()
+
+	fields, err := json.Marshal(entry.Fields)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO request_logs (request_id, timestamp, level, message, fields)
+		SELECT $1, $2, $3, $4, $5
+		WHERE EXISTS (SELECT 1 FROM commands WHERE request_id=$1);`
+
+	result, err := db.ExecContext(ctx, query,
+		requestID, entry.Timestamp, entry.Level, entry.Message, string(fields))
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	} else if n == 0 {
+		return errs.ErrNoSuchRequest
+	}
+
+	return nil
+}
+
+// getRequestLogs returns the log entries attached to a request, in the order
+// they were appended.
+func (da PostgresDataAccess) getRequestLogs(ctx context.Context, db *sql.DB, requestID int64) ([]data.RequestLogEntry, error) {
+	const query = `SELECT timestamp, level, message, fields
+		FROM request_logs
+		WHERE request_id=$1
+		ORDER BY id`
+
+	rows, err := db.QueryContext(ctx, query, requestID)
+	if err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	var logs []data.RequestLogEntry
+
+	for rows.Next() {
+		var e data.RequestLogEntry
+		var fields string
+
+		if err := rows.Scan(&e.Timestamp, &e.Level, &e.Message, &fields); err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		if err := json.Unmarshal([]byte(fields), &e.Fields); err != nil {
+			return nil, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		logs = append(logs, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return logs, nil
+}
+
 func (da PostgresDataAccess) RequestUpdate(ctx context.Context, req data.CommandRequest) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.RequestUpdate")
@@ -92,11 +395,10 @@ func (da PostgresDataAccess) RequestUpdate(ctx context.Context, req data.Command
 		return fmt.Errorf("command request ID unset")
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	const query = `UPDATE commands
 		SET bundle_name=$1, bundle_version=$2, command_name=$3,
@@ -104,12 +406,12 @@ func (da PostgresDataAccess) RequestUpdate(ctx context.Context, req data.Command
 			user_email=$8, channel_id=$9, gort_user_name=$10
 		WHERE request_id=$11;`
 
-	_, err = conn.ExecContext(ctx, query,
+	_, err = db.ExecContext(ctx, query,
 		req.Bundle.Name,
 		req.Bundle.Version,
 		req.Command.Name,
 		encodeStringSlice(req.Command.Executable),
-		strings.Join(req.Parameters, " "),
+		req.RedactedParameters(config.GetRedactPatterns()...).String(),
 		req.Adapter,
 		req.UserID,
 		req.UserEmail,
@@ -123,6 +425,36 @@ func (da PostgresDataAccess) RequestUpdate(ctx context.Context, req data.Command
 	return err
 }
 
+// RequestUpdateTimings replaces the stage timings of a request.
+func (da PostgresDataAccess) RequestUpdateTimings(ctx context.Context, requestID int64, timings data.StageTimings) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RequestUpdateTimings")
+	defer sp.End()
+
+	if requestID == 0 {
+		return fmt.Errorf("command request ID unset")
+	}
+
+	encoded, err := encodeStageTimings(timings)
+	if err != nil {
+		return err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE commands SET timings=$1 WHERE request_id=$2;`
+
+	_, err = db.ExecContext(ctx, query, encoded, requestID)
+	if err != nil {
+		err = gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return err
+}
+
 func (da PostgresDataAccess) RequestClose(ctx context.Context, envelope data.CommandResponseEnvelope) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.RequestClose")
@@ -132,30 +464,34 @@ func (da PostgresDataAccess) RequestClose(ctx context.Context, envelope data.Com
 		return fmt.Errorf("command request ID unset")
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	const query = `UPDATE commands
 		SET bundle_name=$1, bundle_version=$2, command_name=$3,
 			command_executable=$4, command_parameters=$5, adapter=$6, user_id=$7,
 			user_email=$8, channel_id=$9, gort_user_name=$10, timestamp=$11,
-			duration=$12, result_status=$13, result_error=$14
-		WHERE request_id=$15;`
+			duration=$12, result_status=$13, result_error=$14, timings=$15
+		WHERE request_id=$16;`
 
 	errMsg := ""
 	if envelope.Data.Error != nil {
 		errMsg = envelope.Data.Error.Error()
 	}
 
-	_, err = conn.ExecContext(ctx, query,
+	timings, err := encodeStageTimings(envelope.Request.Timings)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, query,
 		envelope.Request.Bundle.Name,
 		envelope.Request.Bundle.Version,
 		envelope.Request.Command.Name,
 		encodeStringSlice(envelope.Request.Command.Executable),
-		strings.Join(envelope.Request.Parameters, " "),
+		envelope.Request.RedactedParameters(config.GetRedactPatterns()...).String(),
 		envelope.Request.Adapter,
 		envelope.Request.UserID,
 		envelope.Request.UserEmail,
@@ -165,6 +501,7 @@ func (da PostgresDataAccess) RequestClose(ctx context.Context, envelope data.Com
 		envelope.Data.Duration.Milliseconds(),
 		envelope.Data.ExitCode,
 		errMsg,
+		timings,
 		envelope.Request.RequestID)
 	if err != nil {
 		err = gerr.Wrap(errs.ErrDataAccess, err)
@@ -173,6 +510,119 @@ func (da PostgresDataAccess) RequestClose(ctx context.Context, envelope data.Com
 	return err
 }
 
+const requestRecordQuery = `SELECT request_id, timestamp, duration,
+		bundle_name, bundle_version, command_name, command_parameters,
+		adapter, user_id, user_email, channel_id, gort_user_name,
+		result_status, result_error, timings, replay_of
+	FROM commands`
+
+// scanRequestRecord scans a row selected by requestRecordQuery.
+func scanRequestRecord(rows *sql.Rows) (data.RequestRecord, error) {
+	var r data.RequestRecord
+	var timestamp sql.NullTime
+	var duration, status sql.NullInt64
+	var errMsg sql.NullString
+	var timings string
+
+	err := rows.Scan(&r.RequestID, &timestamp, &duration,
+		&r.BundleName, &r.BundleVersion, &r.CommandName, &r.Parameters,
+		&r.Adapter, &r.UserID, &r.UserEmail, &r.ChannelID, &r.UserName,
+		&status, &errMsg, &timings, &r.ReplayOf)
+	if err != nil {
+		return data.RequestRecord{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	r.Timestamp = timestamp.Time
+	r.Duration = time.Duration(duration.Int64) * time.Millisecond
+	r.Closed = status.Valid
+	r.ExitCode = int16(status.Int64)
+	r.Error = errMsg.String
+
+	if r.Timings, err = decodeStageTimings(timings); err != nil {
+		return data.RequestRecord{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return r, nil
+}
+
+// encodeStageTimings encodes timings as a JSON object of stage names to
+// durations in milliseconds.
+func encodeStageTimings(t data.StageTimings) (string, error) {
+	if len(t) == 0 {
+		return "", nil
+	}
+
+	ms := map[data.RequestStage]int64{}
+	for stage, d := range t {
+		ms[stage] = d.Milliseconds()
+	}
+
+	b, err := json.Marshal(ms)
+	return string(b), err
+}
+
+func decodeStageTimings(s string) (data.StageTimings, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	ms := map[data.RequestStage]int64{}
+	if err := json.Unmarshal([]byte(s), &ms); err != nil {
+		return nil, err
+	}
+
+	t := data.StageTimings{}
+	for stage, m := range ms {
+		t[stage] = time.Duration(m) * time.Millisecond
+	}
+
+	return t, nil
+}
+
+func (da PostgresDataAccess) createRequestPayloadsTable(ctx context.Context, conn *sql.Conn) error {
+	createRequestPayloadsQuery := `CREATE TABLE request_payloads (
+		request_id		BIGINT NOT NULL,
+		archived_at		TIMESTAMP WITH TIME ZONE NOT NULL,
+		title			TEXT NOT NULL,
+		error_code		TEXT NOT NULL,
+		partial			BOOLEAN NOT NULL,
+		output			TEXT NOT NULL,
+		rendered		TEXT NOT NULL,
+		truncated		BOOLEAN NOT NULL,
+		PRIMARY KEY		(request_id)
+	);
+
+	CREATE INDEX request_payloads_archived_at ON request_payloads (archived_at);`
+
+	_, err := conn.ExecContext(ctx, createRequestPayloadsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+func (da PostgresDataAccess) createRequestLogsTable(ctx context.Context, conn *sql.Conn) error {
+	createRequestLogsQuery := `CREATE TABLE request_logs (
+		id				BIGSERIAL,
+		request_id		BIGINT NOT NULL,
+		timestamp		TIMESTAMP WITH TIME ZONE NOT NULL,
+		level			TEXT NOT NULL,
+		message			TEXT NOT NULL,
+		fields			TEXT NOT NULL,
+		PRIMARY KEY		(id)
+	);
+
+	CREATE INDEX request_logs_request_id ON request_logs (request_id);`
+
+	_, err := conn.ExecContext(ctx, createRequestLogsQuery)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
 func (da PostgresDataAccess) createCommandsTable(ctx context.Context, conn *sql.Conn) error {
 	createCommandsQuery := `CREATE TABLE commands(
 		request_id          BIGSERIAL,
@@ -189,7 +639,8 @@ func (da PostgresDataAccess) createCommandsTable(ctx context.Context, conn *sql.
 		channel_id		    TEXT NOT NULL,
 		gort_user_name      TEXT NOT NULL,
 		result_status		INT,
-		result_error        TEXT
+		result_error        TEXT,
+		timings             TEXT NOT NULL DEFAULT ''
 	);`
 
 	_, err := conn.ExecContext(ctx, createCommandsQuery)
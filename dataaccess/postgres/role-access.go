@@ -18,6 +18,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"sort"
 
@@ -29,6 +30,69 @@ import (
 	"github.com/getgort/gort/telemetry"
 )
 
+// RoleClone creates a new role named clonename that has been granted the same
+// permissions as rolename. Group memberships aren't copied.
+func (da PostgresDataAccess) RoleClone(ctx context.Context, rolename, clonename string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RoleClone")
+	defer sp.End()
+
+	if rolename == "" || clonename == "" {
+		return errs.ErrEmptyRoleName
+	}
+
+	exists, err := da.RoleExists(ctx, rolename)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchRole
+	}
+
+	exists, err = da.RoleExists(ctx, clonename)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errs.ErrRoleExists
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query := `INSERT INTO roles (role_name) VALUES ($1);`
+	_, err = tx.ExecContext(ctx, query, clonename)
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query = `INSERT INTO role_permissions (role_name, bundle_name, permission)
+		SELECT $2, bundle_name, permission
+		FROM role_permissions
+		WHERE role_name=$1;`
+	_, err = tx.ExecContext(ctx, query, rolename, clonename)
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
 // RoleCreate creates a new role.
 func (da PostgresDataAccess) RoleCreate(ctx context.Context, name string) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
@@ -47,14 +111,13 @@ func (da PostgresDataAccess) RoleCreate(ctx context.Context, name string) error
 		return errs.ErrRoleExists
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `INSERT INTO roles (role_name) VALUES ($1);`
-	_, err = conn.ExecContext(ctx, query, name)
+	_, err = db.ExecContext(ctx, query, name)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -85,20 +148,19 @@ func (da PostgresDataAccess) RoleDelete(ctx context.Context, name string) error
 		return errs.ErrNoSuchRole
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
-	query := `DELETE FROM group_roles WHERE group_name=$1;`
-	_, err = conn.ExecContext(ctx, query, name)
+	query := `DELETE FROM group_roles WHERE role_name=$1;`
+	_, err = db.ExecContext(ctx, query, name)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
 	query = `DELETE FROM roles WHERE role_name=$1;`
-	_, err = conn.ExecContext(ctx, query, name)
+	_, err = db.ExecContext(ctx, query, name)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -112,16 +174,15 @@ func (da PostgresDataAccess) RoleExists(ctx context.Context, rolename string) (b
 	ctx, sp := tr.Start(ctx, "postgres.RoleExists")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return false, err
 	}
-	defer conn.Close()
 
 	query := "SELECT EXISTS(SELECT 1 FROM roles WHERE role_name=$1)"
 	exists := false
 
-	err = conn.QueryRowContext(ctx, query, rolename).Scan(&exists)
+	err = db.QueryRowContext(ctx, query, rolename).Scan(&exists)
 	if err != nil {
 		return false, gerr.Wrap(errs.ErrNoSuchRole, err)
 	}
@@ -139,11 +200,10 @@ func (da PostgresDataAccess) RoleGet(ctx context.Context, name string) (rest.Rol
 		return rest.Role{}, errs.ErrEmptyRoleName
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return rest.Role{}, err
 	}
-	defer conn.Close()
 
 	// There will be more fields here eventually
 	query := `SELECT role_name
@@ -151,7 +211,7 @@ func (da PostgresDataAccess) RoleGet(ctx context.Context, name string) (rest.Rol
 		WHERE role_name=$1`
 
 	role := rest.Role{}
-	err = conn.QueryRowContext(ctx, query, name).Scan(&role.Name)
+	err = db.QueryRowContext(ctx, query, name).Scan(&role.Name)
 	if err != nil {
 		return role, gerr.Wrap(errs.ErrNoSuchRole, err)
 	}
@@ -224,18 +284,19 @@ func (da PostgresDataAccess) RoleGroupList(ctx context.Context, rolename string)
 		return nil, errs.ErrNoSuchRole
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
 	query := `SELECT group_name
 		FROM group_roles
-		WHERE role_name = $1
+		WHERE role_name = $1 AND group_name IN (
+			SELECT groupname FROM groups WHERE deleted_at IS NULL
+		)
 		ORDER BY role_name`
 
-	rows, err := conn.QueryContext(ctx, query, rolename)
+	rows, err := db.QueryContext(ctx, query, rolename)
 	if err != nil {
 		return nil, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -267,18 +328,17 @@ func (da PostgresDataAccess) RoleList(ctx context.Context) ([]rest.Role, error)
 	ctx, sp := tr.Start(ctx, "postgres.RoleList")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
 	var rolesByName = make(map[string]*rest.Role)
 	// Load all role names and add to the roles map
 	query := `SELECT role_name
 		FROM roles`
 
-	rows, err := conn.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, gerr.Wrap(errs.ErrNoSuchRole, err)
 	}
@@ -295,7 +355,7 @@ func (da PostgresDataAccess) RoleList(ctx context.Context) ([]rest.Role, error)
 	// Load all permissions and add to role objects
 	query = `SELECT role_name, bundle_name, permission
 		FROM role_permissions`
-	rows, err = conn.QueryContext(ctx, query)
+	rows, err = db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, gerr.Wrap(errs.ErrNoSuchRole, err)
 	}
@@ -345,15 +405,14 @@ func (da PostgresDataAccess) RolePermissionAdd(ctx context.Context, rolename, bu
 		return errs.ErrNoSuchRole
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `INSERT INTO role_permissions (role_name, bundle_name, permission)
 		VALUES ($1, $2, $3);`
-	_, err = conn.ExecContext(ctx, query, rolename, bundle, permission)
+	_, err = db.ExecContext(ctx, query, rolename, bundle, permission)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -361,6 +420,66 @@ func (da PostgresDataAccess) RolePermissionAdd(ctx context.Context, rolename, bu
 	return err
 }
 
+// RolePermissionAddBatch grants all of the given permissions to a role in a
+// single transaction. Permissions that the role has already been granted are
+// ignored.
+func (da PostgresDataAccess) RolePermissionAddBatch(ctx context.Context, rolename string, permissions rest.RolePermissionList) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.RolePermissionAddBatch")
+	defer sp.End()
+
+	if rolename == "" {
+		return errs.ErrEmptyRoleName
+	}
+
+	for _, p := range permissions {
+		if p.BundleName == "" {
+			return errs.ErrEmptyBundleName
+		}
+		if p.Permission == "" {
+			return errs.ErrEmptyPermission
+		}
+	}
+
+	exists, err := da.RoleExists(ctx, rolename)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchRole
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	query := `INSERT INTO role_permissions (role_name, bundle_name, permission)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING;`
+
+	for _, p := range permissions {
+		_, err = tx.ExecContext(ctx, query, rolename, p.BundleName, p.Permission)
+		if err != nil {
+			tx.Rollback()
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
 func (da PostgresDataAccess) RolePermissionDelete(ctx context.Context, rolename, bundle, permission string) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.RolePermissionDelete")
@@ -378,15 +497,14 @@ func (da PostgresDataAccess) RolePermissionDelete(ctx context.Context, rolename,
 		return errs.ErrEmptyPermission
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `DELETE FROM role_permissions
 		WHERE role_name=$1 AND bundle_name=$2 AND permission=$3;`
-	_, err = conn.ExecContext(ctx, query, rolename, bundle, permission)
+	_, err = db.ExecContext(ctx, query, rolename, bundle, permission)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -439,17 +557,16 @@ func (da PostgresDataAccess) doGetRolePermissions(ctx context.Context, name stri
 
 	perms := make([]rest.RolePermission, 0)
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return perms, err
 	}
-	defer conn.Close()
 
 	query := `SELECT bundle_name, permission
 		FROM role_permissions
 		WHERE role_name = $1`
 
-	rows, err := conn.QueryContext(ctx, query, name)
+	rows, err := db.QueryContext(ctx, query, name)
 	if err != nil {
 		return perms, gerr.Wrap(errs.ErrDataAccess, err)
 	}
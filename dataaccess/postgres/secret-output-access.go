@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerr "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+// SecretOutputCreate stores a secret output.
+func (da PostgresDataAccess) SecretOutputCreate(ctx context.Context, output data.SecretOutput) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.SecretOutputCreate")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO secret_outputs
+		(token_hash, request_id, username, title, output, created, expires)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+	_, err = db.ExecContext(ctx, query, output.TokenHash, output.RequestID,
+		output.Username, output.Title, encodeStringSlice(output.Output),
+		output.Created, output.Expires)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// SecretOutputPurge deletes every secret output that expired before the
+// given time, returning the number deleted.
+func (da PostgresDataAccess) SecretOutputPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.SecretOutputPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM secret_outputs WHERE expires < $1;`, before)
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return int(n), nil
+}
+
+// SecretOutputRedeem deletes and returns the secret output with the given
+// token hash. An output that has expired can't be redeemed.
+func (da PostgresDataAccess) SecretOutputRedeem(ctx context.Context, tokenHash string) (data.SecretOutput, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.SecretOutputRedeem")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return data.SecretOutput{}, err
+	}
+
+	// Deleting and returning in a single statement guarantees that each
+	// output is redeemed at most once, even by concurrent requests.
+	const query = `DELETE FROM secret_outputs WHERE token_hash=$1
+		RETURNING token_hash, request_id, username, title, output, created, expires;`
+
+	var s data.SecretOutput
+	var enc string
+
+	err = db.QueryRowContext(ctx, query, tokenHash).Scan(&s.TokenHash,
+		&s.RequestID, &s.Username, &s.Title, &enc, &s.Created, &s.Expires)
+	switch {
+	case err == sql.ErrNoRows:
+		return data.SecretOutput{}, errs.ErrNoSuchSecretOutput
+	case err != nil:
+		return data.SecretOutput{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	if s.Expired() {
+		return data.SecretOutput{}, errs.ErrNoSuchSecretOutput
+	}
+
+	s.Output = decodeStringSlice(enc)
+
+	return s, nil
+}
@@ -82,15 +82,14 @@ func (da PostgresDataAccess) TokenGenerate(ctx context.Context, username string,
 		ValidUntil: validUntil,
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return rest.Token{}, err
 	}
-	defer conn.Close()
 
 	query := `INSERT INTO tokens (token, username, valid_from, valid_until)
 	VALUES ($1, $2, $3, $4);`
-	_, err = conn.ExecContext(ctx, query, token.Token, token.User, token.ValidFrom, token.ValidUntil)
+	_, err = db.ExecContext(ctx, query, token.Token, token.User, token.ValidFrom, token.ValidUntil)
 	if err != nil {
 		return rest.Token{}, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -105,14 +104,13 @@ func (da PostgresDataAccess) TokenInvalidate(ctx context.Context, tokenString st
 	ctx, sp := tr.Start(ctx, "postgres.TokenInvalidate")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `DELETE FROM tokens WHERE token=$1;`
-	_, err = conn.ExecContext(ctx, query, tokenString)
+	_, err = db.ExecContext(ctx, query, tokenString)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -120,6 +118,38 @@ func (da PostgresDataAccess) TokenInvalidate(ctx context.Context, tokenString st
 	return nil
 }
 
+// TokenPurge deletes every token and token revocation that expired before
+// the given time, returning the number deleted.
+func (da PostgresDataAccess) TokenPurge(ctx context.Context, before time.Time) (int, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.TokenPurge")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, query := range []string{
+		`DELETE FROM tokens WHERE valid_until < $1;`,
+		`DELETE FROM revoked_tokens WHERE valid_until < $1;`,
+	} {
+		result, err := db.ExecContext(ctx, query, before)
+		if err != nil {
+			return total, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return total, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+		total += int(n)
+	}
+
+	return total, nil
+}
+
 // TokenRetrieveByUser retrieves the token associated with a username. An
 // error is returned if no such token (or user) exists.
 func (da PostgresDataAccess) TokenRetrieveByUser(ctx context.Context, username string) (rest.Token, error) {
@@ -127,11 +157,10 @@ func (da PostgresDataAccess) TokenRetrieveByUser(ctx context.Context, username s
 	ctx, sp := tr.Start(ctx, "postgres.TokenRetrieveByUser")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return rest.Token{}, err
 	}
-	defer conn.Close()
 
 	// There will be more here eventually
 	query := `SELECT token, username, valid_from, valid_until
@@ -140,7 +169,7 @@ func (da PostgresDataAccess) TokenRetrieveByUser(ctx context.Context, username s
 
 	token := rest.Token{}
 
-	err = conn.
+	err = db.
 		QueryRowContext(ctx, query, username).
 		Scan(&token.Token, &token.User, &token.ValidFrom, &token.ValidUntil)
 
@@ -160,11 +189,10 @@ func (da PostgresDataAccess) TokenRetrieveByToken(ctx context.Context, tokenStri
 	ctx, sp := tr.Start(ctx, "postgres.TokenRetrieveByToken")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return rest.Token{}, err
 	}
-	defer conn.Close()
 
 	// There will be more here eventually
 	query := `SELECT token, username, valid_from, valid_until
@@ -172,7 +200,7 @@ func (da PostgresDataAccess) TokenRetrieveByToken(ctx context.Context, tokenStri
 		WHERE token=$1`
 
 	token := rest.Token{}
-	err = conn.
+	err = db.
 		QueryRowContext(ctx, query, tokenString).
 		Scan(&token.Token, &token.User, &token.ValidFrom, &token.ValidUntil)
 
@@ -184,3 +212,51 @@ func (da PostgresDataAccess) TokenRetrieveByToken(ctx context.Context, tokenStri
 
 	return token, err
 }
+
+// TokenRevoke adds the ID of a self-contained token, like a JWT, to the
+// revocation list. It's kept there until the given time, after which the
+// token will have expired anyway. Revoking a token more than once isn't an
+// error.
+func (da PostgresDataAccess) TokenRevoke(ctx context.Context, id string, until time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.TokenRevoke")
+	defer sp.End()
+
+	if id == "" {
+		return errs.ErrNoSuchToken
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO revoked_tokens (token_id, valid_until) VALUES ($1, $2)
+	ON CONFLICT (token_id) DO NOTHING;`
+	_, err = db.ExecContext(ctx, query, id, until)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// TokenRevoked returns true if the token ID is on the revocation list.
+func (da PostgresDataAccess) TokenRevoked(ctx context.Context, id string) (bool, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.TokenRevoked")
+	defer sp.End()
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	revoked := false
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_id=$1)`
+	if err := db.QueryRowContext(ctx, query, id).Scan(&revoked); err != nil {
+		return false, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return revoked, nil
+}
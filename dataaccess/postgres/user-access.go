@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql"
 	"sort"
+	"time"
 
 	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/data/rest"
@@ -29,7 +30,8 @@ import (
 	"go.opentelemetry.io/otel"
 )
 
-// UserAuthenticate authenticates a username/password combination.
+// UserAuthenticate authenticates a username/password combination. Service
+// accounts have no password, so they never authenticate.
 func (da PostgresDataAccess) UserAuthenticate(ctx context.Context, username string, password string) (bool, error) {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.UserAuthenticate")
@@ -43,27 +45,28 @@ func (da PostgresDataAccess) UserAuthenticate(ctx context.Context, username stri
 		return false, errs.ErrNoSuchUser
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return false, err
 	}
-	defer conn.Close()
 
-	query := `SELECT password_hash
+	query := `SELECT password_hash, service_account
 		FROM users
 		WHERE username=$1`
 
 	var hash string
-	err = conn.QueryRowContext(ctx, query, username).Scan(&hash)
+	var serviceAccount bool
+	err = db.QueryRowContext(ctx, query, username).Scan(&hash, &serviceAccount)
 	if err != nil {
 		err = gerr.Wrap(errs.ErrNoSuchUser, err)
 	}
 
-	return data.CompareHashAndPassword(hash, password), err
+	return !serviceAccount && data.CompareHashAndPassword(hash, password), err
 }
 
 // UserCreate is used to create a new Gort user in the data store. An error is
-// returned if the username is empty or if a user already exists.
+// returned if the username is empty, if a user already exists, or if a service
+// account has a password or mappings.
 func (da PostgresDataAccess) UserCreate(ctx context.Context, user rest.User) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.UserCreate")
@@ -81,11 +84,20 @@ func (da PostgresDataAccess) UserCreate(ctx context.Context, user rest.User) err
 		return errs.ErrUserExists
 	}
 
-	conn, err := da.connect(ctx)
+	if user.ServiceAccount && (user.Password != "" || len(user.Mappings) > 0) {
+		return errs.ErrServiceAccountCredentials
+	}
+
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
+
+	// Creating a user permanently discards any deleted user of the same
+	// name.
+	if err := da.doUserPurgeDeleted(ctx, db, user.Username); err != nil {
+		return err
+	}
 
 	var hash string
 	if user.Password != "" {
@@ -95,8 +107,9 @@ func (da PostgresDataAccess) UserCreate(ctx context.Context, user rest.User) err
 		}
 	}
 
-	userQuery := `INSERT INTO users (email, full_name, password_hash, username) VALUES ($1, $2, $3, $4);`
-	if _, err := conn.ExecContext(ctx, userQuery, user.Email, user.FullName, hash, user.Username); err != nil {
+	userQuery := `INSERT INTO users (email, full_name, password_hash, username, service_account)
+		VALUES ($1, $2, $3, $4, $5);`
+	if _, err := db.ExecContext(ctx, userQuery, user.Email, user.FullName, hash, user.Username, user.ServiceAccount); err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
@@ -107,9 +120,11 @@ func (da PostgresDataAccess) UserCreate(ctx context.Context, user rest.User) err
 	return nil
 }
 
-// UserDelete deletes an existing user from the data store. An error is
-// returned if the username parameter is empty or if the user doesn't
-// exist.
+// UserDelete soft-deletes an existing user from the data store. The user's
+// tokens are revoked immediately, but their group memberships and adapter
+// mappings are retained so that the user can be restored using UserRestore.
+// An error is returned if the username parameter is empty or if the user
+// doesn't exist.
 func (da PostgresDataAccess) UserDelete(ctx context.Context, username string) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.UserDelete")
@@ -132,26 +147,19 @@ func (da PostgresDataAccess) UserDelete(ctx context.Context, username string) er
 		return errs.ErrNoSuchUser
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
-	query := `DELETE FROM groupusers WHERE username=$1;`
-	_, err = conn.ExecContext(ctx, query, username)
+	query := "DELETE FROM tokens WHERE username=$1;"
+	_, err = db.ExecContext(ctx, query, username)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
-	query = "DELETE FROM tokens WHERE username=$1;"
-	_, err = conn.ExecContext(ctx, query, username)
-	if err != nil {
-		return gerr.Wrap(errs.ErrDataAccess, err)
-	}
-
-	query = "DELETE FROM users WHERE username=$1;"
-	_, err = conn.ExecContext(ctx, query, username)
+	query = "UPDATE users SET deleted_at=now() WHERE username=$1;"
+	_, err = db.ExecContext(ctx, query, username)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -166,16 +174,15 @@ func (da PostgresDataAccess) UserExists(ctx context.Context, username string) (b
 	ctx, sp := tr.Start(ctx, "postgres.UserExists")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return false, err
 	}
-	defer conn.Close()
 
-	query := "SELECT EXISTS(SELECT 1 FROM users WHERE username=$1)"
+	query := "SELECT EXISTS(SELECT 1 FROM users WHERE username=$1 AND deleted_at IS NULL)"
 	exists := false
 
-	err = conn.QueryRowContext(ctx, query, username).Scan(&exists)
+	err = db.QueryRowContext(ctx, query, username).Scan(&exists)
 	if err != nil {
 		return false, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -183,6 +190,69 @@ func (da PostgresDataAccess) UserExists(ctx context.Context, username string) (b
 	return exists, nil
 }
 
+// UserExport returns all of the personal data stored about a user, including
+// the records of any command requests that they made.
+func (da PostgresDataAccess) UserExport(ctx context.Context, username string) (rest.UserDataExport, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.UserExport")
+	defer sp.End()
+
+	user, err := da.UserGet(ctx, username)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	groups, err := da.UserGroupList(ctx, username)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	export := rest.UserDataExport{User: user, Exported: time.Now().UTC()}
+	for _, g := range groups {
+		export.Groups = append(export.Groups, g.Name)
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.UserDataExport{}, err
+	}
+
+	query := `SELECT request_id, timestamp, adapter, channel_id, user_id,
+			user_email, bundle_name, command_name, command_parameters
+		FROM commands
+		WHERE gort_user_name=$1
+			OR (adapter, user_id) IN (SELECT adapter, id FROM user_adapter_ids WHERE username=$1)
+		ORDER BY request_id`
+
+	rows, err := db.QueryContext(ctx, query, username)
+	if err != nil {
+		return rest.UserDataExport{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r rest.UserRequestRecord
+		var bundle, command string
+		var timestamp sql.NullTime
+
+		err = rows.Scan(&r.RequestID, &timestamp, &r.Adapter, &r.ChannelID,
+			&r.UserID, &r.UserEmail, &bundle, &command, &r.Parameters)
+		if err != nil {
+			return rest.UserDataExport{}, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+
+		r.Timestamp = timestamp.Time
+		r.Command = bundle + ":" + command
+		export.Requests = append(export.Requests, r)
+	}
+
+	if rows.Err() != nil {
+		return rest.UserDataExport{}, gerr.Wrap(errs.ErrDataAccess, rows.Err())
+	}
+
+	return export, nil
+}
+
 // UserGet returns a user from the data store. An error is returned if the
 // username parameter is empty or if the user doesn't exist.
 func (da PostgresDataAccess) UserGet(ctx context.Context, username string) (rest.User, error) {
@@ -194,19 +264,18 @@ func (da PostgresDataAccess) UserGet(ctx context.Context, username string) (rest
 		return rest.User{}, errs.ErrEmptyUserName
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return rest.User{}, err
 	}
-	defer conn.Close()
 
-	query := `SELECT email, full_name, username
+	query := `SELECT email, full_name, username, service_account
 		FROM users
-		WHERE username=$1`
+		WHERE username=$1 AND deleted_at IS NULL`
 
 	var user rest.User
 
-	err = conn.QueryRowContext(ctx, query, username).Scan(&user.Email, &user.FullName, &user.Username)
+	err = db.QueryRowContext(ctx, query, username).Scan(&user.Email, &user.FullName, &user.Username, &user.ServiceAccount)
 	switch {
 	case err == sql.ErrNoRows:
 		return rest.User{}, errs.ErrNoSuchUser
@@ -232,18 +301,17 @@ func (da PostgresDataAccess) UserGetByEmail(ctx context.Context, email string) (
 		return rest.User{}, errs.ErrEmptyUserEmail
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return rest.User{}, err
 	}
-	defer conn.Close()
 
-	query := `SELECT email, full_name, username
+	query := `SELECT email, full_name, username, service_account
 		FROM users
-		WHERE email=$1`
+		WHERE email=$1 AND deleted_at IS NULL`
 
 	var user rest.User
-	err = conn.QueryRowContext(ctx, query, email).Scan(&user.Email, &user.FullName, &user.Username)
+	err = db.QueryRowContext(ctx, query, email).Scan(&user.Email, &user.FullName, &user.Username, &user.ServiceAccount)
 	switch {
 	case err == sql.ErrNoRows:
 		return rest.User{}, errs.ErrNoSuchUser
@@ -273,18 +341,17 @@ func (da PostgresDataAccess) UserGetByID(ctx context.Context, adapter, id string
 		return rest.User{}, errs.ErrEmptyUserID
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return rest.User{}, err
 	}
-	defer conn.Close()
 
 	query := `SELECT username
 		FROM user_adapter_ids
 		WHERE adapter=$1 AND id=$2`
 
 	var username string
-	err = conn.QueryRowContext(ctx, query, adapter, id).Scan(&username)
+	err = db.QueryRowContext(ctx, query, adapter, id).Scan(&username)
 	switch {
 	case err == nil:
 		return da.UserGet(ctx, username)
@@ -304,14 +371,16 @@ func (da PostgresDataAccess) UserGroupList(ctx context.Context, username string)
 
 	groups := make([]rest.Group, 0)
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return groups, err
 	}
-	defer conn.Close()
 
-	query := `SELECT groupname FROM groupusers WHERE username=$1`
-	rows, err := conn.QueryContext(ctx, query, username)
+	query := `SELECT groupusers.groupname
+		FROM groupusers
+		INNER JOIN groups ON groupusers.groupname=groups.groupname
+		WHERE groupusers.username=$1 AND groups.deleted_at IS NULL`
+	rows, err := db.QueryContext(ctx, query, username)
 	if err != nil {
 		return groups, gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -364,17 +433,16 @@ func (da PostgresDataAccess) UserGroupAdd(ctx context.Context, username string,
 		return errs.ErrNoSuchGroup
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `UPDATE groupusers
 		SET groupname=$1, username=$2
 		WHERE username=$2;`
 
-	_, err = conn.ExecContext(ctx, query, groupname, username)
+	_, err = db.ExecContext(ctx, query, groupname, username)
 	if err != nil {
 		err = gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -412,15 +480,14 @@ func (da PostgresDataAccess) UserGroupDelete(ctx context.Context, username strin
 		return errs.ErrNoSuchGroup
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
 	query := `DELETE FROM groupusers WHERE groupname=$1 AND username=$2;`
 
-	_, err = conn.ExecContext(ctx, query, groupname, username)
+	_, err = db.ExecContext(ctx, query, groupname, username)
 	if err != nil {
 		err = gerr.Wrap(errs.ErrDataAccess, err)
 	}
@@ -435,14 +502,13 @@ func (da PostgresDataAccess) UserList(ctx context.Context) ([]rest.User, error)
 	ctx, sp := tr.Start(ctx, "postgres.UserList")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
-	query := `SELECT email, full_name, username FROM users`
-	rows, err := conn.QueryContext(ctx, query)
+	query := `SELECT email, full_name, username, service_account FROM users WHERE deleted_at IS NULL`
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -452,7 +518,7 @@ func (da PostgresDataAccess) UserList(ctx context.Context) ([]rest.User, error)
 
 	for rows.Next() {
 		user := rest.User{}
-		err = rows.Scan(&user.Email, &user.FullName, &user.Username)
+		err = rows.Scan(&user.Email, &user.FullName, &user.Username, &user.ServiceAccount)
 		if err != nil {
 			err = gerr.Wrap(errs.ErrNoSuchUser, err)
 		}
@@ -505,6 +571,204 @@ func (da PostgresDataAccess) UserPermissionList(ctx context.Context, username st
 	return pp, nil
 }
 
+// UserPurge removes a user, along with their group memberships, adapter
+// mappings, and tokens. The user's request records are kept so that
+// aggregate statistics are preserved, but their personal data is removed and
+// the username replaced with a random pseudonym. An error is returned if the
+// username parameter is empty, if the user doesn't exist, or if the user is
+// "admin".
+func (da PostgresDataAccess) UserPurge(ctx context.Context, username string) (rest.UserPurgeResult, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.UserPurge")
+	defer sp.End()
+
+	if username == "" {
+		return rest.UserPurgeResult{}, errs.ErrEmptyUserName
+	}
+
+	// Thou Shalt Not Purge Admin
+	if username == "admin" {
+		return rest.UserPurgeResult{}, errs.ErrAdminUndeletable
+	}
+
+	pseudonym, err := data.GeneratePseudonym()
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return rest.UserPurgeResult{}, err
+	}
+
+	// Soft-deleted users can be purged too, so UserExists won't do here.
+	exists := false
+	query := "SELECT EXISTS(SELECT 1 FROM users WHERE username=$1)"
+	if err := db.QueryRowContext(ctx, query, username).Scan(&exists); err != nil {
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if !exists {
+		return rest.UserPurgeResult{}, errs.ErrNoSuchUser
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// This has to happen before the user (and therefore their adapter
+	// mappings) is deleted.
+	query = `UPDATE commands
+		SET gort_user_name=$2, user_id='', user_email=''
+		WHERE gort_user_name=$1
+			OR (adapter, user_id) IN (SELECT adapter, id FROM user_adapter_ids WHERE username=$1);`
+	res, err := tx.ExecContext(ctx, query, username, pseudonym)
+	if err != nil {
+		tx.Rollback()
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	anonymized, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	// Adapter mappings are removed by cascade.
+	for _, query := range []string{
+		`DELETE FROM groupusers WHERE username=$1;`,
+		`DELETE FROM tokens WHERE username=$1;`,
+		`DELETE FROM users WHERE username=$1;`,
+	} {
+		_, err = tx.ExecContext(ctx, query, username)
+		if err != nil {
+			tx.Rollback()
+			return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return rest.UserPurgeResult{}, gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return rest.UserPurgeResult{
+		Username:           username,
+		Pseudonym:          pseudonym,
+		RequestsAnonymized: anonymized,
+	}, nil
+}
+
+// UserRename changes a user's username. Tokens, group memberships, and
+// adapter mappings follow by foreign key cascade; user-layer configurations,
+// option defaults, macros, aliases, locks, and request records, which
+// aren't keyed to the users table, are updated in the same transaction. An
+// error is returned if either name is empty, if the user doesn't exist, if
+// the new name is already taken, or if the user is "admin".
+func (da PostgresDataAccess) UserRename(ctx context.Context, username, newname string) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.UserRename")
+	defer sp.End()
+
+	if username == "" || newname == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	// Thou Shalt Not Rename Admin
+	if username == "admin" {
+		return errs.ErrAdminUndeletable
+	}
+
+	exists, err := da.UserExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrNoSuchUser
+	}
+
+	exists, err = da.UserExists(ctx, newname)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errs.ErrUserExists
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	// As with UserCreate, the new name displaces any deleted user.
+	if err := da.doUserPurgeDeleted(ctx, db, newname); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: false})
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	for _, query := range []string{
+		`UPDATE users SET username=$2 WHERE username=$1;`,
+		`UPDATE configs SET owner=$2 WHERE lower(layer)='user' AND owner=$1;`,
+		`UPDATE option_defaults SET owner=$2 WHERE lower(layer)='user' AND owner=$1;`,
+		`UPDATE macros SET owner=$2 WHERE layer='user' AND owner=$1;`,
+		`UPDATE aliases SET owner=$2 WHERE owner=$1;`,
+		`UPDATE locks SET username=$2 WHERE username=$1;`,
+		`UPDATE commands SET gort_user_name=$2 WHERE gort_user_name=$1;`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, username, newname); err != nil {
+			tx.Rollback()
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	return nil
+}
+
+// UserRestore restores a user that was deleted at or after since, along
+// with their group memberships and adapter mappings. Tokens revoked by the
+// deletion are not restored. An error is returned if the username parameter
+// is empty, or if no such user was deleted in that time.
+func (da PostgresDataAccess) UserRestore(ctx context.Context, username string, since time.Time) error {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(ctx, "postgres.UserRestore")
+	defer sp.End()
+
+	if username == "" {
+		return errs.ErrEmptyUserName
+	}
+
+	db, err := da.pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE users SET deleted_at=NULL
+		WHERE username=$1 AND deleted_at IS NOT NULL AND deleted_at >= $2;`
+	res, err := db.ExecContext(ctx, query, username, since)
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return gerr.Wrap(errs.ErrDataAccess, err)
+	}
+	if n == 0 {
+		return errs.ErrNoSuchUser
+	}
+
+	return nil
+}
+
 // UserRoleList returns a slice of Role values representing the specified
 // user's indirect roles (indirect because users are members of groups,
 // and groups have roles).
@@ -539,7 +803,9 @@ func (da PostgresDataAccess) UserRoleList(ctx context.Context, username string)
 }
 
 // UserUpdate is used to update an existing user. An error is returned if the
-// username is empty or if the user doesn't exist.
+// username is empty, if the user doesn't exist, or if a service account is
+// given a password or mappings. Whether a user is a service account can't be
+// changed.
 func (da PostgresDataAccess) UserUpdate(ctx context.Context, user rest.User) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "postgres.UserUpdate")
@@ -557,25 +823,28 @@ func (da PostgresDataAccess) UserUpdate(ctx context.Context, user rest.User) err
 		return errs.ErrNoSuchUser
 	}
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
 
-	query := `SELECT email, full_name, username, password_hash
+	query := `SELECT email, full_name, username, password_hash, service_account
 		FROM users
 		WHERE username=$1`
 
 	userOld := rest.User{}
-	err = conn.
+	err = db.
 		QueryRowContext(ctx, query, user.Username).
-		Scan(&userOld.Email, &userOld.FullName, &userOld.Username, &userOld.Password)
+		Scan(&userOld.Email, &userOld.FullName, &userOld.Username, &userOld.Password, &userOld.ServiceAccount)
 
 	if err != nil {
 		return gerr.Wrap(errs.ErrNoSuchUser, err)
 	}
 
+	if userOld.ServiceAccount && (user.Password != "" || len(user.Mappings) > 0) {
+		return errs.ErrServiceAccountCredentials
+	}
+
 	if user.Email != "" {
 		userOld.Email = user.Email
 	}
@@ -595,7 +864,7 @@ func (da PostgresDataAccess) UserUpdate(ctx context.Context, user rest.User) err
 	SET email=$1, full_name=$2, password_hash=$3
 	WHERE username=$4;`
 
-	if _, err = conn.ExecContext(ctx, query, userOld.Email, userOld.FullName, userOld.Password, userOld.Username); err != nil {
+	if _, err = db.ExecContext(ctx, query, userOld.Email, userOld.FullName, userOld.Password, userOld.Username); err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
@@ -613,17 +882,16 @@ func (da PostgresDataAccess) doUserGetAdapterIDs(ctx context.Context, username s
 	ctx, sp := tr.Start(ctx, "postgres.doUserGetAdapterIDs")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return nil, gerr.Wrap(errs.ErrDataAccess, err)
 	}
-	defer conn.Close()
 
 	query := `SELECT adapter, id
 		FROM user_adapter_ids
 		WHERE username=$1`
 
-	rows, err := conn.QueryContext(ctx, query, username)
+	rows, err := db.QueryContext(ctx, query, username)
 	if err != nil {
 		return nil, err
 	}
@@ -654,21 +922,31 @@ func (da PostgresDataAccess) doUserUpdateAdapterIDs(ctx context.Context, user re
 	ctx, sp := tr.Start(ctx, "postgres.doUserUpdateAdapterIDs")
 	defer sp.End()
 
-	conn, err := da.connect(ctx)
+	db, err := da.pool(ctx)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
-	defer conn.Close()
 
 	deleteQuery := `DELETE FROM user_adapter_ids WHERE username=$1;`
-	_, err = conn.ExecContext(ctx, deleteQuery, user.Username)
+	_, err = db.ExecContext(ctx, deleteQuery, user.Username)
 	if err != nil {
 		return gerr.Wrap(errs.ErrDataAccess, err)
 	}
 
+	// A mapping still held by a deleted user is released to this one.
+	deleteQuery = `DELETE FROM user_adapter_ids
+		WHERE adapter=$1 AND id=$2 AND username IN (
+			SELECT username FROM users WHERE deleted_at IS NOT NULL
+		);`
+	for adapter, id := range user.Mappings {
+		if _, err := db.ExecContext(ctx, deleteQuery, adapter, id); err != nil {
+			return gerr.Wrap(errs.ErrDataAccess, err)
+		}
+	}
+
 	adapterIDQuery := `INSERT INTO user_adapter_ids (username, adapter, id) VALUES ($1, $2, $3);`
 	for adapter, id := range user.Mappings {
-		if _, err := conn.ExecContext(ctx, adapterIDQuery, user.Username, adapter, id); err != nil {
+		if _, err := db.ExecContext(ctx, adapterIDQuery, user.Username, adapter, id); err != nil {
 			return gerr.Wrap(errs.ErrDataAccess, err)
 		}
 	}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+func (da DataAccessTester) testAliasAccess(t *testing.T) {
+	t.Run("testAliasSave", da.testAliasSave)
+	t.Run("testAliasSaveInvalid", da.testAliasSaveInvalid)
+	t.Run("testAliasDelete", da.testAliasDelete)
+	t.Run("testAliasList", da.testAliasList)
+}
+
+func (da DataAccessTester) testAliasSave(t *testing.T) {
+	alias := data.Alias{
+		Name:    "test-alias-save",
+		Owner:   "test-user",
+		Command: "deploy:status prod",
+	}
+
+	err := da.AliasSave(da.ctx, alias)
+	require.NoError(t, err)
+	defer da.AliasDelete(da.ctx, alias.Owner, alias.Name)
+
+	// Saving it again replaces the command.
+	alias.Command = "deploy:status staging"
+	err = da.AliasSave(da.ctx, alias)
+	require.NoError(t, err)
+
+	list, err := da.AliasList(da.ctx, alias.Name)
+	require.NoError(t, err)
+	assert.Equal(t, []data.Alias{alias}, list)
+}
+
+func (da DataAccessTester) testAliasSaveInvalid(t *testing.T) {
+	valid := data.Alias{
+		Name:    "test-alias-save-invalid",
+		Command: "echo",
+	}
+
+	a := valid
+	a.Name = ""
+	assert.ErrorIs(t, da.AliasSave(da.ctx, a), errs.ErrEmptyAliasName)
+
+	a = valid
+	a.Name = "test alias"
+	assert.ErrorIs(t, da.AliasSave(da.ctx, a), data.ErrBadAlias)
+
+	a = valid
+	a.Command = ""
+	assert.ErrorIs(t, da.AliasSave(da.ctx, a), data.ErrBadAlias)
+}
+
+func (da DataAccessTester) testAliasDelete(t *testing.T) {
+	alias := data.Alias{
+		Name:    "test-alias-delete",
+		Command: "echo",
+	}
+
+	err := da.AliasDelete(da.ctx, alias.Owner, alias.Name)
+	assert.ErrorIs(t, err, errs.ErrNoSuchAlias)
+
+	err = da.AliasSave(da.ctx, alias)
+	require.NoError(t, err)
+
+	err = da.AliasDelete(da.ctx, alias.Owner, alias.Name)
+	assert.NoError(t, err)
+
+	list, err := da.AliasList(da.ctx, alias.Name)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func (da DataAccessTester) testAliasList(t *testing.T) {
+	const name = "test-alias-list"
+
+	aliases := []data.Alias{
+		{Name: name, Owner: "", Command: "echo global"},
+		{Name: name, Owner: "test-user", Command: "echo user"},
+		{Name: name + "-other", Owner: "test-user", Command: "echo other"},
+	}
+
+	for _, a := range aliases {
+		require.NoError(t, da.AliasSave(da.ctx, a))
+		defer da.AliasDelete(da.ctx, a.Owner, a.Name)
+	}
+
+	list, err := da.AliasList(da.ctx, name)
+	require.NoError(t, err)
+	assert.Equal(t, aliases[:2], list)
+
+	list, err = da.AliasList(da.ctx, "")
+	require.NoError(t, err)
+	assert.Subset(t, list, aliases)
+}
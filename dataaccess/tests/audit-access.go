@@ -0,0 +1,107 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (da DataAccessTester) testAuditAccess(t *testing.T) {
+	t.Run("testAuditRecordCreate", da.testAuditRecordCreate)
+	t.Run("testAuditRecordList", da.testAuditRecordList)
+}
+
+func (da DataAccessTester) testAuditRecordCreate(t *testing.T) {
+	record := data.AuditRecord{
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+		User:      "admin",
+		SourceIP:  "10.0.0.1",
+		Method:    http.MethodPut,
+		Path:      "/v2/users/test-audit-create",
+		Kind:      "user",
+		Target:    "test-audit-create",
+		Status:    http.StatusOK,
+		After:     json.RawMessage(`{"username":"test-audit-create"}`),
+	}
+
+	require.NoError(t, da.AuditRecordCreate(da.ctx, &record))
+	assert.NotZero(t, record.ID)
+
+	list, err := da.AuditRecordList(da.ctx, data.AuditFilter{Kind: "user", Target: "test-audit-create"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	assert.Equal(t, record.ID, list[0].ID)
+	assert.True(t, record.Timestamp.Equal(list[0].Timestamp))
+	assert.Equal(t, record.User, list[0].User)
+	assert.Equal(t, record.SourceIP, list[0].SourceIP)
+	assert.Equal(t, record.Method, list[0].Method)
+	assert.Equal(t, record.Path, list[0].Path)
+	assert.Equal(t, record.Status, list[0].Status)
+	assert.Empty(t, list[0].Before)
+	assert.JSONEq(t, string(record.After), string(list[0].After))
+}
+
+func (da DataAccessTester) testAuditRecordList(t *testing.T) {
+	const target = "test-audit-list"
+	start := time.Now().UTC().Truncate(time.Second)
+
+	for i, user := range []string{"alice", "bob", "alice"} {
+		record := data.AuditRecord{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			User:      user,
+			Method:    http.MethodPut,
+			Kind:      "group",
+			Target:    target,
+			Status:    http.StatusOK,
+		}
+		require.NoError(t, da.AuditRecordCreate(da.ctx, &record))
+	}
+
+	list, err := da.AuditRecordList(da.ctx, data.AuditFilter{Target: target})
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.True(t, list[0].ID > list[1].ID && list[1].ID > list[2].ID, "records aren't newest first")
+
+	list, err = da.AuditRecordList(da.ctx, data.AuditFilter{Target: target, User: "alice"})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	list, err = da.AuditRecordList(da.ctx, data.AuditFilter{Target: target, Since: start.Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	list, err = da.AuditRecordList(da.ctx, data.AuditFilter{Target: target, Until: start.Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	list, err = da.AuditRecordList(da.ctx, data.AuditFilter{Target: target, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "alice", list[0].User)
+
+	list, err = da.AuditRecordList(da.ctx, data.AuditFilter{Target: target, Kind: "user"})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
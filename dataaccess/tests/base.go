@@ -36,11 +36,23 @@ func NewDataAccessTester(ctx context.Context, cancel context.CancelFunc, da Test
 }
 
 func (da DataAccessTester) RunAllTests(t *testing.T) {
+	t.Run("testAliasAccess", da.testAliasAccess)
+	t.Run("testAuditAccess", da.testAuditAccess)
+	t.Run("testCostAccess", da.testCostAccess)
+	t.Run("testDeadLetterAccess", da.testDeadLetterAccess)
 	t.Run("testUserAccess", da.testUserAccess)
 	t.Run("testGroupAccess", da.testGroupAccess)
+	t.Run("testDeletedAccess", da.testDeletedAccess)
 	t.Run("testTokenAccess", da.testTokenAccess)
 	t.Run("testBundleAccess", da.testBundleAccess)
+	t.Run("testChannelPresenceAccess", da.testChannelPresenceAccess)
+	t.Run("testChannelSettingsAccess", da.testChannelSettingsAccess)
 	t.Run("testRoleAccess", da.testRoleAccess)
+	t.Run("testSecretOutputAccess", da.testSecretOutputAccess)
 	t.Run("testRequestAccess", da.testRequestAccess)
 	t.Run("testDynamicConfigurationAccess", da.testDynamicConfigurationAccess)
+	t.Run("testLockAccess", da.testLockAccess)
+	t.Run("testMacroAccess", da.testMacroAccess)
+	t.Run("testOptionDefaultAccess", da.testOptionDefaultAccess)
+	t.Run("testOutboxAccess", da.testOutboxAccess)
 }
@@ -19,6 +19,7 @@ package tests
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/getgort/gort/bundles"
 	"github.com/getgort/gort/data"
@@ -42,6 +43,9 @@ func (da DataAccessTester) testBundleAccess(t *testing.T) {
 	t.Run("testBundleImageConsistency", da.testBundleImageConsistency)
 	t.Run("testBundleList", da.testBundleList)
 	t.Run("testBundleVersionList", da.testBundleVersionList)
+	t.Run("testBundleListMatchesGet", da.testBundleListMatchesGet)
+	t.Run("testBundleReviewUpdate", da.testBundleReviewUpdate)
+	t.Run("testBundleUpgrade", da.testBundleUpgrade)
 	t.Run("testFindCommandEntry", da.testFindCommandEntry)
 }
 
@@ -403,6 +407,143 @@ func (da DataAccessTester) testBundleVersionList(t *testing.T) {
 	require.Len(t, bundles, 2)
 }
 
+// testBundleListMatchesGet verifies that the bundles returned by BundleList
+// and BundleVersionList are identical to those returned by BundleGet.
+func (da DataAccessTester) testBundleListMatchesGet(t *testing.T) {
+	const name = "test-list-get"
+
+	for _, version := range []string{"0.0.1", "0.0.2"} {
+		b, err := getTestBundle()
+		require.NoError(t, err)
+
+		b.Name = name
+		b.Version = version
+
+		require.NoError(t, da.BundleCreate(da.ctx, b))
+		defer da.BundleDelete(da.ctx, name, version)
+	}
+
+	require.NoError(t, da.BundleEnable(da.ctx, name, "0.0.2"))
+	defer da.BundleDisable(da.ctx, name, "0.0.2")
+
+	list, err := da.BundleList(da.ctx)
+	require.NoError(t, err)
+
+	versions, err := da.BundleVersionList(da.ctx, name)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	listed := 0
+	for _, b := range list {
+		if b.Name != name {
+			continue
+		}
+		listed++
+
+		get, err := da.BundleGet(da.ctx, name, b.Version)
+		require.NoError(t, err)
+		assert.Equal(t, get, b)
+	}
+	assert.Equal(t, 2, listed)
+
+	for _, b := range versions {
+		get, err := da.BundleGet(da.ctx, name, b.Version)
+		require.NoError(t, err)
+		assert.Equal(t, get, b)
+		assert.Equal(t, b.Version == "0.0.2", b.Enabled)
+	}
+}
+
+func (da DataAccessTester) testBundleReviewUpdate(t *testing.T) {
+	err := da.BundleReviewUpdate(da.ctx, "test-review", "0.0.1", data.BundleReview{Status: data.BundleReviewApproved})
+	require.Error(t, err, errs.ErrNoSuchBundle)
+
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+	bundle.Name = "test-review"
+	bundle.InstalledBy = "installer"
+	bundle.Review = data.BundleReview{Status: data.BundleReviewPending}
+
+	require.NoError(t, da.BundleCreate(da.ctx, bundle))
+	defer da.BundleDelete(da.ctx, bundle.Name, bundle.Version)
+
+	got, err := da.BundleGet(da.ctx, bundle.Name, bundle.Version)
+	require.NoError(t, err)
+	assert.Equal(t, data.BundleReview{Status: data.BundleReviewPending}, got.Review)
+	assert.False(t, got.Review.Enableable())
+
+	review := data.BundleReview{
+		Status:     data.BundleReviewApproved,
+		Reviewer:   "reviewer",
+		ReviewedOn: time.Now().UTC().Truncate(time.Second),
+		Comment:    "looks good",
+	}
+	require.NoError(t, da.BundleReviewUpdate(da.ctx, bundle.Name, bundle.Version, review))
+
+	got, err = da.BundleGet(da.ctx, bundle.Name, bundle.Version)
+	require.NoError(t, err)
+	assert.Equal(t, review.Status, got.Review.Status)
+	assert.Equal(t, review.Reviewer, got.Review.Reviewer)
+	assert.Equal(t, review.Comment, got.Review.Comment)
+	assert.True(t, review.ReviewedOn.Equal(got.Review.ReviewedOn))
+	assert.Equal(t, "installer", got.InstalledBy)
+	assert.True(t, got.Review.Enableable())
+}
+
+func (da DataAccessTester) testBundleUpgrade(t *testing.T) {
+	const name = "test-upgrade"
+
+	for _, v := range []string{"0.1.0", "0.2.0", "0.3.0"} {
+		b, err := getTestBundle()
+		require.NoError(t, err)
+		b.Name = name
+		b.Version = v
+
+		require.NoError(t, da.BundleCreate(da.ctx, b))
+		defer da.BundleDelete(da.ctx, name, v)
+	}
+	require.NoError(t, da.BundleEnable(da.ctx, name, "0.1.0"))
+
+	b, err := getTestBundle()
+	require.NoError(t, err)
+	b.Name = name
+	b.Version = "0.4.0"
+	defer da.BundleDelete(da.ctx, name, b.Version)
+
+	// Upgrade, enable, and keep the two newest versions. The previously
+	// enabled version is older than both, so it's pruned.
+	result, err := da.BundleUpgrade(da.ctx, b, true, 2)
+	require.NoError(t, err)
+	assert.True(t, result.Installed)
+	assert.True(t, result.Enabled)
+	assert.Equal(t, "0.1.0", result.PreviousEnabled)
+	assert.ElementsMatch(t, []string{"0.1.0", "0.2.0"}, result.Pruned)
+
+	enabled, err := da.BundleEnabledVersion(da.ctx, name)
+	require.NoError(t, err)
+	assert.Equal(t, b.Version, enabled)
+
+	versions, err := da.BundleVersionList(da.ctx, name)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	// Repeating the upgrade is a no-op.
+	result, err = da.BundleUpgrade(da.ctx, b, true, 2)
+	require.NoError(t, err)
+	assert.False(t, result.Installed)
+	assert.True(t, result.Enabled)
+	assert.Empty(t, result.Pruned)
+
+	// Upgrading to an older version is an error, and changes nothing.
+	b.Version = "0.3.5"
+	_, err = da.BundleUpgrade(da.ctx, b, true, 1)
+	assert.ErrorIs(t, err, errs.ErrBundleVersionOlder)
+
+	versions, err = da.BundleVersionList(da.ctx, name)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+}
+
 func (da DataAccessTester) testFindCommandEntry(t *testing.T) {
 	const BundleName = "test"
 	const BundleVersion = "0.0.1"
@@ -451,6 +592,7 @@ func (da DataAccessTester) testFindCommandEntry(t *testing.T) {
 	assert.Equal(t, tc.LongDescription, cmd.LongDescription)
 	assert.Equal(t, tc.Executable, cmd.Executable)
 	assert.Equal(t, tc.Name, cmd.Name)
+	assert.Equal(t, tc.Options, cmd.Options)
 	assert.Equal(t, tc.Rules, cmd.Rules)
 	assert.Equal(t, tc.Triggers, cmd.Triggers)
 }
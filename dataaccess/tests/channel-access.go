@@ -0,0 +1,142 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+func (da DataAccessTester) testChannelPresenceAccess(t *testing.T) {
+	t.Run("testChannelPresenceMarkGreeted", da.testChannelPresenceMarkGreeted)
+	t.Run("testChannelPresenceMarkActive", da.testChannelPresenceMarkActive)
+	t.Run("testChannelPresenceDelete", da.testChannelPresenceDelete)
+}
+
+func (da DataAccessTester) testChannelPresenceMarkGreeted(t *testing.T) {
+	const adapter = "test-presence-greeted"
+	defer da.ChannelPresenceDelete(da.ctx, adapter, "C002")
+	defer da.ChannelPresenceDelete(da.ctx, adapter, "C001")
+
+	list, err := da.ChannelPresenceList(da.ctx, adapter)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, da.ChannelPresenceMarkGreeted(da.ctx, adapter, "C002"))
+	require.NoError(t, da.ChannelPresenceMarkGreeted(da.ctx, adapter, "C001"))
+
+	list, err = da.ChannelPresenceList(da.ctx, adapter)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	assert.Equal(t, "C001", list[0].ChannelID)
+	assert.Equal(t, adapter, list[0].Adapter)
+	assert.False(t, list[0].FirstSeen.IsZero())
+	assert.False(t, list[0].GreetedAt.IsZero())
+	assert.True(t, list[0].LastCommandAt.IsZero())
+	assert.Equal(t, list[0].FirstSeen, list[0].LastActivity())
+}
+
+func (da DataAccessTester) testChannelPresenceMarkActive(t *testing.T) {
+	const adapter = "test-presence-active"
+	defer da.ChannelPresenceDelete(da.ctx, adapter, "C001")
+
+	require.NoError(t, da.ChannelPresenceMarkGreeted(da.ctx, adapter, "C001"))
+	require.NoError(t, da.ChannelPresenceMarkActive(da.ctx, adapter, "C001"))
+
+	list, err := da.ChannelPresenceList(da.ctx, adapter)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	// Marking the channel active doesn't reset its greeting.
+	assert.False(t, list[0].GreetedAt.IsZero())
+	assert.False(t, list[0].LastCommandAt.IsZero())
+	assert.Equal(t, list[0].LastCommandAt, list[0].LastActivity())
+}
+
+func (da DataAccessTester) testChannelPresenceDelete(t *testing.T) {
+	const adapter = "test-presence-delete"
+
+	require.NoError(t, da.ChannelPresenceMarkActive(da.ctx, adapter, "C001"))
+	require.NoError(t, da.ChannelPresenceDelete(da.ctx, adapter, "C001"))
+
+	list, err := da.ChannelPresenceList(da.ctx, adapter)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// Deleting a channel with no record isn't an error.
+	assert.NoError(t, da.ChannelPresenceDelete(da.ctx, adapter, "C001"))
+}
+
+func (da DataAccessTester) testChannelSettingsAccess(t *testing.T) {
+	t.Run("testChannelSettingsSet", da.testChannelSettingsSet)
+	t.Run("testChannelSettingsDelete", da.testChannelSettingsDelete)
+}
+
+func (da DataAccessTester) testChannelSettingsSet(t *testing.T) {
+	const adapter = "test-settings-set"
+	defer da.ChannelSettingsDelete(da.ctx, adapter, "C001")
+
+	// Channels without settings get zero-value settings.
+	settings, err := da.ChannelSettingsGet(da.ctx, adapter, "C001")
+	require.NoError(t, err)
+	assert.Equal(t, data.ChannelSettings{Adapter: adapter, ChannelID: "C001"}, settings)
+
+	require.NoError(t, da.ChannelSettingsSet(da.ctx, data.ChannelSettings{Adapter: adapter, ChannelID: "C001", DefaultBundle: "aws"}))
+
+	settings, err = da.ChannelSettingsGet(da.ctx, adapter, "C001")
+	require.NoError(t, err)
+	assert.Equal(t, "aws", settings.DefaultBundle)
+
+	// Setting again replaces the settings.
+	require.NoError(t, da.ChannelSettingsSet(da.ctx, data.ChannelSettings{Adapter: adapter, ChannelID: "C001", DefaultBundle: "gcp"}))
+
+	settings, err = da.ChannelSettingsGet(da.ctx, adapter, "C001")
+	require.NoError(t, err)
+	assert.Equal(t, "gcp", settings.DefaultBundle)
+	assert.Nil(t, settings.QuietHours)
+
+	quiet := &data.QuietHours{Start: "22:00", End: "07:00", Timezone: "Europe/London", Digest: true}
+	require.NoError(t, da.ChannelSettingsSet(da.ctx, data.ChannelSettings{Adapter: adapter, ChannelID: "C001", DefaultBundle: "gcp", QuietHours: quiet}))
+
+	settings, err = da.ChannelSettingsGet(da.ctx, adapter, "C001")
+	require.NoError(t, err)
+	assert.Equal(t, "gcp", settings.DefaultBundle)
+	assert.Equal(t, quiet, settings.QuietHours)
+
+	err = da.ChannelSettingsSet(da.ctx, data.ChannelSettings{Adapter: adapter, DefaultBundle: "aws"})
+	assert.ErrorIs(t, err, errs.ErrEmptyChannel)
+}
+
+func (da DataAccessTester) testChannelSettingsDelete(t *testing.T) {
+	const adapter = "test-settings-delete"
+
+	require.NoError(t, da.ChannelSettingsSet(da.ctx, data.ChannelSettings{Adapter: adapter, ChannelID: "C001", DefaultBundle: "aws"}))
+	require.NoError(t, da.ChannelSettingsDelete(da.ctx, adapter, "C001"))
+
+	settings, err := da.ChannelSettingsGet(da.ctx, adapter, "C001")
+	require.NoError(t, err)
+	assert.Empty(t, settings.DefaultBundle)
+
+	// Deleting a channel with no settings isn't an error.
+	assert.NoError(t, da.ChannelSettingsDelete(da.ctx, adapter, "C001"))
+}
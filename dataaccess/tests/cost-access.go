@@ -0,0 +1,79 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/getgort/gort/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (da DataAccessTester) testCostAccess(t *testing.T) {
+	t.Run("testCostAdd", da.testCostAdd)
+}
+
+func (da DataAccessTester) testCostAdd(t *testing.T) {
+	r := data.CostRecord{
+		Month:            "1999-12",
+		Bundle:           "test-cost",
+		Group:            "ops",
+		Executions:       1,
+		Seconds:          2,
+		CPUSeconds:       1,
+		MemoryGiBSeconds: 0.5,
+		Cost:             0.25,
+	}
+
+	require.NoError(t, da.CostAdd(da.ctx, r))
+	require.NoError(t, da.CostAdd(da.ctx, r))
+
+	other := r
+	other.Group = "dev"
+	require.NoError(t, da.CostAdd(da.ctx, other))
+
+	elsewhen := r
+	elsewhen.Month = "1999-11"
+	require.NoError(t, da.CostAdd(da.ctx, elsewhen))
+
+	list, err := da.CostList(da.ctx, "1999-12")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	// Ordered by month, bundle, then group.
+	assert.Equal(t, "dev", list[0].Group)
+	assert.Equal(t, int64(1), list[0].Executions)
+
+	assert.Equal(t, "ops", list[1].Group)
+	assert.Equal(t, int64(2), list[1].Executions)
+	assert.InDelta(t, 4.0, list[1].Seconds, 0.0001)
+	assert.InDelta(t, 2.0, list[1].CPUSeconds, 0.0001)
+	assert.InDelta(t, 1.0, list[1].MemoryGiBSeconds, 0.0001)
+	assert.InDelta(t, 0.5, list[1].Cost, 0.0001)
+
+	list, err = da.CostList(da.ctx, "")
+	require.NoError(t, err)
+
+	var months []string
+	for _, r := range list {
+		if r.Bundle == "test-cost" {
+			months = append(months, r.Month)
+		}
+	}
+	assert.Equal(t, []string{"1999-11", "1999-12", "1999-12"}, months)
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (da DataAccessTester) testDeadLetterAccess(t *testing.T) {
+	t.Run("testDeadLetterCreate", da.testDeadLetterCreate)
+	t.Run("testDeadLetterDelete", da.testDeadLetterDelete)
+	t.Run("testDeadLetterList", da.testDeadLetterList)
+	t.Run("testDeadLetterPurge", da.testDeadLetterPurge)
+	t.Run("testDeadLetterUpdate", da.testDeadLetterUpdate)
+}
+
+func (da DataAccessTester) testDeadLetterCreate(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	letter := data.DeadLetter{
+		RequestID:   42,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-CREATE",
+		Message:     "hello, world",
+		Error:       "channel_is_archived",
+		Attempts:    1,
+		Created:     now,
+		LastAttempt: now,
+	}
+
+	require.NoError(t, da.DeadLetterCreate(da.ctx, &letter))
+	assert.NotZero(t, letter.ID)
+	defer da.DeadLetterDelete(da.ctx, letter.ID)
+
+	got, err := da.DeadLetterGet(da.ctx, letter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, letter.ID, got.ID)
+	assert.Equal(t, letter.ChannelID, got.ChannelID)
+	assert.Equal(t, letter.Message, got.Message)
+	assert.Equal(t, letter.Error, got.Error)
+	assert.True(t, letter.Created.Equal(got.Created))
+
+	_, err = da.DeadLetterGet(da.ctx, -1)
+	assert.ErrorIs(t, err, errs.ErrNoSuchDeadLetter)
+}
+
+func (da DataAccessTester) testDeadLetterDelete(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	letter := data.DeadLetter{
+		RequestID:   42,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-DELETE",
+		Message:     "hello, world",
+		Error:       "channel_is_archived",
+		Attempts:    1,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.DeadLetterCreate(da.ctx, &letter))
+
+	require.NoError(t, da.DeadLetterDelete(da.ctx, letter.ID))
+
+	_, err := da.DeadLetterGet(da.ctx, letter.ID)
+	assert.ErrorIs(t, err, errs.ErrNoSuchDeadLetter)
+
+	err = da.DeadLetterDelete(da.ctx, letter.ID)
+	assert.ErrorIs(t, err, errs.ErrNoSuchDeadLetter)
+}
+
+func (da DataAccessTester) testDeadLetterList(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	first := data.DeadLetter{
+		RequestID:   42,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-LIST-1",
+		Message:     "hello, world",
+		Error:       "channel_is_archived",
+		Attempts:    1,
+		Created:     now,
+		LastAttempt: now,
+	}
+	second := data.DeadLetter{
+		RequestID:   42,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-LIST-2",
+		Message:     "hello, world",
+		Error:       "channel_is_archived",
+		Attempts:    1,
+		Created:     now,
+		LastAttempt: now,
+	}
+
+	require.NoError(t, da.DeadLetterCreate(da.ctx, &first))
+	defer da.DeadLetterDelete(da.ctx, first.ID)
+	require.NoError(t, da.DeadLetterCreate(da.ctx, &second))
+	defer da.DeadLetterDelete(da.ctx, second.ID)
+
+	list, err := da.DeadLetterList(da.ctx)
+	require.NoError(t, err)
+
+	var ids []int64
+	for _, l := range list {
+		ids = append(ids, l.ID)
+	}
+
+	// Oldest first.
+	require.Contains(t, ids, first.ID)
+	require.Contains(t, ids, second.ID)
+	assert.Less(t, indexOf(ids, first.ID), indexOf(ids, second.ID))
+}
+
+func (da DataAccessTester) testDeadLetterPurge(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	old := data.DeadLetter{
+		RequestID:   42,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-PURGE-OLD",
+		Message:     "hello, world",
+		Error:       "channel_is_archived",
+		Attempts:    1,
+		Created:     now.Add(-48 * time.Hour),
+		LastAttempt: now.Add(-48 * time.Hour),
+	}
+	require.NoError(t, da.DeadLetterCreate(da.ctx, &old))
+	defer da.DeadLetterDelete(da.ctx, old.ID)
+
+	recent := data.DeadLetter{
+		RequestID:   42,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-PURGE-RECENT",
+		Message:     "hello, world",
+		Error:       "channel_is_archived",
+		Attempts:    1,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.DeadLetterCreate(da.ctx, &recent))
+	defer da.DeadLetterDelete(da.ctx, recent.ID)
+
+	n, err := da.DeadLetterPurge(da.ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = da.DeadLetterGet(da.ctx, old.ID)
+	assert.ErrorIs(t, err, errs.ErrNoSuchDeadLetter)
+
+	_, err = da.DeadLetterGet(da.ctx, recent.ID)
+	assert.NoError(t, err)
+}
+
+func (da DataAccessTester) testDeadLetterUpdate(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	letter := data.DeadLetter{
+		RequestID:   42,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-UPDATE",
+		Message:     "hello, world",
+		Error:       "channel_is_archived",
+		Attempts:    1,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.DeadLetterCreate(da.ctx, &letter))
+	defer da.DeadLetterDelete(da.ctx, letter.ID)
+
+	letter.Attempts = 2
+	letter.Error = "not_in_channel"
+	letter.LastAttempt = letter.LastAttempt.Add(time.Minute)
+	letter.Message = "ignored"
+	require.NoError(t, da.DeadLetterUpdate(da.ctx, letter))
+
+	got, err := da.DeadLetterGet(da.ctx, letter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Attempts)
+	assert.Equal(t, "not_in_channel", got.Error)
+	assert.True(t, letter.LastAttempt.Equal(got.LastAttempt))
+	assert.Equal(t, "hello, world", got.Message)
+
+	letter.ID = -1
+	assert.ErrorIs(t, da.DeadLetterUpdate(da.ctx, letter), errs.ErrNoSuchDeadLetter)
+}
+
+func indexOf(ids []int64, id int64) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
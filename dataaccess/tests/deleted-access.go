@@ -0,0 +1,60 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (da DataAccessTester) testDeletedAccess(t *testing.T) {
+	t.Run("testDeletedPurge", da.testDeletedPurge)
+}
+
+func (da DataAccessTester) testDeletedPurge(t *testing.T) {
+	const groupname = "group-test-deleted-purge"
+	const username = "user-test-deleted-purge"
+
+	require.NoError(t, da.GroupCreate(da.ctx, rest.Group{Name: groupname}))
+	require.NoError(t, da.UserCreate(da.ctx, rest.User{Username: username}))
+	require.NoError(t, da.GroupUserAdd(da.ctx, groupname, username))
+
+	deleted := time.Now().Add(-time.Minute)
+	require.NoError(t, da.UserDelete(da.ctx, username))
+	require.NoError(t, da.GroupDelete(da.ctx, groupname))
+
+	// Nothing was deleted before this, so nothing is purged
+	n, err := da.DeletedPurge(da.ctx, deleted)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// Other tests' deletions are purged too, so there may be more than two
+	n, err = da.DeletedPurge(da.ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 2)
+
+	err = da.UserRestore(da.ctx, username, deleted)
+	assert.ErrorIs(t, err, errs.ErrNoSuchUser)
+
+	err = da.GroupRestore(da.ctx, groupname, deleted)
+	assert.ErrorIs(t, err, errs.ErrNoSuchGroup)
+}
@@ -18,6 +18,7 @@ package tests
 
 import (
 	"testing"
+	"time"
 
 	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/dataaccess/errs"
@@ -36,6 +37,7 @@ func (da DataAccessTester) testGroupAccess(t *testing.T) {
 	t.Run("testGroupRoleAdd", da.testGroupRoleAdd)
 	t.Run("testGroupPermissionList", da.testGroupPermissionList)
 	t.Run("testGroupList", da.testGroupList)
+	t.Run("testGroupRestore", da.testGroupRestore)
 	t.Run("testGroupRoleList", da.testGroupRoleList)
 	t.Run("testGroupUserDelete", da.testGroupUserDelete)
 }
@@ -369,3 +371,57 @@ func (da DataAccessTester) testGroupUserDelete(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func (da DataAccessTester) testGroupRestore(t *testing.T) {
+	const groupname = "group-test-group-restore"
+	const rolename = "role-test-group-restore"
+	const username = "user-test-group-restore"
+
+	err := da.GroupRestore(da.ctx, "", time.Time{})
+	assert.ErrorIs(t, err, errs.ErrEmptyGroupName)
+
+	// Restore group that was never deleted
+	err = da.GroupRestore(da.ctx, "no-such-group", time.Time{})
+	assert.ErrorIs(t, err, errs.ErrNoSuchGroup)
+
+	require.NoError(t, da.GroupCreate(da.ctx, rest.Group{Name: groupname}))
+	defer da.GroupDelete(da.ctx, groupname)
+	require.NoError(t, da.UserCreate(da.ctx, rest.User{Username: username}))
+	defer da.UserDelete(da.ctx, username)
+	require.NoError(t, da.RoleCreate(da.ctx, rolename))
+	defer da.RoleDelete(da.ctx, rolename)
+
+	require.NoError(t, da.GroupUserAdd(da.ctx, groupname, username))
+	require.NoError(t, da.GroupRoleAdd(da.ctx, groupname, rolename))
+
+	deleted := time.Now().Add(-time.Minute)
+	require.NoError(t, da.GroupDelete(da.ctx, groupname))
+
+	exists, err := da.GroupExists(da.ctx, groupname)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	groups, err := da.UserGroupList(da.ctx, username)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	groups, err = da.RoleGroupList(da.ctx, rolename)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	// Deleted before the retention window
+	err = da.GroupRestore(da.ctx, groupname, time.Now().Add(time.Minute))
+	assert.ErrorIs(t, err, errs.ErrNoSuchGroup)
+
+	require.NoError(t, da.GroupRestore(da.ctx, groupname, deleted))
+
+	members, err := da.GroupUserList(da.ctx, groupname)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, username, members[0].Username)
+
+	roles, err := da.GroupRoleList(da.ctx, groupname)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, rolename, roles[0].Name)
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+func (da DataAccessTester) testLockAccess(t *testing.T) {
+	t.Run("testLockAcquire", da.testLockAcquire)
+	t.Run("testLockAcquireExpired", da.testLockAcquireExpired)
+	t.Run("testLockGet", da.testLockGet)
+	t.Run("testLockPurge", da.testLockPurge)
+	t.Run("testLockRelease", da.testLockRelease)
+	t.Run("testLockRenew", da.testLockRenew)
+}
+
+func (da DataAccessTester) testLockAcquire(t *testing.T) {
+	lock, err := da.LockAcquire(da.ctx, "test-acquire", "owner-1", "user1", time.Minute)
+	defer da.LockRelease(da.ctx, "test-acquire", "owner-1")
+	require.NoError(t, err)
+	assert.Equal(t, "test-acquire", lock.Name)
+	assert.Equal(t, "owner-1", lock.Owner)
+	assert.Equal(t, "user1", lock.UserName)
+
+	// Re-acquiring by the same owner is allowed.
+	_, err = da.LockAcquire(da.ctx, "test-acquire", "owner-1", "user1", time.Minute)
+	assert.NoError(t, err)
+
+	// A different owner can't acquire a held lock, and gets the holder info.
+	held, err := da.LockAcquire(da.ctx, "test-acquire", "owner-2", "user2", time.Minute)
+	assert.ErrorIs(t, err, errs.ErrLockHeld)
+	assert.Equal(t, "owner-1", held.Owner)
+	assert.Equal(t, "user1", held.UserName)
+}
+
+func (da DataAccessTester) testLockAcquireExpired(t *testing.T) {
+	_, err := da.LockAcquire(da.ctx, "test-acquire-expired", "owner-1", "user1", time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	lock, err := da.LockAcquire(da.ctx, "test-acquire-expired", "owner-2", "user2", time.Minute)
+	defer da.LockRelease(da.ctx, "test-acquire-expired", "owner-2")
+	require.NoError(t, err)
+	assert.Equal(t, "owner-2", lock.Owner)
+}
+
+func (da DataAccessTester) testLockGet(t *testing.T) {
+	_, err := da.LockGet(da.ctx, "test-get")
+	assert.ErrorIs(t, err, errs.ErrNoSuchLock)
+
+	_, err = da.LockAcquire(da.ctx, "test-get", "owner-1", "user1", time.Minute)
+	defer da.LockRelease(da.ctx, "test-get", "owner-1")
+	require.NoError(t, err)
+
+	lock, err := da.LockGet(da.ctx, "test-get")
+	require.NoError(t, err)
+	assert.Equal(t, "owner-1", lock.Owner)
+}
+
+func (da DataAccessTester) testLockPurge(t *testing.T) {
+	_, err := da.LockAcquire(da.ctx, "test-purge-expired", "owner-1", "user1", time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = da.LockAcquire(da.ctx, "test-purge-held", "owner-1", "user1", time.Minute)
+	defer da.LockRelease(da.ctx, "test-purge-held", "owner-1")
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	n, err := da.LockPurge(da.ctx, time.Now())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+
+	_, err = da.LockGet(da.ctx, "test-purge-expired")
+	assert.ErrorIs(t, err, errs.ErrNoSuchLock)
+
+	_, err = da.LockGet(da.ctx, "test-purge-held")
+	assert.NoError(t, err)
+}
+
+func (da DataAccessTester) testLockRelease(t *testing.T) {
+	err := da.LockRelease(da.ctx, "test-release", "owner-1")
+	assert.ErrorIs(t, err, errs.ErrNoSuchLock)
+
+	_, err = da.LockAcquire(da.ctx, "test-release", "owner-1", "user1", time.Minute)
+	require.NoError(t, err)
+
+	// Only the owner can release a lock.
+	err = da.LockRelease(da.ctx, "test-release", "owner-2")
+	assert.ErrorIs(t, err, errs.ErrNoSuchLock)
+
+	err = da.LockRelease(da.ctx, "test-release", "owner-1")
+	assert.NoError(t, err)
+
+	_, err = da.LockGet(da.ctx, "test-release")
+	assert.ErrorIs(t, err, errs.ErrNoSuchLock)
+}
+
+func (da DataAccessTester) testLockRenew(t *testing.T) {
+	_, err := da.LockRenew(da.ctx, "test-renew", "owner-1", time.Minute)
+	assert.ErrorIs(t, err, errs.ErrNoSuchLock)
+
+	lock, err := da.LockAcquire(da.ctx, "test-renew", "owner-1", "user1", time.Minute)
+	defer da.LockRelease(da.ctx, "test-renew", "owner-1")
+	require.NoError(t, err)
+
+	renewed, err := da.LockRenew(da.ctx, "test-renew", "owner-1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, renewed.ExpiresAt.After(lock.ExpiresAt))
+
+	_, err = da.LockRenew(da.ctx, "test-renew", "owner-2", time.Hour)
+	assert.ErrorIs(t, err, errs.ErrNoSuchLock)
+}
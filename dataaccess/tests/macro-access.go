@@ -0,0 +1,127 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+func (da DataAccessTester) testMacroAccess(t *testing.T) {
+	t.Run("testMacroSave", da.testMacroSave)
+	t.Run("testMacroSaveInvalid", da.testMacroSaveInvalid)
+	t.Run("testMacroDelete", da.testMacroDelete)
+	t.Run("testMacroList", da.testMacroList)
+}
+
+func (da DataAccessTester) testMacroSave(t *testing.T) {
+	macro := data.Macro{
+		Name:    "test-macro-save",
+		Layer:   data.LayerUser,
+		Owner:   "test-user",
+		Command: "kubectl:rollout restart deploy/{{.svc}}",
+	}
+
+	err := da.MacroSave(da.ctx, macro)
+	require.NoError(t, err)
+	defer da.MacroDelete(da.ctx, macro.Layer, macro.Owner, macro.Name)
+
+	// Saving it again replaces the command.
+	macro.Command = "kubectl:rollout restart deploy/{{.svc}} -n {{.ns}}"
+	err = da.MacroSave(da.ctx, macro)
+	require.NoError(t, err)
+
+	list, err := da.MacroList(da.ctx, macro.Name)
+	require.NoError(t, err)
+	assert.Equal(t, []data.Macro{macro}, list)
+}
+
+func (da DataAccessTester) testMacroSaveInvalid(t *testing.T) {
+	valid := data.Macro{
+		Name:    "test-macro-save-invalid",
+		Layer:   data.LayerGroup,
+		Owner:   "ops",
+		Command: "echo",
+	}
+
+	m := valid
+	m.Name = ""
+	assert.ErrorIs(t, da.MacroSave(da.ctx, m), errs.ErrEmptyMacroName)
+
+	m = valid
+	m.Owner = ""
+	assert.ErrorIs(t, da.MacroSave(da.ctx, m), errs.ErrEmptyMacroOwner)
+
+	m = valid
+	m.Layer = data.LayerRoom
+	assert.Error(t, da.MacroSave(da.ctx, m))
+
+	m = valid
+	m.Command = "echo {{.msg"
+	assert.ErrorIs(t, da.MacroSave(da.ctx, m), data.ErrBadMacro)
+}
+
+func (da DataAccessTester) testMacroDelete(t *testing.T) {
+	macro := data.Macro{
+		Name:    "test-macro-delete",
+		Layer:   data.LayerGroup,
+		Owner:   "ops",
+		Command: "echo",
+	}
+
+	err := da.MacroDelete(da.ctx, macro.Layer, macro.Owner, macro.Name)
+	assert.ErrorIs(t, err, errs.ErrNoSuchMacro)
+
+	err = da.MacroSave(da.ctx, macro)
+	require.NoError(t, err)
+
+	err = da.MacroDelete(da.ctx, macro.Layer, macro.Owner, macro.Name)
+	assert.NoError(t, err)
+
+	list, err := da.MacroList(da.ctx, macro.Name)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func (da DataAccessTester) testMacroList(t *testing.T) {
+	const name = "test-macro-list"
+
+	macros := []data.Macro{
+		{Name: name, Layer: data.LayerGroup, Owner: "dev", Command: "echo dev"},
+		{Name: name, Layer: data.LayerGroup, Owner: "ops", Command: "echo ops"},
+		{Name: name, Layer: data.LayerUser, Owner: "test-user", Command: "echo user"},
+		{Name: name + "-other", Layer: data.LayerUser, Owner: "test-user", Command: "echo other"},
+	}
+
+	for _, m := range macros {
+		require.NoError(t, da.MacroSave(da.ctx, m))
+		defer da.MacroDelete(da.ctx, m.Layer, m.Owner, m.Name)
+	}
+
+	list, err := da.MacroList(da.ctx, name)
+	require.NoError(t, err)
+	assert.Equal(t, macros[:3], list)
+
+	list, err = da.MacroList(da.ctx, "")
+	require.NoError(t, err)
+	assert.Subset(t, list, macros)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+func (da DataAccessTester) testOptionDefaultAccess(t *testing.T) {
+	t.Run("testOptionDefaultSet", da.testOptionDefaultSet)
+	t.Run("testOptionDefaultSetInvalid", da.testOptionDefaultSetInvalid)
+	t.Run("testOptionDefaultDelete", da.testOptionDefaultDelete)
+	t.Run("testOptionDefaultList", da.testOptionDefaultList)
+}
+
+func (da DataAccessTester) testOptionDefaultSet(t *testing.T) {
+	def := data.OptionDefault{
+		Bundle:  "test-default-set",
+		Command: "echo",
+		Layer:   data.LayerGroup,
+		Owner:   "eu-ops",
+		Option:  "region",
+		Value:   "eu-west-1",
+	}
+
+	err := da.OptionDefaultSet(da.ctx, def)
+	require.NoError(t, err)
+	defer da.OptionDefaultDelete(da.ctx, def.Layer, def.Owner, def.Bundle, def.Command, def.Option)
+
+	// Setting it again replaces the value.
+	def.Value = "eu-central-1"
+	err = da.OptionDefaultSet(da.ctx, def)
+	require.NoError(t, err)
+
+	list, err := da.OptionDefaultList(da.ctx, def.Bundle, def.Command)
+	require.NoError(t, err)
+	assert.Equal(t, []data.OptionDefault{def}, list)
+}
+
+func (da DataAccessTester) testOptionDefaultSetInvalid(t *testing.T) {
+	valid := data.OptionDefault{
+		Bundle:  "test-default-set-invalid",
+		Command: "echo",
+		Layer:   data.LayerRoom,
+		Owner:   "C123",
+		Option:  "region",
+	}
+
+	def := valid
+	def.Command = ""
+	assert.ErrorIs(t, da.OptionDefaultSet(da.ctx, def), errs.ErrEmptyOptionDefaultCommand)
+
+	def = valid
+	def.Option = ""
+	assert.ErrorIs(t, da.OptionDefaultSet(da.ctx, def), errs.ErrEmptyOptionDefaultOption)
+
+	def = valid
+	def.Owner = ""
+	assert.ErrorIs(t, da.OptionDefaultSet(da.ctx, def), errs.ErrEmptyOptionDefaultOwner)
+
+	def = valid
+	def.Layer = data.LayerUser
+	assert.Error(t, da.OptionDefaultSet(da.ctx, def))
+}
+
+func (da DataAccessTester) testOptionDefaultDelete(t *testing.T) {
+	def := data.OptionDefault{
+		Bundle:  "test-default-delete",
+		Command: "echo",
+		Layer:   data.LayerBundle,
+		Option:  "verbose",
+	}
+
+	err := da.OptionDefaultDelete(da.ctx, def.Layer, def.Owner, def.Bundle, def.Command, def.Option)
+	assert.ErrorIs(t, err, errs.ErrNoSuchOptionDefault)
+
+	err = da.OptionDefaultSet(da.ctx, def)
+	require.NoError(t, err)
+
+	err = da.OptionDefaultDelete(da.ctx, def.Layer, def.Owner, def.Bundle, def.Command, def.Option)
+	assert.NoError(t, err)
+
+	list, err := da.OptionDefaultList(da.ctx, def.Bundle, def.Command)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func (da DataAccessTester) testOptionDefaultList(t *testing.T) {
+	const bundle, command = "test-default-list", "echo"
+
+	defs := []data.OptionDefault{
+		{Bundle: bundle, Command: command, Layer: data.LayerBundle, Option: "region", Value: "us-west-2"},
+		{Bundle: bundle, Command: command, Layer: data.LayerGroup, Owner: "eu-ops", Option: "region", Value: "eu-west-1"},
+		{Bundle: bundle, Command: command, Layer: data.LayerRoom, Owner: "C123", Option: "region", Value: "us-east-1"},
+		{Bundle: bundle, Command: "other", Layer: data.LayerBundle, Option: "region", Value: "ap-south-1"},
+	}
+
+	for _, d := range defs {
+		require.NoError(t, da.OptionDefaultSet(da.ctx, d))
+		defer da.OptionDefaultDelete(da.ctx, d.Layer, d.Owner, d.Bundle, d.Command, d.Option)
+	}
+
+	list, err := da.OptionDefaultList(da.ctx, bundle, command)
+	require.NoError(t, err)
+	assert.Equal(t, defs[:3], list)
+
+	_, err = da.OptionDefaultList(da.ctx, bundle, "")
+	assert.ErrorIs(t, err, errs.ErrEmptyOptionDefaultCommand)
+}
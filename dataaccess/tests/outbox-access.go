@@ -0,0 +1,229 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (da DataAccessTester) testOutboxAccess(t *testing.T) {
+	t.Run("testOutboxCreate", da.testOutboxCreate)
+	t.Run("testOutboxMarkAttempted", da.testOutboxMarkAttempted)
+	t.Run("testOutboxMarkDelivered", da.testOutboxMarkDelivered)
+	t.Run("testOutboxPending", da.testOutboxPending)
+	t.Run("testOutboxPurge", da.testOutboxPurge)
+}
+
+func (da DataAccessTester) testOutboxCreate(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	entry := data.OutboxEntry{
+		RequestID:   9001,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, entry))
+
+	got, err := da.OutboxGet(da.ctx, entry.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, entry.ChannelID, got.ChannelID)
+	assert.Equal(t, entry.Envelope, got.Envelope)
+	assert.True(t, entry.Created.Equal(got.Created))
+	assert.False(t, got.IsDelivered())
+
+	// A second entry for the same request is ignored.
+	dupe := entry
+	dupe.Envelope = "ignored"
+	require.NoError(t, da.OutboxCreate(da.ctx, dupe))
+
+	got, err = da.OutboxGet(da.ctx, entry.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, entry.Envelope, got.Envelope)
+
+	_, err = da.OutboxGet(da.ctx, -1)
+	assert.ErrorIs(t, err, errs.ErrNoSuchOutboxEntry)
+}
+
+func (da DataAccessTester) testOutboxMarkAttempted(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	entry := data.OutboxEntry{
+		RequestID:   9002,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, entry))
+
+	at := entry.LastAttempt.Add(time.Minute)
+	require.NoError(t, da.OutboxMarkAttempted(da.ctx, entry.RequestID, at))
+	require.NoError(t, da.OutboxMarkAttempted(da.ctx, entry.RequestID, at))
+
+	got, err := da.OutboxGet(da.ctx, entry.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Attempts)
+	assert.True(t, at.Equal(got.LastAttempt))
+
+	assert.ErrorIs(t, da.OutboxMarkAttempted(da.ctx, -1, at), errs.ErrNoSuchOutboxEntry)
+}
+
+func (da DataAccessTester) testOutboxMarkDelivered(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	entry := data.OutboxEntry{
+		RequestID:   9003,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, entry))
+
+	first := entry.Created.Add(time.Minute)
+	require.NoError(t, da.OutboxMarkDelivered(da.ctx, entry.RequestID, first))
+
+	// The first delivery time is kept.
+	require.NoError(t, da.OutboxMarkDelivered(da.ctx, entry.RequestID, first.Add(time.Minute)))
+
+	got, err := da.OutboxGet(da.ctx, entry.RequestID)
+	require.NoError(t, err)
+	assert.True(t, got.IsDelivered())
+	assert.True(t, first.Equal(got.Delivered))
+
+	assert.ErrorIs(t, da.OutboxMarkDelivered(da.ctx, -1, first), errs.ErrNoSuchOutboxEntry)
+}
+
+func (da DataAccessTester) testOutboxPending(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	older := data.OutboxEntry{
+		RequestID:   9004,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now.Add(-2 * time.Hour),
+		LastAttempt: now.Add(-2 * time.Hour),
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, older))
+
+	old := data.OutboxEntry{
+		RequestID:   9005,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now.Add(-time.Hour),
+		LastAttempt: now.Add(-time.Hour),
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, old))
+
+	recent := data.OutboxEntry{
+		RequestID:   9006,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, recent))
+
+	delivered := data.OutboxEntry{
+		RequestID:   9007,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now.Add(-time.Hour),
+		LastAttempt: now.Add(-time.Hour),
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, delivered))
+	require.NoError(t, da.OutboxMarkDelivered(da.ctx, delivered.RequestID, time.Now()))
+
+	list, err := da.OutboxPending(da.ctx, time.Now().Add(-30*time.Minute))
+	require.NoError(t, err)
+
+	var ids []int64
+	for _, e := range list {
+		ids = append(ids, e.RequestID)
+	}
+
+	// Oldest first; neither recently attempted nor delivered entries.
+	require.Contains(t, ids, older.RequestID)
+	require.Contains(t, ids, old.RequestID)
+	assert.NotContains(t, ids, recent.RequestID)
+	assert.NotContains(t, ids, delivered.RequestID)
+	assert.Less(t, indexOf(ids, older.RequestID), indexOf(ids, old.RequestID))
+}
+
+func (da DataAccessTester) testOutboxPurge(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	old := data.OutboxEntry{
+		RequestID:   9008,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, old))
+	require.NoError(t, da.OutboxMarkDelivered(da.ctx, old.RequestID, time.Now().Add(-48*time.Hour)))
+
+	recent := data.OutboxEntry{
+		RequestID:   9009,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, recent))
+	require.NoError(t, da.OutboxMarkDelivered(da.ctx, recent.RequestID, time.Now()))
+
+	pending := data.OutboxEntry{
+		RequestID:   9010,
+		Adapter:     "slack",
+		ChannelID:   "C-TEST-OUTBOX",
+		Envelope:    `{"Response":{"Lines":["hello, world"]}}`,
+		Created:     now,
+		LastAttempt: now,
+	}
+	require.NoError(t, da.OutboxCreate(da.ctx, pending))
+
+	n, err := da.OutboxPurge(da.ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = da.OutboxGet(da.ctx, old.RequestID)
+	assert.ErrorIs(t, err, errs.ErrNoSuchOutboxEntry)
+
+	_, err = da.OutboxGet(da.ctx, recent.RequestID)
+	assert.NoError(t, err)
+
+	_, err = da.OutboxGet(da.ctx, pending.RequestID)
+	assert.NoError(t, err)
+}
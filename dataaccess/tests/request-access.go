@@ -22,13 +22,23 @@ import (
 	"time"
 
 	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func (da DataAccessTester) testRequestAccess(t *testing.T) {
 	t.Run("testRequestBegin", da.testRequestBegin)
 	t.Run("testRequestUpdate", da.testRequestUpdate)
 	t.Run("testRequestClose", da.testRequestClose)
+	t.Run("testRequestGet", da.testRequestGet)
+	t.Run("testRequestList", da.testRequestList)
+	t.Run("testRequestHistory", da.testRequestHistory)
+	t.Run("testRequestReplayOf", da.testRequestReplayOf)
+	t.Run("testRequestUpdateTimings", da.testRequestUpdateTimings)
+	t.Run("testRequestArchive", da.testRequestArchive)
+	t.Run("testRequestArchivePurge", da.testRequestArchivePurge)
+	t.Run("testRequestLogAppend", da.testRequestLogAppend)
 }
 
 func (da DataAccessTester) testRequestBegin(t *testing.T) {
@@ -116,3 +126,284 @@ func (da DataAccessTester) testRequestClose(t *testing.T) {
 	err = da.RequestClose(da.ctx, env)
 	assert.NoError(t, err)
 }
+
+func (da DataAccessTester) testRequestGet(t *testing.T) {
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+
+	req := data.CommandRequest{
+		CommandEntry: data.CommandEntry{Bundle: bundle, Command: *bundle.Commands["echox"]},
+		Adapter:      "testAdapter",
+		ChannelID:    "testChannelID",
+		Parameters:   []string{"foo", "bar"},
+		Timestamp:    time.Now().UTC().Truncate(time.Millisecond),
+		Timings:      data.StageTimings{data.StageLookup: 5 * time.Millisecond},
+		UserID:       "testUserID",
+		UserEmail:    "testUserEmail",
+		UserName:     "testUserName",
+	}
+
+	_, err = da.RequestGet(da.ctx, -1)
+	assert.ErrorIs(t, err, errs.ErrNoSuchRequest)
+
+	require.NoError(t, da.RequestBegin(da.ctx, &req))
+
+	r, err := da.RequestGet(da.ctx, req.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, req.RequestID, r.RequestID)
+	assert.Equal(t, "echox", r.CommandName)
+	assert.Equal(t, "foo bar", r.Parameters)
+	assert.False(t, r.Closed)
+
+	env := data.NewCommandResponseEnvelope(req, data.WithError("", fmt.Errorf("fake error"), 2))
+	env.Data.Duration = 1500 * time.Millisecond
+	require.NoError(t, da.RequestClose(da.ctx, env))
+
+	r, err = da.RequestGet(da.ctx, req.RequestID)
+	require.NoError(t, err)
+	assert.True(t, r.Closed)
+	assert.Equal(t, int16(2), r.ExitCode)
+	assert.Equal(t, "fake error", r.Error)
+	assert.Equal(t, 1500*time.Millisecond, r.Duration)
+	assert.Equal(t, 5*time.Millisecond, r.Timings[data.StageLookup])
+}
+
+func (da DataAccessTester) testRequestList(t *testing.T) {
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		req := data.CommandRequest{
+			CommandEntry: data.CommandEntry{Bundle: bundle, Command: *bundle.Commands["echox"]},
+			Adapter:      "testAdapter",
+			Timestamp:    time.Now(),
+		}
+		require.NoError(t, da.RequestBegin(da.ctx, &req))
+		ids = append(ids, req.RequestID)
+	}
+
+	list, err := da.RequestList(da.ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, ids[2], list[0].RequestID)
+	assert.Equal(t, ids[1], list[1].RequestID)
+}
+
+func (da DataAccessTester) testRequestHistory(t *testing.T) {
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+
+	begin := func(channel, user string, cmd *data.BundleCommand, replayOf int64) int64 {
+		req := data.CommandRequest{
+			Adapter:   "testRequestHistory",
+			ChannelID: channel,
+			UserID:    user,
+			ReplayOf:  replayOf,
+			Timestamp: time.Now(),
+		}
+		req.Bundle = bundle
+		if cmd != nil {
+			req.Command = *cmd
+		}
+		require.NoError(t, da.RequestBegin(da.ctx, &req))
+		return req.RequestID
+	}
+
+	echo := bundle.Commands["echox"]
+
+	first := begin("C1", "U1", echo, 0)
+	begin("C2", "U1", echo, 0)
+	begin("C1", "U2", echo, 0)
+	begin("C1", "U1", nil, 0)
+	begin("C1", "U1", echo, first)
+	second := begin("C1", "U1", echo, 0)
+	third := begin("C1", "U1", echo, 0)
+
+	list, err := da.RequestHistory(da.ctx, "testRequestHistory", "C1", "U1", 0)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, third, list[0].RequestID)
+	assert.Equal(t, second, list[1].RequestID)
+	assert.Equal(t, first, list[2].RequestID)
+
+	list, err = da.RequestHistory(da.ctx, "testRequestHistory", "C1", "U1", 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, third, list[0].RequestID)
+
+	list, err = da.RequestHistory(da.ctx, "testRequestHistory", "C3", "U1", 0)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func (da DataAccessTester) testRequestReplayOf(t *testing.T) {
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+
+	original := data.CommandRequest{
+		CommandEntry: data.CommandEntry{Bundle: bundle, Command: *bundle.Commands["echox"]},
+		Adapter:      "testAdapter",
+		Timestamp:    time.Now(),
+	}
+	require.NoError(t, da.RequestBegin(da.ctx, &original))
+
+	replay := original
+	replay.RequestID = 0
+	replay.ReplayOf = original.RequestID
+	require.NoError(t, da.RequestBegin(da.ctx, &replay))
+
+	r, err := da.RequestGet(da.ctx, replay.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, original.RequestID, r.ReplayOf)
+
+	r, err = da.RequestGet(da.ctx, original.RequestID)
+	require.NoError(t, err)
+	assert.Zero(t, r.ReplayOf)
+}
+
+func (da DataAccessTester) testRequestUpdateTimings(t *testing.T) {
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+
+	req := data.CommandRequest{
+		CommandEntry: data.CommandEntry{Bundle: bundle, Command: *bundle.Commands["echox"]},
+		Adapter:      "testAdapter",
+		Timestamp:    time.Now(),
+	}
+	require.NoError(t, da.RequestBegin(da.ctx, &req))
+
+	assert.Error(t, da.RequestUpdateTimings(da.ctx, 0, nil))
+
+	timings := data.StageTimings{
+		data.StageExecution: 2 * time.Second,
+		data.StageSend:      40 * time.Millisecond,
+	}
+	require.NoError(t, da.RequestUpdateTimings(da.ctx, req.RequestID, timings))
+
+	r, err := da.RequestGet(da.ctx, req.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, timings, r.Timings)
+}
+
+func (da DataAccessTester) testRequestArchive(t *testing.T) {
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+
+	req := data.CommandRequest{
+		CommandEntry: data.CommandEntry{Bundle: bundle, Command: *bundle.Commands["echox"]},
+		Adapter:      "testAdapter",
+		Timestamp:    time.Now(),
+	}
+	require.NoError(t, da.RequestBegin(da.ctx, &req))
+
+	payload := data.RequestPayload{
+		ArchivedAt: time.Now().UTC().Truncate(time.Millisecond),
+		Title:      "Error",
+		ErrorCode:  "E1",
+		Output:     []string{"foo", "bar"},
+		Rendered:   "foo\nbar",
+		Truncated:  true,
+	}
+
+	err = da.RequestArchive(da.ctx, -1, payload)
+	assert.ErrorIs(t, err, errs.ErrNoSuchRequest)
+
+	r, err := da.RequestGet(da.ctx, req.RequestID)
+	require.NoError(t, err)
+	assert.Nil(t, r.Payload)
+
+	require.NoError(t, da.RequestArchive(da.ctx, req.RequestID, payload))
+
+	r, err = da.RequestGet(da.ctx, req.RequestID)
+	require.NoError(t, err)
+	require.NotNil(t, r.Payload)
+	assert.Equal(t, payload, *r.Payload)
+
+	// Archiving again replaces the existing payload.
+	payload.Output = []string{"baz"}
+	payload.Rendered = "baz"
+	require.NoError(t, da.RequestArchive(da.ctx, req.RequestID, payload))
+
+	r, err = da.RequestGet(da.ctx, req.RequestID)
+	require.NoError(t, err)
+	require.NotNil(t, r.Payload)
+	assert.Equal(t, []string{"baz"}, r.Payload.Output)
+
+	list, err := da.RequestList(da.ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Nil(t, list[0].Payload)
+}
+
+func (da DataAccessTester) testRequestArchivePurge(t *testing.T) {
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+
+	var ids []int64
+	for _, age := range []time.Duration{48 * time.Hour, time.Minute} {
+		req := data.CommandRequest{
+			CommandEntry: data.CommandEntry{Bundle: bundle, Command: *bundle.Commands["echox"]},
+			Adapter:      "testAdapter",
+			Timestamp:    time.Now(),
+		}
+		require.NoError(t, da.RequestBegin(da.ctx, &req))
+
+		payload := data.RequestPayload{ArchivedAt: now.Add(-age), Output: []string{"foo"}}
+		require.NoError(t, da.RequestArchive(da.ctx, req.RequestID, payload))
+		ids = append(ids, req.RequestID)
+	}
+
+	n, err := da.RequestArchivePurge(da.ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+
+	r, err := da.RequestGet(da.ctx, ids[0])
+	require.NoError(t, err)
+	assert.Nil(t, r.Payload)
+
+	r, err = da.RequestGet(da.ctx, ids[1])
+	require.NoError(t, err)
+	assert.NotNil(t, r.Payload)
+}
+
+func (da DataAccessTester) testRequestLogAppend(t *testing.T) {
+	bundle, err := getTestBundle()
+	require.NoError(t, err)
+
+	req := data.CommandRequest{
+		CommandEntry: data.CommandEntry{Bundle: bundle, Command: *bundle.Commands["echox"]},
+		Adapter:      "testAdapter",
+		Timestamp:    time.Now(),
+	}
+	require.NoError(t, da.RequestBegin(da.ctx, &req))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	entries := []data.RequestLogEntry{
+		{Timestamp: now, Level: data.RequestLogInfo, Message: "starting", Fields: map[string]string{"step": "1"}},
+		{Timestamp: now, Level: data.RequestLogError, Message: "failed"},
+	}
+
+	err = da.RequestLogAppend(da.ctx, -1, entries[0])
+	assert.ErrorIs(t, err, errs.ErrNoSuchRequest)
+
+	for _, e := range entries {
+		require.NoError(t, da.RequestLogAppend(da.ctx, req.RequestID, e))
+	}
+
+	r, err := da.RequestGet(da.ctx, req.RequestID)
+	require.NoError(t, err)
+	require.Len(t, r.Logs, 2)
+	assert.True(t, now.Equal(r.Logs[0].Timestamp))
+	assert.Equal(t, "starting", r.Logs[0].Message)
+	assert.Equal(t, map[string]string{"step": "1"}, r.Logs[0].Fields)
+	assert.Equal(t, data.RequestLogError, r.Logs[1].Level)
+	assert.Empty(t, r.Logs[1].Fields)
+
+	list, err := da.RequestList(da.ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Nil(t, list[0].Logs)
+}
@@ -37,6 +37,8 @@ func (da DataAccessTester) testRoleAccess(t *testing.T) {
 	t.Run("testRoleGroupList", da.testRoleGroupList)
 	t.Run("testRolePermissionExists", da.testRolePermissionExists)
 	t.Run("testRolePermissionAdd", da.testRolePermissionAdd)
+	t.Run("testRolePermissionAddBatch", da.testRolePermissionAddBatch)
+	t.Run("testRoleClone", da.testRoleClone)
 	t.Run("testRolePermissionList", da.testRolePermissionList)
 }
 
@@ -324,6 +326,76 @@ func (da DataAccessTester) testRolePermissionAdd(t *testing.T) {
 	require.True(t, exists)
 }
 
+func (da DataAccessTester) testRolePermissionAddBatch(t *testing.T) {
+	const rolename = "role-test-role-permission-add-batch"
+
+	perms := rest.RolePermissionList{
+		{BundleName: "test", Permission: "perm-test-role-permission-add-batch-0"},
+		{BundleName: "test", Permission: "perm-test-role-permission-add-batch-1"},
+	}
+
+	// Expect an error
+	err := da.RolePermissionAddBatch(da.ctx, rolename, perms)
+	assert.Error(t, err, errs.ErrNoSuchRole)
+
+	da.RoleCreate(da.ctx, rolename)
+	defer da.RoleDelete(da.ctx, rolename)
+
+	// Expect an error; nothing should be granted
+	err = da.RolePermissionAddBatch(da.ctx, rolename, append(perms, rest.RolePermission{BundleName: "test"}))
+	assert.Error(t, err, errs.ErrEmptyPermission)
+
+	role, _ := da.RoleGet(da.ctx, rolename)
+	require.Len(t, role.Permissions, 0)
+
+	// Grant one permission individually, then both in a batch
+	err = da.RolePermissionAdd(da.ctx, rolename, perms[0].BundleName, perms[0].Permission)
+	require.NoError(t, err)
+
+	err = da.RolePermissionAddBatch(da.ctx, rolename, perms)
+	require.NoError(t, err)
+
+	list, err := da.RolePermissionList(da.ctx, rolename)
+	require.NoError(t, err)
+	assert.Equal(t, perms, list)
+}
+
+func (da DataAccessTester) testRoleClone(t *testing.T) {
+	const rolename = "role-test-role-clone"
+	const clonename = "role-test-role-clone-clone"
+
+	// Expect an error
+	err := da.RoleClone(da.ctx, rolename, clonename)
+	assert.Error(t, err, errs.ErrNoSuchRole)
+
+	da.RoleCreate(da.ctx, rolename)
+	defer da.RoleDelete(da.ctx, rolename)
+
+	da.RolePermissionAdd(da.ctx, rolename, "test", "perm-test-role-clone-0")
+	da.RolePermissionAdd(da.ctx, rolename, "test", "perm-test-role-clone-1")
+
+	err = da.RoleClone(da.ctx, rolename, clonename)
+	require.NoError(t, err)
+	defer da.RoleDelete(da.ctx, clonename)
+
+	expected, err := da.RolePermissionList(da.ctx, rolename)
+	require.NoError(t, err)
+
+	actual, err := da.RolePermissionList(da.ctx, clonename)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	// The clone is independent of the original
+	da.RolePermissionDelete(da.ctx, clonename, "test", "perm-test-role-clone-0")
+
+	expected, _ = da.RolePermissionList(da.ctx, rolename)
+	assert.Len(t, expected, 2)
+
+	// Expect an error: the clone already exists
+	err = da.RoleClone(da.ctx, rolename, clonename)
+	assert.Error(t, err, errs.ErrRoleExists)
+}
+
 func (da DataAccessTester) testRolePermissionExists(t *testing.T) {
 	var err error
 
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (da DataAccessTester) testSecretOutputAccess(t *testing.T) {
+	t.Run("testSecretOutputRedeem", da.testSecretOutputRedeem)
+	t.Run("testSecretOutputRedeemExpired", da.testSecretOutputRedeemExpired)
+	t.Run("testSecretOutputPurge", da.testSecretOutputPurge)
+}
+
+func (da DataAccessTester) testSecretOutputRedeem(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	s := data.SecretOutput{
+		TokenHash: data.HashSecretToken("test-redeem"),
+		RequestID: 42,
+		Username:  "test-secret",
+		Title:     "Secret",
+		Output:    []string{"hunter2", "correct horse"},
+		Created:   now,
+		Expires:   now.Add(time.Hour),
+	}
+	require.NoError(t, da.SecretOutputCreate(da.ctx, s))
+
+	got, err := da.SecretOutputRedeem(da.ctx, s.TokenHash)
+	require.NoError(t, err)
+	assert.Equal(t, s.RequestID, got.RequestID)
+	assert.Equal(t, s.Username, got.Username)
+	assert.Equal(t, s.Title, got.Title)
+	assert.Equal(t, s.Output, got.Output)
+
+	// Secret outputs can only be redeemed once.
+	_, err = da.SecretOutputRedeem(da.ctx, s.TokenHash)
+	assert.ErrorIs(t, err, errs.ErrNoSuchSecretOutput)
+
+	_, err = da.SecretOutputRedeem(da.ctx, data.HashSecretToken("test-no-such-token"))
+	assert.ErrorIs(t, err, errs.ErrNoSuchSecretOutput)
+}
+
+func (da DataAccessTester) testSecretOutputRedeemExpired(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	s := data.SecretOutput{
+		TokenHash: data.HashSecretToken("test-redeem-expired"),
+		RequestID: 42,
+		Username:  "test-secret",
+		Title:     "Secret",
+		Output:    []string{"hunter2", "correct horse"},
+		Created:   now,
+		Expires:   now.Add(-time.Minute),
+	}
+	require.NoError(t, da.SecretOutputCreate(da.ctx, s))
+
+	_, err := da.SecretOutputRedeem(da.ctx, s.TokenHash)
+	assert.ErrorIs(t, err, errs.ErrNoSuchSecretOutput)
+}
+
+func (da DataAccessTester) testSecretOutputPurge(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	expired := data.SecretOutput{
+		TokenHash: data.HashSecretToken("test-purge-expired"),
+		RequestID: 42,
+		Username:  "test-secret",
+		Title:     "Secret",
+		Output:    []string{"hunter2", "correct horse"},
+		Created:   now,
+		Expires:   now.Add(-time.Hour),
+	}
+	require.NoError(t, da.SecretOutputCreate(da.ctx, expired))
+
+	current := data.SecretOutput{
+		TokenHash: data.HashSecretToken("test-purge-current"),
+		RequestID: 42,
+		Username:  "test-secret",
+		Title:     "Secret",
+		Output:    []string{"hunter2", "correct horse"},
+		Created:   now,
+		Expires:   now.Add(time.Hour),
+	}
+	require.NoError(t, da.SecretOutputCreate(da.ctx, current))
+
+	n, err := da.SecretOutputPurge(da.ctx, time.Now())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+
+	_, err = da.SecretOutputRedeem(da.ctx, current.TokenHash)
+	assert.NoError(t, err)
+}
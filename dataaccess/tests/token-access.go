@@ -32,6 +32,8 @@ func (da DataAccessTester) testTokenAccess(t *testing.T) {
 	t.Run("testTokenRetrieveByToken", da.testTokenRetrieveByToken)
 	t.Run("testTokenExpiry", da.testTokenExpiry)
 	t.Run("testTokenInvalidate", da.testTokenInvalidate)
+	t.Run("testTokenPurge", da.testTokenPurge)
+	t.Run("testTokenRevoke", da.testTokenRevoke)
 }
 
 func (da DataAccessTester) testTokenGenerate(t *testing.T) {
@@ -111,3 +113,63 @@ func (da DataAccessTester) testTokenInvalidate(t *testing.T) {
 	assert.NoError(t, err)
 	require.False(t, da.TokenEvaluate(da.ctx, token.Token))
 }
+
+func (da DataAccessTester) testTokenPurge(t *testing.T) {
+	err := da.UserCreate(da.ctx, rest.User{Username: "test_purge_expired", Email: "test_purge_expired"})
+	defer da.UserDelete(da.ctx, "test_purge_expired")
+	require.NoError(t, err)
+
+	err = da.UserCreate(da.ctx, rest.User{Username: "test_purge_valid", Email: "test_purge_valid"})
+	defer da.UserDelete(da.ctx, "test_purge_valid")
+	require.NoError(t, err)
+
+	expired, err := da.TokenGenerate(da.ctx, "test_purge_expired", time.Millisecond)
+	defer da.TokenInvalidate(da.ctx, expired.Token)
+	require.NoError(t, err)
+
+	valid, err := da.TokenGenerate(da.ctx, "test_purge_valid", 10*time.Minute)
+	defer da.TokenInvalidate(da.ctx, valid.Token)
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	n, err := da.TokenPurge(da.ctx, time.Now())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+
+	_, err = da.TokenRetrieveByToken(da.ctx, expired.Token)
+	assert.ErrorIs(t, err, errs.ErrNoSuchToken)
+
+	_, err = da.TokenRetrieveByToken(da.ctx, valid.Token)
+	assert.NoError(t, err)
+}
+
+func (da DataAccessTester) testTokenRevoke(t *testing.T) {
+	revoked, err := da.TokenRevoked(da.ctx, "test-revoke")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	err = da.TokenRevoke(da.ctx, "", time.Now().Add(time.Minute))
+	assert.ErrorIs(t, err, errs.ErrNoSuchToken)
+
+	require.NoError(t, da.TokenRevoke(da.ctx, "test-revoke", time.Now().Add(time.Minute)))
+	require.NoError(t, da.TokenRevoke(da.ctx, "test-revoke", time.Now().Add(time.Minute)))
+	require.NoError(t, da.TokenRevoke(da.ctx, "test-revoke-expired", time.Now().Add(-time.Minute)))
+
+	revoked, err = da.TokenRevoked(da.ctx, "test-revoke")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Revocations are purged once their tokens would have expired.
+	n, err := da.TokenPurge(da.ctx, time.Now())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+
+	revoked, err = da.TokenRevoked(da.ctx, "test-revoke-expired")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = da.TokenRevoked(da.ctx, "test-revoke")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
@@ -18,7 +18,9 @@ package tests
 
 import (
 	"testing"
+	"time"
 
+	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/dataaccess/errs"
 
@@ -31,6 +33,7 @@ func (da DataAccessTester) testUserAccess(t *testing.T) {
 	t.Run("testUserCreate", da.testUserCreate)
 	t.Run("testUserDelete", da.testUserDelete)
 	t.Run("testUserExists", da.testUserExists)
+	t.Run("testUserExport", da.testUserExport)
 	t.Run("testUserGet", da.testUserGet)
 	t.Run("testUserGetByEmail", da.testUserGetByEmail)
 	t.Run("testUserGetByID", da.testUserGetByID)
@@ -39,6 +42,10 @@ func (da DataAccessTester) testUserAccess(t *testing.T) {
 	t.Run("testUserList", da.testUserList)
 	t.Run("testUserNotExists", da.testUserNotExists)
 	t.Run("testUserPermissionList", da.testUserPermissionList)
+	t.Run("testUserPurge", da.testUserPurge)
+	t.Run("testUserRename", da.testUserRename)
+	t.Run("testUserRestore", da.testUserRestore)
+	t.Run("testUserServiceAccount", da.testUserServiceAccount)
 	t.Run("testUserUpdate", da.testUserUpdate)
 }
 
@@ -127,6 +134,37 @@ func (da DataAccessTester) testUserExists(t *testing.T) {
 	require.True(t, exists)
 }
 
+func (da DataAccessTester) testUserExport(t *testing.T) {
+	_, err := da.UserExport(da.ctx, "no-such-user")
+	assert.Error(t, err, errs.ErrNoSuchUser)
+
+	da.GroupCreate(da.ctx, rest.Group{Name: "group-test-user-export"})
+	defer da.GroupDelete(da.ctx, "group-test-user-export")
+
+	user := rest.User{
+		Username: "user-test-user-export",
+		Email:    "export@example.com",
+		FullName: "Export User",
+		Password: "password",
+		Mappings: map[string]string{"slack": "U12345"},
+	}
+	da.UserCreate(da.ctx, user)
+	defer da.UserDelete(da.ctx, user.Username)
+
+	da.GroupUserAdd(da.ctx, "group-test-user-export", user.Username)
+
+	export, err := da.UserExport(da.ctx, user.Username)
+	require.NoError(t, err)
+
+	assert.Equal(t, user.Username, export.User.Username)
+	assert.Equal(t, user.Email, export.User.Email)
+	assert.Equal(t, user.FullName, export.User.FullName)
+	assert.Equal(t, user.Mappings, export.User.Mappings)
+	assert.Empty(t, export.User.Password)
+	assert.Equal(t, []string{"group-test-user-export"}, export.Groups)
+	assert.False(t, export.Exported.IsZero())
+}
+
 func (da DataAccessTester) testUserGet(t *testing.T) {
 	const userName = "test-get"
 	const userEmail = "test-get@foo.com"
@@ -355,6 +393,252 @@ func (da DataAccessTester) testUserPermissionList(t *testing.T) {
 	assert.Equal(t, expected, actual.Strings())
 }
 
+func (da DataAccessTester) testUserPurge(t *testing.T) {
+	_, err := da.UserPurge(da.ctx, "")
+	assert.Error(t, err, errs.ErrEmptyUserName)
+
+	_, err = da.UserPurge(da.ctx, "admin")
+	assert.Error(t, err, errs.ErrAdminUndeletable)
+
+	_, err = da.UserPurge(da.ctx, "no-such-user")
+	assert.Error(t, err, errs.ErrNoSuchUser)
+
+	da.GroupCreate(da.ctx, rest.Group{Name: "group-test-user-purge"})
+	defer da.GroupDelete(da.ctx, "group-test-user-purge")
+
+	user := rest.User{
+		Username: "user-test-user-purge",
+		Email:    "purge@example.com",
+		Mappings: map[string]string{"slack": "U54321"},
+	}
+	da.UserCreate(da.ctx, user)
+	defer da.UserDelete(da.ctx, user.Username)
+
+	da.GroupUserAdd(da.ctx, "group-test-user-purge", user.Username)
+
+	_, err = da.TokenGenerate(da.ctx, user.Username, time.Minute)
+	require.NoError(t, err)
+
+	result, err := da.UserPurge(da.ctx, user.Username)
+	require.NoError(t, err)
+	assert.Equal(t, user.Username, result.Username)
+	assert.NotEmpty(t, result.Pseudonym)
+	assert.NotEqual(t, user.Username, result.Pseudonym)
+
+	exists, _ := da.UserExists(da.ctx, user.Username)
+	assert.False(t, exists)
+
+	_, err = da.UserGetByID(da.ctx, "slack", "U54321")
+	assert.Error(t, err, errs.ErrNoSuchUser)
+
+	_, err = da.TokenRetrieveByUser(da.ctx, user.Username)
+	assert.Error(t, err, errs.ErrNoSuchToken)
+
+	users, err := da.GroupUserList(da.ctx, "group-test-user-purge")
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func (da DataAccessTester) testUserRename(t *testing.T) {
+	const username = "test-rename"
+	const newname = "test-rename-new"
+	const groupname = "group-test-rename"
+
+	err := da.UserRename(da.ctx, "", newname)
+	assert.ErrorIs(t, err, errs.ErrEmptyUserName)
+	err = da.UserRename(da.ctx, username, "")
+	assert.ErrorIs(t, err, errs.ErrEmptyUserName)
+
+	err = da.UserRename(da.ctx, "admin", newname)
+	assert.ErrorIs(t, err, errs.ErrAdminUndeletable)
+
+	err = da.UserRename(da.ctx, "no-such-user", newname)
+	assert.ErrorIs(t, err, errs.ErrNoSuchUser)
+
+	user := rest.User{
+		Username: username,
+		Email:    "test-rename@example.com",
+		Mappings: map[string]string{"slack": "U-TEST-RENAME"},
+	}
+	require.NoError(t, da.UserCreate(da.ctx, user))
+	defer da.UserDelete(da.ctx, username)
+	defer da.UserDelete(da.ctx, newname)
+
+	require.NoError(t, da.GroupCreate(da.ctx, rest.Group{Name: groupname}))
+	defer da.GroupDelete(da.ctx, groupname)
+	require.NoError(t, da.GroupUserAdd(da.ctx, groupname, username))
+
+	token, err := da.TokenGenerate(da.ctx, username, time.Hour)
+	require.NoError(t, err)
+	defer da.TokenInvalidate(da.ctx, token.Token)
+
+	config := data.DynamicConfiguration{
+		Bundle: "bundle-test-rename",
+		Layer:  data.LayerUser,
+		Owner:  username,
+		Key:    "key",
+		Value:  "value",
+	}
+	require.NoError(t, da.DynamicConfigurationCreate(da.ctx, config))
+	defer da.DynamicConfigurationDelete(da.ctx, config.Layer, config.Bundle, newname, config.Key)
+
+	macro := data.Macro{Name: "test-rename", Layer: data.LayerUser, Owner: username, Command: "echo"}
+	require.NoError(t, da.MacroSave(da.ctx, macro))
+	defer da.MacroDelete(da.ctx, macro.Layer, newname, macro.Name)
+
+	alias := data.Alias{Name: "test-rename", Owner: username, Command: "echo"}
+	require.NoError(t, da.AliasSave(da.ctx, alias))
+	defer da.AliasDelete(da.ctx, newname, alias.Name)
+
+	// Can't rename over an existing user
+	require.NoError(t, da.UserCreate(da.ctx, rest.User{Username: newname}))
+	err = da.UserRename(da.ctx, username, newname)
+	assert.ErrorIs(t, err, errs.ErrUserExists)
+	require.NoError(t, da.UserDelete(da.ctx, newname))
+
+	require.NoError(t, da.UserRename(da.ctx, username, newname))
+
+	exists, err := da.UserExists(da.ctx, username)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	renamed, err := da.UserGet(da.ctx, newname)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, renamed.Email)
+	assert.Equal(t, user.Mappings, renamed.Mappings)
+
+	mapped, err := da.UserGetByID(da.ctx, "slack", "U-TEST-RENAME")
+	require.NoError(t, err)
+	assert.Equal(t, newname, mapped.Username)
+
+	members, err := da.GroupUserList(da.ctx, groupname)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, newname, members[0].Username)
+
+	renamedToken, err := da.TokenRetrieveByToken(da.ctx, token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, newname, renamedToken.User)
+
+	_, err = da.DynamicConfigurationGet(da.ctx, config.Layer, config.Bundle, username, config.Key)
+	assert.ErrorIs(t, err, errs.ErrNoSuchConfig)
+	renamedConfig, err := da.DynamicConfigurationGet(da.ctx, config.Layer, config.Bundle, newname, config.Key)
+	require.NoError(t, err)
+	assert.Equal(t, config.Value, renamedConfig.Value)
+
+	macros, err := da.MacroList(da.ctx, macro.Name)
+	require.NoError(t, err)
+	require.Len(t, macros, 1)
+	assert.Equal(t, newname, macros[0].Owner)
+
+	aliases, err := da.AliasList(da.ctx, alias.Name)
+	require.NoError(t, err)
+	require.Len(t, aliases, 1)
+	assert.Equal(t, newname, aliases[0].Owner)
+}
+
+func (da DataAccessTester) testUserRestore(t *testing.T) {
+	const username = "test-restore"
+	const groupname = "group-test-restore"
+
+	err := da.UserRestore(da.ctx, "", time.Time{})
+	assert.ErrorIs(t, err, errs.ErrEmptyUserName)
+
+	// Restore user that was never deleted
+	err = da.UserRestore(da.ctx, "no-such-user", time.Time{})
+	assert.ErrorIs(t, err, errs.ErrNoSuchUser)
+
+	user := rest.User{
+		Username: username,
+		Email:    "test-restore@example.com",
+		Mappings: map[string]string{"slack": "U-TEST-RESTORE"},
+	}
+	require.NoError(t, da.UserCreate(da.ctx, user))
+	defer da.UserDelete(da.ctx, username)
+
+	require.NoError(t, da.GroupCreate(da.ctx, rest.Group{Name: groupname}))
+	defer da.GroupDelete(da.ctx, groupname)
+	require.NoError(t, da.GroupUserAdd(da.ctx, groupname, username))
+
+	// Can't restore a user that isn't deleted
+	err = da.UserRestore(da.ctx, username, time.Time{})
+	assert.ErrorIs(t, err, errs.ErrNoSuchUser)
+
+	deleted := time.Now().Add(-time.Minute)
+	require.NoError(t, da.UserDelete(da.ctx, username))
+
+	_, err = da.UserGet(da.ctx, username)
+	assert.ErrorIs(t, err, errs.ErrNoSuchUser)
+	_, err = da.UserGetByID(da.ctx, "slack", "U-TEST-RESTORE")
+	assert.ErrorIs(t, err, errs.ErrNoSuchUser)
+
+	members, err := da.GroupUserList(da.ctx, groupname)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+
+	// Deleted before the retention window
+	err = da.UserRestore(da.ctx, username, time.Now().Add(time.Minute))
+	assert.ErrorIs(t, err, errs.ErrNoSuchUser)
+
+	err = da.UserRestore(da.ctx, username, deleted)
+	require.NoError(t, err)
+
+	restored, err := da.UserGet(da.ctx, username)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, restored.Email)
+	assert.Equal(t, user.Mappings, restored.Mappings)
+
+	members, err = da.GroupUserList(da.ctx, groupname)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, username, members[0].Username)
+
+	// Re-creating a deleted user discards the old one entirely
+	require.NoError(t, da.UserDelete(da.ctx, username))
+	require.NoError(t, da.UserCreate(da.ctx, rest.User{Username: username}))
+
+	members, err = da.GroupUserList(da.ctx, groupname)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+
+	err = da.UserRestore(da.ctx, username, deleted)
+	assert.ErrorIs(t, err, errs.ErrNoSuchUser)
+}
+
+func (da DataAccessTester) testUserServiceAccount(t *testing.T) {
+	// Service accounts can't have passwords or mappings.
+	err := da.UserCreate(da.ctx, rest.User{Username: "test-svc", ServiceAccount: true, Password: "password"})
+	assert.ErrorIs(t, err, errs.ErrServiceAccountCredentials)
+
+	err = da.UserCreate(da.ctx, rest.User{Username: "test-svc", ServiceAccount: true, Mappings: map[string]string{"slack": "U12345"}})
+	assert.ErrorIs(t, err, errs.ErrServiceAccountCredentials)
+
+	err = da.UserCreate(da.ctx, rest.User{Username: "test-svc", Email: "test-svc@bar.com", ServiceAccount: true})
+	defer da.UserDelete(da.ctx, "test-svc")
+	require.NoError(t, err)
+
+	user, err := da.UserGet(da.ctx, "test-svc")
+	require.NoError(t, err)
+	assert.True(t, user.ServiceAccount)
+
+	// There's no password to authenticate with.
+	authenticated, err := da.UserAuthenticate(da.ctx, "test-svc", "")
+	assert.NoError(t, err)
+	assert.False(t, authenticated)
+
+	err = da.UserUpdate(da.ctx, rest.User{Username: "test-svc", Password: "password"})
+	assert.ErrorIs(t, err, errs.ErrServiceAccountCredentials)
+
+	// Updates can't turn a service account into a regular user.
+	err = da.UserUpdate(da.ctx, rest.User{Username: "test-svc", Email: "test-svc2@bar.com"})
+	require.NoError(t, err)
+
+	user, err = da.UserGet(da.ctx, "test-svc")
+	require.NoError(t, err)
+	assert.True(t, user.ServiceAccount)
+	assert.Equal(t, "test-svc2@bar.com", user.Email)
+}
+
 func (da DataAccessTester) testUserUpdate(t *testing.T) {
 	// Update blank user
 	err := da.UserUpdate(da.ctx, rest.User{})
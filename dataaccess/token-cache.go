@@ -0,0 +1,191 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataaccess
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/memory"
+)
+
+// tokenCache holds recently retrieved tokens, keyed by token string. Like
+// commandCache, it's shared by all of the DataAccess values returned by Get.
+// A token is evicted whenever it's revoked through one of them; revocations
+// made by other Gort instances are picked up when its TTL expires.
+var tokenCache = &tokenEntryCache{entries: map[string]tokenEntry{}}
+
+// tokenEntry is a cached token and the time that it must be reloaded.
+type tokenEntry struct {
+	token   rest.Token
+	expires time.Time
+}
+
+// tokenEntryCache is a short-lived cache of tokens.
+type tokenEntryCache struct {
+	sync.Mutex
+	entries map[string]tokenEntry
+}
+
+// get returns the cached token, if it's present and unexpired.
+func (c *tokenEntryCache) get(tokenString string) (rest.Token, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.entries[tokenString]
+	if !ok {
+		return rest.Token{}, false
+	}
+
+	if !time.Now().Before(e.expires) {
+		delete(c.entries, tokenString)
+		return rest.Token{}, false
+	}
+
+	return e.token, true
+}
+
+// put caches a token for ttl, or until the token itself expires, whichever
+// comes first.
+func (c *tokenEntryCache) put(token rest.Token, ttl time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if token.ValidUntil.Before(expires) {
+		expires = token.ValidUntil
+	}
+
+	c.entries[token.Token] = tokenEntry{token: token, expires: expires}
+}
+
+// evict removes a token from the cache.
+func (c *tokenEntryCache) evict(tokenString string) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.entries, tokenString)
+}
+
+// evictUser removes all of a user's tokens from the cache.
+func (c *tokenEntryCache) evictUser(username string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for k, e := range c.entries {
+		if e.token.User == username {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// invalidate empties the cache.
+func (c *tokenEntryCache) invalidate() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.entries = map[string]tokenEntry{}
+}
+
+// withTokenCache wraps da so that token lookups are served from tokenCache.
+// As with withCommandCache, the in-memory DAL is returned as-is.
+func withTokenCache(da DataAccess) DataAccess {
+	if _, ok := da.(*memory.InMemoryDataAccess); ok {
+		return da
+	}
+
+	ttl := config.GetDatabaseConfigs().TokenCacheTTLOrDefault()
+	if ttl <= 0 {
+		return da
+	}
+
+	return tokenCachingDataAccess{da, ttl}
+}
+
+// tokenCachingDataAccess is a DataAccess that serves TokenEvaluate and
+// TokenRetrieveByToken from tokenCache, and evicts tokens from it whenever
+// they're invalidated or their user is changed.
+type tokenCachingDataAccess struct {
+	DataAccess
+	ttl time.Duration
+}
+
+func (da tokenCachingDataAccess) Initialize(ctx context.Context) error {
+	defer tokenCache.invalidate()
+	return da.DataAccess.Initialize(ctx)
+}
+
+// TokenEvaluate returns true if the token exists and hasn't expired.
+func (da tokenCachingDataAccess) TokenEvaluate(ctx context.Context, tokenString string) bool {
+	token, err := da.TokenRetrieveByToken(ctx, tokenString)
+	if err != nil {
+		return false
+	}
+
+	return !token.IsExpired()
+}
+
+// TokenRetrieveByToken retrieves a token, using the cached value if there is
+// one. Failed lookups aren't cached.
+func (da tokenCachingDataAccess) TokenRetrieveByToken(ctx context.Context, tokenString string) (rest.Token, error) {
+	if token, ok := tokenCache.get(tokenString); ok {
+		return token, nil
+	}
+
+	token, err := da.DataAccess.TokenRetrieveByToken(ctx, tokenString)
+	if err != nil {
+		return token, err
+	}
+
+	tokenCache.put(token, da.ttl)
+
+	return token, nil
+}
+
+func (da tokenCachingDataAccess) TokenGenerate(ctx context.Context, username string, duration time.Duration) (rest.Token, error) {
+	// Generating a token invalidates any existing token for the user.
+	defer tokenCache.evictUser(username)
+	return da.DataAccess.TokenGenerate(ctx, username, duration)
+}
+
+func (da tokenCachingDataAccess) TokenInvalidate(ctx context.Context, tokenString string) error {
+	defer tokenCache.evict(tokenString)
+	return da.DataAccess.TokenInvalidate(ctx, tokenString)
+}
+
+func (da tokenCachingDataAccess) TokenPurge(ctx context.Context, before time.Time) (int, error) {
+	defer tokenCache.invalidate()
+	return da.DataAccess.TokenPurge(ctx, before)
+}
+
+func (da tokenCachingDataAccess) UserDelete(ctx context.Context, username string) error {
+	defer tokenCache.evictUser(username)
+	return da.DataAccess.UserDelete(ctx, username)
+}
+
+func (da tokenCachingDataAccess) UserPurge(ctx context.Context, username string) (rest.UserPurgeResult, error) {
+	defer tokenCache.evictUser(username)
+	return da.DataAccess.UserPurge(ctx, username)
+}
+
+func (da tokenCachingDataAccess) UserRename(ctx context.Context, username, newname string) error {
+	defer tokenCache.evictUser(username)
+	return da.DataAccess.UserRename(ctx, username, newname)
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataaccess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+// tokenDataAccess is a DataAccess that holds a fixed set of tokens and counts
+// calls to its TokenRetrieveByToken. Any other method panics.
+type tokenDataAccess struct {
+	DataAccess
+	tokens    map[string]rest.Token
+	retrieves *int
+}
+
+func (da tokenDataAccess) TokenRetrieveByToken(ctx context.Context, tokenString string) (rest.Token, error) {
+	*da.retrieves++
+	if token, ok := da.tokens[tokenString]; ok {
+		return token, nil
+	}
+	return rest.Token{}, errs.ErrNoSuchToken
+}
+
+func (da tokenDataAccess) TokenInvalidate(ctx context.Context, tokenString string) error {
+	delete(da.tokens, tokenString)
+	return nil
+}
+
+func (da tokenDataAccess) UserDelete(ctx context.Context, username string) error {
+	return nil
+}
+
+func TestTokenCache(t *testing.T) {
+	ctx := context.Background()
+	tokenCache.invalidate()
+	defer tokenCache.invalidate()
+
+	retrieves := 0
+	da := tokenCachingDataAccess{tokenDataAccess{
+		retrieves: &retrieves,
+		tokens: map[string]rest.Token{
+			"valid":   {Token: "valid", User: "alice", ValidUntil: time.Now().Add(time.Hour)},
+			"other":   {Token: "other", User: "bob", ValidUntil: time.Now().Add(time.Hour)},
+			"expired": {Token: "expired", User: "bob", ValidUntil: time.Now().Add(-time.Hour)},
+		},
+	}, time.Minute}
+
+	assert.True(t, da.TokenEvaluate(ctx, "valid"))
+	token, err := da.TokenRetrieveByToken(ctx, "valid")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", token.User)
+	assert.Equal(t, 1, retrieves)
+
+	// Expired tokens are never served from the cache.
+	assert.False(t, da.TokenEvaluate(ctx, "expired"))
+	assert.False(t, da.TokenEvaluate(ctx, "expired"))
+	assert.Equal(t, 3, retrieves)
+
+	// Failed lookups aren't cached.
+	_, err = da.TokenRetrieveByToken(ctx, "no-such-token")
+	assert.ErrorIs(t, err, errs.ErrNoSuchToken)
+	_, err = da.TokenRetrieveByToken(ctx, "no-such-token")
+	assert.ErrorIs(t, err, errs.ErrNoSuchToken)
+	assert.Equal(t, 5, retrieves)
+
+	// An invalidated token is evicted.
+	require.NoError(t, da.TokenInvalidate(ctx, "valid"))
+	assert.False(t, da.TokenEvaluate(ctx, "valid"))
+	assert.Equal(t, 6, retrieves)
+
+	// Deleting a user evicts their tokens.
+	assert.True(t, da.TokenEvaluate(ctx, "other"))
+	assert.True(t, da.TokenEvaluate(ctx, "other"))
+	assert.Equal(t, 7, retrieves)
+
+	require.NoError(t, da.UserDelete(ctx, "bob"))
+	assert.True(t, da.TokenEvaluate(ctx, "other"))
+	assert.Equal(t, 8, retrieves)
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/getgort/gort/data"
+)
+
+// AESGCMProvider encrypts values with AES in Galois/Counter Mode. Each
+// ciphertext is prefixed with its randomly generated nonce.
+type AESGCMProvider struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMProvider returns a provider that uses the given 16, 24, or 32
+// byte key.
+func NewAESGCMProvider(key []byte) (*AESGCMProvider, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AESGCMProvider{aead: aead}, nil
+}
+
+// Name returns "aes-gcm".
+func (p *AESGCMProvider) Name() string {
+	return data.EncryptionProviderAESGCM
+}
+
+// Encrypt encrypts and authenticates the plaintext.
+func (p *AESGCMProvider) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return p.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt authenticates and decrypts a ciphertext produced by Encrypt.
+func (p *AESGCMProvider) Decrypt(ciphertext []byte) ([]byte, error) {
+	n := p.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	return p.aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+)
+
+// sealedPrefix marks a stored value as encrypted. It's followed by the name
+// of the provider that encrypted it, a colon, and the base64-encoded
+// ciphertext.
+const sealedPrefix = "gort-sealed:"
+
+var (
+	// ErrNoProvider is returned when an encrypted value is read but no
+	// encryption provider is configured.
+	ErrNoProvider = errors.New("no encryption provider is configured")
+
+	// ErrProviderMismatch is returned when an encrypted value is read that
+	// was encrypted by a provider other than the configured one.
+	ErrProviderMismatch = errors.New("value was encrypted by a different provider")
+
+	// ErrMalformedValue is returned when an encrypted value can't be parsed.
+	ErrMalformedValue = errors.New("malformed encrypted value")
+)
+
+// Provider encrypts and decrypts values for storage. Implementations for
+// external key management services can be added to NewProvider.
+type Provider interface {
+	// Name identifies the provider. It's stored with each value that the
+	// provider encrypts.
+	Name() string
+
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+var warnUnencrypted sync.Once
+
+// Get returns the configured encryption provider, or nil if none is
+// configured.
+func Get() (Provider, error) {
+	return NewProvider(config.GetEncryptionConfigs())
+}
+
+// NewProvider returns the provider described by the configs, or nil if no
+// provider is set.
+func NewProvider(c data.EncryptionConfigs) (Provider, error) {
+	switch c.Provider {
+	case "":
+		return nil, nil
+
+	case data.EncryptionProviderAESGCM:
+		encoded := c.Key
+		if encoded == "" && c.KeyFile != "" {
+			b, err := os.ReadFile(c.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read encryption key: %w", err)
+			}
+			encoded = strings.TrimSpace(string(b))
+		}
+		if encoded == "" {
+			return nil, fmt.Errorf("no key is configured for the %s encryption provider", c.Provider)
+		}
+
+		key, err := data.DecodeEncryptionKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewAESGCMProvider(key)
+
+	default:
+		return nil, fmt.Errorf("unsupported encryption provider: %s", c.Provider)
+	}
+}
+
+// IsSealed returns true if the value was produced by Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Seal encrypts a value with the provider, returning a string that can be
+// stored in place of the original. If the provider is nil the value is
+// returned unchanged, and a warning is logged the first time.
+func Seal(p Provider, value string) (string, error) {
+	if p == nil {
+		warnUnencrypted.Do(func() {
+			log.Warn("No encryption provider is configured; secrets will be stored unencrypted")
+		})
+		return value, nil
+	}
+
+	ciphertext, err := p.Encrypt([]byte(value))
+	if err != nil {
+		return "", err
+	}
+
+	return sealedPrefix + p.Name() + ":" + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Open decrypts a value produced by Seal. Values that weren't sealed, such
+// as those stored before encryption was configured, are returned unchanged.
+func Open(p Provider, value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	if p == nil {
+		return "", ErrNoProvider
+	}
+
+	name, encoded, ok := cut(strings.TrimPrefix(value, sealedPrefix), ":")
+	if !ok {
+		return "", ErrMalformedValue
+	}
+	if name != p.Name() {
+		return "", fmt.Errorf("%w: %s", ErrProviderMismatch, name)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrMalformedValue
+	}
+
+	plaintext, err := p.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// SealConfiguration returns a copy of the dynamic configuration whose value
+// is encrypted by the configured provider if it's a secret.
+func SealConfiguration(dc data.DynamicConfiguration) (data.DynamicConfiguration, error) {
+	if !dc.Secret {
+		return dc, nil
+	}
+
+	p, err := Get()
+	if err != nil {
+		return dc, err
+	}
+
+	dc.Value, err = Seal(p, dc.Value)
+	return dc, err
+}
+
+// OpenConfiguration returns a copy of the dynamic configuration whose value
+// is decrypted, if it was encrypted by SealConfiguration.
+func OpenConfiguration(dc data.DynamicConfiguration) (data.DynamicConfiguration, error) {
+	if !IsSealed(dc.Value) {
+		return dc, nil
+	}
+
+	p, err := Get()
+	if err != nil {
+		return dc, err
+	}
+
+	dc.Value, err = Open(p, dc.Value)
+	return dc, err
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
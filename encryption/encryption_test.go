@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+var testKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+func TestSealOpen(t *testing.T) {
+	p, err := NewProvider(data.EncryptionConfigs{Provider: data.EncryptionProviderAESGCM, Key: testKey})
+	require.NoError(t, err)
+
+	sealed, err := Seal(p, "hunter2")
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, sealed, "hunter2")
+
+	// Each sealing uses a new nonce.
+	again, err := Seal(p, "hunter2")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+
+	opened, err := Open(p, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", opened)
+
+	// Unsealed values are passed through.
+	opened, err = Open(p, "plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", opened)
+
+	// A sealed value can't be read without the provider...
+	_, err = Open(nil, sealed)
+	assert.ErrorIs(t, err, ErrNoProvider)
+
+	// ...or with a different key.
+	other, err := NewAESGCMProvider([]byte("fedcba9876543210"))
+	require.NoError(t, err)
+	_, err = Open(other, sealed)
+	assert.Error(t, err)
+
+	_, err = Open(p, "gort-sealed:vault:abc")
+	assert.ErrorIs(t, err, ErrProviderMismatch)
+
+	_, err = Open(p, "gort-sealed:aes-gcm")
+	assert.ErrorIs(t, err, ErrMalformedValue)
+
+	// Without a provider, values are stored as they are.
+	unsealed, err := Seal(nil, "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", unsealed)
+}
+
+func TestNewProvider(t *testing.T) {
+	p, err := NewProvider(data.EncryptionConfigs{})
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	_, err = NewProvider(data.EncryptionConfigs{Provider: data.EncryptionProviderAESGCM})
+	assert.Error(t, err)
+
+	_, err = NewProvider(data.EncryptionConfigs{Provider: "kms"})
+	assert.Error(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(testKey+"\n"), 0600))
+
+	p, err = NewProvider(data.EncryptionConfigs{Provider: data.EncryptionProviderAESGCM, KeyFile: keyFile})
+	require.NoError(t, err)
+	assert.Equal(t, data.EncryptionProviderAESGCM, p.Name())
+}
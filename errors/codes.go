@@ -0,0 +1,124 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Code describes a stable, user-facing error code, such as "GORT-2001",
+// along with the information a user or operator needs to understand and
+// remediate the error it describes.
+type Code struct {
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// String returns the code itself (e.g., "GORT-2001").
+func (c Code) String() string {
+	return c.Code
+}
+
+var (
+	// codes is the error code catalog, keyed by code.
+	codes = map[string]Code{}
+
+	// codesByMessage maps an error message to its code. Errors are keyed by
+	// message to be consistent with the semantics of Is.
+	codesByMessage = map[string]string{}
+
+	codesMutex sync.RWMutex
+)
+
+// RegisterCode associates an error code with an error value. It panics if
+// either the code or the error has already been registered, since that would
+// indicate a programming error.
+func RegisterCode(err error, code Code) {
+	codesMutex.Lock()
+	defer codesMutex.Unlock()
+
+	msg := message(err)
+
+	if _, ok := codes[code.Code]; ok {
+		panic(fmt.Sprintf("duplicate error code registration: %s", code.Code))
+	}
+	if c, ok := codesByMessage[msg]; ok {
+		panic(fmt.Sprintf("error %q already registered as %s", msg, c))
+	}
+
+	codes[code.Code] = code
+	codesByMessage[msg] = code.Code
+}
+
+// CodeOf returns the code associated with an error. If err is a NestedError
+// its top-level message is checked first, followed by each of its nested
+// errors in turn.
+func CodeOf(err error) (Code, bool) {
+	codesMutex.RLock()
+	defer codesMutex.RUnlock()
+
+	for err != nil {
+		if c, ok := codesByMessage[message(err)]; ok {
+			return codes[c], true
+		}
+
+		if ne, ok := err.(NestedError); ok {
+			err = ne.Err
+		} else {
+			err = nil
+		}
+	}
+
+	return Code{}, false
+}
+
+// LookupCode returns the Code value for a code string (e.g., "GORT-2001").
+func LookupCode(code string) (Code, bool) {
+	codesMutex.RLock()
+	defer codesMutex.RUnlock()
+
+	c, ok := codes[code]
+	return c, ok
+}
+
+// Codes returns all registered codes, sorted by code.
+func Codes() []Code {
+	codesMutex.RLock()
+	defer codesMutex.RUnlock()
+
+	list := make([]Code, 0, len(codes))
+	for _, c := range codes {
+		list = append(list, c)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+
+	return list
+}
+
+// message returns an error's message as understood by Is.
+func message(err error) string {
+	if ne, ok := err.(NestedError); ok {
+		return ne.Message
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	errCoded := errors.New("test coded error")
+	errOther := errors.New("test uncoded error")
+
+	RegisterCode(errCoded, Code{Code: "GORT-9999", Title: "Test"})
+
+	if c, ok := CodeOf(errCoded); !ok || c.Code != "GORT-9999" {
+		t.Errorf("Expected GORT-9999; got %q (%v)", c.Code, ok)
+	}
+
+	if c, ok := CodeOf(Wrap(errCoded, errOther)); !ok || c.Code != "GORT-9999" {
+		t.Errorf("Expected top-level GORT-9999; got %q (%v)", c.Code, ok)
+	}
+
+	if c, ok := CodeOf(Wrap(errOther, errCoded)); !ok || c.Code != "GORT-9999" {
+		t.Errorf("Expected nested GORT-9999; got %q (%v)", c.Code, ok)
+	}
+
+	if _, ok := CodeOf(errOther); ok {
+		t.Error("Expected no code for uncoded error")
+	}
+
+	if c, ok := LookupCode("GORT-9999"); !ok || c.Title != "Test" {
+		t.Errorf("Expected to find GORT-9999; got %+v (%v)", c, ok)
+	}
+}
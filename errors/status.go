@@ -0,0 +1,119 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Status describes how an error is reported by the REST API: the HTTP status
+// it's returned with, the level it's logged at, and optionally a message
+// that's returned in place of the error's own.
+type Status struct {
+	HTTPStatus int
+	Level      log.Level
+	Message    string
+}
+
+// StatusInternal is the Status of any error that hasn't been registered.
+var StatusInternal = Status{HTTPStatus: http.StatusInternalServerError, Level: log.ErrorLevel}
+
+var (
+	// statuses maps an error message to its Status. Errors are keyed by
+	// message to be consistent with the semantics of Is.
+	statuses = map[string]Status{}
+
+	// statusPrefixes maps a message prefix to a Status, for errors whose
+	// messages are built with fmt.Errorf.
+	statusPrefixes = map[string]Status{}
+
+	statusesMutex sync.RWMutex
+)
+
+func init() {
+	RegisterStatus(Status{HTTPStatus: http.StatusNotAcceptable, Level: log.ErrorLevel, Message: "Corrupt JSON payload"}, ErrUnmarshal)
+	RegisterStatus(Status{HTTPStatus: http.StatusNotImplemented, Level: log.InfoLevel}, ErrUnsupported)
+	RegisterStatus(Status{HTTPStatus: http.StatusForbidden, Level: log.WarnLevel}, ErrNotPermitted)
+}
+
+// RegisterStatus associates a Status with one or more error values. It
+// panics if any of the errors has already been registered, since that would
+// indicate a programming error.
+func RegisterStatus(status Status, errs ...error) {
+	statusesMutex.Lock()
+	defer statusesMutex.Unlock()
+
+	for _, err := range errs {
+		msg := message(err)
+
+		if _, ok := statuses[msg]; ok {
+			panic(fmt.Sprintf("error %q already has a registered status", msg))
+		}
+
+		statuses[msg] = status
+	}
+}
+
+// RegisterStatusPrefix associates a Status with every error whose message
+// starts with prefix. It panics if the prefix has already been registered.
+func RegisterStatusPrefix(prefix string, status Status) {
+	statusesMutex.Lock()
+	defer statusesMutex.Unlock()
+
+	if _, ok := statusPrefixes[prefix]; ok {
+		panic(fmt.Sprintf("error prefix %q already has a registered status", prefix))
+	}
+
+	statusPrefixes[prefix] = status
+}
+
+// StatusOf returns the Status associated with an error. Like CodeOf, if err
+// is a NestedError its top-level message is checked first, followed by each
+// of its nested errors in turn; errors wrapped with fmt.Errorf's %w verb are
+// unwrapped the same way.
+func StatusOf(err error) (Status, bool) {
+	statusesMutex.RLock()
+	defer statusesMutex.RUnlock()
+
+	for err != nil {
+		msg := message(err)
+
+		if s, ok := statuses[msg]; ok {
+			return s, true
+		}
+
+		for prefix, s := range statusPrefixes {
+			if strings.HasPrefix(msg, prefix) {
+				return s, true
+			}
+		}
+
+		if ne, ok := err.(NestedError); ok {
+			err = ne.Err
+		} else {
+			err = errors.Unwrap(err)
+		}
+	}
+
+	return Status{}, false
+}
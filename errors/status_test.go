@@ -0,0 +1,56 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestStatusOf(t *testing.T) {
+	errStatus := errors.New("test status error")
+	errOther := errors.New("test status-less error")
+
+	RegisterStatus(Status{HTTPStatus: http.StatusTeapot}, errStatus)
+	RegisterStatusPrefix("test status prefix:", Status{HTTPStatus: http.StatusGone})
+
+	if s, ok := StatusOf(errStatus); !ok || s.HTTPStatus != http.StatusTeapot {
+		t.Errorf("Expected %d; got %d (%v)", http.StatusTeapot, s.HTTPStatus, ok)
+	}
+
+	if s, ok := StatusOf(Wrap(errOther, errStatus)); !ok || s.HTTPStatus != http.StatusTeapot {
+		t.Errorf("Expected nested %d; got %d (%v)", http.StatusTeapot, s.HTTPStatus, ok)
+	}
+
+	if s, ok := StatusOf(fmt.Errorf("%w: with detail", errStatus)); !ok || s.HTTPStatus != http.StatusTeapot {
+		t.Errorf("Expected unwrapped %d; got %d (%v)", http.StatusTeapot, s.HTTPStatus, ok)
+	}
+
+	if s, ok := StatusOf(fmt.Errorf("test status prefix: %d", 42)); !ok || s.HTTPStatus != http.StatusGone {
+		t.Errorf("Expected prefixed %d; got %d (%v)", http.StatusGone, s.HTTPStatus, ok)
+	}
+
+	if _, ok := StatusOf(errOther); ok {
+		t.Error("Expected no status for unregistered error")
+	}
+
+	if s, ok := StatusOf(ErrUnmarshal); !ok || s.Message == "" {
+		t.Errorf("Expected a replacement message for ErrUnmarshal; got %+v (%v)", s, ok)
+	}
+}
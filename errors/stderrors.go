@@ -29,4 +29,12 @@ var (
 
 	// ErrUnmarshal is returned when an item can't be unmarshaled from JSON/YAML.
 	ErrUnmarshal = errors.New("unmarshalling error")
+
+	// ErrUnsupported is returned when an operation isn't supported by the
+	// component (for example, a chat provider) that's asked to perform it.
+	ErrUnsupported = errors.New("operation not supported")
+
+	// ErrNotPermitted is returned when the user that requested an operation
+	// isn't permitted to perform it.
+	ErrNotPermitted = errors.New("operation not permitted")
 )
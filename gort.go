@@ -34,6 +34,7 @@ import (
 	"github.com/getgort/gort/service"
 	"github.com/getgort/gort/telemetry"
 	"github.com/getgort/gort/version"
+	"github.com/getgort/gort/worker"
 )
 
 func initializeConfig(configFile string) error {
@@ -98,14 +99,48 @@ func startGort(ctx context.Context, configFile string, verboseCount int) error {
 
 	log.WithField("version", version.Version).Infof("Starting Gort")
 
+	for _, f := range data.FeatureFlags {
+		log.WithField("feature", f.Name).
+			WithField("enabled", config.FeatureEnabled(f.Name)).
+			Info("Feature flag")
+	}
+
 	err = installAdapters()
 	if err != nil {
 		return err
 	}
 
+	service.SetAnnouncer(adapter.Announce)
+	service.SetCanaryReporter(adapter.CanaryStatuses)
+	service.SetChannelManager(adapter.ChannelManager{})
+	service.SetDirectMessenger(adapter.SendDirectMessage)
+	service.SetExecutor(adapter.Exec)
+	service.SetRedeliverer(adapter.Redeliver)
+	service.SetReplayer(adapter.Replay)
+	service.SetResponder(adapter.Respond)
+	service.SetSelfTester(adapter.SelfTest)
+
+	// Make sure that the namespaces that commands run in exist
+	if err := worker.CheckNamespaces(ctx); err != nil {
+		return err
+	}
+
 	// Start the Gort REST web service
 	startServer(ctx, config.GetGortServerConfigs())
 
+	// Report any dangling references in the data store
+	go service.CheckConsistency(ctx)
+
+	// Periodically remove deleted users and groups past their retention
+	go service.StartDeletedPurge(ctx)
+
+	// Periodically remove expired tokens, locks, secret outputs, and old
+	// dead letters
+	go service.StartJanitor(ctx)
+
+	// Periodically check the health of any worker clusters
+	go worker.StartHealthChecks(ctx)
+
 	// Tells the chat provider adapters (as defined in the config) to connect.
 	// Returns channels to get user command requests and adapter errors out.
 	requestsFrom, responsesTo, adapterErrorsFrom := adapter.StartListening(ctx)
@@ -115,6 +150,12 @@ func startGort(ctx context.Context, configFile string, verboseCount int) error {
 	// responses out.
 	requestsTo, responsesFrom := relay.StartListening()
 
+	// Periodically run any configured canary commands through the pipeline
+	go adapter.StartCanaries(ctx)
+
+	// Periodically roll back newly enabled bundle versions that are failing
+	go adapter.StartAutoRollback(ctx)
+
 	for {
 		select {
 		// A user command request is received from a chat provider adapter.
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+)
+
+// ErrInvalidIDToken is returned by Verify if an ID token is malformed, isn't
+// signed by the provider, or has an unexpected issuer, audience, or nonce.
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+// Claims are the claims of a verified ID token.
+type Claims map[string]interface{}
+
+// String returns the value of a string claim, or "" if it's missing or isn't
+// a string.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns the values of a claim that's either a string or an array
+// of strings, or nil if it's missing.
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var s []string
+		for _, i := range v {
+			if str, ok := i.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	default:
+		return nil
+	}
+}
+
+// Verify verifies an ID token's signature, issuer, audience, and expiry, and
+// returns its claims. If nonce isn't empty the token's nonce must match it.
+func (p *Provider) Verify(ctx context.Context, raw string, nonce string) (Claims, error) {
+	token, err := p.verifier.Verify(gooidc.ClientContext(ctx, p.client), raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIDToken, err)
+	}
+
+	if nonce != "" && token.Nonce != nonce {
+		return nil, fmt.Errorf("%w: unexpected nonce", ErrInvalidIDToken)
+	}
+
+	var claims Claims
+	if err := token.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
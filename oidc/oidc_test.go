@@ -0,0 +1,248 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider is an identity provider that issues ID tokens for "alice".
+type fakeProvider struct {
+	*httptest.Server
+	key   *rsa.PrivateKey
+	nonce string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	fp := &fakeProvider{key: key}
+	mux := http.NewServeMux()
+	fp.Server = httptest.NewServer(mux)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{
+			Issuer:                      fp.URL,
+			AuthorizationEndpoint:       fp.URL + "/authorize",
+			TokenEndpoint:               fp.URL + "/token",
+			DeviceAuthorizationEndpoint: fp.URL + "/device",
+			JWKSURI:                     fp.URL + "/keys",
+		})
+	})
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		e := big.NewInt(int64(key.E)).Bytes()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(e),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(DeviceAuthorization{
+			DeviceCode:      "device-code",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: fp.URL + "/activate",
+			ExpiresIn:       600,
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		user, pass, _ := r.BasicAuth()
+		if user != "gort" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(errorResponse{Error: "invalid_client"})
+			return
+		}
+
+		switch r.FormValue("code") + r.FormValue("device_code") {
+		case "good", "device-code":
+			json.NewEncoder(w).Encode(TokenResponse{
+				AccessToken: "access",
+				TokenType:   "Bearer",
+				IDToken:     fp.sign(t, fp.claims()),
+			})
+		case "pending":
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(errorResponse{Error: "authorization_pending"})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(errorResponse{Error: "access_denied"})
+		}
+	})
+
+	return fp
+}
+
+func (fp *fakeProvider) claims() Claims {
+	return Claims{
+		"iss":                fp.URL,
+		"aud":                "gort",
+		"sub":                "1234",
+		"exp":                time.Now().Add(time.Minute).Unix(),
+		"nonce":              fp.nonce,
+		"preferred_username": "alice",
+		"groups":             []string{"sre", "dev"},
+	}
+}
+
+func (fp *fakeProvider) sign(t *testing.T, claims Claims) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1"})
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, fp.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+	fp := newFakeProvider(t)
+	defer fp.Close()
+
+	config := Config{
+		Issuer:       fp.URL,
+		ClientID:     "gort",
+		ClientSecret: "secret",
+		RedirectURL:  "https://gort.example.com/v2/auth/oidc/callback",
+		Scopes:       []string{"openid", "profile"},
+	}
+
+	p, err := NewProvider(ctx, config, nil)
+	require.NoError(t, err)
+
+	u, err := url.Parse(p.AuthCodeURL("the-state", "the-nonce"))
+	require.NoError(t, err)
+	assert.Equal(t, fp.URL+"/authorize", strings.Split(u.String(), "?")[0])
+	assert.Equal(t, "the-state", u.Query().Get("state"))
+	assert.Equal(t, "the-nonce", u.Query().Get("nonce"))
+	assert.Equal(t, "openid profile", u.Query().Get("scope"))
+
+	// The authorization code flow
+	fp.nonce = "the-nonce"
+	tr, err := p.Exchange(ctx, "good")
+	require.NoError(t, err)
+
+	claims, err := p.Verify(ctx, tr.IDToken, "the-nonce")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.String("preferred_username"))
+	assert.Equal(t, []string{"sre", "dev"}, claims.Strings("groups"))
+
+	_, err = p.Verify(ctx, tr.IDToken, "another-nonce")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+
+	_, err = p.Exchange(ctx, "bad")
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	// The device authorization flow
+	da, err := p.DeviceAuthorization(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-EFGH", da.UserCode)
+	assert.EqualValues(t, 5, da.Interval)
+
+	_, err = p.DeviceToken(ctx, "pending")
+	assert.ErrorIs(t, err, ErrAuthorizationPending)
+
+	tr, err = p.DeviceToken(ctx, da.DeviceCode)
+	require.NoError(t, err)
+	_, err = p.Verify(ctx, tr.IDToken, "")
+	assert.NoError(t, err)
+
+	// The wrong client secret
+	bad, err := NewProvider(ctx, Config{Issuer: fp.URL, ClientID: "gort", ClientSecret: "wrong"}, nil)
+	require.NoError(t, err)
+	_, err = bad.Exchange(ctx, "good")
+	assert.Error(t, err)
+
+	// An issuer that doesn't match its discovery document
+	_, err = NewProvider(ctx, Config{Issuer: fp.URL + "/other"}, nil)
+	assert.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	fp := newFakeProvider(t)
+	defer fp.Close()
+
+	p, err := NewProvider(ctx, Config{Issuer: fp.URL, ClientID: "gort"}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name   string
+		Modify func(c Claims)
+		Valid  bool
+	}{
+		{"valid", func(c Claims) {}, true},
+		{"audience list", func(c Claims) { c["aud"] = []string{"other", "gort"} }, true},
+		{"wrong audience", func(c Claims) { c["aud"] = "other" }, false},
+		{"wrong issuer", func(c Claims) { c["iss"] = "https://evil.example.com" }, false},
+		{"expired", func(c Claims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, false},
+		{"no expiry", func(c Claims) { delete(c, "exp") }, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			claims := fp.claims()
+			test.Modify(claims)
+
+			_, err := p.Verify(ctx, fp.sign(t, claims), "")
+			if test.Valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidIDToken)
+			}
+		})
+	}
+
+	// A token whose claims were changed after it was signed
+	parts := strings.Split(fp.sign(t, fp.claims()), ".")
+	forged := fp.claims()
+	forged["preferred_username"] = "admin"
+	payload, _ := json.Marshal(forged)
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+
+	_, err = p.Verify(ctx, strings.Join(parts, "."), "")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+}
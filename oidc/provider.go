@@ -0,0 +1,327 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package oidc logs users in with an OpenID Connect identity provider.
+// Discovery, the authorization code flow, and ID token verification are
+// handled by go-oidc and golang.org/x/oauth2; the device authorization flow
+// of RFC 8628, which they don't support, is implemented here.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// ErrAuthorizationPending is returned by DeviceToken if the user hasn't
+	// yet completed the device authorization.
+	ErrAuthorizationPending = errors.New("authorization pending")
+
+	// ErrSlowDown is returned by DeviceToken if it's being called too
+	// often. The polling interval should be increased by 5 seconds.
+	ErrSlowDown = errors.New("polling too frequently")
+
+	// ErrAccessDenied is returned if the user denied the authorization.
+	ErrAccessDenied = errors.New("access denied")
+
+	// ErrExpired is returned by DeviceToken if the device code has
+	// expired.
+	ErrExpired = errors.New("device code has expired")
+
+	// ErrNoDeviceFlow is returned by DeviceAuthorization if the provider
+	// doesn't support the device authorization flow.
+	ErrNoDeviceFlow = errors.New("provider doesn't support the device authorization flow")
+)
+
+// Config describes Gort's client at an identity provider.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Metadata is the subset of a provider's discovery document that's used.
+type Metadata struct {
+	Issuer                      string   `json:"issuer"`
+	AuthorizationEndpoint       string   `json:"authorization_endpoint"`
+	TokenEndpoint               string   `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string   `json:"device_authorization_endpoint,omitempty"`
+	JWKSURI                     string   `json:"jwks_uri"`
+	TokenEndpointAuthMethods    []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+}
+
+// TokenResponse is a successful response from the token endpoint.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+}
+
+// DeviceAuthorization is a response from the device authorization endpoint.
+// The user completes the authorization by visiting VerificationURI and
+// entering UserCode.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval,omitempty"`
+}
+
+// errorResponse is an error response from the token or device
+// authorization endpoints, as described by RFC 6749 section 5.2.
+type errorResponse struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// Provider is an identity provider whose configuration has been discovered.
+// It's safe for concurrent use.
+type Provider struct {
+	config   Config
+	client   *http.Client
+	metadata Metadata
+	oauth2   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewProvider discovers the configuration of the provider at
+// config.Issuer. If client is nil, http.DefaultClient is used.
+func NewProvider(ctx context.Context, config Config, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	p := &Provider{config: config, client: client}
+
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, client), config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+
+	if err := provider.Claims(&p.metadata); err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	if p.metadata.TokenEndpoint == "" || p.metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document is missing the token endpoint or JWKS URI")
+	}
+
+	endpoint := provider.Endpoint()
+	endpoint.AuthStyle = oauth2.AuthStyleInParams
+	if p.basicAuth() && config.ClientSecret != "" {
+		endpoint.AuthStyle = oauth2.AuthStyleInHeader
+	}
+
+	p.oauth2 = oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Endpoint:     endpoint,
+		RedirectURL:  config.RedirectURL,
+		Scopes:       config.Scopes,
+	}
+
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: config.ClientID})
+
+	return p, nil
+}
+
+// Metadata returns the provider's discovered configuration.
+func (p *Provider) Metadata() Metadata {
+	return p.metadata
+}
+
+// AuthCodeURL returns the URL that a browser is redirected to in order to
+// begin the authorization code flow. The state and nonce are returned to
+// the redirect URL and in the ID token, respectively.
+func (p *Provider) AuthCodeURL(state, nonce string) string {
+	return p.oauth2.AuthCodeURL(state, gooidc.Nonce(nonce))
+}
+
+// Exchange exchanges an authorization code for tokens.
+func (p *Provider) Exchange(ctx context.Context, code string) (TokenResponse, error) {
+	token, err := p.oauth2.Exchange(gooidc.ClientContext(ctx, p.client), code)
+	if err != nil {
+		var re *oauth2.RetrieveError
+		if errors.As(err, &re) {
+			return TokenResponse{}, responseError(re.Response, re.Body)
+		}
+		return TokenResponse{}, err
+	}
+
+	tr := TokenResponse{
+		AccessToken:  token.AccessToken,
+		TokenType:    token.TokenType,
+		RefreshToken: token.RefreshToken,
+	}
+	tr.IDToken, _ = token.Extra("id_token").(string)
+	if !token.Expiry.IsZero() {
+		tr.ExpiresIn = int64(time.Until(token.Expiry).Seconds())
+	}
+
+	if tr.IDToken == "" {
+		return TokenResponse{}, fmt.Errorf("token response has no ID token")
+	}
+
+	return tr, nil
+}
+
+// DeviceAuthorization begins the device authorization flow of RFC 8628.
+func (p *Provider) DeviceAuthorization(ctx context.Context) (DeviceAuthorization, error) {
+	if p.metadata.DeviceAuthorizationEndpoint == "" {
+		return DeviceAuthorization{}, ErrNoDeviceFlow
+	}
+
+	req, err := p.formRequest(ctx, p.metadata.DeviceAuthorizationEndpoint, url.Values{
+		"scope": {strings.Join(p.config.Scopes, " ")},
+	})
+	if err != nil {
+		return DeviceAuthorization{}, err
+	}
+
+	var da DeviceAuthorization
+	if err := p.do(req, &da); err != nil {
+		return DeviceAuthorization{}, err
+	}
+
+	if da.Interval == 0 {
+		da.Interval = 5
+	}
+
+	return da, nil
+}
+
+// DeviceToken polls for the tokens of a device authorization. Until the
+// user completes it, ErrAuthorizationPending or ErrSlowDown is returned.
+func (p *Provider) DeviceToken(ctx context.Context, deviceCode string) (TokenResponse, error) {
+	req, err := p.formRequest(ctx, p.metadata.TokenEndpoint, url.Values{
+		"device_code": {deviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	})
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	var tr TokenResponse
+	if err := p.do(req, &tr); err != nil {
+		return TokenResponse{}, err
+	}
+
+	if tr.IDToken == "" {
+		return TokenResponse{}, fmt.Errorf("token response has no ID token")
+	}
+
+	return tr, nil
+}
+
+// basicAuth returns whether the client secret is sent with HTTP basic
+// authentication, which it is unless the provider only supports sending it
+// in the form.
+func (p *Provider) basicAuth() bool {
+	basic := len(p.metadata.TokenEndpointAuthMethods) == 0
+	for _, m := range p.metadata.TokenEndpointAuthMethods {
+		if m == "client_secret_basic" {
+			basic = true
+		}
+	}
+
+	return basic
+}
+
+// formRequest builds a form POST to an endpoint that requires client
+// authentication.
+func (p *Provider) formRequest(ctx context.Context, endpoint string, form url.Values) (*http.Request, error) {
+	basic := p.basicAuth()
+
+	form.Set("client_id", p.config.ClientID)
+	if !basic && p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if basic && p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	return req, nil
+}
+
+// do sends req and decodes its JSON response into v. An OAuth 2.0 error
+// response is converted to the corresponding error value.
+func (p *Provider) do(req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, body)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// responseError converts the body of an unsuccessful response from the
+// token or device authorization endpoints to an error.
+func responseError(resp *http.Response, body []byte) error {
+	var e errorResponse
+	if json.Unmarshal(body, &e) != nil || e.Error == "" {
+		return fmt.Errorf("%s returned %s", resp.Request.URL.Path, resp.Status)
+	}
+
+	switch e.Error {
+	case "authorization_pending":
+		return ErrAuthorizationPending
+	case "slow_down":
+		return ErrSlowDown
+	case "access_denied":
+		return ErrAccessDenied
+	case "expired_token":
+		return ErrExpired
+	}
+
+	if e.Description != "" {
+		return fmt.Errorf("%s: %s", e.Error, e.Description)
+	}
+	return errors.New(e.Error)
+}
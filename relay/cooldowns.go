@@ -0,0 +1,60 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// startCooldown starts the request command's cooldown in the channel the
+// request came from. Cooldowns are stored as locks that are never released:
+// the lock simply expires at the end of the cooldown window. This way the
+// cooldown is shared by every Gort controller using the same data store, and
+// survives restarts. If the command is still cooling down from an earlier
+// invocation, the returned error describes how long remains.
+func startCooldown(ctx context.Context, da dataaccess.DataAccess, request data.CommandRequest, cooldown time.Duration) error {
+	name := fmt.Sprintf("cooldown/%s/%s/%s:%s",
+		request.Adapter, request.ChannelID, request.Bundle.Name, request.Command.Name)
+	owner := strconv.FormatInt(request.RequestID, 10)
+
+	held, err := da.LockAcquire(ctx, name, owner, request.UserName, cooldown)
+	switch {
+	case gerrs.Is(err, errs.ErrLockHeld):
+		remaining := time.Until(held.ExpiresAt).Round(time.Second)
+		if remaining < time.Second {
+			remaining = time.Second
+		}
+
+		msg := fmt.Sprintf("%s:%s has a %s cooldown, and was run in this channel by %s %s ago. Try again in %s.",
+			request.Bundle.Name, request.Command.Name, cooldown, held.UserName,
+			time.Since(held.AcquiredAt).Round(time.Second), remaining)
+		return gerrs.Wrap(ErrCommandCooldown, errors.New(msg))
+	case err != nil:
+		return err
+	}
+
+	return nil
+}
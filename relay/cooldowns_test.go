@@ -0,0 +1,77 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/memory"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+func cooldownRequest(id int64, channel string) data.CommandRequest {
+	return data.CommandRequest{
+		CommandEntry: data.CommandEntry{
+			Bundle:  data.Bundle{Name: "test"},
+			Command: data.BundleCommand{Name: "echo"},
+		},
+		Adapter:   "slack",
+		ChannelID: channel,
+		RequestID: id,
+		UserName:  "alice",
+	}
+}
+
+func TestStartCooldownAllow(t *testing.T) {
+	ctx := context.Background()
+	da := memory.NewInMemoryDataAccess()
+
+	require.NoError(t, startCooldown(ctx, da, cooldownRequest(1, "C-ALLOW-1"), time.Minute))
+
+	// Cooldowns are per channel.
+	assert.NoError(t, startCooldown(ctx, da, cooldownRequest(2, "C-ALLOW-2"), time.Minute))
+}
+
+func TestStartCooldownDeny(t *testing.T) {
+	ctx := context.Background()
+	da := memory.NewInMemoryDataAccess()
+
+	require.NoError(t, startCooldown(ctx, da, cooldownRequest(1, "C-DENY"), time.Minute))
+
+	err := startCooldown(ctx, da, cooldownRequest(2, "C-DENY"), time.Minute)
+	require.Error(t, err)
+	assert.True(t, gerrs.Is(err, ErrCommandCooldown))
+	assert.Contains(t, err.Error(), "test:echo has a 1m0s cooldown, and was run in this channel by alice")
+}
+
+func TestStartCooldownExpiry(t *testing.T) {
+	ctx := context.Background()
+	da := memory.NewInMemoryDataAccess()
+
+	require.NoError(t, startCooldown(ctx, da, cooldownRequest(1, "C-EXPIRY"), 50*time.Millisecond))
+	require.Error(t, startCooldown(ctx, da, cooldownRequest(2, "C-EXPIRY"), 50*time.Millisecond))
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.NoError(t, startCooldown(ctx, da, cooldownRequest(3, "C-EXPIRY"), 50*time.Millisecond))
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// recordCost charges the compute used by a command execution that ran for
+// d, if cost accounting is enabled. The execution is charged to the
+// requesting user's group that sorts first by name, consistent with how
+// group service accounts are chosen. Failures are logged but otherwise
+// ignored: accounting never fails a command.
+func recordCost(ctx context.Context, da dataaccess.DataAccess, request data.CommandRequest, d time.Duration) {
+	c := config.GetGlobalConfigs().Costs
+	if !c.Enabled || d <= 0 {
+		return
+	}
+
+	le := log.WithField("request.id", request.RequestID)
+
+	groups, err := da.UserGroupList(ctx, request.UserName)
+	if err != nil {
+		le.WithError(err).Warn("Failed to list user groups; execution cost not recorded")
+		return
+	}
+
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	sort.Strings(names)
+
+	var group string
+	if len(names) > 0 {
+		group = names[0]
+	}
+
+	record, err := data.NewCostRecord(c, request.Bundle, group, request.Timestamp, d)
+	if err != nil {
+		le.WithError(err).Warn("Invalid bundle resources; execution cost not recorded")
+		return
+	}
+
+	if err := da.CostAdd(ctx, record); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to record execution cost")
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"errors"
+
+	gerrs "github.com/getgort/gort/errors"
+)
+
+var (
+	// ErrCommandFailed is returned when a command exits with a non-zero
+	// status.
+	ErrCommandFailed = errors.New("command exited with a non-zero status")
+
+	// ErrCommandCooldown is returned when a command is requested again in
+	// the same channel before its cooldown has elapsed.
+	ErrCommandCooldown = errors.New("command is cooling down")
+
+	// ErrCommandUsage is returned when a command's parameters don't match
+	// its declared usage.
+	ErrCommandUsage = errors.New("invalid command usage")
+
+	// ErrCommandTimeout is returned when a command fails to complete within
+	// the configured command timeout.
+	ErrCommandTimeout = errors.New("command timed out")
+
+	// ErrPipelineInputTooLarge is returned when a command of a pipeline
+	// produces more output than can be passed to the next command.
+	ErrPipelineInputTooLarge = errors.New("pipeline input is too large")
+
+	// ErrPipelineStopped is recorded for the commands of a pipeline that
+	// weren't executed because an earlier command failed.
+	ErrPipelineStopped = errors.New("pipeline stopped by a failed command")
+
+	// ErrDynamicConfigurationLoad is returned when the dynamic
+	// configurations for a command request can't be loaded.
+	ErrDynamicConfigurationLoad = errors.New("failed to load dynamic configurations")
+
+	// ErrWorkerSpawn is returned when a worker can't be created.
+	ErrWorkerSpawn = errors.New("failed to spawn worker")
+
+	// ErrWorkerStart is returned when a worker is created but can't be
+	// started.
+	ErrWorkerStart = errors.New("failed to start worker")
+)
+
+// Worker error codes are in the GORT-3xxx range.
+func init() {
+	gerrs.RegisterCode(ErrWorkerSpawn, gerrs.Code{
+		Code:        "GORT-3001",
+		Title:       "Failed to spawn worker",
+		Description: "Gort couldn't create a worker to execute the command.",
+		Remediation: "Check that the docker or kubernetes section of the Gort configuration is valid, and see the controller logs for details.",
+	})
+	gerrs.RegisterCode(ErrWorkerStart, gerrs.Code{
+		Code:        "GORT-3002",
+		Title:       "Failed to start worker",
+		Description: "The worker was created but couldn't be started. This is commonly caused by an image that can't be pulled.",
+		Remediation: "Verify that the bundle's image exists and can be pulled by the worker host or cluster.",
+	})
+	gerrs.RegisterCode(ErrDynamicConfigurationLoad, gerrs.Code{
+		Code:        "GORT-3003",
+		Title:       "Failed to load dynamic configurations",
+		Description: "The dynamic configurations for the command couldn't be loaded.",
+		Remediation: "Check the health of the data store and see the controller logs for details.",
+	})
+	gerrs.RegisterCode(ErrCommandTimeout, gerrs.Code{
+		Code:        "GORT-3004",
+		Title:       "Command timed out",
+		Description: "The command didn't complete within the configured command timeout.",
+		Remediation: "Try again with a smaller request, or ask a Gort administrator to increase global.command_timeout.",
+	})
+	gerrs.RegisterCode(ErrCommandFailed, gerrs.Code{
+		Code:        "GORT-3005",
+		Title:       "Command failed",
+		Description: "The command ran but exited with a non-zero status. Its output usually describes the problem.",
+		Remediation: "Check the command's output and usage. If the problem persists, contact the bundle author.",
+	})
+	gerrs.RegisterCode(ErrCommandCooldown, gerrs.Code{
+		Code:        "GORT-3006",
+		Title:       "Command cooling down",
+		Description: "The command has a cooldown, and was already run in this channel within the cooldown window.",
+		Remediation: "Wait for the cooldown to elapse, then try again.",
+	})
+	gerrs.RegisterCode(ErrCommandUsage, gerrs.Code{
+		Code:        "GORT-3007",
+		Title:       "Invalid command usage",
+		Description: "The command was passed parameters that don't match its declared usage, so it wasn't run.",
+		Remediation: "Check the usage shown with the error, or run the command with --help.",
+	})
+	gerrs.RegisterCode(ErrPipelineInputTooLarge, gerrs.Code{
+		Code:        "GORT-3008",
+		Title:       "Pipeline input too large",
+		Description: "A command of a pipeline produced more output than can be passed to the command piped from it.",
+		Remediation: "Filter the output earlier in the pipeline, or ask a Gort administrator to increase global.input_files.max_size.",
+	})
+}
@@ -25,6 +25,10 @@ const (
 	// ExitGeneral is catchall for otherwise unspecified errors.
 	ExitGeneral = 1
 
+	// ExitUsage represents a command that was used incorrectly, such as with
+	// an unknown option.
+	ExitUsage = 64
+
 	// ExitNoUser represents a "user unknown" error.
 	ExitNoUser = 67
 
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// lockTTL is how long an exclusive command lock is held without renewal.
+// Locks are renewed at a third of this interval for as long as the command
+// is running, so a lock is only left to expire if its relay goes away.
+const lockTTL = 30 * time.Second
+
+// acquireLock acquires the lock named by the request command's Exclusive
+// value and begins renewing it in the background. The returned function
+// stops the renewal and releases the lock. If the lock is held by another
+// request, the returned error describes the holder.
+func acquireLock(ctx context.Context, da dataaccess.DataAccess, request data.CommandRequest) (func(), error) {
+	name := request.Command.Exclusive
+	owner := strconv.FormatInt(request.RequestID, 10)
+
+	held, err := da.LockAcquire(ctx, name, owner, request.UserName, lockTTL)
+	switch {
+	case gerrs.Is(err, errs.ErrLockHeld):
+		msg := fmt.Sprintf("The %q lock is held by %s (request %s) since %s.",
+			name, held.UserName, held.Owner, held.AcquiredAt.Format(time.RFC1123))
+		return nil, gerrs.Wrap(errs.ErrLockHeld, errors.New(msg))
+	case err != nil:
+		return nil, err
+	}
+
+	renewCtx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		ticker := time.NewTicker(lockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := da.LockRenew(renewCtx, name, owner, lockTTL); err != nil {
+					log.WithError(err).
+						WithField("lock", name).
+						WithField("request.id", request.RequestID).
+						Warn("Failed to renew command lock")
+				}
+			}
+		}
+	}()
+
+	release := func() {
+		cancel()
+
+		if err := da.LockRelease(context.Background(), name, owner); err != nil {
+			log.WithError(err).
+				WithField("lock", name).
+				WithField("request.id", request.RequestID).
+				Warn("Failed to release command lock")
+		}
+	}
+
+	return release, nil
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// storeResponse persists a response in the outbox, if global.outbox is
+// enabled, before it's passed to the adapters to be sent. If Gort stops
+// before the response is delivered, it's resent once Gort restarts.
+// Failures are logged, and the response is sent regardless.
+func storeResponse(ctx context.Context, envelope data.CommandResponseEnvelope) {
+	if !config.GetGlobalConfigs().Outbox.Enabled || envelope.Request.RequestID == 0 {
+		return
+	}
+
+	le := log.WithField("request.id", envelope.Request.RequestID)
+
+	entry, err := data.NewOutboxEntry(envelope)
+	if err != nil {
+		le.WithError(err).Error("Failed to encode response for the outbox")
+		return
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		le.WithError(err).Warn("Failed to get data access; response not stored in the outbox")
+		return
+	}
+
+	if err := da.OutboxCreate(ctx, entry); err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		le.WithError(err).Error("Failed to store response in the outbox")
+	}
+}
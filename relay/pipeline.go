@@ -0,0 +1,110 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// handlePipeline executes a request and then, as long as each succeeds, the
+// commands piped from it, passing each the output of the one before as its
+// input file. The returned envelope is that of the last command executed,
+// with the commands executed before it summarized in its Pipeline.
+func handlePipeline(ctx context.Context, request data.CommandRequest) data.CommandResponseEnvelope {
+	envelope := handleRequest(ctx, request)
+
+	var stages []data.PipelineStage
+	next := request.Next
+
+	for ; next != nil && succeeded(envelope); next = next.Next {
+		stages = append(stages, data.NewPipelineStage(envelope))
+
+		stage := *next
+		f, err := pipeInputFile(envelope)
+		if err != nil {
+			envelope = data.NewCommandResponseEnvelope(
+				stage,
+				data.WithError("Pipeline Input Too Large", err, ExitIoErr),
+			)
+			closeRequest(ctx, envelope)
+			next = next.Next
+			break
+		}
+
+		stage.InputFile = f
+		envelope = handleRequest(ctx, stage)
+	}
+
+	// The commands after a failed one were never executed, but their
+	// requests were begun along with the rest of the pipeline.
+	for ; next != nil; next = next.Next {
+		err := gerrs.Wrap(ErrPipelineStopped, fmt.Errorf("%s:%s failed", envelope.Request.Bundle.Name, envelope.Request.Command.Name))
+		closeRequest(ctx, data.NewCommandResponseEnvelope(
+			*next,
+			data.WithError("Pipeline Stopped", err, ExitGeneral),
+		))
+	}
+
+	envelope.Request.Next = nil
+	envelope.Pipeline = stages
+
+	return envelope
+}
+
+// succeeded returns true if the command described by the envelope completed
+// successfully.
+func succeeded(e data.CommandResponseEnvelope) bool {
+	return e.Data.Error == nil && e.Data.ExitCode == ExitOK && !e.Data.TimedOut
+}
+
+// pipeInputFile returns the output of the command described by the envelope
+// as an input file for the command piped from it. An error is returned if the
+// output is larger than the largest input file accepted.
+func pipeInputFile(e data.CommandResponseEnvelope) (*data.InputFile, error) {
+	if max := config.GetGlobalConfigs().InputFiles.MaxSizeOrDefault(); len(e.Response.Out) > max {
+		return nil, gerrs.Wrap(ErrPipelineInputTooLarge,
+			fmt.Errorf("output of %s:%s is %d bytes; the limit is %d", e.Request.Bundle.Name, e.Request.Command.Name, len(e.Response.Out), max))
+	}
+
+	contentType := "text/plain"
+	if e.Response.Structured {
+		contentType = "application/json"
+	}
+
+	return &data.InputFile{
+		Name:        e.Request.Command.Name + ".out",
+		ContentType: contentType,
+		Data:        []byte(e.Response.Out),
+	}, nil
+}
+
+// closeRequest records the result of a request that handleRequest never
+// saw.
+func closeRequest(ctx context.Context, envelope data.CommandResponseEnvelope) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		return
+	}
+
+	da.RequestClose(ctx, envelope)
+}
@@ -18,14 +18,18 @@ package relay
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"strings"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/getgort/gort/auth"
+	"github.com/getgort/gort/bundles"
+	"github.com/getgort/gort/cluster"
 	"github.com/getgort/gort/config"
 	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/data/rest"
@@ -52,7 +56,12 @@ func StartListening() (chan<- data.CommandRequest, <-chan data.CommandResponseEn
 	go func() {
 		for commandRequest := range commandRequests {
 			go func(request data.CommandRequest) {
-				commandResponses <- handleRequest(request.Context, request)
+				cluster.RequestStarted()
+				envelope := handlePipeline(request.Context, request)
+				cluster.RequestFinished()
+
+				storeResponse(context.Background(), envelope)
+				commandResponses <- envelope
 			}(commandRequest)
 		}
 	}()
@@ -78,6 +87,13 @@ func SpawnWorker(ctx context.Context, command data.CommandRequest) (worker.Worke
 		return nil, err
 	}
 
+	// The worker token is good only for this request, and only until its
+	// deadline, and is only accepted by the worker API.
+	command.WorkerToken, err = auth.SignWorkerToken(command)
+	if err != nil {
+		return nil, err
+	}
+
 	return worker.New(command, token)
 }
 
@@ -101,12 +117,33 @@ func getUser(ctx context.Context, username string) (rest.User, error) {
 // up after worker processes. It receives incoming command requests from the
 // StartListening() CommandRequest channel, returning a CommandResponse which
 // in turn gets forwarded to that function's CommandRequest channel.
-func handleRequest(ctx context.Context, request data.CommandRequest) data.CommandResponseEnvelope {
+func handleRequest(ctx context.Context, request data.CommandRequest) (envelope data.CommandResponseEnvelope) {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "relay.handleRequest")
 	defer sp.End()
 
-	var envelope data.CommandResponseEnvelope
+	start := time.Now()
+
+	// Bundles may declare static attributes (like the owning team) that are
+	// added to the spans and metrics of their command executions.
+	attributes := append([]attribute.KeyValue{
+		attribute.String("bundle.name", request.Bundle.Name),
+		attribute.String("command.name", request.Command.Name),
+	}, telemetry.BundleAttributes(request.Bundle.Telemetry)...)
+	sp.SetAttributes(attributes...)
+
+	defer func() {
+		telemetry.CommandExecutions().
+			WithAttributes(attributes...).
+			WithAttribute("exit_code", int(envelope.Data.ExitCode)).
+			Commit(ctx)
+		telemetry.CommandDuration(ctx, time.Since(start), attributes...)
+	}()
+
+	// Everything from user lookup through log streaming is bounded by the
+	// deadline assigned when the request was received.
+	ctx, cancel := request.DeadlineContext(ctx)
+	defer cancel()
 
 	da, err := dataaccess.Get()
 	if err != nil {
@@ -162,13 +199,95 @@ func handleRequest(ctx context.Context, request data.CommandRequest) data.Comman
 		return envelope
 	}
 
+	// The reserved --help and --version options, and the parameters of
+	// commands that declare a usage string, are handled without ever
+	// starting a worker.
+	if request.Command.HelpRequested(request.Parameters) {
+		envelope = data.NewCommandResponseEnvelope(
+			request,
+			data.WithResponseLines(request.CommandEntry.UsageLines()),
+		)
+		return envelope
+	}
+
+	if request.Command.VersionRequested(request.Parameters) {
+		envelope = data.NewCommandResponseEnvelope(
+			request,
+			data.WithResponseLines(request.CommandEntry.VersionLines()),
+		)
+		return envelope
+	}
+
+	if err := request.Command.CheckParameters(request.Parameters); err != nil {
+		envelope = data.NewCommandResponseEnvelope(
+			request,
+			data.WithError("Invalid Usage", gerrs.Wrap(ErrCommandUsage, err), ExitUsage),
+			data.WithResponseLines(append([]string{err.Error(), ""}, request.CommandEntry.UsageLines()...)),
+		)
+		return envelope
+	}
+
+	// The allowlist may have changed since the bundle was installed.
+	if err := bundles.CheckImage(request.Bundle.Image); err != nil {
+		envelope = data.NewCommandResponseEnvelope(
+			request,
+			data.WithError("Image Not Allowed", err, ExitNoPerm),
+		)
+		return envelope
+	}
+
+	if request.Command.Exclusive != "" {
+		release, err := acquireLock(ctx, da, request)
+		switch {
+		case gerrs.Is(err, errs.ErrLockHeld):
+			envelope = data.NewCommandResponseEnvelope(
+				request,
+				data.WithError("Command Locked", err, ExitTempFail),
+			)
+			return envelope
+		case err != nil:
+			envelope = data.NewCommandResponseEnvelope(
+				request,
+				data.WithError("Failed to acquire command lock", err, ExitIoErr),
+			)
+			return envelope
+		}
+		defer release()
+	}
+
 	worker, err := SpawnWorker(ctx, request)
 	if err != nil {
 		envelope = data.NewCommandResponseEnvelope(
 			request,
-			data.WithError("Failed to spawn worker", err, ExitSystemErr),
+			data.WithError("Failed to spawn worker", gerrs.Wrap(ErrWorkerSpawn, err), ExitSystemErr),
+		)
+		return envelope
+	}
+
+	// The cooldown only starts once there's a worker to run the command, so
+	// that a failure to spawn one doesn't hold up the next attempt.
+	if cooldown, err := request.Command.CooldownDuration(); err != nil {
+		envelope = data.NewCommandResponseEnvelope(
+			request,
+			data.WithError("Invalid command cooldown", err, ExitSystemErr),
 		)
 		return envelope
+	} else if cooldown > 0 {
+		err := startCooldown(ctx, da, request, cooldown)
+		switch {
+		case gerrs.Is(err, ErrCommandCooldown):
+			envelope = data.NewCommandResponseEnvelope(
+				request,
+				data.WithError("Command Cooling Down", err, ExitTempFail),
+			)
+			return envelope
+		case err != nil:
+			envelope = data.NewCommandResponseEnvelope(
+				request,
+				data.WithError("Failed to start command cooldown", err, ExitIoErr),
+			)
+			return envelope
+		}
 	}
 
 	dc, err := loadDynamicConfigurations(ctx, request)
@@ -176,15 +295,34 @@ func handleRequest(ctx context.Context, request data.CommandRequest) data.Comman
 	if err != nil {
 		envelope = data.NewCommandResponseEnvelope(
 			request,
-			data.WithError("Failed to load dynamic configurations", err, ExitSystemErr),
+			data.WithError("Failed to load dynamic configurations", gerrs.Wrap(ErrDynamicConfigurationLoad, err), ExitSystemErr),
 		)
 		return envelope
 	}
 
 	worker.Initialize(dc)
 
+	if err := ctx.Err(); err != nil {
+		envelope = data.NewCommandResponseEnvelope(
+			request,
+			data.WithError("Command Timed Out", gerrs.Wrap(ErrCommandTimeout, err), ExitTimeout),
+			data.WithTimedOut(true),
+		)
+		return envelope
+	}
+
+	request.Timings.Record(data.StageSchedule, time.Since(start))
 	envelope = runWorker(ctx, worker, request)
 
+	// Commands may echo the secrets that they were given.
+	if lines, redacted := data.RedactSecrets(envelope.Response.Lines, dc); redacted {
+		data.WithResponseLines(lines)(&envelope)
+	}
+
+	// The request context may have expired by now, but the cost is owed
+	// regardless.
+	recordCost(context.Background(), da, request, envelope.Data.WorkerDuration)
+
 	return envelope
 }
 
@@ -248,7 +386,7 @@ func loadDynamicConfigurations(ctx context.Context, command data.CommandRequest)
 	for _, g := range groups {
 		go func(g rest.Group) {
 			defer wg.Done()
-			dc, err := da.DynamicConfigurationList(ctx, data.LayerGroup, g.Name, "", "")
+			dc, err := da.DynamicConfigurationList(ctx, data.LayerGroup, command.Bundle.Name, g.Name, "")
 			if err != nil {
 				errs <- err
 				cancel()
@@ -273,43 +411,87 @@ func loadDynamicConfigurations(ctx context.Context, command data.CommandRequest)
 		configs = append(configs, dc...)
 	}
 
+	// The layers were loaded concurrently, so put them back in order.
+	data.SortDynamicConfigurations(configs)
+
 	return configs, nil
 }
 
 // runWorker is called by handleRequest to do the work of starting an
 // individual worker, capturing its output, and cleaning up after it.
-func runWorker(ctx context.Context, worker worker.Worker, request data.CommandRequest) data.CommandResponseEnvelope {
+func runWorker(ctx context.Context, worker worker.Worker, request data.CommandRequest) (envelope data.CommandResponseEnvelope) {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	_, sp := tr.Start(ctx, "relay.runWorker")
 	defer sp.End()
 
-	var envelope data.CommandResponseEnvelope
+	sp.SetAttributes(telemetry.BundleAttributes(request.Bundle.Telemetry)...)
+
+	// Named results ensure that the durations are set on the returned value.
 	defer func() {
 		envelope.Data.Duration = time.Since(envelope.Request.Timestamp)
 	}()
 
-	// Get configured timeout. Zero (or less) is no timeout.
+	// Requests that arrive without a deadline fall back to the configured
+	// timeout. Zero (or less) is no timeout.
 	var cancel context.CancelFunc
-	if timeout := config.GetGlobalConfigs().CommandTimeout; timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, timeout)
+	if _, ok := ctx.Deadline(); !ok && config.GetGlobalConfigs().CommandTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, config.GetGlobalConfigs().CommandTimeout)
 	} else {
 		ctx, cancel = context.WithCancel(ctx)
 	}
 	defer cancel()
 
+	// A command may declare its own timeout. The adapter has already used it
+	// for the request deadline, but it's enforced here too for requests
+	// that arrive from elsewhere.
+	if d, _ := request.Command.TimeoutDuration(); d > 0 {
+		var cancelCommand context.CancelFunc
+		ctx, cancelCommand = context.WithTimeout(ctx, d)
+		defer cancelCommand()
+	}
+
+	// The worker is stopped using a fresh context, since ctx may already be
+	// done by the time we get there.
+	defer func() {
+		forceTerm := time.Second * 10
+		worker.Stop(context.Background(), &forceTerm)
+	}()
+
+	workerStart := time.Now()
+	defer func() {
+		envelope.Data.WorkerDuration = time.Since(workerStart)
+	}()
+
 	stdoutChan, err := worker.Start(ctx)
+	request.Timings.Record(data.StageStart, time.Since(workerStart))
 	if err != nil {
 		envelope = data.NewCommandResponseEnvelope(
 			request,
-			data.WithError("Failed to start worker", err, ExitSystemErr),
+			data.WithError("Failed to start worker", gerrs.Wrap(ErrWorkerStart, err), ExitSystemErr),
 		)
 		return envelope
 	}
 
-	// Read input from the worker until the stream closes
+	execStart := time.Now()
+	defer func() {
+		request.Timings.Record(data.StageExecution, time.Since(execStart))
+	}()
+
+	// Read input from the worker until the stream closes or the deadline
+	// is reached, whichever comes first.
 	var lines []string
-	for line := range stdoutChan {
-		lines = append(lines, line)
+	var done bool
+	for !done {
+		select {
+		case line, ok := <-stdoutChan:
+			if !ok {
+				done = true
+				break
+			}
+			lines = append(lines, line)
+		case <-ctx.Done():
+			done = true
+		}
 	}
 
 	var exitCode int64
@@ -319,15 +501,25 @@ func runWorker(ctx context.Context, worker worker.Worker, request data.CommandRe
 		var opts []data.CommandResponseEnvelopeOption
 
 		if exitCode != ExitOK {
-			if len(lines) == 0 {
+			// A command may describe its failure with a final error detail
+			// line, which is lifted out of the output and into the envelope.
+			detail, rest := data.ParseErrorDetail(lines)
+			msg := strings.Join(lines, " ")
+			if detail != nil {
+				lines, msg = rest, detail.Message
+			} else if len(lines) == 0 {
 				lines = []string{"Unknown error executing command"}
+				msg = lines[0]
 			}
 
-			opts = append(opts, data.WithError(
-				"Command Error",
-				fmt.Errorf(strings.Join(lines, " ")),
-				int16(exitCode),
-			))
+			opts = append(opts,
+				data.WithError(
+					"Command Error",
+					gerrs.Wrap(ErrCommandFailed, errors.New(msg)),
+					int16(exitCode),
+				),
+				data.WithErrorDetail(detail),
+			)
 		}
 
 		opts = append(opts, data.WithResponseLines(lines))
@@ -341,20 +533,26 @@ func runWorker(ctx context.Context, worker worker.Worker, request data.CommandRe
 	case <-ctx.Done():
 		err := ctx.Err()
 
-		envelope = data.NewCommandResponseEnvelope(
-			request,
-			data.WithError(err.Error(), err, ExitTimeout),
-		)
+		opts := []data.CommandResponseEnvelopeOption{
+			data.WithError("Command Timed Out", gerrs.Wrap(ErrCommandTimeout, err), ExitTimeout),
+			data.WithTimedOut(true),
+		}
+
+		// If the command produced any output before the deadline, return what
+		// we have rather than discarding it.
+		if len(lines) > 0 {
+			opts = append(opts, data.WithResponseLines(lines), data.WithPartial(true))
+		}
+
+		envelope = data.NewCommandResponseEnvelope(request, opts...)
 
 		log.
 			WithError(err).
 			WithField("request.id", request.RequestID).
 			WithField("status", ExitTimeout).
+			WithField("lines", len(lines)).
 			Info("Command exited with error")
 	}
 
-	forceTerm := time.Second * 10
-	worker.Stop(ctx, &forceTerm)
-
 	return envelope
 }
@@ -0,0 +1,68 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"testing"
+
+	"github.com/getgort/gort/types"
+)
+
+// FuzzTokenizeAndParse checks that any rule can be tokenized, parsed, and
+// evaluated without panicking.
+func FuzzTokenizeAndParse(f *testing.F) {
+	seeds := []string{
+		`foo:bar allow`,
+		`foo:bar with false == false allow`,
+		`foo:bar with true == true or true == false allow`,
+		`foo:bar with option['foo'] == "bar" and arg[0] == "foo" allow`,
+		`foo:bar with any arg == /^f.*$/ allow`,
+		`foo:bar with all arg in ["foo", "bar"] allow`,
+		`foo:bar with arg[0] in ['foo', false, 100] allow`,
+		`foo:bar with /.*/ == arg[5] allow`,
+		`foo:bar with /.*/ == option['missing'] allow`,
+		`foo:bar with option['n'] > 5 must have foo:write and foo:read or foo:admin`,
+		`foo:bar must have foo:write`,
+		`foo:bar with arg[99999999999999999999] == 1 allow`,
+	}
+
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	env := EvaluationEnvironment{
+		"option": map[string]types.Value{
+			"foo": types.StringValue{V: "bar"},
+			"k":   types.BoolValue{V: true},
+			"n":   types.IntValue{V: 10},
+		},
+		"arg": []types.Value{types.StringValue{V: "foo"}, types.IntValue{V: 1}},
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		rule, err := TokenizeAndParse(in)
+		if err != nil {
+			return
+		}
+
+		rule.Matches(env)
+		rule.Allowed([]string{"foo:read", "foo:write"})
+	})
+}
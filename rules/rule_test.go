@@ -60,6 +60,8 @@ func TestRuleMatches(t *testing.T) {
 		`foo:bar with all arg in [10, 'baz', 'wubba'] allow`:            false,
 		`foo:bar with all option < 10 allow`:                            false,
 		`foo:bar with all option in ['staging', 'list'] allow`:          false,
+		`foo:bar with /.*/ == arg[5] allow`:                             false,
+		`foo:bar with /.*/ == option['missing'] allow`:                  false,
 	}
 
 	for in, expected := range inputs {
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sdk contains helpers for commands that are written in Go and
+// executed by Gort.
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/getgort/gort/data"
+)
+
+// ExitError is the exit status used by Fail.
+const ExitError = 1
+
+// Error is a structured description of a command failure. When a command
+// writes one as the last line of its output and exits with a non-zero
+// status, Gort displays its message, hint, and docs URL in place of the
+// command's raw output.
+type Error = data.ErrorDetail
+
+// ErrorOption sets an optional field of an Error.
+type ErrorOption func(e *Error)
+
+// WithHint sets the Error's hint, which suggests what the user can do to
+// resolve it.
+func WithHint(hint string) ErrorOption {
+	return func(e *Error) {
+		e.Hint = hint
+	}
+}
+
+// WithDocsURL sets the URL of documentation that describes the Error.
+func WithDocsURL(url string) ErrorOption {
+	return func(e *Error) {
+		e.DocsURL = url
+	}
+}
+
+// NewError returns an Error with the given code and message. The code may
+// be empty.
+func NewError(code, message string, opts ...ErrorOption) Error {
+	e := Error{Code: code, Message: message}
+
+	for _, o := range opts {
+		o(&e)
+	}
+
+	return e
+}
+
+// WriteError writes e to w as a single line in the form that Gort expects.
+// Nothing else should be written to w after it.
+func WriteError(w io.Writer, e Error) error {
+	_, err := fmt.Fprintln(w, e.String())
+	return err
+}
+
+// Fail writes an Error to standard output and exits with ExitError. It
+// doesn't return.
+func Fail(code, message string, opts ...ErrorOption) {
+	WriteError(os.Stdout, NewError(code, message, opts...))
+	os.Exit(ExitError)
+}
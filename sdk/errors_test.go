@@ -0,0 +1,42 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sdk
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestWriteError(t *testing.T) {
+	e := NewError("NET-1", "no such host",
+		WithHint("Check the spelling of the host name."),
+		WithDocsURL("https://example.com/net-1"))
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, WriteError(b, e))
+	assert.Equal(t, `{"gort_error":{"code":"NET-1","message":"no such host","hint":"Check the spelling of the host name.","docs_url":"https://example.com/net-1"}}`+"\n", b.String())
+
+	lines := append([]string{"looking up host..."}, strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")...)
+	d, rest := data.ParseErrorDetail(lines)
+	assert.Equal(t, &e, d)
+	assert.Equal(t, []string{"looking up host..."}, rest)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+// The environment variables, set by Gort for every command execution, that
+// locate and authenticate to the worker API.
+const (
+	EnvServicesRoot = "GORT_SERVICES_ROOT"
+	EnvWorkerToken  = "GORT_WORKER_TOKEN"
+)
+
+// ErrNoWorkerToken is returned by the worker API functions if the command
+// wasn't given a worker token; for example, because it wasn't executed by
+// Gort.
+var ErrNoWorkerToken = errors.New("no worker token is available")
+
+var workerClient = &http.Client{Timeout: 10 * time.Second}
+
+// Whoami returns the request that the command is running for.
+func Whoami() (rest.WorkerRequest, error) {
+	var r rest.WorkerRequest
+	err := doWorkerRequest("GET", "whoami", nil, &r)
+	return r, err
+}
+
+// Respond sends an interim message to the channel that the command was
+// invoked from, while the command continues to run.
+func Respond(message string) error {
+	return doWorkerRequest("POST", "respond", rest.WorkerResponse{Message: message}, nil)
+}
+
+// Log attaches a structured log entry to the command's request, where it
+// can be seen with "gort ps <request_id>". The level is one of "debug",
+// "info", "warn", or "error"; fields may be nil.
+func Log(level, message string, fields map[string]string) error {
+	entry := data.RequestLogEntry{Level: level, Message: message, Fields: fields}
+	return doWorkerRequest("POST", "log", entry, nil)
+}
+
+// doWorkerRequest sends a request to the worker API, authenticated with the
+// command's worker token. If body is non-nil it's sent as JSON, and if out
+// is non-nil the response is decoded into it.
+func doWorkerRequest(method, endpoint string, body, out interface{}) error {
+	token := os.Getenv(EnvWorkerToken)
+	if token == "" {
+		return ErrNoWorkerToken
+	}
+
+	url := strings.TrimSuffix(os.Getenv(EnvServicesRoot), "/") + "/v2/worker/" + endpoint
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := workerClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
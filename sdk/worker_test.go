@@ -0,0 +1,81 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+func TestWorkerAPI(t *testing.T) {
+	var entry data.RequestLogEntry
+	var response rest.WorkerResponse
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/worker/whoami":
+			json.NewEncoder(w).Encode(rest.WorkerRequest{RequestID: 42, User: "alice"})
+		case "/v2/worker/respond":
+			json.NewDecoder(r.Body).Decode(&response)
+		case "/v2/worker/log":
+			json.NewDecoder(r.Body).Decode(&entry)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	os.Unsetenv(EnvWorkerToken)
+	_, err := Whoami()
+	assert.ErrorIs(t, err, ErrNoWorkerToken)
+
+	os.Setenv(EnvServicesRoot, server.URL+"/")
+	os.Setenv(EnvWorkerToken, "test-token")
+	defer os.Unsetenv(EnvServicesRoot)
+	defer os.Unsetenv(EnvWorkerToken)
+
+	whoami, err := Whoami()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), whoami.RequestID)
+	assert.Equal(t, "alice", whoami.User)
+
+	require.NoError(t, Respond("Working on it..."))
+	assert.Equal(t, "Working on it...", response.Message)
+
+	require.NoError(t, Log(data.RequestLogInfo, "starting", map[string]string{"step": "1"}))
+	assert.Equal(t, data.RequestLogInfo, entry.Level)
+	assert.Equal(t, "starting", entry.Message)
+	assert.Equal(t, map[string]string{"step": "1"}, entry.Fields)
+
+	os.Setenv(EnvWorkerToken, "wrong-token")
+	_, err = Whoami()
+	assert.Error(t, err)
+}
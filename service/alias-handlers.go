@@ -0,0 +1,176 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// canManageAlias returns true if user may create, replace, or delete an
+// alias with the given owner. Users may manage their own aliases; managing
+// global aliases, or any other user's, requires the manage_aliases
+// permission.
+func canManageAlias(r *http.Request, user rest.User, owner string) (bool, error) {
+	if owner != "" && owner == user.Username {
+		return true, nil
+	}
+
+	return doAuthenticateUser(r, "", "alias", "manage")
+}
+
+// getAliasParameters extracts an alias's identifying fields from a request's
+// path parameters. Global aliases have no username parameter; a username of
+// "-" refers to the requesting user.
+func getAliasParameters(r *http.Request) (data.Alias, rest.User, error) {
+	params := mux.Vars(r)
+
+	a := data.Alias{
+		Owner: params["username"],
+		Name:  params["name"],
+	}
+
+	user, err := getRequestContext(r).User()
+	if err != nil {
+		return data.Alias{}, rest.User{}, err
+	}
+
+	if a.Owner == "-" {
+		a.Owner = user.Username
+	}
+
+	return a, user, nil
+}
+
+// handleDeleteAlias handles "DELETE /v2/aliases/{name}" and
+// "DELETE /v2/users/{username}/aliases/{name}"
+func handleDeleteAlias(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	a, user, err := getAliasParameters(r)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if ok, err := canManageAlias(r, user, a.Owner); err != nil || !ok {
+		if err == nil {
+			err = ErrUnauthorized
+		}
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	err = dataAccessLayer.AliasDelete(r.Context(), a.Owner, a.Name)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleGetAliases handles "GET /v2/aliases"
+// Only the aliases the requesting user can invoke (their own, and global
+// ones) are returned, unless the user has the manage_aliases permission, in
+// which case all are.
+func handleGetAliases(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	aliases, err := dataAccessLayer.AliasList(r.Context(), "")
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if manager, err := doAuthenticateUser(r, "", "alias", "manage"); err != nil || !manager {
+		user, err := getRequestContext(r).User()
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+
+		visible := []data.Alias{}
+		for _, a := range aliases {
+			if a.Owner == "" || a.Owner == user.Username {
+				visible = append(visible, a)
+			}
+		}
+		aliases = visible
+	}
+
+	json.NewEncoder(w).Encode(aliases)
+}
+
+// handlePutAlias handles "PUT /v2/aliases/{name}" and
+// "PUT /v2/users/{username}/aliases/{name}"
+func handlePutAlias(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	var body data.Alias
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	a, user, err := getAliasParameters(r)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+	a.Command = body.Command
+
+	if ok, err := canManageAlias(r, user, a.Owner); err != nil || !ok {
+		if err == nil {
+			err = ErrUnauthorized
+		}
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	err = dataAccessLayer.AliasSave(r.Context(), a)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+func addAliasMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/aliases", otelhttp.NewHandler(authCommand(handleGetAliases, "alias", "list"), "handleGetAliases")).Methods("GET")
+	router.Handle("/v2/aliases/{name}", otelhttp.NewHandler(authCommand(handlePutAlias, "alias", "save"), "handlePutAlias")).Methods("PUT")
+	router.Handle("/v2/aliases/{name}", otelhttp.NewHandler(authCommand(handleDeleteAlias, "alias", "delete"), "handleDeleteAlias")).Methods("DELETE")
+	router.Handle("/v2/users/{username}/aliases/{name}", otelhttp.NewHandler(authCommand(handlePutAlias, "alias", "save"), "handlePutAlias")).Methods("PUT")
+	router.Handle("/v2/users/{username}/aliases/{name}", otelhttp.NewHandler(authCommand(handleDeleteAlias, "alias", "delete"), "handleDeleteAlias")).Methods("DELETE")
+}
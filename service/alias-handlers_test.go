@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestAliases(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	// A user with no special permissions.
+	require.NoError(t, da.UserCreate(ctx, rest.User{Username: "aliaser"}))
+
+	token, err := da.TokenGenerate(ctx, "aliaser", time.Minute)
+	require.NoError(t, err)
+
+	body := data.Alias{Command: "deploy:status prod"}
+
+	// Users can manage their own aliases...
+	NewResponseTester("PUT", "http://example.com/v2/users/aliaser/aliases/dep").WithToken(token).WithBody(body).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/users/-/aliases/stat").WithToken(token).WithBody(body).WithStatus(http.StatusOK).Test(t, router)
+
+	// ...but not global aliases, or anybody else's.
+	NewResponseTester("PUT", "http://example.com/v2/aliases/dep").WithToken(token).WithBody(body).WithStatus(http.StatusUnauthorized).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/users/admin/aliases/dep").WithToken(token).WithBody(body).WithStatus(http.StatusUnauthorized).Test(t, router)
+
+	// Admins can manage anybody's.
+	NewResponseTester("PUT", "http://example.com/v2/aliases/dep").WithBody(body).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/users/admin/aliases/dep").WithBody(body).WithStatus(http.StatusOK).Test(t, router)
+
+	// Aliases must have a command.
+	NewResponseTester("PUT", "http://example.com/v2/users/aliaser/aliases/empty").WithToken(token).WithBody(data.Alias{}).WithStatus(http.StatusBadRequest).Test(t, router)
+
+	aliases := []data.Alias{}
+	NewResponseTester("GET", "http://example.com/v2/aliases").WithToken(token).WithOutput(&aliases).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, []data.Alias{
+		{Name: "dep", Command: body.Command},
+		{Name: "dep", Owner: "aliaser", Command: body.Command},
+		{Name: "stat", Owner: "aliaser", Command: body.Command},
+	}, aliases)
+
+	aliases = []data.Alias{}
+	NewResponseTester("GET", "http://example.com/v2/aliases").WithOutput(&aliases).WithStatus(http.StatusOK).Test(t, router)
+	assert.Len(t, aliases, 4)
+
+	NewResponseTester("DELETE", "http://example.com/v2/aliases/dep").WithToken(token).WithStatus(http.StatusUnauthorized).Test(t, router)
+	NewResponseTester("DELETE", "http://example.com/v2/users/-/aliases/dep").WithToken(token).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("DELETE", "http://example.com/v2/users/aliaser/aliases/dep").WithToken(token).WithStatus(http.StatusNotFound).Test(t, router)
+}
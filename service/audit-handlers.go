@@ -0,0 +1,314 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+// defaultAuditListLimit is the number of records returned by
+// "GET /v2/audit" if no limit is given.
+const defaultAuditListLimit = 50
+
+// auditKind describes how the entity changed by a mutating request is
+// identified and, optionally, snapshotted.
+type auditKind struct {
+	// name is the kind of entity, as recorded in data.AuditRecord.Kind.
+	name string
+
+	// param is the path variable that names the target entity.
+	param string
+
+	// snapshot returns the current state of the target, or nil if it
+	// doesn't exist. It may be nil if the kind doesn't support snapshots.
+	snapshot func(ctx context.Context, da dataaccess.DataAccess, vars map[string]string) (interface{}, error)
+}
+
+// auditKinds maps the first path element after "/v2/" to the kind of entity
+// that its mutating endpoints change.
+var auditKinds = map[string]auditKind{
+	"bootstrap":   {name: "user"},
+	"bundles":     {name: "bundle", param: "name", snapshot: snapshotBundle},
+	"configs":     {name: "config", param: "bundle"},
+	"deadletters": {name: "deadletter", param: "id"},
+	"defaults":    {name: "default", param: "bundle"},
+	"groups":      {name: "group", param: "groupname", snapshot: snapshotGroup},
+	"macros":      {name: "macro", param: "name"},
+	"requests":    {name: "request", param: "id"},
+	"roles":       {name: "role", param: "rolename", snapshot: snapshotRole},
+	"system":      {name: "system"},
+	"users":       {name: "user", param: "username", snapshot: snapshotUser},
+}
+
+// unauditedEndpoints are endpoints that accept mutating methods but don't
+// change anything.
+var unauditedEndpoints = map[string]bool{
+	"/v2/authenticate":           true,
+	"/v2/authenticate/refresh":   true,
+	"/v2/auth/oidc/device":       true,
+	"/v2/auth/oidc/device/token": true,
+}
+
+// auditMiddleware records every mutating (non-GET/HEAD/OPTIONS) request
+// to the audit log, along with the acting user, the source IP, and
+// snapshots of the changed entity from immediately before and after the
+// request was handled.
+func auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if unauditedEndpoints[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		dataAccessLayer, err := getRequestContext(r).DataAccess()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		vars := mux.Vars(r)
+
+		path := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+		kind, ok := auditKinds[path[0]]
+		if !ok {
+			kind = auditKind{name: path[0]}
+		}
+
+		// A bundle's dynamic configuration is changed through its own
+		// routes, but it's a config change rather than a bundle change.
+		if path[0] == "bundles" && len(path) > 2 && path[2] == "config" {
+			kind = auditKind{name: auditKinds["configs"].name, param: "name"}
+		}
+
+		record := data.AuditRecord{
+			Timestamp: time.Now().UTC(),
+			SourceIP:  requestSourceIP(r),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Kind:      kind.name,
+			Target:    vars[kind.param],
+		}
+
+		if token, _ := getRequestContext(r).Token(); token.User != "" {
+			record.User = token.User
+		}
+
+		record.Before = auditSnapshot(ctx, dataAccessLayer, kind, vars)
+
+		status := http.StatusOK
+		bytelen := 0
+		next.ServeHTTP(StatusCaptureWriter{w, &status, &bytelen}, r)
+
+		record.Status = status
+		record.After = auditSnapshot(ctx, dataAccessLayer, kind, vars)
+
+		if err := dataAccessLayer.AuditRecordCreate(ctx, &record); err != nil {
+			log.WithError(err).
+				WithField("method", record.Method).
+				WithField("path", record.Path).
+				Error("Failed to record REST audit entry")
+		}
+	})
+}
+
+// auditSnapshot returns the JSON-encoded state of the target of a request, or
+// nil if it doesn't exist or can't be retrieved.
+func auditSnapshot(ctx context.Context, da dataaccess.DataAccess, kind auditKind, vars map[string]string) json.RawMessage {
+	if kind.snapshot == nil {
+		return nil
+	}
+
+	v, err := kind.snapshot(ctx, da, vars)
+	if err != nil {
+		log.WithError(err).WithField("kind", kind.name).Warn("Failed to snapshot audit target")
+		return nil
+	}
+	if v == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.WithError(err).WithField("kind", kind.name).Warn("Failed to snapshot audit target")
+		return nil
+	}
+
+	return b
+}
+
+func snapshotBundle(ctx context.Context, da dataaccess.DataAccess, vars map[string]string) (interface{}, error) {
+	name, version := vars["name"], vars["version"]
+	if version == "" {
+		return nil, nil
+	}
+
+	exists, err := da.BundleVersionExists(ctx, name, version)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	return da.BundleGet(ctx, name, version)
+}
+
+func snapshotGroup(ctx context.Context, da dataaccess.DataAccess, vars map[string]string) (interface{}, error) {
+	name := vars["groupname"]
+
+	exists, err := da.GroupExists(ctx, name)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	group, err := da.GroupGet(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Roles, err = da.GroupRoleList(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the users, so as not to modify any cached values.
+	users := make([]rest.User, len(group.Users))
+	for i, u := range group.Users {
+		u.Password = ""
+		users[i] = u
+	}
+	group.Users = users
+
+	return group, nil
+}
+
+func snapshotRole(ctx context.Context, da dataaccess.DataAccess, vars map[string]string) (interface{}, error) {
+	name := vars["rolename"]
+
+	exists, err := da.RoleExists(ctx, name)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	return da.RoleGet(ctx, name)
+}
+
+func snapshotUser(ctx context.Context, da dataaccess.DataAccess, vars map[string]string) (interface{}, error) {
+	name := vars["username"]
+
+	exists, err := da.UserExists(ctx, name)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	user, err := da.UserGet(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = ""
+
+	groups, err := da.UserGroupList(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	groupNames := []string{}
+	for _, g := range groups {
+		groupNames = append(groupNames, g.Name)
+	}
+
+	return struct {
+		User   rest.User `json:"user"`
+		Groups []string  `json:"groups"`
+	}{user, groupNames}, nil
+}
+
+// requestSourceIP returns the IP address that a request was sent from.
+func requestSourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// handleGetAudit handles "GET /v2/audit"
+func handleGetAudit(w http.ResponseWriter, r *http.Request) {
+	filter := data.AuditFilter{
+		User:   r.FormValue("user"),
+		Kind:   r.FormValue("kind"),
+		Target: r.FormValue("target"),
+		Limit:  defaultAuditListLimit,
+	}
+
+	if v := r.FormValue("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	for param, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := r.FormValue(param); v != "" {
+			var err error
+			*t, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, param+" must be an RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	records, err := dataAccessLayer.AuditRecordList(r.Context(), filter)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(records)
+}
+
+func addAuditMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/audit", otelhttp.NewHandler(authCommand(handleGetAudit, "audit"), "handleGetAudit")).Methods("GET")
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestAudit(t *testing.T) {
+	router := createTestRouter()
+	router.Use(auditMiddleware)
+
+	NewResponseTester("PUT", "http://example.com/v2/roles/testAudit").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/roles/testAudit/bundles/testbundle/permissions/testpermission").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/roles/testAudit").WithStatus(http.StatusOK).Test(t, router)
+
+	records := []data.AuditRecord{}
+	NewResponseTester("GET", "http://example.com/v2/audit?kind=role&target=testAudit").WithOutput(&records).WithStatus(http.StatusOK).Test(t, router)
+	require.Len(t, records, 2)
+
+	// Newest first.
+	grant, create := records[0], records[1]
+
+	assert.Equal(t, adminToken.User, create.User)
+	assert.Equal(t, "192.0.2.1", create.SourceIP)
+	assert.Equal(t, "PUT", create.Method)
+	assert.Equal(t, "/v2/roles/testAudit", create.Path)
+	assert.Equal(t, http.StatusOK, create.Status)
+	assert.Empty(t, create.Before)
+	assert.Contains(t, string(create.After), `"testAudit"`)
+
+	assert.Equal(t, "/v2/roles/testAudit/bundles/testbundle/permissions/testpermission", grant.Path)
+	assert.NotContains(t, string(grant.Before), "testpermission")
+	assert.Contains(t, string(grant.After), "testpermission")
+
+	NewResponseTester("GET", "http://example.com/v2/audit?user=nobody").WithOutput(&records).WithStatus(http.StatusOK).Test(t, router)
+	assert.Empty(t, records)
+
+	NewResponseTester("GET", "http://example.com/v2/audit?limit=-1").WithStatus(http.StatusBadRequest).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/audit?since=yesterday").WithStatus(http.StatusBadRequest).Test(t, router)
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+var (
+	// ErrInvalidCredentials is returned by an Authenticator if the password
+	// is wrong, or if it doesn't know the user.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrSelfRegistrationOff is returned if a user is authenticated by an
+	// external directory but doesn't exist in Gort, and self-registration
+	// is off.
+	ErrSelfRegistrationOff = errors.New("externally authenticated user doesn't exist and self-registration is off")
+
+	// ErrNotBootstrapped is returned if a user is authenticated by an
+	// external directory before Gort has been bootstrapped.
+	ErrNotBootstrapped = errors.New("externally authenticated users can't be created before gort is bootstrapped")
+)
+
+// Identity is a user whose password was verified by an Authenticator.
+type Identity struct {
+	Username string
+	Email    string
+	FullName string
+
+	// External is true if the user was authenticated by a directory other
+	// than Gort's own database, so that the Gort user may need to be
+	// created and its group memberships synced.
+	External bool
+
+	// Groups are the Gort groups that the user should be a member of, out of
+	// ManagedGroups, the groups whose membership the directory controls.
+	Groups        []string
+	ManagedGroups []string
+}
+
+// An Authenticator verifies users' passwords against some user directory.
+type Authenticator interface {
+	// Name identifies the Authenticator in logs.
+	Name() string
+
+	// Authenticate returns the identity of the user if the password is
+	// correct. If it isn't, or the directory doesn't have the user, it
+	// returns ErrInvalidCredentials.
+	Authenticate(ctx context.Context, username, password string) (Identity, error)
+}
+
+// authenticators returns the configured Authenticators, in the order that
+// they're consulted: an LDAP directory, if there is one, followed by Gort's
+// own user database.
+var authenticators = func(da dataaccess.DataAccess) []Authenticator {
+	var a []Authenticator
+
+	if lc := config.GetLDAPConfigs(); !config.Undefined(lc) {
+		a = append(a, ldapAuthenticator{lc})
+	}
+
+	return append(a, databaseAuthenticator{da})
+}
+
+// authenticatePassword returns the name of the Gort user with the given
+// password. Each Authenticator is tried in turn until one accepts the
+// password. The bootstrap "admin" user is only ever authenticated by Gort's
+// own database, so that a directory can't be used to take it over.
+func authenticatePassword(ctx context.Context, da dataaccess.DataAccess, username, password string) (string, error) {
+	var failure error
+
+	for _, a := range authenticators(da) {
+		if _, isDatabase := a.(databaseAuthenticator); username == "admin" && !isDatabase {
+			continue
+		}
+
+		id, err := a.Authenticate(ctx, username, password)
+		if gerrs.Is(err, ErrInvalidCredentials) {
+			continue
+		}
+		if err != nil {
+			// A directory that's unreachable shouldn't lock out users in
+			// the others, but if nobody accepts the password the failure
+			// is worth reporting.
+			log.WithError(err).WithField("authenticator", a.Name()).
+				WithField("user.username", username).
+				Warn("Authenticator failed")
+			if failure == nil {
+				failure = err
+			}
+			continue
+		}
+
+		if id.External {
+			if err := provisionIdentity(ctx, da, id); err != nil {
+				return "", err
+			}
+		}
+
+		return id.Username, nil
+	}
+
+	if failure != nil {
+		return "", failure
+	}
+
+	return "", ErrInvalidCredentials
+}
+
+// provisionIdentity creates the Gort user of an externally authenticated
+// identity if it doesn't already exist, and syncs its membership of the
+// identity's managed groups.
+func provisionIdentity(ctx context.Context, da dataaccess.DataAccess, id Identity) error {
+	le := log.WithField("user.username", id.Username)
+
+	exists, err := da.UserExists(ctx, id.Username)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		user, err := da.UserGet(ctx, id.Username)
+		if err != nil {
+			return err
+		}
+
+		// Service accounts can't log in with a password, wherever it's
+		// verified.
+		if user.ServiceAccount {
+			return ErrInvalidCredentials
+		}
+	} else {
+		bootstrapped, err := da.UserExists(ctx, "admin")
+		if err != nil {
+			return err
+		}
+		if !bootstrapped {
+			return ErrNotBootstrapped
+		}
+
+		if !config.GetGortServerConfigs().AllowSelfRegistration {
+			return ErrSelfRegistrationOff
+		}
+
+		// The user's password is verified by the directory, so the one
+		// stored by Gort is random and never used.
+		password, err := data.GenerateRandomToken(32)
+		if err != nil {
+			return err
+		}
+
+		user := rest.User{
+			Email:    id.Email,
+			FullName: id.FullName,
+			Password: password,
+			Username: id.Username,
+		}
+		if err := da.UserCreate(ctx, user); err != nil {
+			return err
+		}
+
+		le.WithField("user.email", user.Email).Info("User auto-created")
+	}
+
+	if len(id.ManagedGroups) == 0 {
+		return nil
+	}
+
+	groups, err := da.UserGroupList(ctx, id.Username)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+	for _, g := range groups {
+		current[g.Name] = true
+	}
+
+	wanted := map[string]bool{}
+	for _, g := range id.Groups {
+		wanted[g] = true
+	}
+
+	for _, g := range id.ManagedGroups {
+		switch {
+		case wanted[g] && !current[g]:
+			err = da.GroupUserAdd(ctx, g, id.Username)
+			if gerrs.Is(err, errs.ErrNoSuchGroup) {
+				le.WithField("group.name", g).Warn("Mapped group doesn't exist")
+				continue
+			}
+		case !wanted[g] && current[g]:
+			err = da.GroupUserDelete(ctx, g, id.Username)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		le.WithField("group.name", g).WithField("member", wanted[g]).
+			Info("Synced mapped group membership")
+	}
+
+	return nil
+}
+
+// databaseAuthenticator is an Authenticator that verifies passwords against
+// the hashes in Gort's own user database.
+type databaseAuthenticator struct {
+	da dataaccess.DataAccess
+}
+
+func (a databaseAuthenticator) Name() string {
+	return "database"
+}
+
+func (a databaseAuthenticator) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	authenticated, err := a.da.UserAuthenticate(ctx, username, password)
+	if gerrs.Is(err, errs.ErrNoSuchUser) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, err
+	}
+	if !authenticated {
+		return Identity{}, ErrInvalidCredentials
+	}
+
+	return Identity{Username: username}, nil
+}
+
+// mapExternalGroups returns the Gort groups that an external directory's
+// groups, memberOf, are mapped to, and all of the mapped Gort groups. Group
+// names (or LDAP DNs) are compared case insensitively.
+func mapExternalGroups(mappings map[string]string, memberOf []string) ([]string, []string) {
+	groups := map[string]bool{}
+	managed := map[string]bool{}
+
+	for name, group := range mappings {
+		managed[group] = true
+
+		for _, m := range memberOf {
+			if strings.EqualFold(strings.TrimSpace(m), strings.TrimSpace(name)) {
+				groups[group] = true
+			}
+		}
+	}
+
+	return sortedKeys(groups), sortedKeys(managed)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+// fakeAuthenticator is an external Authenticator with a fixed set of users.
+type fakeAuthenticator struct {
+	passwords map[string]string
+	groups    []string
+	managed   []string
+}
+
+func (a fakeAuthenticator) Name() string {
+	return "fake"
+}
+
+func (a fakeAuthenticator) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	if p, ok := a.passwords[username]; !ok || p != password {
+		return Identity{}, ErrInvalidCredentials
+	}
+
+	return Identity{
+		Username:      username,
+		Email:         username + "@example.com",
+		External:      true,
+		Groups:        a.groups,
+		ManagedGroups: a.managed,
+	}, nil
+}
+
+func TestAuthenticators(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	defer func(f func(dataaccess.DataAccess) []Authenticator) { authenticators = f }(authenticators)
+	authenticators = func(da dataaccess.DataAccess) []Authenticator {
+		return []Authenticator{
+			fakeAuthenticator{
+				passwords: map[string]string{"admin": "directory", "dana": "directory", "eve": "directory"},
+				groups:    []string{"sre", "missing"},
+				managed:   []string{"dev", "missing", "sre"},
+			},
+			databaseAuthenticator{da},
+		}
+	}
+
+	require.NoError(t, da.UserCreate(ctx, rest.User{Username: "dana", Email: "dana@example.com", Password: "local"}))
+	require.NoError(t, da.UserCreate(ctx, rest.User{Username: "local", Email: "local@example.com", Password: "local"}))
+	require.NoError(t, da.GroupCreate(ctx, rest.Group{Name: "dev"}))
+	require.NoError(t, da.GroupCreate(ctx, rest.Group{Name: "sre"}))
+	require.NoError(t, da.GroupUserAdd(ctx, "dev", "dana"))
+
+	// The directory's password is accepted, and the mapped groups synced.
+	var token rest.Token
+	NewResponseTester("POST", "http://example.com/v2/authenticate").WithBody(rest.User{Username: "dana", Password: "directory"}).WithOutput(&token).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "dana", token.User)
+
+	groups, err := da.UserGroupList(ctx, "dana")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "sre", groups[0].Name)
+
+	// Users the directory doesn't know fall back to the database.
+	NewResponseTester("POST", "http://example.com/v2/authenticate").WithBody(rest.User{Username: "local", Password: "local"}).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("POST", "http://example.com/v2/authenticate").WithBody(rest.User{Username: "local", Password: "wrong"}).WithStatus(http.StatusForbidden).Test(t, router)
+
+	// The admin user is never authenticated by a directory.
+	NewResponseTester("POST", "http://example.com/v2/authenticate").WithBody(rest.User{Username: "admin", Password: "directory"}).WithStatus(http.StatusForbidden).Test(t, router)
+
+	// Unknown users aren't created while self-registration is off.
+	NewResponseTester("POST", "http://example.com/v2/authenticate").WithBody(rest.User{Username: "eve", Password: "directory"}).WithStatus(http.StatusForbidden).Test(t, router)
+
+	exists, err := da.UserExists(ctx, "eve")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestMapExternalGroups(t *testing.T) {
+	mappings := map[string]string{
+		"cn=sre,ou=groups,dc=example,dc=com": "sre",
+		"cn=dev,ou=groups,dc=example,dc=com": "dev",
+		"cn=ops,ou=groups,dc=example,dc=com": "sre",
+	}
+
+	groups, managed := mapExternalGroups(mappings, []string{"CN=SRE,OU=Groups,DC=example,DC=com", "cn=other,dc=example,dc=com"})
+	assert.Equal(t, []string{"sre"}, groups)
+	assert.Equal(t, []string{"dev", "sre"}, managed)
+
+	groups, managed = mapExternalGroups(nil, []string{"cn=sre,ou=groups,dc=example,dc=com"})
+	assert.Empty(t, groups)
+	assert.Empty(t, managed)
+}
@@ -20,14 +20,21 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
+	"github.com/getgort/gort/bundles"
+	"github.com/getgort/gort/config"
 	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/dataaccess"
 	"github.com/getgort/gort/dataaccess/errs"
 	gerrs "github.com/getgort/gort/errors"
@@ -37,11 +44,33 @@ var (
 	// ErrMissingValue is returned by a method when an expected form field
 	// is missing.
 	ErrMissingValue = errors.New("a form value is missing")
+
+	// ErrUnknownMappedGroup is returned when enabling a bundle that maps a
+	// group that doesn't exist to a Kubernetes service account.
+	ErrUnknownMappedGroup = errors.New("service account mapping names an unknown group")
+
+	// ErrBundleNotApproved is returned when enabling a bundle version that
+	// is pending review or was rejected.
+	ErrBundleNotApproved = errors.New("bundle version hasn't been approved")
+
+	// ErrBundleNotPending is returned when reviewing a bundle version that
+	// isn't pending review.
+	ErrBundleNotPending = errors.New("bundle version isn't pending review")
+
+	// ErrSelfReview is returned when a user reviews a bundle version that
+	// they installed.
+	ErrSelfReview = errors.New("bundle versions can't be reviewed by the user that installed them")
 )
 
 // handleGetBundles handles "GET /v2/bundles"
 func handleGetBundles(w http.ResponseWriter, r *http.Request) {
-	bundles, err := getAllBundles(r.Context())
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	bundles, err := getAllBundles(r.Context(), dataAccessLayer)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -59,7 +88,7 @@ func handleHeadBundles(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	name := params["name"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -82,7 +111,7 @@ func handleGetBundleVersions(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	name := params["name"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -106,7 +135,7 @@ func handleDeleteBundleVersion(w http.ResponseWriter, r *http.Request) {
 	name := params["name"]
 	version := params["version"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -125,7 +154,7 @@ func handleGetBundleVersion(w http.ResponseWriter, r *http.Request) {
 	name := params["name"]
 	version := params["version"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -146,7 +175,7 @@ func handleHeadBundleVersion(w http.ResponseWriter, r *http.Request) {
 	name := params["name"]
 	version := params["version"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -177,7 +206,7 @@ func handlePatchBundleVersion(w http.ResponseWriter, r *http.Request) {
 		enabledValue = "-"
 	}
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -215,6 +244,21 @@ func handlePatchBundleVersion(w http.ResponseWriter, r *http.Request) {
 		bundle.Name = name
 		bundle.Version = version
 
+		existing, err := dataAccessLayer.BundleGet(r.Context(), name, version)
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+
+		// The review state can't be changed through an update, and any
+		// change to a reviewed bundle has to be reviewed again.
+		bundle.InstalledBy = existing.InstalledBy
+		bundle.Review = existing.Review
+		if bundle.Review.Status != "" {
+			bundle.InstalledBy = requestUsername(r)
+			bundle.Review = data.BundleReview{Status: data.BundleReviewPending}
+		}
+
 		err = dataAccessLayer.BundleUpdate(r.Context(), bundle)
 		if err != nil {
 			respondAndLogError(r.Context(), w, err)
@@ -222,8 +266,19 @@ func handlePatchBundleVersion(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var warnings []rest.BundleWarning
+
 	if enabledValue[0] == 'T' {
-		err = dataAccessLayer.BundleEnable(r.Context(), name, version)
+		err = checkBundleReview(r.Context(), dataAccessLayer, name, version)
+		if err == nil {
+			err = checkGroupServiceAccounts(r.Context(), dataAccessLayer, name, version)
+		}
+		if err == nil {
+			err = dataAccessLayer.BundleEnable(r.Context(), name, version)
+		}
+		if err == nil {
+			warnings, err = checkTriggerCollisions(r.Context(), dataAccessLayer, name, version)
+		}
 	} else if enabledValue[0] == 'F' {
 		err = dataAccessLayer.BundleDisable(r.Context(), name, version)
 	}
@@ -231,6 +286,105 @@ func handlePatchBundleVersion(w http.ResponseWriter, r *http.Request) {
 		respondAndLogError(r.Context(), w, err)
 		return
 	}
+
+	if enabledValue[0] == 'T' {
+		json.NewEncoder(w).Encode(rest.BundleEnableResult{Warnings: warnings})
+	}
+}
+
+// checkTriggerCollisions returns a warning for each of the bundle's triggers
+// that overlaps a trigger of another enabled bundle, and logs them so that
+// they're visible to administrators.
+func checkTriggerCollisions(ctx context.Context, da dataaccess.DataAccess, name, version string) ([]rest.BundleWarning, error) {
+	bundle, err := da.BundleGet(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := da.BundleList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var enabled []data.Bundle
+	for _, b := range all {
+		if b.Enabled {
+			enabled = append(enabled, b)
+		}
+	}
+
+	warnings := bundles.TriggerCollisions(bundle, enabled, config.GetGlobalConfigs().Triggers)
+	for _, w := range warnings {
+		log.WithField("bundle", name).
+			WithField("version", version).
+			WithField("command", w.Command).
+			Warn(w.Message)
+	}
+
+	return warnings, nil
+}
+
+// checkBundleReview returns ErrBundleNotApproved if the bundle version is
+// pending review or was rejected.
+func checkBundleReview(ctx context.Context, da dataaccess.DataAccess, name, version string) error {
+	bundle, err := da.BundleGet(ctx, name, version)
+	if err != nil {
+		return err
+	}
+
+	if !bundle.Review.Enableable() {
+		return gerrs.Wrap(ErrBundleNotApproved, fmt.Errorf("%s %s is %s", name, version, bundle.Review.Status))
+	}
+
+	return nil
+}
+
+// newBundleReview returns the initial review state of a newly installed
+// bundle version.
+func newBundleReview() data.BundleReview {
+	if config.GetGortServerConfigs().RequireBundleReview {
+		return data.BundleReview{Status: data.BundleReviewPending}
+	}
+	return data.BundleReview{}
+}
+
+// requestUsername returns the name of the user that made the request, or
+// an empty string if it can't be determined.
+func requestUsername(r *http.Request) string {
+	user, err := getRequestContext(r).User()
+	if err != nil {
+		return ""
+	}
+	return user.Username
+}
+
+// checkGroupServiceAccounts returns ErrUnknownMappedGroup if the bundle maps
+// a group that doesn't exist to a Kubernetes service account. Groups are
+// checked when the bundle is enabled, rather than when it's installed, so
+// that a bundle may be installed before its groups are created.
+func checkGroupServiceAccounts(ctx context.Context, da dataaccess.DataAccess, name, version string) error {
+	bundle, err := da.BundleGet(ctx, name, version)
+	if err != nil {
+		return err
+	}
+
+	groups := make([]string, 0, len(bundle.Kubernetes.GroupServiceAccounts))
+	for g := range bundle.Kubernetes.GroupServiceAccounts {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	for _, g := range groups {
+		exists, err := da.GroupExists(ctx, g)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return gerrs.Wrap(ErrUnknownMappedGroup, fmt.Errorf("group %q doesn't exist", g))
+		}
+	}
+
+	return nil
 }
 
 // handlePutBundleVersion handles "PUT /v2/bundles/{name}/versions/{version}"
@@ -248,7 +402,26 @@ func handlePutBundleVersion(w http.ResponseWriter, r *http.Request) {
 	bundle.Name = params["name"]
 	bundle.Version = params["version"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	if err := bundle.ValidatePlatforms(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := bundles.CheckImage(bundle.Image); err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	warnings := bundles.Analyze(bundle)
+	if err := bundles.CheckAnalysis(warnings); err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	bundle.InstalledBy = requestUsername(r)
+	bundle.Review = newBundleReview()
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -259,14 +432,331 @@ func handlePutBundleVersion(w http.ResponseWriter, r *http.Request) {
 		respondAndLogError(r.Context(), w, err)
 		return
 	}
+
+	json.NewEncoder(w).Encode(rest.BundleInstallResult{
+		Name:     bundle.Name,
+		Version:  bundle.Version,
+		Warnings: warnings,
+	})
+}
+
+// handlePutBundleVersionUpgrade handles "PUT /v2/bundles/{name}/versions/{version}/upgrade"
+func handlePutBundleVersionUpgrade(w http.ResponseWriter, r *http.Request) {
+	var bundle data.Bundle
+	var err error
+
+	err = json.NewDecoder(r.Body).Decode(&bundle)
+	if err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	params := mux.Vars(r)
+	bundle.Name = params["name"]
+	bundle.Version = params["version"]
+
+	if err := bundle.ValidatePlatforms(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := bundles.CheckImage(bundle.Image); err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	warnings := bundles.Analyze(bundle)
+	if err := bundles.CheckAnalysis(warnings); err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	enable := strings.EqualFold(r.FormValue("enable"), "true")
+
+	bundle.InstalledBy = requestUsername(r)
+	bundle.Review = newBundleReview()
+
+	retain := 0
+	if v := r.FormValue("retain"); v != "" {
+		retain, err = strconv.Atoi(v)
+		if err != nil || retain < 0 {
+			http.Error(w, "retain must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	// A version that's installed by the upgrade can't be enabled by it if
+	// it'll be pending review. A version that's already installed isn't
+	// reinstalled, so its existing review state applies.
+	if enable {
+		exists, err := dataAccessLayer.BundleVersionExists(r.Context(), bundle.Name, bundle.Version)
+		switch {
+		case err != nil:
+		case exists:
+			err = checkBundleReview(r.Context(), dataAccessLayer, bundle.Name, bundle.Version)
+		case !bundle.Review.Enableable():
+			err = gerrs.Wrap(ErrBundleNotApproved, fmt.Errorf("%s %s would be pending review", bundle.Name, bundle.Version))
+		}
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+	}
+
+	result, err := dataAccessLayer.BundleUpgrade(r.Context(), bundle, enable, retain)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if result.Installed {
+		result.Warnings = warnings
+	}
+
+	if result.Enabled {
+		collisions, err := checkTriggerCollisions(r.Context(), dataAccessLayer, bundle.Name, bundle.Version)
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+		result.Warnings = append(result.Warnings, collisions...)
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleGetBundleVersionReview handles "GET /v2/bundles/{name}/versions/{version}/review"
+//
+// The response describes the version's review state and its differences
+// from the currently enabled version of the bundle.
+func handleGetBundleVersionReview(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	name := params["name"]
+	version := params["version"]
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	bundle, err := dataAccessLayer.BundleGet(r.Context(), name, version)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	enabledVersion, err := dataAccessLayer.BundleEnabledVersion(r.Context(), name)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	var base data.Bundle
+	if enabledVersion != "" && enabledVersion != version {
+		base, err = dataAccessLayer.BundleGet(r.Context(), name, enabledVersion)
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+	}
+
+	diff, err := bundles.Diff(base, bundle)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(rest.BundleReviewDiff{
+		Name:        name,
+		Version:     version,
+		BaseVersion: base.Version,
+		Status:      bundle.Review.Status,
+		InstalledBy: bundle.InstalledBy,
+		Reviewer:    bundle.Review.Reviewer,
+		Comment:     bundle.Review.Comment,
+		Diff:        diff,
+	})
 }
 
-func getAllBundles(ctx context.Context) ([]data.Bundle, error) {
-	dataAccessLayer, err := dataaccess.Get()
+// handlePutBundleVersionReview handles "PUT /v2/bundles/{name}/versions/{version}/review"
+//
+// The request body is a rest.BundleReviewDecision. Only a version that's
+// pending review may be reviewed, and not by the user that installed it.
+func handlePutBundleVersionReview(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	name := params["name"]
+	version := params["version"]
+
+	var decision rest.BundleReviewDecision
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	bundle, err := dataAccessLayer.BundleGet(r.Context(), name, version)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if bundle.Review.Status != data.BundleReviewPending {
+		respondAndLogError(r.Context(), w, ErrBundleNotPending)
+		return
+	}
+
+	user, err := getRequestContext(r).User()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if user.Username == bundle.InstalledBy {
+		respondAndLogError(r.Context(), w, ErrSelfReview)
+		return
+	}
+
+	review := data.BundleReview{
+		Status:     data.BundleReviewRejected,
+		Reviewer:   user.Username,
+		ReviewedOn: time.Now().UTC(),
+		Comment:    decision.Comment,
+	}
+	if decision.Approve {
+		review.Status = data.BundleReviewApproved
+	}
+
+	err = dataAccessLayer.BundleReviewUpdate(r.Context(), name, version, review)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleGetBundleOrphans handles "GET /v2/bundles/{name}/orphans"
+//
+// Any "exclude" query values are treated as versions that are about to be
+// uninstalled, so the response describes the orphans that would exist after
+// uninstalling them.
+func handleGetBundleOrphans(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	r.ParseForm()
+
+	orphans, err := findOrphanedPermissions(r.Context(), dataAccessLayer, name, r.Form["exclude"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(orphans)
+}
+
+// handleDeleteBundleOrphans handles "DELETE /v2/bundles/{name}/orphans"
+func handleDeleteBundleOrphans(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	orphans, err := findOrphanedPermissions(r.Context(), dataAccessLayer, name, nil)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	for _, o := range orphans {
+		err = dataAccessLayer.RolePermissionDelete(r.Context(), o.Role, o.BundleName, o.Permission)
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(orphans)
+}
+
+// findOrphanedPermissions returns all role permissions for the named bundle
+// that aren't declared by any of its installed versions, ignoring any
+// versions listed in exclude.
+func findOrphanedPermissions(ctx context.Context, da dataaccess.DataAccess, bundlename string, exclude []string) ([]rest.OrphanedPermission, error) {
+	versions, err := da.BundleVersionList(ctx, bundlename)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[string]bool{}
+	for _, v := range exclude {
+		excluded[v] = true
+	}
+
+	declared := map[string]bool{}
+	for _, b := range versions {
+		if excluded[b.Version] {
+			continue
+		}
+		for _, p := range b.Permissions {
+			declared[p] = true
+		}
+	}
+
+	roles, err := da.RoleList(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	orphans := []rest.OrphanedPermission{}
+	for _, role := range roles {
+		rpl, err := da.RolePermissionList(ctx, role.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range rpl {
+			if p.BundleName != bundlename || declared[p.Permission] {
+				continue
+			}
+
+			orphans = append(orphans, rest.OrphanedPermission{
+				Role:       role.Name,
+				BundleName: p.BundleName,
+				Permission: p.Permission,
+			})
+		}
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Role != orphans[j].Role {
+			return orphans[i].Role < orphans[j].Role
+		}
+		return orphans[i].Permission < orphans[j].Permission
+	})
+
+	return orphans, nil
+}
+
+func getAllBundles(ctx context.Context, dataAccessLayer dataaccess.DataAccess) ([]data.Bundle, error) {
 	// Explicit bundles from the data layer
 	bundles, err := dataAccessLayer.BundleList(ctx)
 	if err != nil {
@@ -281,15 +771,20 @@ func getAllBundles(ctx context.Context) ([]data.Bundle, error) {
 func addBundleMethodsToRouter(router *mux.Router) {
 	router.Handle("/v2/bundles", otelhttp.NewHandler(authCommand(handleGetBundles, "help"), "handleGetBundles")).Methods("GET")
 
-	router.Handle("/v2/bundles/{name}", otelhttp.NewHandler(authCommand(handleHeadBundles, "bundle", "info"), "handleHeadBundles")).Methods("HEAD")
-	router.Handle("/v2/bundles/{name}", otelhttp.NewHandler(authCommand(handleGetBundleVersions, "bundle", "info"), "handleGetBundleVersions")).Methods("GET")
-	router.Handle("/v2/bundles/{name}/versions", otelhttp.NewHandler(authCommand(handleGetBundleVersions, "bundle", "list"), "handleGetBundleVersions")).Methods("GET")
-
-	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authCommand(handleGetBundleVersion, "bundle", "info"), "handleGetBundleVersion")).Methods("GET")
-	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authCommand(handleHeadBundleVersion, "bundle", "info"), "handleHeadBundleVersion")).Methods("HEAD")
-	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authCommand(handlePutBundleVersion, "bundle", "install"), "handlePutBundleVersion")).Methods("PUT")
-	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authCommand(handleDeleteBundleVersion, "bundle", "install"), "handleDeleteBundleVersion")).Methods("DELETE")
-
-	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authCommand(handlePatchBundleVersion, "bundle", "enable"), "handlePatchBundleVersion")).Methods("PATCH")
-	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authCommand(handlePatchBundleVersion, "bundle", "enable"), "handlePatchBundleVersion")).Methods("PATCH").Queries("enabled", "")
+	router.Handle("/v2/bundles/{name}", otelhttp.NewHandler(authBundleCommand(handleHeadBundles, "name", "bundle", "info"), "handleHeadBundles")).Methods("HEAD")
+	router.Handle("/v2/bundles/{name}", otelhttp.NewHandler(authBundleCommand(handleGetBundleVersions, "name", "bundle", "info"), "handleGetBundleVersions")).Methods("GET")
+	router.Handle("/v2/bundles/{name}/orphans", otelhttp.NewHandler(authBundleCommand(handleGetBundleOrphans, "name", "bundle", "info"), "handleGetBundleOrphans")).Methods("GET")
+	router.Handle("/v2/bundles/{name}/orphans", otelhttp.NewHandler(authBundleCommand(handleDeleteBundleOrphans, "name", "bundle", "uninstall"), "handleDeleteBundleOrphans")).Methods("DELETE")
+	router.Handle("/v2/bundles/{name}/versions", otelhttp.NewHandler(authBundleCommand(handleGetBundleVersions, "name", "bundle", "list"), "handleGetBundleVersions")).Methods("GET")
+
+	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authBundleCommand(handleGetBundleVersion, "name", "bundle", "info"), "handleGetBundleVersion")).Methods("GET")
+	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authBundleCommand(handleHeadBundleVersion, "name", "bundle", "info"), "handleHeadBundleVersion")).Methods("HEAD")
+	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authBundleCommand(handlePutBundleVersion, "name", "bundle", "install"), "handlePutBundleVersion")).Methods("PUT")
+	router.Handle("/v2/bundles/{name}/versions/{version}/review", otelhttp.NewHandler(authBundleCommand(handleGetBundleVersionReview, "name", "bundle", "review"), "handleGetBundleVersionReview")).Methods("GET")
+	router.Handle("/v2/bundles/{name}/versions/{version}/review", otelhttp.NewHandler(authBundleCommand(handlePutBundleVersionReview, "name", "bundle", "approve"), "handlePutBundleVersionReview")).Methods("PUT")
+	router.Handle("/v2/bundles/{name}/versions/{version}/upgrade", otelhttp.NewHandler(authBundleCommand(handlePutBundleVersionUpgrade, "name", "bundle", "install"), "handlePutBundleVersionUpgrade")).Methods("PUT")
+	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authBundleCommand(handleDeleteBundleVersion, "name", "bundle", "uninstall"), "handleDeleteBundleVersion")).Methods("DELETE")
+
+	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authBundleCommand(handlePatchBundleVersion, "name", "bundle", "enable"), "handlePatchBundleVersion")).Methods("PATCH")
+	router.Handle("/v2/bundles/{name}/versions/{version}", otelhttp.NewHandler(authBundleCommand(handlePatchBundleVersion, "name", "bundle", "enable"), "handlePatchBundleVersion")).Methods("PATCH").Queries("enabled", "")
 }
@@ -0,0 +1,251 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestBundleOrphans(t *testing.T) {
+	router := createTestRouter()
+
+	// Grant a permission for a bundle that isn't installed.
+	NewResponseTester("PUT", "http://example.com/v2/roles/testBundleOrphans").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/roles/testBundleOrphans/bundles/testbundle/permissions/testpermission").WithStatus(http.StatusOK).Test(t, router)
+
+	// The permission is reported as orphaned.
+	orphans := []rest.OrphanedPermission{}
+	NewResponseTester("GET", "http://example.com/v2/bundles/testbundle/orphans").WithOutput(&orphans).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, []rest.OrphanedPermission{{Role: "testBundleOrphans", BundleName: "testbundle", Permission: "testpermission"}}, orphans)
+
+	// Permissions declared by an installed bundle aren't orphaned.
+	orphans = []rest.OrphanedPermission{}
+	NewResponseTester("GET", "http://example.com/v2/bundles/gort/orphans").WithOutput(&orphans).WithStatus(http.StatusOK).Test(t, router)
+	assert.Empty(t, orphans)
+
+	// Remove the orphans.
+	orphans = []rest.OrphanedPermission{}
+	NewResponseTester("DELETE", "http://example.com/v2/bundles/testbundle/orphans").WithOutput(&orphans).WithStatus(http.StatusOK).Test(t, router)
+	assert.Len(t, orphans, 1)
+
+	orphans = []rest.OrphanedPermission{}
+	NewResponseTester("GET", "http://example.com/v2/bundles/testbundle/orphans").WithOutput(&orphans).WithStatus(http.StatusOK).Test(t, router)
+	assert.Empty(t, orphans)
+}
+
+func TestBundleScopedPermissions(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	// A user that may only enable bundles whose names start with "team-".
+	require.NoError(t, da.UserCreate(ctx, rest.User{Username: "delegate"}))
+	require.NoError(t, da.GroupCreate(ctx, rest.Group{Name: "delegates"}))
+	require.NoError(t, da.GroupUserAdd(ctx, "delegates", "delegate"))
+	require.NoError(t, da.RoleCreate(ctx, "team-enablers"))
+	require.NoError(t, da.GroupRoleAdd(ctx, "delegates", "team-enablers"))
+	require.NoError(t, da.RolePermissionAdd(ctx, "team-enablers", "gort", "bundle_enable@team-*"))
+
+	token, err := da.TokenGenerate(ctx, "delegate", time.Minute)
+	require.NoError(t, err)
+
+	// Authorized, but the bundle doesn't exist.
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/team-foo/versions/0.0.1?enabled=true").WithToken(token).WithStatus(http.StatusNotFound).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/bundles/team-foo/versions/0.0.1").WithToken(token).WithStatus(http.StatusNotFound).Test(t, router)
+
+	// Out of scope.
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/other/versions/0.0.1?enabled=true").WithToken(token).WithStatus(http.StatusUnauthorized).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/bundles/other/versions/0.0.1").WithToken(token).WithStatus(http.StatusUnauthorized).Test(t, router)
+
+	// In scope, but lacking the permission.
+	NewResponseTester("DELETE", "http://example.com/v2/bundles/team-foo/versions/0.0.1").WithToken(token).WithStatus(http.StatusUnauthorized).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/configs/team-foo/bundle/team-foo/key").WithToken(token).WithStatus(http.StatusUnauthorized).Test(t, router)
+
+	// Malformed scopes can't be granted.
+	NewResponseTester("PUT", "http://example.com/v2/roles/team-enablers/bundles/gort/permissions/bundle_enable@%5B").WithStatus(http.StatusBadRequest).Test(t, router)
+}
+
+func TestBundleEnableGroupServiceAccounts(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	require.NoError(t, da.BundleCreate(ctx, data.Bundle{
+		GortBundleVersion: 1,
+		Name:              "mapped",
+		Version:           "0.0.1",
+		Description:       "A bundle with group service accounts.",
+		Kubernetes: data.BundleKubernetes{
+			ServiceAccountName:   "default-sa",
+			GroupServiceAccounts: map[string]string{"mapped-team": "team-sa"},
+		},
+	}))
+	defer da.BundleDelete(ctx, "mapped", "0.0.1")
+
+	// The mapped group doesn't exist yet.
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/mapped/versions/0.0.1?enabled=true").WithStatus(http.StatusBadRequest).Test(t, router)
+
+	require.NoError(t, da.GroupCreate(ctx, rest.Group{Name: "mapped-team"}))
+	defer da.GroupDelete(ctx, "mapped-team")
+
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/mapped/versions/0.0.1?enabled=true").WithStatus(http.StatusOK).Test(t, router)
+	require.NoError(t, da.BundleDisable(ctx, "mapped", "0.0.1"))
+}
+
+func TestBundleReview(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	require.NoError(t, da.BundleCreate(ctx, data.Bundle{
+		GortBundleVersion: 1,
+		Name:              "reviewed",
+		Version:           "0.0.1",
+		Description:       "A bundle that's pending review.",
+		InstalledBy:       adminToken.User,
+		Review:            data.BundleReview{Status: data.BundleReviewPending},
+	}))
+	defer da.BundleDelete(ctx, "reviewed", "0.0.1")
+
+	// Pending bundles can't be enabled.
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/reviewed/versions/0.0.1?enabled=true").WithStatus(http.StatusForbidden).Test(t, router)
+
+	diff := rest.BundleReviewDiff{}
+	NewResponseTester("GET", "http://example.com/v2/bundles/reviewed/versions/0.0.1/review").WithOutput(&diff).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, data.BundleReviewPending, diff.Status)
+	assert.Equal(t, adminToken.User, diff.InstalledBy)
+	assert.Empty(t, diff.BaseVersion)
+	assert.Contains(t, diff.Diff, "+name: reviewed")
+
+	// The installer can't review their own bundle.
+	approve := rest.BundleReviewDecision{Approve: true, Comment: "lgtm"}
+	NewResponseTester("PUT", "http://example.com/v2/bundles/reviewed/versions/0.0.1/review").WithBody(approve).WithStatus(http.StatusForbidden).Test(t, router)
+
+	// A reviewer.
+	require.NoError(t, da.UserCreate(ctx, rest.User{Username: "reviewer"}))
+	require.NoError(t, da.GroupCreate(ctx, rest.Group{Name: "reviewers"}))
+	require.NoError(t, da.GroupUserAdd(ctx, "reviewers", "reviewer"))
+	require.NoError(t, da.RoleCreate(ctx, "reviewers"))
+	require.NoError(t, da.GroupRoleAdd(ctx, "reviewers", "reviewers"))
+	require.NoError(t, da.RolePermissionAdd(ctx, "reviewers", "gort", "bundle_review"))
+
+	token, err := da.TokenGenerate(ctx, "reviewer", time.Minute)
+	require.NoError(t, err)
+
+	// Reviewers may review, but not enable.
+	NewResponseTester("PUT", "http://example.com/v2/bundles/reviewed/versions/0.0.1/review").WithToken(token).WithBody(approve).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/reviewed/versions/0.0.1?enabled=true").WithToken(token).WithStatus(http.StatusUnauthorized).Test(t, router)
+
+	bundle, err := da.BundleGet(ctx, "reviewed", "0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, data.BundleReviewApproved, bundle.Review.Status)
+	assert.Equal(t, "reviewer", bundle.Review.Reviewer)
+	assert.Equal(t, "lgtm", bundle.Review.Comment)
+
+	// Only pending bundles can be reviewed.
+	NewResponseTester("PUT", "http://example.com/v2/bundles/reviewed/versions/0.0.1/review").WithToken(token).WithBody(approve).WithStatus(http.StatusConflict).Test(t, router)
+
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/reviewed/versions/0.0.1?enabled=true").WithStatus(http.StatusOK).Test(t, router)
+	require.NoError(t, da.BundleDisable(ctx, "reviewed", "0.0.1"))
+}
+
+func TestBundleInstallWarnings(t *testing.T) {
+	router := createTestRouter()
+
+	bundle := data.Bundle{
+		GortBundleVersion: 1,
+		Name:              "warned",
+		Version:           "0.0.1",
+		Description:       "A bundle with risky characteristics.",
+		Image:             "ubuntu:latest",
+		Commands: map[string]*data.BundleCommand{
+			"run": {
+				Executable: []string{"/bin/true"},
+				Rules:      []string{"allow"},
+			},
+		},
+	}
+
+	result := rest.BundleInstallResult{}
+	NewResponseTester("PUT", "http://example.com/v2/bundles/warned/versions/0.0.1").WithBody(bundle).WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+	defer NewResponseTester("DELETE", "http://example.com/v2/bundles/warned/versions/0.0.1").WithStatus(http.StatusOK).Test(t, router)
+
+	assert.Equal(t, "warned", result.Name)
+
+	var codes []string
+	for _, w := range result.Warnings {
+		codes = append(codes, w.Code)
+	}
+	assert.Contains(t, codes, "latest-image")
+	assert.Contains(t, codes, "allow-all-rule")
+	assert.Contains(t, codes, "missing-templates")
+}
+
+func TestBundleEnableTriggerCollisions(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	for _, b := range []struct{ name, trigger string }{{"deployer", `^deploy \w+`}, {"shipper", `^deploy web$`}} {
+		require.NoError(t, da.BundleCreate(ctx, data.Bundle{
+			GortBundleVersion: 1,
+			Name:              b.name,
+			Version:           "0.0.1",
+			Description:       "A bundle with a trigger.",
+			Commands: map[string]*data.BundleCommand{
+				"run": {
+					Executable: []string{"/bin/true"},
+					Rules:      []string{"must have " + b.name + ":run"},
+					Triggers:   []data.Trigger{{Match: b.trigger}},
+				},
+			},
+		}))
+		defer da.BundleDelete(ctx, b.name, "0.0.1")
+		defer da.BundleDisable(ctx, b.name, "0.0.1")
+	}
+
+	// The first bundle has nothing to collide with.
+	result := rest.BundleEnableResult{}
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/deployer/versions/0.0.1?enabled=true").WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+	assert.Empty(t, result.Warnings)
+
+	result = rest.BundleEnableResult{}
+	NewResponseTester("PATCH", "http://example.com/v2/bundles/shipper/versions/0.0.1?enabled=true").WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+	if assert.Len(t, result.Warnings, 1) {
+		assert.Equal(t, "trigger-collision", result.Warnings[0].Code)
+		assert.Equal(t, "run", result.Warnings[0].Command)
+		assert.Contains(t, result.Warnings[0].Message, "deployer:run")
+	}
+}
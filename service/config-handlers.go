@@ -18,6 +18,7 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -35,23 +36,53 @@ const (
 	ParameterConfigurationBundle = "bundle"
 	ParameterConfigurationOwner  = "owner"
 	ParameterConfigurationKey    = "key"
+
+	// ParameterConfigurationBundleName is the bundle parameter of the routes
+	// under "/v2/bundles/{name}/config".
+	ParameterConfigurationBundleName = "name"
+)
+
+var (
+	// ErrConfigOwnerRequired is returned when a room or group configuration
+	// is addressed with the "-" owner placeholder.
+	ErrConfigOwnerRequired = errors.New("room and group configurations must name an owner")
 )
 
+// configBundleParameter returns the bundle named by either form of dynamic
+// configuration route.
+func configBundleParameter(params map[string]string) string {
+	if b, ok := params[ParameterConfigurationBundleName]; ok {
+		return b
+	}
+	return params[ParameterConfigurationBundle]
+}
+
 func getDynamicConfigParameters(params map[string]string) (layer data.ConfigurationLayer, bundle string, owner string, key string, err error) {
 	layer = data.ConfigurationLayer(params[ParameterConfigurationLayer])
-	bundle = params[ParameterConfigurationBundle]
+	bundle = configBundleParameter(params)
 	owner = params[ParameterConfigurationOwner]
 	key = params[ParameterConfigurationKey]
 	err = layer.Validate()
+	if err != nil {
+		return
+	}
 
-	if layer == data.LayerBundle {
+	// The bundle layer has no owner, and for the user layer an owner of "-"
+	// refers to the requesting user. Rooms and groups must always be named.
+	switch {
+	case layer == data.LayerBundle:
+		owner = ""
+	case owner == "-" && layer == data.LayerUser:
 		owner = ""
+	case owner == "-":
+		err = ErrConfigOwnerRequired
 	}
 
 	return
 }
 
 // handleDeleteDynamicConfig handles "DELETE /v2/configs/{bundle}/{layer}/{owner}/{key}"
+// and "DELETE /v2/bundles/{name}/config/{layer}/{owner}/{key}"
 func handleDeleteDynamicConfig(w http.ResponseWriter, r *http.Request) {
 	dc, err := dynamic.Get()
 	if err != nil {
@@ -66,7 +97,7 @@ func handleDeleteDynamicConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if layer == data.LayerUser {
-		user, err := getUserByRequest(r)
+		user, err := getRequestContext(r).User()
 		if err != nil {
 			respondAndLogError(r.Context(), w, err)
 			return
@@ -87,7 +118,8 @@ func handleDeleteDynamicConfig(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleGetDynamicConfigs handles "GET /v2/configs"
+// handleGetDynamicConfigs handles "GET /v2/configs/{bundle}/..." and
+// "GET /v2/bundles/{name}/config/..."
 func handleGetDynamicConfigs(w http.ResponseWriter, r *http.Request) {
 	dc, err := dynamic.Get()
 	if err != nil {
@@ -96,18 +128,18 @@ func handleGetDynamicConfigs(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := mux.Vars(r)
-	p := func(key string) string {
-		if val := params[key]; val == "*" {
+	p := func(val string) string {
+		if val == "*" {
 			return ""
 		} else {
 			return val
 		}
 	}
 
-	layer := data.ConfigurationLayer(p(ParameterConfigurationLayer))
-	bundle := p(ParameterConfigurationBundle)
-	owner := p(ParameterConfigurationOwner)
-	key := p(ParameterConfigurationKey)
+	layer := data.ConfigurationLayer(p(params[ParameterConfigurationLayer]))
+	bundle := p(configBundleParameter(params))
+	owner := p(params[ParameterConfigurationOwner])
+	key := p(params[ParameterConfigurationKey])
 
 	configs, err := dc.List(r.Context(), layer, bundle, owner, key)
 	if err != nil {
@@ -119,7 +151,7 @@ func handleGetDynamicConfigs(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Filter to ensure only the accessible configs are returned
-	user, err := getUserByRequest(r)
+	user, err := getRequestContext(r).User()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -143,6 +175,7 @@ func handleGetDynamicConfigs(w http.ResponseWriter, r *http.Request) {
 }
 
 // handlePutDynamicConfiguration handles "PUT /v2/configs/{bundle}/{layer}/{owner}/{key}"
+// and "PUT /v2/bundles/{name}/config/{layer}/{owner}/{key}"
 func handlePutDynamicConfiguration(w http.ResponseWriter, r *http.Request) {
 	dc, err := dynamic.Get()
 	if err != nil {
@@ -164,7 +197,7 @@ func handlePutDynamicConfiguration(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if layer == data.LayerUser {
-		user, err := getUserByRequest(r)
+		user, err := getRequestContext(r).User()
 		if err != nil {
 			respondAndLogError(r.Context(), w, err)
 			return
@@ -196,10 +229,17 @@ func handlePutDynamicConfiguration(w http.ResponseWriter, r *http.Request) {
 }
 
 func addConfigMethodsToRouter(router *mux.Router) {
-	router.Handle("/v2/configs/{bundle}", otelhttp.NewHandler(authCommand(handleGetDynamicConfigs, "config", "get"), "handleGetConfigs")).Methods("GET")
-	router.Handle("/v2/configs/{bundle}/{layer}", otelhttp.NewHandler(authCommand(handleGetDynamicConfigs, "config", "get"), "handleGetConfigs")).Methods("GET")
-	router.Handle("/v2/configs/{bundle}/{layer}/{owner}", otelhttp.NewHandler(authCommand(handleGetDynamicConfigs, "config", "get"), "handleGetConfigs")).Methods("GET")
-	router.Handle("/v2/configs/{bundle}/{layer}/{owner}/{key}", otelhttp.NewHandler(authCommand(handleGetDynamicConfigs, "config", "get"), "handleGetConfigs")).Methods("GET")
-	router.Handle("/v2/configs/{bundle}/{layer}/{owner}/{key}", otelhttp.NewHandler(authCommand(handlePutDynamicConfiguration, "config", "set"), "handlePutDynamicConfiguration")).Methods("PUT")
-	router.Handle("/v2/configs/{bundle}/{layer}/{owner}/{key}", otelhttp.NewHandler(authCommand(handleDeleteDynamicConfig, "config", "delete"), "handleDeleteConfig")).Methods("DELETE")
+	router.Handle("/v2/configs/{bundle}", otelhttp.NewHandler(authBundleCommand(handleGetDynamicConfigs, "bundle", "config", "get"), "handleGetConfigs")).Methods("GET")
+	router.Handle("/v2/configs/{bundle}/{layer}", otelhttp.NewHandler(authBundleCommand(handleGetDynamicConfigs, "bundle", "config", "get"), "handleGetConfigs")).Methods("GET")
+	router.Handle("/v2/configs/{bundle}/{layer}/{owner}", otelhttp.NewHandler(authBundleCommand(handleGetDynamicConfigs, "bundle", "config", "get"), "handleGetConfigs")).Methods("GET")
+	router.Handle("/v2/configs/{bundle}/{layer}/{owner}/{key}", otelhttp.NewHandler(authBundleCommand(handleGetDynamicConfigs, "bundle", "config", "get"), "handleGetConfigs")).Methods("GET")
+	router.Handle("/v2/configs/{bundle}/{layer}/{owner}/{key}", otelhttp.NewHandler(authBundleCommand(handlePutDynamicConfiguration, "bundle", "config", "set"), "handlePutDynamicConfiguration")).Methods("PUT")
+	router.Handle("/v2/configs/{bundle}/{layer}/{owner}/{key}", otelhttp.NewHandler(authBundleCommand(handleDeleteDynamicConfig, "bundle", "config", "delete"), "handleDeleteConfig")).Methods("DELETE")
+
+	router.Handle("/v2/bundles/{name}/config", otelhttp.NewHandler(authBundleCommand(handleGetDynamicConfigs, "name", "config", "get"), "handleGetConfigs")).Methods("GET")
+	router.Handle("/v2/bundles/{name}/config/{layer}", otelhttp.NewHandler(authBundleCommand(handleGetDynamicConfigs, "name", "config", "get"), "handleGetConfigs")).Methods("GET")
+	router.Handle("/v2/bundles/{name}/config/{layer}/{owner}", otelhttp.NewHandler(authBundleCommand(handleGetDynamicConfigs, "name", "config", "get"), "handleGetConfigs")).Methods("GET")
+	router.Handle("/v2/bundles/{name}/config/{layer}/{owner}/{key}", otelhttp.NewHandler(authBundleCommand(handleGetDynamicConfigs, "name", "config", "get"), "handleGetConfigs")).Methods("GET")
+	router.Handle("/v2/bundles/{name}/config/{layer}/{owner}/{key}", otelhttp.NewHandler(authBundleCommand(handlePutDynamicConfiguration, "name", "config", "set"), "handlePutDynamicConfiguration")).Methods("PUT")
+	router.Handle("/v2/bundles/{name}/config/{layer}/{owner}/{key}", otelhttp.NewHandler(authBundleCommand(handleDeleteDynamicConfig, "name", "config", "delete"), "handleDeleteConfig")).Methods("DELETE")
 }
@@ -153,3 +153,76 @@ func TestGetDynamicConfigs(t *testing.T) {
 		assert.ElementsMatch(t, test.expected, list, msg, i, test.layer, test.bundle, test.owner, test.key)
 	}
 }
+
+func TestBundleDynamicConfig(t *testing.T) {
+	const base = "http://example.com/v2/bundles/bundle-service-scoped/config"
+
+	router := createTestRouter()
+
+	dcs := []data.DynamicConfiguration{
+		{Bundle: "bundle-service-scoped", Layer: data.LayerBundle, Key: "REGION", Value: "us-east-1"},
+		{Bundle: "bundle-service-scoped", Layer: data.LayerRoom, Owner: "ops", Key: "TOKEN", Value: "hunter2", Secret: true},
+	}
+
+	NewResponseTester("PUT", base+"/bundle/-/REGION").WithBody(dcs[0]).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", base+"/room/ops/TOKEN").WithBody(dcs[1]).WithStatus(http.StatusOK).Test(t, router)
+
+	// Secret values are never returned.
+	redacted := dcs[1]
+	redacted.Value = ""
+
+	list := []data.DynamicConfiguration{}
+	NewResponseTester("GET", base).WithOutput(&list).WithStatus(http.StatusOK).Test(t, router)
+	assert.ElementsMatch(t, []data.DynamicConfiguration{dcs[0], redacted}, list)
+
+	list = []data.DynamicConfiguration{}
+	NewResponseTester("GET", base+"/room").WithOutput(&list).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, []data.DynamicConfiguration{redacted}, list)
+
+	// The same configurations are visible through the cross-bundle routes.
+	list = []data.DynamicConfiguration{}
+	NewResponseTester("GET", "http://example.com/v2/configs/bundle-service-scoped/bundle").WithOutput(&list).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, []data.DynamicConfiguration{dcs[0]}, list)
+
+	NewResponseTester("PUT", base+"/bundle/-/GORT_FOO").WithBody(dcs[0]).WithStatus(http.StatusForbidden).Test(t, router)
+
+	NewResponseTester("DELETE", base+"/bundle/-/REGION").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("DELETE", base+"/room/ops/TOKEN").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("GET", base).WithStatus(http.StatusNoContent).Test(t, router)
+}
+
+func TestDynamicConfigOwnerPlaceholder(t *testing.T) {
+	const base = "http://example.com/v2/configs/bundle-service-placeholder"
+
+	router := createTestRouter()
+
+	tests := []struct {
+		layer  data.ConfigurationLayer
+		owner  string
+		status int
+	}{
+		{layer: data.LayerBundle, owner: "", status: http.StatusOK},
+		{layer: data.LayerUser, owner: "admin", status: http.StatusOK},
+		{layer: data.LayerRoom, status: http.StatusBadRequest},
+		{layer: data.LayerGroup, status: http.StatusBadRequest},
+	}
+
+	// The "-" placeholder resolves to the layer's real owner, where it has
+	// one that can be implied.
+	for _, test := range tests {
+		url := fmt.Sprintf("%s/%s/-/foo", base, test.layer)
+		dc := data.DynamicConfiguration{Value: "test-value"}
+
+		NewResponseTester("PUT", url).WithBody(dc).WithStatus(test.status).Test(t, router, "Layer=%q", test.layer)
+
+		if test.status == http.StatusOK {
+			list := []data.DynamicConfiguration{}
+			NewResponseTester("GET", fmt.Sprintf("%s/%s", base, test.layer)).WithOutput(&list).WithStatus(http.StatusOK).Test(t, router, "Layer=%q", test.layer)
+			if assert.Len(t, list, 1, "Layer=%q", test.layer) {
+				assert.Equal(t, test.owner, list[0].Owner, "Layer=%q", test.layer)
+			}
+		}
+
+		NewResponseTester("DELETE", url).WithStatus(test.status).Test(t, router, "Layer=%q", test.layer)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// handleGetCosts handles "GET /v2/costs"
+// The optional "month" parameter, like "2021-06", restricts the report to a
+// single month.
+func handleGetCosts(w http.ResponseWriter, r *http.Request) {
+	month := r.FormValue("month")
+	if month != "" {
+		if _, err := time.Parse("2006-01", month); err != nil {
+			http.Error(w, "month must be of the form YYYY-MM", http.StatusBadRequest)
+			return
+		}
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	records, err := dataAccessLayer.CostList(r.Context(), month)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(records)
+}
+
+func addCostMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/costs", otelhttp.NewHandler(authCommand(handleGetCosts, "costs"), "handleGetCosts")).Methods("GET")
+}
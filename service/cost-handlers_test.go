@@ -0,0 +1,55 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestGetCosts(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	for _, month := range []string{"2021-05", "2021-06"} {
+		require.NoError(t, da.CostAdd(ctx, data.CostRecord{
+			Month: month, Bundle: "test", Group: "ops", Executions: 1, CPUSeconds: 2,
+		}))
+	}
+
+	records := []data.CostRecord{}
+	NewResponseTester("GET", "http://example.com/v2/costs").WithOutput(&records).WithStatus(http.StatusOK).Test(t, router)
+	assert.Len(t, records, 2)
+
+	records = []data.CostRecord{}
+	NewResponseTester("GET", "http://example.com/v2/costs?month=2021-06").WithOutput(&records).WithStatus(http.StatusOK).Test(t, router)
+	require.Len(t, records, 1)
+	assert.Equal(t, "2021-06", records[0].Month)
+	assert.Equal(t, 2.0, records[0].CPUSeconds)
+
+	NewResponseTester("GET", "http://example.com/v2/costs?month=June").WithStatus(http.StatusBadRequest).Test(t, router)
+}
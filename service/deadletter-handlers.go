@@ -0,0 +1,170 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess/errs"
+)
+
+// RedeliverFunc sends a dead letter's message to its original channel. It's
+// provided by the adapter layer.
+type RedeliverFunc func(ctx context.Context, letter data.DeadLetter) error
+
+var redeliverer RedeliverFunc
+
+// SetRedeliverer sets the function used to redeliver dead letters by
+// "POST /v2/deadletters/{id}/redeliver".
+func SetRedeliverer(f RedeliverFunc) {
+	redeliverer = f
+}
+
+// getDeadLetterID extracts a dead letter ID from a request's path
+// parameters. A malformed ID can't exist, so it's reported as such.
+func getDeadLetterID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return 0, errs.ErrNoSuchDeadLetter
+	}
+
+	return id, nil
+}
+
+// handleDeleteDeadLetter handles "DELETE /v2/deadletters/{id}"
+func handleDeleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := getDeadLetterID(r)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	err = dataAccessLayer.DeadLetterDelete(r.Context(), id)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleGetDeadLetter handles "GET /v2/deadletters/{id}"
+func handleGetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := getDeadLetterID(r)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	letter, err := dataAccessLayer.DeadLetterGet(r.Context(), id)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(letter)
+}
+
+// handleGetDeadLetters handles "GET /v2/deadletters"
+func handleGetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	letters, err := dataAccessLayer.DeadLetterList(r.Context())
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(letters)
+}
+
+// handlePostDeadLetterRedeliver handles "POST /v2/deadletters/{id}/redeliver"
+// A dead letter that's successfully redelivered is deleted. If redelivery
+// fails again, the dead letter is kept and the failure is recorded.
+func handlePostDeadLetterRedeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := getDeadLetterID(r)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	letter, err := dataAccessLayer.DeadLetterGet(r.Context(), id)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if redeliverer == nil {
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if sendErr := redeliverer(r.Context(), letter); sendErr != nil {
+		letter.Attempts++
+		letter.Error = sendErr.Error()
+		letter.LastAttempt = time.Now().UTC()
+
+		if err := dataAccessLayer.DeadLetterUpdate(r.Context(), letter); err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+
+		http.Error(w, "redelivery failed: "+sendErr.Error(), http.StatusBadGateway)
+		return
+	}
+
+	err = dataAccessLayer.DeadLetterDelete(r.Context(), id)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+func addDeadLetterMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/deadletters", otelhttp.NewHandler(authCommand(handleGetDeadLetters, "deadletter", "list"), "handleGetDeadLetters")).Methods("GET")
+	router.Handle("/v2/deadletters/{id}", otelhttp.NewHandler(authCommand(handleGetDeadLetter, "deadletter", "info"), "handleGetDeadLetter")).Methods("GET")
+	router.Handle("/v2/deadletters/{id}", otelhttp.NewHandler(authCommand(handleDeleteDeadLetter, "deadletter", "delete"), "handleDeleteDeadLetter")).Methods("DELETE")
+	router.Handle("/v2/deadletters/{id}/redeliver", otelhttp.NewHandler(authCommand(handlePostDeadLetterRedeliver, "deadletter", "redeliver"), "handlePostDeadLetterRedeliver")).Methods("POST")
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	letter := data.DeadLetter{
+		Adapter:     "slack",
+		ChannelID:   "C001",
+		Message:     "hello",
+		Error:       "channel_is_archived",
+		Attempts:    1,
+		Created:     time.Now().UTC(),
+		LastAttempt: time.Now().UTC(),
+	}
+	require.NoError(t, da.DeadLetterCreate(ctx, &letter))
+	url := fmt.Sprintf("http://example.com/v2/deadletters/%d", letter.ID)
+
+	letters := []data.DeadLetter{}
+	NewResponseTester("GET", "http://example.com/v2/deadletters").WithOutput(&letters).WithStatus(http.StatusOK).Test(t, router)
+	require.Len(t, letters, 1)
+	assert.Equal(t, letter.ID, letters[0].ID)
+
+	// No adapters to redeliver through.
+	NewResponseTester("POST", url+"/redeliver").WithStatus(http.StatusServiceUnavailable).Test(t, router)
+
+	// Redelivery fails again: the failure is recorded.
+	SetRedeliverer(func(ctx context.Context, l data.DeadLetter) error {
+		return errors.New("not_in_channel")
+	})
+	defer SetRedeliverer(nil)
+
+	NewResponseTester("POST", url+"/redeliver").WithStatus(http.StatusBadGateway).Test(t, router)
+
+	got := data.DeadLetter{}
+	NewResponseTester("GET", url).WithOutput(&got).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, 2, got.Attempts)
+	assert.Equal(t, "not_in_channel", got.Error)
+
+	// Redelivery succeeds: the dead letter is deleted.
+	var redelivered data.DeadLetter
+	SetRedeliverer(func(ctx context.Context, l data.DeadLetter) error {
+		redelivered = l
+		return nil
+	})
+
+	NewResponseTester("POST", url+"/redeliver").WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "hello", redelivered.Message)
+	assert.Equal(t, "C001", redelivered.ChannelID)
+
+	NewResponseTester("GET", url).WithStatus(http.StatusNotFound).Test(t, router)
+	NewResponseTester("DELETE", url).WithStatus(http.StatusNotFound).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/deadletters/bogus").WithStatus(http.StatusNotFound).Test(t, router)
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/data"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// getOptionDefaultParameters extracts an option default's identifying
+// fields from a request's path parameters.
+func getOptionDefaultParameters(params map[string]string) data.OptionDefault {
+	def := data.OptionDefault{
+		Bundle:  params["bundle"],
+		Command: params["command"],
+		Layer:   data.ConfigurationLayer(params["layer"]),
+		Owner:   params["owner"],
+		Option:  params["option"],
+	}
+
+	if def.Layer == data.LayerBundle {
+		def.Owner = ""
+	}
+
+	return def
+}
+
+// handleDeleteOptionDefault handles "DELETE /v2/defaults/{bundle}/{command}/{layer}/{owner}/{option}"
+func handleDeleteOptionDefault(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	def := getOptionDefaultParameters(mux.Vars(r))
+
+	err = dataAccessLayer.OptionDefaultDelete(r.Context(), def.Layer, def.Owner, def.Bundle, def.Command, def.Option)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleGetOptionDefaults handles "GET /v2/defaults/{bundle}/{command}"
+func handleGetOptionDefaults(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	params := mux.Vars(r)
+
+	defaults, err := dataAccessLayer.OptionDefaultList(r.Context(), params["bundle"], params["command"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(defaults)
+}
+
+// handlePutOptionDefault handles "PUT /v2/defaults/{bundle}/{command}/{layer}/{owner}/{option}"
+func handlePutOptionDefault(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	var body data.OptionDefault
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	def := getOptionDefaultParameters(mux.Vars(r))
+	def.Value = body.Value
+
+	err = dataAccessLayer.OptionDefaultSet(r.Context(), def)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+func addOptionDefaultMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/defaults/{bundle}/{command}", otelhttp.NewHandler(authCommand(handleGetOptionDefaults, "defaults", "list"), "handleGetOptionDefaults")).Methods("GET")
+	router.Handle("/v2/defaults/{bundle}/{command}/{layer}/{owner}/{option}", otelhttp.NewHandler(authCommand(handlePutOptionDefault, "defaults", "set"), "handlePutOptionDefault")).Methods("PUT")
+	router.Handle("/v2/defaults/{bundle}/{command}/{layer}/{owner}/{option}", otelhttp.NewHandler(authCommand(handleDeleteOptionDefault, "defaults", "delete"), "handleDeleteOptionDefault")).Methods("DELETE")
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestOptionDefaults(t *testing.T) {
+	router := createTestRouter()
+
+	NewResponseTester("PUT", "http://example.com/v2/defaults/test/echo/group/eu-ops/region").
+		WithBody(data.OptionDefault{Value: "eu-west-1"}).
+		WithStatus(http.StatusOK).
+		Test(t, router)
+
+	// The owner is ignored for the bundle layer.
+	NewResponseTester("PUT", "http://example.com/v2/defaults/test/echo/bundle/ignored/region").
+		WithBody(data.OptionDefault{Value: "us-west-2"}).
+		WithStatus(http.StatusOK).
+		Test(t, router)
+
+	// Users can't have defaults.
+	NewResponseTester("PUT", "http://example.com/v2/defaults/test/echo/user/admin/region").
+		WithBody(data.OptionDefault{Value: "us-west-2"}).
+		WithStatus(http.StatusExpectationFailed).
+		Test(t, router)
+
+	defaults := []data.OptionDefault{}
+	NewResponseTester("GET", "http://example.com/v2/defaults/test/echo").WithOutput(&defaults).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, []data.OptionDefault{
+		{Bundle: "test", Command: "echo", Layer: data.LayerBundle, Option: "region", Value: "us-west-2"},
+		{Bundle: "test", Command: "echo", Layer: data.LayerGroup, Owner: "eu-ops", Option: "region", Value: "eu-west-1"},
+	}, defaults)
+
+	NewResponseTester("DELETE", "http://example.com/v2/defaults/test/echo/group/eu-ops/region").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("DELETE", "http://example.com/v2/defaults/test/echo/group/eu-ops/region").WithStatus(http.StatusNotFound).Test(t, router)
+}
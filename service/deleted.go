@@ -0,0 +1,79 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// deletedPurgeInterval is how often users and groups whose retention period
+// has expired are permanently removed.
+const deletedPurgeInterval = 10 * time.Minute
+
+// deletedRetention returns how long deleted users and groups may be
+// restored for.
+func deletedRetention() time.Duration {
+	if r := config.GetGlobalConfigs().DeletedRetention; r > 0 {
+		return r
+	}
+
+	return data.DefaultDeletedRetention
+}
+
+// StartDeletedPurge periodically and permanently removes users and groups
+// that were deleted longer ago than global.deleted_retention, until the
+// context is cancelled.
+func StartDeletedPurge(ctx context.Context) {
+	ticker := time.NewTicker(deletedPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purgeDeleted(ctx)
+		}
+	}
+}
+
+func purgeDeleted(ctx context.Context) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get data access; deleted users and groups not purged")
+		return
+	}
+
+	n, err := da.DeletedPurge(ctx, time.Now().Add(-deletedRetention()))
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).Error("Failed to purge deleted users and groups")
+		return
+	}
+
+	if n > 0 {
+		log.WithField("count", n).Debug("Purged expired deleted users and groups")
+	}
+}
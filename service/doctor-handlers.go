@@ -0,0 +1,58 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// handleGetDoctor handles "GET /v2/doctor"
+// It reports dangling references in the data store without changing them.
+func handleGetDoctor(w http.ResponseWriter, r *http.Request) {
+	handleDoctor(w, r, false)
+}
+
+// handlePostDoctor handles "POST /v2/doctor"
+// It reports dangling references in the data store and repairs them.
+func handlePostDoctor(w http.ResponseWriter, r *http.Request) {
+	handleDoctor(w, r, true)
+}
+
+func handleDoctor(w http.ResponseWriter, r *http.Request, fix bool) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	report, err := Diagnose(r.Context(), dataAccessLayer, fix)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+func addDoctorMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/doctor", otelhttp.NewHandler(authCommand(handleGetDoctor, "doctor"), "handleGetDoctor")).Methods("GET")
+	router.Handle("/v2/doctor", otelhttp.NewHandler(authCommand(handlePostDoctor, "doctor"), "handlePostDoctor")).Methods("POST")
+}
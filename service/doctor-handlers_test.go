@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestDoctor(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	require.NoError(t, da.RoleCreate(ctx, "testDoctor"))
+	require.NoError(t, da.RolePermissionAdd(ctx, "testDoctor", "nosuchbundle", "perm"))
+
+	problemsFor := func(report rest.DoctorReport) []rest.DoctorProblem {
+		var problems []rest.DoctorProblem
+		for _, p := range report.Problems {
+			if p.Subject == "testDoctor" {
+				problems = append(problems, p)
+			}
+		}
+		return problems
+	}
+
+	// A check reports the problem but doesn't fix it
+	report := rest.DoctorReport{}
+	NewResponseTester("GET", "http://example.com/v2/doctor").WithOutput(&report).WithStatus(http.StatusOK).Test(t, router)
+	problems := problemsFor(report)
+	require.Len(t, problems, 1)
+	assert.Equal(t, rest.DoctorProblemPermission, problems[0].Kind)
+	assert.False(t, problems[0].Fixed)
+
+	exists, err := da.RolePermissionExists(ctx, "testDoctor", "nosuchbundle", "perm")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// A fix repairs it
+	report = rest.DoctorReport{}
+	NewResponseTester("POST", "http://example.com/v2/doctor").WithOutput(&report).WithStatus(http.StatusOK).Test(t, router)
+	problems = problemsFor(report)
+	require.Len(t, problems, 1)
+	assert.True(t, problems[0].Fixed)
+
+	exists, err = da.RolePermissionExists(ctx, "testDoctor", "nosuchbundle", "perm")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// And it's gone
+	report = rest.DoctorReport{}
+	NewResponseTester("GET", "http://example.com/v2/doctor").WithOutput(&report).WithStatus(http.StatusOK).Test(t, router)
+	assert.Empty(t, problemsFor(report))
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// CheckConsistency runs a read-only consistency check of the data store and
+// logs a warning for each dangling reference found. It's intended to be run
+// once at startup; problems can be repaired with `gort doctor --fix`.
+func CheckConsistency(ctx context.Context) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get data access; consistency check not run")
+		return
+	}
+
+	report, err := Diagnose(ctx, da, false)
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).Error("Consistency check failed")
+		return
+	}
+
+	for _, p := range report.Problems {
+		log.WithField("kind", p.Kind).
+			WithField("subject", p.Subject).
+			Warn(p.Description + "; run `gort doctor --fix` to repair")
+	}
+}
+
+// Diagnose checks the data store for dangling references: role permissions
+// for bundles that aren't installed, user mappings to adapters that aren't
+// configured, and enabled bundle versions that don't exist. If fix is true,
+// each problem found is repaired.
+func Diagnose(ctx context.Context, da dataaccess.DataAccess, fix bool) (rest.DoctorReport, error) {
+	report := rest.DoctorReport{Problems: []rest.DoctorProblem{}}
+
+	checks := []func(context.Context, dataaccess.DataAccess, bool) ([]rest.DoctorProblem, error){
+		diagnosePermissions,
+		diagnoseMappings,
+		diagnoseEnabledVersions,
+	}
+
+	for _, check := range checks {
+		problems, err := check(ctx, da, fix)
+		if err != nil {
+			return report, err
+		}
+
+		report.Problems = append(report.Problems, problems...)
+	}
+
+	return report, nil
+}
+
+// diagnosePermissions finds role permissions that refer to bundles that
+// aren't installed. Fixing revokes the permission.
+func diagnosePermissions(ctx context.Context, da dataaccess.DataAccess, fix bool) ([]rest.DoctorProblem, error) {
+	roles, err := da.RoleList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	var problems []rest.DoctorProblem
+
+	for _, role := range roles {
+		perms, err := da.RolePermissionList(ctx, role.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range perms {
+			exists, err := da.BundleExists(ctx, p.BundleName)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+
+			problem := rest.DoctorProblem{
+				Kind:        rest.DoctorProblemPermission,
+				Subject:     role.Name,
+				Description: fmt.Sprintf("role %q has permission %q for missing bundle %q", role.Name, p.String(), p.BundleName),
+			}
+
+			if fix {
+				if err := da.RolePermissionDelete(ctx, role.Name, p.BundleName, p.Permission); err != nil {
+					return nil, err
+				}
+				problem.Fixed = true
+			}
+
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems, nil
+}
+
+// diagnoseMappings finds user mappings to adapters that aren't present in
+// the configuration. Fixing removes the mapping. If no adapters are
+// configured at all, this check is skipped.
+func diagnoseMappings(ctx context.Context, da dataaccess.DataAccess, fix bool) ([]rest.DoctorProblem, error) {
+	adapters := map[string]bool{}
+	for _, p := range config.GetSlackProviders() {
+		adapters[p.Name] = true
+	}
+	for _, p := range config.GetDiscordProviders() {
+		adapters[p.Name] = true
+	}
+
+	if len(adapters) == 0 {
+		return nil, nil
+	}
+
+	users, err := da.UserList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	var problems []rest.DoctorProblem
+
+	for _, u := range users {
+		var stale []string
+		for adapter := range u.Mappings {
+			if !adapters[adapter] {
+				stale = append(stale, adapter)
+			}
+		}
+
+		if len(stale) == 0 {
+			continue
+		}
+
+		sort.Strings(stale)
+
+		var user rest.User
+		if fix {
+			if user, err = da.UserGet(ctx, u.Username); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, adapter := range stale {
+			problem := rest.DoctorProblem{
+				Kind:        rest.DoctorProblemMapping,
+				Subject:     u.Username,
+				Description: fmt.Sprintf("user %q is mapped to unknown adapter %q", u.Username, adapter),
+				Fixed:       fix,
+			}
+
+			if fix {
+				delete(user.Mappings, adapter)
+			}
+
+			problems = append(problems, problem)
+		}
+
+		if fix {
+			if err := da.UserUpdate(ctx, user); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// diagnoseEnabledVersions finds bundles whose enabled version isn't
+// installed. Fixing disables the bundle.
+func diagnoseEnabledVersions(ctx context.Context, da dataaccess.DataAccess, fix bool) ([]rest.DoctorProblem, error) {
+	bundles, err := da.BundleList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, b := range bundles {
+		names[b.Name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var problems []rest.DoctorProblem
+
+	for _, name := range sorted {
+		version, err := da.BundleEnabledVersion(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if version == "" {
+			continue
+		}
+
+		exists, err := da.BundleVersionExists(ctx, name, version)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		problem := rest.DoctorProblem{
+			Kind:        rest.DoctorProblemEnabledVersion,
+			Subject:     name,
+			Description: fmt.Sprintf("bundle %q has enabled version %q, which isn't installed", name, version),
+		}
+
+		if fix {
+			if err := da.BundleDisable(ctx, name, version); err != nil {
+				return nil, err
+			}
+			problem.Fixed = true
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
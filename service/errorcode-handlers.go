@@ -0,0 +1,253 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/oidc"
+)
+
+var (
+	// ErrNoSuchErrorCode is returned when a requested error code doesn't
+	// exist in the error code catalog.
+	ErrNoSuchErrorCode = errors.New("no such error code")
+)
+
+// REST error codes are in the GORT-4xxx range.
+func init() {
+	gerrs.RegisterCode(ErrUnauthorized, gerrs.Code{
+		Code:        "GORT-4001",
+		Title:       "Unauthorized",
+		Description: "The request didn't include a valid session token, or the user lacks the permissions required by the endpoint.",
+		Remediation: "Re-authenticate, or ask a Gort administrator to grant the required permission.",
+	})
+	gerrs.RegisterCode(ErrGortBundleDisabled, gerrs.Code{
+		Code:        "GORT-4002",
+		Title:       "Gort bundle disabled",
+		Description: "The default gort bundle, which is used to authorize REST requests, isn't installed or enabled.",
+		Remediation: "Enable the gort bundle with `gort bundle enable gort <version>`.",
+	})
+	gerrs.RegisterCode(ErrMissingValue, gerrs.Code{
+		Code:        "GORT-4003",
+		Title:       "Missing value",
+		Description: "A required value was missing from the request.",
+	})
+	gerrs.RegisterCode(ErrNoSuchCommand, gerrs.Code{
+		Code:        "GORT-4004",
+		Title:       "No such gort command",
+		Description: "The REST endpoint is authorized by a gort bundle command that doesn't exist in the installed version of the bundle.",
+		Remediation: "Upgrade the gort bundle to match the controller version.",
+	})
+	gerrs.RegisterCode(ErrNoSuchErrorCode, gerrs.Code{
+		Code:        "GORT-4005",
+		Title:       "No such error code",
+		Description: "The requested error code doesn't exist.",
+		Remediation: "Use GET /v2/errors to list all known error codes.",
+	})
+	gerrs.RegisterCode(ErrPurgeNotConfirmed, gerrs.Code{
+		Code:        "GORT-4006",
+		Title:       "User purge not confirmed",
+		Description: "A user purge request didn't include a confirm value matching the username being purged.",
+		Remediation: "Repeat the request with the username as the confirm query value.",
+	})
+	gerrs.RegisterCode(ErrUnknownMappedGroup, gerrs.Code{
+		Code:        "GORT-4007",
+		Title:       "Unknown mapped group",
+		Description: "The bundle maps a Gort group that doesn't exist to a Kubernetes service account.",
+		Remediation: "Create the group, or correct the bundle's kubernetes.group_service_accounts mapping, before enabling the bundle.",
+	})
+	gerrs.RegisterCode(ErrBundleNotApproved, gerrs.Code{
+		Code:        "GORT-4008",
+		Title:       "Bundle not approved",
+		Description: "Bundle review is required, and the bundle version is pending review or was rejected.",
+		Remediation: "Ask a user with the gort:bundle_review permission to approve it with `gort bundle approve <bundle> <version>`. When upgrading, upgrade without enabling and enable once approved.",
+	})
+	gerrs.RegisterCode(ErrBundleNotPending, gerrs.Code{
+		Code:        "GORT-4009",
+		Title:       "Bundle not pending review",
+		Description: "Only a bundle version that's pending review can be approved or rejected.",
+		Remediation: "Use `gort bundle review <bundle> <version>` to check its review status.",
+	})
+	gerrs.RegisterCode(ErrSelfReview, gerrs.Code{
+		Code:        "GORT-4010",
+		Title:       "Self review",
+		Description: "A bundle version can't be approved or rejected by the user that installed it.",
+		Remediation: "Ask a different user with the gort:bundle_review permission to review it.",
+	})
+	gerrs.RegisterCode(ErrReplayRedacted, gerrs.Code{
+		Code:        "GORT-4011",
+		Title:       "Request can't be replayed",
+		Description: "Some of the request's parameters were redacted when it was recorded, so it can't be replayed with identical parameters.",
+		Remediation: "Run the command again in chat instead.",
+	})
+	gerrs.RegisterCode(ErrNotServiceAccount, gerrs.Code{
+		Code:        "GORT-4012",
+		Title:       "Not a service account",
+		Description: "Tokens can only be issued directly to service accounts. Other users authenticate with their password.",
+		Remediation: "Create a service account with `gort user create --service-account <username>`.",
+	})
+	gerrs.RegisterCode(ErrSessionExpired, gerrs.Code{
+		Code:        "GORT-4013",
+		Title:       "Session expired",
+		Description: "An API token can only be refreshed until global.api_tokens.max_session_lifetime has passed since its user authenticated.",
+		Remediation: "Authenticate again with your username and password.",
+	})
+	gerrs.RegisterCode(ErrInvalidCredentials, gerrs.Code{
+		Code:        "GORT-4014",
+		Title:       "Invalid credentials",
+		Description: "The username or password is wrong, or the user doesn't exist in Gort or any configured directory, such as LDAP.",
+		Remediation: "Check the username and password in your client profile.",
+	})
+	gerrs.RegisterCode(ErrSelfRegistrationOff, gerrs.Code{
+		Code:        "GORT-4015",
+		Title:       "Self-registration is off",
+		Description: "The user was authenticated by an external directory, such as LDAP, but doesn't exist in Gort, and gort.allow_self_registration isn't set.",
+		Remediation: "Ask a Gort administrator to create your user, or to enable self-registration.",
+	})
+	gerrs.RegisterCode(ErrNotBootstrapped, gerrs.Code{
+		Code:        "GORT-4016",
+		Title:       "Gort isn't bootstrapped",
+		Description: "Users authenticated by an external directory can't be created until Gort has been bootstrapped.",
+		Remediation: "Bootstrap Gort with `gort bootstrap`.",
+	})
+	gerrs.RegisterCode(ErrOIDCNotConfigured, gerrs.Code{
+		Code:        "GORT-4017",
+		Title:       "OpenID Connect isn't configured",
+		Description: "Logging in with an identity provider requires the oidc config section. Browser logins also require oidc.redirect_url.",
+		Remediation: "Ask a Gort administrator to configure OpenID Connect, or log in with your username and password.",
+	})
+	gerrs.RegisterCode(ErrInvalidOIDCState, gerrs.Code{
+		Code:        "GORT-4018",
+		Title:       "Invalid login state",
+		Description: "The browser login took too long, was begun in a different browser, or was already completed.",
+		Remediation: "Begin a new login at /v2/auth/oidc/login.",
+	})
+	gerrs.RegisterCode(ErrNoUsernameClaim, gerrs.Code{
+		Code:        "GORT-4019",
+		Title:       "No username claim",
+		Description: "The identity provider's ID token doesn't include the claim named by oidc.username_claim.",
+		Remediation: "Configure the identity provider to include the claim, or set oidc.username_claim to one that it includes.",
+	})
+	gerrs.RegisterCode(ErrConfigOwnerRequired, gerrs.Code{
+		Code:        "GORT-4020",
+		Title:       "Configuration owner required",
+		Description: "Room and group configurations belong to a named room or group, so they can't be addressed with the \"-\" owner placeholder.",
+		Remediation: "Name the room or group that owns the configuration.",
+	})
+}
+
+// The HTTP statuses that REST errors are reported with. Errors without a
+// registered status are reported as internal server errors.
+func init() {
+	// A required value is missing or refers to nothing
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusExpectationFailed, Level: log.InfoLevel},
+		ErrMissingValue,
+	)
+
+	// Requested resource doesn't exist
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusNotFound, Level: log.InfoLevel},
+		ErrNoSuchErrorCode,
+	)
+
+	// Malformed request
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusBadRequest, Level: log.WarnLevel},
+		ErrConfigOwnerRequired,
+		ErrInvalidOIDCState,
+		ErrUnknownMappedGroup,
+	)
+
+	// Nope
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusForbidden, Level: log.WarnLevel},
+		ErrBundleNotApproved,
+		ErrInvalidCredentials,
+		ErrNoUsernameClaim,
+		ErrNotServiceAccount,
+		ErrSelfRegistrationOff,
+		ErrSelfReview,
+		oidc.ErrAccessDenied,
+	)
+
+	// Destructive operation without confirmation, or one that can't be
+	// performed faithfully
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusPreconditionFailed, Level: log.WarnLevel},
+		ErrNotBootstrapped,
+		ErrPurgeNotConfirmed,
+		ErrReplayRedacted,
+	)
+
+	// Can't change something from the state it's in
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusConflict, Level: log.InfoLevel},
+		ErrBundleNotPending,
+	)
+
+	// Who are you?
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusUnauthorized, Level: log.ErrorLevel},
+		ErrGortBundleDisabled,
+		ErrSessionExpired,
+		ErrUnauthorized,
+		oidc.ErrInvalidIDToken,
+	)
+
+	// Not possible with this configuration
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusNotImplemented, Level: log.InfoLevel},
+		ErrOIDCNotConfigured,
+		oidc.ErrNoDeviceFlow,
+	)
+
+	// A device login that's still in progress, or too late
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusPreconditionRequired, Level: log.DebugLevel},
+		oidc.ErrAuthorizationPending,
+	)
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusTooManyRequests, Level: log.InfoLevel},
+		oidc.ErrSlowDown,
+	)
+	gerrs.RegisterStatus(gerrs.Status{HTTPStatus: http.StatusGone, Level: log.InfoLevel},
+		oidc.ErrExpired,
+	)
+}
+
+// handleGetErrorCode handles "GET /v2/errors/{code}"
+func handleGetErrorCode(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToUpper(mux.Vars(r)["code"])
+
+	c, ok := gerrs.LookupCode(code)
+	if !ok {
+		respondAndLogError(r.Context(), w, ErrNoSuchErrorCode)
+		return
+	}
+
+	json.NewEncoder(w).Encode(c)
+}
+
+// handleGetErrorCodes handles "GET /v2/errors"
+func handleGetErrorCodes(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(gerrs.Codes())
+}
+
+func addErrorCodeMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/errors", otelhttp.NewHandler(http.HandlerFunc(handleGetErrorCodes), "handleGetErrorCodes")).Methods("GET")
+	router.Handle("/v2/errors/{code}", otelhttp.NewHandler(http.HandlerFunc(handleGetErrorCode), "handleGetErrorCode")).Methods("GET")
+}
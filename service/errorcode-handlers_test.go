@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+func TestGetErrorCode(t *testing.T) {
+	router := createTestRouter()
+
+	code := gerrs.Code{}
+	NewResponseTester("GET", "http://example.com/v2/errors/GORT-4001").WithOutput(&code).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "GORT-4001", code.Code)
+	assert.NotEmpty(t, code.Title)
+
+	// Codes aren't case sensitive
+	NewResponseTester("GET", "http://example.com/v2/errors/gort-4001").WithStatus(http.StatusOK).Test(t, router)
+
+	NewResponseTester("GET", "http://example.com/v2/errors/GORT-0000").WithStatus(http.StatusNotFound).Test(t, router)
+}
+
+func TestGetErrorCodes(t *testing.T) {
+	router := createTestRouter()
+
+	codes := []gerrs.Code{}
+	NewResponseTester("GET", "http://example.com/v2/errors").WithOutput(&codes).WithStatus(http.StatusOK).Test(t, router)
+	assert.NotEmpty(t, codes)
+}
+
+func TestRespondAndLogError(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Err     error
+		Status  int
+		Code    string
+		Message string
+	}{
+		{"registered", ErrUnauthorized, http.StatusUnauthorized, "GORT-4001", "unauthorized"},
+		{"nested", gerrs.Wrap(errors.New("lookup failed"), errs.ErrNoSuchUser), http.StatusNotFound, "", ""},
+		{"unwrapped", fmt.Errorf("%w: alias command may not be empty", data.ErrBadAlias), http.StatusBadRequest, "", ""},
+		{"prefixed", data.ValidateMacroLayer("bundle"), http.StatusExpectationFailed, "", ""},
+		{"replaced message", gerrs.Wrap(gerrs.ErrUnmarshal, errors.New("EOF")), http.StatusNotAcceptable, "", "Corrupt JSON payload"},
+		{"unregistered", errors.New("surprise"), http.StatusInternalServerError, "", "surprise"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			respondAndLogError(context.Background(), w, test.Err)
+
+			assert.Equal(t, test.Status, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var body rest.Error
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, test.Status, body.Status)
+			if test.Code != "" {
+				assert.Equal(t, test.Code, body.Code)
+				assert.NotEmpty(t, body.Title)
+			}
+			if test.Message != "" {
+				assert.Equal(t, test.Message, body.Message)
+			}
+		})
+	}
+}
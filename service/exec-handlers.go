@@ -0,0 +1,81 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/data/rest"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// ExecFunc executes a command on behalf of a user, and returns its output.
+// It's provided by the adapter layer.
+type ExecFunc func(ctx context.Context, user rest.User, r rest.ExecRequest) (rest.ExecResult, error)
+
+var executor ExecFunc
+
+// SetExecutor sets the function used to execute commands received by
+// "POST /v2/exec".
+func SetExecutor(f ExecFunc) {
+	executor = f
+}
+
+// handlePostExec handles "POST /v2/exec"
+// The command is executed on behalf of the user that the session token
+// belongs to, and is subject to the command's rules exactly as it would be in
+// chat, so the endpoint itself requires no permission.
+func handlePostExec(w http.ResponseWriter, r *http.Request) {
+	var exec rest.ExecRequest
+	if err := json.NewDecoder(r.Body).Decode(&exec); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	if exec.Command == "" {
+		http.Error(w, "command is required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := getRequestContext(r).User()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if executor == nil {
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := executor(r.Context(), user, exec)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func addExecMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/exec", otelhttp.NewHandler(http.HandlerFunc(handlePostExec), "handlePostExec")).Methods("POST")
+}
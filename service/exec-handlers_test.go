@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+func TestExec(t *testing.T) {
+	router := createTestRouter()
+
+	bot := rest.User{Username: "userTestExec", Email: "bot@example.com", ServiceAccount: true}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestExec").WithBody(bot).WithStatus(http.StatusOK).Test(t, router)
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+	token, err := da.TokenGenerate(context.Background(), "userTestExec", time.Minute)
+	require.NoError(t, err)
+
+	exec := rest.ExecRequest{Command: "curl:get https://example.com"}
+
+	// No adapters are available.
+	NewResponseTester("POST", "http://example.com/v2/exec").WithBody(exec).WithToken(token).WithStatus(http.StatusServiceUnavailable).Test(t, router)
+
+	var received rest.ExecRequest
+	var receivedUser rest.User
+	SetExecutor(func(ctx context.Context, u rest.User, r rest.ExecRequest) (rest.ExecResult, error) {
+		receivedUser, received = u, r
+		if r.Command == "curl:delete" {
+			return rest.ExecResult{}, gerrs.ErrNotPermitted
+		}
+		return rest.ExecResult{RequestID: 1, Bundle: "curl", Command: "get", Output: []string{"ok"}}, nil
+	})
+	defer SetExecutor(nil)
+
+	// The command runs as the user that the token belongs to.
+	result := rest.ExecResult{}
+	NewResponseTester("POST", "http://example.com/v2/exec").WithBody(exec).WithToken(token).WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, exec, received)
+	assert.Equal(t, "userTestExec", receivedUser.Username)
+	assert.True(t, receivedUser.ServiceAccount)
+	assert.Equal(t, []string{"ok"}, result.Output)
+
+	NewResponseTester("POST", "http://example.com/v2/exec").WithBody(rest.ExecRequest{Command: "curl:delete"}).WithToken(token).WithStatus(http.StatusForbidden).Test(t, router)
+	NewResponseTester("POST", "http://example.com/v2/exec").WithBody(rest.ExecRequest{}).WithToken(token).WithStatus(http.StatusBadRequest).Test(t, router)
+}
@@ -19,12 +19,12 @@ package service
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/getgort/gort/data/rest"
-	"github.com/getgort/gort/dataaccess"
 	gerrs "github.com/getgort/gort/errors"
 )
 
@@ -32,7 +32,7 @@ import (
 func handleDeleteGroup(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -54,7 +54,7 @@ func handleDeleteGroupMember(w http.ResponseWriter, r *http.Request) {
 	groupname := params["groupname"]
 	username := params["username"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -96,7 +96,7 @@ func handleDeleteGroupRole(w http.ResponseWriter, r *http.Request) {
 	groupname := params["groupname"]
 	rolename := params["rolename"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -133,7 +133,7 @@ func handleDeleteGroupRole(w http.ResponseWriter, r *http.Request) {
 func handleGetGroup(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -160,7 +160,7 @@ func handleGetGroup(w http.ResponseWriter, r *http.Request) {
 
 // handleGetGroups handles "GET /v2/groups"
 func handleGetGroups(w http.ResponseWriter, r *http.Request) {
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -181,7 +181,7 @@ func handleGetGroupMembers(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	groupname := params["groupname"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -211,7 +211,7 @@ func handleGetGroupRoles(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	groupname := params["groupname"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -251,7 +251,7 @@ func handlePutGroup(w http.ResponseWriter, r *http.Request) {
 
 	group.Name = params["groupname"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -285,7 +285,7 @@ func handlePutGroupMember(w http.ResponseWriter, r *http.Request) {
 	groupname := params["groupname"]
 	username := params["username"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -327,7 +327,7 @@ func handlePutGroupRole(w http.ResponseWriter, r *http.Request) {
 	groupname := params["groupname"]
 	rolename := params["rolename"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -360,12 +360,32 @@ func handlePutGroupRole(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handlePutGroupRestore handles "PUT /v2/groups/{groupname}/restore"
+// Only groups deleted within the retention period can be restored.
+func handlePutGroupRestore(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	since := time.Now().Add(-deletedRetention())
+	err = dataAccessLayer.GroupRestore(r.Context(), params["groupname"], since)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
 func addGroupMethodsToRouter(router *mux.Router) {
 	// Basic group methods
 	router.Handle("/v2/groups", otelhttp.NewHandler(authCommand(handleGetGroups, "group", "list"), "handleGetGroups")).Methods("GET")
 	router.Handle("/v2/groups/{groupname}", otelhttp.NewHandler(authCommand(handleGetGroup, "group", "info"), "handleGetGroup")).Methods("GET")
 	router.Handle("/v2/groups/{groupname}", otelhttp.NewHandler(authCommand(handlePutGroup, "group", "create"), "handlePutGroup")).Methods("PUT")
 	router.Handle("/v2/groups/{groupname}", otelhttp.NewHandler(authCommand(handleDeleteGroup, "group", "delete"), "handleDeleteGroup")).Methods("DELETE")
+	router.Handle("/v2/groups/{groupname}/restore", otelhttp.NewHandler(authCommand(handlePutGroupRestore, "group", "restore"), "handlePutGroupRestore")).Methods("PUT")
 
 	// Group user membership
 	router.Handle("/v2/groups/{groupname}/members", otelhttp.NewHandler(authCommand(handleGetGroupMembers, "group", ""), "handleGetGroupMembers")).Methods("GET")
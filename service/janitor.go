@@ -0,0 +1,113 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/telemetry"
+)
+
+// janitorInterval returns how often the janitor runs.
+func janitorInterval() time.Duration {
+	if i := config.GetGlobalConfigs().Janitor.Interval; i > 0 {
+		return i
+	}
+
+	return data.DefaultJanitorInterval
+}
+
+// deadLetterRetention returns how long dead letters are kept before the
+// janitor purges them.
+func deadLetterRetention() time.Duration {
+	if r := config.GetGlobalConfigs().Janitor.DeadLetterRetention; r > 0 {
+		return r
+	}
+
+	return data.DefaultDeadLetterRetention
+}
+
+// StartJanitor periodically removes expired tokens, locks, and secret
+// outputs, dead letters older than global.janitor.dead_letter_retention, and
+// delivered responses older than global.outbox.retention, until the context
+// is cancelled.
+func StartJanitor(ctx context.Context) {
+	ticker := time.NewTicker(janitorInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runJanitor(ctx)
+		}
+	}
+}
+
+func runJanitor(ctx context.Context) {
+	da, err := dataaccess.Get()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get data access; janitor not run")
+		return
+	}
+
+	now := time.Now()
+
+	janitorPurge(ctx, "tokens", func() (int, error) {
+		return da.TokenPurge(ctx, now)
+	})
+
+	janitorPurge(ctx, "locks", func() (int, error) {
+		return da.LockPurge(ctx, now)
+	})
+
+	janitorPurge(ctx, "secret_outputs", func() (int, error) {
+		return da.SecretOutputPurge(ctx, now)
+	})
+
+	janitorPurge(ctx, "dead_letters", func() (int, error) {
+		return da.DeadLetterPurge(ctx, now.Add(-deadLetterRetention()))
+	})
+
+	janitorPurge(ctx, "outbox", func() (int, error) {
+		return da.OutboxPurge(ctx, now.Add(-config.GetGlobalConfigs().Outbox.RetentionOrDefault()))
+	})
+}
+
+// janitorPurge calls purge, and logs and records the number of entries of
+// the given kind that it removed. A failure is logged but doesn't prevent
+// other kinds from being purged.
+func janitorPurge(ctx context.Context, kind string, purge func() (int, error)) {
+	n, err := purge()
+	if err != nil {
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).WithField("kind", kind).Error("Janitor failed to purge expired entries")
+		return
+	}
+
+	if n > 0 {
+		telemetry.JanitorPurged().WithAttribute("kind", kind).WithValue(int64(n)).Commit(ctx)
+		log.WithField("kind", kind).WithField("count", n).Debug("Janitor purged expired entries")
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/getgort/gort/data"
+)
+
+// ldapAuthenticator is an Authenticator that verifies passwords by binding
+// to an LDAP or Active Directory server as the user's entry, which is found
+// by a search made as the configured bind account.
+type ldapAuthenticator struct {
+	configs data.LDAPConfigs
+}
+
+func (a ldapAuthenticator) Name() string {
+	return "ldap"
+}
+
+func (a ldapAuthenticator) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	c := a.configs
+
+	if username == "" || password == "" {
+		return Identity{}, ErrInvalidCredentials
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.TimeoutOrDefault())
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		ServerName:         u.Hostname(),
+	}
+
+	conn, err := ldap.DialURL(c.URL,
+		ldap.DialWithDialer(&net.Dialer{Deadline: deadline}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	defer conn.Close()
+
+	conn.SetTimeout(time.Until(deadline))
+
+	if c.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			return Identity{}, fmt.Errorf("LDAP StartTLS failed: %w", err)
+		}
+	}
+
+	if c.BindDN != "" {
+		if err := conn.Bind(c.BindDN, c.BindPassword); err != nil {
+			return Identity{}, fmt.Errorf("failed to bind as %s: %w", c.BindDN, err)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		c.SearchBase, ldap.ScopeWholeSubtree, ldap.DerefAlways, 2, 0, false,
+		strings.ReplaceAll(c.UserFilterOrDefault(), "%s", ldap.EscapeFilter(username)),
+		[]string{"cn", "displayName", "mail", "memberOf"},
+		nil,
+	))
+	if err != nil {
+		return Identity{}, fmt.Errorf("LDAP user search failed: %w", err)
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		return Identity{}, ErrInvalidCredentials
+	case 1:
+	default:
+		return Identity{}, fmt.Errorf("%d LDAP entries match user %q", len(result.Entries), username)
+	}
+
+	entry := result.Entries[0]
+
+	err = conn.Bind(entry.DN, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, err
+	}
+
+	id := Identity{
+		Username: username,
+		Email:    entry.GetEqualFoldAttributeValue("mail"),
+		FullName: entry.GetEqualFoldAttributeValue("displayName"),
+		External: true,
+	}
+	if id.FullName == "" {
+		id.FullName = entry.GetEqualFoldAttributeValue("cn")
+	}
+
+	id.Groups, id.ManagedGroups = mapExternalGroups(c.GroupMappings, entry.GetEqualFoldAttributeValues("memberOf"))
+
+	return id, nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+// serveLDAP runs a fake LDAP server on conn that accepts the bind account's
+// credentials and alice's, and returns alice's entry to searches for her.
+func serveLDAP(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+
+	credentials := map[string]string{
+		"cn=gort,dc=example,dc=com":  "bind-secret",
+		"cn=alice,dc=example,dc=com": "secret",
+	}
+
+	str := func(s string) *ber.Packet {
+		return ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, s, "")
+	}
+
+	for {
+		msg, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		id := msg.Children[0].Value.(int64)
+
+		respond := func(op *ber.Packet) {
+			p := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+			p.AppendChild(op)
+			conn.Write(p.Bytes())
+		}
+		result := func(tag ber.Tag, code int64) *ber.Packet {
+			op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+			op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+			op.AppendChild(str(""))
+			op.AppendChild(str(""))
+			return op
+		}
+
+		op := msg.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+
+			code := int64(ldap.LDAPResultSuccess)
+			if want, ok := credentials[dn]; !ok || password != want {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			respond(result(ldap.ApplicationBindResponse, code))
+
+		case ldap.ApplicationSearchRequest:
+			assert.Equal(t, "dc=example,dc=com", op.Children[0].Value)
+
+			filter, err := ldap.DecompileFilter(op.Children[6])
+			require.NoError(t, err)
+			if !strings.Contains(filter, "alice") {
+				respond(result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+				continue
+			}
+
+			attribute := func(name string, values ...string) *ber.Packet {
+				a := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+				a.AppendChild(str(name))
+				set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+				for _, v := range values {
+					set.AppendChild(str(v))
+				}
+				a.AppendChild(set)
+				return a
+			}
+
+			attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			attributes.AppendChild(attribute("cn", "Alice"))
+			attributes.AppendChild(attribute("mail", "alice@example.com"))
+			attributes.AppendChild(attribute("memberOf", "cn=ops,dc=example,dc=com", "cn=dev,dc=example,dc=com"))
+
+			entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+			entry.AppendChild(str("cn=alice,dc=example,dc=com"))
+			entry.AppendChild(attributes)
+
+			respond(entry)
+			respond(result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func TestLDAPAuthenticator(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveLDAP(t, conn)
+		}
+	}()
+
+	a := ldapAuthenticator{configs: data.LDAPConfigs{
+		URL:           "ldap://" + ln.Addr().String(),
+		BindDN:        "cn=gort,dc=example,dc=com",
+		BindPassword:  "bind-secret",
+		SearchBase:    "dc=example,dc=com",
+		GroupMappings: map[string]string{"cn=ops,dc=example,dc=com": "ops"},
+	}}
+
+	ctx := context.Background()
+
+	id, err := a.Authenticate(ctx, "alice", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", id.Username)
+	assert.Equal(t, "alice@example.com", id.Email)
+	assert.Equal(t, "Alice", id.FullName)
+	assert.True(t, id.External)
+	assert.Equal(t, []string{"ops"}, id.Groups)
+	assert.Equal(t, []string{"ops"}, id.ManagedGroups)
+
+	_, err = a.Authenticate(ctx, "alice", "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = a.Authenticate(ctx, "bob", "secret")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// canManageMacro returns true if user may create, replace, or delete a macro
+// with the given layer and owner. Users may manage their own user-layer
+// macros and the macros of any group they belong to; managing any other
+// macro requires the manage_macros permission.
+func canManageMacro(r *http.Request, user rest.User, layer data.ConfigurationLayer, owner string) (bool, error) {
+	switch layer {
+	case data.LayerUser:
+		if owner == user.Username {
+			return true, nil
+		}
+
+	case data.LayerGroup:
+		isMember, err := userInGroup(r, user.Username, owner)
+		if err != nil || isMember {
+			return isMember, err
+		}
+	}
+
+	return doAuthenticateUser(r, "", "macro", "manage")
+}
+
+// getMacroParameters extracts a macro's identifying fields from a request's
+// path parameters. A user-layer owner of "-" refers to the requesting user.
+func getMacroParameters(r *http.Request) (data.Macro, rest.User, error) {
+	params := mux.Vars(r)
+
+	m := data.Macro{
+		Layer: data.ConfigurationLayer(strings.ToLower(params["layer"])),
+		Owner: params["owner"],
+		Name:  params["name"],
+	}
+
+	if err := data.ValidateMacroLayer(m.Layer); err != nil {
+		return data.Macro{}, rest.User{}, err
+	}
+
+	user, err := getRequestContext(r).User()
+	if err != nil {
+		return data.Macro{}, rest.User{}, err
+	}
+
+	if m.Layer == data.LayerUser && m.Owner == "-" {
+		m.Owner = user.Username
+	}
+
+	return m, user, nil
+}
+
+// userInGroup returns true if username is a member of groupname.
+func userInGroup(r *http.Request, username, groupname string) (bool, error) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		return false, err
+	}
+
+	groups, err := dataAccessLayer.UserGroupList(r.Context(), username)
+	if err != nil {
+		return false, err
+	}
+
+	for _, g := range groups {
+		if g.Name == groupname {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// handleDeleteMacro handles "DELETE /v2/macros/{layer}/{owner}/{name}"
+func handleDeleteMacro(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	m, user, err := getMacroParameters(r)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if ok, err := canManageMacro(r, user, m.Layer, m.Owner); err != nil || !ok {
+		if err == nil {
+			err = ErrUnauthorized
+		}
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	err = dataAccessLayer.MacroDelete(r.Context(), m.Layer, m.Owner, m.Name)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleGetMacros handles "GET /v2/macros"
+// Only the macros the requesting user can invoke are returned, unless the
+// user has the manage_macros permission, in which case all are.
+func handleGetMacros(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	macros, err := dataAccessLayer.MacroList(r.Context(), "")
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if manager, err := doAuthenticateUser(r, "", "macro", "manage"); err != nil || !manager {
+		user, err := getRequestContext(r).User()
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+
+		groups, err := dataAccessLayer.UserGroupList(r.Context(), user.Username)
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+
+		member := map[string]bool{}
+		for _, g := range groups {
+			member[g.Name] = true
+		}
+
+		visible := []data.Macro{}
+		for _, m := range macros {
+			if (m.Layer == data.LayerUser && m.Owner == user.Username) ||
+				(m.Layer == data.LayerGroup && member[m.Owner]) {
+				visible = append(visible, m)
+			}
+		}
+		macros = visible
+	}
+
+	json.NewEncoder(w).Encode(macros)
+}
+
+// handlePutMacro handles "PUT /v2/macros/{layer}/{owner}/{name}"
+func handlePutMacro(w http.ResponseWriter, r *http.Request) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	var body data.Macro
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	m, user, err := getMacroParameters(r)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+	m.Command = body.Command
+
+	if ok, err := canManageMacro(r, user, m.Layer, m.Owner); err != nil || !ok {
+		if err == nil {
+			err = ErrUnauthorized
+		}
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	err = dataAccessLayer.MacroSave(r.Context(), m)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+func addMacroMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/macros", otelhttp.NewHandler(authCommand(handleGetMacros, "macro", "list"), "handleGetMacros")).Methods("GET")
+	router.Handle("/v2/macros/{layer}/{owner}/{name}", otelhttp.NewHandler(authCommand(handlePutMacro, "macro", "save"), "handlePutMacro")).Methods("PUT")
+	router.Handle("/v2/macros/{layer}/{owner}/{name}", otelhttp.NewHandler(authCommand(handleDeleteMacro, "macro", "delete"), "handleDeleteMacro")).Methods("DELETE")
+}
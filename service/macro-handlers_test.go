@@ -0,0 +1,82 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestMacros(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	// A user with no special permissions who belongs to the "devs" group.
+	require.NoError(t, da.UserCreate(ctx, rest.User{Username: "dev"}))
+	require.NoError(t, da.GroupCreate(ctx, rest.Group{Name: "devs"}))
+	require.NoError(t, da.GroupUserAdd(ctx, "devs", "dev"))
+	require.NoError(t, da.GroupCreate(ctx, rest.Group{Name: "ops"}))
+
+	token, err := da.TokenGenerate(ctx, "dev", time.Minute)
+	require.NoError(t, err)
+
+	body := data.Macro{Command: "kubectl:rollout restart deploy/{{.svc}}"}
+
+	// Users can manage their own macros and their groups'.
+	NewResponseTester("PUT", "http://example.com/v2/macros/user/dev/restart-api").WithToken(token).WithBody(body).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/macros/user/-/deploy").WithToken(token).WithBody(body).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/macros/group/devs/restart-api").WithToken(token).WithBody(body).WithStatus(http.StatusOK).Test(t, router)
+
+	// ...but not anybody else's.
+	NewResponseTester("PUT", "http://example.com/v2/macros/user/admin/restart-api").WithToken(token).WithBody(body).WithStatus(http.StatusUnauthorized).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/macros/group/ops/restart-api").WithToken(token).WithBody(body).WithStatus(http.StatusUnauthorized).Test(t, router)
+
+	// Admins can manage anybody's.
+	NewResponseTester("PUT", "http://example.com/v2/macros/group/ops/restart-api").WithBody(body).WithStatus(http.StatusOK).Test(t, router)
+
+	// Rooms can't have macros, and macros must be valid templates.
+	NewResponseTester("PUT", "http://example.com/v2/macros/room/C123/restart-api").WithBody(body).WithStatus(http.StatusExpectationFailed).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/macros/user/dev/broken").WithToken(token).WithBody(data.Macro{Command: "echo {{.msg"}).WithStatus(http.StatusBadRequest).Test(t, router)
+
+	macros := []data.Macro{}
+	NewResponseTester("GET", "http://example.com/v2/macros").WithToken(token).WithOutput(&macros).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, []data.Macro{
+		{Name: "deploy", Layer: data.LayerUser, Owner: "dev", Command: body.Command},
+		{Name: "restart-api", Layer: data.LayerGroup, Owner: "devs", Command: body.Command},
+		{Name: "restart-api", Layer: data.LayerUser, Owner: "dev", Command: body.Command},
+	}, macros)
+
+	macros = []data.Macro{}
+	NewResponseTester("GET", "http://example.com/v2/macros").WithOutput(&macros).WithStatus(http.StatusOK).Test(t, router)
+	assert.Len(t, macros, 4)
+
+	NewResponseTester("DELETE", "http://example.com/v2/macros/group/ops/restart-api").WithToken(token).WithStatus(http.StatusUnauthorized).Test(t, router)
+	NewResponseTester("DELETE", "http://example.com/v2/macros/user/dev/restart-api").WithToken(token).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("DELETE", "http://example.com/v2/macros/user/dev/restart-api").WithToken(token).WithStatus(http.StatusNotFound).Test(t, router)
+}
@@ -0,0 +1,325 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/auth"
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/oidc"
+)
+
+var (
+	// ErrOIDCNotConfigured is returned by the OpenID Connect endpoints if
+	// there's no "oidc" config section.
+	ErrOIDCNotConfigured = errors.New("OpenID Connect isn't configured")
+
+	// ErrInvalidOIDCState is returned by the OpenID Connect callback if the
+	// login's state is missing, expired, or wasn't issued to the browser.
+	ErrInvalidOIDCState = errors.New("invalid or expired login state")
+
+	// ErrNoUsernameClaim is returned if an ID token doesn't have the claim
+	// named by oidc.username_claim.
+	ErrNoUsernameClaim = errors.New("ID token has no username claim")
+)
+
+const (
+	// oidcStateCookie is the cookie that binds a browser login's state to
+	// the browser that began it.
+	oidcStateCookie = "gort_oidc_state"
+
+	// oidcLoginLifetime is how long a browser login can take.
+	oidcLoginLifetime = 10 * time.Minute
+)
+
+var (
+	oidcProviderMutex   sync.Mutex
+	oidcProvider        *oidc.Provider
+	oidcProviderConfigs data.OIDCConfigs
+)
+
+// getOIDCProvider returns the identity provider described by the "oidc"
+// config section. Its configuration is discovered the first time it's
+// needed, and again whenever the section changes.
+func getOIDCProvider(ctx context.Context) (*oidc.Provider, data.OIDCConfigs, error) {
+	c := config.GetOIDCConfigs()
+	if config.Undefined(c) {
+		return nil, c, ErrOIDCNotConfigured
+	}
+
+	oidcProviderMutex.Lock()
+	defer oidcProviderMutex.Unlock()
+
+	if oidcProvider != nil && reflect.DeepEqual(c, oidcProviderConfigs) {
+		return oidcProvider, c, nil
+	}
+
+	oc := oidc.Config{
+		Issuer:       c.Issuer,
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Scopes:       c.ScopesOrDefault(),
+	}
+
+	p, err := oidc.NewProvider(ctx, oc, &http.Client{Timeout: c.TimeoutOrDefault()})
+	if err != nil {
+		return nil, c, fmt.Errorf("OpenID Connect provider discovery failed: %w", err)
+	}
+
+	oidcProvider, oidcProviderConfigs = p, c
+
+	return p, c, nil
+}
+
+// oidcIdentity returns the Identity described by the claims of a verified
+// ID token.
+func oidcIdentity(c data.OIDCConfigs, claims oidc.Claims) (Identity, error) {
+	username := claims.String(c.UsernameClaimOrDefault())
+	if username == "" {
+		return Identity{}, ErrNoUsernameClaim
+	}
+
+	// As with LDAP, the bootstrap admin can only log in with its password.
+	if username == "admin" {
+		return Identity{}, ErrInvalidCredentials
+	}
+
+	id := Identity{
+		Username: username,
+		Email:    claims.String("email"),
+		FullName: claims.String("name"),
+		External: true,
+	}
+
+	id.Groups, id.ManagedGroups = mapExternalGroups(c.GroupMappings, claims.Strings(c.GroupsClaimOrDefault()))
+
+	return id, nil
+}
+
+// completeOIDCLogin provisions the Gort user described by a verified ID
+// token, and issues it an API token.
+func completeOIDCLogin(ctx context.Context, da dataaccess.DataAccess, c data.OIDCConfigs, claims oidc.Claims) (rest.Token, error) {
+	id, err := oidcIdentity(c, claims)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	if err := provisionIdentity(ctx, da, id); err != nil {
+		return rest.Token{}, err
+	}
+
+	return issueAPIToken(ctx, da, id.Username, time.Now())
+}
+
+// handleOIDCLogin handles "GET /v2/auth/oidc/login"
+// The browser is redirected to the identity provider, which redirects it
+// back to the callback endpoint.
+func handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	p, c, err := getOIDCProvider(r.Context())
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if c.RedirectURL == "" {
+		respondAndLogError(r.Context(), w, gerrs.Wrap(ErrOIDCNotConfigured, errors.New("oidc.redirect_url isn't set")))
+		return
+	}
+
+	state, err := auth.NewOIDCState(time.Now(), oidcLoginLifetime)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	signed, err := auth.SignOIDCState(state)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    signed,
+		Path:     "/v2/auth/oidc",
+		MaxAge:   int(oidcLoginLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, p.AuthCodeURL(signed, state.Nonce), http.StatusFound)
+}
+
+// handleOIDCCallback handles "GET /v2/auth/oidc/callback"
+// The identity provider's authorization code is exchanged for an ID token,
+// and the user it describes is issued an API token.
+func handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	switch e := q.Get("error"); e {
+	case "":
+	case "access_denied":
+		respondAndLogError(r.Context(), w, oidc.ErrAccessDenied)
+		return
+	default:
+		respondAndLogError(r.Context(), w, fmt.Errorf("identity provider returned %s: %s", e, q.Get("error_description")))
+		return
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || cookie.Value == "" || cookie.Value != q.Get("state") {
+		respondAndLogError(r.Context(), w, ErrInvalidOIDCState)
+		return
+	}
+
+	state, err := auth.VerifyOIDCState(cookie.Value)
+	if err != nil {
+		respondAndLogError(r.Context(), w, gerrs.Wrap(ErrInvalidOIDCState, err))
+		return
+	}
+
+	// A state can only be used once.
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/v2/auth/oidc", MaxAge: -1})
+
+	p, c, err := getOIDCProvider(r.Context())
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	tr, err := p.Exchange(r.Context(), q.Get("code"))
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	claims, err := p.Verify(r.Context(), tr.IDToken, state.Nonce)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	token, err := completeOIDCLogin(r.Context(), dataAccessLayer, c, claims)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(token)
+}
+
+// handleOIDCDevice handles "POST /v2/auth/oidc/device"
+// It begins a device login, as used by the gort CLI.
+func handleOIDCDevice(w http.ResponseWriter, r *http.Request) {
+	p, _, err := getOIDCProvider(r.Context())
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	da, err := p.DeviceAuthorization(r.Context())
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(rest.DeviceAuthorization{
+		DeviceCode:              da.DeviceCode,
+		UserCode:                da.UserCode,
+		VerificationURI:         da.VerificationURI,
+		VerificationURIComplete: da.VerificationURIComplete,
+		ExpiresIn:               da.ExpiresIn,
+		Interval:                da.Interval,
+	})
+}
+
+// handleOIDCDeviceToken handles "POST /v2/auth/oidc/device/token"
+// Until the user completes the device login, a 428 (authorization pending)
+// or 429 (slow down) is returned.
+func handleOIDCDeviceToken(w http.ResponseWriter, r *http.Request) {
+	var da rest.DeviceAuthorization
+	if err := json.NewDecoder(r.Body).Decode(&da); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+	if da.DeviceCode == "" {
+		respondAndLogError(r.Context(), w, ErrMissingValue)
+		return
+	}
+
+	p, c, err := getOIDCProvider(r.Context())
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	tr, err := p.DeviceToken(r.Context(), da.DeviceCode)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	claims, err := p.Verify(r.Context(), tr.IDToken, "")
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	token, err := completeOIDCLogin(r.Context(), dataAccessLayer, c, claims)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(token)
+}
+
+func addOIDCMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/auth/oidc/login", otelhttp.NewHandler(http.HandlerFunc(handleOIDCLogin), "handleOIDCLogin")).Methods("GET")
+	router.Handle("/v2/auth/oidc/callback", otelhttp.NewHandler(http.HandlerFunc(handleOIDCCallback), "handleOIDCCallback")).Methods("GET")
+	router.Handle("/v2/auth/oidc/device", otelhttp.NewHandler(http.HandlerFunc(handleOIDCDevice), "handleOIDCDevice")).Methods("POST")
+	router.Handle("/v2/auth/oidc/device/token", otelhttp.NewHandler(http.HandlerFunc(handleOIDCDeviceToken), "handleOIDCDeviceToken")).Methods("POST")
+}
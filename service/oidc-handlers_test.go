@@ -0,0 +1,62 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/oidc"
+)
+
+func TestOIDCNotConfigured(t *testing.T) {
+	router := createTestRouter()
+
+	NewResponseTester("GET", "http://example.com/v2/auth/oidc/login").WithStatus(http.StatusNotImplemented).Test(t, router)
+	NewResponseTester("POST", "http://example.com/v2/auth/oidc/device").WithStatus(http.StatusNotImplemented).Test(t, router)
+}
+
+func TestOIDCIdentity(t *testing.T) {
+	c := data.OIDCConfigs{
+		GroupsClaim:   "roles",
+		GroupMappings: map[string]string{"sre": "ops"},
+	}
+
+	id, err := oidcIdentity(c, oidc.Claims{
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"name":               "Alice",
+		"roles":              []interface{}{"sre", "other"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", id.Username)
+	assert.Equal(t, "alice@example.com", id.Email)
+	assert.Equal(t, "Alice", id.FullName)
+	assert.True(t, id.External)
+	assert.Equal(t, []string{"ops"}, id.Groups)
+	assert.Equal(t, []string{"ops"}, id.ManagedGroups)
+
+	_, err = oidcIdentity(c, oidc.Claims{"email": "alice@example.com"})
+	assert.ErrorIs(t, err, ErrNoUsernameClaim)
+
+	_, err = oidcIdentity(c, oidc.Claims{"preferred_username": "admin"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
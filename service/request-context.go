@@ -0,0 +1,200 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/getgort/gort/auth"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+	"github.com/getgort/gort/telemetry"
+)
+
+// requestContextKey is the context key of a request's *requestContext.
+type requestContextKey struct{}
+
+// requestContext holds the state of a single REST request: its data access
+// layer, the token it was made with, the token's user and their
+// permissions, and the span that traces it. It's created once, by the
+// logging middleware, and each value is resolved at most once, by whichever
+// middleware or handler first needs it.
+type requestContext struct {
+	ctx     context.Context
+	session string
+	span    trace.Span
+
+	daOnce sync.Once
+	da     dataaccess.DataAccess
+	daErr  error
+
+	tokenOnce sync.Once
+	token     rest.Token
+	tokenErr  error
+
+	userOnce sync.Once
+	user     rest.User
+	userErr  error
+
+	permsOnce sync.Once
+	perms     rest.RolePermissionList
+	permsErr  error
+}
+
+// withRequestContext returns r with a new requestContext, and a span that
+// traces the request, in its context. The caller is responsible for ending
+// the span. If r already has a requestContext it's returned unchanged.
+func withRequestContext(r *http.Request) (*http.Request, *requestContext) {
+	if rc, ok := r.Context().Value(requestContextKey{}).(*requestContext); ok {
+		return r, rc
+	}
+
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	ctx, sp := tr.Start(r.Context(), "service.request")
+	sp.SetAttributes(
+		attribute.String("http.method", r.Method),
+		attribute.String("http.target", r.URL.Path),
+	)
+
+	rc := &requestContext{session: r.Header.Get("X-Session-Token"), span: sp}
+	ctx = context.WithValue(ctx, requestContextKey{}, rc)
+	rc.ctx = ctx
+
+	return r.WithContext(ctx), rc
+}
+
+// getRequestContext returns the requestContext of r. If r didn't pass
+// through withRequestContext, as when a handler is called directly, a new
+// requestContext is returned whose values are only shared by its callers.
+func getRequestContext(r *http.Request) *requestContext {
+	if rc, ok := r.Context().Value(requestContextKey{}).(*requestContext); ok {
+		return rc
+	}
+
+	return &requestContext{
+		ctx:     r.Context(),
+		session: r.Header.Get("X-Session-Token"),
+		span:    trace.SpanFromContext(r.Context()),
+	}
+}
+
+// DataAccess returns the data access layer that serves the request.
+func (rc *requestContext) DataAccess() (dataaccess.DataAccess, error) {
+	rc.daOnce.Do(func() {
+		rc.da, rc.daErr = dataaccess.Get()
+	})
+
+	return rc.da, rc.daErr
+}
+
+// Token returns the token that the request was made with. If the request has
+// no token, or its token is unknown or expired, an ErrUnauthorized is
+// returned; an expired token is still returned alongside the error so that
+// its user can be logged.
+func (rc *requestContext) Token() (rest.Token, error) {
+	rc.tokenOnce.Do(func() {
+		rc.token, rc.tokenErr = rc.lookupToken()
+	})
+
+	return rc.token, rc.tokenErr
+}
+
+// User returns the user that the request's token belongs to.
+func (rc *requestContext) User() (rest.User, error) {
+	rc.userOnce.Do(func() {
+		token, err := rc.Token()
+		if err != nil {
+			rc.userErr = err
+			return
+		}
+
+		da, err := rc.DataAccess()
+		if err != nil {
+			rc.userErr = err
+			return
+		}
+
+		rc.user, rc.userErr = da.UserGet(rc.ctx, token.User)
+	})
+
+	return rc.user, rc.userErr
+}
+
+// Permissions returns the permissions of the user that the request's token
+// belongs to.
+func (rc *requestContext) Permissions() (rest.RolePermissionList, error) {
+	rc.permsOnce.Do(func() {
+		token, err := rc.Token()
+		if err != nil {
+			rc.permsErr = err
+			return
+		}
+
+		da, err := rc.DataAccess()
+		if err != nil {
+			rc.permsErr = err
+			return
+		}
+
+		rc.perms, rc.permsErr = da.UserPermissionList(rc.ctx, token.User)
+	})
+
+	return rc.perms, rc.permsErr
+}
+
+// Span returns the span that traces the request.
+func (rc *requestContext) Span() trace.Span {
+	return rc.span
+}
+
+// lookupToken does the actual work for Token.
+func (rc *requestContext) lookupToken() (rest.Token, error) {
+	if rc.session == "" {
+		return rest.Token{}, ErrUnauthorized
+	}
+
+	da, err := rc.DataAccess()
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	if auth.IsJWT(rc.session) {
+		return lookupJWT(rc.ctx, da, rc.session)
+	}
+
+	token, err := da.TokenRetrieveByToken(rc.ctx, rc.session)
+	if gerrs.Is(err, errs.ErrNoSuchToken) {
+		return rest.Token{}, ErrUnauthorized
+	}
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	if token.IsExpired() {
+		return token, ErrUnauthorized
+	}
+
+	return token, nil
+}
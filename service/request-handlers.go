@@ -0,0 +1,162 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+var (
+	// ErrReplayRedacted is returned by the request replay endpoint if some
+	// of the original request's parameters were redacted when it was
+	// recorded, so it can't be replayed faithfully.
+	ErrReplayRedacted = errors.New("request parameters were redacted")
+)
+
+// ReplayFunc re-executes a historical request. It's provided by the adapter
+// layer.
+type ReplayFunc func(ctx context.Context, original data.RequestRecord, r rest.ReplayRequest) (rest.ReplayResult, error)
+
+var replayer ReplayFunc
+
+// SetReplayer sets the function used to replay requests by
+// "POST /v2/requests/{id}/replay".
+func SetReplayer(f ReplayFunc) {
+	replayer = f
+}
+
+// defaultRequestListLimit is the number of requests returned by
+// "GET /v2/requests" if no limit is given.
+const defaultRequestListLimit = 20
+
+// handleGetRequests handles "GET /v2/requests"
+func handleGetRequests(w http.ResponseWriter, r *http.Request) {
+	limit := defaultRequestListLimit
+	if v := r.FormValue("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	requests, err := dataAccessLayer.RequestList(r.Context(), limit)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(requests)
+}
+
+// handleGetRequest handles "GET /v2/requests/{id}"
+func handleGetRequest(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	id, err := strconv.ParseInt(params["id"], 10, 64)
+	if err != nil {
+		respondAndLogError(r.Context(), w, errs.ErrNoSuchRequest)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	request, err := dataAccessLayer.RequestGet(r.Context(), id)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(request)
+}
+
+// handlePostRequestReplay handles "POST /v2/requests/{id}/replay"
+func handlePostRequestReplay(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	id, err := strconv.ParseInt(params["id"], 10, 64)
+	if err != nil {
+		respondAndLogError(r.Context(), w, errs.ErrNoSuchRequest)
+		return
+	}
+
+	var replay rest.ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&replay); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	original, err := dataAccessLayer.RequestGet(r.Context(), id)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if strings.Contains(original.Parameters, data.RedactedValue) {
+		respondAndLogError(r.Context(), w, ErrReplayRedacted)
+		return
+	}
+
+	if replayer == nil {
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := replayer(r.Context(), original, replay)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func addRequestMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/requests", otelhttp.NewHandler(authCommand(handleGetRequests, "ps"), "handleGetRequests")).Methods("GET")
+	router.Handle("/v2/requests/{id}", otelhttp.NewHandler(authCommand(handleGetRequest, "ps"), "handleGetRequest")).Methods("GET")
+	router.Handle("/v2/requests/{id}/replay", otelhttp.NewHandler(authCommand(handlePostRequestReplay, "replay"), "handlePostRequestReplay")).Methods("POST")
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestGetRequests(t *testing.T) {
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		req := data.CommandRequest{
+			CommandEntry: data.CommandEntry{
+				Bundle:  data.Bundle{Name: "test", Version: "0.0.1"},
+				Command: data.BundleCommand{Name: "echo"},
+			},
+			Timestamp: time.Now(),
+			Timings:   data.StageTimings{data.StageLookup: time.Millisecond},
+		}
+		require.NoError(t, da.RequestBegin(context.Background(), &req))
+		ids = append(ids, req.RequestID)
+	}
+
+	list := []data.RequestRecord{}
+	NewResponseTester("GET", "http://example.com/v2/requests?limit=2").WithOutput(&list).WithStatus(http.StatusOK).Test(t, router)
+	require.Len(t, list, 2)
+	assert.Equal(t, ids[2], list[0].RequestID)
+
+	record := data.RequestRecord{}
+	url := fmt.Sprintf("http://example.com/v2/requests/%d", ids[0])
+	NewResponseTester("GET", url).WithOutput(&record).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "echo", record.CommandName)
+	assert.Equal(t, time.Millisecond, record.Timings[data.StageLookup])
+
+	NewResponseTester("GET", "http://example.com/v2/requests/0").WithStatus(http.StatusNotFound).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/requests/bogus").WithStatus(http.StatusNotFound).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/requests?limit=-1").WithStatus(http.StatusBadRequest).Test(t, router)
+}
+
+func TestPostRequestReplay(t *testing.T) {
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	req := data.CommandRequest{
+		CommandEntry: data.CommandEntry{
+			Bundle:  data.Bundle{Name: "test", Version: "0.0.1"},
+			Command: data.BundleCommand{Name: "echo"},
+		},
+		Parameters: data.CommandParameters{"foo", "bar"},
+		Timestamp:  time.Now(),
+	}
+	require.NoError(t, da.RequestBegin(context.Background(), &req))
+	url := fmt.Sprintf("http://example.com/v2/requests/%d/replay", req.RequestID)
+
+	// No adapters to replay through.
+	NewResponseTester("POST", url).WithBody(rest.ReplayRequest{}).WithStatus(http.StatusServiceUnavailable).Test(t, router)
+
+	var replayed data.RequestRecord
+	var version string
+	SetReplayer(func(ctx context.Context, original data.RequestRecord, r rest.ReplayRequest) (rest.ReplayResult, error) {
+		replayed, version = original, r.BundleVersion
+		return rest.ReplayResult{RequestID: original.RequestID + 1, ReplayOf: original.RequestID, Output: []string{"foo bar"}}, nil
+	})
+	defer SetReplayer(nil)
+
+	result := rest.ReplayResult{}
+	NewResponseTester("POST", url).WithBody(rest.ReplayRequest{BundleVersion: "0.0.2"}).WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, req.RequestID, replayed.RequestID)
+	assert.Equal(t, "foo bar", replayed.Parameters)
+	assert.Equal(t, "0.0.2", version)
+	assert.Equal(t, req.RequestID, result.ReplayOf)
+
+	// Requests with redacted parameters can't be replayed.
+	redacted := req
+	redacted.RequestID = 0
+	redacted.Parameters = data.CommandParameters{"--token", data.RedactedValue}
+	require.NoError(t, da.RequestBegin(context.Background(), &redacted))
+	url = fmt.Sprintf("http://example.com/v2/requests/%d/replay", redacted.RequestID)
+	NewResponseTester("POST", url).WithBody(rest.ReplayRequest{}).WithStatus(http.StatusPreconditionFailed).Test(t, router)
+
+	NewResponseTester("POST", "http://example.com/v2/requests/0/replay").WithBody(rest.ReplayRequest{}).WithStatus(http.StatusNotFound).Test(t, router)
+}
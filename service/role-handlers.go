@@ -21,17 +21,34 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/getgort/gort/auth"
 	"github.com/getgort/gort/data/rest"
-	"github.com/getgort/gort/dataaccess"
 	"github.com/gorilla/mux"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// handlePutRoleClone handles "PUT /v2/roles/{rolename}/clones/{clonename}"
+func handlePutRoleClone(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	err = dataAccessLayer.RoleClone(r.Context(), params["rolename"], params["clonename"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
 // handleDeleteGroup handles "DELETE /v2/roles/{rolename}"
 func handleDeleteRole(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -46,7 +63,7 @@ func handleDeleteRole(w http.ResponseWriter, r *http.Request) {
 
 // handleGetRoles handles "GET /v2/roles"
 func handleGetRoles(w http.ResponseWriter, r *http.Request) {
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -67,7 +84,7 @@ func handleGetRole(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	rolename := params["rolename"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -141,7 +158,7 @@ func handleGetRolePermissions(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	rolename := params["rolename"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -166,6 +183,35 @@ func handleGetRolePermissions(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(rpl)
 }
 
+// handleGetRoleDiff handles "GET /v2/roles/{rolename}/diff/{othername}"
+func handleGetRoleDiff(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	a, b := params["rolename"], params["othername"]
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	permsA, err := dataAccessLayer.RolePermissionList(r.Context(), a)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	permsB, err := dataAccessLayer.RolePermissionList(r.Context(), b)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	diff := rest.RoleDiff{A: a, B: b}
+	diff.OnlyA, diff.OnlyB, diff.Common = rest.DiffRolePermissions(permsA, permsB)
+
+	json.NewEncoder(w).Encode(diff)
+}
+
 // handleGrantRolePermission handles "PUT /v2/roles/{rolename}/bundles/{bundlename}/permissions/{permissionname}"
 func handleGrantRolePermission(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
@@ -173,7 +219,12 @@ func handleGrantRolePermission(w http.ResponseWriter, r *http.Request) {
 	bundlename := params["bundlename"]
 	permissionname := params["permissionname"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	if err := auth.ValidatePermissionScope(permissionname); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -203,7 +254,7 @@ func handleRevokeRolePermission(w http.ResponseWriter, r *http.Request) {
 	bundlename := params["bundlename"]
 	permissionname := params["permissionname"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -232,7 +283,7 @@ func handlePutRole(w http.ResponseWriter, r *http.Request) {
 
 	params := mux.Vars(r)
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -253,6 +304,8 @@ func addRoleMethodsToRouter(router *mux.Router) {
 	router.Handle("/v2/roles/{rolename}", otelhttp.NewHandler(authCommand(handleDeleteRole, "role", "delete"), "handleDeleteRole")).Methods("DELETE")
 
 	// Role permissions
+	router.Handle("/v2/roles/{rolename}/clones/{clonename}", otelhttp.NewHandler(authCommand(handlePutRoleClone, "role", "clone"), "handlePutRoleClone")).Methods("PUT")
+	router.Handle("/v2/roles/{rolename}/diff/{othername}", otelhttp.NewHandler(authCommand(handleGetRoleDiff, "role", "diff"), "handleGetRoleDiff")).Methods("GET")
 	router.Handle("/v2/roles/{rolename}/permissions", otelhttp.NewHandler(authCommand(handleGetRolePermissions, "role", "info"), "handleGetRolePermissions")).Methods("GET")
 	router.Handle("/v2/roles/{rolename}/bundles/{bundlename}/permissions/{permissionname}", otelhttp.NewHandler(authCommand(handleRevokeRolePermission, "role", "revoke-permission"), "handleDeleteRolePermission")).Methods("DELETE")
 	router.Handle("/v2/roles/{rolename}/bundles/{bundlename}/permissions/{permissionname}", otelhttp.NewHandler(authCommand(handleGrantRolePermission, "role", "grant-permission"), "handlePutRolePermission")).Methods("PUT")
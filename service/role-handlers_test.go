@@ -19,6 +19,10 @@ package service
 import (
 	"net/http"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/getgort/gort/data/rest"
 )
 
 func TestCreateRole(t *testing.T) {
@@ -106,3 +110,40 @@ func TestRevokeRolePermissionInvalidPermission(t *testing.T) {
 	// NOTE: Should we check for this? There may be implications with versioning.
 	NewResponseTester("DELETE", "http://example.com/v2/roles/testRevokeRolePermissionInvalidPermission/bundles/testbundle/permissions/testpermission2").WithStatus(http.StatusOK).Test(t, router)
 }
+
+func TestCloneRole(t *testing.T) {
+	router := createTestRouter()
+
+	NewResponseTester("PUT", "http://example.com/v2/roles/testCloneRole").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/roles/testCloneRole/bundles/testbundle/permissions/testpermission").WithStatus(http.StatusOK).Test(t, router)
+
+	// Clone the role
+	NewResponseTester("PUT", "http://example.com/v2/roles/testCloneRole/clones/testCloneRole2").WithStatus(http.StatusOK).Test(t, router)
+
+	perms := rest.RolePermissionList{}
+	NewResponseTester("GET", "http://example.com/v2/roles/testCloneRole2/permissions").WithOutput(&perms).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, rest.RolePermissionList{{BundleName: "testbundle", Permission: "testpermission"}}, perms)
+
+	// Cloning again conflicts with the existing clone
+	NewResponseTester("PUT", "http://example.com/v2/roles/testCloneRole/clones/testCloneRole2").WithStatus(http.StatusConflict).Test(t, router)
+
+	// Cloning a role that doesn't exist
+	NewResponseTester("PUT", "http://example.com/v2/roles/testCloneRoleMissing/clones/testCloneRole3").WithStatus(http.StatusNotFound).Test(t, router)
+}
+
+func TestDiffRoles(t *testing.T) {
+	router := createTestRouter()
+
+	NewResponseTester("PUT", "http://example.com/v2/roles/testDiffRolesA").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/roles/testDiffRolesB").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/roles/testDiffRolesA/bundles/testbundle/permissions/both").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/roles/testDiffRolesB/bundles/testbundle/permissions/both").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/roles/testDiffRolesA/bundles/testbundle/permissions/a").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/roles/testDiffRolesB/bundles/testbundle/permissions/b").WithStatus(http.StatusOK).Test(t, router)
+
+	diff := rest.RoleDiff{}
+	NewResponseTester("GET", "http://example.com/v2/roles/testDiffRolesA/diff/testDiffRolesB").WithOutput(&diff).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, rest.RolePermissionList{{BundleName: "testbundle", Permission: "a"}}, diff.OnlyA)
+	assert.Equal(t, rest.RolePermissionList{{BundleName: "testbundle", Permission: "b"}}, diff.OnlyB)
+	assert.Equal(t, rest.RolePermissionList{{BundleName: "testbundle", Permission: "both"}}, diff.Common)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/data"
+)
+
+// secretsPathPrefix is the path under which secret outputs are served. These
+// endpoints are protected by the secret's own one-time token rather than by a
+// session token.
+const secretsPathPrefix = "/v2/secrets/"
+
+// handleGetSecret handles "GET /v2/secrets/{token}"
+// The secret output is deleted as it's returned, so it can only be viewed
+// once.
+func handleGetSecret(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	secret, err := dataAccessLayer.SecretOutputRedeem(r.Context(), data.HashSecretToken(token))
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if secret.Title != "" {
+		fmt.Fprintf(w, "%s\n\n", secret.Title)
+	}
+	fmt.Fprintln(w, strings.Join(secret.Output, "\n"))
+}
+
+func addSecretMethodsToRouter(router *mux.Router) {
+	router.Handle(secretsPathPrefix+"{token}", otelhttp.NewHandler(http.HandlerFunc(handleGetSecret), "handleGetSecret")).Methods("GET")
+}
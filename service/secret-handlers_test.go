@@ -0,0 +1,65 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/dataaccess"
+)
+
+func TestGetSecret(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	secret := data.SecretOutput{
+		TokenHash: data.HashSecretToken("abc123"),
+		Title:     "Password",
+		Output:    []string{"hunter2"},
+		Created:   time.Now().UTC(),
+		Expires:   time.Now().UTC().Add(time.Minute),
+	}
+	require.NoError(t, da.SecretOutputCreate(ctx, secret))
+
+	// No session token is needed: the secret's token is enough.
+	req := httptest.NewRequest("GET", "http://example.com/v2/secrets/abc123", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "Password\n\nhunter2\n", string(body))
+
+	// The secret can only be viewed once.
+	NewResponseTester("GET", "http://example.com/v2/secrets/abc123").WithStatus(http.StatusNotFound).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/secrets/bogus").WithStatus(http.StatusNotFound).Test(t, router)
+}
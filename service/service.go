@@ -48,6 +48,8 @@ var (
 	ErrNoSuchCommand = errors.New("no such command")
 
 	ErrGortBundleDisabled = errors.New("gort bundle disabled")
+
+	ErrSessionExpired = errors.New("session has expired")
 )
 
 // RequestEvent represents a request of a service endpoint.
@@ -118,7 +120,7 @@ func BuildRESTServer(ctx context.Context, addr string) *RESTServer {
 	requests := make(chan RequestEvent)
 
 	router := mux.NewRouter()
-	router.Use(buildLoggingMiddleware(requests), tokenObservingMiddleware)
+	router.Use(buildLoggingMiddleware(requests), tokenObservingMiddleware, auditMiddleware)
 
 	err = addMetricsToRouter(router)
 	if err != nil {
@@ -135,11 +137,25 @@ func BuildRESTServer(ctx context.Context, addr string) *RESTServer {
 
 func addAllMethodsToRouter(router *mux.Router) {
 	addHealthzMethodToRouter(router)
+	addAliasMethodsToRouter(router)
+	addAuditMethodsToRouter(router)
 	addBundleMethodsToRouter(router)
 	addConfigMethodsToRouter(router)
+	addCostMethodsToRouter(router)
+	addDeadLetterMethodsToRouter(router)
+	addDoctorMethodsToRouter(router)
+	addErrorCodeMethodsToRouter(router)
+	addExecMethodsToRouter(router)
 	addGroupMethodsToRouter(router)
+	addMacroMethodsToRouter(router)
+	addOIDCMethodsToRouter(router)
+	addOptionDefaultMethodsToRouter(router)
+	addRequestMethodsToRouter(router)
 	addRoleMethodsToRouter(router)
+	addSecretMethodsToRouter(router)
+	addSystemMethodsToRouter(router)
 	addUserMethodsToRouter(router)
+	addWorkerMethodsToRouter(router)
 	addManagementMethodsToRouter(router)
 }
 
@@ -168,6 +184,8 @@ func addManagementMethodsToRouter(router *mux.Router) {
 
 func addHealthzMethodToRouter(router *mux.Router) {
 	router.Handle("/v2/authenticate", otelhttp.NewHandler(http.HandlerFunc(handleAuthenticate), "authenticate")).Methods("POST")
+	router.Handle("/v2/authenticate", otelhttp.NewHandler(http.HandlerFunc(handleDeleteAuthenticate), "authenticate")).Methods("DELETE")
+	router.Handle("/v2/authenticate/refresh", otelhttp.NewHandler(http.HandlerFunc(handleAuthenticateRefresh), "authenticate")).Methods("POST")
 	router.Handle("/v2/bootstrap", otelhttp.NewHandler(http.HandlerFunc(handleBootstrap), "bootstrap")).Methods("POST")
 	router.Handle("/v2/healthz", otelhttp.NewHandler(http.HandlerFunc(handleHealthz), "healthz")).Methods("GET")
 }
@@ -209,22 +227,18 @@ func buildLoggingMiddleware(logsous chan RequestEvent) func(http.Handler) http.H
 			status := 200
 			bytelen := 0
 
+			// The request's state, like its token and user, is resolved at
+			// most once, by whichever handler first needs it, and shared
+			// with the rest of the chain.
+			r, rc := withRequestContext(r)
+			defer rc.Span().End()
+
 			// Call the next handler, which can be another middleware in the chain, or the final handler.
 			next.ServeHTTP(StatusCaptureWriter{w, &status, &bytelen}, r)
 
 			// If there's a token, retrieve it for logging purposes.
 			userID := "-"
-			tokenString := r.Header.Get("X-Session-Token")
-			if tokenString != "" {
-				dataAccessLayer, err := dataaccess.Get()
-				if err != nil {
-					log.WithError(err).Error(errs.ErrDataAccess)
-					telemetry.Errors().WithError(err).Commit(r.Context())
-					respondAndLogError(r.Context(), w, errs.ErrDataAccess)
-					return
-				}
-
-				token, _ := dataAccessLayer.TokenRetrieveByToken(r.Context(), tokenString)
+			if token, _ := rc.Token(); token.User != "" {
 				userID = token.User
 			}
 
@@ -247,7 +261,7 @@ func buildLoggingMiddleware(logsous chan RequestEvent) func(http.Handler) http.H
 	}
 }
 
-// handleAuthenticate handles "GET /authenticate"
+// handleAuthenticate handles "POST /v2/authenticate"
 func handleAuthenticate(w http.ResponseWriter, r *http.Request) {
 	// Grab the user struct from the request. If it doesn't exist, respond with
 	// a client error.
@@ -258,61 +272,168 @@ func handleAuthenticate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := user.Username
-	password := user.Password
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
 
-	le := log.WithField("user", user)
+	// The password may be verified by an external directory, like LDAP, in
+	// which case the user may be created on the fly.
+	username, err := authenticatePassword(r.Context(), dataAccessLayer, user.Username, user.Password)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
 
-	dataAccessLayer, err := dataaccess.Get()
+	token, err := issueAPIToken(r.Context(), dataAccessLayer, username, time.Now())
 	if err != nil {
-		le.WithError(err).Error(err.Error())
-		telemetry.Errors().WithError(err).Commit(r.Context())
+		respondAndLogError(r.Context(), w, err)
 		return
 	}
 
-	exists, err := dataAccessLayer.UserExists(r.Context(), username)
+	json.NewEncoder(w).Encode(token)
+}
+
+// handleAuthenticateRefresh handles "POST /v2/authenticate/refresh"
+// The request's token is exchanged for a new one, and revoked. A JWT can
+// only be refreshed until the session it belongs to is older than
+// global.api_tokens.max_session_lifetime.
+func handleAuthenticateRefresh(w http.ResponseWriter, r *http.Request) {
+	token, err := getRequestContext(r).Token()
 	if err != nil {
-		le.WithError(err).Error("Authentication: failed to find user")
-		telemetry.Errors().WithError(err).Commit(r.Context())
+		respondAndLogError(r.Context(), w, err)
 		return
 	}
 
-	if !exists {
-		http.Error(w, "No such user", http.StatusBadRequest)
-		le.Error("Authentication: No such user")
-		telemetry.Errors().WithError(fmt.Errorf("no such user")).Commit(r.Context())
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
 		return
 	}
 
-	authenticated, err := dataAccessLayer.UserAuthenticate(r.Context(), username, password)
+	sessionStart := time.Now()
+	if auth.IsJWT(token.Token) {
+		claims, err := auth.VerifyAPIToken(token.Token)
+		if err != nil {
+			respondAndLogError(r.Context(), w, gerrs.Wrap(ErrUnauthorized, err))
+			return
+		}
+
+		sessionStart = time.Unix(claims.SessionStart, 0)
+		maxLifetime := config.GetGlobalConfigs().APITokens.MaxSessionLifetimeOrDefault()
+		if time.Since(sessionStart) > maxLifetime {
+			respondAndLogError(r.Context(), w, ErrSessionExpired)
+			return
+		}
+	}
+
+	refreshed, err := issueAPIToken(r.Context(), dataAccessLayer, token.User, sessionStart)
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
 	}
 
-	if !authenticated {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+	if err := revokeAPIToken(r.Context(), dataAccessLayer, token); err != nil {
+		respondAndLogError(r.Context(), w, err)
 		return
 	}
 
-	token, err := dataAccessLayer.TokenGenerate(r.Context(), username, 10*time.Second)
+	json.NewEncoder(w).Encode(refreshed)
+}
+
+// handleDeleteAuthenticate handles "DELETE /v2/authenticate"
+// The request's token is revoked.
+func handleDeleteAuthenticate(w http.ResponseWriter, r *http.Request) {
+	token, err := getRequestContext(r).Token()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(token)
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if err := revokeAPIToken(r.Context(), dataAccessLayer, token); err != nil {
+		respondAndLogError(r.Context(), w, err)
+	}
+}
+
+// issueAPIToken issues a new API token for the user, of the kind selected by
+// global.api_tokens.mode. An opaque token replaces any the user already has;
+// a JWT belongs to the session that began at sessionStart.
+func issueAPIToken(ctx context.Context, da dataaccess.DataAccess, username string, sessionStart time.Time) (rest.Token, error) {
+	tc := config.GetGlobalConfigs().APITokens
+	lifetime := tc.LifetimeOrDefault()
+
+	if tc.Mode != data.APITokenModeJWT {
+		return da.TokenGenerate(ctx, username, lifetime)
+	}
+
+	exists, err := da.UserExists(ctx, username)
+	if err != nil {
+		return rest.Token{}, err
+	}
+	if !exists {
+		return rest.Token{}, errs.ErrNoSuchUser
+	}
+
+	now := time.Now().UTC()
+	claims, err := auth.NewAPIClaims(username, sessionStart, now, lifetime)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	signed, err := auth.SignAPIToken(claims)
+	if err != nil {
+		return rest.Token{}, err
+	}
+
+	return rest.Token{
+		Duration:   lifetime,
+		Token:      signed,
+		User:       username,
+		ValidFrom:  now,
+		ValidUntil: time.Unix(claims.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+// revokeAPIToken revokes the token: a JWT is added to the revocation list
+// until it expires, and an opaque token is deleted. An opaque token that's
+// already been replaced by a newer one isn't an error.
+func revokeAPIToken(ctx context.Context, da dataaccess.DataAccess, token rest.Token) error {
+	if !auth.IsJWT(token.Token) {
+		err := da.TokenInvalidate(ctx, token.Token)
+		if err != nil && !gerrs.Is(err, errs.ErrNoSuchToken) {
+			return err
+		}
+		return nil
+	}
+
+	claims, err := auth.VerifyAPIToken(token.Token)
+	if err != nil {
+		return gerrs.Wrap(ErrUnauthorized, err)
+	}
+
+	return da.TokenRevoke(ctx, claims.ID, time.Unix(claims.ExpiresAt, 0))
 }
 
 func DoBootstrap(ctx context.Context, user rest.User) (rest.User, error) {
 	const adminGroup = "admin"
 	const adminRole = "admin"
 	var adminPermissions = []string{
+		"manage_aliases",
 		"manage_commands",
 		"manage_configs",
 		"manage_groups",
+		"manage_macros",
 		"manage_roles",
+		"manage_system",
 		"manage_users",
+		"send_direct_messages",
 	}
 
 	dataAccessLayer, err := dataaccess.Get()
@@ -385,7 +506,7 @@ func DoBootstrap(ctx context.Context, user rest.User) (rest.User, error) {
 
 // handleBootstrap handles "POST /bootstrap"
 func handleBootstrap(w http.ResponseWriter, r *http.Request) {
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -433,7 +554,7 @@ func handleHealthz(w http.ResponseWriter, r *http.Request) {
 		Password: testPassword,
 	}
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -461,128 +582,67 @@ func handleReload(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// respondAndLogError logs err, and responds with a JSON rest.Error whose
+// HTTP status is the one registered for err with gerrs.RegisterStatus.
+// Errors without a registered status are unexpected, and are reported as
+// internal server errors.
 func respondAndLogError(ctx context.Context, w http.ResponseWriter, err error) {
-	status := http.StatusInternalServerError
+	status, ok := gerrs.StatusOf(err)
 	msg := err.Error()
 
 	switch {
-	// A required field is empty or missing
-	case gerrs.Is(err, errs.ErrEmptyBundleName):
-		fallthrough
-	case gerrs.Is(err, errs.ErrEmptyBundleVersion):
-		fallthrough
-	case gerrs.Is(err, errs.ErrEmptyConfigBundle):
-		fallthrough
-	case gerrs.Is(err, errs.ErrEmptyConfigLayer):
-		fallthrough
-	case gerrs.Is(err, errs.ErrEmptyConfigOwner):
-		fallthrough
-	case gerrs.Is(err, errs.ErrEmptyConfigKey):
-		fallthrough
-	case gerrs.Is(err, errs.ErrEmptyGroupName):
-		fallthrough
-	case gerrs.Is(err, errs.ErrEmptyUserName):
-		fallthrough
-	case gerrs.Is(err, ErrMissingValue):
-		fallthrough
-	case gerrs.Is(err, errs.ErrFieldRequired):
-		fallthrough
-	case strings.HasPrefix(err.Error(), "dynamic configuration layers must be one of:"):
-		status = http.StatusExpectationFailed
-		log.WithError(err).WithField("status", status).Info(msg)
-
-	// Requested resource doesn't exist
-	case gerrs.Is(err, errs.ErrNoSuchBundle):
-		fallthrough
-	case gerrs.Is(err, errs.ErrNoSuchConfig):
-		fallthrough
-	case gerrs.Is(err, errs.ErrNoSuchGroup):
-		fallthrough
-	case gerrs.Is(err, errs.ErrNoSuchRole):
-		fallthrough
-	case gerrs.Is(err, errs.ErrNoSuchToken):
-		fallthrough
-	case gerrs.Is(err, errs.ErrNoSuchUser):
-		status = http.StatusNotFound
-		log.WithError(err).WithField("status", status).Info(msg)
-
-	// Nope
-	case gerrs.Is(err, errs.ErrConfigIllegal):
-		fallthrough
-	case gerrs.Is(err, errs.ErrAdminUndeletable):
-		status = http.StatusForbidden
-		log.WithError(err).WithField("status", status).Warn(msg)
-
-	// Can't insert over something that already exists
-	case gerrs.Is(err, errs.ErrBundleExists):
-		fallthrough
-	case gerrs.Is(err, errs.ErrConfigExists):
-		fallthrough
-	case gerrs.Is(err, errs.ErrGroupExists):
-		fallthrough
-	case gerrs.Is(err, errs.ErrUserExists):
-		status = http.StatusConflict
-		log.WithError(err).WithField("status", status).Info(msg)
-
-	// Not done yet
-	case gerrs.Is(err, errs.ErrNotImplemented):
-		status = http.StatusNotImplemented
-		log.WithError(err).WithField("status", status).Info(msg)
-
-	// Data access errors
-	case gerrs.Is(err, errs.ErrDataAccessNotInitialized):
-		fallthrough
-	case gerrs.Is(err, errs.ErrDataAccessCantInitialize):
-		fallthrough
-	case gerrs.Is(err, errs.ErrDataAccessCantConnect):
-		fallthrough
-	case gerrs.Is(err, errs.ErrDataAccess):
-		status = http.StatusInternalServerError
-		log.WithError(err).WithField("status", status).Error(msg)
-
-	// Bad context
-	case gerrs.Is(err, gerrs.ErrUnmarshal):
-		msg = "Corrupt JSON payload"
-		status = http.StatusNotAcceptable
-		log.WithError(err).WithField("status", status).Error(msg)
-
-	case gerrs.Is(err, ErrUnauthorized):
-		status = http.StatusUnauthorized
-		log.WithError(err).WithField("status", status).Error(msg)
+	case !ok:
+		status = gerrs.StatusInternal
+		telemetry.Errors().WithError(err).Commit(ctx)
+		log.WithError(err).WithField("status", status.HTTPStatus).Error("Unhandled server error")
 
 	case gerrs.Is(err, ErrGortBundleDisabled):
-		status = http.StatusUnauthorized
 		if e, ok := err.(gerrs.NestedError); ok {
-			err = e.Err
+			telemetry.Errors().WithError(e.Err).Commit(ctx)
+		} else {
+			telemetry.Errors().WithError(err).Commit(ctx)
 		}
-		telemetry.Errors().WithError(err).Commit(ctx)
-		log.WithError(err).WithField("status", status).Error(msg)
+		fallthrough
 
-	// Something else?
 	default:
-		telemetry.Errors().WithError(err).Commit(ctx)
-		status = http.StatusInternalServerError
-		log.WithError(err).WithField("status", status).Error("Unhandled server error")
+		if status.Message != "" {
+			msg = status.Message
+		}
+		log.WithError(err).WithField("status", status.HTTPStatus).Log(status.Level, msg)
+	}
+
+	body := rest.Error{Status: status.HTTPStatus, Message: msg}
+	if code, ok := gerrs.CodeOf(err); ok {
+		body.Code = code.Code
+		body.Title = code.Title
 	}
 
-	http.Error(w, msg, status)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status.HTTPStatus)
+	json.NewEncoder(w).Encode(body)
 }
 
 // Provides a middleware function that simply looks for the EXISTENCE of a valid token.
 // More granular role-based auth is also performed at the function level.
 func tokenObservingMiddleware(next http.Handler) http.Handler {
 	exemptEndpoints := map[string]bool{
-		"/v2/authenticate": true,
-		"/v2/bootstrap":    true,
-		"/v2/healthz":      true,
-		"/v2/metrics":      true,
-		"/v2/reload":       true,
+		"/v2/authenticate":           true,
+		"/v2/auth/oidc/callback":     true,
+		"/v2/auth/oidc/device":       true,
+		"/v2/auth/oidc/device/token": true,
+		"/v2/auth/oidc/login":        true,
+		"/v2/bootstrap":              true,
+		"/v2/healthz":                true,
+		"/v2/metrics":                true,
+		"/v2/reload":                 true,
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestURI := strings.Split(r.RequestURI, "?")[0]
 
-		if exemptEndpoints[requestURI] {
+		if exemptEndpoints[requestURI] || strings.HasPrefix(requestURI, secretsPathPrefix) ||
+			strings.HasPrefix(requestURI, workerPathPrefix) {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -592,14 +652,13 @@ func tokenObservingMiddleware(next http.Handler) http.Handler {
 			WithAttribute("request.remote-addr", strings.Split(r.RemoteAddr, ":")[0]).
 			Commit(r.Context())
 
-		dataAccessLayer, err := dataaccess.Get()
-		if err != nil {
-			telemetry.Errors().WithError(err).Commit(r.Context())
+		_, err := getRequestContext(r).Token()
+		if err != nil && !gerrs.Is(err, ErrUnauthorized) {
+			respondAndLogError(r.Context(), w, err)
 			return
 		}
 
-		token := r.Header.Get("X-Session-Token")
-		if token == "" || !dataAccessLayer.TokenEvaluate(r.Context(), token) {
+		if err != nil {
 			telemetry.UnauthorizedRequests().
 				WithAttribute("request.uri", r.RequestURI).
 				WithAttribute("request.remote-addr", strings.Split(r.RemoteAddr, ":")[0]).
@@ -614,7 +673,22 @@ func tokenObservingMiddleware(next http.Handler) http.Handler {
 
 func authCommand(handler func(w http.ResponseWriter, r *http.Request), cmd string, subcmd ...string) http.HandlerFunc {
 	inner := func(w http.ResponseWriter, r *http.Request) {
-		if !authenticateUser(w, r, cmd, subcmd...) {
+		if !authenticateUser(w, r, "", cmd, subcmd...) {
+			return
+		}
+
+		handler(w, r)
+	}
+
+	return http.HandlerFunc(inner)
+}
+
+// authBundleCommand is like authCommand, except that any bundle-scoped
+// permissions held by the user (like "gort:bundle_enable@team-*") are
+// resolved against the bundle named by the request's param path variable.
+func authBundleCommand(handler func(w http.ResponseWriter, r *http.Request), param string, cmd string, subcmd ...string) http.HandlerFunc {
+	inner := func(w http.ResponseWriter, r *http.Request) {
+		if !authenticateUser(w, r, mux.Vars(r)[param], cmd, subcmd...) {
 			return
 		}
 
@@ -629,10 +703,12 @@ func authCommand(handler func(w http.ResponseWriter, r *http.Request), cmd strin
 // is evaluated exactly as if the requesting user executed "gort users" on the
 // command line. If the default gort bundle doesn't exist or isn't enabled a
 // ErrGortBundleDisabled error will be returned.
+// If bundle is non-empty, the user's scoped permissions are resolved against
+// it; otherwise scoped permissions are ignored.
 // The actual work is done by doAuthenticateUser; this function really cares
 // mostly about logging and error handling.
-func authenticateUser(w http.ResponseWriter, r *http.Request, gortCommand string, args ...string) bool {
-	auth, err := doAuthenticateUser(r, gortCommand, args...)
+func authenticateUser(w http.ResponseWriter, r *http.Request, bundle string, gortCommand string, args ...string) bool {
+	auth, err := doAuthenticateUser(r, bundle, gortCommand, args...)
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return false
@@ -646,35 +722,27 @@ func authenticateUser(w http.ResponseWriter, r *http.Request, gortCommand string
 }
 
 // doAuthenticateUser does the actual work for authenticateUser.
-func doAuthenticateUser(r *http.Request, gortCommand string, args ...string) (bool, error) {
-	dataAccessLayer, err := dataaccess.Get()
-	if err != nil {
-		return false, err
-	}
+func doAuthenticateUser(r *http.Request, bundleName string, gortCommand string, args ...string) (bool, error) {
+	rc := getRequestContext(r)
 
-	t := r.Header.Get("X-Session-Token")
-	if t == "" || !dataAccessLayer.TokenEvaluate(r.Context(), t) {
-		return false, ErrUnauthorized
-	}
-
-	token, err := dataAccessLayer.TokenRetrieveByToken(r.Context(), t)
+	perms, err := rc.Permissions()
 	if err != nil {
 		return false, err
 	}
 
-	perms, err := dataAccessLayer.UserPermissionList(r.Context(), token.User)
+	dataAccessLayer, err := rc.DataAccess()
 	if err != nil {
 		return false, err
 	}
 
-	bundle, command, err := getGortBundleCommand(r.Context(), gortCommand)
+	bundle, command, err := getGortBundleCommand(r.Context(), dataAccessLayer, gortCommand)
 	if err != nil {
 		return false, gerrs.Wrap(ErrGortBundleDisabled, err)
 	}
 	ce := data.CommandEntry{Bundle: bundle, Command: command}
 
-	// Convert all args to types.Value values.
-	args = append([]string{gortCommand}, args...)
+	// Convert all args to types.Value values. As in chat, arg[0] is the
+	// first argument to the command, not the command itself.
 	argValues, err := types.Inferrer{}.StrictStrings(false).InferAll(args)
 	if err != nil {
 		return false, err
@@ -682,20 +750,15 @@ func doAuthenticateUser(r *http.Request, gortCommand string, args ...string) (bo
 
 	env := rules.EvaluationEnvironment{"arg": argValues}
 
-	return auth.EvaluateCommandEntry(perms.Strings(), ce, env)
+	return auth.EvaluateCommandEntry(auth.ScopePermissions(perms.Strings(), bundleName), ce, env)
 }
 
 // getGortBundleCommand retrieves the data.BundleCommand value from the default
 // Gort command bundle. If the bundle doesn't exist, isn't enabled, or if the
 // requested command doesn't exist, an error will be returned.
-func getGortBundleCommand(ctx context.Context, commandName string) (data.Bundle, data.BundleCommand, error) {
+func getGortBundleCommand(ctx context.Context, dataAccessLayer dataaccess.DataAccess, commandName string) (data.Bundle, data.BundleCommand, error) {
 	const bundleName = "gort"
 
-	dataAccessLayer, err := dataaccess.Get()
-	if err != nil {
-		return data.Bundle{}, data.BundleCommand{}, err
-	}
-
 	bundleVersion, err := dataAccessLayer.BundleEnabledVersion(ctx, bundleName)
 	if err != nil {
 		return data.Bundle{}, data.BundleCommand{}, err
@@ -717,22 +780,36 @@ func getGortBundleCommand(ctx context.Context, commandName string) (data.Bundle,
 	return bundle, *cmd, nil
 }
 
-// getUserByRequest gets the user associated with a request token.
-func getUserByRequest(r *http.Request) (rest.User, error) {
-	dataAccessLayer, err := dataaccess.Get()
+// lookupJWT validates a JWT API token by its signature and claims, and
+// checks that it hasn't been revoked and that its user still exists.
+func lookupJWT(ctx context.Context, da dataaccess.DataAccess, t string) (rest.Token, error) {
+	claims, err := auth.VerifyAPIToken(t)
 	if err != nil {
-		return rest.User{}, err
+		return rest.Token{}, gerrs.Wrap(ErrUnauthorized, err)
 	}
 
-	t := r.Header.Get("X-Session-Token")
-	if t == "" || !dataAccessLayer.TokenEvaluate(r.Context(), t) {
-		return rest.User{}, ErrUnauthorized
+	revoked, err := da.TokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return rest.Token{}, err
+	}
+	if revoked {
+		return rest.Token{}, ErrUnauthorized
 	}
 
-	token, err := dataAccessLayer.TokenRetrieveByToken(r.Context(), t)
+	// Unlike opaque tokens, JWTs aren't invalidated when their user is
+	// deleted or renamed.
+	exists, err := da.UserExists(ctx, claims.Subject)
 	if err != nil {
-		return rest.User{}, err
+		return rest.Token{}, err
+	}
+	if !exists {
+		return rest.Token{}, ErrUnauthorized
 	}
 
-	return dataAccessLayer.UserGet(r.Context(), token.User)
+	return rest.Token{
+		Token:      t,
+		User:       claims.Subject,
+		ValidFrom:  time.Unix(claims.IssuedAt, 0).UTC(),
+		ValidUntil: time.Unix(claims.ExpiresAt, 0).UTC(),
+	}, nil
 }
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -29,6 +30,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/getgort/gort/auth"
 	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/dataaccess"
 	"github.com/getgort/gort/dataaccess/memory"
@@ -40,9 +42,11 @@ var adminToken rest.Token
 // result to be verified.
 type ResponseTester struct {
 	body           interface{}
+	headers        map[string]string
 	out            interface{}
 	method         string
 	target         string
+	token          *rest.Token
 	expectedStatus *int
 }
 
@@ -61,6 +65,16 @@ func (r ResponseTester) WithBody(body interface{}) ResponseTester {
 	return r
 }
 
+// WithHeader adds a header to a request to be tested.
+func (r ResponseTester) WithHeader(key, value string) ResponseTester {
+	headers := map[string]string{key: value}
+	for k, v := range r.headers {
+		headers[k] = v
+	}
+	r.headers = headers
+	return r
+}
+
 // WithStatus adds an expected HTTP status to a ResponseTester.
 // If the response does not have the specified code, the test fails.
 func (r ResponseTester) WithStatus(status int) ResponseTester {
@@ -68,6 +82,13 @@ func (r ResponseTester) WithStatus(status int) ResponseTester {
 	return r
 }
 
+// WithToken sends the request using the given token rather than the admin
+// user's token.
+func (r ResponseTester) WithToken(token rest.Token) ResponseTester {
+	r.token = &token
+	return r
+}
+
 // WithOutput requests JSON output from a response.
 // The provided pointer will be populated with the unmarshaled form of the JSON
 // in the response body.
@@ -90,8 +111,16 @@ func (r ResponseTester) Test(t *testing.T, router *mux.Router, msgAndArgs ...int
 		bodyReader = bytes.NewReader(jsonData)
 	}
 
+	token := adminToken
+	if r.token != nil {
+		token = *r.token
+	}
+
 	req := httptest.NewRequest(r.method, r.target, bodyReader)
-	req.Header.Add("X-Session-Token", adminToken.Token)
+	req.Header.Add("X-Session-Token", token.Token)
+	for k, v := range r.headers {
+		req.Header.Add(k, v)
+	}
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -100,7 +129,8 @@ func (r ResponseTester) Test(t *testing.T, router *mux.Router, msgAndArgs ...int
 	resBody, err := io.ReadAll(resp.Body)
 	require.NoError(t, err, msgAndArgs...)
 
-	if r.out != nil && json.Valid(resBody) {
+	// Error responses have a rest.Error body, which isn't what's expected.
+	if r.out != nil && resp.StatusCode < http.StatusBadRequest && json.Valid(resBody) {
 		err = json.Unmarshal(resBody, &r.out)
 		require.NoError(t, err, msgAndArgs...)
 	}
@@ -136,3 +166,148 @@ func createTestRouter() *mux.Router {
 
 	return router
 }
+
+func TestRequestContext(t *testing.T) {
+	ctx := context.Background()
+	createTestRouter()
+
+	dataAccessLayer, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	// A request without a token is unauthorized.
+	req := httptest.NewRequest("GET", "/v2/users", nil)
+	_, err = getRequestContext(req).Token()
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, err = getRequestContext(req).User()
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	req = httptest.NewRequest("GET", "/v2/users", nil)
+	req.Header.Add("X-Session-Token", "no-such-token")
+	_, err = getRequestContext(req).Token()
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	req = httptest.NewRequest("GET", "/v2/users", nil)
+	req.Header.Add("X-Session-Token", adminToken.Token)
+	req, rc := withRequestContext(req)
+	defer rc.Span().End()
+
+	// Middleware and handlers share the request's context.
+	assert.Same(t, rc, getRequestContext(req))
+
+	token, err := rc.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "admin", token.User)
+
+	user, err := rc.User()
+	require.NoError(t, err)
+	assert.Equal(t, "admin", user.Username)
+
+	perms, err := rc.Permissions()
+	require.NoError(t, err)
+	assert.Contains(t, perms.Strings(), "gort:manage_users")
+
+	// The token is only looked up once per request.
+	require.NoError(t, dataAccessLayer.TokenInvalidate(ctx, adminToken.Token))
+
+	token, err = getRequestContext(req).Token()
+	require.NoError(t, err)
+	assert.Equal(t, "admin", token.User)
+
+	// But not between requests.
+	req = httptest.NewRequest("GET", "/v2/users", nil)
+	req.Header.Add("X-Session-Token", adminToken.Token)
+	req, rc = withRequestContext(req)
+	defer rc.Span().End()
+
+	_, err = rc.Token()
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestAuthenticateRefresh(t *testing.T) {
+	router := createTestRouter()
+
+	// An opaque token is replaced by a new one.
+	var refreshed rest.Token
+	NewResponseTester("POST", "http://example.com/v2/authenticate/refresh").WithOutput(&refreshed).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "admin", refreshed.User)
+	assert.NotEqual(t, adminToken.Token, refreshed.Token)
+
+	NewResponseTester("GET", "http://example.com/v2/users").WithStatus(http.StatusUnauthorized).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/users").WithToken(refreshed).WithStatus(http.StatusOK).Test(t, router)
+
+	// A JWT is validated by its signature.
+	now := time.Now()
+	claims, err := auth.NewAPIClaims("admin", now, now, time.Minute)
+	require.NoError(t, err)
+	signed, err := auth.SignAPIToken(claims)
+	require.NoError(t, err)
+	jwt := rest.Token{Token: signed}
+
+	NewResponseTester("GET", "http://example.com/v2/users").WithToken(jwt).WithStatus(http.StatusOK).Test(t, router)
+
+	// A refreshed JWT is revoked.
+	NewResponseTester("POST", "http://example.com/v2/authenticate/refresh").WithToken(jwt).WithOutput(&refreshed).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/users").WithToken(jwt).WithStatus(http.StatusUnauthorized).Test(t, router)
+
+	// So is one that's deleted.
+	claims, err = auth.NewAPIClaims("admin", now, now, time.Minute)
+	require.NoError(t, err)
+	signed, err = auth.SignAPIToken(claims)
+	require.NoError(t, err)
+	jwt = rest.Token{Token: signed}
+
+	NewResponseTester("DELETE", "http://example.com/v2/authenticate").WithToken(jwt).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/users").WithToken(jwt).WithStatus(http.StatusUnauthorized).Test(t, router)
+
+	// A JWT from a session that's too old can't be refreshed.
+	claims, err = auth.NewAPIClaims("admin", now.Add(-48*time.Hour), now, time.Minute)
+	require.NoError(t, err)
+	signed, err = auth.SignAPIToken(claims)
+	require.NoError(t, err)
+	jwt = rest.Token{Token: signed}
+
+	NewResponseTester("GET", "http://example.com/v2/users").WithToken(jwt).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("POST", "http://example.com/v2/authenticate/refresh").WithToken(jwt).WithStatus(http.StatusUnauthorized).Test(t, router)
+
+	// Tampered JWTs aren't accepted.
+	jwt.Token += "x"
+	NewResponseTester("GET", "http://example.com/v2/users").WithToken(jwt).WithStatus(http.StatusUnauthorized).Test(t, router)
+}
+
+func TestLookupJWTUser(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	dataAccessLayer, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	lookup := func(username string) error {
+		now := time.Now()
+		claims, err := auth.NewAPIClaims(username, now, now, time.Minute)
+		require.NoError(t, err)
+		signed, err := auth.SignAPIToken(claims)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/v2/users", nil)
+		req.Header.Add("X-Session-Token", signed)
+		_, err = getRequestContext(req).Token()
+		return err
+	}
+
+	t.Run("deleted", func(t *testing.T) {
+		require.NoError(t, dataAccessLayer.UserCreate(ctx, rest.User{Username: "jwt-deleted"}))
+		require.NoError(t, lookup("jwt-deleted"))
+
+		NewResponseTester("DELETE", "http://example.com/v2/users/jwt-deleted").WithStatus(http.StatusOK).Test(t, router)
+		assert.ErrorIs(t, lookup("jwt-deleted"), ErrUnauthorized)
+	})
+
+	t.Run("renamed", func(t *testing.T) {
+		require.NoError(t, dataAccessLayer.UserCreate(ctx, rest.User{Username: "jwt-renamed"}))
+		require.NoError(t, lookup("jwt-renamed"))
+
+		NewResponseTester("PUT", "http://example.com/v2/users/jwt-renamed/rename/jwt-renamed-2").WithStatus(http.StatusOK).Test(t, router)
+		assert.ErrorIs(t, lookup("jwt-renamed"), ErrUnauthorized)
+		assert.NoError(t, lookup("jwt-renamed-2"))
+	})
+}
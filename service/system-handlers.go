@@ -0,0 +1,358 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/cluster"
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess/errs"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// AnnounceFunc sends an announcement to the chat providers. It's provided by
+// the adapter layer, which the service doesn't otherwise depend on.
+type AnnounceFunc func(ctx context.Context, a rest.Announcement) (rest.AnnouncementResult, error)
+
+var announcer AnnounceFunc
+
+// ChannelManager provides access to the chat channels that Gort's adapters
+// are present in. It's provided by the adapter layer.
+type ChannelManager interface {
+	// ChannelList returns every channel that each adapter is present in.
+	ChannelList(ctx context.Context) ([]rest.Channel, error)
+
+	// ChannelJoin causes an adapter to join a channel, specified by ID or
+	// name. It returns an ErrUnsupported error if the chat provider doesn't
+	// support this.
+	ChannelJoin(ctx context.Context, adapter, channel string) (rest.Channel, error)
+
+	// ChannelLeave causes an adapter to leave a channel, specified by ID or
+	// name. It returns an ErrUnsupported error if the chat provider doesn't
+	// support this.
+	ChannelLeave(ctx context.Context, adapter, channel string) error
+}
+
+var channelManager ChannelManager
+
+// SetChannelManager sets the ChannelManager used by the "/v2/system/channels"
+// endpoints.
+func SetChannelManager(m ChannelManager) {
+	channelManager = m
+}
+
+// CanaryStatusFunc reports the recent results of each configured canary.
+// It's provided by the adapter layer.
+type CanaryStatusFunc func(ctx context.Context) ([]rest.CanaryStatus, error)
+
+var canaryReporter CanaryStatusFunc
+
+// SetCanaryReporter sets the function used to report canary results by
+// "GET /v2/system/canaries".
+func SetCanaryReporter(f CanaryStatusFunc) {
+	canaryReporter = f
+}
+
+// SelfTestFunc runs a self-test of the command pipeline on behalf of the
+// named user. It's provided by the adapter layer.
+type SelfTestFunc func(ctx context.Context, username string, t rest.SelfTestRequest) (rest.SelfTestResult, error)
+
+var selfTester SelfTestFunc
+
+// SetAnnouncer sets the function used to send announcements received by
+// "POST /v2/system/announce".
+func SetAnnouncer(f AnnounceFunc) {
+	announcer = f
+}
+
+// SetSelfTester sets the function used to run self-tests requested by
+// "POST /v2/system/selftest".
+func SetSelfTester(f SelfTestFunc) {
+	selfTester = f
+}
+
+// handleGetClusterStatus handles "GET /v2/system/cluster"
+func handleGetClusterStatus(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(cluster.Status())
+}
+
+// handleGetFeatures handles "GET /v2/system/features"
+func handleGetFeatures(w http.ResponseWriter, r *http.Request) {
+	configs := config.GetFeatureConfigs()
+
+	flags := []rest.FeatureFlag{}
+	for _, f := range data.FeatureFlags {
+		flags = append(flags, rest.FeatureFlag{
+			Name:        f.Name,
+			Description: f.Description,
+			Default:     f.Default,
+			Enabled:     configs.Enabled(f.Name),
+		})
+	}
+
+	json.NewEncoder(w).Encode(flags)
+}
+
+// handleGetCanaries handles "GET /v2/system/canaries"
+func handleGetCanaries(w http.ResponseWriter, r *http.Request) {
+	statuses := []rest.CanaryStatus{}
+
+	if canaryReporter != nil {
+		var err error
+		statuses, err = canaryReporter(r.Context())
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// handlePostAnnounce handles "POST /v2/system/announce"
+func handlePostAnnounce(w http.ResponseWriter, r *http.Request) {
+	var a rest.Announcement
+
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	switch {
+	case a.Message == "":
+		http.Error(w, "announcement message is required", http.StatusBadRequest)
+		return
+	case !a.AllChannels && len(a.Channels) == 0:
+		http.Error(w, "announcement requires all_channels or at least one channel", http.StatusBadRequest)
+		return
+	case announcer == nil:
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := announcer(r.Context(), a)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// handlePostSelfTest handles "POST /v2/system/selftest"
+func handlePostSelfTest(w http.ResponseWriter, r *http.Request) {
+	var t rest.SelfTestRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	switch {
+	case (t.Adapter == "") != (t.Channel == ""):
+		http.Error(w, "adapter and channel must be specified together", http.StatusBadRequest)
+		return
+	case selfTester == nil:
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return
+	}
+
+	user, err := getRequestContext(r).User()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	result, err := selfTester(r.Context(), user.Username, t)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleGetChannels handles "GET /v2/system/channels"
+func handleGetChannels(w http.ResponseWriter, r *http.Request) {
+	if channelManager == nil {
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return
+	}
+
+	channels, err := channelManager.ChannelList(r.Context())
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(channels)
+}
+
+// handlePutChannel handles "PUT /v2/system/channels/{adapter}/{channel}"
+func handlePutChannel(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	if !checkChannelAdapter(w, params["adapter"]) {
+		return
+	}
+
+	channel, err := channelManager.ChannelJoin(r.Context(), params["adapter"], params["channel"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(channel)
+}
+
+// handleDeleteChannel handles "DELETE /v2/system/channels/{adapter}/{channel}"
+func handleDeleteChannel(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	if !checkChannelAdapter(w, params["adapter"]) {
+		return
+	}
+
+	err := channelManager.ChannelLeave(r.Context(), params["adapter"], params["channel"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleGetChannelSettings handles "GET /v2/system/channels/{adapter}/{channel}/settings"
+func handleGetChannelSettings(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	settings, err := dataAccessLayer.ChannelSettingsGet(r.Context(), params["adapter"], params["channel"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(settings)
+}
+
+// handlePutChannelSettings handles "PUT /v2/system/channels/{adapter}/{channel}/settings"
+func handlePutChannelSettings(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	var settings data.ChannelSettings
+	err := json.NewDecoder(r.Body).Decode(&settings)
+	if err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	settings.Adapter = params["adapter"]
+	settings.ChannelID = params["channel"]
+
+	if settings.QuietHours != nil {
+		if err := settings.QuietHours.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if settings.DefaultBundle != "" {
+		exists, err := dataAccessLayer.BundleExists(r.Context(), settings.DefaultBundle)
+		if err != nil {
+			respondAndLogError(r.Context(), w, err)
+			return
+		}
+		if !exists {
+			respondAndLogError(r.Context(), w, errs.ErrNoSuchBundle)
+			return
+		}
+	}
+
+	err = dataAccessLayer.ChannelSettingsSet(r.Context(), settings)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleDeleteChannelSettings handles "DELETE /v2/system/channels/{adapter}/{channel}/settings"
+func handleDeleteChannelSettings(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	err = dataAccessLayer.ChannelSettingsDelete(r.Context(), params["adapter"], params["channel"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// checkChannelAdapter verifies that a channel manager is available and that
+// the named adapter exists, writing an error response and returning false if
+// either isn't the case.
+func checkChannelAdapter(w http.ResponseWriter, adapter string) bool {
+	if channelManager == nil {
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return false
+	}
+
+	for _, a := range cluster.LocalMember().Adapters {
+		if a == adapter {
+			return true
+		}
+	}
+
+	http.Error(w, "no such adapter: "+adapter, http.StatusNotFound)
+	return false
+}
+
+func addSystemMethodsToRouter(router *mux.Router) {
+	router.Handle("/v2/system/channels", otelhttp.NewHandler(authCommand(handleGetChannels, "channel", "list"), "handleGetChannels")).Methods("GET")
+	router.Handle("/v2/system/channels/{adapter}/{channel}", otelhttp.NewHandler(authCommand(handlePutChannel, "channel", "join"), "handlePutChannel")).Methods("PUT")
+	router.Handle("/v2/system/channels/{adapter}/{channel}", otelhttp.NewHandler(authCommand(handleDeleteChannel, "channel", "leave"), "handleDeleteChannel")).Methods("DELETE")
+	router.Handle("/v2/system/channels/{adapter}/{channel}/settings", otelhttp.NewHandler(authCommand(handleGetChannelSettings, "channel", "default"), "handleGetChannelSettings")).Methods("GET")
+	router.Handle("/v2/system/channels/{adapter}/{channel}/settings", otelhttp.NewHandler(authCommand(handlePutChannelSettings, "channel", "default"), "handlePutChannelSettings")).Methods("PUT")
+	router.Handle("/v2/system/channels/{adapter}/{channel}/settings", otelhttp.NewHandler(authCommand(handleDeleteChannelSettings, "channel", "default"), "handleDeleteChannelSettings")).Methods("DELETE")
+	router.Handle("/v2/system/announce", otelhttp.NewHandler(authCommand(handlePostAnnounce, "announce"), "handlePostAnnounce")).Methods("POST")
+	router.Handle("/v2/system/selftest", otelhttp.NewHandler(authCommand(handlePostSelfTest, "selftest"), "handlePostSelfTest")).Methods("POST")
+	router.Handle("/v2/system/canaries", otelhttp.NewHandler(authCommand(handleGetCanaries, "system", "canaries"), "handleGetCanaries")).Methods("GET")
+	router.Handle("/v2/system/cluster", otelhttp.NewHandler(authCommand(handleGetClusterStatus, "system", "status"), "handleGetClusterStatus")).Methods("GET")
+	router.Handle("/v2/system/features", otelhttp.NewHandler(authCommand(handleGetFeatures, "system", "features"), "handleGetFeatures")).Methods("GET")
+}
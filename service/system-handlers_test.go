@@ -0,0 +1,230 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/cluster"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+func TestGetClusterStatus(t *testing.T) {
+	router := createTestRouter()
+
+	cluster.AddAdapter("testGetClusterStatus")
+	defer cluster.RemoveAdapter("testGetClusterStatus")
+
+	status := rest.ClusterStatus{}
+	NewResponseTester("GET", "http://example.com/v2/system/cluster").WithOutput(&status).WithStatus(http.StatusOK).Test(t, router)
+
+	if assert.Len(t, status.Members, 1) {
+		member := status.Members[0]
+		assert.True(t, member.Leader)
+		assert.Equal(t, status.Leader, member.Name)
+		assert.Contains(t, member.Adapters, "testGetClusterStatus")
+	}
+}
+
+func TestGetFeatures(t *testing.T) {
+	router := createTestRouter()
+
+	flags := []rest.FeatureFlag{}
+	NewResponseTester("GET", "http://example.com/v2/system/features").WithOutput(&flags).WithStatus(http.StatusOK).Test(t, router)
+
+	require.Len(t, flags, len(data.FeatureFlags))
+
+	enabled := map[string]bool{}
+	for _, f := range flags {
+		enabled[f.Name] = f.Enabled
+	}
+
+	// The service tests load no config, so every feature has its default.
+	for _, f := range data.FeatureFlags {
+		assert.Equal(t, f.Default, enabled[f.Name], f.Name)
+	}
+}
+
+func TestPostAnnounce(t *testing.T) {
+	router := createTestRouter()
+
+	var received rest.Announcement
+	SetAnnouncer(func(ctx context.Context, a rest.Announcement) (rest.AnnouncementResult, error) {
+		received = a
+		return rest.AnnouncementResult{
+			Sent: []rest.AnnouncementTarget{{Adapter: "slack", ChannelID: "C001", ChannelName: "general"}},
+		}, nil
+	})
+	defer SetAnnouncer(nil)
+
+	announcement := rest.Announcement{Message: "Maintenance at 17:00 UTC", AllChannels: true}
+	result := rest.AnnouncementResult{}
+	NewResponseTester("POST", "http://example.com/v2/system/announce").WithBody(announcement).WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+
+	assert.Equal(t, announcement, received)
+	if assert.Len(t, result.Sent, 1) {
+		assert.Equal(t, "C001", result.Sent[0].ChannelID)
+	}
+
+	// No target channels
+	NewResponseTester("POST", "http://example.com/v2/system/announce").WithBody(rest.Announcement{Message: "Hello"}).WithStatus(http.StatusBadRequest).Test(t, router)
+
+	// No message
+	NewResponseTester("POST", "http://example.com/v2/system/announce").WithBody(rest.Announcement{AllChannels: true}).WithStatus(http.StatusBadRequest).Test(t, router)
+}
+
+func TestPostSelfTest(t *testing.T) {
+	router := createTestRouter()
+
+	var username string
+	var received rest.SelfTestRequest
+	SetSelfTester(func(ctx context.Context, u string, st rest.SelfTestRequest) (rest.SelfTestResult, error) {
+		username, received = u, st
+		return rest.SelfTestResult{
+			Success: true,
+			Output:  []string{"0.0.1"},
+			Stages:  []rest.SelfTestStage{{Name: "relay", Duration: time.Millisecond}},
+			Total:   time.Millisecond,
+		}, nil
+	})
+	defer SetSelfTester(nil)
+
+	request := rest.SelfTestRequest{Adapter: "slack", Channel: "C001"}
+	result := rest.SelfTestResult{}
+	NewResponseTester("POST", "http://example.com/v2/system/selftest").WithBody(request).WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+
+	assert.Equal(t, "admin", username)
+	assert.Equal(t, request, received)
+	assert.True(t, result.Success)
+	assert.Equal(t, []string{"0.0.1"}, result.Output)
+
+	// Channel without an adapter
+	NewResponseTester("POST", "http://example.com/v2/system/selftest").WithBody(rest.SelfTestRequest{Channel: "C001"}).WithStatus(http.StatusBadRequest).Test(t, router)
+}
+
+type testChannelManager struct {
+	channels []rest.Channel
+}
+
+func (m *testChannelManager) ChannelList(ctx context.Context) ([]rest.Channel, error) {
+	return m.channels, nil
+}
+
+func (m *testChannelManager) ChannelJoin(ctx context.Context, adapter, channel string) (rest.Channel, error) {
+	if adapter == "testChannelsUnsupported" {
+		return rest.Channel{}, gerrs.ErrUnsupported
+	}
+
+	c := rest.Channel{Adapter: adapter, ID: channel, Name: channel}
+	m.channels = append(m.channels, c)
+	return c, nil
+}
+
+func (m *testChannelManager) ChannelLeave(ctx context.Context, adapter, channel string) error {
+	for i, c := range m.channels {
+		if c.Adapter == adapter && c.ID == channel {
+			m.channels = append(m.channels[:i], m.channels[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("not in channel")
+}
+
+func TestChannels(t *testing.T) {
+	router := createTestRouter()
+
+	cluster.AddAdapter("testChannels")
+	defer cluster.RemoveAdapter("testChannels")
+	cluster.AddAdapter("testChannelsUnsupported")
+	defer cluster.RemoveAdapter("testChannelsUnsupported")
+
+	SetChannelManager(&testChannelManager{})
+	defer SetChannelManager(nil)
+
+	joined := rest.Channel{}
+	NewResponseTester("PUT", "http://example.com/v2/system/channels/testChannels/C001").WithOutput(&joined).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "C001", joined.ID)
+
+	channels := []rest.Channel{}
+	NewResponseTester("GET", "http://example.com/v2/system/channels").WithOutput(&channels).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, []rest.Channel{joined}, channels)
+
+	NewResponseTester("DELETE", "http://example.com/v2/system/channels/testChannels/C001").WithStatus(http.StatusOK).Test(t, router)
+
+	channels = []rest.Channel{}
+	NewResponseTester("GET", "http://example.com/v2/system/channels").WithOutput(&channels).WithStatus(http.StatusOK).Test(t, router)
+	assert.Empty(t, channels)
+
+	// No such adapter
+	NewResponseTester("PUT", "http://example.com/v2/system/channels/noSuchAdapter/C001").WithStatus(http.StatusNotFound).Test(t, router)
+
+	// Provider can't join channels
+	NewResponseTester("PUT", "http://example.com/v2/system/channels/testChannelsUnsupported/C001").WithStatus(http.StatusNotImplemented).Test(t, router)
+}
+
+func TestChannelSettings(t *testing.T) {
+	ctx := context.Background()
+	router := createTestRouter()
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	require.NoError(t, da.BundleCreate(ctx, data.Bundle{GortBundleVersion: 1, Name: "aws", Version: "0.0.1", Description: "A bundle."}))
+	defer da.BundleDelete(ctx, "aws", "0.0.1")
+
+	const url = "http://example.com/v2/system/channels/testSettings/C001/settings"
+
+	settings := data.ChannelSettings{}
+	NewResponseTester("GET", url).WithOutput(&settings).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, data.ChannelSettings{Adapter: "testSettings", ChannelID: "C001"}, settings)
+
+	// The default bundle has to be installed.
+	NewResponseTester("PUT", url).WithBody(data.ChannelSettings{DefaultBundle: "gcp"}).WithStatus(http.StatusNotFound).Test(t, router)
+
+	NewResponseTester("PUT", url).WithBody(data.ChannelSettings{DefaultBundle: "aws"}).WithStatus(http.StatusOK).Test(t, router)
+
+	settings = data.ChannelSettings{}
+	NewResponseTester("GET", url).WithOutput(&settings).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "aws", settings.DefaultBundle)
+
+	// Quiet hours have to be valid.
+	quiet := &data.QuietHours{Start: "22:00", End: "22:00"}
+	NewResponseTester("PUT", url).WithBody(data.ChannelSettings{QuietHours: quiet}).WithStatus(http.StatusBadRequest).Test(t, router)
+
+	quiet = &data.QuietHours{Start: "22:00", End: "07:00", Digest: true}
+	NewResponseTester("PUT", url).WithBody(data.ChannelSettings{DefaultBundle: "aws", QuietHours: quiet}).WithStatus(http.StatusOK).Test(t, router)
+
+	settings = data.ChannelSettings{}
+	NewResponseTester("GET", url).WithOutput(&settings).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, quiet, settings.QuietHours)
+
+	NewResponseTester("DELETE", url).WithStatus(http.StatusOK).Test(t, router)
+
+	settings = data.ChannelSettings{}
+	NewResponseTester("GET", url).WithOutput(&settings).WithStatus(http.StatusOK).Test(t, router)
+	assert.Empty(t, settings.DefaultBundle)
+}
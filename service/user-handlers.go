@@ -17,21 +17,51 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/getgort/gort/data/rest"
-	"github.com/getgort/gort/dataaccess"
+	gerrs "github.com/getgort/gort/errors"
 )
 
+var (
+	// ErrPurgeNotConfirmed is returned by the user purge endpoint if the
+	// request's "confirm" value doesn't match the username being purged.
+	ErrPurgeNotConfirmed = errors.New("user purge not confirmed")
+
+	// ErrNotServiceAccount is returned by the user token endpoint if the
+	// user isn't a service account.
+	ErrNotServiceAccount = errors.New("user isn't a service account")
+)
+
+// defaultServiceAccountTokenDuration is how long a token issued to a service
+// account by "POST /v2/users/{username}/token" is valid, if the request
+// doesn't specify a duration.
+const defaultServiceAccountTokenDuration = 90 * 24 * time.Hour
+
+// DirectMessageFunc sends a direct message to a Gort user through the chat
+// adapters they're mapped to. It's provided by the adapter layer.
+type DirectMessageFunc func(ctx context.Context, user rest.User, m rest.DirectMessage) (rest.DirectMessageResult, error)
+
+var directMessenger DirectMessageFunc
+
+// SetDirectMessenger sets the function used to send direct messages
+// received by "POST /v2/users/{username}/message".
+func SetDirectMessenger(f DirectMessageFunc) {
+	directMessenger = f
+}
+
 // handleDeleteUser handles "DELETE /v2/users/{username}"
 func handleDeleteUser(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -54,7 +84,7 @@ func handleDeleteUserGroup(w http.ResponseWriter, r *http.Request) {
 func handleGetUser(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -79,11 +109,30 @@ func handleGetUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(user)
 }
 
+// handleGetUserExport handles "GET /v2/users/{username}/export"
+func handleGetUserExport(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	export, err := dataAccessLayer.UserExport(r.Context(), params["username"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(export)
+}
+
 // handleGetUserGroups handles "GET /v2/users/{username}/groups"
 func handleGetUserGroups(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -110,7 +159,7 @@ func handleGetUserGroups(w http.ResponseWriter, r *http.Request) {
 
 // handleGetUsers handles "GET /v2/users"
 func handleGetUsers(w http.ResponseWriter, r *http.Request) {
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -129,7 +178,7 @@ func handleGetUsers(w http.ResponseWriter, r *http.Request) {
 func handleGetUserPermissions(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -144,6 +193,106 @@ func handleGetUserPermissions(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(perms)
 }
 
+// handlePostUserMessage handles "POST /v2/users/{username}/message"
+func handlePostUserMessage(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	var m rest.DirectMessage
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	if m.Message == "" {
+		http.Error(w, "message is required", http.StatusBadRequest)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	exists, err := dataAccessLayer.UserExists(r.Context(), params["username"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+	if !exists {
+		http.Error(w, "No such user", http.StatusNotFound)
+		return
+	}
+
+	user, err := dataAccessLayer.UserGet(r.Context(), params["username"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	_, mapped := user.Mappings[m.Adapter]
+	switch {
+	case len(user.Mappings) == 0:
+		http.Error(w, "user isn't mapped to any chat adapter", http.StatusBadRequest)
+		return
+	case m.Adapter != "" && !mapped:
+		http.Error(w, "user isn't mapped to adapter "+m.Adapter, http.StatusBadRequest)
+		return
+	case directMessenger == nil:
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := directMessenger(r.Context(), user, m)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// handlePostUserToken handles "POST /v2/users/{username}/token?duration={duration}"
+// Tokens are only issued this way to service accounts, which have no password
+// to authenticate with. Any token the service account already has is revoked.
+func handlePostUserToken(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	duration := defaultServiceAccountTokenDuration
+	if d := r.URL.Query().Get("duration"); d != "" {
+		var err error
+		if duration, err = time.ParseDuration(d); err != nil || duration <= 0 {
+			http.Error(w, "duration must be a positive duration, like 720h", http.StatusBadRequest)
+			return
+		}
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	user, err := dataAccessLayer.UserGet(r.Context(), params["username"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if !user.ServiceAccount {
+		respondAndLogError(r.Context(), w, ErrNotServiceAccount)
+		return
+	}
+
+	token, err := dataAccessLayer.TokenGenerate(r.Context(), user.Username, duration)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(token)
+}
+
 // handlePutUser handles "POST /v2/users/{username}"
 func handlePutUser(w http.ResponseWriter, r *http.Request) {
 	var user rest.User
@@ -159,7 +308,7 @@ func handlePutUser(w http.ResponseWriter, r *http.Request) {
 
 	user.Username = params["username"]
 
-	dataAccessLayer, err := dataaccess.Get()
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
 	if err != nil {
 		respondAndLogError(r.Context(), w, err)
 		return
@@ -183,6 +332,68 @@ func handlePutUser(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handlePutUserRename handles "PUT /v2/users/{username}/rename/{newname}"
+func handlePutUserRename(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	err = dataAccessLayer.UserRename(r.Context(), params["username"], params["newname"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handlePutUserRestore handles "PUT /v2/users/{username}/restore"
+// Only users deleted within the retention period can be restored.
+func handlePutUserRestore(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	since := time.Now().Add(-deletedRetention())
+	err = dataAccessLayer.UserRestore(r.Context(), params["username"], since)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleDeleteUserPurge handles "DELETE /v2/users/{username}/purge?confirm={username}"
+// Because a purge can't be undone, the request must repeat the username as
+// its "confirm" value.
+func handleDeleteUserPurge(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	if r.URL.Query().Get("confirm") != params["username"] {
+		respondAndLogError(r.Context(), w, ErrPurgeNotConfirmed)
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	result, err := dataAccessLayer.UserPurge(r.Context(), params["username"])
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
 // handlePutUserGroup handles "PUT /v2/users/{username}/groups/{username}"
 func handlePutUserGroup(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Not Implemented", http.StatusNotImplemented)
@@ -193,12 +404,24 @@ func addUserMethodsToRouter(router *mux.Router) {
 	router.Handle("/v2/users/{username}", otelhttp.NewHandler(authCommand(handleGetUser, "user", "info"), "handleGetUser")).Methods("GET")
 	router.Handle("/v2/users/{username}", otelhttp.NewHandler(authCommand(handlePutUser, "user", "update"), "handlePutUser")).Methods("PUT")
 	router.Handle("/v2/users/{username}", otelhttp.NewHandler(authCommand(handleDeleteUser, "user", "delete"), "handleDeleteUser")).Methods("DELETE")
+	router.Handle("/v2/users/{username}/rename/{newname}", otelhttp.NewHandler(authCommand(handlePutUserRename, "user", "rename"), "handlePutUserRename")).Methods("PUT")
+	router.Handle("/v2/users/{username}/restore", otelhttp.NewHandler(authCommand(handlePutUserRestore, "user", "restore"), "handlePutUserRestore")).Methods("PUT")
 
 	// User group membership
 	router.Handle("/v2/users/{username}/groups", otelhttp.NewHandler(authCommand(handleGetUserGroups, "user", "info"), "handleGetUserGroups")).Methods("GET")
 	router.Handle("/v2/users/{username}/groups/{username}", otelhttp.NewHandler(authCommand(handleDeleteUserGroup, "user", "update"), "handleDeleteUserGroup")).Methods("DELETE")
 	router.Handle("/v2/users/{username}/groups/{username}", otelhttp.NewHandler(authCommand(handlePutUserGroup, "user", "update"), "handlePutUserGroup")).Methods("PUT")
 
+	// Personal data export and purge
+	router.Handle("/v2/users/{username}/export", otelhttp.NewHandler(authCommand(handleGetUserExport, "user", "purge"), "handleGetUserExport")).Methods("GET")
+	router.Handle("/v2/users/{username}/purge", otelhttp.NewHandler(authCommand(handleDeleteUserPurge, "user", "purge"), "handleDeleteUserPurge")).Methods("DELETE")
+
+	// Service account tokens
+	router.Handle("/v2/users/{username}/token", otelhttp.NewHandler(authCommand(handlePostUserToken, "user", "token"), "handlePostUserToken")).Methods("POST")
+
+	// Direct messages
+	router.Handle("/v2/users/{username}/message", otelhttp.NewHandler(authCommand(handlePostUserMessage, "dm"), "handlePostUserMessage")).Methods("POST")
+
 	// User permissions list
 	router.Handle("/v2/users/{username}/permissions", otelhttp.NewHandler(authCommand(handleGetUserPermissions, "user", "info"), "handleGetUserPermissions")).Methods("GET")
 }
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/getgort/gort/data/rest"
+)
+
+func TestUserMessage(t *testing.T) {
+	router := createTestRouter()
+
+	user := rest.User{Username: "userTestUserMessage", Email: "message@example.com", Mappings: map[string]string{"slack": "U0001"}}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserMessage").WithBody(user).WithStatus(http.StatusOK).Test(t, router)
+
+	unmapped := rest.User{Username: "userTestUserMessageUnmapped", Email: "unmapped@example.com"}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserMessageUnmapped").WithBody(unmapped).WithStatus(http.StatusOK).Test(t, router)
+
+	message := rest.DirectMessage{Message: "Your deploy finished"}
+
+	// No adapters are available.
+	NewResponseTester("POST", "http://example.com/v2/users/userTestUserMessage/message").WithBody(message).WithStatus(http.StatusServiceUnavailable).Test(t, router)
+
+	var received rest.DirectMessage
+	var receivedUser rest.User
+	SetDirectMessenger(func(ctx context.Context, u rest.User, m rest.DirectMessage) (rest.DirectMessageResult, error) {
+		receivedUser, received = u, m
+		return rest.DirectMessageResult{
+			Sent: []rest.DirectMessageTarget{{Adapter: "slack", UserID: "U0001", ChannelID: "D0001"}},
+		}, nil
+	})
+	defer SetDirectMessenger(nil)
+
+	result := rest.DirectMessageResult{}
+	NewResponseTester("POST", "http://example.com/v2/users/userTestUserMessage/message").WithBody(message).WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, message, received)
+	assert.Equal(t, "userTestUserMessage", receivedUser.Username)
+	if assert.Len(t, result.Sent, 1) {
+		assert.Equal(t, "D0001", result.Sent[0].ChannelID)
+	}
+
+	// No message
+	NewResponseTester("POST", "http://example.com/v2/users/userTestUserMessage/message").WithBody(rest.DirectMessage{}).WithStatus(http.StatusBadRequest).Test(t, router)
+
+	// Not mapped to the requested adapter, or to any adapter
+	NewResponseTester("POST", "http://example.com/v2/users/userTestUserMessage/message").WithBody(rest.DirectMessage{Message: "Hi", Adapter: "discord"}).WithStatus(http.StatusBadRequest).Test(t, router)
+	NewResponseTester("POST", "http://example.com/v2/users/userTestUserMessageUnmapped/message").WithBody(message).WithStatus(http.StatusBadRequest).Test(t, router)
+
+	NewResponseTester("POST", "http://example.com/v2/users/noSuchUser/message").WithBody(message).WithStatus(http.StatusNotFound).Test(t, router)
+}
+
+func TestUserPurge(t *testing.T) {
+	router := createTestRouter()
+
+	user := rest.User{Username: "userTestUserPurge", Email: "purge@example.com", Mappings: map[string]string{"slack": "U0001"}}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserPurge").WithBody(user).WithStatus(http.StatusOK).Test(t, router)
+
+	export := rest.UserDataExport{}
+	NewResponseTester("GET", "http://example.com/v2/users/userTestUserPurge/export").WithOutput(&export).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "purge@example.com", export.User.Email)
+	assert.Equal(t, map[string]string{"slack": "U0001"}, export.User.Mappings)
+
+	// Purges must be confirmed.
+	NewResponseTester("DELETE", "http://example.com/v2/users/userTestUserPurge/purge").WithStatus(http.StatusPreconditionFailed).Test(t, router)
+	NewResponseTester("DELETE", "http://example.com/v2/users/userTestUserPurge/purge?confirm=someoneElse").WithStatus(http.StatusPreconditionFailed).Test(t, router)
+
+	result := rest.UserPurgeResult{}
+	NewResponseTester("DELETE", "http://example.com/v2/users/userTestUserPurge/purge?confirm=userTestUserPurge").WithOutput(&result).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "userTestUserPurge", result.Username)
+	assert.NotEmpty(t, result.Pseudonym)
+
+	NewResponseTester("GET", "http://example.com/v2/users/userTestUserPurge").WithStatus(http.StatusNotFound).Test(t, router)
+	NewResponseTester("DELETE", "http://example.com/v2/users/admin/purge?confirm=admin").WithStatus(http.StatusForbidden).Test(t, router)
+}
+
+func TestUserRestore(t *testing.T) {
+	router := createTestRouter()
+
+	user := rest.User{Username: "userTestUserRestore", Email: "restore@example.com"}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserRestore").WithBody(user).WithStatus(http.StatusOK).Test(t, router)
+
+	// Only deleted users can be restored.
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserRestore/restore").WithStatus(http.StatusNotFound).Test(t, router)
+
+	NewResponseTester("DELETE", "http://example.com/v2/users/userTestUserRestore").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/users/userTestUserRestore").WithStatus(http.StatusNotFound).Test(t, router)
+
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserRestore/restore").WithStatus(http.StatusOK).Test(t, router)
+
+	restored := rest.User{}
+	NewResponseTester("GET", "http://example.com/v2/users/userTestUserRestore").WithOutput(&restored).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "restore@example.com", restored.Email)
+}
+
+func TestUserToken(t *testing.T) {
+	router := createTestRouter()
+
+	human := rest.User{Username: "userTestUserTokenHuman", Email: "human@example.com", Password: "password"}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserTokenHuman").WithBody(human).WithStatus(http.StatusOK).Test(t, router)
+
+	// Service accounts can't have passwords.
+	invalid := rest.User{Username: "userTestUserToken", ServiceAccount: true, Password: "password"}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserToken").WithBody(invalid).WithStatus(http.StatusForbidden).Test(t, router)
+
+	bot := rest.User{Username: "userTestUserToken", Email: "bot@example.com", ServiceAccount: true}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserToken").WithBody(bot).WithStatus(http.StatusOK).Test(t, router)
+
+	token := rest.Token{}
+	NewResponseTester("POST", "http://example.com/v2/users/userTestUserToken/token?duration=1h").WithOutput(&token).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "userTestUserToken", token.User)
+	assert.NotEmpty(t, token.Token)
+	assert.WithinDuration(t, token.ValidFrom.Add(time.Hour), token.ValidUntil, time.Second)
+
+	// Tokens are only issued this way to service accounts.
+	NewResponseTester("POST", "http://example.com/v2/users/userTestUserTokenHuman/token").WithStatus(http.StatusForbidden).Test(t, router)
+	NewResponseTester("POST", "http://example.com/v2/users/noSuchUser/token").WithStatus(http.StatusNotFound).Test(t, router)
+	NewResponseTester("POST", "http://example.com/v2/users/userTestUserToken/token?duration=-1h").WithStatus(http.StatusBadRequest).Test(t, router)
+}
+
+func TestUserRename(t *testing.T) {
+	router := createTestRouter()
+
+	user := rest.User{Username: "userTestUserRename", Email: "rename@example.com"}
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserRename").WithBody(user).WithStatus(http.StatusOK).Test(t, router)
+
+	group := rest.Group{Name: "groupTestUserRename"}
+	NewResponseTester("PUT", "http://example.com/v2/groups/groupTestUserRename").WithBody(group).WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/groups/groupTestUserRename/members/userTestUserRename").WithStatus(http.StatusOK).Test(t, router)
+
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserRename/rename/admin").WithStatus(http.StatusConflict).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/users/admin/rename/someoneElse").WithStatus(http.StatusForbidden).Test(t, router)
+	NewResponseTester("PUT", "http://example.com/v2/users/noSuchUser/rename/someoneElse").WithStatus(http.StatusNotFound).Test(t, router)
+
+	NewResponseTester("PUT", "http://example.com/v2/users/userTestUserRename/rename/userTestUserRenamed").WithStatus(http.StatusOK).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/users/userTestUserRename").WithStatus(http.StatusNotFound).Test(t, router)
+
+	renamed := rest.User{}
+	NewResponseTester("GET", "http://example.com/v2/users/userTestUserRenamed").WithOutput(&renamed).WithStatus(http.StatusOK).Test(t, router)
+	assert.Equal(t, "rename@example.com", renamed.Email)
+
+	members := []rest.User{}
+	NewResponseTester("GET", "http://example.com/v2/groups/groupTestUserRename/members").WithOutput(&members).WithStatus(http.StatusOK).Test(t, router)
+	if assert.Len(t, members, 1) {
+		assert.Equal(t, "userTestUserRenamed", members[0].Username)
+	}
+}
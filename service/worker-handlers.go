@@ -0,0 +1,220 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/getgort/gort/auth"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	gerrs "github.com/getgort/gort/errors"
+)
+
+// workerPathPrefix is the path under which the worker API is served. These
+// endpoints are meant for running commands, and are protected by the
+// request-scoped worker token that each command is given in
+// GORT_WORKER_TOKEN rather than by a session token.
+const workerPathPrefix = "/v2/worker/"
+
+// RespondFunc sends an interim message from a running command to the channel
+// that its request came from. It's provided by the adapter layer.
+type RespondFunc func(ctx context.Context, record data.RequestRecord, message string) error
+
+var responder RespondFunc
+
+// SetResponder sets the function used to send the messages received by
+// "POST /v2/worker/respond".
+func SetResponder(f RespondFunc) {
+	responder = f
+}
+
+// workerClaimsKey is the context key of the claims of the worker token that
+// a worker API request was made with.
+type workerClaimsKey struct{}
+
+// authWorker wraps a worker API handler, rejecting any request that doesn't
+// carry a valid worker token as a bearer token in its Authorization header.
+// The token's claims are available to the handler through workerClaims.
+func authWorker(handler func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	inner := func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, prefix) {
+			respondAndLogError(r.Context(), w, ErrUnauthorized)
+			return
+		}
+
+		claims, err := auth.VerifyWorkerToken(strings.TrimPrefix(header, prefix))
+		if err != nil {
+			respondAndLogError(r.Context(), w, gerrs.Wrap(ErrUnauthorized, err))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), workerClaimsKey{}, claims)
+		handler(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(inner)
+}
+
+// workerClaims returns the claims of the worker token that the request was
+// authenticated with by authWorker.
+func workerClaims(r *http.Request) auth.WorkerClaims {
+	claims, _ := r.Context().Value(workerClaimsKey{}).(auth.WorkerClaims)
+	return claims
+}
+
+// workerRequest returns the record of the request that the worker token was
+// issued for. If the request has already completed, its worker token is no
+// longer useful, so an error is written to w and false is returned.
+func workerRequest(w http.ResponseWriter, r *http.Request) (data.RequestRecord, bool) {
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return data.RequestRecord{}, false
+	}
+
+	record, err := dataAccessLayer.RequestGet(r.Context(), workerClaims(r).RequestID)
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return data.RequestRecord{}, false
+	}
+
+	if record.Closed {
+		http.Error(w, "request has already completed", http.StatusConflict)
+		return data.RequestRecord{}, false
+	}
+
+	return record, true
+}
+
+// handleGetWorkerWhoami handles "GET /v2/worker/whoami"
+func handleGetWorkerWhoami(w http.ResponseWriter, r *http.Request) {
+	record, ok := workerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	json.NewEncoder(w).Encode(rest.WorkerRequest{
+		RequestID:     record.RequestID,
+		Timestamp:     record.Timestamp,
+		ExpiresAt:     time.Unix(workerClaims(r).ExpiresAt, 0).UTC(),
+		Adapter:       record.Adapter,
+		ChannelID:     record.ChannelID,
+		User:          record.UserName,
+		UserID:        record.UserID,
+		UserEmail:     record.UserEmail,
+		Bundle:        record.BundleName,
+		BundleVersion: record.BundleVersion,
+		Command:       record.CommandName,
+		Parameters:    record.Parameters,
+	})
+}
+
+// handlePostWorkerRespond handles "POST /v2/worker/respond"
+func handlePostWorkerRespond(w http.ResponseWriter, r *http.Request) {
+	var m rest.WorkerResponse
+
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	if strings.TrimSpace(m.Message) == "" {
+		http.Error(w, "message is empty", http.StatusBadRequest)
+		return
+	}
+
+	record, ok := workerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if responder == nil {
+		http.Error(w, "no chat adapters are available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := responder(r.Context(), record, m.Message); err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handlePostWorkerLog handles "POST /v2/worker/log"
+// The entry is attached to the request; its timestamp is the time that it
+// was received.
+func handlePostWorkerLog(w http.ResponseWriter, r *http.Request) {
+	var entry data.RequestLogEntry
+
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		respondAndLogError(r.Context(), w, gerrs.ErrUnmarshal)
+		return
+	}
+
+	entry.Timestamp = time.Now().UTC()
+	if err := entry.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	record, ok := workerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	dataAccessLayer, err := getRequestContext(r).DataAccess()
+	if err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+
+	if err := dataAccessLayer.RequestLogAppend(r.Context(), record.RequestID, entry); err != nil {
+		respondAndLogError(r.Context(), w, err)
+		return
+	}
+}
+
+// handleGetWorkerToken handles "GET /v2/worker/token"
+func handleGetWorkerToken(w http.ResponseWriter, r *http.Request) {
+	claims := workerClaims(r)
+
+	json.NewEncoder(w).Encode(rest.WorkerToken{
+		RequestID: claims.RequestID,
+		User:      claims.Subject,
+		Bundle:    claims.Bundle,
+		Command:   claims.Command,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0).UTC(),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC(),
+	})
+}
+
+func addWorkerMethodsToRouter(router *mux.Router) {
+	router.Handle(workerPathPrefix+"log", otelhttp.NewHandler(authWorker(handlePostWorkerLog), "handlePostWorkerLog")).Methods("POST")
+	router.Handle(workerPathPrefix+"respond", otelhttp.NewHandler(authWorker(handlePostWorkerRespond), "handlePostWorkerRespond")).Methods("POST")
+	router.Handle(workerPathPrefix+"token", otelhttp.NewHandler(authWorker(handleGetWorkerToken), "handleGetWorkerToken")).Methods("GET")
+	router.Handle(workerPathPrefix+"whoami", otelhttp.NewHandler(authWorker(handleGetWorkerWhoami), "handleGetWorkerWhoami")).Methods("GET")
+}
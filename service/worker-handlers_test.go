@@ -0,0 +1,176 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/auth"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
+)
+
+// beginWorkerRequest begins a request, and returns it with a worker token
+// issued for it.
+func beginWorkerRequest(t *testing.T) (data.CommandRequest, string) {
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	request := data.CommandRequest{
+		CommandEntry: data.CommandEntry{
+			Bundle:  data.Bundle{Name: "test", Version: "0.0.1"},
+			Command: data.BundleCommand{Name: "echo"},
+		},
+		Adapter:   "testAdapter",
+		ChannelID: "C001",
+		Deadline:  time.Now().Add(time.Minute),
+		Timestamp: time.Now(),
+		UserID:    "U001",
+		UserName:  "admin",
+	}
+	require.NoError(t, da.RequestBegin(context.Background(), &request))
+
+	token, err := auth.SignWorkerToken(request)
+	require.NoError(t, err)
+
+	return request, token
+}
+
+func TestGetWorkerToken(t *testing.T) {
+	router := createTestRouter()
+
+	deadline := time.Now().Add(time.Minute).Truncate(time.Second)
+
+	token, err := auth.SignWorkerToken(data.CommandRequest{
+		CommandEntry: data.CommandEntry{
+			Bundle:  data.Bundle{Name: "test"},
+			Command: data.BundleCommand{Name: "echo"},
+		},
+		Deadline:  deadline,
+		RequestID: 42,
+		UserName:  "admin",
+	})
+	require.NoError(t, err)
+
+	info := rest.WorkerToken{}
+	NewResponseTester("GET", "http://example.com/v2/worker/token").WithHeader("Authorization", "Bearer "+token).WithOutput(&info).WithStatus(http.StatusOK).Test(t, router)
+
+	assert.Equal(t, int64(42), info.RequestID)
+	assert.Equal(t, "admin", info.User)
+	assert.Equal(t, "test", info.Bundle)
+	assert.Equal(t, "echo", info.Command)
+	assert.True(t, deadline.Equal(info.ExpiresAt))
+
+	// A session token isn't accepted in place of a worker token
+	NewResponseTester("GET", "http://example.com/v2/worker/token").WithStatus(http.StatusUnauthorized).Test(t, router)
+	NewResponseTester("GET", "http://example.com/v2/worker/token").WithHeader("Authorization", "Bearer "+adminToken.Token).WithStatus(http.StatusUnauthorized).Test(t, router)
+}
+
+func TestGetWorkerTokenExpired(t *testing.T) {
+	router := createTestRouter()
+
+	token, err := auth.SignWorkerToken(data.CommandRequest{
+		Deadline:  time.Now().Add(-time.Second),
+		RequestID: 42,
+		UserName:  "admin",
+	})
+	require.NoError(t, err)
+
+	NewResponseTester("GET", "http://example.com/v2/worker/token").WithHeader("Authorization", "Bearer "+token).WithStatus(http.StatusUnauthorized).Test(t, router)
+}
+
+func TestGetWorkerWhoami(t *testing.T) {
+	router := createTestRouter()
+
+	request, token := beginWorkerRequest(t)
+
+	whoami := rest.WorkerRequest{}
+	NewResponseTester("GET", "http://example.com/v2/worker/whoami").WithHeader("Authorization", "Bearer "+token).WithOutput(&whoami).WithStatus(http.StatusOK).Test(t, router)
+
+	assert.Equal(t, request.RequestID, whoami.RequestID)
+	assert.Equal(t, "testAdapter", whoami.Adapter)
+	assert.Equal(t, "C001", whoami.ChannelID)
+	assert.Equal(t, "admin", whoami.User)
+	assert.Equal(t, "U001", whoami.UserID)
+	assert.Equal(t, "test", whoami.Bundle)
+	assert.Equal(t, "0.0.1", whoami.BundleVersion)
+	assert.Equal(t, "echo", whoami.Command)
+
+	// The token is no longer useful once its request has completed
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+	require.NoError(t, da.RequestClose(context.Background(), data.NewCommandResponseEnvelope(request)))
+
+	NewResponseTester("GET", "http://example.com/v2/worker/whoami").WithHeader("Authorization", "Bearer "+token).WithStatus(http.StatusConflict).Test(t, router)
+}
+
+func TestPostWorkerRespond(t *testing.T) {
+	router := createTestRouter()
+
+	request, token := beginWorkerRequest(t)
+
+	var received data.RequestRecord
+	var message string
+	SetResponder(func(ctx context.Context, record data.RequestRecord, m string) error {
+		received, message = record, m
+		return nil
+	})
+	defer SetResponder(nil)
+
+	NewResponseTester("POST", "http://example.com/v2/worker/respond").WithHeader("Authorization", "Bearer "+token).WithBody(rest.WorkerResponse{Message: "Working on it..."}).WithStatus(http.StatusOK).Test(t, router)
+
+	assert.Equal(t, request.RequestID, received.RequestID)
+	assert.Equal(t, "C001", received.ChannelID)
+	assert.Equal(t, "Working on it...", message)
+
+	// No message
+	NewResponseTester("POST", "http://example.com/v2/worker/respond").WithHeader("Authorization", "Bearer "+token).WithBody(rest.WorkerResponse{}).WithStatus(http.StatusBadRequest).Test(t, router)
+
+	// No worker token
+	NewResponseTester("POST", "http://example.com/v2/worker/respond").WithBody(rest.WorkerResponse{Message: "Hello"}).WithStatus(http.StatusUnauthorized).Test(t, router)
+}
+
+func TestPostWorkerLog(t *testing.T) {
+	router := createTestRouter()
+
+	request, token := beginWorkerRequest(t)
+
+	entry := data.RequestLogEntry{Level: data.RequestLogWarn, Message: "retrying", Fields: map[string]string{"attempt": "2"}}
+	NewResponseTester("POST", "http://example.com/v2/worker/log").WithHeader("Authorization", "Bearer "+token).WithBody(entry).WithStatus(http.StatusOK).Test(t, router)
+
+	// Unknown level
+	entry.Level = "fatal"
+	NewResponseTester("POST", "http://example.com/v2/worker/log").WithHeader("Authorization", "Bearer "+token).WithBody(entry).WithStatus(http.StatusBadRequest).Test(t, router)
+
+	da, err := dataaccess.Get()
+	require.NoError(t, err)
+
+	record, err := da.RequestGet(context.Background(), request.RequestID)
+	require.NoError(t, err)
+	require.Len(t, record.Logs, 1)
+	assert.Equal(t, data.RequestLogWarn, record.Logs[0].Level)
+	assert.Equal(t, "retrying", record.Logs[0].Message)
+	assert.Equal(t, map[string]string{"attempt": "2"}, record.Logs[0].Fields)
+	assert.False(t, record.Logs[0].Timestamp.IsZero())
+}
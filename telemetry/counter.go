@@ -74,6 +74,12 @@ func (c *MetricCounter) WithAttribute(key string, value interface{}) *MetricCoun
 	return c
 }
 
+// WithAttributes adds already-constructed attributes to the counter.
+func (c *MetricCounter) WithAttributes(attributes ...attribute.KeyValue) *MetricCounter {
+	c.attributes = append(c.attributes, attributes...)
+	return c
+}
+
 func (c *MetricCounter) WithEntryFields(e log.Entry) *MetricCounter {
 	for k, v := range e.Data {
 		c.WithAttribute(k, v)
@@ -77,6 +77,71 @@ func buildCounters() error {
 		return err
 	}
 
+	countSlowStages, err = meter.NewInt64Counter("gort_controller_slow_stages_total",
+		metric.WithDescription("Number of command request stages that exceeded their latency budget."),
+	)
+	if err != nil {
+		return err
+	}
+
+	countKubernetesJobs, err = meter.NewInt64Counter("gort_controller_kubernetes_jobs_total",
+		metric.WithDescription("Number of Kubernetes jobs dispatched, by cluster."),
+	)
+	if err != nil {
+		return err
+	}
+
+	countKubernetesHealthCheckFailures, err = meter.NewInt64Counter("gort_controller_kubernetes_health_check_failures_total",
+		metric.WithDescription("Number of failed Kubernetes cluster health checks, by cluster."),
+	)
+	if err != nil {
+		return err
+	}
+
+	countCanaryRuns, err = meter.NewInt64Counter("gort_controller_canary_runs_total",
+		metric.WithDescription("Number of canary executions, by canary and success."),
+	)
+	if err != nil {
+		return err
+	}
+
+	recordCanaryDurations, err = meter.NewFloat64ValueRecorder("gort_controller_canary_duration_milliseconds",
+		metric.WithDescription("Duration of canary executions, by canary."),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return err
+	}
+
+	countProviderInfoLookups, err = meter.NewInt64Counter("gort_controller_provider_info_lookups_total",
+		metric.WithDescription("Number of chat provider user and channel info lookups, by adapter, kind, and cache hit."),
+	)
+	if err != nil {
+		return err
+	}
+
+	countJanitorPurged, err = meter.NewInt64Counter("gort_controller_janitor_purged_total",
+		metric.WithDescription("Number of expired entries removed by the janitor, by kind."),
+	)
+	if err != nil {
+		return err
+	}
+
+	countCommandExecutions, err = meter.NewInt64Counter("gort_controller_command_executions_total",
+		metric.WithDescription("Number of command executions, by bundle, command, exit code, and bundle telemetry attributes."),
+	)
+	if err != nil {
+		return err
+	}
+
+	recordCommandDurations, err = meter.NewFloat64ValueRecorder("gort_controller_command_duration_milliseconds",
+		metric.WithDescription("Duration of command executions, by bundle, command, and bundle telemetry attributes."),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
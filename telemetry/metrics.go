@@ -17,7 +17,13 @@
 package telemetry
 
 import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+
+	"github.com/getgort/gort/data"
 )
 
 // The error counter instrument.
@@ -43,3 +49,98 @@ var countTotalRequests metric.Int64Counter
 func TotalRequests() *MetricCounter {
 	return newCounter(countTotalRequests)
 }
+
+// The slow stages counter instrument.
+var countSlowStages metric.Int64Counter
+
+// SlowStages increments the counter of request stages that exceeded their
+// latency budget.
+func SlowStages() *MetricCounter {
+	return newCounter(countSlowStages)
+}
+
+// The Kubernetes jobs counter instrument.
+var countKubernetesJobs metric.Int64Counter
+
+// KubernetesJobs increments the counter of Kubernetes jobs dispatched. It's
+// expected to be labeled with the cluster that the job was dispatched to.
+func KubernetesJobs() *MetricCounter {
+	return newCounter(countKubernetesJobs)
+}
+
+// The Kubernetes health check failures counter instrument.
+var countKubernetesHealthCheckFailures metric.Int64Counter
+
+// KubernetesHealthCheckFailures increments the counter of failed
+// Kubernetes cluster health checks.
+func KubernetesHealthCheckFailures() *MetricCounter {
+	return newCounter(countKubernetesHealthCheckFailures)
+}
+
+// The canary runs counter instrument.
+var countCanaryRuns metric.Int64Counter
+
+// CanaryRuns increments the counter of canary executions. It's expected to be
+// labeled with the canary's name and whether it succeeded.
+func CanaryRuns() *MetricCounter {
+	return newCounter(countCanaryRuns)
+}
+
+// The canary durations recorder instrument.
+var recordCanaryDurations metric.Float64ValueRecorder
+
+// CanaryDuration records how long an execution of the named canary took.
+func CanaryDuration(ctx context.Context, canary string, d time.Duration) {
+	attributes := append([]attribute.KeyValue{attribute.Key("canary").String(canary)}, defaultLabels...)
+	recordCanaryDurations.Record(ctx, float64(d)/float64(time.Millisecond), attributes...)
+}
+
+// The provider info lookups counter instrument.
+var countProviderInfoLookups metric.Int64Counter
+
+// ProviderInfoLookups increments the counter of user and channel info
+// lookups. It's expected to be labeled with the adapter, the kind of info,
+// and whether it was served from the cache.
+func ProviderInfoLookups() *MetricCounter {
+	return newCounter(countProviderInfoLookups)
+}
+
+// The janitor purged entries counter instrument.
+var countJanitorPurged metric.Int64Counter
+
+// JanitorPurged increments the counter of entries removed by the janitor.
+// It's expected to be labeled with the kind of entry purged.
+func JanitorPurged() *MetricCounter {
+	return newCounter(countJanitorPurged)
+}
+
+// The command executions counter instrument.
+var countCommandExecutions metric.Int64Counter
+
+// CommandExecutions increments the counter of command executions. It's
+// expected to be labeled with the bundle, command, and exit code, and with
+// the bundle's telemetry attributes.
+func CommandExecutions() *MetricCounter {
+	return newCounter(countCommandExecutions)
+}
+
+// The command durations recorder instrument.
+var recordCommandDurations metric.Float64ValueRecorder
+
+// CommandDuration records how long a command execution took. The attributes
+// are expected to identify the bundle and command.
+func CommandDuration(ctx context.Context, d time.Duration, attributes ...attribute.KeyValue) {
+	attributes = append(append([]attribute.KeyValue{}, attributes...), defaultLabels...)
+	recordCommandDurations.Record(ctx, float64(d)/float64(time.Millisecond), attributes...)
+}
+
+// BundleAttributes converts a bundle's telemetry attributes, in key order,
+// into span and metric attributes.
+func BundleAttributes(t data.TelemetryAttributes) []attribute.KeyValue {
+	attributes := make([]attribute.KeyValue, 0, len(t))
+	for _, k := range t.Keys() {
+		attributes = append(attributes, attribute.Key(k).String(t[k]))
+	}
+
+	return attributes
+}
@@ -25,12 +25,23 @@ import (
 	"github.com/Masterminds/sprig"
 )
 
-type Functions struct{}
+type Functions struct {
+	// locale is used by the functions that format numbers; empty is "en".
+	locale string
+}
 
 func FunctionMap() template.FuncMap {
-	functions := &Functions{}
+	return functionMap(&Functions{})
+}
 
+func functionMap(functions *Functions) template.FuncMap {
 	fm := map[string]interface{}{
+		// Actions
+		"button":  functions.ButtonFunction,
+		"style":   functions.ButtonStyleFunction,
+		"select":  functions.SelectFunction,
+		"confirm": functions.ConfirmFunction,
+
 		// Header
 		"header": functions.HeaderFunction,
 		"color":  functions.HeaderColorFunction,
@@ -43,6 +54,16 @@ func FunctionMap() template.FuncMap {
 		"section":    functions.SectionFunction,
 		"endsection": functions.SectionEndFunction,
 
+		// Formatting
+		"humanBytes":    functions.HumanBytesFunction,
+		"humanDuration": functions.HumanDurationFunction,
+		"number":        functions.NumberFunction,
+
+		// Table
+		"table":   functions.TableFunction,
+		"columns": functions.TableColumnsFunction,
+		"sortby":  functions.TableSortFunction,
+
 		// Text
 		"text":      functions.TextFunction,
 		"inline":    functions.TextInlineFunction,
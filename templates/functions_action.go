@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Button is an interactive element that, when clicked, executes Command as
+// though the clicking user had typed it.
+type Button struct {
+	Tag
+
+	// Command is the command line to execute when clicked, as "bundle:command
+	// args...". Required.
+	Command string
+
+	// Confirm, if set, is the text of a confirmation dialog shown before the
+	// command is executed.
+	Confirm string `json:",omitempty"`
+
+	Label string
+
+	// Style may be "primary", "danger", or empty for the default style.
+	Style string `json:",omitempty"`
+}
+
+func (o *Button) String() string {
+	return encodeTag(*o)
+}
+
+func (o *Button) Alt() string {
+	return fmt.Sprintf("%s: !%s", o.Label, o.Command)
+}
+
+// Select is an interactive menu element. When an option is chosen, Command
+// is executed with the selected option appended as its final parameter.
+type Select struct {
+	Tag
+
+	// Command is the command line to execute when an option is chosen, as
+	// "bundle:command args...". Required.
+	Command string
+
+	// Confirm, if set, is the text of a confirmation dialog shown before the
+	// command is executed.
+	Confirm string `json:",omitempty"`
+
+	Options []string
+
+	Placeholder string `json:",omitempty"`
+}
+
+func (o *Select) String() string {
+	return encodeTag(*o)
+}
+
+func (o *Select) Alt() string {
+	return fmt.Sprintf("%s: !%s <%s>", o.Placeholder, o.Command, strings.Join(o.Options, "|"))
+}
+
+func (f *Functions) ButtonFunction(label, command string) (*Button, error) {
+	if command == "" {
+		return nil, fmt.Errorf("button %q has no command", label)
+	}
+
+	return &Button{Label: label, Command: command}, nil
+}
+
+func (f *Functions) ButtonStyleFunction(s string, b *Button) (*Button, error) {
+	switch s {
+	case "", "primary", "danger":
+		b.Style = s
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unsupported button style %q: must be primary or danger", s)
+	}
+}
+
+func (f *Functions) SelectFunction(placeholder, command string, options ...string) (*Select, error) {
+	if command == "" {
+		return nil, fmt.Errorf("select %q has no command", placeholder)
+	}
+	if len(options) == 0 {
+		return nil, fmt.Errorf("select %q has no options", placeholder)
+	}
+
+	return &Select{Placeholder: placeholder, Command: command, Options: options}, nil
+}
+
+func (f *Functions) ConfirmFunction(s string, i interface{}) (interface{}, error) {
+	switch t := i.(type) {
+	case *Button:
+		t.Confirm = s
+		return t, nil
+	case *Select:
+		t.Confirm = s
+		return t, nil
+	default:
+		return nil, fmt.Errorf("%T does not support the confirm function", t)
+	}
+}
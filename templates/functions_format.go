@@ -0,0 +1,231 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberFormat describes how a locale writes numbers.
+type numberFormat struct {
+	group   string
+	decimal string
+}
+
+var (
+	defaultNumberFormat = numberFormat{group: ",", decimal: "."}
+
+	// numberFormats maps lower-cased locale tags, or just their language
+	// subtags, to their number formats. Locales not listed here use
+	// defaultNumberFormat.
+	numberFormats = map[string]numberFormat{
+		"en":    defaultNumberFormat,
+		"ja":    defaultNumberFormat,
+		"ko":    defaultNumberFormat,
+		"zh":    defaultNumberFormat,
+		"da":    {group: ".", decimal: ","},
+		"de":    {group: ".", decimal: ","},
+		"de-ch": {group: "\u2019", decimal: "."},
+		"es":    {group: ".", decimal: ","},
+		"id":    {group: ".", decimal: ","},
+		"it":    {group: ".", decimal: ","},
+		"nl":    {group: ".", decimal: ","},
+		"pt":    {group: ".", decimal: ","},
+		"tr":    {group: ".", decimal: ","},
+		"cs":    {group: "\u00a0", decimal: ","},
+		"fi":    {group: "\u00a0", decimal: ","},
+		"fr":    {group: "\u202f", decimal: ","},
+		"nb":    {group: "\u00a0", decimal: ","},
+		"pl":    {group: "\u00a0", decimal: ","},
+		"ru":    {group: "\u00a0", decimal: ","},
+		"sv":    {group: "\u00a0", decimal: ","},
+		"uk":    {group: "\u00a0", decimal: ","},
+	}
+)
+
+// lookupNumberFormat returns the number format for a locale like "en-US" or
+// "pt_BR", falling back to its language and then to defaultNumberFormat.
+func lookupNumberFormat(locale string) numberFormat {
+	tag := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+
+	if nf, ok := numberFormats[tag]; ok {
+		return nf
+	}
+
+	if i := strings.Index(tag, "-"); i > 0 {
+		if nf, ok := numberFormats[tag[:i]]; ok {
+			return nf
+		}
+	}
+
+	return defaultNumberFormat
+}
+
+// format writes s, a number as formatted by strconv, using nf's separators.
+func (nf numberFormat) format(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(nf.group)
+		}
+		b.WriteRune(r)
+	}
+
+	if frac != "" {
+		b.WriteString(nf.decimal)
+		b.WriteString(frac)
+	}
+
+	return b.String()
+}
+
+// NumberFunction formats a number with the request locale's digit grouping
+// and decimal separator, like "1,234,567.5" or "1.234.567,5".
+func (f *Functions) NumberFunction(v interface{}) (string, error) {
+	n, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	return lookupNumberFormat(f.locale).format(strconv.FormatFloat(n, 'f', -1, 64)), nil
+}
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// HumanBytesFunction formats a byte count using binary units, like "512 B"
+// or "1.5 MiB". Sizes above 1 KiB are rounded to one decimal place.
+func (f *Functions) HumanBytesFunction(v interface{}) (string, error) {
+	n, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	unit := 0
+	for math.Abs(n) >= 1024 && unit < len(byteUnits)-1 {
+		n /= 1024
+		unit++
+	}
+
+	s := strconv.FormatFloat(math.Round(n*10)/10, 'f', -1, 64)
+
+	return lookupNumberFormat(f.locale).format(s) + " " + byteUnits[unit], nil
+}
+
+// HumanDurationFunction formats a duration using at most its two most
+// significant units, like "3m42s" or "2d5h". Durations under a second are
+// shown in milliseconds. The value may be a time.Duration, a duration string
+// like "1h30m", or a number of seconds.
+func (f *Functions) HumanDurationFunction(v interface{}) (string, error) {
+	d, err := toDuration(v)
+	if err != nil {
+		return "", err
+	}
+
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	if d < time.Second {
+		return sign + d.Round(time.Millisecond).String(), nil
+	}
+
+	d = d.Round(time.Second)
+
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	for i, u := range units {
+		if d < u.size {
+			continue
+		}
+
+		out := fmt.Sprintf("%s%d%s", sign, d/u.size, u.suffix)
+
+		if i+1 < len(units) {
+			next := units[i+1]
+			if n := (d % u.size) / next.size; n > 0 {
+				out += fmt.Sprintf("%d%s", n, next.suffix)
+			}
+		}
+
+		return out, nil
+	}
+
+	return sign + "0s", nil
+}
+
+func toDuration(v interface{}) (time.Duration, error) {
+	switch t := v.(type) {
+	case time.Duration:
+		return t, nil
+	case string:
+		if d, err := time.ParseDuration(t); err == nil {
+			return d, nil
+		}
+	}
+
+	n, err := toFloat(v)
+	if err != nil {
+		return 0, fmt.Errorf("can't use %v (%T) as a duration", v, v)
+	}
+
+	return time.Duration(n * float64(time.Second)), nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		if n, err := strconv.ParseFloat(strings.TrimSpace(rv.String()), 64); err == nil {
+			return n, nil
+		}
+	}
+
+	return 0, fmt.Errorf("can't use %v (%T) as a number", v, v)
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Table renders structured data as a table. Adapters decide how best to
+// display it; its Alt text is a column-aligned plain text table.
+type Table struct {
+	Tag
+	Columns []string   `json:",omitempty"`
+	Rows    [][]string `json:",omitempty"`
+
+	// records holds every field of each row, so that columns can be
+	// reselected and rows sorted by columns that won't be displayed.
+	records []map[string]string
+}
+
+func (o *Table) String() string {
+	return encodeTag(*o)
+}
+
+// Alt returns the table as column-aligned text, with a header row.
+func (o *Table) Alt() string {
+	if len(o.Columns) == 0 {
+		return ""
+	}
+
+	widths := make([]int, len(o.Columns))
+	for i, c := range o.Columns {
+		widths[i] = utf8.RuneCountInString(c)
+	}
+	for _, row := range o.Rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var b strings.Builder
+
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			if i == len(cells)-1 {
+				b.WriteString(cell)
+				break
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(o.Columns)
+
+	rule := make([]string, len(widths))
+	for i, w := range widths {
+		rule[i] = strings.Repeat("-", w)
+	}
+	writeRow(rule)
+
+	for _, row := range o.Rows {
+		writeRow(row)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// project rebuilds Rows from the records using the current Columns.
+func (o *Table) project() {
+	o.Rows = make([][]string, len(o.records))
+
+	for i, r := range o.records {
+		row := make([]string, len(o.Columns))
+		for j, c := range o.Columns {
+			row[j] = r[c]
+		}
+		o.Rows[i] = row
+	}
+}
+
+// TableFunction builds a table from v, which may be a list of objects (one
+// row each), a single object (rendered as key/value rows), or a string
+// containing either as JSON. By default every field is shown, with columns
+// in alphabetical order.
+func (f *Functions) TableFunction(v interface{}) (*Table, error) {
+	records, columns, err := tableRecords(v)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{Columns: columns, records: records}
+	t.project()
+
+	return t, nil
+}
+
+// TableColumnsFunction selects and orders the table's columns, which are
+// given as a comma-delimited list of field names.
+func (f *Functions) TableColumnsFunction(columns string, t *Table) (*Table, error) {
+	known := map[string]bool{}
+	for _, r := range t.records {
+		for k := range r {
+			known[k] = true
+		}
+	}
+
+	t.Columns = nil
+	for _, c := range strings.Split(columns, ",") {
+		c = strings.TrimSpace(c)
+		if len(t.records) > 0 && !known[c] {
+			return nil, fmt.Errorf("unknown table column %q", c)
+		}
+		t.Columns = append(t.Columns, c)
+	}
+
+	t.project()
+
+	return t, nil
+}
+
+// TableSortFunction sorts the table's rows by the named field, which needn't
+// be one of the displayed columns. Values that are both numbers are compared
+// numerically. Prefix the name with "-" to sort in descending order.
+func (f *Functions) TableSortFunction(column string, t *Table) *Table {
+	desc := strings.HasPrefix(column, "-")
+	column = strings.TrimPrefix(column, "-")
+
+	sort.SliceStable(t.records, func(i, j int) bool {
+		a, b := t.records[i][column], t.records[j][column]
+		if desc {
+			a, b = b, a
+		}
+		return lessCell(a, b)
+	})
+
+	t.project()
+
+	return t
+}
+
+func lessCell(a, b string) bool {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return fa < fb
+	}
+
+	return a < b
+}
+
+// tableRecords converts v into string-valued records and a default
+// column list.
+func tableRecords(v interface{}) ([]map[string]string, []string, error) {
+	if s, ok := v.(string); ok {
+		v = nil
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, nil, fmt.Errorf("table data isn't valid JSON: %w", err)
+		}
+	} else {
+		// Round-trip through JSON so that structs and typed maps and slices
+		// look the same as an unmarshalled payload.
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("table data can't be encoded: %w", err)
+		}
+		v = nil
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	switch t := v.(type) {
+	case nil:
+		return nil, nil, nil
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		records := make([]map[string]string, len(keys))
+		for i, k := range keys {
+			records[i] = map[string]string{"Key": k, "Value": formatCell(t[k])}
+		}
+
+		return records, []string{"Key", "Value"}, nil
+
+	case []interface{}:
+		records := make([]map[string]string, len(t))
+		known := map[string]bool{}
+		var columns []string
+
+		for i, e := range t {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("table rows must be objects, got %T", e)
+			}
+
+			records[i] = make(map[string]string, len(m))
+			for k, val := range m {
+				records[i][k] = formatCell(val)
+				if !known[k] {
+					known[k] = true
+					columns = append(columns, k)
+				}
+			}
+		}
+
+		sort.Strings(columns)
+
+		return records, columns, nil
+
+	default:
+		return nil, nil, fmt.Errorf("table data must be a list or an object, got %T", v)
+	}
+}
+
+func formatCell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
@@ -26,15 +26,41 @@ import (
 const (
 	// DefaultCommand is a template used to format the outputs from successfully
 	// executed commands.
-	DefaultCommand = `{{ text | monospace true }}{{ .Response.Out }}{{ endtext }}`
+	DefaultCommand = `{{ text | monospace (not .Response.Markdown) }}{{ .Response.Out }}{{ endtext }}`
 
 	// DefaultCommandError is a template used to format the error messages
-	// produced by commands that return with a non-zero status.
+	// produced by commands that return with a non-zero status. If the command
+	// reported a structured error detail, its message, hint, and docs URL are
+	// shown, followed by any other output.
 	DefaultCommandError = `{{ header | color "#FF0000" | title .Response.Title }}
 {{ text }}Gort failed to execute the following command:{{ endtext }}
 {{ text | monospace true }}{{ .Request.Bundle.Name }}:{{ .Request.Command.Name }} {{ .Request.Parameters }}{{ endtext }}
-{{ text }}The specific error was:{{ endtext }}
-{{ text | monospace true }}{{ .Response.Out }}{{ endtext }}`
+{{ if .Data.ErrorDetail }}{{ text }}The specific error was: {{ .Data.ErrorDetail.Message }}{{ endtext }}
+{{ if .Data.ErrorDetail.Hint }}{{ text }}Hint: {{ .Data.ErrorDetail.Hint }}{{ endtext }}{{ end }}
+{{ if .Data.ErrorDetail.DocsURL }}{{ text }}See {{ .Data.ErrorDetail.DocsURL }} for more information.{{ endtext }}{{ end }}
+{{ if .Response.Out }}{{ text | monospace (not .Response.Markdown) }}{{ .Response.Out }}{{ endtext }}{{ end }}
+{{ else }}{{ text }}The specific error was:{{ endtext }}
+{{ text | monospace (not .Response.Markdown) }}{{ .Response.Out }}{{ endtext }}
+{{ end }}{{ if .Data.ErrorCode }}{{ text }}Error code: {{ .Data.ErrorCode }}{{ endtext }}{{ end }}`
+
+	// DefaultCommandTimeout is a template used to format the error messages
+	// produced when commands don't complete within their timeout. Any output
+	// that the command produced before it was stopped is shown.
+	DefaultCommandTimeout = `{{ header | color "#FF0000" | title .Response.Title }}
+{{ text }}The following command didn't complete in time, and was stopped:{{ endtext }}
+{{ text | monospace true }}{{ .Request.Bundle.Name }}:{{ .Request.Command.Name }} {{ .Request.Parameters }}{{ endtext }}
+{{ if .Data.Partial }}{{ text }}Its output before it was stopped was:{{ endtext }}
+{{ text | monospace (not .Response.Markdown) }}{{ .Response.Out }}{{ endtext }}
+{{ end }}{{ if .Data.ErrorCode }}{{ text }}Error code: {{ .Data.ErrorCode }}{{ endtext }}{{ end }}`
+
+	// DefaultDigest is a template used to format digests, which summarize
+	// the runs of one or more scheduled commands since the last digest. Each
+	// command's most recent error, and its most recent output, are shown.
+	DefaultDigest = `{{ header | title .Response.Title }}
+{{ range .Digest }}{{ text }}{{ .Name }} ({{ .Command }}): {{ .Runs }} runs, {{ .Failures }} failed{{ endtext }}
+{{ if .LastError }}{{ text }}Most recent error: {{ .LastError }}{{ endtext }}
+{{ end }}{{ if .Out }}{{ text | monospace true }}{{ .Out }}{{ endtext }}
+{{ end }}{{ end }}`
 
 	// DefaultMessage is a template used to format standard informative
 	// (non-error) messages from the Gort system (not commands).
@@ -47,10 +73,12 @@ const (
 )
 
 var templateDefaults = data.Templates{
-	Message:      DefaultMessage,
-	MessageError: DefaultMessageError,
-	Command:      DefaultCommand,
-	CommandError: DefaultCommandError,
+	Message:        DefaultMessage,
+	MessageError:   DefaultMessageError,
+	Command:        DefaultCommand,
+	CommandError:   DefaultCommandError,
+	CommandTimeout: DefaultCommandTimeout,
+	Digest:         DefaultDigest,
 }
 
 // Get returns the first defined template found in the following sequence:
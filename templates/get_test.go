@@ -80,6 +80,14 @@ func TestLoadBundleFromFile(t *testing.T) {
 	assert.Equal(t, DefaultCommandError, template)
 	assert.NoError(t, err)
 
+	template, err = Get(cmd, bundle, data.CommandTimeout)
+	assert.Equal(t, DefaultCommandTimeout, template)
+	assert.NoError(t, err)
+
+	template, err = Get(cmd, bundle, data.Digest)
+	assert.Equal(t, DefaultDigest, template)
+	assert.NoError(t, err)
+
 	template, err = Get(cmd, bundle, data.Message)
 	assert.Equal(t, DefaultMessage, template)
 	assert.NoError(t, err)
@@ -111,6 +111,24 @@ func EncodeElements(text string) (OutputElements, error) {
 		case "":
 			continue
 
+		case "Button", "Select":
+			var o OutputElement
+			if tag == "Button" {
+				o = &Button{Tag: etag}
+			} else {
+				o = &Select{Tag: etag}
+			}
+			json.Unmarshal([]byte(jsn), o)
+
+			switch {
+			case lastSection != nil:
+				return encodingError(text, first, "illegal {{"+strings.ToLower(tag)+"}} in {{section}} on line %d")
+			case lastText != nil:
+				return encodingError(text, first, "illegal {{"+strings.ToLower(tag)+"}} in {{text}} on line %d")
+			default:
+				elements.Elements = append(elements.Elements, o)
+			}
+
 		case "Divider":
 			switch {
 			case lastSection != nil:
@@ -168,6 +186,18 @@ func EncodeElements(text string) (OutputElements, error) {
 				lastSection = nil
 			}
 
+		case "Table":
+			switch {
+			case lastSection != nil:
+				return encodingError(text, first, "illegal {{table}} in {{section}} on line %d")
+			case lastText != nil:
+				return encodingError(text, first, "illegal {{table}} in {{text}} on line %d")
+			default:
+				o := &Table{Tag: etag}
+				json.Unmarshal([]byte(jsn), o)
+				elements.Elements = append(elements.Elements, o)
+			}
+
 		case "Text":
 			o := &Text{Tag: etag}
 			json.Unmarshal([]byte(jsn), o)
@@ -222,7 +252,7 @@ func EncodeElements(text string) (OutputElements, error) {
 // Transforms template text + envelope, resulting in intermediate text that
 // can be encoded into an OutputElements value.
 func Transform(tmpl string, envelope data.CommandResponseEnvelope) (string, error) {
-	t, err := template.New(envelope.Request.String()).Funcs(FunctionMap()).Parse(tmpl)
+	t, err := template.New(envelope.Request.String()).Funcs(functionMap(&Functions{locale: envelope.Request.Locale})).Parse(tmpl)
 	if err != nil {
 		return "", err
 	}
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/getgort/gort/bundles"
 	"github.com/getgort/gort/data"
 	"github.com/stretchr/testify/assert"
 )
@@ -277,3 +279,258 @@ func TestTransformAndEncodeText(t *testing.T) {
 		assert.Equal(t, test.Encoded, enc, msg)
 	}
 }
+
+func TestDefaultCommandMarkdown(t *testing.T) {
+	envelope := testStructuredEnvelope
+
+	tf, err := Transform(DefaultCommand, envelope)
+	assert.NoError(t, err)
+	assert.Contains(t, tf, `"Monospace":true`)
+
+	envelope.Response.Markdown = true
+
+	tf, err = Transform(DefaultCommand, envelope)
+	assert.NoError(t, err)
+	assert.NotContains(t, tf, `"Monospace":true`)
+}
+
+func TestDefaultCommandErrorDetail(t *testing.T) {
+	envelope := testStructuredEnvelope
+
+	enc, err := TransformAndEncode(DefaultCommandError, envelope)
+	assert.NoError(t, err)
+	assert.Len(t, enc.Elements, 5)
+
+	envelope.Data.ErrorCode = "NET-1"
+	envelope.Data.ErrorDetail = &data.ErrorDetail{
+		Code:    "NET-1",
+		Message: "no such host",
+		Hint:    "Check the spelling of the host name.",
+		DocsURL: "https://example.com/net-1",
+	}
+
+	tf, err := Transform(DefaultCommandError, envelope)
+	assert.NoError(t, err)
+	assert.Contains(t, tf, "The specific error was: no such host")
+	assert.Contains(t, tf, "Hint: Check the spelling of the host name.")
+	assert.Contains(t, tf, "See https://example.com/net-1 for more information.")
+	assert.Contains(t, tf, "Error code: NET-1")
+
+	enc, err = EncodeElements(tf)
+	assert.NoError(t, err)
+	assert.Len(t, enc.Elements, 8)
+
+	// Without other output or a hint, neither is shown.
+	envelope.Response.Out = ""
+	envelope.Data.ErrorDetail.Hint = ""
+
+	enc, err = TransformAndEncode(DefaultCommandError, envelope)
+	assert.NoError(t, err)
+	assert.Len(t, enc.Elements, 6)
+}
+
+func TestDefaultDigest(t *testing.T) {
+	entries := []data.DigestEntry{
+		{Name: "version", Command: "gort:version --short", Runs: 288, Out: "0.9.0"},
+		{Name: "whoami", Command: "gort:whoami", Runs: 288, Failures: 2, LastError: "command exited with code 1"},
+	}
+	envelope := data.NewCommandResponseEnvelope(data.CommandRequest{}, data.WithDigest("Canary Digest", entries))
+
+	tf, err := Transform(DefaultDigest, envelope)
+	assert.NoError(t, err)
+	assert.Contains(t, tf, "version (gort:version --short): 288 runs, 0 failed")
+	assert.Contains(t, tf, "whoami (gort:whoami): 288 runs, 2 failed")
+	assert.Contains(t, tf, "Most recent error: command exited with code 1")
+
+	enc, err := EncodeElements(tf)
+	assert.NoError(t, err)
+	assert.Len(t, enc.Elements, 5)
+	assert.True(t, strings.HasPrefix(strings.TrimSpace(enc.Alt()), "Canary Digest"))
+}
+
+func TestTransformAndEncodeTable(t *testing.T) {
+	envelope := testStructuredEnvelope
+
+	tmpl := `{{ table .Payload.Results | columns "Name,Stars" | sortby "-Reviews" }}`
+
+	tf, err := Transform(tmpl, envelope)
+	assert.NoError(t, err)
+
+	elements, err := EncodeElements(tf)
+	assert.NoError(t, err)
+	assert.Len(t, elements.Elements, 1)
+
+	table, ok := elements.Elements[0].(*Table)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	assert.Equal(t, []string{"Name", "Stars"}, table.Columns)
+	assert.NotEmpty(t, table.Rows)
+	assert.True(t, strings.HasPrefix(table.Alt(), "Name"))
+
+	_, err = TransformAndEncode(`{{ text }}{{ table .Payload.Results }}{{ endtext }}`, envelope)
+	assert.Error(t, err)
+
+	_, err = Transform(`{{ table .Payload.Results | columns "Nope" }}`, envelope)
+	assert.Error(t, err)
+}
+
+func TestTransformAndEncodeActions(t *testing.T) {
+	envelope := testStructuredEnvelope
+
+	tmpl := `{{ button "Approve" "deploy:approve 42" | style "primary" }}` +
+		`{{ button "Reject" "deploy:reject 42" | style "danger" | confirm "Really reject?" }}` +
+		`{{ select "Environment" "deploy:to 42" "staging" "production" }}`
+
+	elements, err := TransformAndEncode(tmpl, envelope)
+	assert.NoError(t, err)
+	if !assert.Len(t, elements.Elements, 3) {
+		return
+	}
+
+	approve, ok := elements.Elements[0].(*Button)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Approve", approve.Label)
+		assert.Equal(t, "deploy:approve 42", approve.Command)
+		assert.Equal(t, "primary", approve.Style)
+		assert.Empty(t, approve.Confirm)
+	}
+
+	reject, ok := elements.Elements[1].(*Button)
+	if assert.True(t, ok) {
+		assert.Equal(t, "danger", reject.Style)
+		assert.Equal(t, "Really reject?", reject.Confirm)
+	}
+
+	sel, ok := elements.Elements[2].(*Select)
+	if assert.True(t, ok) {
+		assert.Equal(t, "deploy:to 42", sel.Command)
+		assert.Equal(t, []string{"staging", "production"}, sel.Options)
+		assert.Equal(t, "Environment: !deploy:to 42 <staging|production>", sel.Alt())
+	}
+
+	_, err = TransformAndEncode(`{{ text }}{{ button "A" "a:b" }}{{ endtext }}`, envelope)
+	assert.Error(t, err)
+
+	_, err = Transform(`{{ button "A" "a:b" | style "blue" }}`, envelope)
+	assert.Error(t, err)
+
+	_, err = Transform(`{{ select "A" "a:b" }}`, envelope)
+	assert.Error(t, err)
+
+	_, err = Transform(`{{ header | confirm "Sure?" }}`, envelope)
+	assert.Error(t, err)
+}
+
+func TestDefaultBundlePicker(t *testing.T) {
+	b, err := bundles.Default()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tmpl := b.Commands["bundle"].Templates.Command
+
+	picker := `{"picker":{"name":"foo","versions":["0.1.0","0.2.0"],"enabled":true,"enabled_version":"0.1.0"}}`
+	envelope := data.NewCommandResponseEnvelope(data.CommandRequest{}, data.WithResponseLines([]string{picker}))
+
+	elements, err := TransformAndEncode(tmpl, envelope)
+	assert.NoError(t, err)
+	if !assert.Len(t, elements.Elements, 5) {
+		return
+	}
+
+	assert.Equal(t, "Enable foo", elements.Elements[0].(*Header).Title)
+	assert.Contains(t, elements.Elements[1].(*Text).Text, "Version 0.1.0 is enabled now.")
+
+	for i, version := range []string{"0.1.0", "0.2.0"} {
+		button, ok := elements.Elements[2+i].(*Button)
+		if assert.True(t, ok) {
+			assert.Equal(t, version, button.Label)
+			assert.Equal(t, "gort:bundle enable foo "+version, button.Command)
+			assert.Equal(t, "Enable version "+version+" of foo?", button.Confirm)
+		}
+	}
+
+	disable, ok := elements.Elements[4].(*Button)
+	if assert.True(t, ok) {
+		assert.Equal(t, "gort:bundle disable foo", disable.Command)
+		assert.Equal(t, "danger", disable.Style)
+	}
+
+	// Other output, structured or not, is shown as-is.
+	for _, out := range []string{`[{"name":"foo"}]`, `Bundle "foo" disabled.`} {
+		envelope := data.NewCommandResponseEnvelope(data.CommandRequest{}, data.WithResponseLines([]string{out}))
+
+		elements, err := TransformAndEncode(tmpl, envelope)
+		assert.NoError(t, err)
+		if assert.Len(t, elements.Elements, 1) {
+			assert.Equal(t, out, elements.Elements[0].(*Text).Text)
+		}
+	}
+}
+
+func TestTableAlt(t *testing.T) {
+	f := &Functions{}
+
+	table, err := f.TableFunction(`[{"Name":"b","Count":10},{"Name":"a","Count":9,"Extra":true}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Count", "Extra", "Name"}, table.Columns)
+
+	table = f.TableSortFunction("Count", table)
+	assert.Equal(t, "Count  Extra  Name\n-----  -----  ----\n9      true   a\n10            b", table.Alt())
+
+	table, err = f.TableFunction(map[string]interface{}{"b": 2, "a": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "x"}, {"b", "2"}}, table.Rows)
+
+	_, err = f.TableFunction([]int{1})
+	assert.Error(t, err)
+}
+
+func TestFormatFunctions(t *testing.T) {
+	en := &Functions{}
+	de := &Functions{locale: "de_DE"}
+
+	s, err := en.NumberFunction(1234567.5)
+	assert.NoError(t, err)
+	assert.Equal(t, "1,234,567.5", s)
+
+	s, err = de.NumberFunction(1234567.5)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.234.567,5", s)
+
+	_, err = en.NumberFunction("abc")
+	assert.Error(t, err)
+
+	s, err = en.HumanBytesFunction(1536)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.5 KiB", s)
+
+	s, err = de.HumanBytesFunction(512)
+	assert.NoError(t, err)
+	assert.Equal(t, "512 B", s)
+
+	durations := map[interface{}]string{
+		222 * time.Second: "3m42s",
+		"1h30m":           "1h30m",
+		90061:             "1d1h",
+		3600:              "1h",
+		0.25:              "250ms",
+	}
+
+	for v, expected := range durations {
+		s, err := en.HumanDurationFunction(v)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, s, v)
+	}
+}
+
+func TestTransformLocale(t *testing.T) {
+	envelope := testStructuredEnvelope
+	envelope.Request.Locale = "fr-FR"
+
+	tf, err := Transform(`{{ number 1234.5 }}`, envelope)
+	assert.NoError(t, err)
+	assert.Equal(t, "1\u202f234,5", tf)
+}
@@ -0,0 +1,226 @@
+//go:build integration
+// +build integration
+
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/adapter"
+	"github.com/getgort/gort/adapter/scripted"
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/dataaccess"
+	"github.com/getgort/gort/relay"
+	"github.com/getgort/gort/service"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	databaseUser     = "gort"
+	databasePassword = "password"
+)
+
+// configTemplate is the Gort configuration used by the harness. The
+// database port is filled in once the database container is running.
+const configTemplate = `
+gort:
+  allow_self_registration: true
+  development_mode: true
+  enable_spoken_commands: true
+
+database:
+  host: localhost
+  port: %s
+  user: %s
+  password: %s
+  ssl_enabled: false
+
+worker:
+  engine: mock
+
+mock:
+  responses:
+    - command: integration:fail
+      output: ["something went wrong"]
+      exit_code: 1
+  default:
+    echo: true
+`
+
+// startDatabase starts a PostgreSQL container with dockertest, waits until
+// it accepts connections, and returns its host port. The container is
+// removed when the test completes.
+func startDatabase(t *testing.T) string {
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err, "failed to connect to docker")
+
+	pool.MaxWait = time.Minute
+
+	resource, err := pool.Run("postgres", "14", []string{
+		"POSTGRES_USER=" + databaseUser,
+		"POSTGRES_PASSWORD=" + databasePassword,
+	})
+	require.NoError(t, err, "failed to start database container")
+
+	t.Cleanup(func() {
+		if err := pool.Purge(resource); err != nil {
+			t.Log("warning: failed to remove database container: ", err)
+		}
+	})
+
+	// Don't leave the container lying around if the test is killed.
+	resource.Expire(uint(10 * time.Minute / time.Second))
+
+	port := resource.GetPort("5432/tcp")
+	dsn := fmt.Sprintf("host=localhost port=%s user=%s password=%s dbname=postgres sslmode=disable",
+		port, databaseUser, databasePassword)
+
+	err = pool.Retry(func() error {
+		db, err := sql.Open("pgx", dsn)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return db.Ping()
+	})
+	require.NoError(t, err, "timeout waiting for database")
+
+	return port
+}
+
+// startGort loads a configuration pointing at the database on the given
+// port, registers the scripted adapter, and starts the REST service, the
+// adapters, and the relay, wired together as in gort.go. It returns the URL
+// of the REST service.
+func startGort(ctx context.Context, t *testing.T, port string) string {
+	dir := t.TempDir()
+
+	// The client keeps its profiles and tokens in $HOME/.gort.
+	os.Setenv("HOME", dir)
+
+	configFile := filepath.Join(dir, "config.yml")
+	configData := fmt.Sprintf(configTemplate, port, databaseUser, databasePassword)
+	err := ioutil.WriteFile(configFile, []byte(configData), 0600)
+	require.NoError(t, err)
+
+	err = config.Initialize(configFile)
+	require.NoError(t, err)
+
+	waitForDataAccess(ctx, t)
+
+	chat = scripted.NewAdapter(adapterName)
+	adapter.AddAdapter(chat)
+
+	service.SetAnnouncer(adapter.Announce)
+	service.SetCanaryReporter(adapter.CanaryStatuses)
+	service.SetChannelManager(adapter.ChannelManager{})
+	service.SetDirectMessenger(adapter.SendDirectMessage)
+	service.SetExecutor(adapter.Exec)
+	service.SetRedeliverer(adapter.Redeliver)
+	service.SetReplayer(adapter.Replay)
+	service.SetSelfTester(adapter.SelfTest)
+
+	server := service.BuildRESTServer(ctx, "")
+	go func() {
+		for range server.Requests() {
+		}
+	}()
+
+	ts := httptest.NewServer(server.Handler)
+	t.Cleanup(ts.Close)
+
+	requestsFrom, responsesTo, adapterErrorsFrom := adapter.StartListening(ctx)
+	requestsTo, responsesFrom := relay.StartListening()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case request := <-requestsFrom:
+				requestsTo <- request
+			case response := <-responsesFrom:
+				responsesTo <- response
+			case aerr := <-adapterErrorsFrom:
+				t.Log("adapter error: ", aerr)
+			}
+		}
+	}()
+
+	return ts.URL
+}
+
+// waitForDataAccess waits until the data access layer has finished
+// initializing the database, which it does asynchronously after the
+// configuration is loaded.
+func waitForDataAccess(ctx context.Context, t *testing.T) {
+	const timeout = 30 * time.Second
+	timeoutAt := time.Now().Add(timeout)
+
+	for {
+		da, err := dataaccess.Get()
+		if err == nil {
+			_, err = da.UserExists(ctx, "admin")
+		}
+		if err == nil {
+			return
+		}
+
+		if time.Now().After(timeoutAt) {
+			require.NoError(t, err, "timeout waiting for data access layer")
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+// connect returns a client for the REST service at url that authenticates
+// as the given user.
+func connect(t *testing.T, url, username, password string) *client.GortClient {
+	c, err := client.ConnectWithNewProfile(client.ProfileEntry{
+		URLString:     url,
+		AllowInsecure: true,
+		Username:      username,
+		Password:      password,
+	})
+	require.NoError(t, err)
+
+	return c
+}
+
+// play plays the scripted conversation in testdata/<name>.yml.
+func play(t *testing.T, name string) {
+	t.Helper()
+
+	err := chat.PlayFile(ctx, filepath.Join("testdata", name+".yml"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,140 @@
+//go:build integration
+// +build integration
+
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/adapter/scripted"
+	"github.com/getgort/gort/bundles"
+	"github.com/getgort/gort/client"
+	"github.com/getgort/gort/data/rest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const adapterName = "scripted"
+
+var (
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// chat is the scripted adapter that stands in for a chat provider.
+	chat *scripted.Adapter
+
+	// gortURL is the URL of Gort's REST service.
+	gortURL string
+
+	// admin is a client authenticated as the bootstrapped admin user.
+	admin *client.GortClient
+)
+
+// TestIntegration runs Gort end to end against a real PostgreSQL database,
+// with a scripted chat adapter and the mock worker engine. The subtests
+// depend on each other, and run in order.
+func TestIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	port := startDatabase(t)
+	gortURL = startGort(ctx, t, port)
+
+	t.Run("testBootstrap", testBootstrap)
+	t.Run("testBundleInstall", testBundleInstall)
+	t.Run("testCommandRoundTrip", testCommandRoundTrip)
+	t.Run("testPermissions", testPermissions)
+}
+
+func testBootstrap(t *testing.T) {
+	c := connect(t, gortURL, "", "")
+
+	user, err := c.Bootstrap(true)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", user.Username)
+	assert.NotEmpty(t, user.Password)
+
+	admin = connect(t, gortURL, user.Username, user.Password)
+
+	// A second bootstrap is refused.
+	_, err = c.Bootstrap(true)
+	assert.Error(t, err)
+
+	groups, err := admin.UserGroupList("admin")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "admin", groups[0].Name)
+
+	// The default bundle is installed and enabled.
+	def, err := bundles.Default()
+	require.NoError(t, err)
+
+	b, err := admin.BundleGet(def.Name, def.Version)
+	require.NoError(t, err)
+	assert.True(t, b.Enabled)
+}
+
+func testBundleInstall(t *testing.T) {
+	bundle, err := bundles.LoadBundleFromFile("testdata/bundle.yml")
+	require.NoError(t, err)
+
+	_, err = admin.BundleInstall(bundle)
+	require.NoError(t, err)
+
+	_, err = admin.BundleEnable(bundle.Name, bundle.Version)
+	require.NoError(t, err)
+
+	b, err := admin.BundleGet(bundle.Name, bundle.Version)
+	require.NoError(t, err)
+	assert.True(t, b.Enabled)
+	assert.Len(t, b.Commands, 3)
+}
+
+func testCommandRoundTrip(t *testing.T) {
+	play(t, "round-trip")
+
+	// Alice was registered on her first command.
+	user, err := admin.UserGet("alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, "U001", user.Mappings[adapterName])
+}
+
+func testPermissions(t *testing.T) {
+	play(t, "permission-denied")
+
+	require.NoError(t, admin.GroupSave(rest.Group{Name: "shouters"}))
+	require.NoError(t, admin.RoleCreate("shouters"))
+	require.NoError(t, admin.RolePermissionGrant("shouters", "integration", "shout"))
+	require.NoError(t, admin.GroupRoleAdd("shouters", "shouters"))
+	require.NoError(t, admin.GroupMemberAdd("shouters", "alice"))
+
+	play(t, "permission-granted")
+
+	require.NoError(t, admin.RolePermissionRevoke("shouters", "integration", "shout"))
+
+	play(t, "permission-denied")
+}
@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 var (
@@ -103,20 +104,31 @@ func (i Inferrer) Infer(str string) (Value, error) {
 
 	case reFloat.MatchString(str):
 		value, err := strconv.ParseFloat(str, 64)
-		return FloatValue{V: value}, err
+		if err != nil {
+			// Out of range, like a long string of digits.
+			return i.unknown(str), nil
+		}
+		return FloatValue{V: value}, nil
 
 	case reInt.MatchString(str):
 		value, err := strconv.Atoi(str)
-		return IntValue{V: value}, err
+		if err != nil {
+			// Out of range, like a long numeric ID.
+			return i.unknown(str), nil
+		}
+		return IntValue{V: value}, nil
 
 	case i.regularExpressions && reRegex.MatchString(str):
 		value := reRegexTrim.ReplaceAllString(str, "")
 		return RegexValue{V: value}, nil
 
 	case reString.MatchString(str):
-		quoteFlavor := str[0]
+		quoteFlavor, _ := utf8.DecodeRuneInString(str)
+		if quoteFlavor == '“' || quoteFlavor == '”' {
+			quoteFlavor = '"'
+		}
 		value := reStringTrim.ReplaceAllString(str, "")
-		return StringValue{V: value, Quote: rune(quoteFlavor)}, nil
+		return StringValue{V: value, Quote: quoteFlavor}, nil
 
 	case i.literalLists && reList.MatchString(str):
 		submatches := reList.FindStringSubmatch(str)
@@ -155,12 +167,19 @@ func (i Inferrer) Infer(str string) (Value, error) {
 		}
 
 	default:
-		if i.strictStrings {
-			return UnknownValue{V: str}, nil
-		}
+		return i.unknown(str), nil
+	}
+}
 
-		return StringValue{V: str}, nil
+// unknown returns the value of a string that isn't recognizable as any other
+// type: an UnknownValue if strictStrings is true, or an unquoted StringValue
+// if it isn't.
+func (i Inferrer) unknown(str string) Value {
+	if i.strictStrings {
+		return UnknownValue{V: str}
 	}
+
+	return StringValue{V: str}
 }
 
 func (i Inferrer) InferAll(strs []string) ([]Value, error) {
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -165,6 +166,7 @@ func TestInfer(t *testing.T) {
 		`""`:            StringValue{``, '"'},
 		`''`:            StringValue{``, '\''},
 		`'"'`:           StringValue{`"`, '\''},
+		`“testing”`:     StringValue{"testing", '"'},
 		`arg[0]`:        ListElementValue{V: ListValue{Name: "arg"}, Index: 0},
 		`option["foo"]`: MapElementValue{V: MapValue{Name: "option"}, Key: "foo"},
 		`arg`:           UnknownValue{"arg"},
@@ -206,6 +208,23 @@ func TestInferInvalid(t *testing.T) {
 	}
 }
 
+func TestInferOutOfRange(t *testing.T) {
+	const big = `123456789012345678901234567890`
+
+	actual, err := Inferrer{}.StrictStrings(false).Infer(big)
+	assert.NoError(t, err)
+	assert.Equal(t, StringValue{V: big}, actual)
+
+	actual, err = Inferrer{}.StrictStrings(true).Infer(big)
+	assert.NoError(t, err)
+	assert.Equal(t, UnknownValue{V: big}, actual)
+
+	huge := strings.Repeat("9", 400) + ".0"
+	actual, err = Inferrer{}.StrictStrings(false).Infer(huge)
+	assert.NoError(t, err)
+	assert.Equal(t, StringValue{V: huge}, actual)
+}
+
 func TestGuessTypesValue(t *testing.T) {
 	infer := Inferrer{}.ComplexTypes(true).StrictStrings(true)
 
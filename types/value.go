@@ -239,6 +239,10 @@ func (v ListElementValue) String() string {
 }
 
 func (v ListElementValue) Value() interface{} {
+	if v.Index < 0 || v.Index >= len(v.V.V) {
+		return nil
+	}
+
 	return v.V.V[v.Index]
 }
 
@@ -391,7 +395,13 @@ func (v RegexValue) Equals(q Value) bool {
 	case RegexValue:
 		return v.V == o.V
 	default:
-		return re.MatchString(fmt.Sprintf("%v", q.Value()))
+		// Missing collection elements and nulls don't match anything.
+		value := q.Value()
+		if value == nil {
+			return false
+		}
+
+		return re.MatchString(fmt.Sprintf("%v", value))
 	}
 }
 
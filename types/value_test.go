@@ -162,6 +162,18 @@ func TestRegexValueEquals(t *testing.T) {
 	}
 }
 
+func TestRegexValueEqualsMissingElement(t *testing.T) {
+	re := RegexValue{V: `.*`}
+
+	list := ListElementValue{V: ListValue{Name: "arg"}, Index: 3}
+	assert.False(t, re.Equals(list))
+	assert.False(t, list.Equals(re))
+
+	m := MapElementValue{V: MapValue{Name: "option"}, Key: "foo"}
+	assert.False(t, re.Equals(m))
+	assert.False(t, m.Equals(re))
+}
+
 func TestStringValueEquals(t *testing.T) {
 	type Test struct {
 		Input      string
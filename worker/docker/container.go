@@ -17,15 +17,21 @@
 package docker
 
 import (
+	"archive/tar"
 	"bufio"
+	"bytes"
 	"context"
 	"io"
+	"path"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
+	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/telemetry"
 )
 
@@ -94,3 +100,56 @@ func wrapReaderInChannel(rc io.Reader) <-chan string {
 
 	return ch
 }
+
+// copyInputFile copies an input file into a created (but not yet started)
+// container, at the file's Path.
+func copyInputFile(ctx context.Context, client *client.Client, containerID string, f data.InputFile) error {
+	archive, err := inputFileArchive(f, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return client.CopyToContainer(ctx, containerID, "/", archive, types.CopyToContainerOptions{})
+}
+
+// inputFileArchive returns a tar archive, rooted at "/", that contains the
+// input file and the directories above it.
+func inputFileArchive(f data.InputFile, modTime time.Time) (*bytes.Buffer, error) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+
+	dir := ""
+	for _, elem := range strings.Split(strings.Trim(data.InputFileDir, "/"), "/") {
+		dir = path.Join(dir, elem)
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     dir + "/",
+			Mode:     0755,
+			ModTime:  modTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     strings.TrimPrefix(f.Path(), "/"),
+		Mode:     0644,
+		Size:     int64(len(f.Data)),
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tw.Write(f.Data); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestInputFileArchive(t *testing.T) {
+	f := data.InputFile{Name: "../config.yaml", Data: []byte("a: 1\n")}
+
+	buf, err := inputFileArchive(f, time.Now())
+	require.NoError(t, err)
+
+	var names []string
+	var content []byte
+
+	tr := tar.NewReader(buf)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		names = append(names, h.Name)
+		if h.Typeflag == tar.TypeReg {
+			content, err = ioutil.ReadAll(tr)
+			require.NoError(t, err)
+		}
+	}
+
+	assert.Equal(t, []string{"gort/", "gort/input/", "gort/input/config.yaml"}, names)
+	assert.Equal(t, f.Data, content)
+}
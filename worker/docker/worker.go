@@ -30,6 +30,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -51,7 +52,7 @@ type ContainerWorker struct {
 
 // New will build and returns a new Worker for a single command execution.
 func New(command data.CommandRequest, token rest.Token) (*ContainerWorker, error) {
-	entrypoint := command.Command.Executable
+	entrypoint := command.EntryPoint()
 	params := command.Parameters
 
 	dcli, err := client.NewClientWithOpts(client.FromEnv)
@@ -98,10 +99,13 @@ func (w *ContainerWorker) Start(ctx context.Context) (<-chan string, error) {
 	imageName := w.imageName
 	entryPoint := w.entryPoint
 
+	// Parameters may contain secrets, so only the redacted form is recorded.
+	redacted := w.command.RedactedParameters(config.GetRedactPatterns()...).String()
+
 	event := log.
 		WithField("image", w.imageName).
 		WithField("entry", entryPoint).
-		WithField("params", strings.Join(w.commandParameters, " "))
+		WithField("params", redacted)
 	if sp.SpanContext().HasTraceID() {
 		event = event.WithField("trace.id", sp.SpanContext().TraceID())
 	}
@@ -109,7 +113,7 @@ func (w *ContainerWorker) Start(ctx context.Context) (<-chan string, error) {
 	sp.SetAttributes(
 		attribute.String("image", w.imageName),
 		attribute.String("entry", strings.Join(entryPoint, " ")),
-		attribute.String("params", strings.Join(w.commandParameters, " ")),
+		attribute.String("params", redacted),
 	)
 
 	// Start the image pull. This blocks until the pull is complete.
@@ -140,7 +144,7 @@ func (w *ContainerWorker) Start(ctx context.Context) (<-chan string, error) {
 			hc = &container.HostConfig{NetworkMode: container.NetworkMode(network)}
 		}
 
-		return cli.ContainerCreate(ctx, &cfg, hc, nil, nil, "")
+		return cli.ContainerCreate(ctx, &cfg, hc, nil, w.platform(), "")
 	}()
 	if err != nil {
 		return nil, err
@@ -149,6 +153,18 @@ func (w *ContainerWorker) Start(ctx context.Context) (<-chan string, error) {
 	w.containerID = resp.ID
 	event = event.WithField("containerID", w.containerID)
 
+	// Place the input file, if there is one, before the command starts.
+	if f := w.command.InputFile; f != nil {
+		err = func() error {
+			ctx, sp := tr.Start(ctx, "worker.docker.CopyToContainer")
+			defer sp.End()
+			return copyInputFile(ctx, cli, w.containerID, *f)
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy input file to container: %w", err)
+		}
+	}
+
 	// Start the container
 	err = func() error {
 		ctx, sp := tr.Start(ctx, "worker.docker.ContainerStart")
@@ -238,16 +254,33 @@ func (w *ContainerWorker) envVars() []string {
 		env = append(env, fmt.Sprintf("%s=%s", k, v))
 	}
 
+	if p := w.command.ExecutionProfile(); p != nil {
+		for k, v := range p.Env {
+			env = append(env, fmt.Sprintf("%s=%s", k, v))
+		}
+	}
+
 	vars := map[string]string{
-		`GORT_ADAPTER`:       w.command.Adapter,
-		`GORT_BUNDLE`:        w.command.Bundle.Name,
-		`GORT_COMMAND`:       w.command.Command.Name,
-		`GORT_CHAT_ID`:       w.command.UserID,
-		`GORT_INVOCATION_ID`: fmt.Sprintf("%d", w.command.RequestID),
-		`GORT_ROOM`:          w.command.ChannelID,
-		`GORT_SERVICE_TOKEN`: w.token.Token,
-		`GORT_SERVICES_ROOT`: config.GetGortServerConfigs().APIURLBase,
-		`GORT_USER`:          w.command.UserName,
+		`GORT_ADAPTER`:         w.command.Adapter,
+		`GORT_BUNDLE`:          w.command.Bundle.Name,
+		`GORT_CHANNEL_NAME`:    w.command.ChannelName,
+		`GORT_COMMAND`:         w.command.Command.Name,
+		`GORT_CHAT_ID`:         w.command.UserID,
+		`GORT_INVOCATION_ID`:   fmt.Sprintf("%d", w.command.RequestID),
+		`GORT_INVOCATION_TEXT`: w.command.InvocationText,
+		`GORT_PROFILE`:         w.command.Profile,
+		`GORT_ROOM`:            w.command.ChannelID,
+		`GORT_SERVICE_TOKEN`:   w.token.Token,
+		`GORT_SERVICES_ROOT`:   config.GetGortServerConfigs().APIURLBase,
+		`GORT_USER`:            w.command.UserName,
+		`GORT_USER_DISPLAY`:    w.command.UserDisplayName,
+		`GORT_WORKER_TOKEN`:    w.command.WorkerToken,
+	}
+
+	if f := w.command.InputFile; f != nil {
+		for k, v := range f.EnvVars(true) {
+			vars[k] = v
+		}
 	}
 
 	for k, v := range vars {
@@ -280,6 +313,22 @@ func (w *ContainerWorker) imageExistsLocally(ctx context.Context, image string)
 	return false, nil
 }
 
+// platform returns the platform the container should be created for, or nil
+// if the command isn't pinned to one (in which case the daemon uses its own).
+func (w *ContainerWorker) platform() *specs.Platform {
+	p := w.command.Platform()
+	if p.OS == "" && p.Arch == "" {
+		return nil
+	}
+
+	os := strings.ToLower(p.OS)
+	if os == "" {
+		os = data.PlatformLinux
+	}
+
+	return &specs.Platform{OS: os, Architecture: p.Arch}
+}
+
 // pullImage pull the worker's image. It blocks until the pull is complete.
 func (w *ContainerWorker) pullImage(ctx context.Context, force bool) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
@@ -299,7 +348,11 @@ func (w *ContainerWorker) pullImage(ctx context.Context, force bool) error {
 
 		log.WithField("image", imageName).Trace("Pulling container image", imageName)
 
-		reader, err := cli.ImagePull(ctx, imageName, types.ImagePullOptions{})
+		// An empty platform lets the daemon pick the variant for its own
+		// os/arch; Windows images require a Windows daemon in any case.
+		opts := types.ImagePullOptions{Platform: w.command.Platform().String()}
+
+		reader, err := cli.ImagePull(ctx, imageName, opts)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,248 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	k8srest "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/telemetry"
+)
+
+const (
+	// DefaultHealthCheckInterval is used if kubernetes.health_check_interval
+	// isn't set.
+	DefaultHealthCheckInterval = time.Minute
+
+	// healthCheckTimeout bounds how long a single cluster's check may take.
+	healthCheckTimeout = 10 * time.Second
+)
+
+// ErrNoSuchCluster is returned when a command is routed to a cluster that
+// isn't defined in the kubernetes.clusters section of the config.
+var ErrNoSuchCluster = errors.New("no such kubernetes cluster")
+
+// ClusterHealth describes the result of a cluster's most recent health
+// check.
+type ClusterHealth struct {
+	Name    string
+	Healthy bool
+	Error   string
+	Checked time.Time
+}
+
+// cluster is a configured cluster and the client used to reach it. Clients
+// are built on first use and shared by every worker that targets the
+// cluster; they're rebuilt if the cluster's configuration changes.
+type cluster struct {
+	config    data.KubernetesCluster
+	clientset *kubernetes.Clientset
+	namespace string
+
+	mu      sync.Mutex
+	health  error
+	checked time.Time
+}
+
+var clusters = struct {
+	sync.Mutex
+	m map[string]*cluster
+}{m: map[string]*cluster{}}
+
+// getCluster returns the named cluster, building its client if necessary.
+// The empty name refers to the default cluster.
+func getCluster(name string) (*cluster, error) {
+	cc, ok := config.GetKubernetesConfigs().Cluster(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoSuchCluster, name)
+	}
+
+	clusters.Lock()
+	defer clusters.Unlock()
+
+	if c, ok := clusters.m[name]; ok && c.config == cc {
+		return c, nil
+	}
+
+	kconfig, namespace, err := clientConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(kconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &cluster{config: cc, clientset: clientset, namespace: namespace}
+	clusters.m[name] = c
+
+	return c, nil
+}
+
+// displayName returns the cluster's name for logs and metrics.
+func (c *cluster) displayName() string {
+	if c.config.Name == "" {
+		return "default"
+	}
+	return c.config.Name
+}
+
+// jobNamespace returns the namespace that a bundle's jobs run in on this
+// cluster: the bundle's own namespace if it has one, otherwise the cluster's
+// job namespace, otherwise the cluster's namespace. If it's empty, jobs run
+// in Gort's own namespace.
+func (c *cluster) jobNamespace(k data.BundleKubernetes) string {
+	switch {
+	case k.Namespace != "":
+		return k.Namespace
+	case c.config.JobNamespace != "":
+		return c.config.JobNamespace
+	default:
+		return c.namespace
+	}
+}
+
+// check queries the cluster's API server health endpoint and records the
+// result.
+func (c *cluster) check(ctx context.Context) ClusterHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	err := c.clientset.Discovery().RESTClient().Get().AbsPath("/healthz").Do(ctx).Error()
+
+	c.mu.Lock()
+	c.health = err
+	c.checked = time.Now()
+	c.mu.Unlock()
+
+	h := ClusterHealth{Name: c.displayName(), Healthy: err == nil, Checked: c.checked}
+	if err != nil {
+		h.Error = err.Error()
+	}
+
+	return h
+}
+
+// unhealthy returns an error if the cluster failed its most recent health
+// check. A cluster that hasn't been checked yet is assumed to be healthy.
+func (c *cluster) unhealthy() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.health != nil {
+		return fmt.Errorf("kubernetes cluster %s is unhealthy: %w", c.displayName(), c.health)
+	}
+	return nil
+}
+
+// CheckClusters checks the health of the default cluster and every cluster
+// in the kubernetes.clusters section of the config.
+func CheckClusters(ctx context.Context) []ClusterHealth {
+	names := []string{""}
+	for _, cc := range config.GetKubernetesConfigs().Clusters {
+		names = append(names, cc.Name)
+	}
+	sort.Strings(names)
+
+	var results []ClusterHealth
+	for _, name := range names {
+		c, err := getCluster(name)
+		if err != nil {
+			results = append(results, ClusterHealth{Name: name, Error: err.Error(), Checked: time.Now()})
+			continue
+		}
+
+		h := c.check(ctx)
+		results = append(results, h)
+
+		if !h.Healthy {
+			telemetry.KubernetesHealthCheckFailures().WithAttribute("cluster", h.Name).Commit(ctx)
+			log.WithField("cluster", h.Name).WithField("error", h.Error).Warn("Kubernetes cluster health check failed")
+		}
+	}
+
+	return results
+}
+
+// StartHealthChecks periodically checks the health of every configured
+// cluster until ctx is canceled.
+func StartHealthChecks(ctx context.Context) {
+	interval := config.GetKubernetesConfigs().HealthCheckInterval
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		CheckClusters(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// clientConfig builds the client configuration for a cluster. If no
+// kubeconfig or context is configured, the in-cluster configuration is
+// used. The returned namespace is the explicit namespace override if there
+// is one; otherwise, out of cluster, it's the namespace of the selected
+// kubeconfig context. In cluster, it may be empty, in which case it's
+// discovered from Gort's own pod.
+func clientConfig(cc data.KubernetesCluster) (*k8srest.Config, string, error) {
+	if !cc.OutOfCluster() {
+		kconfig, err := k8srest.InClusterConfig()
+		return kconfig, cc.Namespace, err
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if cc.Kubeconfig != "" {
+		rules.ExplicitPath = cc.Kubeconfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: cc.Context}
+	kcc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	kconfig, err := kcc.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	namespace := cc.Namespace
+	if namespace == "" {
+		if namespace, _, err = kcc.Namespace(); err != nil {
+			return nil, "", fmt.Errorf("failed to get kubeconfig namespace: %w", err)
+		}
+	}
+
+	return kconfig, namespace, nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+users:
+- name: gort
+  user:
+    token: abc123
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: gort
+- name: prod
+  context:
+    cluster: prod
+    user: gort
+    namespace: gort-jobs
+`
+
+func TestClientConfigOutOfCluster(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte(testKubeconfig), 0600))
+
+	tests := []struct {
+		cc        data.KubernetesCluster
+		host      string
+		namespace string
+	}{
+		{data.KubernetesCluster{Kubeconfig: path}, "https://dev.example.com:6443", "default"},
+		{data.KubernetesCluster{Kubeconfig: path, Context: "prod"}, "https://prod.example.com:6443", "gort-jobs"},
+		{data.KubernetesCluster{Kubeconfig: path, Context: "prod", Namespace: "override"}, "https://prod.example.com:6443", "override"},
+	}
+
+	for _, test := range tests {
+		kconfig, namespace, err := clientConfig(test.cc)
+		require.NoError(t, err)
+		assert.Equal(t, test.host, kconfig.Host)
+		assert.Equal(t, test.namespace, namespace)
+	}
+
+	_, _, err := clientConfig(data.KubernetesCluster{Kubeconfig: path, Context: "missing"})
+	assert.Error(t, err)
+}
+
+func TestClusterJobNamespace(t *testing.T) {
+	c := &cluster{config: data.KubernetesCluster{Namespace: "gort"}, namespace: "gort"}
+	assert.Equal(t, "gort", c.jobNamespace(data.BundleKubernetes{}))
+	assert.Equal(t, []string{"gort"}, c.configuredNamespaces())
+
+	c.config.JobNamespace = "gort-jobs"
+	assert.Equal(t, "gort-jobs", c.jobNamespace(data.BundleKubernetes{}))
+	assert.Equal(t, "team-jobs", c.jobNamespace(data.BundleKubernetes{Namespace: "team-jobs"}))
+	assert.Equal(t, []string{"gort", "gort-jobs"}, c.configuredNamespaces())
+
+	// In cluster, with nothing configured, jobs run in Gort's own namespace,
+	// which is discovered later.
+	c = &cluster{}
+	assert.Equal(t, "", c.jobNamespace(data.BundleKubernetes{}))
+	assert.Empty(t, c.configuredNamespaces())
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/getgort/gort/config"
+)
+
+// CheckNamespaces checks that the namespaces configured for the default
+// cluster and every cluster in the kubernetes.clusters section of the config
+// exist, and returns an error naming any that don't. A namespace that can't
+// be checked, because the cluster is unreachable or Gort isn't permitted to
+// get namespaces, is logged and otherwise ignored.
+func CheckNamespaces(ctx context.Context) error {
+	names := []string{""}
+	for _, cc := range config.GetKubernetesConfigs().Clusters {
+		names = append(names, cc.Name)
+	}
+	sort.Strings(names)
+
+	var missing []string
+
+	for _, name := range names {
+		c, err := getCluster(name)
+		if err != nil {
+			return err
+		}
+
+		for _, ns := range c.configuredNamespaces() {
+			_, err := c.clientset.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+			switch {
+			case err == nil:
+			case apierrors.IsNotFound(err):
+				missing = append(missing, fmt.Sprintf("%s (cluster %s)", ns, c.displayName()))
+			default:
+				log.WithError(err).
+					WithField("cluster", c.displayName()).
+					WithField("namespace", ns).
+					Warn("Failed to check that Kubernetes namespace exists")
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("kubernetes namespaces don't exist: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// configuredNamespaces returns the distinct namespaces that are explicitly
+// set for the cluster, either as Gort's namespace or as the job namespace.
+func (c *cluster) configuredNamespaces() []string {
+	var namespaces []string
+
+	for _, ns := range []string{c.config.Namespace, c.config.JobNamespace} {
+		if ns != "" && (len(namespaces) == 0 || namespaces[0] != ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	return namespaces
+}
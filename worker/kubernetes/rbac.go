@@ -0,0 +1,182 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/getgort/gort/data"
+)
+
+// RBACOptions describe Gort's service account, for generating the RBAC
+// manifests that grant it access to a cluster.
+type RBACOptions struct {
+	// ServiceAccount is the name of Gort's service account.
+	ServiceAccount string
+
+	// Namespace is the namespace that Gort and its service account are in.
+	// If empty, the cluster's namespace is used.
+	Namespace string
+
+	// JobNamespaces are namespaces that jobs run in in addition to the
+	// cluster's job namespace, such as those named by bundles.
+	JobNamespaces []string
+}
+
+// rbacTemplate grants Gort's service account what it needs to find its own
+// endpoint in its namespace, to run jobs and read their logs in each job
+// namespace, and to check at startup that the job namespaces exist.
+var rbacTemplate = template.Must(template.New("rbac").Parse(`# Generated by "gort rbac" for Gort's service account {{ .Namespace }}/{{ .ServiceAccount }}.
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: {{ .ServiceAccount }}
+  namespace: {{ .Namespace }}
+rules:
+- apiGroups: ['']
+  resources: ['endpoints']
+  verbs: ['list']
+- apiGroups: ['']
+  resources: ['pods']
+  verbs: ['get', 'list']
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: {{ .ServiceAccount }}
+  namespace: {{ .Namespace }}
+subjects:
+- kind: ServiceAccount
+  name: {{ .ServiceAccount }}
+  namespace: {{ .Namespace }}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: {{ .ServiceAccount }}
+{{- range .JobNamespaces }}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: {{ $.ServiceAccount }}-jobs
+  namespace: {{ . }}
+rules:
+- apiGroups: ['batch']
+  resources: ['jobs']
+  verbs: ['create', 'delete', 'get', 'list', 'watch']
+- apiGroups: ['']
+  resources: ['pods']
+  verbs: ['delete', 'get', 'list', 'watch']
+- apiGroups: ['']
+  resources: ['pods/log']
+  verbs: ['get', 'watch']
+- apiGroups: ['']
+  resources: ['secrets']
+  verbs: ['create', 'delete']
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: {{ $.ServiceAccount }}-jobs
+  namespace: {{ . }}
+subjects:
+- kind: ServiceAccount
+  name: {{ $.ServiceAccount }}
+  namespace: {{ $.Namespace }}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: {{ $.ServiceAccount }}-jobs
+{{- end }}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: {{ .Namespace }}-{{ .ServiceAccount }}-namespaces
+rules:
+- apiGroups: ['']
+  resources: ['namespaces']
+  resourceNames: [{{ range $i, $ns := .JobNamespaces }}{{ if $i }}, {{ end }}'{{ $ns }}'{{ end }}]
+  verbs: ['get']
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: {{ .Namespace }}-{{ .ServiceAccount }}-namespaces
+subjects:
+- kind: ServiceAccount
+  name: {{ .ServiceAccount }}
+  namespace: {{ .Namespace }}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: {{ .Namespace }}-{{ .ServiceAccount }}-namespaces
+`))
+
+// RBAC returns the Kubernetes RBAC manifests, as a multi-document YAML
+// stream, that grant Gort's service account the permissions it needs on a
+// cluster. Jobs are granted access to the cluster's job namespace, or to
+// Gort's namespace if it has none, and to any additional job namespaces.
+func RBAC(cc data.KubernetesCluster, opts RBACOptions) (string, error) {
+	if opts.ServiceAccount == "" {
+		return "", fmt.Errorf("service account name must not be empty")
+	}
+
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = cc.Namespace
+	}
+	if namespace == "" {
+		return "", fmt.Errorf("gort's namespace must be set, either with kubernetes.namespace or explicitly")
+	}
+	if err := data.ValidateKubernetesNamespace(namespace); err != nil {
+		return "", err
+	}
+
+	jobNamespace := cc.JobNamespace
+	if jobNamespace == "" {
+		jobNamespace = namespace
+	}
+
+	seen := map[string]bool{}
+	var jobNamespaces []string
+	for _, ns := range append([]string{jobNamespace}, opts.JobNamespaces...) {
+		if err := data.ValidateKubernetesNamespace(ns); err != nil {
+			return "", err
+		}
+		if !seen[ns] {
+			seen[ns] = true
+			jobNamespaces = append(jobNamespaces, ns)
+		}
+	}
+	sort.Strings(jobNamespaces)
+
+	var b bytes.Buffer
+	err := rbacTemplate.Execute(&b, struct {
+		ServiceAccount string
+		Namespace      string
+		JobNamespaces  []string
+	}{opts.ServiceAccount, namespace, jobNamespaces})
+	if err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
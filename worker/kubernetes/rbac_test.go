@@ -0,0 +1,98 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/getgort/gort/data"
+)
+
+type rbacManifest struct {
+	Kind     string
+	Metadata struct {
+		Name      string
+		Namespace string
+	}
+	Rules []struct {
+		Resources     []string
+		ResourceNames []string `yaml:"resourceNames"`
+	}
+}
+
+func decodeRBAC(t *testing.T, s string) []rbacManifest {
+	var manifests []rbacManifest
+
+	dec := yaml.NewDecoder(strings.NewReader(s))
+	for {
+		var m rbacManifest
+		err := dec.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			return manifests
+		}
+		require.NoError(t, err)
+		manifests = append(manifests, m)
+	}
+}
+
+func TestRBAC(t *testing.T) {
+	cc := data.KubernetesCluster{Namespace: "gort", JobNamespace: "gort-jobs"}
+
+	s, err := RBAC(cc, RBACOptions{ServiceAccount: "gort", JobNamespaces: []string{"team-jobs", "gort-jobs"}})
+	require.NoError(t, err)
+
+	manifests := decodeRBAC(t, s)
+	require.Len(t, manifests, 8)
+
+	var names []string
+	for _, m := range manifests {
+		names = append(names, m.Kind+" "+m.Metadata.Namespace+"/"+m.Metadata.Name)
+	}
+	assert.Equal(t, []string{
+		"Role gort/gort",
+		"RoleBinding gort/gort",
+		"Role gort-jobs/gort-jobs",
+		"RoleBinding gort-jobs/gort-jobs",
+		"Role team-jobs/gort-jobs",
+		"RoleBinding team-jobs/gort-jobs",
+		"ClusterRole /gort-gort-namespaces",
+		"ClusterRoleBinding /gort-gort-namespaces",
+	}, names)
+
+	assert.Equal(t, []string{"gort-jobs", "team-jobs"}, manifests[6].Rules[0].ResourceNames)
+
+	// Without a job namespace, jobs run in Gort's
+	s, err = RBAC(data.KubernetesCluster{}, RBACOptions{ServiceAccount: "bot", Namespace: "chatops"})
+	require.NoError(t, err)
+	manifests = decodeRBAC(t, s)
+	require.Len(t, manifests, 6)
+	assert.Equal(t, "chatops", manifests[2].Metadata.Namespace)
+	assert.Equal(t, "bot-jobs", manifests[2].Metadata.Name)
+
+	_, err = RBAC(data.KubernetesCluster{}, RBACOptions{ServiceAccount: "gort"})
+	assert.Error(t, err)
+
+	_, err = RBAC(cc, RBACOptions{ServiceAccount: "gort", JobNamespaces: []string{"Not_Valid"}})
+	assert.Error(t, err)
+}
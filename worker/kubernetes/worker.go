@@ -22,31 +22,36 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/getgort/gort/config"
 	"github.com/getgort/gort/data"
 	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/dataaccess"
 	"github.com/getgort/gort/telemetry"
 
 	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	k8srest "k8s.io/client-go/rest"
 )
 
 // KubernetesWorker represents a container executor. It has a lifetime of a single command execution.
 type KubernetesWorker struct {
 	clientset         *kubernetes.Clientset
+	cluster           *cluster
 	command           data.CommandRequest
 	commandParameters []string
 	configs           map[string]string
 	entryPoint        []string
 	exitStatus        chan int64
+	gortNamespace     string
 	imageName         string
+	inputSecret       string
 	jobName           string
 	namespace         string
 	token             rest.Token
@@ -54,29 +59,28 @@ type KubernetesWorker struct {
 
 // New will build and returns a new Worker for a single command execution.
 func New(command data.CommandRequest, token rest.Token) (*KubernetesWorker, error) {
-	entrypoint := command.Command.Executable
+	entrypoint := command.EntryPoint()
 	params := command.Parameters
 
-	// creates the in-cluster config
-	kconfig, err := k8srest.InClusterConfig()
+	c, err := getCluster(command.KubernetesCluster())
 	if err != nil {
 		return nil, err
 	}
-
-	// creates the clientset
-	clientset, err := kubernetes.NewForConfig(kconfig)
-	if err != nil {
+	if err := c.unhealthy(); err != nil {
 		return nil, err
 	}
 
 	w := &KubernetesWorker{
-		clientset:         clientset,
+		clientset:         c.clientset,
+		cluster:           c,
 		command:           command,
 		commandParameters: params,
 		configs:           map[string]string{},
 		entryPoint:        entrypoint,
 		exitStatus:        make(chan int64, 1),
+		gortNamespace:     c.namespace,
 		imageName:         command.Bundle.ImageFull(),
+		namespace:         c.jobNamespace(command.Bundle.Kubernetes),
 		token:             token,
 	}
 
@@ -96,26 +100,41 @@ func (w *KubernetesWorker) Start(ctx context.Context) (<-chan string, error) {
 	_, sp := tr.Start(ctx, "worker.kubernetes.Start")
 	defer sp.End()
 
-	// We have to set the namespace!
-	pod, err := w.findGortPod(ctx)
-	if err != nil {
-		return nil, err
+	// We have to set the namespaces! If Gort's own wasn't configured, it's
+	// found from Gort's pod, and if the jobs' wasn't, it's the same as
+	// Gort's.
+	if w.gortNamespace == "" {
+		pod, err := w.findGortPod(ctx)
+		if err != nil {
+			return nil, err
+		}
+		w.gortNamespace = pod.Namespace
+	}
+	if w.namespace == "" {
+		w.namespace = w.gortNamespace
+	}
+
+	if err := w.createInputSecret(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create input file secret: %w", err)
 	}
-	w.namespace = pod.Namespace
 
 	job, err := w.buildJobData(ctx)
 	if err != nil {
+		w.deleteInputSecret(ctx)
 		return nil, fmt.Errorf("failed to build job struct: %w", err)
 	}
 
 	jobInterface := w.clientset.BatchV1().Jobs(w.namespace)
 	job, err = jobInterface.Create(ctx, job, metav1.CreateOptions{})
 	if err != nil {
+		w.deleteInputSecret(ctx)
 		return nil, fmt.Errorf("failed to start kubernetes job: %w", err)
 	}
 
 	w.jobName = job.Name
 
+	telemetry.KubernetesJobs().WithAttribute("cluster", w.cluster.displayName()).Commit(ctx)
+
 	// Watch the Job's pod for termination.
 	if err := w.watchForPodTermination(ctx); err != nil {
 		return nil, fmt.Errorf("failed to watch job pod: %w", err)
@@ -141,6 +160,8 @@ func (w *KubernetesWorker) Stop(ctx context.Context, timeout *time.Duration) {
 	ctx, sp := tr.Start(ctx, "worker.kubernetes.Stop")
 	defer sp.End()
 
+	defer w.deleteInputSecret(ctx)
+
 	// Clean up the Job resource
 	err := func() error {
 		ctx, sp := tr.Start(ctx, "worker.kubernetes.Stop.CleanUpJob")
@@ -208,13 +229,35 @@ func (w *KubernetesWorker) buildJobData(ctx context.Context) (*batchv1.Job, erro
 		return nil, err
 	}
 
+	serviceAccountName, err := w.serviceAccountName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A selected execution profile may override the bundle's secret and
+	// service account.
+	envSecret := w.command.Bundle.Kubernetes.EnvSecret
+	if p := w.command.ExecutionProfile(); p != nil {
+		if p.EnvSecret != "" {
+			envSecret = p.EnvSecret
+		}
+		if p.ServiceAccountName != "" {
+			serviceAccountName = p.ServiceAccountName
+		}
+	}
+
+	resources, err := w.resources()
+	if err != nil {
+		return nil, err
+	}
+
 	secretEnv := []corev1.EnvFromSource{}
 
-	if w.command.Bundle.Kubernetes.EnvSecret != "" {
+	if envSecret != "" {
 		secretEnv = append(secretEnv, corev1.EnvFromSource{
 			SecretRef: &corev1.SecretEnvSource{
 				LocalObjectReference: corev1.LocalObjectReference{
-					Name: w.command.Bundle.Kubernetes.EnvSecret,
+					Name: envSecret,
 				},
 			},
 		},
@@ -228,6 +271,7 @@ func (w *KubernetesWorker) buildJobData(ctx context.Context) (*batchv1.Job, erro
 			Labels: map[string]string{
 				"gort.bundle":  w.command.Bundle.Name,
 				"gort.command": w.command.Command.Name,
+				"gort.profile": w.command.Profile,
 				"gort.request": fmt.Sprintf("%d", w.command.RequestID),
 			},
 		},
@@ -235,15 +279,18 @@ func (w *KubernetesWorker) buildJobData(ctx context.Context) (*batchv1.Job, erro
 			BackoffLimit: &backoffLimit,
 			Template: corev1.PodTemplateSpec{
 				Spec: corev1.PodSpec{
-					ServiceAccountName: w.command.Bundle.Kubernetes.ServiceAccountName,
+					ServiceAccountName: serviceAccountName,
+					NodeSelector:       w.nodeSelector(),
+					Tolerations:        w.tolerations(),
 					Containers: []corev1.Container{
 						{
-							Name:    "command",
-							Image:   w.imageName,
-							Command: w.entryPoint,
-							Args:    w.commandParameters,
-							Env:     envVars,
-							EnvFrom: secretEnv,
+							Name:      "command",
+							Image:     w.imageName,
+							Command:   w.entryPoint,
+							Args:      w.commandParameters,
+							Env:       envVars,
+							EnvFrom:   secretEnv,
+							Resources: resources,
 						},
 					},
 					RestartPolicy: corev1.RestartPolicyNever,
@@ -256,13 +303,150 @@ func (w *KubernetesWorker) buildJobData(ctx context.Context) (*batchv1.Job, erro
 		job.Spec.Template.Spec.Containers[0].Command = w.entryPoint
 	}
 
+	if f := w.command.InputFile; f != nil && w.inputSecret != "" {
+		spec := &job.Spec.Template.Spec
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: inputVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: w.inputSecret,
+					Items:      []corev1.KeyToPath{{Key: inputSecretKey, Path: f.FileName()}},
+				},
+			},
+		})
+		spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      inputVolumeName,
+			MountPath: data.InputFileDir,
+			ReadOnly:  true,
+		})
+	}
+
 	return job, nil
 }
 
+const (
+	inputSecretKey  = "file"
+	inputVolumeName = "gort-input"
+)
+
+// createInputSecret stores the command's input file, if it has one, in a
+// secret that's mounted into the job's pod.
+func (w *KubernetesWorker) createInputSecret(ctx context.Context) error {
+	f := w.command.InputFile
+	if f == nil {
+		return nil
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "gort-input-",
+			Labels: map[string]string{
+				"gort.request": fmt.Sprintf("%d", w.command.RequestID),
+			},
+		},
+		Data: map[string][]byte{inputSecretKey: f.Data},
+	}
+
+	secret, err := w.clientset.CoreV1().Secrets(w.namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+
+	w.inputSecret = secret.Name
+	return nil
+}
+
+// deleteInputSecret deletes the secret created by createInputSecret, if
+// there is one.
+func (w *KubernetesWorker) deleteInputSecret(ctx context.Context) {
+	if w.inputSecret == "" {
+		return
+	}
+
+	err := w.clientset.CoreV1().Secrets(w.namespace).Delete(ctx, w.inputSecret, metav1.DeleteOptions{})
+	if err != nil {
+		log.WithError(err).WithField("secret", w.inputSecret).Error("Failed to delete input file secret")
+		return
+	}
+
+	w.inputSecret = ""
+}
+
+// resources builds the container's resource requests from the bundle's
+// declared resources, which are also what cost accounting charges for.
+func (w *KubernetesWorker) resources() (corev1.ResourceRequirements, error) {
+	r := w.command.Bundle.Resources
+	if r.IsZero() {
+		return corev1.ResourceRequirements{}, nil
+	}
+
+	requests := corev1.ResourceList{}
+
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    r.CPU,
+		corev1.ResourceMemory: r.Memory,
+	} {
+		if value == "" {
+			continue
+		}
+
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return corev1.ResourceRequirements{}, fmt.Errorf("invalid %s request %q: %w", name, value, err)
+		}
+		requests[name] = q
+	}
+
+	return corev1.ResourceRequirements{Requests: requests}, nil
+}
+
+// nodeSelector builds the pod's node selector from the command's platform and
+// any explicit node selector in its kubernetes section, which takes
+// precedence.
+func (w *KubernetesWorker) nodeSelector() map[string]string {
+	selector := map[string]string{}
+
+	platform := w.command.Platform()
+	if platform.OS != "" {
+		selector[corev1.LabelOSStable] = strings.ToLower(platform.OS)
+	}
+	if platform.Arch != "" {
+		selector[corev1.LabelArchStable] = platform.Arch
+	}
+
+	for k, v := range w.command.Bundle.Kubernetes.NodeSelector {
+		selector[k] = v
+	}
+
+	if len(selector) == 0 {
+		return nil
+	}
+
+	return selector
+}
+
+// tolerations returns the pod's tolerations. Windows nodes are commonly
+// tainted with "node.kubernetes.io/os=windows:NoSchedule" so that Linux pods
+// aren't scheduled onto them, so pods for Windows bundles tolerate that taint.
+func (w *KubernetesWorker) tolerations() []corev1.Toleration {
+	if !w.command.Platform().IsWindows() {
+		return nil
+	}
+
+	return []corev1.Toleration{
+		{
+			Key:      "node.kubernetes.io/os",
+			Operator: corev1.TolerationOpEqual,
+			Value:    data.PlatformWindows,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}
+}
+
 // envVars builds the default environment variables that get injected into
 // the command pod.
 func (w *KubernetesWorker) envVars(ctx context.Context) ([]corev1.EnvVar, error) {
-	gortIP, gortPort, err := w.findGortEndpoint(ctx)
+	servicesRoot, err := w.servicesRoot(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -273,16 +457,33 @@ func (w *KubernetesWorker) envVars(ctx context.Context) ([]corev1.EnvVar, error)
 		env = append(env, corev1.EnvVar{Name: k, Value: v})
 	}
 
+	if p := w.command.ExecutionProfile(); p != nil {
+		for k, v := range p.Env {
+			env = append(env, corev1.EnvVar{Name: k, Value: v})
+		}
+	}
+
 	vars := map[string]string{
-		`GORT_ADAPTER`:       w.command.Adapter,
-		`GORT_BUNDLE`:        w.command.Bundle.Name,
-		`GORT_COMMAND`:       w.command.Command.Name,
-		`GORT_CHAT_ID`:       w.command.UserID,
-		`GORT_INVOCATION_ID`: fmt.Sprintf("%d", w.command.RequestID),
-		`GORT_ROOM`:          w.command.ChannelID,
-		`GORT_SERVICE_TOKEN`: w.token.Token,
-		`GORT_SERVICES_ROOT`: fmt.Sprintf("%s:%d", gortIP, gortPort),
-		`GORT_USER`:          w.command.UserName,
+		`GORT_ADAPTER`:         w.command.Adapter,
+		`GORT_BUNDLE`:          w.command.Bundle.Name,
+		`GORT_CHANNEL_NAME`:    w.command.ChannelName,
+		`GORT_COMMAND`:         w.command.Command.Name,
+		`GORT_CHAT_ID`:         w.command.UserID,
+		`GORT_INVOCATION_ID`:   fmt.Sprintf("%d", w.command.RequestID),
+		`GORT_INVOCATION_TEXT`: w.command.InvocationText,
+		`GORT_PROFILE`:         w.command.Profile,
+		`GORT_ROOM`:            w.command.ChannelID,
+		`GORT_SERVICE_TOKEN`:   w.token.Token,
+		`GORT_SERVICES_ROOT`:   servicesRoot,
+		`GORT_USER`:            w.command.UserName,
+		`GORT_USER_DISPLAY`:    w.command.UserDisplayName,
+		`GORT_WORKER_TOKEN`:    w.command.WorkerToken,
+	}
+
+	if f := w.command.InputFile; f != nil {
+		for k, v := range f.EnvVars(true) {
+			vars[k] = v
+		}
 	}
 
 	for k, v := range vars {
@@ -292,15 +493,66 @@ func (w *KubernetesWorker) envVars(ctx context.Context) ([]corev1.EnvVar, error)
 	return env, nil
 }
 
+// serviceAccountName returns the service account that the job should run
+// as, based on the invoking user's group memberships. If the bundle maps no
+// groups to service accounts, the bundle's default is returned without
+// looking the user up.
+func (w *KubernetesWorker) serviceAccountName(ctx context.Context) (string, error) {
+	k := w.command.Bundle.Kubernetes
+	if len(k.GroupServiceAccounts) == 0 || w.command.UserName == "" {
+		return k.ServiceAccountName, nil
+	}
+
+	da, err := dataaccess.Get()
+	if err != nil {
+		return "", err
+	}
+
+	groups, err := da.UserGroupList(ctx, w.command.UserName)
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+
+	return k.ServiceAccountFor(names), nil
+}
+
+// servicesRoot returns the address that jobs use to reach Gort's API. When
+// Gort runs outside of the cluster there's no endpoint resource to find, so
+// the configured API URL base is used instead.
+func (w *KubernetesWorker) servicesRoot(ctx context.Context) (string, error) {
+	if w.cluster.config.OutOfCluster() {
+		root := w.cluster.config.APIURLBase
+		if root == "" {
+			root = config.GetGortServerConfigs().APIURLBase
+		}
+		if root == "" {
+			return "", fmt.Errorf("gort.api_url_base must be set when running out of cluster")
+		}
+		return root, nil
+	}
+
+	gortIP, gortPort, err := w.findGortEndpoint(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%d", gortIP, gortPort), nil
+}
+
 // findGortEndpoint uses the Kubernetes API to look for Gort's API endpoint.
-// It will return an error if it doesn't have permission to "get" endpoint
-// resources in the active namespace.
+// It will return an error if it doesn't have permission to "list" endpoint
+// resources in Gort's namespace.
 func (w *KubernetesWorker) findGortEndpoint(ctx context.Context) (string, int32, error) {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	ctx, sp := tr.Start(ctx, "worker.kubernetes.findGortEndpoint")
 	defer sp.End()
 
-	epInterface := w.clientset.CoreV1().Endpoints(w.namespace)
+	epInterface := w.clientset.CoreV1().Endpoints(w.gortNamespace)
 	fieldSelector := config.GetKubernetesConfigs().EndpointFieldSelector
 	labelSelector := config.GetKubernetesConfigs().EndpointFieldSelector
 
@@ -333,8 +585,8 @@ func (w *KubernetesWorker) findGortPod(ctx context.Context) (*corev1.Pod, error)
 
 	name := os.Getenv("GORT_POD_NAME")
 
-	// The first time this is used, w.namespace may not be defined yet.
-	namespace := w.namespace
+	// If Gort's namespace wasn't configured, the Downward API may provide it.
+	namespace := w.gortNamespace
 	if namespace == "" {
 		namespace = os.Getenv("GORT_POD_NAMESPACE")
 	}
@@ -430,7 +682,7 @@ func (w *KubernetesWorker) getJobOutput(ctx context.Context) (<-chan string, err
 // watchForPodTermination watches for changes in the job's pod. When its
 // container process terminates, it sends the exit code to w.exitStatus.
 // An error is returned if the current service account lacks permissions to
-// watch on pods in the working namespace.
+// watch on pods in the job namespace.
 func (w *KubernetesWorker) watchForPodTermination(ctx context.Context) error {
 	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
 	_, sp := tr.Start(ctx, "worker.kubernetes.watchForPodTermination")
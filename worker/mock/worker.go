@@ -0,0 +1,157 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/telemetry"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MockWorker is a worker that doesn't run a container. Instead, it returns
+// the canned response configured for its command. It has a lifetime of a
+// single command execution.
+type MockWorker struct {
+	command    data.CommandRequest
+	response   data.MockResponse
+	exitStatus chan int64
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+// New will build and return a new MockWorker for a single command
+// execution, using the first of the configured responses that matches the
+// command.
+func New(command data.CommandRequest, token rest.Token, configs data.MockConfigs) (*MockWorker, error) {
+	response, err := FindResponse(configs, command)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MockWorker{
+		command:    command,
+		response:   response,
+		exitStatus: make(chan int64, 1),
+		stop:       make(chan struct{}),
+	}, nil
+}
+
+// FindResponse returns the first of the configured responses that matches
+// the command, or the default response if none do. An error is returned if
+// a response has an invalid Matches expression.
+func FindResponse(configs data.MockConfigs, command data.CommandRequest) (data.MockResponse, error) {
+	name := command.Bundle.Name + ":" + command.Command.Name
+	params := command.Parameters.String()
+
+	for _, r := range configs.Responses {
+		if r.Command != "" && r.Command != name {
+			continue
+		}
+
+		if r.Matches != "" {
+			re, err := regexp.Compile(r.Matches)
+			if err != nil {
+				return data.MockResponse{}, fmt.Errorf("invalid mock response expression for %s: %w", name, err)
+			}
+			if !re.MatchString(params) {
+				continue
+			}
+		}
+
+		return r, nil
+	}
+
+	return configs.Default, nil
+}
+
+// Initialize does nothing: canned responses don't depend on dynamic
+// configuration.
+func (w *MockWorker) Initialize(dc []data.DynamicConfiguration) {}
+
+// Start "executes" the command. After the response's delay, the returned
+// channel emits its output lines and closes, and the exit code is sent to
+// the Stopped channel.
+func (w *MockWorker) Start(ctx context.Context) (<-chan string, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "worker.mock.Start")
+	defer sp.End()
+
+	sp.SetAttributes(
+		attribute.String("command", w.command.Bundle.Name+":"+w.command.Command.Name),
+		attribute.Int64("exit_code", w.response.ExitCode),
+	)
+
+	log.WithField("bundle", w.command.Bundle.Name).
+		WithField("command", w.command.Command.Name).
+		WithField("exit_code", w.response.ExitCode).
+		Debug("Starting mock worker")
+
+	lines := append([]string{}, w.response.Output...)
+	if w.response.Echo {
+		lines = append(lines, w.command.Parameters.String())
+	}
+
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+
+		select {
+		case <-time.After(w.response.Delay):
+		case <-ctx.Done():
+			return
+		case <-w.stop:
+			return
+		}
+
+		for _, line := range lines {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+				return
+			case <-w.stop:
+				return
+			}
+		}
+
+		w.exitStatus <- w.response.ExitCode
+	}()
+
+	return out, nil
+}
+
+// Stop stops a running worker. The timeout is ignored: a mock worker always
+// stops immediately.
+func (w *MockWorker) Stop(ctx context.Context, timeout *time.Duration) {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+// Stopped returns a channel that receives the command's exit code once its
+// output is complete.
+func (w *MockWorker) Stopped() <-chan int64 {
+	return w.exitStatus
+}
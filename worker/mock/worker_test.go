@@ -0,0 +1,102 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfigs = data.MockConfigs{
+	Responses: []data.MockResponse{
+		{Command: "test:deploy", Matches: "^prod", Output: []string{"denied"}, ExitCode: 1},
+		{Command: "test:deploy", Output: []string{"deploying", "done"}},
+		{Command: "test:slow", Delay: time.Hour},
+	},
+	Default: data.MockResponse{Output: []string{"default"}, Echo: true},
+}
+
+func request(bundle, command string, params ...string) data.CommandRequest {
+	r := data.CommandRequest{Parameters: params}
+	r.Bundle.Name = bundle
+	r.Command.Name = command
+	return r
+}
+
+func run(ctx context.Context, t *testing.T, r data.CommandRequest) ([]string, int64, bool) {
+	w, err := New(r, rest.Token{}, testConfigs)
+	require.NoError(t, err)
+
+	out, err := w.Start(ctx)
+	require.NoError(t, err)
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+
+	select {
+	case code := <-w.Stopped():
+		return lines, code, true
+	default:
+		return lines, 0, false
+	}
+}
+
+func TestMockWorker(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Request  data.CommandRequest
+		Lines    []string
+		ExitCode int64
+	}{
+		{"Matches", request("test", "deploy", "prod", "v1"), []string{"denied"}, 1},
+		{"Command only", request("test", "deploy", "staging"), []string{"deploying", "done"}, 0},
+		{"Default", request("test", "other", "a", "b"), []string{"default", "a b"}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			lines, code, stopped := run(context.Background(), t, test.Request)
+			assert.True(t, stopped)
+			assert.Equal(t, test.Lines, lines)
+			assert.Equal(t, test.ExitCode, code)
+		})
+	}
+}
+
+func TestMockWorkerTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	lines, _, stopped := run(ctx, t, request("test", "slow"))
+	assert.False(t, stopped)
+	assert.Empty(t, lines)
+}
+
+func TestMockWorkerBadExpression(t *testing.T) {
+	configs := data.MockConfigs{Responses: []data.MockResponse{{Matches: "("}}}
+
+	_, err := New(request("test", "cmd"), rest.Token{}, configs)
+	assert.Error(t, err)
+}
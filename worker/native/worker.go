@@ -0,0 +1,304 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package native
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/telemetry"
+)
+
+// NativeWorker is a worker that executes a command as a subprocess on the
+// Gort host. It has a lifetime of a single command execution.
+type NativeWorker struct {
+	command       data.CommandRequest
+	configs       map[string]string
+	exitStatus    chan int64
+	nativeConfigs data.NativeConfigs
+	token         rest.Token
+
+	cancel     context.CancelFunc
+	cancelOnce sync.Once
+}
+
+// New will build and return a new NativeWorker for a single command
+// execution. An error is returned if the command's executable isn't one of
+// the allowed executables.
+func New(command data.CommandRequest, token rest.Token, configs data.NativeConfigs) (*NativeWorker, error) {
+	entrypoint := command.EntryPoint()
+	if len(entrypoint) == 0 {
+		return nil, fmt.Errorf("command %s:%s doesn't define an executable", command.Bundle.Name, command.Command.Name)
+	}
+
+	if !configs.Allows(entrypoint[0]) {
+		return nil, fmt.Errorf("executable %s isn't in native.allowed_executables", entrypoint[0])
+	}
+
+	return &NativeWorker{
+		command:       command,
+		configs:       map[string]string{},
+		exitStatus:    make(chan int64, 1),
+		nativeConfigs: configs,
+		token:         token,
+	}, nil
+}
+
+func (w *NativeWorker) Initialize(dc []data.DynamicConfiguration) {
+	for _, c := range dc {
+		w.configs[c.Key] = c.Value
+	}
+}
+
+// Start executes the command. Output is streamed as it's written, with
+// stdout and stderr combined. Once the command exits, the output channel is
+// closed and its exit code is sent to the Stopped channel.
+func (w *NativeWorker) Start(ctx context.Context) (<-chan string, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "worker.native.Start")
+	defer sp.End()
+
+	sp.SetAttributes(
+		attribute.String("command", w.command.Bundle.Name+":"+w.command.Command.Name),
+	)
+
+	// The temporary directory holds the input file, if any, and is the
+	// working directory if none is configured.
+	tmp, err := ioutil.TempDir("", "gort-")
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := w.environment(tmp)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	args := w.args()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = env
+	cmd.Dir = w.nativeConfigs.WorkingDirectory
+	if cmd.Dir == "" {
+		cmd.Dir = tmp
+	}
+
+	// Both streams are written to the same pipe, so that their lines are
+	// interleaved in the order they were written.
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	log.WithField("bundle", w.command.Bundle.Name).
+		WithField("command", w.command.Command.Name).
+		Debug("Executing command natively")
+
+	if err := cmd.Start(); err != nil {
+		w.cancel()
+		os.RemoveAll(tmp)
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close()
+		waited <- err
+	}()
+
+	out := make(chan string)
+
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			select {
+			case out <- scanner.Text():
+			case <-ctx.Done():
+			}
+		}
+
+		// If the scanner gave up on an overlong line, the rest of the
+		// output must still be read for the command to finish.
+		io.Copy(ioutil.Discard, pr)
+
+		err := <-waited
+		os.RemoveAll(tmp)
+		w.cancel()
+		close(out)
+
+		w.exitStatus <- exitCode(err)
+	}()
+
+	return out, nil
+}
+
+// Stop kills the command. The timeout is ignored.
+func (w *NativeWorker) Stop(ctx context.Context, timeout *time.Duration) {
+	w.cancelOnce.Do(func() {
+		if w.cancel != nil {
+			w.cancel()
+		}
+	})
+}
+
+// Stopped returns a channel that receives the command's exit code once it
+// has exited.
+func (w *NativeWorker) Stopped() <-chan int64 {
+	return w.exitStatus
+}
+
+// args returns the command line to execute. If any limits are set, the
+// command is run by a shell that first applies them with ulimit.
+func (w *NativeWorker) args() []string {
+	args := append(append([]string{}, w.command.EntryPoint()...), w.command.Parameters...)
+
+	ulimits := ulimitArgs(w.nativeConfigs.Limits)
+	if len(ulimits) == 0 {
+		return args
+	}
+
+	script := "ulimit " + strings.Join(ulimits, " ") + ` && exec "$@"`
+	return append([]string{"/bin/sh", "-c", script, "sh"}, args...)
+}
+
+// ulimitArgs returns the ulimit options that apply the limits. The shell's
+// ulimit measures memory in kilobytes, and file sizes in 512-byte blocks.
+func ulimitArgs(l data.NativeLimits) []string {
+	var args []string
+
+	if l.CPUTime > 0 {
+		args = append(args, "-t", fmt.Sprint(int64(math.Ceil(l.CPUTime.Seconds()))))
+	}
+	if l.Memory > 0 {
+		args = append(args, "-v", fmt.Sprint((l.Memory+1023)/1024))
+	}
+	if l.FileSize > 0 {
+		args = append(args, "-f", fmt.Sprint((l.FileSize+511)/512))
+	}
+	if l.OpenFiles > 0 {
+		args = append(args, "-n", fmt.Sprint(l.OpenFiles))
+	}
+
+	return args
+}
+
+// environment returns the command's environment. Gort's own environment,
+// which may contain credentials, is not inherited: only the variables listed
+// in native.pass_env are passed along. If the command has an input file,
+// it's written into dir.
+func (w *NativeWorker) environment(dir string) ([]string, error) {
+	env := map[string]string{"PATH": data.DefaultNativePath}
+
+	for _, name := range w.nativeConfigs.PassEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			env[name] = v
+		}
+	}
+
+	for k, v := range w.configs {
+		env[k] = v
+	}
+
+	if p := w.command.ExecutionProfile(); p != nil {
+		for k, v := range p.Env {
+			env[k] = v
+		}
+	}
+
+	vars := map[string]string{
+		`GORT_ADAPTER`:         w.command.Adapter,
+		`GORT_BUNDLE`:          w.command.Bundle.Name,
+		`GORT_CHANNEL_NAME`:    w.command.ChannelName,
+		`GORT_COMMAND`:         w.command.Command.Name,
+		`GORT_CHAT_ID`:         w.command.UserID,
+		`GORT_INVOCATION_ID`:   fmt.Sprintf("%d", w.command.RequestID),
+		`GORT_INVOCATION_TEXT`: w.command.InvocationText,
+		`GORT_PROFILE`:         w.command.Profile,
+		`GORT_ROOM`:            w.command.ChannelID,
+		`GORT_SERVICE_TOKEN`:   w.token.Token,
+		`GORT_SERVICES_ROOT`:   config.GetGortServerConfigs().APIURLBase,
+		`GORT_USER`:            w.command.UserName,
+		`GORT_USER_DISPLAY`:    w.command.UserDisplayName,
+		`GORT_WORKER_TOKEN`:    w.command.WorkerToken,
+	}
+
+	if f := w.command.InputFile; f != nil {
+		path := filepath.Join(dir, f.FileName())
+		if err := ioutil.WriteFile(path, f.Data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write input file: %w", err)
+		}
+
+		for k, v := range f.EnvVars(true) {
+			vars[k] = v
+		}
+		vars[data.EnvInputFile] = path
+	}
+
+	for k, v := range vars {
+		env[k] = v
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = name + "=" + env[name]
+	}
+
+	return out, nil
+}
+
+// exitCode returns the exit code of a command, given the error returned by
+// waiting for it. If the exit code couldn't be determined (because the
+// command was killed by a signal, for example) it's 1.
+func exitCode(err error) int64 {
+	var exitErr *exec.ExitError
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return int64(exitErr.ExitCode())
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package native
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+func request(executable ...string) data.CommandRequest {
+	r := data.CommandRequest{}
+	r.Bundle.Name = "test"
+	r.Command.Name = "run"
+	r.Command.Executable = executable
+	return r
+}
+
+func run(t *testing.T, w *NativeWorker) ([]string, int64) {
+	out, err := w.Start(context.Background())
+	require.NoError(t, err)
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+
+	select {
+	case code := <-w.Stopped():
+		return lines, code
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker didn't stop")
+		return nil, 0
+	}
+}
+
+func TestNewNotAllowed(t *testing.T) {
+	configs := data.NativeConfigs{AllowedExecutables: []string{"/usr/bin/*"}}
+
+	_, err := New(request("/bin/sh", "-c", "true"), rest.Token{}, configs)
+	assert.Error(t, err)
+
+	_, err = New(request(), rest.Token{}, configs)
+	assert.Error(t, err)
+}
+
+func TestWorker(t *testing.T) {
+	r := request("/bin/sh", "-c", `echo "$1"; echo oops >&2; exit 3`, "sh")
+	r.Parameters = []string{"hello"}
+
+	w, err := New(r, rest.Token{}, data.NativeConfigs{AllowedExecutables: []string{"/bin/sh"}})
+	require.NoError(t, err)
+
+	lines, code := run(t, w)
+	assert.ElementsMatch(t, []string{"hello", "oops"}, lines)
+	assert.Equal(t, int64(3), code)
+}
+
+func TestWorkerEnvironment(t *testing.T) {
+	os.Setenv("GORT_TEST_SECRET", "xyzzy")
+	defer os.Unsetenv("GORT_TEST_SECRET")
+
+	r := request("/bin/sh", "-c", `echo "$GORT_BUNDLE:${GORT_TEST_SECRET:-scrubbed}:$API_KEY:$(cat "$GORT_INPUT_FILE")"`)
+	r.InputFile = &data.InputFile{Name: "in.txt", Data: []byte("input")}
+
+	w, err := New(r, rest.Token{}, data.NativeConfigs{AllowedExecutables: []string{"/bin/sh"}})
+	require.NoError(t, err)
+	w.Initialize([]data.DynamicConfiguration{{Key: "API_KEY", Value: "abc"}})
+
+	lines, code := run(t, w)
+	assert.Equal(t, []string{"test:scrubbed:abc:input"}, lines)
+	assert.Equal(t, int64(0), code)
+}
+
+func TestWorkerLimits(t *testing.T) {
+	configs := data.NativeConfigs{
+		AllowedExecutables: []string{"/bin/sh"},
+		Limits:             data.NativeLimits{OpenFiles: 64},
+	}
+
+	w, err := New(request("/bin/sh", "-c", "ulimit -n"), rest.Token{}, configs)
+	require.NoError(t, err)
+
+	lines, code := run(t, w)
+	assert.Equal(t, []string{"64"}, lines)
+	assert.Equal(t, int64(0), code)
+}
+
+func TestUlimitArgs(t *testing.T) {
+	assert.Empty(t, ulimitArgs(data.NativeLimits{}))
+
+	l := data.NativeLimits{CPUTime: 1500 * time.Millisecond, Memory: 1 << 20, FileSize: 1000, OpenFiles: 32}
+	assert.Equal(t, []string{"-t", "2", "-v", "1024", "-f", "2", "-n", "32"}, ulimitArgs(l))
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getgort/gort/data"
+)
+
+const (
+	// DefaultCloudRunPollInterval is used if serverless.cloud_run.poll_interval
+	// isn't set.
+	DefaultCloudRunPollInterval = 2 * time.Second
+
+	cloudRunEndpoint   = "https://run.googleapis.com"
+	loggingEndpoint    = "https://logging.googleapis.com"
+	gceMetadataToken   = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	googleTokenEnvName = "GOOGLE_OAUTH_ACCESS_TOKEN"
+)
+
+// CloudRunBackend invokes commands as Google Cloud Run job executions.
+//
+// The job's container is run with the command's executable and parameters
+// as its arguments, and the command's environment added to its own. Once
+// the execution completes, its output is read from Cloud Logging. An
+// execution in which any task failed gives an exit code of 1.
+type CloudRunBackend struct {
+	Client          *http.Client
+	Endpoint        string
+	LoggingEndpoint string
+	Location        string
+	PollInterval    time.Duration
+	Project         string
+
+	token func(ctx context.Context) (string, error)
+}
+
+// NewCloudRunBackend returns a CloudRunBackend for the configured project
+// and location.
+func NewCloudRunBackend(c data.CloudRunConfigs) *CloudRunBackend {
+	b := &CloudRunBackend{
+		Client:          http.DefaultClient,
+		Endpoint:        strings.TrimSuffix(c.Endpoint, "/"),
+		LoggingEndpoint: strings.TrimSuffix(c.LoggingEndpoint, "/"),
+		Location:        c.Location,
+		PollInterval:    c.PollInterval,
+		Project:         c.Project,
+	}
+
+	if b.Endpoint == "" {
+		b.Endpoint = cloudRunEndpoint
+	}
+	if b.LoggingEndpoint == "" {
+		b.LoggingEndpoint = loggingEndpoint
+	}
+	if b.PollInterval <= 0 {
+		b.PollInterval = DefaultCloudRunPollInterval
+	}
+	b.token = b.googleToken
+
+	return b
+}
+
+type cloudRunEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cloudRunOperation struct {
+	Name     string `json:"name"`
+	Done     bool   `json:"done"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Response struct {
+		SucceededCount int64 `json:"succeededCount"`
+		FailedCount    int64 `json:"failedCount"`
+		CancelledCount int64 `json:"cancelledCount"`
+	} `json:"response"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Invoke runs the job named by inv and waits for its execution to complete.
+func (b *CloudRunBackend) Invoke(ctx context.Context, inv Invocation) (Result, error) {
+	names := make([]string, 0, len(inv.Env))
+	for name := range inv.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make([]cloudRunEnvVar, 0, len(names))
+	for _, name := range names {
+		env = append(env, cloudRunEnvVar{name, inv.Env[name]})
+	}
+
+	args := append(append([]string{}, inv.Command...), inv.Parameters...)
+
+	body := map[string]interface{}{
+		"overrides": map[string]interface{}{
+			"containerOverrides": []interface{}{
+				map[string]interface{}{"args": args, "env": env},
+			},
+		},
+	}
+
+	job := fmt.Sprintf("projects/%s/locations/%s/jobs/%s", b.Project, b.Location, inv.Name)
+
+	var op cloudRunOperation
+	if err := b.do(ctx, http.MethodPost, b.Endpoint+"/v2/"+job+":run", body, &op); err != nil {
+		return Result{}, fmt.Errorf("failed to run cloud run job %s: %w", inv.Name, err)
+	}
+
+	for !op.Done {
+		select {
+		case <-ctx.Done():
+			return Result{}, ctx.Err()
+		case <-time.After(b.PollInterval):
+		}
+
+		if err := b.do(ctx, http.MethodGet, b.Endpoint+"/v2/"+op.Name, nil, &op); err != nil {
+			return Result{}, fmt.Errorf("failed to get cloud run operation %s: %w", op.Name, err)
+		}
+	}
+
+	if op.Error != nil {
+		return Result{}, fmt.Errorf("cloud run job %s failed: %s", inv.Name, op.Error.Message)
+	}
+
+	output, err := b.logs(ctx, path.Base(op.Metadata.Name))
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Output: output}
+	if op.Response.FailedCount > 0 || op.Response.CancelledCount > 0 {
+		result.ExitCode = 1
+	}
+
+	return result, nil
+}
+
+// logs returns the text of every log entry written by the execution, in
+// order.
+func (b *CloudRunBackend) logs(ctx context.Context, execution string) ([]string, error) {
+	filter := fmt.Sprintf(`resource.type="cloud_run_job" AND labels."run.googleapis.com/execution_name"=%q`, execution)
+
+	var lines []string
+	var pageToken string
+
+	for {
+		body := map[string]interface{}{
+			"resourceNames": []string{"projects/" + b.Project},
+			"filter":        filter,
+			"orderBy":       "timestamp asc",
+			"pageSize":      1000,
+		}
+		if pageToken != "" {
+			body["pageToken"] = pageToken
+		}
+
+		var page struct {
+			Entries []struct {
+				TextPayload string `json:"textPayload"`
+			} `json:"entries"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+
+		if err := b.do(ctx, http.MethodPost, b.LoggingEndpoint+"/v2/entries:list", body, &page); err != nil {
+			return nil, fmt.Errorf("failed to read logs for cloud run execution %s: %w", execution, err)
+		}
+
+		for _, e := range page.Entries {
+			lines = append(lines, splitLines(e.TextPayload)...)
+		}
+
+		if page.NextPageToken == "" {
+			return lines, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// do sends an authenticated JSON request and decodes the response into out.
+func (b *CloudRunBackend) do(ctx context.Context, method, url string, in, out interface{}) error {
+	token, err := b.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// googleToken returns an access token from the environment or, failing
+// that, from the GCE metadata server.
+func (b *CloudRunBackend) googleToken(ctx context.Context) (string, error) {
+	if token := os.Getenv(googleTokenEnvName); token != "" {
+		return token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gceMetadataToken, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get access token: %s not set and metadata server unavailable: %w", googleTokenEnvName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get access token from metadata server: %s", resp.Status)
+	}
+
+	var t struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", err
+	}
+
+	return t.AccessToken, nil
+}
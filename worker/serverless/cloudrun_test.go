@@ -0,0 +1,123 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+)
+
+func TestCloudRunInvoke(t *testing.T) {
+	const execution = "projects/p/locations/l/jobs/echo/executions/echo-abc12"
+
+	var overrides struct {
+		Overrides struct {
+			ContainerOverrides []struct {
+				Args []string         `json:"args"`
+				Env  []cloudRunEnvVar `json:"env"`
+			} `json:"containerOverrides"`
+		} `json:"overrides"`
+	}
+
+	polls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/projects/p/locations/l/jobs/echo:run":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&overrides))
+			fmt.Fprintf(w, `{"name":"projects/p/locations/l/operations/op1","metadata":{"name":%q}}`, execution)
+
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/projects/p/locations/l/operations/op1":
+			polls++
+			if polls < 2 {
+				fmt.Fprintf(w, `{"name":"projects/p/locations/l/operations/op1","metadata":{"name":%q}}`, execution)
+				return
+			}
+			fmt.Fprintf(w, `{"name":"projects/p/locations/l/operations/op1","done":true,"metadata":{"name":%q},"response":{"failedCount":1}}`, execution)
+
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/entries:list":
+			var req struct {
+				Filter    string `json:"filter"`
+				PageToken string `json:"pageToken"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Contains(t, req.Filter, `"run.googleapis.com/execution_name"="echo-abc12"`)
+
+			if req.PageToken == "" {
+				w.Write([]byte(`{"entries":[{"textPayload":"hello"}],"nextPageToken":"next"}`))
+				return
+			}
+			w.Write([]byte(`{"entries":[{"textPayload":"world\n"}]}`))
+
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := NewCloudRunBackend(data.CloudRunConfigs{
+		Project:         "p",
+		Location:        "l",
+		Endpoint:        server.URL,
+		LoggingEndpoint: server.URL,
+		PollInterval:    time.Millisecond,
+	})
+	b.Client = server.Client()
+	b.token = func(context.Context) (string, error) { return "test-token", nil }
+
+	result, err := b.Invoke(context.Background(), Invocation{
+		Name:       "echo",
+		Command:    []string{"/bin/echo"},
+		Parameters: []string{"hello", "world"},
+		Env:        map[string]string{"GORT_BUNDLE": "test"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Result{Output: []string{"hello", "world"}, ExitCode: 1}, result)
+	assert.Equal(t, 2, polls)
+
+	require.Len(t, overrides.Overrides.ContainerOverrides, 1)
+	assert.Equal(t, []string{"/bin/echo", "hello", "world"}, overrides.Overrides.ContainerOverrides[0].Args)
+	assert.Equal(t, []cloudRunEnvVar{{"GORT_BUNDLE", "test"}}, overrides.Overrides.ContainerOverrides[0].Env)
+}
+
+func TestCloudRunInvokeOperationError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"op1","done":true,"error":{"code":5,"message":"job not found"}}`))
+	}))
+	defer server.Close()
+
+	b := NewCloudRunBackend(data.CloudRunConfigs{Project: "p", Location: "l", Endpoint: server.URL})
+	b.Client = server.Client()
+	b.token = func(context.Context) (string, error) { return "test-token", nil }
+
+	_, err := b.Invoke(context.Background(), Invocation{Name: "missing"})
+	assert.EqualError(t, err, "cloud run job missing failed: job not found")
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/getgort/gort/data"
+)
+
+// LambdaBackend invokes commands as AWS Lambda functions.
+//
+// The function receives a JSON payload with "command", "parameters", and
+// "env" fields. It may return an object with "output" (a string or a list
+// of lines) and "exit_code" fields; any other response is treated as the
+// command's output, with an exit code of 0. A function error (an uncaught
+// exception, for example) gives an exit code of 1.
+type LambdaBackend struct {
+	Endpoint string
+	Region   string
+
+	loadConfig func(ctx context.Context) (aws.Config, error)
+}
+
+// NewLambdaBackend returns a LambdaBackend for the configured region.
+// Credentials are found by the AWS SDK's default chain.
+func NewLambdaBackend(c data.LambdaConfigs) *LambdaBackend {
+	b := &LambdaBackend{
+		Endpoint: strings.TrimSuffix(c.Endpoint, "/"),
+		Region:   c.Region,
+	}
+
+	b.loadConfig = func(ctx context.Context) (aws.Config, error) {
+		return config.LoadDefaultConfig(ctx, config.WithRegion(b.Region))
+	}
+
+	return b
+}
+
+type lambdaPayload struct {
+	Command    []string          `json:"command"`
+	Parameters []string          `json:"parameters"`
+	Env        map[string]string `json:"env"`
+}
+
+type lambdaResponse struct {
+	Output   json.RawMessage `json:"output"`
+	ExitCode *int64          `json:"exit_code"`
+}
+
+// Invoke synchronously invokes the function named by inv.
+func (b *LambdaBackend) Invoke(ctx context.Context, inv Invocation) (Result, error) {
+	cfg, err := b.loadConfig(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := lambda.NewFromConfig(cfg, func(o *lambda.Options) {
+		if b.Endpoint != "" {
+			o.EndpointResolver = lambda.EndpointResolverFromURL(b.Endpoint)
+		}
+	})
+
+	payload, err := json.Marshal(lambdaPayload{inv.Command, inv.Parameters, inv.Env})
+	if err != nil {
+		return Result{}, err
+	}
+
+	out, err := client.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(inv.Name),
+		InvocationType: types.InvocationTypeRequestResponse,
+		Payload:        payload,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to invoke lambda function %s: %w", inv.Name, err)
+	}
+
+	if aws.ToString(out.FunctionError) != "" {
+		return Result{Output: splitLines(string(out.Payload)), ExitCode: 1}, nil
+	}
+
+	return parseLambdaResponse(out.Payload), nil
+}
+
+func parseLambdaResponse(payload []byte) Result {
+	var r lambdaResponse
+	if err := json.Unmarshal(payload, &r); err != nil || (r.Output == nil && r.ExitCode == nil) {
+		return Result{Output: splitLines(string(payload))}
+	}
+
+	var result Result
+	if r.ExitCode != nil {
+		result.ExitCode = *r.ExitCode
+	}
+
+	var lines []string
+	var text string
+	switch {
+	case json.Unmarshal(r.Output, &lines) == nil:
+		result.Output = lines
+	case json.Unmarshal(r.Output, &text) == nil:
+		result.Output = splitLines(text)
+	}
+
+	return result
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverless
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLambdaResponse(t *testing.T) {
+	tests := []struct {
+		Payload  string
+		Expected Result
+	}{
+		{`{"output":"foo\nbar\n","exit_code":0}`, Result{Output: []string{"foo", "bar"}}},
+		{`{"output":["foo","bar"],"exit_code":2}`, Result{Output: []string{"foo", "bar"}, ExitCode: 2}},
+		{`"just a string"`, Result{Output: []string{`"just a string"`}}},
+		{`{"unrelated":true}`, Result{Output: []string{`{"unrelated":true}`}}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.Expected, parseLambdaResponse([]byte(test.Payload)), test.Payload)
+	}
+}
+
+func TestLambdaInvoke(t *testing.T) {
+	var payload lambdaPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/2015-03-31/functions/echo/invocations", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		if payload.Parameters[0] == "fail" {
+			w.Header().Set("X-Amz-Function-Error", "Unhandled")
+			w.Write([]byte(`{"errorMessage":"boom"}`))
+			return
+		}
+
+		w.Write([]byte(`{"output":"hello\nworld","exit_code":0}`))
+	}))
+	defer server.Close()
+
+	b := &LambdaBackend{
+		Endpoint: server.URL,
+		Region:   "us-east-1",
+		loadConfig: func(ctx context.Context) (aws.Config, error) {
+			return aws.Config{
+				Region: "us-east-1",
+				Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+					return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+				}),
+			}, nil
+		},
+	}
+
+	inv := Invocation{
+		Name:       "echo",
+		Command:    []string{"/bin/echo"},
+		Parameters: []string{"hello", "world"},
+		Env:        map[string]string{"GORT_BUNDLE": "test"},
+	}
+
+	result, err := b.Invoke(context.Background(), inv)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Output: []string{"hello", "world"}}, result)
+	assert.Equal(t, []string{"/bin/echo"}, payload.Command)
+	assert.Equal(t, "test", payload.Env["GORT_BUNDLE"])
+
+	inv.Parameters = []string{"fail"}
+	result, err = b.Invoke(context.Background(), inv)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Output: []string{`{"errorMessage":"boom"}`}, ExitCode: 1}, result)
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverless
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/telemetry"
+)
+
+// Invocation describes a single command execution by a serverless backend.
+type Invocation struct {
+	// Name is the function or job to invoke.
+	Name string
+
+	// Command is the command's executable, from the bundle, and Parameters
+	// are the parameters that it was invoked with.
+	Command    []string
+	Parameters []string
+
+	// Env is the command's environment.
+	Env map[string]string
+}
+
+// Result is the outcome of an Invocation.
+type Result struct {
+	Output   []string
+	ExitCode int64
+}
+
+// Backend invokes commands on a serverless platform. Invoke blocks until
+// the command completes or ctx is canceled.
+type Backend interface {
+	Invoke(ctx context.Context, inv Invocation) (Result, error)
+}
+
+// ServerlessWorker is a worker that executes a command by invoking a
+// serverless function or job. It has a lifetime of a single command
+// execution.
+type ServerlessWorker struct {
+	backend    Backend
+	command    data.CommandRequest
+	configs    map[string]string
+	exitStatus chan int64
+	name       string
+	token      rest.Token
+
+	cancel     context.CancelFunc
+	cancelOnce sync.Once
+}
+
+// New will build and return a new ServerlessWorker for a single command
+// execution. The bundle's serverless section determines which backend is
+// used.
+func New(command data.CommandRequest, token rest.Token, configs data.ServerlessConfigs) (*ServerlessWorker, error) {
+	var backend Backend
+	var name string
+
+	switch s := command.Bundle.Serverless; {
+	case s.Function != "":
+		backend, name = NewLambdaBackend(configs.Lambda), s.Function
+	case s.Job != "":
+		backend, name = NewCloudRunBackend(configs.CloudRun), s.Job
+	default:
+		return nil, fmt.Errorf("bundle %s doesn't define a serverless function or job", command.Bundle.Name)
+	}
+
+	return NewWithBackend(command, token, backend, name), nil
+}
+
+// NewWithBackend builds a ServerlessWorker that invokes name using backend.
+func NewWithBackend(command data.CommandRequest, token rest.Token, backend Backend, name string) *ServerlessWorker {
+	return &ServerlessWorker{
+		backend:    backend,
+		command:    command,
+		configs:    map[string]string{},
+		exitStatus: make(chan int64, 1),
+		name:       name,
+		token:      token,
+	}
+}
+
+func (w *ServerlessWorker) Initialize(dc []data.DynamicConfiguration) {
+	for _, c := range dc {
+		w.configs[c.Key] = c.Value
+	}
+}
+
+// Start invokes the function or job. The returned channel emits the
+// command's output once it completes, and then closes; the exit code is
+// then sent to the Stopped channel.
+func (w *ServerlessWorker) Start(ctx context.Context) (<-chan string, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "worker.serverless.Start")
+	defer sp.End()
+
+	sp.SetAttributes(
+		attribute.String("command", w.command.Bundle.Name+":"+w.command.Command.Name),
+		attribute.String("serverless.name", w.name),
+	)
+
+	log.WithField("bundle", w.command.Bundle.Name).
+		WithField("command", w.command.Command.Name).
+		WithField("name", w.name).
+		Debug("Invoking serverless command")
+
+	// Functions and jobs can't be given files, so an input file is passed
+	// in the environment, which has a limited size.
+	if f := w.command.InputFile; f != nil {
+		if max := config.GetGlobalConfigs().InputFiles.MaxEnvSizeOrDefault(); len(f.Data) > max {
+			return nil, fmt.Errorf("input file is %d bytes; serverless commands accept at most %d", len(f.Data), max)
+		}
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	inv := Invocation{
+		Name:       w.name,
+		Command:    w.command.EntryPoint(),
+		Parameters: w.command.Parameters,
+		Env:        w.envVars(),
+	}
+
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+		defer w.cancel()
+
+		start := time.Now()
+		result, err := w.backend.Invoke(ctx, inv)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.WithError(err).WithField("name", w.name).Error("Serverless invocation failed")
+			result = Result{Output: []string{err.Error()}, ExitCode: 1}
+		}
+
+		log.WithField("name", w.name).
+			WithField("exit_code", result.ExitCode).
+			WithField("duration", time.Since(start)).
+			Debug("Serverless command complete")
+
+		for _, line := range result.Output {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		w.exitStatus <- result.ExitCode
+	}()
+
+	return out, nil
+}
+
+// Stop cancels a running invocation. The timeout is ignored. A Lambda
+// invocation that's already running can't be stopped: it runs to completion
+// but its result is discarded.
+func (w *ServerlessWorker) Stop(ctx context.Context, timeout *time.Duration) {
+	w.cancelOnce.Do(func() {
+		if w.cancel != nil {
+			w.cancel()
+		}
+	})
+}
+
+// Stopped returns a channel that receives the command's exit code once its
+// output is complete.
+func (w *ServerlessWorker) Stopped() <-chan int64 {
+	return w.exitStatus
+}
+
+func (w *ServerlessWorker) envVars() map[string]string {
+	env := map[string]string{}
+
+	for k, v := range w.configs {
+		env[k] = v
+	}
+
+	if p := w.command.ExecutionProfile(); p != nil {
+		for k, v := range p.Env {
+			env[k] = v
+		}
+	}
+
+	vars := map[string]string{
+		`GORT_ADAPTER`:         w.command.Adapter,
+		`GORT_BUNDLE`:          w.command.Bundle.Name,
+		`GORT_CHANNEL_NAME`:    w.command.ChannelName,
+		`GORT_COMMAND`:         w.command.Command.Name,
+		`GORT_CHAT_ID`:         w.command.UserID,
+		`GORT_INVOCATION_ID`:   fmt.Sprintf("%d", w.command.RequestID),
+		`GORT_INVOCATION_TEXT`: w.command.InvocationText,
+		`GORT_PROFILE`:         w.command.Profile,
+		`GORT_ROOM`:            w.command.ChannelID,
+		`GORT_SERVICE_TOKEN`:   w.token.Token,
+		`GORT_SERVICES_ROOT`:   config.GetGortServerConfigs().APIURLBase,
+		`GORT_USER`:            w.command.UserName,
+		`GORT_USER_DISPLAY`:    w.command.UserDisplayName,
+		`GORT_WORKER_TOKEN`:    w.command.WorkerToken,
+	}
+
+	if f := w.command.InputFile; f != nil {
+		for k, v := range f.EnvVars(false) {
+			vars[k] = v
+		}
+	}
+
+	for k, v := range vars {
+		env[k] = v
+	}
+
+	return env
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverless
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+type fakeBackend struct {
+	invocation Invocation
+	result     Result
+	err        error
+	block      bool
+}
+
+func (b *fakeBackend) Invoke(ctx context.Context, inv Invocation) (Result, error) {
+	b.invocation = inv
+	if b.block {
+		<-ctx.Done()
+		return Result{}, ctx.Err()
+	}
+	return b.result, b.err
+}
+
+func request() data.CommandRequest {
+	r := data.CommandRequest{Parameters: []string{"foo", "bar"}}
+	r.Bundle.Name = "test"
+	r.Command.Name = "echo"
+	r.Command.Executable = []string{"/bin/echo"}
+	return r
+}
+
+func run(t *testing.T, w *ServerlessWorker) ([]string, int64) {
+	out, err := w.Start(context.Background())
+	require.NoError(t, err)
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+
+	select {
+	case code := <-w.Stopped():
+		return lines, code
+	case <-time.After(time.Second):
+		t.Fatal("worker didn't report an exit code")
+		return nil, 0
+	}
+}
+
+func TestNew(t *testing.T) {
+	r := request()
+
+	_, err := New(r, rest.Token{}, data.ServerlessConfigs{})
+	assert.Error(t, err)
+
+	r.Bundle.Serverless.Function = "echo"
+	w, err := New(r, rest.Token{}, data.ServerlessConfigs{})
+	require.NoError(t, err)
+	assert.IsType(t, &LambdaBackend{}, w.backend)
+
+	r.Bundle.Serverless = data.BundleServerless{Job: "echo"}
+	w, err = New(r, rest.Token{}, data.ServerlessConfigs{})
+	require.NoError(t, err)
+	assert.IsType(t, &CloudRunBackend{}, w.backend)
+}
+
+func TestWorkerOutput(t *testing.T) {
+	b := &fakeBackend{result: Result{Output: []string{"foo", "bar"}, ExitCode: 3}}
+	w := NewWithBackend(request(), rest.Token{Token: "secret"}, b, "echo")
+	w.Initialize([]data.DynamicConfiguration{{Key: "API_KEY", Value: "xyzzy"}})
+
+	lines, code := run(t, w)
+	assert.Equal(t, []string{"foo", "bar"}, lines)
+	assert.Equal(t, int64(3), code)
+
+	assert.Equal(t, "echo", b.invocation.Name)
+	assert.Equal(t, []string{"/bin/echo"}, b.invocation.Command)
+	assert.Equal(t, []string{"foo", "bar"}, b.invocation.Parameters)
+	assert.Equal(t, "secret", b.invocation.Env["GORT_SERVICE_TOKEN"])
+	assert.Equal(t, "xyzzy", b.invocation.Env["API_KEY"])
+}
+
+func TestWorkerInputFile(t *testing.T) {
+	r := request()
+	r.InputFile = &data.InputFile{Name: "in.txt", Data: []byte("hello\n")}
+
+	b := &fakeBackend{}
+	run(t, NewWithBackend(r, rest.Token{}, b, "echo"))
+
+	assert.Equal(t, "aGVsbG8K", b.invocation.Env["GORT_INPUT_FILE_BASE64"])
+	assert.Equal(t, "in.txt", b.invocation.Env["GORT_INPUT_FILE_NAME"])
+	assert.NotContains(t, b.invocation.Env, "GORT_INPUT_FILE")
+
+	r.InputFile.Data = make([]byte, data.DefaultInputFileMaxEnvSize+1)
+	_, err := NewWithBackend(r, rest.Token{}, b, "echo").Start(context.Background())
+	assert.Error(t, err)
+}
+
+func TestWorkerBackendError(t *testing.T) {
+	b := &fakeBackend{err: errors.New("function not found")}
+	w := NewWithBackend(request(), rest.Token{}, b, "echo")
+
+	lines, code := run(t, w)
+	assert.Equal(t, []string{"function not found"}, lines)
+	assert.Equal(t, int64(1), code)
+}
+
+func TestWorkerStop(t *testing.T) {
+	w := NewWithBackend(request(), rest.Token{}, &fakeBackend{block: true}, "echo")
+
+	out, err := w.Start(context.Background())
+	require.NoError(t, err)
+
+	w.Stop(context.Background(), nil)
+
+	select {
+	case _, ok := <-out:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("output channel wasn't closed")
+	}
+}
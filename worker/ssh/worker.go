@@ -0,0 +1,485 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	xssh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/getgort/gort/config"
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+	"github.com/getgort/gort/telemetry"
+)
+
+// DefaultConnectTimeout is used if ssh.connect_timeout isn't set.
+const DefaultConnectTimeout = 10 * time.Second
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SSHWorker is a worker that executes a command over SSH on each of the
+// hosts selected by its bundle. It has a lifetime of a single command
+// execution.
+type SSHWorker struct {
+	command    data.CommandRequest
+	configs    map[string]string
+	exitStatus chan int64
+	hosts      []data.SSHHost
+	sshConfigs data.SSHConfigs
+	token      rest.Token
+
+	cancel     context.CancelFunc
+	cancelOnce sync.Once
+}
+
+// New will build and return a new SSHWorker for a single command execution.
+func New(command data.CommandRequest, token rest.Token, configs data.SSHConfigs) (*SSHWorker, error) {
+	if len(command.EntryPoint()) == 0 {
+		return nil, fmt.Errorf("command %s:%s doesn't define an executable", command.Bundle.Name, command.Command.Name)
+	}
+
+	hosts, err := configs.HostsFor(command.Bundle.SSH)
+	if err != nil {
+		return nil, fmt.Errorf("bundle %s: %w", command.Bundle.Name, err)
+	}
+
+	return &SSHWorker{
+		command:    command,
+		configs:    map[string]string{},
+		exitStatus: make(chan int64, 1),
+		hosts:      hosts,
+		sshConfigs: configs,
+		token:      token,
+	}, nil
+}
+
+func (w *SSHWorker) Initialize(dc []data.DynamicConfiguration) {
+	for _, c := range dc {
+		w.configs[c.Key] = c.Value
+	}
+}
+
+// Start executes the command on every selected host concurrently. Output is
+// streamed as it's written, with stdout and stderr combined; if there's
+// more than one host, each line is prefixed with the host's name. Once
+// every host has finished, the output channel is closed and the first
+// non-zero exit code, in host order, is sent to the Stopped channel.
+func (w *SSHWorker) Start(ctx context.Context) (<-chan string, error) {
+	tr := otel.GetTracerProvider().Tracer(telemetry.ServiceName)
+	_, sp := tr.Start(ctx, "worker.ssh.Start")
+	defer sp.End()
+
+	names := make([]string, len(w.hosts))
+	for i, h := range w.hosts {
+		names[i] = h.Name
+	}
+
+	sp.SetAttributes(
+		attribute.String("command", w.command.Bundle.Name+":"+w.command.Command.Name),
+		attribute.String("ssh.hosts", strings.Join(names, ",")),
+	)
+
+	hostKeys, err := w.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	script := w.script()
+	out := make(chan string)
+	codes := make([]int64, len(w.hosts))
+
+	wg := sync.WaitGroup{}
+	for i, host := range w.hosts {
+		prefix := ""
+		if len(w.hosts) > 1 {
+			prefix = host.Name + ": "
+		}
+
+		wg.Add(1)
+		go func(i int, host data.SSHHost) {
+			defer wg.Done()
+
+			code, err := w.run(ctx, host, hostKeys, script, prefix, out)
+			if err != nil {
+				log.WithError(err).WithField("host", host.Name).Error("SSH command execution failed")
+				if ctx.Err() == nil {
+					send(ctx, out, prefix+err.Error())
+				}
+			}
+			codes[i] = code
+		}(i, host)
+	}
+
+	go func() {
+		wg.Wait()
+		w.cancel()
+		close(out)
+
+		for _, code := range codes {
+			if code != 0 {
+				w.exitStatus <- code
+				return
+			}
+		}
+		w.exitStatus <- 0
+	}()
+
+	return out, nil
+}
+
+// Stop closes every open connection, which terminates the remote commands.
+// The timeout is ignored.
+func (w *SSHWorker) Stop(ctx context.Context, timeout *time.Duration) {
+	w.cancelOnce.Do(func() {
+		if w.cancel != nil {
+			w.cancel()
+		}
+	})
+}
+
+// Stopped returns a channel that receives the command's exit code once its
+// output is complete.
+func (w *SSHWorker) Stopped() <-chan int64 {
+	return w.exitStatus
+}
+
+// run executes the script on a single host, and returns its exit code. If
+// the command couldn't be run, or its exit code couldn't be determined, the
+// exit code is 1.
+func (w *SSHWorker) run(ctx context.Context, host data.SSHHost, hostKeys xssh.HostKeyCallback, script, prefix string, out chan<- string) (int64, error) {
+	cc, closeAuth, err := w.clientConfig(host, hostKeys)
+	if err != nil {
+		return 1, err
+	}
+	defer closeAuth()
+
+	client, err := dial(ctx, hostAddress(host), cc)
+	if err != nil {
+		return 1, fmt.Errorf("failed to connect to %s: %w", host.Name, err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return 1, fmt.Errorf("failed to open session on %s: %w", host.Name, err)
+	}
+	defer session.Close()
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return 1, err
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		return 1, err
+	}
+	session.Stdin = strings.NewReader(script)
+
+	log.WithField("bundle", w.command.Bundle.Name).
+		WithField("command", w.command.Command.Name).
+		WithField("host", host.Name).
+		Debug("Executing command over SSH")
+
+	// The script is passed on stdin, so that the environment (which
+	// includes the service token) doesn't appear in the remote process list.
+	if err := session.Start("/bin/sh -s"); err != nil {
+		return 1, fmt.Errorf("failed to start command on %s: %w", host.Name, err)
+	}
+
+	// Closing the connection is the only reliable way to terminate the
+	// remote command: many servers ignore signal requests.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Signal(xssh.SIGTERM)
+			client.Close()
+		case <-done:
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for _, r := range []io.Reader{stdout, stderr} {
+		wg.Add(1)
+		go func(r io.Reader) {
+			defer wg.Done()
+			scanner := bufio.NewScanner(r)
+			for scanner.Scan() {
+				send(ctx, out, prefix+scanner.Text())
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	err = session.Wait()
+
+	var exitErr *xssh.ExitError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr):
+		return int64(exitErr.ExitStatus()), nil
+	case ctx.Err() != nil:
+		return 1, ctx.Err()
+	default:
+		return 1, fmt.Errorf("command on %s failed: %w", host.Name, err)
+	}
+}
+
+// script returns the shell script that's executed on each host: it exports
+// the command's environment and then replaces itself with the command. If
+// the command has an input file, the script instead writes the file to a
+// temporary directory, runs the command, and then removes the directory.
+func (w *SSHWorker) script() string {
+	env := w.envVars()
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if !envNamePattern.MatchString(name) {
+			log.WithField("name", name).Warn("Skipping invalid environment variable name")
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "export %s=%s\n", name, shellQuote(env[name]))
+	}
+
+	args := append(append([]string{}, w.command.EntryPoint()...), w.command.Parameters...)
+	for i, a := range args {
+		args[i] = shellQuote(a)
+	}
+
+	f := w.command.InputFile
+	if f == nil {
+		fmt.Fprintf(&b, "exec %s\n", strings.Join(args, " "))
+		return b.String()
+	}
+
+	b.WriteString("GORT_INPUT_DIR=$(mktemp -d) || exit 1\n")
+	fmt.Fprintf(&b, "export %s=\"$GORT_INPUT_DIR\"/%s\n", data.EnvInputFile, shellQuote(f.FileName()))
+	fmt.Fprintf(&b, "base64 -d > \"$%s\" <<'%s'\n", data.EnvInputFile, inputFileDelimiter)
+
+	enc := base64.StdEncoding.EncodeToString(f.Data)
+	for len(enc) > 76 {
+		fmt.Fprintf(&b, "%s\n", enc[:76])
+		enc = enc[76:]
+	}
+	fmt.Fprintf(&b, "%s\n%s\n", enc, inputFileDelimiter)
+
+	// The rest must be on one line: the shell reads the script from stdin,
+	// which the command inherits.
+	fmt.Fprintf(&b, "%s; status=$?; rm -rf \"$GORT_INPUT_DIR\"; exit $status\n", strings.Join(args, " "))
+
+	return b.String()
+}
+
+// inputFileDelimiter ends the here-document that contains the input file.
+// It can't collide with base64 content, which never contains "_".
+const inputFileDelimiter = "GORT_INPUT_FILE_EOF"
+
+func (w *SSHWorker) envVars() map[string]string {
+	env := map[string]string{}
+
+	for k, v := range w.configs {
+		env[k] = v
+	}
+
+	if p := w.command.ExecutionProfile(); p != nil {
+		for k, v := range p.Env {
+			env[k] = v
+		}
+	}
+
+	vars := map[string]string{
+		`GORT_ADAPTER`:         w.command.Adapter,
+		`GORT_BUNDLE`:          w.command.Bundle.Name,
+		`GORT_CHANNEL_NAME`:    w.command.ChannelName,
+		`GORT_COMMAND`:         w.command.Command.Name,
+		`GORT_CHAT_ID`:         w.command.UserID,
+		`GORT_INVOCATION_ID`:   fmt.Sprintf("%d", w.command.RequestID),
+		`GORT_INVOCATION_TEXT`: w.command.InvocationText,
+		`GORT_PROFILE`:         w.command.Profile,
+		`GORT_ROOM`:            w.command.ChannelID,
+		`GORT_SERVICE_TOKEN`:   w.token.Token,
+		`GORT_SERVICES_ROOT`:   config.GetGortServerConfigs().APIURLBase,
+		`GORT_USER`:            w.command.UserName,
+		`GORT_USER_DISPLAY`:    w.command.UserDisplayName,
+		`GORT_WORKER_TOKEN`:    w.command.WorkerToken,
+	}
+
+	// The script sets the path of the input file, since it's placed in a
+	// temporary directory.
+	if f := w.command.InputFile; f != nil {
+		for k, v := range f.EnvVars(true) {
+			vars[k] = v
+		}
+		delete(vars, data.EnvInputFile)
+	}
+
+	for k, v := range vars {
+		env[k] = v
+	}
+
+	return env
+}
+
+// clientConfig returns the client configuration for host. The host's own
+// user and private key take precedence over those of the ssh section. The
+// returned function releases the SSH agent connection, if one was opened.
+func (w *SSHWorker) clientConfig(host data.SSHHost, hostKeys xssh.HostKeyCallback) (*xssh.ClientConfig, func(), error) {
+	closeAuth := func() {}
+
+	user := host.User
+	if user == "" {
+		user = w.sshConfigs.User
+	}
+	if user == "" {
+		return nil, nil, fmt.Errorf("no ssh user defined for host %s", host.Name)
+	}
+
+	keyFile := host.PrivateKeyFile
+	if keyFile == "" {
+		keyFile = w.sshConfigs.PrivateKeyFile
+	}
+
+	var auth xssh.AuthMethod
+
+	if keyFile != "" {
+		key, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read private key for host %s: %w", host.Name, err)
+		}
+
+		signer, err := xssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse private key for host %s: %w", host.Name, err)
+		}
+
+		auth = xssh.PublicKeys(signer)
+	} else {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, nil, fmt.Errorf("no private key defined for host %s and SSH_AUTH_SOCK not set", host.Name)
+		}
+
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to ssh agent: %w", err)
+		}
+
+		auth = xssh.PublicKeysCallback(agent.NewClient(conn).Signers)
+		closeAuth = func() { conn.Close() }
+	}
+
+	timeout := w.sshConfigs.ConnectTimeout
+	if timeout <= 0 {
+		timeout = DefaultConnectTimeout
+	}
+
+	return &xssh.ClientConfig{
+		User:            user,
+		Auth:            []xssh.AuthMethod{auth},
+		HostKeyCallback: hostKeys,
+		Timeout:         timeout,
+	}, closeAuth, nil
+}
+
+// hostKeyCallback verifies host keys against the configured known_hosts
+// file. Unknown hosts are always rejected.
+func (w *SSHWorker) hostKeyCallback() (xssh.HostKeyCallback, error) {
+	file := w.sshConfigs.KnownHostsFile
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	cb, err := knownhosts.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ssh known hosts: %w", err)
+	}
+
+	return cb, nil
+}
+
+// dial connects to addr, honoring both ctx and the config's timeout.
+func dial(ctx context.Context, addr string, cc *xssh.ClientConfig) (*xssh.Client, error) {
+	d := net.Dialer{Timeout: cc.Timeout}
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c, chans, reqs, err := xssh.NewClientConn(conn, addr, cc)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return xssh.NewClient(c, chans, reqs), nil
+}
+
+// hostAddress returns the host's address, with the default port added if
+// it doesn't include one.
+func hostAddress(host data.SSHHost) string {
+	if _, _, err := net.SplitHostPort(host.Address); err == nil {
+		return host.Address
+	}
+	return net.JoinHostPort(host.Address, "22")
+}
+
+// shellQuote quotes s for use as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func send(ctx context.Context, out chan<- string, line string) {
+	select {
+	case out <- line:
+	case <-ctx.Done():
+	}
+}
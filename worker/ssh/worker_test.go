@@ -0,0 +1,280 @@
+/*
+ * Copyright 2021 The Gort Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ssh
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	xssh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/getgort/gort/data"
+	"github.com/getgort/gort/data/rest"
+)
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'foo'`, shellQuote("foo"))
+	assert.Equal(t, `''`, shellQuote(""))
+	assert.Equal(t, `'it'\''s $HOME'`, shellQuote("it's $HOME"))
+}
+
+func TestScript(t *testing.T) {
+	r := data.CommandRequest{Parameters: []string{"it's"}}
+	r.Bundle.Name = "test"
+	r.Command.Executable = []string{"/bin/echo"}
+
+	w := &SSHWorker{command: r, configs: map[string]string{"API_KEY": "xyzzy", "NOT-VALID": "x"}}
+	script := w.script()
+
+	assert.Contains(t, script, "export API_KEY='xyzzy'\n")
+	assert.Contains(t, script, "export GORT_BUNDLE='test'\n")
+	assert.NotContains(t, script, "NOT-VALID")
+	assert.Contains(t, script, `exec '/bin/echo' 'it'\''s'`+"\n")
+}
+
+func TestScriptInputFile(t *testing.T) {
+	r := data.CommandRequest{InputFile: &data.InputFile{Name: "in.txt", Data: []byte("hello\n")}}
+	r.Command.Executable = []string{"/bin/cat"}
+
+	w := &SSHWorker{command: r, configs: map[string]string{}}
+	script := w.script()
+
+	assert.Contains(t, script, "export GORT_INPUT_FILE_NAME='in.txt'\n")
+	assert.Contains(t, script, `export GORT_INPUT_FILE="$GORT_INPUT_DIR"/'in.txt'`+"\n")
+	assert.Contains(t, script, "aGVsbG8K\nGORT_INPUT_FILE_EOF\n")
+	assert.NotContains(t, script, "exec ")
+}
+
+func TestWorkerInputFile(t *testing.T) {
+	dir := t.TempDir()
+	addr, configs := startServer(t, dir)
+
+	configs.Hosts = []data.SSHHost{{Name: "a", Address: addr, Tags: []string{"fleet"}}}
+
+	r := data.CommandRequest{InputFile: &data.InputFile{Name: "in.txt", Data: []byte("hello\nworld\n")}}
+	r.Bundle.Name = "test"
+	r.Bundle.SSH.Tags = []string{"fleet"}
+	r.Command.Name = "run"
+	r.Command.Executable = []string{"/bin/sh", "-c", `cat "$GORT_INPUT_FILE"; echo "$GORT_INPUT_FILE_NAME"`}
+
+	w, err := New(r, rest.Token{}, configs)
+	require.NoError(t, err)
+
+	out, err := w.Start(context.Background())
+	require.NoError(t, err)
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+
+	assert.Equal(t, []string{"hello", "world", "in.txt"}, lines)
+
+	select {
+	case code := <-w.Stopped():
+		assert.Equal(t, int64(0), code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker didn't report an exit code")
+	}
+}
+
+func TestHostAddress(t *testing.T) {
+	assert.Equal(t, "example.com:22", hostAddress(data.SSHHost{Address: "example.com"}))
+	assert.Equal(t, "example.com:2222", hostAddress(data.SSHHost{Address: "example.com:2222"}))
+}
+
+func TestWorkerExecution(t *testing.T) {
+	dir := t.TempDir()
+	addr, configs := startServer(t, dir)
+
+	configs.Hosts = []data.SSHHost{
+		{Name: "a", Address: addr, Tags: []string{"fleet"}},
+		{Name: "b", Address: addr, Tags: []string{"fleet"}},
+	}
+
+	r := data.CommandRequest{Parameters: []string{"it's"}}
+	r.Bundle.Name = "test"
+	r.Bundle.SSH.Tags = []string{"fleet"}
+	r.Command.Name = "run"
+	r.Command.Executable = []string{"/bin/sh", "-c", `echo "$GORT_BUNDLE $1"; echo oops >&2; exit 3`, "sh"}
+
+	w, err := New(r, rest.Token{}, configs)
+	require.NoError(t, err)
+
+	out, err := w.Start(context.Background())
+	require.NoError(t, err)
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+
+	assert.Equal(t, []string{"a: oops", "a: test it's", "b: oops", "b: test it's"}, lines)
+
+	select {
+	case code := <-w.Stopped():
+		assert.Equal(t, int64(3), code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker didn't report an exit code")
+	}
+}
+
+func TestWorkerUnknownHostKey(t *testing.T) {
+	dir := t.TempDir()
+	addr, configs := startServer(t, dir)
+
+	require.NoError(t, ioutil.WriteFile(configs.KnownHostsFile, nil, 0600))
+	configs.Hosts = []data.SSHHost{{Name: "a", Address: addr}}
+
+	r := data.CommandRequest{}
+	r.Bundle.SSH.Hosts = []string{"a"}
+	r.Command.Executable = []string{"/bin/true"}
+
+	w, err := New(r, rest.Token{}, configs)
+	require.NoError(t, err)
+
+	out, err := w.Start(context.Background())
+	require.NoError(t, err)
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "failed to connect to a")
+	assert.Equal(t, int64(1), <-w.Stopped())
+}
+
+// startServer starts an SSH server that executes each session's command
+// with the local shell. It returns the server's address, and configs with
+// a client key and known hosts file that it accepts.
+func startServer(t *testing.T, dir string) (string, data.SSHConfigs) {
+	hostKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := xssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	clientPub, err := xssh.NewPublicKey(&clientKey.PublicKey)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalECPrivateKey(clientKey)
+	require.NoError(t, err)
+	keyFile := filepath.Join(dir, "id_ecdsa")
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+
+	sc := &xssh.ServerConfig{
+		PublicKeyCallback: func(c xssh.ConnMetadata, key xssh.PublicKey) (*xssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientPub.Marshal()) {
+				return nil, nil
+			}
+			return nil, assert.AnError
+		},
+	}
+	sc.AddHostKey(hostSigner)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn, sc)
+		}
+	}()
+
+	addr := l.Addr().String()
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostSigner.PublicKey())
+	require.NoError(t, ioutil.WriteFile(knownHostsFile, []byte(line+"\n"), 0600))
+
+	return addr, data.SSHConfigs{
+		User:           "gort",
+		PrivateKeyFile: keyFile,
+		KnownHostsFile: knownHostsFile,
+	}
+}
+
+func serve(conn net.Conn, sc *xssh.ServerConfig) {
+	_, chans, reqs, err := xssh.NewServerConn(conn, sc)
+	if err != nil {
+		return
+	}
+	go xssh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			nc.Reject(xssh.UnknownChannelType, "unsupported")
+			continue
+		}
+
+		ch, reqs, err := nc.Accept()
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			defer ch.Close()
+
+			for req := range reqs {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+
+				var payload struct{ Command string }
+				xssh.Unmarshal(req.Payload, &payload)
+				req.Reply(true, nil)
+
+				cmd := exec.Command("/bin/sh", "-c", payload.Command)
+				cmd.Stdin, cmd.Stdout, cmd.Stderr = ch, ch, ch.Stderr()
+
+				status := uint32(0)
+				if err := cmd.Run(); err != nil {
+					status = 1
+					if e, ok := err.(*exec.ExitError); ok {
+						status = uint32(e.ExitCode())
+					}
+				}
+
+				ch.SendRequest("exit-status", false, xssh.Marshal(struct{ Status uint32 }{status}))
+				return
+			}
+		}()
+	}
+}
@@ -19,6 +19,7 @@ package worker
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/getgort/gort/config"
@@ -26,6 +27,10 @@ import (
 	"github.com/getgort/gort/data/rest"
 	"github.com/getgort/gort/worker/docker"
 	"github.com/getgort/gort/worker/kubernetes"
+	"github.com/getgort/gort/worker/mock"
+	"github.com/getgort/gort/worker/native"
+	"github.com/getgort/gort/worker/serverless"
+	"github.com/getgort/gort/worker/ssh"
 )
 
 // Worker represents a container executor. It has a lifetime of a single command execution.
@@ -36,19 +41,110 @@ type Worker interface {
 	Stopped() <-chan int64
 }
 
+// Factory builds a Worker for a single command execution.
+type Factory func(command data.CommandRequest, token rest.Token) (Worker, error)
+
+var factory Factory
+
+// SetFactory overrides how New builds workers, allowing the relay to be
+// exercised without a container engine. A nil factory restores the default.
+func SetFactory(f Factory) {
+	factory = f
+}
+
+// StartHealthChecks periodically checks the health of the clusters that
+// commands may be executed on, if the configured engine has any. It returns
+// when ctx is canceled.
+func StartHealthChecks(ctx context.Context) {
+	if factory != nil {
+		return
+	}
+
+	if e, err := engine(); err != nil || e != data.WorkerEngineKubernetes {
+		return
+	}
+
+	kubernetes.StartHealthChecks(ctx)
+}
+
+// CheckNamespaces returns an error if a namespace that's configured for
+// commands to run in doesn't exist, if the configured engine has any.
+func CheckNamespaces(ctx context.Context) error {
+	if factory != nil {
+		return nil
+	}
+
+	if e, err := engine(); err != nil || e != data.WorkerEngineKubernetes {
+		return nil
+	}
+
+	return kubernetes.CheckNamespaces(ctx)
+}
+
 // New will build and return a new Worker for a single command execution.
 func New(command data.CommandRequest, token rest.Token) (Worker, error) {
-	dockerDefined := !config.Undefined(config.GetDockerConfigs())
-	kubernetesDefined := !config.Undefined(config.GetKubernetesConfigs())
+	if factory != nil {
+		return factory(command, token)
+	}
+
+	e, err := engine()
+	if err != nil {
+		return nil, err
+	}
 
-	switch {
-	case dockerDefined && kubernetesDefined:
-		return nil, fmt.Errorf("exactly one of the following config sections expected: docker, kubernetes")
-	case dockerDefined:
+	switch e {
+	case data.WorkerEngineDocker:
 		return docker.New(command, token)
-	case kubernetesDefined:
+	case data.WorkerEngineKubernetes:
 		return kubernetes.New(command, token)
+	case data.WorkerEngineNative:
+		return native.New(command, token, config.GetNativeConfigs())
+	case data.WorkerEngineServerless:
+		return serverless.New(command, token, config.GetServerlessConfigs())
+	case data.WorkerEngineSSH:
+		return ssh.New(command, token, config.GetSSHConfigs())
 	default:
-		return nil, fmt.Errorf("exactly one of the following config sections expected: docker, kubernetes")
+		return mock.New(command, token, config.GetMockConfigs())
+	}
+}
+
+// engine returns the worker engine that commands are executed with. It's
+// the one named by worker.engine if that's set; otherwise it's whichever
+// engine has a config section, of which there must be exactly one.
+func engine() (string, error) {
+	defined := map[string]bool{
+		data.WorkerEngineDocker:     !config.Undefined(config.GetDockerConfigs()),
+		data.WorkerEngineKubernetes: !config.Undefined(config.GetKubernetesConfigs()),
+		data.WorkerEngineMock:       !config.Undefined(config.GetMockConfigs()),
+		data.WorkerEngineNative:     !config.Undefined(config.GetNativeConfigs()),
+		data.WorkerEngineServerless: !config.Undefined(config.GetServerlessConfigs()),
+		data.WorkerEngineSSH:        !config.Undefined(config.GetSSHConfigs()),
 	}
+
+	if e := config.GetWorkerConfigs().Engine; e != "" {
+		// The docker and kubernetes engines have usable defaults, but the
+		// others can't run without their config sections.
+		switch e {
+		case data.WorkerEngineDocker, data.WorkerEngineKubernetes:
+		default:
+			if !defined[e] {
+				return "", fmt.Errorf("worker engine %q selected, but there is no %s config section", e, e)
+			}
+		}
+		return e, nil
+	}
+
+	var found []string
+	for _, e := range data.WorkerEngines {
+		if defined[e] {
+			found = append(found, e)
+		}
+	}
+
+	if len(found) != 1 {
+		return "", fmt.Errorf("exactly one of the following config sections expected: %s; or select one with worker.engine",
+			strings.Join(data.WorkerEngines, ", "))
+	}
+
+	return found[0], nil
 }